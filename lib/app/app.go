@@ -207,6 +207,8 @@ type HookRunRequest struct {
 	Application loc.Locator `json:"application"`
 	// Hook defines the hook to run
 	Hook schema.HookType `json:"hook"`
+	// SmokeTest names the smoke test to run if the hook is a smoke test
+	SmokeTest string `json:"smoke_test,omitempty"`
 	// Volumes lists additional volumes to add inside a hook's job container
 	Volumes []v1.Volume `json:"volumes"`
 	// VolumeMounts lists additional volume mounts to create inside a hook's job container
@@ -272,6 +274,19 @@ type InstallerRequest struct {
 	CACert string `json:"ca_cert,omitempty"`
 	// EncryptionKey is encryption key to encrypt installer packages with
 	EncryptionKey string `json:"encryption_key,omitempty"`
+	// BaseImage optionally specifies the cluster image to build a delta installer against
+	BaseImage *loc.Locator `json:"base_image,omitempty"`
+	// ExcludePackages lists packages to leave out of a delta installer
+	// as they are unchanged since the base image
+	ExcludePackages []loc.Locator `json:"exclude_packages,omitempty"`
+	// ExcludeBaseImage leaves the packages a layered cluster image inherits
+	// from its base cluster image out of the installer. The base cluster image
+	// is supplied separately at install time
+	ExcludeBaseImage bool `json:"exclude_base_image,omitempty"`
+	// Timestamp optionally specifies the time to record in the installer
+	// instead of the current time. Installers generated with the timestamp
+	// from identical inputs are byte-identical
+	Timestamp time.Time `json:"timestamp"`
 }
 
 // Check validates this request
@@ -282,6 +297,9 @@ func (r InstallerRequest) Check() error {
 	if r.EncryptionKey != "" && r.CACert == "" {
 		return trace.BadParameter("CACert is required when EncryptionKey is provided")
 	}
+	if len(r.ExcludePackages) != 0 && r.BaseImage == nil {
+		return trace.BadParameter("BaseImage is required when ExcludePackages is provided")
+	}
 	return nil
 }
 
@@ -292,11 +310,15 @@ func (r InstallerRequest) ToRaw() (*InstallerRequestRaw, error) {
 		return nil, trace.Wrap(err)
 	}
 	return &InstallerRequestRaw{
-		Account:        r.Account,
-		Application:    r.Application,
-		TrustedCluster: json.RawMessage(bytes),
-		CACert:         r.CACert,
-		EncryptionKey:  r.EncryptionKey,
+		Account:          r.Account,
+		Application:      r.Application,
+		TrustedCluster:   json.RawMessage(bytes),
+		CACert:           r.CACert,
+		EncryptionKey:    r.EncryptionKey,
+		BaseImage:        r.BaseImage,
+		ExcludePackages:  r.ExcludePackages,
+		ExcludeBaseImage: r.ExcludeBaseImage,
+		Timestamp:        r.Timestamp,
 	}, nil
 }
 
@@ -313,6 +335,14 @@ type InstallerRequestRaw struct {
 	CACert string `json:"ca_cert,omitempty"`
 	// EncryptionKey is encryption key to encrypt installer packages with
 	EncryptionKey string `json:"encryption_key,omitempty"`
+	// BaseImage is the cluster image to build a delta installer against
+	BaseImage *loc.Locator `json:"base_image,omitempty"`
+	// ExcludePackages lists packages to leave out of a delta installer
+	ExcludePackages []loc.Locator `json:"exclude_packages,omitempty"`
+	// ExcludeBaseImage leaves the packages inherited from the base cluster image out of the installer
+	ExcludeBaseImage bool `json:"exclude_base_image,omitempty"`
+	// Timestamp is the time to record in the installer instead of the current time
+	Timestamp time.Time `json:"timestamp"`
 }
 
 // ToNative converts the request from API-friendly to its regular format
@@ -322,11 +352,15 @@ func (r InstallerRequestRaw) ToNative() (*InstallerRequest, error) {
 		return nil, trace.Wrap(err)
 	}
 	return &InstallerRequest{
-		Account:        r.Account,
-		Application:    r.Application,
-		TrustedCluster: cluster,
-		CACert:         r.CACert,
-		EncryptionKey:  r.EncryptionKey,
+		Account:          r.Account,
+		Application:      r.Application,
+		TrustedCluster:   cluster,
+		CACert:           r.CACert,
+		EncryptionKey:    r.EncryptionKey,
+		BaseImage:        r.BaseImage,
+		ExcludePackages:  r.ExcludePackages,
+		ExcludeBaseImage: r.ExcludeBaseImage,
+		Timestamp:        r.Timestamp,
 	}, nil
 }
 
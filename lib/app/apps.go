@@ -91,6 +91,18 @@ func CheckHasAppHook(apps Applications, req HookRunRequest) (*schema.Hook, error
 		return nil, trace.Wrap(err)
 	}
 
+	if req.Hook == schema.HookSmokeTest {
+		test, err := app.Manifest.GetSmokeTest(req.SmokeTest)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if test.Job == "" {
+			return nil, trace.NotFound("smoke test %q of %v:%v is not a job",
+				req.SmokeTest, req.Application.Name, req.Application.Version)
+		}
+		return test.GetHook(), nil
+	}
+
 	if app.Manifest.Hooks == nil {
 		return nil, trace.NotFound("%v:%v does not have hooks",
 			req.Application.Name, req.Application.Version)
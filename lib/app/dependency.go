@@ -77,6 +77,33 @@ func VerifyDependencies(app *Application, apps Applications, packages pack.Packa
 	return nil
 }
 
+// GetBaseImages returns the chain of base applications of the specified application
+// starting with the runtime application.
+//
+// A layered cluster image is built on top of another cluster image instead of
+// the runtime directly, in which case the chain also includes all the cluster
+// images in between, ordered from the bottom up
+func GetBaseImages(app Application, apps Applications) (bases []Application, err error) {
+	visited := make(map[loc.Locator]struct{})
+	for base := app.Manifest.Base(); base != nil; {
+		if _, ok := visited[*base]; ok {
+			return nil, trace.BadParameter("application %v has a cyclic base image chain at %v",
+				app.Package, base)
+		}
+		visited[*base] = struct{}{}
+		baseApp, err := apps.GetApp(*base)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		bases = append([]Application{*baseApp}, bases...)
+		base = baseApp.Manifest.Base()
+	}
+	if len(bases) == 0 {
+		return nil, trace.NotFound("application %v does not have a runtime", app.Package)
+	}
+	return bases, nil
+}
+
 // Dependencies defines a set of package and application dependencies
 // for an application
 type Dependencies struct {
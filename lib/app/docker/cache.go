@@ -0,0 +1,147 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"bytes"
+
+	"github.com/gravitational/gravity/lib/buildcache"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/context"
+	"github.com/gravitational/trace"
+	"github.com/opencontainers/go-digest"
+)
+
+// CacheImage stores the image with the specified repository and tag from
+// the local registry directory dir in the build cache under the specified key,
+// so it can be restored with RestoreImage without exporting it again
+func CacheImage(ctx context.Context, cache *buildcache.Cache, dir, repository, tag, key string) error {
+	local, err := openLocal(dir)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	localRepo, err := local.Repository(ctx, repository)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	desc, err := localRepo.Tags(ctx).Get(ctx, tag)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	localManifests, err := localRepo.Manifests(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	manifest, err := localManifests.Get(ctx, desc.Digest)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	blobs := localRepo.Blobs(ctx)
+	for _, ref := range manifest.References() {
+		if err := cacheBlob(ctx, cache, blobs, ref.Digest); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	mediaType, payload, err := manifest.Payload()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := cache.Put(desc.Digest, bytes.NewReader(payload)); err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(cache.PutRef(key, distribution.Descriptor{
+		MediaType: mediaType,
+		Digest:    desc.Digest,
+		Size:      int64(len(payload)),
+	}))
+}
+
+// RestoreImage restores the image cached under the specified key into
+// the local registry directory dir with the specified repository and tag.
+// Returns NotFound if the build cache has no image with the specified key
+func RestoreImage(ctx context.Context, cache *buildcache.Cache, dir, repository, tag, key string) error {
+	desc, err := cache.GetRef(key)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	payload, err := cache.Get(ctx, desc.Digest)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	manifest, _, err := distribution.UnmarshalManifest(desc.MediaType, payload)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	local, err := openLocal(dir)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	localRepo, err := local.Repository(ctx, repository)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	dgst, err := storeManifest(ctx, cache, localRepo, manifest)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(localRepo.Tags(ctx).Tag(ctx, tag, distribution.Descriptor{
+		MediaType: desc.MediaType,
+		Digest:    dgst,
+	}))
+}
+
+// cachingBlobProvider serves the blobs from the build cache
+// and downloads the blobs missing from the cache into it first
+type cachingBlobProvider struct {
+	remote distribution.BlobProvider
+	cache  *buildcache.Cache
+}
+
+// Get returns the contents of the blob with the specified digest
+func (r *cachingBlobProvider) Get(ctx context.Context, dgst digest.Digest) ([]byte, error) {
+	if err := cacheBlob(ctx, r.cache, r.remote, dgst); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return r.cache.Get(ctx, dgst)
+}
+
+// Open returns the reader for the blob with the specified digest
+func (r *cachingBlobProvider) Open(ctx context.Context, dgst digest.Digest) (distribution.ReadSeekCloser, error) {
+	if err := cacheBlob(ctx, r.cache, r.remote, dgst); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return r.cache.Open(ctx, dgst)
+}
+
+// cacheBlob copies the blob with the specified digest into the build cache
+// unless it is already cached
+func cacheBlob(ctx context.Context, cache *buildcache.Cache, from distribution.BlobProvider, dgst digest.Digest) error {
+	_, err := cache.Stat(ctx, dgst)
+	if err == nil {
+		return nil
+	}
+	if !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	reader, err := from.Open(ctx, dgst)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer reader.Close()
+	return trace.Wrap(cache.Put(dgst, reader))
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"github.com/gravitational/gravity/lib/buildcache"
+
+	"github.com/docker/distribution/context"
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+type CacheSuite struct{}
+
+var _ = Suite(&CacheSuite{})
+
+func (s *CacheSuite) TestCachesAndRestoresImages(c *C) {
+	ctx := context.Background()
+	cache, err := buildcache.New(c.MkDir())
+	c.Assert(err, IsNil)
+
+	dir := c.MkDir()
+	image := newTestImage(c, dir, "org/app", "1.0.0")
+	c.Assert(CacheImage(ctx, cache, dir, "org/app", "1.0.0", "sha256:image"), IsNil)
+
+	dir = c.MkDir()
+	err = RestoreImage(ctx, cache, dir, "org/app", "2.0.0", "sha256:another")
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%v", err))
+
+	c.Assert(RestoreImage(ctx, cache, dir, "org/app", "2.0.0", "sha256:image"), IsNil)
+	images, err := ListImages(ctx, dir)
+	c.Assert(err, IsNil)
+	c.Assert(images, DeepEquals, []LocalImage{
+		{TagSpec: TagSpec{Name: "org/app", Version: "2.0.0"}, Digest: image},
+	})
+}
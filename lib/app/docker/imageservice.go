@@ -39,6 +39,7 @@ import (
 
 	"github.com/docker/distribution"
 	"github.com/docker/distribution/context"
+	"github.com/docker/distribution/manifest/manifestlist"
 	"github.com/docker/distribution/registry/api/errcode"
 	registryclient "github.com/docker/distribution/registry/client"
 	registrystorage "github.com/docker/distribution/registry/storage"
@@ -46,6 +47,7 @@ import (
 	"github.com/docker/distribution/registry/storage/driver/filesystem"
 	"github.com/docker/libtrust"
 	"github.com/gravitational/trace"
+	"github.com/opencontainers/go-digest"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -177,6 +179,12 @@ func (r *imageService) Sync(ctx context.Context, dir string, progress utils.Prin
 			return nil, trace.Wrap(err)
 		}
 		for _, tag := range tags {
+			// signatures are verified before the sync and are not
+			// pushed to the registry
+			if isSignatureTag(tag) {
+				r.Debugf("Skipping signature %v:%v.", localRepoName, tag)
+				continue
+			}
 			desc, err := localTags.Get(ctx, tag)
 			if err != nil {
 				return nil, trace.Wrap(err)
@@ -426,6 +434,84 @@ func (l *localStore) Repository(ctx context.Context, name string) (distribution.
 	return l.registry.Repository(ctx, named)
 }
 
+// ListImages returns all images in the local registry directory dir.
+// Image signatures are not included
+func ListImages(ctx context.Context, dir string) (images []LocalImage, err error) {
+	localStore, err := openLocal(dir)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to open local directory %q as local registry", dir)
+	}
+	images, err = listImages(ctx, localStore)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to list images in %q", dir)
+	}
+	return images, nil
+}
+
+// ListRegistryImages returns all images in the registry with the specified
+// connection parameters. Image signatures are not included
+func ListRegistryImages(ctx context.Context, req RegistryConnectionRequest) (images []LocalImage, err error) {
+	if err := req.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	remoteStore, err := ConnectRegistry(ctx, req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	images, err = listImages(ctx, remoteStore)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to list images in %v", req.RegistryAddress)
+	}
+	return images, nil
+}
+
+// imageStore is a registry with named repositories
+type imageStore interface {
+	registryclient.Registry
+	// Repository provides access to the repository named with name
+	Repository(ctx context.Context, name string) (distribution.Repository, error)
+}
+
+func listImages(ctx context.Context, store imageStore) (images []LocalImage, err error) {
+	repos, err := ListRepos(ctx, store)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to list repositories")
+	}
+	for _, repoName := range repos {
+		repo, err := store.Repository(ctx, repoName)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		tagService := repo.Tags(ctx)
+		tags, err := tagService.All(ctx)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		for _, tag := range tags {
+			if isSignatureTag(tag) {
+				continue
+			}
+			desc, err := tagService.Get(ctx, tag)
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			images = append(images, LocalImage{
+				TagSpec: TagSpec{Name: repoName, Version: tag},
+				Digest:  desc.Digest,
+			})
+		}
+	}
+	return images, nil
+}
+
+// LocalImage describes an image in a local registry directory
+type LocalImage struct {
+	// TagSpec is the image name and tag
+	TagSpec
+	// Digest is the digest of the image manifest
+	Digest digest.Digest
+}
+
 func ListRepos(ctx context.Context, namespace registryclient.Registry) (repos []string, err error) {
 	const pageSize = 50
 	var last string
@@ -476,12 +562,33 @@ func compareManifests(a, b distribution.Manifest) bool {
 
 // updateRepo takes a pair of local+remote repositories and makes the remote repo identical
 // to the local one.
+// For multi-platform images, the image manifests the manifest list references
+// are pushed by digest before the manifest list itself.
+// The manifest is pushed without a tag if tag is empty
 func (s *remoteStore) updateRepo(ctx context.Context, remote, local distribution.Repository, manifest distribution.Manifest, tag string) error {
 	s.Debugf("Pushing %[1]v --> %[2]v/%[1]v.", local.Named(), s.addr)
 	remoteManifests, err := remote.Manifests(ctx)
 	if err != nil {
 		return trace.Wrap(err)
 	}
+	if _, ok := manifest.(*manifestlist.DeserializedManifestList); ok {
+		localManifests, err := local.Manifests(ctx)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		for _, desc := range manifest.References() {
+			platformManifest, err := localManifests.Get(ctx, desc.Digest)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			if err := s.updateRepo(ctx, remote, local, platformManifest, ""); err != nil {
+				return trace.Wrap(err)
+			}
+		}
+		s.Debugf("Updating manifest list for %v.", local.Named())
+		_, err = remoteManifests.Put(ctx, manifest, distribution.WithTag(tag))
+		return trace.Wrap(err)
+	}
 	localBlobs := local.Blobs(ctx)
 	remoteBlobs := remote.Blobs(ctx)
 	// copy layers:
@@ -513,6 +620,10 @@ func (s *remoteStore) updateRepo(ctx context.Context, remote, local distribution
 		s.Debugf("Written %v bytes.", written)
 	}
 	s.Debugf("Updating manifest for %v.", local.Named())
-	_, err = remoteManifests.Put(ctx, manifest, distribution.WithTag(tag))
+	var options []distribution.ManifestServiceOption
+	if tag != "" {
+		options = append(options, distribution.WithTag(tag))
+	}
+	_, err = remoteManifests.Put(ctx, manifest, options...)
 	return trace.Wrap(err)
 }
@@ -0,0 +1,571 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gravitational/gravity/lib/buildcache"
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/run"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/context"
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/manifest/schema2"
+	registryclient "github.com/docker/distribution/registry/client"
+	"github.com/docker/distribution/registry/client/auth"
+	"github.com/docker/distribution/registry/client/auth/challenge"
+	"github.com/docker/distribution/registry/client/transport"
+	"github.com/gravitational/trace"
+	"github.com/opencontainers/go-digest"
+	log "github.com/sirupsen/logrus"
+)
+
+// RegistryCredentials defines the credentials to authenticate with a Docker registry
+type RegistryCredentials struct {
+	// Username is the registry user name
+	Username string
+	// Password is the user password or access token
+	Password string
+}
+
+// RegistryAuth maps registry addresses to the credentials to authenticate with them
+type RegistryAuth map[string]RegistryCredentials
+
+// Get returns the credentials for the registry with the specified address
+func (r RegistryAuth) Get(registry string) RegistryCredentials {
+	return r[registryHost(registry)]
+}
+
+// DockerConfigPath returns the path to the Docker client configuration file
+// of the current user
+func DockerConfigPath() string {
+	if dir := os.Getenv(constants.EnvDockerConfig); dir != "" {
+		return filepath.Join(dir, dockerConfigFile)
+	}
+	return filepath.Join(os.Getenv(constants.EnvHome), ".docker", dockerConfigFile)
+}
+
+// ReadRegistryAuth reads the registry credentials from the Docker client
+// configuration file at the specified path.
+// Only the credentials stored in the file itself are supported,
+// credential helpers are not consulted
+func ReadRegistryAuth(path string) (RegistryAuth, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	var config dockerClientConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, trace.BadParameter("invalid Docker client configuration %v: %v", path, err)
+	}
+	registryAuth := make(RegistryAuth)
+	for registry, entry := range config.Auths {
+		creds := RegistryCredentials{
+			Username: entry.Username,
+			Password: entry.Password,
+		}
+		if entry.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+			if err != nil {
+				return nil, trace.BadParameter("invalid credentials for registry %v in %v", registry, path)
+			}
+			parts := strings.SplitN(string(decoded), ":", 2)
+			if len(parts) != 2 {
+				return nil, trace.BadParameter("invalid credentials for registry %v in %v", registry, path)
+			}
+			creds = RegistryCredentials{
+				Username: parts[0],
+				Password: parts[1],
+			}
+		}
+		registryAuth[registryHost(registry)] = creds
+	}
+	return registryAuth, nil
+}
+
+// PullRequest describes a request to pull images from remote registries
+type PullRequest struct {
+	// Images lists the images to pull.
+	// Images can be pinned to a manifest digest, e.g. 'nginx@sha256:...'
+	Images []string
+	// Dir is the local registry directory to pull the images into
+	Dir string
+	// Auth specifies the registry credentials
+	Auth RegistryAuth
+	// Insecure disables the verification of the registry TLS certificates
+	Insecure bool
+	// Parallel defines the number of images to pull concurrently
+	Parallel int
+	// Progress reports the pull progress
+	Progress utils.Progress
+	// Architectures lists the CPU architectures to pull the images for.
+	// Defaults to amd64
+	Architectures []string
+	// Cache optionally specifies the build cache to serve the image blobs from.
+	// Blobs missing from the cache are downloaded into it
+	Cache *buildcache.Cache
+}
+
+func (r PullRequest) architectures() []string {
+	if len(r.Architectures) == 0 {
+		return []string{constants.ArchAMD64}
+	}
+	return r.Architectures
+}
+
+// blobs returns the provider of the blobs of the specified remote repository
+func (r PullRequest) blobs(ctx context.Context, remote distribution.Repository) distribution.BlobProvider {
+	if r.Cache == nil {
+		return remote.Blobs(ctx)
+	}
+	return &cachingBlobProvider{remote: remote.Blobs(ctx), cache: r.Cache}
+}
+
+// PullImages pulls the specified images from their registries into the local
+// registry directory without going through the Docker daemon.
+//
+// The images are stored in the local registry without the registry address.
+// Images pinned to a digest are verified against it and stored
+// under the tag returned by UnpinImage.
+// If an image reference resolves to a multi-platform manifest list,
+// only the linux images for the requested architectures are pulled.
+// With multiple architectures, the images are stored as a manifest list
+// that only references these images
+func PullImages(ctx context.Context, req PullRequest) error {
+	if req.Progress == nil {
+		req.Progress = utils.DiscardProgress
+	}
+	local, err := openLocal(req.Dir)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	group, groupCtx := run.WithContext(ctx, run.WithParallel(req.Parallel))
+	for _, image := range req.Images {
+		image := image
+		group.Go(groupCtx, func() error {
+			if err := pullImage(groupCtx, local, image, req); err != nil {
+				return trace.Wrap(err, "failed to pull %v", image)
+			}
+			req.Progress.PrintSubStep("Vendored image %v", image)
+			return nil
+		})
+	}
+	return trace.Wrap(group.Wait())
+}
+
+// PushArtifactRequest describes a request to publish a file
+// to a remote registry as an artifact
+type PushArtifactRequest struct {
+	// Image is the reference to publish the artifact under, e.g. 'registry.example.com/org/name:tag'
+	Image string
+	// Path is the path to the artifact file
+	Path string
+	// MediaType is the media type of the artifact file
+	MediaType string
+	// Config is the artifact configuration
+	Config []byte
+	// ConfigMediaType is the media type of the artifact configuration
+	ConfigMediaType string
+	// Auth specifies the registry credentials
+	Auth RegistryAuth
+	// Insecure disables the verification of the registry TLS certificates
+	Insecure bool
+}
+
+// PushArtifact uploads the specified file to the remote registry as a single
+// layer of an image manifest with the custom configuration media type.
+// Returns the digest of the published manifest
+func PushArtifact(ctx context.Context, req PushArtifactRequest) (digest.Digest, error) {
+	parsed, err := loc.ParseDockerImage(req.Image)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	if parsed.Tag == "" || strings.HasPrefix(parsed.Tag, string(digest.SHA256)+":") {
+		return "", trace.BadParameter("artifact reference %v should specify a tag", req.Image)
+	}
+	repo, err := connectRemoteRepository(ctx, parsed, req.Auth, req.Insecure, "pull", "push")
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	f, err := os.Open(req.Path)
+	if err != nil {
+		return "", trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	blobs := repo.Blobs(ctx)
+	writer, err := blobs.Create(ctx)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	defer writer.Close()
+	digester := digest.Canonical.Digester()
+	size, err := io.Copy(io.MultiWriter(writer, digester.Hash()), f)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	layer, err := writer.Commit(ctx, distribution.Descriptor{
+		MediaType: req.MediaType,
+		Digest:    digester.Digest(),
+		Size:      size,
+	})
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	// The blob service does not preserve the media type of the layer
+	layer.MediaType = req.MediaType
+	builder := schema2.NewManifestBuilder(blobs, req.ConfigMediaType, req.Config)
+	if err := builder.AppendReference(layer); err != nil {
+		return "", trace.Wrap(err)
+	}
+	manifest, err := builder.Build(ctx)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	manifests, err := repo.Manifests(ctx)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	dgst, err := manifests.Put(ctx, manifest, distribution.WithTag(parsed.Tag))
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return dgst, nil
+}
+
+// UnpinImage returns the reference to the image pinned to a manifest
+// digest using the tag derived from the digest, e.g.
+//
+//	nginx@sha256:<hex> -> nginx:sha256-<hex>
+//
+// References without a digest are returned unchanged
+func UnpinImage(image string) string {
+	parsed, err := loc.ParseDockerImage(image)
+	if err != nil {
+		return image
+	}
+	dgst, err := digest.Parse(parsed.Tag)
+	if err != nil {
+		return image
+	}
+	parsed.Tag = digestTag(dgst)
+	return parsed.String()
+}
+
+func pullImage(ctx context.Context, local *localStore, image string, req PullRequest) error {
+	parsed, err := loc.ParseDockerImage(image)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	remote, err := connectRemoteRepository(ctx, parsed, req.Auth, req.Insecure, "pull")
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	manifests, err := remote.Manifests(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	tag := parsed.Tag
+	var manifest distribution.Manifest
+	if dgst, err := digest.Parse(parsed.Tag); err == nil {
+		manifest, err = getManifest(ctx, manifests, dgst)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		tag = digestTag(dgst)
+	} else {
+		if tag == "" {
+			tag = "latest"
+		}
+		manifest, err = manifests.Get(ctx, "", distribution.WithTag(tag))
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	localRepo, err := local.Repository(ctx, parsed.Repository)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	architectures := req.architectures()
+	if list, ok := manifest.(*manifestlist.DeserializedManifestList); ok {
+		manifest, err = selectPlatforms(ctx, req.blobs(ctx, remote), manifests, localRepo, *list, architectures)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	} else if len(architectures) > 1 {
+		log.WithField("image", image).Warnf("Image is not a multi-platform image, "+
+			"it is vendored as-is for all of %v.", architectures)
+	}
+	dgst, err := storeManifest(ctx, req.blobs(ctx, remote), localRepo, manifest)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	mediaType, _, err := manifest.Payload()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// The local manifest service does not tag the manifests
+	return trace.Wrap(localRepo.Tags(ctx).Tag(ctx, tag, distribution.Descriptor{
+		MediaType: mediaType,
+		Digest:    dgst,
+	}))
+}
+
+// selectPlatforms returns the image manifest for the single specified architecture
+// from the manifest list. For multiple architectures, it stores the image manifests
+// of these architectures in the local repository and returns the manifest list
+// that only references them
+func selectPlatforms(ctx context.Context, blobs distribution.BlobProvider, manifests distribution.ManifestService, localRepo distribution.Repository, list manifestlist.DeserializedManifestList, architectures []string) (distribution.Manifest, error) {
+	var selected []manifestlist.ManifestDescriptor
+	for _, arch := range architectures {
+		desc, err := selectPlatform(list, arch)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		manifest, err := getManifest(ctx, manifests, desc.Digest)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if len(architectures) == 1 {
+			return manifest, nil
+		}
+		if _, err := storeManifest(ctx, blobs, localRepo, manifest); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		selected = append(selected, *desc)
+	}
+	return manifestlist.FromDescriptors(selected)
+}
+
+// storeManifest copies the blobs the specified manifest references
+// from the blob provider and the manifest itself to the local repository.
+// The image manifests a manifest list references should be stored first
+func storeManifest(ctx context.Context, blobs distribution.BlobProvider, localRepo distribution.Repository, manifest distribution.Manifest) (digest.Digest, error) {
+	switch manifest.(type) {
+	case *schema2.DeserializedManifest:
+		for _, desc := range manifest.References() {
+			if err := copyBlob(ctx, blobs, localRepo.Blobs(ctx), desc); err != nil {
+				return "", trace.Wrap(err)
+			}
+		}
+	case *manifestlist.DeserializedManifestList:
+	default:
+		mediaType, _, _ := manifest.Payload()
+		return "", trace.BadParameter("unsupported manifest type %q, only %q is supported",
+			mediaType, schema2.MediaTypeManifest)
+	}
+	localManifests, err := localRepo.Manifests(ctx)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	dgst, err := localManifests.Put(ctx, manifest)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return dgst, nil
+}
+
+// getManifest returns the manifest with the specified digest
+// after verifying that its contents match the digest
+func getManifest(ctx context.Context, manifests distribution.ManifestService, dgst digest.Digest) (distribution.Manifest, error) {
+	manifest, err := manifests.Get(ctx, dgst)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	_, payload, err := manifest.Payload()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if actual := dgst.Algorithm().FromBytes(payload); actual != dgst {
+		return nil, trace.BadParameter("manifest digest %v does not match the pinned digest %v", actual, dgst)
+	}
+	return manifest, nil
+}
+
+// selectPlatform returns the descriptor of the linux image for the specified
+// architecture from the manifest list
+func selectPlatform(list manifestlist.DeserializedManifestList, arch string) (*manifestlist.ManifestDescriptor, error) {
+	for _, manifest := range list.Manifests {
+		if manifest.Platform.OS == "linux" && manifest.Platform.Architecture == arch {
+			return &manifest, nil
+		}
+	}
+	return nil, trace.NotFound("manifest list has no linux/%v image", arch)
+}
+
+// copyBlob copies the blob with the specified descriptor unless it already exists
+// in the destination. The destination verifies the contents against the digest
+func copyBlob(ctx context.Context, from distribution.BlobProvider, to distribution.BlobService, desc distribution.Descriptor) error {
+	if existing, err := to.Stat(ctx, desc.Digest); err == nil && existing.Digest == desc.Digest {
+		return nil
+	}
+	reader, err := from.Open(ctx, desc.Digest)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer reader.Close()
+	writer, err := to.Create(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer writer.Close()
+	if _, err := io.Copy(writer, reader); err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = writer.Commit(ctx, distribution.Descriptor{
+		MediaType: desc.MediaType,
+		Digest:    desc.Digest,
+		Size:      desc.Size,
+	})
+	return trace.Wrap(err)
+}
+
+// connectRemoteRepository returns the client for the repository of the specified image
+// authorized for the specified actions
+func connectRemoteRepository(ctx context.Context, image *loc.DockerImage, registryAuth RegistryAuth, insecure bool, actions ...string) (distribution.Repository, error) {
+	host := registryHost(image.Registry)
+	name := image.Repository
+	if host == dockerHubRegistry && !strings.Contains(name, "/") {
+		name = fmt.Sprintf("library/%v", name)
+	}
+	named, err := parseNamed(name)
+	if err != nil {
+		return nil, trace.Wrap(err, "invalid named reference %q", name)
+	}
+	const connectTimeout = 30 * time.Second
+	const keepAlivePeriod = 30 * time.Second
+	const handshakeTimeout = 30 * time.Second
+	base := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		Dial: (&net.Dialer{
+			Timeout:   connectTimeout,
+			KeepAlive: keepAlivePeriod,
+		}).Dial,
+		TLSHandshakeTimeout: handshakeTimeout,
+		TLSClientConfig:     &tls.Config{InsecureSkipVerify: insecure},
+	}
+	endpoint := fmt.Sprintf("https://%v", host)
+	if isLoopback(host) {
+		// Like Docker, consider registries on the loopback interface insecure
+		endpoint = fmt.Sprintf("http://%v", host)
+	}
+	manager := challenge.NewSimpleManager()
+	resp, err := (&http.Client{Transport: base}).Get(endpoint + "/v2/")
+	if err != nil {
+		return nil, trace.ConnectionProblem(err, "failed to connect to registry %v", host)
+	}
+	resp.Body.Close()
+	if err := manager.AddResponse(resp); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	creds := &credentialStore{RegistryCredentials: registryAuth.Get(host)}
+	authorizer := auth.NewAuthorizer(manager,
+		auth.NewTokenHandler(base, creds, named.Name(), actions...),
+		auth.NewBasicHandler(creds))
+	repo, err := registryclient.NewRepository(ctx, named, endpoint,
+		transport.NewTransport(base, authorizer))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	log.WithField("registry", host).Debugf("Connected to repository %v.", named.Name())
+	return repo, nil
+}
+
+// credentialStore implements auth.CredentialStore with static credentials
+type credentialStore struct {
+	RegistryCredentials
+}
+
+// Basic returns the user name and password
+func (r *credentialStore) Basic(*url.URL) (string, string) {
+	return r.Username, r.Password
+}
+
+// RefreshToken returns the refresh token. Refresh tokens are not supported
+func (r *credentialStore) RefreshToken(*url.URL, string) string {
+	return ""
+}
+
+// SetRefreshToken is a no-op as refresh tokens are not supported
+func (r *credentialStore) SetRefreshToken(*url.URL, string, string) {}
+
+// registryHost returns the host of the registry with the specified address
+// as used in the Docker client configuration file or image references
+func registryHost(registry string) string {
+	if u, err := url.Parse(registry); err == nil && u.Host != "" {
+		registry = u.Host
+	}
+	switch registry {
+	case "", "docker.io", "index.docker.io", dockerHubRegistry:
+		return dockerHubRegistry
+	}
+	return registry
+}
+
+func isLoopback(host string) bool {
+	hostname, _ := utils.SplitHostPort(host, "")
+	if hostname == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(hostname)
+	return ip != nil && ip.IsLoopback()
+}
+
+// digestTag returns the tag to store the image
+// with the specified manifest digest under
+func digestTag(dgst digest.Digest) string {
+	return fmt.Sprintf("%v-%v", dgst.Algorithm(), dgst.Hex())
+}
+
+// dockerClientConfig is the subset of the Docker client configuration file
+// with the registry credentials
+type dockerClientConfig struct {
+	// Auths maps registry addresses to credentials
+	Auths map[string]dockerAuthEntry `json:"auths"`
+}
+
+// dockerAuthEntry defines the credentials for a single registry
+type dockerAuthEntry struct {
+	// Auth is the base64-encoded 'username:password' pair
+	Auth string `json:"auth,omitempty"`
+	// Username is the registry user name
+	Username string `json:"username,omitempty"`
+	// Password is the registry user password
+	Password string `json:"password,omitempty"`
+}
+
+const (
+	// dockerHubRegistry is the address of the Docker Hub registry
+	dockerHubRegistry = "registry-1.docker.io"
+	// dockerConfigFile is the name of the Docker client configuration file
+	dockerConfigFile = "config.json"
+)
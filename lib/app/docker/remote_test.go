@@ -0,0 +1,269 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/gravitational/gravity/lib/buildcache"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/context"
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/opencontainers/go-digest"
+	. "gopkg.in/check.v1"
+)
+
+type RemoteSuite struct {
+	registry *Registry
+	dir      string
+}
+
+var _ = Suite(&RemoteSuite{})
+
+func (s *RemoteSuite) SetUpTest(c *C) {
+	s.dir = c.MkDir()
+	var err error
+	s.registry, err = NewRegistry(BasicConfiguration("127.0.0.1:0", s.dir))
+	c.Assert(err, IsNil)
+	c.Assert(s.registry.Start(), IsNil)
+}
+
+func (s *RemoteSuite) TearDownTest(c *C) {
+	s.registry.Close()
+}
+
+func (s *RemoteSuite) TestPullsImages(c *C) {
+	image := newTestImage(c, s.dir, "org/app", "1.0.0")
+	pinned := newTestImage(c, s.dir, "org/pinned", "2.0.0")
+
+	dir := c.MkDir()
+	err := PullImages(context.Background(), PullRequest{
+		Images: []string{
+			fmt.Sprintf("%v/org/app:1.0.0", s.registry.Addr()),
+			fmt.Sprintf("%v/org/pinned@%v", s.registry.Addr(), pinned),
+		},
+		Dir: dir,
+	})
+	c.Assert(err, IsNil)
+
+	images, err := ListImages(context.Background(), dir)
+	c.Assert(err, IsNil)
+	c.Assert(images, DeepEquals, []LocalImage{
+		{TagSpec: TagSpec{Name: "org/app", Version: "1.0.0"}, Digest: image},
+		{TagSpec: TagSpec{Name: "org/pinned", Version: "sha256-" + pinned.Hex()}, Digest: pinned},
+	})
+}
+
+func (s *RemoteSuite) TestPullsImagesThroughCache(c *C) {
+	image := newTestImage(c, s.dir, "org/app", "1.0.0")
+	cache, err := buildcache.New(c.MkDir())
+	c.Assert(err, IsNil)
+
+	for i := 0; i < 2; i++ {
+		dir := c.MkDir()
+		err = PullImages(context.Background(), PullRequest{
+			Images: []string{fmt.Sprintf("%v/org/app:1.0.0", s.registry.Addr())},
+			Dir:    dir,
+			Cache:  cache,
+		})
+		c.Assert(err, IsNil)
+		images, err := ListImages(context.Background(), dir)
+		c.Assert(err, IsNil)
+		c.Assert(images, DeepEquals, []LocalImage{
+			{TagSpec: TagSpec{Name: "org/app", Version: "1.0.0"}, Digest: image},
+		})
+	}
+	for _, desc := range getTestManifest(c, s.dir, "org/app", "1.0.0").References() {
+		_, err := cache.Stat(context.Background(), desc.Digest)
+		c.Assert(err, IsNil)
+	}
+}
+
+func (s *RemoteSuite) TestPullsMultiArchImages(c *C) {
+	platforms := newTestMultiArchImage(c, s.dir, "org/multi", "1.0.0", "amd64", "arm64", "s390x")
+	image := fmt.Sprintf("%v/org/multi:1.0.0", s.registry.Addr())
+
+	dir := c.MkDir()
+	err := PullImages(context.Background(), PullRequest{
+		Images:        []string{image},
+		Dir:           dir,
+		Architectures: []string{"amd64", "arm64"},
+	})
+	c.Assert(err, IsNil)
+	list := getTestManifest(c, dir, "org/multi", "1.0.0")
+	manifestList, ok := list.(*manifestlist.DeserializedManifestList)
+	c.Assert(ok, Equals, true, Commentf("expected manifest list, got %T", list))
+	var digests []digest.Digest
+	for _, manifest := range manifestList.Manifests {
+		digests = append(digests, manifest.Digest)
+	}
+	c.Assert(digests, DeepEquals, []digest.Digest{platforms["amd64"], platforms["arm64"]})
+
+	dir = c.MkDir()
+	err = PullImages(context.Background(), PullRequest{
+		Images:        []string{image},
+		Dir:           dir,
+		Architectures: []string{"arm64"},
+	})
+	c.Assert(err, IsNil)
+	images, err := ListImages(context.Background(), dir)
+	c.Assert(err, IsNil)
+	c.Assert(images, DeepEquals, []LocalImage{
+		{TagSpec: TagSpec{Name: "org/multi", Version: "1.0.0"}, Digest: platforms["arm64"]},
+	})
+}
+
+func (s *RemoteSuite) TestSyncsMultiArchImages(c *C) {
+	dir := c.MkDir()
+	platforms := newTestMultiArchImage(c, dir, "org/multi", "1.0.0", "amd64", "arm64")
+
+	service, err := NewImageService(RegistryConnectionRequest{
+		RegistryAddress: s.registry.Addr(),
+		CertName:        "localhost",
+	})
+	c.Assert(err, IsNil)
+	_, err = service.Sync(context.Background(), dir, utils.DiscardPrinter)
+	c.Assert(err, IsNil)
+
+	list, ok := getTestManifest(c, s.dir, "org/multi", "1.0.0").(*manifestlist.DeserializedManifestList)
+	c.Assert(ok, Equals, true)
+	c.Assert(list.Manifests, HasLen, 2)
+	for _, manifest := range list.Manifests {
+		c.Assert(manifest.Digest, Equals, platforms[manifest.Platform.Architecture])
+	}
+}
+
+func (s *RemoteSuite) TestRefusesUnknownDigest(c *C) {
+	newTestImage(c, s.dir, "app", "1.0.0")
+
+	err := PullImages(context.Background(), PullRequest{
+		Images: []string{fmt.Sprintf("%v/app@%v", s.registry.Addr(), digest.FromString("another image"))},
+		Dir:    c.MkDir(),
+	})
+	c.Assert(err, NotNil)
+}
+
+func (s *RemoteSuite) TestPushesArtifacts(c *C) {
+	path := filepath.Join(c.MkDir(), "app.tar")
+	c.Assert(ioutil.WriteFile(path, []byte("cluster image"), 0644), IsNil)
+
+	dgst, err := PushArtifact(context.Background(), PushArtifactRequest{
+		Image:           fmt.Sprintf("%v/org/cluster:1.0.0", s.registry.Addr()),
+		Path:            path,
+		MediaType:       "application/vnd.example.layer.v1.tar",
+		Config:          []byte(`{"name":"cluster"}`),
+		ConfigMediaType: "application/vnd.example.config.v1+json",
+	})
+	c.Assert(err, IsNil)
+
+	images, err := ListImages(context.Background(), s.dir)
+	c.Assert(err, IsNil)
+	c.Assert(images, DeepEquals, []LocalImage{
+		{TagSpec: TagSpec{Name: "org/cluster", Version: "1.0.0"}, Digest: dgst},
+	})
+	store, err := openLocal(s.dir)
+	c.Assert(err, IsNil)
+	data, err := store.readBlob(digest.FromString("cluster image"))
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "cluster image")
+}
+
+func (s *RemoteSuite) TestReadsRegistryAuth(c *C) {
+	path := filepath.Join(c.MkDir(), "config.json")
+	c.Assert(ioutil.WriteFile(path, []byte(`{"auths": {
+  "https://index.docker.io/v1/": {"auth": "dXNlcjpzZWNyZXQ="},
+  "registry.example.com": {"username": "robot", "password": "token"}
+}}`), 0600), IsNil)
+
+	auth, err := ReadRegistryAuth(path)
+	c.Assert(err, IsNil)
+	c.Assert(auth.Get(""), DeepEquals, RegistryCredentials{Username: "user", Password: "secret"})
+	c.Assert(auth.Get("docker.io"), DeepEquals, RegistryCredentials{Username: "user", Password: "secret"})
+	c.Assert(auth.Get("registry.example.com"), DeepEquals, RegistryCredentials{Username: "robot", Password: "token"})
+	c.Assert(auth.Get("quay.io"), DeepEquals, RegistryCredentials{})
+}
+
+func (s *RemoteSuite) TestUnpinsImages(c *C) {
+	dgst := digest.FromString("image")
+	c.Assert(UnpinImage("quay.io/org/app@"+dgst.String()), Equals, "quay.io/org/app:sha256-"+dgst.Hex())
+	c.Assert(UnpinImage("quay.io/org/app:1.0.0"), Equals, "quay.io/org/app:1.0.0")
+	c.Assert(UnpinImage("app"), Equals, "app")
+}
+
+// newTestMultiArchImage creates a multi-platform image for the specified architectures
+// and returns the digests of the image manifests keyed by architecture
+func newTestMultiArchImage(c *C, dir, name, tag string, architectures ...string) map[string]digest.Digest {
+	ctx := context.Background()
+	store, err := openLocal(dir)
+	c.Assert(err, IsNil)
+	repo, err := store.Repository(ctx, name)
+	c.Assert(err, IsNil)
+	manifests, err := repo.Manifests(ctx)
+	c.Assert(err, IsNil)
+	blobs := repo.Blobs(ctx)
+	digests := make(map[string]digest.Digest)
+	var descriptors []manifestlist.ManifestDescriptor
+	for _, arch := range architectures {
+		layer, err := blobs.Put(ctx, schema2.MediaTypeLayer, []byte(name+arch))
+		c.Assert(err, IsNil)
+		config := []byte(fmt.Sprintf(`{"architecture":%q}`, arch))
+		builder := schema2.NewManifestBuilder(blobs, schema2.MediaTypeImageConfig, config)
+		c.Assert(builder.AppendReference(layer), IsNil)
+		manifest, err := builder.Build(ctx)
+		c.Assert(err, IsNil)
+		dgst, err := manifests.Put(ctx, manifest)
+		c.Assert(err, IsNil)
+		_, payload, err := manifest.Payload()
+		c.Assert(err, IsNil)
+		digests[arch] = dgst
+		descriptors = append(descriptors, manifestlist.ManifestDescriptor{
+			Descriptor: distribution.Descriptor{
+				MediaType: schema2.MediaTypeManifest,
+				Digest:    dgst,
+				Size:      int64(len(payload)),
+			},
+			Platform: manifestlist.PlatformSpec{OS: "linux", Architecture: arch},
+		})
+	}
+	list, err := manifestlist.FromDescriptors(descriptors)
+	c.Assert(err, IsNil)
+	dgst, err := manifests.Put(ctx, list)
+	c.Assert(err, IsNil)
+	desc := distribution.Descriptor{MediaType: manifestlist.MediaTypeManifestList, Digest: dgst}
+	c.Assert(repo.Tags(ctx).Tag(ctx, tag, desc), IsNil)
+	return digests
+}
+
+func getTestManifest(c *C, dir, name, tag string) distribution.Manifest {
+	ctx := context.Background()
+	store, err := openLocal(dir)
+	c.Assert(err, IsNil)
+	repo, err := store.Repository(ctx, name)
+	c.Assert(err, IsNil)
+	desc, err := repo.Tags(ctx).Get(ctx, tag)
+	c.Assert(err, IsNil)
+	manifests, err := repo.Manifests(ctx)
+	c.Assert(err, IsNil)
+	manifest, err := manifests.Get(ctx, desc.Digest)
+	c.Assert(err, IsNil)
+	return manifest
+}
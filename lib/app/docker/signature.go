@@ -0,0 +1,279 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/docker/distribution/context"
+	"github.com/gravitational/trace"
+	"github.com/opencontainers/go-digest"
+	log "github.com/sirupsen/logrus"
+)
+
+// VerifyImages verifies the signatures of all images in the local registry
+// directory dir against the specified trust policy.
+//
+// Signatures are expected in the cosign format: for every image, the signature
+// is stored in the same repository with the tag derived from the image manifest
+// digest ("sha256-<hex>.sig").
+//
+// In enforcing mode, an error is returned if any image does not have a valid
+// signature from one of the trusted keys. In permissive mode, such images
+// are only reported
+func VerifyImages(ctx context.Context, dir string, policy storage.ImageTrustPolicy, logger log.FieldLogger) error {
+	if policy == nil || policy.GetMode() == storage.ImageTrustModeDisabled {
+		return nil
+	}
+	keys, err := parsePublicKeys(policy.GetPublicKeys())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	localStore, err := openLocal(dir)
+	if err != nil {
+		return trace.Wrap(err, "failed to open local directory %q as local registry", dir)
+	}
+	repos, err := ListRepos(ctx, localStore)
+	if err != nil {
+		return trace.Wrap(err, "failed to list local repositories in %q", dir)
+	}
+	var untrusted []string
+	for _, repoName := range repos {
+		repo, err := localStore.Repository(ctx, repoName)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		tagService := repo.Tags(ctx)
+		tags, err := tagService.All(ctx)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		for _, tag := range tags {
+			if isSignatureTag(tag) {
+				continue
+			}
+			image := TagSpec{Name: repoName, Version: tag}
+			desc, err := tagService.Get(ctx, tag)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			sigDesc, err := tagService.Get(ctx, signatureTag(desc.Digest))
+			if err != nil {
+				logger.WithField("image", image).Warn("Image is not signed.")
+				untrusted = append(untrusted, image.String())
+				continue
+			}
+			manifest, err := localStore.readBlob(sigDesc.Digest)
+			if err != nil {
+				return trace.Wrap(err, "failed to read signature of %v", image)
+			}
+			err = verifySignature(localStore, manifest, desc.Digest, keys)
+			if err != nil {
+				logger.WithError(err).WithField("image", image).Warn("Image signature is not trusted.")
+				untrusted = append(untrusted, image.String())
+				continue
+			}
+			logger.WithField("image", image).Debug("Verified image signature.")
+		}
+	}
+	if len(untrusted) == 0 {
+		return nil
+	}
+	if policy.GetMode() == storage.ImageTrustModeEnforcing {
+		return trace.AccessDenied("the following images do not have a valid signature "+
+			"from a trusted key: %v", strings.Join(untrusted, ", "))
+	}
+	logger.Warnf("The following images do not have a valid signature from a trusted key: %v.",
+		strings.Join(untrusted, ", "))
+	return nil
+}
+
+// verifySignature verifies the signature manifest of the image with the specified digest.
+// The image is trusted if any of the signatures in the manifest is valid for any of the keys
+func verifySignature(store *localStore, manifestBytes []byte, imageDigest digest.Digest, keys []crypto.PublicKey) error {
+	var manifest signatureManifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return trace.BadParameter("invalid signature manifest: %v", err)
+	}
+	for _, layer := range manifest.Layers {
+		signature, ok := layer.Annotations[cosignSignatureAnnotation]
+		if !ok {
+			continue
+		}
+		payload, err := store.readBlob(layer.Digest)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if err := verifyPayload(payload, signature, imageDigest, keys); err != nil {
+			log.WithError(err).Debug("Failed to verify signature.")
+			continue
+		}
+		return nil
+	}
+	return trace.NotFound("no valid signature from a trusted key")
+}
+
+// verifyPayload verifies the signature of the simple signing payload
+// and that the payload refers to the image with the specified digest
+func verifyPayload(payload []byte, signature string, imageDigest digest.Digest, keys []crypto.PublicKey) error {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return trace.BadParameter("invalid signature encoding: %v", err)
+	}
+	hash := sha256.Sum256(payload)
+	if !verifyWithAnyKey(hash[:], sig, keys) {
+		return trace.BadParameter("signature does not match any of the trusted keys")
+	}
+	var simpleSigning simpleSigningPayload
+	if err := json.Unmarshal(payload, &simpleSigning); err != nil {
+		return trace.BadParameter("invalid signature payload: %v", err)
+	}
+	signedDigest := simpleSigning.Critical.Image.DockerManifestDigest
+	if signedDigest != imageDigest.String() {
+		return trace.BadParameter("signature is for image %v, not %v", signedDigest, imageDigest)
+	}
+	return nil
+}
+
+func verifyWithAnyKey(hash, sig []byte, keys []crypto.PublicKey) bool {
+	for _, key := range keys {
+		switch key := key.(type) {
+		case *ecdsa.PublicKey:
+			var ecdsaSig struct {
+				R, S *big.Int
+			}
+			if _, err := asn1.Unmarshal(sig, &ecdsaSig); err != nil {
+				continue
+			}
+			if ecdsa.Verify(key, hash, ecdsaSig.R, ecdsaSig.S) {
+				return true
+			}
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(key, crypto.SHA256, hash, sig) == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func parsePublicKeys(keys []string) (result []crypto.PublicKey, err error) {
+	for _, data := range keys {
+		key, err := storage.ParsePublicKeyPEM([]byte(data))
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		switch key.(type) {
+		case *ecdsa.PublicKey, *rsa.PublicKey:
+		default:
+			return nil, trace.BadParameter("unsupported public key type %T, "+
+				"only ECDSA and RSA keys are supported", key)
+		}
+		result = append(result, key)
+	}
+	return result, nil
+}
+
+// readBlob returns the contents of the blob with the specified digest.
+// The blob is read from the registry directory directly since the signature
+// manifests are not supported by the registry manifest service
+func (l *localStore) readBlob(dgst digest.Digest) ([]byte, error) {
+	if err := dgst.Validate(); err != nil {
+		return nil, trace.BadParameter("invalid digest %q: %v", dgst, err)
+	}
+	path := filepath.Join(l.dir, "docker", "registry", "v2", "blobs",
+		dgst.Algorithm().String(), dgst.Hex()[:2], dgst.Hex(), "data")
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	if dgst.Algorithm().FromBytes(data) != dgst {
+		return nil, trace.BadParameter("blob %v is corrupted", dgst)
+	}
+	return data, nil
+}
+
+// signatureTag returns the tag of the cosign signature
+// for the image with the specified manifest digest
+func signatureTag(dgst digest.Digest) string {
+	return fmt.Sprintf("%v-%v%v", dgst.Algorithm(), dgst.Hex(), cosignSignatureSuffix)
+}
+
+// isSignatureTag returns true if the specified tag refers to
+// a cosign signature, attestation or SBOM instead of an image
+func isSignatureTag(tag string) bool {
+	if !strings.HasPrefix(tag, string(digest.SHA256)+"-") {
+		return false
+	}
+	for _, suffix := range []string{cosignSignatureSuffix, ".att", ".sbom"} {
+		if strings.HasSuffix(tag, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// signatureManifest is the subset of the cosign signature manifest
+// required to verify the signatures
+type signatureManifest struct {
+	// Layers lists the signature payloads
+	Layers []signatureLayer `json:"layers"`
+}
+
+// signatureLayer describes a single signature payload
+type signatureLayer struct {
+	// MediaType is the payload media type
+	MediaType string `json:"mediaType"`
+	// Digest is the payload digest
+	Digest digest.Digest `json:"digest"`
+	// Annotations contains the signature of the payload
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// simpleSigningPayload is the signed payload in the simple signing format
+type simpleSigningPayload struct {
+	// Critical is the signed image identity
+	Critical struct {
+		// Image identifies the signed image manifest
+		Image struct {
+			// DockerManifestDigest is the digest of the signed image manifest
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		// Type is the payload type
+		Type string `json:"type"`
+	} `json:"critical"`
+}
+
+const (
+	// cosignSignatureAnnotation is the layer annotation with the payload signature
+	cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+	// cosignSignatureSuffix is the suffix of cosign signature tags
+	cosignSignatureSuffix = ".sig"
+)
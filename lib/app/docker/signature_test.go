@@ -0,0 +1,231 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docker
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/context"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/gravitational/trace"
+	"github.com/opencontainers/go-digest"
+	log "github.com/sirupsen/logrus"
+	. "gopkg.in/check.v1"
+)
+
+type SignatureSuite struct{}
+
+var _ = Suite(&SignatureSuite{})
+
+func (s *SignatureSuite) TestVerifiesImages(c *C) {
+	dir := c.MkDir()
+	key := newSigningKey(c)
+	otherKey := newSigningKey(c)
+	signed := newTestImage(c, dir, "signed", "1.0.0")
+	signImage(c, dir, "signed", signed, key)
+
+	var testCases = []struct {
+		comment string
+		mode    string
+		key     *ecdsa.PrivateKey
+		trusted bool
+	}{
+		{
+			comment: "signed with trusted key",
+			mode:    storage.ImageTrustModeEnforcing,
+			key:     key,
+			trusted: true,
+		},
+		{
+			comment: "signed with untrusted key",
+			mode:    storage.ImageTrustModeEnforcing,
+			key:     otherKey,
+		},
+		{
+			comment: "untrusted key in permissive mode",
+			mode:    storage.ImageTrustModePermissive,
+			key:     otherKey,
+			trusted: true,
+		},
+		{
+			comment: "untrusted key with disabled verification",
+			mode:    storage.ImageTrustModeDisabled,
+			key:     otherKey,
+			trusted: true,
+		},
+	}
+	for _, tc := range testCases {
+		comment := Commentf(tc.comment)
+		err := VerifyImages(context.Background(), dir, newTrustPolicy(c, tc.mode, tc.key), log.StandardLogger())
+		if tc.trusted {
+			c.Assert(err, IsNil, comment)
+		} else {
+			c.Assert(trace.IsAccessDenied(err), Equals, true, comment)
+		}
+	}
+}
+
+func (s *SignatureSuite) TestRefusesUnsignedImages(c *C) {
+	dir := c.MkDir()
+	key := newSigningKey(c)
+	signed := newTestImage(c, dir, "signed", "1.0.0")
+	signImage(c, dir, "signed", signed, key)
+	newTestImage(c, dir, "unsigned", "1.0.0")
+
+	err := VerifyImages(context.Background(), dir,
+		newTrustPolicy(c, storage.ImageTrustModeEnforcing, key), log.StandardLogger())
+	c.Assert(trace.IsAccessDenied(err), Equals, true)
+	c.Assert(err, ErrorMatches, ".*unsigned:1.0.0.*")
+}
+
+func (s *SignatureSuite) TestRefusesSignatureOfAnotherImage(c *C) {
+	dir := c.MkDir()
+	key := newSigningKey(c)
+	image := newTestImage(c, dir, "app", "1.0.0")
+	// The signature is valid but the payload refers to a different image
+	signImageWithPayload(c, dir, "app", image, key, digest.FromString("another image"))
+
+	err := VerifyImages(context.Background(), dir,
+		newTrustPolicy(c, storage.ImageTrustModeEnforcing, key), log.StandardLogger())
+	c.Assert(trace.IsAccessDenied(err), Equals, true)
+}
+
+func (s *SignatureSuite) TestListsImagesWithoutSignatures(c *C) {
+	dir := c.MkDir()
+	image := newTestImage(c, dir, "app", "1.0.0")
+	signImage(c, dir, "app", image, newSigningKey(c))
+
+	images, err := ListImages(context.Background(), dir)
+	c.Assert(err, IsNil)
+	c.Assert(images, DeepEquals, []LocalImage{{
+		TagSpec: TagSpec{Name: "app", Version: "1.0.0"},
+		Digest:  image,
+	}})
+}
+
+func (s *SignatureSuite) TestDetectsSignatureTags(c *C) {
+	dgst := digest.FromString("image")
+	c.Assert(isSignatureTag(signatureTag(dgst)), Equals, true)
+	c.Assert(isSignatureTag(fmt.Sprintf("sha256-%v.att", dgst.Hex())), Equals, true)
+	c.Assert(isSignatureTag("1.0.0"), Equals, false)
+	c.Assert(isSignatureTag("sha256-latest"), Equals, false)
+}
+
+// newTestImage creates an image in the local registry in dir
+// and returns its manifest digest
+func newTestImage(c *C, dir, name, tag string) digest.Digest {
+	ctx := context.Background()
+	store, err := openLocal(dir)
+	c.Assert(err, IsNil)
+	repo, err := store.Repository(ctx, name)
+	c.Assert(err, IsNil)
+	blobs := repo.Blobs(ctx)
+	layer, err := blobs.Put(ctx, schema2.MediaTypeLayer, []byte(name))
+	c.Assert(err, IsNil)
+	builder := schema2.NewManifestBuilder(blobs, schema2.MediaTypeImageConfig, []byte(`{"architecture":"amd64"}`))
+	c.Assert(builder.AppendReference(layer), IsNil)
+	manifest, err := builder.Build(ctx)
+	c.Assert(err, IsNil)
+	manifests, err := repo.Manifests(ctx)
+	c.Assert(err, IsNil)
+	dgst, err := manifests.Put(ctx, manifest)
+	c.Assert(err, IsNil)
+	desc := distribution.Descriptor{MediaType: schema2.MediaTypeManifest, Digest: dgst}
+	c.Assert(repo.Tags(ctx).Tag(ctx, tag, desc), IsNil)
+	return dgst
+}
+
+func signImage(c *C, dir, name string, image digest.Digest, key *ecdsa.PrivateKey) {
+	signImageWithPayload(c, dir, name, image, key, image)
+}
+
+// signImageWithPayload stores the cosign signature for the specified image
+// with the payload referring to the signed digest
+func signImageWithPayload(c *C, dir, name string, image digest.Digest, key *ecdsa.PrivateKey, signed digest.Digest) {
+	ctx := context.Background()
+	store, err := openLocal(dir)
+	c.Assert(err, IsNil)
+	repo, err := store.Repository(ctx, name)
+	c.Assert(err, IsNil)
+	blobs := repo.Blobs(ctx)
+
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":%q},`+
+		`"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`,
+		name, signed))
+	hash := sha256.Sum256(payload)
+	r, ss, err := ecdsa.Sign(rand.Reader, key, hash[:])
+	c.Assert(err, IsNil)
+	sig, err := asn1.Marshal(struct{ R, S *big.Int }{r, ss})
+	c.Assert(err, IsNil)
+	payloadDesc, err := blobs.Put(ctx, simpleSigningMediaType, payload)
+	c.Assert(err, IsNil)
+	config, err := blobs.Put(ctx, "application/vnd.oci.image.config.v1+json", []byte(`{}`))
+	c.Assert(err, IsNil)
+
+	manifest, err := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     ociManifestMediaType,
+		"config":        config,
+		"layers": []map[string]interface{}{{
+			"mediaType": simpleSigningMediaType,
+			"size":      payloadDesc.Size,
+			"digest":    payloadDesc.Digest,
+			"annotations": map[string]string{
+				cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(sig),
+			},
+		}},
+	})
+	c.Assert(err, IsNil)
+	manifestDesc, err := blobs.Put(ctx, ociManifestMediaType, manifest)
+	c.Assert(err, IsNil)
+	c.Assert(repo.Tags(ctx).Tag(ctx, signatureTag(image), manifestDesc), IsNil)
+}
+
+func newSigningKey(c *C) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+	return key
+}
+
+func newTrustPolicy(c *C, mode string, key *ecdsa.PrivateKey) storage.ImageTrustPolicy {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	c.Assert(err, IsNil)
+	policy := storage.NewImageTrustPolicy(storage.ImageTrustPolicySpecV2{
+		Mode:       mode,
+		PublicKeys: []string{string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))},
+	})
+	c.Assert(policy.CheckAndSetDefaults(), IsNil)
+	return policy
+}
+
+const (
+	simpleSigningMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+	ociManifestMediaType   = "application/vnd.oci.image.manifest.v1+json"
+)
@@ -203,6 +203,13 @@ func (r *ResourceFiles) RewriteImages(rewriteFunc func(string) string) error {
 						}
 					}
 				}
+				for i, test := range resource.SmokeTests {
+					hook := test.GetHook()
+					if err := rewriteInHook(hook); err != nil {
+						return trace.Wrap(err)
+					}
+					resource.SmokeTests[i].Job = hook.Job
+				}
 			case *corev1.Pod:
 				log.Infof("Rewriting images in Pod %q.", resource.Name)
 				rewrite(&resource.Spec)
@@ -312,6 +319,17 @@ func extractImages(objects []runtime.Object) (*ExtractedImages, error) {
 					containers = append(containers, job.Spec.Template.Spec.InitContainers...)
 				}
 			}
+			for _, test := range resource.SmokeTests {
+				job, err := test.GetHook().GetJob()
+				if err != nil && !trace.IsNotFound(err) {
+					return nil, trace.Wrap(err)
+				}
+				if job == nil {
+					continue
+				}
+				containers = append(containers, job.Spec.Template.Spec.Containers...)
+				containers = append(containers, job.Spec.Template.Spec.InitContainers...)
+			}
 		case *corev1.Pod:
 			containers = append(resource.Spec.Containers, resource.Spec.InitContainers...)
 		case *corev1.ReplicationController:
@@ -218,7 +218,7 @@ func (r *applications) withApp(locator loc.Locator, process processAppFn) (*apps
 	return app, nil
 }
 
-func syncWithRegistry(ctx context.Context, registryDir string, imageService docker.ImageService, log log.FieldLogger) error {
+func syncWithRegistry(ctx context.Context, registryDir string, imageService docker.ImageService, policy storage.ImageTrustPolicy, log log.FieldLogger) error {
 	if ok, _ := utils.IsDirectory(registryDir); !ok {
 		log.Infof("No registry directory is present - skipping registry sync.")
 		return nil
@@ -230,6 +230,9 @@ func syncWithRegistry(ctx context.Context, registryDir string, imageService dock
 	if empty {
 		return trace.BadParameter("registry directory %v is empty", registryDir)
 	}
+	if err := docker.VerifyImages(ctx, registryDir, policy, log); err != nil {
+		return trace.Wrap(err)
+	}
 	if _, err = imageService.Sync(ctx, registryDir, utils.DiscardPrinter); err != nil {
 		return trace.Wrap(err)
 	}
@@ -255,8 +258,21 @@ func (r *applications) runAppHook(ctx context.Context, req appservice.HookRunReq
 }
 
 func (r *applications) exportApp(ctx context.Context, dir string, imageService docker.ImageService) error {
+	policy, err := r.getImageTrustPolicy()
+	if err != nil {
+		return trace.Wrap(err)
+	}
 	dir = filepath.Join(dir, defaults.RegistryDir)
-	return syncWithRegistry(ctx, dir, imageService, r.FieldLogger)
+	return syncWithRegistry(ctx, dir, imageService, policy, r.FieldLogger)
+}
+
+// getImageTrustPolicy returns the image trust policy or nil if none has been configured
+func (r *applications) getImageTrustPolicy() (storage.ImageTrustPolicy, error) {
+	policy, err := r.Backend.GetImageTrustPolicy()
+	if err != nil && !trace.IsNotFound(err) {
+		return nil, trace.Wrap(err)
+	}
+	return policy, nil
 }
 
 // uninstallApp calls "pre-uninstall" and "uninstall" hooks for the specified app
@@ -23,6 +23,7 @@ import (
 	"strings"
 
 	"github.com/gravitational/gravity/lib/app/docker"
+	"github.com/gravitational/gravity/lib/buildcache"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/loc"
 	"github.com/gravitational/gravity/lib/run"
@@ -34,10 +35,12 @@ import (
 )
 
 // exportLayers exports the layers of the specified set of images into
-// the specified local directory
+// the specified local directory.
+// With the build cache specified, the images are restored from the cache
+// and the exported images are stored in the cache
 func exportLayers(ctx context.Context, dir string, images []string, dockerClient docker.DockerInterface, log log.FieldLogger,
-	parallel int, progress utils.Progress) error {
-	layerExporter, err := newLayerExporter(dir, dockerClient, log, progress)
+	parallel int, progress utils.Progress, cache *buildcache.Cache) error {
+	layerExporter, err := newLayerExporter(dir, dockerClient, log, progress, cache)
 	if err != nil {
 		return trace.Wrap(err, "failed to create layer export")
 	}
@@ -55,7 +58,7 @@ func exportLayers(ctx context.Context, dir string, images []string, dockerClient
 }
 
 // newLayerExporter creates an instance of layer exporter
-func newLayerExporter(exportDir string, client docker.DockerInterface, log log.FieldLogger, progress utils.Progress) (*layerExporter, error) {
+func newLayerExporter(exportDir string, client docker.DockerInterface, log log.FieldLogger, progress utils.Progress, cache *buildcache.Cache) (*layerExporter, error) {
 	outputDir := filepath.Join(exportDir, defaults.RegistryDir)
 	config := docker.BasicConfiguration("127.0.0.1:0", outputDir)
 	registry, err := docker.NewRegistry(config)
@@ -69,7 +72,9 @@ func newLayerExporter(exportDir string, client docker.DockerInterface, log log.F
 		FieldLogger:      log,
 		dockerClient:     client,
 		registry:         registry,
+		registryDir:      outputDir,
 		progressReporter: progress,
+		cache:            cache,
 	}, nil
 }
 
@@ -80,14 +85,17 @@ type layerExporter struct {
 	log.FieldLogger
 	dockerClient     docker.DockerInterface
 	registry         *docker.Registry
+	registryDir      string
 	progressReporter utils.Progress
+	// cache is the optional build cache the exported images are cached in
+	cache *buildcache.Cache
 }
 
 // push pushes the list of specified images into the temporary local registry
 func (r *layerExporter) push(ctx context.Context, images []string, parallel int) error {
 	group, ctx := run.WithContext(ctx, run.WithParallel(parallel))
 	for _, image := range images {
-		group.Go(ctx, r.pushImage(image))
+		group.Go(ctx, r.pushImage(ctx, image))
 	}
 	if err := group.Wait(); err != nil {
 		return trace.Wrap(err)
@@ -100,12 +108,27 @@ func (r *layerExporter) stop() error {
 	return r.registry.Close()
 }
 
-func (r *layerExporter) pushImage(image string) func() error {
+func (r *layerExporter) pushImage(ctx context.Context, image string) func() error {
 	return func() error {
 		parsed, err := loc.ParseDockerImage(image)
 		if err != nil {
 			return trace.Wrap(err)
 		}
+		var cacheKey string
+		if r.cache != nil {
+			cacheKey, err = r.cacheKey(image)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			err = docker.RestoreImage(ctx, r.cache, r.registryDir, parsed.Repository, imageTag(parsed.Tag), cacheKey)
+			if err == nil {
+				r.progressReporter.PrintSubStep("Vendored image %v from cache", image)
+				return nil
+			}
+			if !trace.IsNotFound(err) {
+				r.Warnf("Failed to restore %v from cache: %v.", image, err)
+			}
+		}
 		if err = r.tagCmd(image, parsed.Repository, parsed.Tag); err != nil {
 			return trace.Wrap(err)
 		}
@@ -117,10 +140,27 @@ func (r *layerExporter) pushImage(image string) func() error {
 		if err = r.removeTagCmd(parsed.Repository, parsed.Tag); err != nil {
 			r.Warnf("Failed to remove %v.", image)
 		}
+		if r.cache != nil {
+			err = docker.CacheImage(ctx, r.cache, r.registryDir, parsed.Repository, imageTag(parsed.Tag), cacheKey)
+			if err != nil {
+				r.Warnf("Failed to cache %v: %v.", image, err)
+			}
+		}
 		return nil
 	}
 }
 
+// cacheKey returns the build cache key for the specified image.
+// The key is based on the image ID so an image is exported again
+// whenever its contents change
+func (r *layerExporter) cacheKey(image string) (string, error) {
+	info, err := r.dockerClient.InspectImage(image)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return fmt.Sprintf("docker-image/%v", info.ID), nil
+}
+
 func (r *layerExporter) tagCmd(image, repository, tag string) error {
 	opts := dockerapi.TagImageOptions{
 		Repo:  fmt.Sprintf("%v/%v", r.registry.Addr(), repository),
@@ -141,14 +181,19 @@ func (r *layerExporter) pushCmd(name, tag string) error {
 }
 
 func (r *layerExporter) removeTagCmd(name, tag string) error {
-	if tag == "" {
-		tag = "latest"
-	}
-	localImage := fmt.Sprintf("%v/%v:%v", r.registry.Addr(), name, tag)
+	localImage := fmt.Sprintf("%v/%v:%v", r.registry.Addr(), name, imageTag(tag))
 	r.Infof("Removing %v.", localImage)
 	return r.dockerClient.RemoveImage(localImage)
 }
 
+// imageTag returns the specified image tag or the default tag if it is empty
+func imageTag(tag string) string {
+	if tag == "" {
+		return "latest"
+	}
+	return tag
+}
+
 // parseImageNameTag parses the specified image reference into name/tag tuple.
 // The returned name will include domain/path parts merged in a way to conform
 // to telekube package name syntax.
@@ -17,13 +17,17 @@ limitations under the License.
 package service
 
 import (
+	"context"
 	"path/filepath"
 	"time"
 
 	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/app/docker"
+	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/loc"
 	"github.com/gravitational/gravity/lib/schema"
 	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/utils"
 	"github.com/gravitational/trace"
 
 	"github.com/docker/docker/pkg/archive"
@@ -57,6 +61,10 @@ func (r *applications) importAppWithContext(ctx *operationContext, request *app.
 		return trace.Wrap(err)
 	}
 
+	if err := r.verifyImages(context.TODO(), unpackedDir); err != nil {
+		return trace.Wrap(err)
+	}
+
 	archiveOptions := &archive.TarOptions{
 		Compression:     archive.Gzip,
 		ExcludePatterns: request.ExcludePatterns,
@@ -85,6 +93,20 @@ func (r *applications) importAppWithContext(ctx *operationContext, request *app.
 	return trace.Wrap(err)
 }
 
+// verifyImages verifies the images of the application unpacked in dir
+// against the image trust policy
+func (r *applications) verifyImages(ctx context.Context, dir string) error {
+	registryDir := filepath.Join(dir, defaults.RegistryDir)
+	if ok, _ := utils.IsDirectory(registryDir); !ok {
+		return nil
+	}
+	policy, err := r.getImageTrustPolicy()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(docker.VerifyImages(ctx, registryDir, policy, r.FieldLogger))
+}
+
 // importOperation implements operation interface
 type importOperation struct {
 	op        *storage.AppOperation
@@ -135,7 +157,7 @@ func applicationType(manifest *schema.Manifest) (storage.AppType, error) {
 		return storage.AppService, nil
 	case schema.KindRuntime:
 		return storage.AppRuntime, nil
-	case schema.KindBundle, schema.KindCluster, schema.KindApplication:
+	case schema.KindBundle, schema.KindCluster, schema.KindPlatform, schema.KindApplication:
 		return storage.AppUser, nil
 	}
 	return "", trace.BadParameter("unknown application type: %v", manifest.Kind)
@@ -42,6 +42,8 @@ import (
 	"github.com/ghodss/yaml"
 	"github.com/gravitational/license/authority"
 	"github.com/gravitational/trace"
+	"github.com/mailgun/timetools"
+	"github.com/pborman/uuid"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -50,7 +52,7 @@ func (r *applications) getApplicationInstaller(
 	app *appservice.Application,
 	apps *applications,
 ) ([]*archive.Item, error) {
-	err := pullApplications([]loc.Locator{app.Package}, apps, r, r)
+	err := pullApplications([]loc.Locator{app.Package}, apps, r, nil, r)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -62,7 +64,7 @@ func (r *applications) getClusterInstaller(
 	app *appservice.Application,
 	apps *applications,
 ) ([]*archive.Item, error) {
-	err := pullDependencies(app, apps, r, r)
+	err := pullDependencies(req, app, apps, r, r)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -123,12 +125,16 @@ func (r *applications) GetAppInstaller(req appservice.InstallerRequest) (install
 		return nil, trace.Wrap(err)
 	}
 
-	var localPackages pack.PackageService
-	localPackages, err = localpack.New(localpack.Config{
+	packagesConfig := localpack.Config{
 		Backend:     localBackend,
 		UnpackedDir: filepath.Join(tempDir, defaults.PackagesDir, defaults.UnpackedDir),
 		Objects:     objects,
-	})
+	}
+	if !req.Timestamp.IsZero() {
+		packagesConfig.Clock = &timetools.FreezedTime{CurrentTime: req.Timestamp.UTC()}
+	}
+	var localPackages pack.PackageService
+	localPackages, err = localpack.New(packagesConfig)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -146,7 +152,13 @@ func (r *applications) GetAppInstaller(req appservice.InstallerRequest) (install
 		return nil, trace.Wrap(err)
 	}
 
-	_, err = localBackend.CreateAccount(req.Account)
+	account := req.Account
+	if account.ID == "" && !req.Timestamp.IsZero() {
+		// derive the account ID from the application instead of
+		// generating a random one so the installer is reproducible
+		account.ID = uuid.NewSHA1(uuid.NameSpace_OID, []byte(req.Application.String())).String()
+	}
+	_, err = localBackend.CreateAccount(account)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -164,7 +176,7 @@ func (r *applications) GetAppInstaller(req appservice.InstallerRequest) (install
 
 	var items []*archive.Item
 	switch app.Manifest.Kind {
-	case schema.KindBundle, schema.KindCluster:
+	case schema.KindBundle, schema.KindCluster, schema.KindPlatform:
 		items, err = r.getClusterInstaller(req, app, localApps)
 	case schema.KindApplication:
 		items, err = r.getApplicationInstaller(req, app, localApps)
@@ -186,7 +198,13 @@ func (r *applications) GetAppInstaller(req appservice.InstallerRequest) (install
 			}
 			return
 		}
-		err = archive.CompressDirectory(tempDir, writer, append(items,
+		compress := archive.CompressDirectory
+		if !req.Timestamp.IsZero() {
+			compress = func(dir string, writer io.Writer, items ...*archive.Item) error {
+				return archive.CompressDirectoryReproducible(dir, writer, req.Timestamp, items...)
+			}
+		}
+		err = compress(tempDir, writer, append(items,
 			archive.ItemFromStringMode(
 				defaults.ManifestFileName, string(manifestBytes), defaults.SharedReadMask),
 			archive.ItemFromStringMode(
@@ -236,25 +254,83 @@ func (r *applications) getGravityBinaryForApp(app *appservice.Application) (*arc
 	return archive.ItemFromStream("gravity", packageBytes, envelope.SizeBytes, defaults.SharedExecutableMask), nil
 }
 
-// pullDependencies transitively pulls all dependent packages for app to localApps
-func pullDependencies(app *appservice.Application, localApps, remoteApps *applications, log log.FieldLogger) error {
+// pullDependencies transitively pulls all dependent packages for app to localApps.
+// Packages excluded by the request are not pulled
+func pullDependencies(req appservice.InstallerRequest, app *appservice.Application, localApps, remoteApps *applications, log log.FieldLogger) error {
 	dependencies, err := appservice.GetDependencies(app, remoteApps)
 	if err != nil {
 		return trace.Wrap(err)
 	}
 
-	if err = pullPackages(dependencies.Packages, localApps.Packages, remoteApps.Packages, log); err != nil {
+	excludes := req.ExcludePackages
+	if req.ExcludeBaseImage {
+		inherited, err := basePackages(app, remoteApps)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		excludes = append(excludes, inherited...)
+	}
+
+	packages := excludePackages(dependencies.Packages, excludes)
+	if err = pullPackages(packages, localApps.Packages, remoteApps.Packages, log); err != nil {
+		return trace.Wrap(err)
+	}
+
+	apps := excludePackages(dependencies.Apps, excludes)
+	if err = pullApplications(apps, localApps, remoteApps, nil, log); err != nil {
 		return trace.Wrap(err)
 	}
 
-	apps := dependencies.Apps
-	apps = append(apps, app.Package)
-	if err = pullApplications(apps, localApps, remoteApps, log); err != nil {
+	// mark the delta installer so it can only be applied to the cluster
+	// running the base image
+	var labels map[string]string
+	if req.BaseImage != nil {
+		labels = map[string]string{pack.DeltaBaseLabel: req.BaseImage.String()}
+	}
+	if req.ExcludeBaseImage {
+		// the manifest of a layered cluster image can only be resolved
+		// once the base cluster image is available at install time
+		// so the application package is copied as-is
+		return trace.Wrap(pullApplicationPackage(app.Package, localApps, remoteApps, labels, log))
+	}
+	if err = pullApplications([]loc.Locator{app.Package}, localApps, remoteApps, labels, log); err != nil {
 		return trace.Wrap(err)
 	}
 	return nil
 }
 
+// basePackages returns the packages and applications the specified layered
+// cluster image inherits from its base cluster image, including the base image itself
+func basePackages(app *appservice.Application, apps *applications) ([]loc.Locator, error) {
+	base := app.Manifest.Base()
+	if base == nil {
+		return nil, trace.BadParameter("application %v does not have a base image", app.Package)
+	}
+	baseApp, err := apps.GetApp(*base)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	dependencies, err := appservice.GetDependencies(baseApp, apps)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return append(append(dependencies.Packages, dependencies.Apps...), baseApp.Package), nil
+}
+
+// excludePackages returns the list of locators without the excluded ones
+func excludePackages(locators, excludes []loc.Locator) (result []loc.Locator) {
+	excluded := make(map[loc.Locator]struct{}, len(excludes))
+	for _, locator := range excludes {
+		excluded[locator] = struct{}{}
+	}
+	for _, locator := range locators {
+		if _, ok := excluded[locator]; !ok {
+			result = append(result, locator)
+		}
+	}
+	return result
+}
+
 // pullPackages pulls package locators from remotePackages to localPackages
 func pullPackages(locators []loc.Locator, localPackages pack.PackageService, remotePackages pack.PackageService, log log.FieldLogger) error {
 	log.Infof("Pulling packages %v.", locators)
@@ -279,7 +355,8 @@ func pullPackages(locators []loc.Locator, localPackages pack.PackageService, rem
 }
 
 // pullApplications pulls applications specified with locators from remoteApps to localApps
-func pullApplications(locators []loc.Locator, localApps *applications, remoteApps *applications, log log.FieldLogger) error {
+// and assigns them the optional labels
+func pullApplications(locators []loc.Locator, localApps *applications, remoteApps *applications, labels map[string]string, log log.FieldLogger) error {
 	log.Infof("Pulling applications %v.", locators)
 
 	for _, locator := range locators {
@@ -289,7 +366,6 @@ func pullApplications(locators []loc.Locator, localApps *applications, remoteApp
 		}
 		defer reader.Close()
 
-		var labels map[string]string
 		_, err = localApps.CreateAppWithManifest(envelope.Locator, envelope.Manifest, reader, labels)
 		if err != nil && !trace.IsAlreadyExists(err) {
 			return trace.Wrap(err)
@@ -299,6 +375,29 @@ func pullApplications(locators []loc.Locator, localApps *applications, remoteApp
 	return nil
 }
 
+// pullApplicationPackage pulls the package of the application specified with locator
+// from remoteApps to localApps along with its metadata without resolving the application
+// manifest and assigns it the optional labels
+func pullApplicationPackage(locator loc.Locator, localApps *applications, remoteApps *applications, labels map[string]string, log log.FieldLogger) error {
+	log.Infof("Pulling application package %v.", locator)
+
+	envelope, reader, err := remoteApps.Packages.ReadPackage(locator)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer reader.Close()
+
+	err = localApps.Packages.UpsertRepository(locator.Repository, time.Time{})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = localApps.Packages.CreatePackage(envelope.Locator, reader,
+		pack.WithLabels(labels),
+		pack.WithManifest(envelope.Type, envelope.Manifest),
+		pack.WithHidden(envelope.Hidden))
+	return trace.Wrap(err)
+}
+
 // addCertificateAuthority makes the certificate authority package from the provided CA and key
 // and puts it alongside other installer packages
 func addCertificateAuthority(req appservice.InstallerRequest, destPackages pack.PackageService) error {
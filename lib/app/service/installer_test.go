@@ -0,0 +1,119 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/gravitational/gravity/lib/app"
+	apptest "github.com/gravitational/gravity/lib/app/service/test"
+	"github.com/gravitational/gravity/lib/archive"
+	"github.com/gravitational/gravity/lib/blob/fs"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/pack/localpack"
+	"github.com/gravitational/gravity/lib/storage/keyval"
+
+	. "gopkg.in/check.v1"
+)
+
+type InstallerSuite struct{}
+
+var _ = Suite(&InstallerSuite{})
+
+func (s *InstallerSuite) TestGeneratesReproducibleInstaller(c *C) {
+	_, packages, apps := setupServices(c)
+	apptest.CreatePackage(packages, loc.MustParseLocator("gravitational.io/planet:0.0.1"), nil, c)
+	apptest.CreateRuntimeApplication(apps, c)
+	apptest.CreatePackage(packages, loc.MustParseLocator("gravitational.io/gravity:0.0.1"), nil, c)
+	locator := loc.MustParseLocator("gravitational.io/app:0.0.1")
+	apptest.CreateDummyApplicationWithDependencies(apps, locator, `
+dependencies:
+  packages:
+  - gravitational.io/gravity:0.0.1`, c)
+
+	generate := func() []byte {
+		installer, err := apps.GetAppInstaller(app.InstallerRequest{
+			Application: locator,
+			Timestamp:   time.Unix(1500000000, 0),
+		})
+		c.Assert(err, IsNil)
+		defer installer.Close()
+		data, err := ioutil.ReadAll(installer)
+		c.Assert(err, IsNil)
+		return data
+	}
+	installer := generate()
+	// the timestamps of the generated files should not depend on the current time
+	time.Sleep(time.Second)
+	c.Assert(generate(), DeepEquals, installer)
+}
+
+func (s *InstallerSuite) TestGeneratesLayeredInstaller(c *C) {
+	_, packages, apps := setupServices(c)
+	apptest.CreatePackage(packages, loc.MustParseLocator("gravitational.io/planet:0.0.1"), nil, c)
+	apptest.CreateRuntimeApplication(apps, c)
+	apptest.CreatePackage(packages, loc.MustParseLocator("gravitational.io/gravity:0.0.1"), nil, c)
+	apptest.CreatePackage(packages, loc.MustParseLocator("gravitational.io/extra:0.0.1"), nil, c)
+	apptest.CreateDummyApplicationWithDependencies(apps, loc.MustParseLocator("gravitational.io/base:0.0.1"), `
+dependencies:
+  packages:
+  - gravitational.io/gravity:0.0.1`, c)
+	locator := loc.MustParseLocator("gravitational.io/app:0.0.1")
+	apptest.CreateDummyApplicationWithDependencies(apps, locator, `
+baseImage: gravitational.io/base:0.0.1
+dependencies:
+  packages:
+  - gravitational.io/extra:0.0.1`, c)
+
+	installer, err := apps.GetAppInstaller(app.InstallerRequest{
+		Application:      locator,
+		ExcludeBaseImage: true,
+	})
+	c.Assert(err, IsNil)
+	defer installer.Close()
+	dir := c.MkDir()
+	c.Assert(archive.Extract(installer, dir), IsNil)
+
+	backend, err := keyval.NewBolt(keyval.BoltConfig{
+		Path:     filepath.Join(dir, defaults.GravityDBFile),
+		Readonly: true,
+	})
+	c.Assert(err, IsNil)
+	defer backend.Close()
+	objects, err := fs.New(filepath.Join(dir, defaults.PackagesDir))
+	c.Assert(err, IsNil)
+	localPackages, err := localpack.New(localpack.Config{
+		Backend:     backend,
+		UnpackedDir: filepath.Join(dir, defaults.PackagesDir, defaults.UnpackedDir),
+		Objects:     objects,
+	})
+	c.Assert(err, IsNil)
+	envelopes, err := localPackages.GetPackages("gravitational.io")
+	c.Assert(err, IsNil)
+	var locators []string
+	for _, envelope := range envelopes {
+		locators = append(locators, envelope.Locator.String())
+	}
+	// the packages inherited from the base image are not included
+	c.Assert(locators, DeepEquals, []string{
+		"gravitational.io/app:0.0.1",
+		"gravitational.io/extra:0.0.1",
+	})
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/gravitational/gravity/lib/app"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/metrics"
 	"github.com/gravitational/gravity/lib/pack"
 	"github.com/gravitational/gravity/lib/run"
 	"github.com/gravitational/gravity/lib/schema"
@@ -90,6 +91,10 @@ type AppPullRequest struct {
 	// If < 0, the number of tasks is unrestricted.
 	// If in [0,1], the tasks are executed sequentially.
 	Parallel int
+	// SkipMissing allows dependencies missing from the source to be skipped
+	// as long as they already exist in the destination, e.g. when pulling
+	// an application from a delta cluster image
+	SkipMissing bool
 }
 
 // CheckAndSetDefaults checks the app pull request and sets some defaults
@@ -113,6 +118,7 @@ func (r *AppPullRequest) Clone(locator loc.Locator) AppPullRequest {
 		Progress:     r.Progress,
 		Parallel:     r.Parallel,
 		MetadataOnly: r.MetadataOnly,
+		SkipMissing:  r.SkipMissing,
 	}
 }
 
@@ -162,13 +168,13 @@ func pullPackageWithRetries(req PackagePullRequest, state *pullState) (env *pack
 	return env, nil
 }
 
-func pullPackage(req PackagePullRequest) (*pack.PackageEnvelope, error) {
-	err := req.CheckAndSetDefaults()
+func pullPackage(req PackagePullRequest) (env *pack.PackageEnvelope, err error) {
+	err = req.CheckAndSetDefaults()
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
 
-	env, err := req.DstPack.ReadPackageEnvelope(req.Package)
+	env, err = req.DstPack.ReadPackageEnvelope(req.Package)
 	if err != nil && !trace.IsNotFound(err) {
 		return nil, trace.Wrap(err)
 	}
@@ -179,6 +185,14 @@ func pullPackage(req PackagePullRequest) (*pack.PackageEnvelope, error) {
 	}
 
 	req.Infof("Pulling package %v.", req.Package)
+	start := time.Now()
+	defer func() {
+		if err != nil {
+			metrics.PackagePullFailures.Inc()
+			return
+		}
+		metrics.PackagePullDuration.Observe(metrics.Since(start))
+	}()
 
 	reader := ioutil.NopCloser(utils.NopReader())
 	if req.MetadataOnly {
@@ -196,6 +210,7 @@ func pullPackage(req PackagePullRequest) (*pack.PackageEnvelope, error) {
 			R:    req.Progress,
 		})
 	}
+	reader = utils.TeeReadCloser(reader, metrics.NewCounterWriter(metrics.PackagePullBytes))
 	defer reader.Close()
 
 	err = req.DstPack.UpsertRepository(env.Locator.Repository, time.Time{})
@@ -262,6 +277,12 @@ func pullApp(req AppPullRequest, state *pullState) (*app.Application, error) {
 
 	application, err := req.SrcApp.GetApp(req.Package)
 	if err != nil {
+		if trace.IsNotFound(err) && req.SkipMissing {
+			if _, errDst := req.DstApp.GetApp(req.Package); errDst == nil {
+				req.Infof("Application %v is missing from source but already exists.", req.Package)
+				return nil, trace.AlreadyExists("app %v already exists", req.Package)
+			}
+		}
 		return nil, trace.Wrap(err)
 	}
 
@@ -275,7 +296,7 @@ func pullApp(req AppPullRequest, state *pullState) (*app.Application, error) {
 
 	// first pull all app dependencies
 	switch manifest.Kind {
-	case schema.KindBundle, schema.KindCluster, schema.KindRuntime:
+	case schema.KindBundle, schema.KindCluster, schema.KindPlatform, schema.KindRuntime:
 		err = pullAppDeps(req, *manifest, state)
 		if err != nil {
 			return nil, trace.Wrap(err)
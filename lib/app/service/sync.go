@@ -27,6 +27,7 @@ import (
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/loc"
 	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/utils"
 
 	"github.com/cenkalti/backoff"
@@ -41,6 +42,12 @@ type SyncRequest struct {
 	ImageService docker.ImageService
 	Package      loc.Locator
 	Progress     utils.Printer
+	// SkipMissing allows applications missing from AppService to be skipped,
+	// e.g. applications left out of a delta cluster image
+	SkipMissing bool
+	// TrustPolicy specifies the optional policy to verify
+	// the application images with before the sync
+	TrustPolicy storage.ImageTrustPolicy
 }
 
 // CheckAndSetDefaults validates the request and sets some defaults.
@@ -60,6 +67,10 @@ func SyncApp(ctx context.Context, req SyncRequest) error {
 
 	application, err := req.AppService.GetApp(req.Package)
 	if err != nil {
+		if trace.IsNotFound(err) && req.SkipMissing {
+			log.Infof("Skip missing application %v.", req.Package)
+			return nil
+		}
 		return trace.Wrap(err)
 	}
 
@@ -72,6 +83,8 @@ func SyncApp(ctx context.Context, req SyncRequest) error {
 			ImageService: req.ImageService,
 			Package:      *base,
 			Progress:     req.Progress,
+			SkipMissing:  req.SkipMissing,
+			TrustPolicy:  req.TrustPolicy,
 		})
 		if err != nil {
 			return trace.Wrap(err)
@@ -86,6 +99,8 @@ func SyncApp(ctx context.Context, req SyncRequest) error {
 			ImageService: req.ImageService,
 			Package:      dep.Locator,
 			Progress:     req.Progress,
+			SkipMissing:  req.SkipMissing,
+			TrustPolicy:  req.TrustPolicy,
 		})
 		if err != nil {
 			return trace.Wrap(err)
@@ -131,6 +146,11 @@ func SyncApp(ctx context.Context, req SyncRequest) error {
 		return nil
 	}
 
+	err = docker.VerifyImages(ctx, syncPath, req.TrustPolicy, log.StandardLogger())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
 	log.Infof("Syncing %v.", req.Package)
 
 	if _, err = req.ImageService.Sync(ctx, syncPath, req.Progress); err != nil {
@@ -25,6 +25,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -33,6 +34,7 @@ import (
 	"github.com/gravitational/gravity/lib/app/hooks"
 	"github.com/gravitational/gravity/lib/app/resources"
 	"github.com/gravitational/gravity/lib/archive"
+	"github.com/gravitational/gravity/lib/buildcache"
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/helm"
@@ -132,6 +134,25 @@ type VendorRequest struct {
 	// ProgressReporter is a special writer, if set, vendorer will output user-friendly
 	// information during vendoring
 	ProgressReporter utils.Progress
+	// PullFromRegistry specifies whether to pull the application images directly
+	// from their registries instead of going through the local Docker daemon.
+	// Runtime images are always pulled with the Docker daemon
+	PullFromRegistry bool
+	// RegistryAuth specifies the credentials for the registries
+	// the images are pulled from
+	RegistryAuth docker.RegistryAuth
+	// Insecure disables the verification of the registry TLS certificates
+	Insecure bool
+	// Architectures lists the CPU architectures the application supports.
+	// The images are vendored for all of these architectures which
+	// requires PullFromRegistry if more than one is specified
+	Architectures []string
+	// ArchPackages lists the runtime packages built for the architectures
+	// other than amd64 to add to the application dependencies
+	ArchPackages []loc.Locator
+	// Cache optionally specifies the build cache to reuse the image layers
+	// vendored by the previous builds from
+	Cache *buildcache.Cache
 }
 
 // vendorer is a helper struct that encapsulates all services needed to vendor/rewrite images in
@@ -211,6 +232,14 @@ func (v *vendorer) VendorDir(ctx context.Context, unpackedDir string, req Vendor
 	}
 	log.Infof("Images: %v.", images)
 
+	// charts declared in the manifest are also rendered with the values
+	// of every flavor since these can refer to additional images
+	flavorResources, err := manifestChartResources(req.ManifestPath)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	chartResources = append(chartResources, flavorResources...)
+
 	// vendor chart images as well
 	chartImages, err := chartResources.Images()
 	if err != nil {
@@ -225,6 +254,18 @@ func (v *vendorer) VendorDir(ctx context.Context, unpackedDir string, req Vendor
 	}
 
 	images = append(images, chartImages...)
+	// remember the source references of the images since the registry
+	// address is lost once the images are rewritten below
+	sourceImages := make([]string, 0, len(images)+2)
+	sourceImages = append(sourceImages, images...)
+	sourceImages = append(sourceImages, defaults.ContainerImage, hooks.InitContainerImage)
+
+	if req.PullFromRegistry {
+		// images pinned to a digest are stored under a tag derived from the digest
+		if err = resourceFiles.RewriteImages(docker.UnpinImage); err != nil {
+			return trace.Wrap(err)
+		}
+	}
 
 	// Now that we have all referenced images in our local registry, and can find them without
 	// a registry prefix, rewrite our resource files to vendor the images.
@@ -241,6 +282,10 @@ func (v *vendorer) VendorDir(ctx context.Context, unpackedDir string, req Vendor
 	if req.VendorRuntime {
 		manifestRewrites = append(manifestRewrites, fetchRuntimeImages(&runtimeImages))
 	}
+	if len(req.Architectures) != 0 {
+		manifestRewrites = append(manifestRewrites,
+			makeRewriteArchitecturesFunc(req.Architectures, req.ArchPackages))
+	}
 
 	err = resourceFiles.RewriteManifest(manifestRewrites...)
 	if err != nil {
@@ -250,6 +295,10 @@ func (v *vendorer) VendorDir(ctx context.Context, unpackedDir string, req Vendor
 	// pull the default container image along with the rest of images
 	imagesToPull := append(images, defaults.ContainerImage)
 	imagesToPull = append(imagesToPull, runtimeImages...)
+	if req.PullFromRegistry {
+		// runtime images are translated into packages using the Docker daemon
+		imagesToPull = runtimeImages
+	}
 
 	group, groupCtx := run.WithContext(ctx, run.WithParallel(req.Parallel))
 	for _, image := range imagesToPull {
@@ -295,6 +344,11 @@ func (v *vendorer) VendorDir(ctx context.Context, unpackedDir string, req Vendor
 		return nil
 	}
 
+	if req.PullFromRegistry {
+		log.Infof("No registry layers found, will pull images %q from registries.", sourceImages)
+		return trace.Wrap(v.pullFromRegistries(ctx, teleutils.Deduplicate(sourceImages), unpackedDir, req))
+	}
+
 	// if the application package does not contain the dump of docker images of the referenced
 	// containers, pull all the necessary images, then export those images to disk
 	images, err = resourceFiles.Images()
@@ -308,11 +362,11 @@ func (v *vendorer) VendorDir(ctx context.Context, unpackedDir string, req Vendor
 	}
 
 	log.Infof("No registry layers found, will pull and export images %q.", images)
-	if err = v.pullAndExportImages(ctx, teleutils.Deduplicate(images), unpackedDir, req.Parallel, req.ProgressReporter); err != nil {
+	if err = v.pullAndExportImages(ctx, teleutils.Deduplicate(images), unpackedDir, req.Parallel, req.ProgressReporter, req.Cache); err != nil {
 		return trace.Wrap(err)
 	}
 
-	if err = v.pullAndExportImages(ctx, teleutils.Deduplicate(chartImages), unpackedDir, req.Parallel, req.ProgressReporter); err != nil {
+	if err = v.pullAndExportImages(ctx, teleutils.Deduplicate(chartImages), unpackedDir, req.Parallel, req.ProgressReporter, req.Cache); err != nil {
 		return trace.Wrap(err)
 	}
 
@@ -353,7 +407,7 @@ func printResourceStatus(resourceFile resources.ResourceFile, manifestPath strin
 // pullAndExportImages pulls the docker images of all referenced container images (if not yet
 // present locally), pushes them into an instance of a private docker registry and then
 // dumps the contents of this private registry into the specified directory
-func (v *vendorer) pullAndExportImages(ctx context.Context, images []string, exportDir string, parallel int, progress utils.Progress) error {
+func (v *vendorer) pullAndExportImages(ctx context.Context, images []string, exportDir string, parallel int, progress utils.Progress, cache *buildcache.Cache) error {
 	resourcesDir := filepath.Join(exportDir, "resources")
 	if err := os.MkdirAll(resourcesDir, defaults.PrivateDirMask); err != nil {
 		return trace.Wrap(trace.ConvertSystemError(err),
@@ -372,12 +426,32 @@ func (v *vendorer) pullAndExportImages(ctx context.Context, images []string, exp
 	}
 
 	if err := exportLayers(ctx, exportDir, images, v.dockerClient,
-		log.WithField("export-directory", exportDir), parallel, progress); err != nil {
+		log.WithField("export-directory", exportDir), parallel, progress, cache); err != nil {
 		return trace.Wrap(err)
 	}
 	return nil
 }
 
+// pullFromRegistries pulls the specified images directly from their registries
+// into the registry directory of the specified export directory
+func (v *vendorer) pullFromRegistries(ctx context.Context, images []string, exportDir string, req VendorRequest) error {
+	layersDir := filepath.Join(exportDir, defaults.RegistryDir)
+	if err := os.MkdirAll(layersDir, defaults.PrivateDirMask); err != nil {
+		return trace.Wrap(trace.ConvertSystemError(err),
+			"failed to create %q", layersDir)
+	}
+	return trace.Wrap(docker.PullImages(ctx, docker.PullRequest{
+		Images:        images,
+		Dir:           layersDir,
+		Auth:          req.RegistryAuth,
+		Insecure:      req.Insecure,
+		Parallel:      req.Parallel,
+		Progress:      req.ProgressReporter,
+		Architectures: req.Architectures,
+		Cache:         req.Cache,
+	}))
+}
+
 func (v *vendorer) translateRuntimeImages(m *schema.Manifest) error {
 	if m.SystemOptions != nil && m.SystemOptions.BaseImage != "" {
 		_, tag, err := parseImageNameTag(m.SystemOptions.BaseImage)
@@ -649,6 +723,24 @@ func makeRewriteDepsFunc(setPackages []loc.Locator) resources.ManifestRewriteFun
 	}
 }
 
+// makeRewriteArchitecturesFunc returns a function that sets the CPU architectures
+// the application supports and adds the packages built for them to the dependencies
+func makeRewriteArchitecturesFunc(architectures []string, packages []loc.Locator) resources.ManifestRewriteFunc {
+	return func(m *schema.Manifest) error {
+		if m.SystemOptions == nil {
+			m.SystemOptions = &schema.SystemOptions{}
+		}
+		m.SystemOptions.Architectures = architectures
+		for _, pkg := range packages {
+			if _, err := m.Dependencies.ByName(pkg.Name); err == nil {
+				continue
+			}
+			m.Dependencies.Packages = append(m.Dependencies.Packages, schema.Dependency{Locator: pkg})
+		}
+		return nil
+	}
+}
+
 // makeRewritePackagesMetadataFunc returns a function that processes metadata for the app's dependency
 // packages (base, packages, apps) and rewrites versions accordingly
 func makeRewritePackagesMetadataFunc(packages pack.PackageService) resources.ManifestRewriteFunc {
@@ -710,6 +802,70 @@ func resourceFromChart(path string) (*resources.Resource, error) {
 	return resources.Decode(bytes.NewReader(out))
 }
 
+// manifestChartResources renders the Helm charts declared in the manifest
+// at the specified path with the values files of the chart and every flavor
+func manifestChartResources(manifestPath string) (result resources.ResourceFiles, err error) {
+	if manifestPath == "" {
+		return nil, nil
+	}
+	manifest, err := schema.ParseManifest(manifestPath)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	resourcesDir := filepath.Dir(manifestPath)
+	for _, chart := range manifest.Charts {
+		path := filepath.Join(resourcesDir, chart.Path)
+		isChartDir, err := isChartDirectory(path)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if !isChartDir {
+			return nil, trace.BadParameter("chart %q: %v is not a Helm chart directory",
+				chart.Name, chart.Path)
+		}
+		for _, values := range chartValueSets(chart) {
+			log.WithField("chart", chart.Name).Infof("Extracting images from Helm chart with values %v.", values)
+			out, err := helm.Render(helm.RenderParameters{
+				Path:   path,
+				Values: resourcePaths(resourcesDir, values),
+			})
+			if err != nil {
+				return nil, trace.Wrap(err, "failed to render chart %q with values %v", chart.Name, values)
+			}
+			resource, err := resources.Decode(bytes.NewReader(out))
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			result = append(result, resources.NewResourceFileObject(path, *resource))
+		}
+	}
+	return result, nil
+}
+
+// chartValueSets returns the combinations of values files the chart
+// can be installed with: the chart values alone and with the values of every flavor
+func chartValueSets(chart schema.Chart) [][]string {
+	var flavors []string
+	for flavor := range chart.Flavors {
+		flavors = append(flavors, flavor)
+	}
+	sort.Strings(flavors)
+	valueSets := [][]string{chart.Values}
+	for _, flavor := range flavors {
+		valueSets = append(valueSets, chart.ValuesFor(flavor))
+	}
+	return valueSets
+}
+
+// resourcePaths returns the specified paths relative to the resources directory
+// as paths in the resources directory
+func resourcePaths(resourcesDir string, paths []string) (result []string) {
+	for _, path := range paths {
+		result = append(result, filepath.Join(resourcesDir, path))
+	}
+	return result
+}
+
 // resourcesFromPath collects resource files in root for further processing.
 // It will search for files starting with root and matching a set of file path patterns
 // specified with patterns.
@@ -19,7 +19,9 @@ package service
 import (
 	"bytes"
 	"io/ioutil"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -91,6 +93,30 @@ func (s *VendorSuite) TestRewriteDeps(c *C) {
 	c.Assert(locators, DeepEquals, deps)
 }
 
+func (s *VendorSuite) TestRewriteArchitectures(c *C) {
+	rFiles := createResourceFile("testarch", manifestWithDeps, c)
+	archPackages := []loc.Locator{
+		loc.MustParseLocator("gravitational.io/gravity-arm64:0.0.1"),
+		loc.MustParseLocator("gravitational.io/planet-arm64:0.0.1"),
+	}
+	rewrite := makeRewriteArchitecturesFunc([]string{"amd64", "arm64"}, archPackages)
+	// rewriting twice does not duplicate the dependencies
+	c.Assert(rFiles.RewriteManifest(rewrite, rewrite), IsNil)
+
+	var architectures []string
+	var packages []loc.Locator
+	err := rFiles.RewriteManifest(func(m *schema.Manifest) error {
+		architectures = m.Architectures()
+		packages = m.Dependencies.GetPackages()
+		return nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(architectures, DeepEquals, []string{"amd64", "arm64"})
+	c.Assert(packages, DeepEquals, append([]loc.Locator{
+		loc.MustParseLocator("gravitational.io/gravity:0.0.1"),
+	}, archPackages...))
+}
+
 func (s *VendorSuite) TestRewitePackagesMetadata(c *C) {
 	rFiles := createResourceFile("testmeta", manifestWithPackagesMetadata, c)
 
@@ -202,6 +228,47 @@ func (*VendorSuite) TestGeneratesProperPackageNames(c *C) {
 	}
 }
 
+func (s *VendorSuite) TestRendersManifestChartsForFlavors(c *C) {
+	dir := c.MkDir()
+	files := map[string]string{
+		defaults.ManifestFileName: `apiVersion: bundle.gravitational.io/v2
+kind: Bundle
+metadata:
+  name: app
+  resourceVersion: 0.0.1
+charts:
+  - name: app
+    path: charts/app
+    values: [charts/values.yaml]
+    flavors:
+      large: [charts/values-large.yaml]`,
+		"charts/app/Chart.yaml": `name: app
+version: 0.0.1`,
+		"charts/app/templates/pod.yaml": `apiVersion: v1
+kind: Pod
+metadata:
+  name: app
+spec:
+  containers:
+  - name: app
+    image: {{ .Values.image }}`,
+		"charts/values.yaml":       `image: app:1.0.0`,
+		"charts/values-large.yaml": `image: app-large:1.0.0`,
+	}
+	for path, data := range files {
+		path = filepath.Join(dir, path)
+		c.Assert(os.MkdirAll(filepath.Dir(path), defaults.SharedDirMask), IsNil)
+		c.Assert(ioutil.WriteFile(path, []byte(data), defaults.SharedReadMask), IsNil)
+	}
+
+	chartResources, err := manifestChartResources(filepath.Join(dir, defaults.ManifestFileName))
+	c.Assert(err, IsNil)
+	images, err := chartResources.Images()
+	c.Assert(err, IsNil)
+	sort.Strings(images)
+	c.Assert(images, DeepEquals, []string{"app-large:1.0.0", "app:1.0.0"})
+}
+
 func createResourceFile(path, manifest string, c *C) resources.ResourceFiles {
 	dir := c.MkDir()
 	fileName := filepath.Join(dir, path)
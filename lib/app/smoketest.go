@@ -0,0 +1,122 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/httplib"
+	"github.com/gravitational/gravity/lib/schema"
+
+	"github.com/gravitational/trace"
+)
+
+// RunSmokeTest runs the application smoke test specified with req.SmokeTest
+// and streams its output into the provided writer until the test
+// passes, fails or times out. The writer is closed when the test completes
+func RunSmokeTest(ctx context.Context, apps Applications, req HookRunRequest, wc io.WriteCloser) error {
+	app, err := apps.GetApp(req.Application)
+	if err != nil {
+		wc.Close()
+		return trace.Wrap(err)
+	}
+	test, err := app.Manifest.GetSmokeTest(req.SmokeTest)
+	if err != nil {
+		wc.Close()
+		return trace.Wrap(err)
+	}
+	timeout, err := test.GetTimeout()
+	if err != nil {
+		wc.Close()
+		return trace.Wrap(err)
+	}
+	if test.HTTP != nil {
+		defer wc.Close()
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return trace.Wrap(runHTTPSmokeTest(ctx, *test.HTTP, wc))
+	}
+	req.Hook = schema.HookSmokeTest
+	req.Timeout = timeout
+	// smoke tests verify the running application and do not need
+	// the application resources or the gravity binary
+	req.SkipInitContainers = true
+	_, err = StreamAppHook(ctx, apps, req, wc)
+	return trace.Wrap(err)
+}
+
+// runHTTPSmokeTest requests the test endpoint until it responds
+// as expected or the context expires
+func runHTTPSmokeTest(ctx context.Context, test schema.SmokeTestHTTP, w io.Writer) error {
+	client := httplib.GetClient(test.InsecureSkipVerify)
+	ticker := time.NewTicker(defaults.SmokeTestRetryInterval)
+	defer ticker.Stop()
+	for {
+		err := checkHTTPSmokeTest(ctx, client, test)
+		if err == nil {
+			fmt.Fprintf(w, "%v responded as expected\n", test.URL)
+			return nil
+		}
+		fmt.Fprintf(w, "%v\n", err)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return trace.LimitExceeded("%v did not respond as expected: %v", test.URL, err)
+		}
+	}
+}
+
+func checkHTTPSmokeTest(ctx context.Context, client *http.Client, test schema.SmokeTestHTTP) error {
+	req, err := http.NewRequest(http.MethodGet, test.URL, nil)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer resp.Body.Close()
+	if test.ExpectedStatus != 0 && resp.StatusCode != test.ExpectedStatus {
+		return trace.BadParameter("%v responded with status %v, expected %v",
+			test.URL, resp.StatusCode, test.ExpectedStatus)
+	}
+	if test.ExpectedStatus == 0 && (resp.StatusCode < 200 || resp.StatusCode > 299) {
+		return trace.BadParameter("%v responded with status %v", test.URL, resp.StatusCode)
+	}
+	if test.ExpectedBody == "" {
+		return nil
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxSmokeTestBodySize))
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	if !strings.Contains(string(body), test.ExpectedBody) {
+		return trace.BadParameter("%v response does not contain %q", test.URL, test.ExpectedBody)
+	}
+	return nil
+}
+
+// maxSmokeTestBodySize is the maximum size of the response body
+// searched for the expected text
+const maxSmokeTestBodySize = 1 << 20
@@ -0,0 +1,78 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gravitational/gravity/lib/schema"
+
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+type SmokeTestSuite struct{}
+
+var _ = Suite(&SmokeTestSuite{})
+
+func (s *SmokeTestSuite) TestHTTPSmokeTest(c *C) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "status: ok")
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	err := runHTTPSmokeTest(ctx, schema.SmokeTestHTTP{
+		URL:          server.URL,
+		ExpectedBody: "ok",
+	}, ioutil.Discard)
+	c.Assert(err, IsNil)
+	c.Assert(requests, Equals, 2)
+}
+
+func (s *SmokeTestSuite) TestHTTPSmokeTestTimesOut(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "status: degraded")
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := runHTTPSmokeTest(ctx, schema.SmokeTestHTTP{
+		URL:          server.URL,
+		ExpectedBody: "status: ok",
+	}, ioutil.Discard)
+	c.Assert(trace.IsLimitExceeded(err), Equals, true)
+
+	err = checkHTTPSmokeTest(context.Background(), http.DefaultClient, schema.SmokeTestHTTP{
+		URL:            server.URL,
+		ExpectedStatus: http.StatusNoContent,
+	})
+	c.Assert(trace.IsBadParameter(err), Equals, true)
+}
@@ -45,7 +45,20 @@ func DefaultOptions() *dockerarchive.TarOptions {
 func CompressDirectory(dir string, writer io.Writer, items ...*Item) error {
 	archive := NewTarAppender(writer)
 	defer archive.Close()
+	return compressDirectory(archive, dir, items...)
+}
+
+// CompressDirectoryReproducible is like CompressDirectory but creates
+// the archive with the modification time of all items set to modTime
+// and their ownership reset, so that the archives of the identical
+// contents are byte-identical
+func CompressDirectoryReproducible(dir string, writer io.Writer, modTime time.Time, items ...*Item) error {
+	archive := NewReproducibleTarAppender(writer, modTime)
+	defer archive.Close()
+	return compressDirectory(archive, dir, items...)
+}
 
+func compressDirectory(archive *TarAppender, dir string, items ...*Item) error {
 	if err := archive.Add(items...); err != nil {
 		return trace.Wrap(err, "failed to write tarball: %v", err.Error())
 	}
@@ -240,11 +253,20 @@ type TarGlobHandler func(match *tar.Header, r *tar.Reader) error
 // TarAppender wraps a tar writer and can append items to it
 type TarAppender struct {
 	tw *tar.Writer
+	// modTime optionally overrides the modification time of the items
+	modTime time.Time
 }
 
 // NewTarAppender creates a new tar appender writing to w
 func NewTarAppender(w io.Writer) *TarAppender {
-	return &TarAppender{tar.NewWriter(w)}
+	return &TarAppender{tw: tar.NewWriter(w)}
+}
+
+// NewReproducibleTarAppender creates a new tar appender writing to w
+// that sets the modification time of the items to modTime and resets
+// their ownership
+func NewReproducibleTarAppender(w io.Writer, modTime time.Time) *TarAppender {
+	return &TarAppender{tw: tar.NewWriter(w), modTime: modTime.UTC()}
 }
 
 // Add adds the specified items to the underlined archive
@@ -257,7 +279,9 @@ func (r *TarAppender) Add(items ...*Item) (err error) {
 		}
 	}()
 	for _, item := range items {
-		if item.ModTime.IsZero() {
+		if !r.modTime.IsZero() {
+			normalizeHeader(&item.Header, r.modTime)
+		} else if item.ModTime.IsZero() {
 			item.ModTime = time.Now()
 		}
 		if err = r.tw.WriteHeader(&item.Header); err != nil {
@@ -278,6 +302,18 @@ func (r *TarAppender) Close() error {
 	return r.tw.Close()
 }
 
+// normalizeHeader removes the attributes that depend on the time and
+// the user the item has been created by from the specified header
+func normalizeHeader(header *tar.Header, modTime time.Time) {
+	header.ModTime = modTime
+	header.AccessTime = time.Time{}
+	header.ChangeTime = time.Time{}
+	header.Uid = defaults.ArchiveUID
+	header.Gid = defaults.ArchiveGID
+	header.Uname = ""
+	header.Gname = ""
+}
+
 // ItemFromString creates an Item from given string
 func ItemFromString(path, value string) *Item {
 	return ItemFromStringMode(path, value, defaults.SharedExecutableMask)
@@ -317,7 +353,15 @@ func ItemFromStream(path string, rc io.ReadCloser, size, mode int64) *Item {
 
 // ItemFromFile creates an Item from the specified file
 func ItemFromFile(localPath, path string, fi os.FileInfo) (*Item, error) {
-	fiHeader, err := tar.FileInfoHeader(fi, "")
+	var link string
+	if fi.Mode()&os.ModeSymlink != 0 {
+		var err error
+		link, err = os.Readlink(path)
+		if err != nil {
+			return nil, trace.ConvertSystemError(err)
+		}
+	}
+	fiHeader, err := tar.FileInfoHeader(fi, link)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -325,7 +369,7 @@ func ItemFromFile(localPath, path string, fi os.FileInfo) (*Item, error) {
 		Header: *fiHeader,
 	}
 	item.Name = localPath
-	if !fi.IsDir() {
+	if fiHeader.Typeflag == tar.TypeReg {
 		item.Data, err = os.Open(path)
 		if err != nil {
 			return nil, trace.Wrap(err)
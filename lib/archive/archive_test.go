@@ -24,6 +24,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -121,6 +122,43 @@ func (_ *S) TestCompressesDirectory(c *C) {
 	AssertArchiveHasItems(c, ioutil.NopCloser(&buf), nil, testCases[0], testCases[1], testCases[2])
 }
 
+func (_ *S) TestCompressesDirectoryReproducibly(c *C) {
+	var testCases = []file{
+		{name: "dir", isDir: true},
+		{name: "dir/file1", data: []byte("brown")},
+		{name: "dir/file2", data: []byte("fox")},
+	}
+	modTime := time.Unix(1500000000, 0)
+
+	compress := func(dir string) []byte {
+		var buf bytes.Buffer
+		err := CompressDirectoryReproducible(dir, &buf, modTime, ItemFromString("extra", "jumps"))
+		c.Assert(err, IsNil)
+		return buf.Bytes()
+	}
+	dir1, dir2 := c.MkDir(), c.MkDir()
+	write(c, dir1, testCases)
+	write(c, dir2, testCases)
+	c.Assert(os.Chtimes(filepath.Join(dir2, "dir/file1"), time.Now(), time.Now().Add(time.Hour)), IsNil)
+
+	tarball := compress(dir1)
+	c.Assert(compress(dir2), DeepEquals, tarball)
+	AssertArchiveHasItems(c, ioutil.NopCloser(bytes.NewReader(tarball)), nil,
+		file{name: "extra", data: []byte("jumps")}, testCases[0], testCases[1], testCases[2])
+
+	r := tar.NewReader(bytes.NewReader(tarball))
+	for {
+		hdr, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		c.Assert(err, IsNil)
+		c.Assert(hdr.ModTime.Equal(modTime), Equals, true, Commentf("%v", hdr.Name))
+		c.Assert(hdr.Uid, Equals, defaults.ArchiveUID)
+		c.Assert(hdr.Uname, Equals, "")
+	}
+}
+
 func (_ *S) TestExtractsWithoutPermissions(c *C) {
 	var data = []byte("root")
 	rc := ioutil.NopCloser(bytes.NewReader(data))
@@ -62,6 +62,9 @@ type Autoscaler struct {
 	publishedToken string
 	// publishedserviceURL is the service url that has been published to SSM
 	publishedServiceURL string
+	// publishedScaleUpTokens maps node profiles to the scale up tokens
+	// that have been published to SSM
+	publishedScaleUpTokens map[string]string
 }
 
 // Config is autoscaler config
@@ -119,8 +122,9 @@ func New(cfg Config) (*Autoscaler, error) {
 		cfg.Cloud = ec2.New(sess)
 	}
 	a := &Autoscaler{
-		Config: cfg,
-		Entry:  log.WithFields(log.Fields{trace.Component: "autoscale"}),
+		Config:                 cfg,
+		Entry:                  log.WithFields(log.Fields{trace.Component: "autoscale"}),
+		publishedScaleUpTokens: make(map[string]string),
 	}
 	return a, nil
 }
@@ -194,6 +198,21 @@ func (a *Autoscaler) GetJoinToken(ctx context.Context) (string, error) {
 	return aws.StringValue(resp.Parameter.Value), nil
 }
 
+// GetScaleUpToken fetches and decrypts the join token issued for scaling up
+// the cluster with nodes of the specified profile from SSM parameter
+func (a *Autoscaler) GetScaleUpToken(ctx context.Context, profile string) (string, error) {
+	name := a.scaleUpTokenParam(profile)
+	a.Debugf("GetScaleUpToken(%v)", name)
+	resp, err := a.SystemsManager.GetParameterWithContext(ctx, &ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return "", ConvertError(err)
+	}
+	return aws.StringValue(resp.Parameter.Value), nil
+}
+
 // GetServiceURL returns service URL
 func (a *Autoscaler) GetServiceURL(ctx context.Context) (string, error) {
 	name := a.serviceURLParam()
@@ -250,10 +269,49 @@ func (a *Autoscaler) publishJoinToken(ctx context.Context, token string, force b
 	return nil
 }
 
+func (a *Autoscaler) publishScaleUpTokens(ctx context.Context, tokens map[string]string, force bool) error {
+	for profile, token := range tokens {
+		if token == a.publishedScaleUpTokens[profile] && !force {
+			continue
+		}
+		name := a.scaleUpTokenParam(profile)
+		a.Debugf("PublishScaleUpToken(%v)", name)
+		_, err := a.SystemsManager.PutParameterWithContext(ctx, &ssm.PutParameterInput{
+			Type:      aws.String("SecureString"),
+			Name:      aws.String(name),
+			Value:     aws.String(token),
+			Overwrite: aws.Bool(true),
+		})
+		if err != nil {
+			return ConvertError(err)
+		}
+		a.publishedScaleUpTokens[profile] = token
+	}
+	for profile := range a.publishedScaleUpTokens {
+		if _, ok := tokens[profile]; ok {
+			continue
+		}
+		name := a.scaleUpTokenParam(profile)
+		a.Debugf("DeleteScaleUpToken(%v)", name)
+		_, err := a.SystemsManager.DeleteParameterWithContext(ctx, &ssm.DeleteParameterInput{
+			Name: aws.String(name),
+		})
+		if err = ConvertError(err); err != nil && !trace.IsNotFound(err) {
+			return trace.Wrap(err)
+		}
+		delete(a.publishedScaleUpTokens, profile)
+	}
+	return nil
+}
+
 func (a *Autoscaler) tokenParam() string {
 	return fmt.Sprintf("/telekube/%v/token", a.ClusterName)
 }
 
+func (a *Autoscaler) scaleUpTokenParam(profile string) string {
+	return fmt.Sprintf("/telekube/%v/tokens/%v", a.ClusterName, profile)
+}
+
 func (a *Autoscaler) serviceURLParam() string {
 	return fmt.Sprintf("/telekube/%v/service", a.ClusterName)
 }
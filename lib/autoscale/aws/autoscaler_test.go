@@ -288,3 +288,33 @@ func (o *mockOperator) CreateSiteShrinkOperation(ctx context.Context, req ops.Cr
 		OperationID: "op-1",
 	}, nil
 }
+
+// TestSelectsActiveScaleUpTokens verifies which scale up tokens get published
+func (s *AutoscalerSuite) TestSelectsActiveScaleUpTokens(c *check.C) {
+	now := time.Date(2019, time.March, 1, 12, 0, 0, 0, time.UTC)
+	tokens := []storage.ProvisioningToken{
+		{Token: "node-old", ScaleUp: true, Roles: []string{"node"}, Expires: now.Add(time.Minute)},
+		{Token: "node-new", ScaleUp: true, Roles: []string{"node"}, Expires: now.Add(time.Hour)},
+		{Token: "db-used", ScaleUp: true, Roles: []string{"db"}, Expires: now.Add(time.Hour), MaxUses: 1, Uses: 1},
+		{Token: "gpu-expired", ScaleUp: true, Roles: []string{"gpu"}, Expires: now.Add(-time.Minute)},
+		{Token: "worker", Roles: []string{"worker"}, Expires: now.Add(time.Hour)},
+	}
+	c.Assert(activeScaleUpTokens(tokens, now), check.DeepEquals, map[string]string{
+		"node": "node-new",
+	})
+}
+
+// TestRenewsJoinToken verifies when the published join token is replaced
+func (s *AutoscalerSuite) TestRenewsJoinToken(c *check.C) {
+	now := time.Date(2019, time.March, 1, 12, 0, 0, 0, time.UTC)
+	tokens := []storage.ProvisioningToken{
+		{Token: "active", Expires: now.Add(2 * time.Hour)},
+		{Token: "used", Expires: now.Add(2 * time.Hour), MaxUses: 1, Uses: 1},
+	}
+	c.Assert(isValidToken(tokens, "active", now), check.Equals, true)
+	c.Assert(isValidToken(tokens, "active", now.Add(3*time.Hour)), check.Equals, false,
+		check.Commentf("token about to expire should be renewed"))
+	c.Assert(isValidToken(tokens, "used", now), check.Equals, false)
+	c.Assert(isValidToken(tokens, "deleted", now), check.Equals, false)
+	c.Assert(isValidToken(tokens, "", now), check.Equals, false)
+}
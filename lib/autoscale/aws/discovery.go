@@ -24,6 +24,7 @@ import (
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
 
 	"github.com/gravitational/trace"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -64,7 +65,16 @@ func (a *Autoscaler) syncDiscovery(ctx context.Context, operator ops.Operator, f
 		return trace.Wrap(err)
 	}
 
-	if err := a.syncToken(ctx, operator, cluster, force); err != nil {
+	tokens, err := operator.GetJoinTokens(ctx, cluster.Key())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	if err := a.syncToken(ctx, operator, cluster, tokens, force); err != nil {
+		return trace.Wrap(err)
+	}
+
+	if err := a.publishScaleUpTokens(ctx, activeScaleUpTokens(tokens, time.Now().UTC()), force); err != nil {
 		return trace.Wrap(err)
 	}
 
@@ -74,17 +84,62 @@ func (a *Autoscaler) syncDiscovery(ctx context.Context, operator ops.Operator, f
 	return nil
 }
 
-func (a *Autoscaler) syncToken(ctx context.Context, operator ops.Operator, cluster *ops.Site, force bool) error {
-	joinToken, err := operator.GetExpandToken(cluster.Key())
-	if err != nil {
-		return trace.Wrap(err)
+// syncToken publishes the join token for the instances of the auto scaling
+// groups. The published token is a short-lived join token that is replaced
+// with a new one before it expires
+func (a *Autoscaler) syncToken(ctx context.Context, operator ops.Operator, cluster *ops.Site, tokens []storage.ProvisioningToken, force bool) error {
+	token := a.publishedToken
+	if !isValidToken(tokens, token, time.Now().UTC().Add(defaults.AutoscaleJoinTokenRenewal)) {
+		joinToken, err := operator.CreateJoinToken(ctx, ops.CreateJoinTokenRequest{
+			SiteKey: cluster.Key(),
+			TTL:     defaults.MaxJoinTokenTTL,
+		})
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		token = joinToken.Token
 	}
-	if err := a.publishJoinToken(ctx, joinToken.Token, force); err != nil {
+	if err := a.publishJoinToken(ctx, token, force); err != nil {
 		return trace.Wrap(err)
 	}
 	return nil
 }
 
+// isValidToken returns true if the specified token is among the join tokens
+// and is still valid at the specified time
+func isValidToken(tokens []storage.ProvisioningToken, token string, at time.Time) bool {
+	if token == "" {
+		return false
+	}
+	for _, t := range tokens {
+		if t.Token == token {
+			return !t.IsExhausted() && t.Expires.After(at)
+		}
+	}
+	return false
+}
+
+// activeScaleUpTokens returns the scale up token to publish for each node profile.
+// If there are several active tokens for the same profile, the one that expires last is selected
+func activeScaleUpTokens(tokens []storage.ProvisioningToken, now time.Time) map[string]string {
+	active := make(map[string]storage.ProvisioningToken)
+	for _, token := range tokens {
+		if !token.ScaleUp || len(token.Roles) != 1 || token.IsExhausted() || !token.Expires.After(now) {
+			continue
+		}
+		profile := token.Roles[0]
+		if existing, ok := active[profile]; ok && existing.Expires.After(token.Expires) {
+			continue
+		}
+		active[profile] = token
+	}
+	result := make(map[string]string, len(active))
+	for profile, token := range active {
+		result[profile] = token.Token
+	}
+	return result
+}
+
 func (a *Autoscaler) getServiceURL() (string, error) {
 	service, err := a.Client.Core().Services(constants.KubeSystemNamespace).Get(constants.GravityServiceName, v1.GetOptions{})
 	if err != nil {
@@ -0,0 +1,250 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/defaults"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/gravitational/trace"
+)
+
+// AppRelease describes the application release backed up
+// separately from the rest of the cluster
+type AppRelease struct {
+	// Name is the name of the release
+	Name string `json:"name"`
+	// Namespace is the namespace the release is deployed in
+	Namespace string `json:"namespace"`
+	// Image is the locator of the application image of the release
+	Image string `json:"image"`
+}
+
+// Check validates the release
+func (r AppRelease) Check() error {
+	if r.Name == "" {
+		return trace.BadParameter("release name is required")
+	}
+	if r.Namespace == "" {
+		return trace.BadParameter("release %v has no namespace", r.Name)
+	}
+	if r.Namespace == defaults.KubeSystemNamespace {
+		return trace.BadParameter("release %v is deployed in the %v namespace shared "+
+			"with the cluster components and can not be backed up separately",
+			r.Name, defaults.KubeSystemNamespace)
+	}
+	return nil
+}
+
+// AppResource is the etcd record of a Kubernetes resource of the application
+type AppResource struct {
+	// Key is the etcd key of the resource
+	Key string `json:"key"`
+	// Value is the resource as stored in etcd
+	Value []byte `json:"value"`
+}
+
+// KVReader reads the Kubernetes resources from etcd
+type KVReader interface {
+	// Get retrieves the keys from etcd
+	Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error)
+}
+
+// AppBackup describes the backup of a single application release.
+// The backup is a directory with the following contents:
+//
+//	release.json     the backed up release
+//	resources.json   etcd records of the Kubernetes resources of the release
+//	data/            data written by the backup hook of the application
+//
+// The resources of the release are the resources in its namespace, the
+// namespace itself and the records Helm keeps for the release
+type AppBackup struct {
+	// Dir is the backup directory
+	Dir string
+	// Release is the backed up release
+	Release AppRelease
+}
+
+// BackupApp writes the Kubernetes resources of the release read from etcd
+// into the specified directory. The application data is written into
+// the data directory of the backup separately by the backup hook.
+// Returns the backup and the number of the backed up resources
+func BackupApp(ctx context.Context, kv KVReader, release AppRelease, dir string) (*AppBackup, int, error) {
+	if err := release.Check(); err != nil {
+		return nil, 0, trace.Wrap(err)
+	}
+	keys, err := kv.Get(ctx, kubernetesPrefix, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	if err != nil {
+		return nil, 0, trace.Wrap(err)
+	}
+	var resources []AppResource
+	for _, key := range keys.Kvs {
+		if !isAppResource(string(key.Key), release) {
+			continue
+		}
+		// the resource is read separately so the values
+		// of all cluster resources are not read at once
+		resp, err := kv.Get(ctx, string(key.Key))
+		if err != nil {
+			return nil, 0, trace.Wrap(err)
+		}
+		for _, resource := range resp.Kvs {
+			resources = append(resources, AppResource{Key: string(resource.Key), Value: resource.Value})
+		}
+	}
+	if len(resources) == 0 {
+		return nil, 0, trace.NotFound("no resources of release %v found in namespace %v",
+			release.Name, release.Namespace)
+	}
+	backup := &AppBackup{Dir: dir, Release: release}
+	if err := os.MkdirAll(backup.DataDir(), defaults.PrivateDirMask); err != nil {
+		return nil, 0, trace.ConvertSystemError(err)
+	}
+	if err := writeJSON(backup.path(AppReleaseFile), release); err != nil {
+		return nil, 0, trace.Wrap(err)
+	}
+	if err := writeJSON(backup.path(AppResourcesFile), resources); err != nil {
+		return nil, 0, trace.Wrap(err)
+	}
+	return backup, len(resources), nil
+}
+
+// OpenApp returns the application backup in the specified directory
+func OpenApp(dir string) (*AppBackup, error) {
+	backup := &AppBackup{Dir: dir}
+	data, err := ioutil.ReadFile(backup.path(AppReleaseFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, trace.NotFound("%v is not an application backup", dir)
+		}
+		return nil, trace.ConvertSystemError(err)
+	}
+	if err := json.Unmarshal(data, &backup.Release); err != nil {
+		return nil, trace.BadParameter("invalid release in %v: %v", dir, err)
+	}
+	if err := backup.Release.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return backup, nil
+}
+
+// DataDir returns the directory with the data written by the backup hook
+func (r *AppBackup) DataDir() string {
+	return r.path(AppDataDir)
+}
+
+// HasData returns true if the backup includes the application data
+func (r *AppBackup) HasData() bool {
+	entries, err := ioutil.ReadDir(r.DataDir())
+	return err == nil && len(entries) != 0
+}
+
+// RestoreResources writes the Kubernetes resources of the release into the
+// cluster etcd, replacing the existing resources with the same keys.
+// The resources of the other applications are not modified.
+// Returns the number of restored resources
+func (r *AppBackup) RestoreResources(ctx context.Context, kv KV) (count int, err error) {
+	data, err := ioutil.ReadFile(r.path(AppResourcesFile))
+	if err != nil {
+		return 0, trace.ConvertSystemError(err)
+	}
+	var resources []AppResource
+	if err := json.Unmarshal(data, &resources); err != nil {
+		return 0, trace.BadParameter("invalid resources in %v: %v", r.Dir, err)
+	}
+	for _, resource := range resources {
+		// the backup can not modify the resources of other applications
+		if !isAppResource(resource.Key, r.Release) {
+			return count, trace.BadParameter("resource %v does not belong to release %v",
+				resource.Key, r.Release.Name)
+		}
+		if _, err := kv.Put(ctx, resource.Key, string(resource.Value)); err != nil {
+			return count, trace.Wrap(err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+func (r *AppBackup) path(name string) string {
+	return filepath.Join(r.Dir, name)
+}
+
+// isAppResource returns true if the Kubernetes resource with
+// the specified etcd key belongs to the release
+func isAppResource(key string, release AppRelease) bool {
+	if !isRestoredResource(key) {
+		return false
+	}
+	if key == kubernetesPrefix+"namespaces/"+release.Namespace {
+		return true
+	}
+	// Helm keeps the releases in the configmaps named after the release
+	// and its revision in the namespace of tiller
+	if strings.HasPrefix(key, kubernetesPrefix+"configmaps/"+defaults.KubeSystemNamespace+"/"+release.Name+".v") {
+		return true
+	}
+	return resourceNamespace(key) == release.Namespace
+}
+
+// resourceNamespace returns the namespace of the Kubernetes resource with
+// the specified etcd key or an empty string for cluster-wide resources.
+//
+// The keys of the namespaced resources are
+// /registry/<resource>/<namespace>/<name> for the built-in resources
+// and /registry/<group>/<resource>/<namespace>/<name> for the custom
+// resources, where the group always includes a dot
+func resourceNamespace(key string) string {
+	parts := strings.Split(strings.TrimPrefix(key, kubernetesPrefix), "/")
+	// services are kept under the services/specs prefix
+	if len(parts) > 1 && parts[0] == "services" {
+		parts = parts[1:]
+	}
+	if len(parts) > 0 && strings.Contains(parts[0], ".") {
+		parts = parts[1:]
+	}
+	if len(parts) != 3 {
+		return ""
+	}
+	return parts[1]
+}
+
+// writeJSON writes the value as JSON into the file at path
+func writeJSON(path string, v interface{}) error {
+	return writeFile(path, func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(v)
+	})
+}
+
+const (
+	// AppReleaseFile is the name of the file with the backed up release
+	AppReleaseFile = "release.json"
+	// AppResourcesFile is the name of the file with the etcd records
+	// of the Kubernetes resources of the release
+	AppResourcesFile = "resources.json"
+	// AppDataDir is the name of the directory with the application data
+	AppDataDir = "data"
+)
@@ -0,0 +1,134 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+type AppSuite struct {
+	kv      *memKV
+	release AppRelease
+}
+
+var _ = Suite(&AppSuite{})
+
+func (s *AppSuite) SetUpTest(c *C) {
+	s.kv = &memKV{values: map[string]string{
+		"/registry/namespaces/blog":                                     "ns",
+		"/registry/namespaces/shop":                                     "ns",
+		"/registry/deployments/blog/wordpress":                          "deployment",
+		"/registry/pods/blog/wordpress-1":                               "pod",
+		"/registry/services/specs/blog/wordpress":                       "service",
+		"/registry/services/endpoints/blog/wordpress":                   "endpoints",
+		"/registry/secrets/blog/db":                                     "secret",
+		"/registry/etcd.database.coreos.com/etcdclusters/blog/db":       "custom",
+		"/registry/deployments/shop/cart":                               "deployment",
+		"/registry/clusterroles/blog":                                   "clusterrole",
+		"/registry/configmaps/kube-system/blog.v1":                      "release",
+		"/registry/configmaps/kube-system/blog.v2":                      "release",
+		"/registry/configmaps/kube-system/shop.v1":                      "release",
+		"/registry/apiextensions.k8s.io/customresourcedefinitions/blog": "crd",
+	}}
+	s.release = AppRelease{Name: "blog", Namespace: "blog", Image: "gravitational.io/blog:0.0.1"}
+}
+
+func (s *AppSuite) TestBacksUpAppResources(c *C) {
+	dir := c.MkDir()
+	backup, count, err := BackupApp(context.TODO(), s.kv, s.release, dir)
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, 7)
+	c.Assert(backup.HasData(), Equals, false)
+	c.Assert(ioutil.WriteFile(filepath.Join(backup.DataDir(), "dump.sql"), []byte("data"), 0600), IsNil)
+
+	backup, err = OpenApp(dir)
+	c.Assert(err, IsNil)
+	c.Assert(backup.Release, DeepEquals, s.release)
+	c.Assert(backup.HasData(), Equals, true)
+	kv := &fakeKV{}
+	count, err = backup.RestoreResources(context.TODO(), kv)
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, 7)
+	sort.Strings(kv.puts)
+	c.Assert(kv.puts, DeepEquals, []string{
+		"/registry/configmaps/kube-system/blog.v1=release",
+		"/registry/configmaps/kube-system/blog.v2=release",
+		"/registry/deployments/blog/wordpress=deployment",
+		"/registry/etcd.database.coreos.com/etcdclusters/blog/db=custom",
+		"/registry/namespaces/blog=ns",
+		"/registry/secrets/blog/db=secret",
+		"/registry/services/specs/blog/wordpress=service",
+	})
+}
+
+func (s *AppSuite) TestRejectsSystemNamespace(c *C) {
+	_, _, err := BackupApp(context.TODO(), s.kv,
+		AppRelease{Name: "dns", Namespace: "kube-system"}, c.MkDir())
+	c.Assert(trace.IsBadParameter(err), Equals, true, Commentf("%v", err))
+}
+
+func (s *AppSuite) TestFailsWithoutResources(c *C) {
+	_, _, err := BackupApp(context.TODO(), s.kv,
+		AppRelease{Name: "wiki", Namespace: "wiki"}, c.MkDir())
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%v", err))
+}
+
+func (s *AppSuite) TestRejectsResourcesOfOtherApps(c *C) {
+	dir := c.MkDir()
+	_, _, err := BackupApp(context.TODO(), s.kv, s.release, dir)
+	c.Assert(err, IsNil)
+	c.Assert(writeJSON(filepath.Join(dir, AppResourcesFile), []AppResource{
+		{Key: "/registry/deployments/shop/cart", Value: []byte("deployment")},
+	}), IsNil)
+	backup, err := OpenApp(dir)
+	c.Assert(err, IsNil)
+	kv := &fakeKV{}
+	_, err = backup.RestoreResources(context.TODO(), kv)
+	c.Assert(trace.IsBadParameter(err), Equals, true, Commentf("%v", err))
+	c.Assert(kv.puts, HasLen, 0)
+}
+
+// memKV is the in-memory etcd supporting the prefix
+// and keys-only reads
+type memKV struct {
+	values map[string]string
+}
+
+func (r *memKV) Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	op := clientv3.OpGet(key, opts...)
+	var resp clientv3.GetResponse
+	for k, v := range r.values {
+		if k != key && (len(op.RangeBytes()) == 0 || !strings.HasPrefix(k, key)) {
+			continue
+		}
+		kv := &mvccpb.KeyValue{Key: []byte(k)}
+		if !op.IsKeysOnly() {
+			kv.Value = []byte(v)
+		}
+		resp.Kvs = append(resp.Kvs, kv)
+	}
+	return &resp, nil
+}
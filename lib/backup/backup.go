@@ -0,0 +1,422 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package backup implements the scheduled backups of the cluster etcd
+// database, the cluster state and the registry metadata.
+//
+// Each backup is a directory named after the time it was taken:
+//
+//	backup-20190601T020000Z/
+//	  etcd.db            etcd snapshot
+//	  gravity-state.gz   cluster state archive, see gravity system import-state
+//	  registry.json      images in the cluster registry with their digests
+//	  apps/<name>/       data written by the backup hook of the application
+//	  apps/<name>.log    output of the backup hook of the application
+//
+// The backups uploaded to the off-site destination are compressed
+// tarballs of the backup directory encrypted with AES-256-GCM,
+// see WriteArchive and ExtractArchive
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gravitational/gravity/lib/app/docker"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"github.com/sirupsen/logrus"
+)
+
+// Snapshotter takes the etcd database snapshots
+type Snapshotter interface {
+	// Snapshot returns the stream with the snapshot of the etcd database
+	Snapshot(ctx context.Context) (io.ReadCloser, error)
+}
+
+// SchedulerConfig describes the configuration of the backup scheduler
+type SchedulerConfig struct {
+	// Etcd takes the etcd database snapshots
+	Etcd Snapshotter
+	// Backend is the cluster backend. It stores the backup schedule
+	// and status and its state is included into the backups
+	Backend storage.Backend
+	// ClusterName is the name of the local cluster
+	ClusterName string
+	// NodeName is the name of the node the backups are taken on
+	NodeName string
+	// ListImages optionally lists the images in the cluster registry
+	ListImages func(context.Context) ([]docker.LocalImage, error)
+	// Hooks optionally runs the backup hooks of the cluster applications
+	Hooks HookRunner
+	// Destinations returns the off-site destinations to upload the backups to.
+	// Required if the backup schedule specifies the destination
+	Destinations DestinationFactory
+	// Interval is how often the schedule is checked
+	Interval time.Duration
+	// Timeout is the maximum duration of a single backup
+	Timeout time.Duration
+	// Clock is used to determine whether the backup is due
+	Clock clockwork.Clock
+	// FieldLogger is used for logging
+	logrus.FieldLogger
+}
+
+// CheckAndSetDefaults validates the configuration and sets defaults
+func (r *SchedulerConfig) CheckAndSetDefaults() error {
+	if r.Etcd == nil {
+		return trace.BadParameter("etcd client is required")
+	}
+	if r.Backend == nil {
+		return trace.BadParameter("backend is required")
+	}
+	if r.ClusterName == "" {
+		return trace.BadParameter("cluster name is required")
+	}
+	if r.Interval == 0 {
+		r.Interval = defaults.BackupCheckInterval
+	}
+	if r.Timeout == 0 {
+		r.Timeout = defaults.BackupTimeout
+	}
+	if r.Clock == nil {
+		r.Clock = clockwork.NewRealClock()
+	}
+	if r.FieldLogger == nil {
+		r.FieldLogger = logrus.WithField(trace.Component, "backup")
+	}
+	return nil
+}
+
+// NewScheduler returns a new scheduler that backs up the cluster
+// according to the backup schedule
+func NewScheduler(config SchedulerConfig) (*Scheduler, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &Scheduler{SchedulerConfig: config}, nil
+}
+
+// Scheduler takes the cluster backups according to the backup schedule
+// and removes the backups beyond the configured number to retain.
+// If the schedule specifies the off-site destination, each backup is
+// also uploaded there as an encrypted archive.
+//
+// The next backup is scheduled after the last attempt recorded in the
+// backup status, so the backup missed while the scheduler was not running
+// is taken as soon as it starts. Without a previous attempt, the first
+// backup is scheduled after the time the schedule has been observed
+type Scheduler struct {
+	SchedulerConfig
+	// firstSeen is the time the current schedule has been first observed
+	firstSeen time.Time
+	// schedule is the last observed schedule
+	schedule string
+}
+
+// Run takes the backups according to the schedule until the context is canceled
+func (r *Scheduler) Run(ctx context.Context) {
+	r.Info("Starting backup scheduler.")
+	ticker := r.Clock.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.Chan():
+			if err := r.backupIfDue(ctx); err != nil {
+				r.WithError(err).Warn("Failed to back up cluster.")
+			}
+		case <-ctx.Done():
+			r.Info("Stopping backup scheduler.")
+			return
+		}
+	}
+}
+
+func (r *Scheduler) backupIfDue(ctx context.Context) error {
+	schedule, err := r.Backend.GetBackupSchedule(r.ClusterName)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			r.schedule = ""
+			return nil
+		}
+		return trace.Wrap(err)
+	}
+	now := r.Clock.Now().UTC()
+	if schedule.GetSchedule() != r.schedule {
+		r.schedule, r.firstSeen = schedule.GetSchedule(), now
+	}
+	prev, err := r.Backend.GetBackupStatus(r.ClusterName)
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	if prev == nil {
+		prev = &storage.BackupStatus{}
+	}
+	since := prev.LastAttempt
+	if since.IsZero() {
+		since = r.firstSeen
+	}
+	next := schedule.Next(since)
+	if next.IsZero() || now.Before(next) {
+		return nil
+	}
+	return trace.Wrap(r.Backup(ctx, schedule))
+}
+
+// Backup takes a single backup according to the schedule, removes the
+// backups beyond the number to retain and records the backup status
+func (r *Scheduler) Backup(ctx context.Context, schedule storage.BackupSchedule) error {
+	prev, err := r.Backend.GetBackupStatus(r.ClusterName)
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	if prev == nil {
+		prev = &storage.BackupStatus{}
+	}
+	status := *prev
+	status.LastAttempt = r.Clock.Now().UTC()
+	path, size, apps, backupErr := r.backup(ctx, schedule.GetDirectory(), status.LastAttempt)
+	status.Apps = apps
+	if backupErr != nil {
+		status.Message = backupErr.Error()
+	} else {
+		r.Infof("Backed up cluster to %v.", path)
+		status.LastBackup = r.Clock.Now().UTC()
+		status.Path = path
+		status.Size = size
+		status.Node = r.NodeName
+		status.Message = ""
+		if err := prune(schedule.GetDirectory(), schedule.GetRetain()); err != nil {
+			r.WithError(err).Warn("Failed to remove old backups.")
+		}
+		status.Uploaded, status.UploadMessage = "", ""
+		if destination := schedule.GetDestination(); destination != nil {
+			location, err := r.upload(ctx, *destination, path, schedule.GetRetain())
+			if err != nil {
+				r.WithError(err).Warn("Failed to upload backup.")
+				status.UploadMessage = trace.UserMessage(err)
+			} else {
+				r.Infof("Uploaded backup to %v.", location)
+				status.Uploaded = location
+			}
+		}
+	}
+	if err := r.Backend.UpsertBackupStatus(r.ClusterName, status); err != nil {
+		return trace.NewAggregate(backupErr, err)
+	}
+	return trace.Wrap(backupErr)
+}
+
+// backup writes the backup into a new directory under dir and
+// returns the path to the directory, the size of the backup
+// and the outcome of the application backup hooks
+func (r *Scheduler) backup(ctx context.Context, dir string, now time.Time) (path string, size int64, apps []storage.BackupAppStatus, err error) {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+	path = filepath.Join(dir, backupPrefix+now.Format(backupTimeFormat))
+	tmp := path + partialSuffix
+	if err := os.MkdirAll(tmp, defaults.PrivateDirMask); err != nil {
+		return "", 0, nil, trace.ConvertSystemError(err)
+	}
+	defer os.RemoveAll(tmp)
+	err = writeFile(filepath.Join(tmp, EtcdSnapshotFile), func(w io.Writer) error {
+		snapshot, err := r.Etcd.Snapshot(ctx)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		defer snapshot.Close()
+		_, err = io.Copy(w, snapshot)
+		return trace.Wrap(err, "failed to snapshot etcd")
+	})
+	if err != nil {
+		return "", 0, nil, trace.Wrap(err)
+	}
+	err = writeFile(filepath.Join(tmp, StateArchiveFile), func(w io.Writer) error {
+		_, err := storage.WriteStateArchive(ctx, r.Backend, w)
+		return trace.Wrap(err, "failed to export cluster state")
+	})
+	if err != nil {
+		return "", 0, nil, trace.Wrap(err)
+	}
+	if r.ListImages != nil {
+		err = writeFile(filepath.Join(tmp, RegistryFile), func(w io.Writer) error {
+			images, err := r.ListImages(ctx)
+			if err != nil {
+				return trace.Wrap(err, "failed to list registry images")
+			}
+			return trace.Wrap(writeRegistryMetadata(w, images))
+		})
+		if err != nil {
+			return "", 0, nil, trace.Wrap(err)
+		}
+	}
+	if r.Hooks != nil {
+		apps, err = r.backupApps(ctx, filepath.Join(tmp, AppsDir))
+		if err != nil {
+			return "", 0, nil, trace.Wrap(err)
+		}
+	}
+	size, err = dirSize(tmp)
+	if err != nil {
+		return "", 0, apps, trace.Wrap(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return "", 0, apps, trace.ConvertSystemError(err)
+	}
+	return path, size, apps, nil
+}
+
+// backupApps runs the backup hooks of the cluster applications, each with
+// its own directory under dir to write the application data to.
+//
+// The failed hooks do not fail the backup of the cluster state but
+// are recorded in the returned status and their data is removed
+func (r *Scheduler) backupApps(ctx context.Context, dir string) (result []storage.BackupAppStatus, err error) {
+	apps, err := r.Hooks.Applications(ctx, schema.HookBackup)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to list applications with backup hooks")
+	}
+	for _, app := range apps {
+		status := storage.BackupAppStatus{App: app.String()}
+		if err := r.backupApp(ctx, app, dir); err != nil {
+			r.WithError(err).Warnf("Failed to back up application %v.", app)
+			status.Message = trace.UserMessage(err)
+		}
+		result = append(result, status)
+	}
+	return result, nil
+}
+
+func (r *Scheduler) backupApp(ctx context.Context, app loc.Locator, dir string) error {
+	path := filepath.Join(dir, app.Name)
+	// the hook containers do not necessarily run as root
+	if err := os.MkdirAll(path, defaults.SharedDirMask); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	err := writeFile(path+hookLogSuffix, func(w io.Writer) error {
+		return trace.Wrap(r.Hooks.Run(ctx, app, schema.HookBackup, path, w))
+	})
+	if err != nil {
+		return trace.NewAggregate(err, trace.ConvertSystemError(os.RemoveAll(path)))
+	}
+	return nil
+}
+
+// RegistryImage describes an image in the cluster registry
+type RegistryImage struct {
+	// Image is the image reference
+	Image string `json:"image"`
+	// Digest is the digest of the image manifest
+	Digest string `json:"digest"`
+}
+
+func writeRegistryMetadata(w io.Writer, images []docker.LocalImage) error {
+	result := make([]RegistryImage, 0, len(images))
+	for _, image := range images {
+		result = append(result, RegistryImage{
+			Image:  image.String(),
+			Digest: image.Digest.String(),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Image < result[j].Image
+	})
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return trace.Wrap(encoder.Encode(result))
+}
+
+// prune removes the oldest backups in dir so that at most retain backups
+// are left, as well as the incomplete backups
+func prune(dir string, retain int) error {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	var backups []string
+	var errors []error
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || !strings.HasPrefix(name, backupPrefix) {
+			continue
+		}
+		if strings.HasSuffix(name, partialSuffix) {
+			errors = append(errors, trace.ConvertSystemError(os.RemoveAll(filepath.Join(dir, name))))
+			continue
+		}
+		backups = append(backups, name)
+	}
+	// backup names sort in the order they were taken
+	sort.Strings(backups)
+	for len(backups) > retain {
+		errors = append(errors, trace.ConvertSystemError(os.RemoveAll(filepath.Join(dir, backups[0]))))
+		backups = backups[1:]
+	}
+	return trace.NewAggregate(errors...)
+}
+
+func writeFile(path string, fn func(io.Writer) error) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, defaults.PrivateFileMask)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	if err := fn(f); err != nil {
+		f.Close()
+		return trace.Wrap(err)
+	}
+	return trace.ConvertSystemError(f.Close())
+}
+
+func dirSize(dir string) (size int64, err error) {
+	err = filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return trace.ConvertSystemError(err)
+		}
+		if fi.Mode().IsRegular() {
+			size += fi.Size()
+		}
+		return nil
+	})
+	return size, trace.Wrap(err)
+}
+
+const (
+	// EtcdSnapshotFile is the name of the etcd snapshot in the backup
+	EtcdSnapshotFile = "etcd.db"
+	// StateArchiveFile is the name of the cluster state archive in the backup
+	StateArchiveFile = "gravity-state.gz"
+	// RegistryFile is the name of the registry metadata in the backup
+	RegistryFile = "registry.json"
+	// AppsDir is the name of the directory with the application data in the backup
+	AppsDir = "apps"
+
+	backupPrefix     = "backup-"
+	partialSuffix    = ".partial"
+	hookLogSuffix    = ".log"
+	backupTimeFormat = "20060102T150405Z"
+)
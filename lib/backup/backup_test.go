@@ -0,0 +1,241 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gravitational/gravity/lib/app/docker"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/keyval"
+
+	"github.com/jonboulle/clockwork"
+	. "gopkg.in/check.v1"
+)
+
+func TestBackup(t *testing.T) { TestingT(t) }
+
+type SchedulerSuite struct {
+	backend storage.Backend
+	clock   clockwork.FakeClock
+	dir     string
+	etcd    *fakeEtcd
+}
+
+var _ = Suite(&SchedulerSuite{})
+
+func (s *SchedulerSuite) SetUpTest(c *C) {
+	var err error
+	s.backend, err = keyval.NewBolt(keyval.BoltConfig{Path: filepath.Join(c.MkDir(), "bolt.db")})
+	c.Assert(err, IsNil)
+	s.clock = clockwork.NewFakeClockAt(time.Date(2019, time.June, 1, 1, 0, 0, 0, time.UTC))
+	s.dir = c.MkDir()
+	s.etcd = &fakeEtcd{snapshot: []byte("snapshot")}
+}
+
+func (s *SchedulerSuite) TearDownTest(c *C) {
+	c.Assert(s.backend.Close(), IsNil)
+}
+
+func (s *SchedulerSuite) TestBacksUpOnSchedule(c *C) {
+	scheduler := s.newScheduler(c)
+	s.upsertSchedule(c, 2)
+
+	c.Assert(scheduler.backupIfDue(context.TODO()), IsNil)
+	c.Assert(s.backups(c), HasLen, 0)
+
+	s.clock.Advance(time.Hour)
+	c.Assert(scheduler.backupIfDue(context.TODO()), IsNil)
+	c.Assert(s.backups(c), DeepEquals, []string{"backup-20190601T020000Z"})
+
+	path := filepath.Join(s.dir, "backup-20190601T020000Z")
+	data, err := ioutil.ReadFile(filepath.Join(path, EtcdSnapshotFile))
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "snapshot")
+	data, err = ioutil.ReadFile(filepath.Join(path, RegistryFile))
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Matches, `(?s).*"image": "nginx:1.17".*`)
+	f, err := os.Open(filepath.Join(path, StateArchiveFile))
+	c.Assert(err, IsNil)
+	defer f.Close()
+	restored, err := keyval.NewBolt(keyval.BoltConfig{Path: filepath.Join(c.MkDir(), "bolt.db")})
+	c.Assert(err, IsNil)
+	defer restored.Close()
+	_, err = storage.ReadStateArchive(context.TODO(), restored, f)
+	c.Assert(err, IsNil)
+	_, err = restored.GetBackupSchedule("example.com")
+	c.Assert(err, IsNil)
+
+	status, err := s.backend.GetBackupStatus("example.com")
+	c.Assert(err, IsNil)
+	c.Assert(status.IsHealthy(), Equals, true)
+	c.Assert(status.Path, Equals, path)
+	c.Assert(status.Node, Equals, "node-1")
+	c.Assert(status.LastBackup, DeepEquals, s.clock.Now().UTC())
+
+	// the backup is not repeated until the next scheduled time
+	s.clock.Advance(time.Hour)
+	c.Assert(scheduler.backupIfDue(context.TODO()), IsNil)
+	c.Assert(s.backups(c), HasLen, 1)
+}
+
+func (s *SchedulerSuite) TestRemovesOldBackups(c *C) {
+	scheduler := s.newScheduler(c)
+	s.upsertSchedule(c, 2)
+	c.Assert(os.Mkdir(filepath.Join(s.dir, "backup-20190530T020000Z.partial"), 0700), IsNil)
+	c.Assert(os.Mkdir(filepath.Join(s.dir, "other"), 0700), IsNil)
+	c.Assert(scheduler.backupIfDue(context.TODO()), IsNil)
+	for i := 0; i < 3; i++ {
+		s.clock.Advance(24 * time.Hour)
+		c.Assert(scheduler.backupIfDue(context.TODO()), IsNil)
+	}
+	c.Assert(s.backups(c), DeepEquals, []string{
+		"backup-20190603T010000Z",
+		"backup-20190604T010000Z",
+		"other",
+	})
+}
+
+func (s *SchedulerSuite) TestRecordsFailure(c *C) {
+	scheduler := s.newScheduler(c)
+	s.upsertSchedule(c, 2)
+	c.Assert(scheduler.backupIfDue(context.TODO()), IsNil)
+	s.clock.Advance(time.Hour)
+	c.Assert(scheduler.backupIfDue(context.TODO()), IsNil)
+
+	s.etcd.err = errors.New("etcd is unavailable")
+	s.clock.Advance(24 * time.Hour)
+	c.Assert(scheduler.backupIfDue(context.TODO()), NotNil)
+	c.Assert(s.backups(c), DeepEquals, []string{"backup-20190601T020000Z"})
+
+	status, err := s.backend.GetBackupStatus("example.com")
+	c.Assert(err, IsNil)
+	c.Assert(status.IsHealthy(), Equals, false)
+	c.Assert(status.Path, Equals, filepath.Join(s.dir, "backup-20190601T020000Z"))
+	c.Assert(status.Age(s.clock.Now()), Equals, 24*time.Hour)
+}
+
+func (s *SchedulerSuite) TestBacksUpApps(c *C) {
+	scheduler := s.newScheduler(c)
+	hooks := &fakeHooks{
+		apps: []loc.Locator{
+			loc.MustParseLocator("gravitational.io/app:1.0.0"),
+			loc.MustParseLocator("gravitational.io/cluster:1.0.0"),
+		},
+		failures: map[string]error{"app": errors.New("database is unavailable")},
+	}
+	scheduler.Hooks = hooks
+	s.upsertSchedule(c, 2)
+	c.Assert(scheduler.backupIfDue(context.TODO()), IsNil)
+	s.clock.Advance(time.Hour)
+	c.Assert(scheduler.backupIfDue(context.TODO()), IsNil)
+
+	path := filepath.Join(s.dir, "backup-20190601T020000Z")
+	data, err := ioutil.ReadFile(filepath.Join(path, AppsDir, "cluster", "data"))
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "cluster data")
+	_, err = os.Stat(filepath.Join(path, AppsDir, "app"))
+	c.Assert(os.IsNotExist(err), Equals, true)
+	data, err = ioutil.ReadFile(filepath.Join(path, AppsDir, "app.log"))
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "backup app\n")
+
+	status, err := s.backend.GetBackupStatus("example.com")
+	c.Assert(err, IsNil)
+	c.Assert(status.IsHealthy(), Equals, false)
+	c.Assert(status.Message, Equals, "")
+	c.Assert(status.Path, Equals, path)
+	c.Assert(status.Apps, DeepEquals, []storage.BackupAppStatus{
+		{App: "gravitational.io/app:1.0.0", Message: "database is unavailable"},
+		{App: "gravitational.io/cluster:1.0.0"},
+	})
+}
+
+func (s *SchedulerSuite) newScheduler(c *C) *Scheduler {
+	scheduler, err := NewScheduler(SchedulerConfig{
+		Etcd:        s.etcd,
+		Backend:     s.backend,
+		ClusterName: "example.com",
+		NodeName:    "node-1",
+		ListImages: func(context.Context) ([]docker.LocalImage, error) {
+			return []docker.LocalImage{
+				{TagSpec: docker.TagSpec{Name: "nginx", Version: "1.17"}, Digest: "sha256:aaaa"},
+			}, nil
+		},
+		Clock: s.clock,
+	})
+	c.Assert(err, IsNil)
+	return scheduler
+}
+
+func (s *SchedulerSuite) upsertSchedule(c *C, retain int) {
+	c.Assert(s.backend.UpsertBackupSchedule("example.com", storage.NewBackupSchedule(
+		storage.BackupScheduleSpecV2{
+			Schedule:  "0 2 * * *",
+			Directory: s.dir,
+			Retain:    retain,
+		})), IsNil)
+}
+
+func (s *SchedulerSuite) backups(c *C) (names []string) {
+	entries, err := ioutil.ReadDir(s.dir)
+	c.Assert(err, IsNil)
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+type fakeHooks struct {
+	apps     []loc.Locator
+	failures map[string]error
+}
+
+func (r *fakeHooks) Applications(context.Context, schema.HookType) ([]loc.Locator, error) {
+	return r.apps, nil
+}
+
+func (r *fakeHooks) Run(ctx context.Context, app loc.Locator, hook schema.HookType, dir string, w io.Writer) error {
+	fmt.Fprintf(w, "%v %v\n", hook, app.Name)
+	if err := r.failures[app.Name]; err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "data"), []byte(app.Name+" data"), 0600)
+}
+
+type fakeEtcd struct {
+	snapshot []byte
+	err      error
+}
+
+func (r *fakeEtcd) Snapshot(context.Context) (io.ReadCloser, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return ioutil.NopCloser(bytes.NewReader(r.snapshot)), nil
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+
+	"github.com/gravitational/trace"
+)
+
+// NewEncryptor returns a writer that encrypts the data written to it
+// with the specified key and writes it to w. The writer must be closed
+// to write the final chunk, the data is incomplete otherwise.
+//
+// The data is encrypted with AES-256-GCM in chunks so it can be streamed.
+// Each chunk is authenticated together with its position and whether it
+// is the final chunk, so the reordered and truncated data is detected
+func NewEncryptor(w io.Writer, key []byte) (io.WriteCloser, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	prefix := make([]byte, noncePrefixSize)
+	if _, err := io.ReadFull(rand.Reader, prefix); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if _, err := w.Write(append([]byte(encryptionMagic), prefix...)); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &encryptor{
+		w:      w,
+		aead:   aead,
+		prefix: prefix,
+		buf:    make([]byte, 0, chunkSize),
+	}, nil
+}
+
+// NewDecryptor returns a reader that decrypts the data encrypted by
+// the writer returned by NewEncryptor from r with the specified key
+func NewDecryptor(r io.Reader, key []byte) (io.Reader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	header := make([]byte, len(encryptionMagic)+noncePrefixSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, trace.BadParameter("data is not an encrypted backup: %v", err)
+	}
+	if !bytes.HasPrefix(header, []byte(encryptionMagic)) {
+		return nil, trace.BadParameter("data is not an encrypted backup")
+	}
+	return &decryptor{
+		r:      r,
+		aead:   aead,
+		prefix: header[len(encryptionMagic):],
+	}, nil
+}
+
+type encryptor struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	prefix  []byte
+	counter uint32
+	buf     []byte
+}
+
+// Write buffers the data and encrypts it in chunks
+func (r *encryptor) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		if len(r.buf) == chunkSize {
+			if err := r.writeChunk(false); err != nil {
+				return n, trace.Wrap(err)
+			}
+		}
+		written := copy(r.buf[len(r.buf):chunkSize], p)
+		r.buf = r.buf[:len(r.buf)+written]
+		p = p[written:]
+		n += written
+	}
+	return n, nil
+}
+
+// Close encrypts the remaining data as the final chunk
+func (r *encryptor) Close() error {
+	return trace.Wrap(r.writeChunk(true))
+}
+
+func (r *encryptor) writeChunk(final bool) error {
+	flag := chunkFlag(final)
+	sealed := r.aead.Seal(nil, chunkNonce(r.prefix, r.counter), r.buf, []byte{flag})
+	header := make([]byte, chunkHeaderSize)
+	header[0] = flag
+	binary.BigEndian.PutUint32(header[1:], uint32(len(sealed)))
+	if _, err := r.w.Write(append(header, sealed...)); err != nil {
+		return trace.Wrap(err)
+	}
+	r.counter++
+	r.buf = r.buf[:0]
+	return nil
+}
+
+type decryptor struct {
+	r       io.Reader
+	aead    cipher.AEAD
+	prefix  []byte
+	counter uint32
+	buf     []byte
+	final   bool
+}
+
+// Read returns the decrypted data
+func (r *decryptor) Read(p []byte) (n int, err error) {
+	for len(r.buf) == 0 {
+		if r.final {
+			return 0, io.EOF
+		}
+		if err := r.readChunk(); err != nil {
+			return 0, trace.Wrap(err)
+		}
+	}
+	n = copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *decryptor) readChunk() error {
+	header := make([]byte, chunkHeaderSize)
+	if _, err := io.ReadFull(r.r, header); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return trace.BadParameter("encrypted backup is truncated")
+		}
+		return trace.Wrap(err)
+	}
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > chunkSize+uint32(r.aead.Overhead()) {
+		return trace.BadParameter("encrypted backup is corrupted")
+	}
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(r.r, sealed); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return trace.BadParameter("encrypted backup is truncated")
+		}
+		return trace.Wrap(err)
+	}
+	data, err := r.aead.Open(nil, chunkNonce(r.prefix, r.counter), sealed, header[:1])
+	if err != nil {
+		return trace.BadParameter("failed to decrypt backup, " +
+			"the encryption key is invalid or the backup is corrupted")
+	}
+	r.counter++
+	r.buf = data
+	r.final = header[0] == chunkFlag(true)
+	return nil
+}
+
+// newAEAD returns the cipher for the specified key.
+// The encryption key is derived from the key with SHA-256
+// so the key of any length can be used
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) == 0 {
+		return nil, trace.BadParameter("encryption key is empty")
+	}
+	derived := sha256.Sum256(key)
+	block, err := aes.NewCipher(derived[:])
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return aead, nil
+}
+
+func chunkNonce(prefix []byte, counter uint32) []byte {
+	nonce := make([]byte, noncePrefixSize+4)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], counter)
+	return nonce
+}
+
+func chunkFlag(final bool) byte {
+	if final {
+		return 1
+	}
+	return 0
+}
+
+const (
+	// encryptionMagic identifies the encrypted backups
+	encryptionMagic = "GRAVBAK1"
+	// noncePrefixSize is the size of the random part of the chunk nonces
+	noncePrefixSize = 8
+	// chunkSize is the size of the encrypted chunks
+	chunkSize = 64 * 1024
+	// chunkHeaderSize is the size of the chunk header with
+	// the final chunk flag and the size of the sealed chunk
+	chunkHeaderSize = 5
+)
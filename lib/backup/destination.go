@@ -0,0 +1,377 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	awss3 "github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/gravitational/rigging"
+	"github.com/gravitational/trace"
+	"golang.org/x/crypto/ssh"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Destination stores the backup archives off the node
+type Destination interface {
+	// Upload stores the archive with the specified name read from r
+	Upload(ctx context.Context, name string, r io.Reader) error
+	// Download writes the archive with the specified name to w
+	Download(ctx context.Context, name string, w io.Writer) error
+	// List returns the names of the stored archives
+	List(ctx context.Context) ([]string, error)
+	// Delete deletes the archive with the specified name
+	Delete(ctx context.Context, name string) error
+}
+
+// DestinationSecret describes the contents of the destination secret
+type DestinationSecret struct {
+	// EncryptionKey is the key the backups are encrypted with
+	EncryptionKey []byte
+	// AccessKeyID is the optional access key of the S3-compatible storage,
+	// the default credentials chain is used if unspecified
+	AccessKeyID string
+	// SecretAccessKey is the secret for the access key
+	SecretAccessKey string
+	// PrivateKey is the SSH private key of the SFTP user
+	PrivateKey []byte
+}
+
+// GetDestinationSecret returns the contents of the Kubernetes secret
+// with the specified name in the kube-system namespace
+func GetDestinationSecret(client kubernetes.Interface, name string) (*DestinationSecret, error) {
+	secret, err := client.CoreV1().Secrets(defaults.KubeSystemNamespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, trace.Wrap(rigging.ConvertError(err))
+	}
+	result := &DestinationSecret{
+		EncryptionKey:   secret.Data[EncryptionKeySecretKey],
+		AccessKeyID:     string(secret.Data[AccessKeyIDSecretKey]),
+		SecretAccessKey: string(secret.Data[SecretAccessKeySecretKey]),
+		PrivateKey:      secret.Data[v1.SSHAuthPrivateKey],
+	}
+	if len(result.EncryptionKey) == 0 {
+		return nil, trace.NotFound("secret %v has no %v key", name, EncryptionKeySecretKey)
+	}
+	return result, nil
+}
+
+// NewDestination returns the destination described by the specified
+// backup destination configuration and secret
+func NewDestination(config storage.BackupDestination, secret DestinationSecret) (Destination, error) {
+	if err := config.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	switch config.Type {
+	case storage.BackupDestinationS3Type:
+		return newS3Destination(*config.S3, secret)
+	case storage.BackupDestinationSFTPType:
+		return newSFTPDestination(*config.SFTP, secret)
+	case storage.BackupDestinationNFSType:
+		return &nfsDestination{dir: config.NFS.Path}, nil
+	}
+	return nil, trace.BadParameter("unsupported backup destination type %q", config.Type)
+}
+
+func newS3Destination(config storage.BackupDestinationS3, secret DestinationSecret) (*s3Destination, error) {
+	if (secret.AccessKeyID == "") != (secret.SecretAccessKey == "") {
+		return nil, trace.BadParameter("both %v and %v are required", AccessKeyIDSecretKey, SecretAccessKeySecretKey)
+	}
+	awsConfig := &aws.Config{
+		S3ForcePathStyle: aws.Bool(config.ForcePathStyle),
+	}
+	if config.Region != "" {
+		awsConfig.Region = aws.String(config.Region)
+	}
+	if config.Endpoint != "" {
+		awsConfig.Endpoint = aws.String(config.Endpoint)
+	}
+	if secret.AccessKeyID != "" {
+		awsConfig.Credentials = credentials.NewStaticCredentials(secret.AccessKeyID, secret.SecretAccessKey, "")
+	}
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	client := awss3.New(sess)
+	return &s3Destination{
+		bucket:   config.Bucket,
+		prefix:   strings.Trim(config.Prefix, "/"),
+		api:      client,
+		uploader: s3manager.NewUploaderWithClient(client),
+	}, nil
+}
+
+// s3Destination stores the backups in the bucket of S3-compatible object storage
+type s3Destination struct {
+	bucket   string
+	prefix   string
+	api      s3iface.S3API
+	uploader *s3manager.Uploader
+}
+
+// Upload uploads the archive to the bucket, in multiple parts if necessary
+func (r *s3Destination) Upload(ctx context.Context, name string, data io.Reader) error {
+	_, err := r.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(r.bucket),
+		Key:    aws.String(path.Join(r.prefix, name)),
+		Body:   data,
+	})
+	return trace.Wrap(err)
+}
+
+// Download writes the archive from the bucket to w
+func (r *s3Destination) Download(ctx context.Context, name string, w io.Writer) error {
+	out, err := r.api.GetObjectWithContext(ctx, &awss3.GetObjectInput{
+		Bucket: aws.String(r.bucket),
+		Key:    aws.String(path.Join(r.prefix, name)),
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer out.Body.Close()
+	_, err = io.Copy(w, out.Body)
+	return trace.Wrap(err)
+}
+
+// List returns the names of the archives in the bucket under the prefix
+func (r *s3Destination) List(ctx context.Context) (names []string, err error) {
+	prefix := r.prefix
+	if prefix != "" {
+		prefix += "/"
+	}
+	err = r.api.ListObjectsV2PagesWithContext(ctx, &awss3.ListObjectsV2Input{
+		Bucket:    aws.String(r.bucket),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String("/"),
+	}, func(page *awss3.ListObjectsV2Output, _ bool) bool {
+		for _, object := range page.Contents {
+			names = append(names, strings.TrimPrefix(aws.StringValue(object.Key), prefix))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return names, nil
+}
+
+// Delete deletes the archive from the bucket
+func (r *s3Destination) Delete(ctx context.Context, name string) error {
+	_, err := r.api.DeleteObjectWithContext(ctx, &awss3.DeleteObjectInput{
+		Bucket: aws.String(r.bucket),
+		Key:    aws.String(path.Join(r.prefix, name)),
+	})
+	return trace.Wrap(err)
+}
+
+func newSFTPDestination(config storage.BackupDestinationSFTP, secret DestinationSecret) (*sftpDestination, error) {
+	if len(secret.PrivateKey) == 0 {
+		return nil, trace.BadParameter("sftp backup destination requires the %v key in the secret",
+			v1.SSHAuthPrivateKey)
+	}
+	signer, err := ssh.ParsePrivateKey(secret.PrivateKey)
+	if err != nil {
+		return nil, trace.BadParameter("failed to parse SSH private key: %v", err)
+	}
+	hostKeyCallback := ssh.InsecureIgnoreHostKey()
+	if config.HostKey != "" {
+		hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(config.HostKey))
+		if err != nil {
+			return nil, trace.BadParameter("failed to parse SSH host key: %v", err)
+		}
+		hostKeyCallback = ssh.FixedHostKey(hostKey)
+	}
+	return &sftpDestination{
+		addr: config.Addr,
+		dir:  config.Path,
+		config: &ssh.ClientConfig{
+			User:            config.User,
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+			HostKeyCallback: hostKeyCallback,
+			Timeout:         defaults.DialTimeout,
+		},
+	}, nil
+}
+
+// sftpDestination stores the backups in the directory on the SFTP server
+type sftpDestination struct {
+	addr   string
+	dir    string
+	config *ssh.ClientConfig
+}
+
+// Upload uploads the archive into the directory on the server.
+// The archive is uploaded under a temporary name first so the
+// incomplete archives are not mistaken for backups
+func (r *sftpDestination) Upload(ctx context.Context, name string, data io.Reader) error {
+	return r.withClient(ctx, func(client *sftpClient) error {
+		if err := client.mkdir(r.dir); err != nil {
+			return trace.Wrap(err, "failed to create directory %v", r.dir)
+		}
+		tmp := path.Join(r.dir, name+partialSuffix)
+		if err := client.upload(tmp, data); err != nil {
+			return trace.Wrap(err)
+		}
+		return trace.Wrap(client.rename(tmp, path.Join(r.dir, name)))
+	})
+}
+
+// Download writes the archive from the directory on the server to w
+func (r *sftpDestination) Download(ctx context.Context, name string, w io.Writer) error {
+	return r.withClient(ctx, func(client *sftpClient) error {
+		return trace.Wrap(client.download(path.Join(r.dir, name), w))
+	})
+}
+
+// List returns the names of the archives in the directory on the server
+func (r *sftpDestination) List(ctx context.Context) (names []string, err error) {
+	err = r.withClient(ctx, func(client *sftpClient) error {
+		names, err = client.list(r.dir)
+		return trace.Wrap(err)
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return names, nil
+}
+
+// Delete deletes the archive from the directory on the server
+func (r *sftpDestination) Delete(ctx context.Context, name string) error {
+	return r.withClient(ctx, func(client *sftpClient) error {
+		return trace.Wrap(client.remove(path.Join(r.dir, name)))
+	})
+}
+
+// withClient connects to the server and invokes fn with the client
+// of the sftp subsystem. The connection is closed when the context
+// is canceled to interrupt the transfer
+func (r *sftpDestination) withClient(ctx context.Context, fn func(*sftpClient) error) error {
+	conn, err := ssh.Dial("tcp", r.addr, r.config)
+	if err != nil {
+		return trace.Wrap(err, "failed to connect to %v", r.addr)
+	}
+	defer conn.Close()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	session, err := conn.NewSession()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer session.Close()
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		return trace.Wrap(err, "failed to start sftp subsystem on %v", r.addr)
+	}
+	client, err := newSFTPClient(stdout, stdin)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(fn(client))
+}
+
+// nfsDestination stores the backups in the directory on the NFS share
+// mounted on the node
+type nfsDestination struct {
+	dir string
+}
+
+// Upload copies the archive into the directory on the share
+func (r *nfsDestination) Upload(ctx context.Context, name string, data io.Reader) error {
+	if err := os.MkdirAll(r.dir, defaults.PrivateDirMask); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	tmp := filepath.Join(r.dir, name+partialSuffix)
+	err := writeFile(tmp, func(w io.Writer) error {
+		_, err := io.Copy(w, data)
+		return trace.ConvertSystemError(err)
+	})
+	if err != nil {
+		os.Remove(tmp)
+		return trace.Wrap(err)
+	}
+	return trace.ConvertSystemError(os.Rename(tmp, filepath.Join(r.dir, name)))
+}
+
+// Download writes the archive from the directory on the share to w
+func (r *nfsDestination) Download(ctx context.Context, name string, w io.Writer) error {
+	f, err := os.Open(filepath.Join(r.dir, name))
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return trace.Wrap(err)
+}
+
+// List returns the names of the archives in the directory on the share
+func (r *nfsDestination) List(context.Context) (names []string, err error) {
+	entries, err := ioutil.ReadDir(r.dir)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	for _, entry := range entries {
+		if entry.Mode().IsRegular() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// Delete deletes the archive from the directory on the share
+func (r *nfsDestination) Delete(ctx context.Context, name string) error {
+	return trace.ConvertSystemError(os.Remove(filepath.Join(r.dir, name)))
+}
+
+const (
+	// EncryptionKeySecretKey is the key of the encryption key in the destination secret
+	EncryptionKeySecretKey = "encryption-key"
+	// AccessKeyIDSecretKey is the key of the S3 access key ID in the destination secret
+	AccessKeyIDSecretKey = "access-key-id"
+	// SecretAccessKeySecretKey is the key of the S3 secret access key in the destination secret
+	SecretAccessKeySecretKey = "secret-access-key"
+)
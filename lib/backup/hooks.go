@@ -0,0 +1,142 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"io"
+
+	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/app/hooks"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+)
+
+// HookRunner runs the backup and restore hooks of the cluster applications
+type HookRunner interface {
+	// Applications returns the cluster applications that define the specified hook
+	Applications(ctx context.Context, hook schema.HookType) ([]loc.Locator, error)
+	// Run runs the hook of the application with the specified host directory
+	// mounted as the hook's backup directory and streams the hook output to w
+	Run(ctx context.Context, app loc.Locator, hook schema.HookType, dir string, w io.Writer) error
+}
+
+// AppHooksConfig describes the configuration of the application hook runner
+type AppHooksConfig struct {
+	// Apps is the cluster application service
+	Apps app.Applications
+	// Cluster is the cluster application package
+	Cluster loc.Locator
+	// NodeName is the name of the Kubernetes node to run the hooks on.
+	// The backup directories are local to this node
+	NodeName string
+	// FieldLogger is used for logging
+	logrus.FieldLogger
+}
+
+// CheckAndSetDefaults validates the configuration and sets defaults
+func (r *AppHooksConfig) CheckAndSetDefaults() error {
+	if r.Apps == nil {
+		return trace.BadParameter("application service is required")
+	}
+	if r.Cluster.IsEmpty() {
+		return trace.BadParameter("cluster application is required")
+	}
+	if r.NodeName == "" {
+		return trace.BadParameter("node name is required")
+	}
+	if r.FieldLogger == nil {
+		r.FieldLogger = logrus.WithField(trace.Component, "backup:hooks")
+	}
+	return nil
+}
+
+// NewAppHooks returns a new runner of the application hooks
+// as Kubernetes jobs scheduled on the configured node
+func NewAppHooks(config AppHooksConfig) (*AppHooks, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &AppHooks{AppHooksConfig: config}, nil
+}
+
+// AppHooks runs the backup and restore hooks of the cluster application
+// and of the applications it depends on
+type AppHooks struct {
+	AppHooksConfig
+}
+
+// Applications returns the cluster application and its application
+// dependencies that define the specified hook
+func (r *AppHooks) Applications(ctx context.Context, hook schema.HookType) (result []loc.Locator, err error) {
+	cluster, err := r.Apps.GetApp(r.Cluster)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, locator := range append(cluster.Manifest.Dependencies.GetApps(), r.Cluster) {
+		application, err := r.Apps.GetApp(locator)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if application.Manifest.HasHook(hook) {
+			result = append(result, locator)
+		}
+	}
+	return result, nil
+}
+
+// Run runs the hook of the application with the specified directory
+// mounted as the hook's backup directory
+func (r *AppHooks) Run(ctx context.Context, locator loc.Locator, hook schema.HookType, dir string, w io.Writer) error {
+	r.Infof("Run %v hook of %v.", hook, locator)
+	req := app.HookRunRequest{
+		Application: locator,
+		Hook:        hook,
+		Volumes: []v1.Volume{{
+			Name: hooks.VolumeBackup,
+			VolumeSource: v1.VolumeSource{
+				HostPath: &v1.HostPathVolumeSource{
+					Path: dir,
+				},
+			},
+		}},
+		VolumeMounts: []v1.VolumeMount{{
+			Name:      hooks.VolumeBackup,
+			MountPath: hooks.ContainerBackupDir,
+		}},
+		NodeSelector: map[string]string{
+			defaults.KubernetesHostnameLabel: r.NodeName,
+		},
+	}
+	ref, err := app.StreamAppHook(ctx, r.Apps, req, utils.NopWriteCloser(w))
+	if ref != nil {
+		deleteErr := r.Apps.DeleteAppHookJob(ctx, app.DeleteAppHookJobRequest{
+			HookRef: *ref,
+			Cascade: true,
+		})
+		if deleteErr != nil {
+			r.WithError(deleteErr).Warnf("Failed to delete hook job %v.", ref)
+		}
+	}
+	return trace.Wrap(err)
+}
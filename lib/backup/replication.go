@@ -0,0 +1,282 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gravitational/gravity/lib/app/docker"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"github.com/sirupsen/logrus"
+)
+
+// ReplicatorConfig describes the configuration of the replicator
+type ReplicatorConfig struct {
+	// Backend is the backend of the standby cluster. It stores
+	// the replication configuration and status
+	Backend storage.Backend
+	// ClusterName is the name of the standby cluster which
+	// must match the name of the primary cluster
+	ClusterName string
+	// NodeName is the name of the node the backups are replicated to
+	NodeName string
+	// ListImages optionally lists the images in the registry
+	// of the standby cluster
+	ListImages func(context.Context) ([]docker.LocalImage, error)
+	// Destinations returns the off-site destination to replicate the backups from
+	Destinations DestinationFactory
+	// Interval is how often the replication is checked
+	Interval time.Duration
+	// Timeout is the maximum duration of a single replication
+	Timeout time.Duration
+	// Clock is used to determine whether the replication is due
+	Clock clockwork.Clock
+	// FieldLogger is used for logging
+	logrus.FieldLogger
+}
+
+// CheckAndSetDefaults validates the configuration and sets defaults
+func (r *ReplicatorConfig) CheckAndSetDefaults() error {
+	if r.Backend == nil {
+		return trace.BadParameter("backend is required")
+	}
+	if r.ClusterName == "" {
+		return trace.BadParameter("cluster name is required")
+	}
+	if r.Destinations == nil {
+		return trace.BadParameter("destination factory is required")
+	}
+	if r.Interval == 0 {
+		r.Interval = defaults.ReplicationCheckInterval
+	}
+	if r.Timeout == 0 {
+		r.Timeout = defaults.BackupTimeout
+	}
+	if r.Clock == nil {
+		r.Clock = clockwork.NewRealClock()
+	}
+	if r.FieldLogger == nil {
+		r.FieldLogger = logrus.WithField(trace.Component, "replication")
+	}
+	return nil
+}
+
+// NewReplicator returns a new replicator that keeps the standby
+// cluster up to date with the backups of the primary cluster
+func NewReplicator(config ReplicatorConfig) (*Replicator, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &Replicator{ReplicatorConfig: config}, nil
+}
+
+// Replicator runs on the standby cluster. It periodically downloads the
+// latest backup the primary cluster has uploaded to its off-site destination,
+// verifies it and keeps it in the local directory, so the standby cluster
+// can be promoted by restoring from it with gravity cluster promote.
+//
+// The registry of the standby cluster is compared with the registry
+// metadata of the backup and the images the standby cluster is missing
+// are recorded in the replication status
+type Replicator struct {
+	ReplicatorConfig
+}
+
+// Run replicates the backups according to the replication
+// until the context is canceled
+func (r *Replicator) Run(ctx context.Context) {
+	r.Info("Starting replicator.")
+	ticker := r.Clock.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.Chan():
+			if err := r.replicateIfDue(ctx); err != nil {
+				r.WithError(err).Warn("Failed to replicate backup.")
+			}
+		case <-ctx.Done():
+			r.Info("Stopping replicator.")
+			return
+		}
+	}
+}
+
+func (r *Replicator) replicateIfDue(ctx context.Context) error {
+	replication, err := r.Backend.GetReplication(r.ClusterName)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil
+		}
+		return trace.Wrap(err)
+	}
+	prev, err := r.Backend.GetReplicationStatus(r.ClusterName)
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	if prev != nil && r.Clock.Now().UTC().Before(prev.LastAttempt.Add(replication.GetInterval())) {
+		return nil
+	}
+	return trace.Wrap(r.Replicate(ctx, replication))
+}
+
+// Replicate replicates the latest backup of the primary cluster,
+// removes the replicated backups beyond the number to retain
+// and records the replication status
+func (r *Replicator) Replicate(ctx context.Context, replication storage.Replication) error {
+	prev, err := r.Backend.GetReplicationStatus(r.ClusterName)
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	if prev == nil {
+		prev = &storage.ReplicationStatus{}
+	}
+	status := *prev
+	status.LastAttempt = r.Clock.Now().UTC()
+	backup, replicateErr := r.replicate(ctx, replication)
+	if replicateErr != nil {
+		status.Message = trace.UserMessage(replicateErr)
+	} else {
+		name := filepath.Base(backup.Dir)
+		if name != status.Backup {
+			r.Infof("Replicated backup %v.", name)
+			status.LastReplicated = r.Clock.Now().UTC()
+		}
+		status.Backup = name
+		status.Taken, _ = time.Parse(backupTimeFormat, strings.TrimPrefix(name, backupPrefix))
+		status.Path = backup.Dir
+		status.Node = r.NodeName
+		status.Message = ""
+		status.MissingImages = nil
+		if r.ListImages != nil {
+			status.MissingImages, err = r.missingImages(ctx, backup.Images)
+			if err != nil {
+				r.WithError(err).Warn("Failed to compare registry images.")
+			}
+		}
+	}
+	if err := r.Backend.UpsertReplicationStatus(r.ClusterName, status); err != nil {
+		return trace.NewAggregate(replicateErr, err)
+	}
+	return trace.Wrap(replicateErr)
+}
+
+// replicate downloads the latest backup from the source destination
+// unless it has already been replicated and returns the replicated backup
+func (r *Replicator) replicate(ctx context.Context, replication storage.Replication) (*Backup, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+	source := replication.GetSource()
+	destination, key, err := r.Destinations(source)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	names, err := destination.List(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to list backups in %v", source)
+	}
+	archive := latestArchive(names)
+	if archive == "" {
+		return nil, trace.NotFound("no backups found in %v", source)
+	}
+	dir := replication.GetDirectory()
+	path := filepath.Join(dir, strings.TrimSuffix(archive, ArchiveSuffix))
+	if _, err := os.Stat(path); err == nil {
+		return Open(path)
+	}
+	r.Infof("Replicate backup %v from %v.", archive, source)
+	tmp := path + partialSuffix
+	if err := os.RemoveAll(tmp); err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	if err := os.MkdirAll(tmp, defaults.PrivateDirMask); err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	defer os.RemoveAll(tmp)
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(destination.Download(ctx, archive, writer))
+	}()
+	err = ExtractArchive(reader, tmp, key)
+	// unblock the download if the archive has not been read completely
+	reader.Close()
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to download backup %v from %v", archive, source)
+	}
+	backup, err := Open(tmp)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if backup.ClusterName != r.ClusterName {
+		return nil, trace.BadParameter("backup %v has been taken on cluster %v, "+
+			"the standby cluster must have the same name as the primary cluster %v",
+			archive, backup.ClusterName, r.ClusterName)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	backup.Dir = path
+	if err := prune(dir, replication.GetRetain()); err != nil {
+		r.WithError(err).Warn("Failed to remove old replicated backups.")
+	}
+	return backup, nil
+}
+
+// missingImages returns the images of the primary cluster
+// that are not in the registry of the standby cluster
+func (r *Replicator) missingImages(ctx context.Context, images []RegistryImage) (missing []string, err error) {
+	local, err := r.ListImages(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	digests := make(map[string]string, len(local))
+	for _, image := range local {
+		digests[image.String()] = image.Digest.String()
+	}
+	for _, image := range images {
+		if digest, ok := digests[image.Image]; !ok || digest != image.Digest {
+			missing = append(missing, image.Image)
+		}
+	}
+	return missing, nil
+}
+
+// latestArchive returns the name of the most recent backup archive
+// among the specified names or an empty string if there is none
+func latestArchive(names []string) string {
+	var archives []string
+	for _, name := range names {
+		if strings.HasPrefix(name, backupPrefix) && strings.HasSuffix(name, ArchiveSuffix) {
+			archives = append(archives, name)
+		}
+	}
+	if len(archives) == 0 {
+		return ""
+	}
+	// backup names sort in the order they were taken
+	sort.Strings(archives)
+	return archives[len(archives)-1]
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gravitational/gravity/lib/app/docker"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/keyval"
+
+	"github.com/jonboulle/clockwork"
+	. "gopkg.in/check.v1"
+)
+
+func (s *RestoreSuite) TestReplicatesLatestBackup(c *C) {
+	remote, local := c.MkDir(), c.MkDir()
+	s.uploadBackup(c, remote, "backup-20190601T020000Z")
+	replicator, backend, clock := s.newReplicator(c, "example.com")
+	defer backend.Close()
+	upsertReplication(c, backend, "example.com", remote, local)
+
+	c.Assert(replicator.replicateIfDue(context.TODO()), IsNil)
+	c.Assert(listDir(c, local), DeepEquals, []string{"backup-20190601T020000Z"})
+	status, err := backend.GetReplicationStatus("example.com")
+	c.Assert(err, IsNil)
+	c.Assert(status.IsHealthy(), Equals, true, Commentf("%v", status))
+	c.Assert(status.Backup, Equals, "backup-20190601T020000Z")
+	c.Assert(status.Path, Equals, filepath.Join(local, "backup-20190601T020000Z"))
+	c.Assert(status.Node, Equals, "node-1")
+	c.Assert(status.Taken, DeepEquals, time.Date(2019, time.June, 1, 2, 0, 0, 0, time.UTC))
+	c.Assert(status.Lag(clock.Now()), Equals, time.Hour)
+	_, err = Open(status.Path)
+	c.Assert(err, IsNil)
+
+	// the replication is not repeated until the interval elapses
+	s.uploadBackup(c, remote, "backup-20190601T030000Z")
+	clock.Advance(time.Minute)
+	c.Assert(replicator.replicateIfDue(context.TODO()), IsNil)
+	c.Assert(listDir(c, local), HasLen, 1)
+
+	clock.Advance(15 * time.Minute)
+	c.Assert(replicator.replicateIfDue(context.TODO()), IsNil)
+	s.uploadBackup(c, remote, "backup-20190601T040000Z")
+	clock.Advance(15 * time.Minute)
+	c.Assert(replicator.replicateIfDue(context.TODO()), IsNil)
+	// only the last replicated backups are retained
+	c.Assert(listDir(c, local), DeepEquals, []string{
+		"backup-20190601T030000Z",
+		"backup-20190601T040000Z",
+	})
+	status, err = backend.GetReplicationStatus("example.com")
+	c.Assert(err, IsNil)
+	c.Assert(status.Backup, Equals, "backup-20190601T040000Z")
+	c.Assert(status.LastReplicated, DeepEquals, clock.Now().UTC())
+}
+
+func (s *RestoreSuite) TestRecordsMissingImages(c *C) {
+	remote, local := c.MkDir(), c.MkDir()
+	s.uploadBackup(c, remote, "backup-20190601T020000Z")
+	replicator, backend, _ := s.newReplicator(c, "example.com")
+	defer backend.Close()
+	replicator.ListImages = func(context.Context) ([]docker.LocalImage, error) {
+		return []docker.LocalImage{
+			{TagSpec: docker.TagSpec{Name: "nginx", Version: "1.17"}, Digest: "sha256:bbbb"},
+		}, nil
+	}
+	upsertReplication(c, backend, "example.com", remote, local)
+
+	c.Assert(replicator.replicateIfDue(context.TODO()), IsNil)
+	status, err := backend.GetReplicationStatus("example.com")
+	c.Assert(err, IsNil)
+	c.Assert(status.IsHealthy(), Equals, false)
+	c.Assert(status.Message, Equals, "")
+	c.Assert(status.MissingImages, DeepEquals, []string{"nginx:1.17"})
+}
+
+func (s *RestoreSuite) TestRejectsBackupOfAnotherCluster(c *C) {
+	remote, local := c.MkDir(), c.MkDir()
+	s.uploadBackup(c, remote, "backup-20190601T020000Z")
+	replicator, backend, _ := s.newReplicator(c, "standby.example.com")
+	defer backend.Close()
+	upsertReplication(c, backend, "standby.example.com", remote, local)
+
+	err := replicator.replicateIfDue(context.TODO())
+	c.Assert(err, ErrorMatches, ".*same name as the primary cluster.*")
+	c.Assert(listDir(c, local), HasLen, 0)
+	status, err := backend.GetReplicationStatus("standby.example.com")
+	c.Assert(err, IsNil)
+	c.Assert(status.Backup, Equals, "")
+	c.Assert(status.Message, Matches, ".*same name as the primary cluster.*")
+}
+
+func (s *RestoreSuite) newReplicator(c *C, clusterName string) (*Replicator, storage.Backend, clockwork.FakeClock) {
+	backend, err := keyval.NewBolt(keyval.BoltConfig{Path: filepath.Join(c.MkDir(), "bolt.db")})
+	c.Assert(err, IsNil)
+	clock := clockwork.NewFakeClockAt(time.Date(2019, time.June, 1, 3, 0, 0, 0, time.UTC))
+	replicator, err := NewReplicator(ReplicatorConfig{
+		Backend:     backend,
+		ClusterName: clusterName,
+		NodeName:    "node-1",
+		ListImages: func(context.Context) ([]docker.LocalImage, error) {
+			return []docker.LocalImage{
+				{TagSpec: docker.TagSpec{Name: "nginx", Version: "1.17"}, Digest: "sha256:aaaa"},
+			}, nil
+		},
+		Destinations: func(config storage.BackupDestination) (Destination, []byte, error) {
+			destination, err := NewDestination(config, DestinationSecret{})
+			return destination, []byte("key"), err
+		},
+		Clock: clock,
+	})
+	c.Assert(err, IsNil)
+	return replicator, backend, clock
+}
+
+// uploadBackup uploads the backup of the suite
+// to the specified directory under the specified name
+func (s *RestoreSuite) uploadBackup(c *C, remote, name string) {
+	f, err := os.Create(filepath.Join(remote, name+ArchiveSuffix))
+	c.Assert(err, IsNil)
+	defer f.Close()
+	c.Assert(WriteArchive(f, s.dir, []byte("key")), IsNil)
+}
+
+func upsertReplication(c *C, backend storage.Backend, clusterName, remote, local string) {
+	c.Assert(backend.UpsertReplication(clusterName, storage.NewReplication(
+		storage.ReplicationSpecV2{
+			Source: storage.BackupDestination{
+				Type:   storage.BackupDestinationNFSType,
+				Secret: "backups",
+				NFS:    &storage.BackupDestinationNFS{Path: remote},
+			},
+			Directory: local,
+		})), IsNil)
+}
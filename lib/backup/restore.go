@@ -0,0 +1,408 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/boltdb/bolt"
+	"github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+	"github.com/gravitational/trace"
+)
+
+// Backup describes a cluster backup written by the scheduler
+type Backup struct {
+	// Dir is the backup directory
+	Dir string
+	// ClusterName is the name of the backed up cluster
+	ClusterName string
+	// Servers lists the nodes of the backed up cluster
+	Servers storage.Servers
+	// Images lists the images that were in the cluster registry,
+	// empty if the backup does not include the registry metadata
+	Images []RegistryImage
+	// Apps lists the names of the applications whose data
+	// has been backed up by their backup hooks
+	Apps []string
+}
+
+// Open verifies the integrity of the backup in the specified directory
+// and returns its description
+func Open(dir string) (*Backup, error) {
+	backup := &Backup{Dir: dir}
+	if err := verifySnapshot(backup.path(EtcdSnapshotFile)); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := backup.readState(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	data, err := ioutil.ReadFile(backup.path(RegistryFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, trace.ConvertSystemError(err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &backup.Images); err != nil {
+			return nil, trace.BadParameter("invalid registry metadata in %v: %v", dir, err)
+		}
+	}
+	entries, err := ioutil.ReadDir(backup.path(AppsDir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, trace.ConvertSystemError(err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			backup.Apps = append(backup.Apps, entry.Name())
+		}
+	}
+	return backup, nil
+}
+
+// AppDir returns the directory with the data of the specified application
+// or an error if the backup does not include the application data
+func (r *Backup) AppDir(name string) (string, error) {
+	if !utils.StringInSlice(r.Apps, name) {
+		return "", trace.NotFound("backup %v does not include data of application %v", r.Dir, name)
+	}
+	return filepath.Join(r.Dir, AppsDir, name), nil
+}
+
+// KV stores the restored Kubernetes resources
+type KV interface {
+	// Put puts a key-value pair into etcd
+	Put(ctx context.Context, key, val string, opts ...clientv3.OpOption) (*clientv3.PutResponse, error)
+}
+
+// RestoreKubernetes writes the Kubernetes resources from the etcd snapshot
+// into the cluster etcd, replacing the existing resources with the same keys.
+//
+// The resources bound to the nodes of the backed up cluster, like nodes, pods
+// and endpoints, are not restored as the controllers recreate them on the
+// nodes of the new cluster.
+// Returns the number of restored resources
+func (r *Backup) RestoreKubernetes(ctx context.Context, kv KV) (count int, err error) {
+	resources, err := readSnapshot(r.path(EtcdSnapshotFile))
+	if err != nil {
+		return 0, trace.Wrap(err)
+	}
+	for _, resource := range resources {
+		if !isRestoredResource(string(resource.Key)) {
+			continue
+		}
+		if _, err := kv.Put(ctx, string(resource.Key), string(resource.Value)); err != nil {
+			return count, trace.Wrap(err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// RestoreState imports the records of the cluster state archive that describe
+// the cluster configuration and its users into the specified backend.
+//
+// The records specific to the backed up cluster installation, like its
+// packages, operations, certificate authorities and nodes, are not restored
+// and the new cluster keeps its own.
+// Returns the number of restored records
+func (r *Backup) RestoreState(ctx context.Context, backend storage.Backend) (count int, err error) {
+	importer, ok := backend.(storage.StateExporter)
+	if !ok {
+		return 0, trace.BadParameter("backend %T does not support state import", backend)
+	}
+	f, err := os.Open(r.path(StateArchiveFile))
+	if err != nil {
+		return 0, trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	_, err = storage.ScanStateArchive(f, func(item storage.StateItem) error {
+		if !isRestoredState(item.Key, r.ClusterName) {
+			return nil
+		}
+		count++
+		return trace.Wrap(importer.ImportState(ctx, item))
+	})
+	return count, trace.Wrap(err)
+}
+
+// Secrets returns the secrets restored by RestoreKubernetes
+// as names in the namespace/name format
+func (r *Backup) Secrets() (names []string, err error) {
+	resources, err := readSnapshot(r.path(EtcdSnapshotFile))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, resource := range resources {
+		key := string(resource.Key)
+		if isRestoredResource(key) && strings.HasPrefix(key, secretsPrefix) {
+			names = append(names, strings.TrimPrefix(key, secretsPrefix))
+		}
+	}
+	return names, nil
+}
+
+// Workers returns the worker nodes of the backed up cluster
+// that are not among the specified nodes of the restored cluster
+func (r *Backup) Workers(servers storage.Servers) (workers storage.Servers) {
+	for _, server := range r.Servers {
+		if server.IsMaster() {
+			continue
+		}
+		if servers.FindByIP(server.AdvertiseIP) != nil {
+			continue
+		}
+		workers = append(workers, server)
+	}
+	return workers
+}
+
+// JoinCommand returns the commands to run on the worker node of the backed up
+// cluster to remove it from that cluster and join the restored cluster
+func JoinCommand(server storage.Server, masterAddr, token string) string {
+	return fmt.Sprintf("gravity leave --force && gravity join %v --token=%v --role=%v",
+		masterAddr, token, server.Role)
+}
+
+// JoinToken issues a short-lived token for the specified worker nodes of
+// the backed up cluster to join the restored cluster with.
+// The token is limited to the roles and the number of the workers
+func JoinToken(ctx context.Context, operator ops.Operator, key ops.SiteKey, workers storage.Servers) (string, error) {
+	var roles []string
+	for _, worker := range workers {
+		if !utils.StringInSlice(roles, worker.Role) {
+			roles = append(roles, worker.Role)
+		}
+	}
+	token, err := operator.CreateJoinToken(ctx, ops.CreateJoinTokenRequest{
+		SiteKey: key,
+		TTL:     defaults.InternalJoinTokenTTL,
+		Roles:   roles,
+		MaxUses: len(workers),
+	})
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return token.Token, nil
+}
+
+func (r *Backup) path(name string) string {
+	return filepath.Join(r.Dir, name)
+}
+
+// isRestoredResource returns true if the Kubernetes resource with
+// the specified etcd key should be restored
+func isRestoredResource(key string) bool {
+	if !strings.HasPrefix(key, kubernetesPrefix) {
+		return false
+	}
+	for _, prefix := range skippedResources {
+		if strings.HasPrefix(key, kubernetesPrefix+prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// isRestoredState returns true if the cluster state record with
+// the specified key should be restored
+func isRestoredState(key []string, clusterName string) bool {
+	if len(key) == 0 {
+		return false
+	}
+	switch key[0] {
+	case "users", "roles", "connectors", "authpreference", "imagetrustpolicy":
+		return true
+	case "auth":
+		return len(key) > 1 && key[1] == "connectors"
+	case "sites":
+		if len(key) < 3 || key[1] != clusterName {
+			return false
+		}
+		switch key[2] {
+		case "nodepools", "operationwebhooks", "healthchecks", "approvalpolicy",
+			"catalogsync", "backupschedule", "releases", "events":
+			return true
+		}
+	}
+	return false
+}
+
+// readState reads the name and the nodes of the local cluster
+// from the state archive of the backup
+func (r *Backup) readState() error {
+	path := r.path(StateArchiveFile)
+	f, err := os.Open(path)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	sites := make(map[string][]byte)
+	_, err = storage.ScanStateArchive(f, func(item storage.StateItem) error {
+		switch {
+		case len(item.Key) == 1 && item.Key[0] == "localcluster":
+			return trace.Wrap(json.Unmarshal(item.Value, &r.ClusterName))
+		case len(item.Key) == 3 && item.Key[0] == "sites" && item.Key[2] == "val":
+			sites[item.Key[1]] = item.Value
+		}
+		return nil
+	})
+	if err != nil {
+		return trace.Wrap(err, "invalid cluster state archive %v", path)
+	}
+	if r.ClusterName == "" {
+		return trace.BadParameter("cluster state archive %v does not record the local cluster", path)
+	}
+	data, ok := sites[r.ClusterName]
+	if !ok {
+		return trace.BadParameter("cluster state archive %v does not include cluster %v", path, r.ClusterName)
+	}
+	var site storage.Site
+	if err := json.Unmarshal(data, &site); err != nil {
+		return trace.Wrap(err)
+	}
+	r.Servers = site.ClusterState.Servers
+	return nil
+}
+
+// verifySnapshot verifies the checksum of the etcd snapshot at path.
+// The snapshots streamed from etcd end with the sha256 checksum of the database
+func verifySnapshot(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	if fi.Size()%512 != sha256.Size {
+		// the snapshot has been copied from the database file
+		// and has no checksum
+		return nil
+	}
+	hash := sha256.New()
+	if _, err := io.CopyN(hash, f, fi.Size()-sha256.Size); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	expected := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(f, expected); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	if !bytes.Equal(hash.Sum(nil), expected) {
+		return trace.BadParameter("etcd snapshot %v is corrupted: checksum mismatch", path)
+	}
+	return nil
+}
+
+// readSnapshot returns the latest revisions of the keys stored
+// in the etcd snapshot at path in the order they were last modified
+func readSnapshot(path string) ([]mvccpb.KeyValue, error) {
+	latest := make(map[string]mvccpb.KeyValue)
+	err := viewSnapshot(path, func(bucket *bolt.Bucket) error {
+		return bucket.ForEach(func(rev, value []byte) error {
+			var kv mvccpb.KeyValue
+			if err := kv.Unmarshal(value); err != nil {
+				return trace.Wrap(err)
+			}
+			key := string(kv.Key)
+			if isTombstone(rev) {
+				delete(latest, key)
+				return nil
+			}
+			latest[key] = kv
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	result := make([]mvccpb.KeyValue, 0, len(latest))
+	for _, kv := range latest {
+		result = append(result, kv)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ModRevision < result[j].ModRevision
+	})
+	return result, nil
+}
+
+func copySnapshot(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	size := fi.Size()
+	if size%512 == sha256.Size {
+		size -= sha256.Size
+	}
+	_, err = io.CopyN(w, f, size)
+	return trace.ConvertSystemError(err)
+}
+
+// isTombstone returns true if the revision key in the etcd key bucket
+// marks the deletion of the key
+func isTombstone(rev []byte) bool {
+	return len(rev) == revisionSize+1 && rev[revisionSize] == 't'
+}
+
+var (
+	// keyBucket is the etcd bucket with the key revisions
+	keyBucket = []byte("key")
+
+	// skippedResources lists the Kubernetes resources bound
+	// to the nodes of the backed up cluster
+	skippedResources = []string{
+		"minions/",
+		"pods/",
+		"events/",
+		"leases/",
+		"masterleases/",
+		"services/endpoints/",
+		"ranges/",
+		"csinodes/",
+		"volumeattachments/",
+	}
+)
+
+const (
+	// kubernetesPrefix is the etcd prefix of the Kubernetes resources
+	kubernetesPrefix = "/registry/"
+	// secretsPrefix is the etcd prefix of the Kubernetes secrets
+	secretsPrefix = kubernetesPrefix + "secrets/"
+	// revisionSize is the size of the etcd revision key
+	revisionSize = 17
+)
@@ -0,0 +1,217 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/keyval"
+
+	"github.com/boltdb/bolt"
+	"github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+type RestoreSuite struct {
+	dir string
+}
+
+var _ = Suite(&RestoreSuite{})
+
+func (s *RestoreSuite) SetUpTest(c *C) {
+	s.dir = c.MkDir()
+	s.writeSnapshot(c, []snapshotRevision{
+		{key: "/registry/deployments/default/nginx", value: "v1"},
+		{key: "/registry/pods/default/nginx-1", value: "pod"},
+		{key: "/registry/configmaps/default/removed", value: "config"},
+		{key: "/registry/deployments/default/nginx", value: "v2"},
+		{key: "/registry/configmaps/default/removed", tombstone: true},
+		{key: "/gravity/local/sites/example.com/val", value: "cluster"},
+		{key: "/registry/secrets/default/token", value: "secret"},
+	})
+	backend, err := keyval.NewBolt(keyval.BoltConfig{Path: filepath.Join(c.MkDir(), "bolt.db")})
+	c.Assert(err, IsNil)
+	defer backend.Close()
+	c.Assert(backend.UpsertLocalClusterName("example.com"), IsNil)
+	_, err = backend.CreateSite(storage.Site{
+		AccountID: "0",
+		Domain:    "example.com",
+		Created:   time.Now().UTC(),
+		ClusterState: storage.ClusterState{
+			Servers: storage.Servers{
+				{Hostname: "node-1", AdvertiseIP: "10.0.0.1", ClusterRole: "master"},
+				{Hostname: "node-2", AdvertiseIP: "10.0.0.2", ClusterRole: "node"},
+			},
+		},
+	})
+	c.Assert(err, IsNil)
+	_, err = backend.CreateRepository(storage.NewRepository("example.com"))
+	c.Assert(err, IsNil)
+	c.Assert(backend.UpsertBackupSchedule("example.com", storage.NewBackupSchedule(
+		storage.BackupScheduleSpecV2{Schedule: "0 2 * * *"})), IsNil)
+	f, err := os.Create(filepath.Join(s.dir, StateArchiveFile))
+	c.Assert(err, IsNil)
+	defer f.Close()
+	_, err = storage.WriteStateArchive(context.TODO(), backend, f)
+	c.Assert(err, IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(s.dir, RegistryFile),
+		[]byte(`[{"image": "nginx:1.17", "digest": "sha256:aaaa"}]`), 0600), IsNil)
+	c.Assert(os.MkdirAll(filepath.Join(s.dir, AppsDir, "app"), 0700), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(s.dir, AppsDir, "app.log"), nil, 0600), IsNil)
+}
+
+func (s *RestoreSuite) TestOpensBackup(c *C) {
+	backup, err := Open(s.dir)
+	c.Assert(err, IsNil)
+	c.Assert(backup.ClusterName, Equals, "example.com")
+	c.Assert(backup.Servers, HasLen, 2)
+	workers := backup.Workers(storage.Servers{
+		{Hostname: "node-3", AdvertiseIP: "10.0.0.3", ClusterRole: "master"},
+	})
+	c.Assert(workers, HasLen, 1)
+	c.Assert(workers[0].Hostname, Equals, "node-2")
+	c.Assert(backup.Images, DeepEquals, []RegistryImage{
+		{Image: "nginx:1.17", Digest: "sha256:aaaa"},
+	})
+	c.Assert(backup.Apps, DeepEquals, []string{"app"})
+	dir, err := backup.AppDir("app")
+	c.Assert(err, IsNil)
+	c.Assert(dir, Equals, filepath.Join(s.dir, AppsDir, "app"))
+	_, err = backup.AppDir("cluster")
+	c.Assert(trace.IsNotFound(err), Equals, true)
+}
+
+func (s *RestoreSuite) TestDetectsCorruptedSnapshot(c *C) {
+	path := filepath.Join(s.dir, EtcdSnapshotFile)
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	data[0] ^= 0xff
+	c.Assert(ioutil.WriteFile(path, data, 0600), IsNil)
+	_, err = Open(s.dir)
+	c.Assert(trace.IsBadParameter(err), Equals, true, Commentf("%v", err))
+}
+
+func (s *RestoreSuite) TestReadsSnapshotStatus(c *C) {
+	status, err := GetSnapshotStatus(filepath.Join(s.dir, EtcdSnapshotFile))
+	c.Assert(err, IsNil)
+	c.Assert(*status, DeepEquals, SnapshotStatus{
+		Revision:    7,
+		Keys:        4,
+		HasChecksum: true,
+	})
+}
+
+func (s *RestoreSuite) TestRestoresKubernetesResources(c *C) {
+	backup, err := Open(s.dir)
+	c.Assert(err, IsNil)
+	kv := &fakeKV{}
+	count, err := backup.RestoreKubernetes(context.TODO(), kv)
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, 2)
+	c.Assert(kv.puts, DeepEquals, []string{
+		"/registry/deployments/default/nginx=v2",
+		"/registry/secrets/default/token=secret",
+	})
+	secrets, err := backup.Secrets()
+	c.Assert(err, IsNil)
+	c.Assert(secrets, DeepEquals, []string{"default/token"})
+}
+
+func (s *RestoreSuite) TestRestoresClusterState(c *C) {
+	backup, err := Open(s.dir)
+	c.Assert(err, IsNil)
+	backend, err := keyval.NewBolt(keyval.BoltConfig{Path: filepath.Join(c.MkDir(), "bolt.db")})
+	c.Assert(err, IsNil)
+	defer backend.Close()
+	count, err := backup.RestoreState(context.TODO(), backend)
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, 1)
+	_, err = backend.GetBackupSchedule("example.com")
+	c.Assert(err, IsNil)
+	_, err = backend.GetRepository("example.com")
+	c.Assert(trace.IsNotFound(err), Equals, true)
+	_, err = backend.GetLocalClusterName()
+	c.Assert(trace.IsNotFound(err), Equals, true)
+	_, err = backend.GetSite("example.com")
+	c.Assert(trace.IsNotFound(err), Equals, true)
+}
+
+// writeSnapshot writes the etcd snapshot with the specified revisions
+// followed by the checksum into the backup directory
+func (s *RestoreSuite) writeSnapshot(c *C, revisions []snapshotRevision) {
+	path := filepath.Join(c.MkDir(), "etcd.db")
+	db, err := bolt.Open(path, 0600, nil)
+	c.Assert(err, IsNil)
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucket(keyBucket)
+		if err != nil {
+			return err
+		}
+		for i, revision := range revisions {
+			rev := make([]byte, revisionSize, revisionSize+1)
+			binary.BigEndian.PutUint64(rev, uint64(i+1))
+			rev[8] = '_'
+			if revision.tombstone {
+				rev = append(rev, 't')
+			}
+			kv := mvccpb.KeyValue{
+				Key:         []byte(revision.key),
+				Value:       []byte(revision.value),
+				ModRevision: int64(i + 1),
+			}
+			value, err := kv.Marshal()
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put(rev, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(db.Close(), IsNil)
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	checksum := sha256.Sum256(data)
+	data = append(data, checksum[:]...)
+	c.Assert(ioutil.WriteFile(filepath.Join(s.dir, EtcdSnapshotFile), data, 0600), IsNil)
+}
+
+type snapshotRevision struct {
+	key       string
+	value     string
+	tombstone bool
+}
+
+type fakeKV struct {
+	puts []string
+}
+
+func (r *fakeKV) Put(ctx context.Context, key, val string, opts ...clientv3.OpOption) (*clientv3.PutResponse, error) {
+	r.puts = append(r.puts, key+"="+val)
+	return &clientv3.PutResponse{}, nil
+}
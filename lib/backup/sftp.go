@@ -0,0 +1,499 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/gravitational/trace"
+)
+
+// sftpClient implements the subset of the SFTP version 3 protocol
+// required to upload, download, list and remove the backups.
+//
+// See https://tools.ietf.org/html/draft-ietf-secsh-filexfer-02
+type sftpClient struct {
+	r io.Reader
+	w io.Writer
+	// id is the ID of the last request
+	id uint32
+}
+
+// newSFTPClient returns a new SFTP client that sends the requests to w
+// and reads the responses from r, usually the streams of the sftp subsystem
+func newSFTPClient(r io.Reader, w io.Writer) (*sftpClient, error) {
+	client := &sftpClient{r: r, w: w}
+	err := client.send(sftpPacket(sshFxpInit).uint32(sftpVersion))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	typ, payload, err := client.recv()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if typ != sshFxpVersion {
+		return nil, trace.BadParameter("unexpected SFTP packet %v, expected version", typ)
+	}
+	version, _, err := readUint32(payload)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if version < sftpVersion {
+		return nil, trace.BadParameter("unsupported SFTP version %v", version)
+	}
+	return client, nil
+}
+
+// upload writes the data read from r to the file at path
+func (r *sftpClient) upload(path string, data io.Reader) error {
+	handle, err := r.open(r.request(sshFxpOpen).string(path).
+		uint32(sshFxfWrite | sshFxfCreat | sshFxfTrunc).uint32(0))
+	if err != nil {
+		return trace.Wrap(err, "failed to create %v", path)
+	}
+	writeErr := r.write(handle, data)
+	closeErr := r.close(handle)
+	return trace.NewAggregate(writeErr, closeErr)
+}
+
+// download writes the contents of the file at path to w
+func (r *sftpClient) download(path string, w io.Writer) error {
+	handle, err := r.open(r.request(sshFxpOpen).string(path).
+		uint32(sshFxfRead).uint32(0))
+	if err != nil {
+		return trace.Wrap(err, "failed to open %v", path)
+	}
+	readErr := r.read(handle, w)
+	closeErr := r.close(handle)
+	return trace.NewAggregate(readErr, closeErr)
+}
+
+// read writes the contents of the file with the specified handle to w.
+// The file is read sequentially, one chunk per request
+func (r *sftpClient) read(handle string, w io.Writer) error {
+	var offset uint64
+	for {
+		packet := r.request(sshFxpRead).string(handle).
+			uint64(offset).uint32(sftpChunkSize)
+		if err := r.send(packet); err != nil {
+			return trace.Wrap(err)
+		}
+		typ, payload, err := r.recv()
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		switch typ {
+		case sshFxpStatus:
+			err := statusError(payload)
+			if trace.IsEOF(err) {
+				return nil
+			}
+			// the status can not be OK in response to read
+			if err == nil {
+				err = trace.BadParameter("unexpected SFTP status, expected data")
+			}
+			return trace.Wrap(err)
+		case sshFxpData:
+			_, payload, err := readUint32(payload)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			data, _, err := readString(payload)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			if _, err := io.WriteString(w, data); err != nil {
+				return trace.Wrap(err)
+			}
+			offset += uint64(len(data))
+		default:
+			return trace.BadParameter("unexpected SFTP packet %v, expected data", typ)
+		}
+	}
+}
+
+// write writes the data from r to the file with the specified handle.
+// Up to sftpMaxPending requests are sent without waiting for the responses
+func (r *sftpClient) write(handle string, data io.Reader) error {
+	buf := make([]byte, sftpChunkSize)
+	var offset uint64
+	var pending int
+	for {
+		n, err := io.ReadFull(data, buf)
+		if n > 0 {
+			packet := r.request(sshFxpWrite).string(handle).
+				uint64(offset).bytes(buf[:n])
+			if err := r.send(packet); err != nil {
+				return trace.Wrap(err)
+			}
+			offset += uint64(n)
+			pending++
+			if pending == sftpMaxPending {
+				if err := r.status(); err != nil {
+					return trace.Wrap(err)
+				}
+				pending--
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	for ; pending > 0; pending-- {
+		if err := r.status(); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// list returns the names of the entries in the directory at path
+func (r *sftpClient) list(path string) (names []string, err error) {
+	handle, err := r.open(r.request(sshFxpOpendir).string(path))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer r.close(handle)
+	for {
+		if err := r.send(r.request(sshFxpReaddir).string(handle)); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		typ, payload, err := r.recv()
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		switch typ {
+		case sshFxpStatus:
+			err := statusError(payload)
+			if trace.IsEOF(err) {
+				return names, nil
+			}
+			return nil, trace.Wrap(err)
+		case sshFxpName:
+			entries, err := readNames(payload)
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			names = append(names, entries...)
+		default:
+			return nil, trace.BadParameter("unexpected SFTP packet %v, expected name", typ)
+		}
+	}
+}
+
+// mkdir creates the directory at path if it does not exist
+func (r *sftpClient) mkdir(path string) error {
+	if err := r.send(r.request(sshFxpMkdir).string(path).uint32(0)); err != nil {
+		return trace.Wrap(err)
+	}
+	err := r.status()
+	if err != nil {
+		// the servers report the existing directory as a generic failure
+		if _, listErr := r.list(path); listErr == nil {
+			return nil
+		}
+	}
+	return trace.Wrap(err)
+}
+
+// rename renames the file at oldpath to newpath
+func (r *sftpClient) rename(oldpath, newpath string) error {
+	if err := r.send(r.request(sshFxpRename).string(oldpath).string(newpath)); err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(r.status())
+}
+
+// remove removes the file at path
+func (r *sftpClient) remove(path string) error {
+	if err := r.send(r.request(sshFxpRemove).string(path)); err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(r.status())
+}
+
+// open sends the request to open a file or a directory
+// and returns the handle
+func (r *sftpClient) open(packet *sftpBuffer) (handle string, err error) {
+	if err := r.send(packet); err != nil {
+		return "", trace.Wrap(err)
+	}
+	typ, payload, err := r.recv()
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	switch typ {
+	case sshFxpHandle:
+		_, payload, err := readUint32(payload)
+		if err != nil {
+			return "", trace.Wrap(err)
+		}
+		handle, _, err := readString(payload)
+		return handle, trace.Wrap(err)
+	case sshFxpStatus:
+		return "", trace.Wrap(statusError(payload))
+	}
+	return "", trace.BadParameter("unexpected SFTP packet %v, expected handle", typ)
+}
+
+func (r *sftpClient) close(handle string) error {
+	if err := r.send(r.request(sshFxpClose).string(handle)); err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(r.status())
+}
+
+// status reads the status response and returns the error it describes
+func (r *sftpClient) status() error {
+	typ, payload, err := r.recv()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if typ != sshFxpStatus {
+		return trace.BadParameter("unexpected SFTP packet %v, expected status", typ)
+	}
+	return trace.Wrap(statusError(payload))
+}
+
+// request returns a new request packet of the specified type with the next ID
+func (r *sftpClient) request(typ byte) *sftpBuffer {
+	r.id++
+	return sftpPacket(typ).uint32(r.id)
+}
+
+func (r *sftpClient) send(packet *sftpBuffer) error {
+	data := append(uint32Bytes(uint32(len(packet.data))), packet.data...)
+	_, err := r.w.Write(data)
+	return trace.Wrap(err)
+}
+
+func (r *sftpClient) recv() (typ byte, payload []byte, err error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r.r, header); err != nil {
+		return 0, nil, trace.ConnectionProblem(err, "failed to read SFTP response")
+	}
+	size := binary.BigEndian.Uint32(header)
+	if size == 0 || size > sftpMaxPacketSize {
+		return 0, nil, trace.BadParameter("invalid SFTP packet size %v", size)
+	}
+	packet := make([]byte, size)
+	if _, err := io.ReadFull(r.r, packet); err != nil {
+		return 0, nil, trace.ConnectionProblem(err, "failed to read SFTP response")
+	}
+	return packet[0], packet[1:], nil
+}
+
+// statusError returns the error described by the status response payload
+func statusError(payload []byte) error {
+	_, payload, err := readUint32(payload)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	code, payload, err := readUint32(payload)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// the message is optional in the early protocol versions
+	message, _, _ := readString(payload)
+	if message == "" {
+		message = "SFTP request failed"
+	}
+	switch code {
+	case sshFxOK:
+		return nil
+	case sshFxEOF:
+		return io.EOF
+	case sshFxNoSuchFile:
+		return trace.NotFound(message)
+	case sshFxPermissionDenied:
+		return trace.AccessDenied(message)
+	}
+	return trace.BadParameter("%v (code %v)", message, code)
+}
+
+// readNames returns the file names from the name response payload
+func readNames(payload []byte) (names []string, err error) {
+	_, payload, err = readUint32(payload)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	count, payload, err := readUint32(payload)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for i := uint32(0); i < count; i++ {
+		var name string
+		name, payload, err = readString(payload)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		// skip the long name
+		_, payload, err = readString(payload)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		payload, err = skipAttrs(payload)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if name != "." && name != ".." {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// skipAttrs skips the file attributes at the beginning of the payload
+func skipAttrs(payload []byte) ([]byte, error) {
+	flags, payload, err := readUint32(payload)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	size := 0
+	if flags&sshFileXferAttrSize != 0 {
+		size += 8
+	}
+	if flags&sshFileXferAttrUIDGID != 0 {
+		size += 8
+	}
+	if flags&sshFileXferAttrPermissions != 0 {
+		size += 4
+	}
+	if flags&sshFileXferAttrACModTime != 0 {
+		size += 8
+	}
+	if len(payload) < size {
+		return nil, trace.BadParameter("invalid SFTP file attributes")
+	}
+	payload = payload[size:]
+	if flags&sshFileXferAttrExtended != 0 {
+		count, rest, err := readUint32(payload)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		payload = rest
+		// each extension is a pair of type and data strings
+		for i := uint32(0); i < count*2; i++ {
+			_, payload, err = readString(payload)
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+		}
+	}
+	return payload, nil
+}
+
+func readUint32(data []byte) (uint32, []byte, error) {
+	if len(data) < 4 {
+		return 0, nil, trace.BadParameter("SFTP packet is too short")
+	}
+	return binary.BigEndian.Uint32(data), data[4:], nil
+}
+
+func readString(data []byte) (string, []byte, error) {
+	size, data, err := readUint32(data)
+	if err != nil {
+		return "", nil, trace.Wrap(err)
+	}
+	if uint32(len(data)) < size {
+		return "", nil, trace.BadParameter("SFTP packet is too short")
+	}
+	return string(data[:size]), data[size:], nil
+}
+
+func uint32Bytes(v uint32) []byte {
+	data := make([]byte, 4)
+	binary.BigEndian.PutUint32(data, v)
+	return data
+}
+
+// sftpBuffer builds the SFTP request packets
+type sftpBuffer struct {
+	data []byte
+}
+
+func sftpPacket(typ byte) *sftpBuffer {
+	return &sftpBuffer{data: []byte{typ}}
+}
+
+func (r *sftpBuffer) uint32(v uint32) *sftpBuffer {
+	r.data = append(r.data, uint32Bytes(v)...)
+	return r
+}
+
+func (r *sftpBuffer) uint64(v uint64) *sftpBuffer {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, v)
+	r.data = append(r.data, data...)
+	return r
+}
+
+func (r *sftpBuffer) string(s string) *sftpBuffer {
+	return r.bytes([]byte(s))
+}
+
+func (r *sftpBuffer) bytes(data []byte) *sftpBuffer {
+	r.data = append(r.uint32(uint32(len(data))).data, data...)
+	return r
+}
+
+const (
+	sftpVersion = 3
+	// sftpChunkSize is the size of the data sent in a single write request
+	sftpChunkSize = 32 * 1024
+	// sftpMaxPending is the maximum number of the write requests
+	// sent without waiting for the response
+	sftpMaxPending = 16
+	// sftpMaxPacketSize is the maximum size of the accepted response
+	sftpMaxPacketSize = 256 * 1024
+
+	sshFxpInit    = 1
+	sshFxpVersion = 2
+	sshFxpOpen    = 3
+	sshFxpClose   = 4
+	sshFxpRead    = 5
+	sshFxpWrite   = 6
+	sshFxpOpendir = 11
+	sshFxpReaddir = 12
+	sshFxpRemove  = 13
+	sshFxpMkdir   = 14
+	sshFxpRename  = 18
+	sshFxpStatus  = 101
+	sshFxpHandle  = 102
+	sshFxpData    = 103
+	sshFxpName    = 104
+
+	sshFxOK               = 0
+	sshFxEOF              = 1
+	sshFxNoSuchFile       = 2
+	sshFxPermissionDenied = 3
+
+	sshFxfRead  = 0x01
+	sshFxfWrite = 0x02
+	sshFxfCreat = 0x08
+	sshFxfTrunc = 0x10
+
+	sshFileXferAttrSize        = 0x01
+	sshFileXferAttrUIDGID      = 0x02
+	sshFileXferAttrPermissions = 0x04
+	sshFileXferAttrACModTime   = 0x08
+	sshFileXferAttrExtended    = 0x80000000
+)
@@ -0,0 +1,102 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+
+	"github.com/gravitational/gravity/lib/defaults"
+
+	"github.com/boltdb/bolt"
+	"github.com/gravitational/trace"
+)
+
+// SnapshotStatus describes the etcd snapshot
+type SnapshotStatus struct {
+	// Revision is the latest revision of the etcd database in the snapshot
+	Revision int64
+	// Keys is the number of the keys in the snapshot
+	Keys int
+	// HasChecksum is true if the snapshot has been streamed from etcd
+	// and ends with the checksum of the database. The snapshots copied
+	// from the database file have no checksum
+	HasChecksum bool
+}
+
+// GetSnapshotStatus verifies the integrity of the etcd snapshot
+// at path and returns its status
+func GetSnapshotStatus(path string) (*SnapshotStatus, error) {
+	if err := verifySnapshot(path); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	status := &SnapshotStatus{
+		HasChecksum: fi.Size()%512 == sha256.Size,
+	}
+	err = viewSnapshot(path, func(bucket *bolt.Bucket) error {
+		// revisions sort in the order they were made
+		if rev, _ := bucket.Cursor().Last(); len(rev) >= revisionSize {
+			status.Revision = int64(binary.BigEndian.Uint64(rev))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	keys, err := readSnapshot(path)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	status.Keys = len(keys)
+	return status, nil
+}
+
+// viewSnapshot invokes fn with the bucket of the key revisions
+// of the etcd snapshot at path
+func viewSnapshot(path string, fn func(*bolt.Bucket) error) error {
+	// bolt requires a database file without the checksum
+	tmp, err := ioutil.TempFile("", "etcd-snapshot")
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer os.Remove(tmp.Name())
+	err = copySnapshot(tmp, path)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	db, err := bolt.Open(tmp.Name(), defaults.PrivateFileMask, &bolt.Options{ReadOnly: true})
+	if err != nil {
+		return trace.Wrap(err, "failed to open etcd snapshot %v", path)
+	}
+	defer db.Close()
+	return db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(keyBucket)
+		if bucket == nil {
+			return trace.BadParameter("etcd snapshot %v has no keys", path)
+		}
+		return trace.Wrap(fn(bucket))
+	})
+}
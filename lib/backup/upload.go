@@ -0,0 +1,141 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/archive"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DestinationFactory returns the off-site destination described by the
+// specified configuration and the key to encrypt the backups with
+type DestinationFactory func(storage.BackupDestination) (Destination, []byte, error)
+
+// NewDestinationFactory returns the destination factory that reads
+// the destination secrets with the specified Kubernetes client
+func NewDestinationFactory(client kubernetes.Interface) DestinationFactory {
+	return func(config storage.BackupDestination) (Destination, []byte, error) {
+		secret, err := GetDestinationSecret(client, config.Secret)
+		if err != nil {
+			return nil, nil, trace.Wrap(err)
+		}
+		destination, err := NewDestination(config, *secret)
+		if err != nil {
+			return nil, nil, trace.Wrap(err)
+		}
+		return destination, secret.EncryptionKey, nil
+	}
+}
+
+// WriteArchive writes the backup in the specified directory to w
+// as a compressed tarball encrypted with the specified key
+func WriteArchive(w io.Writer, dir string, key []byte) error {
+	encryptor, err := NewEncryptor(w, key)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	compressor := gzip.NewWriter(encryptor)
+	if err := archive.CompressDirectory(dir, compressor); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := compressor.Close(); err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(encryptor.Close())
+}
+
+// ExtractArchive decrypts the backup archive read from r with the
+// specified key and extracts it into the specified directory
+func ExtractArchive(r io.Reader, dir string, key []byte) error {
+	decryptor, err := NewDecryptor(r, key)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	decompressor, err := gzip.NewReader(decryptor)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer decompressor.Close()
+	return trace.Wrap(archive.Extract(decompressor, dir))
+}
+
+// upload uploads the backup in the specified directory to the destination
+// and removes the uploaded backups beyond the number to retain.
+// Returns the location of the uploaded backup
+func (r *Scheduler) upload(ctx context.Context, config storage.BackupDestination, dir string, retain int) (location string, err error) {
+	if r.Destinations == nil {
+		return "", trace.BadParameter("uploading backups is not supported")
+	}
+	destination, key, err := r.Destinations(config)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+	name := filepath.Base(dir) + ArchiveSuffix
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(WriteArchive(writer, dir, key))
+	}()
+	err = destination.Upload(ctx, name, reader)
+	// unblock the writer if the upload has failed before reading the archive
+	reader.Close()
+	if err != nil {
+		return "", trace.Wrap(err, "failed to upload backup to %v", config)
+	}
+	if err := pruneDestination(ctx, destination, retain); err != nil {
+		r.WithError(err).Warnf("Failed to remove old backups from %v.", config)
+	}
+	return strings.TrimSuffix(config.String(), "/") + "/" + name, nil
+}
+
+// pruneDestination removes the oldest backups from the destination
+// so that at most retain backups are left
+func pruneDestination(ctx context.Context, destination Destination, retain int) error {
+	names, err := destination.List(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	var backups []string
+	for _, name := range names {
+		if strings.HasPrefix(name, backupPrefix) && strings.HasSuffix(name, ArchiveSuffix) {
+			backups = append(backups, name)
+		}
+	}
+	// backup names sort in the order they were taken
+	sort.Strings(backups)
+	var errors []error
+	for len(backups) > retain {
+		errors = append(errors, destination.Delete(ctx, backups[0]))
+		backups = backups[1:]
+	}
+	return trace.NewAggregate(errors...)
+}
+
+// ArchiveSuffix is the suffix of the encrypted backup archives
+// uploaded to the off-site destination
+const ArchiveSuffix = ".tar.gz.enc"
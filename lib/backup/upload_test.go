@@ -0,0 +1,299 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+type UploadSuite struct{}
+
+var _ = Suite(&UploadSuite{})
+
+func (s *UploadSuite) TestEncryptsInChunks(c *C) {
+	data := bytes.Repeat([]byte("backup"), chunkSize/3)
+	var buf bytes.Buffer
+	encryptor, err := NewEncryptor(&buf, []byte("key"))
+	c.Assert(err, IsNil)
+	_, err = encryptor.Write(data)
+	c.Assert(err, IsNil)
+	c.Assert(encryptor.Close(), IsNil)
+	c.Assert(bytes.Contains(buf.Bytes(), []byte("backup")), Equals, false)
+	encrypted := buf.Bytes()
+
+	decryptor, err := NewDecryptor(bytes.NewReader(encrypted), []byte("key"))
+	c.Assert(err, IsNil)
+	decrypted, err := ioutil.ReadAll(decryptor)
+	c.Assert(err, IsNil)
+	c.Assert(decrypted, DeepEquals, data)
+
+	decryptor, err = NewDecryptor(bytes.NewReader(encrypted), []byte("other key"))
+	c.Assert(err, IsNil)
+	_, err = ioutil.ReadAll(decryptor)
+	c.Assert(trace.IsBadParameter(err), Equals, true, Commentf("%v", err))
+
+	// drop the empty final chunk
+	truncated := encrypted[:len(encrypted)-chunkHeaderSize-16]
+	decryptor, err = NewDecryptor(bytes.NewReader(truncated), []byte("key"))
+	c.Assert(err, IsNil)
+	_, err = ioutil.ReadAll(decryptor)
+	c.Assert(trace.IsBadParameter(err), Equals, true, Commentf("%v", err))
+}
+
+func (s *SchedulerSuite) TestUploadsBackups(c *C) {
+	remote := c.MkDir()
+	scheduler := s.newScheduler(c)
+	scheduler.Destinations = func(config storage.BackupDestination) (Destination, []byte, error) {
+		destination, err := NewDestination(config, DestinationSecret{})
+		return destination, []byte("key"), err
+	}
+	s.upsertScheduleWithDestination(c, &storage.BackupDestination{
+		Type:   storage.BackupDestinationNFSType,
+		Secret: "backups",
+		NFS:    &storage.BackupDestinationNFS{Path: remote},
+	})
+	c.Assert(scheduler.backupIfDue(context.TODO()), IsNil)
+	for i := 0; i < 2; i++ {
+		s.clock.Advance(24 * time.Hour)
+		c.Assert(scheduler.backupIfDue(context.TODO()), IsNil)
+	}
+
+	status, err := s.backend.GetBackupStatus("example.com")
+	c.Assert(err, IsNil)
+	c.Assert(status.IsHealthy(), Equals, true)
+	c.Assert(status.Uploaded, Equals, "nfs:"+remote+"/backup-20190603T010000Z.tar.gz.enc")
+	// only the last backup is retained off-site
+	names := listDir(c, remote)
+	c.Assert(names, DeepEquals, []string{"backup-20190603T010000Z.tar.gz.enc"})
+
+	f, err := os.Open(filepath.Join(remote, names[0]))
+	c.Assert(err, IsNil)
+	defer f.Close()
+	dir := c.MkDir()
+	c.Assert(ExtractArchive(f, dir, []byte("key")), IsNil)
+	data, err := ioutil.ReadFile(filepath.Join(dir, EtcdSnapshotFile))
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "snapshot")
+}
+
+func (s *SchedulerSuite) TestRecordsUploadFailure(c *C) {
+	scheduler := s.newScheduler(c)
+	scheduler.Destinations = func(config storage.BackupDestination) (Destination, []byte, error) {
+		return nil, nil, trace.NotFound("secret backups not found")
+	}
+	s.upsertScheduleWithDestination(c, &storage.BackupDestination{
+		Type:   storage.BackupDestinationNFSType,
+		Secret: "backups",
+		NFS:    &storage.BackupDestinationNFS{Path: "/backups"},
+	})
+	c.Assert(scheduler.backupIfDue(context.TODO()), IsNil)
+	s.clock.Advance(time.Hour)
+	c.Assert(scheduler.backupIfDue(context.TODO()), IsNil)
+	c.Assert(s.backups(c), DeepEquals, []string{"backup-20190601T020000Z"})
+
+	status, err := s.backend.GetBackupStatus("example.com")
+	c.Assert(err, IsNil)
+	c.Assert(status.IsHealthy(), Equals, false)
+	c.Assert(status.Message, Equals, "")
+	c.Assert(status.Uploaded, Equals, "")
+	c.Assert(status.UploadMessage, Matches, ".*secret backups not found.*")
+}
+
+func (s *UploadSuite) TestSFTPClient(c *C) {
+	root := c.MkDir()
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	go (&fakeSFTPServer{root: root}).serve(serverConn)
+
+	client, err := newSFTPClient(clientConn, clientConn)
+	c.Assert(err, IsNil)
+	c.Assert(client.mkdir("/backups"), IsNil)
+	c.Assert(client.mkdir("/backups"), IsNil)
+	data := bytes.Repeat([]byte("backup"), sftpChunkSize)
+	c.Assert(client.upload("/backups/backup.partial", bytes.NewReader(data)), IsNil)
+	c.Assert(client.rename("/backups/backup.partial", "/backups/backup"), IsNil)
+	uploaded, err := ioutil.ReadFile(filepath.Join(root, "backups", "backup"))
+	c.Assert(err, IsNil)
+	c.Assert(uploaded, DeepEquals, data)
+	var downloaded bytes.Buffer
+	c.Assert(client.download("/backups/backup", &downloaded), IsNil)
+	c.Assert(downloaded.Bytes(), DeepEquals, data)
+	err = client.download("/backups/missing", &downloaded)
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%v", err))
+
+	names, err := client.list("/backups")
+	c.Assert(err, IsNil)
+	c.Assert(names, DeepEquals, []string{"backup"})
+	c.Assert(client.remove("/backups/backup"), IsNil)
+	err = client.remove("/backups/backup")
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%v", err))
+	names, err = client.list("/backups")
+	c.Assert(err, IsNil)
+	c.Assert(names, HasLen, 0)
+}
+
+func (s *SchedulerSuite) upsertScheduleWithDestination(c *C, destination *storage.BackupDestination) {
+	c.Assert(s.backend.UpsertBackupSchedule("example.com", storage.NewBackupSchedule(
+		storage.BackupScheduleSpecV2{
+			Schedule:    "0 2 * * *",
+			Directory:   s.dir,
+			Retain:      1,
+			Destination: destination,
+		})), IsNil)
+}
+
+func listDir(c *C, dir string) (names []string) {
+	entries, err := ioutil.ReadDir(dir)
+	c.Assert(err, IsNil)
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+// fakeSFTPServer serves the subset of the SFTP protocol used
+// by the client from the local directory
+type fakeSFTPServer struct {
+	root  string
+	files map[string]*os.File
+	dirs  map[string]bool
+}
+
+func (r *fakeSFTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r.files = make(map[string]*os.File)
+	r.dirs = make(map[string]bool)
+	// responses are queued since the client sends multiple
+	// requests before reading the responses
+	responses := make(chan []byte, 64)
+	defer close(responses)
+	go func() {
+		for response := range responses {
+			if _, err := conn.Write(response); err != nil {
+				return
+			}
+		}
+	}()
+	for {
+		header := make([]byte, 4)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		packet := make([]byte, binary.BigEndian.Uint32(header))
+		if _, err := io.ReadFull(conn, packet); err != nil {
+			return
+		}
+		response := r.handle(packet[0], packet[1:])
+		responses <- append(uint32Bytes(uint32(len(response.data))), response.data...)
+	}
+}
+
+func (r *fakeSFTPServer) handle(typ byte, payload []byte) *sftpBuffer {
+	if typ == sshFxpInit {
+		return sftpPacket(sshFxpVersion).uint32(sftpVersion)
+	}
+	id, payload, _ := readUint32(payload)
+	name, payload, _ := readString(payload)
+	path := filepath.Join(r.root, name)
+	var err error
+	switch typ {
+	case sshFxpOpen:
+		var f *os.File
+		flags, _, _ := readUint32(payload)
+		if flags&sshFxfRead != 0 {
+			f, err = os.Open(path)
+		} else {
+			f, err = os.Create(path)
+		}
+		if err == nil {
+			r.files[name] = f
+			return sftpPacket(sshFxpHandle).uint32(id).string(name)
+		}
+	case sshFxpRead:
+		offset := binary.BigEndian.Uint64(payload)
+		size, _, _ := readUint32(payload[8:])
+		data := make([]byte, size)
+		var n int
+		n, err = r.files[name].ReadAt(data, int64(offset))
+		if n > 0 {
+			return sftpPacket(sshFxpData).uint32(id).bytes(data[:n])
+		}
+		if err == io.EOF {
+			return sftpPacket(sshFxpStatus).uint32(id).uint32(sshFxEOF)
+		}
+	case sshFxpWrite:
+		offset := binary.BigEndian.Uint64(payload)
+		data, _, _ := readString(payload[8:])
+		_, err = r.files[name].WriteAt([]byte(data), int64(offset))
+	case sshFxpClose:
+		if f, ok := r.files[name]; ok {
+			err = f.Close()
+			delete(r.files, name)
+		}
+		delete(r.dirs, name)
+	case sshFxpOpendir:
+		if _, err = os.Stat(path); err == nil {
+			r.dirs[name] = false
+			return sftpPacket(sshFxpHandle).uint32(id).string(name)
+		}
+	case sshFxpReaddir:
+		if r.dirs[name] {
+			return sftpPacket(sshFxpStatus).uint32(id).uint32(sshFxEOF)
+		}
+		r.dirs[name] = true
+		entries, _ := ioutil.ReadDir(path)
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		sort.Strings(names)
+		response := sftpPacket(sshFxpName).uint32(id).uint32(uint32(len(names) + 1)).
+			string(".").string("").uint32(0)
+		for _, name := range names {
+			response.string(name).string("").uint32(sshFileXferAttrSize).uint64(0)
+		}
+		return response
+	case sshFxpRemove:
+		err = os.Remove(path)
+	case sshFxpMkdir:
+		err = os.Mkdir(path, 0700)
+	case sshFxpRename:
+		newname, _, _ := readString(payload)
+		err = os.Rename(path, filepath.Join(r.root, newname))
+	}
+	switch {
+	case err == nil:
+		return sftpPacket(sshFxpStatus).uint32(id).uint32(sshFxOK)
+	case os.IsNotExist(err):
+		return sftpPacket(sshFxpStatus).uint32(id).uint32(sshFxNoSuchFile).string(err.Error())
+	}
+	return sftpPacket(sshFxpStatus).uint32(id).uint32(4).string(err.Error())
+}
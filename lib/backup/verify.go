@@ -0,0 +1,177 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+
+	"github.com/gravitational/gravity/lib/storage/keyval"
+
+	"github.com/boltdb/bolt"
+	"github.com/coreos/etcd/clientv3"
+	"github.com/gravitational/trace"
+)
+
+// Verification describes the outcome of the backup verification
+type Verification struct {
+	// Backup describes the verified backup
+	*Backup
+	// Snapshot describes the etcd snapshot of the backup
+	Snapshot SnapshotStatus
+	// Resources is the number of the Kubernetes resources
+	// in the snapshot that would be restored
+	Resources int
+	// Warnings lists the problems that do not prevent the restore
+	Warnings []string
+}
+
+// TestRestoreResult describes the outcome of the sandboxed test restore
+type TestRestoreResult struct {
+	// Resources is the number of the restored Kubernetes resources
+	Resources int
+	// Records is the number of the restored cluster state records
+	Records int
+}
+
+// Verify verifies the integrity of the backup in the specified directory
+// and checks that the etcd snapshot is healthy and can be restored
+func Verify(dir string) (*Verification, error) {
+	backup, err := Open(dir)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	status, err := GetSnapshotStatus(backup.path(EtcdSnapshotFile))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	resources, err := readSnapshot(backup.path(EtcdSnapshotFile))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	verification := &Verification{Backup: backup, Snapshot: *status}
+	for _, resource := range resources {
+		if isRestoredResource(string(resource.Key)) {
+			verification.Resources++
+		}
+	}
+	if verification.Resources == 0 {
+		return nil, trace.BadParameter("etcd snapshot of backup %v does not include any Kubernetes resources", dir)
+	}
+	if !status.HasChecksum {
+		verification.Warnings = append(verification.Warnings,
+			"etcd snapshot has no checksum, its integrity cannot be fully verified")
+	}
+	if len(backup.Images) == 0 {
+		verification.Warnings = append(verification.Warnings,
+			"backup does not include the registry metadata, missing images cannot be detected on restore")
+	}
+	return verification, nil
+}
+
+// VerifyArchive decrypts the backup archive read from r with the specified key,
+// extracts it into the specified directory and verifies the extracted backup
+func VerifyArchive(r io.Reader, dir string, key []byte) (*Verification, error) {
+	if err := ExtractArchive(r, dir, key); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	verification, err := Verify(dir)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return verification, nil
+}
+
+// TestRestore restores the backup into a sandbox in the specified data
+// directory instead of the cluster: the Kubernetes resources are written
+// into a local database in place of etcd and the cluster state records are
+// imported into a new local backend. The cluster is not modified
+func (r *Backup) TestRestore(ctx context.Context, dataDir string) (*TestRestoreResult, error) {
+	kv, err := newSandboxKV(filepath.Join(dataDir, EtcdSnapshotFile))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer kv.Close()
+	var result TestRestoreResult
+	result.Resources, err = r.RestoreKubernetes(ctx, kv)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to restore Kubernetes resources")
+	}
+	if count := kv.count(); count != result.Resources {
+		return nil, trace.BadParameter("restored %v Kubernetes resources, found %v", result.Resources, count)
+	}
+	backend, err := keyval.NewBolt(keyval.BoltConfig{Path: filepath.Join(dataDir, sandboxStateFile)})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer backend.Close()
+	result.Records, err = r.RestoreState(ctx, backend)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to restore cluster state")
+	}
+	return &result, nil
+}
+
+// sandboxKV stores the Kubernetes resources restored
+// by the test restore in a local database
+type sandboxKV struct {
+	db *bolt.DB
+}
+
+func newSandboxKV(path string) (*sandboxKV, error) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	return &sandboxKV{db: db}, nil
+}
+
+// Put puts a key-value pair into the local database
+func (r *sandboxKV) Put(ctx context.Context, key, val string, opts ...clientv3.OpOption) (*clientv3.PutResponse, error) {
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(keyBucket)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(key), []byte(val))
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &clientv3.PutResponse{}, nil
+}
+
+// count returns the number of the keys in the local database
+func (r *sandboxKV) count() (count int) {
+	r.db.View(func(tx *bolt.Tx) error {
+		if bucket := tx.Bucket(keyBucket); bucket != nil {
+			count = bucket.Stats().KeyN
+		}
+		return nil
+	})
+	return count
+}
+
+// Close closes the local database
+func (r *sandboxKV) Close() error {
+	return r.db.Close()
+}
+
+// sandboxStateFile is the name of the backend the cluster
+// state is restored into by the test restore
+const sandboxStateFile = "gravity.db"
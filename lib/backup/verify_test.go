@@ -0,0 +1,73 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+func (s *RestoreSuite) TestVerifiesBackup(c *C) {
+	verification, err := Verify(s.dir)
+	c.Assert(err, IsNil)
+	c.Assert(verification.ClusterName, Equals, "example.com")
+	c.Assert(verification.Snapshot.Revision, Equals, int64(7))
+	c.Assert(verification.Resources, Equals, 2)
+	c.Assert(verification.Warnings, HasLen, 0)
+
+	c.Assert(os.Remove(filepath.Join(s.dir, RegistryFile)), IsNil)
+	verification, err = Verify(s.dir)
+	c.Assert(err, IsNil)
+	c.Assert(verification.Warnings, HasLen, 1)
+}
+
+func (s *RestoreSuite) TestVerifiesArchive(c *C) {
+	var buf bytes.Buffer
+	c.Assert(WriteArchive(&buf, s.dir, []byte("key")), IsNil)
+	data := buf.Bytes()
+
+	verification, err := VerifyArchive(bytes.NewReader(data), c.MkDir(), []byte("key"))
+	c.Assert(err, IsNil)
+	c.Assert(verification.ClusterName, Equals, "example.com")
+
+	_, err = VerifyArchive(bytes.NewReader(data), c.MkDir(), []byte("other"))
+	c.Assert(err, NotNil)
+
+	_, err = VerifyArchive(bytes.NewReader(data[:len(data)/2]), c.MkDir(), []byte("key"))
+	c.Assert(err, NotNil)
+}
+
+func (s *RestoreSuite) TestRejectsSnapshotWithoutResources(c *C) {
+	s.writeSnapshot(c, []snapshotRevision{
+		{key: "/gravity/local/sites/example.com/val", value: "cluster"},
+	})
+	_, err := Verify(s.dir)
+	c.Assert(trace.IsBadParameter(err), Equals, true, Commentf("%v", err))
+}
+
+func (s *RestoreSuite) TestRestoresIntoSandbox(c *C) {
+	backup, err := Open(s.dir)
+	c.Assert(err, IsNil)
+	result, err := backup.TestRestore(context.TODO(), c.MkDir())
+	c.Assert(err, IsNil)
+	c.Assert(*result, DeepEquals, TestRestoreResult{Resources: 2, Records: 1})
+}
@@ -0,0 +1,398 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fs
+
+import (
+	"archive/tar"
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gravitational/gravity/lib/defaults"
+
+	"github.com/gravitational/trace"
+)
+
+// Packages are tarballs and the bulk of an application package are the
+// layers of its Docker images. The layers rarely change between the versions
+// of an application and are shared between applications built from the same
+// base images, so the contents of the large files inside a tarball BLOB are
+// stored separately as content-addressed chunks, once per object storage.
+//
+// The BLOB file keeps the rest of the tarball (the tar headers, padding and
+// small files) and the index records where the chunks are spliced in, so the
+// BLOB is read back byte for byte and its hash does not change.
+
+// index describes a BLOB stored with the contents of its large files
+// split off into chunks
+type index struct {
+	// Size is the size of the BLOB file without the chunks
+	Size int64 `json:"size"`
+	// Chunks lists the chunks ordered by their offset
+	Chunks []chunk `json:"chunks"`
+}
+
+// sizeBytes returns the size of the BLOB contents
+func (r index) sizeBytes() int64 {
+	size := r.Size
+	for _, chunk := range r.Chunks {
+		size += chunk.Size
+	}
+	return size
+}
+
+// chunk describes a part of the BLOB contents stored as a separate file
+type chunk struct {
+	// Offset is the offset of the chunk in the BLOB contents
+	Offset int64 `json:"offset"`
+	// Size is the size of the chunk in bytes
+	Size int64 `json:"size"`
+	// SHA512 is the half SHA512 hash of the chunk
+	SHA512 string `json:"sha512"`
+}
+
+// splitBLOB copies the data into the BLOB file w, storing the contents of the
+// large regular files as chunks if the data is a tarball.
+// Returns the total number of bytes read and the chunks
+func (o *objects) splitBLOB(w io.Writer, data io.Reader) (size int64, chunks []chunk, err error) {
+	s := &splitter{objects: o, blob: w}
+	defer s.abortChunk()
+	r := io.TeeReader(data, s)
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			// not a tarball or the end of archive, the rest is copied as-is
+			break
+		}
+		if header.Typeflag != tar.TypeReg || header.Size < minChunkSize {
+			continue
+		}
+		if err := s.startChunk(); err != nil {
+			return 0, nil, trace.Wrap(err)
+		}
+		if _, err := io.Copy(ioutil.Discard, tr); err != nil {
+			return 0, nil, trace.Wrap(err)
+		}
+		if err := s.finishChunk(); err != nil {
+			return 0, nil, trace.Wrap(err)
+		}
+	}
+	if _, err := io.Copy(ioutil.Discard, r); err != nil {
+		return 0, nil, trace.Wrap(err)
+	}
+	return s.offset, s.chunks, nil
+}
+
+// splitter routes the data written to it either to the BLOB file
+// or to the chunk being written
+type splitter struct {
+	*objects
+	blob   io.Writer
+	offset int64
+	chunks []chunk
+	// chunk is the chunk being written
+	chunk  *os.File
+	hasher hash.Hash
+	start  int64
+}
+
+// Write writes p to the chunk if there is one being written,
+// or to the BLOB file otherwise
+func (s *splitter) Write(p []byte) (n int, err error) {
+	if s.chunk != nil {
+		n, err = io.MultiWriter(s.chunk, s.hasher).Write(p)
+	} else {
+		n, err = s.blob.Write(p)
+	}
+	s.offset += int64(n)
+	return n, err
+}
+
+func (s *splitter) startChunk() (err error) {
+	s.chunk, err = ioutil.TempFile(s.tempDir(), "chunk")
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	s.hasher = sha512.New()
+	s.start = s.offset
+	return nil
+}
+
+// finishChunk moves the chunk into the chunk store. The chunk that is already
+// in the store has the same contents and is kept as-is
+func (s *splitter) finishChunk() error {
+	defer s.abortChunk()
+	if err := s.chunk.Close(); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	hash := fmt.Sprintf("%x", s.hasher.Sum(nil)[:sha512.Size/2])
+	path := s.chunkPath(hash)
+	if err := os.MkdirAll(filepath.Dir(path), defaults.SharedDirMask); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	if err := os.Rename(s.chunk.Name(), path); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	s.chunks = append(s.chunks, chunk{
+		Offset: s.start,
+		Size:   s.offset - s.start,
+		SHA512: hash,
+	})
+	return nil
+}
+
+// abortChunk removes the temporary file of the chunk being written, if any
+func (s *splitter) abortChunk() {
+	if s.chunk == nil {
+		return
+	}
+	s.chunk.Close()
+	os.Remove(s.chunk.Name())
+	s.chunk = nil
+}
+
+// readIndex returns the index of the BLOB with the specified hash and size
+// of the BLOB file. Returns NotFound if the BLOB is stored as a single file
+func (o *objects) readIndex(hash string, size int64) (*index, error) {
+	data, err := ioutil.ReadFile(o.indexPath(hash))
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	var index index
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	// the index is written before the BLOB file, so the index that does not
+	// match the BLOB file belongs to an interrupted write of the same BLOB
+	if index.Size != size {
+		return nil, trace.NotFound("BLOB %v is not split into chunks", hash)
+	}
+	return &index, nil
+}
+
+// writeIndex writes the index of the BLOB with the specified hash
+func (o *objects) writeIndex(hash string, index index) error {
+	data, err := json.Marshal(index)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	path := o.indexPath(hash)
+	if err := os.MkdirAll(filepath.Dir(path), defaults.SharedDirMask); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	f, err := ioutil.TempFile(o.tempDir(), "index")
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(data)
+	if errClose := f.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	return trace.ConvertSystemError(os.Rename(f.Name(), path))
+}
+
+// deleteIndex deletes the index of the BLOB with the specified hash, if any
+func (o *objects) deleteIndex(hash string) error {
+	err := os.Remove(o.indexPath(hash))
+	if err != nil && !os.IsNotExist(err) {
+		return trace.ConvertSystemError(err)
+	}
+	return nil
+}
+
+// deleteChunks deletes the specified chunks unless they are
+// referenced by the index of another BLOB
+func (o *objects) deleteChunks(chunks []chunk) error {
+	used := make(map[string]struct{})
+	err := filepath.Walk(o.indexDir(), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return trace.ConvertSystemError(err)
+		}
+		if info.IsDir() {
+			return nil
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return trace.ConvertSystemError(err)
+		}
+		var index index
+		if err := json.Unmarshal(data, &index); err != nil {
+			return trace.Wrap(err)
+		}
+		for _, chunk := range index.Chunks {
+			used[chunk.SHA512] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	for _, chunk := range chunks {
+		if _, ok := used[chunk.SHA512]; ok {
+			continue
+		}
+		err := os.Remove(o.chunkPath(chunk.SHA512))
+		if err != nil && !os.IsNotExist(err) {
+			return trace.ConvertSystemError(err)
+		}
+	}
+	return nil
+}
+
+// newChunkedReader returns the reader of the contents of the BLOB stored
+// in file f with the chunks described by index
+func (o *objects) newChunkedReader(f *os.File, index index) *chunkedReader {
+	var segments []segment
+	var offset, blobOffset int64
+	addBlobSegment := func(size int64) {
+		if size != 0 {
+			segments = append(segments, segment{start: offset, size: size, offset: blobOffset})
+			offset += size
+			blobOffset += size
+		}
+	}
+	for _, chunk := range index.Chunks {
+		addBlobSegment(chunk.Offset - offset)
+		if chunk.Size != 0 {
+			segments = append(segments, segment{start: offset, size: chunk.Size, path: o.chunkPath(chunk.SHA512)})
+			offset += chunk.Size
+		}
+	}
+	addBlobSegment(index.Size - blobOffset)
+	return &chunkedReader{
+		blob:     f,
+		segments: segments,
+		size:     offset,
+	}
+}
+
+// chunkedReader reads the BLOB contents from the BLOB file
+// and the chunk files
+type chunkedReader struct {
+	blob     *os.File
+	segments []segment
+	size     int64
+	pos      int64
+	// chunk is the open chunk file
+	chunk *os.File
+}
+
+// segment is a part of the BLOB contents stored
+// in the BLOB file or in a chunk file
+type segment struct {
+	// start is the offset of the segment in the BLOB contents
+	start int64
+	// size is the size of the segment
+	size int64
+	// path is the path to the chunk file, empty for the BLOB file
+	path string
+	// offset is the offset of the segment in the file
+	offset int64
+}
+
+// Read reads the BLOB contents at the current position
+func (r *chunkedReader) Read(p []byte) (int, error) {
+	if r.pos >= r.size {
+		return 0, io.EOF
+	}
+	i := sort.Search(len(r.segments), func(i int) bool {
+		return r.segments[i].start+r.segments[i].size > r.pos
+	})
+	segment := r.segments[i]
+	f, err := r.open(segment)
+	if err != nil {
+		return 0, trace.Wrap(err)
+	}
+	if remaining := segment.start + segment.size - r.pos; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := f.ReadAt(p, segment.offset+r.pos-segment.start)
+	r.pos += int64(n)
+	if err == io.EOF {
+		if n == len(p) {
+			return n, nil
+		}
+		return n, io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// Seek sets the position for the next Read
+func (r *chunkedReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.pos
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, trace.BadParameter("invalid whence %v", whence)
+	}
+	if offset < 0 {
+		return 0, trace.BadParameter("negative position %v", offset)
+	}
+	r.pos = offset
+	return offset, nil
+}
+
+// Close closes the BLOB file and the open chunk file
+func (r *chunkedReader) Close() error {
+	if r.chunk != nil {
+		r.chunk.Close()
+		r.chunk = nil
+	}
+	return r.blob.Close()
+}
+
+// open returns the file with the specified segment.
+// Only one chunk file is kept open at a time
+func (r *chunkedReader) open(segment segment) (*os.File, error) {
+	if segment.path == "" {
+		return r.blob, nil
+	}
+	if r.chunk != nil && r.chunk.Name() == segment.path {
+		return r.chunk, nil
+	}
+	if r.chunk != nil {
+		r.chunk.Close()
+		r.chunk = nil
+	}
+	f, err := os.Open(segment.path)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	r.chunk = f
+	return f, nil
+}
+
+// minChunkSize is the minimum size of a file in a tarball
+// to be stored as a chunk
+const minChunkSize = 1 << 20
@@ -24,6 +24,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"sync"
 
 	"github.com/gravitational/gravity/lib/blob"
 	"github.com/gravitational/gravity/lib/defaults"
@@ -47,6 +48,9 @@ func New(path string) (blob.Objects, error) {
 
 type objects struct {
 	dir string
+	// mu is held for writing when deleting BLOBs
+	// so the chunks of a BLOB being written are not garbage collected
+	mu sync.RWMutex
 }
 
 func (o *objects) tempDir() string {
@@ -57,6 +61,28 @@ func (o *objects) blobDir() string {
 	return filepath.Join(o.dir, "blobs")
 }
 
+func (o *objects) chunkDir() string {
+	return filepath.Join(o.dir, "chunks")
+}
+
+func (o *objects) indexDir() string {
+	return filepath.Join(o.dir, "index")
+}
+
+func (o *objects) blobPath(h string) string {
+	return filepath.Join(o.hashDir(h), h)
+}
+
+// chunkPath returns the path to the chunk file, chunks are
+// organized in directories the same way as BLOBs
+func (o *objects) chunkPath(h string) string {
+	return filepath.Join(o.chunkDir(), h[0:3], h)
+}
+
+func (o *objects) indexPath(h string) string {
+	return filepath.Join(o.indexDir(), h[0:3], h)
+}
+
 // hashDir helps us to organize the blobs in the folder -
 // instead of putting all blobs in one folder, we
 // will put them in 4096 folders, groping by first 3 strings
@@ -93,7 +119,9 @@ func (o *objects) GetBLOBs() ([]string, error) {
 	return out, nil
 }
 
-// WriteBLOB writes object to the storage, returns object envelope
+// WriteBLOB writes object to the storage, returns object envelope.
+// The contents of large files in tarballs are stored as separate chunks
+// shared with other BLOBs
 func (o *objects) WriteBLOB(data io.Reader) (*blob.Envelope, error) {
 	// step1 : write data and compute it's hash to the temporary file,
 	// then move it to the proper location based on it's hash
@@ -103,9 +131,11 @@ func (o *objects) WriteBLOB(data io.Reader) (*blob.Envelope, error) {
 	}
 	defer f.Close()
 
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
 	hasher := sha512.New()
-	w := io.MultiWriter(f, hasher)
-	size, err := io.Copy(w, data)
+	size, chunks, err := o.splitBLOB(f, io.TeeReader(data, hasher))
 	if err != nil {
 		defer os.Remove(f.Name())
 		return nil, trace.Wrap(err)
@@ -122,12 +152,25 @@ func (o *objects) WriteBLOB(data io.Reader) (*blob.Envelope, error) {
 		defer os.Remove(f.Name())
 		return nil, trace.Wrap(err)
 	}
+	if len(chunks) != 0 {
+		err := o.writeIndex(hash, index{Size: size - index{Chunks: chunks}.sizeBytes(), Chunks: chunks})
+		if err != nil {
+			defer os.Remove(f.Name())
+			return nil, trace.Wrap(err)
+		}
+	}
 	// now place it to the right place in the filesystem
-	targetPath := filepath.Join(targetDir, hash)
+	targetPath := o.blobPath(hash)
 	if err := os.Rename(f.Name(), targetPath); err != nil {
 		defer os.Remove(f.Name())
 		return nil, trace.Wrap(err)
 	}
+	if len(chunks) == 0 {
+		// the BLOB could have been split into chunks before
+		if err := o.deleteIndex(hash); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
 	fileInfo, err := os.Stat(targetPath)
 	if err != nil {
 		if err2 := os.Remove(targetPath); err2 != nil {
@@ -144,13 +187,20 @@ func (o *objects) WriteBLOB(data io.Reader) (*blob.Envelope, error) {
 
 // GetBLOBEnvelope returns file information identified by hash
 func (o *objects) GetBLOBEnvelope(hash string) (*blob.Envelope, error) {
-	targetPath := filepath.Join(o.hashDir(hash), hash)
-	fileInfo, err := os.Stat(targetPath)
+	fileInfo, err := os.Stat(o.blobPath(hash))
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+	size := fileInfo.Size()
+	index, err := o.readIndex(hash, size)
+	if err != nil && !trace.IsNotFound(err) {
+		return nil, trace.Wrap(err)
+	}
+	if index != nil {
+		size = index.sizeBytes()
+	}
 	return &blob.Envelope{
-		SizeBytes: fileInfo.Size(),
+		SizeBytes: size,
 		SHA512:    hash,
 		Modified:  fileInfo.ModTime().UTC(),
 	}, nil
@@ -158,18 +208,49 @@ func (o *objects) GetBLOBEnvelope(hash string) (*blob.Envelope, error) {
 
 // OpenBLOB opens file identified by hash and returns reader
 func (o *objects) OpenBLOB(hash string) (blob.ReadSeekCloser, error) {
-	f, err := os.Open(filepath.Join(o.hashDir(hash), hash))
+	f, err := os.Open(o.blobPath(hash))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	fileInfo, err := f.Stat()
 	if err != nil {
+		f.Close()
+		return nil, trace.Wrap(err)
+	}
+	index, err := o.readIndex(hash, fileInfo.Size())
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return f, nil
+		}
+		f.Close()
 		return nil, trace.Wrap(err)
 	}
-	return f, nil
+	return o.newChunkedReader(f, *index), nil
 }
 
-// DeleteBLOB deletes BLOB from the storage
+// DeleteBLOB deletes BLOB from the storage along with its chunks
+// that are not shared with other BLOBs
 func (o *objects) DeleteBLOB(hash string) error {
-	err := os.Remove(filepath.Join(o.hashDir(hash), hash))
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	path := o.blobPath(hash)
+	fileInfo, err := os.Stat(path)
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	return nil
+	index, err := o.readIndex(hash, fileInfo.Size())
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	err = os.Remove(path)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := o.deleteIndex(hash); err != nil {
+		return trace.Wrap(err)
+	}
+	if index == nil {
+		return nil
+	}
+	return trace.Wrap(o.deleteChunks(index.Chunks))
 }
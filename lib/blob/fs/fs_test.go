@@ -17,10 +17,16 @@ limitations under the License.
 package fs
 
 import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/gravitational/gravity/lib/blob/suite"
+	"github.com/gravitational/gravity/lib/utils"
 
 	log "github.com/sirupsen/logrus"
 	. "gopkg.in/check.v1"
@@ -60,3 +66,86 @@ func (s *FSSuite) TestBLOBWriteTwice(c *C) {
 func (s *FSSuite) TestBLOBList(c *C) {
 	s.suite.BLOBList(c)
 }
+
+func (s *FSSuite) TestStoresLargeFilesOnce(c *C) {
+	layer1 := bytes.Repeat([]byte("a"), minChunkSize)
+	layer2 := bytes.Repeat([]byte("b"), minChunkSize+1)
+	layer3 := bytes.Repeat([]byte("c"), 2*minChunkSize)
+	tarball1 := newTarball(c, map[string][]byte{
+		"manifest.json": []byte("{}"),
+		"layer1":        layer1,
+		"layer2":        layer2,
+	})
+	tarball2 := newTarball(c, map[string][]byte{
+		"manifest.json": []byte(`{"version": 2}`),
+		"layer1":        layer1,
+		"layer3":        layer3,
+	})
+
+	e1, err := s.suite.Objects.WriteBLOB(bytes.NewReader(tarball1))
+	c.Assert(err, IsNil)
+	c.Assert(e1.SizeBytes, Equals, int64(len(tarball1)))
+	c.Assert(e1.SHA512, Equals, utils.MustSHA512Half(tarball1))
+	e2, err := s.suite.Objects.WriteBLOB(bytes.NewReader(tarball2))
+	c.Assert(err, IsNil)
+	c.Assert(s.listChunks(c), HasLen, 3)
+
+	envelope, err := s.suite.Objects.GetBLOBEnvelope(e1.SHA512)
+	c.Assert(err, IsNil)
+	c.Assert(envelope, DeepEquals, e1)
+
+	r, err := s.suite.Objects.OpenBLOB(e2.SHA512)
+	c.Assert(err, IsNil)
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	c.Assert(err, IsNil)
+	c.Assert(data, DeepEquals, tarball2)
+	// seek into the middle of the shared chunk
+	offset := int64(bytes.Index(tarball2, layer1)) + minChunkSize/2
+	_, err = r.Seek(offset, io.SeekStart)
+	c.Assert(err, IsNil)
+	data, err = ioutil.ReadAll(r)
+	c.Assert(err, IsNil)
+	c.Assert(data, DeepEquals, tarball2[offset:])
+
+	// only the chunks not shared with the other BLOB are deleted
+	c.Assert(s.suite.Objects.DeleteBLOB(e1.SHA512), IsNil)
+	c.Assert(s.listChunks(c), HasLen, 2)
+	c.Assert(s.suite.Objects.DeleteBLOB(e2.SHA512), IsNil)
+	c.Assert(s.listChunks(c), HasLen, 0)
+}
+
+func (s *FSSuite) listChunks(c *C) (chunks []string) {
+	err := filepath.Walk(filepath.Join(s.dir, "chunks"), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			chunks = append(chunks, info.Name())
+		}
+		return nil
+	})
+	c.Assert(err, IsNil)
+	return chunks
+}
+
+func newTarball(c *C, files map[string][]byte) []byte {
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+	for _, name := range []string{"manifest.json", "layer1", "layer2", "layer3"} {
+		data, ok := files[name]
+		if !ok {
+			continue
+		}
+		c.Assert(w.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(data)),
+			Typeflag: tar.TypeReg,
+		}), IsNil)
+		_, err := w.Write(data)
+		c.Assert(err, IsNil)
+	}
+	c.Assert(w.Close(), IsNil)
+	return buf.Bytes()
+}
@@ -0,0 +1,385 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package s3 implements BLOB storage in S3-compatible object storage
+package s3
+
+import (
+	"crypto/sha512"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/blob"
+	"github.com/gravitational/gravity/lib/constants"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	awss3 "github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// TypeS3 is the Amazon S3 or another S3-compatible object storage
+	TypeS3 = "s3"
+	// TypeGCS is the Google Cloud Storage accessed via its
+	// S3-compatible XML API with HMAC keys
+	TypeGCS = "gcs"
+	// GCSEndpoint is the endpoint of the Google Cloud Storage XML API
+	GCSEndpoint = "https://storage.googleapis.com"
+)
+
+// API defines the subset of the object storage API used by the BLOB storage
+type API interface {
+	// HeadObject returns the metadata of the object
+	HeadObject(*awss3.HeadObjectInput) (*awss3.HeadObjectOutput, error)
+	// GetObject returns the object contents
+	GetObject(*awss3.GetObjectInput) (*awss3.GetObjectOutput, error)
+	// DeleteObject deletes the object
+	DeleteObject(*awss3.DeleteObjectInput) (*awss3.DeleteObjectOutput, error)
+	// ListObjectsV2Pages iterates over the objects in the bucket
+	ListObjectsV2Pages(*awss3.ListObjectsV2Input, func(*awss3.ListObjectsV2Output, bool) bool) error
+}
+
+// Uploader uploads objects to the object storage
+type Uploader interface {
+	// Upload uploads the object, in multiple parts if necessary
+	Upload(*s3manager.UploadInput, ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error)
+}
+
+// Config is the object storage configuration
+type Config struct {
+	// Type is the object storage type, either s3 or gcs
+	Type string
+	// Bucket is the name of the bucket with BLOBs
+	Bucket string
+	// Prefix is an optional key prefix for BLOBs in the bucket
+	Prefix string
+	// Region is the bucket region
+	Region string
+	// Endpoint is an optional custom endpoint of the object storage
+	Endpoint string
+	// AccessKeyID is an optional access key, the default credentials
+	// chain is used if unspecified
+	AccessKeyID string
+	// SecretAccessKey is the secret for the access key
+	SecretAccessKey string
+	// ForcePathStyle forces the path-style bucket addressing which
+	// is required by some S3-compatible storage
+	ForcePathStyle bool
+	// TempDir is the directory for BLOBs while their hash is computed
+	TempDir string
+	// API is the object storage client, created from the configuration
+	// if unspecified
+	API API
+	// Uploader uploads the BLOBs, created from the configuration
+	// if unspecified
+	Uploader Uploader
+}
+
+// CheckAndSetDefaults validates the configuration and sets defaults
+func (c *Config) CheckAndSetDefaults() error {
+	switch c.Type {
+	case TypeS3:
+	case TypeGCS:
+		if c.Endpoint == "" {
+			c.Endpoint = GCSEndpoint
+		}
+		if c.Region == "" {
+			c.Region = "auto"
+		}
+	default:
+		return trace.BadParameter("unsupported object storage type %q, supported are: %v, %v",
+			c.Type, TypeS3, TypeGCS)
+	}
+	if c.Bucket == "" {
+		return trace.BadParameter("missing object storage bucket")
+	}
+	if (c.AccessKeyID == "") != (c.SecretAccessKey == "") {
+		return trace.BadParameter("both access key ID and secret access key are required")
+	}
+	if c.TempDir == "" {
+		c.TempDir = os.TempDir()
+	}
+	c.Prefix = strings.Trim(c.Prefix, "/")
+	return nil
+}
+
+func (c *Config) newSession() (*session.Session, error) {
+	config := &aws.Config{
+		S3ForcePathStyle: aws.Bool(c.ForcePathStyle),
+	}
+	if c.Region != "" {
+		config.Region = aws.String(c.Region)
+	}
+	if c.Endpoint != "" {
+		config.Endpoint = aws.String(c.Endpoint)
+	}
+	if c.AccessKeyID != "" {
+		config.Credentials = credentials.NewStaticCredentials(c.AccessKeyID, c.SecretAccessKey, "")
+	}
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return sess, nil
+}
+
+// New returns BLOB storage backed by the bucket in S3-compatible object storage.
+// Unlike the cluster BLOB storage, the object storage is shared by all
+// cluster controllers so BLOBs do not need to be replicated between them
+func New(config Config) (blob.Objects, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if config.API == nil || config.Uploader == nil {
+		sess, err := config.newSession()
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		client := awss3.New(sess)
+		if config.API == nil {
+			config.API = client
+		}
+		if config.Uploader == nil {
+			config.Uploader = s3manager.NewUploaderWithClient(client)
+		}
+	}
+	return &objects{
+		Config: config,
+		Entry: log.WithFields(log.Fields{
+			trace.Component: constants.ComponentBLOB,
+			"bucket":        config.Bucket,
+		}),
+	}, nil
+}
+
+type objects struct {
+	Config
+	*log.Entry
+}
+
+func (o *objects) Close() error {
+	return nil
+}
+
+// key returns the object key of the BLOB identified by hash
+func (o *objects) key(hash string) string {
+	return path.Join(o.Prefix, "blobs", hash)
+}
+
+func (o *objects) keyPrefix() string {
+	return o.key("") + "/"
+}
+
+// GetBLOBs returns a list of BLOBs in the storage
+func (o *objects) GetBLOBs() ([]string, error) {
+	var out []string
+	err := o.API.ListObjectsV2Pages(&awss3.ListObjectsV2Input{
+		Bucket: aws.String(o.Bucket),
+		Prefix: aws.String(o.keyPrefix()),
+	}, func(page *awss3.ListObjectsV2Output, _ bool) bool {
+		for _, object := range page.Contents {
+			out = append(out, strings.TrimPrefix(aws.StringValue(object.Key), o.keyPrefix()))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, trace.Wrap(convertError(err))
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// WriteBLOB writes object to the storage, returns object envelope.
+// The data is staged in a temporary file to compute its hash
+// before it is uploaded
+func (o *objects) WriteBLOB(data io.Reader) (*blob.Envelope, error) {
+	f, err := ioutil.TempFile(o.TempDir, "blob")
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+	}()
+	hasher := sha512.New()
+	if _, err := io.Copy(io.MultiWriter(f, hasher), data); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	hash := fmt.Sprintf("%x", hasher.Sum(nil)[:sha512.Size/2])
+	envelope, err := o.GetBLOBEnvelope(hash)
+	if err == nil {
+		return envelope, nil
+	}
+	if !trace.IsNotFound(err) {
+		return nil, trace.Wrap(err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	_, err = o.Uploader.Upload(&s3manager.UploadInput{
+		Bucket: aws.String(o.Bucket),
+		Key:    aws.String(o.key(hash)),
+		Body:   f,
+	})
+	if err != nil {
+		return nil, trace.Wrap(convertError(err))
+	}
+	o.Debugf("Uploaded BLOB %v.", hash)
+	return o.GetBLOBEnvelope(hash)
+}
+
+// GetBLOBEnvelope returns file information identified by hash
+func (o *objects) GetBLOBEnvelope(hash string) (*blob.Envelope, error) {
+	resp, err := o.API.HeadObject(&awss3.HeadObjectInput{
+		Bucket: aws.String(o.Bucket),
+		Key:    aws.String(o.key(hash)),
+	})
+	if err != nil {
+		return nil, trace.Wrap(convertError(err))
+	}
+	return &blob.Envelope{
+		SizeBytes: aws.Int64Value(resp.ContentLength),
+		SHA512:    hash,
+		Modified:  aws.TimeValue(resp.LastModified).UTC(),
+	}, nil
+}
+
+// OpenBLOB opens file identified by hash and returns reader
+func (o *objects) OpenBLOB(hash string) (blob.ReadSeekCloser, error) {
+	envelope, err := o.GetBLOBEnvelope(hash)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &reader{
+		objects: o,
+		key:     o.key(hash),
+		size:    envelope.SizeBytes,
+	}, nil
+}
+
+// DeleteBLOB deletes BLOB from the storage
+func (o *objects) DeleteBLOB(hash string) error {
+	_, err := o.API.DeleteObject(&awss3.DeleteObjectInput{
+		Bucket: aws.String(o.Bucket),
+		Key:    aws.String(o.key(hash)),
+	})
+	if err != nil {
+		return trace.Wrap(convertError(err))
+	}
+	return nil
+}
+
+// reader reads the object contents starting at the current offset.
+// Seeking discards the current object stream and the next read
+// requests the object contents from the new offset
+type reader struct {
+	*objects
+	key    string
+	size   int64
+	offset int64
+	body   io.ReadCloser
+}
+
+// Read reads the object contents into p
+func (r *reader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+	if r.body == nil {
+		resp, err := r.API.GetObject(&awss3.GetObjectInput{
+			Bucket: aws.String(r.Bucket),
+			Key:    aws.String(r.key),
+			Range:  aws.String(fmt.Sprintf("bytes=%v-", r.offset)),
+		})
+		if err != nil {
+			return 0, trace.Wrap(convertError(err))
+		}
+		r.body = resp.Body
+	}
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+	return n, err
+}
+
+// Seek sets the offset for the next read
+func (r *reader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, trace.BadParameter("unsupported whence: %v", whence)
+	}
+	if offset < 0 {
+		return 0, trace.BadParameter("negative offset: %v", offset)
+	}
+	if offset != r.offset {
+		r.closeBody()
+		r.offset = offset
+	}
+	return r.offset, nil
+}
+
+// Close closes the reader
+func (r *reader) Close() error {
+	r.closeBody()
+	return nil
+}
+
+func (r *reader) closeBody() {
+	if r.body != nil {
+		r.body.Close()
+		r.body = nil
+	}
+}
+
+// convertError converts the object storage error to
+// the corresponding trace error
+func convertError(err error) error {
+	awsErr, ok := err.(awserr.Error)
+	if !ok {
+		return err
+	}
+	notFound := false
+	switch awsErr.Code() {
+	case awss3.ErrCodeNoSuchKey, awss3.ErrCodeNoSuchBucket, "NotFound":
+		notFound = true
+	}
+	if reqErr, ok := err.(awserr.RequestFailure); ok && reqErr.StatusCode() == http.StatusNotFound {
+		notFound = true
+	}
+	if !notFound {
+		return err
+	}
+	// HEAD responses have no body so the message can be empty
+	if awsErr.Message() == "" {
+		return trace.NotFound("%v", awsErr.Error())
+	}
+	return trace.NotFound("%v", awsErr.Message())
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package s3
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gravitational/gravity/lib/blob/suite"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awss3 "github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	. "gopkg.in/check.v1"
+)
+
+func TestS3(t *testing.T) { TestingT(t) }
+
+type S3Suite struct {
+	suite  suite.BLOBSuite
+	bucket *fakeBucket
+}
+
+var _ = Suite(&S3Suite{})
+
+func (s *S3Suite) SetUpTest(c *C) {
+	s.bucket = &fakeBucket{objects: make(map[string]fakeObject)}
+	obj, err := New(Config{
+		Type:     TypeS3,
+		Bucket:   "packages",
+		Prefix:   "/cluster/",
+		TempDir:  c.MkDir(),
+		API:      s.bucket,
+		Uploader: s.bucket,
+	})
+	c.Assert(err, IsNil)
+	s.suite.Objects = obj
+}
+
+func (s *S3Suite) TestBLOB(c *C) {
+	s.suite.BLOB(c)
+}
+
+func (s *S3Suite) TestBLOBSeek(c *C) {
+	s.suite.BLOBSeek(c)
+}
+
+func (s *S3Suite) TestBLOBWriteTwice(c *C) {
+	s.suite.BLOBWriteTwice(c)
+	c.Assert(s.bucket.uploads, Equals, 1)
+}
+
+func (s *S3Suite) TestBLOBList(c *C) {
+	s.suite.BLOBList(c)
+}
+
+func (s *S3Suite) TestUsesPrefix(c *C) {
+	e, err := s.suite.Objects.WriteBLOB(bytes.NewBufferString("hello"))
+	c.Assert(err, IsNil)
+	c.Assert(s.bucket.keys(), DeepEquals, []string{"cluster/blobs/" + e.SHA512})
+}
+
+func (s *S3Suite) TestSeeksWithinBLOB(c *C) {
+	e, err := s.suite.Objects.WriteBLOB(bytes.NewBufferString("hello, world"))
+	c.Assert(err, IsNil)
+	r, err := s.suite.Objects.OpenBLOB(e.SHA512)
+	c.Assert(err, IsNil)
+	defer r.Close()
+
+	_, err = r.Seek(7, io.SeekStart)
+	c.Assert(err, IsNil)
+	out, err := ioutil.ReadAll(r)
+	c.Assert(err, IsNil)
+	c.Assert(string(out), Equals, "world")
+
+	_, err = r.Seek(-5, io.SeekEnd)
+	c.Assert(err, IsNil)
+	buf := make([]byte, 3)
+	_, err = io.ReadFull(r, buf)
+	c.Assert(err, IsNil)
+	c.Assert(string(buf), Equals, "wor")
+}
+
+func (s *S3Suite) TestValidatesConfig(c *C) {
+	var tcs = []struct {
+		config  Config
+		comment string
+	}{
+		{config: Config{Type: "ftp", Bucket: "packages"}, comment: "unsupported type"},
+		{config: Config{Type: TypeS3}, comment: "missing bucket"},
+		{config: Config{Type: TypeS3, Bucket: "packages", AccessKeyID: "id"}, comment: "missing secret"},
+	}
+	for _, tc := range tcs {
+		c.Assert(tc.config.CheckAndSetDefaults(), NotNil, Commentf(tc.comment))
+	}
+
+	config := Config{Type: TypeGCS, Bucket: "packages"}
+	c.Assert(config.CheckAndSetDefaults(), IsNil)
+	c.Assert(config.Endpoint, Equals, GCSEndpoint)
+}
+
+type fakeObject struct {
+	data     []byte
+	modified time.Time
+}
+
+// fakeBucket is an in-memory object storage bucket
+type fakeBucket struct {
+	sync.Mutex
+	objects map[string]fakeObject
+	uploads int
+}
+
+func (b *fakeBucket) keys() (keys []string) {
+	b.Lock()
+	defer b.Unlock()
+	for key := range b.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (b *fakeBucket) get(key *string) (*fakeObject, error) {
+	b.Lock()
+	defer b.Unlock()
+	object, ok := b.objects[aws.StringValue(key)]
+	if !ok {
+		return nil, awserr.NewRequestFailure(awserr.New("NotFound", "", nil), http.StatusNotFound, "")
+	}
+	return &object, nil
+}
+
+func (b *fakeBucket) HeadObject(input *awss3.HeadObjectInput) (*awss3.HeadObjectOutput, error) {
+	object, err := b.get(input.Key)
+	if err != nil {
+		return nil, err
+	}
+	return &awss3.HeadObjectOutput{
+		ContentLength: aws.Int64(int64(len(object.data))),
+		LastModified:  aws.Time(object.modified),
+	}, nil
+}
+
+func (b *fakeBucket) GetObject(input *awss3.GetObjectInput) (*awss3.GetObjectOutput, error) {
+	object, err := b.get(input.Key)
+	if err != nil {
+		return nil, err
+	}
+	var offset int
+	if input.Range != nil {
+		var n int64
+		_, err := fmt.Sscanf(aws.StringValue(input.Range), "bytes=%d-", &n)
+		if err != nil {
+			return nil, err
+		}
+		offset = int(n)
+	}
+	return &awss3.GetObjectOutput{
+		Body: ioutil.NopCloser(bytes.NewReader(object.data[offset:])),
+	}, nil
+}
+
+func (b *fakeBucket) DeleteObject(input *awss3.DeleteObjectInput) (*awss3.DeleteObjectOutput, error) {
+	b.Lock()
+	defer b.Unlock()
+	delete(b.objects, aws.StringValue(input.Key))
+	return &awss3.DeleteObjectOutput{}, nil
+}
+
+func (b *fakeBucket) ListObjectsV2Pages(input *awss3.ListObjectsV2Input, fn func(*awss3.ListObjectsV2Output, bool) bool) error {
+	var contents []*awss3.Object
+	for _, key := range b.keys() {
+		if strings.HasPrefix(key, aws.StringValue(input.Prefix)) {
+			contents = append(contents, &awss3.Object{Key: aws.String(key)})
+		}
+	}
+	fn(&awss3.ListObjectsV2Output{Contents: contents}, true)
+	return nil
+}
+
+func (b *fakeBucket) Upload(input *s3manager.UploadInput, _ ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error) {
+	data, err := ioutil.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	b.Lock()
+	defer b.Unlock()
+	b.uploads++
+	b.objects[aws.StringValue(input.Key)] = fakeObject{
+		data:     data,
+		modified: time.Now().Truncate(time.Second),
+	}
+	return &s3manager.UploadOutput{}, nil
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package buildcache implements the content-addressed cache of build artifacts.
+//
+// The cache stores blobs (image layers, image configurations, manifests and
+// other build artifacts) keyed by the digest of their contents, and references
+// that map an arbitrary key, e.g. a Docker image ID, to the descriptor
+// of a cached blob. The cache directory can be shared between builds
+// and persisted between CI runs
+package buildcache
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/gravitational/gravity/lib/defaults"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/context"
+	"github.com/gravitational/trace"
+	"github.com/opencontainers/go-digest"
+)
+
+// New returns a new cache that stores its contents in the specified directory
+func New(dir string) (*Cache, error) {
+	for _, subdir := range []string{blobsDir, refsDir} {
+		if err := os.MkdirAll(filepath.Join(dir, subdir), defaults.SharedDirMask); err != nil {
+			return nil, trace.ConvertSystemError(err)
+		}
+	}
+	return &Cache{dir: dir}, nil
+}
+
+// Cache is a content-addressed store of build artifacts.
+//
+// Cache implements distribution.BlobProvider and distribution.BlobStatter
+// so it can be used as a source of blobs when storing images
+type Cache struct {
+	dir string
+}
+
+// Dir returns the cache directory
+func (r *Cache) Dir() string {
+	return r.dir
+}
+
+// Stat returns the descriptor of the blob with the specified digest
+func (r *Cache) Stat(ctx context.Context, dgst digest.Digest) (distribution.Descriptor, error) {
+	path, err := r.blobPath(dgst)
+	if err != nil {
+		return distribution.Descriptor{}, trace.Wrap(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return distribution.Descriptor{}, trace.ConvertSystemError(err)
+	}
+	return distribution.Descriptor{
+		Digest: dgst,
+		Size:   fi.Size(),
+	}, nil
+}
+
+// Get returns the contents of the blob with the specified digest
+func (r *Cache) Get(ctx context.Context, dgst digest.Digest) ([]byte, error) {
+	path, err := r.blobPath(dgst)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	return data, nil
+}
+
+// Open returns the reader for the blob with the specified digest
+func (r *Cache) Open(ctx context.Context, dgst digest.Digest) (distribution.ReadSeekCloser, error) {
+	path, err := r.blobPath(dgst)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	return f, nil
+}
+
+// Put stores the contents of the specified reader as the blob with the specified
+// digest. The contents are verified against the digest before they are
+// added to the cache so the cache never serves corrupted blobs
+func (r *Cache) Put(dgst digest.Digest, reader io.Reader) error {
+	path, err := r.blobPath(dgst)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), defaults.SharedDirMask); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), tempPrefix)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer os.Remove(f.Name())
+	verifier := dgst.Verifier()
+	_, err = io.Copy(f, io.TeeReader(reader, verifier))
+	if errClose := f.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	if !verifier.Verified() {
+		return trace.BadParameter("contents do not match the digest %v", dgst)
+	}
+	if err := os.Chmod(f.Name(), defaults.SharedReadMask); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	// concurrent builds that store the same blob write the same contents
+	return trace.ConvertSystemError(os.Rename(f.Name(), path))
+}
+
+// GetRef returns the descriptor the reference with the specified key points to
+func (r *Cache) GetRef(key string) (*distribution.Descriptor, error) {
+	data, err := ioutil.ReadFile(r.refPath(key))
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	var desc distribution.Descriptor
+	if err := json.Unmarshal(data, &desc); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	// the reference is only valid as long as the blob it points to exists
+	if _, err := r.Stat(context.Background(), desc.Digest); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &desc, nil
+}
+
+// PutRef creates or updates the reference with the specified key
+// to point to the blob with the specified descriptor
+func (r *Cache) PutRef(key string, desc distribution.Descriptor) error {
+	if _, err := r.Stat(context.Background(), desc.Digest); err != nil {
+		return trace.Wrap(err)
+	}
+	data, err := json.Marshal(desc)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	f, err := ioutil.TempFile(filepath.Join(r.dir, refsDir), tempPrefix)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(data)
+	if errClose := f.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	return trace.ConvertSystemError(os.Rename(f.Name(), r.refPath(key)))
+}
+
+// blobPath returns the path to the blob with the specified digest.
+// Blobs are stored as blobs/<algorithm>/<first two characters of the hex>/<hex>
+func (r *Cache) blobPath(dgst digest.Digest) (string, error) {
+	if err := dgst.Validate(); err != nil {
+		return "", trace.BadParameter("invalid digest %q: %v", dgst, err)
+	}
+	hex := dgst.Hex()
+	return filepath.Join(r.dir, blobsDir, string(dgst.Algorithm()), hex[:2], hex), nil
+}
+
+// refPath returns the path to the reference with the specified key.
+// Keys are hashed as they can contain characters not allowed in file names
+func (r *Cache) refPath(key string) string {
+	return filepath.Join(r.dir, refsDir, digest.FromString(key).Hex())
+}
+
+const (
+	// blobsDir is the cache subdirectory with blobs
+	blobsDir = "blobs"
+	// refsDir is the cache subdirectory with references
+	refsDir = "refs"
+	// tempPrefix is the prefix of the temporary files the blobs and
+	// references are written to before they are moved into place
+	tempPrefix = ".tmp-"
+)
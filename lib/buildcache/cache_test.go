@@ -0,0 +1,90 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildcache
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/context"
+	"github.com/gravitational/trace"
+	"github.com/opencontainers/go-digest"
+	. "gopkg.in/check.v1"
+)
+
+func TestBuildCache(t *testing.T) { TestingT(t) }
+
+type CacheSuite struct {
+	cache *Cache
+}
+
+var _ = Suite(&CacheSuite{})
+
+func (s *CacheSuite) SetUpTest(c *C) {
+	var err error
+	s.cache, err = New(c.MkDir())
+	c.Assert(err, IsNil)
+}
+
+func (s *CacheSuite) TestStoresBlobs(c *C) {
+	ctx := context.Background()
+	dgst := digest.FromString("layer")
+	_, err := s.cache.Stat(ctx, dgst)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+
+	c.Assert(s.cache.Put(dgst, strings.NewReader("layer")), IsNil)
+	desc, err := s.cache.Stat(ctx, dgst)
+	c.Assert(err, IsNil)
+	c.Assert(desc, DeepEquals, distribution.Descriptor{Digest: dgst, Size: 5})
+
+	data, err := s.cache.Get(ctx, dgst)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "layer")
+
+	reader, err := s.cache.Open(ctx, dgst)
+	c.Assert(err, IsNil)
+	defer reader.Close()
+	data, err = ioutil.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "layer")
+}
+
+func (s *CacheSuite) TestRefusesCorruptedBlobs(c *C) {
+	dgst := digest.FromString("layer")
+	err := s.cache.Put(dgst, strings.NewReader("corrupted"))
+	c.Assert(trace.IsBadParameter(err), Equals, true, Commentf("%v", err))
+	_, err = s.cache.Stat(context.Background(), dgst)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+}
+
+func (s *CacheSuite) TestStoresReferences(c *C) {
+	dgst := digest.FromString("manifest")
+	desc := distribution.Descriptor{MediaType: "application/json", Digest: dgst, Size: 8}
+	// references can only point to the cached blobs
+	c.Assert(s.cache.PutRef("docker-image/sha256:1234", desc), NotNil)
+
+	c.Assert(s.cache.Put(dgst, strings.NewReader("manifest")), IsNil)
+	c.Assert(s.cache.PutRef("docker-image/sha256:1234", desc), IsNil)
+	ref, err := s.cache.GetRef("docker-image/sha256:1234")
+	c.Assert(err, IsNil)
+	c.Assert(*ref, DeepEquals, desc)
+
+	_, err = s.cache.GetRef("docker-image/sha256:5678")
+	c.Assert(trace.IsNotFound(err), Equals, true)
+}
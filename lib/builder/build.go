@@ -21,9 +21,12 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"runtime"
 
+	"github.com/gravitational/gravity/lib/builder/plugin"
 	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/pack"
 	"github.com/gravitational/gravity/lib/schema"
 	"github.com/gravitational/gravity/lib/utils"
 
@@ -34,7 +37,7 @@ import (
 
 // Build builds the standalone application installer using the provided builder
 func Build(ctx context.Context, builder *Builder) error {
-	err := checkBuildEnv()
+	err := checkBuildEnv(!builder.VendorReq.PullFromRegistry)
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -48,20 +51,34 @@ func Build(ctx context.Context, builder *Builder) error {
 		}
 	}
 
+	if builder.BaseImage != nil {
+		if builder.Manifest.Kind == schema.KindApplication {
+			return trace.BadParameter("delta images can only be built for cluster images")
+		}
+		err = pack.CheckUpdatePackage(builder.BaseImage.Package, locator)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
+	var pushSteps int
+	if builder.PushTo != "" {
+		pushSteps = 1
+	}
 	switch builder.Manifest.Kind {
-	case schema.KindBundle, schema.KindCluster:
+	case schema.KindBundle, schema.KindCluster, schema.KindPlatform:
 		builder.Config.Progress = utils.NewProgress(ctx, "Build",
-			clusterBuildSteps, builder.Config.Silent)
+			clusterBuildSteps+pushSteps, builder.Config.Silent)
 	case schema.KindApplication:
 		builder.Config.Progress = utils.NewProgress(ctx, "Build",
-			appBuildSteps, builder.Config.Silent)
+			appBuildSteps+pushSteps, builder.Config.Silent)
 	default:
 		return trace.BadParameter("unknown manifest kind %q",
 			builder.Manifest.Kind)
 	}
 
 	switch builder.Manifest.Kind {
-	case schema.KindBundle, schema.KindCluster:
+	case schema.KindBundle, schema.KindCluster, schema.KindPlatform:
 		builder.NextStep("Selecting base image version")
 		runtimeVersion, err := builder.SelectRuntime()
 		if err != nil {
@@ -78,6 +95,10 @@ func Build(ctx context.Context, builder *Builder) error {
 			}
 			return trace.Wrap(err)
 		}
+		err = builder.SyncArchPackages(runtimeVersion)
+		if err != nil {
+			return trace.Wrap(err)
+		}
 	}
 
 	builder.NextStep("Embedding application container images")
@@ -111,16 +132,38 @@ func Build(ctx context.Context, builder *Builder) error {
 		return trace.Wrap(err)
 	}
 
+	if len(builder.Plugins) != 0 {
+		imagePath, err := filepath.Abs(builder.OutPath)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		err = builder.runPlugins(ctx, plugin.HookPostPackage, builder.pluginBuild("", imagePath))
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
+	if builder.PushTo != "" {
+		builder.NextStep("Publishing the image to %v", builder.PushTo)
+		err = builder.PushImage(ctx)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
 	return nil
 }
 
 // checkBuildEnv makes sure that the environment "tele build" is invoked in is
-// suitable, for example, OS is supported and Docker is running
-func checkBuildEnv() error {
+// suitable, for example, OS is supported and Docker is running if required
+func checkBuildEnv(requireDocker bool) error {
 	if runtime.GOOS != "linux" {
 		return trace.BadParameter("tele build is not supported on %v, only "+
 			"Linux is supported", runtime.GOOS)
 	}
+	if !requireDocker {
+		return nil
+	}
 	client, err := docker.NewClient(constants.DockerEngineURL)
 	if err != nil {
 		return trace.Wrap(err)
@@ -18,26 +18,36 @@ package builder
 
 import (
 	"context"
+	"crypto"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/url"
 	"os"
+	"os/exec"
+	"os/user"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/gravitational/gravity/lib/app"
 	"github.com/gravitational/gravity/lib/app/service"
+	archiveutils "github.com/gravitational/gravity/lib/archive"
 	blobfs "github.com/gravitational/gravity/lib/blob/fs"
+	"github.com/gravitational/gravity/lib/builder/plugin"
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/helm"
 	"github.com/gravitational/gravity/lib/loc"
 	"github.com/gravitational/gravity/lib/localenv"
 	"github.com/gravitational/gravity/lib/localenv/credentials"
 	"github.com/gravitational/gravity/lib/pack"
 	"github.com/gravitational/gravity/lib/pack/layerpack"
 	"github.com/gravitational/gravity/lib/pack/localpack"
+	"github.com/gravitational/gravity/lib/provenance"
+	"github.com/gravitational/gravity/lib/sbom"
+	"github.com/gravitational/gravity/lib/scan"
 	"github.com/gravitational/gravity/lib/schema"
 	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/storage/keyval"
@@ -49,6 +59,7 @@ import (
 	"github.com/ghodss/yaml"
 	"github.com/gravitational/trace"
 	"github.com/gravitational/version"
+	"github.com/mailgun/timetools"
 	"github.com/sirupsen/logrus"
 )
 
@@ -92,6 +103,31 @@ type Config struct {
 	utils.Progress
 	// Silent suppresses all std output when set to true
 	Silent bool
+	// BaseImage optionally specifies the cluster image to build
+	// a delta cluster image against
+	BaseImage *BaseImage
+	// Scanner optionally specifies the vulnerability scanner to scan
+	// the application images with. The scan report is embedded into the image
+	Scanner scan.Scanner
+	// ScanPolicy defines the vulnerability thresholds that fail the build
+	ScanPolicy scan.Policy
+	// PushTo optionally specifies the registry repository to publish
+	// the built image to as an artifact, e.g. registry.example.com/org/name
+	PushTo string
+	// Timestamp optionally makes the build reproducible: it is recorded
+	// in the image instead of the current time, so the images built
+	// from identical inputs are byte-identical
+	Timestamp time.Time
+	// Plugins lists the plugins that hook into the build
+	Plugins []plugin.Plugin
+	// SigningKey optionally specifies the key to sign the build provenance
+	// with. The signed provenance is embedded into the image
+	SigningKey crypto.Signer
+	// BuilderID optionally identifies the builder in the provenance.
+	// Defaults to user@host
+	BuilderID string
+	// Arguments lists the build command line arguments recorded in the provenance
+	Arguments []string
 }
 
 // CheckAndSetDefaults validates builder config and fills in defaults
@@ -120,6 +156,9 @@ func (c *Config) CheckAndSetDefaults() error {
 	if c.VendorReq.Parallel == 0 {
 		c.VendorReq.Parallel = runtime.NumCPU()
 	}
+	if err := checkArchitectures(c.VendorReq); err != nil {
+		return trace.Wrap(err)
+	}
 	if c.Generator == nil {
 		c.Generator = &generator{}
 	}
@@ -172,7 +211,7 @@ func New(config Config) (*Builder, error) {
 		if err != nil {
 			return nil, trace.Wrap(err)
 		}
-		manifest, err = generateManifest(chart)
+		manifest, err = helm.GenerateManifest(chart)
 		if err != nil {
 			return nil, trace.Wrap(err)
 		}
@@ -240,11 +279,8 @@ func (b *Builder) SelectRuntime() (*semver.Version, error) {
 	if runtime == nil {
 		return nil, trace.NotFound("failed to determine application runtime")
 	}
-	switch runtime.Name {
-	case constants.BaseImageName, defaults.Runtime:
-	default:
-		return nil, trace.BadParameter("unsupported base image %q, only %q is "+
-			"supported as a base image", runtime.Name, constants.BaseImageName)
+	if b.layered() {
+		return b.selectLayeredRuntime(*runtime)
 	}
 	// If runtime version is explicitly set in the manifest, use it.
 	if runtime.Version != loc.LatestVersion {
@@ -263,6 +299,74 @@ func (b *Builder) SelectRuntime() (*semver.Version, error) {
 	return teleVersion, nil
 }
 
+// selectLayeredRuntime makes sure that the cluster image specified as the base
+// image of the layered image being built is present in the local cache directory
+// and returns the version of the runtime the base image is built with
+func (b *Builder) selectLayeredRuntime(base loc.Locator) (*semver.Version, error) {
+	if base.Version == loc.LatestVersion {
+		return nil, trace.BadParameter("base cluster image %v should specify "+
+			"the version to build on top of", base.Name)
+	}
+	apps, err := b.Env.AppServiceLocal(localenv.AppConfig{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	_, err = apps.GetApp(base)
+	if err != nil && !trace.IsNotFound(err) {
+		return nil, trace.Wrap(err)
+	}
+	if err != nil {
+		repository, err := b.GetRepository(b)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		b.Infof("Downloading base cluster image %v from %v.", base, repository)
+		b.PrintSubStep("Downloading base cluster image %v from %v", base, repository)
+		syncer, err := b.NewSyncer(b)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if err := syncer.Sync(b, nil); err != nil {
+			if trace.IsNotFound(err) {
+				return nil, trace.NotFound("base cluster image %v not found", base)
+			}
+			return nil, trace.Wrap(err)
+		}
+	}
+	baseApp, err := apps.GetApp(base)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	switch baseApp.Manifest.Kind {
+	case schema.KindBundle, schema.KindCluster, schema.KindPlatform:
+	default:
+		return nil, trace.BadParameter("base image %v is not a cluster image", base)
+	}
+	bases, err := app.GetBaseImages(*baseApp, apps)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	runtimeVersion := bases[0].Package.Version
+	b.Infof("Using runtime version %s of base cluster image %v.", runtimeVersion, base)
+	b.PrintSubStep("Will build on top of cluster image %v with base image version %s",
+		base, runtimeVersion)
+	return semver.NewVersion(runtimeVersion)
+}
+
+// layered returns true if the image is built on top of another cluster image
+// instead of the base image directly
+func (b *Builder) layered() bool {
+	base := b.Manifest.Base()
+	if base == nil {
+		return false
+	}
+	switch base.Name {
+	case constants.BaseImageName, defaults.Runtime:
+		return false
+	}
+	return true
+}
+
 // SyncPackageCache ensures that all system dependencies are present in
 // the local cache directory
 func (b *Builder) SyncPackageCache(runtimeVersion *semver.Version) error {
@@ -270,8 +374,11 @@ func (b *Builder) SyncPackageCache(runtimeVersion *semver.Version) error {
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	// see if all required packages/apps are already present in the local cache
-	b.Manifest.SetBase(loc.Runtime.WithVersion(runtimeVersion))
+	// see if all required packages/apps are already present in the local cache.
+	// A layered image keeps the cluster image it is built on top of as its base
+	if !b.layered() {
+		b.Manifest.SetBase(loc.Runtime.WithVersion(runtimeVersion))
+	}
 	err = app.VerifyDependencies(&app.Application{
 		Manifest: b.Manifest,
 		Package:  b.Manifest.Locator(),
@@ -297,6 +404,89 @@ func (b *Builder) SyncPackageCache(runtimeVersion *semver.Version) error {
 	return syncer.Sync(b, runtimeVersion)
 }
 
+// SyncArchPackages ensures that the runtime packages built for the CPU
+// architectures other than amd64 the image supports are present in the local
+// cache directory and adds them to the image dependencies
+func (b *Builder) SyncArchPackages(runtimeVersion *semver.Version) error {
+	apps, err := b.Env.AppServiceLocal(localenv.AppConfig{})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	base := b.Manifest.Base()
+	if base == nil {
+		return trace.NotFound("%v does not have a base image", b.Manifest.Locator())
+	}
+	runtimeApp, err := apps.GetApp(*base)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	packages, err := archPackages(runtimeApp.Manifest, b.VendorReq.Architectures)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if len(packages) == 0 {
+		return nil
+	}
+	for _, pkg := range packages {
+		if _, err := b.Manifest.Dependencies.ByName(pkg.Name); err != nil {
+			b.Manifest.Dependencies.Packages = append(b.Manifest.Dependencies.Packages,
+				schema.Dependency{Locator: pkg})
+		}
+	}
+	b.VendorReq.ArchPackages = packages
+	err = app.VerifyDependencies(&app.Application{
+		Manifest: b.Manifest,
+		Package:  b.Manifest.Locator(),
+	}, apps, b.Env.Packages)
+	if err == nil || !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	b.Infof("Synchronizing packages for architectures %v.", b.VendorReq.Architectures)
+	syncer, err := b.NewSyncer(b)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return syncer.Sync(b, runtimeVersion)
+}
+
+// archPackages returns the gravity and planet packages from the specified
+// runtime application manifest built for the architectures other than amd64
+func archPackages(runtimeManifest schema.Manifest, architectures []string) (packages []loc.Locator, err error) {
+	gravityPackage, err := runtimeManifest.Dependencies.ByName(constants.GravityPackage)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	runtimePackage, err := runtimeManifest.DefaultRuntimePackage()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, arch := range architectures {
+		if arch == constants.ArchAMD64 {
+			continue
+		}
+		packages = append(packages, gravityPackage.ForArch(arch), runtimePackage.ForArch(arch))
+	}
+	return packages, nil
+}
+
+// checkArchitectures validates the CPU architectures to build the image for
+func checkArchitectures(req service.VendorRequest) error {
+	for _, arch := range req.Architectures {
+		if !utils.StringInSlice(supportedArchitectures, arch) {
+			return trace.BadParameter("unsupported architecture %q, supported architectures: %v",
+				arch, strings.Join(supportedArchitectures, ", "))
+		}
+	}
+	if len(req.Architectures) > 1 && !req.PullFromRegistry {
+		return trace.BadParameter("building multi-architecture images requires " +
+			"pulling the images from registries")
+	}
+	return nil
+}
+
+// supportedArchitectures lists the CPU architectures the images can be built for
+var supportedArchitectures = []string{constants.ArchAMD64, constants.ArchARM64}
+
 // Vendor vendors the application images in the provided directory and
 // returns the compressed data stream with the application data
 func (b *Builder) Vendor(ctx context.Context, dir string) (io.ReadCloser, error) {
@@ -318,6 +508,12 @@ func (b *Builder) Vendor(ctx context.Context, dir string) (io.ReadCloser, error)
 			return nil, trace.Wrap(err)
 		}
 	}
+	// the resources generated by plugins can refer to container images
+	// so the plugins are invoked before the images are vendored
+	err = b.runPlugins(ctx, plugin.HookPrePackage, b.pluginBuild(dir, ""))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
 	vendorer, err := service.NewVendorer(service.VendorerConfig{
 		DockerURL:   constants.DockerEngineURL,
 		RegistryURL: constants.DockerRegistry,
@@ -329,11 +525,246 @@ func (b *Builder) Vendor(ctx context.Context, dir string) (io.ReadCloser, error)
 	vendorReq := b.VendorReq
 	vendorReq.ManifestPath = manifestPath
 	vendorReq.ProgressReporter = b.Progress
+	vendorReq.Insecure = b.Insecure
 	err = vendorer.VendorDir(ctx, dir, vendorReq)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
-	return archive.Tar(dir, archive.Uncompressed)
+	if b.Scanner != nil {
+		if err := b.scanImages(ctx, dir); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	if err := b.generateSBOM(ctx, dir, manifestPath); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if b.SigningKey != nil {
+		if err := b.attest(ctx, dir, manifestPath); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	if b.Timestamp.IsZero() {
+		return archive.Tar(dir, archive.Uncompressed)
+	}
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(archiveutils.CompressDirectoryReproducible(dir, writer, b.Timestamp))
+	}()
+	return reader, nil
+}
+
+// generateSBOM generates the software bill of materials with all packages
+// and container images of the image and stores it with the application resources
+func (b *Builder) generateSBOM(ctx context.Context, dir, manifestPath string) error {
+	b.PrintSubStep("Generating software bill of materials")
+	manifest, err := schema.ParseManifest(manifestPath)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	locator := b.Locator()
+	doc := sbom.Document{
+		Name:    locator.Name,
+		Version: locator.Version,
+		Created: b.now(),
+	}
+	switch manifest.Kind {
+	case schema.KindBundle, schema.KindCluster, schema.KindPlatform:
+		dependencies, err := app.GetDependencies(&app.Application{
+			Package:  locator,
+			Manifest: *manifest,
+		}, b.Apps)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		components, err := sbom.PackageComponents(b.Packages,
+			append(dependencies.Packages, dependencies.Apps...))
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		doc.Components = append(doc.Components, components...)
+	}
+	registryDir := filepath.Join(dir, defaults.RegistryDir)
+	if ok, _ := utils.IsDirectory(registryDir); ok {
+		components, err := sbom.ImageComponents(ctx, registryDir)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		doc.Components = append(doc.Components, components...)
+	}
+	doc.Sort()
+	f, err := os.Create(filepath.Join(dir, defaults.ResourcesDir, defaults.SBOMFilename))
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	return trace.Wrap(sbom.Encode(f, doc, sbom.FormatCycloneDX))
+}
+
+// attest records the build provenance with the digests of the image manifest,
+// packages and container images, signs it with the configured key
+// and stores the attestation with the application resources
+func (b *Builder) attest(ctx context.Context, dir, manifestPath string) error {
+	b.PrintSubStep("Signing build provenance")
+	manifest, err := schema.ParseManifest(manifestPath)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	locator := b.Locator()
+	var materials []provenance.Material
+	if ok, _ := utils.IsFile(b.ManifestPath); ok {
+		material, err := provenance.ManifestMaterial(b.ManifestPath)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		materials = append(materials, *material)
+	}
+	switch manifest.Kind {
+	case schema.KindBundle, schema.KindCluster, schema.KindPlatform:
+		dependencies, err := app.GetDependencies(&app.Application{
+			Package:  locator,
+			Manifest: *manifest,
+		}, b.Apps)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		packages, err := provenance.PackageMaterials(b.Packages,
+			append(dependencies.Packages, dependencies.Apps...))
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		materials = append(materials, packages...)
+	}
+	images, err := provenance.ImageMaterials(ctx, filepath.Join(dir, defaults.RegistryDir))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	materials = append(materials, images...)
+	provenance.SortMaterials(materials)
+	attestation, err := provenance.Sign(provenance.Provenance{
+		Image: locator.String(),
+		Builder: provenance.Builder{
+			ID:      b.builderID(),
+			Version: version.Get().Version,
+		},
+		Source:    b.source(ctx),
+		Arguments: b.Arguments,
+		Materials: materials,
+		Finished:  b.now(),
+	}, b.SigningKey)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	f, err := os.Create(filepath.Join(dir, defaults.ResourcesDir, defaults.AttestationFilename))
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	return trace.Wrap(provenance.Encode(f, *attestation))
+}
+
+// builderID returns the builder identity recorded in the provenance
+func (b *Builder) builderID() string {
+	if b.BuilderID != "" {
+		return b.BuilderID
+	}
+	hostname, _ := os.Hostname()
+	current, err := user.Current()
+	if err != nil {
+		return hostname
+	}
+	return fmt.Sprintf("%v@%v", current.Username, hostname)
+}
+
+// source returns the git repository and revision of the image manifest.
+// Returns an empty source if the manifest is not in a git repository
+func (b *Builder) source(ctx context.Context) (source provenance.Source) {
+	git := func(args ...string) string {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = b.manifestDir
+		out, err := cmd.Output()
+		if err != nil {
+			b.WithError(err).Debugf("Failed to run git %v.", args)
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+	source.Revision = git("rev-parse", "HEAD")
+	if source.Revision != "" {
+		source.URI = git("config", "--get", "remote.origin.url")
+	}
+	return source
+}
+
+// scanImages scans the vendored application images for vulnerabilities,
+// embeds the scan report into the application resources and verifies
+// the report against the scan policy
+func (b *Builder) scanImages(ctx context.Context, dir string) error {
+	registryDir := filepath.Join(dir, defaults.RegistryDir)
+	if ok, _ := utils.IsDirectory(registryDir); !ok {
+		b.Info("No registry directory is present - skipping vulnerability scan.")
+		return nil
+	}
+	b.PrintSubStep("Scanning container images for vulnerabilities with %v", b.Scanner.Name())
+	report, err := scan.ScanDir(ctx, scan.Config{
+		Dir:         registryDir,
+		Scanner:     b.Scanner,
+		Progress:    b.Progress,
+		FieldLogger: b.FieldLogger,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = scan.WriteReport(filepath.Join(dir, defaults.ResourcesDir, defaults.ScanReportFilename), *report)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	b.PrintSubStep("Found vulnerabilities: %v", scan.FormatCounts(report.Counts()))
+	return trace.Wrap(b.ScanPolicy.Check(*report))
+}
+
+// runPlugins invokes the specified hook of the configured plugins in order
+func (b *Builder) runPlugins(ctx context.Context, hook plugin.Hook, build plugin.Build) error {
+	for _, p := range b.Plugins {
+		b.PrintSubStep("Running %v hook of plugin %v", hook, p.Name())
+		b.WithField("plugin", p.Name()).Infof("Run %v hook.", hook)
+		var err error
+		switch hook {
+		case plugin.HookPrePackage:
+			err = p.PrePackage(ctx, build)
+		case plugin.HookPostPackage:
+			err = p.PostPackage(ctx, build)
+		default:
+			return trace.BadParameter("unknown plugin hook %q", hook)
+		}
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// pluginBuild describes the build to the plugins given the vendor directory
+// and the path to the built image
+func (b *Builder) pluginBuild(dir, imagePath string) plugin.Build {
+	locator := b.Locator()
+	build := plugin.Build{
+		Name:      locator.Name,
+		Version:   locator.Version,
+		ImagePath: imagePath,
+	}
+	if dir != "" {
+		build.ResourcesDir = filepath.Join(dir, defaults.ResourcesDir)
+		build.ManifestPath = filepath.Join(build.ResourcesDir, defaults.ManifestFileName)
+	}
+	return build
+}
+
+// now returns the time to record in the image
+func (b *Builder) now() time.Time {
+	if !b.Timestamp.IsZero() {
+		return b.Timestamp.UTC()
+	}
+	return time.Now().UTC()
 }
 
 // CreateApplication creates a Gravity application from the provided
@@ -399,11 +830,15 @@ func (b *Builder) initServices() (err error) {
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	packages, err := localpack.New(localpack.Config{
+	packagesConfig := localpack.Config{
 		Backend:     b.Backend,
 		UnpackedDir: filepath.Join(b.Dir, defaults.PackagesDir, defaults.UnpackedDir),
 		Objects:     objects,
-	})
+	}
+	if !b.Timestamp.IsZero() {
+		packagesConfig.Clock = &timetools.FreezedTime{CurrentTime: b.Timestamp.UTC()}
+	}
+	packages, err := localpack.New(packagesConfig)
 	if err != nil {
 		return trace.Wrap(err)
 	}
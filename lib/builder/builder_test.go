@@ -19,9 +19,12 @@ package builder
 import (
 	"testing"
 
-	"github.com/coreos/go-semver/semver"
+	"github.com/gravitational/gravity/lib/app/service"
+	"github.com/gravitational/gravity/lib/loc"
 	"github.com/gravitational/gravity/lib/schema"
 	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/coreos/go-semver/semver"
 	"github.com/gravitational/trace"
 	"github.com/gravitational/version"
 	"github.com/sirupsen/logrus"
@@ -91,13 +94,96 @@ func (s *BuilderSuite) TestSelectRuntimeVersion(c *check.C) {
 	c.Assert(err, check.IsNil)
 	c.Assert(ver, check.DeepEquals, semver.New("5.4.2"))
 
-	b.Manifest = schema.MustParseManifestYAML([]byte(manifestInvalidBase))
+	b.Manifest = schema.MustParseManifestYAML([]byte(manifestLayeredNoVersion))
 	ver, err = b.SelectRuntime()
 	c.Assert(err, check.FitsTypeOf, trace.BadParameter(""))
-	c.Assert(err, check.ErrorMatches, "unsupported base image .*")
+	c.Assert(err, check.ErrorMatches, "base cluster image .* should specify the version .*")
+}
+
+func (s *BuilderSuite) TestWritesPlatformManifest(c *check.C) {
+	path, err := WritePlatformManifest(c.MkDir(), "", "1.0.0")
+	c.Assert(err, check.IsNil)
+	manifest, err := schema.ParseManifest(path)
+	c.Assert(err, check.IsNil)
+	c.Assert(schema.CheckAndSetDefaults(manifest), check.IsNil)
+	c.Assert(manifest.Kind, check.Equals, schema.KindPlatform)
+	c.Assert(manifest.Locator().String(), check.Equals, "gravitational.io/platform:1.0.0")
+	c.Assert(manifest.Base(), check.DeepEquals, &loc.Runtime)
+}
+
+func (s *BuilderSuite) TestArchPackages(c *check.C) {
+	runtimeManifest := schema.MustParseManifestYAML([]byte(runtimeManifest))
+	packages, err := archPackages(runtimeManifest, []string{"amd64", "arm64"})
+	c.Assert(err, check.IsNil)
+	c.Assert(packages, check.DeepEquals, []loc.Locator{
+		loc.MustParseLocator("gravitational.io/gravity-arm64:5.5.0"),
+		loc.MustParseLocator("gravitational.io/planet-arm64:5.5.0-11106"),
+	})
+
+	packages, err = archPackages(runtimeManifest, []string{"amd64"})
+	c.Assert(err, check.IsNil)
+	c.Assert(packages, check.HasLen, 0)
+}
+
+func (s *BuilderSuite) TestCheckArchitectures(c *check.C) {
+	var testCases = []struct {
+		req     service.VendorRequest
+		valid   bool
+		comment string
+	}{
+		{
+			req:     service.VendorRequest{},
+			valid:   true,
+			comment: "default architecture",
+		},
+		{
+			req:     service.VendorRequest{Architectures: []string{"arm64"}},
+			valid:   true,
+			comment: "single architecture",
+		},
+		{
+			req:     service.VendorRequest{Architectures: []string{"amd64", "arm64"}},
+			valid:   false,
+			comment: "multiple architectures require pulling from registries",
+		},
+		{
+			req: service.VendorRequest{
+				Architectures:    []string{"amd64", "arm64"},
+				PullFromRegistry: true,
+			},
+			valid:   true,
+			comment: "multiple architectures",
+		},
+		{
+			req:     service.VendorRequest{Architectures: []string{"ppc64le"}},
+			valid:   false,
+			comment: "unsupported architecture",
+		},
+	}
+	for _, tc := range testCases {
+		err := checkArchitectures(tc.req)
+		if tc.valid {
+			c.Assert(err, check.IsNil, check.Commentf(tc.comment))
+		} else {
+			c.Assert(err, check.NotNil, check.Commentf(tc.comment))
+		}
+	}
 }
 
 const (
+	runtimeManifest = `apiVersion: bundle.gravitational.io/v2
+kind: Runtime
+metadata:
+  name: kubernetes
+  resourceVersion: 5.5.0
+dependencies:
+  packages:
+  - gravitational.io/gravity:5.5.0
+  - gravitational.io/teleport:3.0.5
+systemOptions:
+  dependencies:
+    runtimePackage: gravitational.io/planet:5.5.0-11106`
+
 	manifestWithBase = `apiVersion: cluster.gravitational.io/v2
 kind: Cluster
 baseImage: gravity:5.5.0
@@ -111,9 +197,9 @@ metadata:
   name: test
   resourceVersion: 1.0.0`
 
-	manifestInvalidBase = `apiVersion: cluster.gravitational.io/v2
+	manifestLayeredNoVersion = `apiVersion: cluster.gravitational.io/v2
 kind: Cluster
-baseImage: example:1.2.3
+baseImage: example
 metadata:
   name: test
   resourceVersion: 1.0.0`
@@ -0,0 +1,185 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"archive/tar"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/app/service"
+	"github.com/gravitational/gravity/lib/archive"
+	blobfs "github.com/gravitational/gravity/lib/blob/fs"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/pack/localpack"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/keyval"
+
+	"github.com/gravitational/trace"
+)
+
+// BaseImage describes the cluster image a delta cluster image is built against
+type BaseImage struct {
+	// Package is the cluster image package
+	Package loc.Locator
+	// Packages maps all packages in the image to their checksums
+	Packages map[loc.Locator]string
+}
+
+// ReadBaseImage reads the package metadata of the cluster image tarball at the specified path
+func ReadBaseImage(path string) (*BaseImage, error) {
+	dir, err := ioutil.TempDir("", "base")
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	defer os.RemoveAll(dir)
+	err = extractBackend(path, dir)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	backend, err := keyval.NewBolt(keyval.BoltConfig{
+		Path: filepath.Join(dir, defaults.GravityDBFile),
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer backend.Close()
+	objects, err := blobfs.New(filepath.Join(dir, defaults.PackagesDir))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	packages, err := localpack.New(localpack.Config{
+		Backend:     backend,
+		UnpackedDir: filepath.Join(dir, defaults.PackagesDir, defaults.UnpackedDir),
+		Objects:     objects,
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	apps, err := service.New(service.Config{
+		Backend:  backend,
+		Packages: packages,
+		StateDir: dir,
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	image, err := findClusterImage(apps)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	base := &BaseImage{
+		Package:  *image,
+		Packages: make(map[loc.Locator]string),
+	}
+	err = pack.ForeachPackage(packages, func(env pack.PackageEnvelope) error {
+		base.Packages[env.Locator] = env.SHA512
+		return nil
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return base, nil
+}
+
+// Unchanged returns the packages from the specified list that are present
+// in this image with the same contents
+func (r BaseImage) Unchanged(envelopes []pack.PackageEnvelope) (result []loc.Locator) {
+	for _, env := range envelopes {
+		checksum, ok := r.Packages[env.Locator]
+		if ok && checksum == env.SHA512 {
+			result = append(result, env.Locator)
+		}
+	}
+	return result
+}
+
+// unchangedPackages returns the dependencies of the specified application
+// that have not changed since the base image
+func (b *Builder) unchangedPackages(application app.Application) ([]loc.Locator, error) {
+	dependencies, err := app.GetDependencies(&application, b.Apps)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var envelopes []pack.PackageEnvelope
+	for _, locator := range append(dependencies.Packages, dependencies.Apps...) {
+		env, err := b.Packages.ReadPackageEnvelope(locator)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		envelopes = append(envelopes, *env)
+	}
+	return b.BaseImage.Unchanged(envelopes), nil
+}
+
+// extractBackend extracts the package metadata database from the cluster image
+// tarball at the specified path into dir
+func extractBackend(path, dir string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	var found bool
+	err = archive.TarGlob(tar.NewReader(f), ".", []string{defaults.GravityDBFile},
+		func(match string, file io.Reader) error {
+			if match != defaults.GravityDBFile {
+				return nil
+			}
+			out, err := os.Create(filepath.Join(dir, defaults.GravityDBFile))
+			if err != nil {
+				return trace.ConvertSystemError(err)
+			}
+			defer out.Close()
+			if _, err := io.Copy(out, file); err != nil {
+				return trace.Wrap(err)
+			}
+			found = true
+			return archive.Abort
+		})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if !found {
+		return trace.NotFound("%v does not look like a cluster image", path)
+	}
+	return nil
+}
+
+// findClusterImage returns the cluster image package from the specified application service
+func findClusterImage(apps app.Applications) (*loc.Locator, error) {
+	images, err := apps.ListApps(app.ListAppsRequest{
+		Repository: defaults.SystemAccountOrg,
+		Type:       storage.AppUser,
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, image := range images {
+		switch image.Manifest.Kind {
+		case schema.KindBundle, schema.KindCluster, schema.KindPlatform:
+			return &image.Package, nil
+		}
+	}
+	return nil, trace.NotFound("no cluster image found")
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/pack"
+
+	check "gopkg.in/check.v1"
+)
+
+type DeltaSuite struct{}
+
+var _ = check.Suite(&DeltaSuite{})
+
+func (s *DeltaSuite) TestUnchangedPackages(c *check.C) {
+	base := BaseImage{
+		Package: loc.MustParseLocator("gravitational.io/app:1.0.0"),
+		Packages: map[loc.Locator]string{
+			loc.MustParseLocator("gravitational.io/planet:1.0.0"):  "planet",
+			loc.MustParseLocator("gravitational.io/gravity:1.0.0"): "gravity",
+			loc.MustParseLocator("gravitational.io/dns-app:1.0.0"): "dns-app",
+		},
+	}
+	unchanged := base.Unchanged([]pack.PackageEnvelope{
+		{Locator: loc.MustParseLocator("gravitational.io/planet:1.0.0"), SHA512: "planet"},
+		{Locator: loc.MustParseLocator("gravitational.io/gravity:2.0.0"), SHA512: "gravity"},
+		{Locator: loc.MustParseLocator("gravitational.io/dns-app:1.0.0"), SHA512: "rebuilt"},
+	})
+	c.Assert(unchanged, check.DeepEquals, []loc.Locator{
+		loc.MustParseLocator("gravitational.io/planet:1.0.0"),
+	})
+}
@@ -20,6 +20,8 @@ import (
 	"io"
 
 	"github.com/gravitational/gravity/lib/app"
+
+	"github.com/gravitational/trace"
 )
 
 // Generator defines a method for generating standalone installers
@@ -34,7 +36,23 @@ type generator struct{}
 // Generate generates an installer tarball for the specified application
 // using the provided builder and returns its data as a stream
 func (g *generator) Generate(builder *Builder, application app.Application) (io.ReadCloser, error) {
-	return builder.Apps.GetAppInstaller(app.InstallerRequest{
+	req := app.InstallerRequest{
 		Application: application.Package,
-	})
+		Timestamp:   builder.Timestamp,
+	}
+	if builder.BaseImage != nil {
+		unchanged, err := builder.unchangedPackages(application)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		builder.Infof("Excluding packages unchanged since %v: %v.",
+			builder.BaseImage.Package, unchanged)
+		req.BaseImage = &builder.BaseImage.Package
+		req.ExcludePackages = unchanged
+	}
+	if builder.layered() {
+		builder.Infof("Excluding packages inherited from %v.", builder.Manifest.Base())
+		req.ExcludeBaseImage = true
+	}
+	return builder.Apps.GetAppInstaller(req)
 }
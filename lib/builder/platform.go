@@ -0,0 +1,70 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"text/template"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/schema"
+
+	"github.com/gravitational/trace"
+	"github.com/gravitational/version"
+)
+
+// WritePlatformManifest writes the manifest of the runtime-only cluster image
+// with the specified name and version into dir and returns the path to the manifest.
+//
+// The image has the Kubernetes runtime compatible with this version of tele
+// and no application. If unspecified, the name defaults to "platform" and the
+// version defaults to the version of tele
+func WritePlatformManifest(dir, name, imageVersion string) (path string, err error) {
+	if name == "" {
+		name = defaultPlatformName
+	}
+	if imageVersion == "" {
+		imageVersion = version.Get().Version
+	}
+	var buf bytes.Buffer
+	err = platformManifest.Execute(&buf, map[string]string{
+		"APIVersion": schema.APIVersionV2Cluster,
+		"Kind":       schema.KindPlatform,
+		"Name":       name,
+		"Version":    imageVersion,
+	})
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	path = filepath.Join(dir, defaults.ManifestFileName)
+	if err := ioutil.WriteFile(path, buf.Bytes(), defaults.SharedReadMask); err != nil {
+		return "", trace.ConvertSystemError(err)
+	}
+	return path, nil
+}
+
+var platformManifest = template.Must(template.New("platform").Parse(`apiVersion: {{.APIVersion}}
+kind: {{.Kind}}
+metadata:
+  name: {{.Name}}
+  resourceVersion: {{.Version}}
+`))
+
+// defaultPlatformName is the default name of the runtime-only cluster image
+const defaultPlatformName = "platform"
@@ -0,0 +1,105 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/gravitational/trace"
+)
+
+// newExec returns the plugin that invokes the executable at the specified path
+// as `<path> <hook>` with the build described in the environment
+func newExec(path string) (*execPlugin, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	if fi.IsDir() || fi.Mode()&0111 == 0 {
+		return nil, trace.BadParameter("plugin %v is not an executable", path)
+	}
+	return &execPlugin{path: path}, nil
+}
+
+type execPlugin struct {
+	path string
+}
+
+// Name returns the name of the plugin
+func (r *execPlugin) Name() string {
+	return filepath.Base(r.path)
+}
+
+// PrePackage invokes the executable with the pre-package hook
+func (r *execPlugin) PrePackage(ctx context.Context, build Build) error {
+	return r.run(ctx, HookPrePackage, build)
+}
+
+// PostPackage invokes the executable with the post-package hook
+func (r *execPlugin) PostPackage(ctx context.Context, build Build) error {
+	return r.run(ctx, HookPostPackage, build)
+}
+
+func (r *execPlugin) run(ctx context.Context, hook Hook, build Build) error {
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, r.path, string(hook))
+	cmd.Dir = build.ResourcesDir
+	cmd.Env = append(os.Environ(), environ(hook, build)...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return trace.Wrap(err, "plugin %v failed the %v hook: %s",
+			r.Name(), hook, strings.TrimSpace(out.String()))
+	}
+	return nil
+}
+
+// environ returns the environment variables that describe the build to the plugin
+func environ(hook Hook, build Build) []string {
+	vars := []string{
+		fmt.Sprintf("%v=%v", EnvHook, hook),
+		fmt.Sprintf("%v=%v", EnvImageName, build.Name),
+		fmt.Sprintf("%v=%v", EnvImageVersion, build.Version),
+		fmt.Sprintf("%v=%v", EnvResourcesDir, build.ResourcesDir),
+		fmt.Sprintf("%v=%v", EnvManifestPath, build.ManifestPath),
+	}
+	if build.ImagePath != "" {
+		vars = append(vars, fmt.Sprintf("%v=%v", EnvImagePath, build.ImagePath))
+	}
+	return vars
+}
+
+const (
+	// EnvHook is the name of the hook the plugin is invoked with
+	EnvHook = "TELE_BUILD_HOOK"
+	// EnvImageName is the name of the image being built
+	EnvImageName = "TELE_BUILD_IMAGE_NAME"
+	// EnvImageVersion is the version of the image being built
+	EnvImageVersion = "TELE_BUILD_IMAGE_VERSION"
+	// EnvResourcesDir is the directory with the application resources
+	EnvResourcesDir = "TELE_BUILD_RESOURCES_DIR"
+	// EnvManifestPath is the path to the image manifest
+	EnvManifestPath = "TELE_BUILD_MANIFEST"
+	// EnvImagePath is the path to the built image
+	EnvImagePath = "TELE_BUILD_IMAGE_PATH"
+)
@@ -0,0 +1,48 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	goplugin "plugin"
+
+	"github.com/gravitational/trace"
+)
+
+// openGoPlugin loads the Go plugin from the shared object at the specified path.
+//
+// The plugin has to be built with the same version of Go and the same
+// versions of the shared packages as tele
+func openGoPlugin(path string) (Plugin, error) {
+	p, err := goplugin.Open(path)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to load plugin %v", path)
+	}
+	symbol, err := p.Lookup(Symbol)
+	if err != nil {
+		return nil, trace.NotFound("plugin %v does not export %v", path, Symbol)
+	}
+	switch plugin := symbol.(type) {
+	case Plugin:
+		return plugin, nil
+	case *Plugin:
+		if *plugin != nil {
+			return *plugin, nil
+		}
+	}
+	return nil, trace.BadParameter("plugin %v exports %v of type %T that does not "+
+		"implement the build plugin interface", path, Symbol, symbol)
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package plugin implements the plugins that hook into the image build.
+//
+// A plugin is either an executable invoked with the name of the hook
+// as an argument, or a Go plugin (a shared object built with
+// -buildmode=plugin) that exports a Plugin symbol implementing Plugin
+package plugin
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/gravitational/trace"
+)
+
+// Plugin hooks into the stages of the image build.
+// The build fails if any of the hooks returns an error
+type Plugin interface {
+	// Name returns the name of the plugin
+	Name() string
+	// PrePackage is invoked before the application is packaged.
+	// The plugin can add or update the application resources, or
+	// validate them against a policy
+	PrePackage(ctx context.Context, build Build) error
+	// PostPackage is invoked after the image has been written,
+	// e.g. to sign it
+	PostPackage(ctx context.Context, build Build) error
+}
+
+// Build describes the image being built
+type Build struct {
+	// Name is the name of the image
+	Name string
+	// Version is the version of the image
+	Version string
+	// ResourcesDir is the directory with the application resources
+	// to be packaged
+	ResourcesDir string
+	// ManifestPath is the path to the image manifest in the resources directory
+	ManifestPath string
+	// ImagePath is the path to the built image, only set for the post-package hook
+	ImagePath string
+}
+
+// Load returns the plugin at the specified path.
+// Shared objects (with the .so extension) are loaded as Go plugins,
+// other files are invoked as executables
+func Load(path string) (Plugin, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if filepath.Ext(path) == ".so" {
+		return openGoPlugin(path)
+	}
+	return newExec(path)
+}
+
+// Hook names the build stage a plugin is invoked at
+type Hook string
+
+const (
+	// HookPrePackage is invoked before the application is packaged
+	HookPrePackage Hook = "pre-package"
+	// HookPostPackage is invoked after the image has been written
+	HookPostPackage Hook = "post-package"
+)
+
+// Symbol is the name of the symbol a Go plugin exports.
+// The symbol is a variable of a type that implements Plugin
+const Symbol = "Plugin"
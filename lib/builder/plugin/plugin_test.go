@@ -0,0 +1,90 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/gravitational/trace"
+	"gopkg.in/check.v1"
+)
+
+func TestPlugin(t *testing.T) { check.TestingT(t) }
+
+type PluginSuite struct{}
+
+var _ = check.Suite(&PluginSuite{})
+
+func (s *PluginSuite) TestExecPlugin(c *check.C) {
+	dir := c.MkDir()
+	path := writeScript(c, dir, `#!/bin/sh
+echo "$1 $TELE_BUILD_HOOK $TELE_BUILD_IMAGE_NAME:$TELE_BUILD_IMAGE_VERSION $TELE_BUILD_IMAGE_PATH" > generated.txt
+`)
+	p, err := Load(path)
+	c.Assert(err, check.IsNil)
+	c.Assert(p.Name(), check.Equals, "plugin.sh")
+
+	build := Build{
+		Name:         "app",
+		Version:      "1.0.0",
+		ResourcesDir: dir,
+		ManifestPath: filepath.Join(dir, "app.yaml"),
+	}
+	c.Assert(p.PrePackage(context.TODO(), build), check.IsNil)
+	c.Assert(readFile(c, filepath.Join(dir, "generated.txt")), check.Equals,
+		"pre-package pre-package app:1.0.0 \n")
+
+	build.ImagePath = "/images/app-1.0.0.tar"
+	c.Assert(p.PostPackage(context.TODO(), build), check.IsNil)
+	c.Assert(readFile(c, filepath.Join(dir, "generated.txt")), check.Equals,
+		"post-package post-package app:1.0.0 /images/app-1.0.0.tar\n")
+}
+
+func (s *PluginSuite) TestExecPluginFailsBuild(c *check.C) {
+	dir := c.MkDir()
+	path := writeScript(c, dir, `#!/bin/sh
+echo "policy violation"
+exit 1
+`)
+	p, err := Load(path)
+	c.Assert(err, check.IsNil)
+	err = p.PrePackage(context.TODO(), Build{ResourcesDir: dir})
+	c.Assert(err, check.NotNil)
+	c.Assert(err, check.ErrorMatches, "plugin plugin.sh failed the pre-package hook: policy violation")
+}
+
+func (s *PluginSuite) TestRejectsNonExecutable(c *check.C) {
+	path := filepath.Join(c.MkDir(), "plugin")
+	c.Assert(ioutil.WriteFile(path, []byte("data"), 0644), check.IsNil)
+	_, err := Load(path)
+	c.Assert(trace.IsBadParameter(err), check.Equals, true, check.Commentf("%v", err))
+}
+
+func writeScript(c *check.C, dir, script string) string {
+	path := filepath.Join(dir, "plugin.sh")
+	c.Assert(ioutil.WriteFile(path, []byte(script), 0755), check.IsNil)
+	return path
+}
+
+func readFile(c *check.C, path string) string {
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, check.IsNil)
+	return string(data)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/app/docker"
+	"github.com/gravitational/gravity/lib/loc"
+
+	"github.com/gravitational/trace"
+)
+
+// PushImage publishes the image tarball to the registry repository
+// specified with PushTo as an artifact.
+// The image version is used as the tag unless the reference specifies one
+func (b *Builder) PushImage(ctx context.Context) error {
+	locator := b.Locator()
+	image, err := artifactReference(b.PushTo, locator)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	config, err := json.Marshal(imageArtifactConfig{
+		Kind:       b.Manifest.Kind,
+		Repository: locator.Repository,
+		Name:       locator.Name,
+		Version:    locator.Version,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	dgst, err := docker.PushArtifact(ctx, docker.PushArtifactRequest{
+		Image:           image,
+		Path:            b.OutPath,
+		MediaType:       ImageArtifactMediaType,
+		Config:          config,
+		ConfigMediaType: ImageArtifactConfigMediaType,
+		Auth:            b.VendorReq.RegistryAuth,
+		Insecure:        b.Insecure,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	b.PrintSubStep("Published %v (digest %v)", image, dgst)
+	return nil
+}
+
+// artifactReference returns the reference to publish the image with the specified
+// locator under. Semver build metadata is not allowed in tags so '+' is replaced with '_'
+func artifactReference(pushTo string, locator loc.Locator) (string, error) {
+	parsed, err := loc.ParseDockerImage(pushTo)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	if parsed.Registry == "" {
+		return "", trace.BadParameter("%v does not specify the registry to publish the image to", pushTo)
+	}
+	if parsed.Tag == "" {
+		parsed.Tag = strings.Replace(locator.Version, "+", "_", -1)
+	}
+	return parsed.String(), nil
+}
+
+// imageArtifactConfig is the configuration of the image artifact
+type imageArtifactConfig struct {
+	// Kind is the image kind, e.g. Cluster or Application
+	Kind string `json:"kind"`
+	// Repository is the image package repository
+	Repository string `json:"repository"`
+	// Name is the image name
+	Name string `json:"name"`
+	// Version is the image version
+	Version string `json:"version"`
+}
+
+const (
+	// ImageArtifactMediaType is the media type of the image tarball
+	// published to a registry
+	ImageArtifactMediaType = "application/vnd.gravitational.gravity.image.v1.tar"
+	// ImageArtifactConfigMediaType is the media type of the configuration
+	// of the image published to a registry
+	ImageArtifactConfigMediaType = "application/vnd.gravitational.gravity.image.config.v1+json"
+)
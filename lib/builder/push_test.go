@@ -0,0 +1,54 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"github.com/gravitational/gravity/lib/loc"
+
+	check "gopkg.in/check.v1"
+)
+
+type PushSuite struct{}
+
+var _ = check.Suite(&PushSuite{})
+
+func (s *PushSuite) TestArtifactReference(c *check.C) {
+	locator := loc.MustParseLocator("gravitational.io/app:1.0.0+build.1")
+	var testCases = []struct {
+		pushTo   string
+		expected string
+		comment  string
+	}{
+		{
+			pushTo:   "registry.example.com/org/app",
+			expected: "registry.example.com/org/app:1.0.0_build.1",
+			comment:  "image version is used as tag",
+		},
+		{
+			pushTo:   "registry.example.com:5000/org/app:stable",
+			expected: "registry.example.com:5000/org/app:stable",
+			comment:  "explicit tag",
+		},
+	}
+	for _, tc := range testCases {
+		image, err := artifactReference(tc.pushTo, locator)
+		c.Assert(err, check.IsNil, check.Commentf(tc.comment))
+		c.Assert(image, check.Equals, tc.expected, check.Commentf(tc.comment))
+	}
+	_, err := artifactReference("org/app", locator)
+	c.Assert(err, check.NotNil, check.Commentf("registry is required"))
+}
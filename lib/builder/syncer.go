@@ -37,7 +37,9 @@ import (
 // Syncer synchronizes the local package cache from a (remote) repository
 type Syncer interface {
 	// Sync makes sure that local cache has all required dependencies for the
-	// selected runtime
+	// selected runtime.
+	// The runtime version is nil when synchronizing the base cluster image
+	// of a layered image as the runtime is determined by the base image
 	Sync(*Builder, *semver.Version) error
 }
 
@@ -71,11 +73,18 @@ func newS3Syncer() (*s3Syncer, error) {
 // Sync makes sure that local cache has all required dependencies for the
 // selected runtime
 func (s *s3Syncer) Sync(builder *Builder, runtimeVersion *semver.Version) error {
-	tarball, err := s.hub.Get(loc.Locator{
-		Repository: defaults.SystemAccountOrg,
-		Name:       defaults.TelekubePackage,
-		Version:    runtimeVersion.String(),
-	})
+	var locator loc.Locator
+	if builder.layered() {
+		// layered image dependencies come from the cluster image it is built on top of
+		locator = *builder.Manifest.Base()
+	} else {
+		locator = loc.Locator{
+			Repository: defaults.SystemAccountOrg,
+			Name:       defaults.TelekubePackage,
+			Version:    runtimeVersion.String(),
+		}
+	}
+	tarball, err := s.hub.Get(locator)
 	if err != nil {
 		return trace.Wrap(err)
 	}
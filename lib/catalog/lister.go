@@ -152,11 +152,12 @@ func (l ListItems) Swap(i, j int) {
 
 // Less implements sort.Interace.
 //
-// The items are sorted first by type (cluster images appear before application
-// images), then by name (lexicographically) and finally by semantic version.
+// The items are sorted first by type (cluster images appear before platform
+// images, which appear before application images), then by name (lexicographically)
+// and finally by semantic version.
 func (l ListItems) Less(i, j int) bool {
 	if l[i].GetType() != l[j].GetType() {
-		return l[i].GetType() == schema.KindCluster
+		return typeOrder(l[i].GetType()) < typeOrder(l[j].GetType())
 	}
 	if l[i].GetName() < l[j].GetName() {
 		return true
@@ -264,3 +265,15 @@ func formatDescription(description string) string {
 	}
 	return strings.TrimSpace(description)
 }
+
+// typeOrder returns the position of the image type in the sorted list
+func typeOrder(imageType string) int {
+	switch imageType {
+	case schema.KindCluster:
+		return 0
+	case schema.KindPlatform:
+		return 1
+	default:
+		return 2
+	}
+}
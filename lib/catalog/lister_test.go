@@ -40,12 +40,14 @@ func (s *listerSuite) TestLatestAndSorting(c *check.C) {
 		listItem{Name: "zookeeper", Version: v("1.0.0-beta.2"), Type: "Application"},
 		listItem{Name: "kubernetes", Version: v("13.0.0"), Type: "Cluster"},
 		listItem{Name: "kubernetes", Version: v("14.0.0-beta.1"), Type: "Cluster"},
+		listItem{Name: "platform", Version: v("1.0.0"), Type: "Platform"},
 	}
 	latest, err := items.Latest()
 	c.Assert(err, check.IsNil)
 	sort.Sort(latest)
 	c.Assert(latest, compare.DeepEquals, ListItems{
 		listItem{Name: "kubernetes", Version: v("13.0.0"), Type: "Cluster"},
+		listItem{Name: "platform", Version: v("1.0.0"), Type: "Platform"},
 		listItem{Name: "alpine", Version: v("1.0.0"), Type: "Application"},
 		listItem{Name: "kafka", Version: v("1.0.1"), Type: "Application"},
 		listItem{Name: "nginx", Version: v("1.2.3"), Type: "Application"},
@@ -55,6 +57,7 @@ func (s *listerSuite) TestLatestAndSorting(c *check.C) {
 	c.Assert(items, compare.DeepEquals, ListItems{
 		listItem{Name: "kubernetes", Version: v("14.0.0-beta.1"), Type: "Cluster"},
 		listItem{Name: "kubernetes", Version: v("13.0.0"), Type: "Cluster"},
+		listItem{Name: "platform", Version: v("1.0.0"), Type: "Platform"},
 		listItem{Name: "alpine", Version: v("1.0.0"), Type: "Application"},
 		listItem{Name: "alpine", Version: v("1.0.0-rc.3"), Type: "Application"},
 		listItem{Name: "kafka", Version: v("1.0.1"), Type: "Application"},
@@ -0,0 +1,322 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catalog
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/app/service"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/helm"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/docker/docker/pkg/archive"
+	"github.com/ghodss/yaml"
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"github.com/sirupsen/logrus"
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/repo"
+)
+
+// SyncerConfig describes the configuration of the catalog syncer
+type SyncerConfig struct {
+	// Apps is the application service the charts are imported into
+	Apps app.Applications
+	// Backend stores the catalog sync schedule
+	Backend storage.CatalogSyncSchedules
+	// ClusterName is the name of the local cluster
+	ClusterName string
+	// Vendorer vendors the images referenced by the charts
+	Vendorer service.Vendorer
+	// Client is the HTTP client used to download the repository index and charts
+	Client *http.Client
+	// Interval is how often the schedule is checked
+	Interval time.Duration
+	// Progress optionally reports the progress of the image vendoring
+	Progress utils.Progress
+	// Clock is used to determine whether the repository is due for synchronization
+	Clock clockwork.Clock
+	// FieldLogger is used for logging
+	logrus.FieldLogger
+}
+
+// CheckAndSetDefaults validates the configuration and sets defaults
+func (r *SyncerConfig) CheckAndSetDefaults() error {
+	if r.Apps == nil {
+		return trace.BadParameter("application service is required")
+	}
+	if r.Vendorer == nil {
+		return trace.BadParameter("vendorer is required")
+	}
+	if r.Client == nil {
+		r.Client = http.DefaultClient
+	}
+	if r.Interval == 0 {
+		r.Interval = defaults.CatalogSyncCheckInterval
+	}
+	if r.Progress == nil {
+		r.Progress = utils.DiscardProgress
+	}
+	if r.Clock == nil {
+		r.Clock = clockwork.NewRealClock()
+	}
+	if r.FieldLogger == nil {
+		r.FieldLogger = logrus.WithField(trace.Component, "catalog-sync")
+	}
+	return nil
+}
+
+// NewSyncer returns a new syncer that mirrors the charts of an upstream
+// Helm repository into the local application catalog
+func NewSyncer(config SyncerConfig) (*Syncer, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &Syncer{SyncerConfig: config}, nil
+}
+
+// Syncer periodically imports the latest versions of the charts from the
+// repository specified with the catalog sync schedule as application
+// images, including the Docker images they reference, so they can be
+// installed without access to the upstream repository or registries.
+//
+// Versions that already exist in the catalog are not imported again
+type Syncer struct {
+	SyncerConfig
+	// lastSync is the time of the last synchronization
+	lastSync time.Time
+	// lastRepository is the repository of the last synchronization
+	lastRepository string
+}
+
+// SyncResult describes the outcome of a catalog synchronization
+type SyncResult struct {
+	// Imported lists the applications imported from the repository
+	Imported []loc.Locator
+	// Skipped lists the applications that already exist in the catalog
+	Skipped []loc.Locator
+}
+
+// Run synchronizes the catalog according to the schedule until the context is canceled
+func (r *Syncer) Run(ctx context.Context) {
+	if r.Backend == nil {
+		r.Warn("Catalog sync schedule backend is not configured.")
+		return
+	}
+	r.Info("Starting catalog syncer.")
+	ticker := r.Clock.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.Chan():
+			if err := r.syncIfDue(ctx); err != nil {
+				r.WithError(err).Warn("Failed to synchronize catalog.")
+			}
+		case <-ctx.Done():
+			r.Info("Stopping catalog syncer.")
+			return
+		}
+	}
+}
+
+func (r *Syncer) syncIfDue(ctx context.Context) error {
+	schedule, err := r.Backend.GetCatalogSyncSchedule(r.ClusterName)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil
+		}
+		return trace.Wrap(err)
+	}
+	now := r.Clock.Now()
+	if schedule.GetRepository() == r.lastRepository && now.Sub(r.lastSync) < schedule.GetInterval() {
+		return nil
+	}
+	// the failed synchronization is retried with the next interval
+	r.lastSync, r.lastRepository = now, schedule.GetRepository()
+	result, err := r.Sync(ctx, schedule)
+	if result != nil && len(result.Imported) != 0 {
+		r.Infof("Imported %v from %v.", result.Imported, schedule.GetRepository())
+	}
+	return trace.Wrap(err)
+}
+
+// Sync imports the latest versions of the charts selected by the schedule
+// that do not exist in the catalog yet.
+// A chart that fails to import does not prevent the import of other charts
+func (r *Syncer) Sync(ctx context.Context, schedule storage.CatalogSyncSchedule) (*SyncResult, error) {
+	index, err := r.fetchIndex(ctx, schedule.GetRepository())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var names []string
+	for name := range index.Entries {
+		if schedule.Matches(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range schedule.GetCharts() {
+		if _, ok := index.Entries[name]; !ok {
+			r.Warnf("Chart %v is not found in %v.", name, schedule.GetRepository())
+		}
+	}
+	var result SyncResult
+	var errors []error
+	for _, name := range names {
+		chartVersion, err := index.Get(name, "")
+		if err != nil {
+			errors = append(errors, trace.Wrap(err))
+			continue
+		}
+		locator, err := loc.NewLocator(defaults.SystemAccountOrg, name, chartVersion.Version)
+		if err != nil {
+			errors = append(errors, trace.Wrap(err))
+			continue
+		}
+		_, err = r.Apps.GetApp(*locator)
+		if err == nil {
+			result.Skipped = append(result.Skipped, *locator)
+			continue
+		}
+		if !trace.IsNotFound(err) {
+			errors = append(errors, trace.Wrap(err))
+			continue
+		}
+		r.WithField("chart", locator).Info("Importing chart.")
+		if err := r.importChart(ctx, schedule.GetRepository(), *chartVersion, *locator); err != nil {
+			errors = append(errors, trace.Wrap(err, "failed to import chart %v", locator))
+			continue
+		}
+		result.Imported = append(result.Imported, *locator)
+	}
+	return &result, trace.NewAggregate(errors...)
+}
+
+// importChart downloads the specified chart, vendors the images it references
+// and creates an application image for it
+func (r *Syncer) importChart(ctx context.Context, repoURL string, chartVersion repo.ChartVersion, locator loc.Locator) error {
+	if len(chartVersion.URLs) == 0 {
+		return trace.BadParameter("repository index does not specify the chart URL")
+	}
+	chartURL, err := repo.ResolveReferenceURL(repoURL, chartVersion.URLs[0])
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	dir, err := ioutil.TempDir("", "catalog")
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer os.RemoveAll(dir)
+	resp, err := r.get(ctx, chartURL)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer resp.Body.Close()
+	// the chart archive contains a single directory named after the chart
+	if err := chartutil.Expand(dir, resp.Body); err != nil {
+		return trace.Wrap(err)
+	}
+	resourcesDir := filepath.Join(dir, defaults.ResourcesDir)
+	if err := os.Rename(filepath.Join(dir, chartVersion.Name), resourcesDir); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	chart, err := chartutil.LoadDir(resourcesDir)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	manifest, err := helm.GenerateManifest(chart)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	manifestBytes, err := yaml.Marshal(manifest)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	manifestPath := filepath.Join(resourcesDir, defaults.ManifestFileName)
+	if err := ioutil.WriteFile(manifestPath, manifestBytes, defaults.SharedReadMask); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	err = r.Vendorer.VendorDir(ctx, dir, service.VendorRequest{
+		Repository:       locator.Repository,
+		PackageName:      locator.Name,
+		PackageVersion:   locator.Version,
+		ManifestPath:     manifestPath,
+		ResourcePatterns: []string{defaults.VendorPattern},
+		ProgressReporter: r.Progress,
+		PullFromRegistry: true,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	reader, err := archive.Tar(dir, archive.Uncompressed)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer reader.Close()
+	_, err = r.Apps.CreateApp(locator, reader, nil)
+	return trace.Wrap(err)
+}
+
+// fetchIndex downloads the index file of the specified repository
+func (r *Syncer) fetchIndex(ctx context.Context, repoURL string) (*repo.IndexFile, error) {
+	indexURL, err := repo.ResolveReferenceURL(repoURL, "index.yaml")
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	resp, err := r.get(ctx, indexURL)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var index repo.IndexFile
+	if err := yaml.Unmarshal(data, &index); err != nil {
+		return nil, trace.BadParameter("failed to parse repository index %v: %v", indexURL, err)
+	}
+	// sort the versions of each chart from the latest to the oldest
+	index.SortEntries()
+	return &index, nil
+}
+
+func (r *Syncer) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	resp, err := r.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, trace.BadParameter("failed to download %v: %v", url, resp.Status)
+	}
+	return resp, nil
+}
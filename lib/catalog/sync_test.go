@@ -0,0 +1,165 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catalog
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+
+	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/app/service"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/ops/opsservice"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	check "gopkg.in/check.v1"
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/repo"
+)
+
+type syncSuite struct {
+	services opsservice.TestServices
+	server   *httptest.Server
+	vendorer *fakeVendorer
+	clock    clockwork.FakeClock
+	syncer   *Syncer
+}
+
+var _ = check.Suite(&syncSuite{})
+
+func (s *syncSuite) SetUpTest(c *check.C) {
+	s.services = opsservice.SetupTestServices(c)
+	_, err := s.services.Backend.CreateAccount(storage.Account{
+		ID:  defaults.SystemAccountID,
+		Org: defaults.SystemAccountOrg,
+	})
+	c.Assert(err, check.IsNil)
+
+	dir := c.MkDir()
+	index := repo.NewIndexFile()
+	for _, md := range []*chart.Metadata{
+		{Name: "alpine", Version: "0.1.0", Description: "Alpine"},
+		{Name: "alpine", Version: "0.2.0", Description: "Alpine"},
+		{Name: "nginx", Version: "1.0.0", Description: "Nginx"},
+	} {
+		path, err := chartutil.Save(&chart.Chart{Metadata: md}, dir)
+		c.Assert(err, check.IsNil)
+		index.Add(md, filepath.Base(path), "", "")
+	}
+	c.Assert(index.WriteFile(filepath.Join(dir, "index.yaml"), defaults.SharedReadMask), check.IsNil)
+	s.server = httptest.NewServer(http.StripPrefix("/charts", http.FileServer(http.Dir(dir))))
+
+	s.vendorer = &fakeVendorer{}
+	s.clock = clockwork.NewFakeClock()
+	s.syncer, err = NewSyncer(SyncerConfig{
+		Apps:        s.services.Apps,
+		Backend:     s.services.Backend,
+		ClusterName: "example.com",
+		Vendorer:    s.vendorer,
+		Clock:       s.clock,
+	})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *syncSuite) TearDownTest(c *check.C) {
+	s.server.Close()
+}
+
+func (s *syncSuite) TestImportsLatestCharts(c *check.C) {
+	schedule := s.newSchedule("alpine", "nginx")
+	result, err := s.syncer.Sync(context.TODO(), schedule)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Imported, check.DeepEquals, []loc.Locator{
+		loc.MustParseLocator("gravitational.io/alpine:0.2.0"),
+		loc.MustParseLocator("gravitational.io/nginx:1.0.0"),
+	})
+	c.Assert(result.Skipped, check.HasLen, 0)
+	c.Assert(s.vendorer.requests, check.HasLen, 2)
+	c.Assert(s.vendorer.requests[0].PullFromRegistry, check.Equals, true)
+
+	alpine, err := s.services.Apps.GetApp(loc.MustParseLocator("gravitational.io/alpine:0.2.0"))
+	c.Assert(err, check.IsNil)
+	c.Assert(alpine.Manifest.Kind, check.Equals, schema.KindApplication)
+	c.Assert(alpine.Manifest.Metadata.Description, check.Equals, "Alpine")
+	_, err = s.services.Apps.GetApp(loc.MustParseLocator("gravitational.io/alpine:0.1.0"))
+	c.Assert(trace.IsNotFound(err), check.Equals, true)
+
+	result, err = s.syncer.Sync(context.TODO(), schedule)
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Imported, check.HasLen, 0)
+	c.Assert(result.Skipped, check.HasLen, 2)
+}
+
+func (s *syncSuite) TestImportsSelectedCharts(c *check.C) {
+	result, err := s.syncer.Sync(context.TODO(), s.newSchedule("nginx"))
+	c.Assert(err, check.IsNil)
+	c.Assert(result.Imported, check.DeepEquals, []loc.Locator{
+		loc.MustParseLocator("gravitational.io/nginx:1.0.0"),
+	})
+}
+
+func (s *syncSuite) TestSyncsOnSchedule(c *check.C) {
+	c.Assert(s.syncer.syncIfDue(context.TODO()), check.IsNil)
+	c.Assert(s.vendorer.requests, check.HasLen, 0)
+
+	c.Assert(s.services.Backend.UpsertCatalogSyncSchedule("example.com", s.newSchedule("nginx")), check.IsNil)
+	c.Assert(s.syncer.syncIfDue(context.TODO()), check.IsNil)
+	c.Assert(s.vendorer.requests, check.HasLen, 1)
+
+	// remove the imported chart so the next synchronization imports it again
+	c.Assert(s.services.Apps.DeleteApp(app.DeleteRequest{
+		Package: loc.MustParseLocator("gravitational.io/nginx:1.0.0"),
+		Force:   true,
+	}), check.IsNil)
+	// not due yet
+	s.clock.Advance(defaults.CatalogSyncInterval / 2)
+	c.Assert(s.syncer.syncIfDue(context.TODO()), check.IsNil)
+	c.Assert(s.vendorer.requests, check.HasLen, 1)
+
+	s.clock.Advance(defaults.CatalogSyncInterval)
+	c.Assert(s.syncer.syncIfDue(context.TODO()), check.IsNil)
+	c.Assert(s.vendorer.requests, check.HasLen, 2)
+}
+
+func (s *syncSuite) newSchedule(charts ...string) storage.CatalogSyncSchedule {
+	return storage.NewCatalogSyncSchedule(storage.CatalogSyncScheduleSpecV2{
+		Repository: s.server.URL + "/charts",
+		Charts:     charts,
+	})
+}
+
+// fakeVendorer records the vendoring requests without pulling any images
+type fakeVendorer struct {
+	requests []service.VendorRequest
+}
+
+func (r *fakeVendorer) VendorDir(ctx context.Context, dir string, req service.VendorRequest) error {
+	r.requests = append(r.requests, req)
+	return nil
+}
+
+func (r *fakeVendorer) VendorTarball(ctx context.Context, tarball io.ReadCloser, req service.VendorRequest) (string, error) {
+	return "", trace.NotImplemented("not implemented")
+}
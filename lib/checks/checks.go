@@ -28,12 +28,14 @@ import (
 	"time"
 
 	"github.com/gravitational/gravity/lib/checks/autofix"
+	"github.com/gravitational/gravity/lib/cloudprovider/vsphere"
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
 	validationpb "github.com/gravitational/gravity/lib/network/validation/proto"
 	"github.com/gravitational/gravity/lib/schema"
 	"github.com/gravitational/gravity/lib/state"
 	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/clusterconfig"
 	"github.com/gravitational/gravity/lib/system"
 	"github.com/gravitational/gravity/lib/systeminfo"
 	"github.com/gravitational/gravity/lib/utils"
@@ -84,6 +86,12 @@ func ValidateManifest(
 	failedProbes = append(failedProbes, failed...)
 
 	failedProbes = append(failedProbes, schema.ValidateKubelet(profile, manifest)...)
+
+	if profile.Requirements.Disks != nil {
+		var probes health.Probes
+		newDiskRequirementsChecker(*profile.Requirements.Disks).Check(context.TODO(), &probes)
+		failedProbes = append(failedProbes, probes.GetFailed()...)
+	}
 	return failedProbes, trace.NewAggregate(errors...)
 }
 
@@ -112,6 +120,11 @@ type LocalChecksRequest struct {
 	Options *validationpb.ValidateOptions
 	// Docker specifies Docker configuration overrides (if any)
 	Docker storage.DockerConfig
+	// CloudProvider is the optional cloud provider to run additional checks for
+	CloudProvider string
+	// ResourcePressure specifies optional resource pressure thresholds.
+	// Default thresholds are used if unspecified
+	ResourcePressure *clusterconfig.ResourcePressure
 	// AutoFix when set to true attempts to fix some common problems
 	AutoFix bool
 	// Progress is used to report information about auto-fixed problems
@@ -126,6 +139,10 @@ func (r *LocalChecksRequest) CheckAndSetDefaults() error {
 	if r.Progress == nil {
 		r.Progress = utils.DiscardProgress
 	}
+	if r.ResourcePressure == nil {
+		thresholds := clusterconfig.DefaultResourcePressure()
+		r.ResourcePressure = &thresholds
+	}
 	return nil
 }
 
@@ -166,7 +183,9 @@ func ValidateLocal(ctx context.Context, req LocalChecksRequest) (*LocalChecksRes
 		return nil, trace.Wrap(err)
 	}
 
-	autofix.AutoloadModules(ctx, schema.DefaultKernelModules, req.Progress)
+	modules := append([]monitoring.ModuleRequest{}, schema.DefaultKernelModules...)
+	modules = append(modules, profile.Requirements.KernelModuleRequests()...)
+	autofix.AutoloadModules(ctx, modules, req.Progress)
 
 	dockerConfig := DockerConfigFromSchemaValue(req.Manifest.SystemDocker())
 	OverrideDockerConfig(&dockerConfig, req.Docker)
@@ -176,6 +195,9 @@ func ValidateLocal(ctx context.Context, req LocalChecksRequest) (*LocalChecksRes
 	}
 
 	failedProbes = append(failedProbes, RunBasicChecks(ctx, req.Options)...)
+	failedProbes = append(failedProbes, runCloudChecks(ctx, req.CloudProvider)...)
+	failedProbes = append(failedProbes, runResourcePressureChecks(ctx, *req.ResourcePressure)...)
+	failedProbes = append(failedProbes, runSELinuxChecks(ctx, stateDir)...)
 	if len(failedProbes) == 0 {
 		return &LocalChecksResult{}, nil
 	}
@@ -296,6 +318,9 @@ type Features struct {
 	// TestEtcdDisk specifies whether the device where etcd data resides
 	// should be performance-tested.
 	TestEtcdDisk bool
+	// TestDisks specifies whether the disk performance of the system
+	// and profile volumes should be tested.
+	TestDisks bool
 }
 
 // String return textual representation of this server object
@@ -365,6 +390,11 @@ func (r *checker) CheckNode(ctx context.Context, server Server) error {
 		errors = append(errors, err)
 	}
 
+	err = checkArchitecture(server, r.Manifest)
+	if err != nil {
+		errors = append(errors, err)
+	}
+
 	dockerConfig := r.Manifest.SystemDocker()
 	if r.TestDockerDevice {
 		err = checkDockerDevice(server, dockerConfig)
@@ -390,9 +420,11 @@ func (r *checker) CheckNode(ctx context.Context, server Server) error {
 		}
 	}
 
-	err = r.checkDisks(ctx, server)
-	if err != nil {
-		errors = append(errors, err)
+	if r.TestDisks {
+		err = r.checkDisks(ctx, server)
+		if err != nil {
+			errors = append(errors, err)
+		}
 	}
 
 	return trace.NewAggregate(errors...)
@@ -709,6 +741,33 @@ func checkServerProfile(server Server, requirements Requirements) error {
 		}
 	}
 
+	if requirements.Network.Interfaces != nil {
+		err := checkNetworkInterfaces(server.ServerInfo, *requirements.Network.Interfaces)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
+	return nil
+}
+
+// checkNetworkInterfaces makes sure the server has the network interfaces
+// the profile dedicates to the cluster and application traffic
+func checkNetworkInterfaces(info ServerInfo, interfaces schema.NetworkInterfaces) error {
+	var server storage.Server
+	if err := ConfigureInterfaces(&server, info, interfaces); err != nil {
+		return trace.Wrap(err)
+	}
+	log.Infof("Server %q passed network interfaces check.", info.GetHostname())
+	return nil
+}
+
+// checkArchitecture makes sure the cluster image supports the server's CPU architecture
+func checkArchitecture(server Server, manifest schema.Manifest) error {
+	err := manifest.CheckArchitecture(server.ServerInfo.GetArch())
+	if err != nil {
+		return trace.BadParameter("server %q: %v", server.ServerInfo.GetHostname(), err)
+	}
 	return nil
 }
 
@@ -867,40 +926,63 @@ func checkSystemPackages(server Server, dockerConfig schema.Docker) error {
 }
 
 func basicCheckers(options *validationpb.ValidateOptions) health.Checker {
-	return monitoring.NewCompositeChecker(
-		"local",
-		[]health.Checker{
-			monitoring.NewIPForwardChecker(),
-			monitoring.NewBridgeNetfilterChecker(),
-			monitoring.NewMayDetachMountsChecker(),
-			monitoring.DefaultProcessChecker(),
-			defaultPortChecker(options),
-			monitoring.DefaultBootConfigParams(),
-		},
-	)
+	checkers := []health.Checker{
+		monitoring.NewIPForwardChecker(),
+		monitoring.NewBridgeNetfilterChecker(),
+		monitoring.NewMayDetachMountsChecker(),
+		monitoring.DefaultProcessChecker(),
+		defaultPortChecker(options),
+		monitoring.DefaultBootConfigParams(),
+	}
+	if options != nil && options.WireguardPort != 0 {
+		// the pod traffic between the nodes is encrypted with WireGuard
+		checkers = append(checkers,
+			monitoring.NewKernelModuleChecker(monitoring.ModuleRequest{Name: "wireguard"}))
+	}
+	return monitoring.NewCompositeChecker("local", checkers)
+}
+
+// runCloudChecks executes the checks specific to the cloud provider.
+// Returns list of failed health probes.
+func runCloudChecks(ctx context.Context, cloudProvider string) (failed []*agentpb.Probe) {
+	if cloudProvider != schema.ProviderVSphere {
+		return nil
+	}
+	var reporter health.Probes
+	vsphere.NewChecker().Check(ctx, &reporter)
+	for _, p := range reporter {
+		if p.Status == agentpb.Probe_Failed {
+			failed = append(failed, p)
+		}
+	}
+	return failed
 }
 
 func defaultPortChecker(options *validationpb.ValidateOptions) health.Checker {
-	vxlanPort := uint64(defaults.VxlanPort)
+	vxlanPort := defaults.VxlanPort
 	if options != nil && options.VxlanPort != 0 {
-		vxlanPort = uint64(options.VxlanPort)
-	}
-
-	var portRanges = []monitoring.PortRange{
-		{Protocol: "tcp", From: 7496, To: 7496, Description: "serf (health check agents) peer to peer"},
-		{Protocol: "tcp", From: 7373, To: 7373, Description: "serf (health check agents) peer to peer"},
-		{Protocol: "tcp", From: 2379, To: 2380, Description: "etcd"},
-		{Protocol: "tcp", From: 4001, To: 4001, Description: "etcd"},
-		{Protocol: "tcp", From: 7001, To: 7001, Description: "etcd"},
-		{Protocol: "tcp", From: 6443, To: 6443, Description: "kubernetes API server"},
-		{Protocol: "tcp", From: 10248, To: 10255, Description: "kubernetes internal services range"},
-		{Protocol: "tcp", From: 5000, To: 5000, Description: "docker registry"},
-		{Protocol: "tcp", From: 3022, To: 3025, Description: "teleport internal SSH control panel"},
-		{Protocol: "tcp", From: 3080, To: 3080, Description: "teleport Web UI"},
-		{Protocol: "tcp", From: 3008, To: 3011, Description: "internal Gravity services"},
-		{Protocol: "tcp", From: 32009, To: 32009, Description: "Gravity Hub control panel"},
-		{Protocol: "tcp", From: 7575, To: 7575, Description: "Gravity RPC agent"},
-		{Protocol: "udp", From: vxlanPort, To: vxlanPort, Description: "overlay network"},
+		vxlanPort = int(options.VxlanPort)
+	}
+
+	var portRanges []monitoring.PortRange
+	for _, r := range schema.ReservedPorts(vxlanPort) {
+		portRanges = append(portRanges, monitoring.PortRange{
+			Protocol:    r.Protocol,
+			From:        uint64(r.From),
+			To:          uint64(r.To),
+			Description: r.Description,
+		})
+	}
+
+	if options != nil && options.WireguardPort != 0 {
+		portRanges = append(portRanges,
+			monitoring.PortRange{
+				Protocol:    "udp",
+				Description: "encrypted overlay network (WireGuard)",
+				From:        uint64(options.WireguardPort),
+				To:          uint64(options.WireguardPort),
+			},
+		)
 	}
 
 	dnsConfig := storage.DefaultDNSConfig
@@ -17,13 +17,19 @@ limitations under the License.
 package checks
 
 import (
+	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/schema"
 	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/clusterconfig"
+	"github.com/gravitational/gravity/lib/system/selinux"
+	"github.com/gravitational/gravity/lib/utils"
 
+	"github.com/gravitational/satellite/agent/health"
 	"github.com/gravitational/trace"
 	. "gopkg.in/check.v1"
 )
@@ -75,6 +81,33 @@ func (s *ChecksSuite) TestCheckRAM(c *C) {
 	c.Assert(checkRAM(s.info, notEnoughRAM), NotNil)
 }
 
+func (s *ChecksSuite) TestCheckNetworkInterfaces(c *C) {
+	info := ServerInfo{
+		System: storage.NewSystemInfo(storage.SystemSpecV2{
+			Hostname: "foo",
+			NetworkInterfaces: map[string]storage.NetworkInterface{
+				"eth0": {Name: "eth0", IPv4: "192.168.0.2"},
+				"eth1": {Name: "eth1", IPv4: "10.100.0.2"},
+			},
+		}),
+	}
+	interfaces := schema.NetworkInterfaces{
+		Cluster:     &schema.NetworkInterface{Subnet: "10.100.0.0/16"},
+		Application: &schema.NetworkInterface{Name: "eth0"},
+	}
+	c.Assert(checkNetworkInterfaces(info, interfaces), IsNil)
+
+	var server storage.Server
+	c.Assert(ConfigureInterfaces(&server, info, interfaces), IsNil)
+	c.Assert(server.ClusterAddr, Equals, "10.100.0.2")
+	c.Assert(server.ApplicationAddr, Equals, "192.168.0.2")
+
+	missing := schema.NetworkInterfaces{
+		Cluster: &schema.NetworkInterface{Name: "eth1", Subnet: "172.16.0.0/12"},
+	}
+	c.Assert(checkNetworkInterfaces(info, missing), NotNil)
+}
+
 func (s *ChecksSuite) TestTime(c *C) {
 	server := storage.NewSystemInfo(storage.SystemSpecV2{
 		Hostname: "node-1",
@@ -156,3 +189,196 @@ func (s *ChecksSuite) TestCheckSameOS(c *C) {
 	c.Assert(checkSameOS(infos[:2]), NotNil)
 	c.Assert(checkSameOS(infos[1:]), IsNil)
 }
+
+func (s *ChecksSuite) TestCheckArchitecture(c *C) {
+	server := func(arch string) Server {
+		return Server{
+			ServerInfo: ServerInfo{
+				System: storage.NewSystemInfo(storage.SystemSpecV2{
+					Hostname: "node-1",
+					Arch:     arch,
+				}),
+			},
+		}
+	}
+	var manifest schema.Manifest
+	c.Assert(checkArchitecture(server(""), manifest), IsNil)
+	c.Assert(checkArchitecture(server("amd64"), manifest), IsNil)
+	c.Assert(checkArchitecture(server("arm64"), manifest), NotNil)
+
+	manifest.SystemOptions = &schema.SystemOptions{
+		Architectures: []string{"amd64", "arm64"},
+	}
+	c.Assert(checkArchitecture(server("arm64"), manifest), IsNil)
+}
+
+func (s *ChecksSuite) TestDemoManifestRelaxesRequirements(c *C) {
+	manifest := schema.Manifest{
+		NodeProfiles: schema.NodeProfiles{
+			{
+				Name: "node",
+				Requirements: schema.Requirements{
+					CPU: schema.CPU{Min: 8},
+					RAM: schema.RAM{Min: utils.MustParseCapacity("16GB")},
+					Network: schema.Network{
+						MinTransferRate: utils.MustParseTransferRate("50MB/s"),
+					},
+					Volumes: []schema.Volume{
+						{
+							Path:            "/var/lib/data",
+							Capacity:        utils.MustParseCapacity("100GB"),
+							MinTransferRate: utils.MustParseTransferRate("50MB/s"),
+						},
+					},
+				},
+			},
+		},
+	}
+	demo := DemoManifest(manifest)
+	requirements := demo.NodeProfiles[0].Requirements
+	c.Assert(requirements.CPU.Min, Equals, defaults.DemoMinCPU)
+	c.Assert(requirements.RAM.Min, Equals, utils.MustParseCapacity(defaults.DemoMinRAM))
+	c.Assert(requirements.Network.MinTransferRate, Equals, utils.TransferRate(0))
+	c.Assert(requirements.Volumes, DeepEquals, []schema.Volume{{Path: "/var/lib/data"}})
+	// original manifest is not modified
+	c.Assert(manifest.NodeProfiles[0].Requirements.CPU.Min, Equals, 8)
+	c.Assert(manifest.NodeProfiles[0].Requirements.Volumes[0].Capacity, Equals,
+		utils.MustParseCapacity("100GB"))
+}
+
+func (s *ChecksSuite) TestCheckDiskRequirements(c *C) {
+	devices := []storage.Device{
+		{Name: "sdb", Type: storage.DeviceDisk, SizeMB: 200 * 1024},
+		{Name: "sdc", Type: storage.DeviceDisk, SizeMB: 50 * 1024},
+		{Name: "sdc1", Type: storage.DevicePartition, SizeMB: 50 * 1024},
+	}
+	var testCases = []struct {
+		disks   schema.Disks
+		failed  bool
+		comment string
+	}{
+		{
+			disks:   schema.Disks{Count: 2},
+			comment: "enough disks of any capacity",
+		},
+		{
+			disks:   schema.Disks{Count: 3},
+			failed:  true,
+			comment: "partitions are not counted",
+		},
+		{
+			disks:   schema.Disks{Count: 1, MinCapacity: utils.MustParseCapacity("100GB")},
+			comment: "enough disks of sufficient capacity",
+		},
+		{
+			disks:   schema.Disks{Count: 2, MinCapacity: utils.MustParseCapacity("100GB")},
+			failed:  true,
+			comment: "not enough disks of sufficient capacity",
+		},
+	}
+	for _, tc := range testCases {
+		checker := &diskRequirementsChecker{
+			disks: tc.disks,
+			getDevices: func() ([]storage.Device, error) {
+				return devices, nil
+			},
+		}
+		var probes health.Probes
+		checker.Check(context.TODO(), &probes)
+		c.Assert(len(probes.GetFailed()) != 0, Equals, tc.failed, Commentf(tc.comment))
+	}
+}
+
+func (s *ChecksSuite) TestCheckResourcePressure(c *C) {
+	files := map[string]string{
+		conntrackCountPath: "7600\n",
+		conntrackMaxPath:   "10000\n",
+		loadavgPath:        "0.10 0.20 0.30 2/950 12345\n",
+		pidMaxPath:         "1000\n",
+	}
+	checker := &resourcePressureChecker{
+		thresholds: clusterconfig.DefaultResourcePressure(),
+		getStateDirs: func() ([]string, error) {
+			return []string{"/var/lib/gravity", "/var/lib/gravity/planet/etcd", "/missing"}, nil
+		},
+		statfs: func(path string) (*filesystemUsage, error) {
+			switch path {
+			case "/missing":
+				return nil, trace.NotFound("%v not found", path)
+			default:
+				return &filesystemUsage{
+					device:      1,
+					usedBytes:   85,
+					totalBytes:  100,
+					usedInodes:  10,
+					totalInodes: 100,
+				}, nil
+			}
+		},
+		readFile: func(path string) ([]byte, error) {
+			if data, ok := files[path]; ok {
+				return []byte(data), nil
+			}
+			return nil, trace.NotFound("%v not found", path)
+		},
+	}
+	var probes health.Probes
+	checker.Check(context.TODO(), &probes)
+	var details []string
+	for _, probe := range probes.GetFailed() {
+		details = append(details, fmt.Sprintf("%v: %v", probe.Severity, probe.Detail))
+	}
+	c.Assert(details, DeepEquals, []string{
+		"Warning: disk space on /var/lib/gravity: 85% used (85 of 100)",
+		"Warning: connection tracking table: 76% used (7600 of 10000)",
+		"Critical: process IDs: 95% used (950 of 1000)",
+	})
+
+	checker.thresholds = clusterconfig.ResourcePressure{}
+	probes = nil
+	checker.Check(context.TODO(), &probes)
+	c.Assert(probes.GetFailed(), HasLen, 0)
+}
+
+func (s *ChecksSuite) TestCheckSELinux(c *C) {
+	mode := selinux.ModeDisabled
+	tools := map[string]bool{"semodule": true, "restorecon": true}
+	modules := []string{"sandbox"}
+	checker := &selinuxChecker{
+		stateDir: "/data/gravity",
+		getMode: func() (selinux.Mode, error) {
+			return mode, nil
+		},
+		listModules: func() ([]string, error) {
+			return modules, nil
+		},
+		lookPath: func(file string) (string, error) {
+			if tools[file] {
+				return "/usr/sbin/" + file, nil
+			}
+			return "", trace.NotFound("%v not found", file)
+		},
+	}
+	failed := func() (details []string) {
+		var probes health.Probes
+		checker.Check(context.TODO(), &probes)
+		for _, probe := range probes.GetFailed() {
+			details = append(details, probe.Detail)
+		}
+		return details
+	}
+	c.Assert(failed(), HasLen, 0)
+
+	mode = selinux.ModeEnforcing
+	c.Assert(failed(), DeepEquals, []string{
+		"SELinux is enforcing but semanage is not installed, install the policycoreutils package",
+	})
+
+	tools["semanage"] = true
+	c.Assert(failed(), DeepEquals, []string{
+		`SELinux is enforcing but the "container" policy module is not installed, install the container-selinux package`,
+	})
+
+	modules = append(modules, "container")
+	c.Assert(failed(), HasLen, 0)
+}
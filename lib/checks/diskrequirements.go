@@ -0,0 +1,96 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gravitational/gravity/lib/devicemapper"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/satellite/agent/health"
+	"github.com/gravitational/satellite/agent/proto/agentpb"
+	"github.com/gravitational/satellite/monitoring"
+	"github.com/gravitational/trace"
+)
+
+// newDiskRequirementsChecker returns a checker that verifies that the node
+// has enough unused block devices to satisfy the profile disk requirements
+func newDiskRequirementsChecker(disks schema.Disks) health.Checker {
+	return &diskRequirementsChecker{
+		disks:      disks,
+		getDevices: devicemapper.GetDevices,
+	}
+}
+
+type diskRequirementsChecker struct {
+	// disks specifies the disk requirements
+	disks schema.Disks
+	// getDevices returns the unused block devices
+	getDevices func() ([]storage.Device, error)
+}
+
+// Name returns the name of the checker
+func (*diskRequirementsChecker) Name() string {
+	return diskRequirementsCheckerID
+}
+
+// Check counts the unused block devices of sufficient capacity
+func (r *diskRequirementsChecker) Check(ctx context.Context, reporter health.Reporter) {
+	devices, err := r.getDevices()
+	if err != nil {
+		reporter.Add(monitoring.NewProbeFromErr(diskRequirementsCheckerID,
+			"failed to list block devices", trace.Wrap(err)))
+		return
+	}
+	var suitable []string
+	for _, device := range devices {
+		if device.Type != storage.DeviceDisk {
+			continue
+		}
+		if device.SizeMB<<20 < r.disks.MinCapacity.Bytes() {
+			continue
+		}
+		suitable = append(suitable, device.Name.Path())
+	}
+	if len(suitable) < r.disks.Count {
+		reporter.Add(&agentpb.Probe{
+			Checker: diskRequirementsCheckerID,
+			Detail:  r.failureDetail(len(suitable)),
+			Status:  agentpb.Probe_Failed,
+		})
+		return
+	}
+	reporter.Add(&agentpb.Probe{
+		Checker: diskRequirementsCheckerID,
+		Status:  agentpb.Probe_Running,
+	})
+}
+
+func (r *diskRequirementsChecker) failureDetail(found int) string {
+	if r.disks.MinCapacity == 0 {
+		return fmt.Sprintf("node profile requires at least %v unused disks, found %v",
+			r.disks.Count, found)
+	}
+	return fmt.Sprintf("node profile requires at least %v unused disks of at least %v, found %v",
+		r.disks.Count, r.disks.MinCapacity, found)
+}
+
+// diskRequirementsCheckerID is the name of the disk requirements checker
+const diskRequirementsCheckerID = "disk-requirements"
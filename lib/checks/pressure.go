@@ -0,0 +1,261 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checks
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/state"
+	"github.com/gravitational/gravity/lib/storage/clusterconfig"
+
+	"github.com/gravitational/satellite/agent/health"
+	"github.com/gravitational/satellite/agent/proto/agentpb"
+	"github.com/gravitational/satellite/monitoring"
+	"github.com/gravitational/trace"
+)
+
+// NewResourcePressureChecker returns a checker that reports the node resources
+// used above the specified thresholds: disk space and inodes on the gravity
+// and planet state directories, the connection tracking table and process IDs.
+//
+// The resources used above the warning threshold are reported as warning probes
+// and above the critical threshold as critical probes
+func NewResourcePressureChecker(thresholds clusterconfig.ResourcePressure) health.Checker {
+	return &resourcePressureChecker{
+		thresholds: thresholds,
+		getStateDirs: func() ([]string, error) {
+			stateDir, err := state.GetStateDir()
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			return []string{
+				stateDir,
+				filepath.Join(stateDir, defaults.PlanetDir, defaults.EtcdDir),
+				filepath.Join(stateDir, defaults.PlanetDir, defaults.DockerDir),
+			}, nil
+		},
+		statfs:   statfs,
+		readFile: ioutil.ReadFile,
+	}
+}
+
+// RunResourcePressureChecks checks the local node for resource pressure
+// and returns the failed probes
+func RunResourcePressureChecks(ctx context.Context, thresholds clusterconfig.ResourcePressure) (failed []*agentpb.Probe) {
+	var reporter health.Probes
+	NewResourcePressureChecker(thresholds).Check(ctx, &reporter)
+	return reporter.GetFailed()
+}
+
+// runResourcePressureChecks checks the local node for resource pressure
+// and returns the critical probes. Warnings are only logged
+func runResourcePressureChecks(ctx context.Context, thresholds clusterconfig.ResourcePressure) (failed []*agentpb.Probe) {
+	for _, probe := range RunResourcePressureChecks(ctx, thresholds) {
+		if probe.Severity == agentpb.Probe_Warning {
+			log.Warnf("Resource pressure: %v.", probe.Detail)
+			continue
+		}
+		failed = append(failed, probe)
+	}
+	return failed
+}
+
+type resourcePressureChecker struct {
+	// thresholds specifies the resource usage thresholds
+	thresholds clusterconfig.ResourcePressure
+	// getStateDirs returns the state directories to check
+	getStateDirs func() ([]string, error)
+	// statfs returns the usage of the filesystem the path resides on
+	statfs func(path string) (*filesystemUsage, error)
+	// readFile reads the contents of the specified file
+	readFile func(path string) ([]byte, error)
+}
+
+// filesystemUsage describes the usage of a filesystem
+type filesystemUsage struct {
+	// device identifies the filesystem
+	device uint64
+	// usedBytes is the used disk space
+	usedBytes uint64
+	// totalBytes is the total disk space
+	totalBytes uint64
+	// usedInodes is the number of used inodes
+	usedInodes uint64
+	// totalInodes is the total number of inodes
+	totalInodes uint64
+}
+
+// Name returns the name of the checker
+func (*resourcePressureChecker) Name() string {
+	return resourcePressureCheckerID
+}
+
+// Check reports the node resources used above the thresholds
+func (r *resourcePressureChecker) Check(ctx context.Context, reporter health.Reporter) {
+	var probes health.Probes
+	r.checkStateDirs(&probes)
+	r.checkConntrack(&probes)
+	r.checkPIDs(&probes)
+	if len(probes) == 0 {
+		reporter.Add(monitoring.NewSuccessProbe(resourcePressureCheckerID))
+		return
+	}
+	for _, probe := range probes {
+		reporter.Add(probe)
+	}
+}
+
+func (r *resourcePressureChecker) checkStateDirs(reporter health.Reporter) {
+	dirs, err := r.getStateDirs()
+	if err != nil {
+		reporter.Add(monitoring.NewProbeFromErr(resourcePressureCheckerID,
+			"failed to determine state directory", trace.Wrap(err)))
+		return
+	}
+	devices := make(map[uint64]struct{})
+	for _, dir := range dirs {
+		usage, err := r.statfs(dir)
+		if err != nil {
+			if !trace.IsNotFound(err) {
+				log.WithError(err).Warnf("Failed to query filesystem usage of %v.", dir)
+			}
+			continue
+		}
+		if _, ok := devices[usage.device]; ok {
+			continue
+		}
+		devices[usage.device] = struct{}{}
+		r.checkUsage(reporter, r.thresholds.Disk, usage.usedBytes, usage.totalBytes,
+			fmt.Sprintf("disk space on %v", dir))
+		r.checkUsage(reporter, r.thresholds.Inodes, usage.usedInodes, usage.totalInodes,
+			fmt.Sprintf("inodes on %v", dir))
+	}
+}
+
+func (r *resourcePressureChecker) checkConntrack(reporter health.Reporter) {
+	used, err := r.readUint(conntrackCountPath)
+	if err != nil {
+		// connection tracking is not enabled
+		return
+	}
+	total, err := r.readUint(conntrackMaxPath)
+	if err != nil {
+		return
+	}
+	r.checkUsage(reporter, r.thresholds.Conntrack, used, total, "connection tracking table")
+}
+
+func (r *resourcePressureChecker) checkPIDs(reporter health.Reporter) {
+	data, err := r.readFile(loadavgPath)
+	if err != nil {
+		log.WithError(err).Warn("Failed to query the number of processes.")
+		return
+	}
+	// The fourth field of loadavg is the number of currently runnable
+	// scheduling entities over the total number of them, i.e. processes
+	// and threads, each of which consumes a process ID
+	fields := strings.Fields(string(data))
+	if len(fields) < 4 || !strings.Contains(fields[3], "/") {
+		log.Warnf("Unexpected %v format: %q.", loadavgPath, data)
+		return
+	}
+	used, err := strconv.ParseUint(strings.SplitN(fields[3], "/", 2)[1], 10, 64)
+	if err != nil {
+		log.WithError(err).Warnf("Unexpected %v format: %q.", loadavgPath, data)
+		return
+	}
+	total, err := r.readUint(pidMaxPath)
+	if err != nil {
+		log.WithError(err).Warn("Failed to query the maximum process ID.")
+		return
+	}
+	r.checkUsage(reporter, r.thresholds.PIDs, used, total, "process IDs")
+}
+
+func (r *resourcePressureChecker) checkUsage(reporter health.Reporter, threshold *clusterconfig.Threshold, used, total uint64, resource string) {
+	if threshold == nil || total == 0 {
+		return
+	}
+	percent := used * 100 / total
+	var severity agentpb.Probe_Severity
+	switch {
+	case threshold.Critical != 0 && percent >= uint64(threshold.Critical):
+		severity = agentpb.Probe_Critical
+	case threshold.Warning != 0 && percent >= uint64(threshold.Warning):
+		severity = agentpb.Probe_Warning
+	default:
+		return
+	}
+	reporter.Add(&agentpb.Probe{
+		Checker:  resourcePressureCheckerID,
+		Detail:   fmt.Sprintf("%v: %v%% used (%v of %v)", resource, percent, used, total),
+		Status:   agentpb.Probe_Failed,
+		Severity: severity,
+	})
+}
+
+func (r *resourcePressureChecker) readUint(path string) (uint64, error) {
+	data, err := r.readFile(path)
+	if err != nil {
+		return 0, trace.ConvertSystemError(err)
+	}
+	value, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, trace.BadParameter("unexpected %v format: %q", path, data)
+	}
+	return value, nil
+}
+
+func statfs(path string) (*filesystemUsage, error) {
+	var info syscall.Stat_t
+	if err := syscall.Stat(path, &info); err != nil {
+		return nil, trace.ConvertSystemError(os.NewSyscallError("stat", err))
+	}
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(path, &fs); err != nil {
+		return nil, trace.ConvertSystemError(os.NewSyscallError("statfs", err))
+	}
+	return &filesystemUsage{
+		device:      uint64(info.Dev),
+		usedBytes:   (fs.Blocks - fs.Bfree) * uint64(fs.Bsize),
+		totalBytes:  (fs.Blocks - fs.Bfree + fs.Bavail) * uint64(fs.Bsize),
+		usedInodes:  fs.Files - fs.Ffree,
+		totalInodes: fs.Files,
+	}, nil
+}
+
+const (
+	// resourcePressureCheckerID is the name of the resource pressure checker
+	resourcePressureCheckerID = "resource-pressure"
+
+	// conntrackCountPath is the number of entries in the connection tracking table
+	conntrackCountPath = "/proc/sys/net/netfilter/nf_conntrack_count"
+	// conntrackMaxPath is the size of the connection tracking table
+	conntrackMaxPath = "/proc/sys/net/netfilter/nf_conntrack_max"
+	// loadavgPath contains the number of scheduling entities
+	loadavgPath = "/proc/loadavg"
+	// pidMaxPath is the maximum process ID
+	pidMaxPath = "/proc/sys/kernel/pid_max"
+)
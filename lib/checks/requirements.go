@@ -17,6 +17,7 @@ limitations under the License.
 package checks
 
 import (
+	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/schema"
 	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/utils"
@@ -46,6 +47,9 @@ type Network struct {
 	MinTransferRate utils.TransferRate
 	// Ports specifies requirements for ports to be available on server.
 	Ports Ports
+	// Interfaces specifies network interfaces dedicated to different
+	// kinds of traffic.
+	Interfaces *schema.NetworkInterfaces
 }
 
 // Ports describes port requirements for a specific profile.
@@ -73,6 +77,7 @@ func RequirementsFromManifest(manifest schema.Manifest) (map[string]Requirements
 			Network: Network{
 				MinTransferRate: profile.Requirements.Network.MinTransferRate,
 				Ports:           Ports{TCP: tcp, UDP: udp},
+				Interfaces:      profile.Requirements.Network.Interfaces,
 			},
 		}
 		result[profile.Name] = req
@@ -80,6 +85,36 @@ func RequirementsFromManifest(manifest schema.Manifest) (map[string]Requirements
 	return result, nil
 }
 
+// DemoManifest returns a copy of the specified manifest with node profile
+// requirements relaxed for demo installations: CPU and RAM minimums are capped
+// to the demo defaults and disk capacity and transfer rate requirements are removed.
+func DemoManifest(manifest schema.Manifest) schema.Manifest {
+	profiles := make(schema.NodeProfiles, 0, len(manifest.NodeProfiles))
+	for _, profile := range manifest.NodeProfiles {
+		requirements := &profile.Requirements
+		if requirements.CPU.Min > defaults.DemoMinCPU {
+			requirements.CPU.Min = defaults.DemoMinCPU
+		}
+		if requirements.RAM.Min > demoMinRAM {
+			requirements.RAM.Min = demoMinRAM
+		}
+		requirements.Network.MinTransferRate = 0
+		volumes := make([]schema.Volume, 0, len(requirements.Volumes))
+		for _, volume := range requirements.Volumes {
+			volume.Capacity = 0
+			volume.MinTransferRate = 0
+			volumes = append(volumes, volume)
+		}
+		requirements.Volumes = volumes
+		profiles = append(profiles, profile)
+	}
+	manifest.NodeProfiles = profiles
+	return manifest
+}
+
+// demoMinRAM is the maximum RAM requirement enforced for demo installations
+var demoMinRAM = utils.MustParseCapacity(defaults.DemoMinRAM)
+
 // RequirementsFromManifests generates check requirements as a difference
 // between two manifests - old and new.
 func RequirementsFromManifests(old, new schema.Manifest, profiles map[string]string, docker storage.DockerConfig) (map[string]Requirements, error) {
@@ -102,7 +137,8 @@ func RequirementsFromManifests(old, new schema.Manifest, profiles map[string]str
 			OS:      newProfile.Requirements.OS,
 			Volumes: schema.DiffVolumes(oldProfile.Requirements.Volumes, newProfile.Requirements.Volumes),
 			Network: Network{
-				Ports: Ports{TCP: tcp, UDP: udp},
+				Ports:      Ports{TCP: tcp, UDP: udp},
+				Interfaces: newProfile.Requirements.Network.Interfaces,
 			},
 			Docker: docker,
 		}
@@ -0,0 +1,123 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checks
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+
+	"github.com/gravitational/gravity/lib/system/selinux"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/satellite/agent/health"
+	"github.com/gravitational/satellite/agent/proto/agentpb"
+	"github.com/gravitational/satellite/monitoring"
+	"github.com/gravitational/trace"
+)
+
+// NewSELinuxChecker returns a checker that verifies that the gravity
+// SELinux policy can be installed on the node if SELinux is enabled.
+// The node does not need to be switched to permissive mode
+func NewSELinuxChecker(stateDir string) health.Checker {
+	return &selinuxChecker{
+		stateDir:    stateDir,
+		getMode:     selinux.GetMode,
+		listModules: selinux.ListModules,
+		lookPath:    exec.LookPath,
+	}
+}
+
+// runSELinuxChecks checks the node SELinux configuration
+// and returns the failed probes
+func runSELinuxChecks(ctx context.Context, stateDir string) []*agentpb.Probe {
+	var reporter health.Probes
+	NewSELinuxChecker(stateDir).Check(ctx, &reporter)
+	return reporter.GetFailed()
+}
+
+type selinuxChecker struct {
+	// stateDir is the gravity state directory
+	stateDir string
+	// getMode returns the SELinux mode
+	getMode func() (selinux.Mode, error)
+	// listModules returns the installed SELinux policy modules
+	listModules func() ([]string, error)
+	// lookPath searches for the specified executable
+	lookPath func(file string) (string, error)
+}
+
+// Name returns the name of the checker
+func (*selinuxChecker) Name() string {
+	return selinuxCheckerID
+}
+
+// Check verifies that the SELinux policy management tools and the policy
+// modules the gravity policy depends on are available
+func (r *selinuxChecker) Check(ctx context.Context, reporter health.Reporter) {
+	mode, err := r.getMode()
+	if err != nil {
+		reporter.Add(monitoring.NewProbeFromErr(selinuxCheckerID,
+			"failed to determine SELinux mode", trace.Wrap(err)))
+		return
+	}
+	if !mode.IsEnabled() {
+		reporter.Add(monitoring.NewSuccessProbe(selinuxCheckerID))
+		return
+	}
+	var probes health.Probes
+	for _, tool := range selinux.RequiredTools(r.stateDir) {
+		if _, err := r.lookPath(tool); err != nil {
+			probes.Add(r.newFailedProbe(fmt.Sprintf(
+				"SELinux is %v but %v is not installed, install the policycoreutils package",
+				mode, tool)))
+		}
+	}
+	if len(probes) == 0 {
+		modules, err := r.listModules()
+		if err != nil {
+			probes.Add(monitoring.NewProbeFromErr(selinuxCheckerID,
+				"failed to list SELinux policy modules", trace.Wrap(err)))
+		}
+		for _, module := range selinux.RequiredModules {
+			if err == nil && !utils.StringInSlice(modules, module) {
+				probes.Add(r.newFailedProbe(fmt.Sprintf(
+					"SELinux is %v but the %q policy module is not installed, install the container-selinux package",
+					mode, module)))
+			}
+		}
+	}
+	if len(probes) == 0 {
+		reporter.Add(monitoring.NewSuccessProbe(selinuxCheckerID))
+		return
+	}
+	for _, probe := range probes {
+		reporter.Add(probe)
+	}
+}
+
+func (r *selinuxChecker) newFailedProbe(detail string) *agentpb.Probe {
+	return &agentpb.Probe{
+		Checker:  selinuxCheckerID,
+		Detail:   detail,
+		Status:   agentpb.Probe_Failed,
+		Severity: agentpb.Probe_Critical,
+	}
+}
+
+// selinuxCheckerID is the name of the SELinux checker
+const selinuxCheckerID = "selinux"
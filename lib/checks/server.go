@@ -18,12 +18,14 @@ package checks
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/rpc"
 	rpcclient "github.com/gravitational/gravity/lib/rpc/client"
 	pb "github.com/gravitational/gravity/lib/rpc/proto"
+	"github.com/gravitational/gravity/lib/schema"
 	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/utils"
 
@@ -46,6 +48,43 @@ func (r ServerInfos) FindByIP(addr string) (*ServerInfo, error) {
 	return nil, trace.NotFound("no system info found for IP %v", ip)
 }
 
+// InterfaceAddr returns the IPv4 address of the network interface
+// that matches the specified selector
+func (r ServerInfo) InterfaceAddr(selector schema.NetworkInterface) (addr string, err error) {
+	interfaces := r.GetNetworkInterfaces()
+	names := make([]string, 0, len(interfaces))
+	for name := range interfaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		iface := interfaces[name]
+		if iface.IPv4 != "" && selector.Matches(iface.Name, iface.IPv4) {
+			return iface.IPv4, nil
+		}
+	}
+	return "", trace.NotFound("server %q has no network interface %v with an IPv4 address",
+		r.GetHostname(), selector)
+}
+
+// ConfigureInterfaces sets the addresses of the network interfaces
+// dedicated to the cluster and application traffic on the specified server
+func ConfigureInterfaces(server *storage.Server, info ServerInfo, interfaces schema.NetworkInterfaces) (err error) {
+	if interfaces.Cluster != nil {
+		server.ClusterAddr, err = info.InterfaceAddr(*interfaces.Cluster)
+		if err != nil {
+			return trace.Wrap(err, "failed to find the cluster network interface")
+		}
+	}
+	if interfaces.Application != nil {
+		server.ApplicationAddr, err = info.InterfaceAddr(*interfaces.Application)
+		if err != nil {
+			return trace.Wrap(err, "failed to find the application network interface")
+		}
+	}
+	return nil
+}
+
 // Hostnames returns the list of all hostnames
 func (r ServerInfos) Hostnames() (hostnames []string) {
 	for _, info := range r {
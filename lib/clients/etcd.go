@@ -17,10 +17,13 @@ limitations under the License.
 package clients
 
 import (
+	"context"
+	"fmt"
 	"path/filepath"
 	"time"
 
 	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/utils"
 
 	etcd "github.com/coreos/etcd/client"
 	"github.com/coreos/etcd/pkg/transport"
@@ -100,3 +103,71 @@ func EtcdMembers(config *EtcdConfig) (etcd.MembersAPI, error) {
 func DefaultEtcdMembers() (etcd.MembersAPI, error) {
 	return EtcdMembers(&EtcdConfig{})
 }
+
+// EtcdMemberHealth describes the health of an etcd cluster member
+type EtcdMemberHealth struct {
+	// Member is the etcd cluster member
+	Member etcd.Member
+	// Error is the error returned by the member health check, nil if the member is healthy
+	Error error
+}
+
+// EtcdHealth returns the health of every member of the etcd cluster
+// the specified configuration points to
+func EtcdHealth(ctx context.Context, config EtcdConfig) (health []EtcdMemberHealth, err error) {
+	membersAPI, err := EtcdMembers(&config)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	members, err := membersAPI.List(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, member := range members {
+		memberConfig := config
+		memberConfig.Endpoints = member.ClientURLs
+		health = append(health, EtcdMemberHealth{
+			Member: member,
+			Error:  checkEtcdMember(ctx, memberConfig),
+		})
+	}
+	return health, nil
+}
+
+// CheckEtcdMemberRemoval verifies that the etcd member with the specified peer
+// address can be removed without losing the quorum of the etcd cluster
+func CheckEtcdMemberRemoval(health []EtcdMemberHealth, addr string) (issues []string) {
+	var remaining, healthy int
+	for _, member := range health {
+		if len(member.Member.PeerURLs) != 0 {
+			host, err := utils.URLHostname(member.Member.PeerURLs[0])
+			if err == nil && host == addr {
+				continue
+			}
+		}
+		remaining++
+		if member.Error != nil {
+			issues = append(issues, fmt.Sprintf("etcd member %v is unhealthy: %v",
+				member.Member.Name, trace.UserMessage(member.Error)))
+			continue
+		}
+		healthy++
+	}
+	if quorum := remaining/2 + 1; remaining != 0 && healthy < quorum {
+		issues = append(issues, fmt.Sprintf("removing the node leaves %v healthy etcd "+
+			"member(-s) of %v which is less than the required quorum of %v",
+			healthy, remaining, quorum))
+	}
+	return issues
+}
+
+func checkEtcdMember(ctx context.Context, config EtcdConfig) error {
+	client, err := Etcd(&config)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, defaults.DialTimeout)
+	defer cancel()
+	_, err = client.GetVersion(ctx)
+	return trace.Wrap(err)
+}
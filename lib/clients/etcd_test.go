@@ -0,0 +1,60 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"testing"
+
+	etcd "github.com/coreos/etcd/client"
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+func TestClients(t *testing.T) { TestingT(t) }
+
+type EtcdSuite struct{}
+
+var _ = Suite(&EtcdSuite{})
+
+func (*EtcdSuite) TestChecksMemberRemoval(c *C) {
+	healthy := func(name, addr string) EtcdMemberHealth {
+		return EtcdMemberHealth{Member: etcd.Member{
+			Name:     name,
+			PeerURLs: []string{"https://" + addr + ":2380"},
+		}}
+	}
+	unhealthy := func(name, addr string) EtcdMemberHealth {
+		health := healthy(name, addr)
+		health.Error = trace.ConnectionProblem(nil, "connection refused")
+		return health
+	}
+
+	members := []EtcdMemberHealth{
+		healthy("node-1", "10.0.0.1"),
+		healthy("node-2", "10.0.0.2"),
+		healthy("node-3", "10.0.0.3"),
+	}
+	c.Assert(CheckEtcdMemberRemoval(members, "10.0.0.3"), HasLen, 0)
+
+	// removing an unhealthy member is safe
+	members[2] = unhealthy("node-3", "10.0.0.3")
+	c.Assert(CheckEtcdMemberRemoval(members, "10.0.0.3"), HasLen, 0)
+
+	// removing a healthy member while another one is unhealthy loses the quorum
+	issues := CheckEtcdMemberRemoval(members, "10.0.0.1")
+	c.Assert(issues, HasLen, 2, Commentf("%v", issues))
+}
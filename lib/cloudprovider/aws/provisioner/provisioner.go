@@ -0,0 +1,350 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package provisioner implements provisioning of AWS infrastructure
+// (security groups and instances) for cluster install and expand operations
+// from a declarative spec.
+package provisioner
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/gravitational/gravity/lib/constants"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// EC2 defines the subset of the EC2 API used by the provisioner.
+// It is implemented by *ec2.EC2
+type EC2 interface {
+	CreateSecurityGroupWithContext(aws.Context, *ec2.CreateSecurityGroupInput, ...request.Option) (*ec2.CreateSecurityGroupOutput, error)
+	AuthorizeSecurityGroupIngressWithContext(aws.Context, *ec2.AuthorizeSecurityGroupIngressInput, ...request.Option) (*ec2.AuthorizeSecurityGroupIngressOutput, error)
+	DescribeSecurityGroupsWithContext(aws.Context, *ec2.DescribeSecurityGroupsInput, ...request.Option) (*ec2.DescribeSecurityGroupsOutput, error)
+	DeleteSecurityGroupWithContext(aws.Context, *ec2.DeleteSecurityGroupInput, ...request.Option) (*ec2.DeleteSecurityGroupOutput, error)
+	RunInstancesWithContext(aws.Context, *ec2.RunInstancesInput, ...request.Option) (*ec2.Reservation, error)
+	DescribeInstancesWithContext(aws.Context, *ec2.DescribeInstancesInput, ...request.Option) (*ec2.DescribeInstancesOutput, error)
+	TerminateInstancesWithContext(aws.Context, *ec2.TerminateInstancesInput, ...request.Option) (*ec2.TerminateInstancesOutput, error)
+	WaitUntilInstanceRunningWithContext(aws.Context, *ec2.DescribeInstancesInput, ...request.WaiterOption) error
+	WaitUntilInstanceTerminatedWithContext(aws.Context, *ec2.DescribeInstancesInput, ...request.WaiterOption) error
+}
+
+// Config defines the provisioner configuration
+type Config struct {
+	// Spec is the provisioning spec
+	Spec Spec
+	// ClusterName is the name of the cluster the instances are provisioned for.
+	// All provisioned resources are tagged with the cluster name
+	ClusterName string
+	// UserData returns the instance user data script for the specified
+	// node profile. The script is expected to start the join agent
+	UserData func(profile string) string
+	// EC2 is the optional EC2 API client.
+	// If unspecified, a client for the spec's region is created
+	// using the default credentials chain
+	EC2 EC2
+	// FieldLogger is used for logging
+	log.FieldLogger
+}
+
+// CheckAndSetDefaults validates the configuration and sets defaults
+func (r *Config) CheckAndSetDefaults() error {
+	if err := r.Spec.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	if r.ClusterName == "" {
+		return trace.BadParameter("missing ClusterName")
+	}
+	if r.UserData == nil {
+		return trace.BadParameter("missing UserData")
+	}
+	if r.EC2 == nil {
+		session, err := session.NewSession(&aws.Config{
+			Region: aws.String(r.Spec.Region),
+		})
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		r.EC2 = ec2.New(session)
+	}
+	if r.FieldLogger == nil {
+		r.FieldLogger = log.WithFields(log.Fields{
+			trace.Component: "aws:provisioner",
+			"cluster":       r.ClusterName,
+		})
+	}
+	return nil
+}
+
+// New returns a new provisioner for the specified configuration
+func New(config Config) (*Provisioner, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &Provisioner{Config: config}, nil
+}
+
+// Provisioner provisions AWS infrastructure for a cluster
+type Provisioner struct {
+	// Config is the provisioner configuration
+	Config
+}
+
+// Instance describes a provisioned instance
+type Instance struct {
+	// ID is the EC2 instance ID
+	ID string
+	// Profile is the node profile the instance has been provisioned for
+	Profile string
+	// PrivateIP is the private IPv4 address of the instance
+	PrivateIP string
+}
+
+// Provision creates the cluster security group and all instances
+// described by the spec
+func (r *Provisioner) Provision(ctx context.Context) ([]Instance, error) {
+	groupID, err := r.ensureSecurityGroup(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var instances []Instance
+	for _, group := range r.Spec.Nodes {
+		provisioned, err := r.runInstances(ctx, groupID, group)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		instances = append(instances, provisioned...)
+	}
+	return instances, nil
+}
+
+// AddInstances provisions count additional instances for the specified
+// node profile. It is used by expand operations to request new nodes
+func (r *Provisioner) AddInstances(ctx context.Context, profile string, count int) ([]Instance, error) {
+	group, err := r.nodeGroup(profile)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	group.Count = count
+	if err := group.Check(r.Spec.Region); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	groupID, err := r.ensureSecurityGroup(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return r.runInstances(ctx, groupID, *group)
+}
+
+// Deprovision terminates all instances provisioned for the cluster
+// and removes the cluster security group
+func (r *Provisioner) Deprovision(ctx context.Context) error {
+	ids, err := r.clusterInstanceIDs(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if len(ids) != 0 {
+		r.WithField("instances", aws.StringValueSlice(ids)).Info("Terminate instances.")
+		_, err = r.EC2.TerminateInstancesWithContext(ctx, &ec2.TerminateInstancesInput{
+			InstanceIds: ids,
+		})
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		err = r.EC2.WaitUntilInstanceTerminatedWithContext(ctx, &ec2.DescribeInstancesInput{
+			InstanceIds: ids,
+		})
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	groupID, err := r.securityGroupID(ctx)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil
+		}
+		return trace.Wrap(err)
+	}
+	r.WithField("group", groupID).Info("Delete security group.")
+	_, err = r.EC2.DeleteSecurityGroupWithContext(ctx, &ec2.DeleteSecurityGroupInput{
+		GroupId: aws.String(groupID),
+	})
+	return trace.Wrap(err)
+}
+
+func (r *Provisioner) runInstances(ctx context.Context, groupID string, group NodeGroup) ([]Instance, error) {
+	logger := r.WithFields(log.Fields{
+		"profile": group.Profile,
+		"type":    group.InstanceType,
+		"count":   group.Count,
+	})
+	logger.Info("Run instances.")
+	input := &ec2.RunInstancesInput{
+		ImageId:          aws.String(r.Spec.ImageID),
+		InstanceType:     aws.String(group.InstanceType),
+		KeyName:          aws.String(r.Spec.KeyPair),
+		SubnetId:         aws.String(r.Spec.SubnetID),
+		SecurityGroupIds: []*string{aws.String(groupID)},
+		MinCount:         aws.Int64(int64(group.Count)),
+		MaxCount:         aws.Int64(int64(group.Count)),
+		UserData: aws.String(base64.StdEncoding.EncodeToString(
+			[]byte(r.UserData(group.Profile)))),
+		TagSpecifications: []*ec2.TagSpecification{{
+			ResourceType: aws.String(ec2.ResourceTypeInstance),
+			Tags:         r.instanceTags(group.Profile),
+		}},
+	}
+	if r.Spec.InstanceProfile != "" {
+		input.IamInstanceProfile = &ec2.IamInstanceProfileSpecification{
+			Name: aws.String(r.Spec.InstanceProfile),
+		}
+	}
+	reservation, err := r.EC2.RunInstancesWithContext(ctx, input)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var ids []*string
+	for _, instance := range reservation.Instances {
+		ids = append(ids, instance.InstanceId)
+	}
+	err = r.EC2.WaitUntilInstanceRunningWithContext(ctx, &ec2.DescribeInstancesInput{
+		InstanceIds: ids,
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	instances := make([]Instance, 0, len(reservation.Instances))
+	for _, instance := range reservation.Instances {
+		instances = append(instances, Instance{
+			ID:        aws.StringValue(instance.InstanceId),
+			Profile:   group.Profile,
+			PrivateIP: aws.StringValue(instance.PrivateIpAddress),
+		})
+	}
+	logger.WithField("instances", aws.StringValueSlice(ids)).Info("Instances running.")
+	return instances, nil
+}
+
+// ensureSecurityGroup creates the cluster security group unless it already exists
+// and returns its ID. The group allows all traffic between cluster instances
+// and SSH access from anywhere
+func (r *Provisioner) ensureSecurityGroup(ctx context.Context) (groupID string, err error) {
+	groupID, err = r.securityGroupID(ctx)
+	if err == nil {
+		return groupID, nil
+	}
+	if !trace.IsNotFound(err) {
+		return "", trace.Wrap(err)
+	}
+	out, err := r.EC2.CreateSecurityGroupWithContext(ctx, &ec2.CreateSecurityGroupInput{
+		GroupName:   aws.String(r.securityGroupName()),
+		Description: aws.String(fmt.Sprintf("Security group for cluster %v", r.ClusterName)),
+		VpcId:       aws.String(r.Spec.VPCID),
+	})
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	groupID = aws.StringValue(out.GroupId)
+	r.WithField("group", groupID).Info("Created security group.")
+	_, err = r.EC2.AuthorizeSecurityGroupIngressWithContext(ctx, &ec2.AuthorizeSecurityGroupIngressInput{
+		GroupId: aws.String(groupID),
+		IpPermissions: []*ec2.IpPermission{
+			{
+				IpProtocol:       aws.String("-1"),
+				UserIdGroupPairs: []*ec2.UserIdGroupPair{{GroupId: aws.String(groupID)}},
+			},
+			{
+				IpProtocol: aws.String("tcp"),
+				FromPort:   aws.Int64(22),
+				ToPort:     aws.Int64(22),
+				IpRanges:   []*ec2.IpRange{{CidrIp: aws.String("0.0.0.0/0")}},
+			},
+		},
+	})
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return groupID, nil
+}
+
+func (r *Provisioner) securityGroupID(ctx context.Context) (string, error) {
+	out, err := r.EC2.DescribeSecurityGroupsWithContext(ctx, &ec2.DescribeSecurityGroupsInput{
+		Filters: []*ec2.Filter{
+			{Name: aws.String("vpc-id"), Values: []*string{aws.String(r.Spec.VPCID)}},
+			{Name: aws.String("group-name"), Values: []*string{aws.String(r.securityGroupName())}},
+		},
+	})
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	if len(out.SecurityGroups) == 0 {
+		return "", trace.NotFound("security group %v not found", r.securityGroupName())
+	}
+	return aws.StringValue(out.SecurityGroups[0].GroupId), nil
+}
+
+// clusterInstanceIDs returns IDs of all non-terminated instances
+// provisioned for the cluster
+func (r *Provisioner) clusterInstanceIDs(ctx context.Context) (ids []*string, err error) {
+	out, err := r.EC2.DescribeInstancesWithContext(ctx, &ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			{Name: aws.String("tag:" + constants.AWSClusterNameTag), Values: []*string{aws.String(r.ClusterName)}},
+			{Name: aws.String("instance-state-name"), Values: aws.StringSlice([]string{
+				ec2.InstanceStateNamePending, ec2.InstanceStateNameRunning,
+				ec2.InstanceStateNameStopping, ec2.InstanceStateNameStopped,
+			})},
+		},
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, reservation := range out.Reservations {
+		for _, instance := range reservation.Instances {
+			ids = append(ids, instance.InstanceId)
+		}
+	}
+	return ids, nil
+}
+
+func (r *Provisioner) nodeGroup(profile string) (*NodeGroup, error) {
+	for _, group := range r.Spec.Nodes {
+		if group.Profile == profile {
+			return &group, nil
+		}
+	}
+	return nil, trace.NotFound("no node group for profile %q in provisioning spec", profile)
+}
+
+func (r *Provisioner) instanceTags(profile string) []*ec2.Tag {
+	return []*ec2.Tag{
+		{Key: aws.String(constants.AWSClusterNameTag), Value: aws.String(r.ClusterName)},
+		{Key: aws.String(ProfileTag), Value: aws.String(profile)},
+		{Key: aws.String("Name"), Value: aws.String(fmt.Sprintf("%v-%v", r.ClusterName, profile))},
+	}
+}
+
+func (r *Provisioner) securityGroupName() string {
+	return fmt.Sprintf("%v-cluster", r.ClusterName)
+}
+
+// ProfileTag is the tag with the node profile of a provisioned instance
+const ProfileTag = "gravitational.io/profile"
@@ -0,0 +1,186 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioner
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	. "gopkg.in/check.v1"
+)
+
+func TestProvisioner(t *testing.T) { TestingT(t) }
+
+type ProvisionerSuite struct{}
+
+var _ = Suite(&ProvisionerSuite{})
+
+func (*ProvisionerSuite) TestParsesSpec(c *C) {
+	spec, err := ParseSpec([]byte(`
+region: us-west-2
+vpcId: vpc-1
+subnetId: subnet-1
+keyPair: ops
+nodes:
+- profile: node
+  instanceType: m4.xlarge
+  count: 3
+`))
+	c.Assert(err, IsNil)
+	c.Assert(spec, DeepEquals, &Spec{
+		Region:   "us-west-2",
+		VPCID:    "vpc-1",
+		SubnetID: "subnet-1",
+		KeyPair:  "ops",
+		ImageID:  "ami-14b07274",
+		Nodes: []NodeGroup{
+			{Profile: "node", InstanceType: "m4.xlarge", Count: 3},
+		},
+	})
+}
+
+func (*ProvisionerSuite) TestValidatesSpec(c *C) {
+	testCases := []struct {
+		spec    string
+		comment string
+	}{
+		{
+			spec:    `{"vpcId": "vpc-1", "subnetId": "subnet-1", "keyPair": "ops", "nodes": [{"profile": "node", "instanceType": "m4.xlarge", "count": 1}]}`,
+			comment: "missing region",
+		},
+		{
+			spec:    `{"region": "us-west-2", "vpcId": "vpc-1", "subnetId": "subnet-1", "keyPair": "ops"}`,
+			comment: "no node groups",
+		},
+		{
+			spec:    `{"region": "ap-northeast-2", "vpcId": "vpc-1", "subnetId": "subnet-1", "keyPair": "ops", "nodes": [{"profile": "node", "instanceType": "m3.large", "count": 1}]}`,
+			comment: "unsupported instance type",
+		},
+		{
+			spec:    `{"region": "us-west-2", "vpcId": "vpc-1", "subnetId": "subnet-1", "keyPair": "ops", "nodes": [{"profile": "node", "instanceType": "m4.xlarge"}]}`,
+			comment: "missing count",
+		},
+	}
+	for _, tc := range testCases {
+		_, err := ParseSpec([]byte(tc.spec))
+		c.Assert(err, NotNil, Commentf(tc.comment))
+	}
+}
+
+func (*ProvisionerSuite) TestProvisionsInstances(c *C) {
+	client := &fakeEC2{}
+	provisioner, err := New(Config{
+		Spec: Spec{
+			Region:   "us-west-2",
+			VPCID:    "vpc-1",
+			SubnetID: "subnet-1",
+			KeyPair:  "ops",
+			ImageID:  "ami-1",
+			Nodes: []NodeGroup{
+				{Profile: "master", InstanceType: "m4.xlarge", Count: 1},
+				{Profile: "node", InstanceType: "m4.large", Count: 2},
+			},
+		},
+		ClusterName: "example",
+		UserData: func(profile string) string {
+			return "join " + profile
+		},
+		EC2: client,
+	})
+	c.Assert(err, IsNil)
+
+	instances, err := provisioner.Provision(context.TODO())
+	c.Assert(err, IsNil)
+	c.Assert(instances, DeepEquals, []Instance{
+		{ID: "i-1", Profile: "master", PrivateIP: "10.0.0.1"},
+		{ID: "i-2", Profile: "node", PrivateIP: "10.0.0.2"},
+		{ID: "i-3", Profile: "node", PrivateIP: "10.0.0.3"},
+	})
+	c.Assert(client.groups, DeepEquals, []string{"example-cluster"})
+	c.Assert(client.userData, DeepEquals, []string{"join master", "join node"})
+
+	instances, err = provisioner.AddInstances(context.TODO(), "node", 1)
+	c.Assert(err, IsNil)
+	c.Assert(instances, DeepEquals, []Instance{
+		{ID: "i-4", Profile: "node", PrivateIP: "10.0.0.4"},
+	})
+	c.Assert(client.groups, DeepEquals, []string{"example-cluster"}, Commentf("security group should be reused"))
+
+	_, err = provisioner.AddInstances(context.TODO(), "unknown", 1)
+	c.Assert(err, NotNil)
+}
+
+// fakeEC2 implements the subset of EC2 API the provisioner uses
+type fakeEC2 struct {
+	EC2
+	// groups lists the names of created security groups
+	groups []string
+	// userData lists the user data of run instance requests
+	userData []string
+	// instances counts the provisioned instances
+	instances int
+}
+
+func (r *fakeEC2) DescribeSecurityGroupsWithContext(_ aws.Context, input *ec2.DescribeSecurityGroupsInput, _ ...request.Option) (*ec2.DescribeSecurityGroupsOutput, error) {
+	var groups []*ec2.SecurityGroup
+	for _, filter := range input.Filters {
+		if aws.StringValue(filter.Name) != "group-name" {
+			continue
+		}
+		for _, name := range r.groups {
+			if name == aws.StringValue(filter.Values[0]) {
+				groups = append(groups, &ec2.SecurityGroup{GroupId: aws.String("sg-" + name)})
+			}
+		}
+	}
+	return &ec2.DescribeSecurityGroupsOutput{SecurityGroups: groups}, nil
+}
+
+func (r *fakeEC2) CreateSecurityGroupWithContext(_ aws.Context, input *ec2.CreateSecurityGroupInput, _ ...request.Option) (*ec2.CreateSecurityGroupOutput, error) {
+	r.groups = append(r.groups, aws.StringValue(input.GroupName))
+	return &ec2.CreateSecurityGroupOutput{GroupId: aws.String("sg-" + aws.StringValue(input.GroupName))}, nil
+}
+
+func (r *fakeEC2) AuthorizeSecurityGroupIngressWithContext(aws.Context, *ec2.AuthorizeSecurityGroupIngressInput, ...request.Option) (*ec2.AuthorizeSecurityGroupIngressOutput, error) {
+	return &ec2.AuthorizeSecurityGroupIngressOutput{}, nil
+}
+
+func (r *fakeEC2) RunInstancesWithContext(_ aws.Context, input *ec2.RunInstancesInput, _ ...request.Option) (*ec2.Reservation, error) {
+	userData, err := base64.StdEncoding.DecodeString(aws.StringValue(input.UserData))
+	if err != nil {
+		return nil, err
+	}
+	r.userData = append(r.userData, string(userData))
+	var reservation ec2.Reservation
+	for i := int64(0); i < aws.Int64Value(input.MaxCount); i++ {
+		r.instances++
+		reservation.Instances = append(reservation.Instances, &ec2.Instance{
+			InstanceId:       aws.String(fmt.Sprintf("i-%v", r.instances)),
+			PrivateIpAddress: aws.String(fmt.Sprintf("10.0.0.%v", r.instances)),
+		})
+	}
+	return &reservation, nil
+}
+
+func (r *fakeEC2) WaitUntilInstanceRunningWithContext(aws.Context, *ec2.DescribeInstancesInput, ...request.WaiterOption) error {
+	return nil
+}
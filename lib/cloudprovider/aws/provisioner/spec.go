@@ -0,0 +1,140 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioner
+
+import (
+	"io/ioutil"
+
+	"github.com/gravitational/gravity/lib/cloudprovider/aws"
+
+	"github.com/ghodss/yaml"
+	"github.com/gravitational/trace"
+)
+
+// Spec is the declarative description of the AWS infrastructure
+// to provision for a cluster.
+//
+// Example:
+//
+//	region: us-west-2
+//	vpcId: vpc-0a1b2c3d
+//	subnetId: subnet-0a1b2c3d
+//	keyPair: ops
+//	nodes:
+//	- profile: node
+//	  instanceType: m4.xlarge
+//	  count: 3
+type Spec struct {
+	// Region is the AWS region to provision instances in
+	Region string `json:"region"`
+	// VPCID is the ID of the existing VPC to provision instances in
+	VPCID string `json:"vpcId"`
+	// SubnetID is the ID of the existing subnet to provision instances in
+	SubnetID string `json:"subnetId"`
+	// KeyPair is the name of the SSH key pair to assign to instances
+	KeyPair string `json:"keyPair"`
+	// ImageID is the ID of the AMI to launch instances from.
+	// Defaults to the image from aws.Regions for the region
+	ImageID string `json:"imageId"`
+	// InstanceProfile is the optional IAM instance profile name
+	// to assign to instances
+	InstanceProfile string `json:"instanceProfile,omitempty"`
+	// Nodes lists the groups of instances to provision
+	Nodes []NodeGroup `json:"nodes"`
+}
+
+// NodeGroup describes a set of instances with the same node profile
+type NodeGroup struct {
+	// Profile is the name of the node profile from the application manifest
+	Profile string `json:"profile"`
+	// InstanceType is the EC2 instance type
+	InstanceType string `json:"instanceType"`
+	// Count is the number of instances to provision
+	Count int `json:"count"`
+}
+
+// ReadSpec reads the provisioning spec from the specified file
+func ReadSpec(path string) (*Spec, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	return ParseSpec(data)
+}
+
+// ParseSpec parses the provisioning spec from the specified YAML or JSON data
+func ParseSpec(data []byte) (*Spec, error) {
+	var spec Spec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, trace.BadParameter("failed to parse provisioning spec: %v", err)
+	}
+	if err := spec.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &spec, nil
+}
+
+// CheckAndSetDefaults validates the spec and sets defaults
+func (r *Spec) CheckAndSetDefaults() error {
+	if r.Region == "" {
+		return trace.BadParameter("provisioning spec is missing region")
+	}
+	if r.VPCID == "" {
+		return trace.BadParameter("provisioning spec is missing vpcId")
+	}
+	if r.SubnetID == "" {
+		return trace.BadParameter("provisioning spec is missing subnetId")
+	}
+	if r.KeyPair == "" {
+		return trace.BadParameter("provisioning spec is missing keyPair")
+	}
+	if r.ImageID == "" {
+		mapping, ok := aws.Regions[aws.RegionName(r.Region)]
+		if !ok {
+			return trace.BadParameter("provisioning spec is missing imageId "+
+				"and there is no default image for region %q", r.Region)
+		}
+		r.ImageID = mapping.Image
+	}
+	if len(r.Nodes) == 0 {
+		return trace.BadParameter("provisioning spec should list at least one node group")
+	}
+	for _, group := range r.Nodes {
+		if err := group.Check(r.Region); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// Check validates the node group for the specified region
+func (r NodeGroup) Check(region string) error {
+	if r.Profile == "" {
+		return trace.BadParameter("node group is missing profile")
+	}
+	if r.InstanceType == "" {
+		return trace.BadParameter("node group %q is missing instanceType", r.Profile)
+	}
+	if !aws.SupportsInstanceType(region, r.InstanceType) {
+		return trace.BadParameter("instance type %q is not supported in region %q",
+			r.InstanceType, region)
+	}
+	if r.Count < 1 {
+		return trace.BadParameter("node group %q should have a positive count", r.Profile)
+	}
+	return nil
+}
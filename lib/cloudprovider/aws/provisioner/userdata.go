@@ -0,0 +1,52 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisioner
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/gravitational/gravity/lib/schema"
+)
+
+// JoinUserData returns a function that generates instance user data for a node profile.
+// The user data downloads the join instructions for the profile from the portal
+// at portalURL (an installer or a cluster) using the specified token and executes them.
+func JoinUserData(portalURL, token string) func(profile string) string {
+	return func(profile string) string {
+		var buf bytes.Buffer
+		// The template is static and the parameters are plain strings
+		// so execution cannot fail
+		userDataTemplate.Execute(&buf, map[string]string{
+			"url":            strings.Join([]string{portalURL, "t", token, profile}, "/"),
+			"advertise_addr": schema.AdvertiseAddr,
+		})
+		return buf.String()
+	}
+}
+
+// userDataTemplate downloads the join instructions retrying until
+// the portal becomes available and executes them
+var userDataTemplate = template.Must(template.New("userdata").Parse(`#!/bin/bash
+set -e
+ADVERTISE_ADDR=$(curl -s http://169.254.169.254/latest/meta-data/local-ipv4)
+until curl -s -f --tlsv1.2 -0 -k "{{.url}}?{{.advertise_addr}}=${ADVERTISE_ADDR}" -o /tmp/gravity-join.sh; do
+    sleep 5
+done
+bash /tmp/gravity-join.sh
+`))
@@ -0,0 +1,101 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// Instance describes the Azure virtual machine the process is running on
+type Instance struct {
+	// ID is the unique virtual machine ID
+	ID string `json:"vmId"`
+	// Name is the virtual machine name
+	Name string `json:"name"`
+	// SubscriptionID is the ID of the subscription the machine belongs to
+	SubscriptionID string `json:"subscriptionId"`
+	// ResourceGroup is the name of the machine resource group
+	ResourceGroup string `json:"resourceGroupName"`
+	// Location is the region the machine is running in
+	Location string `json:"location"`
+	// Size is the virtual machine size
+	Size string `json:"vmSize"`
+	// Zone is the availability zone of the machine, if any
+	Zone string `json:"zone"`
+	// FaultDomain is the fault domain of the machine in its availability set
+	FaultDomain string `json:"platformFaultDomain"`
+	// UpdateDomain is the update domain of the machine in its availability set
+	UpdateDomain string `json:"platformUpdateDomain"`
+}
+
+// IsRunningOnAzure indicates if the current running process appears to be
+// running on an Azure virtual machine by checking the availability of
+// the instance metadata service
+func IsRunningOnAzure() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), metadataProbeTimeout)
+	defer cancel()
+	_, err := newInstance(ctx, http.DefaultClient, metadataURL)
+	return err == nil
+}
+
+// NewLocalInstance returns the description of the Azure virtual machine
+// the process is running on using the instance metadata service
+func NewLocalInstance() (*Instance, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), metadataTimeout)
+	defer cancel()
+	return newInstance(ctx, http.DefaultClient, metadataURL)
+}
+
+func newInstance(ctx context.Context, client *http.Client, url string) (*Instance, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	// The metadata service rejects requests without this header
+	req.Header.Set("Metadata", "true")
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, trace.BadParameter("instance metadata service returned %v", resp.Status)
+	}
+	var instance Instance
+	if err := json.NewDecoder(resp.Body).Decode(&instance); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if instance.ID == "" || instance.SubscriptionID == "" {
+		return nil, trace.BadParameter("incomplete instance metadata: %#v", instance)
+	}
+	return &instance, nil
+}
+
+const (
+	// metadataURL is the compute metadata endpoint of the instance metadata service
+	metadataURL = "http://169.254.169.254/metadata/instance/compute?api-version=2018-10-01"
+	// metadataProbeTimeout limits the time to wait for the metadata service
+	// when detecting the provider
+	metadataProbeTimeout = 2 * time.Second
+	// metadataTimeout limits the time to wait for the metadata service
+	metadataTimeout = 10 * time.Second
+)
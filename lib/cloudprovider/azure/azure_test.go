@@ -0,0 +1,112 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+func TestAzure(t *testing.T) { TestingT(t) }
+
+type AzureSuite struct{}
+
+var _ = Suite(&AzureSuite{})
+
+func (*AzureSuite) TestReadsInstanceMetadata(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata") != "true" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{
+  "location": "westus2",
+  "name": "master-1",
+  "platformFaultDomain": "1",
+  "platformUpdateDomain": "2",
+  "resourceGroupName": "cluster-rg",
+  "subscriptionId": "sub-1",
+  "vmId": "vm-1",
+  "vmSize": "Standard_D4s_v3",
+  "zone": ""
+}`))
+	}))
+	defer server.Close()
+
+	instance, err := newInstance(context.TODO(), http.DefaultClient, server.URL)
+	c.Assert(err, IsNil)
+	c.Assert(instance, DeepEquals, &Instance{
+		ID:             "vm-1",
+		Name:           "master-1",
+		SubscriptionID: "sub-1",
+		ResourceGroup:  "cluster-rg",
+		Location:       "westus2",
+		Size:           "Standard_D4s_v3",
+		FaultDomain:    "1",
+		UpdateDomain:   "2",
+	})
+}
+
+func (*AzureSuite) TestGeneratesCloudConfig(c *C) {
+	instance := Instance{
+		SubscriptionID: "sub-1",
+		ResourceGroup:  "cluster-rg",
+		Location:       "westus2",
+	}
+	_, err := NewCloudConfig(instance, NetworkConfig{VnetName: "vnet"})
+	c.Assert(err, NotNil, Commentf("subnet and security group are required"))
+
+	config, err := NewCloudConfig(instance, NetworkConfig{
+		VnetName:          "vnet",
+		SubnetName:        "subnet",
+		SecurityGroupName: "nsg",
+		AvailabilitySet:   "masters",
+	})
+	c.Assert(err, IsNil)
+	var rendered map[string]interface{}
+	c.Assert(json.Unmarshal([]byte(config.String()), &rendered), IsNil)
+	c.Assert(rendered, DeepEquals, map[string]interface{}{
+		"cloud":                       "AzurePublicCloud",
+		"subscriptionId":              "sub-1",
+		"resourceGroup":               "cluster-rg",
+		"location":                    "westus2",
+		"vmType":                      "standard",
+		"vnetName":                    "vnet",
+		"vnetResourceGroup":           "cluster-rg",
+		"subnetName":                  "subnet",
+		"securityGroupName":           "nsg",
+		"primaryAvailabilitySetName":  "masters",
+		"useManagedIdentityExtension": true,
+		"useInstanceMetadata":         true,
+		"loadBalancerSku":             "basic",
+	})
+}
+
+func (*AzureSuite) TestAPIServerLoadBalancer(c *C) {
+	service, endpoints := APIServerLoadBalancer([]string{"10.0.0.1", "10.0.0.2"})
+	c.Assert(service.Annotations[internalLoadBalancerAnnotation], Equals, "true")
+	c.Assert(service.Spec.Selector, IsNil)
+	c.Assert(endpoints.Name, Equals, service.Name)
+	c.Assert(endpoints.Subsets, HasLen, 1)
+	c.Assert(endpoints.Subsets[0].Addresses, HasLen, 2)
+	c.Assert(endpoints.Subsets[0].Addresses[1].IP, Equals, "10.0.0.2")
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"encoding/json"
+
+	"github.com/gravitational/trace"
+)
+
+// NetworkConfig describes the cluster network resources that cannot be
+// discovered from the instance metadata
+type NetworkConfig struct {
+	// VnetName is the name of the cluster virtual network
+	VnetName string
+	// VnetResourceGroup is the resource group of the virtual network.
+	// Defaults to the resource group of the instance
+	VnetResourceGroup string
+	// SubnetName is the name of the cluster subnet
+	SubnetName string
+	// SecurityGroupName is the name of the network security group
+	// attached to the cluster subnet
+	SecurityGroupName string
+	// RouteTableName is the name of the route table for pod routes
+	RouteTableName string
+	// AvailabilitySet is the name of the availability set with master nodes.
+	// The cloud controller adds the nodes of this set to load balancers
+	AvailabilitySet string
+}
+
+// Check validates the network configuration
+func (r NetworkConfig) Check() error {
+	var errors []error
+	if r.VnetName == "" {
+		errors = append(errors, trace.BadParameter("missing virtual network name"))
+	}
+	if r.SubnetName == "" {
+		errors = append(errors, trace.BadParameter("missing subnet name"))
+	}
+	if r.SecurityGroupName == "" {
+		errors = append(errors, trace.BadParameter("missing network security group name"))
+	}
+	return trace.NewAggregate(errors...)
+}
+
+// CloudConfig describes the configuration of the Kubernetes Azure cloud provider.
+// Credentials are obtained with the managed identity of the virtual machines
+type CloudConfig struct {
+	// Cloud is the name of the Azure cloud environment
+	Cloud string `json:"cloud"`
+	// SubscriptionID is the ID of the subscription of the cluster resources
+	SubscriptionID string `json:"subscriptionId"`
+	// ResourceGroup is the resource group of the cluster virtual machines
+	ResourceGroup string `json:"resourceGroup"`
+	// Location is the region of the cluster resources
+	Location string `json:"location"`
+	// VMType is the type of virtual machines, "standard" or "vmss"
+	VMType string `json:"vmType"`
+	// VnetName is the name of the cluster virtual network
+	VnetName string `json:"vnetName"`
+	// VnetResourceGroup is the resource group of the virtual network
+	VnetResourceGroup string `json:"vnetResourceGroup"`
+	// SubnetName is the name of the cluster subnet
+	SubnetName string `json:"subnetName"`
+	// SecurityGroupName is the name of the network security group
+	SecurityGroupName string `json:"securityGroupName"`
+	// RouteTableName is the name of the route table
+	RouteTableName string `json:"routeTableName,omitempty"`
+	// PrimaryAvailabilitySetName is the name of the availability set
+	// used for load balancer backends
+	PrimaryAvailabilitySetName string `json:"primaryAvailabilitySetName,omitempty"`
+	// UseManagedIdentityExtension enables authentication with the
+	// managed identity of the virtual machine
+	UseManagedIdentityExtension bool `json:"useManagedIdentityExtension"`
+	// UseInstanceMetadata enables the instance metadata service for node information
+	UseInstanceMetadata bool `json:"useInstanceMetadata"`
+	// LoadBalancerSku is the load balancer SKU
+	LoadBalancerSku string `json:"loadBalancerSku"`
+}
+
+// NewCloudConfig returns the cloud provider configuration for the cluster
+// running on the specified instance
+func NewCloudConfig(instance Instance, network NetworkConfig) (*CloudConfig, error) {
+	if err := network.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	vnetResourceGroup := network.VnetResourceGroup
+	if vnetResourceGroup == "" {
+		vnetResourceGroup = instance.ResourceGroup
+	}
+	return &CloudConfig{
+		Cloud:                       publicCloud,
+		SubscriptionID:              instance.SubscriptionID,
+		ResourceGroup:               instance.ResourceGroup,
+		Location:                    instance.Location,
+		VMType:                      vmTypeStandard,
+		VnetName:                    network.VnetName,
+		VnetResourceGroup:           vnetResourceGroup,
+		SubnetName:                  network.SubnetName,
+		SecurityGroupName:           network.SecurityGroupName,
+		RouteTableName:              network.RouteTableName,
+		PrimaryAvailabilitySetName:  network.AvailabilitySet,
+		UseManagedIdentityExtension: true,
+		UseInstanceMetadata:         true,
+		LoadBalancerSku:             loadBalancerSkuBasic,
+	}, nil
+}
+
+// String renders the configuration in the JSON format expected
+// by the cloud provider
+func (r CloudConfig) String() string {
+	// The configuration only has fields of basic types so marshaling cannot fail
+	bytes, _ := json.MarshalIndent(r, "", "  ")
+	return string(bytes)
+}
+
+const (
+	// publicCloud names the Azure public cloud environment
+	publicCloud = "AzurePublicCloud"
+	// vmTypeStandard is the type of virtual machines in availability sets
+	vmTypeStandard = "standard"
+	// loadBalancerSkuBasic is the basic load balancer SKU that supports
+	// availability set backends
+	loadBalancerSkuBasic = "basic"
+)
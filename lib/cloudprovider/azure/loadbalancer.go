@@ -0,0 +1,82 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// APIServerLoadBalancer returns the service and its endpoints that expose
+// the API servers on the specified master nodes with an Azure internal
+// load balancer. The load balancer is created by the cloud controller
+func APIServerLoadBalancer(masterIPs []string) (*v1.Service, *v1.Endpoints) {
+	meta := metav1.ObjectMeta{
+		Name:      APIServerLoadBalancerName,
+		Namespace: constants.KubeSystemNamespace,
+	}
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      meta.Name,
+			Namespace: meta.Namespace,
+			Annotations: map[string]string{
+				internalLoadBalancerAnnotation: "true",
+			},
+		},
+		// The service has no selector since the API servers do not run
+		// as pods, endpoints are managed explicitly
+		Spec: v1.ServiceSpec{
+			Type: v1.ServiceTypeLoadBalancer,
+			Ports: []v1.ServicePort{{
+				Name:       "https",
+				Protocol:   v1.ProtocolTCP,
+				Port:       defaults.APIServerSecurePort,
+				TargetPort: intstr.FromInt(defaults.APIServerSecurePort),
+			}},
+		},
+	}
+	var addresses []v1.EndpointAddress
+	for _, ip := range masterIPs {
+		addresses = append(addresses, v1.EndpointAddress{IP: ip})
+	}
+	endpoints := &v1.Endpoints{
+		ObjectMeta: meta,
+		Subsets: []v1.EndpointSubset{{
+			Addresses: addresses,
+			Ports: []v1.EndpointPort{{
+				Name:     "https",
+				Protocol: v1.ProtocolTCP,
+				Port:     defaults.APIServerSecurePort,
+			}},
+		}},
+	}
+	return service, endpoints
+}
+
+const (
+	// APIServerLoadBalancerName is the name of the service with
+	// the internal load balancer for the API servers
+	APIServerLoadBalancerName = "kube-apiserver-internal"
+
+	// internalLoadBalancerAnnotation instructs the cloud controller to create
+	// an internal load balancer for the service
+	internalLoadBalancerAnnotation = "service.beta.kubernetes.io/azure-load-balancer-internal"
+)
@@ -0,0 +1,190 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	gcemeta "cloud.google.com/go/compute/metadata"
+	"github.com/gravitational/trace"
+)
+
+// Instance describes the GCE instance the process is running on
+type Instance struct {
+	// ID is the instance ID
+	ID string
+	// Name is the instance name
+	Name string
+	// ProjectID is the ID of the project the instance belongs to
+	ProjectID string
+	// Zone is the zone the instance is running in
+	Zone string
+	// MachineType is the instance machine type
+	MachineType string
+	// NetworkName is the name of the network of the primary interface
+	NetworkName string
+	// Tags lists the instance network tags
+	Tags []string
+	// Scopes lists the API scopes of the default service account
+	Scopes []string
+}
+
+// NewLocalInstance returns the description of the GCE instance
+// the process is running on using the metadata server
+func NewLocalInstance() (*Instance, error) {
+	if !gcemeta.OnGCE() {
+		return nil, trace.NotFound("not running on a GCE instance")
+	}
+	var instance Instance
+	var err error
+	if instance.ID, err = gcemeta.InstanceID(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if instance.Name, err = gcemeta.InstanceName(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if instance.ProjectID, err = gcemeta.ProjectID(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if instance.Zone, err = gcemeta.Zone(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	machineType, err := gcemeta.Get("instance/machine-type")
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	// Machine type and network are returned as resource paths, e.g.
+	// projects/123/machineTypes/n1-standard-2
+	instance.MachineType = path.Base(machineType)
+	network, err := gcemeta.Get("instance/network-interfaces/0/network")
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	instance.NetworkName = path.Base(network)
+	if instance.Tags, err = gcemeta.InstanceTags(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if instance.Scopes, err = gcemeta.Scopes("default"); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &instance, nil
+}
+
+// ValidateScopes verifies that the specified service account scopes
+// allow the Kubernetes GCE cloud provider to manage routes, load balancers
+// and persistent disks
+func ValidateScopes(scopes []string) error {
+	var missing []string
+	for _, required := range RequiredScopes {
+		if !hasScope(scopes, required) {
+			missing = append(missing, required)
+		}
+	}
+	if len(missing) != 0 {
+		return trace.BadParameter("instance service account is missing "+
+			"required API scopes: %v", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// CloudConfig describes the configuration of the Kubernetes GCE cloud provider
+type CloudConfig struct {
+	// ProjectID is the ID of the project the cluster is running in
+	ProjectID string
+	// NetworkName is the name of the cluster network
+	NetworkName string
+	// SubnetworkName is the name of the cluster subnetwork.
+	// It is required to create internal load balancers in custom-mode networks
+	SubnetworkName string
+	// NodeTags lists the network tags of cluster nodes used
+	// for the load balancer firewall rules
+	NodeTags []string
+	// Multizone specifies whether the cluster spans multiple zones
+	Multizone bool
+}
+
+// NewCloudConfig returns the cloud provider configuration for the specified
+// instance and subnetwork. If nodeTags is empty, the instance network
+// tags are used
+func NewCloudConfig(instance Instance, subnetwork string, nodeTags []string) CloudConfig {
+	if len(nodeTags) == 0 {
+		nodeTags = instance.Tags
+	}
+	return CloudConfig{
+		ProjectID:      instance.ProjectID,
+		NetworkName:    instance.NetworkName,
+		SubnetworkName: subnetwork,
+		NodeTags:       nodeTags,
+		Multizone:      true,
+	}
+}
+
+// String renders the configuration in the gcfg format expected
+// by the cloud provider
+func (r CloudConfig) String() string {
+	var buf bytes.Buffer
+	buf.WriteString("[global]\n")
+	writeValue := func(key, value string) {
+		if value != "" {
+			fmt.Fprintf(&buf, "%v = %v\n", key, value)
+		}
+	}
+	writeValue("project-id", r.ProjectID)
+	writeValue("network-name", r.NetworkName)
+	writeValue("subnetwork-name", r.SubnetworkName)
+	writeValue("node-tags", strings.Join(r.NodeTags, ","))
+	fmt.Fprintf(&buf, "multizone = %v\n", r.Multizone)
+	return buf.String()
+}
+
+// DiskDevicePath returns the path to the block device of the persistent disk
+// with the specified device name attached to the instance
+func DiskDevicePath(name string) (string, error) {
+	if err := ValidateTag(name); err != nil {
+		return "", trace.BadParameter("invalid persistent disk device name %q: %v", name, err)
+	}
+	return filepath.Join(diskByIDDir, diskPrefix+name), nil
+}
+
+func hasScope(scopes []string, required string) bool {
+	for _, scope := range scopes {
+		if scope == required || scope == CloudPlatformScope {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	// ComputeScope is the API scope with read/write access to Compute Engine
+	ComputeScope = "https://www.googleapis.com/auth/compute"
+	// CloudPlatformScope is the API scope with access to all Google Cloud services
+	CloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+	// diskByIDDir is the directory with symlinks to block devices by ID
+	diskByIDDir = "/dev/disk/by-id"
+	// diskPrefix is the prefix GCE assigns to persistent disk device IDs
+	diskPrefix = "google-"
+)
+
+// RequiredScopes lists the API scopes the instance service account needs
+// for the cloud provider integration
+var RequiredScopes = []string{ComputeScope}
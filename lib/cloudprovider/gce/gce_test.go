@@ -0,0 +1,84 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func (_ *S) TestValidatesScopes(c *C) {
+	var testCases = []struct {
+		scopes  []string
+		err     string
+		comment string
+	}{
+		{
+			scopes:  []string{ComputeScope, "https://www.googleapis.com/auth/devstorage.read_only"},
+			comment: "compute scope",
+		},
+		{
+			scopes:  []string{CloudPlatformScope},
+			comment: "cloud platform scope implies all scopes",
+		},
+		{
+			scopes:  []string{"https://www.googleapis.com/auth/devstorage.read_only"},
+			err:     ".*missing required API scopes: " + ComputeScope,
+			comment: "missing compute scope",
+		},
+	}
+	for _, tc := range testCases {
+		err := ValidateScopes(tc.scopes)
+		if tc.err == "" {
+			c.Assert(err, IsNil, Commentf(tc.comment))
+		} else {
+			c.Assert(err, ErrorMatches, tc.err, Commentf(tc.comment))
+		}
+	}
+}
+
+func (_ *S) TestRendersCloudConfig(c *C) {
+	instance := Instance{
+		ProjectID:   "example-project",
+		NetworkName: "default",
+		Tags:        []string{"example"},
+	}
+	config := NewCloudConfig(instance, "internal", nil)
+	c.Assert(config.String(), Equals, `[global]
+project-id = example-project
+network-name = default
+subnetwork-name = internal
+node-tags = example
+multizone = true
+`)
+
+	config = NewCloudConfig(instance, "", []string{"node-a", "node-b"})
+	c.Assert(config.String(), Equals, `[global]
+project-id = example-project
+network-name = default
+node-tags = node-a,node-b
+multizone = true
+`)
+}
+
+func (_ *S) TestDiskDevicePath(c *C) {
+	path, err := DiskDevicePath("gravity-state")
+	c.Assert(err, IsNil)
+	c.Assert(path, Equals, "/dev/disk/by-id/google-gravity-state")
+
+	_, err = DiskDevicePath("Gravity_State")
+	c.Assert(err, NotNil)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vsphere
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gravitational/satellite/agent/health"
+	pb "github.com/gravitational/satellite/agent/proto/agentpb"
+	"github.com/gravitational/satellite/monitoring"
+	"github.com/gravitational/trace"
+)
+
+// NewChecker returns a checker that verifies that the node is a vSphere
+// virtual machine configured for the Kubernetes vSphere cloud provider
+func NewChecker() health.Checker {
+	return &checker{
+		dmiDir:    dmiDir,
+		diskIDDir: diskIDDir,
+	}
+}
+
+type checker struct {
+	// dmiDir is the directory with the DMI identification attributes
+	dmiDir string
+	// diskIDDir is the directory with symlinks to block devices by ID
+	diskIDDir string
+}
+
+// Name returns the name of the checker
+func (*checker) Name() string {
+	return checkerName
+}
+
+// Check verifies that the node is a VMware virtual machine with the disk
+// UUIDs exposed to the guest
+func (r *checker) Check(ctx context.Context, reporter health.Reporter) {
+	vendor, err := ioutil.ReadFile(filepath.Join(r.dmiDir, "sys_vendor"))
+	if err != nil {
+		reporter.Add(monitoring.NewProbeFromErr(checkerName,
+			"failed to determine the system vendor", trace.ConvertSystemError(err)))
+		return
+	}
+	if !strings.HasPrefix(strings.TrimSpace(string(vendor)), vmwareVendor) {
+		reporter.Add(&pb.Probe{
+			Checker: checkerName,
+			Detail: "vSphere cloud provider was specified but the node " +
+				"does not appear to be a VMware virtual machine",
+			Status: pb.Probe_Failed,
+		})
+		return
+	}
+	enabled, err := r.hasDiskUUIDs()
+	if err != nil {
+		reporter.Add(monitoring.NewProbeFromErr(checkerName,
+			"failed to list disk IDs", trace.Wrap(err)))
+		return
+	}
+	if !enabled {
+		reporter.Add(&pb.Probe{
+			Checker: checkerName,
+			Detail: "disk UUIDs are not exposed to the virtual machine, " +
+				"set disk.EnableUUID=TRUE in the virtual machine advanced configuration",
+			Status: pb.Probe_Failed,
+		})
+		return
+	}
+	reporter.Add(&pb.Probe{
+		Checker: checkerName,
+		Status:  pb.Probe_Running,
+	})
+}
+
+// hasDiskUUIDs returns true if the VMware virtual disks are listed
+// by their UUIDs which requires disk.EnableUUID
+func (r *checker) hasDiskUUIDs() (bool, error) {
+	files, err := ioutil.ReadDir(r.diskIDDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, trace.ConvertSystemError(err)
+	}
+	for _, file := range files {
+		for _, prefix := range vmwareDiskIDPrefixes {
+			if strings.HasPrefix(file.Name(), prefix) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+const (
+	// checkerName is the name of the vSphere checker
+	checkerName = "vsphere"
+	// vmwareVendor is the system vendor of VMware virtual machines
+	vmwareVendor = "VMware"
+	// dmiDir is the directory with the DMI identification attributes
+	dmiDir = "/sys/class/dmi/id"
+	// diskIDDir is the directory with symlinks to block devices by ID
+	diskIDDir = "/dev/disk/by-id"
+)
+
+// vmwareDiskIDPrefixes lists the prefixes of the IDs of VMware virtual
+// disks that are derived from the disk UUIDs
+var vmwareDiskIDPrefixes = []string{"scsi-36000c29", "wwn-0x6000c29"}
@@ -0,0 +1,206 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vsphere
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/gravitational/trace"
+)
+
+// Config describes the vSphere environment of the cluster.
+//
+// Example:
+//
+//	server: vcenter.example.com
+//	user: k8s@vsphere.local
+//	password: secret
+//	datacenter: dc1
+//	datastore: ds1
+//	folder: kubernetes
+//	masters:
+//	  antiAffinityRule: k8s-masters
+//	  vms: [master-1, master-2, master-3]
+type Config struct {
+	// Server is the address of the vCenter server
+	Server string `json:"server"`
+	// Port is the vCenter server port
+	Port int `json:"port,omitempty"`
+	// Insecure disables the verification of the vCenter server certificate
+	Insecure bool `json:"insecure,omitempty"`
+	// User is the vCenter user name
+	User string `json:"user"`
+	// Password is the vCenter user password
+	Password string `json:"password"`
+	// Datacenter is the name of the datacenter with cluster virtual machines
+	Datacenter string `json:"datacenter"`
+	// Datastore is the default datastore for persistent volumes
+	Datastore string `json:"datastore"`
+	// Folder is the virtual machine folder for the volumes of dynamically
+	// provisioned persistent volumes.
+	// Either relative to the datacenter virtual machine folder or an absolute
+	// inventory path in the datacenter, e.g. /dc1/vm/kubernetes
+	Folder string `json:"folder"`
+	// ResourcePool is the optional resource pool path
+	ResourcePool string `json:"resourcePool,omitempty"`
+	// Masters describes the placement of master virtual machines
+	Masters *MasterPlacement `json:"masters,omitempty"`
+}
+
+// MasterPlacement describes the anti-affinity placement of master virtual machines
+type MasterPlacement struct {
+	// AntiAffinityRule is the name of the DRS rule that keeps masters on separate hosts
+	AntiAffinityRule string `json:"antiAffinityRule"`
+	// VMs lists the names of master virtual machines
+	VMs []string `json:"vms"`
+}
+
+// ReadConfig reads the vSphere configuration from the specified file
+func ReadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	return ParseConfig(data)
+}
+
+// ParseConfig parses the vSphere configuration from the specified YAML or JSON data
+func ParseConfig(data []byte) (*Config, error) {
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, trace.BadParameter("failed to parse vSphere configuration: %v", err)
+	}
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &config, nil
+}
+
+// CheckAndSetDefaults validates the configuration and sets defaults
+func (r *Config) CheckAndSetDefaults() error {
+	var errors []error
+	if r.Server == "" {
+		errors = append(errors, trace.BadParameter("missing vCenter server"))
+	}
+	if r.User == "" || r.Password == "" {
+		errors = append(errors, trace.BadParameter("missing vCenter credentials"))
+	}
+	if r.Datacenter == "" {
+		errors = append(errors, trace.BadParameter("missing datacenter"))
+	}
+	if err := r.checkDatastore(); err != nil {
+		errors = append(errors, err)
+	}
+	if err := r.checkFolder(); err != nil {
+		errors = append(errors, err)
+	}
+	if r.Masters != nil {
+		if err := r.Masters.Check(); err != nil {
+			errors = append(errors, err)
+		}
+	}
+	if len(errors) != 0 {
+		return trace.BadParameter("invalid vSphere configuration: %v",
+			trace.NewAggregate(errors...))
+	}
+	if r.Port == 0 {
+		r.Port = defaultPort
+	}
+	return nil
+}
+
+func (r Config) checkDatastore() error {
+	if r.Datastore == "" {
+		return trace.BadParameter("missing datastore")
+	}
+	// The cloud provider expects a datastore name, datastore
+	// clusters and inventory paths are not supported
+	if strings.Contains(r.Datastore, "/") {
+		return trace.BadParameter("datastore should be specified by name, got %q", r.Datastore)
+	}
+	return nil
+}
+
+func (r Config) checkFolder() error {
+	if r.Folder == "" {
+		return trace.BadParameter("missing virtual machine folder")
+	}
+	if !path.IsAbs(r.Folder) {
+		return nil
+	}
+	// Absolute paths should point into the virtual machine folder of the datacenter
+	vmFolder := path.Join("/", r.Datacenter, "vm")
+	if !strings.HasPrefix(path.Clean(r.Folder)+"/", vmFolder+"/") {
+		return trace.BadParameter("folder %q is not in the virtual machine folder %q "+
+			"of the datacenter", r.Folder, vmFolder)
+	}
+	return nil
+}
+
+// Check validates the master placement
+func (r MasterPlacement) Check() error {
+	if r.AntiAffinityRule == "" {
+		return trace.BadParameter("missing master anti-affinity rule name")
+	}
+	if len(r.VMs) < 2 {
+		return trace.BadParameter("master anti-affinity rule requires at least two virtual machines")
+	}
+	seen := make(map[string]bool, len(r.VMs))
+	for _, vm := range r.VMs {
+		if seen[vm] {
+			return trace.BadParameter("virtual machine %q is listed multiple times "+
+				"in the master anti-affinity rule", vm)
+		}
+		seen[vm] = true
+	}
+	return nil
+}
+
+// Command returns the govc command that creates the anti-affinity rule
+// for the masters in the specified datacenter
+func (r MasterPlacement) Command(datacenter string) string {
+	return fmt.Sprintf("govc cluster.rule.create -dc %v -name %v -enable -anti-affinity %v",
+		datacenter, r.AntiAffinityRule, strings.Join(r.VMs, " "))
+}
+
+// CloudConfig renders the configuration of the Kubernetes vSphere
+// cloud provider in the gcfg format
+func (r Config) CloudConfig() string {
+	var buf bytes.Buffer
+	insecure := 0
+	if r.Insecure {
+		insecure = 1
+	}
+	fmt.Fprintf(&buf, "[Global]\nuser = %q\npassword = %q\nport = %q\ninsecure-flag = %q\n\n",
+		r.User, r.Password, fmt.Sprint(r.Port), fmt.Sprint(insecure))
+	fmt.Fprintf(&buf, "[VirtualCenter %q]\ndatacenters = %q\n\n", r.Server, r.Datacenter)
+	fmt.Fprintf(&buf, "[Workspace]\nserver = %q\ndatacenter = %q\ndefault-datastore = %q\nfolder = %q\n",
+		r.Server, r.Datacenter, r.Datastore, r.Folder)
+	if r.ResourcePool != "" {
+		fmt.Fprintf(&buf, "resourcepool-path = %q\n", r.ResourcePool)
+	}
+	fmt.Fprintf(&buf, "\n[Disk]\nscsicontrollertype = pvscsi\n")
+	return buf.String()
+}
+
+// defaultPort is the default vCenter server port
+const defaultPort = 443
@@ -0,0 +1,136 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vsphere
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gravitational/satellite/agent/health"
+	pb "github.com/gravitational/satellite/agent/proto/agentpb"
+	. "gopkg.in/check.v1"
+)
+
+func TestVSphere(t *testing.T) { TestingT(t) }
+
+type VSphereSuite struct{}
+
+var _ = Suite(&VSphereSuite{})
+
+func (*VSphereSuite) TestParsesConfig(c *C) {
+	config, err := ParseConfig([]byte(`
+server: vcenter.example.com
+user: k8s@vsphere.local
+password: secret
+datacenter: dc1
+datastore: ds1
+folder: /dc1/vm/kubernetes
+masters:
+  antiAffinityRule: k8s-masters
+  vms: [master-1, master-2, master-3]
+`))
+	c.Assert(err, IsNil)
+	c.Assert(config.Port, Equals, defaultPort)
+	c.Assert(config.CloudConfig(), Equals, `[Global]
+user = "k8s@vsphere.local"
+password = "secret"
+port = "443"
+insecure-flag = "0"
+
+[VirtualCenter "vcenter.example.com"]
+datacenters = "dc1"
+
+[Workspace]
+server = "vcenter.example.com"
+datacenter = "dc1"
+default-datastore = "ds1"
+folder = "/dc1/vm/kubernetes"
+
+[Disk]
+scsicontrollertype = pvscsi
+`)
+	c.Assert(config.Masters.Command(config.Datacenter), Equals,
+		"govc cluster.rule.create -dc dc1 -name k8s-masters -enable -anti-affinity master-1 master-2 master-3")
+}
+
+func (*VSphereSuite) TestValidatesConfig(c *C) {
+	testCases := []struct {
+		config  string
+		comment string
+	}{
+		{
+			config:  `{"user": "u", "password": "p", "datacenter": "dc1", "datastore": "ds1", "folder": "k8s"}`,
+			comment: "missing server",
+		},
+		{
+			config:  `{"server": "vc", "user": "u", "password": "p", "datacenter": "dc1", "datastore": "cluster/ds1", "folder": "k8s"}`,
+			comment: "datastore path",
+		},
+		{
+			config:  `{"server": "vc", "user": "u", "password": "p", "datacenter": "dc1", "datastore": "ds1", "folder": "/dc2/vm/k8s"}`,
+			comment: "folder outside of datacenter",
+		},
+		{
+			config:  `{"server": "vc", "user": "u", "password": "p", "datacenter": "dc1", "datastore": "ds1", "folder": "/dc1/host/k8s"}`,
+			comment: "folder outside of virtual machine folder",
+		},
+		{
+			config:  `{"server": "vc", "user": "u", "password": "p", "datacenter": "dc1", "datastore": "ds1", "folder": "k8s", "masters": {"antiAffinityRule": "masters", "vms": ["m1", "m1"]}}`,
+			comment: "duplicate master",
+		},
+		{
+			config:  `{"server": "vc", "user": "u", "password": "p", "datacenter": "dc1", "datastore": "ds1", "folder": "k8s", "masters": {"vms": ["m1", "m2"]}}`,
+			comment: "missing rule name",
+		},
+	}
+	for _, tc := range testCases {
+		_, err := ParseConfig([]byte(tc.config))
+		c.Assert(err, NotNil, Commentf(tc.comment))
+	}
+}
+
+func (*VSphereSuite) TestChecksDiskUUIDs(c *C) {
+	dir := c.MkDir()
+	checker := &checker{
+		dmiDir:    filepath.Join(dir, "dmi"),
+		diskIDDir: filepath.Join(dir, "by-id"),
+	}
+	c.Assert(os.MkdirAll(checker.dmiDir, 0755), IsNil)
+	c.Assert(os.MkdirAll(checker.diskIDDir, 0755), IsNil)
+	writeFile := func(path string, data string) {
+		c.Assert(ioutil.WriteFile(path, []byte(data), 0644), IsNil)
+	}
+
+	writeFile(filepath.Join(checker.dmiDir, "sys_vendor"), "QEMU\n")
+	c.Assert(runCheck(checker), Equals, pb.Probe_Failed, Commentf("not a VMware virtual machine"))
+
+	writeFile(filepath.Join(checker.dmiDir, "sys_vendor"), "VMware, Inc.\n")
+	writeFile(filepath.Join(checker.diskIDDir, "ata-VMware_Virtual_SATA_CDRW_Drive_00000000000000000001"), "")
+	c.Assert(runCheck(checker), Equals, pb.Probe_Failed, Commentf("disk UUIDs are not enabled"))
+
+	writeFile(filepath.Join(checker.diskIDDir, "scsi-36000c2955a1e2b7c4d1f0e8a9b3c6d7e"), "")
+	c.Assert(runCheck(checker), Equals, pb.Probe_Running)
+}
+
+func runCheck(checker health.Checker) pb.Probe_Type {
+	var reporter health.Probes
+	checker.Check(context.TODO(), &reporter)
+	return reporter[0].Status
+}
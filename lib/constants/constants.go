@@ -105,6 +105,9 @@ const (
 	// ETCDBackend defines storage backend as Etcd
 	ETCDBackend = "etcd"
 
+	// PostgresBackend defines storage backend as PostgreSQL
+	PostgresBackend = "postgres"
+
 	// WebAssetsPackage names the web assets package
 	WebAssetsPackage = "web-assets"
 
@@ -126,6 +129,10 @@ const (
 	// LicensePackage is the package with license used during initial site installation
 	LicensePackage = "license"
 
+	// EncryptionConfigPackage is the package with the apiserver encryption
+	// provider configuration of a hardened cluster
+	EncryptionConfigPackage = "encryption-config"
+
 	// GravitySitePackage specifies the name of the garvity site application package
 	GravitySitePackage = "site"
 
@@ -210,6 +217,22 @@ const (
 	// If not empty, turns the preflight checks off
 	PreflightChecksOffEnvVar = "GRAVITY_CHECKS_OFF"
 
+	// HTTPProxyEnvVar names the environment variable that specifies the proxy
+	// for outbound HTTP connections
+	HTTPProxyEnvVar = "HTTP_PROXY"
+
+	// HTTPSProxyEnvVar names the environment variable that specifies the proxy
+	// for outbound HTTPS connections
+	HTTPSProxyEnvVar = "HTTPS_PROXY"
+
+	// NoProxyEnvVar names the environment variable that specifies the list
+	// of destinations to exclude from proxying
+	NoProxyEnvVar = "NO_PROXY"
+
+	// NodeNameEnvVar names the environment variable with the name of the
+	// Kubernetes node the pod is running on
+	NodeNameEnvVar = "NODE_NAME"
+
 	// DockerRegistry is a default name for private docker registry
 	DockerRegistry = "leader.telekube.local:5000"
 
@@ -257,6 +280,12 @@ const (
 
 	// RootKeyPair is a name of the K8s root certificate authority keypair
 	RootKeyPair = "root"
+	// PendingRootKeyPair is a name of the new K8s root certificate authority keypair
+	// while the certificate authority is being rotated
+	PendingRootKeyPair = "root-pending"
+	// PreviousRootKeyPair is a name of the K8s root certificate authority keypair
+	// replaced by the last certificate authority rotation
+	PreviousRootKeyPair = "root-previous"
 	// APIServerKeyPair is a name of the K8s apiserver key pair
 	APIServerKeyPair = "apiserver"
 	// APIServerKubeletClientKeyPair is the name of the cert for the API server to connect to kubelet
@@ -339,6 +368,19 @@ const (
 	// EnvHome is home environment variable
 	EnvHome = "HOME"
 
+	// EnvSourceDateEpoch is the environment variable with the timestamp
+	// reproducible builds use instead of the current time, in seconds since the Unix epoch
+	EnvSourceDateEpoch = "SOURCE_DATE_EPOCH"
+
+	// ArchAMD64 identifies the x86-64 CPU architecture
+	ArchAMD64 = "amd64"
+	// ArchARM64 identifies the 64-bit ARM CPU architecture
+	ArchARM64 = "arm64"
+
+	// EnvDockerConfig is environment variable that overrides the location
+	// of the Docker client configuration directory
+	EnvDockerConfig = "DOCKER_CONFIG"
+
 	// EnvSudoUser is environment variable containing name of the user who invoked "sudo"
 	EnvSudoUser = "SUDO_USER"
 
@@ -506,6 +548,10 @@ const (
 	// UserContext is a context field that contains authenticated user name
 	UserContext = "user.context"
 
+	// IdempotencyKeyContext is a context field that contains the idempotency
+	// key of the operation creating request
+	IdempotencyKeyContext = "idempotency_key.context"
+
 	// PrivilegedKubeconfig is a path to privileged kube config
 	// that is stored on K8s master node
 	PrivilegedKubeconfig = "/etc/kubernetes/scheduler.kubeconfig"
@@ -522,6 +568,10 @@ const (
 	// DockerStorageDriverOverlay2 identifes the overlay2 docker storage driver
 	DockerStorageDriverOverlay2 = "overlay2"
 
+	// NetworkEncryptionWireguard encrypts the pod traffic between
+	// the nodes with WireGuard tunnels
+	NetworkEncryptionWireguard = "wireguard"
+
 	// ClusterControllerChangeset names the changeset with cluster controller resources
 	// of the currently installed version
 	ClusterControllerChangeset = "old-cluster-controller"
@@ -550,6 +600,11 @@ const (
 	// ClusterConfigurationMap is the name of the ConfigMap that hosts cluster configuration resource
 	ClusterConfigurationMap = "cluster-configuration"
 
+	// CoreDNSConfigMap is the name of the ConfigMap that hosts the cluster CoreDNS configuration
+	CoreDNSConfigMap = "coredns"
+	// CoreDNSConfigMapKey is the Corefile field name in the above ConfigMap
+	CoreDNSConfigMapKey = "Corefile"
+
 	// ClusterInfoMap is the name of the ConfigMap that contains cluster information.
 	ClusterInfoMap = "cluster-info"
 	// ClusterNameEnv is the environment variable that contains cluster domain name.
@@ -702,6 +757,16 @@ const (
 	//
 	// Used in audit events.
 	ServiceStatusChecker = "@statuschecker"
+	// ServiceRosterReconciler is the name of the service that reconciles
+	// the cluster membership against the cluster roster.
+	//
+	// Used in audit events.
+	ServiceRosterReconciler = "@rosterreconciler"
+	// ServiceOperationController is the name of the service that starts
+	// cluster operations requested with Kubernetes custom resources.
+	//
+	// Used in audit events.
+	ServiceOperationController = "@operationcontroller"
 	// ServiceSystem is the identifier used as a "user" field for events
 	// that are triggered not by a human user but by a system process.
 	//
@@ -720,6 +785,8 @@ var (
 	EncodingShort Format = "short"
 	// EncodingYAML is for the YAML encoding format
 	EncodingYAML Format = "yaml"
+	// EncodingNagios is for the Nagios plugin output format
+	EncodingNagios Format = "nagios"
 	// OutputFormats is a list of recognized output formats for gravity CLI commands
 	OutputFormats = []Format{
 		EncodingText,
@@ -743,6 +810,11 @@ var (
 		DockerStorageDriverOverlay2,
 	}
 
+	// NetworkEncryptionModes is a list of recognized overlay network encryption modes
+	NetworkEncryptionModes = []string{
+		NetworkEncryptionWireguard,
+	}
+
 	// DockerSupportedTargetDrivers is a list of docker storage drivers
 	// that the existing storage driver can be switched to
 	DockerSupportedTargetDrivers = []string{
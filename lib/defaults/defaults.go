@@ -127,6 +127,20 @@ const (
 	// has been completed/or failed
 	InstallTokenTTL = time.Hour
 
+	// JoinTokenTTL is the default TTL for short-lived cluster join tokens
+	JoinTokenTTL = time.Hour
+
+	// MaxJoinTokenTTL is the maximum TTL for short-lived cluster join tokens
+	MaxJoinTokenTTL = 7 * 24 * time.Hour
+
+	// InternalJoinTokenTTL is the TTL for the join tokens the cluster issues
+	// to join the nodes it adds itself, e.g. from the cluster roster
+	InternalJoinTokenTTL = 24 * time.Hour
+
+	// AutoscaleJoinTokenRenewal is how long before the expiration the join token
+	// published for the auto scaling groups is replaced with a new one
+	AutoscaleJoinTokenRenewal = 24 * time.Hour
+
 	// MaxOperationConcurrency defines a number of servers an operation can run on concurrently
 	MaxOperationConcurrency = 5
 
@@ -151,6 +165,21 @@ const (
 	// CertTTL is Teleport's SSH cert default TTL
 	CertTTL = 10 * time.Hour
 
+	// DeviceLoginTTL is how long the user has to complete the single
+	// sign-on login of a command line client
+	DeviceLoginTTL = 10 * time.Minute
+
+	// DeviceLoginPollInterval is how often the command line client checks
+	// whether the user has completed the single sign-on login
+	DeviceLoginPollInterval = 5 * time.Second
+
+	// DeviceCodeBytes is the number of random bytes in the device code
+	// of the single sign-on login of a command line client
+	DeviceCodeBytes = 32
+
+	// PortalAPIVersion is the path prefix of the web API
+	PortalAPIVersion = "portalapi/v1"
+
 	// CertRenewPeriod is how often the certificate is renewed
 	CertRenewPeriod = time.Minute
 
@@ -189,6 +218,9 @@ const (
 	// GravityRPCAgentPort defines which port RPC agent is listening on
 	GravityRPCAgentPort = 3012
 
+	// GravityRPCAgentMetricsAddr is the address the RPC agent serves its metrics on
+	GravityRPCAgentMetricsAddr = ":3013"
+
 	// GravityRPCAgentServiceName defines systemd unit service name for RPC agents
 	GravityRPCAgentServiceName = "gravity-agent.service"
 
@@ -267,6 +299,11 @@ const (
 	// Source: https://www.freedesktop.org/software/systemd/man/systemd.mount.html
 	GravityMountService = "var-lib-gravity.mount"
 
+	// GravityEtcdMountService defines the name of the service that mounts
+	// a dedicated etcd device into the etcd data directory.
+	// Important: keep this mount service in sync with the value of GravityDir
+	GravityEtcdMountService = "var-lib-gravity-planet-etcd.mount"
+
 	// SecretsDir is the place for gravity TLS secrets to be
 	SecretsDir = "secrets"
 
@@ -406,12 +443,27 @@ const (
 	// ResourcesDir is the name of the directory where apps store their resources such as app manifest
 	ResourcesDir = "resources"
 
+	// ScanReportFilename is the name of the vulnerability scan report
+	// embedded into the application resources
+	ScanReportFilename = "scan-report.json"
+
+	// SBOMFilename is the name of the software bill of materials
+	// in CycloneDX format embedded into the application resources
+	SBOMFilename = "sbom.json"
+
+	// AttestationFilename is the name of the signed build provenance
+	// embedded into the application resources
+	AttestationFilename = "attestation.json"
+
 	// PlanetDir is the name of the planet directory
 	PlanetDir = "planet"
 
 	// EtcdDir is the name of the etcd directory
 	EtcdDir = "etcd"
 
+	// DockerDir is the name of the docker directory
+	DockerDir = "docker"
+
 	// ShareDir is the name of the share directory
 	ShareDir = "share"
 
@@ -457,6 +509,10 @@ const (
 	// GravityDBFile is a default file name for gravity sqlite DB file
 	GravityDBFile = "gravity.db"
 
+	// EncryptionConfigFile is the name of the file in the state directory
+	// with the configuration of the local state encryption
+	EncryptionConfigFile = "encryption.json"
+
 	// SystemAccountID is the ID of the system account
 	SystemAccountID = "00000000-0000-0000-0000-000000000001"
 	// SystemAccountOrg is the default name of Gravitational organization
@@ -507,6 +563,18 @@ const (
 	// DBOpenTimeout is a default timeout for opening the DB
 	DBOpenTimeout = 30 * time.Second
 
+	// PostgresTable is the default name of the table with the cluster state
+	// in the PostgreSQL backend
+	PostgresTable = "gravity_state"
+
+	// PostgresMaxOpenConnections is the default maximum number of open
+	// connections to the PostgreSQL database
+	PostgresMaxOpenConnections = 20
+
+	// PostgresExpiryInterval is the interval between removals of expired
+	// keys from the PostgreSQL database
+	PostgresExpiryInterval = time.Minute
+
 	// AgentRequestTimeout defines the maximum amount of time an agent is blocked on a request
 	AgentRequestTimeout = 10 * time.Second
 
@@ -526,6 +594,17 @@ const (
 	APIServerPort = 8080
 	// APIServerSecurePort is api server secure port
 	APIServerSecurePort = 6443
+	// KubeletReadOnlyPort is the kubelet read-only port disabled in hardened clusters
+	KubeletReadOnlyPort = 10255
+
+	// AuditLogPath is the path to the apiserver audit log inside the runtime container
+	AuditLogPath = "/var/log/kubernetes/audit.log"
+	// AuditLogMaxAge is the default number of days to retain the rotated audit logs
+	AuditLogMaxAge = 30
+	// AuditLogMaxBackup is the default number of rotated audit logs to retain
+	AuditLogMaxBackup = 10
+	// AuditLogMaxSize is the default size of the audit log in megabytes before it is rotated
+	AuditLogMaxSize = 100
 
 	// KubeForwarderUser is the identity used to generate a certificate
 	// for access to kubernetes API server on secure port.
@@ -599,6 +678,189 @@ const (
 	RegistrySyncInterval = 20 * time.Second
 	// AppSyncInterval is how often app images are synced with the local registry
 	AppSyncInterval = 30 * time.Second
+	// RosterReconcileInterval is how often the cluster membership is reconciled
+	// against the cluster roster
+	RosterReconcileInterval = 1 * time.Minute
+
+	// OperationControllerInterval is how often the gravity custom resources
+	// are reconciled with the cluster operations
+	OperationControllerInterval = 30 * time.Second
+	// OperationControllerStartTimeout is how long the operation controller
+	// waits for the operation requested with a custom resource to be created
+	OperationControllerStartTimeout = 5 * time.Minute
+
+	// OperationWebhookTimeout is the default timeout of the request to
+	// an operation admission webhook
+	OperationWebhookTimeout = 10 * time.Second
+	// OperationWebhookMaxTimeout is the maximum allowed timeout of the request
+	// to an operation admission webhook
+	OperationWebhookMaxTimeout = 30 * time.Second
+
+	// HealthHistoryInterval is how often the cluster health snapshot is
+	// recorded in the health history if the cluster health has not changed
+	HealthHistoryInterval = 5 * time.Minute
+	// HealthHistoryCapacity is the maximum number of the cluster health
+	// snapshots kept in the health history, about a week of snapshots
+	// recorded every HealthHistoryInterval
+	HealthHistoryCapacity = 2016
+
+	// HealthHistorySince is the default duration of the displayed
+	// cluster health history
+	HealthHistorySince = 24 * time.Hour
+
+	// HealthCheckTimeout is the default timeout of a custom cluster health probe
+	HealthCheckTimeout = 10 * time.Second
+	// HealthCheckMaxTimeout is the maximum allowed timeout of a custom
+	// cluster health probe
+	HealthCheckMaxTimeout = 1 * time.Minute
+
+	// AgentXAddr is the default address of the AgentX master agent,
+	// the default socket of net-snmp snmpd
+	AgentXAddr = "/var/agentx/master"
+	// AgentXRetryInterval is the interval between attempts to reconnect
+	// to the AgentX master agent
+	AgentXRetryInterval = 10 * time.Second
+	// AgentXRequestTimeout is the timeout of a request to the AgentX master agent
+	AgentXRequestTimeout = 5 * time.Second
+	// AgentXPriority is the priority of the subtree registered with the AgentX
+	// master agent, the default priority of RFC 2741
+	AgentXPriority = 127
+	// SNMPRootOID is the default object identifier of the cluster health subtree.
+	// It is netSnmpPlaypen from the NET-SNMP-MIB reserved for local use
+	SNMPRootOID = "1.3.6.1.4.1.8072.9999.9999"
+	// SNMPStatusInterval is how often the SNMP sub-agent refreshes the cluster status
+	SNMPStatusInterval = 30 * time.Second
+
+	// NetworkMonitorAddr is the address the network monitor exchanges
+	// the UDP probes with the other nodes on
+	NetworkMonitorAddr = ":3014"
+	// NetworkMonitorMetricsAddr is the address the network monitor serves its metrics on
+	NetworkMonitorMetricsAddr = ":3014"
+	// NetworkMonitorInterval is how often the network monitor probes the other nodes
+	NetworkMonitorInterval = 30 * time.Second
+	// NetworkMonitorProbes is the number of probes sent to each node per interval
+	NetworkMonitorProbes = 5
+	// NetworkMonitorProbeTimeout is how long the network monitor waits for
+	// the replies to the probes
+	NetworkMonitorProbeTimeout = 2 * time.Second
+	// NetworkMonitorLatencyThreshold is the round-trip time between nodes
+	// above which the network is reported as degraded
+	NetworkMonitorLatencyThreshold = 100 * time.Millisecond
+	// NetworkMonitorPacketLossThreshold is the percentage of lost probes
+	// above which the network is reported as degraded
+	NetworkMonitorPacketLossThreshold = 5
+	// NetworkHealthConfigMapPrefix is the name prefix of the config maps
+	// the network monitors publish their reports in
+	NetworkHealthConfigMapPrefix = "gravity-network-health-"
+
+	// CatalogSyncInterval is how often the charts of an upstream Helm
+	// repository are mirrored into the local catalog by default
+	CatalogSyncInterval = 1 * time.Hour
+	// CatalogSyncMinInterval is the minimum allowed catalog sync interval
+	CatalogSyncMinInterval = 5 * time.Minute
+	// CatalogSyncCheckInterval is how often the catalog syncer checks
+	// whether the repository is due for synchronization
+	CatalogSyncCheckInterval = 1 * time.Minute
+
+	// BackupCheckInterval is how often the backup scheduler checks
+	// whether the cluster backup is due
+	BackupCheckInterval = 1 * time.Minute
+	// BackupRetain is the number of the most recent scheduled
+	// backups kept by default
+	BackupRetain = 7
+	// BackupTimeout is the maximum duration of a single scheduled backup
+	BackupTimeout = 30 * time.Minute
+	// ScheduledBackupDir is the default directory on the master nodes
+	// the scheduled backups are written to
+	ScheduledBackupDir = "/var/lib/gravity/backups"
+
+	// ReplicationCheckInterval is how often the replicator of the standby
+	// cluster checks whether the replication is due
+	ReplicationCheckInterval = 1 * time.Minute
+	// ReplicationInterval is how often the standby cluster replicates
+	// the latest backup of the primary cluster by default
+	ReplicationInterval = 15 * time.Minute
+	// ReplicationMinInterval is the minimum allowed replication interval
+	ReplicationMinInterval = 1 * time.Minute
+	// ReplicationRetain is the number of the most recent replicated
+	// backups kept by default
+	ReplicationRetain = 2
+	// ReplicationDir is the default directory on the master nodes
+	// of the standby cluster the replicated backups are written to
+	ReplicationDir = "/var/lib/gravity/backups/replicas"
+
+	// WireguardSyncInterval is how often the WireGuard keys of the nodes
+	// are checked for distribution and rotation
+	WireguardSyncInterval = 1 * time.Minute
+	// WireguardKeyRotationInterval is the maximum age of a WireGuard node key
+	// after which the key is rotated
+	WireguardKeyRotationInterval = 7 * 24 * time.Hour
+	// WireguardSecret is the name of the secret in the kube-system namespace
+	// with the WireGuard keys and the tunnel configuration of the nodes
+	WireguardSecret = "wireguard"
+
+	// EtcdMaintenanceInterval is how often the etcd database is checked
+	// for compaction and defragmentation
+	EtcdMaintenanceInterval = 1 * time.Hour
+	// EtcdRetainedRevisions is the number of the most recent etcd revisions
+	// kept by the compaction
+	EtcdRetainedRevisions = 10000
+	// EtcdDefragThreshold is the size of an etcd member database in bytes
+	// above which the member is defragmented
+	EtcdDefragThreshold = 512 * 1024 * 1024
+	// EtcdDefragInterval is the minimum interval between defragmentations
+	// of the same etcd member
+	EtcdDefragInterval = 24 * time.Hour
+	// EtcdMaintenanceTimeout is the timeout for a single etcd maintenance request
+	EtcdMaintenanceTimeout = 5 * time.Minute
+
+	// EtcdHealthInterval is how often the etcd health and capacity are checked
+	EtcdHealthInterval = 1 * time.Minute
+	// EtcdHealthTimeout is the timeout for a single etcd health request
+	EtcdHealthTimeout = 10 * time.Second
+	// EtcdQuotaBytes is the etcd space quota, same as the etcd default
+	EtcdQuotaBytes = 2 * 1024 * 1024 * 1024
+	// EtcdQuotaWarningThreshold is the percentage of the etcd space quota
+	// used above which etcd is reported as degraded
+	EtcdQuotaWarningThreshold = 80
+	// EtcdQuotaCriticalThreshold is the percentage of the etcd space quota
+	// used above which etcd is reported as critical
+	EtcdQuotaCriticalThreshold = 95
+	// EtcdLeaderChangesWindow is the time window the etcd leader changes are counted in
+	EtcdLeaderChangesWindow = 1 * time.Hour
+	// EtcdLeaderChangesThreshold is the number of etcd leader changes within
+	// EtcdLeaderChangesWindow above which etcd is reported as degraded
+	EtcdLeaderChangesThreshold = 3
+	// EtcdSlowReadThreshold is the latency of a linearizable etcd read
+	// above which etcd is reported as degraded
+	EtcdSlowReadThreshold = 500 * time.Millisecond
+	// EtcdRaftIndexLagThreshold is the number of raft entries an etcd member
+	// can be behind the leader before it is reported as degraded
+	EtcdRaftIndexLagThreshold = 1000
+
+	// StateChangelogRetention is how long the changes to the cluster state
+	// are kept in the state changelog
+	StateChangelogRetention = 7 * 24 * time.Hour
+
+	// ClusterEventsRetention is how long the cluster events are kept
+	// in the cluster events table
+	ClusterEventsRetention = 7 * 24 * time.Hour
+	// ClusterEventsLimit is the maximum number of cluster events
+	// returned with a single request
+	ClusterEventsLimit = 1000
+	// ClusterEventsPollInterval is how often the cluster events table
+	// is polled for new events when streaming the events
+	ClusterEventsPollInterval = 1 * time.Second
+
+	// VaultMount is the default mount path of the Vault KV secrets engine
+	VaultMount = "secret"
+	// VaultPrefix is the default path prefix of the secrets in Vault
+	VaultPrefix = "gravity"
+	// VaultRequestTimeout is the timeout for a single Vault API request
+	VaultRequestTimeout = 30 * time.Second
+	// SecretLeaseCheckInterval is how often the secrets of the expired
+	// backend records are removed from the secrets provider
+	SecretLeaseCheckInterval = 5 * time.Minute
 
 	// KubeSystemNamespace is the name of k8s namespace where all our system stuff goes
 	KubeSystemNamespace = "kube-system"
@@ -669,6 +931,9 @@ const (
 	// KubernetesAdvertiseIPLabel is the kubernetes node label of the advertise IP address
 	KubernetesAdvertiseIPLabel = "gravitational.io/advertise-ip"
 
+	// KubernetesNodePoolLabel is the Kubernetes node label with the name of the node pool
+	KubernetesNodePoolLabel = "gravitational.io/node-pool"
+
 	// RunLevelLabel is the Kubernetes node taint label representing a run-level
 	RunLevelLabel = "gravitational.io/runlevel"
 
@@ -741,6 +1006,9 @@ const (
 	// TODO(klizhentas) what user to choose, this should be site-specific and use principle of least privilege
 	SSHUser = "root"
 
+	// SSHPort is the default SSH port
+	SSHPort = 22
+
 	// HTTPSPort is a default HTTPS port
 	HTTPSPort = "443"
 
@@ -791,6 +1059,21 @@ const (
 	// DiskTransferRate is the minimum required disk speed for some default locations
 	DiskTransferRate = "10MB/s"
 
+	// SystemDeviceCapacity is the minimum size of a device dedicated
+	// to the system state directory
+	SystemDeviceCapacity = "10GB"
+	// EtcdDeviceCapacity is the minimum size of a device dedicated to etcd
+	// and the size of the etcd logical volume created by the disk layout planner
+	EtcdDeviceCapacity = "5GB"
+	// DiskLayoutVolumeGroup is the name of the LVM volume group created
+	// by the disk layout planner
+	DiskLayoutVolumeGroup = "gravity"
+
+	// DemoMinCPU is the maximum CPU requirement enforced for demo installations
+	DemoMinCPU = 1
+	// DemoMinRAM is the maximum RAM requirement enforced for demo installations
+	DemoMinRAM = "2GB"
+
 	// PingPongDuration is the duration of a ping-pong game agents play
 	PingPongDuration = 10 * time.Second
 	// BandwidthTestPort is the port for the bandwidth test agents do
@@ -868,6 +1151,20 @@ const (
 	// EtcdUpgradeBackupFile is the filename to store a temporary backup of the etcd database when recreating the etcd datastore
 	EtcdUpgradeBackupFile = "etcd.bak"
 
+	// EtcdShutdownBackupFile is the filename to store the backup of the etcd database taken before the cluster is stopped
+	EtcdShutdownBackupFile = "etcd-shutdown.bak"
+
+	// EtcdVersionFile is the file in the planet etcd directory that names
+	// the version of etcd to run and, thus, its data directory
+	EtcdVersionFile = "etcd-version.txt"
+
+	// EtcdRestoreSnapshotFile is the filename of the etcd snapshot staged
+	// on the master nodes to restore the etcd database from
+	EtcdRestoreSnapshotFile = "etcd-restore.db"
+
+	// ClusterStopStateFile is the filename to store the state of the stopped cluster
+	ClusterStopStateFile = "cluster-stop.json"
+
 	// EtcdPeerPort is etcd inter-cluster communication port
 	EtcdPeerPort = 2380
 	// EtcdAPIPort is etcd client API port
@@ -877,6 +1174,8 @@ const (
 	EtcdGravityPrefix = "/gravity"
 	// EtcdPlanetPrefix is etcd prefix under which planet keeps its data
 	EtcdPlanetPrefix = "/planet"
+	// EtcdKubernetesPrefix is etcd prefix under which Kubernetes keeps its data
+	EtcdKubernetesPrefix = "/registry"
 
 	// SchedulerKeyFilename is the kube-scheduler private key filename
 	SchedulerKeyFilename = "scheduler.key"
@@ -910,6 +1209,16 @@ const (
 	// EndpointsWaitTimeout specifies the timeout for waiting for system service endpoints
 	EndpointsWaitTimeout = 5 * time.Minute
 
+	// CanaryHealthTimeout specifies the timeout for the canary node to become healthy
+	// after it has been upgraded
+	CanaryHealthTimeout = 5 * time.Minute
+
+	// SmokeTestTimeout specifies the default timeout of an application smoke test
+	SmokeTestTimeout = 5 * time.Minute
+
+	// SmokeTestRetryInterval specifies how often a failing HTTP smoke test is retried
+	SmokeTestRetryInterval = 5 * time.Second
+
 	// DrainErrorTimeout specifies the timeout for the initial failures of drain operation.
 	// Drain operation might experience transient errors (e.g. api server connect failures)
 	// in which case the timeout defines the maximum time frame to retry such failed attempts.
@@ -984,6 +1293,9 @@ const (
 	// VxlanPort is the port used for overlay network
 	VxlanPort = 8472
 
+	// WireguardPort is the port of the WireGuard tunnels of the encrypted overlay network
+	WireguardPort = 51820
+
 	// DNSListenAddr is the default address coredns will be configured to listen on
 	DNSListenAddr = "127.0.0.2"
 
@@ -1014,6 +1326,14 @@ const (
 	// for an operation that spans multiple nodes
 	AgentDeployTimeout = 5 * time.Minute
 
+	// AgentRebootTimeout specifies the maximum amount of time to wait for the agent
+	// on a remote node to reconnect after the node has rebooted during an operation
+	AgentRebootTimeout = 20 * time.Minute
+
+	// RemovalReplicationTimeout specifies the maximum amount of time to wait
+	// for stateful applications to replicate their data before removing a node
+	RemovalReplicationTimeout = 5 * time.Minute
+
 	// InstanceTerminationTimeout is the maximum amount of time to wait
 	// for AWS EC2 instance to terminate
 	InstanceTerminationTimeout = 20 * time.Minute
@@ -1043,6 +1363,10 @@ var (
 	// LocalCacheDir is the location where gravity stores downloaded packages
 	LocalCacheDir = filepath.Join(LocalDataDir, "cache")
 
+	// BuildCacheDir is the name of the build cache directory in LocalCacheDir
+	// where tele build stores the vendored image layers
+	BuildCacheDir = "build"
+
 	// ClusterRegistryDir is the location of the cluster's Docker registry backend.
 	ClusterRegistryDir = filepath.Join(GravityDir, PlanetDir, StateRegistryDir)
 
@@ -1119,6 +1443,18 @@ var (
 	// CertificateExpiry is the validity period of certificates generated
 	// during cluster installation (such as apiserver, etcd, kubelet, etc.)
 	CertificateExpiry = 10 * 365 * 24 * time.Hour // 10 years
+	// CertificateExpiryWarning is how long before the expiration
+	// the certificates are reported as expiring
+	CertificateExpiryWarning = 30 * 24 * time.Hour
+	// CertificateExpiryCritical is how long before the expiration
+	// of a certificate the cluster is considered degraded
+	CertificateExpiryCritical = 7 * 24 * time.Hour
+	// CertificateExpiryCheckInterval is how often the expiration
+	// of the certificates is checked
+	CertificateExpiryCheckInterval = 1 * time.Hour
+	// CertificateRenewBefore is how long before the expiration
+	// the certificates are rotated automatically
+	CertificateRenewBefore = 30 * 24 * time.Hour
 
 	// GravitySystemLog defines the default location for the system log
 	GravitySystemLog = filepath.Join(SystemLogDir, GravitySystemLogFile)
@@ -1138,6 +1474,10 @@ var (
 	// node to leave the cluster
 	NodeLeaveTimeout = 1 * time.Minute
 
+	// ClusterStartTimeout specifies the maximum amount of time to wait for
+	// the stopped cluster to become healthy after it has been started
+	ClusterStartTimeout = 15 * time.Minute
+
 	// AgentWaitTimeout specifies the maximum amount of time to wait for
 	// agents to form a cluster before commencing the operation
 	AgentWaitTimeout = 5 * time.Minute
@@ -1179,6 +1519,11 @@ var (
 	// MetricsStep is the default interval b/w cluster metrics data points.
 	MetricsStep = 15 * time.Second
 
+	// MirrorRegistryAddr is the default address the air-gapped mirror serves docker registry on
+	MirrorRegistryAddr = "0.0.0.0:5000"
+	// MirrorPackagesAddr is the default address the air-gapped mirror serves packages on
+	MirrorPackagesAddr = "0.0.0.0:8080"
+
 	// AbortedOperationExitCode specifies the exit code for this process when an operation is aborted.
 	// The exit code is used to prevent the installer service from restarting in case the operation
 	// is aborted
@@ -1238,6 +1583,17 @@ var BaseTaintsVersion = semver.Must(semver.NewVersion("4.36.0"))
 // can update
 var BaseUpdateVersion = semver.Must(semver.NewVersion("3.51.0"))
 
+// UpgradeReleaseLines lists the first version of each release line in ascending order.
+// A cluster can only be upgraded to the next release line directly - upgrades
+// across several release lines go through an intermediate release of each line
+var UpgradeReleaseLines = []semver.Version{
+	*semver.New("3.51.0"),
+	*semver.New("4.0.0"),
+	*semver.New("5.0.0"),
+	*semver.New("5.2.0"),
+	*semver.New("5.5.0"),
+}
+
 // DockerRegistryAddr returns the address of docker registry running on server
 func DockerRegistryAddr(server string) string {
 	return fmt.Sprintf("%v:%v", server, constants.DockerRegistryPort)
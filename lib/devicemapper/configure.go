@@ -96,6 +96,12 @@ func Unmount(out io.Writer, logger log.FieldLogger) (err error) {
 	return nil
 }
 
+// QueryPhysicalVolume returns the disk of the docker volume group
+// or an empty string if the volume group does not exist
+func QueryPhysicalVolume(logger log.FieldLogger) (disk string, err error) {
+	return queryPhysicalVolume(logger)
+}
+
 func queryPhysicalVolume(logger log.FieldLogger) (disk string, err error) {
 	logger.Debug("Query physical volume information.")
 
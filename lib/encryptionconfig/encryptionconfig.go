@@ -0,0 +1,258 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package encryptionconfig implements the apiserver encryption provider
+// configuration that encrypts Kubernetes secrets at rest.
+//
+// The configuration lists the encryption keys shared by all apiservers of
+// the cluster. The first key encrypts the secrets while the rest of the keys
+// only decrypt the secrets written before the first key has been promoted
+package encryptionconfig
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+
+	"github.com/gravitational/gravity/lib/storage/clusterconfig"
+
+	"github.com/ghodss/yaml"
+	"github.com/gravitational/trace"
+)
+
+// New returns a new configuration with a single randomly generated key
+func New() (*Config, error) {
+	key, err := NewKey(initialKeyName)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	config := FromKeys([]Key{*key}, clusterconfig.Encryption{
+		Provider: clusterconfig.EncryptionProviderAESCBC,
+	})
+	return &config, nil
+}
+
+// FromKeys returns the configuration that encrypts the secrets with the
+// specified keys using the provider from the given cluster configuration.
+//
+// The configuration lists all local providers with the same keys followed
+// by the identity provider so that the secrets written before the provider
+// has been changed, or before the encryption has been enabled, stay readable
+func FromKeys(keys []Key, encryption clusterconfig.Encryption) Config {
+	aescbc := Provider{AESCBC: &Keys{Keys: keys}}
+	secretbox := Provider{Secretbox: &Keys{Keys: keys}}
+	var providers []Provider
+	switch encryption.Provider {
+	case clusterconfig.EncryptionProviderKMS:
+		kms := &KMS{
+			Name:      encryption.KMS.Name,
+			Endpoint:  encryption.KMS.Endpoint,
+			CacheSize: encryption.KMS.CacheSize,
+		}
+		if encryption.KMS.Timeout != nil {
+			kms.Timeout = encryption.KMS.Timeout.Duration.String()
+		}
+		providers = append(providers, Provider{KMS: kms}, aescbc, secretbox)
+	case clusterconfig.EncryptionProviderSecretbox:
+		providers = append(providers, secretbox, aescbc)
+	default:
+		providers = append(providers, aescbc, secretbox)
+	}
+	providers = append(providers, Provider{Identity: &Identity{}})
+	return Config{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Resources: []Resources{{
+			Resources: []string{"secrets"},
+			Providers: providers,
+		}},
+	}
+}
+
+// Parse parses the configuration from the specified data
+func Parse(data []byte) (*Config, error) {
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if config.Kind != kind {
+		return nil, trace.BadParameter("expected %v, got %q", kind, config.Kind)
+	}
+	return &config, nil
+}
+
+// Marshal returns this configuration as YAML
+func (r Config) Marshal() ([]byte, error) {
+	data, err := yaml.Marshal(r)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return data, nil
+}
+
+// Keys returns the keys of the local providers, active key first
+func (r Config) Keys() []Key {
+	for _, resources := range r.Resources {
+		for _, provider := range resources.Providers {
+			switch {
+			case provider.AESCBC != nil:
+				return provider.AESCBC.Keys
+			case provider.Secretbox != nil:
+				return provider.Secretbox.Keys
+			}
+		}
+	}
+	return nil
+}
+
+// NewKey returns a new randomly generated key with the specified name
+func NewKey(name string) (*Key, error) {
+	secret := make([]byte, keySize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &Key{
+		Name:   name,
+		Secret: base64.StdEncoding.EncodeToString(secret),
+	}, nil
+}
+
+// StageKey adds the specified key to the keys as the key that only decrypts
+// the secrets until it has been promoted with PromoteKey.
+// The keys other than the active key are removed as the secrets have
+// been re-encrypted with the active key by the previous rotation
+func StageKey(keys []Key, key Key) []Key {
+	if index := indexOf(keys, key.Name); index >= 0 {
+		return keys
+	}
+	if len(keys) == 0 {
+		return []Key{key}
+	}
+	return []Key{keys[0], key}
+}
+
+// UnstageKey removes the key with the specified name from the keys
+func UnstageKey(keys []Key, name string) (result []Key) {
+	for _, key := range keys {
+		if key.Name != name {
+			result = append(result, key)
+		}
+	}
+	return result
+}
+
+// PromoteKey makes the key with the specified name the active key.
+// The replaced active key is kept to decrypt the existing secrets
+func PromoteKey(keys []Key, name string) ([]Key, error) {
+	index := indexOf(keys, name)
+	if index < 0 {
+		return nil, trace.NotFound("encryption key %v not found", name)
+	}
+	result := []Key{keys[index]}
+	return append(result, UnstageKey(keys, name)...), nil
+}
+
+// DemoteKey reverts PromoteKey: it makes the key that follows the key with
+// the specified name the active key again
+func DemoteKey(keys []Key, name string) ([]Key, error) {
+	index := indexOf(keys, name)
+	if index < 0 {
+		return nil, trace.NotFound("encryption key %v not found", name)
+	}
+	if index != 0 || len(keys) < 2 {
+		return keys, nil
+	}
+	return []Key{keys[1], keys[0]}, nil
+}
+
+func indexOf(keys []Key, name string) int {
+	for i, key := range keys {
+		if key.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Config is the apiserver encryption provider configuration
+type Config struct {
+	// APIVersion is the configuration API version
+	APIVersion string `json:"apiVersion"`
+	// Kind is the configuration kind
+	Kind string `json:"kind"`
+	// Resources lists the resources to encrypt
+	Resources []Resources `json:"resources"`
+}
+
+// Resources defines the providers that encrypt the specified resources
+type Resources struct {
+	// Resources lists the names of the resources
+	Resources []string `json:"resources"`
+	// Providers lists the providers in the order of preference.
+	// The first provider encrypts the resources
+	Providers []Provider `json:"providers"`
+}
+
+// Provider configures a single encryption provider
+type Provider struct {
+	// AESCBC configures the AES-CBC provider
+	AESCBC *Keys `json:"aescbc,omitempty"`
+	// Secretbox configures the XSalsa20 and Poly1305 provider
+	Secretbox *Keys `json:"secretbox,omitempty"`
+	// KMS configures the envelope encryption with a KMS plugin
+	KMS *KMS `json:"kms,omitempty"`
+	// Identity configures the provider that does not encrypt the resources
+	Identity *Identity `json:"identity,omitempty"`
+}
+
+// Keys lists the keys of a local provider
+type Keys struct {
+	// Keys lists the keys, active key first
+	Keys []Key `json:"keys"`
+}
+
+// Key is a single encryption key
+type Key struct {
+	// Name is the name of the key
+	Name string `json:"name"`
+	// Secret is the base64-encoded key
+	Secret string `json:"secret"`
+}
+
+// KMS configures the envelope encryption with a KMS plugin
+type KMS struct {
+	// Name is the name of the KMS plugin
+	Name string `json:"name"`
+	// Endpoint is the unix socket the KMS plugin listens on
+	Endpoint string `json:"endpoint"`
+	// CacheSize is the number of data encryption keys cached in memory
+	CacheSize int `json:"cachesize,omitempty"`
+	// Timeout is the timeout of the requests to the KMS plugin
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// Identity configures the provider that does not encrypt the resources
+type Identity struct{}
+
+const (
+	apiVersion = "apiserver.config.k8s.io/v1"
+	kind       = "EncryptionConfiguration"
+
+	// initialKeyName is the name of the key generated
+	// when the encryption is enabled
+	initialKeyName = "key1"
+	// keySize is the size of the generated keys in bytes
+	keySize = 32
+)
@@ -0,0 +1,99 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryptionconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gravitational/gravity/lib/storage/clusterconfig"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	"gopkg.in/check.v1"
+)
+
+func TestEncryptionConfig(t *testing.T) { check.TestingT(t) }
+
+type EncryptionConfigSuite struct{}
+
+var _ = check.Suite(&EncryptionConfigSuite{})
+
+func (s *EncryptionConfigSuite) TestGeneratesConfig(c *check.C) {
+	config1, err := New()
+	c.Assert(err, check.IsNil)
+	config2, err := New()
+	c.Assert(err, check.IsNil)
+	c.Assert(config1.Keys()[0].Secret, check.Not(check.Equals), config2.Keys()[0].Secret)
+
+	data, err := config1.Marshal()
+	c.Assert(err, check.IsNil)
+	parsed, err := Parse(data)
+	c.Assert(err, check.IsNil)
+	c.Assert(*parsed, check.DeepEquals, *config1)
+	providers := parsed.Resources[0].Providers
+	c.Assert(providers, check.HasLen, 3)
+	c.Assert(providers[0].AESCBC, check.NotNil)
+	c.Assert(providers[2].Identity, check.NotNil)
+
+	_, err = Parse([]byte("kind: ConfigMap"))
+	c.Assert(err, check.NotNil)
+}
+
+func (s *EncryptionConfigSuite) TestConfiguresKMSProvider(c *check.C) {
+	timeout := teleservices.NewDuration(3 * time.Second)
+	keys := []Key{{Name: "key1", Secret: "secret"}}
+	config := FromKeys(keys, clusterconfig.Encryption{
+		Provider: clusterconfig.EncryptionProviderKMS,
+		KMS: &clusterconfig.KMS{
+			Name:     "vault",
+			Endpoint: "unix:///var/run/kms-plugin/socket.sock",
+			Timeout:  &timeout,
+		},
+	})
+	providers := config.Resources[0].Providers
+	c.Assert(*providers[0].KMS, check.DeepEquals, KMS{
+		Name:     "vault",
+		Endpoint: "unix:///var/run/kms-plugin/socket.sock",
+		Timeout:  "3s",
+	})
+	c.Assert(config.Keys(), check.DeepEquals, keys, check.Commentf("Local keys decrypt existing secrets."))
+}
+
+func (s *EncryptionConfigSuite) TestRotatesKeys(c *check.C) {
+	old := Key{Name: "key1"}
+	active := Key{Name: "key2"}
+	pending := Key{Name: "key3"}
+
+	keys := StageKey([]Key{active, old}, pending)
+	c.Assert(keys, check.DeepEquals, []Key{active, pending})
+	c.Assert(StageKey(keys, pending), check.DeepEquals, keys)
+	c.Assert(UnstageKey(keys, pending.Name), check.DeepEquals, []Key{active})
+
+	keys, err := PromoteKey(keys, pending.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(keys, check.DeepEquals, []Key{pending, active})
+	promoted, err := PromoteKey(keys, pending.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(promoted, check.DeepEquals, keys)
+
+	keys, err = DemoteKey(keys, pending.Name)
+	c.Assert(err, check.IsNil)
+	c.Assert(keys, check.DeepEquals, []Key{active, pending})
+
+	_, err = PromoteKey(keys, "key4")
+	c.Assert(err, check.NotNil)
+}
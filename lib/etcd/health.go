@@ -0,0 +1,327 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"context"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/metrics"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	log "github.com/sirupsen/logrus"
+)
+
+// HealthClient defines the subset of the etcd API used for the health monitoring
+type HealthClient interface {
+	// MemberList lists the members of the etcd cluster
+	MemberList(ctx context.Context) (*clientv3.MemberListResponse, error)
+	// Status returns the status of the member with the specified endpoint
+	Status(ctx context.Context, endpoint string) (*clientv3.StatusResponse, error)
+	// AlarmList returns the active alarms
+	AlarmList(ctx context.Context) (*clientv3.AlarmResponse, error)
+	// Get retrieves the keys
+	Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error)
+}
+
+// MonitorConfig describes the configuration of the etcd health monitor
+type MonitorConfig struct {
+	// Client is the etcd client
+	Client HealthClient
+	// Backend stores the health status
+	Backend storage.EtcdHealth
+	// ClusterName is the name of the local cluster
+	ClusterName string
+	// Interval is how often the health is checked
+	Interval time.Duration
+	// QuotaBytes is the etcd space quota
+	QuotaBytes int64
+	// Timeout is the timeout for a single health request
+	Timeout time.Duration
+	// Clock is used to timestamp the health status
+	Clock clockwork.Clock
+	// FieldLogger is used for logging
+	log.FieldLogger
+}
+
+// CheckAndSetDefaults validates the configuration and sets defaults
+func (r *MonitorConfig) CheckAndSetDefaults() error {
+	if r.Client == nil {
+		return trace.BadParameter("etcd client is required")
+	}
+	if r.Backend == nil {
+		return trace.BadParameter("backend is required")
+	}
+	if r.ClusterName == "" {
+		return trace.BadParameter("cluster name is required")
+	}
+	if r.QuotaBytes < 0 {
+		return trace.BadParameter("etcd space quota can not be negative")
+	}
+	if r.Interval == 0 {
+		r.Interval = defaults.EtcdHealthInterval
+	}
+	if r.QuotaBytes == 0 {
+		r.QuotaBytes = defaults.EtcdQuotaBytes
+	}
+	if r.Timeout == 0 {
+		r.Timeout = defaults.EtcdHealthTimeout
+	}
+	if r.Clock == nil {
+		r.Clock = clockwork.NewRealClock()
+	}
+	if r.FieldLogger == nil {
+		r.FieldLogger = log.WithField(trace.Component, "etcd-monitor")
+	}
+	return nil
+}
+
+// NewMonitor returns a new monitor that periodically checks
+// the etcd health and capacity
+func NewMonitor(config MonitorConfig) (*Monitor, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &Monitor{MonitorConfig: config}, nil
+}
+
+// Monitor periodically records the size of the member databases relative
+// to the space quota, the active alarms, the leader changes, the latency
+// of a linearizable read and how far the members are behind the leader,
+// and exports them as metrics
+type Monitor struct {
+	MonitorConfig
+}
+
+// Run checks the health until the context is canceled
+func (r *Monitor) Run(ctx context.Context) {
+	r.Info("Starting etcd health monitor.")
+	ticker := r.Clock.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		if err := r.Check(ctx); err != nil {
+			r.WithError(err).Warn("Failed to check etcd health.")
+		}
+		select {
+		case <-ticker.Chan():
+		case <-ctx.Done():
+			r.Info("Stopping etcd health monitor.")
+			return
+		}
+	}
+}
+
+// Check executes a single health check and records its status
+func (r *Monitor) Check(ctx context.Context) error {
+	prev, err := r.Backend.GetEtcdHealthStatus(r.ClusterName)
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	if prev == nil {
+		prev = &storage.EtcdHealthStatus{}
+	}
+	status := r.check(ctx, *prev)
+	updateMetrics(status)
+	if err := r.Backend.UpsertEtcdHealthStatus(r.ClusterName, status); err != nil {
+		return trace.Wrap(err)
+	}
+	if status.Message != "" {
+		return trace.BadParameter("%v", status.Message)
+	}
+	for _, problem := range status.Problems(status.Updated) {
+		r.Warnf("Etcd problem: %v.", problem)
+	}
+	return nil
+}
+
+func (r *Monitor) check(ctx context.Context, prev storage.EtcdHealthStatus) storage.EtcdHealthStatus {
+	now := r.Clock.Now().UTC()
+	status := storage.EtcdHealthStatus{
+		Updated:    now,
+		Interval:   r.Interval,
+		QuotaBytes: r.QuotaBytes,
+		RaftTerm:   prev.RaftTerm,
+	}
+	for _, change := range prev.LeaderChanges {
+		if now.Sub(change) < defaults.EtcdLeaderChangesWindow {
+			status.LeaderChanges = append(status.LeaderChanges, change)
+		}
+	}
+	members, err := r.listMembers(ctx)
+	if err != nil {
+		status.Message = err.Error()
+		return status
+	}
+	names := make(map[uint64]string, len(members))
+	for _, member := range members {
+		names[member.ID] = member.Name
+	}
+	var leaderIndex uint64
+	for _, member := range members {
+		memberHealth := storage.EtcdMemberHealth{
+			Name:     member.Name,
+			Endpoint: member.ClientURLs[0],
+		}
+		resp, err := endpointStatus(ctx, r.Client, memberHealth.Endpoint, r.Timeout)
+		if err != nil {
+			memberHealth.Message = err.Error()
+			status.Members = append(status.Members, memberHealth)
+			continue
+		}
+		memberHealth.DBSize = resp.DbSize
+		memberHealth.RaftIndex = resp.RaftIndex
+		if resp.Leader != 0 {
+			status.Leader = names[resp.Leader]
+		}
+		if resp.Leader == member.ID {
+			leaderIndex = resp.RaftIndex
+		}
+		if resp.RaftTerm > status.RaftTerm {
+			if status.RaftTerm != 0 {
+				r.Infof("Etcd raft term changed from %v to %v.", status.RaftTerm, resp.RaftTerm)
+				status.LeaderChanges = append(status.LeaderChanges, now)
+				metrics.EtcdLeaderChanges.Inc()
+			}
+			status.RaftTerm = resp.RaftTerm
+		}
+		status.Members = append(status.Members, memberHealth)
+	}
+	for i := range status.Members {
+		member := &status.Members[i]
+		if member.Message == "" && leaderIndex > member.RaftIndex {
+			member.RaftIndexLag = leaderIndex - member.RaftIndex
+		}
+	}
+	status.Alarms, err = r.alarms(ctx, names)
+	if err != nil {
+		status.Message = err.Error()
+	}
+	status.ReadLatency, err = r.readLatency(ctx)
+	if err != nil {
+		status.Message = err.Error()
+	}
+	status.Stores, err = r.stores(ctx)
+	if err != nil {
+		status.Message = err.Error()
+	}
+	return status
+}
+
+func (r *Monitor) alarms(ctx context.Context, names map[uint64]string) ([]storage.EtcdAlarm, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+	resp, err := r.Client.AlarmList(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var alarms []storage.EtcdAlarm
+	for _, alarm := range resp.Alarms {
+		alarms = append(alarms, storage.EtcdAlarm{
+			Member: names[alarm.MemberID],
+			Type:   alarm.Alarm.String(),
+		})
+	}
+	return alarms, nil
+}
+
+// readLatency returns the latency of a linearizable read.
+// The member serving the read waits until it has applied the entries
+// committed at the time of the read
+func (r *Monitor) readLatency(ctx context.Context) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+	start := r.Clock.Now()
+	_, err := r.Client.Get(ctx, defaults.EtcdGravityPrefix, clientv3.WithCountOnly())
+	if err != nil {
+		return 0, trace.Wrap(err)
+	}
+	return r.Clock.Now().Sub(start), nil
+}
+
+// stores returns the number of keys in the state stores
+// that share the etcd database
+func (r *Monitor) stores(ctx context.Context) ([]storage.EtcdStoreUsage, error) {
+	stores := []storage.EtcdStoreUsage{
+		{Name: StoreKubernetes, Prefix: defaults.EtcdKubernetesPrefix},
+		{Name: StorePlanet, Prefix: defaults.EtcdPlanetPrefix},
+		{Name: StoreGravity, Prefix: defaults.EtcdGravityPrefix},
+	}
+	for i := range stores {
+		keys, err := r.countKeys(ctx, stores[i].Prefix)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		stores[i].Keys = keys
+	}
+	return stores, nil
+}
+
+func (r *Monitor) countKeys(ctx context.Context, prefix string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+	resp, err := r.Client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithCountOnly(),
+		clientv3.WithSerializable())
+	if err != nil {
+		return 0, trace.Wrap(err)
+	}
+	return resp.Count, nil
+}
+
+func (r *Monitor) listMembers(ctx context.Context) ([]clientv3.Member, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+	return listMembers(ctx, r.Client)
+}
+
+func updateMetrics(status storage.EtcdHealthStatus) {
+	metrics.EtcdQuota.Set(float64(status.QuotaBytes))
+	if status.Leader != "" {
+		metrics.EtcdHasLeader.Set(1)
+	} else {
+		metrics.EtcdHasLeader.Set(0)
+	}
+	metrics.EtcdReadLatency.Set(status.ReadLatency.Seconds())
+	metrics.EtcdDBSize.Reset()
+	metrics.EtcdRaftIndexLag.Reset()
+	for _, member := range status.Members {
+		if member.Message != "" {
+			continue
+		}
+		metrics.EtcdDBSize.WithLabelValues(member.Name).Set(float64(member.DBSize))
+		metrics.EtcdRaftIndexLag.WithLabelValues(member.Name).Set(float64(member.RaftIndexLag))
+	}
+	metrics.EtcdAlarms.Reset()
+	for _, alarm := range status.Alarms {
+		metrics.EtcdAlarms.WithLabelValues(alarm.Type).Inc()
+	}
+	for _, store := range status.Stores {
+		metrics.EtcdKeys.WithLabelValues(store.Name).Set(float64(store.Keys))
+	}
+}
+
+const (
+	// StoreKubernetes is the name of the Kubernetes state store
+	StoreKubernetes = "kubernetes"
+	// StorePlanet is the name of the planet state store
+	StorePlanet = "planet"
+	// StoreGravity is the name of the Gravity cluster state store
+	StoreGravity = "gravity"
+)
@@ -0,0 +1,189 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"time"
+
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/keyval"
+
+	"github.com/coreos/etcd/clientv3"
+	pb "github.com/coreos/etcd/etcdserver/etcdserverpb"
+	"github.com/jonboulle/clockwork"
+	. "gopkg.in/check.v1"
+)
+
+type MonitorSuite struct {
+	backend storage.Backend
+	client  *fakeHealthClient
+	clock   clockwork.FakeClock
+}
+
+var _ = Suite(&MonitorSuite{})
+
+func (s *MonitorSuite) SetUpTest(c *C) {
+	var err error
+	s.backend, err = keyval.NewBolt(keyval.BoltConfig{Path: filepath.Join(c.MkDir(), "bolt.db")})
+	c.Assert(err, IsNil)
+	s.clock = clockwork.NewFakeClock()
+	s.client = &fakeHealthClient{
+		members: []*pb.Member{
+			{ID: 1, Name: "node-1", ClientURLs: []string{"https://node-1:2379"}},
+			{ID: 2, Name: "node-2", ClientURLs: []string{"https://node-2:2379"}},
+		},
+		statuses: map[string]*clientv3.StatusResponse{
+			"https://node-1:2379": {DbSize: 100, Leader: 1, RaftIndex: 5000, RaftTerm: 2},
+			"https://node-2:2379": {DbSize: 90, Leader: 1, RaftIndex: 4990, RaftTerm: 2},
+		},
+		keys: map[string]int64{
+			"/registry": 300,
+			"/planet":   10,
+			"/gravity":  200,
+		},
+	}
+}
+
+func (s *MonitorSuite) TearDownTest(c *C) {
+	c.Assert(s.backend.Close(), IsNil)
+}
+
+func (s *MonitorSuite) TestRecordsHealth(c *C) {
+	monitor := s.newMonitor(c)
+	c.Assert(monitor.Check(context.TODO()), IsNil)
+
+	status, err := s.backend.GetEtcdHealthStatus("example.com")
+	c.Assert(err, IsNil)
+	c.Assert(status.Leader, Equals, "node-1")
+	c.Assert(status.RaftTerm, Equals, uint64(2))
+	c.Assert(status.LeaderChanges, HasLen, 0)
+	c.Assert(status.QuotaUsage(), Equals, 50)
+	c.Assert(status.Members, DeepEquals, []storage.EtcdMemberHealth{
+		{Name: "node-1", Endpoint: "https://node-1:2379", DBSize: 100, RaftIndex: 5000},
+		{Name: "node-2", Endpoint: "https://node-2:2379", DBSize: 90, RaftIndex: 4990, RaftIndexLag: 10},
+	})
+	c.Assert(status.Stores, DeepEquals, []storage.EtcdStoreUsage{
+		{Name: StoreKubernetes, Prefix: "/registry", Keys: 300},
+		{Name: StorePlanet, Prefix: "/planet", Keys: 10},
+		{Name: StoreGravity, Prefix: "/gravity", Keys: 200},
+	})
+	c.Assert(status.Problems(s.clock.Now()), HasLen, 0)
+}
+
+func (s *MonitorSuite) TestDetectsProblems(c *C) {
+	monitor := s.newMonitor(c)
+	c.Assert(monitor.Check(context.TODO()), IsNil)
+
+	// Leader moves to node-2 which is filling up the quota
+	for _, status := range s.client.statuses {
+		status.Leader = 2
+		status.RaftTerm = 3
+	}
+	s.client.statuses["https://node-2:2379"].DbSize = 170
+	s.clock.Advance(time.Minute)
+	c.Assert(monitor.Check(context.TODO()), IsNil)
+
+	status, err := s.backend.GetEtcdHealthStatus("example.com")
+	c.Assert(err, IsNil)
+	c.Assert(status.Leader, Equals, "node-2")
+	c.Assert(status.LeaderChanges, DeepEquals, []time.Time{s.clock.Now().UTC()})
+	c.Assert(problems(status.Problems(s.clock.Now())), DeepEquals, []string{
+		"member node-2: database is 85% of the space quota (170B of 200B), " +
+			"the members stop accepting writes when the quota is exceeded",
+	})
+
+	// Quota is exceeded
+	s.client.statuses["https://node-2:2379"].DbSize = 200
+	s.client.alarms = []*pb.AlarmMember{{MemberID: 2, Alarm: pb.AlarmType_NOSPACE}}
+	s.clock.Advance(time.Minute)
+	c.Assert(monitor.Check(context.TODO()), IsNil)
+	status, err = s.backend.GetEtcdHealthStatus("example.com")
+	c.Assert(err, IsNil)
+	var critical int
+	for _, problem := range status.Problems(s.clock.Now()) {
+		if problem.Critical {
+			critical++
+		}
+	}
+	c.Assert(critical, Equals, 2)
+
+	// Leader changes outside of the window are forgotten
+	s.clock.Advance(time.Hour)
+	c.Assert(monitor.Check(context.TODO()), IsNil)
+	status, err = s.backend.GetEtcdHealthStatus("example.com")
+	c.Assert(err, IsNil)
+	c.Assert(status.LeaderChanges, HasLen, 0)
+
+	s.client.membersErr = errors.New("etcd is unavailable")
+	s.clock.Advance(time.Minute)
+	c.Assert(monitor.Check(context.TODO()), NotNil)
+	status, err = s.backend.GetEtcdHealthStatus("example.com")
+	c.Assert(err, IsNil)
+	c.Assert(problems(status.Problems(s.clock.Now())), DeepEquals, []string{
+		"etcd is unavailable",
+	})
+}
+
+func (s *MonitorSuite) newMonitor(c *C) *Monitor {
+	monitor, err := NewMonitor(MonitorConfig{
+		Client:      s.client,
+		Backend:     s.backend,
+		ClusterName: "example.com",
+		QuotaBytes:  200,
+		Clock:       s.clock,
+	})
+	c.Assert(err, IsNil)
+	return monitor
+}
+
+func problems(problems []storage.EtcdProblem) (result []string) {
+	for _, problem := range problems {
+		result = append(result, problem.String())
+	}
+	return result
+}
+
+type fakeHealthClient struct {
+	members    []*pb.Member
+	membersErr error
+	statuses   map[string]*clientv3.StatusResponse
+	alarms     []*pb.AlarmMember
+	keys       map[string]int64
+}
+
+func (r *fakeHealthClient) MemberList(context.Context) (*clientv3.MemberListResponse, error) {
+	if r.membersErr != nil {
+		return nil, r.membersErr
+	}
+	return &clientv3.MemberListResponse{Members: r.members}, nil
+}
+
+func (r *fakeHealthClient) Status(_ context.Context, endpoint string) (*clientv3.StatusResponse, error) {
+	status := *r.statuses[endpoint]
+	return &status, nil
+}
+
+func (r *fakeHealthClient) AlarmList(context.Context) (*clientv3.AlarmResponse, error) {
+	return &clientv3.AlarmResponse{Alarms: r.alarms}, nil
+}
+
+func (r *fakeHealthClient) Get(_ context.Context, key string, _ ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	return &clientv3.GetResponse{Count: r.keys[key]}, nil
+}
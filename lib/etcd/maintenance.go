@@ -0,0 +1,328 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package etcd implements the maintenance and the health monitoring
+// of the cluster etcd database
+package etcd
+
+import (
+	"context"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/keyval"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/etcdserver/api/v3rpc/rpctypes"
+	"github.com/coreos/etcd/pkg/transport"
+	"github.com/dustin/go-humanize"
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	log "github.com/sirupsen/logrus"
+)
+
+// Client defines the subset of the etcd API used for the maintenance
+type Client interface {
+	// MemberList lists the members of the etcd cluster
+	MemberList(ctx context.Context) (*clientv3.MemberListResponse, error)
+	// Status returns the status of the member with the specified endpoint
+	Status(ctx context.Context, endpoint string) (*clientv3.StatusResponse, error)
+	// Defragment defragments the database of the member with the specified endpoint
+	Defragment(ctx context.Context, endpoint string) (*clientv3.DefragmentResponse, error)
+	// Compact compacts the keyspace up to the specified revision
+	Compact(ctx context.Context, rev int64, opts ...clientv3.CompactOption) (*clientv3.CompactResponse, error)
+}
+
+type memberLister interface {
+	// MemberList lists the members of the etcd cluster
+	MemberList(ctx context.Context) (*clientv3.MemberListResponse, error)
+}
+
+type statusGetter interface {
+	// Status returns the status of the member with the specified endpoint
+	Status(ctx context.Context, endpoint string) (*clientv3.StatusResponse, error)
+}
+
+// NewClient returns a new etcd client for the specified configuration
+func NewClient(config keyval.ETCDConfig) (*clientv3.Client, error) {
+	tlsInfo := transport.TLSInfo{
+		CertFile:      config.TLSCertFile,
+		KeyFile:       config.TLSKeyFile,
+		TrustedCAFile: config.TLSCAFile,
+	}
+	tlsConfig, err := tlsInfo.ClientConfig()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   config.Nodes,
+		TLS:         tlsConfig,
+		DialTimeout: defaults.DialTimeout,
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client, nil
+}
+
+// MaintainerConfig describes the configuration of the etcd maintainer
+type MaintainerConfig struct {
+	// Client is the etcd client
+	Client Client
+	// Backend stores the maintenance status
+	Backend storage.EtcdMaintenance
+	// ClusterName is the name of the local cluster
+	ClusterName string
+	// Interval is how often the database is checked for maintenance
+	Interval time.Duration
+	// RetainedRevisions is the number of the most recent revisions
+	// kept by the compaction
+	RetainedRevisions int64
+	// DefragThreshold is the size of a member database in bytes
+	// above which the member is defragmented
+	DefragThreshold int64
+	// DefragInterval is the minimum interval between defragmentations
+	// of the same member
+	DefragInterval time.Duration
+	// Timeout is the timeout for a single maintenance request
+	Timeout time.Duration
+	// Clock is used to timestamp the maintenance status
+	Clock clockwork.Clock
+	// FieldLogger is used for logging
+	log.FieldLogger
+}
+
+// CheckAndSetDefaults validates the configuration and sets defaults
+func (r *MaintainerConfig) CheckAndSetDefaults() error {
+	if r.Client == nil {
+		return trace.BadParameter("etcd client is required")
+	}
+	if r.Backend == nil {
+		return trace.BadParameter("backend is required")
+	}
+	if r.ClusterName == "" {
+		return trace.BadParameter("cluster name is required")
+	}
+	if r.RetainedRevisions < 0 {
+		return trace.BadParameter("number of retained revisions can not be negative")
+	}
+	if r.Interval == 0 {
+		r.Interval = defaults.EtcdMaintenanceInterval
+	}
+	if r.RetainedRevisions == 0 {
+		r.RetainedRevisions = defaults.EtcdRetainedRevisions
+	}
+	if r.DefragThreshold == 0 {
+		r.DefragThreshold = defaults.EtcdDefragThreshold
+	}
+	if r.DefragInterval == 0 {
+		r.DefragInterval = defaults.EtcdDefragInterval
+	}
+	if r.Timeout == 0 {
+		r.Timeout = defaults.EtcdMaintenanceTimeout
+	}
+	if r.Clock == nil {
+		r.Clock = clockwork.NewRealClock()
+	}
+	if r.FieldLogger == nil {
+		r.FieldLogger = log.WithField(trace.Component, "etcd-maintainer")
+	}
+	return nil
+}
+
+// NewMaintainer returns a new maintainer that periodically compacts
+// and defragments the etcd database
+func NewMaintainer(config MaintainerConfig) (*Maintainer, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &Maintainer{MaintainerConfig: config}, nil
+}
+
+// Maintainer periodically compacts the etcd keyspace keeping the configured
+// number of the most recent revisions and defragments the members whose
+// database exceeds the configured size.
+// Members are defragmented one at a time since defragmentation blocks
+// the member for its duration
+type Maintainer struct {
+	MaintainerConfig
+}
+
+// Run performs the maintenance until the context is canceled
+func (r *Maintainer) Run(ctx context.Context) {
+	r.Info("Starting etcd maintainer.")
+	ticker := r.Clock.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.Chan():
+			if err := r.Maintain(ctx); err != nil {
+				r.WithError(err).Warn("Failed to maintain etcd database.")
+			}
+		case <-ctx.Done():
+			r.Info("Stopping etcd maintainer.")
+			return
+		}
+	}
+}
+
+// Maintain executes a single maintenance pass and records its status
+func (r *Maintainer) Maintain(ctx context.Context) error {
+	prev, err := r.Backend.GetEtcdMaintenanceStatus(r.ClusterName)
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	if prev == nil {
+		prev = &storage.EtcdMaintenanceStatus{}
+	}
+	status := r.maintain(ctx, *prev)
+	if err := r.Backend.UpsertEtcdMaintenanceStatus(r.ClusterName, status); err != nil {
+		return trace.Wrap(err)
+	}
+	if status.Message != "" {
+		return trace.BadParameter("%v", status.Message)
+	}
+	return nil
+}
+
+func (r *Maintainer) maintain(ctx context.Context, prev storage.EtcdMaintenanceStatus) storage.EtcdMaintenanceStatus {
+	status := storage.EtcdMaintenanceStatus{
+		Updated:           r.Clock.Now().UTC(),
+		CompactedRevision: prev.CompactedRevision,
+		LastCompaction:    prev.LastCompaction,
+	}
+	members, err := r.listMembers(ctx)
+	if err != nil {
+		status.Message = err.Error()
+		return status
+	}
+	for _, member := range members {
+		memberStatus := storage.EtcdMemberMaintenanceStatus{
+			Name:     member.Name,
+			Endpoint: member.ClientURLs[0],
+		}
+		if prevMember := prev.FindMember(member.Name); prevMember != nil {
+			memberStatus.LastDefragmentation = prevMember.LastDefragmentation
+			memberStatus.ReclaimedBytes = prevMember.ReclaimedBytes
+		}
+		resp, err := endpointStatus(ctx, r.Client, memberStatus.Endpoint, r.Timeout)
+		if err != nil {
+			memberStatus.Message = err.Error()
+		} else {
+			memberStatus.DBSize = resp.DbSize
+			if resp.Header != nil && resp.Header.Revision > status.Revision {
+				status.Revision = resp.Header.Revision
+			}
+		}
+		status.Members = append(status.Members, memberStatus)
+	}
+	if err := r.compact(ctx, &status); err != nil {
+		r.WithError(err).Warn("Failed to compact etcd keyspace.")
+		status.Message = err.Error()
+	}
+	for i := range status.Members {
+		member := &status.Members[i]
+		if member.Message != "" || !r.needsDefragmentation(*member) {
+			continue
+		}
+		if err := r.defragment(ctx, member); err != nil {
+			r.WithError(err).Warnf("Failed to defragment etcd member %v.", member.Name)
+			member.Message = err.Error()
+		}
+	}
+	return status
+}
+
+// compact compacts the keyspace if more than the configured number
+// of revisions have accumulated since the last compaction
+func (r *Maintainer) compact(ctx context.Context, status *storage.EtcdMaintenanceStatus) error {
+	revision := status.Revision - r.RetainedRevisions
+	if revision-status.CompactedRevision < r.RetainedRevisions {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+	_, err := r.Client.Compact(ctx, revision, clientv3.WithCompactPhysical())
+	if err != nil && err != rpctypes.ErrCompacted {
+		return trace.Wrap(err)
+	}
+	r.Infof("Compacted etcd keyspace to revision %v.", revision)
+	status.CompactedRevision = revision
+	status.LastCompaction = r.Clock.Now().UTC()
+	return nil
+}
+
+func (r *Maintainer) needsDefragmentation(member storage.EtcdMemberMaintenanceStatus) bool {
+	if member.DBSize < r.DefragThreshold {
+		return false
+	}
+	return r.Clock.Now().Sub(member.LastDefragmentation) >= r.DefragInterval
+}
+
+func (r *Maintainer) defragment(ctx context.Context, member *storage.EtcdMemberMaintenanceStatus) error {
+	r.Infof("Defragmenting etcd member %v with database size %v.",
+		member.Name, humanize.Bytes(uint64(member.DBSize)))
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+	if _, err := r.Client.Defragment(ctx, member.Endpoint); err != nil {
+		return trace.Wrap(err)
+	}
+	member.LastDefragmentation = r.Clock.Now().UTC()
+	resp, err := endpointStatus(ctx, r.Client, member.Endpoint, r.Timeout)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	member.ReclaimedBytes = member.DBSize - resp.DbSize
+	member.DBSize = resp.DbSize
+	r.Infof("Defragmented etcd member %v, reclaimed %v.",
+		member.Name, humanize.Bytes(uint64(member.ReclaimedBytes)))
+	return nil
+}
+
+func (r *Maintainer) listMembers(ctx context.Context) ([]clientv3.Member, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+	return listMembers(ctx, r.Client)
+}
+
+// listMembers returns the etcd members that have started
+func listMembers(ctx context.Context, client memberLister) ([]clientv3.Member, error) {
+	resp, err := client.MemberList(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var members []clientv3.Member
+	for _, member := range resp.Members {
+		// Skip members that have not started yet
+		if member.Name == "" || len(member.ClientURLs) == 0 {
+			continue
+		}
+		members = append(members, clientv3.Member(*member))
+	}
+	return members, nil
+}
+
+// endpointStatus returns the status of the member with the specified endpoint
+func endpointStatus(ctx context.Context, client statusGetter, endpoint string, timeout time.Duration) (*clientv3.StatusResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	resp, err := client.Status(ctx, endpoint)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/keyval"
+
+	"github.com/coreos/etcd/clientv3"
+	pb "github.com/coreos/etcd/etcdserver/etcdserverpb"
+	"github.com/jonboulle/clockwork"
+	. "gopkg.in/check.v1"
+)
+
+func TestEtcd(t *testing.T) { TestingT(t) }
+
+type MaintainerSuite struct {
+	backend storage.Backend
+	client  *fakeClient
+	clock   clockwork.FakeClock
+}
+
+var _ = Suite(&MaintainerSuite{})
+
+func (s *MaintainerSuite) SetUpTest(c *C) {
+	var err error
+	s.backend, err = keyval.NewBolt(keyval.BoltConfig{Path: filepath.Join(c.MkDir(), "bolt.db")})
+	c.Assert(err, IsNil)
+	s.clock = clockwork.NewFakeClock()
+	s.client = &fakeClient{
+		revision: 25000,
+		sizes: map[string]int64{
+			"https://node-1:2379": 1000,
+			"https://node-2:2379": 100,
+		},
+		members: []*pb.Member{
+			{Name: "node-1", ClientURLs: []string{"https://node-1:2379"}},
+			{Name: "node-2", ClientURLs: []string{"https://node-2:2379"}},
+			// not started yet
+			{PeerURLs: []string{"https://node-3:2380"}},
+		},
+	}
+}
+
+func (s *MaintainerSuite) TearDownTest(c *C) {
+	c.Assert(s.backend.Close(), IsNil)
+}
+
+func (s *MaintainerSuite) TestCompactsAndDefragments(c *C) {
+	maintainer := s.newMaintainer(c)
+	c.Assert(maintainer.Maintain(context.TODO()), IsNil)
+	c.Assert(s.client.compacted, DeepEquals, []int64{15000})
+	c.Assert(s.client.defragmented, DeepEquals, []string{"https://node-1:2379"})
+
+	status, err := s.backend.GetEtcdMaintenanceStatus("example.com")
+	c.Assert(err, IsNil)
+	c.Assert(status.IsHealthy(), Equals, true)
+	c.Assert(status.Revision, Equals, int64(25000))
+	c.Assert(status.CompactedRevision, Equals, int64(15000))
+	c.Assert(status.Members, DeepEquals, []storage.EtcdMemberMaintenanceStatus{
+		{
+			Name:                "node-1",
+			Endpoint:            "https://node-1:2379",
+			DBSize:              200,
+			LastDefragmentation: s.clock.Now().UTC(),
+			ReclaimedBytes:      800,
+		},
+		{
+			Name:     "node-2",
+			Endpoint: "https://node-2:2379",
+			DBSize:   100,
+		},
+	})
+
+	// Neither compaction nor defragmentation are repeated until
+	// the thresholds are exceeded again
+	s.client.revision = 26000
+	s.client.sizes["https://node-1:2379"] = 1000
+	s.clock.Advance(time.Hour)
+	c.Assert(maintainer.Maintain(context.TODO()), IsNil)
+	c.Assert(s.client.compacted, DeepEquals, []int64{15000})
+	c.Assert(s.client.defragmented, DeepEquals, []string{"https://node-1:2379"})
+
+	s.client.revision = 40000
+	s.clock.Advance(24 * time.Hour)
+	c.Assert(maintainer.Maintain(context.TODO()), IsNil)
+	c.Assert(s.client.compacted, DeepEquals, []int64{15000, 30000})
+	c.Assert(s.client.defragmented, DeepEquals, []string{"https://node-1:2379", "https://node-1:2379"})
+}
+
+func (s *MaintainerSuite) TestRecordsFailures(c *C) {
+	s.client.defragErr = errors.New("defragmentation failed")
+	maintainer := s.newMaintainer(c)
+	c.Assert(maintainer.Maintain(context.TODO()), IsNil)
+
+	status, err := s.backend.GetEtcdMaintenanceStatus("example.com")
+	c.Assert(err, IsNil)
+	c.Assert(status.IsHealthy(), Equals, false)
+	member := status.FindMember("node-1")
+	c.Assert(member, NotNil)
+	c.Assert(member.Message, Matches, ".*defragmentation failed.*")
+	c.Assert(member.LastDefragmentation.IsZero(), Equals, true)
+
+	s.client.membersErr = errors.New("etcd is unavailable")
+	c.Assert(maintainer.Maintain(context.TODO()), NotNil)
+	status, err = s.backend.GetEtcdMaintenanceStatus("example.com")
+	c.Assert(err, IsNil)
+	c.Assert(status.Message, Matches, ".*etcd is unavailable.*")
+	// The last compaction is retained
+	c.Assert(status.CompactedRevision, Equals, int64(15000))
+}
+
+func (s *MaintainerSuite) newMaintainer(c *C) *Maintainer {
+	maintainer, err := NewMaintainer(MaintainerConfig{
+		Client:            s.client,
+		Backend:           s.backend,
+		ClusterName:       "example.com",
+		RetainedRevisions: 10000,
+		DefragThreshold:   500,
+		DefragInterval:    24 * time.Hour,
+		Clock:             s.clock,
+	})
+	c.Assert(err, IsNil)
+	return maintainer
+}
+
+type fakeClient struct {
+	members      []*pb.Member
+	membersErr   error
+	revision     int64
+	sizes        map[string]int64
+	defragErr    error
+	compacted    []int64
+	defragmented []string
+}
+
+func (r *fakeClient) MemberList(context.Context) (*clientv3.MemberListResponse, error) {
+	if r.membersErr != nil {
+		return nil, r.membersErr
+	}
+	return &clientv3.MemberListResponse{Members: r.members}, nil
+}
+
+func (r *fakeClient) Status(_ context.Context, endpoint string) (*clientv3.StatusResponse, error) {
+	return &clientv3.StatusResponse{
+		Header: &pb.ResponseHeader{Revision: r.revision},
+		DbSize: r.sizes[endpoint],
+	}, nil
+}
+
+func (r *fakeClient) Defragment(_ context.Context, endpoint string) (*clientv3.DefragmentResponse, error) {
+	if r.defragErr != nil {
+		return nil, r.defragErr
+	}
+	r.defragmented = append(r.defragmented, endpoint)
+	r.sizes[endpoint] = r.sizes[endpoint] / 5
+	return &clientv3.DefragmentResponse{}, nil
+}
+
+func (r *fakeClient) Compact(_ context.Context, rev int64, _ ...clientv3.CompactOption) (*clientv3.CompactResponse, error) {
+	r.compacted = append(r.compacted, rev)
+	return &clientv3.CompactResponse{}, nil
+}
@@ -25,6 +25,7 @@ import (
 	rpcserver "github.com/gravitational/gravity/lib/rpc/server"
 	"github.com/gravitational/gravity/lib/state"
 	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/system/disklayout"
 	"github.com/gravitational/gravity/lib/system/environ"
 	log "github.com/sirupsen/logrus"
 
@@ -47,7 +48,11 @@ func (p *Peer) initEnviron(ctx operationContext) error {
 	if err := p.logIntoPeer(); err != nil {
 		return trace.Wrap(err)
 	}
-	if err := p.configureStateDirectory(); err != nil {
+	if p.AutoPartition || p.EtcdDevice != "" {
+		if err := p.configureDiskLayout(ctx); err != nil {
+			return trace.Wrap(err)
+		}
+	} else if err := p.configureStateDirectory(); err != nil {
 		return trace.Wrap(err)
 	}
 	if err := p.ensureServiceUserAndBinary(ctx); err != nil {
@@ -96,6 +101,36 @@ func (p *Peer) configureStateDirectory() error {
 	return nil
 }
 
+// configureDiskLayout plans the disk layout of this node for the profile
+// of the joining node and configures the local gravity state directory
+// and etcd data directory accordingly
+func (p *Peer) configureDiskLayout(ctx operationContext) error {
+	layout, err := install.PlanDiskLayout(install.DiskLayoutConfig{
+		Manifest:      ctx.Cluster.App.Manifest,
+		Profile:       p.Role,
+		Docker:        ctx.Cluster.ClusterState.Docker,
+		SystemDevice:  p.SystemDevice,
+		DockerDevice:  p.DockerDevice,
+		EtcdDevice:    p.EtcdDevice,
+		AutoPartition: p.AutoPartition,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	p.WithField("layout", layout.String()).Info("Planned disk layout.")
+	stateDir, err := state.GetStateDir()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = environ.ConfigureDiskLayout(stateDir, *layout, p.FieldLogger)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	p.SystemDevice = layout.DevicePath(disklayout.RoleSystem)
+	p.DockerDevice = layout.DevicePath(disklayout.RoleDocker)
+	return nil
+}
+
 // ensureServiceUserAndBinary makes sure specified service user exists and installs gravity binary
 func (p *Peer) ensureServiceUserAndBinary(ctx operationContext) error {
 	_, err := install.EnsureServiceUserAndBinary(ctx.Cluster.ServiceUser.UID, ctx.Cluster.ServiceUser.GID)
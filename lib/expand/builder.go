@@ -317,10 +317,6 @@ func (p *Peer) getPlanBuilder(ctx operationContext) (*planBuilder, error) {
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
-	planetPackage, err := application.Manifest.RuntimePackageForProfile(p.Role)
-	if err != nil {
-		return nil, trace.Wrap(err)
-	}
 	adminAgent, err := ctx.Operator.GetClusterAgent(ops.ClusterAgentRequest{
 		AccountID:   ctx.Operation.AccountID,
 		ClusterName: ctx.Operation.SiteDomain,
@@ -344,6 +340,11 @@ func (p *Peer) getPlanBuilder(ctx operationContext) (*planBuilder, error) {
 		return nil, trace.NotFound("operation does not have servers: %v",
 			operation)
 	}
+	planetPackage, err := application.Manifest.RuntimePackageForServer(
+		p.Role, operation.Servers[0].GetArch())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
 	return &planBuilder{
 		Application:     *application,
 		Runtime:         *runtime,
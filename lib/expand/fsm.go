@@ -163,7 +163,7 @@ func (e *fsmEngine) ChangePhaseState(ctx context.Context, change fsm.StateChange
 		Error:       utils.ToRawTrace(change.Error),
 		Created:     time.Now().UTC(),
 	}
-	_, err := e.JoinBackend.CreateOperationPlanChange(planChange)
+	_, err := storage.AppendOperationPlanChange(e.JoinBackend, planChange)
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -49,6 +49,7 @@ import (
 	rpcserver "github.com/gravitational/gravity/lib/rpc/server"
 	"github.com/gravitational/gravity/lib/schema"
 	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/clusterconfig"
 	"github.com/gravitational/gravity/lib/utils"
 
 	"github.com/cenkalti/backoff"
@@ -292,6 +293,11 @@ type PeerConfig struct {
 	// SkipWizard specifies to the peer agents that the peer is not a wizard
 	// and attempts to contact the wizard should be skipped
 	SkipWizard bool
+	// EtcdDevice is the optional device for etcd data
+	EtcdDevice string
+	// AutoPartition enables automatic placement of system data,
+	// Docker storage and etcd data on unused devices
+	AutoPartition bool
 }
 
 // CheckAndSetDefaults checks the parameters and autodetects some defaults
@@ -595,7 +601,7 @@ func (p *Peer) dialWizard(addr string) (*operationContext, error) {
 		Creds:     *creds,
 	}
 	if shouldRunLocalChecks(ctx) {
-		err = p.runLocalChecks(*cluster, *operation)
+		err = p.runLocalChecks(env.Operator, *cluster, *operation)
 		if err != nil {
 			return nil, utils.Abort(err)
 		}
@@ -716,20 +722,31 @@ func (p *Peer) runLocalChecksExpand(operator ops.Operator, cluster ops.Site) err
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	return p.runLocalChecks(cluster, *installOperation)
+	return p.runLocalChecks(operator, cluster, *installOperation)
 }
 
-func (p *Peer) runLocalChecks(cluster ops.Site, installOperation ops.SiteOperation) error {
+func (p *Peer) runLocalChecks(operator ops.Operator, cluster ops.Site, installOperation ops.SiteOperation) error {
+	var resourcePressure *clusterconfig.ResourcePressure
+	config, err := operator.GetClusterConfiguration(cluster.Key())
+	if err != nil {
+		p.WithError(err).Warn("Failed to query cluster configuration, will use default resource pressure thresholds.")
+	} else {
+		thresholds := config.GetResourcePressure()
+		resourcePressure = &thresholds
+	}
 	return checks.RunLocalChecks(p.ctx, checks.LocalChecksRequest{
-		Manifest: cluster.App.Manifest,
-		Role:     p.Role,
-		Docker:   cluster.ClusterState.Docker,
+		Manifest:      cluster.App.Manifest,
+		Role:          p.Role,
+		Docker:        cluster.ClusterState.Docker,
+		CloudProvider: cluster.Provider,
 		Options: &validationpb.ValidateOptions{
-			VxlanPort: int32(installOperation.GetVars().OnPrem.VxlanPort),
-			DnsAddrs:  cluster.DNSConfig.Addrs,
-			DnsPort:   int32(cluster.DNSConfig.Port),
+			VxlanPort:     int32(installOperation.GetVars().OnPrem.VxlanPort),
+			DnsAddrs:      cluster.DNSConfig.Addrs,
+			DnsPort:       int32(cluster.DNSConfig.Port),
+			WireguardPort: int32(installOperation.GetVars().OnPrem.WireguardPort()),
 		},
-		AutoFix: true,
+		ResourcePressure: resourcePressure,
+		AutoFix:          true,
 	})
 }
 
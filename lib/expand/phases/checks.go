@@ -80,6 +80,7 @@ func (p *checksExecutor) Execute(ctx context.Context) error {
 		Requirements: reqs,
 		Features: checks.Features{
 			TestEtcdDisk: true,
+			TestDisks:    true,
 		},
 	})
 	if err != nil {
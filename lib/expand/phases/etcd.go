@@ -98,7 +98,7 @@ type etcdExecutor struct {
 func (p *etcdExecutor) Execute(ctx context.Context) error {
 	p.Progress.NextStep("Adding etcd member")
 	member, err := p.Etcd.Add(ctx, fmt.Sprintf("https://%v:%v",
-		p.Phase.Data.Server.AdvertiseIP, defaults.EtcdPeerPort))
+		p.Phase.Data.Server.GetClusterAddr(), defaults.EtcdPeerPort))
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -145,6 +145,15 @@ func (p *waitK8sExecutor) Execute(ctx context.Context) error {
 		return trace.Wrap(err)
 	}
 	p.Info("Node has registered with Kubernetes cluster.")
+	server := *p.Phase.Data.Server
+	if len(server.Labels) != 0 || len(server.Taints) != 0 {
+		p.Progress.NextStep("Applying custom node labels and taints")
+		err = kubeutils.ApplyServerLabelsAndTaints(ctx, p.Client, server)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		p.Info("Applied custom node labels and taints.")
+	}
 	return nil
 }
 
@@ -0,0 +1,194 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roster
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/rpc"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+)
+
+// JoinerConfig describes the configuration of the node joiner
+type JoinerConfig struct {
+	// PortalURL is the URL of the cluster portal serving the join instructions
+	PortalURL string
+	// Token is the token authorizing the nodes to join the cluster
+	Token string
+	// Agents provides access to the agents running on the nodes.
+	// Only required if some of the nodes are joined with pre-started agents
+	Agents rpc.AgentRepository
+	// FieldLogger is used for logging
+	log.FieldLogger
+}
+
+// CheckAndSetDefaults validates the configuration and sets defaults
+func (r *JoinerConfig) CheckAndSetDefaults() error {
+	if r.PortalURL == "" {
+		return trace.BadParameter("cluster portal URL is required")
+	}
+	if r.Token == "" {
+		return trace.BadParameter("join token is required")
+	}
+	if r.FieldLogger == nil {
+		r.FieldLogger = log.WithField(trace.Component, "roster")
+	}
+	return nil
+}
+
+// NewJoiner returns a new joiner that downloads and executes the cluster
+// join instructions on the nodes either over SSH or using the agent
+// already running on the node
+func NewJoiner(config JoinerConfig) (*joiner, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &joiner{JoinerConfig: config}, nil
+}
+
+// Join executes the join instructions on the specified node
+func (r *joiner) Join(ctx context.Context, node Node) error {
+	command := JoinCommand(r.PortalURL, r.Token, node)
+	logger := r.WithField("node", node)
+	if node.Agent {
+		return trace.Wrap(r.joinWithAgent(ctx, node, command, logger))
+	}
+	return trace.Wrap(r.joinWithSSH(ctx, node, command, logger))
+}
+
+// TokenIssuer issues short-lived cluster join tokens
+type TokenIssuer interface {
+	// CreateJoinToken creates a new short-lived cluster join token
+	CreateJoinToken(context.Context, ops.CreateJoinTokenRequest) (*storage.ProvisioningToken, error)
+}
+
+// NewJoinToken issues a short-lived join token that only lets the specified
+// nodes join the cluster: the token is limited to the roles and the number
+// of the nodes
+func NewJoinToken(ctx context.Context, issuer TokenIssuer, key ops.SiteKey, nodes []Node) (string, error) {
+	roles := make(map[string]struct{})
+	for _, node := range nodes {
+		roles[node.Role] = struct{}{}
+	}
+	req := ops.CreateJoinTokenRequest{
+		SiteKey: key,
+		TTL:     defaults.InternalJoinTokenTTL,
+		MaxUses: len(nodes),
+	}
+	for role := range roles {
+		req.Roles = append(req.Roles, role)
+	}
+	sort.Strings(req.Roles)
+	token, err := issuer.CreateJoinToken(ctx, req)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return token.Token, nil
+}
+
+// JoinCommand returns the shell command that downloads the join instructions
+// for the specified node from the cluster portal and executes them
+func JoinCommand(portalURL, token string, node Node) string {
+	query := url.Values{schema.AdvertiseAddr: []string{node.Addr}}
+	instructionsURL := fmt.Sprintf("%v?%v",
+		strings.Join([]string{portalURL, "t", token, node.Role}, "/"), query.Encode())
+	return fmt.Sprintf(`curl -s -f --tlsv1.2 -0 -k "%v" -o %v && bash %[2]v`,
+		instructionsURL, joinScriptPath)
+}
+
+func (r *joiner) joinWithAgent(ctx context.Context, node Node, command string, logger log.FieldLogger) error {
+	if r.Agents == nil {
+		return trace.BadParameter("node %v requires an agent but no agent credentials are available", node.Addr)
+	}
+	client, err := r.Agents.GetClient(ctx, node.Addr)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = client.Command(ctx, logger, utils.NopWriteCloser(ioutil.Discard), "bash", "-c", command)
+	return trace.Wrap(err)
+}
+
+func (r *joiner) joinWithSSH(ctx context.Context, node Node, command string, logger log.FieldLogger) error {
+	config, err := sshClientConfig(*node.SSH)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	client, err := ssh.Dial("tcp", net.JoinHostPort(node.Addr, strconv.Itoa(node.SSH.Port)), config)
+	if err != nil {
+		return trace.Wrap(err, "failed to connect to %v over SSH", node.Addr)
+	}
+	defer client.Close()
+	if node.SSH.User != defaults.SSHUser {
+		command = fmt.Sprintf("sudo -n bash -c '%v'", command)
+	}
+	err = utils.NewSSHCommands(client).
+		C(command).
+		WithLogger(logger).
+		Run(ctx)
+	return trace.Wrap(err)
+}
+
+func sshClientConfig(creds SSHCredentials) (*ssh.ClientConfig, error) {
+	keyBytes := creds.PrivateKey
+	if len(keyBytes) == 0 {
+		var err error
+		keyBytes, err = ioutil.ReadFile(creds.PrivateKeyPath)
+		if err != nil {
+			return nil, trace.ConvertSystemError(err)
+		}
+	}
+	signer, err := ssh.ParsePrivateKey(keyBytes)
+	if err != nil {
+		return nil, trace.BadParameter("failed to parse SSH private key: %v", err)
+	}
+	hostKeyCallback := ssh.InsecureIgnoreHostKey()
+	if creds.HostKey != "" {
+		hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(creds.HostKey))
+		if err != nil {
+			return nil, trace.BadParameter("failed to parse SSH host key: %v", err)
+		}
+		hostKeyCallback = ssh.FixedHostKey(hostKey)
+	}
+	return &ssh.ClientConfig{
+		User:            creds.User,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         defaults.DialTimeout,
+	}, nil
+}
+
+type joiner struct {
+	JoinerConfig
+}
+
+// joinScriptPath is the path on the node to download the join instructions to
+const joinScriptPath = "/tmp/gravity-join.sh"
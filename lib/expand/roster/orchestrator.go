@@ -0,0 +1,175 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roster
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// Joiner joins a single node to the cluster
+type Joiner interface {
+	// Join executes the join on the specified node and blocks
+	// until the node has joined the cluster
+	Join(ctx context.Context, node Node) error
+}
+
+// Config describes the configuration of a bulk join
+type Config struct {
+	// Nodes lists the nodes to join
+	Nodes []Node
+	// Parallel is the maximum number of nodes joining concurrently.
+	// Defaults to defaults.MaxExpandConcurrency
+	Parallel int
+	// IsMaster returns true if the nodes with the specified role join as masters.
+	// Master nodes are joined one at a time before the regular nodes
+	IsMaster func(role string) bool
+	// Joiner executes the join on a single node
+	Joiner Joiner
+	// Printer outputs the progress
+	utils.Printer
+	// FieldLogger is used for logging
+	log.FieldLogger
+}
+
+// CheckAndSetDefaults validates the configuration and sets defaults
+func (r *Config) CheckAndSetDefaults() error {
+	if len(r.Nodes) == 0 {
+		return trace.BadParameter("at least one node is required")
+	}
+	if r.Joiner == nil {
+		return trace.BadParameter("node joiner is required")
+	}
+	if r.Parallel < 0 {
+		return trace.BadParameter("parallelism should be positive")
+	}
+	if r.Parallel == 0 || r.Parallel > defaults.MaxExpandConcurrency {
+		r.Parallel = defaults.MaxExpandConcurrency
+	}
+	if r.IsMaster == nil {
+		r.IsMaster = func(string) bool { return false }
+	}
+	if r.Printer == nil {
+		r.Printer = utils.DiscardPrinter
+	}
+	if r.FieldLogger == nil {
+		r.FieldLogger = log.WithField(trace.Component, "roster")
+	}
+	return nil
+}
+
+// JoinResult describes the outcome of joining a single node
+type JoinResult struct {
+	// Node is the node that was joined
+	Node Node
+	// Error is the error joining the node, nil if the node has joined successfully
+	Error error
+}
+
+// Join joins the nodes from the specified configuration to the cluster.
+// Master nodes are joined sequentially as the cluster only allows a single
+// master to join at a time, the regular nodes are joined concurrently.
+// Failure to join a node does not abort joining the remaining nodes.
+// Returns the results for all nodes in the order of completion and an error
+// if any of the nodes has failed to join
+func Join(ctx context.Context, config Config) (results []JoinResult, err error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	masters, nodes := splitNodes(config.Nodes, config.IsMaster)
+	p := &progress{Config: config, total: len(config.Nodes)}
+	for _, node := range masters {
+		if ctx.Err() != nil {
+			break
+		}
+		p.join(ctx, node)
+	}
+	sem := make(chan struct{}, config.Parallel)
+	var wg sync.WaitGroup
+	for _, node := range nodes {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(node Node) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			p.join(ctx, node)
+		}(node)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return p.results, trace.Wrap(ctx.Err())
+	}
+	var failed []string
+	for _, result := range p.results {
+		if result.Error != nil {
+			failed = append(failed, result.Node.Addr)
+		}
+	}
+	if len(failed) != 0 {
+		return p.results, trace.BadParameter("%v of %v nodes failed to join: %v",
+			len(failed), len(config.Nodes), failed)
+	}
+	return p.results, nil
+}
+
+func (r *progress) join(ctx context.Context, node Node) {
+	r.PrintStep("Joining %v node %v", node.Role, node.Addr)
+	err := r.Joiner.Join(ctx, node)
+	r.Lock()
+	defer r.Unlock()
+	r.results = append(r.results, JoinResult{Node: node, Error: err})
+	if err != nil {
+		r.WithError(err).WithField("node", node).Warn("Failed to join node.")
+		r.PrintStep("[%v/%v] Node %v failed to join: %v", len(r.results), r.total,
+			node.Addr, trace.UserMessage(err))
+		return
+	}
+	r.PrintStep("[%v/%v] Node %v has joined the cluster", len(r.results), r.total, node.Addr)
+}
+
+func splitNodes(nodes []Node, isMaster func(string) bool) (masters, regular []Node) {
+	for _, node := range nodes {
+		if isMaster(node.Role) {
+			masters = append(masters, node)
+		} else {
+			regular = append(regular, node)
+		}
+	}
+	return masters, regular
+}
+
+// progress tracks the results of the bulk join
+type progress struct {
+	Config
+	sync.Mutex
+	total   int
+	results []JoinResult
+}
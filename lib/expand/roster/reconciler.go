@@ -0,0 +1,387 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roster
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/events"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/cenkalti/backoff"
+	"github.com/gravitational/rigging"
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ReconcilerConfig describes the configuration of the cluster roster reconciler
+type ReconcilerConfig struct {
+	// Operator is the cluster operator service
+	Operator ops.Operator
+	// Backend stores the cluster roster and the reconciliation status
+	Backend storage.ClusterRosters
+	// Client is the Kubernetes client used to read the SSH private key secret
+	Client kubernetes.Interface
+	// GetPrivateKey returns the SSH private key from the secret with the specified name.
+	// Defaults to reading the secret from the kube-system namespace
+	GetPrivateKey func(secret string) ([]byte, error)
+	// NewJoiner returns a new joiner for the specified cluster portal URL and join token.
+	// Defaults to the joiner connecting to the nodes over SSH
+	NewJoiner func(portalURL, token string) (Joiner, error)
+	// Interval is the reconciliation interval
+	Interval time.Duration
+	// Clock is used to timestamp the reconciliation status
+	Clock clockwork.Clock
+	// FieldLogger is used for logging
+	log.FieldLogger
+}
+
+// CheckAndSetDefaults validates the configuration and sets defaults
+func (r *ReconcilerConfig) CheckAndSetDefaults() error {
+	if r.Operator == nil {
+		return trace.BadParameter("operator service is required")
+	}
+	if r.Backend == nil {
+		return trace.BadParameter("backend is required")
+	}
+	if r.GetPrivateKey == nil {
+		if r.Client == nil {
+			return trace.BadParameter("Kubernetes client is required")
+		}
+		r.GetPrivateKey = r.getPrivateKey
+	}
+	if r.NewJoiner == nil {
+		r.NewJoiner = func(portalURL, token string) (Joiner, error) {
+			return NewJoiner(JoinerConfig{
+				PortalURL:   portalURL,
+				Token:       token,
+				FieldLogger: r.FieldLogger,
+			})
+		}
+	}
+	if r.Interval == 0 {
+		r.Interval = defaults.RosterReconcileInterval
+	}
+	if r.Clock == nil {
+		r.Clock = clockwork.NewRealClock()
+	}
+	if r.FieldLogger == nil {
+		r.FieldLogger = log.WithField(trace.Component, "roster-reconciler")
+	}
+	return nil
+}
+
+// NewReconciler returns a new reconciler that keeps the cluster membership
+// in sync with the cluster roster
+func NewReconciler(config ReconcilerConfig) (*Reconciler, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &Reconciler{ReconcilerConfig: config}, nil
+}
+
+// Reconciler periodically compares the cluster membership with the cluster
+// roster and joins the missing nodes and removes the nodes not in the roster.
+// With the manual approval policy, the changes are only applied once they have
+// been approved
+type Reconciler struct {
+	ReconcilerConfig
+}
+
+// Run reconciles the cluster membership until the context is canceled
+func (r *Reconciler) Run(ctx context.Context) {
+	r.Info("Starting cluster roster reconciler.")
+	ticker := r.Clock.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.Chan():
+			if err := r.Reconcile(ctx); err != nil {
+				r.WithError(err).Warn("Failed to reconcile cluster roster.")
+			}
+		case <-ctx.Done():
+			r.Info("Stopping cluster roster reconciler.")
+			return
+		}
+	}
+}
+
+// Reconcile executes a single reconciliation pass
+func (r *Reconciler) Reconcile(ctx context.Context) error {
+	cluster, err := r.Operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roster, err := r.Backend.GetClusterRoster(cluster.Domain)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil
+		}
+		return trace.Wrap(err)
+	}
+	status, err := r.Backend.GetClusterRosterStatus(cluster.Domain)
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	if status == nil {
+		status = &storage.ClusterRosterStatus{}
+	}
+	changes := Diff(roster, cluster.ClusterState.Servers)
+	if len(changes) == 0 {
+		return trace.Wrap(r.updateStatus(cluster.Domain, storage.ClusterRosterStatus{
+			Message: "Cluster is in sync with the roster.",
+		}))
+	}
+	changesID := ChangesID(changes)
+	notified := status.ChangesID == changesID && status.Message == pendingApprovalMessage(changesID)
+	if status.ChangesID != changesID {
+		status.ApprovedID = ""
+	}
+	status.Changes = changes
+	status.ChangesID = changesID
+	if err := checkMasters(changes, cluster.ClusterState.Servers); err != nil {
+		status.Message = trace.UserMessage(err)
+		if err := r.updateStatus(cluster.Domain, *status); err != nil {
+			return trace.Wrap(err)
+		}
+		return trace.Wrap(err)
+	}
+	if cluster.State != ops.SiteStateActive {
+		status.Message = fmt.Sprintf("Waiting for the cluster to become active, the cluster is %v.",
+			cluster.State)
+		return trace.Wrap(r.updateStatus(cluster.Domain, *status))
+	}
+	if roster.GetApproval() == storage.RosterApprovalManual && status.ApprovedID != changesID {
+		if !notified {
+			r.Infof("Cluster roster changes %v await approval: %v.", changesID, changes)
+			events.Emit(ctx, r.Operator, events.ClusterRosterChangesPending, events.Fields{
+				events.FieldName:  changesID,
+				events.FieldCount: len(changes),
+			})
+		}
+		status.Message = pendingApprovalMessage(changesID)
+		return trace.Wrap(r.updateStatus(cluster.Domain, *status))
+	}
+	status.Message = fmt.Sprintf("Applying changes %v.", changesID)
+	if err := r.updateStatus(cluster.Domain, *status); err != nil {
+		return trace.Wrap(err)
+	}
+	status.Message = fmt.Sprintf("Applied changes %v.", changesID)
+	err = r.apply(ctx, *cluster, roster, changes)
+	if err != nil {
+		status.Message = fmt.Sprintf("Failed to apply changes %v: %v.", changesID, trace.UserMessage(err))
+	}
+	if err := r.updateStatus(cluster.Domain, *status); err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(err)
+}
+
+// apply joins the missing nodes to the cluster and then removes
+// the nodes not in the roster one at a time
+func (r *Reconciler) apply(ctx context.Context, cluster ops.Site, roster storage.ClusterRoster, changes []storage.RosterChange) error {
+	var nodes []Node
+	var removals []storage.RosterChange
+	for _, change := range changes {
+		switch change.Action {
+		case storage.RosterActionAdd:
+			nodes = append(nodes, Node{Addr: change.Addr, Role: change.Role})
+		case storage.RosterActionRemove:
+			removals = append(removals, change)
+		}
+	}
+	if len(nodes) != 0 {
+		if err := r.join(ctx, cluster, roster, nodes); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	for _, change := range removals {
+		r.Infof("Remove node %v.", change.Addr)
+		if err := r.remove(ctx, cluster.Key(), change.Hostname); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+func (r *Reconciler) join(ctx context.Context, cluster ops.Site, roster storage.ClusterRoster, nodes []Node) error {
+	privateKey, err := r.GetPrivateKey(roster.GetSSH().Secret)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	hostKeys := make(map[string]string, len(roster.GetNodes()))
+	for _, node := range roster.GetNodes() {
+		hostKeys[node.Addr] = node.HostKey
+	}
+	for i := range nodes {
+		nodes[i].SSH = &SSHCredentials{
+			User:       roster.GetSSH().User,
+			Port:       roster.GetSSH().Port,
+			PrivateKey: privateKey,
+			HostKey:    hostKeys[nodes[i].Addr],
+		}
+	}
+	masters := cluster.ClusterState.Servers.Masters()
+	if len(masters) == 0 {
+		return trace.NotFound("cluster %v has no master nodes", cluster.Domain)
+	}
+	token, err := NewJoinToken(ctx, r.Operator, cluster.Key(), nodes)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	joiner, err := r.NewJoiner(fmt.Sprintf("https://%v:%v/portal/v1",
+		masters[0].AdvertiseIP, defaults.GravitySiteNodePort), token)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = Join(ctx, Config{
+		Nodes: nodes,
+		IsMaster: func(role string) bool {
+			profile, err := cluster.App.Manifest.NodeProfiles.ByName(role)
+			return err == nil && profile.ServiceRole == schema.ServiceRoleMaster
+		},
+		Joiner:      joiner,
+		FieldLogger: r.FieldLogger,
+	})
+	return trace.Wrap(err)
+}
+
+// remove removes the node with the specified hostname from the cluster
+// and waits for the shrink operation to complete
+func (r *Reconciler) remove(ctx context.Context, key ops.SiteKey, hostname string) error {
+	operationKey, err := r.Operator.CreateSiteShrinkOperation(ctx, ops.CreateSiteShrinkOperationRequest{
+		AccountID:  key.AccountID,
+		SiteDomain: key.SiteDomain,
+		Servers:    []string{hostname},
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = utils.RetryWithInterval(ctx, backoff.NewConstantBackOff(defaults.RetryInterval), func() error {
+		operation, err := r.Operator.GetSiteOperation(*operationKey)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		switch {
+		case operation.IsCompleted():
+			return nil
+		case operation.IsFailed():
+			return &backoff.PermanentError{Err: trace.BadParameter(
+				"failed to remove node %v", hostname)}
+		}
+		return trace.Retry(nil, "node %v is being removed", hostname)
+	})
+	return trace.Wrap(err)
+}
+
+// getPrivateKey returns the SSH private key from the specified secret
+func (r *ReconcilerConfig) getPrivateKey(name string) ([]byte, error) {
+	secret, err := r.Client.CoreV1().Secrets(defaults.KubeSystemNamespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, trace.Wrap(rigging.ConvertError(err))
+	}
+	privateKey, ok := secret.Data[v1.SSHAuthPrivateKey]
+	if !ok {
+		return nil, trace.NotFound("secret %v has no %v key", name, v1.SSHAuthPrivateKey)
+	}
+	return privateKey, nil
+}
+
+func (r *Reconciler) updateStatus(clusterName string, status storage.ClusterRosterStatus) error {
+	status.Updated = r.Clock.Now().UTC()
+	return trace.Wrap(r.Backend.UpsertClusterRosterStatus(clusterName, status))
+}
+
+// Diff returns the membership changes required to bring the cluster
+// with the specified servers in sync with the roster.
+// The nodes to add are listed before the nodes to remove
+func Diff(roster storage.ClusterRoster, servers []storage.Server) (changes []storage.RosterChange) {
+	existing := make(map[string]struct{}, len(servers))
+	for _, server := range servers {
+		existing[server.AdvertiseIP] = struct{}{}
+	}
+	desired := make(map[string]struct{}, len(roster.GetNodes()))
+	for _, node := range roster.GetNodes() {
+		desired[node.Addr] = struct{}{}
+		if _, ok := existing[node.Addr]; !ok {
+			changes = append(changes, storage.RosterChange{
+				Action: storage.RosterActionAdd,
+				Addr:   node.Addr,
+				Role:   node.Role,
+			})
+		}
+	}
+	for _, server := range servers {
+		if _, ok := desired[server.AdvertiseIP]; !ok {
+			changes = append(changes, storage.RosterChange{
+				Action:   storage.RosterActionRemove,
+				Addr:     server.AdvertiseIP,
+				Role:     server.Role,
+				Hostname: server.Hostname,
+			})
+		}
+	}
+	return changes
+}
+
+// ChangesID returns the ID that identifies the specified set of changes
+func ChangesID(changes []storage.RosterChange) string {
+	items := make([]string, 0, len(changes))
+	for _, change := range changes {
+		items = append(items, change.String())
+	}
+	hash := sha256.Sum256([]byte(strings.Join(items, ",")))
+	return hex.EncodeToString(hash[:])[:changesIDLength]
+}
+
+// checkMasters makes sure the changes do not remove all master nodes
+func checkMasters(changes []storage.RosterChange, servers storage.Servers) error {
+	removed := make(map[string]struct{})
+	for _, change := range changes {
+		if change.Action == storage.RosterActionRemove {
+			removed[change.Addr] = struct{}{}
+		}
+	}
+	for _, master := range servers.Masters() {
+		if _, ok := removed[master.AdvertiseIP]; !ok {
+			return nil
+		}
+	}
+	return trace.BadParameter("refusing to remove all master nodes, " +
+		"the roster should retain at least one of the current master nodes")
+}
+
+func pendingApprovalMessage(changesID string) string {
+	return fmt.Sprintf("Changes %v await approval, approve them with 'gravity roster approve %v'.",
+		changesID, changesID)
+}
+
+// changesIDLength is the length of the changes ID
+const changesIDLength = 12
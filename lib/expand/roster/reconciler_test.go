@@ -0,0 +1,223 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roster
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/compare"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	. "gopkg.in/check.v1"
+)
+
+type ReconcilerSuite struct{}
+
+var _ = Suite(&ReconcilerSuite{})
+
+func (s *ReconcilerSuite) TestDiff(c *C) {
+	changes := Diff(newRoster(storage.RosterApprovalManual,
+		storage.RosterNode{Addr: "10.0.0.1", Role: "master"},
+		storage.RosterNode{Addr: "10.0.0.3", Role: "node"},
+	), []storage.Server{
+		{AdvertiseIP: "10.0.0.1", Hostname: "master-1", Role: "master"},
+		{AdvertiseIP: "10.0.0.2", Hostname: "node-1", Role: "node"},
+	})
+	compare.DeepCompare(c, changes, []storage.RosterChange{
+		{Action: storage.RosterActionAdd, Addr: "10.0.0.3", Role: "node"},
+		{Action: storage.RosterActionRemove, Addr: "10.0.0.2", Role: "node", Hostname: "node-1"},
+	})
+	c.Assert(ChangesID(changes), Equals, ChangesID(changes))
+	c.Assert(ChangesID(changes), Not(Equals), ChangesID(changes[:1]))
+}
+
+func (s *ReconcilerSuite) TestRequiresApproval(c *C) {
+	r, operator, backend, joiner := newTestReconciler(c, newRoster(storage.RosterApprovalManual,
+		storage.RosterNode{Addr: "10.0.0.1", Role: "master"},
+		storage.RosterNode{Addr: "10.0.0.3", Role: "node"},
+	))
+
+	c.Assert(r.Reconcile(context.TODO()), IsNil)
+	c.Assert(joiner.order, HasLen, 0)
+	c.Assert(operator.shrinks, HasLen, 0)
+	c.Assert(backend.status.IsPendingApproval(), Equals, true)
+	c.Assert(backend.status.Changes, HasLen, 2)
+	c.Assert(operator.events, HasLen, 1)
+
+	// Pending changes are only announced once
+	c.Assert(r.Reconcile(context.TODO()), IsNil)
+	c.Assert(operator.events, HasLen, 1)
+
+	backend.status.ApprovedID = backend.status.ChangesID
+	c.Assert(r.Reconcile(context.TODO()), IsNil)
+	c.Assert(joiner.order, DeepEquals, []string{"10.0.0.3"})
+	c.Assert(operator.shrinks, DeepEquals, []string{"node-1"})
+}
+
+func (s *ReconcilerSuite) TestAppliesChangesAutomatically(c *C) {
+	r, operator, _, joiner := newTestReconciler(c, newRoster(storage.RosterApprovalAutomatic,
+		storage.RosterNode{Addr: "10.0.0.1", Role: "master"},
+		storage.RosterNode{Addr: "10.0.0.2", Role: "node"},
+		storage.RosterNode{Addr: "10.0.0.3", Role: "node"},
+	))
+	c.Assert(r.Reconcile(context.TODO()), IsNil)
+	c.Assert(joiner.order, DeepEquals, []string{"10.0.0.3"})
+	c.Assert(operator.shrinks, HasLen, 0)
+	// The nodes join with a token limited to the nodes being added
+	c.Assert(operator.tokens, HasLen, 1)
+	c.Assert(operator.tokens[0].Roles, DeepEquals, []string{"node"})
+	c.Assert(operator.tokens[0].MaxUses, Equals, 1)
+}
+
+func (s *ReconcilerSuite) TestWaitsForActiveCluster(c *C) {
+	r, operator, backend, joiner := newTestReconciler(c, newRoster(storage.RosterApprovalAutomatic,
+		storage.RosterNode{Addr: "10.0.0.1", Role: "master"},
+		storage.RosterNode{Addr: "10.0.0.3", Role: "node"},
+	))
+	operator.cluster.State = ops.SiteStateExpanding
+	c.Assert(r.Reconcile(context.TODO()), IsNil)
+	c.Assert(joiner.order, HasLen, 0)
+	c.Assert(backend.status.Changes, HasLen, 2)
+}
+
+func (s *ReconcilerSuite) TestRefusesToRemoveAllMasters(c *C) {
+	r, operator, _, _ := newTestReconciler(c, newRoster(storage.RosterApprovalAutomatic,
+		storage.RosterNode{Addr: "10.0.0.2", Role: "node"},
+	))
+	err := r.Reconcile(context.TODO())
+	c.Assert(trace.IsBadParameter(err), Equals, true, Commentf("%v", err))
+	c.Assert(operator.shrinks, HasLen, 0)
+}
+
+func newTestReconciler(c *C, roster storage.ClusterRoster) (*Reconciler, *testOperator, *testBackend, *testJoiner) {
+	operator := &testOperator{
+		cluster: ops.Site{
+			Domain: "example.com",
+			State:  ops.SiteStateActive,
+			App: ops.Application{
+				Manifest: schema.Manifest{
+					NodeProfiles: schema.NodeProfiles{
+						{Name: "master", ServiceRole: schema.ServiceRoleMaster},
+						{Name: "node", ServiceRole: schema.ServiceRoleNode},
+					},
+				},
+			},
+			ClusterState: storage.ClusterState{
+				Servers: storage.Servers{
+					{AdvertiseIP: "10.0.0.1", Hostname: "master-1", Role: "master",
+						ClusterRole: string(schema.ServiceRoleMaster)},
+					{AdvertiseIP: "10.0.0.2", Hostname: "node-1", Role: "node",
+						ClusterRole: string(schema.ServiceRoleNode)},
+				},
+			},
+		},
+	}
+	backend := &testBackend{roster: roster}
+	joiner := newTestJoiner()
+	r, err := NewReconciler(ReconcilerConfig{
+		Operator: operator,
+		Backend:  backend,
+		GetPrivateKey: func(string) ([]byte, error) {
+			return []byte("private key"), nil
+		},
+		NewJoiner: func(portalURL, token string) (Joiner, error) {
+			return joiner, nil
+		},
+		Clock: clockwork.NewFakeClock(),
+	})
+	c.Assert(err, IsNil)
+	return r, operator, backend, joiner
+}
+
+func newRoster(approval string, nodes ...storage.RosterNode) storage.ClusterRoster {
+	return storage.NewClusterRoster(storage.ClusterRosterSpecV2{
+		Nodes:    nodes,
+		SSH:      storage.RosterSSH{User: "root", Secret: "ssh-key"},
+		Approval: approval,
+	})
+}
+
+type testOperator struct {
+	ops.Operator
+	cluster ops.Site
+	shrinks []string
+	events  []ops.AuditEventRequest
+	tokens  []ops.CreateJoinTokenRequest
+}
+
+func (r *testOperator) GetLocalSite() (*ops.Site, error) {
+	cluster := r.cluster
+	return &cluster, nil
+}
+
+func (r *testOperator) CreateJoinToken(ctx context.Context, req ops.CreateJoinTokenRequest) (*storage.ProvisioningToken, error) {
+	r.tokens = append(r.tokens, req)
+	return &storage.ProvisioningToken{Token: "token", Roles: req.Roles, MaxUses: req.MaxUses}, nil
+}
+
+func (r *testOperator) CreateSiteShrinkOperation(ctx context.Context, req ops.CreateSiteShrinkOperationRequest) (*ops.SiteOperationKey, error) {
+	r.shrinks = append(r.shrinks, req.Servers...)
+	return &ops.SiteOperationKey{SiteDomain: req.SiteDomain, OperationID: "shrink"}, nil
+}
+
+func (r *testOperator) GetSiteOperation(key ops.SiteOperationKey) (*ops.SiteOperation, error) {
+	return &ops.SiteOperation{ID: key.OperationID, State: ops.OperationStateCompleted}, nil
+}
+
+func (r *testOperator) EmitAuditEvent(ctx context.Context, req ops.AuditEventRequest) error {
+	r.events = append(r.events, req)
+	return nil
+}
+
+type testBackend struct {
+	roster storage.ClusterRoster
+	status *storage.ClusterRosterStatus
+}
+
+func (r *testBackend) UpsertClusterRoster(clusterName string, roster storage.ClusterRoster) error {
+	r.roster = roster
+	return nil
+}
+
+func (r *testBackend) GetClusterRoster(clusterName string) (storage.ClusterRoster, error) {
+	if r.roster == nil {
+		return nil, trace.NotFound("cluster roster not found")
+	}
+	return r.roster, nil
+}
+
+func (r *testBackend) DeleteClusterRoster(clusterName string) error {
+	r.roster, r.status = nil, nil
+	return nil
+}
+
+func (r *testBackend) UpsertClusterRosterStatus(clusterName string, status storage.ClusterRosterStatus) error {
+	r.status = &status
+	return nil
+}
+
+func (r *testBackend) GetClusterRosterStatus(clusterName string) (*storage.ClusterRosterStatus, error) {
+	if r.status == nil {
+		return nil, trace.NotFound("cluster roster status not found")
+	}
+	status := *r.status
+	return &status, nil
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roster
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+
+	"github.com/gravitational/gravity/lib/defaults"
+
+	"github.com/ghodss/yaml"
+	"github.com/gravitational/trace"
+)
+
+// Roster lists the nodes to join to the cluster.
+//
+// Example:
+//
+//	nodes:
+//	- addr: 10.0.0.10
+//	  role: knode
+//	  ssh:
+//	    user: ubuntu
+//	    privateKeyPath: /home/ubuntu/.ssh/id_rsa
+//	- addr: 10.0.0.11
+//	  role: knode
+//	  agent: true
+type Roster struct {
+	// Nodes lists the nodes to join
+	Nodes []Node `json:"nodes"`
+}
+
+// Node describes a single node to join
+type Node struct {
+	// Addr is the IP address the node advertises to other cluster nodes.
+	// The node is also reached using this address
+	Addr string `json:"addr"`
+	// Role is the name of the node profile from the application manifest
+	Role string `json:"role"`
+	// SSH specifies the credentials to connect to the node with over SSH
+	SSH *SSHCredentials `json:"ssh,omitempty"`
+	// Agent specifies that the node is already running a Gravity agent
+	// trusted by the cluster which is used to execute the join instead of SSH
+	Agent bool `json:"agent,omitempty"`
+}
+
+// String returns a textual representation of this node
+func (r Node) String() string {
+	return fmt.Sprintf("node(addr=%v, role=%v)", r.Addr, r.Role)
+}
+
+// SSHCredentials describes how to connect to a node over SSH
+type SSHCredentials struct {
+	// User is the name of the SSH user.
+	// The user must be root or be able to run sudo without a password
+	User string `json:"user"`
+	// Port is the SSH port. Defaults to 22
+	Port int `json:"port,omitempty"`
+	// PrivateKeyPath is the path to the private key to authenticate with
+	PrivateKeyPath string `json:"privateKeyPath"`
+	// PrivateKey is the private key to authenticate with.
+	// Takes precedence over PrivateKeyPath and is never read from the roster file
+	PrivateKey []byte `json:"-"`
+	// HostKey is the optional public host key of the node in the authorized_keys format.
+	// If unspecified, the host key is not verified
+	HostKey string `json:"hostKey,omitempty"`
+}
+
+// ReadRoster reads the roster from the specified file
+func ReadRoster(path string) (*Roster, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	return ParseRoster(data)
+}
+
+// ParseRoster parses the roster from the specified YAML or JSON data
+func ParseRoster(data []byte) (*Roster, error) {
+	var roster Roster
+	if err := yaml.Unmarshal(data, &roster); err != nil {
+		return nil, trace.BadParameter("failed to parse roster: %v", err)
+	}
+	if err := roster.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &roster, nil
+}
+
+// CheckAndSetDefaults validates the roster and sets defaults
+func (r *Roster) CheckAndSetDefaults() error {
+	if len(r.Nodes) == 0 {
+		return trace.BadParameter("roster should list at least one node")
+	}
+	addrs := make(map[string]struct{}, len(r.Nodes))
+	for i := range r.Nodes {
+		if err := r.Nodes[i].CheckAndSetDefaults(); err != nil {
+			return trace.Wrap(err)
+		}
+		if _, ok := addrs[r.Nodes[i].Addr]; ok {
+			return trace.BadParameter("node %v is listed more than once", r.Nodes[i].Addr)
+		}
+		addrs[r.Nodes[i].Addr] = struct{}{}
+	}
+	return nil
+}
+
+// CheckAndSetDefaults validates the node and sets defaults
+func (r *Node) CheckAndSetDefaults() error {
+	if net.ParseIP(r.Addr) == nil {
+		return trace.BadParameter("node address %q is not a valid IP address", r.Addr)
+	}
+	if r.Role == "" {
+		return trace.BadParameter("node %v is missing role", r.Addr)
+	}
+	if r.Agent && r.SSH != nil {
+		return trace.BadParameter("node %v should specify either SSH credentials or agent, not both", r.Addr)
+	}
+	if !r.Agent && r.SSH == nil {
+		return trace.BadParameter("node %v should specify either SSH credentials or agent", r.Addr)
+	}
+	if r.SSH == nil {
+		return nil
+	}
+	if r.SSH.User == "" {
+		return trace.BadParameter("node %v is missing SSH user", r.Addr)
+	}
+	if r.SSH.PrivateKeyPath == "" && len(r.SSH.PrivateKey) == 0 {
+		return trace.BadParameter("node %v is missing SSH private key path", r.Addr)
+	}
+	if r.SSH.Port == 0 {
+		r.SSH.Port = defaults.SSHPort
+	}
+	return nil
+}
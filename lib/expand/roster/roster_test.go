@@ -0,0 +1,201 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roster
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gravitational/gravity/lib/compare"
+	"github.com/gravitational/gravity/lib/defaults"
+
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+func TestRoster(t *testing.T) { TestingT(t) }
+
+type S struct{}
+
+var _ = Suite(&S{})
+
+func (s *S) TestParsesRoster(c *C) {
+	roster, err := ParseRoster([]byte(`
+nodes:
+- addr: 10.0.0.10
+  role: master
+  ssh:
+    user: ubuntu
+    privateKeyPath: /home/ubuntu/.ssh/id_rsa
+- addr: 10.0.0.11
+  role: node
+  agent: true
+`))
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, roster, &Roster{
+		Nodes: []Node{
+			{
+				Addr: "10.0.0.10",
+				Role: "master",
+				SSH: &SSHCredentials{
+					User:           "ubuntu",
+					Port:           defaults.SSHPort,
+					PrivateKeyPath: "/home/ubuntu/.ssh/id_rsa",
+				},
+			},
+			{
+				Addr:  "10.0.0.11",
+				Role:  "node",
+				Agent: true,
+			},
+		},
+	})
+}
+
+func (s *S) TestValidatesRoster(c *C) {
+	var testCases = []struct {
+		roster  string
+		comment string
+	}{
+		{
+			roster:  `nodes: []`,
+			comment: "no nodes",
+		},
+		{
+			roster:  `nodes: [{addr: node-1, role: node, agent: true}]`,
+			comment: "invalid address",
+		},
+		{
+			roster:  `nodes: [{addr: 10.0.0.1, agent: true}]`,
+			comment: "missing role",
+		},
+		{
+			roster:  `nodes: [{addr: 10.0.0.1, role: node}]`,
+			comment: "missing credentials",
+		},
+		{
+			roster:  `nodes: [{addr: 10.0.0.1, role: node, agent: true, ssh: {user: root, privateKeyPath: /key}}]`,
+			comment: "both SSH and agent",
+		},
+		{
+			roster:  `nodes: [{addr: 10.0.0.1, role: node, ssh: {privateKeyPath: /key}}]`,
+			comment: "missing SSH user",
+		},
+		{
+			roster:  `nodes: [{addr: 10.0.0.1, role: node, agent: true}, {addr: 10.0.0.1, role: node, agent: true}]`,
+			comment: "duplicate address",
+		},
+	}
+	for _, tc := range testCases {
+		_, err := ParseRoster([]byte(tc.roster))
+		c.Assert(trace.IsBadParameter(err), Equals, true, Commentf(tc.comment))
+	}
+}
+
+func (s *S) TestJoinsMastersSequentially(c *C) {
+	joiner := newTestJoiner()
+	results, err := Join(context.TODO(), Config{
+		Nodes: []Node{
+			{Addr: "10.0.0.1", Role: "node"},
+			{Addr: "10.0.0.2", Role: "master"},
+			{Addr: "10.0.0.3", Role: "node"},
+			{Addr: "10.0.0.4", Role: "master"},
+		},
+		Parallel: 2,
+		IsMaster: func(role string) bool { return role == "master" },
+		Joiner:   joiner,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(results, HasLen, 4)
+	c.Assert(joiner.order[:2], DeepEquals, []string{"10.0.0.2", "10.0.0.4"})
+	c.Assert(joiner.maxActive <= 2, Equals, true, Commentf("%v", joiner.maxActive))
+}
+
+func (s *S) TestLimitsParallelism(c *C) {
+	joiner := newTestJoiner()
+	var nodes []Node
+	for _, addr := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5"} {
+		nodes = append(nodes, Node{Addr: addr, Role: "node"})
+	}
+	results, err := Join(context.TODO(), Config{
+		Nodes:    nodes,
+		Parallel: 3,
+		Joiner:   joiner,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(results, HasLen, 5)
+	c.Assert(joiner.maxActive <= 3, Equals, true, Commentf("%v", joiner.maxActive))
+}
+
+func (s *S) TestReportsFailedNodes(c *C) {
+	joiner := newTestJoiner()
+	joiner.failures["10.0.0.2"] = trace.ConnectionProblem(nil, "connection refused")
+	results, err := Join(context.TODO(), Config{
+		Nodes: []Node{
+			{Addr: "10.0.0.1", Role: "node"},
+			{Addr: "10.0.0.2", Role: "node"},
+			{Addr: "10.0.0.3", Role: "node"},
+		},
+		Joiner: joiner,
+	})
+	c.Assert(trace.IsBadParameter(err), Equals, true, Commentf("%v", err))
+	c.Assert(results, HasLen, 3)
+	for _, result := range results {
+		if result.Node.Addr == "10.0.0.2" {
+			c.Assert(result.Error, NotNil)
+		} else {
+			c.Assert(result.Error, IsNil)
+		}
+	}
+}
+
+func (s *S) TestFormatsJoinCommand(c *C) {
+	command := JoinCommand("https://10.0.0.1:3009/portal/v1", "token", Node{Addr: "10.0.0.2", Role: "node"})
+	c.Assert(command, Equals, `curl -s -f --tlsv1.2 -0 -k `+
+		`"https://10.0.0.1:3009/portal/v1/t/token/node?advertise_addr=10.0.0.2" `+
+		`-o /tmp/gravity-join.sh && bash /tmp/gravity-join.sh`)
+}
+
+func newTestJoiner() *testJoiner {
+	return &testJoiner{failures: make(map[string]error)}
+}
+
+// testJoiner records the order of joins and the maximum number of concurrent joins
+type testJoiner struct {
+	sync.Mutex
+	failures  map[string]error
+	order     []string
+	active    int
+	maxActive int
+}
+
+func (r *testJoiner) Join(ctx context.Context, node Node) error {
+	r.Lock()
+	r.order = append(r.order, node.Addr)
+	r.active++
+	if r.active > r.maxActive {
+		r.maxActive = r.active
+	}
+	r.Unlock()
+	time.Sleep(50 * time.Millisecond)
+	r.Lock()
+	defer r.Unlock()
+	r.active--
+	return r.failures[node.Addr]
+}
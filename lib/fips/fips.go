@@ -0,0 +1,82 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fips implements the FIPS 140-2 mode of operation.
+//
+// Binaries built with the fips build tag use the validated BoringCrypto
+// module for all cryptographic operations and restrict TLS to the
+// FIPS-approved versions and cipher suites
+package fips
+
+import (
+	"crypto/tls"
+
+	"github.com/gravitational/trace"
+)
+
+// Enabled returns true if this binary has been built in FIPS mode
+func Enabled() bool {
+	return enabled
+}
+
+// CheckEnabled returns an error if this binary has not been built in FIPS mode
+func CheckEnabled() error {
+	if !enabled {
+		return trace.BadParameter("FIPS mode requires a FIPS build of gravity, " +
+			"build with FIPS=true or use a FIPS release")
+	}
+	return nil
+}
+
+// CipherSuites returns the FIPS-approved TLS cipher suites
+func CipherSuites() []uint16 {
+	return []uint16{
+		tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+		tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	}
+}
+
+// CurvePreferences returns the FIPS-approved elliptic curves
+func CurvePreferences() []tls.CurveID {
+	return []tls.CurveID{tls.CurveP256, tls.CurveP384, tls.CurveP521}
+}
+
+// ConfigureTLS restricts the specified TLS configuration
+// to the FIPS-approved protocol versions, cipher suites and curves
+func ConfigureTLS(config *tls.Config) {
+	config.MinVersion = tls.VersionTLS12
+	config.CipherSuites = CipherSuites()
+	config.CurvePreferences = CurvePreferences()
+	config.PreferServerCipherSuites = true
+}
+
+// TeleportCipherSuites lists the FIPS-approved TLS cipher suites
+// in the teleport configuration format
+var TeleportCipherSuites = []string{
+	"tls-ecdhe-ecdsa-with-aes-128-gcm-sha256",
+	"tls-ecdhe-rsa-with-aes-128-gcm-sha256",
+	"tls-ecdhe-ecdsa-with-aes-256-gcm-sha384",
+	"tls-ecdhe-rsa-with-aes-256-gcm-sha384",
+}
+
+// TeleportKEXAlgorithms lists the FIPS-approved SSH key exchange algorithms
+var TeleportKEXAlgorithms = []string{
+	"ecdh-sha2-nistp256",
+	"ecdh-sha2-nistp384",
+	"ecdh-sha2-nistp521",
+}
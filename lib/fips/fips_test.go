@@ -0,0 +1,36 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fips
+
+import (
+	"testing"
+
+	teleutils "github.com/gravitational/teleport/lib/utils"
+	"gopkg.in/check.v1"
+)
+
+func TestFIPS(t *testing.T) { check.TestingT(t) }
+
+type FIPSSuite struct{}
+
+var _ = check.Suite(&FIPSSuite{})
+
+func (s *FIPSSuite) TestTeleportCipherSuites(c *check.C) {
+	cipherSuites, err := teleutils.CipherSuiteMapping(TeleportCipherSuites)
+	c.Assert(err, check.IsNil)
+	c.Assert(cipherSuites, check.DeepEquals, CipherSuites())
+}
@@ -20,14 +20,21 @@ import (
 	"context"
 	"fmt"
 	"path"
+	"time"
 
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/metrics"
 	"github.com/gravitational/gravity/lib/ops"
 	"github.com/gravitational/gravity/lib/rpc"
 	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/utils"
 
+	"github.com/cenkalti/backoff"
 	"github.com/gravitational/trace"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
 )
 
 // Engine defines interface for specific FSM implementations
@@ -84,6 +91,9 @@ type Params struct {
 	Resume bool
 	// Progress is optional progress reporter
 	Progress utils.Progress
+	// Rollback specifies whether the phase is rolled back instead of executed.
+	// It is used to roll back phases on remote nodes
+	Rollback bool
 }
 
 // CheckAndSetDefaults makes sure all required parameters are set
@@ -128,6 +138,11 @@ type Config struct {
 	Insecure bool
 	// Logger allows to override default logger
 	Logger logrus.FieldLogger
+	// AgentReconnectTimeout specifies how long to wait for the agent on a remote
+	// node to reconnect if it becomes unavailable, for example, when the node reboots.
+	// The interrupted phase is then resumed on the node.
+	// If unspecified, the phase fails as soon as the agent is unavailable
+	AgentReconnectTimeout time.Duration
 }
 
 // CheckAndSetDefaults makes sure the config is valid and sets some defaults
@@ -278,6 +293,34 @@ func (f *FSM) RollbackPhase(ctx context.Context, p Params) error {
 	return nil
 }
 
+// RollbackPlan rolls back all phases of the plan that have been executed
+// in the reverse order of execution.
+// Phases are rolled back either locally or on the node they have been executed on
+func (f *FSM) RollbackPlan(ctx context.Context, progress utils.Progress) error {
+	plan, err := f.GetPlan()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	phases := FlattenPlan(plan)
+	for i := len(phases) - 1; i >= 0; i-- {
+		phase := *phases[i]
+		if phase.HasSubphases() || phase.IsUnstarted() || phase.IsRolledBack() {
+			continue
+		}
+		f.Debugf("Rolling back phase %q.", phase.ID)
+		err := f.rollbackPhaseOnServer(ctx, Params{
+			PhaseID:     phase.ID,
+			OperationID: plan.OperationID,
+			Progress:    progress,
+			Rollback:    true,
+		}, phase)
+		if err != nil {
+			return trace.Wrap(err, "failed to roll back phase %q", phase.ID)
+		}
+	}
+	return nil
+}
+
 // ChangePhaseState updates the specified phase state.
 func (f *FSM) ChangePhaseState(ctx context.Context, change StateChange) error {
 	if err := change.Check(); err != nil {
@@ -339,11 +382,16 @@ func (f *FSM) executePhase(ctx context.Context, p Params, phase storage.Operatio
 
 	switch execWhere {
 	case ShouldRunRemotely:
-		err = trace.NotFound("no agent is running on node %v, please execute phase %q locally on that node",
-			serverName(*execServer), phase.ID)
-
-	case CanRunLocally:
-		err = trace.Wrap(f.executePhaseLocally(ctx, p, phase))
+		if f.AgentReconnectTimeout == 0 {
+			err = trace.NotFound("no agent is running on node %v, please execute phase %q locally on that node",
+				serverName(*execServer), phase.ID)
+			break
+		}
+		// The node might be rebooting
+		if err = f.waitForAgent(ctx, p, *execServer); err != nil {
+			break
+		}
+		fallthrough
 
 	case CanRunRemotely:
 		err = trace.Wrap(f.executePhaseRemotely(ctx, p, phase, *execServer))
@@ -356,6 +404,9 @@ func (f *FSM) executePhase(ctx context.Context, p Params, phase storage.Operatio
 			})
 		}
 
+	case CanRunLocally:
+		err = trace.Wrap(f.executePhaseLocally(ctx, p, phase))
+
 	default:
 		err = trace.BadParameter("unsupported execution location: %v", execWhere)
 	}
@@ -376,7 +427,49 @@ func (f *FSM) executePhaseRemotely(ctx context.Context, p Params, phase storage.
 	p.Progress.NextStep("Executing %q on remote node %v", phase.ID,
 		server.Hostname)
 
-	return f.RunCommand(ctx, f.Runner, server, p)
+	err := f.RunCommand(ctx, f.Runner, server, p)
+	for attempt := 1; err != nil && f.shouldReconnect(err, attempt); attempt++ {
+		f.WithError(err).Warnf("Lost connection to agent on %v.", serverName(server))
+		if err := f.waitForAgent(ctx, p, server); err != nil {
+			return trace.Wrap(err)
+		}
+		// The phase has been interrupted and is still marked in progress on the node
+		p.Force = true
+		p.Progress.NextStep("Resuming %q on remote node %v", phase.ID, server.Hostname)
+		err = f.RunCommand(ctx, f.Runner, server, p)
+	}
+	return trace.Wrap(err)
+}
+
+// shouldReconnect returns true if the phase failed with the specified error
+// should be resumed once the agent on the remote node has reconnected
+func (f *FSM) shouldReconnect(err error, attempt int) bool {
+	if f.AgentReconnectTimeout == 0 || attempt > maxReconnectAttempts {
+		return false
+	}
+	if trace.IsConnectionProblem(err) {
+		return true
+	}
+	status, ok := grpcstatus.FromError(trace.Unwrap(err))
+	return ok && status.Code() == codes.Unavailable
+}
+
+// waitForAgent blocks until the agent on the specified server is able
+// to execute commands or the reconnect timeout expires
+func (f *FSM) waitForAgent(ctx context.Context, p Params, server storage.Server) error {
+	p.Progress.NextStep("Waiting for the agent on node %v to reconnect", server.Hostname)
+	ctx, cancel := context.WithTimeout(ctx, f.AgentReconnectTimeout)
+	defer cancel()
+	err := utils.RetryWithInterval(ctx, backoff.NewConstantBackOff(defaults.RetryInterval), func() error {
+		// Run a command to make sure the agent is serving requests
+		// as the connection to the agent might be cached
+		return trace.Wrap(f.Runner.Run(ctx, server, "version"))
+	})
+	if err != nil {
+		return trace.Wrap(err, "agent on node %v has not reconnected in %v",
+			serverName(server), f.AgentReconnectTimeout)
+	}
+	return nil
 }
 
 // executePhaseLocally executes the specified operation phase on this server
@@ -451,8 +544,15 @@ func (f *FSM) executeOnePhase(ctx context.Context, p Params, phase storage.Opera
 
 	executor.Infof("Executing phase: %v.", phase.ID)
 
+	labels := prometheus.Labels{
+		metrics.LabelOperation: plan.OperationType,
+		metrics.LabelPhase:     phase.Executor,
+	}
+	start := time.Now()
 	err = executor.Execute(ctx)
+	metrics.OperationPhaseDuration.With(labels).Observe(metrics.Since(start))
 	if err != nil {
+		metrics.OperationPhaseFailures.With(labels).Inc()
 		executor.Errorf("Phase execution failed: %v.", err)
 		if err := f.ChangePhaseState(ctx,
 			StateChange{
@@ -467,6 +567,7 @@ func (f *FSM) executeOnePhase(ctx context.Context, p Params, phase storage.Opera
 
 	err = executor.PostCheck(ctx)
 	if err != nil {
+		metrics.OperationPhaseFailures.With(labels).Inc()
 		executor.Errorf("Phase postcheck failed: %v.", err)
 		return trace.Wrap(err)
 	}
@@ -532,6 +633,51 @@ func (f *FSM) rollbackPhase(ctx context.Context, p Params, phase storage.Operati
 	return nil
 }
 
+// rollbackPhaseOnServer rolls back the specified phase on the server
+// it has been executed on
+func (f *FSM) rollbackPhaseOnServer(ctx context.Context, p Params, phase storage.OperationPhase) error {
+	var execServer *storage.Server
+	if phase.Data != nil {
+		if phase.Data.ExecServer != nil {
+			execServer = phase.Data.ExecServer
+		} else {
+			execServer = phase.Data.Server
+		}
+	}
+
+	var err error
+	execWhere := CanRunLocally
+	if execServer != nil {
+		execWhere, err = canExecuteOnServer(ctx, *execServer, f.Runner, f.FieldLogger)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
+	switch execWhere {
+	case CanRunLocally:
+		p.Progress.NextStep("Rolling back %q", phase.ID)
+		return trace.Wrap(f.rollbackPhase(ctx, p, phase))
+	case CanRunRemotely:
+		p.Progress.NextStep("Rolling back %q on remote node %v", phase.ID,
+			execServer.Hostname)
+		if err := f.RunCommand(ctx, f.Runner, *execServer, p); err != nil {
+			return trace.Wrap(err)
+		}
+		// Record the state locally as the remote node might not be able
+		// to synchronize the changes back to us
+		return trace.Wrap(f.ChangePhaseState(ctx, StateChange{
+			Phase: phase.ID,
+			State: storage.OperationPhaseStateRolledBack,
+		}))
+	case ShouldRunRemotely:
+		return trace.NotFound("no agent is running on node %v, please roll back phase %q locally on that node",
+			serverName(*execServer), phase.ID)
+	default:
+		return trace.BadParameter("unsupported execution location: %v", execWhere)
+	}
+}
+
 // prerequisitesComplete checks if specified phase can be executed in the
 // provided plan
 func (f *FSM) prerequisitesComplete(phaseID string) error {
@@ -592,3 +738,7 @@ func (c StateChange) String() string {
 
 // RootPhase is the name of the top-level phase
 const RootPhase = "/"
+
+// maxReconnectAttempts specifies the maximum number of times a remote phase
+// is resumed after the agent executing it has been disconnected
+const maxReconnectAttempts = 3
@@ -0,0 +1,175 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fsm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gravitational/gravity/lib/rpc"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+	"gopkg.in/check.v1"
+)
+
+func TestFSM(t *testing.T) { check.TestingT(t) }
+
+type FSMSuite struct{}
+
+var _ = check.Suite(&FSMSuite{})
+
+func (s *FSMSuite) TestResumesPhaseAfterAgentReconnects(c *check.C) {
+	var testCases = []struct {
+		err     error
+		comment string
+	}{
+		{
+			err:     grpcstatus.Error(codes.Unavailable, "transport is closing"),
+			comment: "agent is unavailable",
+		},
+		{
+			err:     trace.ConnectionProblem(nil, "connection refused"),
+			comment: "connection problem",
+		},
+	}
+	for _, tc := range testCases {
+		comment := check.Commentf(tc.comment)
+		engine := &testEngine{errors: []error{tc.err}}
+		runner := &testRunner{}
+		fsm := newTestFSM(engine, runner, time.Minute)
+
+		err := fsm.executePhaseRemotely(context.TODO(), testParams(), testPhase, testServer)
+		c.Assert(err, check.IsNil, comment)
+		c.Assert(engine.params, check.HasLen, 2, comment)
+		c.Assert(engine.params[0].Force, check.Equals, false, comment)
+		c.Assert(engine.params[1].Force, check.Equals, true,
+			check.Commentf("interrupted phase should be forced on resume: %v", tc.comment))
+		c.Assert(runner.commands, check.Equals, 1, comment)
+	}
+}
+
+func (s *FSMSuite) TestDoesNotResumeOnOtherErrors(c *check.C) {
+	engine := &testEngine{errors: []error{trace.BadParameter("phase failed")}}
+	runner := &testRunner{}
+	fsm := newTestFSM(engine, runner, time.Minute)
+
+	err := fsm.executePhaseRemotely(context.TODO(), testParams(), testPhase, testServer)
+	c.Assert(trace.IsBadParameter(err), check.Equals, true, check.Commentf("%v", err))
+	c.Assert(engine.params, check.HasLen, 1)
+	c.Assert(runner.commands, check.Equals, 0)
+}
+
+func (s *FSMSuite) TestDoesNotResumeWithoutReconnectTimeout(c *check.C) {
+	engine := &testEngine{errors: []error{trace.ConnectionProblem(nil, "connection refused")}}
+	fsm := newTestFSM(engine, &testRunner{}, 0)
+
+	err := fsm.executePhaseRemotely(context.TODO(), testParams(), testPhase, testServer)
+	c.Assert(trace.IsConnectionProblem(err), check.Equals, true, check.Commentf("%v", err))
+	c.Assert(engine.params, check.HasLen, 1)
+}
+
+func (s *FSMSuite) TestLimitsReconnectAttempts(c *check.C) {
+	var errors []error
+	for i := 0; i < maxReconnectAttempts+2; i++ {
+		errors = append(errors, grpcstatus.Error(codes.Unavailable, "transport is closing"))
+	}
+	engine := &testEngine{errors: errors}
+	runner := &testRunner{}
+	fsm := newTestFSM(engine, runner, time.Minute)
+
+	err := fsm.executePhaseRemotely(context.TODO(), testParams(), testPhase, testServer)
+	c.Assert(err, check.NotNil)
+	c.Assert(engine.params, check.HasLen, maxReconnectAttempts+1)
+	c.Assert(runner.commands, check.Equals, maxReconnectAttempts)
+}
+
+func (s *FSMSuite) TestFailsIfAgentDoesNotReconnect(c *check.C) {
+	engine := &testEngine{errors: []error{trace.ConnectionProblem(nil, "connection refused")}}
+	runner := &testRunner{err: trace.ConnectionProblem(nil, "connection refused")}
+	fsm := newTestFSM(engine, runner, 100*time.Millisecond)
+
+	err := fsm.executePhaseRemotely(context.TODO(), testParams(), testPhase, testServer)
+	c.Assert(err, check.NotNil)
+	c.Assert(err, check.ErrorMatches, ".*agent on node .* has not reconnected in 100ms")
+	c.Assert(engine.params, check.HasLen, 1)
+}
+
+func newTestFSM(engine Engine, runner rpc.RemoteRunner, reconnectTimeout time.Duration) *FSM {
+	config := Config{
+		Engine:                engine,
+		Runner:                runner,
+		AgentReconnectTimeout: reconnectTimeout,
+	}
+	if err := config.CheckAndSetDefaults(); err != nil {
+		panic(err)
+	}
+	return &FSM{
+		Config:      config,
+		FieldLogger: config.Logger,
+	}
+}
+
+// testEngine fails the phase commands with the specified errors in order
+type testEngine struct {
+	Engine
+	errors []error
+	params []Params
+}
+
+func (r *testEngine) RunCommand(ctx context.Context, runner rpc.RemoteRunner, server storage.Server, p Params) error {
+	r.params = append(r.params, p)
+	if len(r.params) > len(r.errors) {
+		return nil
+	}
+	return r.errors[len(r.params)-1]
+}
+
+// testRunner counts the commands used to check whether the agent is available
+type testRunner struct {
+	err      error
+	commands int
+}
+
+func (r *testRunner) Run(ctx context.Context, server storage.Server, command ...string) error {
+	r.commands++
+	return r.err
+}
+
+func (r *testRunner) CanExecute(context.Context, storage.Server) error {
+	return r.err
+}
+
+func (r *testRunner) Close() error {
+	return nil
+}
+
+var (
+	testServer = storage.Server{Hostname: "node-1", AdvertiseIP: "192.168.1.1"}
+	testPhase  = storage.OperationPhase{ID: "/phase"}
+)
+
+func testParams() Params {
+	p := Params{PhaseID: testPhase.ID}
+	if err := p.CheckAndSetDefaults(); err != nil {
+		panic(err)
+	}
+	return p
+}
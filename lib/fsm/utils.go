@@ -79,6 +79,22 @@ func IsFailed(plan *storage.OperationPlan) bool {
 	return true
 }
 
+// IsRolledBack returns true if the provided plan has been rolled back,
+// i.e. all of its phases are either rolled back or unstarted
+func IsRolledBack(plan *storage.OperationPlan) bool {
+	var rolledBack bool
+	for _, phase := range FlattenPlan(plan) {
+		if phase.HasSubphases() {
+			continue
+		}
+		if !phase.IsRolledBack() && !phase.IsUnstarted() {
+			return false
+		}
+		rolledBack = rolledBack || phase.IsRolledBack()
+	}
+	return rolledBack
+}
+
 // FindPhase finds a phase with the specified id in the provided plan
 func FindPhase(plan *storage.OperationPlan, phaseID string) (*storage.OperationPhase, error) {
 	allPhases := FlattenPlan(plan)
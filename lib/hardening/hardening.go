@@ -0,0 +1,161 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package hardening implements the hardened cluster configuration profiles.
+//
+// A hardening profile is selected during installation and determines
+// the additional configuration of the Kubernetes components (apiserver
+// flags, kubelet authentication and authorization, audit policy and
+// encryption of secrets at rest) as well as the restrictions of the
+// default pod security policy
+package hardening
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gravitational/trace"
+)
+
+// Profile names a hardened cluster configuration profile
+type Profile string
+
+// ParseProfile parses the hardening profile from the specified string.
+// Empty string means no hardening
+func ParseProfile(s string) (Profile, error) {
+	profile := Profile(strings.ToLower(strings.TrimSpace(s)))
+	if profile == ProfileNone {
+		return ProfileNone, nil
+	}
+	for _, p := range Profiles {
+		if p == profile {
+			return profile, nil
+		}
+	}
+	return ProfileNone, trace.BadParameter("unknown hardening profile %q, supported profiles are %v",
+		s, Profiles)
+}
+
+// IsEnabled returns true if this profile applies any hardening
+func (p Profile) IsEnabled() bool {
+	return p != ProfileNone
+}
+
+// APIServerArgs returns the additional apiserver flags of this profile
+func (p Profile) APIServerArgs() []string {
+	if p != ProfileCIS {
+		return nil
+	}
+	return []string{
+		"--anonymous-auth=false",
+		"--profiling=false",
+		"--service-account-lookup=true",
+		fmt.Sprintf("--tls-cipher-suites=%v", strings.Join(cipherSuites, ",")),
+	}
+}
+
+// KubeletArgs returns the additional kubelet flags of this profile
+func (p Profile) KubeletArgs() []string {
+	if p != ProfileCIS {
+		return nil
+	}
+	return []string{
+		"--anonymous-auth=false",
+		"--authorization-mode=Webhook",
+		"--read-only-port=0",
+		"--make-iptables-util-chains=true",
+		"--streaming-connection-idle-timeout=5m",
+		fmt.Sprintf("--tls-cipher-suites=%v", strings.Join(cipherSuites, ",")),
+	}
+}
+
+// AuditPolicy returns the apiserver audit policy of this profile.
+// The policy can be replaced with the cluster configuration
+func (p Profile) AuditPolicy() []byte {
+	if p != ProfileCIS {
+		return nil
+	}
+	return []byte(auditPolicy)
+}
+
+const (
+	// ProfileNone means no hardening
+	ProfileNone Profile = ""
+	// ProfileCIS is the profile that follows the CIS Kubernetes Benchmark
+	ProfileCIS Profile = "cis"
+)
+
+// Profiles lists the supported hardening profiles
+var Profiles = []Profile{ProfileCIS}
+
+// ProfilesAsStrings returns the names of the supported hardening profiles
+func ProfilesAsStrings() (profiles []string) {
+	for _, profile := range Profiles {
+		profiles = append(profiles, string(profile))
+	}
+	return profiles
+}
+
+// cipherSuites lists the strong TLS cipher suites the Kubernetes
+// components are restricted to
+var cipherSuites = []string{
+	"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+	"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+	"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+	"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+	"TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305",
+	"TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305",
+}
+
+// auditPolicy skips the high-volume system requests, records only the
+// metadata of the requests to secrets and config maps so their contents
+// never end up in the audit log, and records all other requests in full
+const auditPolicy = `apiVersion: audit.k8s.io/v1
+kind: Policy
+omitStages:
+  - RequestReceived
+rules:
+  - level: None
+    users: ["system:kube-proxy"]
+    verbs: ["watch"]
+    resources:
+      - group: ""
+        resources: ["endpoints", "services", "services/status"]
+  - level: None
+    userGroups: ["system:nodes"]
+    verbs: ["get"]
+    resources:
+      - group: ""
+        resources: ["nodes", "nodes/status"]
+  - level: None
+    nonResourceURLs:
+      - /healthz*
+      - /version
+      - /swagger*
+  - level: None
+    resources:
+      - group: ""
+        resources: ["events"]
+  - level: Metadata
+    resources:
+      - group: ""
+        resources: ["secrets", "configmaps"]
+      - group: authentication.k8s.io
+        resources: ["tokenreviews"]
+  - level: Request
+    verbs: ["get", "list", "watch"]
+  - level: RequestResponse
+`
@@ -0,0 +1,107 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hardening
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gravitational/trace"
+	"gopkg.in/check.v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestHardening(t *testing.T) { check.TestingT(t) }
+
+type HardeningSuite struct{}
+
+var _ = check.Suite(&HardeningSuite{})
+
+func (s *HardeningSuite) TestParsesProfile(c *check.C) {
+	profile, err := ParseProfile("CIS")
+	c.Assert(err, check.IsNil)
+	c.Assert(profile, check.Equals, ProfileCIS)
+
+	profile, err = ParseProfile("")
+	c.Assert(err, check.IsNil)
+	c.Assert(profile.IsEnabled(), check.Equals, false)
+	c.Assert(profile.APIServerArgs(), check.IsNil)
+
+	_, err = ParseProfile("nsa")
+	c.Assert(trace.IsBadParameter(err), check.Equals, true)
+}
+
+func (s *HardeningSuite) TestHardensPodSecurityPolicy(c *check.C) {
+	policy := policyv1beta1.PodSecurityPolicy{
+		Spec: policyv1beta1.PodSecurityPolicySpec{
+			HostNetwork: true,
+			Volumes:     []policyv1beta1.FSType{policyv1beta1.All},
+		},
+	}
+	policy.Name = RestrictedPodSecurityPolicy
+	c.Assert(trace.IsCompareFailed(ProfileCIS.CheckPodSecurityPolicy(policy)), check.Equals, true)
+
+	ProfileCIS.HardenPodSecurityPolicy(&policy)
+	c.Assert(ProfileCIS.CheckPodSecurityPolicy(policy), check.IsNil)
+	c.Assert(*policy.Spec.AllowPrivilegeEscalation, check.Equals, false)
+}
+
+func (s *HardeningSuite) TestVerifiesAnonymousAccessAndReadOnlyPort(c *check.C) {
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer rejecting.Close()
+	allowing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"major": "1", "minor": "15"}`))
+	}))
+	defer allowing.Close()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+	closedAddr := listener.Addr().String()
+	c.Assert(listener.Close(), check.IsNil)
+	listener, err = net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, check.IsNil)
+	defer listener.Close()
+
+	problems := Verify(VerifyConfig{
+		Profile:             ProfileCIS,
+		AnonymousClient:     newClient(c, rejecting.URL),
+		KubeletReadOnlyAddr: closedAddr,
+	})
+	c.Assert(problems, check.HasLen, 0)
+
+	problems = Verify(VerifyConfig{
+		Profile:             ProfileCIS,
+		AnonymousClient:     newClient(c, allowing.URL),
+		KubeletReadOnlyAddr: listener.Addr().String(),
+	})
+	c.Assert(problems, check.HasLen, 2)
+
+	c.Assert(Verify(VerifyConfig{
+		AnonymousClient: newClient(c, allowing.URL),
+	}), check.HasLen, 0, check.Commentf("Unhardened clusters are not verified."))
+}
+
+func newClient(c *check.C, url string) *kubernetes.Clientset {
+	client, err := kubernetes.NewForConfig(&rest.Config{Host: url})
+	c.Assert(err, check.IsNil)
+	return client
+}
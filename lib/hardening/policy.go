@@ -0,0 +1,103 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hardening
+
+import (
+	"github.com/gravitational/trace"
+	v1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+)
+
+// HardenPodSecurityPolicy restricts the specified pod security policy
+// according to this profile
+func (p Profile) HardenPodSecurityPolicy(policy *policyv1beta1.PodSecurityPolicy) {
+	if p != ProfileCIS {
+		return
+	}
+	allowPrivilegeEscalation := false
+	policy.Spec.Privileged = false
+	policy.Spec.AllowPrivilegeEscalation = &allowPrivilegeEscalation
+	policy.Spec.DefaultAllowPrivilegeEscalation = &allowPrivilegeEscalation
+	policy.Spec.HostNetwork = false
+	policy.Spec.HostPID = false
+	policy.Spec.HostIPC = false
+	policy.Spec.HostPorts = nil
+	policy.Spec.AllowedCapabilities = nil
+	policy.Spec.DefaultAddCapabilities = nil
+	policy.Spec.RequiredDropCapabilities = []v1.Capability{capabilityAll}
+	policy.Spec.Volumes = restrictedVolumes
+}
+
+// CheckPodSecurityPolicy verifies that the specified pod security policy
+// has been restricted according to this profile
+func (p Profile) CheckPodSecurityPolicy(policy policyv1beta1.PodSecurityPolicy) error {
+	if p != ProfileCIS {
+		return nil
+	}
+	spec := policy.Spec
+	if spec.Privileged || spec.HostNetwork || spec.HostPID || spec.HostIPC {
+		return trace.CompareFailed("pod security policy %v allows privileged or host namespace access",
+			policy.Name)
+	}
+	if spec.AllowPrivilegeEscalation == nil || *spec.AllowPrivilegeEscalation {
+		return trace.CompareFailed("pod security policy %v allows privilege escalation", policy.Name)
+	}
+	if !hasCapability(spec.RequiredDropCapabilities, capabilityAll) {
+		return trace.CompareFailed("pod security policy %v does not drop all capabilities", policy.Name)
+	}
+	for _, volume := range spec.Volumes {
+		if !isRestrictedVolume(volume) {
+			return trace.CompareFailed("pod security policy %v allows %v volumes", policy.Name, volume)
+		}
+	}
+	return nil
+}
+
+func hasCapability(capabilities []v1.Capability, capability v1.Capability) bool {
+	for _, c := range capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+func isRestrictedVolume(volume policyv1beta1.FSType) bool {
+	for _, v := range restrictedVolumes {
+		if v == volume {
+			return true
+		}
+	}
+	return false
+}
+
+// RestrictedPodSecurityPolicy is the name of the default pod security policy
+// for the unprivileged workloads
+const RestrictedPodSecurityPolicy = "restricted"
+
+const capabilityAll v1.Capability = "ALL"
+
+// restrictedVolumes lists the volume types that do not provide
+// access to the host
+var restrictedVolumes = []policyv1beta1.FSType{
+	policyv1beta1.ConfigMap,
+	policyv1beta1.EmptyDir,
+	policyv1beta1.Projected,
+	policyv1beta1.Secret,
+	policyv1beta1.DownwardAPI,
+	policyv1beta1.PersistentVolumeClaim,
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hardening
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/gravitational/rigging"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// VerifyConfig describes the cluster to verify the hardening of
+type VerifyConfig struct {
+	// Profile is the hardening profile the cluster has been installed with
+	Profile Profile
+	// Client is the authenticated Kubernetes client
+	Client kubernetes.Interface
+	// AnonymousClient is the Kubernetes client without credentials
+	AnonymousClient kubernetes.Interface
+	// KubeletReadOnlyAddr is the address of the kubelet read-only port
+	KubeletReadOnlyAddr string
+}
+
+// Verify asserts that the cluster has been configured according to the
+// hardening profile and returns the list of detected problems.
+// The checks that lack the corresponding client or address are skipped
+func Verify(config VerifyConfig) (problems []string) {
+	if !config.Profile.IsEnabled() {
+		return nil
+	}
+	if config.AnonymousClient != nil {
+		_, err := config.AnonymousClient.Discovery().ServerVersion()
+		switch {
+		case err == nil:
+			problems = append(problems, "apiserver allows anonymous requests")
+		case !errors.IsUnauthorized(err):
+			problems = append(problems, fmt.Sprintf("failed to verify apiserver anonymous access: %v", err))
+		}
+	}
+	if config.KubeletReadOnlyAddr != "" {
+		conn, err := net.DialTimeout("tcp", config.KubeletReadOnlyAddr, dialTimeout)
+		if err == nil {
+			conn.Close()
+			problems = append(problems, fmt.Sprintf("kubelet read-only port is open on %v",
+				config.KubeletReadOnlyAddr))
+		}
+	}
+	if config.Client != nil {
+		policy, err := config.Client.PolicyV1beta1().PodSecurityPolicies().Get(
+			RestrictedPodSecurityPolicy, metav1.GetOptions{})
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("failed to query pod security policy %v: %v",
+				RestrictedPodSecurityPolicy, rigging.ConvertError(err)))
+		} else if err := config.Profile.CheckPodSecurityPolicy(*policy); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	return problems
+}
+
+const dialTimeout = 2 * time.Second
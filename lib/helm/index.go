@@ -33,7 +33,7 @@ func GenerateIndexFile(apps []app.Application) *repo.IndexFile {
 	indexFile := repo.NewIndexFile()
 	for _, item := range apps {
 		switch item.Manifest.Kind {
-		case schema.KindBundle, schema.KindApplication, schema.KindCluster:
+		case schema.KindBundle, schema.KindApplication, schema.KindCluster, schema.KindPlatform:
 		default: // Do not include system apps and runtimes.
 			continue
 		}
@@ -372,6 +372,18 @@ func GetClusterKubeClient(dnsAddr string, options ...KubeConfigOption) (*kuberne
 	}, options...)
 }
 
+// GetAnonymousKubeClient returns a Kubernetes client that does not
+// authenticate with the API server
+func GetAnonymousKubeClient(dnsAddr string, options ...KubeConfigOption) (*kubernetes.Clientset, *rest.Config, error) {
+	stateDir, err := state.GetStateDir()
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	return getKubeClient(dnsAddr, rest.TLSClientConfig{
+		CAFile: state.Secret(stateDir, defaults.RootCertFilename),
+	}, options...)
+}
+
 func getKubeClient(dnsAddr string, tlsConfig rest.TLSClientConfig, options ...KubeConfigOption) (*kubernetes.Clientset, *rest.Config, error) {
 	config := &rest.Config{
 		Host: fmt.Sprintf("https://%v:%v", constants.APIServerDomainName,
@@ -58,7 +58,12 @@ func ParseAuthHeaders(r *http.Request) (*AuthCreds, error) {
 		}, nil
 	}
 
-	authHeader := r.Header.Get("Authorization")
+	return ParseAuthHeader(r.Header.Get("Authorization"))
+}
+
+// ParseAuthHeader parses the value of the Authorization header
+// and returns the credentials
+func ParseAuthHeader(authHeader string) (*AuthCreds, error) {
 	if authHeader == "" {
 		return nil, trace.AccessDenied("unauthorized")
 	}
@@ -0,0 +1,173 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/app/service"
+	"github.com/gravitational/gravity/lib/archive"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/pack/layerpack"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+)
+
+// ImportBaseImages imports the cluster images from the tarballs at the specified
+// paths into the installer state directory stateDir so the layered cluster image
+// built on top of them can be installed.
+//
+// A base image that is itself a layered image requires its own base image
+// so the tarballs are imported in the specified order, from the bottom of
+// the chain up
+func ImportBaseImages(stateDir string, paths []string, logger logrus.FieldLogger) error {
+	env, err := localenv.New(stateDir)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer env.Close()
+	apps, err := env.AppServiceLocal(localenv.AppConfig{})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	for _, path := range paths {
+		logger.WithField("path", path).Info("Import base cluster image.")
+		if err := importBaseImage(stateDir, path, env.Packages, apps, logger); err != nil {
+			return trace.Wrap(err, "failed to import cluster image from %v", path)
+		}
+	}
+	return nil
+}
+
+// CheckBaseImages makes sure that the base images of all cluster images
+// in the specified package service are available
+func CheckBaseImages(packages pack.PackageService) error {
+	return pack.ForeachPackage(packages, func(env pack.PackageEnvelope) error {
+		if env.Type != string(storage.AppUser) {
+			return nil
+		}
+		manifest, err := schema.ParseManifestYAMLNoValidate(env.Manifest)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		base := manifest.Base()
+		if base == nil {
+			return nil
+		}
+		_, err = packages.ReadPackageEnvelope(*base)
+		if err == nil || !trace.IsNotFound(err) {
+			return trace.Wrap(err)
+		}
+		return trace.NotFound("cluster image %v is built on top of the cluster image %v, "+
+			"specify the path to its tarball with --base-image", env.Locator, base)
+	})
+}
+
+// importBaseImage imports the cluster image from the tarball at the specified path
+// along with its dependencies into the provided package and application services
+func importBaseImage(stateDir, path string, packages pack.PackageService, apps app.Applications, logger logrus.FieldLogger) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	// unpack next to the installer state as cluster images are large
+	dir, err := ioutil.TempDir(stateDir, "base")
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := archive.Extract(f, dir); err != nil {
+		return trace.Wrap(err)
+	}
+	env, err := localenv.NewLocalEnvironment(localenv.LocalEnvironmentArgs{
+		StateDir:        dir,
+		ReadonlyBackend: true,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer env.Close()
+	image, err := findClusterImage(env.Packages)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// the image can be a layered image itself built on top of
+	// the image that has already been imported
+	source := layerpack.New(env.Packages, packages)
+	sourceApps, err := env.AppServiceLocal(localenv.AppConfig{
+		Packages: source,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = service.PullApp(service.AppPullRequest{
+		FieldLogger: logger,
+		SrcPack:     source,
+		SrcApp:      sourceApps,
+		DstPack:     packages,
+		DstApp:      apps,
+		Package:     *image,
+	})
+	if err != nil && !trace.IsAlreadyExists(err) {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// findClusterImage returns the top-level cluster image in the specified package service.
+// The application manifests are not resolved as the base image of a layered image
+// is not available in the same package service
+func findClusterImage(packages pack.PackageService) (*loc.Locator, error) {
+	var images []loc.Locator
+	bases := make(map[loc.Locator]struct{})
+	err := pack.ForeachPackage(packages, func(env pack.PackageEnvelope) error {
+		if env.Type != string(storage.AppUser) {
+			return nil
+		}
+		manifest, err := schema.ParseManifestYAMLNoValidate(env.Manifest)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		switch manifest.Kind {
+		case schema.KindBundle, schema.KindCluster, schema.KindPlatform:
+		default:
+			return nil
+		}
+		images = append(images, env.Locator)
+		if base := manifest.Base(); base != nil {
+			bases[*base] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, image := range images {
+		if _, ok := bases[image]; !ok {
+			return &image, nil
+		}
+	}
+	return nil, trace.NotFound("no cluster image found")
+}
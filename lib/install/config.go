@@ -34,6 +34,7 @@ import (
 	rpcserver "github.com/gravitational/gravity/lib/rpc/server"
 	"github.com/gravitational/gravity/lib/schema"
 	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/system/disklayout"
 	"github.com/gravitational/gravity/lib/systeminfo"
 	"github.com/gravitational/gravity/lib/utils"
 
@@ -63,14 +64,20 @@ func NewFSMConfig(operator ops.Operator, operationKey ops.SiteOperationKey, conf
 
 // RunLocalChecks executes host-local preflight checks for this configuration
 func (c *Config) RunLocalChecks(ctx context.Context) error {
+	manifest := c.App.Manifest
+	if c.Demo {
+		manifest = checks.DemoManifest(manifest)
+	}
 	return trace.Wrap(checks.RunLocalChecks(ctx, checks.LocalChecksRequest{
-		Manifest: c.App.Manifest,
-		Role:     c.Role,
-		Docker:   c.Docker,
+		Manifest:      manifest,
+		Role:          c.Role,
+		Docker:        c.Docker,
+		CloudProvider: c.CloudProvider,
 		Options: &validationpb.ValidateOptions{
-			VxlanPort: int32(c.VxlanPort),
-			DnsAddrs:  c.DNSConfig.Addrs,
-			DnsPort:   int32(c.DNSConfig.Port),
+			VxlanPort:     int32(c.VxlanPort),
+			DnsAddrs:      c.DNSConfig.Addrs,
+			DnsPort:       int32(c.DNSConfig.Port),
+			WireguardPort: int32(storage.OnPremVariables{NetworkEncryption: c.NetworkEncryption}.WireguardPort()),
 		},
 		AutoFix: true,
 	}))
@@ -119,8 +126,14 @@ type Config struct {
 	SystemDevice string
 	// DockerDevice is a device for docker
 	DockerDevice string
+	// DiskLayout is the optional planned disk layout of the installer node
+	DiskLayout *disklayout.Layout
 	// Mounts is a list of mount points (name -> source pairs)
 	Mounts map[string]string
+	// Labels is a list of custom labels for the installer node in the key=value format
+	Labels []string
+	// Taints is a list of custom taints for the installer node in the key=value:effect format
+	Taints []string
 	// DNSOverrides contains installer node DNS overrides
 	DNSOverrides storage.DNSOverrides
 	// PodCIDR is a pod network CIDR
@@ -129,6 +142,8 @@ type Config struct {
 	ServiceCIDR string
 	// VxlanPort is the overlay network port
 	VxlanPort int
+	// NetworkEncryption is the encryption mode of the pod traffic between the nodes
+	NetworkEncryption string
 	// DNSConfig overrides the local cluster DNS configuration
 	DNSConfig storage.DNSConfig
 	// Docker specifies docker configuration
@@ -158,6 +173,12 @@ type Config struct {
 	Packages pack.PackageService
 	// LocalAgent specifies whether the installer will also run an agent
 	LocalAgent bool
+	// Demo specifies whether to install the cluster in demo mode with
+	// relaxed preflight requirements
+	Demo bool
+	// CertAuthority specifies the optional certificate authority
+	// to issue the cluster certificates instead of a generated one
+	CertAuthority *storage.CertAuthorityFiles
 }
 
 // checkAndSetDefaults checks the parameters and autodetects some defaults
@@ -240,6 +261,7 @@ func (r *clusterFactory) NewCluster() ops.NewSiteRequest {
 		DNSOverrides: r.DNSOverrides,
 		DNSConfig:    r.DNSConfig,
 		Docker:       r.Docker,
+		Demo:         r.Demo,
 	}
 }
 
@@ -313,6 +335,7 @@ func newAgent(ctx context.Context, config Config) (*rpcserver.PeerServer, error)
 		DockerDevice: config.DockerDevice,
 		Role:         config.Role,
 		Mounts:       mounts,
+		KeyValues:    NodeKeyValues(config.Labels, config.Taints),
 	}
 	return NewAgent(AgentConfig{
 		FieldLogger:   config.FieldLogger.WithField(trace.Component, "agent:rpc"),
@@ -0,0 +1,96 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"path/filepath"
+
+	"github.com/gravitational/gravity/lib/checks"
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/devicemapper"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/system/disklayout"
+
+	"github.com/gravitational/trace"
+)
+
+// DiskLayoutConfig describes the node to plan the disk layout for
+type DiskLayoutConfig struct {
+	// Manifest is the manifest of the cluster application
+	Manifest schema.Manifest
+	// Profile is the node profile
+	Profile string
+	// Docker optionally overrides the Docker configuration from the manifest
+	Docker storage.DockerConfig
+	// SystemDevice optionally specifies the device for the system state directory
+	SystemDevice string
+	// DockerDevice optionally specifies the device for Docker storage
+	DockerDevice string
+	// EtcdDevice optionally specifies the device for etcd data
+	EtcdDevice string
+	// AutoPartition specifies whether to automatically place data
+	// on unused devices
+	AutoPartition bool
+}
+
+// PlanDiskLayout plans the disk layout of this node using its unused block devices
+func PlanDiskLayout(config DiskLayoutConfig) (*disklayout.Layout, error) {
+	if config.Profile == "" {
+		return nil, trace.BadParameter("node profile is required to plan the disk layout")
+	}
+	profile, err := config.Manifest.NodeProfiles.ByName(config.Profile)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	devices, err := devicemapper.GetDevices()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	manifestDocker := config.Manifest.SystemDocker()
+	docker := checks.DockerConfigFromSchemaValue(manifestDocker)
+	checks.OverrideDockerConfig(&docker, config.Docker)
+	layoutConfig := disklayout.Config{
+		Devices:        devices,
+		Devicemapper:   docker.StorageDriver == constants.DockerStorageDriverDevicemapper,
+		DockerCapacity: manifestDocker.Capacity,
+		Etcd:           profile.ServiceRole != schema.ServiceRoleNode,
+		AutoPartition:  config.AutoPartition,
+	}
+	// Devices can be given as symlinks, e.g. /dev/disk/by-id/<id>
+	for _, device := range []struct {
+		path   string
+		target *string
+	}{
+		{config.SystemDevice, &layoutConfig.SystemDevice},
+		{config.DockerDevice, &layoutConfig.DockerDevice},
+		{config.EtcdDevice, &layoutConfig.EtcdDevice},
+	} {
+		if device.path == "" {
+			continue
+		}
+		*device.target, err = filepath.EvalSymlinks(storage.DeviceName(device.path).Path())
+		if err != nil {
+			return nil, trace.ConvertSystemError(err)
+		}
+	}
+	layout, err := disklayout.Plan(layoutConfig)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return layout, nil
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/gravitational/gravity/lib/schema"
 	"github.com/gravitational/gravity/lib/state"
 	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/system/disklayout"
 	"github.com/gravitational/gravity/lib/system/environ"
 	"github.com/gravitational/gravity/lib/utils"
 
@@ -130,7 +131,11 @@ func (r *executor) bootstrap() error {
 	if err != nil {
 		return trace.Wrap(err, "failed to install binary")
 	}
-	err = configureStateDirectory(r.config.SystemDevice)
+	if r.config.DiskLayout != nil {
+		err = configureDiskLayout(*r.config.DiskLayout, r.FieldLogger)
+	} else {
+		err = configureStateDirectory(r.config.SystemDevice)
+	}
 	if err != nil {
 		return trace.Wrap(err, "failed to configure state directory")
 	}
@@ -178,9 +183,10 @@ func (r *executor) createOperation() (*ops.SiteOperation, error) {
 				Docker: r.config.Docker,
 			},
 			OnPrem: storage.OnPremVariables{
-				PodCIDR:     r.config.PodCIDR,
-				ServiceCIDR: r.config.ServiceCIDR,
-				VxlanPort:   r.config.VxlanPort,
+				PodCIDR:           r.config.PodCIDR,
+				ServiceCIDR:       r.config.ServiceCIDR,
+				VxlanPort:         r.config.VxlanPort,
+				NetworkEncryption: r.config.NetworkEncryption,
 			},
 		},
 		Profiles: install.ServerRequirements(*r.config.Flavor),
@@ -293,6 +299,17 @@ func configureStateDirectory(systemDevice string) error {
 	return trace.Wrap(err)
 }
 
+// configureDiskLayout configures local gravity state directory and
+// etcd data directory using the specified disk layout
+func configureDiskLayout(layout disklayout.Layout, logger log.FieldLogger) error {
+	stateDir, err := state.GetStateDir()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = environ.ConfigureDiskLayout(stateDir, layout, logger)
+	return trace.Wrap(err)
+}
+
 func formatNeededAndExtra(needed map[string]int, extra []checks.ServerInfo) string {
 	var buf bytes.Buffer
 	fmt.Fprint(&buf, "still requires:[")
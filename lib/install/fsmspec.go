@@ -90,6 +90,25 @@ func FSMSpec(config FSMConfig) fsm.FSMSpecFunc {
 				config.LocalApps,
 				client)
 
+		case p.Phase.ID == phases.HardeningPhase:
+			client, err := getKubeClient(p)
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			return phases.NewHardening(p,
+				config.Operator,
+				client)
+
+		case p.Phase.ID == phases.NetworkPolicyPhase:
+			client, err := getKubeClient(p)
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			return phases.NewNetworkPolicy(p,
+				config.Operator,
+				config.LocalApps,
+				client)
+
 		case p.Phase.ID == phases.CorednsPhase:
 			client, err := getKubeClient(p)
 			if err != nil {
@@ -108,6 +127,15 @@ func FSMSpec(config FSMConfig) fsm.FSMSpecFunc {
 				config.Operator,
 				client)
 
+		case p.Phase.ID == phases.AzureLoadBalancerPhase:
+			client, err := getKubeClient(p)
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			return phases.NewAzureLoadBalancer(p,
+				config.Operator,
+				client)
+
 		case p.Phase.ID == phases.UserResourcesPhase:
 			return phases.NewUserResources(p,
 				config.Operator)
@@ -149,6 +177,16 @@ func FSMSpec(config FSMConfig) fsm.FSMSpecFunc {
 			}
 			return phases.NewGravityResourcesPhase(p, operator, factory)
 
+		case strings.HasPrefix(p.Phase.ID, phases.SmokeTestsPhase):
+			operator, err := config.LocalClusterClient()
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			return phases.NewSmokeTest(p,
+				config.Operator,
+				operator,
+				config.LocalApps)
+
 		default:
 			return nil, trace.BadParameter("unknown phase %q", p.Phase.ID)
 		}
@@ -74,6 +74,7 @@ func New(ctx context.Context, config RuntimeConfig) (installer *Installer, err e
 	if err := installer.maybeStartAgent(); err != nil {
 		return nil, trace.Wrap(utils.NewFailedPreconditionError(err))
 	}
+	installer.maybeResume()
 	return installer, nil
 }
 
@@ -164,6 +165,41 @@ func (i *Installer) GenerateDebugReport(path string) error {
 	return nil
 }
 
+// GetStatus returns the status of the install operation.
+// Implements server.StatusReporter
+func (i *Installer) GetStatus() (*installpb.StatusResponse, error) {
+	resp := &installpb.StatusResponse{
+		Token:     i.config.Token.Token,
+		AgentAddr: i.config.GetWizardAddr(),
+	}
+	clusters, err := i.config.Operator.GetSites(defaults.SystemAccountID)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if len(clusters) == 0 {
+		// The operation has not been created yet
+		return resp, nil
+	}
+	op, _, err := ops.GetInstallOperation(clusters[0].Key(), i.config.Operator)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return resp, nil
+		}
+		return nil, trace.Wrap(err)
+	}
+	resp.Key = installpb.KeyToProto(op.Key())
+	resp.State = op.State
+	progress, err := i.config.Operator.GetSiteOperationProgress(op.Key())
+	if err != nil && !trace.IsNotFound(err) {
+		return nil, trace.Wrap(err)
+	}
+	if progress != nil {
+		resp.Completion = int32(progress.Completion)
+		resp.Message = progress.Message
+	}
+	return resp, nil
+}
+
 // Interface defines the interface of the installer as presented
 // to engine
 type Interface interface {
@@ -248,6 +284,40 @@ func (i *Installer) maybeStartAgent() error {
 	return nil
 }
 
+// maybeResume resumes the install operation if the installer service has been
+// restarted (e.g. after the node has been rebooted) while the operation was in progress.
+// The operation plan serves as a checkpoint: completed phases are skipped and
+// the phase interrupted by the restart is executed again.
+// Progress events are buffered until a client reconnects with 'gravity resume'.
+func (i *Installer) maybeResume() {
+	op, err := ops.GetWizardOperation(i.config.Operator)
+	if err != nil {
+		// No operation has been created yet
+		return
+	}
+	if op.IsFinished() {
+		return
+	}
+	_, err = i.config.Operator.GetOperationPlan(op.Key())
+	if err != nil {
+		if !trace.IsNotFound(err) {
+			i.WithError(err).Warn("Failed to query operation plan.")
+		}
+		// The operation has not started executing yet
+		return
+	}
+	i.WithField("operation", op.ID).Info("Resume interrupted operation.")
+	req := &installpb.ExecuteRequest{Phase: phaseForOperation(*op)}
+	i.wg.Add(1)
+	go func() {
+		defer i.wg.Done()
+		select {
+		case i.execC <- req:
+		case <-i.ctx.Done():
+		}
+	}()
+}
+
 func (i *Installer) execute(req *installpb.ExecuteRequest) (dispatcher.Status, error) {
 	i.WithField("req", req).Info("Execute.")
 	existingOperation, _ := ops.GetWizardOperation(i.config.Operator)
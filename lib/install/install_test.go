@@ -0,0 +1,210 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"context"
+	"crypto/tls"
+	"sync"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/install/dispatcher/buffered"
+	installpb "github.com/gravitational/gravity/lib/install/proto"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/opsclient"
+	"github.com/gravitational/gravity/lib/ops/opsservice"
+	"github.com/gravitational/gravity/lib/ops/suite"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	"github.com/pborman/uuid"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/credentials"
+	"gopkg.in/check.v1"
+)
+
+type InstallerSuite struct {
+	services opsservice.TestServices
+}
+
+var _ = check.Suite(&InstallerSuite{})
+
+func (s *InstallerSuite) SetUpTest(c *check.C) {
+	s.services = opsservice.SetupTestServices(c)
+	_, err := s.services.Operator.CreateAccount(ops.NewAccountRequest{
+		ID:  defaults.SystemAccountID,
+		Org: defaults.SystemAccountOrg,
+	})
+	c.Assert(err, check.IsNil)
+}
+
+// Makes sure a restarted installer resumes the interrupted operation
+// from the first unfinished phase
+func (s *InstallerSuite) TestResumesInterruptedOperation(c *check.C) {
+	key := s.createInstallOperation(c)
+	err := s.services.Operator.CreateOperationPlan(key, storage.OperationPlan{
+		OperationID:   key.OperationID,
+		OperationType: ops.OperationInstall,
+		AccountID:     key.AccountID,
+		ClusterName:   key.SiteDomain,
+		Phases: []storage.OperationPhase{
+			{ID: "/init"},
+			{ID: "/checks"},
+			{ID: "/configure"},
+		},
+	})
+	c.Assert(err, check.IsNil)
+	// the installer was interrupted after completing the first phase
+	err = s.services.Operator.CreateOperationPlanChange(key, storage.PlanChange{
+		ID:          uuid.New(),
+		ClusterName: key.SiteDomain,
+		OperationID: key.OperationID,
+		PhaseID:     "/init",
+		NewState:    storage.OperationPhaseStateCompleted,
+		Created:     time.Now().UTC(),
+	})
+	c.Assert(err, check.IsNil)
+
+	factory := &recordingFSMFactory{services: s.services}
+	installer := s.newInstaller(factory)
+	defer func() {
+		installer.cancel()
+		installer.wg.Wait()
+		installer.dispatcher.Close()
+	}()
+	installer.maybeResume()
+
+	select {
+	case result := <-installer.execDoneC:
+		c.Assert(result.Error, check.IsNil)
+	case <-time.After(10 * time.Second):
+		c.Fatal("Timed out waiting for the operation to resume.")
+	}
+	c.Assert(factory.getExecuted(), check.DeepEquals, []string{"/checks", "/configure"})
+	plan, err := s.services.Operator.GetOperationPlan(key)
+	c.Assert(err, check.IsNil)
+	c.Assert(fsm.IsCompleted(plan), check.Equals, true)
+}
+
+func (s *InstallerSuite) createInstallOperation(c *check.C) ops.SiteOperationKey {
+	appPackage := suite.SetUpTestPackage(c, s.services.Apps, s.services.Packages)
+	_, err := s.services.Backend.CreateSite(storage.Site{
+		AccountID: defaults.SystemAccountID,
+		Domain:    "example.com",
+		Created:   time.Now().UTC(),
+		State:     ops.SiteStateInstalling,
+		App: storage.Package{
+			Repository: appPackage.Repository,
+			Name:       appPackage.Name,
+			Version:    appPackage.Version,
+		},
+	})
+	c.Assert(err, check.IsNil)
+	operation, err := s.services.Backend.CreateSiteOperation(storage.SiteOperation{
+		ID:         uuid.New(),
+		AccountID:  defaults.SystemAccountID,
+		SiteDomain: "example.com",
+		Type:       ops.OperationInstall,
+		Created:    time.Now().UTC(),
+		State:      ops.OperationStateInstallDeploying,
+	})
+	c.Assert(err, check.IsNil)
+	key := ops.SiteOperationKey{
+		AccountID:   operation.AccountID,
+		SiteDomain:  operation.SiteDomain,
+		OperationID: operation.ID,
+	}
+	err = s.services.Operator.CreateProgressEntry(key, ops.ProgressEntry{
+		SiteDomain:  key.SiteDomain,
+		OperationID: key.OperationID,
+		State:       ops.ProgressStateInProgress,
+		Created:     time.Now().UTC(),
+	})
+	c.Assert(err, check.IsNil)
+	return key
+}
+
+// newInstaller returns a new installer as it is after the installer service restart
+func (s *InstallerSuite) newInstaller(factory *recordingFSMFactory) *Installer {
+	ctx, cancel := context.WithCancel(context.Background())
+	installer := &Installer{
+		FieldLogger: logrus.WithField(trace.Component, "installer"),
+		ctx:         ctx,
+		cancel:      cancel,
+		config: RuntimeConfig{
+			Config: Config{
+				Operator: s.services.Operator,
+			},
+			FSMFactory: factory,
+		},
+		errC:       make(chan error, 2),
+		execC:      make(chan *installpb.ExecuteRequest),
+		execDoneC:  make(chan ExecResult, 1),
+		dispatcher: buffered.New(),
+	}
+	installer.startExecuteLoop()
+	return installer
+}
+
+// recordingFSMFactory creates install state machines that
+// record the phases they execute
+type recordingFSMFactory struct {
+	services opsservice.TestServices
+	mu       sync.Mutex
+	executed []string
+}
+
+// NewFSM returns a new install state machine for the specified operation.
+// Implements engine.FSMFactory
+func (r *recordingFSMFactory) NewFSM(operator ops.Operator, operationKey ops.SiteOperationKey) (*fsm.FSM, error) {
+	return NewFSM(FSMConfig{
+		OperationKey:  operationKey,
+		Packages:      r.services.Packages,
+		Apps:          r.services.Apps,
+		Operator:      operator,
+		LocalPackages: r.services.Packages,
+		LocalApps:     r.services.Apps,
+		LocalBackend:  r.services.Backend,
+		LocalClusterClient: func() (*opsclient.Client, error) {
+			return nil, trace.NotImplemented("no cluster client in test")
+		},
+		Credentials: credentials.NewTLS(&tls.Config{}),
+		Spec: func(p fsm.ExecutorParams, remote fsm.Remote) (fsm.PhaseExecutor, error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.executed = append(r.executed, p.Phase.ID)
+			return &noopExecutor{FieldLogger: logrus.WithField("phase", p.Phase.ID)}, nil
+		},
+	})
+}
+
+func (r *recordingFSMFactory) getExecuted() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.executed
+}
+
+type noopExecutor struct {
+	logrus.FieldLogger
+}
+
+func (*noopExecutor) PreCheck(context.Context) error  { return nil }
+func (*noopExecutor) PostCheck(context.Context) error { return nil }
+func (*noopExecutor) Execute(context.Context) error   { return nil }
+func (*noopExecutor) Rollback(context.Context) error  { return nil }
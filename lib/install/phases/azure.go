@@ -0,0 +1,99 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/cloudprovider/azure"
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/rigging"
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// NewAzureLoadBalancer returns executor that exposes the API servers
+// with an Azure internal load balancer
+func NewAzureLoadBalancer(p fsm.ExecutorParams, operator ops.Operator, client *kubernetes.Clientset) (fsm.PhaseExecutor, error) {
+	logger := &fsm.Logger{
+		FieldLogger: logrus.WithField(constants.FieldPhase, p.Phase.ID),
+		Key:         opKey(p.Plan),
+		Operator:    operator,
+	}
+	return &azureLoadBalancer{
+		FieldLogger:    logger,
+		ExecutorParams: p,
+		Client:         client,
+	}, nil
+}
+
+// azureLoadBalancer is executor that creates the API server load balancer service
+type azureLoadBalancer struct {
+	// FieldLogger is used for logging
+	logrus.FieldLogger
+	// ExecutorParams contains common executor parameters
+	fsm.ExecutorParams
+	// Client is the installed cluster's Kubernetes client
+	Client *kubernetes.Clientset
+}
+
+// Execute creates the API server load balancer service and its endpoints
+func (r *azureLoadBalancer) Execute(ctx context.Context) error {
+	r.Progress.NextStep("Creating internal load balancer for Kubernetes API")
+	r.Info("Creating internal load balancer for Kubernetes API.")
+	var masterIPs []string
+	for _, server := range storage.Servers(r.Plan.Servers).Masters() {
+		masterIPs = append(masterIPs, server.AdvertiseIP)
+	}
+	service, endpoints := azure.APIServerLoadBalancer(masterIPs)
+	_, err := r.Client.CoreV1().Endpoints(endpoints.Namespace).Create(endpoints)
+	if err != nil && !trace.IsAlreadyExists(rigging.ConvertError(err)) {
+		return trace.Wrap(rigging.ConvertError(err))
+	}
+	_, err = r.Client.CoreV1().Services(service.Namespace).Create(service)
+	if err != nil && !trace.IsAlreadyExists(rigging.ConvertError(err)) {
+		return trace.Wrap(rigging.ConvertError(err))
+	}
+	return nil
+}
+
+// Rollback deletes the API server load balancer service
+func (r *azureLoadBalancer) Rollback(context.Context) error {
+	// Endpoints are removed together with the service
+	err := r.Client.CoreV1().Services(constants.KubeSystemNamespace).Delete(
+		azure.APIServerLoadBalancerName, &metav1.DeleteOptions{})
+	if err != nil && !trace.IsNotFound(rigging.ConvertError(err)) {
+		return trace.Wrap(rigging.ConvertError(err))
+	}
+	return nil
+}
+
+// PreCheck is no-op for this phase
+func (*azureLoadBalancer) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is no-op for this phase
+func (*azureLoadBalancer) PostCheck(context.Context) error {
+	return nil
+}
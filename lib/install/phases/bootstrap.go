@@ -34,6 +34,7 @@ import (
 	"github.com/gravitational/gravity/lib/schema"
 	"github.com/gravitational/gravity/lib/state"
 	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/system/selinux"
 	"github.com/gravitational/gravity/lib/systeminfo"
 	"github.com/gravitational/gravity/lib/utils"
 
@@ -134,6 +135,10 @@ func (p *bootstrapExecutor) Execute(ctx context.Context) error {
 	if err != nil {
 		return trace.Wrap(err)
 	}
+	err = p.configureSELinux()
+	if err != nil {
+		return trace.Wrap(err)
+	}
 	err = p.logIntoCluster()
 	if err != nil {
 		return trace.Wrap(err)
@@ -254,6 +259,30 @@ func (p *bootstrapExecutor) configureSystemDirectories() error {
 	return nil
 }
 
+// configureSELinux installs the gravity SELinux policy module and labels
+// the system directories if SELinux is enabled on the node
+func (p *bootstrapExecutor) configureSELinux() error {
+	mode, err := selinux.GetMode()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if !mode.IsEnabled() {
+		p.Debug("SELinux is disabled.")
+		return nil
+	}
+	p.Progress.NextStep("Configuring SELinux policy")
+	p.Infof("Configuring SELinux policy, SELinux is %v.", mode)
+	stateDir, err := state.GetStateDir()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = selinux.Bootstrap(selinux.BootstrapConfig{
+		StateDir:    stateDir,
+		FieldLogger: p.FieldLogger,
+	})
+	return trace.Wrap(err)
+}
+
 // configureApplicationVolumes creates necessary directories for
 // application mounts
 func (p *bootstrapExecutor) configureApplicationVolumes() error {
@@ -22,7 +22,9 @@ import (
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/fsm"
 	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
 
+	"github.com/gravitational/license/authority"
 	"github.com/gravitational/trace"
 	"github.com/sirupsen/logrus"
 )
@@ -38,9 +40,11 @@ func NewConfigure(p fsm.ExecutorParams, operator ops.Operator) (*configureExecut
 	}
 	var env map[string]string
 	var config []byte
+	var certAuthority *storage.CertAuthorityFiles
 	if p.Phase.Data != nil && p.Phase.Data.Install != nil {
 		env = p.Phase.Data.Install.Env
 		config = p.Phase.Data.Install.Config
+		certAuthority = p.Phase.Data.Install.CertAuthority
 	}
 	return &configureExecutor{
 		FieldLogger:    logger,
@@ -48,6 +52,7 @@ func NewConfigure(p fsm.ExecutorParams, operator ops.Operator) (*configureExecut
 		ExecutorParams: p,
 		env:            env,
 		config:         config,
+		certAuthority:  certAuthority,
 	}, nil
 }
 
@@ -58,19 +63,29 @@ type configureExecutor struct {
 	Operator ops.Operator
 	// ExecutorParams is common executor params
 	fsm.ExecutorParams
-	env    map[string]string
-	config []byte
+	env           map[string]string
+	config        []byte
+	certAuthority *storage.CertAuthorityFiles
 }
 
 // Execute executes the configure phase
 func (p *configureExecutor) Execute(ctx context.Context) error {
 	p.Progress.NextStep("Configuring cluster packages")
 	p.Info("Configuring cluster packages.")
-	err := p.Operator.ConfigurePackages(ops.ConfigurePackagesRequest{
+	req := ops.ConfigurePackagesRequest{
 		SiteOperationKey: fsm.OperationKey(p.Plan),
 		Env:              p.env,
 		Config:           p.config,
-	})
+	}
+	if p.certAuthority != nil {
+		p.Infof("Using certificate authority from %v.", p.certAuthority.CertPath)
+		keyPair, err := authority.NewTLSKeyPair(p.certAuthority.KeyPath, p.certAuthority.CertPath)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		req.CertAuthority = keyPair
+	}
+	err := p.Operator.ConfigurePackages(req)
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -41,10 +41,18 @@ const (
 	HealthPhase = "/health"
 	// RBACPhase is a phase that creates Kubernetes RBAC resources
 	RBACPhase = "/rbac"
+	// HardeningPhase is a phase that applies the hardening profile
+	// to the Kubernetes resources
+	HardeningPhase = "/hardening"
+	// NetworkPolicyPhase is a phase that creates the network policy baseline
+	NetworkPolicyPhase = "/network-policy"
 	// CorednsPhase is a phase that generates coredns configuration for the cluster
 	CorednsPhase = "/coredns"
 	// SystemResourcesPhase is a phase that creates system Kubernetes resources
 	SystemResourcesPhase = "/system-resources"
+	// AzureLoadBalancerPhase is a phase that creates the Azure internal load balancer
+	// for the Kubernetes API servers
+	AzureLoadBalancerPhase = "/azure-load-balancer"
 	// UserResourcesPhase is a phase that creates user supplied Kubernetes resources
 	UserResourcesPhase = "/user-resources"
 	// GravityResourcesPhase is a phase that creates user supplied Gravity resources
@@ -63,4 +71,7 @@ const (
 	EnableElectionPhase = "/election"
 	// InstallOverlayPhase installs a custom overlay network
 	InstallOverlayPhase = "/overlay"
+	// SmokeTestsPhase runs the application smoke tests as the final
+	// verification step of the installation
+	SmokeTestsPhase = "/smoke-tests"
 )
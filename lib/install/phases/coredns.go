@@ -17,21 +17,19 @@ limitations under the License.
 package phases
 
 import (
-	"bytes"
 	"context"
 
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/fsm"
 	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/opsservice"
 	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/systeminfo"
 	"github.com/gravitational/teleport/lib/utils"
 
-	"github.com/alecthomas/template"
 	"github.com/gravitational/trace"
 	log "github.com/sirupsen/logrus"
-	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
@@ -102,26 +100,17 @@ func (r *corednsExecutor) Execute(ctx context.Context) error {
 		return trace.Wrap(err)
 	}
 
+	// Upstream nameservers configured for the cluster take precedence
+	// over the ones detected on the host
 	upstreams := mergeUpstreamResolvers(resolvConf, systemdResolvConf)
-	conf, err := GenerateCorefile(CorednsConfig{
-		UpstreamNameservers: upstreams,
-		Rotate:              resolvConf.Rotate,
-		Hosts:               r.DNSOverrides.Hosts,
-		Zones:               r.DNSOverrides.Zones,
-	})
+	conf, err := opsservice.GenerateCorefile(opsservice.NewCorednsConfig(
+		r.DNSOverrides, upstreams, resolvConf.Rotate))
 	if err != nil {
 		return trace.Wrap(err)
 	}
 
-	_, err = r.Client.CoreV1().ConfigMaps(constants.KubeSystemNamespace).Create(&v1.ConfigMap{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "coredns",
-			Namespace: constants.KubeSystemNamespace,
-		},
-		Data: map[string]string{
-			"Corefile": conf,
-		},
-	})
+	_, err = r.Client.CoreV1().ConfigMaps(constants.KubeSystemNamespace).Create(
+		opsservice.NewCorednsConfigMap(conf))
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -153,63 +142,10 @@ func mergeUpstreamResolvers(configs ...*storage.ResolvConf) []string {
 
 // Rollback deletes the coredns configmap that was created in the execute step
 func (r *corednsExecutor) Rollback(context.Context) error {
-	err := r.Client.CoreV1().ConfigMaps(constants.KubeSystemNamespace).Delete("coredns", &metav1.DeleteOptions{})
+	err := r.Client.CoreV1().ConfigMaps(constants.KubeSystemNamespace).Delete(constants.CoreDNSConfigMap, &metav1.DeleteOptions{})
 	if err != nil && !trace.IsNotFound(err) {
 		return trace.Wrap(err)
 	}
 
 	return nil
 }
-
-// GenerateCorefile will generate a coredns configuration file to be used from within the cluster
-func GenerateCorefile(config CorednsConfig) (string, error) {
-	var coredns bytes.Buffer
-	err := coreDNSTemplate.Execute(&coredns, config)
-	if err != nil {
-		return "", trace.Wrap(err)
-	}
-	return coredns.String(), nil
-}
-
-// CoreDNSConfig represents the CoreDNS configuration options to apply to our template
-type CorednsConfig struct {
-	// Zones maps a DNS zone to nameservers it will be served by as provided by a user at install time
-	Zones map[string][]string
-	// Hosts  maps a hostname to an IP address it will resolve to as provided by a user at install time
-	Hosts map[string]string
-	// UpstreamNameservers is a list of nameservers to use as resolvers as detected from the system resolv.conf
-	UpstreamNameservers []string
-	// Rotate indicates whether the upstream servers should be round-robin load balanced as detected from the system
-	// resolv.conf
-	Rotate bool
-}
-
-var coreDNSTemplate = template.Must(template.New("coredns").Parse(coreDNSTemplateText))
-
-const coreDNSTemplateText = `
-.:53 {
-  reload
-  errors
-  health
-  prometheus :9153
-  cache 30
-  loop
-  reload
-  loadbalance
-  hosts { {{range $hostname, $ip := .Hosts}}
-    {{$ip}} {{$hostname}}{{end}}
-    fallthrough
-  }
-  kubernetes cluster.local in-addr.arpa ip6.arpa {
-    pods verified
-    fallthrough in-addr.arpa ip6.arpa
-  }{{range $zone, $servers := .Zones}}
-  proxy {{$zone}} {{range $server := $servers}}{{$server}} {{end}}{
-    policy sequential
-  }{{end}}
-  {{if .UpstreamNameservers}}forward . {{range $server := .UpstreamNameservers}}{{$server}} {{end}}{
-    {{if .Rotate}}policy random{{else}}policy sequential{{end}}
-    health_check 0
-  }{{end}}
-}
-`
@@ -30,120 +30,6 @@ type StartSuite struct{}
 
 var _ = check.Suite(&StartSuite{})
 
-func (*StartSuite) TestCoreDNSConf(c *check.C) {
-	var configTable = []struct {
-		config   CorednsConfig
-		expected string
-	}{
-		{
-			CorednsConfig{
-				Zones: map[string][]string{
-					"example.com":  []string{"1.1.1.1", "2.2.2.2"},
-					"example2.com": []string{"1.1.1.1", "2.2.2.2"},
-				},
-				Hosts: map[string]string{
-					"override.com":  "5.5.5.5",
-					"override2.com": "1.2.3.4",
-				},
-				UpstreamNameservers: []string{"1.1.1.1", "8.8.8.8"},
-			},
-			`
-.:53 {
-  reload
-  errors
-  health
-  prometheus :9153
-  cache 30
-  loop
-  reload
-  loadbalance
-  hosts { 
-    5.5.5.5 override.com
-    1.2.3.4 override2.com
-    fallthrough
-  }
-  kubernetes cluster.local in-addr.arpa ip6.arpa {
-    pods verified
-    fallthrough in-addr.arpa ip6.arpa
-  }
-  proxy example.com 1.1.1.1 2.2.2.2 {
-    policy sequential
-  }
-  proxy example2.com 1.1.1.1 2.2.2.2 {
-    policy sequential
-  }
-  forward . 1.1.1.1 8.8.8.8 {
-    policy sequential
-    health_check 0
-  }
-}
-`,
-		},
-		{
-			CorednsConfig{
-				UpstreamNameservers: []string{"1.1.1.1"},
-				Rotate:              true,
-			},
-			`
-.:53 {
-  reload
-  errors
-  health
-  prometheus :9153
-  cache 30
-  loop
-  reload
-  loadbalance
-  hosts { 
-    fallthrough
-  }
-  kubernetes cluster.local in-addr.arpa ip6.arpa {
-    pods verified
-    fallthrough in-addr.arpa ip6.arpa
-  }
-  forward . 1.1.1.1 {
-    policy random
-    health_check 0
-  }
-}
-`,
-		},
-		{
-			CorednsConfig{
-				Rotate: true,
-			},
-			`
-.:53 {
-  reload
-  errors
-  health
-  prometheus :9153
-  cache 30
-  loop
-  reload
-  loadbalance
-  hosts { 
-    fallthrough
-  }
-  kubernetes cluster.local in-addr.arpa ip6.arpa {
-    pods verified
-    fallthrough in-addr.arpa ip6.arpa
-  }
-  
-}
-`,
-		},
-	}
-
-	for _, tt := range configTable {
-		config, err := GenerateCorefile(tt.config)
-
-		c.Assert(err, check.IsNil)
-		c.Assert(config, check.Equals, tt.expected)
-	}
-
-}
-
 func (*StartSuite) TestMergeUpstreamResolvers(c *check.C) {
 	var cases = []struct {
 		configs     []*storage.ResolvConf
@@ -29,6 +29,7 @@ import (
 	"github.com/gravitational/gravity/lib/ops"
 	"github.com/gravitational/gravity/lib/pack"
 	"github.com/gravitational/gravity/lib/state"
+	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/utils"
 
 	"github.com/gravitational/trace"
@@ -151,10 +152,28 @@ func (p *exportExecutor) exportApp(ctx context.Context, locator loc.Locator) err
 		locator.Name, locator.Version)
 	p.Infof("Exporting application %v:%v to local registry.",
 		locator.Name, locator.Version)
+	if err := p.verifyImages(ctx, locator); err != nil {
+		return trace.Wrap(err)
+	}
 	_, err := p.ImageService.Sync(ctx, p.registryPath(locator), utils.DiscardPrinter)
 	return trace.Wrap(err)
 }
 
+// verifyImages verifies the application images against the image trust policy
+// if one has been specified for the installation
+func (p *exportExecutor) verifyImages(ctx context.Context, locator loc.Locator) error {
+	install := p.Phase.Data.Install
+	if install == nil || len(install.ImageTrustPolicy) == 0 {
+		return nil
+	}
+	policy, err := storage.UnmarshalImageTrustPolicy(install.ImageTrustPolicy)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	p.Infof("Verifying images of application %v:%v.", locator.Name, locator.Version)
+	return trace.Wrap(docker.VerifyImages(ctx, p.registryPath(locator), policy, p.FieldLogger))
+}
+
 func (p *exportExecutor) packagePath(locator loc.Locator) string {
 	return pack.PackagePath(filepath.Join(p.StateDir, defaults.LocalDir,
 		defaults.PackagesDir, defaults.UnpackedDir), locator)
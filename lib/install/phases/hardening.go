@@ -0,0 +1,106 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/hardening"
+	"github.com/gravitational/gravity/lib/ops"
+
+	"github.com/gravitational/rigging"
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// NewHardening returns executor that applies the hardening profile
+// to the Kubernetes resources
+func NewHardening(p fsm.ExecutorParams, operator ops.Operator, client *kubernetes.Clientset) (fsm.PhaseExecutor, error) {
+	if p.Phase.Data == nil {
+		return nil, trace.BadParameter("hardening profile is required")
+	}
+	profile, err := hardening.ParseProfile(p.Phase.Data.Data)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	logger := &fsm.Logger{
+		FieldLogger: logrus.WithField(constants.FieldPhase, p.Phase.ID),
+		Key:         opKey(p.Plan),
+		Operator:    operator,
+	}
+	return &hardeningExecutor{
+		FieldLogger:    logger,
+		ExecutorParams: p,
+		Client:         client,
+		Profile:        profile,
+	}, nil
+}
+
+// hardeningExecutor is executor that applies the hardening profile
+// to the Kubernetes resources
+type hardeningExecutor struct {
+	// FieldLogger is used for logging
+	logrus.FieldLogger
+	// ExecutorParams contains common executor parameters
+	fsm.ExecutorParams
+	// Client is the installed cluster's Kubernetes client
+	Client *kubernetes.Clientset
+	// Profile is the hardening profile to apply
+	Profile hardening.Profile
+}
+
+// Execute restricts the default pod security policy according to the profile
+func (r *hardeningExecutor) Execute(ctx context.Context) error {
+	r.Progress.NextStep("Applying %v hardening profile", r.Profile)
+	r.Infof("Applying %v hardening profile.", r.Profile)
+	policies := r.Client.PolicyV1beta1().PodSecurityPolicies()
+	policy, err := policies.Get(hardening.RestrictedPodSecurityPolicy, metav1.GetOptions{})
+	if err != nil {
+		return trace.Wrap(rigging.ConvertError(err))
+	}
+	r.Profile.HardenPodSecurityPolicy(policy)
+	_, err = policies.Update(policy)
+	if err != nil {
+		return trace.Wrap(rigging.ConvertError(err))
+	}
+	return nil
+}
+
+// Rollback is no-op for this phase as the pod security policy
+// is removed together with the rest of the RBAC resources
+func (*hardeningExecutor) Rollback(context.Context) error {
+	return nil
+}
+
+// PreCheck is no-op for this phase
+func (*hardeningExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck verifies that the pod security policy has been restricted
+func (r *hardeningExecutor) PostCheck(context.Context) error {
+	policy, err := r.Client.PolicyV1beta1().PodSecurityPolicies().Get(
+		hardening.RestrictedPodSecurityPolicy, metav1.GetOptions{})
+	if err != nil {
+		return trace.Wrap(rigging.ConvertError(err))
+	}
+	return trace.Wrap(r.Profile.CheckPodSecurityPolicy(*policy))
+}
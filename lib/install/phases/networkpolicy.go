@@ -0,0 +1,155 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/networkpolicy"
+	"github.com/gravitational/gravity/lib/ops"
+
+	"github.com/gravitational/rigging"
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// NewNetworkPolicy returns executor that creates the network policy baseline
+func NewNetworkPolicy(p fsm.ExecutorParams, operator ops.Operator, apps app.Applications, client *kubernetes.Clientset) (fsm.PhaseExecutor, error) {
+	if p.Phase.Data == nil || p.Phase.Data.Package == nil {
+		return nil, trace.BadParameter("application package is required")
+	}
+	application, err := apps.GetApp(*p.Phase.Data.Package)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	policies, err := networkpolicy.Policies(application.Manifest)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	logger := &fsm.Logger{
+		FieldLogger: logrus.WithField(constants.FieldPhase, p.Phase.ID),
+		Key:         opKey(p.Plan),
+		Operator:    operator,
+	}
+	return &networkPolicyExecutor{
+		FieldLogger:    logger,
+		ExecutorParams: p,
+		Client:         client,
+		Policies:       policies,
+	}, nil
+}
+
+// networkPolicyExecutor is executor that creates the network policy baseline
+type networkPolicyExecutor struct {
+	// FieldLogger is used for logging
+	logrus.FieldLogger
+	// ExecutorParams contains common executor parameters
+	fsm.ExecutorParams
+	// Client is the installed cluster's Kubernetes client
+	Client *kubernetes.Clientset
+	// Policies lists the network policies to create
+	Policies []networkingv1.NetworkPolicy
+}
+
+// Execute labels the locked down namespaces and creates the network policies
+func (r *networkPolicyExecutor) Execute(ctx context.Context) error {
+	r.Progress.NextStep("Creating network policy baseline")
+	r.Info("Creating network policy baseline.")
+	for _, namespace := range networkpolicy.Namespaces {
+		if err := r.labelNamespace(namespace); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	for _, policy := range r.Policies {
+		if err := r.upsertPolicy(policy); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// Rollback removes the network policies
+func (r *networkPolicyExecutor) Rollback(context.Context) error {
+	for _, policy := range r.Policies {
+		err := r.Client.NetworkingV1().NetworkPolicies(policy.Namespace).Delete(policy.Name, nil)
+		if err = rigging.ConvertError(err); err != nil && !trace.IsNotFound(err) {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// PreCheck is no-op for this phase
+func (*networkPolicyExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck verifies that the network policies have been created
+func (r *networkPolicyExecutor) PostCheck(context.Context) error {
+	for _, policy := range r.Policies {
+		_, err := r.Client.NetworkingV1().NetworkPolicies(policy.Namespace).Get(policy.Name, metav1.GetOptions{})
+		if err != nil {
+			return trace.Wrap(rigging.ConvertError(err))
+		}
+	}
+	return nil
+}
+
+// labelNamespace adds the label the network policies select
+// the specified namespace with
+func (r *networkPolicyExecutor) labelNamespace(name string) error {
+	namespaces := r.Client.CoreV1().Namespaces()
+	namespace, err := namespaces.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return trace.Wrap(rigging.ConvertError(err))
+	}
+	if namespace.Labels == nil {
+		namespace.Labels = make(map[string]string)
+	}
+	for key, value := range networkpolicy.NamespaceLabels(name) {
+		namespace.Labels[key] = value
+	}
+	_, err = namespaces.Update(namespace)
+	if err != nil {
+		return trace.Wrap(rigging.ConvertError(err))
+	}
+	return nil
+}
+
+func (r *networkPolicyExecutor) upsertPolicy(policy networkingv1.NetworkPolicy) error {
+	r.WithField("policy", policy.Name).Infof("Create network policy in namespace %v.", policy.Namespace)
+	policies := r.Client.NetworkingV1().NetworkPolicies(policy.Namespace)
+	_, err := policies.Create(&policy)
+	err = rigging.ConvertError(err)
+	if err == nil {
+		return nil
+	}
+	if !trace.IsAlreadyExists(err) {
+		return trace.Wrap(err)
+	}
+	_, err = policies.Update(&policy)
+	if err != nil {
+		return trace.Wrap(rigging.ConvertError(err))
+	}
+	return nil
+}
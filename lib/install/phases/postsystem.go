@@ -28,6 +28,7 @@ import (
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/fsm"
+	libkube "github.com/gravitational/gravity/lib/kubernetes"
 	"github.com/gravitational/gravity/lib/ops"
 	"github.com/gravitational/gravity/lib/status"
 	"github.com/gravitational/gravity/lib/utils"
@@ -77,10 +78,19 @@ func (p *waitExecutor) Execute(ctx context.Context) error {
 	select {
 	case <-done:
 		p.Info("Kubernetes API is available.")
-		return nil
 	case <-ctx.Done():
 		return trace.Wrap(ctx.Err())
 	}
+	for _, server := range p.Plan.Servers {
+		if len(server.Labels) == 0 && len(server.Taints) == 0 {
+			continue
+		}
+		p.Infof("Applying custom labels and taints to node %v.", server.Hostname)
+		err := libkube.ApplyServerLabelsAndTaints(ctx, p.Client, server)
+		if err != nil {
+			return trace.Wrap(err, "failed to apply labels and taints to node %v", server.Hostname)
+		}
+	}
 	return nil
 }
 
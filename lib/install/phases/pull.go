@@ -56,7 +56,8 @@ func NewPull(p fsm.ExecutorParams, operator ops.Operator, wizardPack, localPack
 		return nil, trace.Wrap(err)
 	}
 
-	runtimePackage, err := app.Manifest.RuntimePackageForProfile(p.Phase.Data.Server.Role)
+	runtimePackage, err := app.Manifest.RuntimePackageForServer(
+		p.Phase.Data.Server.Role, p.Phase.Data.Server.GetArch())
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -0,0 +1,121 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/events"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+)
+
+// NewSmokeTest returns executor that runs an application smoke test
+func NewSmokeTest(p fsm.ExecutorParams, operator, clusterOperator ops.Operator, apps app.Applications) (*smokeTestExecutor, error) {
+	if p.Phase.Data == nil || p.Phase.Data.Package == nil || p.Phase.Data.Data == "" {
+		return nil, trace.BadParameter("application package and smoke test name are required")
+	}
+	logger := &fsm.Logger{
+		FieldLogger: logrus.WithFields(logrus.Fields{
+			constants.FieldPhase: p.Phase.ID,
+		}),
+		Key:      opKey(p.Plan),
+		Operator: operator,
+		Server:   p.Phase.Data.Server,
+	}
+	return &smokeTestExecutor{
+		FieldLogger:     logger,
+		Operator:        operator,
+		ClusterOperator: clusterOperator,
+		Apps:            apps,
+		ExecutorParams:  p,
+	}, nil
+}
+
+type smokeTestExecutor struct {
+	// FieldLogger is used for logging
+	logrus.FieldLogger
+	// Operator is installer ops service
+	Operator ops.Operator
+	// ClusterOperator is the cluster ops service the test results are reported to
+	ClusterOperator ops.Operator
+	// Apps is the app service that runs the test
+	Apps app.Applications
+	// ExecutorParams is common executor params
+	fsm.ExecutorParams
+}
+
+// Execute runs the smoke test and fails if the test does not pass
+func (p *smokeTestExecutor) Execute(ctx context.Context) error {
+	locator := *p.Phase.Data.Package
+	name := p.Phase.Data.Data
+	req := app.HookRunRequest{
+		Application: locator,
+		SmokeTest:   name,
+	}
+	if p.Phase.Data.ServiceUser != nil {
+		req.ServiceUser = *p.Phase.Data.ServiceUser
+	}
+	p.Progress.NextStep("Running smoke test %v", name)
+	p.Infof("Running smoke test %v for %v.", name, locator)
+	reader, writer := io.Pipe()
+	go func() {
+		defer reader.Close()
+		err := p.Operator.StreamOperationLogs(p.Key(), reader)
+		if err != nil && !utils.IsStreamClosedError(err) {
+			logrus.Warnf("Error streaming smoke test logs: %v.",
+				trace.DebugReport(err))
+		}
+	}()
+	start := time.Now()
+	err := app.RunSmokeTest(ctx, p.Apps, req, writer)
+	fields := events.Fields{
+		events.FieldName:        name,
+		events.FieldOperationID: p.Plan.OperationID,
+		events.FieldLatency:     time.Since(start).String(),
+	}
+	if err != nil {
+		events.Emit(ctx, p.ClusterOperator, events.SmokeTestFailed,
+			fields.WithField(events.FieldError, trace.UserMessage(err)))
+		return trace.Wrap(err, "smoke test %v failed", name)
+	}
+	events.Emit(ctx, p.ClusterOperator, events.SmokeTestPassed, fields)
+	return nil
+}
+
+// Rollback is no-op for this phase
+func (*smokeTestExecutor) Rollback(ctx context.Context) error {
+	return nil
+}
+
+// PreCheck is no-op for this phase
+func (*smokeTestExecutor) PreCheck(ctx context.Context) error {
+	return nil
+}
+
+// PostCheck is no-op for this phase
+func (*smokeTestExecutor) PostCheck(ctx context.Context) error {
+	return nil
+}
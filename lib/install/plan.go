@@ -80,10 +80,15 @@ func (r *Planner) GetOperationPlan(operator ops.Operator, cluster ops.Site, oper
 	// to start up, creating RBAC resources, etc.
 	builder.AddWaitPhase(plan)
 	builder.AddRBACPhase(plan)
+	builder.AddHardeningPhase(plan)
+	builder.AddNetworkPolicyPhase(plan)
 	builder.AddCorednsPhase(plan)
 
 	// create system and user-supplied Kubernetes resources
 	builder.AddSystemResourcesPhase(plan)
+	if cluster.Provider == schema.ProviderAzure {
+		builder.AddAzureLoadBalancerPhase(plan)
+	}
 	builder.AddUserResourcesPhase(plan)
 
 	// export applications to registries
@@ -118,6 +123,9 @@ func (r *Planner) GetOperationPlan(operator ops.Operator, cluster ops.Site, oper
 	// Add a phase to create optional Gravity resources upon successful installation
 	builder.AddGravityResourcesPhase(plan)
 
+	// verify the installed application with its smoke tests
+	builder.AddSmokeTestsPhase(plan)
+
 	return plan, nil
 }
 
@@ -26,14 +26,17 @@ import (
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/hardening"
 	"github.com/gravitational/gravity/lib/install/phases"
 	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/networkpolicy"
 	"github.com/gravitational/gravity/lib/ops"
 	"github.com/gravitational/gravity/lib/ops/opsservice"
 	resourceutil "github.com/gravitational/gravity/lib/ops/resources"
 	"github.com/gravitational/gravity/lib/pack"
 	"github.com/gravitational/gravity/lib/schema"
 	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/clusterconfig"
 
 	"github.com/gravitational/trace"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -47,6 +50,9 @@ type PlanBuilder struct {
 	Application app.Application
 	// Runtime is the Runtime of the app being installed
 	Runtime app.Application
+	// BaseImages lists the cluster images a layered app is built on top of,
+	// ordered from the bottom up
+	BaseImages []app.Application
 	// TeleportPackage is the runtime teleport package
 	TeleportPackage loc.Locator
 	// RBACPackage is the runtime rbac app package
@@ -71,12 +77,21 @@ type PlanBuilder struct {
 	env map[string]string
 	// config specifies the optional cluster configuration
 	config []byte
+	// imageTrustPolicy specifies the optional image trust policy
+	imageTrustPolicy []byte
+	// hardening specifies the optional hardening profile
+	hardening hardening.Profile
+	// networkPolicy specifies whether to create the network policy baseline
+	networkPolicy bool
 	// resources specifies the optional Kubernetes resources to create
 	resources []byte
 	// gravityResources specifies the optional Gravity resources to create upon successful install
 	gravityResources []storage.UnknownResource
 	// InstallerTrustedCluster represents the trusted cluster for installer process
 	InstallerTrustedCluster storage.TrustedCluster
+	// CertAuthority specifies the optional certificate authority
+	// to issue the cluster certificates
+	CertAuthority *storage.CertAuthorityFiles
 }
 
 // AddInitPhase appends initialization phase to the provided plan
@@ -125,8 +140,9 @@ func (b *PlanBuilder) AddConfigurePhase(plan *storage.OperationPlan) {
 		Requires:    fsm.RequireIfPresent(plan, phases.InstallerPhase, phases.DecryptPhase),
 		Data: &storage.OperationPhaseData{
 			Install: &storage.InstallOperationData{
-				Env:    b.env,
-				Config: b.config,
+				Env:           b.env,
+				Config:        b.config,
+				CertAuthority: b.CertAuthority,
 			},
 		},
 		Step: 3,
@@ -207,7 +223,7 @@ func (b *PlanBuilder) AddPullPhase(plan *storage.OperationPlan) {
 func (b *PlanBuilder) AddMastersPhase(plan *storage.OperationPlan) error {
 	var masterPhases []storage.OperationPhase
 	for i, node := range b.Masters {
-		planetPackage, err := b.Application.Manifest.RuntimePackageForProfile(node.Role)
+		planetPackage, err := b.Application.Manifest.RuntimePackageForServer(node.Role, node.GetArch())
 		if err != nil {
 			return trace.Wrap(err)
 		}
@@ -261,7 +277,7 @@ func (b *PlanBuilder) AddMastersPhase(plan *storage.OperationPlan) error {
 func (b *PlanBuilder) AddNodesPhase(plan *storage.OperationPlan) error {
 	var nodePhases []storage.OperationPhase
 	for i, node := range b.Nodes {
-		planetPackage, err := b.Application.Manifest.RuntimePackageForProfile(node.Role)
+		planetPackage, err := b.Application.Manifest.RuntimePackageForServer(node.Role, node.GetArch())
 		if err != nil {
 			return trace.Wrap(err)
 		}
@@ -351,6 +367,46 @@ func (b *PlanBuilder) AddRBACPhase(plan *storage.OperationPlan) {
 	})
 }
 
+// AddHardeningPhase appends the phase that applies the hardening profile
+// to the Kubernetes resources, if the cluster is installed with one
+func (b *PlanBuilder) AddHardeningPhase(plan *storage.OperationPlan) {
+	if !b.hardening.IsEnabled() {
+		// Nothing to add
+		return
+	}
+	plan.Phases = append(plan.Phases, storage.OperationPhase{
+		ID:          phases.HardeningPhase,
+		Description: fmt.Sprintf("Apply %v hardening profile", b.hardening),
+		Data: &storage.OperationPhaseData{
+			Server: &b.Master,
+			Data:   string(b.hardening),
+		},
+		Requires: []string{phases.RBACPhase},
+		Step:     4,
+	})
+}
+
+// AddNetworkPolicyPhase appends the phase that creates the network policy
+// baseline, if the cluster is installed with one and its network type
+// enforces network policies
+func (b *PlanBuilder) AddNetworkPolicyPhase(plan *storage.OperationPlan) {
+	networkType := b.Application.Manifest.GetNetworkType(b.Cluster.Provider, "")
+	if !b.networkPolicy || !networkpolicy.IsSupported(networkType) {
+		// Nothing to add
+		return
+	}
+	plan.Phases = append(plan.Phases, storage.OperationPhase{
+		ID:          phases.NetworkPolicyPhase,
+		Description: "Create network policy baseline",
+		Data: &storage.OperationPhaseData{
+			Server:  &b.Master,
+			Package: &b.Application.Package,
+		},
+		Requires: []string{phases.RBACPhase},
+		Step:     4,
+	})
+}
+
 // AddSystemResourcesPhase appends phase that creates system Kubernetes
 // resources to the provided plan.
 func (b *PlanBuilder) AddSystemResourcesPhase(plan *storage.OperationPlan) {
@@ -365,6 +421,20 @@ func (b *PlanBuilder) AddSystemResourcesPhase(plan *storage.OperationPlan) {
 	})
 }
 
+// AddAzureLoadBalancerPhase appends a phase that exposes the API servers
+// with an Azure internal load balancer
+func (b *PlanBuilder) AddAzureLoadBalancerPhase(plan *storage.OperationPlan) {
+	plan.Phases = append(plan.Phases, storage.OperationPhase{
+		ID:          phases.AzureLoadBalancerPhase,
+		Description: "Create internal load balancer for Kubernetes API",
+		Data: &storage.OperationPhaseData{
+			Server: &b.Master,
+		},
+		Requires: []string{phases.SystemResourcesPhase},
+		Step:     4,
+	})
+}
+
 // AddUserResourcesPhase appends K8s resources initialization phase to the provided plan
 func (b *PlanBuilder) AddUserResourcesPhase(plan *storage.OperationPlan) {
 	if len(b.resources) == 0 {
@@ -405,6 +475,37 @@ func (b *PlanBuilder) AddGravityResourcesPhase(plan *storage.OperationPlan) {
 	})
 }
 
+// AddSmokeTestsPhase appends the phase that runs the application smoke tests
+func (b *PlanBuilder) AddSmokeTestsPhase(plan *storage.OperationPlan) {
+	tests := b.Application.Manifest.SmokeTestsFor(schema.SmokeTestInstall)
+	if len(tests) == 0 {
+		// Nothing to add
+		return
+	}
+	var testPhases []storage.OperationPhase
+	for _, test := range tests {
+		testPhases = append(testPhases, storage.OperationPhase{
+			ID:          fmt.Sprintf("%v/%v", phases.SmokeTestsPhase, test.Name),
+			Description: fmt.Sprintf("Run smoke test %v", test.Name),
+			Data: &storage.OperationPhaseData{
+				Server:      &b.Master,
+				Package:     &b.Application.Package,
+				ServiceUser: &b.ServiceUser,
+				Data:        test.Name,
+			},
+			Requires: []string{phases.EnableElectionPhase},
+			Step:     11,
+		})
+	}
+	plan.Phases = append(plan.Phases, storage.OperationPhase{
+		ID:          phases.SmokeTestsPhase,
+		Description: "Run application smoke tests",
+		Phases:      testPhases,
+		Requires:    []string{phases.EnableElectionPhase},
+		Step:        11,
+	})
+}
+
 // AddInstallOverlayPhase appends a phase to install a non-flannel overlay network
 func (b *PlanBuilder) AddInstallOverlayPhase(plan *storage.OperationPlan, locator *loc.Locator) {
 	plan.Phases = append(plan.Phases, storage.OperationPhase{
@@ -435,6 +536,12 @@ func (b *PlanBuilder) AddCorednsPhase(plan *storage.OperationPlan) {
 
 // AddExportPhase appends Docker images export phase to the provided plan
 func (b *PlanBuilder) AddExportPhase(plan *storage.OperationPlan) {
+	var install *storage.InstallOperationData
+	if len(b.imageTrustPolicy) != 0 {
+		install = &storage.InstallOperationData{
+			ImageTrustPolicy: b.imageTrustPolicy,
+		}
+	}
 	var exportPhases []storage.OperationPhase
 	for i, node := range b.Masters {
 		exportPhases = append(exportPhases, storage.OperationPhase{
@@ -445,6 +552,7 @@ func (b *PlanBuilder) AddExportPhase(plan *storage.OperationPlan) {
 				Server:     &b.Masters[i],
 				ExecServer: &b.Masters[i],
 				Package:    &b.Application.Package,
+				Install:    install,
 			},
 			Requires: []string{phases.WaitPhase},
 			Step:     4,
@@ -462,15 +570,26 @@ func (b *PlanBuilder) AddExportPhase(plan *storage.OperationPlan) {
 
 // AddRuntimePhase appends system applications installation phase to the provided plan
 func (b *PlanBuilder) AddRuntimePhase(plan *storage.OperationPlan) error {
-	runtimeLocators, err := app.GetDirectDeps(b.Runtime)
-	if err != nil {
-		return trace.Wrap(err)
+	// system applications of a layered app also include the applications
+	// of all cluster images it is built on top of
+	var runtimeLocators []loc.Locator
+	for _, base := range append([]app.Application{b.Runtime}, b.BaseImages...) {
+		locators, err := app.GetDirectDeps(base)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		runtimeLocators = append(runtimeLocators, locators...)
 	}
 	var runtimePhases []storage.OperationPhase
+	installed := make(map[string]struct{})
 	for i, locator := range runtimeLocators {
 		if b.skipDependency(locator) {
 			continue
 		}
+		if _, ok := installed[locator.Name]; ok {
+			continue
+		}
+		installed[locator.Name] = struct{}{}
 		runtimePhases = append(runtimePhases, storage.OperationPhase{
 			ID: fmt.Sprintf("%v/%v", phases.RuntimePhase, locator.Name),
 			Description: fmt.Sprintf("Install system application %v:%v",
@@ -570,12 +689,10 @@ func (b *PlanBuilder) skipDependency(dep loc.Locator) bool {
 // operation that can be used to build operation plan phases
 func (c *Config) GetPlanBuilder(operator ops.Operator, cluster ops.Site, op ops.SiteOperation) (*PlanBuilder, error) {
 	// determine which app and runtime are being installed
-	base := cluster.App.Manifest.Base()
-	if base == nil {
-		return nil, trace.BadParameter("application %v does not have a runtime",
-			cluster.App.Package)
-	}
-	runtime, err := c.Apps.GetApp(*base)
+	bases, err := app.GetBaseImages(app.Application{
+		Package:  cluster.App.Package,
+		Manifest: cluster.App.Manifest,
+	}, c.Apps)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -637,7 +754,8 @@ func (c *Config) GetPlanBuilder(operator ops.Operator, cluster ops.Site, op ops.
 			PackageEnvelope: cluster.App.PackageEnvelope,
 			Manifest:        cluster.App.Manifest,
 		},
-		Runtime:            *runtime,
+		Runtime:            bases[0],
+		BaseImages:         bases[1:],
 		TeleportPackage:    *teleportPackage,
 		RBACPackage:        *rbacPackage,
 		GravitySitePackage: *gravitySitePackage,
@@ -653,6 +771,7 @@ func (c *Config) GetPlanBuilder(operator ops.Operator, cluster ops.Site, op ops.
 			GID:  strconv.Itoa(c.ServiceUser.GID),
 		},
 		InstallerTrustedCluster: trustedCluster,
+		CertAuthority:           c.CertAuthority,
 	}
 	err = addResources(builder, cluster.Resources, c.RuntimeResources, c.ClusterResources)
 	if err != nil {
@@ -728,8 +847,18 @@ func addResources(builder *PlanBuilder, resourceBytes []byte, runtimeResources [
 			kubernetesResources = append(kubernetesResources, configmap)
 		case storage.KindClusterConfiguration:
 			builder.config = res.Raw
+			config, err := clusterconfig.Unmarshal(res.Raw)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			builder.hardening = hardening.Profile(config.GetHardeningProfile())
+			builder.networkPolicy = config.IsNetworkPolicyEnabled()
 			configmap := opsservice.NewConfigurationConfigMap(res.Raw)
 			kubernetesResources = append(kubernetesResources, configmap)
+		case storage.KindImageTrustPolicy:
+			builder.imageTrustPolicy = res.Raw
+			// The policy is also created in the cluster using the regular workflow
+			rest = append(rest, res)
 		default:
 			// Filter out resources that are created using the regular workflow
 			rest = append(rest, res)
@@ -539,6 +539,123 @@ func (m *OperationKey) GetID() string {
 	return ""
 }
 
+// StatusRequest describes a request to query the operation status
+type StatusRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StatusRequest) Reset()         { *m = StatusRequest{} }
+func (m *StatusRequest) String() string { return proto.CompactTextString(m) }
+func (*StatusRequest) ProtoMessage()    {}
+func (*StatusRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_675879a591bd3155, []int{10}
+}
+func (m *StatusRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StatusRequest.Unmarshal(m, b)
+}
+func (m *StatusRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StatusRequest.Marshal(b, m, deterministic)
+}
+func (m *StatusRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StatusRequest.Merge(m, src)
+}
+func (m *StatusRequest) XXX_Size() int {
+	return xxx_messageInfo_StatusRequest.Size(m)
+}
+func (m *StatusRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StatusRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StatusRequest proto.InternalMessageInfo
+
+// StatusResponse describes the status of the operation
+type StatusResponse struct {
+	// Key identifies the operation.
+	// Unset if the operation has not been created yet
+	Key *OperationKey `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// State specifies the operation state
+	State string `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	// Completion specifies the operation completion percentage
+	Completion int32 `protobuf:"varint,3,opt,name=completion,proto3" json:"completion,omitempty"`
+	// Message specifies the last progress message
+	Message string `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	// Token specifies the token the nodes use to join the operation
+	Token string `protobuf:"bytes,5,opt,name=token,proto3" json:"token,omitempty"`
+	// AgentAddr specifies the address the joining nodes connect to
+	AgentAddr            string   `protobuf:"bytes,6,opt,name=agent_addr,json=agentAddr,proto3" json:"agent_addr,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StatusResponse) Reset()         { *m = StatusResponse{} }
+func (m *StatusResponse) String() string { return proto.CompactTextString(m) }
+func (*StatusResponse) ProtoMessage()    {}
+func (*StatusResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_675879a591bd3155, []int{11}
+}
+func (m *StatusResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StatusResponse.Unmarshal(m, b)
+}
+func (m *StatusResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StatusResponse.Marshal(b, m, deterministic)
+}
+func (m *StatusResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StatusResponse.Merge(m, src)
+}
+func (m *StatusResponse) XXX_Size() int {
+	return xxx_messageInfo_StatusResponse.Size(m)
+}
+func (m *StatusResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_StatusResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StatusResponse proto.InternalMessageInfo
+
+func (m *StatusResponse) GetKey() *OperationKey {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *StatusResponse) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *StatusResponse) GetCompletion() int32 {
+	if m != nil {
+		return m.Completion
+	}
+	return 0
+}
+
+func (m *StatusResponse) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *StatusResponse) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *StatusResponse) GetAgentAddr() string {
+	if m != nil {
+		return m.AgentAddr
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterEnum("installer.ProgressResponse_Status", ProgressResponse_Status_name, ProgressResponse_Status_value)
 	proto.RegisterType((*Phase)(nil), "installer.Phase")
@@ -551,56 +668,63 @@ func init() {
 	proto.RegisterType((*ProgressResponse)(nil), "installer.ProgressResponse")
 	proto.RegisterType((*Error)(nil), "installer.Error")
 	proto.RegisterType((*OperationKey)(nil), "installer.OperationKey")
+	proto.RegisterType((*StatusRequest)(nil), "installer.StatusRequest")
+	proto.RegisterType((*StatusResponse)(nil), "installer.StatusResponse")
 }
 
 func init() { proto.RegisterFile("installer.proto", fileDescriptor_675879a591bd3155) }
 
 var fileDescriptor_675879a591bd3155 = []byte{
-	// 697 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0xcd, 0x4e, 0xdb, 0x4a,
-	0x14, 0xc6, 0x09, 0x0e, 0xf1, 0x09, 0x90, 0x30, 0x5c, 0x71, 0x73, 0xcd, 0x6d, 0x48, 0xbd, 0xa8,
-	0x52, 0xa9, 0x0a, 0x6d, 0xba, 0x41, 0x55, 0x55, 0x29, 0x24, 0x11, 0x8a, 0xa0, 0x49, 0x34, 0x29,
-	0xea, 0x12, 0x39, 0xf6, 0xc1, 0x44, 0x38, 0x1e, 0xd7, 0x1e, 0x0b, 0x90, 0xfa, 0x00, 0x88, 0x75,
-	0xb7, 0xac, 0xca, 0xa2, 0xfb, 0xee, 0xfa, 0x00, 0x7d, 0x0c, 0x16, 0x3c, 0x49, 0x65, 0x8f, 0x93,
-	0x38, 0xa9, 0x42, 0xd5, 0x9d, 0xcf, 0xcf, 0x77, 0xbe, 0xf3, 0xe3, 0x6f, 0x20, 0x3f, 0x74, 0x7c,
-	0xae, 0xdb, 0x36, 0x7a, 0x55, 0xd7, 0x63, 0x9c, 0x11, 0x65, 0xe2, 0x50, 0xb7, 0x2d, 0xc6, 0x2c,
-	0x1b, 0x77, 0xa3, 0xc0, 0x20, 0x38, 0xdd, 0xc5, 0x91, 0xcb, 0xaf, 0x44, 0x9e, 0x0a, 0x16, 0xb3,
-	0x98, 0xf8, 0xd6, 0x3e, 0x83, 0xdc, 0x3b, 0xd3, 0x7d, 0x24, 0x5b, 0x90, 0x1a, 0x9a, 0x45, 0xa9,
-	0x2c, 0x55, 0x94, 0xfd, 0xcc, 0xc3, 0xfd, 0x4e, 0xaa, 0xdd, 0xa4, 0xa9, 0xa1, 0x49, 0x9e, 0x43,
-	0xfa, 0x1c, 0xaf, 0x8a, 0xa9, 0xb2, 0x54, 0xc9, 0xd5, 0xfe, 0xad, 0x4e, 0x39, 0xbb, 0x2e, 0x7a,
-	0x3a, 0x1f, 0x32, 0xe7, 0x10, 0xaf, 0x68, 0x98, 0x43, 0x54, 0xc8, 0x7a, 0xcc, 0xb6, 0x07, 0xba,
-	0x71, 0x5e, 0x4c, 0x97, 0xa5, 0x4a, 0x96, 0x4e, 0x6c, 0xf2, 0x0f, 0xc8, 0xa7, 0xcc, 0x33, 0xb0,
-	0xb8, 0x1c, 0x05, 0x84, 0xa1, 0xed, 0xc1, 0x7a, 0xeb, 0x12, 0x8d, 0x80, 0x23, 0xc5, 0x4f, 0x01,
-	0xfa, 0x9c, 0x3c, 0x03, 0xd9, 0x0d, 0xfb, 0x89, 0x3a, 0xc9, 0xd5, 0x0a, 0x09, 0xc2, 0xa8, 0x4f,
-	0x2a, 0xc2, 0x5a, 0x17, 0xf2, 0x7d, 0xe4, 0x7d, 0xae, 0xff, 0x35, 0x34, 0x6c, 0xc5, 0x0f, 0x71,
-	0xd1, 0x4c, 0x0a, 0x15, 0x86, 0xf6, 0x16, 0xf2, 0x0d, 0x36, 0x72, 0x6d, 0x9c, 0x16, 0x8c, 0x47,
-	0x97, 0xfe, 0x3c, 0xba, 0xb6, 0x0e, 0xab, 0xf5, 0x01, 0xf3, 0x78, 0x0c, 0xd5, 0x0e, 0x21, 0xdf,
-	0x3f, 0x0b, 0xb8, 0xc9, 0x2e, 0x9c, 0x71, 0xb5, 0xff, 0x41, 0x31, 0x62, 0x02, 0xb1, 0xe7, 0x2c,
-	0x9d, 0x3a, 0xc2, 0xdd, 0xe1, 0xe5, 0x90, 0x37, 0x98, 0x29, 0xfa, 0x92, 0xe9, 0xc4, 0xd6, 0x2a,
-	0x40, 0x9a, 0x38, 0x08, 0x2c, 0x8a, 0xee, 0x94, 0x82, 0x10, 0x58, 0x76, 0x75, 0x7e, 0x26, 0x4e,
-	0x46, 0xa3, 0x6f, 0xed, 0x67, 0x0a, 0x0a, 0x3d, 0x8f, 0x59, 0x1e, 0xfa, 0x3e, 0x45, 0xdf, 0x65,
-	0x8e, 0x8f, 0xa4, 0x08, 0x2b, 0x23, 0xf4, 0x7d, 0xdd, 0xc2, 0x38, 0x77, 0x6c, 0x92, 0x37, 0x90,
-	0x09, 0x87, 0x0f, 0xfc, 0x88, 0x72, 0xbd, 0xa6, 0x25, 0x57, 0x36, 0x57, 0xa6, 0xda, 0x8f, 0x32,
-	0x69, 0x8c, 0x08, 0xb7, 0x8d, 0x9e, 0xc7, 0xbc, 0xe8, 0xd2, 0xb3, 0xdb, 0x6e, 0x85, 0x7e, 0x2a,
-	0xc2, 0xda, 0x77, 0x09, 0x32, 0x02, 0x4a, 0x4a, 0xb0, 0x72, 0xdc, 0x39, 0xec, 0x74, 0x3f, 0x76,
-	0x0a, 0x4b, 0xea, 0xc6, 0xcd, 0x6d, 0x79, 0x4d, 0x04, 0x8e, 0x9d, 0x73, 0x87, 0x5d, 0x38, 0x44,
-	0x03, 0xa5, 0xd1, 0x7d, 0xdf, 0x3b, 0x6a, 0x7d, 0x68, 0x35, 0x0b, 0x92, 0xba, 0x79, 0x73, 0x5b,
-	0xce, 0x8b, 0x8c, 0xc6, 0x64, 0x4f, 0xaf, 0x60, 0x63, 0x92, 0x73, 0xd2, 0x6b, 0x75, 0x9a, 0xed,
-	0xce, 0x41, 0x21, 0xa5, 0xaa, 0x37, 0xb7, 0xe5, 0xad, 0xb9, 0xdc, 0x1e, 0x3a, 0xe6, 0xd0, 0xb1,
-	0x42, 0xda, 0xfa, 0x7e, 0x97, 0x86, 0x45, 0xd3, 0x49, 0xda, 0xe8, 0x60, 0x68, 0xaa, 0xe4, 0xfa,
-	0x6b, 0x69, 0xe9, 0xdb, 0x5d, 0x69, 0xe9, 0xc7, 0x5d, 0x29, 0x6e, 0x55, 0x7b, 0x0a, 0x72, 0x34,
-	0xc5, 0xe2, 0xe5, 0x69, 0xd7, 0x12, 0xac, 0x26, 0x7f, 0x04, 0xf2, 0x02, 0x40, 0x37, 0x0c, 0x16,
-	0x38, 0xfc, 0x64, 0xa2, 0xa4, 0xb5, 0x87, 0xfb, 0x1d, 0xa5, 0x2e, 0xbc, 0xed, 0x26, 0x55, 0xe2,
-	0x84, 0xb6, 0x49, 0x6a, 0xb0, 0x6a, 0xd8, 0x81, 0xcf, 0xd1, 0x3b, 0x71, 0xf4, 0x51, 0xfc, 0x33,
-	0xee, 0xe7, 0x1f, 0xee, 0x77, 0x72, 0x0d, 0xe1, 0xef, 0xe8, 0x23, 0xa4, 0x39, 0x63, 0x6a, 0xc4,
-	0x1a, 0x4d, 0xcf, 0x6b, 0xb4, 0xf6, 0x25, 0x0d, 0x72, 0xdd, 0x42, 0x87, 0x93, 0x06, 0xac, 0xc4,
-	0x82, 0x22, 0xff, 0x25, 0x2f, 0x32, 0x23, 0x32, 0x75, 0xfb, 0x91, 0x3b, 0xbf, 0x94, 0xc8, 0x3b,
-	0xc8, 0x8e, 0x97, 0x48, 0xd4, 0x44, 0xea, 0x9c, 0x3e, 0xd4, 0xad, 0xaa, 0x78, 0x65, 0xaa, 0xe3,
-	0x57, 0xa6, 0xda, 0x0a, 0x5f, 0x99, 0x10, 0x3f, 0xd6, 0xe6, 0x0c, 0x7e, 0x4e, 0xb0, 0x0b, 0xf1,
-	0x7b, 0x20, 0x47, 0xb7, 0x21, 0x49, 0xcd, 0x25, 0xe5, 0xf5, 0x28, 0x73, 0x2c, 0xbb, 0x59, 0xe6,
-	0x59, 0x2d, 0x2e, 0xc4, 0x1f, 0xc1, 0xe6, 0x01, 0x3a, 0xe1, 0x4d, 0x31, 0xa1, 0x38, 0xf2, 0x24,
-	0x51, 0xea, 0x77, 0x25, 0x2e, 0xaa, 0x36, 0xc8, 0x44, 0xf6, 0xeb, 0x5f, 0x01, 0x00, 0x00, 0xff,
-	0xff, 0x3d, 0x71, 0x20, 0x38, 0xa9, 0x05, 0x00, 0x00,
+	// 782 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0xcf, 0x4e, 0xe3, 0x46,
+	0x18, 0xc7, 0x09, 0x0e, 0xf1, 0x17, 0x48, 0xc2, 0x80, 0xa8, 0x31, 0x25, 0xa4, 0x3e, 0x54, 0xa9,
+	0x54, 0x85, 0x36, 0xbd, 0xa0, 0xaa, 0xaa, 0x1a, 0x92, 0x08, 0x45, 0xd0, 0x24, 0x9a, 0x14, 0xf5,
+	0x18, 0x39, 0xf6, 0x60, 0xa2, 0x24, 0x1e, 0xd7, 0x1e, 0x0b, 0x90, 0xfa, 0x00, 0x88, 0x77, 0xe0,
+	0xb4, 0x1c, 0xf6, 0xbe, 0xb7, 0x3d, 0xed, 0x69, 0x1f, 0x61, 0x8f, 0x1c, 0x78, 0x92, 0x95, 0x67,
+	0x9c, 0xc4, 0x09, 0x84, 0xdd, 0xbd, 0xcd, 0xf7, 0xff, 0xcf, 0xfc, 0xbe, 0x1f, 0xe4, 0x06, 0x8e,
+	0xcf, 0x8c, 0xd1, 0x88, 0x78, 0x65, 0xd7, 0xa3, 0x8c, 0x22, 0x65, 0xaa, 0xd0, 0xf6, 0x6c, 0x4a,
+	0xed, 0x11, 0x39, 0xe4, 0x86, 0x7e, 0x70, 0x71, 0x48, 0xc6, 0x2e, 0xbb, 0x11, 0x7e, 0x1a, 0xd8,
+	0xd4, 0xa6, 0xe2, 0xad, 0xff, 0x0f, 0x72, 0xe7, 0xd2, 0xf0, 0x09, 0xda, 0x81, 0xc4, 0xc0, 0x52,
+	0xa5, 0xa2, 0x54, 0x52, 0x8e, 0x53, 0x4f, 0x8f, 0x07, 0x89, 0x66, 0x1d, 0x27, 0x06, 0x16, 0xfa,
+	0x09, 0x92, 0x43, 0x72, 0xa3, 0x26, 0x8a, 0x52, 0x29, 0x53, 0xf9, 0xae, 0x3c, 0xab, 0xd9, 0x76,
+	0x89, 0x67, 0xb0, 0x01, 0x75, 0x4e, 0xc9, 0x0d, 0x0e, 0x7d, 0x90, 0x06, 0x69, 0x8f, 0x8e, 0x46,
+	0x7d, 0xc3, 0x1c, 0xaa, 0xc9, 0xa2, 0x54, 0x4a, 0xe3, 0xa9, 0x8c, 0xb6, 0x41, 0xbe, 0xa0, 0x9e,
+	0x49, 0xd4, 0x55, 0x6e, 0x10, 0x82, 0x7e, 0x04, 0xd9, 0xc6, 0x35, 0x31, 0x03, 0x46, 0x30, 0xf9,
+	0x2f, 0x20, 0x3e, 0x43, 0x3f, 0x82, 0xec, 0x86, 0xfd, 0xf0, 0x4e, 0x32, 0x95, 0x7c, 0xac, 0x20,
+	0xef, 0x13, 0x0b, 0xb3, 0xde, 0x86, 0x5c, 0x97, 0xb0, 0x2e, 0x33, 0xbe, 0x39, 0x34, 0x6c, 0xc5,
+	0x0f, 0xe3, 0xf8, 0x4c, 0x0a, 0x16, 0x82, 0xfe, 0x07, 0xe4, 0x6a, 0x74, 0xec, 0x8e, 0xc8, 0x2c,
+	0x61, 0x34, 0xba, 0xf4, 0xe5, 0xd1, 0xf5, 0x2c, 0xac, 0x57, 0xfb, 0xd4, 0x63, 0x51, 0xa8, 0x7e,
+	0x0a, 0xb9, 0xee, 0x65, 0xc0, 0x2c, 0x7a, 0xe5, 0x4c, 0xb2, 0x7d, 0x0f, 0x8a, 0x19, 0x15, 0x10,
+	0x7b, 0x4e, 0xe3, 0x99, 0x22, 0xdc, 0x1d, 0xb9, 0x1e, 0xb0, 0x1a, 0xb5, 0x44, 0x5f, 0x32, 0x9e,
+	0xca, 0x7a, 0x09, 0x50, 0x9d, 0xf4, 0x03, 0x1b, 0x13, 0x77, 0x56, 0x02, 0x21, 0x58, 0x75, 0x0d,
+	0x76, 0x29, 0xbe, 0x0c, 0xf3, 0xb7, 0xfe, 0x31, 0x01, 0xf9, 0x8e, 0x47, 0x6d, 0x8f, 0xf8, 0x3e,
+	0x26, 0xbe, 0x4b, 0x1d, 0x9f, 0x20, 0x15, 0xd6, 0xc6, 0xc4, 0xf7, 0x0d, 0x9b, 0x44, 0xbe, 0x13,
+	0x11, 0xfd, 0x0e, 0xa9, 0x70, 0xf8, 0xc0, 0xe7, 0x25, 0xb3, 0x15, 0x3d, 0xbe, 0xb2, 0x85, 0x34,
+	0xe5, 0x2e, 0xf7, 0xc4, 0x51, 0x44, 0xb8, 0x6d, 0xe2, 0x79, 0xd4, 0x53, 0x93, 0xcf, 0xb6, 0xdd,
+	0x08, 0xf5, 0x58, 0x98, 0xf5, 0x77, 0x12, 0xa4, 0x44, 0x28, 0x2a, 0xc0, 0xda, 0x79, 0xeb, 0xb4,
+	0xd5, 0xfe, 0xb7, 0x95, 0x5f, 0xd1, 0x36, 0xef, 0xee, 0x8b, 0x1b, 0xc2, 0x70, 0xee, 0x0c, 0x1d,
+	0x7a, 0xe5, 0x20, 0x1d, 0x94, 0x5a, 0xfb, 0xef, 0xce, 0x59, 0xe3, 0x9f, 0x46, 0x3d, 0x2f, 0x69,
+	0x5b, 0x77, 0xf7, 0xc5, 0x9c, 0xf0, 0xa8, 0x4d, 0xf7, 0xf4, 0x2b, 0x6c, 0x4e, 0x7d, 0x7a, 0x9d,
+	0x46, 0xab, 0xde, 0x6c, 0x9d, 0xe4, 0x13, 0x9a, 0x76, 0x77, 0x5f, 0xdc, 0x59, 0xf0, 0xed, 0x10,
+	0xc7, 0x1a, 0x38, 0x76, 0x58, 0xb6, 0x7a, 0xdc, 0xc6, 0x61, 0xd2, 0x64, 0xbc, 0x2c, 0xff, 0x30,
+	0x62, 0x69, 0xe8, 0xf6, 0x4d, 0x61, 0xe5, 0xed, 0x43, 0x61, 0xe5, 0xfd, 0x43, 0x21, 0x6a, 0x55,
+	0xff, 0x01, 0x64, 0x3e, 0xc5, 0xf2, 0xe5, 0xe9, 0xb7, 0x12, 0xac, 0xc7, 0x81, 0x80, 0x7e, 0x06,
+	0x30, 0x4c, 0x93, 0x06, 0x0e, 0xeb, 0x4d, 0x2f, 0x69, 0xe3, 0xe9, 0xf1, 0x40, 0xa9, 0x0a, 0x6d,
+	0xb3, 0x8e, 0x95, 0xc8, 0xa1, 0x69, 0xa1, 0x0a, 0xac, 0x9b, 0xa3, 0xc0, 0x67, 0xc4, 0xeb, 0x39,
+	0xc6, 0x38, 0x02, 0xe3, 0x71, 0xee, 0xe9, 0xf1, 0x20, 0x53, 0x13, 0xfa, 0x96, 0x31, 0x26, 0x38,
+	0x63, 0xce, 0x84, 0xe8, 0x46, 0x93, 0x8b, 0x37, 0xaa, 0xe7, 0x20, 0x1a, 0x69, 0x02, 0xbf, 0x0f,
+	0x12, 0x64, 0x27, 0x9a, 0x08, 0x05, 0x5f, 0x0f, 0xe6, 0x97, 0x0f, 0x04, 0x15, 0x00, 0x22, 0xb8,
+	0x0e, 0xa8, 0xc3, 0x9b, 0x90, 0x71, 0x4c, 0x13, 0xdf, 0xd4, 0xea, 0x3c, 0xcc, 0xb6, 0x41, 0x66,
+	0x74, 0x48, 0x1c, 0x55, 0x16, 0xf9, 0xb8, 0x80, 0xf6, 0x01, 0x0c, 0x9b, 0x38, 0xac, 0x67, 0x58,
+	0x96, 0xa7, 0xa6, 0xb8, 0x49, 0xe1, 0x9a, 0xaa, 0x65, 0x79, 0x95, 0x4f, 0x49, 0x90, 0xab, 0xa1,
+	0x84, 0x6a, 0xb0, 0x16, 0x91, 0x04, 0xda, 0x8d, 0xa3, 0x6c, 0x8e, 0x38, 0xb4, 0xbd, 0x57, 0xb0,
+	0xfb, 0x8b, 0x84, 0xfe, 0x84, 0xf4, 0x04, 0x18, 0x48, 0x8b, 0xb9, 0x2e, 0xdc, 0xbc, 0xb6, 0x53,
+	0x16, 0xcc, 0x59, 0x9e, 0x30, 0x67, 0xb9, 0x11, 0x32, 0x67, 0x18, 0x3f, 0xe1, 0x9b, 0xb9, 0xf8,
+	0x05, 0x12, 0x5a, 0x1a, 0x7f, 0x04, 0x32, 0xc7, 0x1b, 0x8a, 0xaf, 0x3e, 0x4e, 0x19, 0xaf, 0x56,
+	0x8e, 0xa8, 0x64, 0xbe, 0xf2, 0x3c, 0xbf, 0x2c, 0x8d, 0x3f, 0x83, 0xad, 0x13, 0xe2, 0x84, 0x7f,
+	0x4c, 0x62, 0x2c, 0x82, 0xf6, 0x63, 0xa9, 0x9e, 0xb3, 0xcb, 0xd2, 0x6c, 0x7f, 0x81, 0x72, 0x22,
+	0x46, 0x0e, 0x7c, 0xa4, 0xc6, 0xdb, 0x89, 0x03, 0x50, 0xdb, 0x7d, 0xc1, 0x22, 0xfe, 0xa2, 0x9f,
+	0xe2, 0x19, 0x7f, 0xfb, 0x3c, 0x00, 0xdf, 0xd2, 0x09, 0x0a, 0xbf, 0x06, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -630,6 +754,10 @@ type AgentClient interface {
 	Shutdown(ctx context.Context, in *ShutdownRequest, opts ...grpc.CallOption) (*types.Empty, error)
 	// GenerateDebugReport requests the installer to generate a debug report
 	GenerateDebugReport(ctx context.Context, in *DebugReportRequest, opts ...grpc.CallOption) (*types.Empty, error)
+	// GetStatus returns the status of the operation.
+	// Unlike Execute, it neither starts nor waits for the operation
+	// which makes it suitable for polling the progress from external tools
+	GetStatus(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error)
 }
 
 type agentClient struct {
@@ -717,6 +845,15 @@ func (c *agentClient) GenerateDebugReport(ctx context.Context, in *DebugReportRe
 	return out, nil
 }
 
+func (c *agentClient) GetStatus(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, "/installer.Agent/GetStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AgentServer is the server API for Agent service.
 type AgentServer interface {
 	// Execute runs the operation specified with request.
@@ -734,6 +871,10 @@ type AgentServer interface {
 	Shutdown(context.Context, *ShutdownRequest) (*types.Empty, error)
 	// GenerateDebugReport requests the installer to generate a debug report
 	GenerateDebugReport(context.Context, *DebugReportRequest) (*types.Empty, error)
+	// GetStatus returns the status of the operation.
+	// Unlike Execute, it neither starts nor waits for the operation
+	// which makes it suitable for polling the progress from external tools
+	GetStatus(context.Context, *StatusRequest) (*StatusResponse, error)
 }
 
 // UnimplementedAgentServer can be embedded to have forward compatible implementations.
@@ -758,6 +899,9 @@ func (*UnimplementedAgentServer) Shutdown(ctx context.Context, req *ShutdownRequ
 func (*UnimplementedAgentServer) GenerateDebugReport(ctx context.Context, req *DebugReportRequest) (*types.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GenerateDebugReport not implemented")
 }
+func (*UnimplementedAgentServer) GetStatus(ctx context.Context, req *StatusRequest) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
 
 func RegisterAgentServer(s *grpc.Server, srv AgentServer) {
 	s.RegisterService(&_Agent_serviceDesc, srv)
@@ -874,6 +1018,24 @@ func _Agent_GenerateDebugReport_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Agent_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/installer.Agent/GetStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).GetStatus(ctx, req.(*StatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Agent_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "installer.Agent",
 	HandlerType: (*AgentServer)(nil),
@@ -898,6 +1060,10 @@ var _Agent_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GenerateDebugReport",
 			Handler:    _Agent_GenerateDebugReport_Handler,
 		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _Agent_GetStatus_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
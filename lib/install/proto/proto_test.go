@@ -0,0 +1,68 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package installer
+
+import (
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	. "gopkg.in/check.v1"
+)
+
+func TestProto(t *testing.T) { TestingT(t) }
+
+type ProtoSuite struct{}
+
+var _ = Suite(&ProtoSuite{})
+
+func (*ProtoSuite) TestStatusResponseRoundtrip(c *C) {
+	resp := &StatusResponse{
+		Key:        &OperationKey{AccountID: "system", ClusterName: "example", ID: "op-1"},
+		State:      "install_in_progress",
+		Completion: 42,
+		Message:    "Installing system service",
+		Token:      "token",
+		AgentAddr:  "10.0.0.1:61009",
+	}
+	data, err := proto.Marshal(resp)
+	c.Assert(err, IsNil)
+	var decoded StatusResponse
+	c.Assert(proto.Unmarshal(data, &decoded), IsNil)
+	c.Assert(decoded.String(), Equals, resp.String())
+}
+
+func (*ProtoSuite) TestDescriptorMatchesService(c *C) {
+	fd, md := descriptor.ForMessage(&StatusResponse{})
+	c.Assert(md.GetName(), Equals, "StatusResponse")
+	var methods []string
+	for _, method := range fd.Service[0].Method {
+		methods = append(methods, method.GetName())
+	}
+	// Streaming methods are listed separately in the service description
+	var descMethods []string
+	for _, stream := range _Agent_serviceDesc.Streams {
+		descMethods = append(descMethods, stream.StreamName)
+	}
+	for _, method := range _Agent_serviceDesc.Methods {
+		descMethods = append(descMethods, method.MethodName)
+	}
+	c.Assert(descMethods, DeepEquals, methods)
+	c.Assert(methods, DeepEquals, []string{
+		"Execute", "Complete", "SetState", "Abort", "Shutdown", "GenerateDebugReport", "GetStatus",
+	})
+}
@@ -156,6 +156,20 @@ func (r *Server) GenerateDebugReport(ctx context.Context, req *installpb.DebugRe
 	return nil, status.Error(codes.Unimplemented, "not implemented")
 }
 
+// GetStatus returns the status of the operation.
+// Implements installpb.AgentServer
+func (r *Server) GetStatus(ctx context.Context, req *installpb.StatusRequest) (*installpb.StatusResponse, error) {
+	if reporter, ok := r.executor.(StatusReporter); ok {
+		resp, err := reporter.GetStatus()
+		if err != nil {
+			// Not wrapping err as it passes the gRPC boundary
+			return nil, err
+		}
+		return resp, nil
+	}
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
 // Executor wraps a potentially failing operation
 type Executor interface {
 	Completer
@@ -173,6 +187,12 @@ type DebugReporter interface {
 	GenerateDebugReport(path string) error
 }
 
+// StatusReporter allows to query the status of the operation
+type StatusReporter interface {
+	// GetStatus returns the status of the operation
+	GetStatus() (*installpb.StatusResponse, error)
+}
+
 // Completer describes completion outcomes
 type Completer interface {
 	// HandleAborted indicates that the operation has been aborted and completion steps
@@ -26,6 +26,7 @@ import (
 
 	"github.com/gravitational/gravity/lib/app"
 	"github.com/gravitational/gravity/lib/checks"
+	"github.com/gravitational/gravity/lib/cloudprovider/gce"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/fsm"
 	"github.com/gravitational/gravity/lib/install/dispatcher"
@@ -183,6 +184,8 @@ func FetchCloudMetadata(cloudProvider string, config *pb.RuntimeConfig) error {
 		docLink = "https://gravitational.com/gravity/docs/requirements/#aws-iam-policy"
 	case schema.ProviderGCE:
 		docLink = "https://gravitational.com/gravity/docs/installation/#installing-on-google-compute-engine"
+	case schema.ProviderAzure:
+		docLink = "https://gravitational.com/gravity/docs/installation/#azure"
 	default:
 		return nil
 	}
@@ -196,9 +199,25 @@ func FetchCloudMetadata(cloudProvider string, config *pb.RuntimeConfig) error {
 			"--cloud-provider=generic flag", strings.ToUpper(cloudProvider), err, docLink)
 	}
 	config.CloudMetadata = metadata
+	if cloudProvider == schema.ProviderGCE {
+		if err := checkGCEScopes(); err != nil {
+			return trace.Wrap(err, "check the documentation to see the required "+
+				"instance permissions (%v)", docLink)
+		}
+	}
 	return nil
 }
 
+// checkGCEScopes verifies that the local instance service account has
+// the API scopes required by the GCE cloud provider integration
+func checkGCEScopes() error {
+	instance, err := gce.NewLocalInstance()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(gce.ValidateScopes(instance.Scopes))
+}
+
 // LoadRPCCredentials loads and validates the contents of the default RPC credentials package
 func LoadRPCCredentials(ctx context.Context, packages pack.PackageService) (*rpcserver.Credentials, error) {
 	tls, err := loadCredentialsFromPackage(ctx, packages, loc.RPCSecrets)
@@ -258,6 +277,7 @@ func GetServerUpdateRequest(op ops.SiteOperation, servers []checks.ServerInfo) (
 			Hostname:    serverInfo.GetHostname(),
 			Role:        serverInfo.Role,
 			OSInfo:      serverInfo.GetOS(),
+			Arch:        serverInfo.GetArch(),
 			Mounts:      pb.MountsFromProto(serverInfo.Mounts),
 			User:        serverInfo.GetUser(),
 			Provisioner: op.Provisioner,
@@ -268,6 +288,8 @@ func GetServerUpdateRequest(op ops.SiteOperation, servers []checks.ServerInfo) (
 			server.InstanceType = serverInfo.CloudMetadata.InstanceType
 			server.InstanceID = serverInfo.CloudMetadata.InstanceId
 		}
+		server.Labels, server.Taints = NodeLabelsAndTaints(serverInfo.KeyValues)
+		server.NodePool = serverInfo.KeyValues[ops.AgentNodePool]
 		req.Servers = append(req.Servers, server)
 		profile := req.Profiles[serverInfo.Role]
 		profile.Count += 1
@@ -276,6 +298,31 @@ func GetServerUpdateRequest(op ops.SiteOperation, servers []checks.ServerInfo) (
 	return &req, nil
 }
 
+// NodeKeyValues returns the agent runtime parameters that specify
+// the custom labels and taints for the node
+func NodeKeyValues(labels, taints []string) map[string]string {
+	keyValues := make(map[string]string)
+	if len(labels) != 0 {
+		keyValues[ops.AgentLabels] = strings.Join(labels, ",")
+	}
+	if len(taints) != 0 {
+		keyValues[ops.AgentTaints] = strings.Join(taints, ",")
+	}
+	return keyValues
+}
+
+// NodeLabelsAndTaints extracts the custom node labels and taints
+// from the specified agent runtime parameters
+func NodeLabelsAndTaints(keyValues map[string]string) (labels map[string]string, taints []string) {
+	if value := keyValues[ops.AgentLabels]; value != "" {
+		labels = utils.ParseLabels(value)
+	}
+	if value := keyValues[ops.AgentTaints]; value != "" {
+		taints = strings.Split(value, ",")
+	}
+	return labels, taints
+}
+
 // ServerRequirements computes server requirements based on the selected flavor
 func ServerRequirements(flavor schema.Flavor) map[string]storage.ServerProfileRequest {
 	result := make(map[string]storage.ServerProfileRequest)
@@ -45,6 +45,11 @@ func (d *drain) drainPods(ctx context.Context) error {
 	}
 
 	err = d.deleteOrEvictPods(ctx, pods)
+	if err != nil && d.forceAfter > 0 && ctx.Err() == nil {
+		// Some pods could not be evicted in time, delete the remaining pods
+		// regardless of their disruption budgets
+		err = d.forceDeletePods(ctx)
+	}
 	if err != nil {
 		pendingPods, err := d.getPodsForDeletion()
 		if err != nil {
@@ -63,17 +68,37 @@ func (d *drain) deleteOrEvictPods(ctx context.Context, pods []v1.Pod) error {
 		return nil
 	}
 
+	if d.ignorePDBs {
+		return trace.Wrap(d.deletePods(ctx, pods))
+	}
+
 	policyGroupVersion, err := queryEvictionPolicyGroupVersion(d.client.Discovery())
 	if err != nil {
 		return trace.Wrap(err)
 	}
 
 	if len(policyGroupVersion) > 0 {
+		if d.forceAfter > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d.forceAfter)
+			defer cancel()
+		}
 		return trace.Wrap(d.evictPods(ctx, pods, policyGroupVersion))
 	}
 	return trace.Wrap(d.deletePods(ctx, pods))
 }
 
+// forceDeletePods deletes the pods remaining on the node bypassing Eviction API
+func (d *drain) forceDeletePods(ctx context.Context) error {
+	pods, err := d.getPodsForDeletion()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	log.WithField("node", d.nodeName).Warnf("Failed to evict pods in %v, will delete: %v.",
+		d.forceAfter, formatPodList(pods))
+	return trace.Wrap(d.deletePods(ctx, pods))
+}
+
 func (d *drain) evictPods(ctx context.Context, pods []v1.Pod, policyGroupVersion string) error {
 	errCh := make(chan error, len(pods))
 
@@ -165,7 +190,7 @@ func (d *drain) evictPod(pod v1.Pod, policyGroupVersion string) error {
 }
 
 // getPodsForDeletion returns all the pods to delete.
-// DaemonSet pods are always filtered out
+// DaemonSet pods are filtered out unless configured otherwise
 func (d *drain) getPodsForDeletion() (pods []v1.Pod, err error) {
 	podList, err := d.client.Core().Pods(metav1.NamespaceAll).List(metav1.ListOptions{
 		FieldSelector: fields.SelectorFromSet(fields.Set{"spec.nodeName": d.nodeName}).String()})
@@ -201,6 +226,9 @@ func (d *drain) canDeletePod(pod v1.Pod) (remove bool, err error) {
 		log.WithFields(podFields(pod)).Warnf("Pod does not have controller.")
 		return true, nil
 	}
+	if d.evictDaemonSets {
+		return true, nil
+	}
 	// if the controller is DaemonSet, do not allow to delete the pod
 	for _, ref := range pod.OwnerReferences {
 		if ref.Kind == rigging.KindDaemonSet {
@@ -219,6 +247,15 @@ type drain struct {
 	// timeout sets the timeout for the operation.
 	// zero value means no timeout
 	timeout time.Duration
+	// forceAfter specifies the timeout after which the pods that
+	// have not been evicted are deleted.
+	// zero value means pods are never deleted forcibly
+	forceAfter time.Duration
+	// ignorePDBs specifies whether to delete pods instead of evicting them
+	// with respect to pod disruption budgets
+	ignorePDBs bool
+	// evictDaemonSets specifies whether to also remove pods managed by daemon sets
+	evictDaemonSets bool
 }
 
 // queryEvictionPolicyGroupVersion uses Discovery API to find out if the server supports eviction subresource.
@@ -18,6 +18,8 @@ package kubernetes
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/storage"
@@ -29,12 +31,18 @@ import (
 	log "github.com/sirupsen/logrus"
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 )
 
 // Drain safely drains the specified node and uses Eviction API if supported on the api server.
 func Drain(ctx context.Context, client *kubernetes.Clientset, nodeName string) error {
+	return trace.Wrap(DrainWithPolicy(ctx, client, nodeName, storage.DrainPolicy{}))
+}
+
+// DrainWithPolicy drains the specified node according to the given policy
+func DrainWithPolicy(ctx context.Context, client *kubernetes.Clientset, nodeName string, policy storage.DrainPolicy) error {
 	err := SetUnschedulable(ctx, client.CoreV1().Nodes(), nodeName, true)
 	if err != nil {
 		return trace.Wrap(err)
@@ -44,6 +52,12 @@ func Drain(ctx context.Context, client *kubernetes.Clientset, nodeName string) e
 		client:             client,
 		nodeName:           nodeName,
 		gracePeriodSeconds: defaults.ResourceGracePeriod,
+		forceAfter:         policy.ForceAfter,
+		ignorePDBs:         policy.IgnorePodDisruptionBudgets,
+		evictDaemonSets:    policy.EvictDaemonSets,
+	}
+	if policy.GracePeriod > 0 {
+		d.gracePeriodSeconds = int64(policy.GracePeriod / time.Second)
 	}
 	err = d.drainPods(ctx)
 	return trace.Wrap(err)
@@ -111,6 +125,97 @@ func UpdateLabels(ctx context.Context, client corev1.NodeInterface, nodeName str
 	return rigging.ConvertError(err)
 }
 
+// ApplyServerLabelsAndTaints applies the custom labels and taints configured
+// for the specified server to the corresponding Kubernetes node.
+// Waits for the node to register if necessary
+func ApplyServerLabelsAndTaints(ctx context.Context, client *kubernetes.Clientset, server storage.Server) error {
+	if len(server.Labels) == 0 && len(server.Taints) == 0 {
+		return nil
+	}
+	taints, err := ParseTaints(server.Taints)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	var node *v1.Node
+	err = utils.RetryWithInterval(ctx, backoff.NewConstantBackOff(defaults.RetryInterval), func() (err error) {
+		node, err = GetNode(client, server)
+		return trace.Wrap(err)
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	nodes := client.CoreV1().Nodes()
+	if len(server.Labels) != 0 {
+		err = UpdateLabels(ctx, nodes, node.Name, server.Labels)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	if len(taints) != 0 {
+		err = UpdateTaints(ctx, nodes, node.Name, taints, nil)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// ParseLabel parses the label specification in the "key=value" format
+func ParseLabel(spec string) (key, value string, err error) {
+	parts := strings.SplitN(spec, "=", 2)
+	if len(parts) != 2 {
+		return "", "", trace.BadParameter("invalid label %q, expected key=value", spec)
+	}
+	key, value = parts[0], parts[1]
+	if errs := validation.IsQualifiedName(key); len(errs) != 0 {
+		return "", "", trace.BadParameter("invalid label key %q: %v", key, strings.Join(errs, "; "))
+	}
+	if errs := validation.IsValidLabelValue(value); len(errs) != 0 {
+		return "", "", trace.BadParameter("invalid label value %q: %v", value, strings.Join(errs, "; "))
+	}
+	return key, value, nil
+}
+
+// ParseTaints parses the list of taints in the "key=value:effect" format
+func ParseTaints(specs []string) (taints []v1.Taint, err error) {
+	for _, spec := range specs {
+		taint, err := ParseTaint(spec)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		taints = append(taints, *taint)
+	}
+	return taints, nil
+}
+
+// ParseTaint parses the taint specification in either "key=value:effect"
+// or "key:effect" format
+func ParseTaint(spec string) (*v1.Taint, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) != 2 || parts[0] == "" {
+		return nil, trace.BadParameter("invalid taint %q, expected key=value:effect", spec)
+	}
+	taint := v1.Taint{Effect: v1.TaintEffect(parts[1])}
+	switch taint.Effect {
+	case v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule, v1.TaintEffectNoExecute:
+	default:
+		return nil, trace.BadParameter("invalid taint effect %q, supported are: %v, %v, %v",
+			taint.Effect, v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule, v1.TaintEffectNoExecute)
+	}
+	keyValue := strings.SplitN(parts[0], "=", 2)
+	taint.Key = keyValue[0]
+	if len(keyValue) == 2 {
+		taint.Value = keyValue[1]
+	}
+	if errs := validation.IsQualifiedName(taint.Key); len(errs) != 0 {
+		return nil, trace.BadParameter("invalid taint key %q: %v", taint.Key, strings.Join(errs, "; "))
+	}
+	if errs := validation.IsValidLabelValue(taint.Value); len(errs) != 0 {
+		return nil, trace.BadParameter("invalid taint value %q: %v", taint.Value, strings.Join(errs, "; "))
+	}
+	return &taint, nil
+}
+
 // GetNode returns Kubernetes node corresponding to the provided server
 func GetNode(client *kubernetes.Clientset, server storage.Server) (*v1.Node, error) {
 	nodes, err := client.Core().Nodes().List(metav1.ListOptions{
@@ -0,0 +1,83 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"github.com/gravitational/gravity/lib/compare"
+
+	"k8s.io/api/core/v1"
+
+	. "gopkg.in/check.v1"
+)
+
+type NodesSuite struct{}
+
+var _ = Suite(&NodesSuite{})
+
+func (*NodesSuite) TestParsesTaints(c *C) {
+	testCases := []struct {
+		spec    string
+		taint   *v1.Taint
+		comment string
+	}{
+		{
+			spec:    "dedicated=gpu:NoSchedule",
+			taint:   &v1.Taint{Key: "dedicated", Value: "gpu", Effect: v1.TaintEffectNoSchedule},
+			comment: "key, value and effect",
+		},
+		{
+			spec:    "example.com/maintenance:NoExecute",
+			taint:   &v1.Taint{Key: "example.com/maintenance", Effect: v1.TaintEffectNoExecute},
+			comment: "key and effect",
+		},
+		{
+			spec:    "dedicated=gpu",
+			comment: "missing effect",
+		},
+		{
+			spec:    "dedicated=gpu:Forbid",
+			comment: "unknown effect",
+		},
+		{
+			spec:    "=gpu:NoSchedule",
+			comment: "empty key",
+		},
+	}
+	for _, tc := range testCases {
+		comment := Commentf(tc.comment)
+		taint, err := ParseTaint(tc.spec)
+		if tc.taint == nil {
+			c.Assert(err, NotNil, comment)
+			continue
+		}
+		c.Assert(err, IsNil, comment)
+		c.Assert(taint, compare.DeepEquals, tc.taint, comment)
+	}
+}
+
+func (*NodesSuite) TestParsesLabels(c *C) {
+	key, value, err := ParseLabel("example.com/rack=r1")
+	c.Assert(err, IsNil)
+	c.Assert(key, Equals, "example.com/rack")
+	c.Assert(value, Equals, "r1")
+
+	_, _, err = ParseLabel("rack")
+	c.Assert(err, NotNil)
+
+	_, _, err = ParseLabel("rack=not a valid value")
+	c.Assert(err, NotNil)
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"fmt"
+
+	"github.com/gravitational/gravity/lib/defaults"
+
+	"github.com/gravitational/rigging"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+// LocalVolumePod describes a pod that stores data on a persistent volume
+// local to the node it is running on
+type LocalVolumePod struct {
+	// Pod is the pod using the local volume
+	Pod v1.Pod
+	// Claim is the name of the persistent volume claim bound to the local volume
+	Claim string
+	// Volume is the name of the local persistent volume
+	Volume string
+}
+
+// String returns a textual representation of this pod
+func (r LocalVolumePod) String() string {
+	return fmt.Sprintf("pod %v/%v stores data on local volume %v (claim %v)",
+		r.Pod.Namespace, r.Pod.Name, r.Volume, r.Claim)
+}
+
+// LocalVolumePods returns the pods on the specified node that use persistent
+// volumes local to the node. The data on these volumes is lost
+// when the node is removed from the cluster
+func LocalVolumePods(client *kubernetes.Clientset, nodeName string) ([]LocalVolumePod, error) {
+	pods, err := client.CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{
+		FieldSelector: fields.SelectorFromSet(fields.Set{"spec.nodeName": nodeName}).String(),
+	})
+	if err != nil {
+		return nil, rigging.ConvertError(err)
+	}
+	claims, err := client.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, rigging.ConvertError(err)
+	}
+	volumes, err := client.CoreV1().PersistentVolumes().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, rigging.ConvertError(err)
+	}
+	return localVolumePods(pods.Items, claims.Items, volumes.Items, nodeName), nil
+}
+
+// UnreadyStatefulSets returns the stateful sets that do not have all replicas ready.
+// A stateful application that has not yet replicated its data to all replicas
+// might lose data if a node hosting one of the ready replicas is removed
+func UnreadyStatefulSets(client *kubernetes.Clientset) ([]appsv1.StatefulSet, error) {
+	statefulSets, err := client.AppsV1().StatefulSets(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, rigging.ConvertError(err)
+	}
+	return unreadyStatefulSets(statefulSets.Items), nil
+}
+
+func localVolumePods(pods []v1.Pod, claims []v1.PersistentVolumeClaim, volumes []v1.PersistentVolume, nodeName string) (result []LocalVolumePod) {
+	claimVolumes := make(map[string]string, len(claims))
+	for _, claim := range claims {
+		claimVolumes[claim.Namespace+"/"+claim.Name] = claim.Spec.VolumeName
+	}
+	localVolumes := make(map[string]bool)
+	for _, volume := range volumes {
+		if isLocalVolume(volume, nodeName) {
+			localVolumes[volume.Name] = true
+		}
+	}
+	for _, pod := range pods {
+		for _, podVolume := range pod.Spec.Volumes {
+			if podVolume.PersistentVolumeClaim == nil {
+				continue
+			}
+			claim := podVolume.PersistentVolumeClaim.ClaimName
+			volume := claimVolumes[pod.Namespace+"/"+claim]
+			if volume == "" || !localVolumes[volume] {
+				continue
+			}
+			result = append(result, LocalVolumePod{
+				Pod:    pod,
+				Claim:  claim,
+				Volume: volume,
+			})
+		}
+	}
+	return result
+}
+
+// isLocalVolume returns true if the specified persistent volume
+// stores data on the node with the specified name
+func isLocalVolume(volume v1.PersistentVolume, nodeName string) bool {
+	if volume.Spec.Local != nil || volume.Spec.HostPath != nil {
+		return true
+	}
+	affinity := volume.Spec.NodeAffinity
+	if affinity == nil || affinity.Required == nil {
+		return false
+	}
+	for _, term := range affinity.Required.NodeSelectorTerms {
+		for _, expr := range term.MatchExpressions {
+			if expr.Key != defaults.KubernetesHostnameLabel || expr.Operator != v1.NodeSelectorOpIn {
+				continue
+			}
+			for _, value := range expr.Values {
+				if value == nodeName {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func unreadyStatefulSets(statefulSets []appsv1.StatefulSet) (result []appsv1.StatefulSet) {
+	for _, statefulSet := range statefulSets {
+		replicas := int32(1)
+		if statefulSet.Spec.Replicas != nil {
+			replicas = *statefulSet.Spec.Replicas
+		}
+		if statefulSet.Status.ReadyReplicas < replicas {
+			result = append(result, statefulSet)
+		}
+	}
+	return result
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"github.com/gravitational/gravity/lib/defaults"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "gopkg.in/check.v1"
+)
+
+type RemovalSuite struct{}
+
+var _ = Suite(&RemovalSuite{})
+
+func (*RemovalSuite) TestFindsPodsWithLocalVolumes(c *C) {
+	pods := []v1.Pod{
+		newPodWithClaim("db-0", "data-db-0"),
+		newPodWithClaim("web-0", "cache"),
+		newPodWithClaim("queue-0", "data-queue-0"),
+	}
+	claims := []v1.PersistentVolumeClaim{
+		newClaim("data-db-0", "pv-local"),
+		newClaim("cache", "pv-network"),
+		newClaim("data-queue-0", "pv-pinned"),
+	}
+	volumes := []v1.PersistentVolume{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-local"},
+			Spec: v1.PersistentVolumeSpec{
+				PersistentVolumeSource: v1.PersistentVolumeSource{
+					Local: &v1.LocalVolumeSource{Path: "/mnt/disks/db"},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-network"},
+			Spec: v1.PersistentVolumeSpec{
+				PersistentVolumeSource: v1.PersistentVolumeSource{
+					NFS: &v1.NFSVolumeSource{Server: "nfs.example.com", Path: "/exports"},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-pinned"},
+			Spec: v1.PersistentVolumeSpec{
+				NodeAffinity: &v1.VolumeNodeAffinity{
+					Required: &v1.NodeSelector{
+						NodeSelectorTerms: []v1.NodeSelectorTerm{{
+							MatchExpressions: []v1.NodeSelectorRequirement{{
+								Key:      defaults.KubernetesHostnameLabel,
+								Operator: v1.NodeSelectorOpIn,
+								Values:   []string{"node-1"},
+							}},
+						}},
+					},
+				},
+			},
+		},
+	}
+	result := localVolumePods(pods, claims, volumes, "node-1")
+	c.Assert(result, HasLen, 2)
+	c.Assert(result[0].Pod.Name, Equals, "db-0")
+	c.Assert(result[0].Volume, Equals, "pv-local")
+	c.Assert(result[1].Pod.Name, Equals, "queue-0")
+	c.Assert(result[1].Volume, Equals, "pv-pinned")
+
+	result = localVolumePods(pods, claims, volumes, "node-2")
+	c.Assert(result, HasLen, 1)
+	c.Assert(result[0].Pod.Name, Equals, "db-0")
+}
+
+func (*RemovalSuite) TestFindsUnreadyStatefulSets(c *C) {
+	three := int32(3)
+	statefulSets := []appsv1.StatefulSet{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "ready"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &three},
+			Status:     appsv1.StatefulSetStatus{ReadyReplicas: 3},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "replicating"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &three},
+			Status:     appsv1.StatefulSetStatus{ReadyReplicas: 2},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "default-replicas"},
+			Status:     appsv1.StatefulSetStatus{ReadyReplicas: 0},
+		},
+	}
+	result := unreadyStatefulSets(statefulSets)
+	c.Assert(result, HasLen, 2)
+	c.Assert(result[0].Name, Equals, "replicating")
+	c.Assert(result[1].Name, Equals, "default-replicas")
+}
+
+func newPodWithClaim(name, claim string) v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: v1.PodSpec{
+			Volumes: []v1.Volume{{
+				Name: "data",
+				VolumeSource: v1.VolumeSource{
+					PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+				},
+			}},
+		},
+	}
+}
+
+func newClaim(name, volume string) v1.PersistentVolumeClaim {
+	return v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       v1.PersistentVolumeClaimSpec{VolumeName: volume},
+	}
+}
@@ -177,6 +177,22 @@ func (l Locator) WithVersion(version *semver.Version) Locator {
 	}
 }
 
+// ForArch returns the locator of the variant of this package built for
+// the specified CPU architecture.
+// Packages for the default amd64 architecture keep their names, the names
+// of packages for other architectures are suffixed with the architecture,
+// e.g. gravitational.io/planet-arm64:7.0.0
+func (l Locator) ForArch(arch string) Locator {
+	if arch == "" || arch == constants.ArchAMD64 {
+		return l
+	}
+	return Locator{
+		Repository: l.Repository,
+		Name:       fmt.Sprintf("%v-%v", l.Name, arch),
+		Version:    l.Version,
+	}
+}
+
 func ParseLocator(v string) (*Locator, error) {
 	parts := strings.Split(v, "/")
 	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
@@ -155,3 +155,10 @@ func (s *LocatorSuite) TestDeduplicate(c *C) {
 	}
 	c.Assert(uniq, compare.DeepEquals, expected)
 }
+
+func (s *LocatorSuite) TestForArch(c *C) {
+	planet := MustParseLocator("gravitational.io/planet:7.0.0")
+	c.Assert(planet.ForArch(""), Equals, planet)
+	c.Assert(planet.ForArch("amd64"), Equals, planet)
+	c.Assert(planet.ForArch("arm64"), Equals, MustParseLocator("gravitational.io/planet-arm64:7.0.0"))
+}
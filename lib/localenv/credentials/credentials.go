@@ -27,6 +27,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"net"
 	"path"
 	"path/filepath"
 
@@ -53,6 +54,9 @@ type Service interface {
 	// DEPRECATED: This method can removed when authentication via local
 	//             Gravity key store is no longer supported.
 	UpsertLoginEntry(clusterURL, username, password string) error
+	// UpsertKey saves the client key with the certificates issued by the
+	// specified cluster in the Teleport key store and makes the cluster current.
+	UpsertKey(clusterURL, username string, key client.Key) error
 }
 
 // Credentials defines a set of user credentials.
@@ -241,6 +245,48 @@ func (s *credentialsService) UpsertLoginEntry(clusterURL, username, password str
 	return nil
 }
 
+// UpsertKey saves the client key with the certificates issued by the
+// specified cluster in the Teleport key store and makes the cluster current.
+func (s *credentialsService) UpsertKey(clusterURL, username string, key client.Key) error {
+	url, err := parseURL(clusterURL)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	teleportKeyStore, err := s.getTeleportKeyStore()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := teleportKeyStore.AddKey(url.hostname, username, &key); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := teleportKeyStore.SaveCerts(url.hostname, key.TrustedCA); err != nil {
+		return trace.Wrap(err)
+	}
+	_, port, err := utils.URLSplitHostPort(url.normalized, defaults.HTTPSPort)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	profile := client.ClientProfile{
+		WebProxyAddr: net.JoinHostPort(url.hostname, port),
+		Username:     username,
+	}
+	profilePath := filepath.Join(client.FullProfilePath(s.TeleportKeyStoreDir), url.hostname+".yaml")
+	if err := profile.SaveTo("", profilePath, client.ProfileMakeCurrent); err != nil {
+		return trace.Wrap(err)
+	}
+	// The login entries of the local key store take precedence over
+	// the Teleport key store so remove the stale entry for the cluster.
+	localKeyStore, err := s.getLocalKeyStore()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = localKeyStore.DeleteLoginEntry(url.normalized)
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(localKeyStore.SetCurrentOpsCenter(url.normalized))
+}
+
 func (s *credentialsService) getLocalKeyStore() (*users.KeyStore, error) {
 	return GetLocalKeyStore(s.LocalKeyStoreDir)
 }
@@ -81,6 +81,8 @@ type LocalEnvironmentArgs struct {
 	ReadonlyBackend bool
 	// Credentials is the predefined static credentials entry
 	Credentials *credentials.Credentials
+	// EncryptionKey is the optional key to encrypt the local state database with
+	EncryptionKey []byte
 	// Close allows to perform extra cleanup actions
 	Close func() error
 }
@@ -157,10 +159,11 @@ func (env *LocalEnvironment) init() error {
 	}
 
 	env.Backend, err = keyval.NewBolt(keyval.BoltConfig{
-		Path:     filepath.Join(env.StateDir, defaults.GravityDBFile),
-		Multi:    true,
-		Readonly: env.ReadonlyBackend,
-		Timeout:  env.BoltOpenTimeout,
+		Path:          filepath.Join(env.StateDir, defaults.GravityDBFile),
+		Multi:         true,
+		Readonly:      env.ReadonlyBackend,
+		Timeout:       env.BoltOpenTimeout,
+		EncryptionKey: env.EncryptionKey,
 	})
 	if err != nil {
 		return trace.Wrap(err)
@@ -462,6 +465,18 @@ func (env *LocalEnvironment) GravityCommand(gravityPath string, args ...string)
 	return append(command, args...)
 }
 
+// KubeClient returns a new Kubernetes client for the local cluster
+func (env *LocalEnvironment) KubeClient() (*kubernetes.Clientset, error) {
+	client, err := env.getKubeClient()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if client == nil {
+		return nil, trace.NotFound("no Kubernetes client available on this node")
+	}
+	return client, nil
+}
+
 func (env *LocalEnvironment) getKubeClient() (*kubernetes.Clientset, error) {
 	_, err := os.Stat(constants.PrivilegedKubeconfig)
 	if err == nil {
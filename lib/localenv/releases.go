@@ -0,0 +1,72 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package localenv
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/httplib"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// TrackRelease records the state of the application release in the cluster
+// backend so it is reported by gravity status.
+//
+// The release has already been deployed when it is recorded, so failures
+// are logged instead of being returned
+func (env *LocalEnvironment) TrackRelease(ctx context.Context, release storage.Release) {
+	if err := httplib.InGravity(env.DNS.Addr()); err != nil {
+		return // Not inside Gravity cluster.
+	}
+	operator, err := env.SiteOperator()
+	if err != nil {
+		log.WithError(err).Warn("Failed to create cluster operator.")
+		return
+	}
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		log.WithError(err).Warn("Failed to query local cluster.")
+		return
+	}
+	if err := operator.TrackRelease(ctx, cluster.Key(), release); err != nil {
+		log.WithError(err).Warnf("Failed to record release %v.", release.GetName())
+	}
+}
+
+// UntrackRelease removes the record of the application release
+// with the specified name from the cluster backend
+func (env *LocalEnvironment) UntrackRelease(ctx context.Context, name string) {
+	if err := httplib.InGravity(env.DNS.Addr()); err != nil {
+		return // Not inside Gravity cluster.
+	}
+	operator, err := env.SiteOperator()
+	if err != nil {
+		log.WithError(err).Warn("Failed to create cluster operator.")
+		return
+	}
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		log.WithError(err).Warn("Failed to query local cluster.")
+		return
+	}
+	err = operator.UntrackRelease(ctx, cluster.Key(), name)
+	if err != nil && !trace.IsNotFound(err) {
+		log.WithError(err).Warnf("Failed to delete record of release %v.", name)
+	}
+}
@@ -0,0 +1,318 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package metrics defines the Prometheus metrics of gravity processes.
+
+The metrics are registered with the default Prometheus registry and are served
+by gravity-site on its health endpoint and by the gravity agents on the address
+given with --metrics-addr:
+
+	GET /metrics
+*/
+package metrics
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gravitational/trace"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// OperationPhaseDuration is the duration of the operation phase execution
+	OperationPhaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "operation",
+		Name:      "phase_duration_seconds",
+		Help:      "Duration of the operation phase execution.",
+		Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+	}, []string{LabelOperation, LabelPhase})
+	// OperationPhaseFailures counts the failed operation phases
+	OperationPhaseFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "operation",
+		Name:      "phase_failures_total",
+		Help:      "Number of failed operation phases.",
+	}, []string{LabelOperation, LabelPhase})
+
+	// PackagePullBytes counts the bytes of the pulled packages
+	PackagePullBytes = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "package",
+		Name:      "pull_bytes_total",
+		Help:      "Number of bytes of the pulled packages.",
+	})
+	// PackagePullDuration is the duration of the package pulls
+	PackagePullDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "package",
+		Name:      "pull_duration_seconds",
+		Help:      "Duration of the package pulls.",
+		Buckets:   []float64{0.1, 0.5, 1, 5, 15, 30, 60, 120, 300, 600},
+	})
+	// PackagePullFailures counts the failed package pulls
+	PackagePullFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "package",
+		Name:      "pull_failures_total",
+		Help:      "Number of failed package pulls.",
+	})
+
+	// BackendRequestDuration is the latency of the storage backend requests
+	BackendRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "backend",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of the storage backend requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{LabelBackend, LabelRequest})
+
+	// AgentPeers is the number of the agents connected to this process
+	AgentPeers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "agent",
+		Name:      "peers",
+		Help:      "Number of the agents connected to this process.",
+	})
+	// AgentHealthCheckFailures counts the failed agent health checks
+	AgentHealthCheckFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "agent",
+		Name:      "health_check_failures_total",
+		Help:      "Number of failed agent health checks.",
+	})
+	// AgentReconnects counts the attempts to reconnect to the agents
+	// that have failed a health check
+	AgentReconnects = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "agent",
+		Name:      "reconnects_total",
+		Help:      "Number of attempts to reconnect to the agents.",
+	}, []string{LabelResult})
+
+	// NetworkPeerRTT is the round-trip time to the other cluster node
+	NetworkPeerRTT = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "network",
+		Name:      "peer_rtt_seconds",
+		Help:      "Average round-trip time to the other cluster node.",
+	}, []string{LabelPeer, LabelPath})
+	// NetworkPeerPacketLoss is the ratio of the lost probes to the other cluster node
+	NetworkPeerPacketLoss = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "network",
+		Name:      "peer_packet_loss_ratio",
+		Help:      "Ratio of the lost probes to the other cluster node.",
+	}, []string{LabelPeer, LabelPath})
+	// NetworkPeerMTUExceeded is 1 if the packets of the maximum size
+	// do not reach the other cluster node
+	NetworkPeerMTUExceeded = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "network",
+		Name:      "peer_mtu_exceeded",
+		Help:      "Whether the packets of the maximum size do not reach the other cluster node.",
+	}, []string{LabelPeer})
+
+	// EtcdDBSize is the size of the etcd member database
+	EtcdDBSize = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "etcd",
+		Name:      "db_size_bytes",
+		Help:      "Size of the etcd member database.",
+	}, []string{LabelMember})
+	// EtcdQuota is the etcd space quota
+	EtcdQuota = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "etcd",
+		Name:      "quota_bytes",
+		Help:      "Etcd space quota.",
+	})
+	// EtcdRaftIndexLag is the number of raft entries the etcd member is behind the leader
+	EtcdRaftIndexLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "etcd",
+		Name:      "raft_index_lag",
+		Help:      "Number of raft entries the etcd member is behind the leader.",
+	}, []string{LabelMember})
+	// EtcdLeaderChanges counts the observed etcd leader changes
+	EtcdLeaderChanges = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "etcd",
+		Name:      "leader_changes_total",
+		Help:      "Number of observed etcd leader changes.",
+	})
+	// EtcdHasLeader is 1 if the etcd cluster has a leader
+	EtcdHasLeader = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "etcd",
+		Name:      "has_leader",
+		Help:      "Whether the etcd cluster has a leader.",
+	})
+	// EtcdReadLatency is the latency of a linearizable etcd read
+	EtcdReadLatency = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "etcd",
+		Name:      "read_latency_seconds",
+		Help:      "Latency of a linearizable etcd read.",
+	})
+	// EtcdAlarms is the number of active etcd alarms
+	EtcdAlarms = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "etcd",
+		Name:      "alarms",
+		Help:      "Number of active etcd alarms.",
+	}, []string{LabelAlarm})
+	// EtcdKeys is the number of keys in the state store
+	EtcdKeys = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "etcd",
+		Name:      "keys",
+		Help:      "Number of keys in the state store.",
+	}, []string{LabelStore})
+	// CertificateExpiry is the expiration time of the cluster certificate
+	CertificateExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "certificate",
+		Name:      "expiry_timestamp_seconds",
+		Help:      "Expiration time of the cluster certificate in seconds since the epoch.",
+	}, []string{LabelSource, LabelName, LabelNode})
+)
+
+const (
+	// LabelOperation is the label with the operation type
+	LabelOperation = "operation"
+	// LabelPhase is the label with the phase executor
+	LabelPhase = "phase"
+	// LabelBackend is the label with the storage backend type
+	LabelBackend = "backend"
+	// LabelRequest is the label with the storage backend request type
+	LabelRequest = "request"
+	// LabelResult is the label with the attempt result: success or failure
+	LabelResult = "result"
+	// LabelPeer is the label with the name of the other cluster node
+	LabelPeer = "peer"
+	// LabelPath is the label with the network path: host or overlay
+	LabelPath = "path"
+	// LabelMember is the label with the etcd member name
+	LabelMember = "member"
+	// LabelAlarm is the label with the etcd alarm type
+	LabelAlarm = "alarm"
+	// LabelStore is the label with the state store: kubernetes, planet or gravity
+	LabelStore = "store"
+	// LabelSource is the label with the certificate source: ca, planet, rpc, web or teleport
+	LabelSource = "source"
+	// LabelName is the label with the certificate name
+	LabelName = "name"
+	// LabelNode is the label with the name of the cluster node
+	LabelNode = "node"
+
+	// ResultSuccess is the value of the result label of a successful attempt
+	ResultSuccess = "success"
+	// ResultFailure is the value of the result label of a failed attempt
+	ResultFailure = "failure"
+
+	// PathHost is the value of the path label of the node advertise address
+	PathHost = "host"
+	// PathOverlay is the value of the path label of the overlay network address
+	PathOverlay = "overlay"
+
+	namespace = "gravity"
+)
+
+func init() {
+	prometheus.MustRegister(
+		OperationPhaseDuration,
+		OperationPhaseFailures,
+		PackagePullBytes,
+		PackagePullDuration,
+		PackagePullFailures,
+		BackendRequestDuration,
+		AgentPeers,
+		AgentHealthCheckFailures,
+		AgentReconnects,
+		NetworkPeerRTT,
+		NetworkPeerPacketLoss,
+		NetworkPeerMTUExceeded,
+		EtcdDBSize,
+		EtcdQuota,
+		EtcdRaftIndexLag,
+		EtcdLeaderChanges,
+		EtcdHasLeader,
+		EtcdReadLatency,
+		EtcdAlarms,
+		EtcdKeys,
+		CertificateExpiry,
+	)
+}
+
+// Result returns the value of the result label for the specified error
+func Result(err error) string {
+	if err != nil {
+		return ResultFailure
+	}
+	return ResultSuccess
+}
+
+// Since returns the number of seconds elapsed since the specified time
+func Since(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
+
+// NewCounterWriter returns a writer that adds the number of bytes
+// written to it to the specified counter
+func NewCounterWriter(counter prometheus.Counter) io.Writer {
+	return counterWriter{counter: counter}
+}
+
+type counterWriter struct {
+	counter prometheus.Counter
+}
+
+// Write adds the length of p to the counter
+func (w counterWriter) Write(p []byte) (int, error) {
+	w.counter.Add(float64(len(p)))
+	return len(p), nil
+}
+
+// Handler returns the HTTP handler that serves the metrics
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Serve starts serving the metrics on the specified address in the background
+func Serve(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return trace.Wrap(err, "failed to serve metrics on %v", addr)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	logger := log.WithFields(log.Fields{
+		trace.Component: "metrics",
+		"addr":          listener.Addr(),
+	})
+	logger.Info("Serving metrics.")
+	go func() {
+		logger.Warn(http.Serve(listener, mux))
+	}()
+	return nil
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"gopkg.in/check.v1"
+)
+
+func TestMetrics(t *testing.T) { check.TestingT(t) }
+
+type MetricsSuite struct{}
+
+var _ = check.Suite(&MetricsSuite{})
+
+func (s *MetricsSuite) TestCountsWrittenBytes(c *check.C) {
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_bytes_total"})
+	_, err := io.Copy(NewCounterWriter(counter), strings.NewReader("package data"))
+	c.Assert(err, check.IsNil)
+
+	var metric dto.Metric
+	c.Assert(counter.Write(&metric), check.IsNil)
+	c.Assert(metric.GetCounter().GetValue(), check.Equals, float64(len("package data")))
+}
+
+func (s *MetricsSuite) TestServesMetrics(c *check.C) {
+	OperationPhaseFailures.WithLabelValues("operation_update", "bootstrap").Inc()
+	AgentReconnects.WithLabelValues(Result(nil)).Inc()
+
+	server := httptest.NewServer(Handler())
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	c.Assert(err, check.IsNil)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, check.IsNil)
+
+	for _, metric := range []string{
+		`gravity_operation_phase_failures_total{operation="operation_update",phase="bootstrap"} 1`,
+		`gravity_agent_reconnects_total{result="success"} 1`,
+		`gravity_package_pull_bytes_total`,
+		`gravity_agent_peers`,
+	} {
+		c.Assert(strings.Contains(string(body), metric), check.Equals, true, check.Commentf(metric))
+	}
+}
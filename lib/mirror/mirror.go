@@ -0,0 +1,289 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mirror implements exporting the contents of a cluster image
+// into a self-contained directory that can be served to installers running
+// in air-gapped environments.
+//
+// The mirror directory has the following layout:
+//
+//	<dir>/registry                                  - docker registry (v2 filesystem storage)
+//	<dir>/packages/index.json                       - list of exported package envelopes
+//	<dir>/packages/<repository>/<name>/<version>.tar.gz - package blobs
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/app/docker"
+	"github.com/gravitational/gravity/lib/app/service"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// ExportConfig describes a request to export a cluster image into a mirror directory
+type ExportConfig struct {
+	// Packages is the package service with the cluster image packages
+	Packages pack.PackageService
+	// Apps is the application service with the cluster image applications
+	Apps app.Applications
+	// Application is the cluster image application locator
+	Application loc.Locator
+	// Dir is the mirror output directory
+	Dir string
+	// Progress is the progress reporter
+	Progress utils.Printer
+	// FieldLogger is used for logging
+	log.FieldLogger
+}
+
+// CheckAndSetDefaults validates the config and sets defaults
+func (c *ExportConfig) CheckAndSetDefaults() error {
+	if c.Packages == nil {
+		return trace.BadParameter("missing Packages")
+	}
+	if c.Apps == nil {
+		return trace.BadParameter("missing Apps")
+	}
+	if c.Application.IsEmpty() {
+		return trace.BadParameter("missing Application")
+	}
+	if c.Dir == "" {
+		return trace.BadParameter("missing Dir")
+	}
+	if c.Progress == nil {
+		c.Progress = utils.DiscardPrinter
+	}
+	if c.FieldLogger == nil {
+		c.FieldLogger = log.WithField(trace.Component, "mirror")
+	}
+	return nil
+}
+
+// Export exports all packages and container images of the configured
+// cluster image into the mirror directory
+func Export(ctx context.Context, config ExportConfig) error {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	config.Progress.PrintStep("Exporting packages to %v", PackagesDir(config.Dir))
+	if err := ExportPackages(config.Packages, PackagesDir(config.Dir), config.FieldLogger); err != nil {
+		return trace.Wrap(err)
+	}
+	config.Progress.PrintStep("Exporting container images to %v", RegistryDir(config.Dir))
+	if err := exportImages(ctx, config); err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// ExportPackages writes all packages from the specified package service
+// into dir along with the index file describing them
+func ExportPackages(packages pack.PackageService, dir string, logger log.FieldLogger) error {
+	if err := os.MkdirAll(dir, defaults.SharedDirMask); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	index := Index{}
+	err := pack.ForeachPackage(packages, func(env pack.PackageEnvelope) error {
+		path := PackagePath(dir, env.Locator)
+		logger.WithField("package", env.Locator).Info("Export package.")
+		if err := exportPackage(packages, env.Locator, path); err != nil {
+			return trace.Wrap(err)
+		}
+		index.Packages = append(index.Packages, env)
+		return nil
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, IndexFile), data, defaults.SharedReadMask)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	return nil
+}
+
+// ReadIndex reads the package index from the specified mirror packages directory
+func ReadIndex(dir string) (*Index, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, IndexFile))
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	var index Index
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &index, nil
+}
+
+// Index describes the set of packages available in a mirror
+type Index struct {
+	// Packages lists exported package envelopes
+	Packages []pack.PackageEnvelope `json:"packages"`
+}
+
+// ServeConfig describes the configuration of the mirror server
+type ServeConfig struct {
+	// Dir is the mirror directory created with Export
+	Dir string
+	// RegistryAddr is the address to serve the docker registry on
+	RegistryAddr string
+	// PackagesAddr is the address to serve the packages on
+	PackagesAddr string
+	// FieldLogger is used for logging
+	log.FieldLogger
+}
+
+// CheckAndSetDefaults validates the config and sets defaults
+func (c *ServeConfig) CheckAndSetDefaults() error {
+	if c.Dir == "" {
+		return trace.BadParameter("missing Dir")
+	}
+	if c.RegistryAddr == "" {
+		return trace.BadParameter("missing RegistryAddr")
+	}
+	if c.PackagesAddr == "" {
+		return trace.BadParameter("missing PackagesAddr")
+	}
+	for _, dir := range []string{RegistryDir(c.Dir), PackagesDir(c.Dir)} {
+		if isDir, _ := utils.IsDirectory(dir); !isDir {
+			return trace.NotFound("%v is not a mirror directory: %v is missing",
+				c.Dir, dir)
+		}
+	}
+	if c.FieldLogger == nil {
+		c.FieldLogger = log.WithField(trace.Component, "mirror")
+	}
+	return nil
+}
+
+// Serve serves the docker registry and the packages from the mirror directory
+// until the specified context is canceled
+func Serve(ctx context.Context, config ServeConfig) error {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	registry, err := docker.NewRegistry(docker.BasicConfiguration(
+		config.RegistryAddr, RegistryDir(config.Dir)))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := registry.Start(); err != nil {
+		return trace.Wrap(err)
+	}
+	defer registry.Close()
+	config.WithField("addr", registry.Addr()).Info("Serving docker registry.")
+
+	server := &http.Server{
+		Addr:    config.PackagesAddr,
+		Handler: http.FileServer(http.Dir(PackagesDir(config.Dir))),
+	}
+	errC := make(chan error, 1)
+	go func() {
+		errC <- server.ListenAndServe()
+	}()
+	config.WithField("addr", config.PackagesAddr).Info("Serving packages.")
+
+	select {
+	case err := <-errC:
+		return trace.Wrap(err)
+	case <-ctx.Done():
+		return trace.Wrap(server.Close())
+	}
+}
+
+// RegistryDir returns the path to the docker registry inside the mirror directory
+func RegistryDir(dir string) string {
+	return filepath.Join(dir, defaults.RegistryDir)
+}
+
+// PackagesDir returns the path to the packages inside the mirror directory
+func PackagesDir(dir string) string {
+	return filepath.Join(dir, defaults.PackagesDir)
+}
+
+// PackagePath returns the path to the specified package blob inside
+// the packages directory
+func PackagePath(dir string, locator loc.Locator) string {
+	return filepath.Join(dir, locator.Repository, locator.Name,
+		locator.Version+".tar.gz")
+}
+
+// exportImages pushes container images of the application and all of its
+// dependencies into the docker registry in the mirror directory
+func exportImages(ctx context.Context, config ExportConfig) error {
+	registry, err := docker.NewRegistry(docker.BasicConfiguration(
+		"127.0.0.1:0", RegistryDir(config.Dir)))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := registry.Start(); err != nil {
+		return trace.Wrap(err)
+	}
+	defer registry.Close()
+	imageService, err := docker.NewImageService(docker.RegistryConnectionRequest{
+		RegistryAddress: registry.Addr(),
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return service.SyncApp(ctx, service.SyncRequest{
+		PackService:  config.Packages,
+		AppService:   config.Apps,
+		ImageService: imageService,
+		Package:      config.Application,
+		Progress:     config.Progress,
+	})
+}
+
+func exportPackage(packages pack.PackageService, locator loc.Locator, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), defaults.SharedDirMask); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	_, reader, err := packages.ReadPackage(locator)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer reader.Close()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, defaults.SharedReadMask)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, reader); err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// IndexFile is the name of the package index file in the mirror packages directory
+const IndexFile = "index.json"
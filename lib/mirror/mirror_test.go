@@ -0,0 +1,90 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mirror
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gravitational/gravity/lib/blob/fs"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/pack/localpack"
+	"github.com/gravitational/gravity/lib/storage/keyval"
+
+	log "github.com/sirupsen/logrus"
+	. "gopkg.in/check.v1"
+)
+
+func TestMirror(t *testing.T) { TestingT(t) }
+
+type MirrorSuite struct {
+	packages pack.PackageService
+}
+
+var _ = Suite(&MirrorSuite{})
+
+func (s *MirrorSuite) SetUpTest(c *C) {
+	dir := c.MkDir()
+	backend, err := keyval.NewBolt(keyval.BoltConfig{
+		Path: filepath.Join(dir, "bolt.db"),
+	})
+	c.Assert(err, IsNil)
+	objects, err := fs.New(dir)
+	c.Assert(err, IsNil)
+	s.packages, err = localpack.New(localpack.Config{
+		Backend:     backend,
+		UnpackedDir: filepath.Join(dir, defaults.UnpackedDir),
+		Objects:     objects,
+	})
+	c.Assert(err, IsNil)
+	err = s.packages.UpsertRepository("example.com", time.Time{})
+	c.Assert(err, IsNil)
+}
+
+func (s *MirrorSuite) TestExportsPackages(c *C) {
+	locators := []loc.Locator{
+		loc.MustParseLocator("example.com/package:0.0.1"),
+		loc.MustParseLocator("example.com/package:0.0.2"),
+	}
+	for _, locator := range locators {
+		_, err := s.packages.CreatePackage(locator, bytes.NewBufferString(locator.Version))
+		c.Assert(err, IsNil)
+	}
+
+	dir := c.MkDir()
+	err := ExportPackages(s.packages, dir, log.WithField("test", "ExportPackages"))
+	c.Assert(err, IsNil)
+
+	for _, locator := range locators {
+		data, err := ioutil.ReadFile(PackagePath(dir, locator))
+		c.Assert(err, IsNil)
+		c.Assert(string(data), Equals, locator.Version)
+	}
+
+	index, err := ReadIndex(dir)
+	c.Assert(err, IsNil)
+	var exported []loc.Locator
+	for _, env := range index.Packages {
+		exported = append(exported, env.Locator)
+	}
+	c.Assert(exported, DeepEquals, locators)
+}
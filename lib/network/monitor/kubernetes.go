@@ -0,0 +1,151 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/rigging"
+	"github.com/gravitational/trace"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// NewKubernetes returns the peer getter and publisher that use
+// the Kubernetes API: the peers are the Kubernetes nodes and the reports
+// are published as config maps
+func NewKubernetes(client kubernetes.Interface) *Kubernetes {
+	return &Kubernetes{client: client}
+}
+
+// Kubernetes discovers the cluster nodes and publishes
+// the reports using the Kubernetes API
+type Kubernetes struct {
+	client kubernetes.Interface
+}
+
+// GetPeers returns the cluster nodes
+func (r *Kubernetes) GetPeers(context.Context) ([]Peer, error) {
+	nodes, err := r.client.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, trace.Wrap(rigging.ConvertError(err))
+	}
+	peers := make([]Peer, 0, len(nodes.Items))
+	for _, node := range nodes.Items {
+		peer := Peer{
+			Node:        node.Name,
+			AdvertiseIP: node.Labels[defaults.KubernetesAdvertiseIPLabel],
+			OverlayIP:   overlayIP(node),
+		}
+		if peer.AdvertiseIP == "" {
+			peer.AdvertiseIP = internalIP(node)
+		}
+		if peer.AdvertiseIP == "" {
+			continue
+		}
+		peers = append(peers, peer)
+	}
+	return peers, nil
+}
+
+// Publish publishes the network health report in the config map of the node
+func (r *Kubernetes) Publish(ctx context.Context, report storage.NetworkHealthReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      defaults.NetworkHealthConfigMapPrefix + report.Node,
+			Namespace: defaults.KubeSystemNamespace,
+			Labels: map[string]string{
+				defaults.ApplicationLabel: networkMonitorApp,
+			},
+		},
+		Data: map[string]string{
+			reportKey: string(data),
+		},
+	}
+	configMaps := r.client.CoreV1().ConfigMaps(defaults.KubeSystemNamespace)
+	_, err = configMaps.Update(configMap)
+	if err == nil {
+		return nil
+	}
+	err = rigging.ConvertError(err)
+	if !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	_, err = configMaps.Create(configMap)
+	return trace.Wrap(rigging.ConvertError(err))
+}
+
+// GetReports returns the network health reports published by the network
+// monitors on the cluster nodes
+func GetReports(client kubernetes.Interface) ([]storage.NetworkHealthReport, error) {
+	configMaps, err := client.CoreV1().ConfigMaps(defaults.KubeSystemNamespace).List(metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{
+			defaults.ApplicationLabel: networkMonitorApp,
+		}).String(),
+	})
+	if err != nil {
+		return nil, trace.Wrap(rigging.ConvertError(err))
+	}
+	reports := make([]storage.NetworkHealthReport, 0, len(configMaps.Items))
+	for _, configMap := range configMaps.Items {
+		var report storage.NetworkHealthReport
+		if err := json.Unmarshal([]byte(configMap.Data[reportKey]), &report); err != nil {
+			return nil, trace.Wrap(err, "invalid network health report in %v", configMap.Name)
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// overlayIP returns the address of the node on the overlay network.
+// The overlay network interface of the node is assigned the network
+// address of the node pod subnet
+func overlayIP(node v1.Node) string {
+	_, ipNet, err := net.ParseCIDR(node.Spec.PodCIDR)
+	if err != nil {
+		return ""
+	}
+	return ipNet.IP.String()
+}
+
+// internalIP returns the internal address of the node
+func internalIP(node v1.Node) string {
+	for _, addr := range node.Status.Addresses {
+		if addr.Type == v1.NodeInternalIP {
+			return addr.Address
+		}
+	}
+	return ""
+}
+
+const (
+	// networkMonitorApp is the application label of the network health config maps
+	networkMonitorApp = "gravity-network-monitor"
+	// reportKey is the config map key with the network health report
+	reportKey = "report"
+)
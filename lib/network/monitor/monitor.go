@@ -0,0 +1,449 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package monitor implements the network monitor that continuously checks
+the network between the cluster nodes.
+
+The monitor runs on every cluster node and both answers and sends UDP probes.
+Every interval it probes each of the other nodes on its advertise address and
+on its overlay network address, which exercises the VXLAN tunnel between the
+nodes, and measures the round-trip time and packet loss. The probes are sent
+with the Don't Fragment flag set and the probes of the interface MTU size
+detect the paths that drop the packets of the maximum size instead of
+fragmenting them.
+
+The results are published as a report that the cluster status collects
+from all nodes, and exported as Prometheus metrics.
+*/
+package monitor
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/metrics"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"github.com/sirupsen/logrus"
+)
+
+// Config defines the network monitor configuration
+type Config struct {
+	// Node is the name of the node the monitor runs on
+	Node string
+	// Addr is the UDP address to exchange the probes on.
+	// The monitors on all nodes use the same port
+	Addr string
+	// Interval is how often the other nodes are probed
+	Interval time.Duration
+	// Probes is the number of probes sent to each address per interval
+	Probes int
+	// Timeout is how long to wait for the reply to a probe
+	Timeout time.Duration
+	// MTU is the size of the probes that check the packets of the maximum
+	// size are delivered. Defaults to the MTU of the interface with the
+	// node advertise address
+	MTU int
+	// Peers returns the cluster nodes
+	Peers PeerGetter
+	// Publisher publishes the reports
+	Publisher Publisher
+	// FieldLogger is used for logging
+	logrus.FieldLogger
+	// Clock is used to timestamp the reports
+	Clock clockwork.Clock
+}
+
+// CheckAndSetDefaults validates the configuration and sets defaults
+func (r *Config) CheckAndSetDefaults() error {
+	if r.Node == "" {
+		return trace.BadParameter("node name is required")
+	}
+	if r.Peers == nil {
+		return trace.BadParameter("peers are required")
+	}
+	if r.Publisher == nil {
+		return trace.BadParameter("publisher is required")
+	}
+	if r.Addr == "" {
+		r.Addr = defaults.NetworkMonitorAddr
+	}
+	if r.Interval == 0 {
+		r.Interval = defaults.NetworkMonitorInterval
+	}
+	if r.Probes == 0 {
+		r.Probes = defaults.NetworkMonitorProbes
+	}
+	if r.Timeout == 0 {
+		r.Timeout = defaults.NetworkMonitorProbeTimeout
+	}
+	if r.MTU != 0 && r.MTU < minPacketSize+ipv4HeaderSize+udpHeaderSize {
+		return trace.BadParameter("MTU %v is too small", r.MTU)
+	}
+	if r.FieldLogger == nil {
+		r.FieldLogger = logrus.WithField(trace.Component, "netmon")
+	}
+	if r.Clock == nil {
+		r.Clock = clockwork.NewRealClock()
+	}
+	return nil
+}
+
+// Peer describes a cluster node
+type Peer struct {
+	// Node is the node name
+	Node string
+	// AdvertiseIP is the node advertise IP address
+	AdvertiseIP string
+	// OverlayIP is the address of the node on the overlay network.
+	// Empty if unknown
+	OverlayIP string
+}
+
+// PeerGetter returns the cluster nodes
+type PeerGetter interface {
+	// GetPeers returns all cluster nodes, including the local one
+	GetPeers(context.Context) ([]Peer, error)
+}
+
+// Publisher publishes the network health reports
+type Publisher interface {
+	// Publish publishes the network health report of the node
+	Publish(context.Context, storage.NetworkHealthReport) error
+}
+
+// New returns a new network monitor listening on the configured address
+func New(config Config) (*Monitor, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	addr, err := net.ResolveUDPAddr("udp4", config.Addr)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	conn, err := net.ListenUDP("udp4", addr)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	if err := setDontFragment(conn); err != nil {
+		config.WithError(err).Warn("Failed to disable fragmentation, MTU will not be checked.")
+		config.MTU = -1
+	}
+	return &Monitor{
+		Config:  config,
+		conn:    conn,
+		port:    conn.LocalAddr().(*net.UDPAddr).Port,
+		pending: make(map[uint32]chan struct{}),
+	}, nil
+}
+
+// Monitor probes the network between the node and the other cluster nodes
+type Monitor struct {
+	// Config is the monitor configuration
+	Config
+	conn *net.UDPConn
+	// port is the port of the monitors on the other nodes
+	port int
+
+	mu sync.Mutex
+	// seq is the sequence number of the last probe
+	seq uint32
+	// pending maps the sequence numbers of the probes to the
+	// channels receiving the replies
+	pending map[uint32]chan struct{}
+}
+
+// Addr returns the address the monitor listens on
+func (m *Monitor) Addr() net.Addr {
+	return m.conn.LocalAddr()
+}
+
+// Run answers the probes of the other nodes and probes the other nodes
+// every interval until the context is cancelled
+func (m *Monitor) Run(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		m.conn.Close()
+	}()
+	go m.serve()
+	ticker := m.Clock.NewTicker(m.Interval)
+	defer ticker.Stop()
+	for {
+		if err := m.runOnce(ctx); err != nil {
+			m.WithError(err).Warn("Failed to probe network.")
+		}
+		select {
+		case <-ticker.Chan():
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (m *Monitor) runOnce(ctx context.Context) error {
+	peers, err := m.Peers.GetPeers(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	report, err := m.probe(ctx, peers)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	updateMetrics(*report)
+	return trace.Wrap(m.Publisher.Publish(ctx, *report))
+}
+
+// probe probes the other nodes and returns the report
+func (m *Monitor) probe(ctx context.Context, peers []Peer) (*storage.NetworkHealthReport, error) {
+	report := storage.NetworkHealthReport{
+		Node:     m.Node,
+		Interval: m.Interval,
+	}
+	var others []Peer
+	for _, peer := range peers {
+		if peer.Node == m.Node {
+			report.AdvertiseIP = peer.AdvertiseIP
+			continue
+		}
+		others = append(others, peer)
+	}
+	if report.AdvertiseIP == "" {
+		return nil, trace.NotFound("node %v is not a cluster node", m.Node)
+	}
+	mtu := m.MTU
+	if mtu == 0 {
+		var err error
+		mtu, err = interfaceMTU(report.AdvertiseIP)
+		if err != nil {
+			m.WithError(err).Warn("Failed to determine MTU.")
+		}
+	}
+	if mtu > 0 {
+		report.MTU = mtu
+	}
+	report.Peers = make([]storage.NetworkPeerHealth, len(others))
+	var wg sync.WaitGroup
+	for i, peer := range others {
+		wg.Add(1)
+		go func(i int, peer Peer) {
+			defer wg.Done()
+			report.Peers[i] = m.probePeer(ctx, peer, report.MTU)
+		}(i, peer)
+	}
+	wg.Wait()
+	report.Updated = m.Clock.Now().UTC()
+	return &report, nil
+}
+
+// probePeer probes the other node on its advertise and overlay addresses
+func (m *Monitor) probePeer(ctx context.Context, peer Peer, mtu int) storage.NetworkPeerHealth {
+	health := storage.NetworkPeerHealth{
+		Node:        peer.Node,
+		AdvertiseIP: peer.AdvertiseIP,
+		Host:        m.probePath(ctx, peer.AdvertiseIP, m.Probes, minPacketSize),
+	}
+	if peer.OverlayIP != "" {
+		overlay := m.probePath(ctx, peer.OverlayIP, m.Probes, minPacketSize)
+		health.Overlay = &overlay
+	}
+	if mtu > 0 && !health.Host.IsUnreachable() {
+		path := m.probePath(ctx, peer.AdvertiseIP, mtuProbes, mtu-ipv4HeaderSize-udpHeaderSize)
+		health.MTUExceeded = path.IsUnreachable()
+	}
+	return health
+}
+
+// probePath sends the specified number of probes of the given size
+// to the address and returns the result
+func (m *Monitor) probePath(ctx context.Context, ip string, probes, size int) storage.NetworkPathHealth {
+	addr := &net.UDPAddr{IP: net.ParseIP(ip), Port: m.port}
+	rtts := make(chan time.Duration, probes)
+	var wg sync.WaitGroup
+	for i := 0; i < probes; i++ {
+		if i != 0 {
+			select {
+			case <-time.After(probeSpacing):
+			case <-ctx.Done():
+			}
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rtt, err := m.ping(ctx, addr, size)
+			if err != nil {
+				m.WithError(err).Debugf("Probe to %v failed.", addr)
+				return
+			}
+			rtts <- rtt
+		}()
+	}
+	wg.Wait()
+	close(rtts)
+	var answered int
+	var total time.Duration
+	for rtt := range rtts {
+		answered++
+		total += rtt
+	}
+	health := storage.NetworkPathHealth{
+		Addr:       net.JoinHostPort(ip, strconv.Itoa(m.port)),
+		PacketLoss: float64(probes-answered) * 100 / float64(probes),
+	}
+	if answered != 0 {
+		health.RTT = total / time.Duration(answered)
+	}
+	return health
+}
+
+// ping sends a probe of the specified size to the address
+// and returns the round-trip time
+func (m *Monitor) ping(ctx context.Context, addr *net.UDPAddr, size int) (time.Duration, error) {
+	seq, replyC := m.register()
+	defer m.unregister(seq)
+	start := time.Now()
+	if _, err := m.conn.WriteToUDP(newProbe(seq, size), addr); err != nil {
+		return 0, trace.ConvertSystemError(err)
+	}
+	select {
+	case <-replyC:
+		return time.Since(start), nil
+	case <-time.After(m.Timeout):
+		return 0, trace.LimitExceeded("no reply from %v within %v", addr, m.Timeout)
+	case <-ctx.Done():
+		return 0, trace.Wrap(ctx.Err())
+	}
+}
+
+// serve answers the probes and dispatches the replies
+// until the connection is closed
+func (m *Monitor) serve() {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, addr, err := m.conn.ReadFromUDP(buf)
+		if err != nil {
+			if utils.IsClosedConnectionError(err) {
+				return
+			}
+			m.WithError(err).Warn("Failed to read probe.")
+			continue
+		}
+		packet := buf[:n]
+		kind, seq, err := parsePacket(packet)
+		if err != nil {
+			m.WithError(err).Debugf("Invalid packet from %v.", addr)
+			continue
+		}
+		switch kind {
+		case packetProbe:
+			packet[kindOffset] = packetReply
+			if _, err := m.conn.WriteToUDP(packet, addr); err != nil {
+				m.WithError(err).Debugf("Failed to reply to %v.", addr)
+			}
+		case packetReply:
+			m.dispatch(seq)
+		}
+	}
+}
+
+func (m *Monitor) register() (uint32, <-chan struct{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.seq++
+	replyC := make(chan struct{}, 1)
+	m.pending[m.seq] = replyC
+	return m.seq, replyC
+}
+
+func (m *Monitor) unregister(seq uint32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.pending, seq)
+}
+
+func (m *Monitor) dispatch(seq uint32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if replyC, ok := m.pending[seq]; ok {
+		select {
+		case replyC <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// updateMetrics exports the report as Prometheus metrics
+func updateMetrics(report storage.NetworkHealthReport) {
+	metrics.NetworkPeerRTT.Reset()
+	metrics.NetworkPeerPacketLoss.Reset()
+	metrics.NetworkPeerMTUExceeded.Reset()
+	for _, peer := range report.Peers {
+		setPathMetrics(peer.Node, metrics.PathHost, peer.Host)
+		if peer.Overlay != nil {
+			setPathMetrics(peer.Node, metrics.PathOverlay, *peer.Overlay)
+		}
+		var exceeded float64
+		if peer.MTUExceeded {
+			exceeded = 1
+		}
+		metrics.NetworkPeerMTUExceeded.WithLabelValues(peer.Node).Set(exceeded)
+	}
+}
+
+func setPathMetrics(peer, path string, health storage.NetworkPathHealth) {
+	metrics.NetworkPeerPacketLoss.WithLabelValues(peer, path).Set(health.PacketLoss / 100)
+	if !health.IsUnreachable() {
+		metrics.NetworkPeerRTT.WithLabelValues(peer, path).Set(health.RTT.Seconds())
+	}
+}
+
+// interfaceMTU returns the MTU of the interface with the specified address
+func interfaceMTU(ip string) (int, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return 0, trace.Wrap(err)
+	}
+	for _, iface := range ifaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.String() == ip {
+				return iface.MTU, nil
+			}
+		}
+	}
+	return 0, trace.NotFound("no interface with address %v", ip)
+}
+
+const (
+	// mtuProbes is the number of probes of the maximum size sent to each node
+	mtuProbes = 2
+	// probeSpacing is the interval between the probes sent to an address
+	probeSpacing = 100 * time.Millisecond
+	// ipv4HeaderSize is the size of the IPv4 header without options
+	ipv4HeaderSize = 20
+	// udpHeaderSize is the size of the UDP header
+	udpHeaderSize = 8
+)
@@ -0,0 +1,118 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gravitational/gravity/lib/storage"
+
+	"gopkg.in/check.v1"
+)
+
+func TestMonitor(t *testing.T) { check.TestingT(t) }
+
+type MonitorSuite struct{}
+
+var _ = check.Suite(&MonitorSuite{})
+
+func (s *MonitorSuite) TestParsesPackets(c *check.C) {
+	packet := newProbe(42, 1400)
+	c.Assert(len(packet), check.Equals, 1400)
+	kind, seq, err := parsePacket(packet)
+	c.Assert(err, check.IsNil)
+	c.Assert(kind, check.Equals, packetProbe)
+	c.Assert(seq, check.Equals, uint32(42))
+
+	c.Assert(len(newProbe(1, 1)), check.Equals, minPacketSize)
+	_, _, err = parsePacket([]byte("GNM1"))
+	c.Assert(err, check.NotNil)
+	_, _, err = parsePacket(make([]byte, minPacketSize))
+	c.Assert(err, check.NotNil)
+}
+
+func (s *MonitorSuite) TestProbesPeers(c *check.C) {
+	publisher := &testPublisher{reports: make(chan storage.NetworkHealthReport, 1)}
+	m, err := New(Config{
+		Node: "node-1",
+		Addr: ":0",
+		// the monitor answers its own probes so all peers are on the loopback
+		Peers: testPeers{
+			{Node: "node-1", AdvertiseIP: "127.0.0.1"},
+			{Node: "node-2", AdvertiseIP: "127.0.0.1", OverlayIP: "127.0.0.2"},
+			{Node: "node-3", AdvertiseIP: "127.0.0.1", OverlayIP: "192.0.2.1"},
+		},
+		Publisher: publisher,
+		Probes:    2,
+		Timeout:   200 * time.Millisecond,
+		MTU:       1500,
+	})
+	c.Assert(err, check.IsNil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.Run(ctx)
+
+	var report storage.NetworkHealthReport
+	select {
+	case report = <-publisher.reports:
+	case <-time.After(5 * time.Second):
+		c.Fatal("timeout waiting for report")
+	}
+	c.Assert(report.Node, check.Equals, "node-1")
+	c.Assert(report.AdvertiseIP, check.Equals, "127.0.0.1")
+	c.Assert(report.MTU, check.Equals, 1500)
+	c.Assert(report.Peers, check.HasLen, 2)
+
+	node2 := report.Peers[0]
+	c.Assert(node2.Node, check.Equals, "node-2")
+	c.Assert(node2.Host.PacketLoss, check.Equals, float64(0))
+	c.Assert(node2.Host.RTT > 0, check.Equals, true)
+	c.Assert(node2.Overlay, check.NotNil)
+	c.Assert(node2.Overlay.PacketLoss, check.Equals, float64(0))
+	c.Assert(node2.MTUExceeded, check.Equals, false)
+
+	node3 := report.Peers[1]
+	c.Assert(node3.Node, check.Equals, "node-3")
+	c.Assert(node3.Host.IsUnreachable(), check.Equals, false)
+	c.Assert(node3.Overlay, check.NotNil)
+	c.Assert(node3.Overlay.IsUnreachable(), check.Equals, true)
+
+	problems := storage.NetworkProblems([]storage.NetworkHealthReport{report}, report.Updated)
+	c.Assert(problems, check.HasLen, 1)
+	c.Assert(problems[0].To, check.Equals, "node-3")
+	c.Assert(problems[0].Critical, check.Equals, true)
+}
+
+type testPeers []Peer
+
+func (r testPeers) GetPeers(context.Context) ([]Peer, error) {
+	return r, nil
+}
+
+type testPublisher struct {
+	reports chan storage.NetworkHealthReport
+}
+
+func (r *testPublisher) Publish(ctx context.Context, report storage.NetworkHealthReport) error {
+	select {
+	case r.reports <- report:
+	default:
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package monitor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"syscall"
+
+	"github.com/gravitational/trace"
+)
+
+// The probe packet consists of the magic, the packet kind and the sequence
+// number, padded to the probe size. The reply is the probe sent back with
+// the packet kind changed, so the probes of the maximum size check the
+// path in both directions
+
+// newProbe returns a new probe packet with the specified sequence number and size
+func newProbe(seq uint32, size int) []byte {
+	if size < minPacketSize {
+		size = minPacketSize
+	}
+	packet := make([]byte, size)
+	copy(packet, magic)
+	packet[kindOffset] = packetProbe
+	binary.BigEndian.PutUint32(packet[seqOffset:], seq)
+	return packet
+}
+
+// parsePacket returns the kind and the sequence number of the packet
+func parsePacket(packet []byte) (kind byte, seq uint32, err error) {
+	if len(packet) < minPacketSize || !bytes.Equal(packet[:len(magic)], magic) {
+		return 0, 0, trace.BadParameter("not a network monitor packet")
+	}
+	kind = packet[kindOffset]
+	if kind != packetProbe && kind != packetReply {
+		return 0, 0, trace.BadParameter("unknown packet kind %v", kind)
+	}
+	return kind, binary.BigEndian.Uint32(packet[seqOffset:]), nil
+}
+
+// setDontFragment sets the Don't Fragment flag on the packets sent
+// with the connection
+func setDontFragment(conn *net.UDPConn) error {
+	rawConn, err := conn.SyscallConn()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	var sockErr error
+	err = rawConn.Control(func(fd uintptr) {
+		sockErr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IP,
+			syscall.IP_MTU_DISCOVER, syscall.IP_PMTUDISC_DO)
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if sockErr != nil {
+		return trace.ConvertSystemError(sockErr)
+	}
+	return nil
+}
+
+var magic = []byte("GNM1")
+
+const (
+	// packetProbe is the kind of the probe packet
+	packetProbe byte = 1
+	// packetReply is the kind of the reply packet
+	packetReply byte = 2
+
+	// kindOffset is the offset of the packet kind
+	kindOffset = 4
+	// seqOffset is the offset of the sequence number
+	seqOffset = 5
+	// minPacketSize is the size of the smallest probe
+	minPacketSize = 64
+	// maxPacketSize is the size of the largest probe
+	maxPacketSize = 65535
+)
@@ -465,7 +465,10 @@ type ValidateOptions struct {
 	// DnsAddrs specifies the list of listen IP addresses for coredns
 	DnsAddrs []string `protobuf:"bytes,2,rep,name=dns_addrs,json=dnsAddrs,proto3" json:"dns_addrs,omitempty"`
 	// DnsPort specifies the DNS port for coredns
-	DnsPort              int32    `protobuf:"varint,3,opt,name=dns_port,json=dnsPort,proto3" json:"dns_port,omitempty"`
+	DnsPort int32 `protobuf:"varint,3,opt,name=dns_port,json=dnsPort,proto3" json:"dns_port,omitempty"`
+	// WireguardPort is the port of the WireGuard tunnels between
+	// the nodes if the overlay network is encrypted, 0 otherwise
+	WireguardPort        int32    `protobuf:"varint,4,opt,name=wireguard_port,json=wireguardPort,proto3" json:"wireguard_port,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -516,6 +519,13 @@ func (m *ValidateOptions) GetDnsPort() int32 {
 	return 0
 }
 
+func (m *ValidateOptions) GetWireguardPort() int32 {
+	if m != nil {
+		return m.WireguardPort
+	}
+	return 0
+}
+
 // Docker groups Docker-relevant attributes to validate
 type Docker struct {
 	// StorageDriver specifies the Docker storage driver
@@ -1000,73 +1010,74 @@ func init() {
 func init() { proto.RegisterFile("validation.proto", fileDescriptor_bfc2ab0b60b7792f) }
 
 var fileDescriptor_bfc2ab0b60b7792f = []byte{
-	// 1047 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x56, 0xcd, 0x6e, 0x1b, 0xb7,
-	0x13, 0xc7, 0xda, 0xfa, 0x1c, 0x7f, 0xc4, 0xa6, 0xe3, 0xfc, 0x65, 0xfd, 0x9d, 0xca, 0xd8, 0xc2,
-	0x89, 0x81, 0x20, 0x72, 0xe1, 0xb4, 0x41, 0x91, 0x20, 0x87, 0xb8, 0x4e, 0x0f, 0x45, 0xda, 0x1a,
-	0x34, 0x92, 0x9e, 0x0a, 0x61, 0x57, 0x4b, 0x49, 0xb4, 0xd6, 0xe4, 0x86, 0xa4, 0xec, 0x3a, 0x4f,
-	0xd1, 0x43, 0xcf, 0x3d, 0xf6, 0x6d, 0x7a, 0xed, 0xd1, 0x0f, 0xe0, 0x87, 0x28, 0x0a, 0x0e, 0xb9,
-	0xda, 0x5d, 0xdb, 0x45, 0x6e, 0xbd, 0x68, 0x39, 0x33, 0xbf, 0xe1, 0xfc, 0x66, 0x86, 0x1c, 0x0a,
-	0xd6, 0xce, 0xa3, 0x94, 0x27, 0x91, 0xe1, 0x52, 0xf4, 0x33, 0x25, 0x8d, 0x24, 0x75, 0xfc, 0x74,
-	0x9f, 0x8e, 0xb9, 0x99, 0xcc, 0xe2, 0xfe, 0x50, 0x9e, 0xed, 0x8f, 0xe5, 0x58, 0xee, 0xa3, 0x3a,
-	0x9e, 0x8d, 0x50, 0x42, 0x01, 0x57, 0xce, 0xab, 0xfb, 0xd9, 0x58, 0xca, 0x71, 0xca, 0x0a, 0x54,
-	0x32, 0x53, 0xa5, 0x5d, 0xbb, 0x1b, 0xd1, 0x98, 0x09, 0x93, 0xc5, 0xfb, 0xf8, 0x75, 0xca, 0xf0,
-	0xd7, 0x00, 0xd6, 0xbf, 0x99, 0xb0, 0xe1, 0xf4, 0x58, 0x2a, 0xa3, 0x29, 0xfb, 0x30, 0x63, 0xda,
-	0x90, 0xcf, 0xa1, 0x91, 0x72, 0x6d, 0x98, 0xe8, 0x04, 0x3b, 0x8b, 0x7b, 0x4b, 0x07, 0x4b, 0x0e,
-	0xdd, 0x7f, 0x9d, 0x24, 0x8a, 0x7a, 0x13, 0xe9, 0x41, 0x2d, 0xe3, 0x62, 0xdc, 0x59, 0xb8, 0x0d,
-	0x41, 0x03, 0xf9, 0x0a, 0x5a, 0x39, 0x85, 0xce, 0xe2, 0x4e, 0xb0, 0xb7, 0x74, 0xb0, 0xd5, 0x77,
-	0x1c, 0xfb, 0x39, 0xc7, 0xfe, 0x91, 0x07, 0xd0, 0x39, 0x34, 0x3c, 0x05, 0x52, 0x66, 0xa4, 0x33,
-	0x29, 0x34, 0x23, 0x4f, 0x6e, 0x50, 0xda, 0xf0, 0xf1, 0x4e, 0x98, 0x3a, 0x67, 0x8a, 0x32, 0x3d,
-	0x4b, 0xcd, 0x9c, 0xda, 0xe3, 0x0a, 0xb5, 0x3b, 0xa1, 0x08, 0x08, 0x7f, 0x0b, 0x60, 0x13, 0x83,
-	0x1d, 0x46, 0x22, 0xb9, 0xe0, 0x89, 0x99, 0xdc, 0x55, 0x82, 0xe0, 0xbf, 0x2e, 0xc1, 0x73, 0x78,
-	0x70, 0x93, 0x95, 0x2f, 0xc3, 0x36, 0xb4, 0xe3, 0x5c, 0x89, 0xcc, 0x6a, 0xb4, 0x50, 0x84, 0x3f,
-	0xc3, 0x72, 0x39, 0x49, 0x42, 0xa0, 0x36, 0x94, 0x09, 0x43, 0x60, 0x9d, 0xe2, 0x9a, 0xdc, 0x87,
-	0x3a, 0x53, 0x4a, 0xaa, 0xce, 0xc2, 0x4e, 0xb0, 0xd7, 0xa6, 0x4e, 0xb0, 0xe9, 0x6a, 0xf4, 0xf4,
-	0x34, 0xab, 0xe9, 0x3a, 0x53, 0xf8, 0x25, 0xd4, 0xac, 0x4c, 0x3a, 0xd0, 0x14, 0xcc, 0x5c, 0x48,
-	0x35, 0xc5, 0x9d, 0xdb, 0x34, 0x17, 0x6d, 0xc0, 0x28, 0x49, 0xf2, 0xbd, 0x71, 0x1d, 0xfe, 0x19,
-	0xc0, 0xbd, 0xf7, 0xee, 0x88, 0xb3, 0xbc, 0xba, 0x5d, 0x68, 0x9d, 0x45, 0x82, 0x8f, 0x98, 0x36,
-	0xb8, 0xc5, 0x32, 0x9d, 0xcb, 0x76, 0xf7, 0x4c, 0xc9, 0x11, 0x4f, 0x99, 0xdf, 0x26, 0x17, 0xc9,
-	0x13, 0x58, 0x1f, 0xcd, 0xd2, 0x74, 0xa0, 0xd8, 0x87, 0x19, 0x57, 0xec, 0x8c, 0x09, 0xa3, 0x91,
-	0x6f, 0x8b, 0xae, 0x59, 0x03, 0x2d, 0xe9, 0xc9, 0x17, 0xd0, 0x94, 0x99, 0xad, 0xa6, 0xee, 0xd4,
-	0x30, 0xa5, 0x07, 0x3e, 0xa5, 0x9c, 0xcb, 0x8f, 0xce, 0x4a, 0x73, 0x18, 0xd9, 0x85, 0x46, 0x22,
-	0x87, 0x53, 0xa6, 0x3a, 0x75, 0x74, 0x58, 0xf1, 0x0e, 0x47, 0xa8, 0xa4, 0xde, 0x18, 0xbe, 0x80,
-	0xb5, 0x22, 0x1d, 0xdf, 0x96, 0x47, 0xd0, 0x18, 0x45, 0x3c, 0x65, 0x89, 0x3f, 0x9d, 0xab, 0x7d,
-	0x7f, 0xd9, 0xfa, 0xc7, 0x4a, 0xc6, 0x8c, 0x7a, 0x6b, 0x38, 0x29, 0x4a, 0xe1, 0xc3, 0x93, 0x87,
-	0x00, 0xe7, 0xbf, 0xa4, 0x91, 0x18, 0x64, 0x52, 0x19, 0xdf, 0xa9, 0x36, 0x6a, 0xec, 0x05, 0x20,
-	0xff, 0x87, 0x76, 0x22, 0xf4, 0xc0, 0x56, 0x52, 0xe3, 0x39, 0x6b, 0xd3, 0x56, 0x22, 0xb4, 0xed,
-	0x83, 0x26, 0x5b, 0x60, 0xd7, 0xce, 0x73, 0x11, 0x3d, 0x9b, 0x89, 0xd0, 0xd6, 0x2f, 0xdc, 0x87,
-	0x86, 0xe3, 0x4d, 0x76, 0x61, 0x55, 0x1b, 0xa9, 0xa2, 0x31, 0x1b, 0x24, 0x8a, 0xdb, 0x16, 0xbb,
-	0xa6, 0xad, 0x78, 0xed, 0x11, 0x2a, 0xc3, 0x77, 0x7e, 0x10, 0x1c, 0x71, 0x3d, 0x9d, 0x0f, 0x82,
-	0x5d, 0xa8, 0x9d, 0xca, 0x58, 0xfb, 0xac, 0xd6, 0x7d, 0x41, 0xbe, 0xe5, 0xf2, 0x3b, 0x19, 0x9f,
-	0x64, 0x6c, 0x48, 0xd1, 0x6c, 0x79, 0x8c, 0xb8, 0x1c, 0x64, 0x91, 0x99, 0xe4, 0x3d, 0x1b, 0x71,
-	0x79, 0x1c, 0x99, 0x49, 0xf8, 0x77, 0x00, 0x50, 0xe0, 0xed, 0x01, 0x11, 0xd1, 0x19, 0xf3, 0x14,
-	0x70, 0x6d, 0x2b, 0xa0, 0x58, 0x94, 0x0c, 0x2e, 0x14, 0x37, 0x79, 0xcf, 0xdb, 0x56, 0xf3, 0x93,
-	0x55, 0xd8, 0x0a, 0x70, 0x39, 0x60, 0x62, 0xcc, 0x05, 0xc3, 0x2c, 0xdb, 0xb4, 0xc5, 0xe5, 0x1b,
-	0x94, 0xed, 0x7d, 0x18, 0x25, 0x91, 0x89, 0xf4, 0xa5, 0x18, 0x62, 0x9f, 0x5b, 0xb4, 0x50, 0xd8,
-	0x63, 0x66, 0x0f, 0x0e, 0x46, 0xac, 0x3b, 0xcf, 0x5c, 0xb6, 0x51, 0xe3, 0x54, 0x0e, 0xa7, 0x03,
-	0xcd, 0x3f, 0xb2, 0x4e, 0xc3, 0x45, 0x45, 0xcd, 0x09, 0xff, 0xc8, 0x2c, 0x51, 0x34, 0x34, 0x1d,
-	0x51, 0xbb, 0x26, 0xcf, 0xa0, 0xa9, 0x66, 0xc2, 0xf0, 0x33, 0xd6, 0x69, 0x7d, 0xea, 0x32, 0xe7,
-	0xc8, 0xf0, 0x95, 0x1f, 0x67, 0xbe, 0xae, 0xfe, 0xc0, 0x3c, 0xae, 0x14, 0x76, 0xa3, 0x52, 0xd8,
-	0x7c, 0x42, 0x59, 0x40, 0xf8, 0x57, 0x00, 0xcb, 0x65, 0x35, 0x79, 0x04, 0xad, 0x53, 0x19, 0x0f,
-	0x8a, 0x2a, 0x1e, 0x2e, 0x5d, 0x5f, 0xf5, 0x9a, 0xa7, 0x32, 0xb6, 0x2a, 0x6a, 0x17, 0x3f, 0xd8,
-	0xfc, 0x0e, 0xa0, 0x66, 0x6b, 0x88, 0xf5, 0x5c, 0x3a, 0xb8, 0x5f, 0x44, 0xa0, 0x2c, 0x4a, 0xdc,
-	0x5e, 0x87, 0xad, 0xeb, 0xab, 0x1e, 0xa2, 0x28, 0xfe, 0x92, 0xe7, 0x50, 0x77, 0x4d, 0x70, 0x43,
-	0x60, 0xb3, 0x70, 0xc2, 0x56, 0x78, 0xaf, 0xf6, 0xf5, 0x55, 0xcf, 0xe1, 0xa8, 0xfb, 0xd8, 0x58,
-	0xf3, 0x06, 0x54, 0x62, 0x9d, 0x5c, 0x8a, 0x61, 0x39, 0x96, 0x45, 0x51, 0xfc, 0x0d, 0x9f, 0xc2,
-	0x4a, 0x85, 0x0c, 0xd9, 0x86, 0x1a, 0x97, 0x99, 0xc6, 0xa4, 0x02, 0x07, 0xb7, 0x32, 0xc5, 0xdf,
-	0xb0, 0x0f, 0xab, 0x55, 0x1a, 0x9f, 0xc0, 0xbf, 0xc5, 0xed, 0x8b, 0xf8, 0xe4, 0x25, 0x34, 0xd3,
-	0xc8, 0x30, 0x31, 0xbc, 0xf4, 0x13, 0x7d, 0xb3, 0x4a, 0xf3, 0xad, 0x33, 0x1e, 0xc2, 0xf5, 0x55,
-	0xaf, 0x91, 0x46, 0x66, 0x60, 0x47, 0x83, 0xf7, 0x08, 0xff, 0x08, 0x30, 0x7c, 0x09, 0x47, 0xde,
-	0x01, 0x64, 0x4c, 0x0d, 0x99, 0x30, 0x76, 0x52, 0xb9, 0x3e, 0xee, 0xde, 0xb9, 0x65, 0xff, 0x78,
-	0x8e, 0x7b, 0x23, 0x8c, 0xba, 0x3c, 0x5c, 0xbd, 0xbe, 0xea, 0x95, 0x9c, 0x69, 0x69, 0xdd, 0x7d,
-	0x05, 0xf7, 0x6e, 0xc0, 0xc9, 0x1a, 0x2c, 0x4e, 0xd9, 0xa5, 0xbf, 0x32, 0x76, 0x69, 0x67, 0xf8,
-	0x79, 0x94, 0xce, 0xdc, 0x65, 0x59, 0xa4, 0x4e, 0x78, 0xb1, 0xf0, 0x75, 0x70, 0xf0, 0xfb, 0x02,
-	0xc0, 0xfb, 0xf9, 0xff, 0x09, 0xf2, 0x1a, 0xa0, 0x78, 0x4b, 0x49, 0xc7, 0xd3, 0xbb, 0xf5, 0xe0,
-	0x77, 0xb7, 0xee, 0xb0, 0xf8, 0x93, 0xfa, 0x3d, 0xac, 0x56, 0xdf, 0x22, 0xb2, 0x5d, 0x06, 0xdf,
-	0x7c, 0x38, 0xbb, 0x0f, 0xff, 0xc5, 0xea, 0xb7, 0xcb, 0x19, 0xe1, 0x75, 0xa8, 0x32, 0x2a, 0x4f,
-	0x9e, 0x2a, 0xa3, 0xea, 0xdd, 0x79, 0x09, 0xad, 0x7c, 0x88, 0x92, 0x9b, 0x43, 0x3d, 0x77, 0xff,
-	0xdf, 0x2d, 0xbd, 0x73, 0x8e, 0x1b, 0xa8, 0x7f, 0xf6, 0x4f, 0x00, 0x00, 0x00, 0xff, 0xff, 0x50,
-	0x92, 0xcd, 0x81, 0x76, 0x09, 0x00, 0x00,
+	// 1060 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x56, 0xcd, 0x6e, 0xdc, 0x36,
+	0x10, 0x86, 0xec, 0xfd, 0x1d, 0xc7, 0x1b, 0x87, 0x4e, 0x52, 0x79, 0xeb, 0x74, 0x0d, 0x15, 0x4e,
+	0x0c, 0x04, 0x59, 0x17, 0x4e, 0x1b, 0x14, 0x09, 0x72, 0x88, 0xeb, 0xf4, 0x50, 0xa4, 0xad, 0x41,
+	0x23, 0xe9, 0xa9, 0x58, 0x48, 0x2b, 0xee, 0x9a, 0x5e, 0x99, 0x54, 0x48, 0xae, 0x5d, 0xe7, 0x25,
+	0xda, 0x43, 0xcf, 0x3d, 0xf6, 0x6d, 0x7a, 0xed, 0xd1, 0x0f, 0xe0, 0x87, 0x28, 0x0a, 0x0e, 0xa9,
+	0x5d, 0x69, 0xed, 0x22, 0xb7, 0x5e, 0x24, 0xce, 0xcc, 0x37, 0x9c, 0x6f, 0x86, 0xc3, 0x91, 0x60,
+	0xed, 0x2c, 0xce, 0x78, 0x1a, 0x1b, 0x2e, 0x45, 0x3f, 0x57, 0xd2, 0x48, 0x52, 0xc7, 0x57, 0xf7,
+	0xc9, 0x98, 0x9b, 0xe3, 0x69, 0xd2, 0x1f, 0xca, 0xd3, 0xdd, 0xb1, 0x1c, 0xcb, 0x5d, 0x54, 0x27,
+	0xd3, 0x11, 0x4a, 0x28, 0xe0, 0xca, 0x79, 0x75, 0x3f, 0x1b, 0x4b, 0x39, 0xce, 0xd8, 0x1c, 0x95,
+	0x4e, 0x55, 0x69, 0xd7, 0xee, 0x7a, 0x3c, 0x66, 0xc2, 0xe4, 0xc9, 0x2e, 0xbe, 0x9d, 0x32, 0xfa,
+	0x2d, 0x80, 0x3b, 0xdf, 0x1c, 0xb3, 0xe1, 0xe4, 0x50, 0x2a, 0xa3, 0x29, 0x7b, 0x3f, 0x65, 0xda,
+	0x90, 0xcf, 0xa1, 0x91, 0x71, 0x6d, 0x98, 0x08, 0x83, 0xad, 0xe5, 0x9d, 0x95, 0xbd, 0x15, 0x87,
+	0xee, 0xbf, 0x4a, 0x53, 0x45, 0xbd, 0x89, 0xf4, 0xa0, 0x96, 0x73, 0x31, 0x0e, 0x97, 0xae, 0x43,
+	0xd0, 0x40, 0xbe, 0x82, 0x56, 0x41, 0x21, 0x5c, 0xde, 0x0a, 0x76, 0x56, 0xf6, 0x36, 0xfa, 0x8e,
+	0x63, 0xbf, 0xe0, 0xd8, 0x3f, 0xf0, 0x00, 0x3a, 0x83, 0x46, 0x27, 0x40, 0xca, 0x8c, 0x74, 0x2e,
+	0x85, 0x66, 0xe4, 0xf1, 0x02, 0xa5, 0x75, 0x1f, 0xef, 0x88, 0xa9, 0x33, 0xa6, 0x28, 0xd3, 0xd3,
+	0xcc, 0xcc, 0xa8, 0x3d, 0xaa, 0x50, 0xbb, 0x11, 0x8a, 0x80, 0xe8, 0xf7, 0x00, 0xee, 0x61, 0xb0,
+	0xfd, 0x58, 0xa4, 0xe7, 0x3c, 0x35, 0xc7, 0x37, 0x95, 0x20, 0xf8, 0xbf, 0x4b, 0xf0, 0x0c, 0xee,
+	0x2f, 0xb2, 0xf2, 0x65, 0xd8, 0x84, 0x76, 0x52, 0x28, 0x91, 0x59, 0x8d, 0xce, 0x15, 0xd1, 0xcf,
+	0x70, 0xab, 0x9c, 0x24, 0x21, 0x50, 0x1b, 0xca, 0x94, 0x21, 0xb0, 0x4e, 0x71, 0x4d, 0xee, 0x42,
+	0x9d, 0x29, 0x25, 0x55, 0xb8, 0xb4, 0x15, 0xec, 0xb4, 0xa9, 0x13, 0x6c, 0xba, 0x1a, 0x3d, 0x3d,
+	0xcd, 0x6a, 0xba, 0xce, 0x14, 0x7d, 0x09, 0x35, 0x2b, 0x93, 0x10, 0x9a, 0x82, 0x99, 0x73, 0xa9,
+	0x26, 0xb8, 0x73, 0x9b, 0x16, 0xa2, 0x0d, 0x18, 0xa7, 0x69, 0xb1, 0x37, 0xae, 0xa3, 0xbf, 0x02,
+	0xb8, 0xfd, 0xce, 0xb5, 0x38, 0x2b, 0xaa, 0xdb, 0x85, 0xd6, 0x69, 0x2c, 0xf8, 0x88, 0x69, 0x83,
+	0x5b, 0xdc, 0xa2, 0x33, 0xd9, 0xee, 0x9e, 0x2b, 0x39, 0xe2, 0x19, 0xf3, 0xdb, 0x14, 0x22, 0x79,
+	0x0c, 0x77, 0x46, 0xd3, 0x2c, 0x1b, 0x28, 0xf6, 0x7e, 0xca, 0x15, 0x3b, 0x65, 0xc2, 0x68, 0xe4,
+	0xdb, 0xa2, 0x6b, 0xd6, 0x40, 0x4b, 0x7a, 0xf2, 0x05, 0x34, 0x65, 0x6e, 0xab, 0xa9, 0xc3, 0x1a,
+	0xa6, 0x74, 0xdf, 0xa7, 0x54, 0x70, 0xf9, 0xd1, 0x59, 0x69, 0x01, 0x23, 0xdb, 0xd0, 0x48, 0xe5,
+	0x70, 0xc2, 0x54, 0x58, 0x47, 0x87, 0x55, 0xef, 0x70, 0x80, 0x4a, 0xea, 0x8d, 0xd1, 0x73, 0x58,
+	0x9b, 0xa7, 0xe3, 0x8f, 0xe5, 0x21, 0x34, 0x46, 0x31, 0xcf, 0x58, 0xea, 0xbb, 0xb3, 0xd3, 0xf7,
+	0x97, 0xad, 0x7f, 0xa8, 0x64, 0xc2, 0xa8, 0xb7, 0x46, 0xbf, 0x96, 0x6a, 0xe1, 0xe3, 0x93, 0x07,
+	0x00, 0x67, 0xbf, 0x64, 0xb1, 0x18, 0xe4, 0x52, 0x19, 0x7f, 0x54, 0x6d, 0xd4, 0xd8, 0x1b, 0x40,
+	0x3e, 0x85, 0x76, 0x2a, 0xf4, 0xc0, 0x96, 0x52, 0x63, 0xa3, 0xb5, 0x69, 0x2b, 0x15, 0xda, 0x1e,
+	0x84, 0x26, 0x1b, 0x60, 0xd7, 0xce, 0x73, 0x19, 0x3d, 0x9b, 0xa9, 0xd0, 0xe8, 0xb7, 0x0d, 0x9d,
+	0x73, 0xae, 0xd8, 0x78, 0x1a, 0xab, 0xd4, 0x01, 0x6a, 0x08, 0x58, 0x9d, 0x69, 0x2d, 0x2c, 0xda,
+	0x85, 0x86, 0xcb, 0xcf, 0x3a, 0x68, 0x23, 0x55, 0x3c, 0x66, 0x83, 0x54, 0x71, 0xdb, 0x0a, 0xee,
+	0x70, 0x57, 0xbd, 0xf6, 0x00, 0x95, 0xd1, 0x5b, 0x3f, 0x30, 0x0e, 0xb8, 0x9e, 0xcc, 0x06, 0xc6,
+	0x36, 0xd4, 0x4e, 0x64, 0xa2, 0x7d, 0xf6, 0x77, 0x7c, 0xe1, 0xbe, 0xe5, 0xf2, 0x3b, 0x99, 0x1c,
+	0xe5, 0x6c, 0x48, 0xd1, 0x6c, 0xe9, 0x8e, 0xb8, 0x1c, 0xe4, 0xb1, 0x39, 0x2e, 0xce, 0x76, 0xc4,
+	0xe5, 0x61, 0x6c, 0x8e, 0xa3, 0x7f, 0x02, 0x80, 0x39, 0xde, 0x36, 0x92, 0x88, 0x4f, 0x99, 0xa7,
+	0x80, 0x6b, 0x5b, 0x28, 0xc5, 0xe2, 0x74, 0x70, 0xae, 0xb8, 0x29, 0x7a, 0xa3, 0x6d, 0x35, 0x3f,
+	0x59, 0x85, 0x2d, 0x14, 0x97, 0x03, 0x26, 0xc6, 0x5c, 0x30, 0x2c, 0x46, 0x9b, 0xb6, 0xb8, 0x7c,
+	0x8d, 0xb2, 0xbd, 0x37, 0xa3, 0x34, 0x36, 0xb1, 0xbe, 0x10, 0x43, 0x2c, 0x44, 0x8b, 0xce, 0x15,
+	0xb6, 0x1d, 0x6d, 0x83, 0x61, 0xc4, 0xba, 0xf3, 0x2c, 0x64, 0x1b, 0x35, 0xc9, 0xe4, 0x70, 0x32,
+	0xd0, 0xfc, 0x03, 0x0b, 0x1b, 0x2e, 0x2a, 0x6a, 0x8e, 0xf8, 0x07, 0x66, 0x89, 0xa2, 0xa1, 0xe9,
+	0x88, 0xda, 0x35, 0x79, 0x0a, 0x4d, 0x35, 0x15, 0x86, 0x9f, 0xb2, 0xb0, 0xf5, 0xb1, 0x4b, 0x5f,
+	0x20, 0xa3, 0x97, 0x40, 0xca, 0x75, 0xf5, 0x8d, 0xf5, 0xa8, 0x52, 0xd8, 0xf5, 0x4a, 0x61, 0x8b,
+	0x49, 0x66, 0x01, 0xd1, 0xdf, 0x01, 0xdc, 0x2a, 0xab, 0xc9, 0x43, 0x68, 0x9d, 0xc8, 0x64, 0x30,
+	0xaf, 0xe2, 0xfe, 0xca, 0xd5, 0x65, 0xaf, 0x79, 0x22, 0x13, 0xab, 0xa2, 0x76, 0xf1, 0x83, 0xcd,
+	0x6f, 0x0f, 0x6a, 0xb6, 0x86, 0x58, 0xcf, 0x95, 0xbd, 0xbb, 0xf3, 0x08, 0x94, 0xc5, 0xa9, 0xdb,
+	0x6b, 0xbf, 0x75, 0x75, 0xd9, 0x43, 0x14, 0xc5, 0x27, 0x79, 0x06, 0x75, 0x77, 0x08, 0x6e, 0x58,
+	0xdc, 0x9b, 0x3b, 0xe1, 0x51, 0x78, 0xaf, 0xf6, 0xd5, 0x65, 0xcf, 0xe1, 0xa8, 0x7b, 0xd9, 0x58,
+	0xb3, 0x03, 0xa8, 0xc4, 0x3a, 0xba, 0x10, 0xc3, 0x72, 0x2c, 0x8b, 0xa2, 0xf8, 0x8c, 0x9e, 0xc0,
+	0x6a, 0x85, 0x0c, 0xd9, 0x84, 0x1a, 0x97, 0xb9, 0xc6, 0xa4, 0x02, 0x07, 0xb7, 0x32, 0xc5, 0x67,
+	0xd4, 0x87, 0x4e, 0x95, 0xc6, 0x47, 0xf0, 0x6f, 0x60, 0xb5, 0x12, 0x9f, 0xbc, 0x80, 0x66, 0x16,
+	0x1b, 0x26, 0x86, 0x17, 0x61, 0xb0, 0x98, 0x9d, 0x85, 0xbd, 0x71, 0xc6, 0x7d, 0xb8, 0xba, 0xec,
+	0x35, 0xb2, 0xd8, 0x0c, 0xec, 0x08, 0xf1, 0x1e, 0xd1, 0x9f, 0x01, 0x74, 0xaa, 0x38, 0xf2, 0x16,
+	0x20, 0x67, 0x6a, 0xc8, 0x84, 0xb1, 0x13, 0xcd, 0x9d, 0xe3, 0xf6, 0x8d, 0x5b, 0xf6, 0x0f, 0x67,
+	0xb8, 0xd7, 0xc2, 0xa8, 0x8b, 0xfd, 0xce, 0xd5, 0x65, 0xaf, 0xe4, 0x4c, 0x4b, 0xeb, 0xee, 0x4b,
+	0xb8, 0xbd, 0x00, 0x27, 0x6b, 0xb0, 0x3c, 0x61, 0x17, 0xfe, 0xca, 0xd8, 0xa5, 0x9d, 0xf5, 0x67,
+	0x71, 0x36, 0x75, 0x97, 0x65, 0x99, 0x3a, 0xe1, 0xf9, 0xd2, 0xd7, 0xc1, 0xde, 0x1f, 0x4b, 0x00,
+	0xef, 0x66, 0xff, 0x1d, 0xe4, 0x15, 0xc0, 0xfc, 0x9b, 0x4b, 0x42, 0x4f, 0xef, 0xda, 0x8f, 0x41,
+	0x77, 0xe3, 0x06, 0x8b, 0xef, 0xd4, 0xef, 0xa1, 0x53, 0xfd, 0x66, 0x91, 0xcd, 0x32, 0x78, 0xf1,
+	0x03, 0xdb, 0x7d, 0xf0, 0x1f, 0x56, 0xbf, 0x5d, 0xc1, 0x08, 0xaf, 0x43, 0x95, 0x51, 0x79, 0xf2,
+	0x74, 0x37, 0x6e, 0xb0, 0xf8, 0x2d, 0x5e, 0x40, 0xab, 0x98, 0xb5, 0x64, 0x71, 0xf8, 0x17, 0xee,
+	0x9f, 0x5c, 0xd3, 0x3b, 0xe7, 0xa4, 0x81, 0xfa, 0xa7, 0xff, 0x0e, 0x00, 0xc8, 0x29, 0x25, 0x29,
+	0x9e, 0x09, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
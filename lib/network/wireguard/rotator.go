@@ -0,0 +1,217 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wireguard
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+
+	"github.com/gravitational/rigging"
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// RotatorConfig describes the configuration of the key rotator
+type RotatorConfig struct {
+	// Client is the Kubernetes client used to read the nodes
+	// and to update the secret with the keys
+	Client kubernetes.Interface
+	// Port is the port of the WireGuard tunnels
+	Port int
+	// Interval is how often the keys are checked for distribution and rotation
+	Interval time.Duration
+	// RotationInterval is the maximum age of a node key
+	RotationInterval time.Duration
+	// Clock is used to timestamp the keys
+	Clock clockwork.Clock
+	// FieldLogger is used for logging
+	log.FieldLogger
+}
+
+// CheckAndSetDefaults validates the configuration and sets defaults
+func (r *RotatorConfig) CheckAndSetDefaults() error {
+	if r.Client == nil {
+		return trace.BadParameter("Kubernetes client is required")
+	}
+	if r.Port == 0 {
+		r.Port = defaults.WireguardPort
+	}
+	if r.Interval == 0 {
+		r.Interval = defaults.WireguardSyncInterval
+	}
+	if r.RotationInterval == 0 {
+		r.RotationInterval = defaults.WireguardKeyRotationInterval
+	}
+	if r.Clock == nil {
+		r.Clock = clockwork.NewRealClock()
+	}
+	if r.FieldLogger == nil {
+		r.FieldLogger = log.WithField(trace.Component, "wireguard")
+	}
+	return nil
+}
+
+// NewRotator returns a new rotator that distributes and rotates
+// the WireGuard keys of the cluster nodes
+func NewRotator(config RotatorConfig) (*Rotator, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &Rotator{RotatorConfig: config}, nil
+}
+
+// Rotator periodically generates the keys for the new nodes, rotates the
+// expired keys and renders the tunnel configuration of every node into
+// the cluster secret planet configures the tunnels from
+type Rotator struct {
+	RotatorConfig
+}
+
+// Run distributes and rotates the keys until the context is canceled
+func (r *Rotator) Run(ctx context.Context) {
+	r.Info("Starting WireGuard key rotator.")
+	ticker := r.Clock.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		if err := r.Sync(ctx); err != nil {
+			r.WithError(err).Warn("Failed to sync WireGuard keys.")
+		}
+		select {
+		case <-ticker.Chan():
+		case <-ctx.Done():
+			r.Info("Stopping WireGuard key rotator.")
+			return
+		}
+	}
+}
+
+// Sync executes a single distribution and rotation pass
+func (r *Rotator) Sync(ctx context.Context) error {
+	nodes, err := r.getNodes()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	secrets := r.Client.CoreV1().Secrets(defaults.KubeSystemNamespace)
+	secret, err := secrets.Get(defaults.WireguardSecret, metav1.GetOptions{})
+	if err != nil {
+		if err = rigging.ConvertError(err); !trace.IsNotFound(err) {
+			return trace.Wrap(err)
+		}
+		secret = nil
+	}
+	var keys Keys
+	if secret != nil && len(secret.Data[keysSecretKey]) != 0 {
+		if err := json.Unmarshal(secret.Data[keysSecretKey], &keys); err != nil {
+			return trace.Wrap(err, "invalid keys in secret %v", defaults.WireguardSecret)
+		}
+	}
+	keys, changed, err := keys.Sync(nodes, r.Clock.Now().UTC(), r.RotationInterval)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	data, err := r.renderSecretData(nodes, keys)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if secret == nil {
+		r.Infof("Distributing WireGuard keys to %v nodes.", len(nodes))
+		_, err = secrets.Create(&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      defaults.WireguardSecret,
+				Namespace: defaults.KubeSystemNamespace,
+			},
+			Data: data,
+		})
+		return trace.Wrap(rigging.ConvertError(err))
+	}
+	if !changed && secretDataEqual(secret.Data, data) {
+		return nil
+	}
+	r.Info("Updating WireGuard keys.")
+	secret.Data = data
+	_, err = secrets.Update(secret)
+	return trace.Wrap(rigging.ConvertError(err))
+}
+
+// getNodes returns the cluster nodes connected to the overlay network
+func (r *Rotator) getNodes() ([]Node, error) {
+	list, err := r.Client.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, trace.Wrap(rigging.ConvertError(err))
+	}
+	nodes := make([]Node, 0, len(list.Items))
+	for _, node := range list.Items {
+		addr := node.Labels[defaults.KubernetesAdvertiseIPLabel]
+		if addr == "" {
+			r.Warnf("Node %v has no advertise address label, skipping.", node.Name)
+			continue
+		}
+		nodes = append(nodes, Node{
+			Name:        node.Name,
+			AdvertiseIP: addr,
+			PodCIDR:     node.Spec.PodCIDR,
+		})
+	}
+	return nodes, nil
+}
+
+// renderSecretData returns the contents of the secret with the keys
+// and the tunnel configuration of every node
+func (r *Rotator) renderSecretData(nodes []Node, keys Keys) (map[string][]byte, error) {
+	data := make(map[string][]byte, len(nodes)+1)
+	encoded, err := json.Marshal(keys)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	data[keysSecretKey] = encoded
+	for _, node := range nodes {
+		config, err := RenderConfig(node, nodes, keys, r.Port)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		data[ConfigSecretKey(node.Name)] = config
+	}
+	return data, nil
+}
+
+// ConfigSecretKey returns the key of the secret with the tunnel
+// configuration of the node with the specified name
+func ConfigSecretKey(nodeName string) string {
+	return nodeName + ".conf"
+}
+
+func secretDataEqual(a, b map[string][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if string(b[key]) != string(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// keysSecretKey is the key of the secret with the keys of all nodes
+const keysSecretKey = "keys.json"
@@ -0,0 +1,183 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package wireguard implements the key management for the encrypted overlay
+// network. The pod traffic between the nodes is sent over WireGuard tunnels
+// configured by planet on every node from the tunnel configuration distributed
+// by gravity in the cluster secret
+package wireguard
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"sort"
+	"text/template"
+	"time"
+
+	"github.com/gravitational/trace"
+	"golang.org/x/crypto/curve25519"
+)
+
+// Node describes a cluster node connected to the encrypted overlay network
+type Node struct {
+	// Name is the Kubernetes name of the node
+	Name string
+	// AdvertiseIP is the address the tunnels to the node are established with
+	AdvertiseIP string
+	// PodCIDR is the pod subnet of the node routed over the tunnel to the node
+	PodCIDR string
+}
+
+// NodeKey is the WireGuard key pair of a node
+type NodeKey struct {
+	// PrivateKey is the base64-encoded private key
+	PrivateKey string `json:"private_key"`
+	// PublicKey is the base64-encoded public key
+	PublicKey string `json:"public_key"`
+	// Created is the time the key was generated
+	Created time.Time `json:"created"`
+}
+
+// GenerateKey generates a new WireGuard key pair
+func GenerateKey(created time.Time) (*NodeKey, error) {
+	return generateKey(rand.Reader, created)
+}
+
+func generateKey(r io.Reader, created time.Time) (*NodeKey, error) {
+	var privateKey, publicKey [keySize]byte
+	if _, err := io.ReadFull(r, privateKey[:]); err != nil {
+		return nil, trace.Wrap(err, "failed to generate private key")
+	}
+	// clamp the private key as required by curve25519
+	privateKey[0] &= 248
+	privateKey[31] &= 127
+	privateKey[31] |= 64
+	curve25519.ScalarBaseMult(&publicKey, &privateKey)
+	return &NodeKey{
+		PrivateKey: base64.StdEncoding.EncodeToString(privateKey[:]),
+		PublicKey:  base64.StdEncoding.EncodeToString(publicKey[:]),
+		Created:    created,
+	}, nil
+}
+
+// Keys maps the names of the nodes to their keys
+type Keys map[string]NodeKey
+
+// Sync distributes the keys to the nodes that do not have one yet, drops the
+// keys of the nodes that have left the cluster and rotates the keys older
+// than the specified interval.
+//
+// At most one key is rotated per call so only the tunnels of a single node
+// are re-established at once. Returns the updated keys and whether they have
+// changed
+func (r Keys) Sync(nodes []Node, now time.Time, interval time.Duration) (Keys, bool, error) {
+	keys := make(Keys, len(nodes))
+	changed := false
+	for _, node := range nodes {
+		key, ok := r[node.Name]
+		if !ok {
+			newKey, err := GenerateKey(now)
+			if err != nil {
+				return nil, false, trace.Wrap(err)
+			}
+			key = *newKey
+			changed = true
+		}
+		keys[node.Name] = key
+	}
+	if len(keys) != len(r) {
+		changed = true
+	}
+	if name := keys.oldest(); name != "" && now.Sub(keys[name].Created) >= interval {
+		key, err := GenerateKey(now)
+		if err != nil {
+			return nil, false, trace.Wrap(err)
+		}
+		keys[name] = *key
+		changed = true
+	}
+	return keys, changed, nil
+}
+
+// oldest returns the name of the node with the oldest key
+func (r Keys) oldest() (name string) {
+	for node, key := range r {
+		if name == "" || key.Created.Before(r[name].Created) ||
+			(key.Created.Equal(r[name].Created) && node < name) {
+			name = node
+		}
+	}
+	return name
+}
+
+// RenderConfig renders the WireGuard configuration of the specified node
+// with a peer for every other node of the cluster that has a key and a pod subnet
+func RenderConfig(node Node, nodes []Node, keys Keys, port int) ([]byte, error) {
+	key, ok := keys[node.Name]
+	if !ok {
+		return nil, trace.NotFound("no key for node %v", node.Name)
+	}
+	values := configValues{PrivateKey: key.PrivateKey, Port: port}
+	for _, peer := range nodes {
+		peerKey, ok := keys[peer.Name]
+		if peer.Name == node.Name || !ok || peer.PodCIDR == "" {
+			continue
+		}
+		values.Peers = append(values.Peers, peerValues{
+			Name:       peer.Name,
+			PublicKey:  peerKey.PublicKey,
+			Endpoint:   peer.AdvertiseIP,
+			AllowedIPs: peer.PodCIDR,
+		})
+	}
+	sort.Slice(values.Peers, func(i, j int) bool {
+		return values.Peers[i].Name < values.Peers[j].Name
+	})
+	var buf bytes.Buffer
+	if err := configTemplate.Execute(&buf, values); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return buf.Bytes(), nil
+}
+
+type configValues struct {
+	PrivateKey string
+	Port       int
+	Peers      []peerValues
+}
+
+type peerValues struct {
+	Name       string
+	PublicKey  string
+	Endpoint   string
+	AllowedIPs string
+}
+
+var configTemplate = template.Must(template.New("wireguard").Parse(`[Interface]
+PrivateKey = {{.PrivateKey}}
+ListenPort = {{.Port}}
+{{range .Peers}}
+# {{.Name}}
+[Peer]
+PublicKey = {{.PublicKey}}
+Endpoint = {{.Endpoint}}:{{$.Port}}
+AllowedIPs = {{.AllowedIPs}}
+{{end}}`))
+
+// keySize is the size of the WireGuard keys in bytes
+const keySize = 32
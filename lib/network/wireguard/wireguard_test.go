@@ -0,0 +1,120 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wireguard
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+func TestWireguard(t *testing.T) { TestingT(t) }
+
+type WireguardSuite struct {
+	now   time.Time
+	nodes []Node
+}
+
+var _ = Suite(&WireguardSuite{})
+
+func (s *WireguardSuite) SetUpTest(c *C) {
+	s.now = time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	s.nodes = []Node{
+		{Name: "node-1", AdvertiseIP: "192.168.1.1", PodCIDR: "10.244.1.0/24"},
+		{Name: "node-2", AdvertiseIP: "192.168.1.2", PodCIDR: "10.244.2.0/24"},
+		{Name: "node-3", AdvertiseIP: "192.168.1.3"},
+	}
+}
+
+func (s *WireguardSuite) TestGeneratesKey(c *C) {
+	// RFC 7748, section 6.1
+	privateKey, err := base64.StdEncoding.DecodeString("dwdtCnMYpX08FsFyUbJmRd9ML4frwJkqsXf7pR25LCo=")
+	c.Assert(err, IsNil)
+	key, err := generateKey(bytes.NewReader(privateKey), s.now)
+	c.Assert(err, IsNil)
+	c.Assert(key.PrivateKey, Equals, "cAdtCnMYpX08FsFyUbJmRd9ML4frwJkqsXf7pR25LGo=")
+	c.Assert(key.PublicKey, Equals, "hSDwCYkwp1R0i33ctD73Wg2/Og0mOBr066SpjqqbTmo=")
+	c.Assert(key.Created, Equals, s.now)
+}
+
+func (s *WireguardSuite) TestDistributesKeys(c *C) {
+	keys, changed, err := Keys(nil).Sync(s.nodes, s.now, time.Hour)
+	c.Assert(err, IsNil)
+	c.Assert(changed, Equals, true)
+	c.Assert(keys, HasLen, 3)
+
+	synced, changed, err := keys.Sync(s.nodes[:2], s.now.Add(time.Minute), time.Hour)
+	c.Assert(err, IsNil)
+	c.Assert(changed, Equals, true)
+	c.Assert(synced, DeepEquals, Keys{"node-1": keys["node-1"], "node-2": keys["node-2"]})
+
+	synced, changed, err = synced.Sync(s.nodes[:2], s.now.Add(time.Minute), time.Hour)
+	c.Assert(err, IsNil)
+	c.Assert(changed, Equals, false)
+}
+
+func (s *WireguardSuite) TestRotatesOneKeyAtATime(c *C) {
+	keys := Keys{
+		"node-1": NodeKey{PublicKey: "key-1", Created: s.now.Add(-2 * time.Hour)},
+		"node-2": NodeKey{PublicKey: "key-2", Created: s.now.Add(-3 * time.Hour)},
+		"node-3": NodeKey{PublicKey: "key-3", Created: s.now},
+	}
+	rotated, changed, err := keys.Sync(s.nodes, s.now, time.Hour)
+	c.Assert(err, IsNil)
+	c.Assert(changed, Equals, true)
+	c.Assert(rotated["node-1"], DeepEquals, keys["node-1"])
+	c.Assert(rotated["node-2"].PublicKey, Not(Equals), "key-2")
+	c.Assert(rotated["node-2"].Created, Equals, s.now)
+	c.Assert(rotated["node-3"], DeepEquals, keys["node-3"])
+
+	rotated, _, err = rotated.Sync(s.nodes, s.now, time.Hour)
+	c.Assert(err, IsNil)
+	c.Assert(rotated["node-1"].PublicKey, Not(Equals), "key-1")
+}
+
+func (s *WireguardSuite) TestRendersConfig(c *C) {
+	keys := Keys{
+		"node-1": NodeKey{PrivateKey: "private-1", PublicKey: "public-1"},
+		"node-2": NodeKey{PrivateKey: "private-2", PublicKey: "public-2"},
+		"node-3": NodeKey{PrivateKey: "private-3", PublicKey: "public-3"},
+	}
+	config, err := RenderConfig(s.nodes[2], s.nodes, keys, 51820)
+	c.Assert(err, IsNil)
+	c.Assert(string(config), Equals, `[Interface]
+PrivateKey = private-3
+ListenPort = 51820
+
+# node-1
+[Peer]
+PublicKey = public-1
+Endpoint = 192.168.1.1:51820
+AllowedIPs = 10.244.1.0/24
+
+# node-2
+[Peer]
+PublicKey = public-2
+Endpoint = 192.168.1.2:51820
+AllowedIPs = 10.244.2.0/24
+`)
+
+	_, err = RenderConfig(Node{Name: "node-4"}, s.nodes, keys, 51820)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package networkpolicy implements the baseline of Kubernetes network
+// policies a cluster can be installed with.
+//
+// The baseline denies all ingress traffic to the pods in the locked down
+// namespaces except for the traffic within the same namespace, the traffic
+// from the system namespace, the cluster DNS queries and the traffic to the
+// ports the application manifest declares for the system components
+package networkpolicy
+
+import (
+	"sort"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/schema"
+
+	"github.com/gravitational/trace"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// IsSupported returns true if the specified network type
+// enforces network policies
+func IsSupported(networkType string) bool {
+	return networkType == schema.NetworkingCalico
+}
+
+// Policies returns the network policy baseline for the specified
+// application manifest
+func Policies(manifest schema.Manifest) ([]networkingv1.NetworkPolicy, error) {
+	tcp, udp, err := systemPorts(manifest)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var policies []networkingv1.NetworkPolicy
+	for _, namespace := range Namespaces {
+		policies = append(policies,
+			newPolicy(DefaultDenyPolicy, namespace, networkingv1.NetworkPolicySpec{
+				PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			}),
+			newPolicy(allowNamespacePolicy, namespace, networkingv1.NetworkPolicySpec{
+				PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+				Ingress: []networkingv1.NetworkPolicyIngressRule{{
+					From: []networkingv1.NetworkPolicyPeer{
+						{PodSelector: &metav1.LabelSelector{}},
+						{NamespaceSelector: namespaceSelector(constants.KubeSystemNamespace)},
+					},
+				}},
+			}))
+	}
+	policies = append(policies,
+		newPolicy(allowDNSPolicy, constants.KubeSystemNamespace, networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      dnsAppLabel,
+					Operator: metav1.LabelSelectorOpIn,
+					Values:   []string{defaults.KubeDNSLabel, defaults.KubeDNSWorkerLabel},
+				}},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From:  []networkingv1.NetworkPolicyPeer{{NamespaceSelector: &metav1.LabelSelector{}}},
+				Ports: policyPorts([]int{dnsPort}, []int{dnsPort}),
+			}},
+		}))
+	if len(tcp) != 0 || len(udp) != 0 {
+		policies = append(policies,
+			newPolicy(allowSystemPortsPolicy, constants.KubeSystemNamespace, networkingv1.NetworkPolicySpec{
+				PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+				Ingress: []networkingv1.NetworkPolicyIngressRule{{
+					Ports: policyPorts(tcp, udp),
+				}},
+			}))
+	}
+	return policies, nil
+}
+
+// NamespaceLabels returns the labels the policies select
+// the specified namespace with
+func NamespaceLabels(namespace string) map[string]string {
+	return map[string]string{NamespaceLabel: namespace}
+}
+
+// systemPorts returns the sorted sets of TCP and UDP ports declared
+// by all node profiles of the specified manifest
+func systemPorts(manifest schema.Manifest) (tcp, udp []int, err error) {
+	tcpPorts := make(map[int]struct{})
+	udpPorts := make(map[int]struct{})
+	for _, profile := range manifest.NodeProfiles {
+		profileTCP, profileUDP, err := profile.Ports()
+		if err != nil {
+			return nil, nil, trace.Wrap(err)
+		}
+		for _, port := range profileTCP {
+			tcpPorts[port] = struct{}{}
+		}
+		for _, port := range profileUDP {
+			udpPorts[port] = struct{}{}
+		}
+	}
+	return sortedPorts(tcpPorts), sortedPorts(udpPorts), nil
+}
+
+func sortedPorts(ports map[int]struct{}) (result []int) {
+	for port := range ports {
+		result = append(result, port)
+	}
+	sort.Ints(result)
+	return result
+}
+
+func policyPorts(tcp, udp []int) (result []networkingv1.NetworkPolicyPort) {
+	for _, ports := range []struct {
+		protocol v1.Protocol
+		ports    []int
+	}{{v1.ProtocolTCP, tcp}, {v1.ProtocolUDP, udp}} {
+		for _, port := range ports.ports {
+			protocol := ports.protocol
+			portNumber := intstr.FromInt(port)
+			result = append(result, networkingv1.NetworkPolicyPort{
+				Protocol: &protocol,
+				Port:     &portNumber,
+			})
+		}
+	}
+	return result
+}
+
+func namespaceSelector(namespace string) *metav1.LabelSelector {
+	return &metav1.LabelSelector{MatchLabels: NamespaceLabels(namespace)}
+}
+
+func newPolicy(name, namespace string, spec networkingv1.NetworkPolicySpec) networkingv1.NetworkPolicy {
+	return networkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       KindNetworkPolicy,
+			APIVersion: networkingv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{defaults.ApplicationLabel: appLabel},
+		},
+		Spec: spec,
+	}
+}
+
+const (
+	// KindNetworkPolicy is the network policy resource kind
+	KindNetworkPolicy = "NetworkPolicy"
+	// NamespaceLabel is the label the locked down namespaces are selected with
+	NamespaceLabel = "gravitational.io/namespace"
+	// DefaultDenyPolicy is the name of the policy that denies
+	// all ingress traffic in a namespace
+	DefaultDenyPolicy = "default-deny"
+
+	// allowNamespacePolicy is the name of the policy that allows
+	// the traffic within the namespace and from the system namespace
+	allowNamespacePolicy = "allow-namespace"
+	// allowDNSPolicy is the name of the policy that allows
+	// the cluster DNS queries
+	allowDNSPolicy = "allow-dns"
+	// allowSystemPortsPolicy is the name of the policy that allows the traffic
+	// to the ports declared by the manifest for the system components
+	allowSystemPortsPolicy = "allow-system-ports"
+	// appLabel marks the policies of the baseline
+	appLabel = "network-policy-baseline"
+	// dnsAppLabel is the label that selects the cluster DNS pods
+	dnsAppLabel = "k8s-app"
+	// dnsPort is the cluster DNS port
+	dnsPort = 53
+)
+
+// Namespaces lists the namespaces the baseline locks down
+var Namespaces = []string{
+	constants.KubeSystemNamespace,
+	metav1.NamespaceDefault,
+	metav1.NamespacePublic,
+}
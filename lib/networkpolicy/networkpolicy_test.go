@@ -0,0 +1,90 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkpolicy
+
+import (
+	"testing"
+
+	"github.com/gravitational/gravity/lib/schema"
+
+	"gopkg.in/check.v1"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+func TestNetworkPolicy(t *testing.T) { check.TestingT(t) }
+
+type NetworkPolicySuite struct{}
+
+var _ = check.Suite(&NetworkPolicySuite{})
+
+func (s *NetworkPolicySuite) TestGeneratesPolicies(c *check.C) {
+	manifest := schema.Manifest{
+		NodeProfiles: schema.NodeProfiles{
+			newProfile("master", schema.Port{Protocol: "tcp", Ranges: []string{"3009", "3023-3024"}}),
+			newProfile("node",
+				schema.Port{Protocol: "tcp", Ranges: []string{"3009"}},
+				schema.Port{Protocol: "udp", Ranges: []string{"8472"}}),
+		},
+	}
+	policies, err := Policies(manifest)
+	c.Assert(err, check.IsNil)
+	c.Assert(policies, check.HasLen, len(Namespaces)*2+2)
+
+	deny := findPolicy(policies, DefaultDenyPolicy, "default")
+	c.Assert(deny, check.NotNil)
+	c.Assert(deny.Spec.Ingress, check.HasLen, 0)
+	c.Assert(deny.Spec.PolicyTypes, check.DeepEquals, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress})
+
+	system := findPolicy(policies, allowSystemPortsPolicy, "kube-system")
+	c.Assert(system, check.NotNil)
+	var ports []string
+	for _, port := range system.Spec.Ingress[0].Ports {
+		ports = append(ports, string(*port.Protocol)+"/"+port.Port.String())
+	}
+	c.Assert(ports, check.DeepEquals, []string{"TCP/3009", "TCP/3023", "TCP/3024", "UDP/8472"})
+}
+
+func (s *NetworkPolicySuite) TestRejectsInvalidPorts(c *check.C) {
+	manifest := schema.Manifest{
+		NodeProfiles: schema.NodeProfiles{
+			newProfile("master", schema.Port{Protocol: "sctp", Ranges: []string{"3009"}}),
+		},
+	}
+	_, err := Policies(manifest)
+	c.Assert(err, check.NotNil)
+}
+
+func (s *NetworkPolicySuite) TestSupportsNetworkTypes(c *check.C) {
+	c.Assert(IsSupported(schema.NetworkingCalico), check.Equals, true)
+	c.Assert(IsSupported(schema.NetworkingFlannel), check.Equals, false)
+	c.Assert(IsSupported(schema.NetworkingAWSVPC), check.Equals, false)
+}
+
+func newProfile(name string, ports ...schema.Port) schema.NodeProfile {
+	profile := schema.NodeProfile{Name: name}
+	profile.Requirements.Network.Ports = ports
+	return profile
+}
+
+func findPolicy(policies []networkingv1.NetworkPolicy, name, namespace string) *networkingv1.NetworkPolicy {
+	for _, policy := range policies {
+		if policy.Name == name && policy.Namespace == namespace {
+			return &policy
+		}
+	}
+	return nil
+}
@@ -36,12 +36,14 @@ import (
 // agentService is the access point to the agent cluster for running remote
 // commands.
 // manifest specifies the application manifest with requirements.
+// features specifies the optional tests to execute.
 func CheckServers(ctx context.Context,
 	opKey SiteOperationKey,
 	infos checks.ServerInfos,
 	servers []storage.Server,
 	agentService AgentService,
 	manifest schema.Manifest,
+	features checks.Features,
 ) error {
 	nodes, err := mergeServers(infos, servers)
 	if err != nil {
@@ -56,12 +58,7 @@ func CheckServers(ctx context.Context,
 		Manifest:     manifest,
 		Servers:      nodes,
 		Requirements: requirements,
-		Features: checks.Features{
-			TestBandwidth:    true,
-			TestPorts:        true,
-			TestDockerDevice: true,
-			TestEtcdDisk:     true,
-		},
+		Features:     features,
 	})
 	if err != nil {
 		return trace.Wrap(err)
@@ -0,0 +1,60 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ops
+
+import (
+	"context"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// StreamClusterEvents invokes fn for every cluster event recorded after
+// the event specified in the request as the events are recorded.
+// The events table is checked for new events with the specified interval.
+// Returns when the context is cancelled or fn returns an error
+func StreamClusterEvents(ctx context.Context, audit Audit, req GetClusterEventsRequest, interval time.Duration, fn func(storage.ClusterEvent) error) error {
+	if req.Limit == 0 {
+		req.Limit = defaults.ClusterEventsLimit
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		events, err := audit.GetClusterEvents(ctx, req)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		for _, event := range events {
+			if err := fn(event); err != nil {
+				return trace.Wrap(err)
+			}
+			req.After = event.ID
+		}
+		if len(events) == req.Limit && ctx.Err() == nil {
+			// there might be more events to send
+			continue
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return trace.Wrap(ctx.Err())
+		}
+	}
+}
@@ -85,6 +85,25 @@ const (
 	SiteStateUpdatingEnviron = "updating_cluster_environ"
 	// SiteStateUpdatingConfig is the state of the cluster when it's updating configuration
 	SiteStateUpdatingConfig = "updating_cluster_config"
+	// SiteStateUpdatingNodeRole is the state of the cluster when it's promoting or demoting a node
+	SiteStateUpdatingNodeRole = "updating_node_role"
+	// SiteStateReplacingNode is the state of the cluster when it's replacing a node
+	SiteStateReplacingNode = "replacing_node"
+	// SiteStateRotatingCertificates is the state of the cluster when it's rotating certificates
+	SiteStateRotatingCertificates = "rotating_certificates"
+	// SiteStateRotatingSecretsKey is the state of the cluster when it's rotating
+	// the key that encrypts secrets at rest
+	SiteStateRotatingSecretsKey = "rotating_secrets_key"
+	// SiteStateChangingCIDR is the state of the cluster when its pod and service subnets are being changed
+	SiteStateChangingCIDR = "changing_cidr"
+	// SiteStateRestoring is the state of the cluster when it's being restored from a backup
+	SiteStateRestoring = "restoring"
+	// SiteStateRestoringEtcd is the state of the cluster when its etcd is being restored from a snapshot
+	SiteStateRestoringEtcd = "restoring_etcd"
+	// SiteStateBackingUpApp is the state of the cluster when an application is being backed up
+	SiteStateBackingUpApp = "backing_up_app"
+	// SiteStateRestoringApp is the state of the cluster when an application is being restored from a backup
+	SiteStateRestoringApp = "restoring_app"
 	// SiteStateDegraded means that the application installed on a deployed site is failing its health check
 	SiteStateDegraded = "degraded"
 	// SiteStateOffline means that OpsCenter cannot connect to remote site
@@ -132,9 +151,48 @@ const (
 	OperationUpdateConfig           = "operation_update_config"
 	OperationUpdateConfigInProgress = "update_config_in_progress"
 
+	// node promotion/demotion operation
+	OperationUpdateNodeRole           = "operation_update_node_role"
+	OperationUpdateNodeRoleInProgress = "update_node_role_in_progress"
+
+	// node replacement operation
+	OperationReplaceNode           = "operation_replace_node"
+	OperationReplaceNodeInProgress = "replace_node_in_progress"
+
+	// certificate rotation operation
+	OperationRotateCertificates           = "operation_rotate_certs"
+	OperationRotateCertificatesInProgress = "rotate_certs_in_progress"
+
+	// secrets encryption key rotation operation
+	OperationRotateSecretsKey           = "operation_rotate_secrets_key"
+	OperationRotateSecretsKeyInProgress = "rotate_secrets_key_in_progress"
+
+	// pod and service subnet change operation
+	OperationChangeCIDR           = "operation_change_cidr"
+	OperationChangeCIDRInProgress = "change_cidr_in_progress"
+
+	// disaster recovery restore operation
+	OperationRestore           = "operation_restore"
+	OperationRestoreInProgress = "restore_in_progress"
+
+	// etcd snapshot restore operation
+	OperationEtcdRestore           = "operation_etcd_restore"
+	OperationEtcdRestoreInProgress = "etcd_restore_in_progress"
+
+	// application backup operation
+	OperationAppBackup           = "operation_app_backup"
+	OperationAppBackupInProgress = "app_backup_in_progress"
+
+	// application restore operation
+	OperationAppRestore           = "operation_app_restore"
+	OperationAppRestoreInProgress = "app_restore_in_progress"
+
 	// common operation states
 	OperationStateCompleted = "completed"
 	OperationStateFailed    = "failed"
+	// OperationStateFailedRolledBack indicates that the operation has failed
+	// and has been automatically rolled back
+	OperationStateFailedRolledBack = "failed_rolled_back"
 
 	// Teleport node labels
 	// AdvertiseIP defines a label with advertise IP address
@@ -181,6 +239,15 @@ const (
 	// a shrink operation
 	AgentModeShrink = "shrink"
 
+	// AgentLabels is used to pass the custom node labels as comma-separated key=value pairs
+	AgentLabels = "labels"
+
+	// AgentTaints is used to pass the custom node taints as comma-separated key=value:effect values
+	AgentTaints = "taints"
+
+	// AgentNodePool is used to pass the name of the node pool the node joins
+	AgentNodePool = "node_pool"
+
 	// InstallToken names the query parameter with a one-time install token
 	InstallToken = "install_token"
 
@@ -200,6 +267,15 @@ var (
 		OperationGarbageCollect:       SiteStateGarbageCollecting,
 		OperationUpdateRuntimeEnviron: SiteStateUpdatingEnviron,
 		OperationUpdateConfig:         SiteStateUpdatingConfig,
+		OperationUpdateNodeRole:       SiteStateUpdatingNodeRole,
+		OperationReplaceNode:          SiteStateReplacingNode,
+		OperationRotateCertificates:   SiteStateRotatingCertificates,
+		OperationRotateSecretsKey:     SiteStateRotatingSecretsKey,
+		OperationChangeCIDR:           SiteStateChangingCIDR,
+		OperationRestore:              SiteStateRestoring,
+		OperationEtcdRestore:          SiteStateRestoringEtcd,
+		OperationAppBackup:            SiteStateBackingUpApp,
+		OperationAppRestore:           SiteStateRestoringApp,
 	}
 
 	// OperationSucceededToClusterState defines states the cluster transitions
@@ -213,6 +289,15 @@ var (
 		OperationGarbageCollect:       SiteStateActive,
 		OperationUpdateRuntimeEnviron: SiteStateActive,
 		OperationUpdateConfig:         SiteStateActive,
+		OperationUpdateNodeRole:       SiteStateActive,
+		OperationReplaceNode:          SiteStateActive,
+		OperationRotateCertificates:   SiteStateActive,
+		OperationRotateSecretsKey:     SiteStateActive,
+		OperationChangeCIDR:           SiteStateActive,
+		OperationRestore:              SiteStateActive,
+		OperationEtcdRestore:          SiteStateActive,
+		OperationAppBackup:            SiteStateActive,
+		OperationAppRestore:           SiteStateActive,
 	}
 
 	// OperationFailedToClusterState defines states the cluster transitions
@@ -228,5 +313,29 @@ var (
 		OperationGarbageCollect:       SiteStateActive,
 		OperationUpdateRuntimeEnviron: SiteStateUpdatingEnviron,
 		OperationUpdateConfig:         SiteStateUpdatingConfig,
+		OperationUpdateNodeRole:       SiteStateUpdatingNodeRole,
+		OperationReplaceNode:          SiteStateReplacingNode,
+		OperationRotateCertificates:   SiteStateRotatingCertificates,
+		OperationRotateSecretsKey:     SiteStateRotatingSecretsKey,
+		OperationChangeCIDR:           SiteStateChangingCIDR,
+		OperationRestore:              SiteStateRestoring,
+		OperationEtcdRestore:          SiteStateRestoringEtcd,
+		OperationAppBackup:            SiteStateActive,
+		OperationAppRestore:           SiteStateActive,
+	}
+
+	// ApprovableOperations lists the types of operations that can be
+	// configured to require approval from a second user before
+	// they are executed
+	ApprovableOperations = []string{
+		OperationUpdate,
+		OperationShrink,
+		OperationUpdateRuntimeEnviron,
+		OperationUpdateConfig,
+		OperationUpdateNodeRole,
+		OperationReplaceNode,
+		OperationRotateCertificates,
+		OperationRotateSecretsKey,
+		OperationChangeCIDR,
 	}
 )
@@ -0,0 +1,69 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/gravitational/rigging"
+	"github.com/gravitational/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+)
+
+// Resources provides access to the gravity custom resources
+type Resources interface {
+	// List returns all custom resources of the specified kind
+	List(kind string) ([]Object, error)
+	// UpdateStatus updates the status of the specified custom resource
+	UpdateStatus(Object) error
+}
+
+// NewResources returns access to the custom resources with the specified
+// Kubernetes client
+func NewResources(client dynamic.Interface) Resources {
+	return &kubeResources{client: client}
+}
+
+type kubeResources struct {
+	client dynamic.Interface
+}
+
+// List returns all custom resources of the specified kind
+func (r *kubeResources) List(kind string) ([]Object, error) {
+	list, err := r.client.Resource(Resource(kind)).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, trace.Wrap(rigging.ConvertError(err))
+	}
+	objects := make([]Object, 0, len(list.Items))
+	for i := range list.Items {
+		object, err := newObject(&list.Items[i])
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		objects = append(objects, *object)
+	}
+	return objects, nil
+}
+
+// UpdateStatus updates the status of the specified custom resource
+func (r *kubeResources) UpdateStatus(object Object) error {
+	if err := object.setStatus(); err != nil {
+		return trace.Wrap(err)
+	}
+	_, err := r.client.Resource(Resource(object.GetKind())).UpdateStatus(
+		object.Unstructured, metav1.UpdateOptions{})
+	return trace.Wrap(rigging.ConvertError(err))
+}
@@ -0,0 +1,439 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/clusterconfig"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	log "github.com/sirupsen/logrus"
+)
+
+// Config describes the configuration of the operation controller
+type Config struct {
+	// Operator is the cluster operator service
+	Operator ops.Operator
+	// Resources provides access to the gravity custom resources
+	Resources Resources
+	// Executor starts the gravity commands on the cluster nodes
+	Executor Executor
+	// Interval is the reconciliation interval
+	Interval time.Duration
+	// StartTimeout is how long to wait for the started command
+	// to create the operation
+	StartTimeout time.Duration
+	// Clock is used to timestamp the requests
+	Clock clockwork.Clock
+	// FieldLogger is used for logging
+	log.FieldLogger
+}
+
+// CheckAndSetDefaults validates the configuration and sets defaults
+func (r *Config) CheckAndSetDefaults() error {
+	if r.Operator == nil {
+		return trace.BadParameter("operator service is required")
+	}
+	if r.Resources == nil {
+		return trace.BadParameter("custom resources client is required")
+	}
+	if r.Executor == nil {
+		return trace.BadParameter("command executor is required")
+	}
+	if r.Interval == 0 {
+		r.Interval = defaults.OperationControllerInterval
+	}
+	if r.StartTimeout == 0 {
+		r.StartTimeout = defaults.OperationControllerStartTimeout
+	}
+	if r.Clock == nil {
+		r.Clock = clockwork.NewRealClock()
+	}
+	if r.FieldLogger == nil {
+		r.FieldLogger = log.WithField(trace.Component, "operation-controller")
+	}
+	return nil
+}
+
+// New returns a new controller that translates the gravity custom
+// resources into cluster operations
+func New(config Config) (*Controller, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &Controller{Config: config}, nil
+}
+
+// Controller periodically lists the ClusterUpgrade, RuntimeEnvironment
+// and ClusterConfig custom resources and starts the gravity operation
+// for each new resource generation, one at a time. The progress of the
+// operation is reported in the resource status
+type Controller struct {
+	Config
+}
+
+// Run reconciles the custom resources until the context is canceled
+func (r *Controller) Run(ctx context.Context) {
+	r.Info("Starting operation controller.")
+	ticker := r.Clock.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.Chan():
+			if err := r.Reconcile(ctx); err != nil {
+				r.WithError(err).Warn("Failed to reconcile custom resources.")
+			}
+		case <-ctx.Done():
+			r.Info("Stopping operation controller.")
+			return
+		}
+	}
+}
+
+// Reconcile executes a single reconciliation pass.
+// The running requests are updated first so the pending requests
+// are only started once the previous ones have finished
+func (r *Controller) Reconcile(ctx context.Context) error {
+	cluster, err := r.Operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	var objects []Object
+	for _, kind := range Kinds {
+		list, err := r.Resources.List(kind)
+		if err != nil {
+			if trace.IsNotFound(err) {
+				// The custom resource definition has not been created
+				continue
+			}
+			return trace.Wrap(err)
+		}
+		objects = append(objects, list...)
+	}
+	sort.SliceStable(objects, func(i, j int) bool {
+		return objects[i].GetCreationTimestamp().Time.Before(objects[j].GetCreationTimestamp().Time)
+	})
+	var errors []error
+	var busy bool
+	for _, object := range objects {
+		if !isRunning(object) {
+			continue
+		}
+		status, err := r.update(*cluster, object)
+		if err != nil {
+			errors = append(errors, trace.Wrap(err, "failed to update %v", object))
+			busy = true
+			continue
+		}
+		busy = busy || status.Phase == PhaseRunning
+	}
+	for _, object := range objects {
+		if !isPending(object) {
+			continue
+		}
+		status, err := r.start(ctx, *cluster, object, busy)
+		if err != nil {
+			errors = append(errors, trace.Wrap(err, "failed to start %v", object))
+			continue
+		}
+		busy = busy || status.Phase == PhaseRunning
+	}
+	return trace.NewAggregate(errors...)
+}
+
+// start starts the operation requested with the specified resource
+// unless another operation is in progress
+func (r *Controller) start(ctx context.Context, cluster ops.Site, object Object, busy bool) (*Status, error) {
+	status := Status{
+		Phase:              PhasePending,
+		ObservedGeneration: object.GetGeneration(),
+	}
+	active, err := ops.GetActiveOperations(cluster.Key(), r.Operator)
+	if err != nil && !trace.IsNotFound(err) {
+		return nil, trace.Wrap(err)
+	}
+	switch {
+	case len(active) != 0:
+		status.Message = fmt.Sprintf("Waiting for operation %v to finish.", active[0].ID)
+		return r.updateStatus(object, status)
+	case busy:
+		status.Message = "Waiting for the previous request to finish."
+		return r.updateStatus(object, status)
+	}
+	args, err := command(object)
+	if err != nil {
+		status.Phase = PhaseFailed
+		status.Message = trace.UserMessage(err)
+		return r.updateStatus(object, status)
+	}
+	unit := unitName(object)
+	startedAt := r.Clock.Now().UTC()
+	node, err := r.Executor.Start(ctx, unit, args)
+	if err != nil {
+		r.WithError(err).Warnf("Failed to start %v.", object)
+		status.Message = fmt.Sprintf("Failed to start operation, will retry: %v.", trace.UserMessage(err))
+		return r.updateStatus(object, status)
+	}
+	r.Infof("Started %v on %v for %v.", unit, node, object)
+	status.Phase = PhaseRunning
+	status.Node = node
+	status.Unit = unit
+	status.StartedAt = &startedAt
+	status.Message = fmt.Sprintf("Started %v on %v.", unit, node)
+	return r.updateStatus(object, status)
+}
+
+// update updates the status of the resource with the progress
+// of the requested operation
+func (r *Controller) update(cluster ops.Site, object Object) (*Status, error) {
+	status := object.Status
+	if status.StartedAt == nil {
+		return nil, trace.BadParameter("%v status is missing the start time", object)
+	}
+	if status.OperationID == "" {
+		operation, err := r.findOperation(cluster.Key(), object.GetKind(), *status.StartedAt)
+		if err != nil && !trace.IsNotFound(err) {
+			return nil, trace.Wrap(err)
+		}
+		if operation == nil {
+			if r.Clock.Now().Sub(*status.StartedAt) > r.StartTimeout {
+				status.Phase = PhaseFailed
+				status.Message = fmt.Sprintf("Operation has not started within %v, "+
+					"check the logs of %v on %v with journalctl.",
+					r.StartTimeout, status.Unit, status.Node)
+			}
+			return r.updateStatus(object, status)
+		}
+		status.OperationID = operation.ID
+	}
+	key := ops.SiteOperationKey{
+		AccountID:   cluster.AccountID,
+		SiteDomain:  cluster.Domain,
+		OperationID: status.OperationID,
+	}
+	operation, err := r.Operator.GetSiteOperation(key)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	progress, err := r.Operator.GetSiteOperationProgress(key)
+	if err != nil && !trace.IsNotFound(err) {
+		return nil, trace.Wrap(err)
+	}
+	if progress != nil {
+		status.Completion = progress.Completion
+		status.Message = progress.Message
+	}
+	plan, err := r.Operator.GetOperationPlan(key)
+	if err != nil && !trace.IsNotFound(err) {
+		return nil, trace.Wrap(err)
+	}
+	if plan != nil {
+		status.Phases = phases(*plan)
+	}
+	switch {
+	case operation.IsCompleted():
+		upgraded, err := isUpgraded(cluster, object)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if !upgraded {
+			// Upgrade through an intermediate release has completed,
+			// wait for the next upgrade operation
+			startedAt := operation.Created.Add(time.Nanosecond).UTC()
+			status.StartedAt = &startedAt
+			status.OperationID = ""
+			status.Message = fmt.Sprintf("Upgraded to %v, waiting for the next upgrade.",
+				cluster.App.Package)
+			break
+		}
+		status.Phase = PhaseCompleted
+		status.Completion = 100
+		status.Message = fmt.Sprintf("Operation %v has completed.", operation.ID)
+	case operation.IsFailed():
+		status.Phase = PhaseFailed
+		status.Message = fmt.Sprintf("Operation %v has failed.", operation.ID)
+		if progress != nil {
+			status.Message = fmt.Sprintf("Operation %v has failed: %v.", operation.ID, progress.Message)
+		}
+	}
+	return r.updateStatus(object, status)
+}
+
+// findOperation returns the first operation of the type requested with
+// the specified kind of resource created after the specified time
+func (r *Controller) findOperation(key ops.SiteKey, kind string, startedAt time.Time) (*ops.SiteOperation, error) {
+	operations, err := r.Operator.GetSiteOperations(key)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var result *ops.SiteOperation
+	for i, operation := range operations {
+		if operation.Type != operationTypes[kind] || operation.Created.Before(startedAt) {
+			continue
+		}
+		if result == nil || operation.Created.Before(result.Created) {
+			result = (*ops.SiteOperation)(&operations[i])
+		}
+	}
+	if result == nil {
+		return nil, trace.NotFound("no %v operation created after %v", operationTypes[kind], startedAt)
+	}
+	return result, nil
+}
+
+func (r *Controller) updateStatus(object Object, status Status) (*Status, error) {
+	if reflect.DeepEqual(object.Status, status) {
+		return &status, nil
+	}
+	if object.Status.Phase != status.Phase {
+		r.Infof("%v is %v: %v", object, status.Phase, status.Message)
+	}
+	object.Status = status
+	if err := r.Resources.UpdateStatus(object); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &status, nil
+}
+
+// command returns the gravity command that starts the operation
+// requested with the specified resource
+func command(object Object) ([]string, error) {
+	switch object.GetKind() {
+	case KindClusterUpgrade:
+		var spec ClusterUpgradeSpec
+		if err := object.spec(&spec); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if _, _, err := parseImage(spec.Image); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		args := []string{defaults.GravityBin, "upgrade", spec.Image}
+		if spec.Strategy != "" {
+			if !utils.StringInSlice(schema.UpgradeStrategies, spec.Strategy) {
+				return nil, trace.BadParameter("unsupported upgrade strategy %q, supported are: %v",
+					spec.Strategy, schema.UpgradeStrategies)
+			}
+			args = append(args, "--strategy="+spec.Strategy)
+		}
+		return args, nil
+	case KindRuntimeEnvironment:
+		var spec RuntimeEnvironmentSpec
+		if err := object.spec(&spec); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		data, err := storage.MarshalEnvironment(storage.NewEnvironment(spec.Data))
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return createResource(data), nil
+	case KindClusterConfig:
+		var spec clusterconfig.Spec
+		if err := object.spec(&spec); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		data, err := clusterconfig.Marshal(clusterconfig.New(spec))
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		// Validate the configuration against the resource schema
+		if _, err := clusterconfig.Unmarshal(data); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return createResource(data), nil
+	}
+	return nil, trace.BadParameter("unsupported resource kind %q", object.GetKind())
+}
+
+// createResource returns the command that creates the specified
+// gravity resource
+func createResource(data []byte) []string {
+	return []string{"/bin/sh", "-c", fmt.Sprintf("echo %v | base64 -d | %v resource create --confirm",
+		base64.StdEncoding.EncodeToString(data), defaults.GravityBin)}
+}
+
+// isUpgraded returns true if the cluster runs the image requested
+// with the specified resource.
+// Always returns true for other kinds of resources
+func isUpgraded(cluster ops.Site, object Object) (bool, error) {
+	if object.GetKind() != KindClusterUpgrade {
+		return true, nil
+	}
+	var spec ClusterUpgradeSpec
+	if err := object.spec(&spec); err != nil {
+		return false, trace.Wrap(err)
+	}
+	name, version, err := parseImage(spec.Image)
+	if err != nil {
+		return false, trace.Wrap(err)
+	}
+	return cluster.App.Package.Name == name && cluster.App.Package.Version == version, nil
+}
+
+// parseImage parses the cluster image reference in the name:version format
+func parseImage(image string) (name, version string, err error) {
+	parts := strings.Split(image, ":")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", trace.BadParameter("cluster image should be in the name:version format, got %q", image)
+	}
+	return parts[0], parts[1], nil
+}
+
+// phases returns the state of the top-level phases of the specified plan
+func phases(plan storage.OperationPlan) []PhaseStatus {
+	result := make([]PhaseStatus, 0, len(plan.Phases))
+	for _, phase := range plan.Phases {
+		result = append(result, PhaseStatus{
+			ID:    phase.ID,
+			State: phase.GetState(),
+		})
+	}
+	return result
+}
+
+// unitName returns the name of the systemd unit that starts
+// the operation for the specified resource
+func unitName(object Object) string {
+	uid := string(object.GetUID())
+	if len(uid) > 8 {
+		uid = uid[:8]
+	}
+	return fmt.Sprintf("gravity-%v-%v-%v-%v", strings.ToLower(object.GetKind()),
+		object.GetName(), object.GetGeneration(), uid)
+}
+
+func isRunning(object Object) bool {
+	return object.Status.ObservedGeneration == object.GetGeneration() &&
+		object.Status.Phase == PhaseRunning
+}
+
+func isPending(object Object) bool {
+	return object.Status.ObservedGeneration != object.GetGeneration() ||
+		object.Status.Phase == PhasePending || object.Status.Phase == ""
+}
@@ -0,0 +1,321 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	. "gopkg.in/check.v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestController(t *testing.T) { TestingT(t) }
+
+type ControllerSuite struct {
+	clock     clockwork.FakeClock
+	operator  *testOperator
+	resources *testResources
+	executor  *testExecutor
+	ctrl      *Controller
+}
+
+var _ = Suite(&ControllerSuite{})
+
+func (s *ControllerSuite) SetUpTest(c *C) {
+	s.clock = clockwork.NewFakeClockAt(time.Date(2019, time.June, 1, 10, 0, 0, 0, time.UTC))
+	s.operator = &testOperator{
+		cluster: ops.Site{
+			AccountID: "system",
+			Domain:    "example.com",
+			App: ops.Application{
+				Package: loc.MustParseLocator("gravitational.io/telekube:6.1.0"),
+			},
+		},
+	}
+	s.resources = &testResources{objects: make(map[string]*unstructured.Unstructured)}
+	s.executor = &testExecutor{}
+	var err error
+	s.ctrl, err = New(Config{
+		Operator:  s.operator,
+		Resources: s.resources,
+		Executor:  s.executor,
+		Clock:     s.clock,
+	})
+	c.Assert(err, IsNil)
+}
+
+func (s *ControllerSuite) TestReportsOperationProgress(c *C) {
+	s.resources.add(KindRuntimeEnvironment, "env", 1, map[string]interface{}{
+		"data": map[string]interface{}{"HTTP_PROXY": "http://proxy:3128"},
+	})
+
+	c.Assert(s.ctrl.Reconcile(context.TODO()), IsNil)
+	status := s.resources.status(c, KindRuntimeEnvironment, "env")
+	c.Assert(status.Phase, Equals, PhaseRunning)
+	c.Assert(status.Node, Equals, "10.0.0.1:3022")
+	c.Assert(s.executor.units, DeepEquals, []string{status.Unit})
+	c.Assert(s.executor.args[0][:2], DeepEquals, []string{"/bin/sh", "-c"})
+	c.Assert(s.executor.args[0][2], Matches, "echo .* | base64 -d | /usr/bin/gravity resource create --confirm")
+
+	// The operation has not been created yet
+	c.Assert(s.ctrl.Reconcile(context.TODO()), IsNil)
+	c.Assert(s.resources.status(c, KindRuntimeEnvironment, "env").OperationID, Equals, "")
+
+	s.operator.addOperation(ops.OperationUpdateRuntimeEnviron, s.clock.Now().Add(time.Second))
+	s.operator.progress = &ops.ProgressEntry{Completion: 40, Message: "Restarting runtime on node-1"}
+	s.operator.plan = &storage.OperationPlan{Phases: []storage.OperationPhase{
+		{ID: "/masters", State: storage.OperationPhaseStateCompleted},
+		{ID: "/nodes", State: storage.OperationPhaseStateInProgress},
+	}}
+	c.Assert(s.ctrl.Reconcile(context.TODO()), IsNil)
+	status = s.resources.status(c, KindRuntimeEnvironment, "env")
+	c.Assert(status.Phase, Equals, PhaseRunning)
+	c.Assert(status.OperationID, Equals, "op-1")
+	c.Assert(status.Completion, Equals, 40)
+	c.Assert(status.Message, Equals, "Restarting runtime on node-1")
+	c.Assert(status.Phases, DeepEquals, []PhaseStatus{
+		{ID: "/masters", State: storage.OperationPhaseStateCompleted},
+		{ID: "/nodes", State: storage.OperationPhaseStateInProgress},
+	})
+
+	s.operator.operations[0].State = ops.OperationStateCompleted
+	c.Assert(s.ctrl.Reconcile(context.TODO()), IsNil)
+	status = s.resources.status(c, KindRuntimeEnvironment, "env")
+	c.Assert(status.Phase, Equals, PhaseCompleted)
+	c.Assert(status.Completion, Equals, 100)
+
+	// Finished requests are not restarted
+	c.Assert(s.ctrl.Reconcile(context.TODO()), IsNil)
+	c.Assert(s.executor.units, HasLen, 1)
+
+	// A new generation of the resource is a new request
+	s.resources.objects[KindRuntimeEnvironment+"/env"].SetGeneration(2)
+	c.Assert(s.ctrl.Reconcile(context.TODO()), IsNil)
+	c.Assert(s.executor.units, HasLen, 2)
+	status = s.resources.status(c, KindRuntimeEnvironment, "env")
+	c.Assert(status.Phase, Equals, PhaseRunning)
+	c.Assert(status.ObservedGeneration, Equals, int64(2))
+}
+
+func (s *ControllerSuite) TestStartsOneOperationAtATime(c *C) {
+	s.operator.addOperation(ops.OperationExpand, s.clock.Now())
+	s.resources.add(KindClusterConfig, "config", 1, map[string]interface{}{
+		"global": map[string]interface{}{"featureGates": map[string]interface{}{"PodPriority": true}},
+	})
+	s.resources.add(KindClusterUpgrade, "upgrade", 1, map[string]interface{}{
+		"image": "telekube:6.1.5",
+	})
+
+	c.Assert(s.ctrl.Reconcile(context.TODO()), IsNil)
+	c.Assert(s.executor.units, HasLen, 0)
+	status := s.resources.status(c, KindClusterConfig, "config")
+	c.Assert(status.Phase, Equals, PhasePending)
+	c.Assert(status.Message, Equals, "Waiting for operation op-1 to finish.")
+
+	s.operator.operations[0].State = ops.OperationStateCompleted
+	c.Assert(s.ctrl.Reconcile(context.TODO()), IsNil)
+	c.Assert(s.executor.units, HasLen, 1)
+	c.Assert(s.resources.status(c, KindClusterConfig, "config").Phase, Equals, PhaseRunning)
+	status = s.resources.status(c, KindClusterUpgrade, "upgrade")
+	c.Assert(status.Phase, Equals, PhasePending)
+	c.Assert(status.Message, Equals, "Waiting for the previous request to finish.")
+
+	s.clock.Advance(time.Second)
+	s.operator.addOperation(ops.OperationUpdateConfig, s.clock.Now())
+	s.operator.operations[1].State = ops.OperationStateFailed
+	c.Assert(s.ctrl.Reconcile(context.TODO()), IsNil)
+	c.Assert(s.resources.status(c, KindClusterConfig, "config").Phase, Equals, PhaseFailed)
+	c.Assert(s.executor.args[1], DeepEquals, []string{"/usr/bin/gravity", "upgrade", "telekube:6.1.5"})
+	c.Assert(s.resources.status(c, KindClusterUpgrade, "upgrade").Phase, Equals, PhaseRunning)
+}
+
+func (s *ControllerSuite) TestWaitsForIntermediateUpgrades(c *C) {
+	s.resources.add(KindClusterUpgrade, "upgrade", 1, map[string]interface{}{
+		"image": "telekube:6.1.5",
+	})
+	c.Assert(s.ctrl.Reconcile(context.TODO()), IsNil)
+
+	s.clock.Advance(time.Second)
+	s.operator.addOperation(ops.OperationUpdate, s.clock.Now())
+	s.operator.operations[0].State = ops.OperationStateCompleted
+	s.operator.cluster.App.Package = loc.MustParseLocator("gravitational.io/telekube:6.1.2")
+	c.Assert(s.ctrl.Reconcile(context.TODO()), IsNil)
+	status := s.resources.status(c, KindClusterUpgrade, "upgrade")
+	c.Assert(status.Phase, Equals, PhaseRunning)
+	c.Assert(status.OperationID, Equals, "")
+	c.Assert(status.Message, Equals, "Upgraded to gravitational.io/telekube:6.1.2, waiting for the next upgrade.")
+
+	s.clock.Advance(time.Second)
+	s.operator.addOperation(ops.OperationUpdate, s.clock.Now())
+	s.operator.operations[1].State = ops.OperationStateCompleted
+	s.operator.cluster.App.Package = loc.MustParseLocator("gravitational.io/telekube:6.1.5")
+	c.Assert(s.ctrl.Reconcile(context.TODO()), IsNil)
+	status = s.resources.status(c, KindClusterUpgrade, "upgrade")
+	c.Assert(status.Phase, Equals, PhaseCompleted)
+	c.Assert(status.OperationID, Equals, "op-2")
+	c.Assert(s.executor.units, HasLen, 1)
+}
+
+func (s *ControllerSuite) TestFailsInvalidRequests(c *C) {
+	s.resources.add(KindClusterUpgrade, "upgrade", 1, map[string]interface{}{
+		"image": "telekube",
+	})
+	c.Assert(s.ctrl.Reconcile(context.TODO()), IsNil)
+	status := s.resources.status(c, KindClusterUpgrade, "upgrade")
+	c.Assert(status.Phase, Equals, PhaseFailed)
+	c.Assert(status.Message, Matches, "cluster image should be in the name:version format.*")
+	c.Assert(s.executor.units, HasLen, 0)
+}
+
+func (s *ControllerSuite) TestFailsIfOperationHasNotStarted(c *C) {
+	s.resources.add(KindRuntimeEnvironment, "env", 1, map[string]interface{}{
+		"data": map[string]interface{}{"HTTP_PROXY": "http://proxy:3128"},
+	})
+	c.Assert(s.ctrl.Reconcile(context.TODO()), IsNil)
+	c.Assert(s.resources.status(c, KindRuntimeEnvironment, "env").Phase, Equals, PhaseRunning)
+
+	s.clock.Advance(s.ctrl.StartTimeout + time.Second)
+	c.Assert(s.ctrl.Reconcile(context.TODO()), IsNil)
+	status := s.resources.status(c, KindRuntimeEnvironment, "env")
+	c.Assert(status.Phase, Equals, PhaseFailed)
+	c.Assert(status.Message, Matches, "Operation has not started within 5m0s.*")
+}
+
+func (s *ControllerSuite) TestFormatsShellCommand(c *C) {
+	c.Assert(shellCommand([]string{"systemd-run", "/bin/sh", "-c", "echo 'hello'"}), Equals,
+		`'systemd-run' '/bin/sh' '-c' 'echo '\''hello'\'''`)
+}
+
+type testOperator struct {
+	ops.Operator
+	cluster    ops.Site
+	operations []storage.SiteOperation
+	progress   *ops.ProgressEntry
+	plan       *storage.OperationPlan
+}
+
+func (r *testOperator) addOperation(operationType string, created time.Time) {
+	r.operations = append(r.operations, storage.SiteOperation{
+		ID:         fmt.Sprintf("op-%v", len(r.operations)+1),
+		AccountID:  r.cluster.AccountID,
+		SiteDomain: r.cluster.Domain,
+		Type:       operationType,
+		State:      ops.OperationStateUpdateInProgress,
+		Created:    created,
+	})
+}
+
+func (r *testOperator) GetLocalSite() (*ops.Site, error) {
+	cluster := r.cluster
+	return &cluster, nil
+}
+
+func (r *testOperator) GetSiteOperations(ops.SiteKey) (ops.SiteOperations, error) {
+	return ops.SiteOperations(r.operations), nil
+}
+
+func (r *testOperator) GetSiteOperation(key ops.SiteOperationKey) (*ops.SiteOperation, error) {
+	for _, operation := range r.operations {
+		if operation.ID == key.OperationID {
+			return (*ops.SiteOperation)(&operation), nil
+		}
+	}
+	return nil, trace.NotFound("operation %v not found", key.OperationID)
+}
+
+func (r *testOperator) GetSiteOperationProgress(ops.SiteOperationKey) (*ops.ProgressEntry, error) {
+	if r.progress == nil {
+		return nil, trace.NotFound("no progress")
+	}
+	return r.progress, nil
+}
+
+func (r *testOperator) GetOperationPlan(ops.SiteOperationKey) (*storage.OperationPlan, error) {
+	if r.plan == nil {
+		return nil, trace.NotFound("no plan")
+	}
+	return r.plan, nil
+}
+
+type testResources struct {
+	objects map[string]*unstructured.Unstructured
+	created int
+}
+
+func (r *testResources) add(kind, name string, generation int64, spec map[string]interface{}) {
+	object := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": spec,
+	}}
+	object.SetAPIVersion(Group + "/" + Version)
+	object.SetKind(kind)
+	object.SetName(name)
+	object.SetUID(types.UID("4f8c1a2e-" + name))
+	object.SetGeneration(generation)
+	r.created++
+	object.SetCreationTimestamp(metav1.NewTime(time.Unix(int64(r.created), 0)))
+	r.objects[kind+"/"+name] = object
+}
+
+func (r *testResources) status(c *C, kind, name string) Status {
+	object, err := newObject(r.objects[kind+"/"+name].DeepCopy())
+	c.Assert(err, IsNil)
+	return object.Status
+}
+
+func (r *testResources) List(kind string) (objects []Object, err error) {
+	for _, object := range r.objects {
+		if object.GetKind() != kind {
+			continue
+		}
+		parsed, err := newObject(object.DeepCopy())
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		objects = append(objects, *parsed)
+	}
+	return objects, nil
+}
+
+func (r *testResources) UpdateStatus(object Object) error {
+	if err := object.setStatus(); err != nil {
+		return trace.Wrap(err)
+	}
+	r.objects[object.GetKind()+"/"+object.GetName()] = object.DeepCopy()
+	return nil
+}
+
+type testExecutor struct {
+	units []string
+	args  [][]string
+}
+
+func (r *testExecutor) Start(ctx context.Context, unit string, args []string) (string, error) {
+	r.units = append(r.units, unit)
+	r.args = append(r.args, args)
+	return "10.0.0.1:3022", nil
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/schema"
+
+	"github.com/gravitational/trace"
+)
+
+// Executor starts gravity commands on the cluster nodes
+type Executor interface {
+	// Start starts the command as a transient systemd unit with the specified
+	// name on one of the cluster master nodes and returns the node address.
+	// The unit keeps running after Start returns
+	Start(ctx context.Context, unit string, args []string) (node string, err error)
+}
+
+// NewExecutor returns a new executor that starts commands on the master
+// nodes of the specified cluster using the teleport proxy
+func NewExecutor(proxy ops.TeleportProxyService, clusterName string) Executor {
+	return &teleportExecutor{
+		proxy:       proxy,
+		clusterName: clusterName,
+	}
+}
+
+type teleportExecutor struct {
+	proxy       ops.TeleportProxyService
+	clusterName string
+}
+
+// Start starts the command on one of the cluster master nodes.
+// Implements Executor
+func (r *teleportExecutor) Start(ctx context.Context, unit string, args []string) (node string, err error) {
+	masters, err := r.proxy.GetServers(ctx, r.clusterName, map[string]string{
+		schema.ServiceLabelRole: string(schema.ServiceRoleMaster),
+	})
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	if len(masters) == 0 {
+		return "", trace.NotFound("no master nodes found in cluster %v", r.clusterName)
+	}
+	node = masters[0].GetAddr()
+	command := append([]string{"systemd-run", "--unit=" + unit}, args...)
+	var out bytes.Buffer
+	err = r.proxy.ExecuteCommand(ctx, r.clusterName, node, shellCommand(command), &out)
+	if err != nil {
+		return "", trace.Wrap(err, "failed to start %v on %v: %s", unit, node, out.Bytes())
+	}
+	return node, nil
+}
+
+// shellCommand formats the arguments as a shell command line
+func shellCommand(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, "'"+strings.Replace(arg, "'", `'\''`, -1)+"'")
+	}
+	return strings.Join(quoted, " ")
+}
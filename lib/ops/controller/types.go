@@ -0,0 +1,190 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gravitational/gravity/lib/ops"
+
+	"github.com/gravitational/trace"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// Group is the API group of the gravity custom resources
+	Group = "gravitational.io"
+	// Version is the API version of the gravity custom resources
+	Version = "v1"
+
+	// KindClusterUpgrade is the custom resource that requests a cluster upgrade
+	KindClusterUpgrade = "ClusterUpgrade"
+	// KindRuntimeEnvironment is the custom resource that requests
+	// an update of the cluster runtime environment variables
+	KindRuntimeEnvironment = "RuntimeEnvironment"
+	// KindClusterConfig is the custom resource that requests
+	// an update of the cluster configuration
+	KindClusterConfig = "ClusterConfig"
+)
+
+const (
+	// PhasePending means the requested operation has not been started yet
+	PhasePending = "Pending"
+	// PhaseRunning means the requested operation is in progress
+	PhaseRunning = "Running"
+	// PhaseCompleted means the requested operation has completed successfully
+	PhaseCompleted = "Completed"
+	// PhaseFailed means the requested operation has failed
+	PhaseFailed = "Failed"
+)
+
+// Kinds lists all custom resource kinds handled by the controller
+var Kinds = []string{
+	KindClusterUpgrade,
+	KindRuntimeEnvironment,
+	KindClusterConfig,
+}
+
+// Resource returns the API resource for the specified kind
+func Resource(kind string) schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    Group,
+		Version:  Version,
+		Resource: resources[kind],
+	}
+}
+
+// ClusterUpgradeSpec defines the requested cluster upgrade
+type ClusterUpgradeSpec struct {
+	// Image is the cluster image to upgrade to, in the name:version format.
+	// The image must have been uploaded to the cluster
+	Image string `json:"image"`
+	// Strategy optionally overrides the upgrade strategy from the image manifest
+	Strategy string `json:"strategy,omitempty"`
+}
+
+// RuntimeEnvironmentSpec defines the requested runtime environment
+type RuntimeEnvironmentSpec struct {
+	// Data is the set of environment variables
+	Data map[string]string `json:"data"`
+}
+
+// Status is the status of the operation requested with a custom resource
+type Status struct {
+	// Phase is the state of the request, one of Pending, Running,
+	// Completed or Failed
+	Phase string `json:"phase,omitempty"`
+	// ObservedGeneration is the resource generation the status refers to
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Message describes the current state of the request
+	Message string `json:"message,omitempty"`
+	// Node is the address of the node the operation has been started on
+	Node string `json:"node,omitempty"`
+	// Unit is the name of the systemd unit that started the operation
+	Unit string `json:"unit,omitempty"`
+	// StartedAt is when the operation has been started
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+	// OperationID is the ID of the gravity operation
+	OperationID string `json:"operationID,omitempty"`
+	// Completion is the operation progress in percent
+	Completion int `json:"completion,omitempty"`
+	// Phases lists the state of the top-level operation plan phases
+	Phases []PhaseStatus `json:"phases,omitempty"`
+}
+
+// PhaseStatus is the state of a single operation plan phase
+type PhaseStatus struct {
+	// ID is the phase ID
+	ID string `json:"id"`
+	// State is the phase state
+	State string `json:"state"`
+}
+
+// IsFinished returns true if the request has either completed or failed
+func (r Status) IsFinished() bool {
+	return r.Phase == PhaseCompleted || r.Phase == PhaseFailed
+}
+
+// Object is a gravity custom resource
+type Object struct {
+	*unstructured.Unstructured
+	// Status is the parsed resource status
+	Status Status
+}
+
+// newObject parses the status of the specified custom resource
+func newObject(obj *unstructured.Unstructured) (*Object, error) {
+	var status Status
+	if data, ok := obj.Object["status"]; ok {
+		bytes, err := json.Marshal(data)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if err := json.Unmarshal(bytes, &status); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	return &Object{Unstructured: obj, Status: status}, nil
+}
+
+// spec decodes the resource spec into the provided value
+func (r *Object) spec(spec interface{}) error {
+	bytes, err := json.Marshal(r.Object["spec"])
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := json.Unmarshal(bytes, spec); err != nil {
+		return trace.BadParameter("invalid %v spec: %v", r.GetKind(), err)
+	}
+	return nil
+}
+
+// setStatus stores the parsed status in the underlying resource
+func (r *Object) setStatus() error {
+	bytes, err := json.Marshal(r.Status)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	var status map[string]interface{}
+	if err := json.Unmarshal(bytes, &status); err != nil {
+		return trace.Wrap(err)
+	}
+	r.Object["status"] = status
+	return nil
+}
+
+// String returns the textual representation of this resource
+func (r Object) String() string {
+	return r.GetKind() + "/" + r.GetName()
+}
+
+// operationTypes maps custom resource kinds to the types
+// of the gravity operations they request
+var operationTypes = map[string]string{
+	KindClusterUpgrade:     ops.OperationUpdate,
+	KindRuntimeEnvironment: ops.OperationUpdateRuntimeEnviron,
+	KindClusterConfig:      ops.OperationUpdateConfig,
+}
+
+// resources maps custom resource kinds to their API resource names
+var resources = map[string]string{
+	KindClusterUpgrade:     "clusterupgrades",
+	KindRuntimeEnvironment: "runtimeenvironments",
+	KindClusterConfig:      "clusterconfigs",
+}
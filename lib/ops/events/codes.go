@@ -139,6 +139,141 @@ var (
 		Name: OperationFailedEvent,
 		Code: OperationConfigFailureCode,
 	}
+	// OperationNodeRoleStart is emitted when node promotion or demotion launches.
+	OperationNodeRoleStart = events.Event{
+		Name: OperationStartedEvent,
+		Code: OperationNodeRoleStartCode,
+	}
+	// OperationNodeRoleComplete is emitted when node promotion or demotion successfully completes.
+	OperationNodeRoleComplete = events.Event{
+		Name: OperationCompletedEvent,
+		Code: OperationNodeRoleCompleteCode,
+	}
+	// OperationNodeRoleFailure is emitted when node promotion or demotion fails.
+	OperationNodeRoleFailure = events.Event{
+		Name: OperationFailedEvent,
+		Code: OperationNodeRoleFailureCode,
+	}
+	// OperationReplaceNodeStart is emitted when node replacement launches.
+	OperationReplaceNodeStart = events.Event{
+		Name: OperationStartedEvent,
+		Code: OperationReplaceNodeStartCode,
+	}
+	// OperationReplaceNodeComplete is emitted when node replacement successfully completes.
+	OperationReplaceNodeComplete = events.Event{
+		Name: OperationCompletedEvent,
+		Code: OperationReplaceNodeCompleteCode,
+	}
+	// OperationReplaceNodeFailure is emitted when node replacement fails.
+	OperationReplaceNodeFailure = events.Event{
+		Name: OperationFailedEvent,
+		Code: OperationReplaceNodeFailureCode,
+	}
+	// OperationRotateCertsStart is emitted when certificate rotation launches.
+	OperationRotateCertsStart = events.Event{
+		Name: OperationStartedEvent,
+		Code: OperationRotateCertsStartCode,
+	}
+	// OperationRotateCertsComplete is emitted when certificate rotation successfully completes.
+	OperationRotateCertsComplete = events.Event{
+		Name: OperationCompletedEvent,
+		Code: OperationRotateCertsCompleteCode,
+	}
+	// OperationRotateCertsFailure is emitted when certificate rotation fails.
+	OperationRotateCertsFailure = events.Event{
+		Name: OperationFailedEvent,
+		Code: OperationRotateCertsFailureCode,
+	}
+	// OperationRotateSecretsKeyStart is emitted when secrets key rotation launches.
+	OperationRotateSecretsKeyStart = events.Event{
+		Name: OperationStartedEvent,
+		Code: OperationRotateSecretsKeyStartCode,
+	}
+	// OperationRotateSecretsKeyComplete is emitted when secrets key rotation successfully completes.
+	OperationRotateSecretsKeyComplete = events.Event{
+		Name: OperationCompletedEvent,
+		Code: OperationRotateSecretsKeyCompleteCode,
+	}
+	// OperationRotateSecretsKeyFailure is emitted when secrets key rotation fails.
+	OperationRotateSecretsKeyFailure = events.Event{
+		Name: OperationFailedEvent,
+		Code: OperationRotateSecretsKeyFailureCode,
+	}
+	// OperationChangeCIDRStart is emitted when the subnet change launches.
+	OperationChangeCIDRStart = events.Event{
+		Name: OperationStartedEvent,
+		Code: OperationChangeCIDRStartCode,
+	}
+	// OperationChangeCIDRComplete is emitted when the subnet change successfully completes.
+	OperationChangeCIDRComplete = events.Event{
+		Name: OperationCompletedEvent,
+		Code: OperationChangeCIDRCompleteCode,
+	}
+	// OperationChangeCIDRFailure is emitted when the subnet change fails.
+	OperationChangeCIDRFailure = events.Event{
+		Name: OperationFailedEvent,
+		Code: OperationChangeCIDRFailureCode,
+	}
+	// OperationRestoreStart is emitted when cluster restore launches.
+	OperationRestoreStart = events.Event{
+		Name: OperationStartedEvent,
+		Code: OperationRestoreStartCode,
+	}
+	// OperationRestoreComplete is emitted when cluster restore successfully completes.
+	OperationRestoreComplete = events.Event{
+		Name: OperationCompletedEvent,
+		Code: OperationRestoreCompleteCode,
+	}
+	// OperationRestoreFailure is emitted when cluster restore fails.
+	OperationRestoreFailure = events.Event{
+		Name: OperationFailedEvent,
+		Code: OperationRestoreFailureCode,
+	}
+	// OperationEtcdRestoreStart is emitted when etcd restore launches.
+	OperationEtcdRestoreStart = events.Event{
+		Name: OperationStartedEvent,
+		Code: OperationEtcdRestoreStartCode,
+	}
+	// OperationEtcdRestoreComplete is emitted when etcd restore successfully completes.
+	OperationEtcdRestoreComplete = events.Event{
+		Name: OperationCompletedEvent,
+		Code: OperationEtcdRestoreCompleteCode,
+	}
+	// OperationEtcdRestoreFailure is emitted when etcd restore fails.
+	OperationEtcdRestoreFailure = events.Event{
+		Name: OperationFailedEvent,
+		Code: OperationEtcdRestoreFailureCode,
+	}
+	// OperationAppBackupStart is emitted when application backup launches.
+	OperationAppBackupStart = events.Event{
+		Name: OperationStartedEvent,
+		Code: OperationAppBackupStartCode,
+	}
+	// OperationAppBackupComplete is emitted when application backup successfully completes.
+	OperationAppBackupComplete = events.Event{
+		Name: OperationCompletedEvent,
+		Code: OperationAppBackupCompleteCode,
+	}
+	// OperationAppBackupFailure is emitted when application backup fails.
+	OperationAppBackupFailure = events.Event{
+		Name: OperationFailedEvent,
+		Code: OperationAppBackupFailureCode,
+	}
+	// OperationAppRestoreStart is emitted when application restore launches.
+	OperationAppRestoreStart = events.Event{
+		Name: OperationStartedEvent,
+		Code: OperationAppRestoreStartCode,
+	}
+	// OperationAppRestoreComplete is emitted when application restore successfully completes.
+	OperationAppRestoreComplete = events.Event{
+		Name: OperationCompletedEvent,
+		Code: OperationAppRestoreCompleteCode,
+	}
+	// OperationAppRestoreFailure is emitted when application restore fails.
+	OperationAppRestoreFailure = events.Event{
+		Name: OperationFailedEvent,
+		Code: OperationAppRestoreFailureCode,
+	}
 	// UserCreated is emitted when a user is created/updated.
 	UserCreated = events.Event{
 		Name: UserCreatedEvent,
@@ -234,6 +369,132 @@ var (
 		Name: InviteCreatedEvent,
 		Code: UserInviteCreatedCode,
 	}
+	// ClusterDNSUpdated is emitted when cluster DNS configuration is updated.
+	ClusterDNSUpdated = events.Event{
+		Name: ClusterDNSUpdatedEvent,
+		Code: ClusterDNSUpdatedCode,
+	}
+	// NodePoolCreated is emitted when a node pool is created/updated.
+	NodePoolCreated = events.Event{
+		Name: NodePoolCreatedEvent,
+		Code: NodePoolCreatedCode,
+	}
+	// NodePoolDeleted is emitted when a node pool is deleted.
+	NodePoolDeleted = events.Event{
+		Name: NodePoolDeletedEvent,
+		Code: NodePoolDeletedCode,
+	}
+	// ClusterRosterUpdated is emitted when the cluster roster is created/updated.
+	ClusterRosterUpdated = events.Event{
+		Name: ClusterRosterUpdatedEvent,
+		Code: ClusterRosterUpdatedCode,
+	}
+	// ClusterRosterDeleted is emitted when the cluster roster is deleted.
+	ClusterRosterDeleted = events.Event{
+		Name: ClusterRosterDeletedEvent,
+		Code: ClusterRosterDeletedCode,
+	}
+	// ClusterRosterChangesPending is emitted when the cluster membership diverges
+	// from the roster and the changes await approval.
+	ClusterRosterChangesPending = events.Event{
+		Name: ClusterRosterChangesPendingEvent,
+		Code: ClusterRosterChangesPendingCode,
+	}
+	// ClusterRosterChangesApproved is emitted when pending roster changes are approved.
+	ClusterRosterChangesApproved = events.Event{
+		Name: ClusterRosterChangesApprovedEvent,
+		Code: ClusterRosterChangesApprovedCode,
+	}
+	// ServiceAccountCreated is emitted when a service account is created/updated.
+	ServiceAccountCreated = events.Event{
+		Name: ServiceAccountCreatedEvent,
+		Code: ServiceAccountCreatedCode,
+	}
+	// ServiceAccountDeleted is emitted when a service account is deleted.
+	ServiceAccountDeleted = events.Event{
+		Name: ServiceAccountDeletedEvent,
+		Code: ServiceAccountDeletedCode,
+	}
+	// OperationWebhookCreated is emitted when an operation webhook is created/updated.
+	OperationWebhookCreated = events.Event{
+		Name: OperationWebhookCreatedEvent,
+		Code: OperationWebhookCreatedCode,
+	}
+	// OperationWebhookDeleted is emitted when an operation webhook is deleted.
+	OperationWebhookDeleted = events.Event{
+		Name: OperationWebhookDeletedEvent,
+		Code: OperationWebhookDeletedCode,
+	}
+	// HealthCheckCreated is emitted when a health check is created/updated.
+	HealthCheckCreated = events.Event{
+		Name: HealthCheckCreatedEvent,
+		Code: HealthCheckCreatedCode,
+	}
+	// HealthCheckDeleted is emitted when a health check is deleted.
+	HealthCheckDeleted = events.Event{
+		Name: HealthCheckDeletedEvent,
+		Code: HealthCheckDeletedCode,
+	}
+	// ImageTrustPolicyUpdated is emitted when the image trust policy is created/updated.
+	ImageTrustPolicyUpdated = events.Event{
+		Name: ImageTrustPolicyUpdatedEvent,
+		Code: ImageTrustPolicyUpdatedCode,
+	}
+	// ImageTrustPolicyDeleted is emitted when the image trust policy is deleted.
+	ImageTrustPolicyDeleted = events.Event{
+		Name: ImageTrustPolicyDeletedEvent,
+		Code: ImageTrustPolicyDeletedCode,
+	}
+	// OperationApprovalPolicyUpdated is emitted when the operation approval policy is created/updated.
+	OperationApprovalPolicyUpdated = events.Event{
+		Name: OperationApprovalPolicyUpdatedEvent,
+		Code: OperationApprovalPolicyUpdatedCode,
+	}
+	// OperationApprovalPolicyDeleted is emitted when the operation approval policy is deleted.
+	OperationApprovalPolicyDeleted = events.Event{
+		Name: OperationApprovalPolicyDeletedEvent,
+		Code: OperationApprovalPolicyDeletedCode,
+	}
+	// CatalogSyncScheduleUpdated is emitted when the catalog sync schedule is created/updated.
+	CatalogSyncScheduleUpdated = events.Event{
+		Name: CatalogSyncScheduleUpdatedEvent,
+		Code: CatalogSyncScheduleUpdatedCode,
+	}
+	// CatalogSyncScheduleDeleted is emitted when the catalog sync schedule is deleted.
+	CatalogSyncScheduleDeleted = events.Event{
+		Name: CatalogSyncScheduleDeletedEvent,
+		Code: CatalogSyncScheduleDeletedCode,
+	}
+	// BackupScheduleUpdated is emitted when the backup schedule is created/updated.
+	BackupScheduleUpdated = events.Event{
+		Name: BackupScheduleUpdatedEvent,
+		Code: BackupScheduleUpdatedCode,
+	}
+	// BackupScheduleDeleted is emitted when the backup schedule is deleted.
+	BackupScheduleDeleted = events.Event{
+		Name: BackupScheduleDeletedEvent,
+		Code: BackupScheduleDeletedCode,
+	}
+	// ReplicationUpdated is emitted when the replication is created/updated.
+	ReplicationUpdated = events.Event{
+		Name: ReplicationUpdatedEvent,
+		Code: ReplicationUpdatedCode,
+	}
+	// ReplicationDeleted is emitted when the replication is deleted.
+	ReplicationDeleted = events.Event{
+		Name: ReplicationDeletedEvent,
+		Code: ReplicationDeletedCode,
+	}
+	// OperationApproved is emitted when an operation that requires approval is approved.
+	OperationApproved = events.Event{
+		Name: OperationApprovedEvent,
+		Code: OperationApprovedCode,
+	}
+	// ScaleUpRequested is emitted when cluster scale up is requested.
+	ScaleUpRequested = events.Event{
+		Name: ScaleUpRequestedEvent,
+		Code: ScaleUpRequestedCode,
+	}
 	// ClusterUnhealthy is emitted when cluster becomes unhealthy.
 	ClusterUnhealthy = events.Event{
 		Name: ClusterDegradedEvent,
@@ -244,6 +505,16 @@ var (
 		Name: ClusterActivatedEvent,
 		Code: ClusterHealthyCode,
 	}
+	// NodeDegraded is emitted when a cluster node becomes degraded.
+	NodeDegraded = events.Event{
+		Name: NodeDegradedEvent,
+		Code: NodeDegradedCode,
+	}
+	// NodeHealthy is emitted when a degraded cluster node becomes healthy.
+	NodeHealthy = events.Event{
+		Name: NodeHealthyEvent,
+		Code: NodeHealthyCode,
+	}
 	// ApplicationInstall is emitted when a new application image is installed.
 	ApplicationInstall = events.Event{
 		Name: AppInstalledEvent,
@@ -264,6 +535,26 @@ var (
 		Name: AppUninstalledEvent,
 		Code: ApplicationUninstallCode,
 	}
+	// SmokeTestPassed is emitted when an application smoke test passes.
+	SmokeTestPassed = events.Event{
+		Name: SmokeTestPassedEvent,
+		Code: SmokeTestPassedCode,
+	}
+	// SmokeTestFailed is emitted when an application smoke test fails.
+	SmokeTestFailed = events.Event{
+		Name: SmokeTestFailedEvent,
+		Code: SmokeTestFailedCode,
+	}
+	// APIRequest is emitted when an operator API request completes successfully.
+	APIRequest = events.Event{
+		Name: APIRequestEvent,
+		Code: APIRequestCode,
+	}
+	// APIRequestFailure is emitted when an operator API request fails.
+	APIRequestFailure = events.Event{
+		Name: APIRequestEvent,
+		Code: APIRequestFailureCode,
+	}
 )
 
 // There is no strict algorithm for picking an event code, however existing
@@ -281,6 +572,8 @@ var (
 //    license expires, etc.) are in `3xxx` group.
 //
 //  * Application catalog related events are in `4xxx` group.
+//
+//  * Operator API request audit records are in `5xxx` group.
 const (
 	// OpereationInstallStartCode is the install operation start event code.
 	OperationInstallStartCode = "G0001I"
@@ -330,6 +623,60 @@ const (
 	OperationConfigCompleteCode = "G0016I"
 	// OperationConfigFailureCode is the cluster configuration update operation failure event code.
 	OperationConfigFailureCode = "G0016E"
+	// OperationNodeRoleStartCode is the node promotion/demotion operation start event code.
+	OperationNodeRoleStartCode = "G0017I"
+	// OperationNodeRoleCompleteCode is the node promotion/demotion operation complete event code.
+	OperationNodeRoleCompleteCode = "G0018I"
+	// OperationNodeRoleFailureCode is the node promotion/demotion operation failure event code.
+	OperationNodeRoleFailureCode = "G0018E"
+	// OperationReplaceNodeStartCode is the node replacement operation start event code.
+	OperationReplaceNodeStartCode = "G0019I"
+	// OperationReplaceNodeCompleteCode is the node replacement operation complete event code.
+	OperationReplaceNodeCompleteCode = "G0020I"
+	// OperationReplaceNodeFailureCode is the node replacement operation failure event code.
+	OperationReplaceNodeFailureCode = "G0020E"
+	// OperationRotateCertsStartCode is the certificate rotation operation start event code.
+	OperationRotateCertsStartCode = "G0021I"
+	// OperationRotateCertsCompleteCode is the certificate rotation operation complete event code.
+	OperationRotateCertsCompleteCode = "G0022I"
+	// OperationRotateCertsFailureCode is the certificate rotation operation failure event code.
+	OperationRotateCertsFailureCode = "G0022E"
+	// OperationRotateSecretsKeyStartCode is the secrets key rotation operation start event code.
+	OperationRotateSecretsKeyStartCode = "G0023I"
+	// OperationRotateSecretsKeyCompleteCode is the secrets key rotation operation complete event code.
+	OperationRotateSecretsKeyCompleteCode = "G0024I"
+	// OperationRotateSecretsKeyFailureCode is the secrets key rotation operation failure event code.
+	OperationRotateSecretsKeyFailureCode = "G0024E"
+	// OperationRestoreStartCode is the cluster restore operation start event code.
+	OperationRestoreStartCode = "G0025I"
+	// OperationRestoreCompleteCode is the cluster restore operation complete event code.
+	OperationRestoreCompleteCode = "G0026I"
+	// OperationRestoreFailureCode is the cluster restore operation failure event code.
+	OperationRestoreFailureCode = "G0026E"
+	// OperationEtcdRestoreStartCode is the etcd restore operation start event code.
+	OperationEtcdRestoreStartCode = "G0027I"
+	// OperationEtcdRestoreCompleteCode is the etcd restore operation complete event code.
+	OperationEtcdRestoreCompleteCode = "G0028I"
+	// OperationEtcdRestoreFailureCode is the etcd restore operation failure event code.
+	OperationEtcdRestoreFailureCode = "G0028E"
+	// OperationAppBackupStartCode is the application backup operation start event code.
+	OperationAppBackupStartCode = "G0029I"
+	// OperationAppBackupCompleteCode is the application backup operation complete event code.
+	OperationAppBackupCompleteCode = "G0030I"
+	// OperationAppBackupFailureCode is the application backup operation failure event code.
+	OperationAppBackupFailureCode = "G0030E"
+	// OperationAppRestoreStartCode is the application restore operation start event code.
+	OperationAppRestoreStartCode = "G0031I"
+	// OperationAppRestoreCompleteCode is the application restore operation complete event code.
+	OperationAppRestoreCompleteCode = "G0032I"
+	// OperationAppRestoreFailureCode is the application restore operation failure event code.
+	OperationAppRestoreFailureCode = "G0032E"
+	// OperationChangeCIDRStartCode is the subnet change operation start event code.
+	OperationChangeCIDRStartCode = "G0033I"
+	// OperationChangeCIDRCompleteCode is the subnet change operation complete event code.
+	OperationChangeCIDRCompleteCode = "G0034I"
+	// OperationChangeCIDRFailureCode is the subnet change operation failure event code.
+	OperationChangeCIDRFailureCode = "G0034E"
 	// UserCreatedCode is the user created event code.
 	UserCreatedCode = "G1000I"
 	// UserDeletedCode is the user deleted event code.
@@ -368,10 +715,64 @@ const (
 	AuthGatewayUpdatedCode = "G1009I"
 	// UserInviteCreatedCode is the user invite created event code.
 	UserInviteCreatedCode = "G1010I"
+	// ClusterDNSUpdatedCode is the cluster DNS configuration updated event code.
+	ClusterDNSUpdatedCode = "G1011I"
+	// NodePoolCreatedCode is the node pool created event code.
+	NodePoolCreatedCode = "G1012I"
+	// NodePoolDeletedCode is the node pool deleted event code.
+	NodePoolDeletedCode = "G2012I"
+	// ScaleUpRequestedCode is the cluster scale up requested event code.
+	ScaleUpRequestedCode = "G1013I"
+	// ClusterRosterUpdatedCode is the cluster roster updated event code.
+	ClusterRosterUpdatedCode = "G1014I"
+	// ClusterRosterDeletedCode is the cluster roster deleted event code.
+	ClusterRosterDeletedCode = "G2014I"
+	// ClusterRosterChangesPendingCode is the roster changes pending event code.
+	ClusterRosterChangesPendingCode = "G1015I"
+	// ClusterRosterChangesApprovedCode is the roster changes approved event code.
+	ClusterRosterChangesApprovedCode = "G1016I"
+	// ServiceAccountCreatedCode is the service account created event code.
+	ServiceAccountCreatedCode = "G1017I"
+	// ServiceAccountDeletedCode is the service account deleted event code.
+	ServiceAccountDeletedCode = "G2017I"
+	// OperationWebhookCreatedCode is the operation webhook created event code.
+	OperationWebhookCreatedCode = "G1018I"
+	// OperationWebhookDeletedCode is the operation webhook deleted event code.
+	OperationWebhookDeletedCode = "G2018I"
+	// HealthCheckCreatedCode is the health check created event code.
+	HealthCheckCreatedCode = "G1019I"
+	// HealthCheckDeletedCode is the health check deleted event code.
+	HealthCheckDeletedCode = "G2019I"
+	// ImageTrustPolicyUpdatedCode is the image trust policy updated event code.
+	ImageTrustPolicyUpdatedCode = "G1020I"
+	// ImageTrustPolicyDeletedCode is the image trust policy deleted event code.
+	ImageTrustPolicyDeletedCode = "G2020I"
+	// OperationApprovalPolicyUpdatedCode is the operation approval policy updated event code.
+	OperationApprovalPolicyUpdatedCode = "G1021I"
+	// OperationApprovalPolicyDeletedCode is the operation approval policy deleted event code.
+	OperationApprovalPolicyDeletedCode = "G2021I"
+	// OperationApprovedCode is the operation approved event code.
+	OperationApprovedCode = "G1022I"
+	// CatalogSyncScheduleUpdatedCode is the catalog sync schedule updated event code.
+	CatalogSyncScheduleUpdatedCode = "G1023I"
+	// CatalogSyncScheduleDeletedCode is the catalog sync schedule deleted event code.
+	CatalogSyncScheduleDeletedCode = "G2023I"
+	// BackupScheduleUpdatedCode is the backup schedule updated event code.
+	BackupScheduleUpdatedCode = "G1024I"
+	// BackupScheduleDeletedCode is the backup schedule deleted event code.
+	BackupScheduleDeletedCode = "G2024I"
+	// ReplicationUpdatedCode is the replication updated event code.
+	ReplicationUpdatedCode = "G1025I"
+	// ReplicationDeletedCode is the replication deleted event code.
+	ReplicationDeletedCode = "G2025I"
 	// ClusterUnhealthyCode is the cluster goes unhealthy event code.
 	ClusterUnhealthyCode = "G3000W"
 	// ClusterHealthyCode is the cluster goes healthy event code.
 	ClusterHealthyCode = "G3001I"
+	// NodeDegradedCode is the node goes degraded event code.
+	NodeDegradedCode = "G3002W"
+	// NodeHealthyCode is the node goes healthy event code.
+	NodeHealthyCode = "G3003I"
 	// ApplicationInstallCode is the application release install event code.
 	ApplicationInstallCode = "G4000I"
 	// ApplicationUpgradeCode is the application release upgrade event code.
@@ -380,6 +781,14 @@ const (
 	ApplicationRollbackCode = "G4002I"
 	// ApplicationUninstallCode is the application release uninstall event code.
 	ApplicationUninstallCode = "G4003I"
+	// SmokeTestPassedCode is the application smoke test passed event code.
+	SmokeTestPassedCode = "G4004I"
+	// SmokeTestFailedCode is the application smoke test failed event code.
+	SmokeTestFailedCode = "G4004E"
+	// APIRequestCode is the successful operator API request event code.
+	APIRequestCode = "G5000I"
+	// APIRequestFailureCode is the failed operator API request event code.
+	APIRequestFailureCode = "G5000E"
 )
 
 const (
@@ -437,9 +846,71 @@ const (
 	AuthGatewayUpdatedEvent = "authgateway.updated"
 	// InviteCreatedEvent fires when a new user invitation is generated.
 	InviteCreatedEvent = "invite.created"
+	// ClusterDNSUpdatedEvent fires when cluster DNS configuration is updated.
+	ClusterDNSUpdatedEvent = "clusterdns.updated"
+	// NodePoolCreatedEvent fires when a node pool is created or updated.
+	NodePoolCreatedEvent = "nodepool.created"
+	// NodePoolDeletedEvent fires when a node pool is deleted.
+	NodePoolDeletedEvent = "nodepool.deleted"
+	// ScaleUpRequestedEvent fires when cluster scale up is requested.
+	ScaleUpRequestedEvent = "cluster.scaleup.requested"
+	// ClusterRosterUpdatedEvent fires when the cluster roster is created or updated.
+	ClusterRosterUpdatedEvent = "clusterroster.updated"
+	// ClusterRosterDeletedEvent fires when the cluster roster is deleted.
+	ClusterRosterDeletedEvent = "clusterroster.deleted"
+	// ClusterRosterChangesPendingEvent fires when roster changes await approval.
+	ClusterRosterChangesPendingEvent = "clusterroster.changes.pending"
+	// ClusterRosterChangesApprovedEvent fires when pending roster changes are approved.
+	ClusterRosterChangesApprovedEvent = "clusterroster.changes.approved"
+	// ServiceAccountCreatedEvent fires when a service account is created or updated.
+	ServiceAccountCreatedEvent = "serviceaccount.created"
+	// ServiceAccountDeletedEvent fires when a service account is deleted.
+	ServiceAccountDeletedEvent = "serviceaccount.deleted"
+	// OperationWebhookCreatedEvent fires when an operation webhook is created or updated.
+	OperationWebhookCreatedEvent = "operationwebhook.created"
+	// OperationWebhookDeletedEvent fires when an operation webhook is deleted.
+	OperationWebhookDeletedEvent = "operationwebhook.deleted"
+	// HealthCheckCreatedEvent fires when a health check is created or updated.
+	HealthCheckCreatedEvent = "healthcheck.created"
+	// HealthCheckDeletedEvent fires when a health check is deleted.
+	HealthCheckDeletedEvent = "healthcheck.deleted"
+	// ImageTrustPolicyUpdatedEvent fires when the image trust policy is created or updated.
+	ImageTrustPolicyUpdatedEvent = "imagetrustpolicy.updated"
+	// ImageTrustPolicyDeletedEvent fires when the image trust policy is deleted.
+	ImageTrustPolicyDeletedEvent = "imagetrustpolicy.deleted"
+	// OperationApprovalPolicyUpdatedEvent fires when the operation approval policy is created or updated.
+	OperationApprovalPolicyUpdatedEvent = "operationapprovalpolicy.updated"
+	// OperationApprovalPolicyDeletedEvent fires when the operation approval policy is deleted.
+	OperationApprovalPolicyDeletedEvent = "operationapprovalpolicy.deleted"
+	// OperationApprovedEvent fires when an operation that requires approval is approved.
+	OperationApprovedEvent = "operation.approved"
+	// CatalogSyncScheduleUpdatedEvent fires when the catalog sync schedule is created or updated.
+	CatalogSyncScheduleUpdatedEvent = "catalogsync.updated"
+	// CatalogSyncScheduleDeletedEvent fires when the catalog sync schedule is deleted.
+	CatalogSyncScheduleDeletedEvent = "catalogsync.deleted"
+	// BackupScheduleUpdatedEvent fires when the backup schedule is created or updated.
+	BackupScheduleUpdatedEvent = "backupschedule.updated"
+	// BackupScheduleDeletedEvent fires when the backup schedule is deleted.
+	BackupScheduleDeletedEvent = "backupschedule.deleted"
+	// ReplicationUpdatedEvent fires when the replication is created or updated.
+	ReplicationUpdatedEvent = "replication.updated"
+	// ReplicationDeletedEvent fires when the replication is deleted.
+	ReplicationDeletedEvent = "replication.deleted"
 
 	// ClusterDegradedEvent fires when cluster health check fails.
 	ClusterDegradedEvent = "cluster.degraded"
 	// ClusterActivatedEvent fires when cluster becomes healthy again.
 	ClusterActivatedEvent = "cluster.activated"
+	// NodeDegradedEvent fires when node health check fails.
+	NodeDegradedEvent = "node.degraded"
+	// NodeHealthyEvent fires when node becomes healthy again.
+	NodeHealthyEvent = "node.healthy"
+
+	// SmokeTestPassedEvent fires when an application smoke test passes.
+	SmokeTestPassedEvent = "smoketest.passed"
+	// SmokeTestFailedEvent fires when an application smoke test fails.
+	SmokeTestFailedEvent = "smoketest.failed"
+
+	// APIRequestEvent fires when an operator API request completes.
+	APIRequestEvent = "api.request"
 )
@@ -105,6 +105,69 @@ func EventForOperation(operation ops.SiteOperation) (events.Event, error) {
 			return OperationConfigFailure, nil
 		}
 		return OperationConfigStart, nil
+	case ops.OperationUpdateNodeRole:
+		if operation.IsCompleted() {
+			return OperationNodeRoleComplete, nil
+		} else if operation.IsFailed() {
+			return OperationNodeRoleFailure, nil
+		}
+		return OperationNodeRoleStart, nil
+	case ops.OperationReplaceNode:
+		if operation.IsCompleted() {
+			return OperationReplaceNodeComplete, nil
+		} else if operation.IsFailed() {
+			return OperationReplaceNodeFailure, nil
+		}
+		return OperationReplaceNodeStart, nil
+	case ops.OperationRotateCertificates:
+		if operation.IsCompleted() {
+			return OperationRotateCertsComplete, nil
+		} else if operation.IsFailed() {
+			return OperationRotateCertsFailure, nil
+		}
+		return OperationRotateCertsStart, nil
+	case ops.OperationRotateSecretsKey:
+		if operation.IsCompleted() {
+			return OperationRotateSecretsKeyComplete, nil
+		} else if operation.IsFailed() {
+			return OperationRotateSecretsKeyFailure, nil
+		}
+		return OperationRotateSecretsKeyStart, nil
+	case ops.OperationChangeCIDR:
+		if operation.IsCompleted() {
+			return OperationChangeCIDRComplete, nil
+		} else if operation.IsFailed() {
+			return OperationChangeCIDRFailure, nil
+		}
+		return OperationChangeCIDRStart, nil
+	case ops.OperationRestore:
+		if operation.IsCompleted() {
+			return OperationRestoreComplete, nil
+		} else if operation.IsFailed() {
+			return OperationRestoreFailure, nil
+		}
+		return OperationRestoreStart, nil
+	case ops.OperationEtcdRestore:
+		if operation.IsCompleted() {
+			return OperationEtcdRestoreComplete, nil
+		} else if operation.IsFailed() {
+			return OperationEtcdRestoreFailure, nil
+		}
+		return OperationEtcdRestoreStart, nil
+	case ops.OperationAppBackup:
+		if operation.IsCompleted() {
+			return OperationAppBackupComplete, nil
+		} else if operation.IsFailed() {
+			return OperationAppBackupFailure, nil
+		}
+		return OperationAppBackupStart, nil
+	case ops.OperationAppRestore:
+		if operation.IsCompleted() {
+			return OperationAppRestoreComplete, nil
+		} else if operation.IsFailed() {
+			return OperationAppRestoreFailure, nil
+		}
+		return OperationAppRestoreStart, nil
 	}
 	return events.Event{}, trace.NotFound(
 		"operation does not have corresponding event: %v", operation)
@@ -143,6 +206,18 @@ func fieldsForOperation(operation ops.SiteOperation) (Fields, error) {
 				fields[FieldNodeRole] = servers[0].Role
 			}
 		}
+	case ops.OperationUpdateNodeRole:
+		if operation.UpdateNodeRole != nil {
+			fields[FieldNodeIP] = operation.UpdateNodeRole.Server.AdvertiseIP
+			fields[FieldNodeHostname] = operation.UpdateNodeRole.Server.Hostname
+			fields[FieldNodeRole] = operation.UpdateNodeRole.ClusterRole
+		}
+	case ops.OperationReplaceNode:
+		if operation.ReplaceNode != nil {
+			fields[FieldNodeIP] = operation.ReplaceNode.Server.AdvertiseIP
+			fields[FieldNodeHostname] = operation.ReplaceNode.Server.Hostname
+			fields[FieldNodeRole] = operation.ReplaceNode.Server.Role
+		}
 	case ops.OperationUpdate:
 		if operation.Update != nil {
 			locator, err := loc.ParseLocator(operation.Update.UpdatePackage)
@@ -196,4 +271,20 @@ const (
 	FieldTime = "time"
 	// FieldRoles contains roles of a new user.
 	FieldRoles = "roles"
+	// FieldCount contains the number of items, e.g. requested nodes.
+	FieldCount = "count"
+	// FieldVerb contains the HTTP method of an API request.
+	FieldVerb = "verb"
+	// FieldResource contains the API endpoint of an API request.
+	FieldResource = "resource"
+	// FieldPath contains the URL path of an API request.
+	FieldPath = "path"
+	// FieldStatus contains the HTTP status code of an API request.
+	FieldStatus = "status"
+	// FieldError contains the error an API request failed with.
+	FieldError = "error"
+	// FieldLatency contains the API request latency in milliseconds.
+	FieldLatency = "latency"
+	// FieldMode contains the image signature verification mode.
+	FieldMode = "mode"
 )
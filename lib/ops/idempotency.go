@@ -0,0 +1,47 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ops
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/constants"
+)
+
+// IdempotencyKeyHeader is the HTTP header with the idempotency key
+// of the operation creating request
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// WithIdempotencyKey returns a copy of the context with the specified
+// idempotency key.
+//
+// Operations are created at most once for the same idempotency key:
+// retrying the request with the key returns the operation created
+// by the original request
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, constants.IdempotencyKeyContext, key)
+}
+
+// IdempotencyKeyFromContext returns the idempotency key from the context
+// or an empty string if the context has no key
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	key, ok := ctx.Value(constants.IdempotencyKeyContext).(string)
+	if !ok {
+		return ""
+	}
+	return key
+}
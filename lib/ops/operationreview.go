@@ -0,0 +1,33 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ops
+
+// OperationReview is the request posted to an operation webhook before
+// the operation is created
+type OperationReview struct {
+	// Operation is the operation to be created.
+	// Operation.CreatedBy is the user who requested it
+	Operation SiteOperation `json:"operation"`
+}
+
+// OperationReviewResponse is the response of an operation webhook
+type OperationReviewResponse struct {
+	// Allowed is whether the operation may be created
+	Allowed bool `json:"allowed"`
+	// Reason explains why the operation has been rejected
+	Reason string `json:"reason,omitempty"`
+}
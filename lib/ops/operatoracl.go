@@ -22,6 +22,7 @@ import (
 	"encoding/pem"
 	"io"
 	"net/url"
+	"time"
 
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
@@ -117,6 +118,23 @@ func (o *OperatorACL) ClusterAction(clusterName, resourceKind, action string) er
 	return o.checker.CheckAccessToRule(ctx, cluster.GetMetadata().Namespace, resourceKind, action, false)
 }
 
+// clusterResourceAction checks access to the specified cluster action and,
+// if denied, to the specified action on a more specific resource kind.
+// This lets roles grant access to a subset of the cluster API (e.g. only
+// environment updates or read-only access to operation plans) while roles
+// with access to the cluster itself keep access to everything
+func (o *OperatorACL) clusterResourceAction(clusterName, clusterAction, resourceKind, action string) error {
+	if err := o.ClusterAction(clusterName, storage.KindCluster, clusterAction); err != nil {
+		if !trace.IsAccessDenied(err) {
+			return trace.Wrap(err)
+		}
+		if err := o.ClusterAction(clusterName, resourceKind, action); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
 func (o *OperatorACL) repoContext(repoName string) *users.Context {
 	return o.resourceContext(storage.NewRepository(repoName))
 }
@@ -141,6 +159,57 @@ func (o *OperatorACL) userActions(actions ...string) error {
 	return nil
 }
 
+// apiKeyActions checks access to the specified actions on the API keys
+// of the specified user. API keys of service accounts can also be managed
+// with access to the "service account" resource as long as the caller
+// could assign the roles of the service account itself
+func (o *OperatorACL) apiKeyActions(username string, actions ...string) error {
+	err := o.currentUserActions(username, actions...)
+	if err == nil || !trace.IsAccessDenied(err) {
+		return trace.Wrap(err)
+	}
+	user, getErr := o.users.GetUser(username)
+	if getErr != nil {
+		return trace.Wrap(err)
+	}
+	if user, ok := user.(storage.User); !ok || user.GetType() != storage.ServiceAccountUser {
+		return trace.Wrap(err)
+	}
+	if err := o.serviceAccountActions(actions...); err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(o.assignableRoles(user.GetRoles()))
+}
+
+// assignableRoles checks that the caller can assign the specified roles.
+// Callers with access to roles can assign any role, others can only assign
+// the roles they hold themselves so they can not escalate their privileges
+func (o *OperatorACL) assignableRoles(roles []string) error {
+	err := o.Action(teleservices.KindRole, teleservices.VerbUpdate)
+	if err == nil || !trace.IsAccessDenied(err) {
+		return trace.Wrap(err)
+	}
+	for _, role := range roles {
+		if !utils.StringInSlice(o.user.GetRoles(), role) {
+			return trace.AccessDenied("user %v can not assign role %q", o.username, role)
+		}
+	}
+	return nil
+}
+
+// serviceAccountActions checks access to the specified actions on the
+// "service account" resource. Access to users implies access to service accounts
+func (o *OperatorACL) serviceAccountActions(actions ...string) error {
+	for _, action := range actions {
+		if err := o.Action(teleservices.KindUser, action); err != nil {
+			if err := o.Action(storage.KindServiceAccount, action); err != nil {
+				return trace.Wrap(err)
+			}
+		}
+	}
+	return nil
+}
+
 // authPreferenceActions checks access to the specified actions on the "cluster
 // auth preference" resource
 func (o *OperatorACL) authPreferenceActions(actions ...string) error {
@@ -246,21 +315,21 @@ func (o *OperatorACL) ResetUserPassword(req ResetUserPasswordRequest) (string, e
 }
 
 func (o *OperatorACL) CreateAPIKey(ctx context.Context, req NewAPIKeyRequest) (*storage.APIKey, error) {
-	if err := o.currentUserActions(req.UserEmail, teleservices.VerbCreate); err != nil {
+	if err := o.apiKeyActions(req.UserEmail, teleservices.VerbCreate); err != nil {
 		return nil, trace.Wrap(err)
 	}
 	return o.operator.CreateAPIKey(ctx, req)
 }
 
 func (o *OperatorACL) GetAPIKeys(userEmail string) ([]storage.APIKey, error) {
-	if err := o.currentUserActions(userEmail, teleservices.VerbRead); err != nil {
+	if err := o.apiKeyActions(userEmail, teleservices.VerbRead); err != nil {
 		return nil, trace.Wrap(err)
 	}
 	return o.operator.GetAPIKeys(userEmail)
 }
 
 func (o *OperatorACL) DeleteAPIKey(ctx context.Context, userEmail, token string) error {
-	if err := o.currentUserActions(userEmail, teleservices.VerbUpdate); err != nil {
+	if err := o.apiKeyActions(userEmail, teleservices.VerbUpdate); err != nil {
 		return trace.Wrap(err)
 	}
 	return o.operator.DeleteAPIKey(ctx, userEmail, token)
@@ -284,15 +353,48 @@ func (o *OperatorACL) CreateProvisioningToken(token storage.ProvisioningToken) e
 	return o.operator.CreateProvisioningToken(token)
 }
 
+// GetExpandToken returns the long-lived cluster join token.
+// Join token access is not granted to the cluster agents so the nodes
+// joined with a join token can not read it
 func (o *OperatorACL) GetExpandToken(key SiteKey) (*storage.ProvisioningToken, error) {
-	// TODO(klizhentas) introduce more fine grained RBAC, right now
-	// we use this Update requirement to limit access to admin only users
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindJoinToken, teleservices.VerbRead); err != nil {
 		return nil, trace.Wrap(err)
 	}
 	return o.operator.GetExpandToken(key)
 }
 
+// CreateJoinToken creates a new short-lived cluster join token
+func (o *OperatorACL) CreateJoinToken(ctx context.Context, req CreateJoinTokenRequest) (*storage.ProvisioningToken, error) {
+	if err := o.ClusterAction(req.SiteDomain, storage.KindJoinToken, teleservices.VerbCreate); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.CreateJoinToken(ctx, req)
+}
+
+// GetJoinTokens returns the active short-lived join tokens of the cluster
+func (o *OperatorACL) GetJoinTokens(ctx context.Context, key SiteKey) ([]storage.ProvisioningToken, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindJoinToken, teleservices.VerbList); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetJoinTokens(ctx, key)
+}
+
+// DeleteJoinToken deletes the specified short-lived join token
+func (o *OperatorACL) DeleteJoinToken(ctx context.Context, req DeleteJoinTokenRequest) error {
+	if err := o.ClusterAction(req.SiteDomain, storage.KindJoinToken, teleservices.VerbDelete); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.DeleteJoinToken(ctx, req)
+}
+
+// RequestScaleUp requests expansion of the cluster by the specified number of nodes
+func (o *OperatorACL) RequestScaleUp(ctx context.Context, req ScaleUpRequest) (*storage.ProvisioningToken, error) {
+	if err := o.ClusterAction(req.SiteDomain, storage.KindJoinToken, teleservices.VerbCreate); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.RequestScaleUp(ctx, req)
+}
+
 func (o *OperatorACL) GetTrustedClusterToken(key SiteKey) (storage.Token, error) {
 	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
 		return nil, trace.Wrap(err)
@@ -427,14 +529,14 @@ func (o *OperatorACL) GetSiteInstructions(tokenID string, serverProfile string,
 }
 
 func (o *OperatorACL) GetSiteOperations(key SiteKey) (SiteOperations, error) {
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+	if err := o.clusterResourceAction(key.SiteDomain, teleservices.VerbRead, storage.KindOperation, teleservices.VerbList); err != nil {
 		return nil, trace.Wrap(err)
 	}
 	return o.operator.GetSiteOperations(key)
 }
 
 func (o *OperatorACL) GetSiteOperation(key SiteOperationKey) (*SiteOperation, error) {
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+	if err := o.clusterResourceAction(key.SiteDomain, teleservices.VerbRead, storage.KindOperation, teleservices.VerbRead); err != nil {
 		return nil, trace.Wrap(err)
 	}
 	return o.operator.GetSiteOperation(key)
@@ -469,14 +571,14 @@ func (o *OperatorACL) CreateSiteShrinkOperation(ctx context.Context, req CreateS
 }
 
 func (o *OperatorACL) CreateSiteAppUpdateOperation(ctx context.Context, req CreateSiteAppUpdateOperationRequest) (*SiteOperationKey, error) {
-	if err := o.ClusterAction(req.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+	if err := o.clusterResourceAction(req.SiteDomain, teleservices.VerbUpdate, storage.KindUpgrade, teleservices.VerbCreate); err != nil {
 		return nil, trace.Wrap(err)
 	}
 	return o.operator.CreateSiteAppUpdateOperation(ctx, req)
 }
 
 func (o *OperatorACL) GetSiteInstallOperationAgentReport(key SiteOperationKey) (*AgentReport, error) {
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+	if err := o.clusterResourceAction(key.SiteDomain, teleservices.VerbRead, storage.KindOperation, teleservices.VerbRead); err != nil {
 		return nil, trace.Wrap(err)
 	}
 	return o.operator.GetSiteInstallOperationAgentReport(key)
@@ -506,29 +608,45 @@ func (o *OperatorACL) CreateClusterGarbageCollectOperation(ctx context.Context,
 
 // CreateUpdateEnvarsOperation creates a new operation to update cluster environment variables
 func (o *OperatorACL) CreateUpdateEnvarsOperation(ctx context.Context, req CreateUpdateEnvarsOperationRequest) (*SiteOperationKey, error) {
-	if err := o.ClusterAction(req.ClusterKey.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+	if err := o.clusterResourceAction(req.ClusterKey.SiteDomain, teleservices.VerbUpdate, storage.KindRuntimeEnvironment, teleservices.VerbUpdate); err != nil {
 		return nil, trace.Wrap(err)
 	}
 	return o.operator.CreateUpdateEnvarsOperation(ctx, req)
 }
 
+// CreateUpdateNodeRoleOperation creates a new operation to promote or demote a cluster node
+func (o *OperatorACL) CreateUpdateNodeRoleOperation(ctx context.Context, req CreateUpdateNodeRoleOperationRequest) (*SiteOperationKey, error) {
+	if err := o.ClusterAction(req.ClusterKey.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.CreateUpdateNodeRoleOperation(ctx, req)
+}
+
+// CreateReplaceNodeOperation creates a new operation to replace a cluster node with a new one
+func (o *OperatorACL) CreateReplaceNodeOperation(ctx context.Context, req CreateReplaceNodeOperationRequest) (*SiteOperationKey, error) {
+	if err := o.ClusterAction(req.ClusterKey.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.CreateReplaceNodeOperation(ctx, req)
+}
+
 // CreateUpdateConfigOperation creates a new operation to update cluster configuration
 func (o *OperatorACL) CreateUpdateConfigOperation(ctx context.Context, req CreateUpdateConfigOperationRequest) (*SiteOperationKey, error) {
-	if err := o.ClusterAction(req.ClusterKey.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+	if err := o.clusterResourceAction(req.ClusterKey.SiteDomain, teleservices.VerbUpdate, storage.KindClusterConfiguration, teleservices.VerbUpdate); err != nil {
 		return nil, trace.Wrap(err)
 	}
 	return o.operator.CreateUpdateConfigOperation(ctx, req)
 }
 
 func (o *OperatorACL) GetSiteOperationLogs(key SiteOperationKey) (io.ReadCloser, error) {
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+	if err := o.clusterResourceAction(key.SiteDomain, teleservices.VerbRead, storage.KindOperation, teleservices.VerbRead); err != nil {
 		return nil, trace.Wrap(err)
 	}
 	return o.operator.GetSiteOperationLogs(key)
 }
 
 func (o *OperatorACL) CreateLogEntry(key SiteOperationKey, entry LogEntry) error {
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+	if err := o.clusterResourceAction(key.SiteDomain, teleservices.VerbUpdate, storage.KindOperation, teleservices.VerbUpdate); err != nil {
 		return trace.Wrap(err)
 	}
 	return o.operator.CreateLogEntry(key, entry)
@@ -537,14 +655,14 @@ func (o *OperatorACL) CreateLogEntry(key SiteOperationKey, entry LogEntry) error
 // StreamOperationLogs appends the logs from the provided reader to the
 // specified operation (user-facing) log file
 func (o *OperatorACL) StreamOperationLogs(key SiteOperationKey, reader io.Reader) error {
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+	if err := o.clusterResourceAction(key.SiteDomain, teleservices.VerbUpdate, storage.KindOperation, teleservices.VerbUpdate); err != nil {
 		return trace.Wrap(err)
 	}
 	return o.operator.StreamOperationLogs(key, reader)
 }
 
 func (o *OperatorACL) GetSiteExpandOperationAgentReport(key SiteOperationKey) (*AgentReport, error) {
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+	if err := o.clusterResourceAction(key.SiteDomain, teleservices.VerbRead, storage.KindOperation, teleservices.VerbRead); err != nil {
 		return nil, trace.Wrap(err)
 	}
 	return o.operator.GetSiteExpandOperationAgentReport(key)
@@ -558,14 +676,14 @@ func (o *OperatorACL) SiteExpandOperationStart(key SiteOperationKey) error {
 }
 
 func (o *OperatorACL) GetSiteOperationProgress(key SiteOperationKey) (*ProgressEntry, error) {
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+	if err := o.clusterResourceAction(key.SiteDomain, teleservices.VerbRead, storage.KindOperation, teleservices.VerbRead); err != nil {
 		return nil, trace.Wrap(err)
 	}
 	return o.operator.GetSiteOperationProgress(key)
 }
 
 func (o *OperatorACL) CreateProgressEntry(key SiteOperationKey, entry ProgressEntry) error {
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+	if err := o.clusterResourceAction(key.SiteDomain, teleservices.VerbUpdate, storage.KindOperation, teleservices.VerbUpdate); err != nil {
 		return trace.Wrap(err)
 	}
 	return o.operator.CreateProgressEntry(key, entry)
@@ -612,14 +730,14 @@ func (o *OperatorACL) UpdateExpandOperationState(key SiteOperationKey, req Opera
 }
 
 func (o *OperatorACL) DeleteSiteOperation(key SiteOperationKey) error {
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+	if err := o.clusterResourceAction(key.SiteDomain, teleservices.VerbUpdate, storage.KindOperation, teleservices.VerbDelete); err != nil {
 		return trace.Wrap(err)
 	}
 	return o.operator.DeleteSiteOperation(key)
 }
 
 func (o *OperatorACL) SetOperationState(key SiteOperationKey, req SetOperationStateRequest) error {
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+	if err := o.clusterResourceAction(key.SiteDomain, teleservices.VerbUpdate, storage.KindOperation, teleservices.VerbUpdate); err != nil {
 		return trace.Wrap(err)
 	}
 	return o.operator.SetOperationState(key, req)
@@ -627,7 +745,7 @@ func (o *OperatorACL) SetOperationState(key SiteOperationKey, req SetOperationSt
 
 // CreateOperationPlan saves the provided operation plan
 func (o *OperatorACL) CreateOperationPlan(key SiteOperationKey, plan storage.OperationPlan) error {
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+	if err := o.clusterResourceAction(key.SiteDomain, teleservices.VerbUpdate, storage.KindOperationPlan, teleservices.VerbCreate); err != nil {
 		return trace.Wrap(err)
 	}
 	return o.operator.CreateOperationPlan(key, plan)
@@ -635,7 +753,7 @@ func (o *OperatorACL) CreateOperationPlan(key SiteOperationKey, plan storage.Ope
 
 // CreateOperationPlanChange creates a new changelog entry for a plan
 func (o *OperatorACL) CreateOperationPlanChange(key SiteOperationKey, change storage.PlanChange) error {
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+	if err := o.clusterResourceAction(key.SiteDomain, teleservices.VerbUpdate, storage.KindOperationPlan, teleservices.VerbUpdate); err != nil {
 		return trace.Wrap(err)
 	}
 	return o.operator.CreateOperationPlanChange(key, change)
@@ -643,7 +761,7 @@ func (o *OperatorACL) CreateOperationPlanChange(key SiteOperationKey, change sto
 
 // GetOperationPlan returns plan for the specified operation
 func (o *OperatorACL) GetOperationPlan(key SiteOperationKey) (*storage.OperationPlan, error) {
-	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+	if err := o.clusterResourceAction(key.SiteDomain, teleservices.VerbRead, storage.KindOperationPlan, teleservices.VerbRead); err != nil {
 		return nil, trace.Wrap(err)
 	}
 	return o.operator.GetOperationPlan(key)
@@ -746,6 +864,311 @@ func (o *OperatorACL) DeleteSMTPConfig(ctx context.Context, key SiteKey) error {
 	return o.operator.DeleteSMTPConfig(ctx, key)
 }
 
+func (o *OperatorACL) GetClusterDNS(key SiteKey) (storage.ClusterDNS, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindClusterDNS, teleservices.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetClusterDNS(key)
+}
+
+func (o *OperatorACL) UpdateClusterDNS(ctx context.Context, key SiteKey, config storage.ClusterDNS) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindClusterDNS, teleservices.VerbUpdate); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.UpdateClusterDNS(ctx, key, config)
+}
+
+func (o *OperatorACL) GetNodePools(key SiteKey) ([]storage.NodePool, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindNodePool, teleservices.VerbList); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetNodePools(key)
+}
+
+func (o *OperatorACL) UpsertNodePool(ctx context.Context, key SiteKey, pool storage.NodePool) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindNodePool, teleservices.VerbUpdate); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.UpsertNodePool(ctx, key, pool)
+}
+
+func (o *OperatorACL) DeleteNodePool(ctx context.Context, key SiteKey, name string) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindNodePool, teleservices.VerbDelete); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.DeleteNodePool(ctx, key, name)
+}
+
+func (o *OperatorACL) GetOperationWebhooks(key SiteKey) ([]storage.OperationWebhook, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindOperationWebhook, teleservices.VerbList); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetOperationWebhooks(key)
+}
+
+func (o *OperatorACL) UpsertOperationWebhook(ctx context.Context, key SiteKey, webhook storage.OperationWebhook) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindOperationWebhook, teleservices.VerbUpdate); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.UpsertOperationWebhook(ctx, key, webhook)
+}
+
+func (o *OperatorACL) DeleteOperationWebhook(ctx context.Context, key SiteKey, name string) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindOperationWebhook, teleservices.VerbDelete); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.DeleteOperationWebhook(ctx, key, name)
+}
+
+func (o *OperatorACL) GetHealthChecks(key SiteKey) ([]storage.HealthCheck, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindHealthCheck, teleservices.VerbList); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetHealthChecks(key)
+}
+
+func (o *OperatorACL) UpsertHealthCheck(ctx context.Context, key SiteKey, check storage.HealthCheck) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindHealthCheck, teleservices.VerbUpdate); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.UpsertHealthCheck(ctx, key, check)
+}
+
+func (o *OperatorACL) DeleteHealthCheck(ctx context.Context, key SiteKey, name string) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindHealthCheck, teleservices.VerbDelete); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.DeleteHealthCheck(ctx, key, name)
+}
+
+func (o *OperatorACL) GetHealthCheckStatus(key SiteKey) (*storage.HealthCheckStatus, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetHealthCheckStatus(key)
+}
+
+func (o *OperatorACL) GetHealthHistory(key SiteKey, since time.Time) ([]storage.HealthSnapshot, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetHealthHistory(key, since)
+}
+
+func (o *OperatorACL) GetNetworkHealth(key SiteKey) ([]storage.NetworkHealthReport, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetNetworkHealth(key)
+}
+
+func (o *OperatorACL) GetClusterRoster(key SiteKey) (storage.ClusterRoster, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindClusterRoster, teleservices.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetClusterRoster(key)
+}
+
+func (o *OperatorACL) UpsertClusterRoster(ctx context.Context, key SiteKey, roster storage.ClusterRoster) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindClusterRoster, teleservices.VerbUpdate); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.UpsertClusterRoster(ctx, key, roster)
+}
+
+func (o *OperatorACL) DeleteClusterRoster(ctx context.Context, key SiteKey) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindClusterRoster, teleservices.VerbDelete); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.DeleteClusterRoster(ctx, key)
+}
+
+func (o *OperatorACL) GetClusterRosterStatus(key SiteKey) (*storage.ClusterRosterStatus, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindClusterRoster, teleservices.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetClusterRosterStatus(key)
+}
+
+func (o *OperatorACL) ApproveClusterRosterChanges(ctx context.Context, key SiteKey, changesID string) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindClusterRoster, teleservices.VerbUpdate); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.ApproveClusterRosterChanges(ctx, key, changesID)
+}
+
+func (o *OperatorACL) GetImageTrustPolicy(key SiteKey) (storage.ImageTrustPolicy, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindImageTrustPolicy, teleservices.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetImageTrustPolicy(key)
+}
+
+func (o *OperatorACL) UpsertImageTrustPolicy(ctx context.Context, key SiteKey, policy storage.ImageTrustPolicy) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindImageTrustPolicy, teleservices.VerbUpdate); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.UpsertImageTrustPolicy(ctx, key, policy)
+}
+
+func (o *OperatorACL) DeleteImageTrustPolicy(ctx context.Context, key SiteKey) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindImageTrustPolicy, teleservices.VerbDelete); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.DeleteImageTrustPolicy(ctx, key)
+}
+
+func (o *OperatorACL) GetOperationApprovalPolicy(key SiteKey) (storage.OperationApprovalPolicy, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindOperationApprovalPolicy, teleservices.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetOperationApprovalPolicy(key)
+}
+
+func (o *OperatorACL) UpsertOperationApprovalPolicy(ctx context.Context, key SiteKey, policy storage.OperationApprovalPolicy) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindOperationApprovalPolicy, teleservices.VerbUpdate); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.UpsertOperationApprovalPolicy(ctx, key, policy)
+}
+
+func (o *OperatorACL) DeleteOperationApprovalPolicy(ctx context.Context, key SiteKey) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindOperationApprovalPolicy, teleservices.VerbDelete); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.DeleteOperationApprovalPolicy(ctx, key)
+}
+
+func (o *OperatorACL) ApproveOperation(ctx context.Context, key SiteOperationKey) error {
+	if err := o.clusterResourceAction(key.SiteDomain, teleservices.VerbUpdate, storage.KindOperation, teleservices.VerbUpdate); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.ApproveOperation(ctx, key)
+}
+
+func (o *OperatorACL) GetCatalogSyncSchedule(key SiteKey) (storage.CatalogSyncSchedule, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCatalogSyncSchedule, teleservices.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetCatalogSyncSchedule(key)
+}
+
+func (o *OperatorACL) UpsertCatalogSyncSchedule(ctx context.Context, key SiteKey, schedule storage.CatalogSyncSchedule) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCatalogSyncSchedule, teleservices.VerbUpdate); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.UpsertCatalogSyncSchedule(ctx, key, schedule)
+}
+
+func (o *OperatorACL) DeleteCatalogSyncSchedule(ctx context.Context, key SiteKey) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCatalogSyncSchedule, teleservices.VerbDelete); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.DeleteCatalogSyncSchedule(ctx, key)
+}
+
+func (o *OperatorACL) GetBackupSchedule(key SiteKey) (storage.BackupSchedule, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindBackupSchedule, teleservices.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetBackupSchedule(key)
+}
+
+func (o *OperatorACL) UpsertBackupSchedule(ctx context.Context, key SiteKey, schedule storage.BackupSchedule) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindBackupSchedule, teleservices.VerbUpdate); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.UpsertBackupSchedule(ctx, key, schedule)
+}
+
+func (o *OperatorACL) DeleteBackupSchedule(ctx context.Context, key SiteKey) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindBackupSchedule, teleservices.VerbDelete); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.DeleteBackupSchedule(ctx, key)
+}
+
+func (o *OperatorACL) GetBackupStatus(key SiteKey) (*storage.BackupStatus, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetBackupStatus(key)
+}
+
+func (o *OperatorACL) GetReplication(key SiteKey) (storage.Replication, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindReplication, teleservices.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetReplication(key)
+}
+
+func (o *OperatorACL) UpsertReplication(ctx context.Context, key SiteKey, replication storage.Replication) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindReplication, teleservices.VerbUpdate); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.UpsertReplication(ctx, key, replication)
+}
+
+func (o *OperatorACL) DeleteReplication(ctx context.Context, key SiteKey) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindReplication, teleservices.VerbDelete); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.DeleteReplication(ctx, key)
+}
+
+func (o *OperatorACL) GetReplicationStatus(key SiteKey) (*storage.ReplicationStatus, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetReplicationStatus(key)
+}
+
+// CreateRestoreOperation creates a new operation to restore the cluster from a backup
+func (o *OperatorACL) CreateRestoreOperation(ctx context.Context, req CreateRestoreOperationRequest) (*SiteOperationKey, error) {
+	if err := o.ClusterAction(req.ClusterKey.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.CreateRestoreOperation(ctx, req)
+}
+
+// CreateEtcdRestoreOperation creates a new operation to restore etcd from a snapshot
+func (o *OperatorACL) CreateEtcdRestoreOperation(ctx context.Context, req CreateEtcdRestoreOperationRequest) (*SiteOperationKey, error) {
+	if err := o.ClusterAction(req.ClusterKey.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.CreateEtcdRestoreOperation(ctx, req)
+}
+
+// CreateAppBackupOperation creates a new operation to back up an application
+func (o *OperatorACL) CreateAppBackupOperation(ctx context.Context, req CreateAppBackupOperationRequest) (*SiteOperationKey, error) {
+	if err := o.ClusterAction(req.ClusterKey.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.CreateAppBackupOperation(ctx, req)
+}
+
+// CreateAppRestoreOperation creates a new operation to restore an application from its backup
+func (o *OperatorACL) CreateAppRestoreOperation(ctx context.Context, req CreateAppBackupOperationRequest) (*SiteOperationKey, error) {
+	if err := o.ClusterAction(req.ClusterKey.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.CreateAppRestoreOperation(ctx, req)
+}
+
+func (o *OperatorACL) GetEtcdMaintenanceStatus(key SiteKey) (*storage.EtcdMaintenanceStatus, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetEtcdMaintenanceStatus(key)
+}
+
+func (o *OperatorACL) GetEtcdHealthStatus(key SiteKey) (*storage.EtcdHealthStatus, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetEtcdHealthStatus(key)
+}
+
 func (o *OperatorACL) GetAlerts(key SiteKey) ([]storage.Alert, error) {
 	if err := o.ClusterAction(key.SiteDomain, storage.KindAlert, teleservices.VerbList); err != nil {
 		return nil, trace.Wrap(err)
@@ -911,6 +1334,37 @@ func (o *OperatorACL) GetClusterCertificate(key SiteKey, withSecrets bool) (*Clu
 	return o.operator.GetClusterCertificate(key, withSecrets)
 }
 
+func (o *OperatorACL) GetCertificateExpiry(key SiteKey) (*storage.CertificateExpiryStatus, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetCertificateExpiry(key)
+}
+
+// CreateRotateSecretsKeyOperation creates a new operation to rotate the key that encrypts secrets at rest
+func (o *OperatorACL) CreateRotateSecretsKeyOperation(ctx context.Context, req CreateRotateSecretsKeyOperationRequest) (*SiteOperationKey, error) {
+	if err := o.ClusterAction(req.ClusterKey.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.CreateRotateSecretsKeyOperation(ctx, req)
+}
+
+// CreateChangeCIDROperation creates a new operation to change the pod and service subnets of the cluster
+func (o *OperatorACL) CreateChangeCIDROperation(ctx context.Context, req CreateChangeCIDROperationRequest) (*SiteOperationKey, error) {
+	if err := o.ClusterAction(req.ClusterKey.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.CreateChangeCIDROperation(ctx, req)
+}
+
+// CreateRotateCertificatesOperation creates a new operation to rotate the cluster certificates
+func (o *OperatorACL) CreateRotateCertificatesOperation(ctx context.Context, req CreateRotateCertificatesOperationRequest) (*SiteOperationKey, error) {
+	if err := o.ClusterAction(req.ClusterKey.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.CreateRotateCertificatesOperation(ctx, req)
+}
+
 func (o *OperatorACL) UpdateClusterCertificate(ctx context.Context, req UpdateCertificateRequest) (*ClusterCertificate, error) {
 	if err := o.ClusterAction(req.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
 		return nil, trace.Wrap(err)
@@ -969,6 +1423,33 @@ func (o *OperatorACL) DeleteUser(ctx context.Context, key SiteKey, name string)
 	return o.operator.DeleteUser(ctx, key, name)
 }
 
+// UpsertServiceAccount creates or updates a service account
+func (o *OperatorACL) UpsertServiceAccount(ctx context.Context, key SiteKey, account storage.ServiceAccount) error {
+	if err := o.serviceAccountActions(teleservices.VerbCreate, teleservices.VerbUpdate); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := o.assignableRoles(account.GetRoles()); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.UpsertServiceAccount(ctx, key, account)
+}
+
+// GetServiceAccounts returns all service accounts
+func (o *OperatorACL) GetServiceAccounts(key SiteKey) ([]storage.ServiceAccount, error) {
+	if err := o.serviceAccountActions(teleservices.VerbList, teleservices.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetServiceAccounts(key)
+}
+
+// DeleteServiceAccount deletes the service account with the specified name
+func (o *OperatorACL) DeleteServiceAccount(ctx context.Context, key SiteKey, name string) error {
+	if err := o.serviceAccountActions(teleservices.VerbDelete); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.DeleteServiceAccount(ctx, key, name)
+}
+
 // UpsertClusterAuthPreference updates cluster authentication preference
 func (o *OperatorACL) UpsertClusterAuthPreference(ctx context.Context, key SiteKey, auth teleservices.AuthPreference) error {
 	if err := o.authPreferenceActions(teleservices.VerbCreate, teleservices.VerbUpdate); err != nil {
@@ -1062,6 +1543,30 @@ func (o *OperatorACL) ListReleases(req ListReleasesRequest) ([]storage.Release,
 	return o.operator.ListReleases(req)
 }
 
+// GetTrackedReleases returns the application releases recorded for the cluster
+func (o *OperatorACL) GetTrackedReleases(key SiteKey) ([]storage.Release, error) {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetTrackedReleases(key)
+}
+
+// TrackRelease records the state of the application release
+func (o *OperatorACL) TrackRelease(ctx context.Context, key SiteKey, release storage.Release) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.TrackRelease(ctx, key, release)
+}
+
+// UntrackRelease removes the record of the release with the specified name
+func (o *OperatorACL) UntrackRelease(ctx context.Context, key SiteKey, name string) error {
+	if err := o.ClusterAction(key.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
+		return trace.Wrap(err)
+	}
+	return o.operator.UntrackRelease(ctx, key, name)
+}
+
 // EmitAuditEvent saves the provided event in the audit log.
 func (o *OperatorACL) EmitAuditEvent(ctx context.Context, req AuditEventRequest) error {
 	if err := o.ClusterAction(req.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
@@ -1070,6 +1575,15 @@ func (o *OperatorACL) EmitAuditEvent(ctx context.Context, req AuditEventRequest)
 	return o.operator.EmitAuditEvent(ctx, req)
 }
 
+// GetClusterEvents returns the cluster events recorded after the
+// event specified in the request.
+func (o *OperatorACL) GetClusterEvents(ctx context.Context, req GetClusterEventsRequest) ([]storage.ClusterEvent, error) {
+	if err := o.ClusterAction(req.SiteDomain, storage.KindCluster, teleservices.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.operator.GetClusterEvents(ctx, req)
+}
+
 // CreateUserInvite creates a new invite token for a user.
 func (o *OperatorACL) CreateUserInvite(ctx context.Context, req CreateUserInviteRequest) (*storage.UserToken, error) {
 	if err := o.ClusterAction(req.SiteDomain, storage.KindCluster, teleservices.VerbUpdate); err != nil {
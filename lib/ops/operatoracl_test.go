@@ -0,0 +1,259 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ops
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/users"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/trace"
+	"gopkg.in/check.v1"
+)
+
+type OperatorACLSuite struct{}
+
+var _ = check.Suite(&OperatorACLSuite{})
+
+func (s *OperatorACLSuite) TestClusterAccessGrantsOperations(c *check.C) {
+	acl := newTestOperatorACL(c, teleservices.Rule{
+		Resources: []string{storage.KindCluster},
+		Verbs:     []string{teleservices.VerbRead, teleservices.VerbUpdate},
+	})
+	key := testOperationKey()
+	_, err := acl.GetSiteOperations(key.SiteKey())
+	c.Assert(err, check.IsNil)
+	_, err = acl.GetOperationPlan(key)
+	c.Assert(err, check.IsNil)
+	_, err = acl.CreateSiteAppUpdateOperation(context.TODO(), CreateSiteAppUpdateOperationRequest{
+		SiteDomain: key.SiteDomain,
+	})
+	c.Assert(err, check.IsNil)
+	_, err = acl.CreateUpdateEnvarsOperation(context.TODO(), CreateUpdateEnvarsOperationRequest{
+		ClusterKey: key.SiteKey(),
+	})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *OperatorACLSuite) TestReadOnlyPlanAccess(c *check.C) {
+	acl := newTestOperatorACL(c, teleservices.Rule{
+		Resources: []string{storage.KindOperation, storage.KindOperationPlan},
+		Verbs:     []string{teleservices.VerbList, teleservices.VerbRead},
+	})
+	key := testOperationKey()
+	_, err := acl.GetSiteOperations(key.SiteKey())
+	c.Assert(err, check.IsNil)
+	_, err = acl.GetSiteOperation(key)
+	c.Assert(err, check.IsNil)
+	_, err = acl.GetOperationPlan(key)
+	c.Assert(err, check.IsNil)
+	err = acl.CreateOperationPlanChange(key, storage.PlanChange{})
+	c.Assert(trace.IsAccessDenied(err), check.Equals, true, check.Commentf("%v", err))
+	err = acl.SetOperationState(key, SetOperationStateRequest{})
+	c.Assert(trace.IsAccessDenied(err), check.Equals, true, check.Commentf("%v", err))
+	_, err = acl.GetSite(key.SiteKey())
+	c.Assert(trace.IsAccessDenied(err), check.Equals, true, check.Commentf("%v", err))
+}
+
+func (s *OperatorACLSuite) TestEnvironmentUpdatesWithoutUpgrades(c *check.C) {
+	acl := newTestOperatorACL(c, teleservices.Rule{
+		Resources: []string{storage.KindRuntimeEnvironment},
+		Verbs:     []string{teleservices.VerbUpdate},
+	})
+	key := testOperationKey()
+	_, err := acl.CreateUpdateEnvarsOperation(context.TODO(), CreateUpdateEnvarsOperationRequest{
+		ClusterKey: key.SiteKey(),
+	})
+	c.Assert(err, check.IsNil)
+	_, err = acl.CreateSiteAppUpdateOperation(context.TODO(), CreateSiteAppUpdateOperationRequest{
+		SiteDomain: key.SiteDomain,
+	})
+	c.Assert(trace.IsAccessDenied(err), check.Equals, true, check.Commentf("%v", err))
+	_, err = acl.CreateUpdateConfigOperation(context.TODO(), CreateUpdateConfigOperationRequest{
+		ClusterKey: key.SiteKey(),
+	})
+	c.Assert(trace.IsAccessDenied(err), check.Equals, true, check.Commentf("%v", err))
+}
+
+func (s *OperatorACLSuite) TestAgentsCanNotManageJoinTokens(c *check.C) {
+	key := testOperationKey().SiteKey()
+	joinRole, err := users.NewClusterJoinRole(storage.ClusterJoinAgent(key.SiteDomain), key.SiteDomain)
+	c.Assert(err, check.IsNil)
+	agentRole, err := users.NewClusterAgentRole(storage.ClusterAgent(key.SiteDomain), key.SiteDomain)
+	c.Assert(err, check.IsNil)
+	for _, role := range []teleservices.Role{joinRole, agentRole} {
+		comment := check.Commentf("role %v", role.GetName())
+		acl := newTestOperatorACLWithRole(role)
+		_, err = acl.CreateSiteExpandOperation(context.TODO(), CreateSiteExpandOperationRequest{
+			AccountID:  key.AccountID,
+			SiteDomain: key.SiteDomain,
+		})
+		c.Assert(err, check.IsNil, comment)
+		_, err = acl.CreateJoinToken(context.TODO(), CreateJoinTokenRequest{SiteKey: key})
+		c.Assert(trace.IsAccessDenied(err), check.Equals, true, comment)
+		_, err = acl.RequestScaleUp(context.TODO(), ScaleUpRequest{SiteKey: key})
+		c.Assert(trace.IsAccessDenied(err), check.Equals, true, comment)
+		_, err = acl.GetJoinTokens(context.TODO(), key)
+		c.Assert(trace.IsAccessDenied(err), check.Equals, true, comment)
+		err = acl.DeleteJoinToken(context.TODO(), DeleteJoinTokenRequest{SiteKey: key})
+		c.Assert(trace.IsAccessDenied(err), check.Equals, true, comment)
+		_, err = acl.GetExpandToken(key)
+		c.Assert(trace.IsAccessDenied(err), check.Equals, true, comment)
+	}
+
+	adminRole, err := users.NewAdminRole()
+	c.Assert(err, check.IsNil)
+	_, err = newTestOperatorACLWithRole(adminRole).CreateJoinToken(context.TODO(), CreateJoinTokenRequest{SiteKey: key})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *OperatorACLSuite) TestServiceAccountsCanNotEscalatePrivileges(c *check.C) {
+	role := newTestRole(c, teleservices.Rule{
+		Resources: []string{storage.KindServiceAccount},
+		Verbs:     []string{teleservices.VerbCreate, teleservices.VerbUpdate, teleservices.VerbRead},
+	})
+	acl := newTestOperatorACLWithRole(role)
+	acl.users = &testIdentity{users: map[string]storage.User{
+		"ci": storage.ServiceAccountToUser(storage.NewServiceAccount("ci", storage.ServiceAccountSpecV2{
+			Roles: []string{constants.RoleAdmin},
+		}), "example.com"),
+		"build": storage.ServiceAccountToUser(storage.NewServiceAccount("build", storage.ServiceAccountSpecV2{
+			Roles: []string{role.GetName()},
+		}), "example.com"),
+	}}
+	key := testOperationKey().SiteKey()
+
+	err := acl.UpsertServiceAccount(context.TODO(), key, storage.NewServiceAccount("ci",
+		storage.ServiceAccountSpecV2{Roles: []string{constants.RoleAdmin}}))
+	c.Assert(trace.IsAccessDenied(err), check.Equals, true, check.Commentf("%v", err))
+	_, err = acl.CreateAPIKey(context.TODO(), NewAPIKeyRequest{UserEmail: "ci"})
+	c.Assert(trace.IsAccessDenied(err), check.Equals, true, check.Commentf("%v", err))
+
+	// The roles held by the caller can be assigned
+	err = acl.UpsertServiceAccount(context.TODO(), key, storage.NewServiceAccount("build",
+		storage.ServiceAccountSpecV2{Roles: []string{role.GetName()}}))
+	c.Assert(err, check.IsNil)
+	_, err = acl.CreateAPIKey(context.TODO(), NewAPIKeyRequest{UserEmail: "build"})
+	c.Assert(err, check.IsNil)
+
+	adminRole, err := users.NewAdminRole()
+	c.Assert(err, check.IsNil)
+	err = newTestOperatorACLWithRole(adminRole).UpsertServiceAccount(context.TODO(), key,
+		storage.NewServiceAccount("ci", storage.ServiceAccountSpecV2{Roles: []string{constants.RoleAdmin}}))
+	c.Assert(err, check.IsNil)
+}
+
+func newTestOperatorACL(c *check.C, rules ...teleservices.Rule) *OperatorACL {
+	return newTestOperatorACLWithRole(newTestRole(c, rules...))
+}
+
+func newTestRole(c *check.C, rules ...teleservices.Rule) teleservices.Role {
+	role, err := teleservices.NewRole("test", teleservices.RoleSpecV3{
+		Allow: teleservices.RoleConditions{
+			Namespaces: []string{defaults.Namespace},
+			Rules:      rules,
+		},
+	})
+	c.Assert(err, check.IsNil)
+	return role
+}
+
+func newTestOperatorACLWithRole(role teleservices.Role) *OperatorACL {
+	user := storage.NewUser("alice@example.com", storage.UserSpecV2{
+		Type:  storage.AdminUser,
+		Roles: []string{role.GetName()},
+	})
+	return OperatorWithACL(&testOperator{}, nil, user, teleservices.NewRoleSet(role))
+}
+
+func testOperationKey() SiteOperationKey {
+	return SiteOperationKey{
+		AccountID:   defaults.SystemAccountID,
+		SiteDomain:  "example.com",
+		OperationID: "1",
+	}
+}
+
+// testOperator is an operator that accepts all requests for the test cluster
+type testOperator struct {
+	Operator
+}
+
+func (r *testOperator) GetSiteByDomain(clusterName string) (*Site, error) {
+	return &Site{Domain: clusterName}, nil
+}
+
+func (r *testOperator) GetSite(key SiteKey) (*Site, error) {
+	return &Site{Domain: key.SiteDomain}, nil
+}
+
+func (r *testOperator) GetSiteOperations(SiteKey) (SiteOperations, error) {
+	return nil, nil
+}
+
+func (r *testOperator) GetSiteOperation(key SiteOperationKey) (*SiteOperation, error) {
+	return &SiteOperation{ID: key.OperationID}, nil
+}
+
+func (r *testOperator) GetOperationPlan(SiteOperationKey) (*storage.OperationPlan, error) {
+	return &storage.OperationPlan{}, nil
+}
+
+func (r *testOperator) CreateSiteAppUpdateOperation(context.Context, CreateSiteAppUpdateOperationRequest) (*SiteOperationKey, error) {
+	key := testOperationKey()
+	return &key, nil
+}
+
+func (r *testOperator) CreateUpdateEnvarsOperation(context.Context, CreateUpdateEnvarsOperationRequest) (*SiteOperationKey, error) {
+	key := testOperationKey()
+	return &key, nil
+}
+
+func (r *testOperator) CreateSiteExpandOperation(context.Context, CreateSiteExpandOperationRequest) (*SiteOperationKey, error) {
+	key := testOperationKey()
+	return &key, nil
+}
+
+func (r *testOperator) CreateJoinToken(context.Context, CreateJoinTokenRequest) (*storage.ProvisioningToken, error) {
+	return &storage.ProvisioningToken{}, nil
+}
+
+func (r *testOperator) UpsertServiceAccount(context.Context, SiteKey, storage.ServiceAccount) error {
+	return nil
+}
+
+func (r *testOperator) CreateAPIKey(context.Context, NewAPIKeyRequest) (*storage.APIKey, error) {
+	return &storage.APIKey{}, nil
+}
+
+// testIdentity is an identity service that serves the specified users
+type testIdentity struct {
+	users.Identity
+	users map[string]storage.User
+}
+
+func (r *testIdentity) GetUser(name string) (teleservices.User, error) {
+	user, ok := r.users[name]
+	if !ok {
+		return nil, trace.NotFound("user %v not found", name)
+	}
+	return user, nil
+}
@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/url"
 	"strings"
 	"time"
@@ -121,6 +122,20 @@ type Operator interface {
 	LogForwarders
 	Monitoring
 	SMTP
+	DNS
+	NodePools
+	OperationWebhooks
+	HealthChecks
+	HealthHistory
+	NetworkHealth
+	ClusterRosters
+	ImageTrustPolicies
+	OperationApprovals
+	CatalogSyncSchedules
+	EtcdMaintenance
+	TrackedReleases
+	BackupSchedules
+	EtcdHealth
 	Endpoints
 	Tokens
 	Certificates
@@ -375,6 +390,93 @@ type Tokens interface {
 	GetExpandToken(SiteKey) (*storage.ProvisioningToken, error)
 	// GetTrustedClusterToken returns the cluster's trusted cluster token
 	GetTrustedClusterToken(SiteKey) (storage.Token, error)
+	// CreateJoinToken creates a new short-lived cluster join token
+	CreateJoinToken(context.Context, CreateJoinTokenRequest) (*storage.ProvisioningToken, error)
+	// GetJoinTokens returns the active short-lived join tokens of the cluster
+	GetJoinTokens(context.Context, SiteKey) ([]storage.ProvisioningToken, error)
+	// DeleteJoinToken deletes the specified short-lived join token
+	DeleteJoinToken(context.Context, DeleteJoinTokenRequest) error
+	// RequestScaleUp requests expansion of the cluster by the specified number
+	// of nodes and returns a join token limited to the requested profile and count
+	RequestScaleUp(context.Context, ScaleUpRequest) (*storage.ProvisioningToken, error)
+}
+
+// CreateJoinTokenRequest is a request to create a new cluster join token
+type CreateJoinTokenRequest struct {
+	// SiteKey is the key of the cluster to route request to
+	SiteKey
+	// TTL specifies how long the token is valid for
+	TTL time.Duration `json:"ttl"`
+	// Roles optionally limits the node roles that can join with the token
+	Roles []string `json:"roles"`
+	// MaxUses is the maximum number of nodes that can join with the token,
+	// zero if unlimited
+	MaxUses int `json:"max_uses"`
+}
+
+// Check validates the request
+func (r *CreateJoinTokenRequest) Check() error {
+	if err := r.SiteKey.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	if r.TTL <= 0 {
+		return trace.BadParameter("join token ttl should be positive")
+	}
+	if r.TTL > defaults.MaxJoinTokenTTL {
+		return trace.BadParameter("join token ttl can't exceed %v", defaults.MaxJoinTokenTTL)
+	}
+	if r.MaxUses < 0 {
+		return trace.BadParameter("join token max uses can't be negative")
+	}
+	return nil
+}
+
+// DeleteJoinTokenRequest is a request to delete a cluster join token
+type DeleteJoinTokenRequest struct {
+	// SiteKey is the key of the cluster to route request to
+	SiteKey
+	// Token is the join token to delete
+	Token string `json:"token"`
+}
+
+// Check validates the request
+func (r *DeleteJoinTokenRequest) Check() error {
+	if err := r.SiteKey.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	if r.Token == "" {
+		return trace.BadParameter("missing join token")
+	}
+	return nil
+}
+
+// ScaleUpRequest is a request to expand the cluster issued by an autoscaler
+type ScaleUpRequest struct {
+	// SiteKey is the key of the cluster to route request to
+	SiteKey
+	// Profile is the node profile of the new nodes
+	Profile string `json:"profile"`
+	// Count is the number of nodes to add
+	Count int `json:"count"`
+	// TTL specifies how long the new nodes have to join the cluster
+	TTL time.Duration `json:"ttl,omitempty"`
+}
+
+// CheckAndSetDefaults validates the request and sets defaults
+func (r *ScaleUpRequest) CheckAndSetDefaults() error {
+	if err := r.SiteKey.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	if r.Profile == "" {
+		return trace.BadParameter("missing node profile")
+	}
+	if r.Count <= 0 {
+		return trace.BadParameter("node count should be positive")
+	}
+	if r.TTL == 0 {
+		r.TTL = defaults.JoinTokenTTL
+	}
+	return nil
 }
 
 // Sites represents a collection of site records, where
@@ -590,12 +692,22 @@ type Certificates interface {
 	UpdateClusterCertificate(context.Context, UpdateCertificateRequest) (*ClusterCertificate, error)
 	// DeleteClusterCertificate deletes the cluster TLS certificate
 	DeleteClusterCertificate(context.Context, SiteKey) error
+	// GetCertificateExpiry returns the expiration status of the certificates
+	// managed by the cluster
+	GetCertificateExpiry(SiteKey) (*storage.CertificateExpiryStatus, error)
+	// CreateRotateCertificatesOperation creates a new operation to rotate
+	// the cluster certificates
+	CreateRotateCertificatesOperation(context.Context, CreateRotateCertificatesOperationRequest) (*SiteOperationKey, error)
 }
 
 // RuntimeEnvironment manages runtime environment variables in cluster
 type RuntimeEnvironment interface {
 	// CreateUpdateEnvarsOperation creates a new operation to update cluster runtime environment variables
 	CreateUpdateEnvarsOperation(context.Context, CreateUpdateEnvarsOperationRequest) (*SiteOperationKey, error)
+	// CreateUpdateNodeRoleOperation creates a new operation to promote or demote a cluster node
+	CreateUpdateNodeRoleOperation(context.Context, CreateUpdateNodeRoleOperationRequest) (*SiteOperationKey, error)
+	// CreateReplaceNodeOperation creates a new operation to replace a cluster node with a new one
+	CreateReplaceNodeOperation(context.Context, CreateReplaceNodeOperationRequest) (*SiteOperationKey, error)
 	// GetClusterEnvironmentVariables retrieves the cluster runtime environment variables
 	GetClusterEnvironmentVariables(SiteKey) (storage.EnvironmentVariables, error)
 	// UpdateClusterEnvironmentVariables updates the cluster runtime environment variables
@@ -611,6 +723,12 @@ type ClusterConfiguration interface {
 	GetClusterConfiguration(SiteKey) (clusterconfig.Interface, error)
 	// UpdateClusterConfiguration updates the cluster configuration from the specified request
 	UpdateClusterConfiguration(UpdateClusterConfigRequest) error
+	// CreateRotateSecretsKeyOperation creates a new operation to rotate
+	// the key that encrypts Kubernetes secrets at rest
+	CreateRotateSecretsKeyOperation(context.Context, CreateRotateSecretsKeyOperationRequest) (*SiteOperationKey, error)
+	// CreateChangeCIDROperation creates a new operation to change
+	// the pod and service subnets of the cluster
+	CreateChangeCIDROperation(context.Context, CreateChangeCIDROperationRequest) (*SiteOperationKey, error)
 }
 
 // ClusterCertificate represents the cluster certificate
@@ -1021,6 +1139,16 @@ type ConfigurePackagesRequest struct {
 	Env map[string]string `json:"env,omitempty"`
 	// Config specifies optional cluster configuration resource in raw form
 	Config []byte `json:"config,omitempty"`
+	// CertAuthority specifies the optional certificate authority to issue
+	// the cluster certificates instead of a generated one
+	CertAuthority *authority.TLSKeyPair `json:"cert_authority,omitempty"`
+}
+
+// String returns the request description.
+// The certificate authority private key is omitted
+func (r ConfigurePackagesRequest) String() string {
+	return fmt.Sprintf("ConfigurePackages(Operation=%v, Env=%v, Config=%s, CertAuthority=%v)",
+		r.SiteOperationKey, r.Env, r.Config, r.CertAuthority != nil)
 }
 
 // Proxy helps to manage connections and clients to remote ops centers
@@ -1081,7 +1209,12 @@ func (s *SiteOperation) GetVars() storage.OperationVariables {
 
 // IsFailed returns whether operation is failed
 func (s *SiteOperation) IsFailed() bool {
-	return s.State == OperationStateFailed
+	return s.State == OperationStateFailed || s.IsRolledBack()
+}
+
+// IsRolledBack returns whether operation has failed and has been rolled back
+func (s *SiteOperation) IsRolledBack() bool {
+	return s.State == OperationStateFailedRolledBack
 }
 
 // IsCompleted returns whether the operation has completed successfully
@@ -1091,7 +1224,23 @@ func (s *SiteOperation) IsCompleted() bool {
 
 // IsFinished returns true if the operation has finished (succeeded or failed)
 func (s *SiteOperation) IsFinished() bool {
-	return s.State == OperationStateCompleted || s.State == OperationStateFailed
+	return s.IsCompleted() || s.IsFailed()
+}
+
+// IsPendingApproval returns true if the operation requires approval
+// and has not been approved yet
+func (s *SiteOperation) IsPendingApproval() bool {
+	return s.Approval != nil && !s.Approval.IsApproved()
+}
+
+// CheckApproved returns an error if the operation cannot be executed
+// because it has not been approved yet
+func (s *SiteOperation) CheckApproved() error {
+	if !s.IsPendingApproval() {
+		return nil
+	}
+	return trace.AccessDenied("%v operation %v requires approval from another user, "+
+		"run 'gravity operation approve %v' as an authorized user", s.TypeString(), s.ID, s.ID)
 }
 
 // IsAWS returns true if the operation has AWS provisioner
@@ -1123,6 +1272,9 @@ func (s *SiteOperation) ClusterState() (string, error) {
 
 	if !s.IsFinished() {
 		state, ok = OperationStartedToClusterState[s.Type]
+	} else if s.IsRolledBack() {
+		// The cluster has been restored to the state before the operation
+		state, ok = SiteStateActive, true
 	} else if s.IsFailed() {
 		state, ok = OperationFailedToClusterState[s.Type]
 	} else {
@@ -1161,6 +1313,24 @@ func (s *SiteOperation) TypeString() string {
 		return "update runtime environment"
 	case OperationUpdateConfig:
 		return "update configuration"
+	case OperationUpdateNodeRole:
+		return "update node role"
+	case OperationReplaceNode:
+		return "replace node"
+	case OperationRotateCertificates:
+		return "rotate certificates"
+	case OperationRotateSecretsKey:
+		return "rotate secrets key"
+	case OperationChangeCIDR:
+		return "change CIDR"
+	case OperationRestore:
+		return "restore"
+	case OperationEtcdRestore:
+		return "restore etcd"
+	case OperationAppBackup:
+		return "back up application"
+	case OperationAppRestore:
+		return "restore application"
 	default:
 		return s.Type
 	}
@@ -1266,6 +1436,9 @@ type CreateSiteExpandOperationRequest struct {
 	Servers map[string]int `json:"servers"`
 	// Provisioner to use for this operation
 	Provisioner string `json:"provisioner"`
+	// JoinToken is the token the request has been authenticated with.
+	// It is set by the handler and is never sent over the wire
+	JoinToken string `json:"-"`
 }
 
 // CheckAndSetDefaults makes sure the request is correct and fills in some unset
@@ -1335,6 +1508,15 @@ type CreateSiteAppUpdateOperationRequest struct {
 	App string `json:"package"`
 	// StartAgents specifies whether the operation will automatically start the update agents
 	StartAgents bool `json:"start_agents"`
+	// Strategy optionally overrides the upgrade strategy from the application manifest
+	Strategy *storage.UpdateStrategy `json:"strategy,omitempty"`
+	// AutoRollback optionally enables automatic rollback of the operation if it fails
+	AutoRollback *storage.AutoRollbackPolicy `json:"auto_rollback,omitempty"`
+	// Schedule optionally restricts node-disruptive phases of the operation
+	// to the specified maintenance window
+	Schedule *storage.MaintenanceWindow `json:"schedule,omitempty"`
+	// Drain optionally overrides how the nodes are drained during the operation
+	Drain *storage.DrainPolicy `json:"drain,omitempty"`
 }
 
 // Check validates this request
@@ -1366,6 +1548,117 @@ type CreateUpdateEnvarsOperationRequest struct {
 	Env map[string]string `json:"env"`
 }
 
+// CreateUpdateNodeRoleOperationRequest is a request
+// to promote a regular node to master or demote a master to a regular node
+type CreateUpdateNodeRoleOperationRequest struct {
+	// ClusterKey identifies the cluster
+	ClusterKey SiteKey `json:"cluster_key"`
+	// Server is the hostname of the server to promote or demote
+	Server string `json:"server"`
+	// ClusterRole is the new cluster role of the server, "master" or "node"
+	ClusterRole string `json:"cluster_role"`
+}
+
+// Check validates this request
+func (r CreateUpdateNodeRoleOperationRequest) Check() error {
+	if err := r.ClusterKey.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	if r.Server == "" {
+		return trace.BadParameter("missing server")
+	}
+	switch schema.ServiceRole(r.ClusterRole) {
+	case schema.ServiceRoleMaster, schema.ServiceRoleNode:
+	default:
+		return trace.BadParameter("cluster role should be either %q or %q, got %q",
+			schema.ServiceRoleMaster, schema.ServiceRoleNode, r.ClusterRole)
+	}
+	return nil
+}
+
+// CreateRotateCertificatesOperationRequest is a request
+// to rotate the certificates of the cluster nodes
+type CreateRotateCertificatesOperationRequest struct {
+	// ClusterKey identifies the cluster
+	ClusterKey SiteKey `json:"cluster_key"`
+	// RotateCA specifies whether the cluster certificate authority
+	// is replaced as well
+	RotateCA bool `json:"rotate_ca"`
+}
+
+// Check validates this request
+func (r CreateRotateCertificatesOperationRequest) Check() error {
+	return trace.Wrap(r.ClusterKey.Check())
+}
+
+// CreateRotateSecretsKeyOperationRequest is a request to rotate
+// the key that encrypts Kubernetes secrets at rest
+type CreateRotateSecretsKeyOperationRequest struct {
+	// ClusterKey identifies the cluster
+	ClusterKey SiteKey `json:"cluster_key"`
+}
+
+// Check validates this request
+func (r CreateRotateSecretsKeyOperationRequest) Check() error {
+	return trace.Wrap(r.ClusterKey.Check())
+}
+
+// CreateChangeCIDROperationRequest is a request to change
+// the pod and service subnets of the cluster
+type CreateChangeCIDROperationRequest struct {
+	// ClusterKey identifies the cluster
+	ClusterKey SiteKey `json:"cluster_key"`
+	// PodCIDR specifies the new pod subnet.
+	// If unspecified, the pod subnet is not changed
+	PodCIDR string `json:"pod_cidr,omitempty"`
+	// ServiceCIDR specifies the new service subnet.
+	// If unspecified, the service subnet is not changed
+	ServiceCIDR string `json:"service_cidr,omitempty"`
+}
+
+// Check validates this request
+func (r CreateChangeCIDROperationRequest) Check() error {
+	if err := r.ClusterKey.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	if r.PodCIDR == "" && r.ServiceCIDR == "" {
+		return trace.BadParameter("either pod or service subnet is required")
+	}
+	return nil
+}
+
+// CreateReplaceNodeOperationRequest is a request
+// to replace an existing cluster node with a new one
+type CreateReplaceNodeOperationRequest struct {
+	// ClusterKey identifies the cluster
+	ClusterKey SiteKey `json:"cluster_key"`
+	// Server is the hostname or IP address of the server to replace
+	Server string `json:"server"`
+	// NewAddr is the advertise address of the replacement node
+	NewAddr string `json:"new_addr"`
+	// SSH specifies the optional credentials to connect to the replacement node with
+	SSH *storage.SSHCredentials `json:"ssh,omitempty"`
+	// Force specifies whether the replaced node is removed even if it is offline
+	Force bool `json:"force"`
+}
+
+// Check validates this request
+func (r CreateReplaceNodeOperationRequest) Check() error {
+	if err := r.ClusterKey.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	if r.Server == "" {
+		return trace.BadParameter("missing server")
+	}
+	if net.ParseIP(r.NewAddr) == nil {
+		return trace.BadParameter("replacement node address %q is not a valid IP address", r.NewAddr)
+	}
+	if r.SSH != nil && (r.SSH.User == "" || r.SSH.PrivateKeyPath == "") {
+		return trace.BadParameter("SSH credentials require user and private key path")
+	}
+	return nil
+}
+
 // CreateUpdateConfigOperationRequest is a request
 // to create an operation to update cluster configuration
 type CreateUpdateConfigOperationRequest struct {
@@ -1563,6 +1856,9 @@ type NewSiteRequest struct {
 	DNSConfig storage.DNSConfig `json:"dns_config"`
 	// Docker specifies the cluster Docker configuration
 	Docker storage.DockerConfig `json:"docker"`
+	// Demo specifies whether the cluster is installed in demo mode
+	// with relaxed requirements
+	Demo bool `json:"demo,omitempty"`
 }
 
 // SiteKey is a key used to identify site
@@ -1660,6 +1956,9 @@ type Site struct {
 	DNSConfig storage.DNSConfig `json:"dns_config"`
 	// InstallToken specifies the original token the cluster was installed with
 	InstallToken string `json:"install_token"`
+	// Demo indicates that the cluster has been installed in demo mode
+	// and is not suitable for production use
+	Demo bool `json:"demo,omitempty"`
 }
 
 // IsOnline returns whether this site is online
@@ -1910,6 +2209,248 @@ type SMTP interface {
 	DeleteSMTPConfig(context.Context, SiteKey) error
 }
 
+// DNS defines the interface to manage cluster DNS configuration
+type DNS interface {
+	// GetClusterDNS returns the cluster DNS configuration
+	GetClusterDNS(SiteKey) (storage.ClusterDNS, error)
+	// UpdateClusterDNS updates the cluster DNS configuration
+	UpdateClusterDNS(context.Context, SiteKey, storage.ClusterDNS) error
+}
+
+// NodePools defines the interface to manage cluster node pools
+type NodePools interface {
+	// GetNodePools returns the list of node pools of the cluster
+	GetNodePools(SiteKey) ([]storage.NodePool, error)
+	// UpsertNodePool creates or updates a node pool
+	UpsertNodePool(context.Context, SiteKey, storage.NodePool) error
+	// DeleteNodePool deletes the node pool with the specified name
+	DeleteNodePool(ctx context.Context, key SiteKey, name string) error
+}
+
+// ClusterRosters defines the interface to manage the cluster roster
+type ClusterRosters interface {
+	// GetClusterRoster returns the cluster roster
+	GetClusterRoster(SiteKey) (storage.ClusterRoster, error)
+	// UpsertClusterRoster creates or updates the cluster roster
+	UpsertClusterRoster(context.Context, SiteKey, storage.ClusterRoster) error
+	// DeleteClusterRoster deletes the cluster roster which stops
+	// the membership reconciliation
+	DeleteClusterRoster(context.Context, SiteKey) error
+	// GetClusterRosterStatus returns the status of the roster reconciliation
+	GetClusterRosterStatus(SiteKey) (*storage.ClusterRosterStatus, error)
+	// ApproveClusterRosterChanges approves the pending membership changes
+	// with the specified ID
+	ApproveClusterRosterChanges(ctx context.Context, key SiteKey, changesID string) error
+}
+
+// ImageTrustPolicies defines the interface to manage the container image trust policy
+type ImageTrustPolicies interface {
+	// GetImageTrustPolicy returns the image trust policy
+	GetImageTrustPolicy(SiteKey) (storage.ImageTrustPolicy, error)
+	// UpsertImageTrustPolicy creates or updates the image trust policy
+	UpsertImageTrustPolicy(context.Context, SiteKey, storage.ImageTrustPolicy) error
+	// DeleteImageTrustPolicy deletes the image trust policy
+	// which disables the image signature verification
+	DeleteImageTrustPolicy(context.Context, SiteKey) error
+}
+
+// OperationApprovals defines the interface to manage the approval
+// of the operations that require sign-off from a second user
+type OperationApprovals interface {
+	// GetOperationApprovalPolicy returns the operation approval policy
+	GetOperationApprovalPolicy(SiteKey) (storage.OperationApprovalPolicy, error)
+	// UpsertOperationApprovalPolicy creates or updates the operation approval policy
+	UpsertOperationApprovalPolicy(context.Context, SiteKey, storage.OperationApprovalPolicy) error
+	// DeleteOperationApprovalPolicy deletes the operation approval policy
+	// which lets all operations execute without approval
+	DeleteOperationApprovalPolicy(context.Context, SiteKey) error
+	// ApproveOperation approves the specified operation on behalf of the
+	// calling user who has to be different from the user who created it
+	ApproveOperation(context.Context, SiteOperationKey) error
+}
+
+// CatalogSyncSchedules defines the interface to manage the schedule
+// to mirror an upstream Helm repository into the local catalog
+type CatalogSyncSchedules interface {
+	// GetCatalogSyncSchedule returns the catalog sync schedule
+	GetCatalogSyncSchedule(SiteKey) (storage.CatalogSyncSchedule, error)
+	// UpsertCatalogSyncSchedule creates or updates the catalog sync schedule
+	UpsertCatalogSyncSchedule(context.Context, SiteKey, storage.CatalogSyncSchedule) error
+	// DeleteCatalogSyncSchedule deletes the catalog sync schedule
+	// which stops the synchronization
+	DeleteCatalogSyncSchedule(context.Context, SiteKey) error
+}
+
+// EtcdMaintenance defines the interface to query the status
+// of the etcd database maintenance
+type EtcdMaintenance interface {
+	// GetEtcdMaintenanceStatus returns the status of the etcd compaction
+	// and defragmentation
+	GetEtcdMaintenanceStatus(SiteKey) (*storage.EtcdMaintenanceStatus, error)
+}
+
+// TrackedReleases defines the interface to manage the application releases
+// recorded in the cluster backend by gravity app install/upgrade/rollback
+type TrackedReleases interface {
+	// GetTrackedReleases returns the application releases recorded for the cluster
+	GetTrackedReleases(SiteKey) ([]storage.Release, error)
+	// TrackRelease records the state of the application release
+	TrackRelease(context.Context, SiteKey, storage.Release) error
+	// UntrackRelease removes the record of the release with the specified name
+	UntrackRelease(ctx context.Context, key SiteKey, name string) error
+}
+
+// BackupSchedules defines the interface to manage the schedule
+// of the cluster backups, their replication to the standby cluster
+// and to restore the cluster or its etcd database from a backup
+type BackupSchedules interface {
+	// GetBackupSchedule returns the backup schedule
+	GetBackupSchedule(SiteKey) (storage.BackupSchedule, error)
+	// UpsertBackupSchedule creates or updates the backup schedule
+	UpsertBackupSchedule(context.Context, SiteKey, storage.BackupSchedule) error
+	// DeleteBackupSchedule deletes the backup schedule
+	// which stops the scheduled backups
+	DeleteBackupSchedule(context.Context, SiteKey) error
+	// GetBackupStatus returns the status of the last scheduled backup
+	GetBackupStatus(SiteKey) (*storage.BackupStatus, error)
+	// GetReplication returns the replication of the backups
+	// of the primary cluster to this standby cluster
+	GetReplication(SiteKey) (storage.Replication, error)
+	// UpsertReplication creates or updates the replication
+	UpsertReplication(context.Context, SiteKey, storage.Replication) error
+	// DeleteReplication deletes the replication
+	// which stops replicating the backups of the primary cluster
+	DeleteReplication(context.Context, SiteKey) error
+	// GetReplicationStatus returns the status of the replication
+	GetReplicationStatus(SiteKey) (*storage.ReplicationStatus, error)
+	// CreateRestoreOperation creates a new operation to restore
+	// the cluster from a backup
+	CreateRestoreOperation(context.Context, CreateRestoreOperationRequest) (*SiteOperationKey, error)
+	// CreateEtcdRestoreOperation creates a new operation to restore
+	// the etcd database of the cluster from a snapshot
+	CreateEtcdRestoreOperation(context.Context, CreateEtcdRestoreOperationRequest) (*SiteOperationKey, error)
+	// CreateAppBackupOperation creates a new operation to back up
+	// a single application of the cluster
+	CreateAppBackupOperation(context.Context, CreateAppBackupOperationRequest) (*SiteOperationKey, error)
+	// CreateAppRestoreOperation creates a new operation to restore
+	// a single application of the cluster from its backup
+	CreateAppRestoreOperation(context.Context, CreateAppBackupOperationRequest) (*SiteOperationKey, error)
+}
+
+// CreateRestoreOperationRequest is a request to restore the cluster
+// from a backup taken on another cluster
+type CreateRestoreOperationRequest struct {
+	// ClusterKey identifies the cluster
+	ClusterKey SiteKey `json:"cluster_key"`
+	// Backup is the path to the backup directory on the node
+	// the operation is started on
+	Backup string `json:"backup"`
+}
+
+// Check validates this request
+func (r CreateRestoreOperationRequest) Check() error {
+	if err := r.ClusterKey.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	if r.Backup == "" {
+		return trace.BadParameter("path to the backup is required")
+	}
+	return nil
+}
+
+// CreateEtcdRestoreOperationRequest is a request to restore
+// the etcd database of the cluster from a snapshot
+type CreateEtcdRestoreOperationRequest struct {
+	// ClusterKey identifies the cluster
+	ClusterKey SiteKey `json:"cluster_key"`
+	// Snapshot is the path to the etcd snapshot on the node
+	// the operation is started on
+	Snapshot string `json:"snapshot"`
+	// Revision is the revision of the etcd database in the snapshot
+	Revision int64 `json:"revision"`
+}
+
+// Check validates this request
+func (r CreateEtcdRestoreOperationRequest) Check() error {
+	if err := r.ClusterKey.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	if r.Snapshot == "" {
+		return trace.BadParameter("path to the snapshot is required")
+	}
+	return nil
+}
+
+// CreateAppBackupOperationRequest is a request to back up a single
+// application of the cluster or to restore it from its backup
+type CreateAppBackupOperationRequest struct {
+	// ClusterKey identifies the cluster
+	ClusterKey SiteKey `json:"cluster_key"`
+	// Release is the name of the application release
+	Release string `json:"release"`
+	// Path is the path to the backup tarball on the node
+	// the operation is started on
+	Path string `json:"path"`
+}
+
+// Check validates this request
+func (r CreateAppBackupOperationRequest) Check() error {
+	if err := r.ClusterKey.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	if r.Release == "" {
+		return trace.BadParameter("release name is required")
+	}
+	if r.Path == "" {
+		return trace.BadParameter("path to the backup is required")
+	}
+	return nil
+}
+
+// EtcdHealth defines the interface to query the health
+// and capacity of the etcd database
+type EtcdHealth interface {
+	// GetEtcdHealthStatus returns the status of the etcd health and capacity
+	GetEtcdHealthStatus(SiteKey) (*storage.EtcdHealthStatus, error)
+}
+
+// OperationWebhooks defines the interface to manage operation admission webhooks
+type OperationWebhooks interface {
+	// GetOperationWebhooks returns the list of operation webhooks of the cluster
+	GetOperationWebhooks(SiteKey) ([]storage.OperationWebhook, error)
+	// UpsertOperationWebhook creates or updates an operation webhook
+	UpsertOperationWebhook(context.Context, SiteKey, storage.OperationWebhook) error
+	// DeleteOperationWebhook deletes the operation webhook with the specified name
+	DeleteOperationWebhook(ctx context.Context, key SiteKey, name string) error
+}
+
+// HealthChecks defines the interface to manage custom cluster health checks
+type HealthChecks interface {
+	// GetHealthChecks returns the list of health checks of the cluster
+	GetHealthChecks(SiteKey) ([]storage.HealthCheck, error)
+	// UpsertHealthCheck creates or updates a health check
+	UpsertHealthCheck(context.Context, SiteKey, storage.HealthCheck) error
+	// DeleteHealthCheck deletes the health check with the specified name
+	DeleteHealthCheck(ctx context.Context, key SiteKey, name string) error
+	// GetHealthCheckStatus returns the result of the last evaluation
+	// of the cluster health checks
+	GetHealthCheckStatus(SiteKey) (*storage.HealthCheckStatus, error)
+}
+
+// HealthHistory defines the interface to the cluster health history
+type HealthHistory interface {
+	// GetHealthHistory returns the cluster health snapshots recorded since
+	// the specified time, ordered from the oldest to the newest
+	GetHealthHistory(key SiteKey, since time.Time) ([]storage.HealthSnapshot, error)
+}
+
+// NetworkHealth defines the interface to the network health between cluster nodes
+type NetworkHealth interface {
+	// GetNetworkHealth returns the network health reports
+	// published by the network monitors on the cluster nodes
+	GetNetworkHealth(SiteKey) ([]storage.NetworkHealthReport, error)
+}
+
 // Monitoring defines the interface to manage monitoring and metrics
 type Monitoring interface {
 	// GetAlerts returns the list of configured monitoring alerts
@@ -2049,6 +2590,13 @@ type Identity interface {
 	GetUsers(key SiteKey) ([]teleservices.User, error)
 	// DeleteUser deletes a user by name
 	DeleteUser(ctx context.Context, key SiteKey, name string) error
+	// UpsertServiceAccount creates or updates a service account
+	UpsertServiceAccount(ctx context.Context, key SiteKey, account storage.ServiceAccount) error
+	// GetServiceAccounts returns all service accounts
+	GetServiceAccounts(key SiteKey) ([]storage.ServiceAccount, error)
+	// DeleteServiceAccount deletes the service account with the specified name
+	// along with all its API keys
+	DeleteServiceAccount(ctx context.Context, key SiteKey, name string) error
 	// UpsertClusterAuthPreference updates cluster authentication preference
 	UpsertClusterAuthPreference(ctx context.Context, key SiteKey, auth teleservices.AuthPreference) error
 	// GetClusterAuthPreference returns cluster authentication preference
@@ -2093,8 +2641,36 @@ func (r AuditEventRequest) String() string {
 	return fmt.Sprintf("AuditEvent(Event=%v, Fields=%v)", r.Event, r.Fields)
 }
 
+// GetClusterEventsRequest is a request to retrieve the cluster events.
+type GetClusterEventsRequest struct {
+	// SiteKey is the ID of the cluster the request is for.
+	SiteKey
+	// After is the ID of the last received event. The events recorded
+	// after it are returned. If empty, the events are returned from
+	// the beginning of the events table.
+	After string `json:"after,omitempty"`
+	// Limit is the maximum number of events to return.
+	// Defaults to defaults.ClusterEventsLimit.
+	Limit int `json:"limit,omitempty"`
+}
+
+// Check validates the cluster events request.
+func (r *GetClusterEventsRequest) Check() error {
+	if err := r.SiteKey.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	if r.Limit < 0 {
+		return trace.BadParameter("limit can not be negative")
+	}
+	return nil
+}
+
 // Audit provides interface for emitting audit log events.
 type Audit interface {
 	// EmitAuditEvent saves the provided event in the audit log.
 	EmitAuditEvent(context.Context, AuditEventRequest) error
+	// GetClusterEvents returns the cluster events recorded after the
+	// event specified in the request, ordered from the oldest to the newest.
+	// Every emitted audit event is recorded as a cluster event.
+	GetClusterEvents(context.Context, GetClusterEventsRequest) ([]storage.ClusterEvent, error)
 }
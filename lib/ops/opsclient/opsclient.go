@@ -17,6 +17,7 @@ limitations under the License.
 package opsclient
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -458,7 +459,7 @@ func (c *Client) GetSiteOperation(key ops.SiteOperationKey) (*ops.SiteOperation,
 }
 
 func (c *Client) CreateSiteInstallOperation(ctx context.Context, req ops.CreateSiteInstallOperationRequest) (*ops.SiteOperationKey, error) {
-	out, err := c.PostJSON(c.Endpoint("accounts", req.AccountID, "sites", req.SiteDomain, "operations", "install"), req)
+	out, err := c.postOperation(ctx, c.Endpoint("accounts", req.AccountID, "sites", req.SiteDomain, "operations", "install"), req)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -535,7 +536,7 @@ func (c *Client) SiteExpandOperationStart(key ops.SiteOperationKey) error {
 }
 
 func (c *Client) CreateSiteUninstallOperation(ctx context.Context, req ops.CreateSiteUninstallOperationRequest) (*ops.SiteOperationKey, error) {
-	out, err := c.PostJSON(c.Endpoint("accounts", req.AccountID, "sites", req.SiteDomain, "operations", "uninstall"), req)
+	out, err := c.postOperation(ctx, c.Endpoint("accounts", req.AccountID, "sites", req.SiteDomain, "operations", "uninstall"), req)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -548,7 +549,7 @@ func (c *Client) CreateSiteUninstallOperation(ctx context.Context, req ops.Creat
 
 // CreateClusterGarbageCollectOperation creates a new garbage collection operation in the cluster
 func (c *Client) CreateClusterGarbageCollectOperation(ctx context.Context, req ops.CreateClusterGarbageCollectOperationRequest) (*ops.SiteOperationKey, error) {
-	out, err := c.PostJSON(c.Endpoint("accounts", req.AccountID, "sites", req.ClusterName, "operations", "gc"), req)
+	out, err := c.postOperation(ctx, c.Endpoint("accounts", req.AccountID, "sites", req.ClusterName, "operations", "gc"), req)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -560,9 +561,35 @@ func (c *Client) CreateClusterGarbageCollectOperation(ctx context.Context, req o
 	return &key, nil
 }
 
+// CreateUpdateNodeRoleOperation creates a new operation to promote or demote a cluster node
+func (c *Client) CreateUpdateNodeRoleOperation(ctx context.Context, req ops.CreateUpdateNodeRoleOperationRequest) (*ops.SiteOperationKey, error) {
+	out, err := c.postOperation(ctx, c.Endpoint("accounts", req.ClusterKey.AccountID, "sites", req.ClusterKey.SiteDomain, "operations", "noderole"), req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var key ops.SiteOperationKey
+	if err := json.Unmarshal(out.Bytes(), &key); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &key, nil
+}
+
+// CreateReplaceNodeOperation creates a new operation to replace a cluster node with a new one
+func (c *Client) CreateReplaceNodeOperation(ctx context.Context, req ops.CreateReplaceNodeOperationRequest) (*ops.SiteOperationKey, error) {
+	out, err := c.postOperation(ctx, c.Endpoint("accounts", req.ClusterKey.AccountID, "sites", req.ClusterKey.SiteDomain, "operations", "replacenode"), req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var key ops.SiteOperationKey
+	if err := json.Unmarshal(out.Bytes(), &key); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &key, nil
+}
+
 // CreateUpdateEnvarsOperation creates a new operation to update cluster runtime environment variables
 func (c *Client) CreateUpdateEnvarsOperation(ctx context.Context, req ops.CreateUpdateEnvarsOperationRequest) (*ops.SiteOperationKey, error) {
-	out, err := c.PostJSON(c.Endpoint("accounts", req.ClusterKey.AccountID, "sites", req.ClusterKey.SiteDomain, "operations", "envars"), req)
+	out, err := c.postOperation(ctx, c.Endpoint("accounts", req.ClusterKey.AccountID, "sites", req.ClusterKey.SiteDomain, "operations", "envars"), req)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -576,7 +603,7 @@ func (c *Client) CreateUpdateEnvarsOperation(ctx context.Context, req ops.Create
 
 // CreateUpdateConfigOperation creates a new operation to update cluster configuration
 func (c *Client) CreateUpdateConfigOperation(ctx context.Context, req ops.CreateUpdateConfigOperationRequest) (*ops.SiteOperationKey, error) {
-	out, err := c.PostJSON(c.Endpoint("accounts", req.ClusterKey.AccountID, "sites", req.ClusterKey.SiteDomain, "operations", "config"), req)
+	out, err := c.postOperation(ctx, c.Endpoint("accounts", req.ClusterKey.AccountID, "sites", req.ClusterKey.SiteDomain, "operations", "config"), req)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -597,7 +624,7 @@ func (c *Client) SiteUninstallOperationStart(req ops.SiteOperationKey) error {
 }
 
 func (c *Client) CreateSiteExpandOperation(ctx context.Context, req ops.CreateSiteExpandOperationRequest) (*ops.SiteOperationKey, error) {
-	out, err := c.PostJSON(c.Endpoint("accounts", req.AccountID, "sites", req.SiteDomain, "operations", "expand"), req)
+	out, err := c.postOperation(ctx, c.Endpoint("accounts", req.AccountID, "sites", req.SiteDomain, "operations", "expand"), req)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -609,7 +636,7 @@ func (c *Client) CreateSiteExpandOperation(ctx context.Context, req ops.CreateSi
 }
 
 func (c *Client) CreateSiteShrinkOperation(ctx context.Context, req ops.CreateSiteShrinkOperationRequest) (*ops.SiteOperationKey, error) {
-	out, err := c.PostJSON(c.Endpoint("accounts", req.AccountID, "sites", req.SiteDomain, "operations", "shrink"), req)
+	out, err := c.postOperation(ctx, c.Endpoint("accounts", req.AccountID, "sites", req.SiteDomain, "operations", "shrink"), req)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -621,7 +648,7 @@ func (c *Client) CreateSiteShrinkOperation(ctx context.Context, req ops.CreateSi
 }
 
 func (c *Client) CreateSiteAppUpdateOperation(ctx context.Context, req ops.CreateSiteAppUpdateOperationRequest) (*ops.SiteOperationKey, error) {
-	out, err := c.PostJSON(c.Endpoint("accounts", req.AccountID, "sites", req.SiteDomain, "operations", "update"), req)
+	out, err := c.postOperation(ctx, c.Endpoint("accounts", req.AccountID, "sites", req.SiteDomain, "operations", "update"), req)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -1043,6 +1070,514 @@ func (c *Client) DeleteSMTPConfig(ctx context.Context, key ops.SiteKey) error {
 	return trace.Wrap(err)
 }
 
+// GetClusterDNS returns the cluster DNS configuration
+func (c *Client) GetClusterDNS(key ops.SiteKey) (storage.ClusterDNS, error) {
+	response, err := c.Get(c.Endpoint(
+		"accounts", key.AccountID, "sites", key.SiteDomain, "dns"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var raw json.RawMessage
+	if err := json.Unmarshal(response.Bytes(), &raw); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	config, err := storage.UnmarshalClusterDNS(raw)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	return config, nil
+}
+
+// UpdateClusterDNS updates the cluster DNS configuration
+func (c *Client) UpdateClusterDNS(ctx context.Context, key ops.SiteKey, config storage.ClusterDNS) error {
+	bytes, err := storage.MarshalClusterDNS(config)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	_, err = c.PutJSON(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "dns"),
+		&UpsertResourceRawReq{Resource: bytes})
+	return trace.Wrap(err)
+}
+
+// GetNodePools returns the list of node pools of the cluster
+func (c *Client) GetNodePools(key ops.SiteKey) ([]storage.NodePool, error) {
+	out, err := c.Get(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "nodepools"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(out.Bytes(), &items); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	pools := make([]storage.NodePool, len(items))
+	for i, raw := range items {
+		pool, err := storage.UnmarshalNodePool(raw)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		pools[i] = pool
+	}
+	return pools, nil
+}
+
+// UpsertNodePool creates or updates a node pool
+func (c *Client) UpsertNodePool(ctx context.Context, key ops.SiteKey, pool storage.NodePool) error {
+	bytes, err := storage.MarshalNodePool(pool)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = c.PutJSON(
+		c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "nodepools", pool.GetName()),
+		&UpsertResourceRawReq{
+			Resource: bytes,
+		})
+	return trace.Wrap(err)
+}
+
+// DeleteNodePool deletes the node pool with the specified name
+func (c *Client) DeleteNodePool(ctx context.Context, key ops.SiteKey, name string) error {
+	_, err := c.Delete(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "nodepools", name))
+	return trace.Wrap(err)
+}
+
+// GetOperationWebhooks returns the list of operation webhooks of the cluster
+func (c *Client) GetOperationWebhooks(key ops.SiteKey) ([]storage.OperationWebhook, error) {
+	out, err := c.Get(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "operationwebhooks"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(out.Bytes(), &items); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	webhooks := make([]storage.OperationWebhook, len(items))
+	for i, raw := range items {
+		webhook, err := storage.UnmarshalOperationWebhook(raw)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		webhooks[i] = webhook
+	}
+	return webhooks, nil
+}
+
+// UpsertOperationWebhook creates or updates an operation webhook
+func (c *Client) UpsertOperationWebhook(ctx context.Context, key ops.SiteKey, webhook storage.OperationWebhook) error {
+	bytes, err := storage.MarshalOperationWebhook(webhook)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = c.PutJSON(
+		c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "operationwebhooks", webhook.GetName()),
+		&UpsertResourceRawReq{
+			Resource: bytes,
+		})
+	return trace.Wrap(err)
+}
+
+// DeleteOperationWebhook deletes the operation webhook with the specified name
+func (c *Client) DeleteOperationWebhook(ctx context.Context, key ops.SiteKey, name string) error {
+	_, err := c.Delete(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "operationwebhooks", name))
+	return trace.Wrap(err)
+}
+
+// GetHealthChecks returns the list of health checks of the cluster
+func (c *Client) GetHealthChecks(key ops.SiteKey) ([]storage.HealthCheck, error) {
+	out, err := c.Get(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "healthchecks"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(out.Bytes(), &items); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	checks := make([]storage.HealthCheck, len(items))
+	for i, raw := range items {
+		check, err := storage.UnmarshalHealthCheck(raw)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		checks[i] = check
+	}
+	return checks, nil
+}
+
+// UpsertHealthCheck creates or updates a health check
+func (c *Client) UpsertHealthCheck(ctx context.Context, key ops.SiteKey, check storage.HealthCheck) error {
+	bytes, err := storage.MarshalHealthCheck(check)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = c.PutJSON(
+		c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "healthchecks", check.GetName()),
+		&UpsertResourceRawReq{
+			Resource: bytes,
+		})
+	return trace.Wrap(err)
+}
+
+// DeleteHealthCheck deletes the health check with the specified name
+func (c *Client) DeleteHealthCheck(ctx context.Context, key ops.SiteKey, name string) error {
+	_, err := c.Delete(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "healthchecks", name))
+	return trace.Wrap(err)
+}
+
+// GetHealthCheckStatus returns the result of the last evaluation
+// of the cluster health checks
+func (c *Client) GetHealthCheckStatus(key ops.SiteKey) (*storage.HealthCheckStatus, error) {
+	out, err := c.Get(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "healthchecks", "status"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var status storage.HealthCheckStatus
+	if err := json.Unmarshal(out.Bytes(), &status); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &status, nil
+}
+
+// GetHealthHistory returns the cluster health snapshots recorded since
+// the specified time, ordered from the oldest to the newest
+func (c *Client) GetHealthHistory(key ops.SiteKey, since time.Time) ([]storage.HealthSnapshot, error) {
+	out, err := c.Get(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "healthhistory"), url.Values{
+		"since": []string{since.Format(time.RFC3339Nano)},
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var snapshots []storage.HealthSnapshot
+	if err := json.Unmarshal(out.Bytes(), &snapshots); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return snapshots, nil
+}
+
+// GetNetworkHealth returns the network health reports
+// published by the network monitors on the cluster nodes
+func (c *Client) GetNetworkHealth(key ops.SiteKey) ([]storage.NetworkHealthReport, error) {
+	out, err := c.Get(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "networkhealth"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var reports []storage.NetworkHealthReport
+	if err := json.Unmarshal(out.Bytes(), &reports); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return reports, nil
+}
+
+// GetClusterRoster returns the cluster roster
+func (c *Client) GetClusterRoster(key ops.SiteKey) (storage.ClusterRoster, error) {
+	out, err := c.Get(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "roster"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return storage.UnmarshalClusterRoster(out.Bytes())
+}
+
+// UpsertClusterRoster creates or updates the cluster roster
+func (c *Client) UpsertClusterRoster(ctx context.Context, key ops.SiteKey, roster storage.ClusterRoster) error {
+	bytes, err := storage.MarshalClusterRoster(roster)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = c.PutJSON(
+		c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "roster"),
+		&UpsertResourceRawReq{
+			Resource: bytes,
+		})
+	return trace.Wrap(err)
+}
+
+// DeleteClusterRoster deletes the cluster roster
+func (c *Client) DeleteClusterRoster(ctx context.Context, key ops.SiteKey) error {
+	_, err := c.Delete(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "roster"))
+	return trace.Wrap(err)
+}
+
+// GetClusterRosterStatus returns the status of the roster reconciliation
+func (c *Client) GetClusterRosterStatus(key ops.SiteKey) (*storage.ClusterRosterStatus, error) {
+	out, err := c.Get(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "roster", "status"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var status storage.ClusterRosterStatus
+	if err := json.Unmarshal(out.Bytes(), &status); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &status, nil
+}
+
+// ApproveClusterRosterChanges approves the pending membership changes
+func (c *Client) ApproveClusterRosterChanges(ctx context.Context, key ops.SiteKey, changesID string) error {
+	_, err := c.PostJSON(
+		c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "roster", "approve"),
+		&ApproveRosterChangesReq{
+			ChangesID: changesID,
+		})
+	return trace.Wrap(err)
+}
+
+// GetImageTrustPolicy returns the image trust policy
+func (c *Client) GetImageTrustPolicy(key ops.SiteKey) (storage.ImageTrustPolicy, error) {
+	out, err := c.Get(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "imagetrustpolicy"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return storage.UnmarshalImageTrustPolicy(out.Bytes())
+}
+
+// UpsertImageTrustPolicy creates or updates the image trust policy
+func (c *Client) UpsertImageTrustPolicy(ctx context.Context, key ops.SiteKey, policy storage.ImageTrustPolicy) error {
+	bytes, err := storage.MarshalImageTrustPolicy(policy)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = c.PutJSON(
+		c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "imagetrustpolicy"),
+		&UpsertResourceRawReq{
+			Resource: bytes,
+		})
+	return trace.Wrap(err)
+}
+
+// DeleteImageTrustPolicy deletes the image trust policy
+func (c *Client) DeleteImageTrustPolicy(ctx context.Context, key ops.SiteKey) error {
+	_, err := c.Delete(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "imagetrustpolicy"))
+	return trace.Wrap(err)
+}
+
+// GetOperationApprovalPolicy returns the operation approval policy
+func (c *Client) GetOperationApprovalPolicy(key ops.SiteKey) (storage.OperationApprovalPolicy, error) {
+	out, err := c.Get(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "approvalpolicy"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return storage.UnmarshalOperationApprovalPolicy(out.Bytes())
+}
+
+// UpsertOperationApprovalPolicy creates or updates the operation approval policy
+func (c *Client) UpsertOperationApprovalPolicy(ctx context.Context, key ops.SiteKey, policy storage.OperationApprovalPolicy) error {
+	bytes, err := storage.MarshalOperationApprovalPolicy(policy)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = c.PutJSON(
+		c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "approvalpolicy"),
+		&UpsertResourceRawReq{
+			Resource: bytes,
+		})
+	return trace.Wrap(err)
+}
+
+// DeleteOperationApprovalPolicy deletes the operation approval policy
+func (c *Client) DeleteOperationApprovalPolicy(ctx context.Context, key ops.SiteKey) error {
+	_, err := c.Delete(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "approvalpolicy"))
+	return trace.Wrap(err)
+}
+
+// ApproveOperation approves the specified operation on behalf of the client's user
+func (c *Client) ApproveOperation(ctx context.Context, key ops.SiteOperationKey) error {
+	_, err := c.PostJSON(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain,
+		"operations", "common", key.OperationID, "approve"), struct{}{})
+	return trace.Wrap(err)
+}
+
+// GetCatalogSyncSchedule returns the catalog sync schedule
+func (c *Client) GetCatalogSyncSchedule(key ops.SiteKey) (storage.CatalogSyncSchedule, error) {
+	out, err := c.Get(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "catalogsync"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return storage.UnmarshalCatalogSyncSchedule(out.Bytes())
+}
+
+// UpsertCatalogSyncSchedule creates or updates the catalog sync schedule
+func (c *Client) UpsertCatalogSyncSchedule(ctx context.Context, key ops.SiteKey, schedule storage.CatalogSyncSchedule) error {
+	bytes, err := storage.MarshalCatalogSyncSchedule(schedule)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = c.PutJSON(
+		c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "catalogsync"),
+		&UpsertResourceRawReq{
+			Resource: bytes,
+		})
+	return trace.Wrap(err)
+}
+
+// DeleteCatalogSyncSchedule deletes the catalog sync schedule
+func (c *Client) DeleteCatalogSyncSchedule(ctx context.Context, key ops.SiteKey) error {
+	_, err := c.Delete(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "catalogsync"))
+	return trace.Wrap(err)
+}
+
+// GetBackupSchedule returns the backup schedule
+func (c *Client) GetBackupSchedule(key ops.SiteKey) (storage.BackupSchedule, error) {
+	out, err := c.Get(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "backupschedule"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return storage.UnmarshalBackupSchedule(out.Bytes())
+}
+
+// UpsertBackupSchedule creates or updates the backup schedule
+func (c *Client) UpsertBackupSchedule(ctx context.Context, key ops.SiteKey, schedule storage.BackupSchedule) error {
+	bytes, err := storage.MarshalBackupSchedule(schedule)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = c.PutJSON(
+		c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "backupschedule"),
+		&UpsertResourceRawReq{
+			Resource: bytes,
+		})
+	return trace.Wrap(err)
+}
+
+// DeleteBackupSchedule deletes the backup schedule
+func (c *Client) DeleteBackupSchedule(ctx context.Context, key ops.SiteKey) error {
+	_, err := c.Delete(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "backupschedule"))
+	return trace.Wrap(err)
+}
+
+// GetBackupStatus returns the status of the last scheduled backup
+func (c *Client) GetBackupStatus(key ops.SiteKey) (*storage.BackupStatus, error) {
+	out, err := c.Get(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "backupstatus"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var status storage.BackupStatus
+	if err := json.Unmarshal(out.Bytes(), &status); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &status, nil
+}
+
+// GetReplication returns the replication of the primary cluster backups
+func (c *Client) GetReplication(key ops.SiteKey) (storage.Replication, error) {
+	out, err := c.Get(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "replication"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return storage.UnmarshalReplication(out.Bytes())
+}
+
+// UpsertReplication creates or updates the replication
+func (c *Client) UpsertReplication(ctx context.Context, key ops.SiteKey, replication storage.Replication) error {
+	bytes, err := storage.MarshalReplication(replication)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = c.PutJSON(
+		c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "replication"),
+		&UpsertResourceRawReq{
+			Resource: bytes,
+		})
+	return trace.Wrap(err)
+}
+
+// DeleteReplication deletes the replication
+func (c *Client) DeleteReplication(ctx context.Context, key ops.SiteKey) error {
+	_, err := c.Delete(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "replication"))
+	return trace.Wrap(err)
+}
+
+// GetReplicationStatus returns the status of the replication
+func (c *Client) GetReplicationStatus(key ops.SiteKey) (*storage.ReplicationStatus, error) {
+	out, err := c.Get(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "replicationstatus"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var status storage.ReplicationStatus
+	if err := json.Unmarshal(out.Bytes(), &status); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &status, nil
+}
+
+// CreateRestoreOperation creates a new operation to restore the cluster from a backup
+func (c *Client) CreateRestoreOperation(ctx context.Context, req ops.CreateRestoreOperationRequest) (*ops.SiteOperationKey, error) {
+	out, err := c.PostJSON(c.Endpoint("accounts", req.ClusterKey.AccountID, "sites", req.ClusterKey.SiteDomain, "operations", "restore"), req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var key ops.SiteOperationKey
+	if err := json.Unmarshal(out.Bytes(), &key); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &key, nil
+}
+
+// CreateEtcdRestoreOperation creates a new operation to restore etcd from a snapshot
+func (c *Client) CreateEtcdRestoreOperation(ctx context.Context, req ops.CreateEtcdRestoreOperationRequest) (*ops.SiteOperationKey, error) {
+	out, err := c.PostJSON(c.Endpoint("accounts", req.ClusterKey.AccountID, "sites", req.ClusterKey.SiteDomain, "operations", "etcdrestore"), req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var key ops.SiteOperationKey
+	if err := json.Unmarshal(out.Bytes(), &key); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &key, nil
+}
+
+// CreateAppBackupOperation creates a new operation to back up an application
+func (c *Client) CreateAppBackupOperation(ctx context.Context, req ops.CreateAppBackupOperationRequest) (*ops.SiteOperationKey, error) {
+	out, err := c.PostJSON(c.Endpoint("accounts", req.ClusterKey.AccountID, "sites", req.ClusterKey.SiteDomain, "operations", "appbackup"), req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var key ops.SiteOperationKey
+	if err := json.Unmarshal(out.Bytes(), &key); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &key, nil
+}
+
+// CreateAppRestoreOperation creates a new operation to restore an application from its backup
+func (c *Client) CreateAppRestoreOperation(ctx context.Context, req ops.CreateAppBackupOperationRequest) (*ops.SiteOperationKey, error) {
+	out, err := c.PostJSON(c.Endpoint("accounts", req.ClusterKey.AccountID, "sites", req.ClusterKey.SiteDomain, "operations", "apprestore"), req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var key ops.SiteOperationKey
+	if err := json.Unmarshal(out.Bytes(), &key); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &key, nil
+}
+
+// GetEtcdMaintenanceStatus returns the status of the etcd compaction and defragmentation
+func (c *Client) GetEtcdMaintenanceStatus(key ops.SiteKey) (*storage.EtcdMaintenanceStatus, error) {
+	out, err := c.Get(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "etcd", "maintenance"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var status storage.EtcdMaintenanceStatus
+	if err := json.Unmarshal(out.Bytes(), &status); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &status, nil
+}
+
+// GetEtcdHealthStatus returns the status of the etcd health and capacity
+func (c *Client) GetEtcdHealthStatus(key ops.SiteKey) (*storage.EtcdHealthStatus, error) {
+	out, err := c.Get(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "etcd", "health"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var status storage.EtcdHealthStatus
+	if err := json.Unmarshal(out.Bytes(), &status); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &status, nil
+}
+
 // GetAlerts returns a list of monitoring alerts for the cluster
 func (c *Client) GetAlerts(key ops.SiteKey) ([]storage.Alert, error) {
 	response, err := c.Get(c.Endpoint(
@@ -1292,6 +1827,58 @@ func (c *Client) GetClusterCertificate(key ops.SiteKey, withSecrets bool) (*ops.
 	return &info, nil
 }
 
+// GetCertificateExpiry returns the expiration status of the cluster certificates
+func (c *Client) GetCertificateExpiry(key ops.SiteKey) (*storage.CertificateExpiryStatus, error) {
+	out, err := c.Get(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "certificates", "expiry"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var status storage.CertificateExpiryStatus
+	if err := json.Unmarshal(out.Bytes(), &status); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &status, nil
+}
+
+// CreateRotateSecretsKeyOperation creates a new operation to rotate the key that encrypts secrets at rest
+func (c *Client) CreateRotateSecretsKeyOperation(ctx context.Context, req ops.CreateRotateSecretsKeyOperationRequest) (*ops.SiteOperationKey, error) {
+	out, err := c.PostJSON(c.Endpoint("accounts", req.ClusterKey.AccountID, "sites", req.ClusterKey.SiteDomain, "operations", "rotatesecretskey"), req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var key ops.SiteOperationKey
+	if err := json.Unmarshal(out.Bytes(), &key); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &key, nil
+}
+
+// CreateChangeCIDROperation creates a new operation to change the pod and service subnets of the cluster
+func (c *Client) CreateChangeCIDROperation(ctx context.Context, req ops.CreateChangeCIDROperationRequest) (*ops.SiteOperationKey, error) {
+	out, err := c.PostJSON(c.Endpoint("accounts", req.ClusterKey.AccountID, "sites", req.ClusterKey.SiteDomain, "operations", "changecidr"), req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var key ops.SiteOperationKey
+	if err := json.Unmarshal(out.Bytes(), &key); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &key, nil
+}
+
+// CreateRotateCertificatesOperation creates a new operation to rotate the cluster certificates
+func (c *Client) CreateRotateCertificatesOperation(ctx context.Context, req ops.CreateRotateCertificatesOperationRequest) (*ops.SiteOperationKey, error) {
+	out, err := c.PostJSON(c.Endpoint("accounts", req.ClusterKey.AccountID, "sites", req.ClusterKey.SiteDomain, "operations", "rotatecerts"), req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var key ops.SiteOperationKey
+	if err := json.Unmarshal(out.Bytes(), &key); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &key, nil
+}
+
 // UpdateClusterCertificate updates the cluster certificate
 func (c *Client) UpdateClusterCertificate(ctx context.Context, req ops.UpdateCertificateRequest) (*ops.ClusterCertificate, error) {
 	out, err := c.PostJSON(c.Endpoint(
@@ -1332,6 +1919,12 @@ type UpsertResourceRawReq struct {
 	TTL time.Duration `json:"ttl"`
 }
 
+// ApproveRosterChangesReq is a request to approve pending cluster roster changes
+type ApproveRosterChangesReq struct {
+	// ChangesID identifies the set of changes to approve
+	ChangesID string `json:"changes_id"`
+}
+
 // UpsertUser creates or updates the user
 func (c *Client) UpsertUser(ctx context.Context, key ops.SiteKey, user teleservices.User) error {
 	data, err := teleservices.GetUserMarshaler().MarshalUser(user)
@@ -1389,6 +1982,95 @@ func (c *Client) DeleteUser(ctx context.Context, key ops.SiteKey, name string) e
 	return trace.Wrap(err)
 }
 
+// UpsertServiceAccount creates or updates a service account
+func (c *Client) UpsertServiceAccount(ctx context.Context, key ops.SiteKey, account storage.ServiceAccount) error {
+	bytes, err := storage.MarshalServiceAccount(account)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = c.PutJSON(
+		c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "serviceaccounts", account.GetName()),
+		&UpsertResourceRawReq{
+			Resource: bytes,
+		})
+	return trace.Wrap(err)
+}
+
+// GetServiceAccounts returns all service accounts
+func (c *Client) GetServiceAccounts(key ops.SiteKey) ([]storage.ServiceAccount, error) {
+	out, err := c.Get(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "serviceaccounts"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(out.Bytes(), &items); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	accounts := make([]storage.ServiceAccount, len(items))
+	for i, raw := range items {
+		account, err := storage.UnmarshalServiceAccount(raw)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		accounts[i] = account
+	}
+	return accounts, nil
+}
+
+// DeleteServiceAccount deletes the service account with the specified name
+func (c *Client) DeleteServiceAccount(ctx context.Context, key ops.SiteKey, name string) error {
+	if name == "" {
+		return trace.BadParameter("missing service account name")
+	}
+	_, err := c.Delete(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "serviceaccounts", name))
+	return trace.Wrap(err)
+}
+
+// CreateJoinToken creates a new short-lived cluster join token
+func (c *Client) CreateJoinToken(ctx context.Context, req ops.CreateJoinTokenRequest) (*storage.ProvisioningToken, error) {
+	out, err := c.PostJSON(c.Endpoint("accounts", req.AccountID, "sites", req.SiteDomain, "tokens", "join"), req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var token storage.ProvisioningToken
+	if err := json.Unmarshal(out.Bytes(), &token); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &token, nil
+}
+
+// GetJoinTokens returns the active short-lived join tokens of the cluster
+func (c *Client) GetJoinTokens(ctx context.Context, key ops.SiteKey) ([]storage.ProvisioningToken, error) {
+	out, err := c.Get(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "tokens", "join"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var tokens []storage.ProvisioningToken
+	if err := json.Unmarshal(out.Bytes(), &tokens); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return tokens, nil
+}
+
+// DeleteJoinToken deletes the specified short-lived join token
+func (c *Client) DeleteJoinToken(ctx context.Context, req ops.DeleteJoinTokenRequest) error {
+	_, err := c.Delete(c.Endpoint("accounts", req.AccountID, "sites", req.SiteDomain, "tokens", "join", req.Token))
+	return trace.Wrap(err)
+}
+
+// RequestScaleUp requests expansion of the cluster by the specified number of nodes
+func (c *Client) RequestScaleUp(ctx context.Context, req ops.ScaleUpRequest) (*storage.ProvisioningToken, error) {
+	out, err := c.PostJSON(c.Endpoint("accounts", req.AccountID, "sites", req.SiteDomain, "scaleup"), req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var token storage.ProvisioningToken
+	if err := json.Unmarshal(out.Bytes(), &token); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &token, nil
+}
+
 // CreateUserInvite creates a new invite token for a user.
 func (c *Client) CreateUserInvite(ctx context.Context, req ops.CreateUserInviteRequest) (*storage.UserToken, error) {
 	out, err := c.PostJSON(c.Endpoint("accounts", req.AccountID, "sites", req.SiteDomain, "tokens", "userinvites"), req)
@@ -1549,6 +2231,47 @@ func (c *Client) ListReleases(req ops.ListReleasesRequest) ([]storage.Release, e
 	return releases, nil
 }
 
+// GetTrackedReleases returns the application releases recorded for the cluster
+func (c *Client) GetTrackedReleases(key ops.SiteKey) ([]storage.Release, error) {
+	out, err := c.Get(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "trackedreleases"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(out.Bytes(), &items); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	releases := make([]storage.Release, len(items))
+	for i, raw := range items {
+		release, err := storage.UnmarshalRelease(raw)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		releases[i] = release
+	}
+	return releases, nil
+}
+
+// TrackRelease records the state of the application release
+func (c *Client) TrackRelease(ctx context.Context, key ops.SiteKey, release storage.Release) error {
+	bytes, err := storage.MarshalRelease(release)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = c.PutJSON(
+		c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "trackedreleases", release.GetName()),
+		&UpsertResourceRawReq{
+			Resource: bytes,
+		})
+	return trace.Wrap(err)
+}
+
+// UntrackRelease removes the record of the release with the specified name
+func (c *Client) UntrackRelease(ctx context.Context, key ops.SiteKey, name string) error {
+	_, err := c.Delete(c.Endpoint("accounts", key.AccountID, "sites", key.SiteDomain, "trackedreleases", name))
+	return trace.Wrap(err)
+}
+
 // EmitAuditEvent saves the provided event in the audit log.
 func (c *Client) EmitAuditEvent(ctx context.Context, req ops.AuditEventRequest) error {
 	_, err := c.PostJSON(c.Endpoint("accounts", req.AccountID, "sites", req.SiteDomain, "events"), req)
@@ -1558,6 +2281,34 @@ func (c *Client) EmitAuditEvent(ctx context.Context, req ops.AuditEventRequest)
 	return nil
 }
 
+// GetClusterEvents returns the cluster events recorded after the
+// event specified in the request.
+func (c *Client) GetClusterEvents(ctx context.Context, req ops.GetClusterEventsRequest) ([]storage.ClusterEvent, error) {
+	out, err := c.Get(c.Endpoint("accounts", req.AccountID, "sites", req.SiteDomain, "events"), url.Values{
+		"after": []string{req.After},
+		"limit": []string{strconv.Itoa(req.Limit)},
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var events []storage.ClusterEvent
+	if err := json.Unmarshal(out.Bytes(), &events); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return events, nil
+}
+
+// WatchClusterEvents returns the stream of the cluster events recorded
+// after the event specified in the request. The stream is a sequence
+// of JSON-encoded events
+func (c *Client) WatchClusterEvents(ctx context.Context, req ops.GetClusterEventsRequest) (io.ReadCloser, error) {
+	endpoint := c.Endpoint("accounts", req.AccountID, "sites", req.SiteDomain, "events", "stream")
+	if req.After != "" {
+		endpoint += "?" + url.Values{"after": []string{req.After}}.Encode()
+	}
+	return httplib.SetupWebsocketClient(ctx, &c.Client, endpoint, c.dialer)
+}
+
 // PostJSON issues HTTP POST request to the server with the provided JSON data
 func (c *Client) PostJSON(endpoint string, data interface{}) (*roundtrip.Response, error) {
 	return telehttplib.ConvertResponse(c.Client.PostJSON(context.TODO(), endpoint, data))
@@ -1569,6 +2320,29 @@ func (c *Client) PostJSONWithContext(ctx context.Context, endpoint string, data
 	return telehttplib.ConvertResponse(c.Client.PostJSON(ctx, endpoint, data))
 }
 
+// postOperation issues HTTP POST request that creates an operation.
+// The idempotency key from the context is sent with the request so the retried
+// request returns the operation created by the original request
+func (c *Client) postOperation(ctx context.Context, endpoint string, data interface{}) (*roundtrip.Response, error) {
+	return telehttplib.ConvertResponse(c.Client.RoundTrip(func() (*http.Response, error) {
+		body, err := json.Marshal(data)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		req = req.WithContext(ctx)
+		req.Header.Set("Content-Type", "application/json")
+		if key := ops.IdempotencyKeyFromContext(ctx); key != "" {
+			req.Header.Set(ops.IdempotencyKeyHeader, key)
+		}
+		c.Client.SetAuthHeader(req.Header)
+		return c.Client.HTTPClient().Do(req)
+	}))
+}
+
 // PutJSON issues HTTP PUT request to the server with the provided JSON data
 func (c *Client) PutJSON(endpoint string, data interface{}) (*roundtrip.Response, error) {
 	return telehttplib.ConvertResponse(c.Client.PutJSON(context.TODO(), endpoint, data))
@@ -0,0 +1,115 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsgrpc
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+
+	"github.com/gravitational/gravity/lib/httplib"
+	pb "github.com/gravitational/gravity/lib/ops/opsgrpc/proto"
+
+	"github.com/gravitational/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// ClientConfig defines the API client configuration
+type ClientConfig struct {
+	// Addr is the address of the API server, e.g. the address
+	// of the cluster gravity-site service
+	Addr string
+	// TLS is the client TLS configuration
+	TLS *tls.Config
+	// Token is the API key to authenticate with
+	Token string
+	// Username is the name of the user to authenticate as.
+	// Used with Password if Token is not set
+	Username string
+	// Password is the password of the user
+	Password string
+}
+
+// CheckAndSetDefaults validates this configuration and sets defaults
+func (c *ClientConfig) CheckAndSetDefaults() error {
+	if c.Addr == "" {
+		return trace.BadParameter("missing Addr")
+	}
+	if c.Token == "" && (c.Username == "" || c.Password == "") {
+		return trace.BadParameter("either Token or Username and Password are required")
+	}
+	if c.TLS == nil {
+		c.TLS = &tls.Config{}
+	}
+	return nil
+}
+
+// NewClient returns a new client to the API server specified with config
+func NewClient(ctx context.Context, config ClientConfig) (*Client, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	conn, err := grpc.DialContext(ctx, config.Addr,
+		grpc.WithTransportCredentials(credentials.NewTLS(config.TLS)),
+		grpc.WithPerRPCCredentials(newCredentials(config)),
+	)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &Client{
+		OperatorClient: pb.NewOperatorClient(conn),
+		conn:           conn,
+	}, nil
+}
+
+// Client is the API client.
+// Use ConvertError to convert the errors returned by the client to trace errors
+type Client struct {
+	pb.OperatorClient
+	conn *grpc.ClientConn
+}
+
+// Close closes the client connection
+func (r *Client) Close() error {
+	return r.conn.Close()
+}
+
+func newCredentials(config ClientConfig) credentials.PerRPCCredentials {
+	if config.Token != "" {
+		return authCredentials(httplib.AuthBearer + " " + config.Token)
+	}
+	payload := base64.StdEncoding.EncodeToString([]byte(config.Username + ":" + config.Password))
+	return authCredentials(httplib.AuthBasic + " " + payload)
+}
+
+// authCredentials passes the value of the authorization header
+// with every request
+type authCredentials string
+
+// GetRequestMetadata returns the request metadata with credentials.
+// Implements credentials.PerRPCCredentials
+func (r authCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{authorizationKey: string(r)}, nil
+}
+
+// RequireTransportSecurity returns true as the credentials
+// cannot be sent over insecure connections.
+// Implements credentials.PerRPCCredentials
+func (r authCredentials) RequireTransportSecurity() bool {
+	return true
+}
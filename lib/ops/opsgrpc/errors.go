@@ -0,0 +1,95 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsgrpc
+
+import (
+	"context"
+
+	"github.com/gravitational/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatus converts the specified error to a gRPC status error
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(trace.Unwrap(err)); ok {
+		return trace.Unwrap(err)
+	}
+	return status.Error(toCode(err), trace.UserMessage(err))
+}
+
+func toCode(err error) codes.Code {
+	origErr := trace.Unwrap(err)
+	switch {
+	case trace.IsNotFound(err):
+		return codes.NotFound
+	case trace.IsAlreadyExists(err):
+		return codes.AlreadyExists
+	case trace.IsAccessDenied(err):
+		return codes.PermissionDenied
+	case trace.IsBadParameter(err):
+		return codes.InvalidArgument
+	case trace.IsCompareFailed(err):
+		return codes.FailedPrecondition
+	case trace.IsNotImplemented(err):
+		return codes.Unimplemented
+	case trace.IsLimitExceeded(err):
+		return codes.ResourceExhausted
+	case trace.IsConnectionProblem(err):
+		return codes.Unavailable
+	case origErr == context.Canceled:
+		return codes.Canceled
+	case origErr == context.DeadlineExceeded:
+		return codes.DeadlineExceeded
+	}
+	return codes.Unknown
+}
+
+// ConvertError converts the specified error returned by the API
+// to the matching trace error
+func ConvertError(err error) error {
+	if err == nil {
+		return nil
+	}
+	s, ok := status.FromError(trace.Unwrap(err))
+	if !ok {
+		return trace.Wrap(err)
+	}
+	message := s.Message()
+	switch s.Code() {
+	case codes.NotFound:
+		return trace.NotFound(message)
+	case codes.AlreadyExists:
+		return trace.AlreadyExists(message)
+	case codes.PermissionDenied, codes.Unauthenticated:
+		return trace.AccessDenied(message)
+	case codes.InvalidArgument:
+		return trace.BadParameter(message)
+	case codes.FailedPrecondition:
+		return trace.CompareFailed(message)
+	case codes.Unimplemented:
+		return trace.NotImplemented(message)
+	case codes.ResourceExhausted:
+		return trace.LimitExceeded(message)
+	case codes.Unavailable:
+		return trace.ConnectionProblem(err, message)
+	}
+	return trace.Wrap(err)
+}
@@ -0,0 +1,2020 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: operator.proto
+
+package proto
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/gogo/protobuf/proto"
+	types "github.com/gogo/protobuf/types"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+// ClusterKey identifies a cluster
+type ClusterKey struct {
+	// AccountID is the ID of the account the cluster belongs to.
+	// Defaults to the account of the local cluster
+	AccountId string `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// ClusterName is the name of the cluster.
+	// Defaults to the local cluster
+	ClusterName          string   `protobuf:"bytes,2,opt,name=cluster_name,json=clusterName,proto3" json:"cluster_name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ClusterKey) Reset()         { *m = ClusterKey{} }
+func (m *ClusterKey) String() string { return proto.CompactTextString(m) }
+func (*ClusterKey) ProtoMessage()    {}
+func (*ClusterKey) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb8d3714996346ac, []int{0}
+}
+func (m *ClusterKey) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ClusterKey.Unmarshal(m, b)
+}
+func (m *ClusterKey) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ClusterKey.Marshal(b, m, deterministic)
+}
+func (m *ClusterKey) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ClusterKey.Merge(m, src)
+}
+func (m *ClusterKey) XXX_Size() int {
+	return xxx_messageInfo_ClusterKey.Size(m)
+}
+func (m *ClusterKey) XXX_DiscardUnknown() {
+	xxx_messageInfo_ClusterKey.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ClusterKey proto.InternalMessageInfo
+
+func (m *ClusterKey) GetAccountId() string {
+	if m != nil {
+		return m.AccountId
+	}
+	return ""
+}
+
+func (m *ClusterKey) GetClusterName() string {
+	if m != nil {
+		return m.ClusterName
+	}
+	return ""
+}
+
+// OperationKey identifies a cluster operation
+type OperationKey struct {
+	// AccountID is the ID of the account the cluster belongs to.
+	// Defaults to the account of the local cluster
+	AccountId string `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// ClusterName is the name of the cluster.
+	// Defaults to the local cluster
+	ClusterName string `protobuf:"bytes,2,opt,name=cluster_name,json=clusterName,proto3" json:"cluster_name,omitempty"`
+	// ID is the operation ID
+	Id                   string   `protobuf:"bytes,3,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *OperationKey) Reset()         { *m = OperationKey{} }
+func (m *OperationKey) String() string { return proto.CompactTextString(m) }
+func (*OperationKey) ProtoMessage()    {}
+func (*OperationKey) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb8d3714996346ac, []int{1}
+}
+func (m *OperationKey) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OperationKey.Unmarshal(m, b)
+}
+func (m *OperationKey) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OperationKey.Marshal(b, m, deterministic)
+}
+func (m *OperationKey) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OperationKey.Merge(m, src)
+}
+func (m *OperationKey) XXX_Size() int {
+	return xxx_messageInfo_OperationKey.Size(m)
+}
+func (m *OperationKey) XXX_DiscardUnknown() {
+	xxx_messageInfo_OperationKey.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OperationKey proto.InternalMessageInfo
+
+func (m *OperationKey) GetAccountId() string {
+	if m != nil {
+		return m.AccountId
+	}
+	return ""
+}
+
+func (m *OperationKey) GetClusterName() string {
+	if m != nil {
+		return m.ClusterName
+	}
+	return ""
+}
+
+func (m *OperationKey) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+// Cluster describes a cluster
+type Cluster struct {
+	// AccountID is the ID of the account the cluster belongs to
+	AccountId string `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	// Name is the cluster name
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// State is the cluster state, e.g. active or degraded
+	State string `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	// Reason is the reason for the cluster state
+	Reason string `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	// App is the locator of the cluster application package
+	App string `protobuf:"bytes,5,opt,name=app,proto3" json:"app,omitempty"`
+	// Provider is the cloud provider of the cluster
+	Provider string `protobuf:"bytes,6,opt,name=provider,proto3" json:"provider,omitempty"`
+	// Created is the cluster creation time
+	Created *types.Timestamp `protobuf:"bytes,7,opt,name=created,proto3" json:"created,omitempty"`
+	// CreatedBy is the user who created the cluster
+	CreatedBy string `protobuf:"bytes,8,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	// Local is whether this is the cluster the API is served by
+	Local bool `protobuf:"varint,9,opt,name=local,proto3" json:"local,omitempty"`
+	// Labels is the cluster labels
+	Labels map[string]string `protobuf:"bytes,10,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Servers lists the cluster nodes
+	Servers              []*Server `protobuf:"bytes,11,rep,name=servers,proto3" json:"servers,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *Cluster) Reset()         { *m = Cluster{} }
+func (m *Cluster) String() string { return proto.CompactTextString(m) }
+func (*Cluster) ProtoMessage()    {}
+func (*Cluster) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb8d3714996346ac, []int{2}
+}
+func (m *Cluster) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Cluster.Unmarshal(m, b)
+}
+func (m *Cluster) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Cluster.Marshal(b, m, deterministic)
+}
+func (m *Cluster) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Cluster.Merge(m, src)
+}
+func (m *Cluster) XXX_Size() int {
+	return xxx_messageInfo_Cluster.Size(m)
+}
+func (m *Cluster) XXX_DiscardUnknown() {
+	xxx_messageInfo_Cluster.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Cluster proto.InternalMessageInfo
+
+func (m *Cluster) GetAccountId() string {
+	if m != nil {
+		return m.AccountId
+	}
+	return ""
+}
+
+func (m *Cluster) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Cluster) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *Cluster) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *Cluster) GetApp() string {
+	if m != nil {
+		return m.App
+	}
+	return ""
+}
+
+func (m *Cluster) GetProvider() string {
+	if m != nil {
+		return m.Provider
+	}
+	return ""
+}
+
+func (m *Cluster) GetCreated() *types.Timestamp {
+	if m != nil {
+		return m.Created
+	}
+	return nil
+}
+
+func (m *Cluster) GetCreatedBy() string {
+	if m != nil {
+		return m.CreatedBy
+	}
+	return ""
+}
+
+func (m *Cluster) GetLocal() bool {
+	if m != nil {
+		return m.Local
+	}
+	return false
+}
+
+func (m *Cluster) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *Cluster) GetServers() []*Server {
+	if m != nil {
+		return m.Servers
+	}
+	return nil
+}
+
+// ClusterStatus describes the result of the cluster status checks
+type ClusterStatus struct {
+	// State is the cluster state after the checks
+	State string `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	// Reason is the reason for the cluster state
+	Reason string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	// Healthy is whether the status checks have passed
+	Healthy bool `protobuf:"varint,3,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	// Message is the error message of the failed status checks
+	Message              string   `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ClusterStatus) Reset()         { *m = ClusterStatus{} }
+func (m *ClusterStatus) String() string { return proto.CompactTextString(m) }
+func (*ClusterStatus) ProtoMessage()    {}
+func (*ClusterStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb8d3714996346ac, []int{3}
+}
+func (m *ClusterStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ClusterStatus.Unmarshal(m, b)
+}
+func (m *ClusterStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ClusterStatus.Marshal(b, m, deterministic)
+}
+func (m *ClusterStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ClusterStatus.Merge(m, src)
+}
+func (m *ClusterStatus) XXX_Size() int {
+	return xxx_messageInfo_ClusterStatus.Size(m)
+}
+func (m *ClusterStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_ClusterStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ClusterStatus proto.InternalMessageInfo
+
+func (m *ClusterStatus) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *ClusterStatus) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *ClusterStatus) GetHealthy() bool {
+	if m != nil {
+		return m.Healthy
+	}
+	return false
+}
+
+func (m *ClusterStatus) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+// Server describes a cluster node
+type Server struct {
+	// Hostname is the node hostname
+	Hostname string `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	// AdvertiseIP is the IP address the node is reachable at
+	AdvertiseIp string `protobuf:"bytes,2,opt,name=advertise_ip,json=advertiseIp,proto3" json:"advertise_ip,omitempty"`
+	// Role is the application role of the node
+	Role string `protobuf:"bytes,3,opt,name=role,proto3" json:"role,omitempty"`
+	// ClusterRole is the Kubernetes role of the node, master or node
+	ClusterRole string `protobuf:"bytes,4,opt,name=cluster_role,json=clusterRole,proto3" json:"cluster_role,omitempty"`
+	// InstanceType is the node instance type
+	InstanceType         string   `protobuf:"bytes,5,opt,name=instance_type,json=instanceType,proto3" json:"instance_type,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Server) Reset()         { *m = Server{} }
+func (m *Server) String() string { return proto.CompactTextString(m) }
+func (*Server) ProtoMessage()    {}
+func (*Server) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb8d3714996346ac, []int{4}
+}
+func (m *Server) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Server.Unmarshal(m, b)
+}
+func (m *Server) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Server.Marshal(b, m, deterministic)
+}
+func (m *Server) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Server.Merge(m, src)
+}
+func (m *Server) XXX_Size() int {
+	return xxx_messageInfo_Server.Size(m)
+}
+func (m *Server) XXX_DiscardUnknown() {
+	xxx_messageInfo_Server.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Server proto.InternalMessageInfo
+
+func (m *Server) GetHostname() string {
+	if m != nil {
+		return m.Hostname
+	}
+	return ""
+}
+
+func (m *Server) GetAdvertiseIp() string {
+	if m != nil {
+		return m.AdvertiseIp
+	}
+	return ""
+}
+
+func (m *Server) GetRole() string {
+	if m != nil {
+		return m.Role
+	}
+	return ""
+}
+
+func (m *Server) GetClusterRole() string {
+	if m != nil {
+		return m.ClusterRole
+	}
+	return ""
+}
+
+func (m *Server) GetInstanceType() string {
+	if m != nil {
+		return m.InstanceType
+	}
+	return ""
+}
+
+// GetOperationsRequest describes a request to list cluster operations
+type GetOperationsRequest struct {
+	// Key identifies the cluster
+	Key *ClusterKey `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Type optionally limits the operations to the specified type
+	Type string `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	// State optionally limits the operations to the specified state
+	State string `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	// Active limits the operations to the operations that have not finished
+	Active               bool     `protobuf:"varint,4,opt,name=active,proto3" json:"active,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetOperationsRequest) Reset()         { *m = GetOperationsRequest{} }
+func (m *GetOperationsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetOperationsRequest) ProtoMessage()    {}
+func (*GetOperationsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb8d3714996346ac, []int{5}
+}
+func (m *GetOperationsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetOperationsRequest.Unmarshal(m, b)
+}
+func (m *GetOperationsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetOperationsRequest.Marshal(b, m, deterministic)
+}
+func (m *GetOperationsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetOperationsRequest.Merge(m, src)
+}
+func (m *GetOperationsRequest) XXX_Size() int {
+	return xxx_messageInfo_GetOperationsRequest.Size(m)
+}
+func (m *GetOperationsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetOperationsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetOperationsRequest proto.InternalMessageInfo
+
+func (m *GetOperationsRequest) GetKey() *ClusterKey {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *GetOperationsRequest) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *GetOperationsRequest) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *GetOperationsRequest) GetActive() bool {
+	if m != nil {
+		return m.Active
+	}
+	return false
+}
+
+// Operations is a list of cluster operations
+type Operations struct {
+	// Items lists the operations
+	Items                []*Operation `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *Operations) Reset()         { *m = Operations{} }
+func (m *Operations) String() string { return proto.CompactTextString(m) }
+func (*Operations) ProtoMessage()    {}
+func (*Operations) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb8d3714996346ac, []int{6}
+}
+func (m *Operations) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Operations.Unmarshal(m, b)
+}
+func (m *Operations) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Operations.Marshal(b, m, deterministic)
+}
+func (m *Operations) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Operations.Merge(m, src)
+}
+func (m *Operations) XXX_Size() int {
+	return xxx_messageInfo_Operations.Size(m)
+}
+func (m *Operations) XXX_DiscardUnknown() {
+	xxx_messageInfo_Operations.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Operations proto.InternalMessageInfo
+
+func (m *Operations) GetItems() []*Operation {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
+
+// Operation describes a cluster operation
+type Operation struct {
+	// Key identifies the operation
+	Key *OperationKey `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Type is the operation type, e.g. operation_update
+	Type string `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	// State is the operation state
+	State string `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	// Created is the operation creation time
+	Created *types.Timestamp `protobuf:"bytes,4,opt,name=created,proto3" json:"created,omitempty"`
+	// CreatedBy is the user who created the operation
+	CreatedBy string `protobuf:"bytes,5,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	// Updated is the time of the last operation update
+	Updated *types.Timestamp `protobuf:"bytes,6,opt,name=updated,proto3" json:"updated,omitempty"`
+	// Servers lists the nodes the operation runs on
+	Servers []*Server `protobuf:"bytes,7,rep,name=servers,proto3" json:"servers,omitempty"`
+	// Raw is the complete operation record in JSON format as returned
+	// by the HTTP API, including the state specific to the operation type
+	Raw                  []byte   `protobuf:"bytes,8,opt,name=raw,proto3" json:"raw,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Operation) Reset()         { *m = Operation{} }
+func (m *Operation) String() string { return proto.CompactTextString(m) }
+func (*Operation) ProtoMessage()    {}
+func (*Operation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb8d3714996346ac, []int{7}
+}
+func (m *Operation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Operation.Unmarshal(m, b)
+}
+func (m *Operation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Operation.Marshal(b, m, deterministic)
+}
+func (m *Operation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Operation.Merge(m, src)
+}
+func (m *Operation) XXX_Size() int {
+	return xxx_messageInfo_Operation.Size(m)
+}
+func (m *Operation) XXX_DiscardUnknown() {
+	xxx_messageInfo_Operation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Operation proto.InternalMessageInfo
+
+func (m *Operation) GetKey() *OperationKey {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *Operation) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *Operation) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *Operation) GetCreated() *types.Timestamp {
+	if m != nil {
+		return m.Created
+	}
+	return nil
+}
+
+func (m *Operation) GetCreatedBy() string {
+	if m != nil {
+		return m.CreatedBy
+	}
+	return ""
+}
+
+func (m *Operation) GetUpdated() *types.Timestamp {
+	if m != nil {
+		return m.Updated
+	}
+	return nil
+}
+
+func (m *Operation) GetServers() []*Server {
+	if m != nil {
+		return m.Servers
+	}
+	return nil
+}
+
+func (m *Operation) GetRaw() []byte {
+	if m != nil {
+		return m.Raw
+	}
+	return nil
+}
+
+// Progress describes an operation progress entry
+type Progress struct {
+	// Key identifies the operation
+	Key *OperationKey `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Created is the time the entry was recorded
+	Created *types.Timestamp `protobuf:"bytes,2,opt,name=created,proto3" json:"created,omitempty"`
+	// Completion is the operation completion percentage
+	Completion int32 `protobuf:"varint,3,opt,name=completion,proto3" json:"completion,omitempty"`
+	// Step is the current operation step
+	Step int32 `protobuf:"varint,4,opt,name=step,proto3" json:"step,omitempty"`
+	// State is the operation state
+	State string `protobuf:"bytes,5,opt,name=state,proto3" json:"state,omitempty"`
+	// Message is the progress message
+	Message              string   `protobuf:"bytes,6,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Progress) Reset()         { *m = Progress{} }
+func (m *Progress) String() string { return proto.CompactTextString(m) }
+func (*Progress) ProtoMessage()    {}
+func (*Progress) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb8d3714996346ac, []int{8}
+}
+func (m *Progress) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Progress.Unmarshal(m, b)
+}
+func (m *Progress) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Progress.Marshal(b, m, deterministic)
+}
+func (m *Progress) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Progress.Merge(m, src)
+}
+func (m *Progress) XXX_Size() int {
+	return xxx_messageInfo_Progress.Size(m)
+}
+func (m *Progress) XXX_DiscardUnknown() {
+	xxx_messageInfo_Progress.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Progress proto.InternalMessageInfo
+
+func (m *Progress) GetKey() *OperationKey {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *Progress) GetCreated() *types.Timestamp {
+	if m != nil {
+		return m.Created
+	}
+	return nil
+}
+
+func (m *Progress) GetCompletion() int32 {
+	if m != nil {
+		return m.Completion
+	}
+	return 0
+}
+
+func (m *Progress) GetStep() int32 {
+	if m != nil {
+		return m.Step
+	}
+	return 0
+}
+
+func (m *Progress) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *Progress) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+// Plan describes an operation plan
+type Plan struct {
+	// Key identifies the operation
+	Key *OperationKey `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// OperationType is the type of the operation
+	OperationType string `protobuf:"bytes,2,opt,name=operation_type,json=operationType,proto3" json:"operation_type,omitempty"`
+	// Created is the plan creation time
+	Created *types.Timestamp `protobuf:"bytes,3,opt,name=created,proto3" json:"created,omitempty"`
+	// Phases lists the top-level plan phases
+	Phases               []*Phase `protobuf:"bytes,4,rep,name=phases,proto3" json:"phases,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Plan) Reset()         { *m = Plan{} }
+func (m *Plan) String() string { return proto.CompactTextString(m) }
+func (*Plan) ProtoMessage()    {}
+func (*Plan) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb8d3714996346ac, []int{9}
+}
+func (m *Plan) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Plan.Unmarshal(m, b)
+}
+func (m *Plan) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Plan.Marshal(b, m, deterministic)
+}
+func (m *Plan) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Plan.Merge(m, src)
+}
+func (m *Plan) XXX_Size() int {
+	return xxx_messageInfo_Plan.Size(m)
+}
+func (m *Plan) XXX_DiscardUnknown() {
+	xxx_messageInfo_Plan.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Plan proto.InternalMessageInfo
+
+func (m *Plan) GetKey() *OperationKey {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *Plan) GetOperationType() string {
+	if m != nil {
+		return m.OperationType
+	}
+	return ""
+}
+
+func (m *Plan) GetCreated() *types.Timestamp {
+	if m != nil {
+		return m.Created
+	}
+	return nil
+}
+
+func (m *Plan) GetPhases() []*Phase {
+	if m != nil {
+		return m.Phases
+	}
+	return nil
+}
+
+// Phase describes an operation plan phase
+type Phase struct {
+	// ID is the phase ID, e.g. /masters/node-1
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Description is the phase description
+	Description string `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	// State is the phase state
+	State string `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	// Step is the phase step
+	Step int32 `protobuf:"varint,4,opt,name=step,proto3" json:"step,omitempty"`
+	// Phases lists the subphases
+	Phases []*Phase `protobuf:"bytes,5,rep,name=phases,proto3" json:"phases,omitempty"`
+	// Requires lists the phases that have to complete before this phase
+	Requires []string `protobuf:"bytes,6,rep,name=requires,proto3" json:"requires,omitempty"`
+	// Parallel is whether the subphases are executed in parallel
+	Parallel bool `protobuf:"varint,7,opt,name=parallel,proto3" json:"parallel,omitempty"`
+	// Updated is the time of the last phase state change
+	Updated *types.Timestamp `protobuf:"bytes,8,opt,name=updated,proto3" json:"updated,omitempty"`
+	// Error is the error message of the failed phase
+	Error                string   `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Phase) Reset()         { *m = Phase{} }
+func (m *Phase) String() string { return proto.CompactTextString(m) }
+func (*Phase) ProtoMessage()    {}
+func (*Phase) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb8d3714996346ac, []int{10}
+}
+func (m *Phase) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Phase.Unmarshal(m, b)
+}
+func (m *Phase) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Phase.Marshal(b, m, deterministic)
+}
+func (m *Phase) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Phase.Merge(m, src)
+}
+func (m *Phase) XXX_Size() int {
+	return xxx_messageInfo_Phase.Size(m)
+}
+func (m *Phase) XXX_DiscardUnknown() {
+	xxx_messageInfo_Phase.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Phase proto.InternalMessageInfo
+
+func (m *Phase) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Phase) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+func (m *Phase) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *Phase) GetStep() int32 {
+	if m != nil {
+		return m.Step
+	}
+	return 0
+}
+
+func (m *Phase) GetPhases() []*Phase {
+	if m != nil {
+		return m.Phases
+	}
+	return nil
+}
+
+func (m *Phase) GetRequires() []string {
+	if m != nil {
+		return m.Requires
+	}
+	return nil
+}
+
+func (m *Phase) GetParallel() bool {
+	if m != nil {
+		return m.Parallel
+	}
+	return false
+}
+
+func (m *Phase) GetUpdated() *types.Timestamp {
+	if m != nil {
+		return m.Updated
+	}
+	return nil
+}
+
+func (m *Phase) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+// GetResourcesRequest describes a request to list cluster resources
+type GetResourcesRequest struct {
+	// Key identifies the cluster
+	Key *ClusterKey `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Kind is the resource kind, e.g. user or alerttarget
+	Kind string `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	// Name optionally limits the resources to the resource with the specified name
+	Name string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	// WithSecrets is whether to include the secret resource fields
+	WithSecrets          bool     `protobuf:"varint,4,opt,name=with_secrets,json=withSecrets,proto3" json:"with_secrets,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetResourcesRequest) Reset()         { *m = GetResourcesRequest{} }
+func (m *GetResourcesRequest) String() string { return proto.CompactTextString(m) }
+func (*GetResourcesRequest) ProtoMessage()    {}
+func (*GetResourcesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb8d3714996346ac, []int{11}
+}
+func (m *GetResourcesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetResourcesRequest.Unmarshal(m, b)
+}
+func (m *GetResourcesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetResourcesRequest.Marshal(b, m, deterministic)
+}
+func (m *GetResourcesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetResourcesRequest.Merge(m, src)
+}
+func (m *GetResourcesRequest) XXX_Size() int {
+	return xxx_messageInfo_GetResourcesRequest.Size(m)
+}
+func (m *GetResourcesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetResourcesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetResourcesRequest proto.InternalMessageInfo
+
+func (m *GetResourcesRequest) GetKey() *ClusterKey {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *GetResourcesRequest) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *GetResourcesRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *GetResourcesRequest) GetWithSecrets() bool {
+	if m != nil {
+		return m.WithSecrets
+	}
+	return false
+}
+
+// Resources is a list of cluster resources
+type Resources struct {
+	// Items lists the resources
+	Items                []*Resource `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *Resources) Reset()         { *m = Resources{} }
+func (m *Resources) String() string { return proto.CompactTextString(m) }
+func (*Resources) ProtoMessage()    {}
+func (*Resources) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb8d3714996346ac, []int{12}
+}
+func (m *Resources) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Resources.Unmarshal(m, b)
+}
+func (m *Resources) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Resources.Marshal(b, m, deterministic)
+}
+func (m *Resources) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Resources.Merge(m, src)
+}
+func (m *Resources) XXX_Size() int {
+	return xxx_messageInfo_Resources.Size(m)
+}
+func (m *Resources) XXX_DiscardUnknown() {
+	xxx_messageInfo_Resources.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Resources proto.InternalMessageInfo
+
+func (m *Resources) GetItems() []*Resource {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
+
+// Resource describes a cluster resource
+type Resource struct {
+	// Kind is the resource kind
+	Kind string `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	// Name is the resource name
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Data is the resource specification in JSON format
+	Data                 []byte   `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Resource) Reset()         { *m = Resource{} }
+func (m *Resource) String() string { return proto.CompactTextString(m) }
+func (*Resource) ProtoMessage()    {}
+func (*Resource) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb8d3714996346ac, []int{13}
+}
+func (m *Resource) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Resource.Unmarshal(m, b)
+}
+func (m *Resource) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Resource.Marshal(b, m, deterministic)
+}
+func (m *Resource) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Resource.Merge(m, src)
+}
+func (m *Resource) XXX_Size() int {
+	return xxx_messageInfo_Resource.Size(m)
+}
+func (m *Resource) XXX_DiscardUnknown() {
+	xxx_messageInfo_Resource.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Resource proto.InternalMessageInfo
+
+func (m *Resource) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *Resource) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Resource) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+// UpsertResourceRequest describes a request to create or update cluster resources
+type UpsertResourceRequest struct {
+	// Key identifies the cluster
+	Key *ClusterKey `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Data is the resource specification in YAML or JSON format,
+	// the same as accepted by gravity resource create.
+	// Multiple YAML documents are created in order
+	Data                 []byte   `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UpsertResourceRequest) Reset()         { *m = UpsertResourceRequest{} }
+func (m *UpsertResourceRequest) String() string { return proto.CompactTextString(m) }
+func (*UpsertResourceRequest) ProtoMessage()    {}
+func (*UpsertResourceRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb8d3714996346ac, []int{14}
+}
+func (m *UpsertResourceRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpsertResourceRequest.Unmarshal(m, b)
+}
+func (m *UpsertResourceRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpsertResourceRequest.Marshal(b, m, deterministic)
+}
+func (m *UpsertResourceRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpsertResourceRequest.Merge(m, src)
+}
+func (m *UpsertResourceRequest) XXX_Size() int {
+	return xxx_messageInfo_UpsertResourceRequest.Size(m)
+}
+func (m *UpsertResourceRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpsertResourceRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpsertResourceRequest proto.InternalMessageInfo
+
+func (m *UpsertResourceRequest) GetKey() *ClusterKey {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *UpsertResourceRequest) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+// RemoveResourceRequest describes a request to remove a cluster resource
+type RemoveResourceRequest struct {
+	// Key identifies the cluster
+	Key *ClusterKey `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Kind is the resource kind
+	Kind string `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	// Name is the resource name
+	Name string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	// Force is whether to ignore the resource not found errors
+	Force                bool     `protobuf:"varint,4,opt,name=force,proto3" json:"force,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RemoveResourceRequest) Reset()         { *m = RemoveResourceRequest{} }
+func (m *RemoveResourceRequest) String() string { return proto.CompactTextString(m) }
+func (*RemoveResourceRequest) ProtoMessage()    {}
+func (*RemoveResourceRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb8d3714996346ac, []int{15}
+}
+func (m *RemoveResourceRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RemoveResourceRequest.Unmarshal(m, b)
+}
+func (m *RemoveResourceRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RemoveResourceRequest.Marshal(b, m, deterministic)
+}
+func (m *RemoveResourceRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RemoveResourceRequest.Merge(m, src)
+}
+func (m *RemoveResourceRequest) XXX_Size() int {
+	return xxx_messageInfo_RemoveResourceRequest.Size(m)
+}
+func (m *RemoveResourceRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RemoveResourceRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RemoveResourceRequest proto.InternalMessageInfo
+
+func (m *RemoveResourceRequest) GetKey() *ClusterKey {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *RemoveResourceRequest) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *RemoveResourceRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *RemoveResourceRequest) GetForce() bool {
+	if m != nil {
+		return m.Force
+	}
+	return false
+}
+
+// GetEventsRequest describes a request to retrieve the cluster events
+type GetEventsRequest struct {
+	// Key identifies the cluster
+	Key *ClusterKey `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// After is the ID of the last received event, the cursor to resume
+	// from. If empty, the events are returned from the beginning of
+	// the events table
+	After string `protobuf:"bytes,2,opt,name=after,proto3" json:"after,omitempty"`
+	// Limit is the maximum number of events to return
+	Limit                int32    `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetEventsRequest) Reset()         { *m = GetEventsRequest{} }
+func (m *GetEventsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetEventsRequest) ProtoMessage()    {}
+func (*GetEventsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb8d3714996346ac, []int{16}
+}
+func (m *GetEventsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetEventsRequest.Unmarshal(m, b)
+}
+func (m *GetEventsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetEventsRequest.Marshal(b, m, deterministic)
+}
+func (m *GetEventsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetEventsRequest.Merge(m, src)
+}
+func (m *GetEventsRequest) XXX_Size() int {
+	return xxx_messageInfo_GetEventsRequest.Size(m)
+}
+func (m *GetEventsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetEventsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetEventsRequest proto.InternalMessageInfo
+
+func (m *GetEventsRequest) GetKey() *ClusterKey {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *GetEventsRequest) GetAfter() string {
+	if m != nil {
+		return m.After
+	}
+	return ""
+}
+
+func (m *GetEventsRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+// Events is a list of cluster events
+type Events struct {
+	// Items lists the events
+	Items                []*Event `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Events) Reset()         { *m = Events{} }
+func (m *Events) String() string { return proto.CompactTextString(m) }
+func (*Events) ProtoMessage()    {}
+func (*Events) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb8d3714996346ac, []int{17}
+}
+func (m *Events) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Events.Unmarshal(m, b)
+}
+func (m *Events) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Events.Marshal(b, m, deterministic)
+}
+func (m *Events) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Events.Merge(m, src)
+}
+func (m *Events) XXX_Size() int {
+	return xxx_messageInfo_Events.Size(m)
+}
+func (m *Events) XXX_DiscardUnknown() {
+	xxx_messageInfo_Events.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Events proto.InternalMessageInfo
+
+func (m *Events) GetItems() []*Event {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
+
+// Event describes a cluster event: operation lifecycle, cluster and node
+// health change or resource modification
+type Event struct {
+	// ID identifies the event, the events are ordered by ID
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Time is the time of the event
+	Time *types.Timestamp `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"`
+	// Name is the event name, e.g. operation.started
+	Name string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	// Code is the event code, e.g. G0005I
+	Code string `protobuf:"bytes,4,opt,name=code,proto3" json:"code,omitempty"`
+	// Fields is the event details in JSON format
+	Fields               []byte   `protobuf:"bytes,5,opt,name=fields,proto3" json:"fields,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Event) Reset()         { *m = Event{} }
+func (m *Event) String() string { return proto.CompactTextString(m) }
+func (*Event) ProtoMessage()    {}
+func (*Event) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cb8d3714996346ac, []int{18}
+}
+func (m *Event) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Event.Unmarshal(m, b)
+}
+func (m *Event) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Event.Marshal(b, m, deterministic)
+}
+func (m *Event) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Event.Merge(m, src)
+}
+func (m *Event) XXX_Size() int {
+	return xxx_messageInfo_Event.Size(m)
+}
+func (m *Event) XXX_DiscardUnknown() {
+	xxx_messageInfo_Event.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Event proto.InternalMessageInfo
+
+func (m *Event) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Event) GetTime() *types.Timestamp {
+	if m != nil {
+		return m.Time
+	}
+	return nil
+}
+
+func (m *Event) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Event) GetCode() string {
+	if m != nil {
+		return m.Code
+	}
+	return ""
+}
+
+func (m *Event) GetFields() []byte {
+	if m != nil {
+		return m.Fields
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*ClusterKey)(nil), "gravity.ops.v1.ClusterKey")
+	proto.RegisterType((*OperationKey)(nil), "gravity.ops.v1.OperationKey")
+	proto.RegisterType((*Cluster)(nil), "gravity.ops.v1.Cluster")
+	proto.RegisterMapType((map[string]string)(nil), "gravity.ops.v1.Cluster.LabelsEntry")
+	proto.RegisterType((*ClusterStatus)(nil), "gravity.ops.v1.ClusterStatus")
+	proto.RegisterType((*Server)(nil), "gravity.ops.v1.Server")
+	proto.RegisterType((*GetOperationsRequest)(nil), "gravity.ops.v1.GetOperationsRequest")
+	proto.RegisterType((*Operations)(nil), "gravity.ops.v1.Operations")
+	proto.RegisterType((*Operation)(nil), "gravity.ops.v1.Operation")
+	proto.RegisterType((*Progress)(nil), "gravity.ops.v1.Progress")
+	proto.RegisterType((*Plan)(nil), "gravity.ops.v1.Plan")
+	proto.RegisterType((*Phase)(nil), "gravity.ops.v1.Phase")
+	proto.RegisterType((*GetResourcesRequest)(nil), "gravity.ops.v1.GetResourcesRequest")
+	proto.RegisterType((*Resources)(nil), "gravity.ops.v1.Resources")
+	proto.RegisterType((*Resource)(nil), "gravity.ops.v1.Resource")
+	proto.RegisterType((*UpsertResourceRequest)(nil), "gravity.ops.v1.UpsertResourceRequest")
+	proto.RegisterType((*RemoveResourceRequest)(nil), "gravity.ops.v1.RemoveResourceRequest")
+	proto.RegisterType((*GetEventsRequest)(nil), "gravity.ops.v1.GetEventsRequest")
+	proto.RegisterType((*Events)(nil), "gravity.ops.v1.Events")
+	proto.RegisterType((*Event)(nil), "gravity.ops.v1.Event")
+}
+
+func init() { proto.RegisterFile("operator.proto", fileDescriptor_cb8d3714996346ac) }
+
+var fileDescriptor_cb8d3714996346ac = []byte{
+	// 1222 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x57, 0x5f, 0x8f, 0xdb, 0x44,
+	0x10, 0x97, 0x93, 0x38, 0x7f, 0x26, 0xb9, 0x53, 0xb5, 0xa4, 0x87, 0x09, 0x14, 0x52, 0x97, 0x4a,
+	0x27, 0x01, 0xee, 0x71, 0x14, 0x09, 0xa8, 0x78, 0xa0, 0xe8, 0x7a, 0x54, 0x85, 0xbb, 0xc3, 0x57,
+	0x84, 0xe0, 0x25, 0xec, 0xd9, 0x73, 0x17, 0xab, 0x4e, 0xec, 0xdb, 0xdd, 0xa4, 0xf2, 0x33, 0x12,
+	0xbc, 0xf1, 0xc8, 0x17, 0xe0, 0x81, 0x27, 0xde, 0xf8, 0x04, 0x7c, 0x06, 0x3e, 0x10, 0xda, 0xf5,
+	0xda, 0xb1, 0x13, 0xe7, 0x9a, 0x1e, 0x7d, 0xca, 0xce, 0xec, 0xec, 0x78, 0xf6, 0x37, 0x33, 0xbf,
+	0xd9, 0xc0, 0x76, 0x14, 0x23, 0xa3, 0x22, 0x62, 0x4e, 0xcc, 0x22, 0x11, 0x91, 0xed, 0x0b, 0x46,
+	0xe7, 0x81, 0x48, 0x9c, 0x28, 0xe6, 0xce, 0xfc, 0xc3, 0xc1, 0x9b, 0x17, 0x51, 0x74, 0x11, 0xe2,
+	0x3d, 0xb5, 0x7b, 0x36, 0x3b, 0xbf, 0x87, 0x93, 0x58, 0x24, 0xa9, 0xf1, 0xe0, 0x9d, 0xe5, 0x4d,
+	0x11, 0x4c, 0x90, 0x0b, 0x3a, 0x89, 0x53, 0x03, 0xfb, 0x08, 0xe0, 0xcb, 0x70, 0xc6, 0x05, 0xb2,
+	0x27, 0x98, 0x90, 0x5b, 0x00, 0xd4, 0xf3, 0xa2, 0xd9, 0x54, 0x8c, 0x02, 0xdf, 0x32, 0x86, 0xc6,
+	0x6e, 0xc7, 0xed, 0x68, 0xcd, 0x63, 0x9f, 0xdc, 0x86, 0x9e, 0x97, 0x1a, 0x8f, 0xa6, 0x74, 0x82,
+	0x56, 0x4d, 0x19, 0x74, 0xb5, 0xee, 0x88, 0x4e, 0xd0, 0xfe, 0x09, 0x7a, 0xc7, 0x2a, 0xde, 0x20,
+	0x9a, 0xbe, 0x12, 0x8f, 0x64, 0x1b, 0x6a, 0x81, 0x6f, 0xd5, 0xd5, 0x46, 0x2d, 0xf0, 0xed, 0xbf,
+	0xea, 0xd0, 0xd2, 0x21, 0xbf, 0xc8, 0x3b, 0x81, 0x46, 0xc1, 0xab, 0x5a, 0x93, 0x3e, 0x98, 0x5c,
+	0x50, 0x81, 0xda, 0x63, 0x2a, 0x90, 0x1d, 0x68, 0x32, 0xa4, 0x3c, 0x9a, 0x5a, 0x0d, 0xa5, 0xd6,
+	0x12, 0xb9, 0x01, 0x75, 0x1a, 0xc7, 0x96, 0xa9, 0x94, 0x72, 0x49, 0x06, 0xd0, 0x8e, 0x59, 0x34,
+	0x0f, 0x7c, 0x64, 0x56, 0x53, 0xa9, 0x73, 0x99, 0xdc, 0x87, 0x96, 0xc7, 0x90, 0x0a, 0xf4, 0xad,
+	0xd6, 0xd0, 0xd8, 0xed, 0xee, 0x0f, 0x9c, 0x14, 0x7f, 0x27, 0xc3, 0xdf, 0x79, 0x9a, 0xe1, 0xef,
+	0x66, 0xa6, 0xf2, 0x12, 0x7a, 0x39, 0x3a, 0x4b, 0xac, 0x76, 0x7a, 0x09, 0xad, 0x79, 0x98, 0xc8,
+	0x80, 0xc3, 0xc8, 0xa3, 0xa1, 0xd5, 0x19, 0x1a, 0xbb, 0x6d, 0x37, 0x15, 0xc8, 0x03, 0x68, 0x86,
+	0xf4, 0x0c, 0x43, 0x6e, 0xc1, 0xb0, 0xbe, 0xdb, 0xdd, 0xbf, 0xe3, 0x94, 0xcb, 0xc2, 0xd1, 0x10,
+	0x39, 0x5f, 0x2b, 0xab, 0x83, 0xa9, 0x60, 0x89, 0xab, 0x8f, 0x90, 0x3d, 0x68, 0x71, 0x64, 0x73,
+	0x64, 0xdc, 0xea, 0xaa, 0xd3, 0x3b, 0xcb, 0xa7, 0x4f, 0xd5, 0xb6, 0x9b, 0x99, 0x0d, 0x3e, 0x85,
+	0x6e, 0xc1, 0x91, 0x84, 0xe5, 0x19, 0x26, 0x1a, 0x70, 0xb9, 0x94, 0x51, 0xce, 0x69, 0x38, 0xcb,
+	0xb0, 0x4e, 0x85, 0xcf, 0x6a, 0x9f, 0x18, 0xf6, 0x25, 0x6c, 0xe9, 0x58, 0x4e, 0x05, 0x15, 0x33,
+	0xbe, 0xc8, 0x80, 0x51, 0x9d, 0x81, 0x5a, 0x29, 0x03, 0x16, 0xb4, 0xc6, 0x48, 0x43, 0x31, 0x4e,
+	0x54, 0xc6, 0xda, 0x6e, 0x26, 0xca, 0x9d, 0x09, 0x72, 0x4e, 0x2f, 0x50, 0x27, 0x2d, 0x13, 0xed,
+	0x3f, 0x0c, 0x68, 0xa6, 0x37, 0x90, 0xe9, 0x1a, 0x47, 0x5c, 0xa8, 0x32, 0x48, 0xbf, 0x97, 0xcb,
+	0xb2, 0xf8, 0xa8, 0x3f, 0x47, 0x26, 0x02, 0x8e, 0xa3, 0x20, 0xce, 0x8a, 0x2f, 0xd7, 0x3d, 0x8e,
+	0x65, 0x05, 0xb1, 0x28, 0xcc, 0x8a, 0x45, 0xad, 0x8b, 0x35, 0xab, 0xf6, 0x1a, 0xa5, 0x9a, 0x75,
+	0xa5, 0xc9, 0x1d, 0xd8, 0x0a, 0xa6, 0x5c, 0xd0, 0xa9, 0x87, 0x23, 0x91, 0xc4, 0xa8, 0x0b, 0xa8,
+	0x97, 0x29, 0x9f, 0x26, 0x31, 0xda, 0xbf, 0x18, 0xd0, 0x3f, 0x44, 0x91, 0xb7, 0x0b, 0x77, 0xf1,
+	0x72, 0x86, 0x5c, 0x90, 0xf7, 0x17, 0xe8, 0xaa, 0x12, 0xaa, 0x4c, 0xec, 0x13, 0x4c, 0x52, 0xe4,
+	0x09, 0x34, 0xd4, 0x27, 0x74, 0x91, 0xcb, 0xf5, 0xfa, 0x22, 0xa7, 0x9e, 0x08, 0xe6, 0x69, 0xc8,
+	0x6d, 0x57, 0x4b, 0xf6, 0xe7, 0x00, 0x8b, 0x20, 0xc8, 0x3d, 0x30, 0x03, 0x81, 0x13, 0x6e, 0x19,
+	0xaa, 0x34, 0xde, 0x58, 0xfe, 0x7e, 0x6e, 0xea, 0xa6, 0x76, 0xf6, 0xdf, 0x35, 0xe8, 0xe4, 0x4a,
+	0xe2, 0x14, 0x83, 0x7f, 0x6b, 0xed, 0xe1, 0x6b, 0x84, 0x5f, 0xe8, 0xae, 0xc6, 0x75, 0xbb, 0xcb,
+	0x5c, 0xee, 0xae, 0xfb, 0xd0, 0x9a, 0xc5, 0xbe, 0x72, 0xda, 0x7c, 0xb1, 0x53, 0x6d, 0x5a, 0x6c,
+	0xa0, 0xd6, 0x46, 0x0d, 0x24, 0x3b, 0x86, 0xd1, 0xe7, 0xaa, 0xbb, 0x7b, 0xae, 0x5c, 0xda, 0xff,
+	0x1a, 0xd0, 0x3e, 0x61, 0xd1, 0x05, 0x43, 0xce, 0x5f, 0x1a, 0xb5, 0x02, 0x16, 0xb5, 0xcd, 0xb1,
+	0x78, 0x1b, 0xc0, 0x8b, 0x26, 0x71, 0x88, 0xd2, 0x97, 0x02, 0xd7, 0x74, 0x0b, 0x1a, 0x99, 0x0b,
+	0x2e, 0x30, 0x56, 0xf0, 0x9a, 0xae, 0x5a, 0x2f, 0x72, 0x61, 0x16, 0x73, 0x51, 0xe8, 0xbd, 0x66,
+	0xb9, 0xf7, 0xfe, 0x31, 0xa0, 0x71, 0x12, 0xd2, 0x97, 0x2f, 0x84, 0xbb, 0xd9, 0xa4, 0x0b, 0xa2,
+	0xe9, 0xa8, 0x50, 0x12, 0x5b, 0xb9, 0x56, 0x76, 0x4d, 0xf1, 0xe6, 0xf5, 0xcd, 0x6f, 0xfe, 0x01,
+	0x34, 0xe3, 0x31, 0xe5, 0xc8, 0xad, 0x86, 0xca, 0xd7, 0xcd, 0xe5, 0x78, 0x4e, 0xe4, 0xae, 0xab,
+	0x8d, 0xec, 0xdf, 0x6b, 0x60, 0x2a, 0x8d, 0x9e, 0x3e, 0x46, 0x36, 0x7d, 0xc8, 0x10, 0xba, 0x3e,
+	0x72, 0x8f, 0x05, 0xb1, 0xc2, 0x50, 0x53, 0x46, 0x41, 0xb5, 0xa6, 0x78, 0xab, 0xa0, 0x5d, 0x04,
+	0x65, 0x6e, 0x10, 0x94, 0xa4, 0x32, 0x86, 0x97, 0xb3, 0x80, 0x21, 0xb7, 0x9a, 0xc3, 0xba, 0xa4,
+	0xb2, 0x4c, 0x96, 0x7b, 0x31, 0x65, 0x34, 0x0c, 0x31, 0x54, 0xa3, 0xa7, 0xed, 0xe6, 0x72, 0xb1,
+	0xc4, 0xdb, 0x9b, 0x97, 0x78, 0x1f, 0x4c, 0x64, 0x2c, 0x62, 0x6a, 0xec, 0x74, 0xdc, 0x54, 0xb0,
+	0x7f, 0x33, 0xe0, 0xb5, 0x43, 0x14, 0x2e, 0xf2, 0x68, 0xc6, 0x3c, 0xbc, 0x3e, 0x65, 0x3d, 0x0b,
+	0xa6, 0x7e, 0xd6, 0xf3, 0x72, 0x9d, 0xcf, 0xea, 0x7a, 0x61, 0x56, 0xdf, 0x86, 0xde, 0xf3, 0x40,
+	0x8c, 0x47, 0x1c, 0x3d, 0x86, 0x82, 0x6b, 0xda, 0xea, 0x4a, 0xdd, 0x69, 0xaa, 0xb2, 0x1f, 0x40,
+	0x27, 0x0f, 0x86, 0x38, 0x65, 0xea, 0xb2, 0x96, 0xe3, 0xc8, 0x2c, 0x33, 0xe6, 0x7a, 0x04, 0xed,
+	0x4c, 0x95, 0xc7, 0x64, 0x54, 0xc4, 0x54, 0x7c, 0x3f, 0x10, 0x68, 0xf8, 0x54, 0x50, 0x15, 0x67,
+	0xcf, 0x55, 0x6b, 0xfb, 0x07, 0xb8, 0xf9, 0x5d, 0xcc, 0x91, 0xe5, 0xb8, 0x5c, 0x1b, 0x16, 0xe5,
+	0xba, 0x56, 0x70, 0xfd, 0xb3, 0x01, 0x37, 0x5d, 0x9c, 0x44, 0x73, 0xfc, 0xdf, 0xbe, 0x37, 0x82,
+	0xbc, 0x0f, 0xe6, 0x79, 0xc4, 0xbc, 0x6c, 0x44, 0xa4, 0x82, 0x1d, 0xc2, 0x8d, 0x43, 0x14, 0x07,
+	0x73, 0x9c, 0x8a, 0x6b, 0xa6, 0xbc, 0x0f, 0x26, 0x3d, 0x17, 0xc8, 0xb2, 0xf7, 0x81, 0x12, 0xa4,
+	0x36, 0x0c, 0x26, 0x81, 0xd0, 0x5c, 0x94, 0x0a, 0xf6, 0xc7, 0xd0, 0x4c, 0x3f, 0x45, 0xde, 0x2b,
+	0x27, 0x74, 0xa5, 0x41, 0x94, 0x59, 0x96, 0xcd, 0x5f, 0x0d, 0x30, 0x95, 0x62, 0xa5, 0x69, 0x1d,
+	0x68, 0xc8, 0x77, 0xef, 0x06, 0x54, 0xa9, 0xec, 0x2a, 0x81, 0x21, 0xd0, 0xf0, 0x22, 0x3f, 0x9b,
+	0xf6, 0x6a, 0x2d, 0x07, 0xea, 0x79, 0x80, 0xa1, 0xcf, 0x15, 0x39, 0xf6, 0x5c, 0x2d, 0xed, 0xff,
+	0xd9, 0x82, 0xf6, 0xb1, 0x7e, 0xb5, 0x93, 0x2f, 0x00, 0x0e, 0x51, 0x64, 0x2f, 0xd6, 0x2b, 0x80,
+	0x1a, 0xbc, 0xbe, 0x66, 0x8f, 0x7c, 0xa3, 0xe0, 0x2f, 0xbf, 0xa2, 0xae, 0x72, 0x74, 0x6b, 0xcd,
+	0x9e, 0x3e, 0xfa, 0x2d, 0x6c, 0x95, 0xde, 0x1d, 0xe4, 0xdd, 0x65, 0xfb, 0xaa, 0x67, 0xc9, 0x60,
+	0xb0, 0x96, 0xc3, 0x39, 0x39, 0x84, 0x5e, 0xf1, 0x0c, 0xb9, 0x92, 0xef, 0x07, 0xeb, 0xdf, 0x14,
+	0xe4, 0xa8, 0xfc, 0x26, 0xca, 0x07, 0xe4, 0xd5, 0x0e, 0x57, 0x3a, 0x3d, 0x3f, 0xe7, 0xc2, 0xce,
+	0xf7, 0x54, 0x78, 0xe3, 0x57, 0xe6, 0x71, 0xcf, 0x20, 0x8f, 0x54, 0x3a, 0x16, 0x1e, 0xe5, 0xb4,
+	0xbb, 0xda, 0x5b, 0x7f, 0xc5, 0x9b, 0x3c, 0x73, 0xa4, 0x40, 0x5b, 0xd0, 0xd7, 0x9d, 0x8a, 0x34,
+	0x2c, 0x33, 0xed, 0x2a, 0x76, 0x8b, 0xf3, 0xc7, 0xb0, 0x5d, 0xa6, 0x21, 0x72, 0x77, 0xd9, 0xb8,
+	0x92, 0xa6, 0x06, 0x3b, 0x2b, 0x1d, 0x71, 0x20, 0xff, 0x43, 0x4a, 0x87, 0x65, 0xee, 0x59, 0x75,
+	0x58, 0xc9, 0x4d, 0x6b, 0x1d, 0x1e, 0x40, 0x27, 0xe7, 0x11, 0x32, 0xac, 0xb8, 0x6e, 0x89, 0x62,
+	0x06, 0x3b, 0x95, 0xfd, 0xce, 0xc9, 0x57, 0xd0, 0x55, 0x49, 0xdd, 0xd8, 0x51, 0x35, 0x71, 0xec,
+	0x19, 0x0f, 0x5b, 0x3f, 0x9a, 0x69, 0x88, 0x4d, 0xf5, 0xf3, 0xd1, 0x7f, 0x03, 0x00, 0x5c, 0xca,
+	0xb9, 0x19, 0x6e, 0x0f, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// OperatorClient is the client API for Operator service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type OperatorClient interface {
+	// GetCluster returns the cluster specified with key.
+	// Returns the local cluster if the cluster name is empty
+	GetCluster(ctx context.Context, in *ClusterKey, opts ...grpc.CallOption) (*Cluster, error)
+	// GetClusterStatus runs the cluster status checks and
+	// returns the resulting cluster status
+	GetClusterStatus(ctx context.Context, in *ClusterKey, opts ...grpc.CallOption) (*ClusterStatus, error)
+	// GetOperations returns the list of cluster operations,
+	// most recent first
+	GetOperations(ctx context.Context, in *GetOperationsRequest, opts ...grpc.CallOption) (*Operations, error)
+	// GetOperation returns the operation specified with key
+	GetOperation(ctx context.Context, in *OperationKey, opts ...grpc.CallOption) (*Operation, error)
+	// GetOperationProgress returns the last progress entry of the operation
+	GetOperationProgress(ctx context.Context, in *OperationKey, opts ...grpc.CallOption) (*Progress, error)
+	// WatchOperationProgress streams the operation progress entries
+	// as they are recorded until the operation has finished
+	WatchOperationProgress(ctx context.Context, in *OperationKey, opts ...grpc.CallOption) (Operator_WatchOperationProgressClient, error)
+	// GetOperationPlan returns the plan of the operation
+	GetOperationPlan(ctx context.Context, in *OperationKey, opts ...grpc.CallOption) (*Plan, error)
+	// GetResources returns the cluster resources of the specified kind
+	GetResources(ctx context.Context, in *GetResourcesRequest, opts ...grpc.CallOption) (*Resources, error)
+	// UpsertResource creates or updates the cluster resources
+	UpsertResource(ctx context.Context, in *UpsertResourceRequest, opts ...grpc.CallOption) (*types.Empty, error)
+	// RemoveResource removes the specified cluster resource
+	RemoveResource(ctx context.Context, in *RemoveResourceRequest, opts ...grpc.CallOption) (*types.Empty, error)
+	// GetEvents returns the cluster events recorded after the specified
+	// event, oldest first
+	GetEvents(ctx context.Context, in *GetEventsRequest, opts ...grpc.CallOption) (*Events, error)
+	// WatchEvents streams the cluster events recorded after the specified
+	// event as they are recorded. To resume the stream after a disconnect,
+	// request the events after the last received event
+	WatchEvents(ctx context.Context, in *GetEventsRequest, opts ...grpc.CallOption) (Operator_WatchEventsClient, error)
+}
+
+type operatorClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewOperatorClient(cc *grpc.ClientConn) OperatorClient {
+	return &operatorClient{cc}
+}
+
+func (c *operatorClient) GetCluster(ctx context.Context, in *ClusterKey, opts ...grpc.CallOption) (*Cluster, error) {
+	out := new(Cluster)
+	err := c.cc.Invoke(ctx, "/gravity.ops.v1.Operator/GetCluster", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *operatorClient) GetClusterStatus(ctx context.Context, in *ClusterKey, opts ...grpc.CallOption) (*ClusterStatus, error) {
+	out := new(ClusterStatus)
+	err := c.cc.Invoke(ctx, "/gravity.ops.v1.Operator/GetClusterStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *operatorClient) GetOperations(ctx context.Context, in *GetOperationsRequest, opts ...grpc.CallOption) (*Operations, error) {
+	out := new(Operations)
+	err := c.cc.Invoke(ctx, "/gravity.ops.v1.Operator/GetOperations", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *operatorClient) GetOperation(ctx context.Context, in *OperationKey, opts ...grpc.CallOption) (*Operation, error) {
+	out := new(Operation)
+	err := c.cc.Invoke(ctx, "/gravity.ops.v1.Operator/GetOperation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *operatorClient) GetOperationProgress(ctx context.Context, in *OperationKey, opts ...grpc.CallOption) (*Progress, error) {
+	out := new(Progress)
+	err := c.cc.Invoke(ctx, "/gravity.ops.v1.Operator/GetOperationProgress", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *operatorClient) WatchOperationProgress(ctx context.Context, in *OperationKey, opts ...grpc.CallOption) (Operator_WatchOperationProgressClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Operator_serviceDesc.Streams[0], "/gravity.ops.v1.Operator/WatchOperationProgress", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &operatorWatchOperationProgressClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Operator_WatchOperationProgressClient interface {
+	Recv() (*Progress, error)
+	grpc.ClientStream
+}
+
+type operatorWatchOperationProgressClient struct {
+	grpc.ClientStream
+}
+
+func (x *operatorWatchOperationProgressClient) Recv() (*Progress, error) {
+	m := new(Progress)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *operatorClient) GetOperationPlan(ctx context.Context, in *OperationKey, opts ...grpc.CallOption) (*Plan, error) {
+	out := new(Plan)
+	err := c.cc.Invoke(ctx, "/gravity.ops.v1.Operator/GetOperationPlan", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *operatorClient) GetResources(ctx context.Context, in *GetResourcesRequest, opts ...grpc.CallOption) (*Resources, error) {
+	out := new(Resources)
+	err := c.cc.Invoke(ctx, "/gravity.ops.v1.Operator/GetResources", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *operatorClient) UpsertResource(ctx context.Context, in *UpsertResourceRequest, opts ...grpc.CallOption) (*types.Empty, error) {
+	out := new(types.Empty)
+	err := c.cc.Invoke(ctx, "/gravity.ops.v1.Operator/UpsertResource", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *operatorClient) RemoveResource(ctx context.Context, in *RemoveResourceRequest, opts ...grpc.CallOption) (*types.Empty, error) {
+	out := new(types.Empty)
+	err := c.cc.Invoke(ctx, "/gravity.ops.v1.Operator/RemoveResource", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *operatorClient) GetEvents(ctx context.Context, in *GetEventsRequest, opts ...grpc.CallOption) (*Events, error) {
+	out := new(Events)
+	err := c.cc.Invoke(ctx, "/gravity.ops.v1.Operator/GetEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *operatorClient) WatchEvents(ctx context.Context, in *GetEventsRequest, opts ...grpc.CallOption) (Operator_WatchEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Operator_serviceDesc.Streams[1], "/gravity.ops.v1.Operator/WatchEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &operatorWatchEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Operator_WatchEventsClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type operatorWatchEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *operatorWatchEventsClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// OperatorServer is the server API for Operator service.
+type OperatorServer interface {
+	// GetCluster returns the cluster specified with key.
+	// Returns the local cluster if the cluster name is empty
+	GetCluster(context.Context, *ClusterKey) (*Cluster, error)
+	// GetClusterStatus runs the cluster status checks and
+	// returns the resulting cluster status
+	GetClusterStatus(context.Context, *ClusterKey) (*ClusterStatus, error)
+	// GetOperations returns the list of cluster operations,
+	// most recent first
+	GetOperations(context.Context, *GetOperationsRequest) (*Operations, error)
+	// GetOperation returns the operation specified with key
+	GetOperation(context.Context, *OperationKey) (*Operation, error)
+	// GetOperationProgress returns the last progress entry of the operation
+	GetOperationProgress(context.Context, *OperationKey) (*Progress, error)
+	// WatchOperationProgress streams the operation progress entries
+	// as they are recorded until the operation has finished
+	WatchOperationProgress(*OperationKey, Operator_WatchOperationProgressServer) error
+	// GetOperationPlan returns the plan of the operation
+	GetOperationPlan(context.Context, *OperationKey) (*Plan, error)
+	// GetResources returns the cluster resources of the specified kind
+	GetResources(context.Context, *GetResourcesRequest) (*Resources, error)
+	// UpsertResource creates or updates the cluster resources
+	UpsertResource(context.Context, *UpsertResourceRequest) (*types.Empty, error)
+	// RemoveResource removes the specified cluster resource
+	RemoveResource(context.Context, *RemoveResourceRequest) (*types.Empty, error)
+	// GetEvents returns the cluster events recorded after the specified
+	// event, oldest first
+	GetEvents(context.Context, *GetEventsRequest) (*Events, error)
+	// WatchEvents streams the cluster events recorded after the specified
+	// event as they are recorded. To resume the stream after a disconnect,
+	// request the events after the last received event
+	WatchEvents(*GetEventsRequest, Operator_WatchEventsServer) error
+}
+
+// UnimplementedOperatorServer can be embedded to have forward compatible implementations.
+type UnimplementedOperatorServer struct {
+}
+
+func (*UnimplementedOperatorServer) GetCluster(ctx context.Context, req *ClusterKey) (*Cluster, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCluster not implemented")
+}
+func (*UnimplementedOperatorServer) GetClusterStatus(ctx context.Context, req *ClusterKey) (*ClusterStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetClusterStatus not implemented")
+}
+func (*UnimplementedOperatorServer) GetOperations(ctx context.Context, req *GetOperationsRequest) (*Operations, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOperations not implemented")
+}
+func (*UnimplementedOperatorServer) GetOperation(ctx context.Context, req *OperationKey) (*Operation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOperation not implemented")
+}
+func (*UnimplementedOperatorServer) GetOperationProgress(ctx context.Context, req *OperationKey) (*Progress, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOperationProgress not implemented")
+}
+func (*UnimplementedOperatorServer) WatchOperationProgress(req *OperationKey, srv Operator_WatchOperationProgressServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchOperationProgress not implemented")
+}
+func (*UnimplementedOperatorServer) GetOperationPlan(ctx context.Context, req *OperationKey) (*Plan, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOperationPlan not implemented")
+}
+func (*UnimplementedOperatorServer) GetResources(ctx context.Context, req *GetResourcesRequest) (*Resources, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetResources not implemented")
+}
+func (*UnimplementedOperatorServer) UpsertResource(ctx context.Context, req *UpsertResourceRequest) (*types.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpsertResource not implemented")
+}
+func (*UnimplementedOperatorServer) RemoveResource(ctx context.Context, req *RemoveResourceRequest) (*types.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveResource not implemented")
+}
+func (*UnimplementedOperatorServer) GetEvents(ctx context.Context, req *GetEventsRequest) (*Events, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEvents not implemented")
+}
+func (*UnimplementedOperatorServer) WatchEvents(req *GetEventsRequest, srv Operator_WatchEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
+
+func RegisterOperatorServer(s *grpc.Server, srv OperatorServer) {
+	s.RegisterService(&_Operator_serviceDesc, srv)
+}
+
+func _Operator_GetCluster_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClusterKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OperatorServer).GetCluster(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gravity.ops.v1.Operator/GetCluster",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OperatorServer).GetCluster(ctx, req.(*ClusterKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Operator_GetClusterStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClusterKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OperatorServer).GetClusterStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gravity.ops.v1.Operator/GetClusterStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OperatorServer).GetClusterStatus(ctx, req.(*ClusterKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Operator_GetOperations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOperationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OperatorServer).GetOperations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gravity.ops.v1.Operator/GetOperations",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OperatorServer).GetOperations(ctx, req.(*GetOperationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Operator_GetOperation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OperationKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OperatorServer).GetOperation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gravity.ops.v1.Operator/GetOperation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OperatorServer).GetOperation(ctx, req.(*OperationKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Operator_GetOperationProgress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OperationKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OperatorServer).GetOperationProgress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gravity.ops.v1.Operator/GetOperationProgress",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OperatorServer).GetOperationProgress(ctx, req.(*OperationKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Operator_WatchOperationProgress_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(OperationKey)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OperatorServer).WatchOperationProgress(m, &operatorWatchOperationProgressServer{stream})
+}
+
+type Operator_WatchOperationProgressServer interface {
+	Send(*Progress) error
+	grpc.ServerStream
+}
+
+type operatorWatchOperationProgressServer struct {
+	grpc.ServerStream
+}
+
+func (x *operatorWatchOperationProgressServer) Send(m *Progress) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Operator_GetOperationPlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OperationKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OperatorServer).GetOperationPlan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gravity.ops.v1.Operator/GetOperationPlan",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OperatorServer).GetOperationPlan(ctx, req.(*OperationKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Operator_GetResources_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetResourcesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OperatorServer).GetResources(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gravity.ops.v1.Operator/GetResources",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OperatorServer).GetResources(ctx, req.(*GetResourcesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Operator_UpsertResource_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpsertResourceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OperatorServer).UpsertResource(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gravity.ops.v1.Operator/UpsertResource",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OperatorServer).UpsertResource(ctx, req.(*UpsertResourceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Operator_RemoveResource_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveResourceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OperatorServer).RemoveResource(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gravity.ops.v1.Operator/RemoveResource",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OperatorServer).RemoveResource(ctx, req.(*RemoveResourceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Operator_GetEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OperatorServer).GetEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gravity.ops.v1.Operator/GetEvents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OperatorServer).GetEvents(ctx, req.(*GetEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Operator_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OperatorServer).WatchEvents(m, &operatorWatchEventsServer{stream})
+}
+
+type Operator_WatchEventsServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type operatorWatchEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *operatorWatchEventsServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _Operator_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "gravity.ops.v1.Operator",
+	HandlerType: (*OperatorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetCluster",
+			Handler:    _Operator_GetCluster_Handler,
+		},
+		{
+			MethodName: "GetClusterStatus",
+			Handler:    _Operator_GetClusterStatus_Handler,
+		},
+		{
+			MethodName: "GetOperations",
+			Handler:    _Operator_GetOperations_Handler,
+		},
+		{
+			MethodName: "GetOperation",
+			Handler:    _Operator_GetOperation_Handler,
+		},
+		{
+			MethodName: "GetOperationProgress",
+			Handler:    _Operator_GetOperationProgress_Handler,
+		},
+		{
+			MethodName: "GetOperationPlan",
+			Handler:    _Operator_GetOperationPlan_Handler,
+		},
+		{
+			MethodName: "GetResources",
+			Handler:    _Operator_GetResources_Handler,
+		},
+		{
+			MethodName: "UpsertResource",
+			Handler:    _Operator_UpsertResource_Handler,
+		},
+		{
+			MethodName: "RemoveResource",
+			Handler:    _Operator_RemoveResource_Handler,
+		},
+		{
+			MethodName: "GetEvents",
+			Handler:    _Operator_GetEvents_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchOperationProgress",
+			Handler:       _Operator_WatchOperationProgress_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _Operator_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "operator.proto",
+}
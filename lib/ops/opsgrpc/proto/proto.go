@@ -0,0 +1,197 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/gravitational/trace"
+)
+
+// ClusterKeyFromProto converts the specified cluster key to internal format
+func ClusterKeyFromProto(key *ClusterKey) ops.SiteKey {
+	if key == nil {
+		return ops.SiteKey{}
+	}
+	return ops.SiteKey{
+		AccountID:  key.AccountId,
+		SiteDomain: key.ClusterName,
+	}
+}
+
+// OperationKeyFromProto converts the specified operation key to internal format
+func OperationKeyFromProto(key *OperationKey) ops.SiteOperationKey {
+	if key == nil {
+		return ops.SiteOperationKey{}
+	}
+	return ops.SiteOperationKey{
+		AccountID:   key.AccountId,
+		SiteDomain:  key.ClusterName,
+		OperationID: key.Id,
+	}
+}
+
+// OperationKeyToProto converts the specified operation key to proto format
+func OperationKeyToProto(key ops.SiteOperationKey) *OperationKey {
+	return &OperationKey{
+		AccountId:   key.AccountID,
+		ClusterName: key.SiteDomain,
+		Id:          key.OperationID,
+	}
+}
+
+// ClusterToProto converts the specified cluster to proto format
+func ClusterToProto(cluster ops.Site) *Cluster {
+	return &Cluster{
+		AccountId: cluster.AccountID,
+		Name:      cluster.Domain,
+		State:     cluster.State,
+		Reason:    string(cluster.Reason),
+		App:       cluster.App.Package.String(),
+		Provider:  cluster.Provider,
+		Created:   TimeToProto(cluster.Created),
+		CreatedBy: cluster.CreatedBy,
+		Local:     cluster.Local,
+		Labels:    cluster.Labels,
+		Servers:   ServersToProto(cluster.ClusterState.Servers),
+	}
+}
+
+// OperationToProto converts the specified operation to proto format
+func OperationToProto(operation ops.SiteOperation) (*Operation, error) {
+	raw, err := json.Marshal(operation)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &Operation{
+		Key:       OperationKeyToProto(operation.Key()),
+		Type:      operation.Type,
+		State:     operation.State,
+		Created:   TimeToProto(operation.Created),
+		CreatedBy: operation.CreatedBy,
+		Updated:   TimeToProto(operation.Updated),
+		Servers:   ServersToProto(operation.Servers),
+		Raw:       raw,
+	}, nil
+}
+
+// ProgressToProto converts the specified progress entry of the operation
+// given with key to proto format
+func ProgressToProto(key ops.SiteOperationKey, entry ops.ProgressEntry) *Progress {
+	return &Progress{
+		Key:        OperationKeyToProto(key),
+		Created:    TimeToProto(entry.Created),
+		Completion: int32(entry.Completion),
+		Step:       int32(entry.Step),
+		State:      entry.State,
+		Message:    entry.Message,
+	}
+}
+
+// PlanToProto converts the specified operation plan to proto format
+func PlanToProto(plan storage.OperationPlan) *Plan {
+	return &Plan{
+		Key: &OperationKey{
+			AccountId:   plan.AccountID,
+			ClusterName: plan.ClusterName,
+			Id:          plan.OperationID,
+		},
+		OperationType: plan.OperationType,
+		Created:       TimeToProto(plan.CreatedAt),
+		Phases:        phasesToProto(plan.Phases),
+	}
+}
+
+// EventToProto converts the specified cluster event to proto format
+func EventToProto(event storage.ClusterEvent) (*Event, error) {
+	fields, err := json.Marshal(event.Fields)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &Event{
+		Id:     event.ID,
+		Time:   TimeToProto(event.Time),
+		Name:   event.Name,
+		Code:   event.Code,
+		Fields: fields,
+	}, nil
+}
+
+// ServersToProto converts the specified servers to proto format
+func ServersToProto(servers []storage.Server) (result []*Server) {
+	for _, server := range servers {
+		result = append(result, &Server{
+			Hostname:     server.Hostname,
+			AdvertiseIp:  server.AdvertiseIP,
+			Role:         server.Role,
+			ClusterRole:  server.ClusterRole,
+			InstanceType: server.InstanceType,
+		})
+	}
+	return result
+}
+
+// TimeToProto converts the specified time to proto format.
+// Returns nil for zero time
+func TimeToProto(t time.Time) *types.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	ts, err := types.TimestampProto(t)
+	if err != nil {
+		return nil
+	}
+	return ts
+}
+
+func phasesToProto(phases []storage.OperationPhase) (result []*Phase) {
+	for _, phase := range phases {
+		result = append(result, &Phase{
+			Id:          phase.ID,
+			Description: phase.Description,
+			State:       phase.State,
+			Step:        int32(phase.Step),
+			Phases:      phasesToProto(phase.Phases),
+			Requires:    phase.Requires,
+			Parallel:    phase.Parallel,
+			Updated:     TimeToProto(phase.Updated),
+			Error:       phaseError(phase.Error),
+		})
+	}
+	return result
+}
+
+// phaseError returns the message of the specified phase error
+func phaseError(raw *trace.RawTrace) string {
+	if raw == nil {
+		return ""
+	}
+	if raw.Message != "" {
+		return raw.Message
+	}
+	var err trace.TraceErr
+	if utils.UnmarshalError(raw.Err, &err) != nil || err.Err == nil {
+		return string(raw.Err)
+	}
+	return err.Err.Error()
+}
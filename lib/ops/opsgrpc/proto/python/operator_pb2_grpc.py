@@ -0,0 +1,470 @@
+# Generated by the gRPC Python protocol compiler plugin. DO NOT EDIT!
+"""Client and server classes corresponding to protobuf-defined services."""
+import grpc
+
+from google.protobuf import empty_pb2 as google_dot_protobuf_dot_empty__pb2
+import operator_pb2 as operator__pb2
+
+
+class OperatorStub(object):
+    """Operator defines a service to manage a cluster.
+    Every request is authenticated with the credentials of a cluster user
+    passed in the authorization metadata in the same format as
+    the Authorization header of the HTTP API:
+    
+      authorization: Bearer <api key>
+      authorization: Basic <base64 encoded user:password>
+    """
+
+    def __init__(self, channel):
+        """Constructor.
+
+        Args:
+            channel: A grpc.Channel.
+        """
+        self.GetCluster = channel.unary_unary(
+                '/gravity.ops.v1.Operator/GetCluster',
+                request_serializer=operator__pb2.ClusterKey.SerializeToString,
+                response_deserializer=operator__pb2.Cluster.FromString,
+                )
+        self.GetClusterStatus = channel.unary_unary(
+                '/gravity.ops.v1.Operator/GetClusterStatus',
+                request_serializer=operator__pb2.ClusterKey.SerializeToString,
+                response_deserializer=operator__pb2.ClusterStatus.FromString,
+                )
+        self.GetOperations = channel.unary_unary(
+                '/gravity.ops.v1.Operator/GetOperations',
+                request_serializer=operator__pb2.GetOperationsRequest.SerializeToString,
+                response_deserializer=operator__pb2.Operations.FromString,
+                )
+        self.GetOperation = channel.unary_unary(
+                '/gravity.ops.v1.Operator/GetOperation',
+                request_serializer=operator__pb2.OperationKey.SerializeToString,
+                response_deserializer=operator__pb2.Operation.FromString,
+                )
+        self.GetOperationProgress = channel.unary_unary(
+                '/gravity.ops.v1.Operator/GetOperationProgress',
+                request_serializer=operator__pb2.OperationKey.SerializeToString,
+                response_deserializer=operator__pb2.Progress.FromString,
+                )
+        self.WatchOperationProgress = channel.unary_stream(
+                '/gravity.ops.v1.Operator/WatchOperationProgress',
+                request_serializer=operator__pb2.OperationKey.SerializeToString,
+                response_deserializer=operator__pb2.Progress.FromString,
+                )
+        self.GetOperationPlan = channel.unary_unary(
+                '/gravity.ops.v1.Operator/GetOperationPlan',
+                request_serializer=operator__pb2.OperationKey.SerializeToString,
+                response_deserializer=operator__pb2.Plan.FromString,
+                )
+        self.GetResources = channel.unary_unary(
+                '/gravity.ops.v1.Operator/GetResources',
+                request_serializer=operator__pb2.GetResourcesRequest.SerializeToString,
+                response_deserializer=operator__pb2.Resources.FromString,
+                )
+        self.UpsertResource = channel.unary_unary(
+                '/gravity.ops.v1.Operator/UpsertResource',
+                request_serializer=operator__pb2.UpsertResourceRequest.SerializeToString,
+                response_deserializer=google_dot_protobuf_dot_empty__pb2.Empty.FromString,
+                )
+        self.RemoveResource = channel.unary_unary(
+                '/gravity.ops.v1.Operator/RemoveResource',
+                request_serializer=operator__pb2.RemoveResourceRequest.SerializeToString,
+                response_deserializer=google_dot_protobuf_dot_empty__pb2.Empty.FromString,
+                )
+        self.GetEvents = channel.unary_unary(
+                '/gravity.ops.v1.Operator/GetEvents',
+                request_serializer=operator__pb2.GetEventsRequest.SerializeToString,
+                response_deserializer=operator__pb2.Events.FromString,
+                )
+        self.WatchEvents = channel.unary_stream(
+                '/gravity.ops.v1.Operator/WatchEvents',
+                request_serializer=operator__pb2.GetEventsRequest.SerializeToString,
+                response_deserializer=operator__pb2.Event.FromString,
+                )
+
+
+class OperatorServicer(object):
+    """Operator defines a service to manage a cluster.
+    Every request is authenticated with the credentials of a cluster user
+    passed in the authorization metadata in the same format as
+    the Authorization header of the HTTP API:
+    
+      authorization: Bearer <api key>
+      authorization: Basic <base64 encoded user:password>
+    """
+
+    def GetCluster(self, request, context):
+        """GetCluster returns the cluster specified with key.
+        Returns the local cluster if the cluster name is empty
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetClusterStatus(self, request, context):
+        """GetClusterStatus runs the cluster status checks and
+        returns the resulting cluster status
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetOperations(self, request, context):
+        """GetOperations returns the list of cluster operations,
+        most recent first
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetOperation(self, request, context):
+        """GetOperation returns the operation specified with key
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetOperationProgress(self, request, context):
+        """GetOperationProgress returns the last progress entry of the operation
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def WatchOperationProgress(self, request, context):
+        """WatchOperationProgress streams the operation progress entries
+        as they are recorded until the operation has finished
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetOperationPlan(self, request, context):
+        """GetOperationPlan returns the plan of the operation
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetResources(self, request, context):
+        """GetResources returns the cluster resources of the specified kind
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def UpsertResource(self, request, context):
+        """UpsertResource creates or updates the cluster resources
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def RemoveResource(self, request, context):
+        """RemoveResource removes the specified cluster resource
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetEvents(self, request, context):
+        """GetEvents returns the cluster events recorded after the specified
+        event, oldest first
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def WatchEvents(self, request, context):
+        """WatchEvents streams the cluster events recorded after the specified
+        event as they are recorded. To resume the stream after a disconnect,
+        request the events after the last received event
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+
+def add_OperatorServicer_to_server(servicer, server):
+    rpc_method_handlers = {
+            'GetCluster': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetCluster,
+                    request_deserializer=operator__pb2.ClusterKey.FromString,
+                    response_serializer=operator__pb2.Cluster.SerializeToString,
+            ),
+            'GetClusterStatus': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetClusterStatus,
+                    request_deserializer=operator__pb2.ClusterKey.FromString,
+                    response_serializer=operator__pb2.ClusterStatus.SerializeToString,
+            ),
+            'GetOperations': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetOperations,
+                    request_deserializer=operator__pb2.GetOperationsRequest.FromString,
+                    response_serializer=operator__pb2.Operations.SerializeToString,
+            ),
+            'GetOperation': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetOperation,
+                    request_deserializer=operator__pb2.OperationKey.FromString,
+                    response_serializer=operator__pb2.Operation.SerializeToString,
+            ),
+            'GetOperationProgress': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetOperationProgress,
+                    request_deserializer=operator__pb2.OperationKey.FromString,
+                    response_serializer=operator__pb2.Progress.SerializeToString,
+            ),
+            'WatchOperationProgress': grpc.unary_stream_rpc_method_handler(
+                    servicer.WatchOperationProgress,
+                    request_deserializer=operator__pb2.OperationKey.FromString,
+                    response_serializer=operator__pb2.Progress.SerializeToString,
+            ),
+            'GetOperationPlan': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetOperationPlan,
+                    request_deserializer=operator__pb2.OperationKey.FromString,
+                    response_serializer=operator__pb2.Plan.SerializeToString,
+            ),
+            'GetResources': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetResources,
+                    request_deserializer=operator__pb2.GetResourcesRequest.FromString,
+                    response_serializer=operator__pb2.Resources.SerializeToString,
+            ),
+            'UpsertResource': grpc.unary_unary_rpc_method_handler(
+                    servicer.UpsertResource,
+                    request_deserializer=operator__pb2.UpsertResourceRequest.FromString,
+                    response_serializer=google_dot_protobuf_dot_empty__pb2.Empty.SerializeToString,
+            ),
+            'RemoveResource': grpc.unary_unary_rpc_method_handler(
+                    servicer.RemoveResource,
+                    request_deserializer=operator__pb2.RemoveResourceRequest.FromString,
+                    response_serializer=google_dot_protobuf_dot_empty__pb2.Empty.SerializeToString,
+            ),
+            'GetEvents': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetEvents,
+                    request_deserializer=operator__pb2.GetEventsRequest.FromString,
+                    response_serializer=operator__pb2.Events.SerializeToString,
+            ),
+            'WatchEvents': grpc.unary_stream_rpc_method_handler(
+                    servicer.WatchEvents,
+                    request_deserializer=operator__pb2.GetEventsRequest.FromString,
+                    response_serializer=operator__pb2.Event.SerializeToString,
+            ),
+    }
+    generic_handler = grpc.method_handlers_generic_handler(
+            'gravity.ops.v1.Operator', rpc_method_handlers)
+    server.add_generic_rpc_handlers((generic_handler,))
+
+
+ # This class is part of an EXPERIMENTAL API.
+class Operator(object):
+    """Operator defines a service to manage a cluster.
+    Every request is authenticated with the credentials of a cluster user
+    passed in the authorization metadata in the same format as
+    the Authorization header of the HTTP API:
+    
+      authorization: Bearer <api key>
+      authorization: Basic <base64 encoded user:password>
+    """
+
+    @staticmethod
+    def GetCluster(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/gravity.ops.v1.Operator/GetCluster',
+            operator__pb2.ClusterKey.SerializeToString,
+            operator__pb2.Cluster.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def GetClusterStatus(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/gravity.ops.v1.Operator/GetClusterStatus',
+            operator__pb2.ClusterKey.SerializeToString,
+            operator__pb2.ClusterStatus.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def GetOperations(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/gravity.ops.v1.Operator/GetOperations',
+            operator__pb2.GetOperationsRequest.SerializeToString,
+            operator__pb2.Operations.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def GetOperation(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/gravity.ops.v1.Operator/GetOperation',
+            operator__pb2.OperationKey.SerializeToString,
+            operator__pb2.Operation.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def GetOperationProgress(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/gravity.ops.v1.Operator/GetOperationProgress',
+            operator__pb2.OperationKey.SerializeToString,
+            operator__pb2.Progress.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def WatchOperationProgress(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_stream(request, target, '/gravity.ops.v1.Operator/WatchOperationProgress',
+            operator__pb2.OperationKey.SerializeToString,
+            operator__pb2.Progress.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def GetOperationPlan(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/gravity.ops.v1.Operator/GetOperationPlan',
+            operator__pb2.OperationKey.SerializeToString,
+            operator__pb2.Plan.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def GetResources(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/gravity.ops.v1.Operator/GetResources',
+            operator__pb2.GetResourcesRequest.SerializeToString,
+            operator__pb2.Resources.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def UpsertResource(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/gravity.ops.v1.Operator/UpsertResource',
+            operator__pb2.UpsertResourceRequest.SerializeToString,
+            google_dot_protobuf_dot_empty__pb2.Empty.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def RemoveResource(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/gravity.ops.v1.Operator/RemoveResource',
+            operator__pb2.RemoveResourceRequest.SerializeToString,
+            google_dot_protobuf_dot_empty__pb2.Empty.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def GetEvents(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/gravity.ops.v1.Operator/GetEvents',
+            operator__pb2.GetEventsRequest.SerializeToString,
+            operator__pb2.Events.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def WatchEvents(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_stream(request, target, '/gravity.ops.v1.Operator/WatchEvents',
+            operator__pb2.GetEventsRequest.SerializeToString,
+            operator__pb2.Event.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
@@ -0,0 +1,565 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package opsgrpc implements the cluster management API over gRPC.
+// The API exposes a subset of ops.Operator to external controllers
+// and is served alongside the HTTP API of the operator service
+package opsgrpc
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/httplib"
+	"github.com/gravitational/gravity/lib/ops"
+	pb "github.com/gravitational/gravity/lib/ops/opsgrpc/proto"
+	"github.com/gravitational/gravity/lib/ops/resources"
+	"github.com/gravitational/gravity/lib/ops/resources/gravity"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/users"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Config defines the API server configuration
+type Config struct {
+	// Operator is the operator service the API is served for
+	Operator ops.Operator
+	// Users is the users service used to authenticate the clients
+	Users users.Identity
+	// FieldLogger is used for logging
+	logrus.FieldLogger
+	// ProgressPollInterval defines how often the operation progress
+	// is polled for progress watchers
+	ProgressPollInterval time.Duration
+	// EventsPollInterval defines how often the cluster events table
+	// is polled for event watchers
+	EventsPollInterval time.Duration
+}
+
+// CheckAndSetDefaults validates this configuration and sets defaults
+func (c *Config) CheckAndSetDefaults() error {
+	if c.Operator == nil {
+		return trace.BadParameter("missing Operator")
+	}
+	if c.Users == nil {
+		return trace.BadParameter("missing Users")
+	}
+	if c.FieldLogger == nil {
+		c.FieldLogger = logrus.WithField(trace.Component, "ops:grpc")
+	}
+	if c.ProgressPollInterval == 0 {
+		c.ProgressPollInterval = defaults.ProgressPollTimeout
+	}
+	if c.EventsPollInterval == 0 {
+		c.EventsPollInterval = defaults.ClusterEventsPollInterval
+	}
+	return nil
+}
+
+// New returns a new instance of the API server
+func New(config Config) (*Server, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	server := &Server{Config: config}
+	server.grpcServer = grpc.NewServer(
+		grpc.UnaryInterceptor(server.unaryInterceptor),
+		grpc.StreamInterceptor(server.streamInterceptor),
+	)
+	pb.RegisterOperatorServer(server.grpcServer, server)
+	return server, nil
+}
+
+// Server implements the cluster management API
+type Server struct {
+	Config
+	grpcServer *grpc.Server
+}
+
+// Serve serves the API on the specified listener.
+// The listener is expected to terminate TLS
+func (s *Server) Serve(listener net.Listener) error {
+	err := s.grpcServer.Serve(listener)
+	if err != nil && utils.IsClosedConnectionError(err) {
+		return nil
+	}
+	return trace.Wrap(err)
+}
+
+// Stop stops the server
+func (s *Server) Stop() {
+	s.grpcServer.GracefulStop()
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.grpcServer.ServeHTTP(w, r)
+}
+
+// Handler returns the handler that serves the API requests
+// and passes all other requests to the specified handler
+func (s *Server) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.handles(r) {
+			s.ServeHTTP(w, r)
+		} else {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// handles returns true if the specified request is for the API service
+func (s *Server) handles(r *http.Request) bool {
+	for name := range s.grpcServer.GetServiceInfo() {
+		if strings.HasPrefix(r.URL.Path, "/"+name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCluster returns the cluster specified with key
+func (s *Server) GetCluster(ctx context.Context, key *pb.ClusterKey) (*pb.Cluster, error) {
+	operator := operatorFromContext(ctx)
+	cluster, err := getCluster(operator, pb.ClusterKeyFromProto(key))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return pb.ClusterToProto(*cluster), nil
+}
+
+// GetClusterStatus runs the cluster status checks and returns the cluster status
+func (s *Server) GetClusterStatus(ctx context.Context, key *pb.ClusterKey) (*pb.ClusterStatus, error) {
+	operator := operatorFromContext(ctx)
+	cluster, err := getCluster(operator, pb.ClusterKeyFromProto(key))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var status pb.ClusterStatus
+	err = operator.CheckSiteStatus(ctx, cluster.Key())
+	if err != nil {
+		if trace.IsAccessDenied(err) {
+			return nil, trace.Wrap(err)
+		}
+		status.Message = trace.UserMessage(err)
+	}
+	status.Healthy = err == nil
+	// The checks update the cluster state
+	cluster, err = operator.GetSite(cluster.Key())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	status.State = cluster.State
+	status.Reason = string(cluster.Reason)
+	return &status, nil
+}
+
+// GetOperations returns the list of cluster operations
+func (s *Server) GetOperations(ctx context.Context, req *pb.GetOperationsRequest) (*pb.Operations, error) {
+	operator := operatorFromContext(ctx)
+	key, err := clusterKey(operator, pb.ClusterKeyFromProto(req.Key))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	operations, err := operator.GetSiteOperations(key)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var result pb.Operations
+	for _, operation := range operations {
+		op := ops.SiteOperation(operation)
+		if req.Type != "" && op.Type != req.Type {
+			continue
+		}
+		if req.State != "" && op.State != req.State {
+			continue
+		}
+		if req.Active && op.IsFinished() {
+			continue
+		}
+		item, err := pb.OperationToProto(op)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		result.Items = append(result.Items, item)
+	}
+	return &result, nil
+}
+
+// GetOperation returns the operation specified with key
+func (s *Server) GetOperation(ctx context.Context, key *pb.OperationKey) (*pb.Operation, error) {
+	operator := operatorFromContext(ctx)
+	opKey, err := operationKey(operator, key)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	operation, err := operator.GetSiteOperation(opKey)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return pb.OperationToProto(*operation)
+}
+
+// GetOperationProgress returns the last progress entry of the operation
+func (s *Server) GetOperationProgress(ctx context.Context, key *pb.OperationKey) (*pb.Progress, error) {
+	operator := operatorFromContext(ctx)
+	opKey, err := operationKey(operator, key)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	progress, err := operator.GetSiteOperationProgress(opKey)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return pb.ProgressToProto(opKey, *progress), nil
+}
+
+// WatchOperationProgress streams the operation progress entries
+// until the operation has finished
+func (s *Server) WatchOperationProgress(key *pb.OperationKey, stream pb.Operator_WatchOperationProgressServer) error {
+	operator := operatorFromContext(stream.Context())
+	opKey, err := operationKey(operator, key)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// Fail early if the operation does not exist
+	if _, err := operator.GetSiteOperation(opKey); err != nil {
+		return trace.Wrap(err)
+	}
+	ticker := time.NewTicker(s.ProgressPollInterval)
+	defer ticker.Stop()
+	var last *ops.ProgressEntry
+	for {
+		progress, err := operator.GetSiteOperationProgress(opKey)
+		if err != nil && !trace.IsNotFound(err) {
+			return trace.Wrap(err)
+		}
+		if progress != nil && (last == nil || progress.ID != last.ID || !progress.IsEqual(*last)) {
+			if err := stream.Send(pb.ProgressToProto(opKey, *progress)); err != nil {
+				return trace.Wrap(err)
+			}
+			if progress.IsCompleted() {
+				return nil
+			}
+			last = progress
+		}
+		select {
+		case <-ticker.C:
+		case <-stream.Context().Done():
+			return trace.Wrap(stream.Context().Err())
+		}
+	}
+}
+
+// GetOperationPlan returns the plan of the operation
+func (s *Server) GetOperationPlan(ctx context.Context, key *pb.OperationKey) (*pb.Plan, error) {
+	operator := operatorFromContext(ctx)
+	opKey, err := operationKey(operator, key)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	plan, err := operator.GetOperationPlan(opKey)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return pb.PlanToProto(*plan), nil
+}
+
+// GetResources returns the cluster resources of the specified kind
+func (s *Server) GetResources(ctx context.Context, req *pb.GetResourcesRequest) (*pb.Resources, error) {
+	control, key, err := s.newResources(ctx, req.Key)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	collection, err := control.GetCollection(resources.ListRequest{
+		SiteKey:     key,
+		Kind:        req.Kind,
+		Name:        req.Name,
+		WithSecrets: req.WithSecrets,
+		User:        userFromContext(ctx),
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	items, err := collection.Resources()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var result pb.Resources
+	for _, item := range items {
+		result.Items = append(result.Items, &pb.Resource{
+			Kind: item.Kind,
+			Name: item.Metadata.Name,
+			Data: item.Raw,
+		})
+	}
+	return &result, nil
+}
+
+// UpsertResource creates or updates the cluster resources
+func (s *Server) UpsertResource(ctx context.Context, req *pb.UpsertResourceRequest) (*types.Empty, error) {
+	control, key, err := s.newResources(ctx, req.Key)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	err = resources.NewControl(control).Create(ctx, bytes.NewReader(req.Data), resources.CreateRequest{
+		SiteKey: key,
+		Upsert:  true,
+		Owner:   userFromContext(ctx),
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &types.Empty{}, nil
+}
+
+// RemoveResource removes the specified cluster resource
+func (s *Server) RemoveResource(ctx context.Context, req *pb.RemoveResourceRequest) (*types.Empty, error) {
+	control, key, err := s.newResources(ctx, req.Key)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	err = control.Remove(ctx, resources.RemoveRequest{
+		SiteKey: key,
+		Kind:    req.Kind,
+		Name:    req.Name,
+		Force:   req.Force,
+		Owner:   userFromContext(ctx),
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &types.Empty{}, nil
+}
+
+// GetEvents returns the cluster events recorded after the specified event
+func (s *Server) GetEvents(ctx context.Context, req *pb.GetEventsRequest) (*pb.Events, error) {
+	operator := operatorFromContext(ctx)
+	eventsReq, err := eventsRequest(operator, req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	events, err := operator.GetClusterEvents(ctx, *eventsReq)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var result pb.Events
+	for _, event := range events {
+		item, err := pb.EventToProto(event)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		result.Items = append(result.Items, item)
+	}
+	return &result, nil
+}
+
+// WatchEvents streams the cluster events recorded after the specified
+// event as they are recorded
+func (s *Server) WatchEvents(req *pb.GetEventsRequest, stream pb.Operator_WatchEventsServer) error {
+	operator := operatorFromContext(stream.Context())
+	eventsReq, err := eventsRequest(operator, req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return ops.StreamClusterEvents(stream.Context(), operator, *eventsReq, s.EventsPollInterval,
+		func(event storage.ClusterEvent) error {
+			item, err := pb.EventToProto(event)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			return stream.Send(item)
+		})
+}
+
+// newResources returns the resource controller for the authenticated user
+func (s *Server) newResources(ctx context.Context, key *pb.ClusterKey) (*gravity.Resources, ops.SiteKey, error) {
+	operator := operatorFromContext(ctx)
+	siteKey, err := clusterKey(operator, pb.ClusterKeyFromProto(key))
+	if err != nil {
+		return nil, ops.SiteKey{}, trace.Wrap(err)
+	}
+	control, err := gravity.New(gravity.Config{
+		Operator:                operator,
+		CurrentUser:             userFromContext(ctx),
+		Silent:                  true,
+		ClusterOperationHandler: operationResources{},
+	})
+	if err != nil {
+		return nil, ops.SiteKey{}, trace.Wrap(err)
+	}
+	return control, siteKey, nil
+}
+
+func (s *Server) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp, err := handler(ctx, req)
+	if err != nil {
+		s.WithError(err).WithField("method", info.FullMethod).Debug("Request failed.")
+		return nil, toStatus(err)
+	}
+	return resp, nil
+}
+
+func (s *Server) streamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(stream.Context())
+	if err != nil {
+		return toStatus(err)
+	}
+	err = handler(srv, &serverStream{ServerStream: stream, ctx: ctx})
+	if err != nil {
+		s.WithError(err).WithField("method", info.FullMethod).Debug("Request failed.")
+		return toStatus(err)
+	}
+	return nil
+}
+
+// authenticate authenticates the user with the credentials from
+// the request metadata and returns the context with the operator
+// bound to the user
+func (s *Server) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(authorizationKey)
+	if len(values) == 0 {
+		return nil, trace.AccessDenied("missing credentials")
+	}
+	creds, err := httplib.ParseAuthHeader(values[0])
+	if err != nil {
+		return nil, trace.AccessDenied("invalid credentials")
+	}
+	user, checker, err := s.Users.AuthenticateUser(*creds)
+	if err != nil {
+		s.WithError(err).Warn("Authentication error.")
+		// Hide the actual error
+		return nil, trace.AccessDenied("bad username or password")
+	}
+	operator := ops.OperatorWithACL(s.Operator, s.Users, user, checker)
+	ctx = context.WithValue(ctx, constants.UserContext, user.GetName())
+	ctx = context.WithValue(ctx, constants.OperatorContext, operator)
+	return ctx, nil
+}
+
+func operatorFromContext(ctx context.Context) ops.Operator {
+	return ctx.Value(constants.OperatorContext).(ops.Operator)
+}
+
+func userFromContext(ctx context.Context) string {
+	user, _ := ctx.Value(constants.UserContext).(string)
+	return user
+}
+
+// getCluster returns the cluster specified with key.
+// Returns the local cluster if the key does not specify the cluster name
+func getCluster(operator ops.Operator, key ops.SiteKey) (*ops.Site, error) {
+	if key.SiteDomain == "" {
+		return operator.GetLocalSite()
+	}
+	key, err := clusterKey(operator, key)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return operator.GetSite(key)
+}
+
+// clusterKey returns the specified cluster key with defaults set
+func clusterKey(operator ops.Operator, key ops.SiteKey) (ops.SiteKey, error) {
+	if key.SiteDomain == "" {
+		cluster, err := operator.GetLocalSite()
+		if err != nil {
+			return ops.SiteKey{}, trace.Wrap(err)
+		}
+		return cluster.Key(), nil
+	}
+	if key.AccountID == "" {
+		key.AccountID = defaults.SystemAccountID
+	}
+	return key, nil
+}
+
+// operationKey converts the specified operation key to internal format
+// with defaults set
+func eventsRequest(operator ops.Operator, req *pb.GetEventsRequest) (*ops.GetClusterEventsRequest, error) {
+	key, err := clusterKey(operator, pb.ClusterKeyFromProto(req.Key))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &ops.GetClusterEventsRequest{
+		SiteKey: key,
+		After:   req.After,
+		Limit:   int(req.Limit),
+	}, nil
+}
+
+func operationKey(operator ops.Operator, key *pb.OperationKey) (ops.SiteOperationKey, error) {
+	opKey := pb.OperationKeyFromProto(key)
+	if opKey.OperationID == "" {
+		return ops.SiteOperationKey{}, trace.BadParameter("missing operation ID")
+	}
+	siteKey, err := clusterKey(operator, opKey.SiteKey())
+	if err != nil {
+		return ops.SiteOperationKey{}, trace.Wrap(err)
+	}
+	opKey.AccountID = siteKey.AccountID
+	opKey.SiteDomain = siteKey.SiteDomain
+	return opKey, nil
+}
+
+// operationResources handles the resources that are updated with
+// cluster operations.
+// These resources are not supported by the API since managing the
+// operations requires access to the cluster nodes
+type operationResources struct{}
+
+// UpdateResource returns an error
+func (operationResources) UpdateResource(req resources.CreateRequest) error {
+	return trace.NotImplemented("resource %q can only be updated with gravity resource create",
+		req.Resource.Kind)
+}
+
+// RemoveResource returns an error
+func (operationResources) RemoveResource(req resources.RemoveRequest) error {
+	return trace.NotImplemented("resource %q can only be removed with gravity resource rm",
+		req.Kind)
+}
+
+// serverStream overrides the context of the wrapped stream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context of this stream
+func (r *serverStream) Context() context.Context {
+	return r.ctx
+}
+
+// authorizationKey is the metadata key with the client credentials
+const authorizationKey = "authorization"
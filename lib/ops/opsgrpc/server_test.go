@@ -0,0 +1,325 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsgrpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gravitational/gravity/lib/ops"
+	pb "github.com/gravitational/gravity/lib/ops/opsgrpc/proto"
+	"github.com/gravitational/gravity/lib/ops/opsservice"
+	"github.com/gravitational/gravity/lib/ops/suite"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/users"
+
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+func TestOpsGRPC(t *testing.T) { TestingT(t) }
+
+type ServerSuite struct {
+	services   opsservice.TestServices
+	webServer  *httptest.Server
+	client     *Client
+	clusterKey *pb.ClusterKey
+	operation  storage.SiteOperation
+}
+
+var _ = Suite(&ServerSuite{})
+
+func (s *ServerSuite) SetUpTest(c *C) {
+	s.services = opsservice.SetupTestServices(c)
+	role, err := users.NewAdminRole()
+	c.Assert(err, IsNil)
+	c.Assert(s.services.Users.UpsertRole(role, storage.Forever), IsNil)
+	c.Assert(s.services.Users.UpsertUser(storage.NewUser("admin@example.com", storage.UserSpecV2{
+		Password: "admin-password",
+		Type:     storage.AdminUser,
+		Roles:    []string{role.GetName()},
+	})), IsNil)
+
+	app := suite.SetUpTestPackage(c, s.services.Apps, s.services.Packages)
+	account, err := s.services.Operator.CreateAccount(ops.NewAccountRequest{Org: "example.com"})
+	c.Assert(err, IsNil)
+	cluster, err := s.services.Operator.CreateSite(ops.NewSiteRequest{
+		AppPackage: app.String(),
+		AccountID:  account.ID,
+		Provider:   schema.ProviderOnPrem,
+		DomainName: "example.com",
+	})
+	c.Assert(err, IsNil)
+	s.clusterKey = &pb.ClusterKey{AccountId: account.ID, ClusterName: cluster.Domain}
+
+	created := time.Date(2019, time.June, 1, 10, 0, 0, 0, time.UTC)
+	operation, err := s.services.Backend.CreateSiteOperation(storage.SiteOperation{
+		ID:         "op-1",
+		AccountID:  account.ID,
+		SiteDomain: cluster.Domain,
+		Type:       ops.OperationUpdate,
+		State:      ops.OperationStateUpdateInProgress,
+		Created:    created,
+		Servers:    []storage.Server{{Hostname: "node-1", AdvertiseIP: "10.0.0.1", Role: "node"}},
+	})
+	c.Assert(err, IsNil)
+	s.operation = *operation
+	_, err = s.services.Backend.CreateProgressEntry(storage.ProgressEntry{
+		SiteDomain:  cluster.Domain,
+		OperationID: operation.ID,
+		Created:     created,
+		Completion:  10,
+		State:       ops.ProgressStateInProgress,
+		Message:     "Updating node-1",
+	})
+	c.Assert(err, IsNil)
+	_, err = s.services.Backend.CreateOperationPlan(storage.OperationPlan{
+		OperationID:   operation.ID,
+		OperationType: operation.Type,
+		AccountID:     account.ID,
+		ClusterName:   cluster.Domain,
+		CreatedAt:     created,
+		Phases: []storage.OperationPhase{{
+			ID:    "/init",
+			State: storage.OperationPhaseStateCompleted,
+			Phases: []storage.OperationPhase{{
+				ID:    "/init/node-1",
+				State: storage.OperationPhaseStateCompleted,
+			}},
+		}, {
+			ID:       "/masters",
+			State:    storage.OperationPhaseStateUnstarted,
+			Requires: []string{"/init"},
+		}},
+	})
+	c.Assert(err, IsNil)
+
+	server, err := New(Config{
+		Operator:             s.services.Operator,
+		Users:                s.services.Users,
+		ProgressPollInterval: 10 * time.Millisecond,
+		EventsPollInterval:   10 * time.Millisecond,
+	})
+	c.Assert(err, IsNil)
+	s.webServer = httptest.NewUnstartedServer(server.Handler(http.NotFoundHandler()))
+	s.webServer.EnableHTTP2 = true
+	s.webServer.StartTLS()
+
+	s.client = s.newClient(c, ClientConfig{Username: "admin@example.com", Password: "admin-password"})
+}
+
+func (s *ServerSuite) TearDownTest(c *C) {
+	if s.client != nil {
+		s.client.Close()
+	}
+	if s.webServer != nil {
+		s.webServer.Close()
+	}
+	if s.services.Backend != nil {
+		s.services.Backend.Close()
+	}
+}
+
+func (s *ServerSuite) TestRejectsUnauthenticatedRequests(c *C) {
+	client := s.newClient(c, ClientConfig{Username: "admin@example.com", Password: "invalid"})
+	defer client.Close()
+	_, err := client.GetCluster(context.TODO(), s.clusterKey)
+	err = ConvertError(err)
+	c.Assert(trace.IsAccessDenied(err), Equals, true, Commentf("%v", err))
+}
+
+func (s *ServerSuite) TestGetsCluster(c *C) {
+	cluster, err := s.client.GetCluster(context.TODO(), s.clusterKey)
+	c.Assert(err, IsNil)
+	c.Assert(cluster.Name, Equals, "example.com")
+	c.Assert(cluster.AccountId, Equals, s.clusterKey.AccountId)
+	c.Assert(cluster.State, Equals, ops.SiteStateNotInstalled)
+
+	_, err = s.client.GetCluster(context.TODO(), &pb.ClusterKey{
+		AccountId:   s.clusterKey.AccountId,
+		ClusterName: "unknown.example.com",
+	})
+	err = ConvertError(err)
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%v", err))
+}
+
+func (s *ServerSuite) TestGetsOperations(c *C) {
+	operations, err := s.client.GetOperations(context.TODO(), &pb.GetOperationsRequest{
+		Key:    s.clusterKey,
+		Active: true,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(operations.Items, HasLen, 1)
+	operation := operations.Items[0]
+	c.Assert(operation.Key.Id, Equals, s.operation.ID)
+	c.Assert(operation.Type, Equals, ops.OperationUpdate)
+	c.Assert(operation.State, Equals, ops.OperationStateUpdateInProgress)
+	c.Assert(operation.Servers, DeepEquals, []*pb.Server{{Hostname: "node-1", AdvertiseIp: "10.0.0.1", Role: "node"}})
+	var raw storage.SiteOperation
+	c.Assert(json.Unmarshal(operation.Raw, &raw), IsNil)
+	c.Assert(raw.ID, Equals, s.operation.ID)
+
+	operations, err = s.client.GetOperations(context.TODO(), &pb.GetOperationsRequest{
+		Key:  s.clusterKey,
+		Type: ops.OperationExpand,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(operations.Items, HasLen, 0)
+
+	key := s.operationKey()
+	operation, err = s.client.GetOperation(context.TODO(), key)
+	c.Assert(err, IsNil)
+	c.Assert(operation.Key.String(), Equals, key.String())
+
+	progress, err := s.client.GetOperationProgress(context.TODO(), key)
+	c.Assert(err, IsNil)
+	c.Assert(progress.Completion, Equals, int32(10))
+	c.Assert(progress.Message, Equals, "Updating node-1")
+}
+
+func (s *ServerSuite) TestGetsOperationPlan(c *C) {
+	plan, err := s.client.GetOperationPlan(context.TODO(), s.operationKey())
+	c.Assert(err, IsNil)
+	c.Assert(plan.OperationType, Equals, ops.OperationUpdate)
+	c.Assert(plan.Phases, HasLen, 2)
+	c.Assert(plan.Phases[0].Id, Equals, "/init")
+	c.Assert(plan.Phases[0].Phases, HasLen, 1)
+	c.Assert(plan.Phases[0].Phases[0].State, Equals, storage.OperationPhaseStateCompleted)
+	c.Assert(plan.Phases[1].Requires, DeepEquals, []string{"/init"})
+}
+
+func (s *ServerSuite) TestWatchesOperationProgress(c *C) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	stream, err := s.client.WatchOperationProgress(ctx, s.operationKey())
+	c.Assert(err, IsNil)
+	progress, err := stream.Recv()
+	c.Assert(err, IsNil)
+	c.Assert(progress.Completion, Equals, int32(10))
+
+	_, err = s.services.Backend.CreateProgressEntry(storage.ProgressEntry{
+		SiteDomain:  s.operation.SiteDomain,
+		OperationID: s.operation.ID,
+		Created:     s.operation.Created.Add(time.Minute),
+		Completion:  100,
+		State:       ops.ProgressStateCompleted,
+		Message:     "Operation has completed",
+	})
+	c.Assert(err, IsNil)
+	progress, err = stream.Recv()
+	c.Assert(err, IsNil)
+	c.Assert(progress.State, Equals, ops.ProgressStateCompleted)
+	_, err = stream.Recv()
+	c.Assert(err, Equals, io.EOF)
+}
+
+func (s *ServerSuite) TestWatchesEvents(c *C) {
+	first, err := s.services.Backend.CreateClusterEvent(s.clusterKey.ClusterName, storage.ClusterEvent{
+		Name:   "operation.started",
+		Code:   "G0008I",
+		Fields: map[string]interface{}{"id": s.operation.ID},
+	}, time.Hour)
+	c.Assert(err, IsNil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	events, err := s.client.GetEvents(ctx, &pb.GetEventsRequest{Key: s.clusterKey})
+	c.Assert(err, IsNil)
+	c.Assert(events.Items, HasLen, 1)
+	c.Assert(events.Items[0].Id, Equals, first.ID)
+	c.Assert(string(events.Items[0].Fields), Equals, `{"id":"op-1"}`)
+
+	stream, err := s.client.WatchEvents(ctx, &pb.GetEventsRequest{Key: s.clusterKey, After: first.ID})
+	c.Assert(err, IsNil)
+	second, err := s.services.Backend.CreateClusterEvent(s.clusterKey.ClusterName, storage.ClusterEvent{
+		Name: "operation.completed",
+		Code: "G0008I",
+	}, time.Hour)
+	c.Assert(err, IsNil)
+	event, err := stream.Recv()
+	c.Assert(err, IsNil)
+	c.Assert(event.Id, Equals, second.ID)
+	c.Assert(event.Name, Equals, "operation.completed")
+}
+
+func (s *ServerSuite) TestManagesResources(c *C) {
+	ctx := context.TODO()
+	_, err := s.client.UpsertResource(ctx, &pb.UpsertResourceRequest{
+		Key: s.clusterKey,
+		Data: []byte(`kind: user
+version: v2
+metadata:
+  name: alice@example.com
+spec:
+  type: agent
+  roles: ["@teleadmin"]
+`),
+	})
+	c.Assert(err, IsNil)
+
+	resources, err := s.client.GetResources(ctx, &pb.GetResourcesRequest{
+		Key:  s.clusterKey,
+		Kind: "user",
+		Name: "alice@example.com",
+	})
+	c.Assert(err, IsNil)
+	c.Assert(resources.Items, HasLen, 1)
+	c.Assert(resources.Items[0].Kind, Equals, "user")
+	c.Assert(resources.Items[0].Name, Equals, "alice@example.com")
+	user, err := storage.UnmarshalUser(resources.Items[0].Data)
+	c.Assert(err, IsNil)
+	c.Assert(user.GetType(), Equals, storage.AgentUser)
+
+	_, err = s.client.RemoveResource(ctx, &pb.RemoveResourceRequest{
+		Key:  s.clusterKey,
+		Kind: "user",
+		Name: "alice@example.com",
+	})
+	c.Assert(err, IsNil)
+	_, err = s.services.Users.GetUser("alice@example.com")
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%v", err))
+
+	_, err = s.client.GetResources(ctx, &pb.GetResourcesRequest{Key: s.clusterKey, Kind: "unknown"})
+	err = ConvertError(err)
+	c.Assert(trace.IsBadParameter(err), Equals, true, Commentf("%v", err))
+}
+
+func (s *ServerSuite) operationKey() *pb.OperationKey {
+	return &pb.OperationKey{
+		AccountId:   s.clusterKey.AccountId,
+		ClusterName: s.clusterKey.ClusterName,
+		Id:          s.operation.ID,
+	}
+}
+
+func (s *ServerSuite) newClient(c *C, config ClientConfig) *Client {
+	roots := x509.NewCertPool()
+	roots.AddCert(s.webServer.Certificate())
+	config.Addr = s.webServer.Listener.Addr().String()
+	config.TLS = &tls.Config{RootCAs: roots}
+	client, err := NewClient(context.TODO(), config)
+	c.Assert(err, IsNil)
+	return client
+}
@@ -0,0 +1,369 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opshandler
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+	"unicode"
+
+	"github.com/gravitational/roundtrip"
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	// APIVersion is the current stable version of the operator HTTP API.
+	//
+	// Within a version, endpoints are only ever added: existing paths,
+	// parameters and response fields are neither removed nor changed
+	// in an incompatible way. Breaking changes require a new version
+	// which is served alongside the previous one for at least one
+	// major release
+	APIVersion = "v2"
+	// DeprecatedAPIVersion is the previous version of the operator HTTP API.
+	// It serves the same endpoints as APIVersion and marks every response
+	// with the Deprecation header and a link to its successor
+	DeprecatedAPIVersion = "v1"
+)
+
+// APIPrefix returns the URL path prefix of the specified API version
+func APIPrefix(version string) string {
+	return fmt.Sprintf("/portal/%v", version)
+}
+
+// route describes a single versioned API endpoint
+type route struct {
+	// method is the HTTP method
+	method string
+	// path is the endpoint path relative to the version prefix
+	path string
+	// operationID uniquely identifies the endpoint.
+	// It is derived from the name of the handler function
+	operationID string
+	// authenticated specifies whether the endpoint requires authentication
+	authenticated bool
+}
+
+// route registers the authenticated handler fn for the specified method and
+// path under all supported API versions.
+// The requests are subject to the rate limits and recorded in the audit log
+// if they modify the state
+func (h *WebHandler) route(method, path string, fn ServiceHandle) {
+	operationID := handlerName(fn)
+	h.register(route{
+		method:        method,
+		path:          path,
+		operationID:   operationID,
+		authenticated: true,
+	}, h.needsAuth(h.withLimits(method, operationID, fn)))
+}
+
+// publicRoute registers the handler for the specified method and path
+// under all supported API versions.
+// The handler is served without authentication
+func (h *WebHandler) publicRoute(method, path string, handler httprouter.Handle) {
+	h.register(route{
+		method:      method,
+		path:        path,
+		operationID: handlerName(handler),
+	}, handler)
+}
+
+func (h *WebHandler) register(r route, handler httprouter.Handle) {
+	h.routes = append(h.routes, r)
+	h.Handle(r.method, APIPrefix(APIVersion)+r.path, handler)
+	h.Handle(r.method, APIPrefix(DeprecatedAPIVersion)+r.path, deprecated(handler))
+}
+
+// deprecated wraps the handler to mark its responses as coming
+// from the deprecated API version
+func deprecated(handler httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", fmt.Sprintf(`<%v>; rel="successor-version"`, successorPath(r.URL.Path)))
+		handler(w, r, p)
+	}
+}
+
+// successorPath returns the path of the current API version endpoint
+// for the specified deprecated endpoint path
+func successorPath(path string) string {
+	return strings.Replace(path, APIPrefix(DeprecatedAPIVersion), APIPrefix(APIVersion), 1)
+}
+
+/*
+getOpenAPI returns the OpenAPI document describing the current API version
+
+	GET /portal/v2/openapi.json
+*/
+func (h *WebHandler) getOpenAPI(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	roundtrip.ReplyJSON(w, http.StatusOK, h.OpenAPI())
+}
+
+// OpenAPI returns the OpenAPI document describing the current API version
+// generated from the registered endpoints
+func (h *WebHandler) OpenAPI() OpenAPIDocument {
+	doc := OpenAPIDocument{
+		OpenAPI: "3.0.3",
+		Info: OpenAPIInfo{
+			Title:   "Gravity Cluster API",
+			Version: APIVersion,
+		},
+		Servers: []OpenAPIServer{{URL: APIPrefix(APIVersion)}},
+		Paths:   make(map[string]map[string]OpenAPIOperation),
+		Components: OpenAPIComponents{
+			Schemas: map[string]OpenAPISchema{
+				"Error": {
+					Type: "object",
+					Properties: map[string]OpenAPISchema{
+						"message": {Type: "string"},
+					},
+				},
+			},
+			SecuritySchemes: map[string]OpenAPISecurityScheme{
+				"basicAuth":  {Type: "http", Scheme: "basic"},
+				"bearerAuth": {Type: "http", Scheme: "bearer"},
+			},
+		},
+	}
+	for _, r := range h.routes {
+		path, params := openAPIPath(r.path)
+		operation := OpenAPIOperation{
+			OperationID: r.operationID,
+			Summary:     summary(r.operationID),
+			Tags:        []string{tag(r.path)},
+			Parameters:  params,
+			Responses: map[string]OpenAPIResponse{
+				"200": {
+					Description: "Success",
+					Content:     jsonContent(OpenAPISchema{Type: "object"}),
+				},
+				"default": {
+					Description: "Error",
+					Content:     jsonContent(OpenAPISchema{Ref: "#/components/schemas/Error"}),
+				},
+			},
+		}
+		if r.method == http.MethodPost || r.method == http.MethodPut {
+			operation.RequestBody = &OpenAPIRequestBody{
+				Content: jsonContent(OpenAPISchema{Type: "object"}),
+			}
+		}
+		if r.authenticated {
+			operation.Security = []map[string][]string{
+				{"basicAuth": {}},
+				{"bearerAuth": {}},
+			}
+		}
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]OpenAPIOperation)
+		}
+		doc.Paths[path][strings.ToLower(r.method)] = operation
+	}
+	return doc
+}
+
+// openAPIPath converts the router path to the OpenAPI path template
+// and returns it along with the list of path parameters
+func openAPIPath(path string) (template string, params []OpenAPIParameter) {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		name := strings.TrimPrefix(segment, ":")
+		segments[i] = fmt.Sprintf("{%v}", name)
+		params = append(params, OpenAPIParameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   OpenAPISchema{Type: "string"},
+		})
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// tag returns the tag to group the endpoint with the specified path under.
+// Cluster endpoints are grouped by the first path segment after the cluster
+func tag(path string) string {
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, segment := range segments {
+		if segment != ":site_domain" {
+			continue
+		}
+		if i+1 < len(segments) {
+			return segments[i+1]
+		}
+		return "sites"
+	}
+	return segments[0]
+}
+
+// summary converts the camel-cased handler name into a sentence,
+// e.g. getSMTPConfig -> Get SMTP config
+func summary(operationID string) string {
+	var words []string
+	runes := []rune(operationID)
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		if i < len(runes) && !isWordBoundary(runes, i) {
+			continue
+		}
+		word := string(runes[start:i])
+		if strings.ToUpper(word) != word || len(word) == 1 {
+			word = strings.ToLower(word)
+		}
+		words = append(words, word)
+		start = i
+	}
+	if len(words) == 0 {
+		return ""
+	}
+	first := []rune(words[0])
+	first[0] = unicode.ToUpper(first[0])
+	words[0] = string(first)
+	return strings.Join(words, " ")
+}
+
+func isWordBoundary(runes []rune, i int) bool {
+	if !unicode.IsUpper(runes[i]) {
+		return false
+	}
+	if !unicode.IsUpper(runes[i-1]) {
+		return true
+	}
+	// the last letter of an acronym followed by a new word
+	return i+1 < len(runes) && unicode.IsLower(runes[i+1])
+}
+
+// handlerName returns the name of the specified handler method
+func handlerName(handler interface{}) string {
+	name := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+func jsonContent(schema OpenAPISchema) map[string]OpenAPIMediaType {
+	return map[string]OpenAPIMediaType{"application/json": {Schema: schema}}
+}
+
+// OpenAPIDocument is the OpenAPI 3.0 document that describes the API
+type OpenAPIDocument struct {
+	// OpenAPI is the OpenAPI specification version
+	OpenAPI string `json:"openapi"`
+	// Info describes the API
+	Info OpenAPIInfo `json:"info"`
+	// Servers lists the API base URLs
+	Servers []OpenAPIServer `json:"servers"`
+	// Paths maps endpoint paths to operations keyed by HTTP method
+	Paths map[string]map[string]OpenAPIOperation `json:"paths"`
+	// Components lists reusable schemas and security schemes
+	Components OpenAPIComponents `json:"components"`
+}
+
+// OpenAPIInfo describes the API
+type OpenAPIInfo struct {
+	// Title is the API title
+	Title string `json:"title"`
+	// Version is the API version
+	Version string `json:"version"`
+}
+
+// OpenAPIServer describes the API base URL
+type OpenAPIServer struct {
+	// URL is the API base URL
+	URL string `json:"url"`
+}
+
+// OpenAPIOperation describes a single API endpoint
+type OpenAPIOperation struct {
+	// OperationID uniquely identifies the endpoint
+	OperationID string `json:"operationId"`
+	// Summary is a short description of the endpoint
+	Summary string `json:"summary"`
+	// Tags groups related endpoints
+	Tags []string `json:"tags,omitempty"`
+	// Parameters lists the endpoint parameters
+	Parameters []OpenAPIParameter `json:"parameters,omitempty"`
+	// RequestBody describes the request body
+	RequestBody *OpenAPIRequestBody `json:"requestBody,omitempty"`
+	// Responses maps status codes to responses
+	Responses map[string]OpenAPIResponse `json:"responses"`
+	// Security lists alternative security requirements
+	Security []map[string][]string `json:"security,omitempty"`
+}
+
+// OpenAPIParameter describes an endpoint parameter
+type OpenAPIParameter struct {
+	// Name is the parameter name
+	Name string `json:"name"`
+	// In is the parameter location
+	In string `json:"in"`
+	// Required specifies whether the parameter is mandatory
+	Required bool `json:"required"`
+	// Schema is the parameter schema
+	Schema OpenAPISchema `json:"schema"`
+}
+
+// OpenAPIRequestBody describes the request body
+type OpenAPIRequestBody struct {
+	// Content maps media types to request payloads
+	Content map[string]OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse describes an endpoint response
+type OpenAPIResponse struct {
+	// Description describes the response
+	Description string `json:"description"`
+	// Content maps media types to response payloads
+	Content map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIMediaType describes the payload of a specific media type
+type OpenAPIMediaType struct {
+	// Schema is the payload schema
+	Schema OpenAPISchema `json:"schema"`
+}
+
+// OpenAPISchema describes a data type
+type OpenAPISchema struct {
+	// Ref references a schema from components
+	Ref string `json:"$ref,omitempty"`
+	// Type is the data type
+	Type string `json:"type,omitempty"`
+	// Properties lists object properties
+	Properties map[string]OpenAPISchema `json:"properties,omitempty"`
+}
+
+// OpenAPIComponents lists reusable document components
+type OpenAPIComponents struct {
+	// Schemas lists reusable data types
+	Schemas map[string]OpenAPISchema `json:"schemas"`
+	// SecuritySchemes lists supported authentication methods
+	SecuritySchemes map[string]OpenAPISecurityScheme `json:"securitySchemes"`
+}
+
+// OpenAPISecurityScheme describes an authentication method
+type OpenAPISecurityScheme struct {
+	// Type is the security scheme type
+	Type string `json:"type"`
+	// Scheme is the HTTP authorization scheme
+	Scheme string `json:"scheme"`
+}
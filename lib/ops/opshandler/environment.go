@@ -62,6 +62,74 @@ func (h *WebHandler) createUpdateEnvarsOperation(w http.ResponseWriter, r *http.
 	return nil
 }
 
+/* createUpdateNodeRoleOperation initiates the operation of promoting or demoting a cluster node
+
+   POST /portal/v1/accounts/:account_id/sites/:site_domain/operations/noderole
+
+   {
+      "server": "<hostname>",
+      "cluster_role": "master|node"
+   }
+
+
+Success response:
+
+   {
+      "account_id": "account id",
+      "site_id": "site_id",
+      "operation_id": "operation id"
+   }
+*/
+func (h *WebHandler) createUpdateNodeRoleOperation(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	d := json.NewDecoder(r.Body)
+	var req ops.CreateUpdateNodeRoleOperationRequest
+	if err := d.Decode(&req); err != nil {
+		return trace.BadParameter(err.Error())
+	}
+	req.ClusterKey = siteKey(p)
+	op, err := context.Operator.CreateUpdateNodeRoleOperation(r.Context(), req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, op)
+	return nil
+}
+
+/* createReplaceNodeOperation initiates the operation of replacing a cluster node with a new one
+
+   POST /portal/v1/accounts/:account_id/sites/:site_domain/operations/replacenode
+
+   {
+      "server": "<hostname>",
+      "new_addr": "<ip>",
+      "ssh": {"user": "<user>", "port": 22, "private_key_path": "<path>"},
+      "force": false
+   }
+
+
+Success response:
+
+   {
+      "account_id": "account id",
+      "site_id": "site_id",
+      "operation_id": "operation id"
+   }
+*/
+func (h *WebHandler) createReplaceNodeOperation(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	d := json.NewDecoder(r.Body)
+	var req ops.CreateReplaceNodeOperationRequest
+	if err := d.Decode(&req); err != nil {
+		return trace.BadParameter(err.Error())
+	}
+	req.ClusterKey = siteKey(p)
+	op, err := context.Operator.CreateReplaceNodeOperation(r.Context(), req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, op)
+	return nil
+}
+
 /* getEnvironmentVariables fetches the cluster environment variables
 
      GET /portal/v1/accounts/:account_id/sites/:site_domain/envars
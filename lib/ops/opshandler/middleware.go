@@ -0,0 +1,243 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opshandler
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/events"
+	"github.com/gravitational/gravity/lib/utils"
+
+	teleevents "github.com/gravitational/teleport/lib/events"
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+// RateLimit defines the rate of API requests allowed for a single user
+type RateLimit struct {
+	// Rate is the number of requests per second.
+	// Zero rate does not limit the requests
+	Rate float64
+	// Burst is the maximum number of requests allowed at once.
+	// Defaults to the rate rounded up
+	Burst int
+}
+
+// IsEmpty returns true if the requests are not limited
+func (r RateLimit) IsEmpty() bool {
+	return r.Rate == 0
+}
+
+// Check validates the rate limit
+func (r RateLimit) Check() error {
+	if r.Rate < 0 {
+		return trace.BadParameter("rate limit can not be negative")
+	}
+	if r.Burst < 0 {
+		return trace.BadParameter("rate limit burst can not be negative")
+	}
+	return nil
+}
+
+// burst returns the burst of the rate limit with defaults applied
+func (r RateLimit) burst() int {
+	if r.Burst != 0 {
+		return r.Burst
+	}
+	return int(math.Ceil(r.Rate))
+}
+
+// RateLimitConfig limits the rate of API requests per user
+type RateLimitConfig struct {
+	// RateLimit is the limit applied to every user
+	RateLimit
+	// Users overrides the limit for specific users, e.g. service accounts
+	// used by automation
+	Users map[string]RateLimit
+}
+
+// Check validates the rate limit configuration
+func (r RateLimitConfig) Check() error {
+	if err := r.RateLimit.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	for user, limit := range r.Users {
+		if err := limit.Check(); err != nil {
+			return trace.Wrap(err, "invalid rate limit for user %v", user)
+		}
+	}
+	return nil
+}
+
+// limitFor returns the rate limit for the specified user
+func (r RateLimitConfig) limitFor(user string) RateLimit {
+	if limit, ok := r.Users[user]; ok {
+		return limit
+	}
+	return r.RateLimit
+}
+
+// newRateLimiter returns a new rate limiter for the specified configuration
+func newRateLimiter(config RateLimitConfig, clock clockwork.Clock) *rateLimiter {
+	return &rateLimiter{
+		config:   config,
+		clock:    clock,
+		limiters: make(map[string]*rate.Limiter),
+	}
+}
+
+// rateLimiter keeps a token bucket per user
+type rateLimiter struct {
+	sync.Mutex
+	config   RateLimitConfig
+	clock    clockwork.Clock
+	limiters map[string]*rate.Limiter
+}
+
+// allow returns true if the specified user can make another request now
+func (r *rateLimiter) allow(user string) bool {
+	limit := r.config.limitFor(user)
+	if limit.IsEmpty() {
+		return true
+	}
+	r.Lock()
+	limiter, ok := r.limiters[user]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Limit(limit.Rate), limit.burst())
+		r.limiters[user] = limiter
+	}
+	r.Unlock()
+	return limiter.AllowN(r.clock.Now(), 1)
+}
+
+// withLimits wraps the handler fn to enforce the request rate limits
+// and record the requests in the audit log.
+// The requests of the authenticated user are limited and recorded
+// after the authentication so the records identify the user
+func (h *WebHandler) withLimits(method, operationID string, fn ServiceHandle) ServiceHandle {
+	audited := h.isAudited(method, operationID)
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+		start := h.cfg.Clock.Now()
+		user := context.User.GetName()
+		var err error
+		if h.limiter.allow(user) {
+			err = fn(w, r, p, context)
+		} else {
+			err = trace.LimitExceeded("rate limit exceeded for user %v", user)
+		}
+		if audited {
+			h.auditor.record(requestEvent(r, operationID, user, h.cfg.Clock.Since(start), err))
+		}
+		return trace.Wrap(err)
+	}
+}
+
+// isAudited returns true if the requests to the specified endpoint
+// are recorded in the audit log.
+// Only the requests that modify the state are recorded unless the
+// read-only requests have been explicitly requested to be recorded
+// as well, since the agents poll the API continuously
+func (h *WebHandler) isAudited(method, operationID string) bool {
+	if h.cfg.DisableAudit || utils.StringInSlice(unauditedOperations, operationID) {
+		return false
+	}
+	if method == http.MethodGet || method == http.MethodHead {
+		return h.cfg.AuditReads
+	}
+	return true
+}
+
+// requestEvent returns the audit log record of the completed API request
+func requestEvent(r *http.Request, operationID, user string, latency time.Duration, err error) auditRecord {
+	record := auditRecord{
+		event: events.APIRequest,
+		fields: events.Fields{
+			events.FieldUser:     user,
+			events.FieldVerb:     r.Method,
+			events.FieldResource: operationID,
+			events.FieldPath:     r.URL.Path,
+			events.FieldStatus:   http.StatusOK,
+			events.FieldLatency:  latency.Nanoseconds() / int64(time.Millisecond),
+		},
+	}
+	if err != nil {
+		record.event = events.APIRequestFailure
+		record.fields[events.FieldStatus] = trace.ErrorToCode(err)
+		record.fields[events.FieldError] = trace.UserMessage(err)
+	}
+	return record
+}
+
+// auditRecord is an API request record to save in the audit log
+type auditRecord struct {
+	event  teleevents.Event
+	fields events.Fields
+}
+
+// newRequestAuditor returns a new auditor that saves the records
+// with the specified operator in the background
+func newRequestAuditor(operator ops.Operator) *requestAuditor {
+	auditor := &requestAuditor{
+		operator: operator,
+		recordsC: make(chan auditRecord, auditQueueSize),
+	}
+	go auditor.loop()
+	return auditor
+}
+
+// requestAuditor saves the API request records in the audit log in the
+// background so the requests do not wait for the audit log writes
+type requestAuditor struct {
+	operator ops.Operator
+	recordsC chan auditRecord
+}
+
+// record queues the specified record to be saved in the audit log.
+// The record is dropped if the audit log can not keep up with the requests
+func (a *requestAuditor) record(record auditRecord) {
+	select {
+	case a.recordsC <- record:
+	default:
+		log.WithField("fields", record.fields).Warn("Audit queue is full, dropping API request record.")
+	}
+}
+
+func (a *requestAuditor) loop() {
+	for record := range a.recordsC {
+		events.Emit(context.TODO(), a.operator, record.event, record.fields)
+	}
+}
+
+// auditQueueSize is the maximum number of API request records
+// waiting to be saved in the audit log
+const auditQueueSize = 1024
+
+// unauditedOperations lists the API endpoints that are not recorded in
+// the audit log. Reading the audit log is not recorded as the readers
+// would otherwise receive the records of their own requests
+var unauditedOperations = []string{
+	"getClusterEvents",
+	"streamClusterEvents",
+}
@@ -49,6 +49,7 @@ import (
 	"github.com/jonboulle/clockwork"
 	"github.com/julienschmidt/httprouter"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/websocket"
 )
 
 // WebHandlerConfig is the ops web handler configuration
@@ -69,6 +70,16 @@ type WebHandlerConfig struct {
 	Devmode bool
 	// PublicAdvertiseAddr is the process public advertise address
 	PublicAdvertiseAddr teleutils.NetAddr
+	// RateLimit limits the rate of API requests per user.
+	// The requests are not limited if unspecified
+	RateLimit RateLimitConfig
+	// DisableAudit turns off recording of API requests in the audit log
+	DisableAudit bool
+	// AuditReads enables recording of the read-only API requests in the audit log.
+	// Only the requests that modify the state are recorded by default
+	AuditReads bool
+	// Clock is used to measure request latency and rate limits
+	Clock clockwork.Clock
 }
 
 // CheckAndSetDefaults validates the config and sets some defaults.
@@ -88,6 +99,12 @@ func (c *WebHandlerConfig) CheckAndSetDefaults() error {
 	if c.Authenticator == nil {
 		c.Authenticator = users.NewAuthenticatorFromIdentity(c.Users)
 	}
+	if err := c.RateLimit.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	if c.Clock == nil {
+		c.Clock = clockwork.NewRealClock()
+	}
 	return nil
 }
 
@@ -95,6 +112,12 @@ type WebHandler struct {
 	httprouter.Router
 	cfg        WebHandlerConfig
 	middleware *auth.AuthMiddleware
+	// limiter limits the rate of API requests per user
+	limiter *rateLimiter
+	// auditor records the API requests in the audit log
+	auditor *requestAuditor
+	// routes lists the registered versioned API endpoints
+	routes []route
 }
 
 // GetConfig returns config web handler was initialized with
@@ -108,7 +131,9 @@ func NewWebHandler(cfg WebHandlerConfig) (*WebHandler, error) {
 	}
 
 	h := &WebHandler{
-		cfg: cfg,
+		cfg:     cfg,
+		limiter: newRateLimiter(cfg.RateLimit, cfg.Clock),
+		auditor: newRequestAuditor(cfg.Operator),
 	}
 
 	// Wrap the router in the authentication middleware which will detect
@@ -122,208 +147,290 @@ func NewWebHandler(cfg WebHandlerConfig) (*WebHandler, error) {
 	h.OPTIONS("/*path", h.options)
 
 	// Report portal status
-	h.GET("/portal/v1/status", h.getStatus)
+	h.publicRoute(http.MethodGet, "/status", h.getStatus)
+
+	// API specification
+	h.GET(APIPrefix(APIVersion)+"/openapi.json", h.getOpenAPI)
 
 	// Applications API
-	h.GET("/portal/v1/apps", h.needsAuth(h.getApps))
-	h.GET("/portal/v1/gravity", h.needsAuth(h.getGravityBinary))
+	h.route(http.MethodGet, "/apps", h.getApps)
+	h.route(http.MethodGet, "/gravity", h.getGravityBinary)
 
 	// Accounts API
-	h.POST("/portal/v1/accounts", h.needsAuth(h.createAccount))
-	h.GET("/portal/v1/accounts/:account_id", h.needsAuth(h.getAccount))
-	h.GET("/portal/v1/accounts", h.needsAuth(h.getAccounts))
+	h.route(http.MethodPost, "/accounts", h.createAccount)
+	h.route(http.MethodGet, "/accounts/:account_id", h.getAccount)
+	h.route(http.MethodGet, "/accounts", h.getAccounts)
 
 	// Users API
-	h.GET("/portal/v1/currentuser", h.needsAuth(h.getCurrentUser))
-	h.GET("/portal/v1/currentuserinfo", h.needsAuth(h.getCurrentUserInfo))
-	h.POST("/portal/v1/users", h.needsAuth(h.createUser))
-	h.DELETE("/portal/v1/users/:user_email", h.needsAuth(h.deleteLocalUser))
-	h.PUT("/portal/v1/accounts/:account_id/sites/:site_domain/users/:user_email", h.needsAuth(h.updateUser))
+	h.route(http.MethodGet, "/currentuser", h.getCurrentUser)
+	h.route(http.MethodGet, "/currentuserinfo", h.getCurrentUserInfo)
+	h.route(http.MethodPost, "/users", h.createUser)
+	h.route(http.MethodDelete, "/users/:user_email", h.deleteLocalUser)
+	h.route(http.MethodPut, "/accounts/:account_id/sites/:site_domain/users/:user_email", h.updateUser)
 
 	// API keys API
-	h.POST("/portal/v1/apikeys/user/:user_email", h.needsAuth(h.createAPIKey))
-	h.GET("/portal/v1/apikeys/user/:user_email", h.needsAuth(h.getAPIKeys))
-	h.DELETE("/portal/v1/apikeys/user/:user_email/:api_key", h.needsAuth(h.deleteAPIKey))
+	h.route(http.MethodPost, "/apikeys/user/:user_email", h.createAPIKey)
+	h.route(http.MethodGet, "/apikeys/user/:user_email", h.getAPIKeys)
+	h.route(http.MethodDelete, "/apikeys/user/:user_email/:api_key", h.deleteAPIKey)
 
 	// Invites API
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/tokens/userinvites", h.needsAuth(h.createUserInvite))
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/tokens/userinvites", h.needsAuth(h.getUserInvites))
-	h.DELETE("/portal/v1/accounts/:account_id/sites/:site_domain/tokens/userinvites/:name", h.needsAuth(h.deleteUserInvite))
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/tokens/userinvites", h.createUserInvite)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/tokens/userinvites", h.getUserInvites)
+	h.route(http.MethodDelete, "/accounts/:account_id/sites/:site_domain/tokens/userinvites/:name", h.deleteUserInvite)
 
 	// Tokens API
-	h.POST("/portal/v1/tokens/install", h.needsAuth(h.createInstallToken))
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/tokens/userresets", h.needsAuth(h.resetUser))
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/tokens/provision", h.needsAuth(h.createProvisioningToken))
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/tokens/expand", h.needsAuth(h.getExpandToken))
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/tokens/trustedcluster", h.needsAuth(h.getTrustedClusterToken))
+	h.route(http.MethodPost, "/tokens/install", h.createInstallToken)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/tokens/userresets", h.resetUser)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/tokens/provision", h.createProvisioningToken)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/tokens/expand", h.getExpandToken)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/tokens/trustedcluster", h.getTrustedClusterToken)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/tokens/join", h.createJoinToken)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/tokens/join", h.getJoinTokens)
+	h.route(http.MethodDelete, "/accounts/:account_id/sites/:site_domain/tokens/join/:token", h.deleteJoinToken)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/scaleup", h.requestScaleUp)
 
 	// Sites API
-	h.GET("/portal/v1/localsite", h.needsAuth(h.getLocalSite))
-	h.POST("/portal/v1/accounts/:account_id/sites", h.needsAuth(h.createSite))
-	h.DELETE("/portal/v1/accounts/:account_id/sites/:site_domain", h.needsAuth(h.deleteSite))
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain", h.needsAuth(h.getSite))
-	h.GET("/portal/v1/accounts/:account_id/sites", h.needsAuth(h.getSites))
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/report", h.needsAuth(h.getSiteReport))
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/deactivate", h.needsAuth(h.deactivateSite))
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/activate", h.needsAuth(h.activateSite))
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/complete", h.needsAuth(h.completeFinalInstallStep))
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/localuser", h.needsAuth(h.getLocalUser))
-	h.PUT("/portal/v1/accounts/:account_id/sites/:site_domain/reset-password", h.needsAuth(h.resetUserPassword))
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/agent", h.needsAuth(h.getClusterAgent))
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/nodes", h.needsAuth(h.getClusterNodes))
+	h.route(http.MethodGet, "/localsite", h.getLocalSite)
+	h.route(http.MethodPost, "/accounts/:account_id/sites", h.createSite)
+	h.route(http.MethodDelete, "/accounts/:account_id/sites/:site_domain", h.deleteSite)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain", h.getSite)
+	h.route(http.MethodGet, "/accounts/:account_id/sites", h.getSites)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/report", h.getSiteReport)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/deactivate", h.deactivateSite)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/activate", h.activateSite)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/complete", h.completeFinalInstallStep)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/localuser", h.getLocalUser)
+	h.route(http.MethodPut, "/accounts/:account_id/sites/:site_domain/reset-password", h.resetUserPassword)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/agent", h.getClusterAgent)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/nodes", h.getClusterNodes)
 
 	// Status API
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/status", h.needsAuth(h.checkSiteStatus))
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/status", h.checkSiteStatus)
 
 	// TODO(klizhetas) refactor this method
-	h.GET("/portal/v1/sites/domain/:domain", h.needsAuth(h.getSiteByDomain))
-	h.GET("/portal/v1/domains/:domain", h.needsAuth(h.validateDomainName))
+	h.route(http.MethodGet, "/sites/domain/:domain", h.getSiteByDomain)
+	h.route(http.MethodGet, "/domains/:domain", h.validateDomainName)
 
 	// Leadership API
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/stepdown", h.needsAuth(h.stepDown))
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/stepdown", h.stepDown)
 
 	// Sign API
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/sign/tls", h.needsAuth(h.signTLSKey))
-	h.POST("/portal/v1/accounts/:account_id/sign/ssh", h.needsAuth(h.signSSHKey))
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/sign/tls", h.signTLSKey)
+	h.route(http.MethodPost, "/accounts/:account_id/sign/ssh", h.signSSHKey)
 
 	// Cluster certificate API
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/certificate", h.needsAuth(h.getClusterCert))
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/certificate", h.needsAuth(h.updateClusterCert))
-	h.DELETE("/portal/v1/accounts/:account_id/sites/:site_domain/certificate", h.needsAuth(h.deleteClusterCert))
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/certificate", h.getClusterCert)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/certificate", h.updateClusterCert)
+	h.route(http.MethodDelete, "/accounts/:account_id/sites/:site_domain/certificate", h.deleteClusterCert)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/certificates/expiry", h.getCertificateExpiry)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/rotatecerts", h.createRotateCertificatesOperation)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/rotatesecretskey", h.createRotateSecretsKeyOperation)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/changecidr", h.createChangeCIDROperation)
 
 	// Prechecks API
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/prechecks", h.needsAuth(h.validateServers))
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/prechecks", h.validateServers)
 
 	// Site Operations API
 
 	// generate agent instructions - compact form
 	h.GET("/t/:token/:server_profile", h.getSiteInstructions)
 	// generate agent instructions
-	h.GET("/portal/v1/tokens/:token/:server_profile", h.getSiteInstructions)
+	h.publicRoute(http.MethodGet, "/tokens/:token/:server_profile", h.getSiteInstructions)
 
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/operations/install", h.needsAuth(h.createSiteInstallOperation))
-	h.PUT("/portal/v1/accounts/:account_id/sites/:site_domain/operations/install/:operation_id", h.needsAuth(h.updateInstallOperation))
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/operations/install/:operation_id/agent-report", h.needsAuth(h.getSiteInstallOperationAgentReport))
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/operations/install/:operation_id/start", h.needsAuth(h.siteInstallOperationStart))
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/install", h.createSiteInstallOperation)
+	h.route(http.MethodPut, "/accounts/:account_id/sites/:site_domain/operations/install/:operation_id", h.updateInstallOperation)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/operations/install/:operation_id/agent-report", h.getSiteInstallOperationAgentReport)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/install/:operation_id/start", h.siteInstallOperationStart)
 
 	// expand - add nodes to the cluster
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/operations/expand", h.needsAuth(h.createSiteExpandOperation))
-	h.PUT("/portal/v1/accounts/:account_id/sites/:site_domain/operations/expand/:operation_id", h.needsAuth(h.updateExpandOperation))
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/operations/expand/:operation_id/agent-report", h.needsAuth(h.getSiteExpandOperationAgentReport))
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/operations/expand/:operation_id/start", h.needsAuth(h.siteExpandOperationStart))
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/expand", h.createSiteExpandOperation)
+	h.route(http.MethodPut, "/accounts/:account_id/sites/:site_domain/operations/expand/:operation_id", h.updateExpandOperation)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/operations/expand/:operation_id/agent-report", h.getSiteExpandOperationAgentReport)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/expand/:operation_id/start", h.siteExpandOperationStart)
 
 	// uninstall - nuke everything
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/operations/uninstall", h.needsAuth(h.createSiteUninstallOperation))
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/uninstall", h.createSiteUninstallOperation)
 
 	// shrink - remove servers
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/operations/shrink", h.needsAuth(h.createSiteShrinkOperation))
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/operations/shrink/resume", h.needsAuth(h.resumeShrink))
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/shrink", h.createSiteShrinkOperation)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/shrink/resume", h.resumeShrink)
 
 	// garbage collection
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/operations/gc", h.needsAuth(h.createClusterGarbageCollectOperation))
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/gc", h.createClusterGarbageCollectOperation)
 
 	// update - update installed application to a new version
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/operations/update", h.needsAuth(h.createSiteUpdateOperation))
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/update", h.createSiteUpdateOperation)
 
 	// common operations methods
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common", h.needsAuth(h.getSiteOperations))
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/operations/common", h.getSiteOperations)
 	// update install/expand operation state
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id", h.needsAuth(h.getSiteOperation))
-	h.DELETE("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id", h.needsAuth(h.deleteOperation))
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/logs", h.needsAuth(h.getSiteOperationLogs))
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/logs/entry", h.needsAuth(h.createLogEntry))
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/logs", h.needsAuth(h.streamOperationLogs))
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/progress", h.needsAuth(h.getSiteOperationProgress))
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/progress", h.needsAuth(h.createProgressEntry))
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/crash-report", h.needsAuth(h.getSiteOperationCrashReport))
-	h.PUT("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/complete", h.needsAuth(h.completeSiteOperation))
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/plan", h.needsAuth(h.createOperationPlan))
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/plan/changelog", h.needsAuth(h.createOperationPlanChange))
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/plan", h.needsAuth(h.getOperationPlan))
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/plan/configure", h.needsAuth(h.configurePackages))
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/operations/common/:operation_id", h.getSiteOperation)
+	h.route(http.MethodDelete, "/accounts/:account_id/sites/:site_domain/operations/common/:operation_id", h.deleteOperation)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/logs", h.getSiteOperationLogs)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/logs/entry", h.createLogEntry)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/logs", h.streamOperationLogs)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/progress", h.getSiteOperationProgress)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/progress", h.createProgressEntry)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/crash-report", h.getSiteOperationCrashReport)
+	h.route(http.MethodPut, "/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/complete", h.completeSiteOperation)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/approve", h.approveOperation)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/plan", h.createOperationPlan)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/plan/changelog", h.createOperationPlanChange)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/plan", h.getOperationPlan)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/plan/configure", h.configurePackages)
 
 	// log forwarders
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/logs/forwarders", h.needsAuth(h.getLogForwarders))
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/logs/forwarders", h.getLogForwarders)
 
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/logs/forwarders", h.needsAuth(h.createLogForwarder))
-	h.PUT("/portal/v1/accounts/:account_id/sites/:site_domain/logs/forwarders/:name", h.needsAuth(h.updateLogForwarder))
-	h.DELETE("/portal/v1/accounts/:account_id/sites/:site_domain/logs/forwarders/:name", h.needsAuth(h.deleteLogForwarder))
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/logs/forwarders", h.createLogForwarder)
+	h.route(http.MethodPut, "/accounts/:account_id/sites/:site_domain/logs/forwarders/:name", h.updateLogForwarder)
+	h.route(http.MethodDelete, "/accounts/:account_id/sites/:site_domain/logs/forwarders/:name", h.deleteLogForwarder)
 
 	// smtp
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/smtp", h.needsAuth(h.getSMTPConfig))
-	h.PUT("/portal/v1/accounts/:account_id/sites/:site_domain/smtp", h.needsAuth(h.updateSMTPConfig))
-	h.DELETE("/portal/v1/accounts/:account_id/sites/:site_domain/smtp", h.needsAuth(h.deleteSMTPConfig))
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/smtp", h.getSMTPConfig)
+	h.route(http.MethodPut, "/accounts/:account_id/sites/:site_domain/smtp", h.updateSMTPConfig)
+	h.route(http.MethodDelete, "/accounts/:account_id/sites/:site_domain/smtp", h.deleteSMTPConfig)
+
+	// cluster DNS configuration
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/dns", h.getClusterDNS)
+	h.route(http.MethodPut, "/accounts/:account_id/sites/:site_domain/dns", h.updateClusterDNS)
+
+	// node pools
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/nodepools", h.getNodePools)
+	h.route(http.MethodPut, "/accounts/:account_id/sites/:site_domain/nodepools/:name", h.upsertNodePool)
+	h.route(http.MethodDelete, "/accounts/:account_id/sites/:site_domain/nodepools/:name", h.deleteNodePool)
+
+	// tracked application releases
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/trackedreleases", h.getTrackedReleases)
+	h.route(http.MethodPut, "/accounts/:account_id/sites/:site_domain/trackedreleases/:name", h.trackRelease)
+	h.route(http.MethodDelete, "/accounts/:account_id/sites/:site_domain/trackedreleases/:name", h.untrackRelease)
+
+	// operation webhooks
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/operationwebhooks", h.getOperationWebhooks)
+	h.route(http.MethodPut, "/accounts/:account_id/sites/:site_domain/operationwebhooks/:name", h.upsertOperationWebhook)
+	h.route(http.MethodDelete, "/accounts/:account_id/sites/:site_domain/operationwebhooks/:name", h.deleteOperationWebhook)
+
+	// health checks
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/healthchecks", h.getHealthChecks)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/healthchecks/status", h.getHealthCheckStatus)
+	h.route(http.MethodPut, "/accounts/:account_id/sites/:site_domain/healthchecks/:name", h.upsertHealthCheck)
+	h.route(http.MethodDelete, "/accounts/:account_id/sites/:site_domain/healthchecks/:name", h.deleteHealthCheck)
+
+	// health history
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/healthhistory", h.getHealthHistory)
+
+	// network health
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/networkhealth", h.getNetworkHealth)
+
+	// cluster roster
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/roster", h.getClusterRoster)
+	h.route(http.MethodPut, "/accounts/:account_id/sites/:site_domain/roster", h.upsertClusterRoster)
+	h.route(http.MethodDelete, "/accounts/:account_id/sites/:site_domain/roster", h.deleteClusterRoster)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/roster/status", h.getClusterRosterStatus)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/roster/approve", h.approveClusterRosterChanges)
+
+	// image trust policy
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/imagetrustpolicy", h.getImageTrustPolicy)
+	h.route(http.MethodPut, "/accounts/:account_id/sites/:site_domain/imagetrustpolicy", h.upsertImageTrustPolicy)
+	h.route(http.MethodDelete, "/accounts/:account_id/sites/:site_domain/imagetrustpolicy", h.deleteImageTrustPolicy)
+
+	// operation approval policy
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/approvalpolicy", h.getOperationApprovalPolicy)
+	h.route(http.MethodPut, "/accounts/:account_id/sites/:site_domain/approvalpolicy", h.upsertOperationApprovalPolicy)
+	h.route(http.MethodDelete, "/accounts/:account_id/sites/:site_domain/approvalpolicy", h.deleteOperationApprovalPolicy)
+
+	// catalog sync schedule
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/catalogsync", h.getCatalogSyncSchedule)
+	h.route(http.MethodPut, "/accounts/:account_id/sites/:site_domain/catalogsync", h.upsertCatalogSyncSchedule)
+	h.route(http.MethodDelete, "/accounts/:account_id/sites/:site_domain/catalogsync", h.deleteCatalogSyncSchedule)
+
+	// scheduled backups
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/backupschedule", h.getBackupSchedule)
+	h.route(http.MethodPut, "/accounts/:account_id/sites/:site_domain/backupschedule", h.upsertBackupSchedule)
+	h.route(http.MethodDelete, "/accounts/:account_id/sites/:site_domain/backupschedule", h.deleteBackupSchedule)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/backupstatus", h.getBackupStatus)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/replication", h.getReplication)
+	h.route(http.MethodPut, "/accounts/:account_id/sites/:site_domain/replication", h.upsertReplication)
+	h.route(http.MethodDelete, "/accounts/:account_id/sites/:site_domain/replication", h.deleteReplication)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/replicationstatus", h.getReplicationStatus)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/restore", h.createRestoreOperation)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/etcdrestore", h.createEtcdRestoreOperation)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/appbackup", h.createAppBackupOperation)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/apprestore", h.createAppRestoreOperation)
+
+	// etcd maintenance
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/etcd/maintenance", h.getEtcdMaintenanceStatus)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/etcd/health", h.getEtcdHealthStatus)
 
 	// monitoring
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/monitoring/alerts", h.needsAuth(h.getAlerts))
-	h.PUT("/portal/v1/accounts/:account_id/sites/:site_domain/monitoring/alerts/:name", h.needsAuth(h.updateAlert))
-	h.DELETE("/portal/v1/accounts/:account_id/sites/:site_domain/monitoring/alerts/:name", h.needsAuth(h.deleteAlert))
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/monitoring/alert-targets", h.needsAuth(h.getAlertTargets))
-	h.PUT("/portal/v1/accounts/:account_id/sites/:site_domain/monitoring/alert-targets", h.needsAuth(h.updateAlertTarget))
-	h.DELETE("/portal/v1/accounts/:account_id/sites/:site_domain/monitoring/alert-targets", h.needsAuth(h.deleteAlertTarget))
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/monitoring/metrics", h.needsAuth(h.getClusterMetrics))
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/monitoring/alerts", h.getAlerts)
+	h.route(http.MethodPut, "/accounts/:account_id/sites/:site_domain/monitoring/alerts/:name", h.updateAlert)
+	h.route(http.MethodDelete, "/accounts/:account_id/sites/:site_domain/monitoring/alerts/:name", h.deleteAlert)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/monitoring/alert-targets", h.getAlertTargets)
+	h.route(http.MethodPut, "/accounts/:account_id/sites/:site_domain/monitoring/alert-targets", h.updateAlertTarget)
+	h.route(http.MethodDelete, "/accounts/:account_id/sites/:site_domain/monitoring/alert-targets", h.deleteAlertTarget)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/monitoring/metrics", h.getClusterMetrics)
 
 	// environment variables
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/envars", h.needsAuth(h.getEnvironmentVariables))
-	h.PUT("/portal/v1/accounts/:account_id/sites/:site_domain/envars", h.needsAuth(h.updateEnvironmentVariables))
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/operations/envars", h.needsAuth(h.createUpdateEnvarsOperation))
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/envars", h.getEnvironmentVariables)
+	h.route(http.MethodPut, "/accounts/:account_id/sites/:site_domain/envars", h.updateEnvironmentVariables)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/envars", h.createUpdateEnvarsOperation)
+
+	// node promotion and demotion
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/noderole", h.createUpdateNodeRoleOperation)
+
+	// node replacement
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/replacenode", h.createReplaceNodeOperation)
 
 	// cluster configuration
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/config", h.needsAuth(h.getClusterConfiguration))
-	h.PUT("/portal/v1/accounts/:account_id/sites/:site_domain/config", h.needsAuth(h.updateClusterConfig))
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/operations/config", h.needsAuth(h.createUpdateConfigOperation))
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/config", h.getClusterConfiguration)
+	h.route(http.MethodPut, "/accounts/:account_id/sites/:site_domain/config", h.updateClusterConfig)
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/operations/config", h.createUpdateConfigOperation)
 
 	// validation
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/validation/remoteaccess", h.needsAuth(h.validateRemoteAccess))
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/validation/remoteaccess", h.validateRemoteAccess)
 
 	// cluster and application endpoints
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/endpoints", h.needsAuth(h.getApplicationEndpoints))
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/endpoints", h.getApplicationEndpoints)
 
 	// app installer
-	h.GET("/portal/v1/accounts/:account_id/apps/:repository_id/:package_name/:version/installer", h.needsAuth(h.getAppInstaller))
+	h.route(http.MethodGet, "/accounts/:account_id/apps/:repository_id/:package_name/:version/installer", h.getAppInstaller)
 
 	// web helpers - special functions for the UI
-	h.GET("/portal/v1/webhelpers/accounts/:account_id/sites/:site_domain/operations/last/:operation_type", h.needsAuth(h.getLastOperation))
+	h.route(http.MethodGet, "/webhelpers/accounts/:account_id/sites/:site_domain/operations/last/:operation_type", h.getLastOperation)
 
 	// Github connector handlers
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/github/connectors",
-		h.needsAuth(h.upsertGithubConnector))
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/github/connectors/:id",
-		h.needsAuth(h.getGithubConnector))
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/github/connectors",
-		h.needsAuth(h.getGithubConnectors))
-	h.DELETE("/portal/v1/accounts/:account_id/sites/:site_domain/github/connectors/:id",
-		h.needsAuth(h.deleteGithubConnector))
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/github/connectors", h.upsertGithubConnector)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/github/connectors/:id", h.getGithubConnector)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/github/connectors", h.getGithubConnectors)
+	h.route(http.MethodDelete, "/accounts/:account_id/sites/:site_domain/github/connectors/:id", h.deleteGithubConnector)
 
 	// user handlers
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/users",
-		h.needsAuth(h.upsertUser))
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/users/:name",
-		h.needsAuth(h.getUser))
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/users",
-		h.needsAuth(h.getUsers))
-	h.DELETE("/portal/v1/accounts/:account_id/sites/:site_domain/users/:name",
-		h.needsAuth(h.deleteUser))
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/users", h.upsertUser)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/users/:name", h.getUser)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/users", h.getUsers)
+	h.route(http.MethodDelete, "/accounts/:account_id/sites/:site_domain/users/:name", h.deleteUser)
+
+	// service accounts
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/serviceaccounts", h.getServiceAccounts)
+	h.route(http.MethodPut, "/accounts/:account_id/sites/:site_domain/serviceaccounts/:name", h.upsertServiceAccount)
+	h.route(http.MethodDelete, "/accounts/:account_id/sites/:site_domain/serviceaccounts/:name", h.deleteServiceAccount)
 
 	// cluster configuration
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/authentication/preference",
-		h.needsAuth(h.upsertClusterAuthPreference))
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/authentication/preference",
-		h.needsAuth(h.getClusterAuthPreference))
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/authentication/preference", h.upsertClusterAuthPreference)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/authentication/preference", h.getClusterAuthPreference)
 
 	// auth gateway settings
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/authgateway",
-		h.needsAuth(h.upsertAuthGateway))
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/authgateway",
-		h.needsAuth(h.getAuthGateway))
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/authgateway", h.upsertAuthGateway)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/authgateway", h.getAuthGateway)
 
 	// application releases
-	h.GET("/portal/v1/accounts/:account_id/sites/:site_domain/releases",
-		h.needsAuth(h.getReleases))
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/releases", h.getReleases)
 
 	// audit log events
-	h.POST("/portal/v1/accounts/:account_id/sites/:site_domain/events",
-		h.needsAuth(h.emitAuditEvent))
+	h.route(http.MethodPost, "/accounts/:account_id/sites/:site_domain/events", h.emitAuditEvent)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/events", h.getClusterEvents)
+	h.route(http.MethodGet, "/accounts/:account_id/sites/:site_domain/events/stream", h.streamClusterEvents)
 
 	return h, nil
 }
@@ -804,6 +911,85 @@ func (h *WebHandler) getExpandToken(w http.ResponseWriter, r *http.Request, p ht
 	return nil
 }
 
+/*  createJoinToken creates a new short-lived cluster join token
+
+    POST /portal/v1/accounts/:account_id/sites/:site_domain/tokens/join
+
+    Success response:
+
+    storage.ProvisioningToken
+*/
+func (h *WebHandler) createJoinToken(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var req ops.CreateJoinTokenRequest
+	if err := telehttplib.ReadJSON(r, &req); err != nil {
+		return trace.Wrap(err)
+	}
+	req.SiteKey = siteKey(p)
+	token, err := context.Operator.CreateJoinToken(r.Context(), req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, token)
+	return nil
+}
+
+/*  getJoinTokens returns the active short-lived join tokens of the cluster
+
+    GET /portal/v1/accounts/:account_id/sites/:site_domain/tokens/join
+
+    Success response:
+
+    []storage.ProvisioningToken
+*/
+func (h *WebHandler) getJoinTokens(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	tokens, err := context.Operator.GetJoinTokens(r.Context(), siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, tokens)
+	return nil
+}
+
+/*  deleteJoinToken deletes the specified short-lived join token
+
+    DELETE /portal/v1/accounts/:account_id/sites/:site_domain/tokens/join/:token
+*/
+func (h *WebHandler) deleteJoinToken(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	err := context.Operator.DeleteJoinToken(r.Context(), ops.DeleteJoinTokenRequest{
+		SiteKey: siteKey(p),
+		Token:   p.ByName("token"),
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("token deleted"))
+	return nil
+}
+
+/*  requestScaleUp requests expansion of the cluster by the specified number of nodes
+
+    POST /portal/v1/accounts/:account_id/sites/:site_domain/scaleup
+
+    Input: ops.ScaleUpRequest
+
+    Success response:
+
+    storage.ProvisioningToken
+*/
+func (h *WebHandler) requestScaleUp(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var req ops.ScaleUpRequest
+	if err := telehttplib.ReadJSON(r, &req); err != nil {
+		return trace.Wrap(err)
+	}
+	req.SiteKey = siteKey(p)
+	token, err := context.Operator.RequestScaleUp(r.Context(), req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, token)
+	return nil
+}
+
 /*  getTrustedClusterToken returns the cluster's trusted cluster token
 
     GET /portal/v1/accounts/:account_id/tokens/trustedcluster
@@ -1510,6 +1696,25 @@ func (h *WebHandler) completeSiteOperation(w http.ResponseWriter, r *http.Reques
 	return nil
 }
 
+/* approveOperation approves the specified operation on behalf of the calling user
+
+   POST /portal/v1/accounts/:account_id/sites/:site_domain/operations/common/:operation_id/approve
+
+Success response:
+
+   {
+      "status": "operation approved",
+   }
+*/
+func (h *WebHandler) approveOperation(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	err := context.Operator.ApproveOperation(r.Context(), siteOperationKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("operation approved"))
+	return nil
+}
+
 /* createOperationPlan saves the provided operation plan
 
    POST /portal/v1/accos/:account_id/sites/:site_domain/operations/common/:operation_id/plan
@@ -1679,6 +1884,11 @@ func (h *WebHandler) createSiteExpandOperation(w http.ResponseWriter, r *http.Re
 	key := siteKey(p)
 	req.AccountID = key.AccountID
 	req.SiteDomain = key.SiteDomain
+	// Nodes joining with a short-lived join token are subject
+	// to the token restrictions
+	if creds, err := httplib.ParseAuthHeaders(r); err == nil && creds.IsToken() {
+		req.JoinToken = creds.Password
+	}
 	if req.Provisioner == "" {
 		installOp, err := ops.GetCompletedInstallOperation(key, context.Operator)
 		if err != nil {
@@ -2181,175 +2391,1289 @@ func (h *WebHandler) deleteSMTPConfig(w http.ResponseWriter, r *http.Request, p
 	return nil
 }
 
-/* getApplicationEndpoints returns application endpoints for a deployed cluster
+/* getClusterDNS returns the cluster DNS configuration
 
-     GET /portal/v1/accounts/:account_id/sites/:site_domain/endpoints
+     GET /portal/v1/accounts/:account_id/sites/:site_domain/dns
 
    Success Response:
 
-     []ops.Endpoint
+     storage.ClusterDNS
 */
-func (h *WebHandler) getApplicationEndpoints(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
-	endpoints, err := context.Operator.GetApplicationEndpoints(siteKey(p))
+func (h *WebHandler) getClusterDNS(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	config, err := context.Operator.GetClusterDNS(siteKey(p))
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	roundtrip.ReplyJSON(w, http.StatusOK, endpoints)
+	roundtrip.ReplyJSON(w, http.StatusOK, config)
 	return nil
 }
 
-/* getAppInstaller returns a standalone installer for the specified application
+/* updateClusterDNS updates the cluster DNS configuration
 
-GET /portal/v1/accounts/:account_id/apps/:repository_id/:package_name/:version/installer
+     PUT /portal/v1/accounts/:account_id/sites/:site_domain/dns
 
    Success Response:
 
-     binary stream with application tarball
+     {
+       "message": "cluster DNS configuration updated"
+     }
 */
-func (h *WebHandler) getAppInstaller(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
-	accountID := p.ByName("account_id")
-	locator, err := loc.NewLocator(p.ByName("repository_id"), p.ByName("package_name"), p.ByName("version"))
+func (h *WebHandler) updateClusterDNS(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var req opsclient.UpsertResourceRawReq
+	if err := telehttplib.ReadJSON(r, &req); err != nil {
+		return trace.Wrap(err)
+	}
+
+	config, err := storage.UnmarshalClusterDNS(req.Resource)
 	if err != nil {
 		return trace.Wrap(err)
 	}
 
-	if err := r.ParseForm(); err != nil {
+	err = context.Operator.UpdateClusterDNS(r.Context(), siteKey(p), config)
+	if err != nil {
 		return trace.Wrap(err)
 	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("cluster DNS configuration updated"))
+	return nil
+}
 
-	var installerReq ops.AppInstallerRequest
-	requestBytes := r.Form.Get("request")
+/* getNodePools returns the list of node pools of the cluster
 
-	err = json.Unmarshal([]byte(requestBytes), &installerReq)
+   GET /portal/v1/accounts/:account_id/sites/:site_domain/nodepools
+
+Success response:
+
+   []storage.NodePool
+*/
+func (h *WebHandler) getNodePools(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	pools, err := context.Operator.GetNodePools(siteKey(p))
 	if err != nil {
-		return trace.Wrap(err, "failed to unmarshal `%s`", requestBytes)
+		return trace.Wrap(err)
 	}
-	installerReq.AccountID = accountID
+	items := make([]json.RawMessage, len(pools))
+	for i, pool := range pools {
+		bytes, err := storage.MarshalNodePool(pool)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		items[i] = bytes
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, items)
+	return nil
+}
 
-	reader, err := context.Operator.GetAppInstaller(installerReq)
+/* upsertNodePool creates or updates a node pool
+
+   PUT /portal/v1/accounts/:account_id/sites/:site_domain/nodepools/:name
+*/
+func (h *WebHandler) upsertNodePool(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var req opsclient.UpsertResourceRawReq
+	if err := telehttplib.ReadJSON(r, &req); err != nil {
+		return trace.Wrap(err)
+	}
+	pool, err := storage.UnmarshalNodePool(req.Resource)
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	defer reader.Close()
+	err = context.Operator.UpsertNodePool(r.Context(), siteKey(p), pool)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("node pool updated"))
+	return nil
+}
 
-	installerFilename := fmt.Sprintf("%v-%v-installer.tar.gz", locator.Name, locator.Version)
-	w.Header().Set("Content-Type", "application/x-gzip")
-	w.Header().Set("Content-Disposition", `attachment; filename="`+installerFilename+`"`)
-	_, err = io.Copy(w, reader)
-	return trace.Wrap(err)
+/* deleteNodePool deletes a node pool
+
+   DELETE /portal/v1/accounts/:account_id/sites/:site_domain/nodepools/:name
+*/
+func (h *WebHandler) deleteNodePool(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	err := context.Operator.DeleteNodePool(r.Context(), siteKey(p), p.ByName("name"))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("node pool deleted"))
+	return nil
 }
 
-/* getClusterCert returns the cluster certificate
+/* getTrackedReleases returns the application releases recorded for the cluster
 
-     GET /portal/v1/accounts/:account_id/sites/:site_domain/certificate
+   GET /portal/v1/accounts/:account_id/sites/:site_domain/trackedreleases
 
-   Success Response:
+Success response:
 
-     ops.ClusterCertificate
+   []storage.Release
 */
-func (h *WebHandler) getClusterCert(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
-	var withSecrets bool
-	var err error
-	if r.URL.Query().Get(constants.WithSecretsParam) != "" {
-		withSecrets, _, err = telehttplib.ParseBool(r.URL.Query(), constants.WithSecretsParam)
+func (h *WebHandler) getTrackedReleases(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	releases, err := context.Operator.GetTrackedReleases(siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	items := make([]json.RawMessage, len(releases))
+	for i, release := range releases {
+		bytes, err := storage.MarshalRelease(release)
 		if err != nil {
 			return trace.Wrap(err)
 		}
+		items[i] = bytes
 	}
+	roundtrip.ReplyJSON(w, http.StatusOK, items)
+	return nil
+}
 
-	cert, err := context.Operator.GetClusterCertificate(siteKey(p), withSecrets)
+/* trackRelease records the state of the application release
+
+   PUT /portal/v1/accounts/:account_id/sites/:site_domain/trackedreleases/:name
+*/
+func (h *WebHandler) trackRelease(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var req opsclient.UpsertResourceRawReq
+	if err := telehttplib.ReadJSON(r, &req); err != nil {
+		return trace.Wrap(err)
+	}
+	release, err := storage.UnmarshalRelease(req.Resource)
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	roundtrip.ReplyJSON(w, http.StatusOK, cert)
+	err = context.Operator.TrackRelease(r.Context(), siteKey(p), release)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("release recorded"))
 	return nil
 }
 
-/* updateClusterCert updates the cluster certificate
-
-     POST /portal/v1/accounts/:account_id/sites/:site_domain/certificate
-
-   Success Response:
+/* untrackRelease removes the record of the application release
 
-     ops.ClusterCertificate
+   DELETE /portal/v1/accounts/:account_id/sites/:site_domain/trackedreleases/:name
 */
-func (h *WebHandler) updateClusterCert(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
-	var req ops.UpdateCertificateRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		return trace.BadParameter(err.Error())
-	}
-	cert, err := context.Operator.UpdateClusterCertificate(r.Context(), req)
+func (h *WebHandler) untrackRelease(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	err := context.Operator.UntrackRelease(r.Context(), siteKey(p), p.ByName("name"))
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	roundtrip.ReplyJSON(w, http.StatusOK, cert)
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("release record deleted"))
 	return nil
 }
 
-/* deleteClusterCert deletes the cluster certificate
+/* getOperationWebhooks returns the list of operation webhooks of the cluster
 
-     DELETE /portal/v1/accounts/:account_id/sites/:site_domain/certificate
+   GET /portal/v1/accounts/:account_id/sites/:site_domain/operationwebhooks
 
-   Success Response:
+Success response:
 
-    200 certificate deleted
+   []storage.OperationWebhook
 */
-func (h *WebHandler) deleteClusterCert(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
-	err := context.Operator.DeleteClusterCertificate(r.Context(), siteKey(p))
+func (h *WebHandler) getOperationWebhooks(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	webhooks, err := context.Operator.GetOperationWebhooks(siteKey(p))
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	roundtrip.ReplyJSON(w, http.StatusOK, message("certificate deleted"))
+	items := make([]json.RawMessage, len(webhooks))
+	for i, webhook := range webhooks {
+		bytes, err := storage.MarshalOperationWebhook(webhook)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		items[i] = bytes
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, items)
 	return nil
 }
 
-/* emitAuditEvent saves the provided event in the audit log.
+/* upsertOperationWebhook creates or updates an operation webhook
 
-     POST /portal/v1/accounts/:account_id/sites/:site_domain/events
+   PUT /portal/v1/accounts/:account_id/sites/:site_domain/operationwebhooks/:name
+*/
+func (h *WebHandler) upsertOperationWebhook(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var req opsclient.UpsertResourceRawReq
+	if err := telehttplib.ReadJSON(r, &req); err != nil {
+		return trace.Wrap(err)
+	}
+	webhook, err := storage.UnmarshalOperationWebhook(req.Resource)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = context.Operator.UpsertOperationWebhook(r.Context(), siteKey(p), webhook)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("operation webhook updated"))
+	return nil
+}
 
-   Success response:
+/* deleteOperationWebhook deletes an operation webhook
 
-     { "message": "audit log event saved" }
+   DELETE /portal/v1/accounts/:account_id/sites/:site_domain/operationwebhooks/:name
 */
-func (h *WebHandler) emitAuditEvent(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
-	var req ops.AuditEventRequest
-	err := telehttplib.ReadJSON(r, &req)
+func (h *WebHandler) deleteOperationWebhook(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	err := context.Operator.DeleteOperationWebhook(r.Context(), siteKey(p), p.ByName("name"))
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	events.Emit(r.Context(), context.Operator, req.Event, events.Fields(req.Fields))
-	roundtrip.ReplyJSON(w, http.StatusOK, message("audit log event saved"))
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("operation webhook deleted"))
 	return nil
 }
 
-func (s *WebHandler) wrap(fn func(w http.ResponseWriter, r *http.Request, p httprouter.Params) error) httprouter.Handle {
-	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
-		if err := fn(w, r, p); err != nil {
-			trace.WriteError(w, err)
+/* getHealthChecks returns the list of health checks of the cluster
+
+   GET /portal/v1/accounts/:account_id/sites/:site_domain/healthchecks
+
+Success response:
+
+   []storage.HealthCheck
+*/
+func (h *WebHandler) getHealthChecks(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	checks, err := context.Operator.GetHealthChecks(siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	items := make([]json.RawMessage, len(checks))
+	for i, check := range checks {
+		bytes, err := storage.MarshalHealthCheck(check)
+		if err != nil {
+			return trace.Wrap(err)
 		}
+		items[i] = bytes
 	}
+	roundtrip.ReplyJSON(w, http.StatusOK, items)
+	return nil
 }
 
-func (s *WebHandler) needsAuth(fn ServiceHandle) httprouter.Handle {
-	return NeedsAuth(s.cfg.Devmode, s.cfg.Backend, s.cfg.Operator, s.cfg.Authenticator, s.cfg.Users, fn)
-}
+/* getHealthCheckStatus returns the result of the last evaluation
+   of the cluster health checks
 
-// GetHandlerContext authenticates the user that made the request and returns
-// the appropriate handler context
-func GetHandlerContext(w http.ResponseWriter, r *http.Request, backend storage.Backend, operator ops.Operator, authenticator users.Authenticator, usersService users.Identity) (*HandlerContext, error) {
-	logger := log.WithFields(fields.FromRequest(r))
+   GET /portal/v1/accounts/:account_id/sites/:site_domain/healthchecks/status
 
-	authResult, err := authenticator.Authenticate(w, r)
+Success response:
+
+   storage.HealthCheckStatus
+*/
+func (h *WebHandler) getHealthCheckStatus(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	status, err := context.Operator.GetHealthCheckStatus(siteKey(p))
 	if err != nil {
-		logger.WithError(err).Warn("Authentication error.")
-		return nil, trace.AccessDenied("bad username or password") // Hide the actual error.
+		return trace.Wrap(err)
 	}
+	roundtrip.ReplyJSON(w, http.StatusOK, status)
+	return nil
+}
 
-	// Enrich the request context with additional auth info.
-	ctx := r.Context()
-	ctx = context.WithValue(ctx, constants.UserContext, authResult.User.GetName())
-	if authResult.Session != nil {
-		ctx = context.WithValue(ctx, constants.WebSessionContext, authResult.Session.GetWebSession())
+/* upsertHealthCheck creates or updates a health check
+
+   PUT /portal/v1/accounts/:account_id/sites/:site_domain/healthchecks/:name
+*/
+func (h *WebHandler) upsertHealthCheck(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var req opsclient.UpsertResourceRawReq
+	if err := telehttplib.ReadJSON(r, &req); err != nil {
+		return trace.Wrap(err)
+	}
+	check, err := storage.UnmarshalHealthCheck(req.Resource)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = context.Operator.UpsertHealthCheck(r.Context(), siteKey(p), check)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("health check updated"))
+	return nil
+}
+
+/* deleteHealthCheck deletes a health check
+
+   DELETE /portal/v1/accounts/:account_id/sites/:site_domain/healthchecks/:name
+*/
+func (h *WebHandler) deleteHealthCheck(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	err := context.Operator.DeleteHealthCheck(r.Context(), siteKey(p), p.ByName("name"))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("health check deleted"))
+	return nil
+}
+
+/* getHealthHistory returns the cluster health snapshots recorded since
+   the specified time, ordered from the oldest to the newest
+
+   GET /portal/v1/accounts/:account_id/sites/:site_domain/healthhistory?since=<RFC3339 time>
+
+Success response:
+
+   []storage.HealthSnapshot
+*/
+func (h *WebHandler) getHealthHistory(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var since time.Time
+	if value := r.URL.Query().Get("since"); value != "" {
+		var err error
+		since, err = time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return trace.BadParameter("invalid since parameter %q: %v", value, err)
+		}
+	}
+	snapshots, err := context.Operator.GetHealthHistory(siteKey(p), since)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if snapshots == nil {
+		snapshots = []storage.HealthSnapshot{}
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, snapshots)
+	return nil
+}
+
+/* getNetworkHealth returns the network health reports published
+   by the network monitors on the cluster nodes
+
+   GET /portal/v1/accounts/:account_id/sites/:site_domain/networkhealth
+
+Success response:
+
+   []storage.NetworkHealthReport
+*/
+func (h *WebHandler) getNetworkHealth(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	reports, err := context.Operator.GetNetworkHealth(siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if reports == nil {
+		reports = []storage.NetworkHealthReport{}
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, reports)
+	return nil
+}
+
+/* getClusterRoster returns the cluster roster
+
+   GET /portal/v1/accounts/:account_id/sites/:site_domain/roster
+
+Success response:
+
+   storage.ClusterRoster
+*/
+func (h *WebHandler) getClusterRoster(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	roster, err := context.Operator.GetClusterRoster(siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	bytes, err := storage.MarshalClusterRoster(roster)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, json.RawMessage(bytes))
+	return nil
+}
+
+/* upsertClusterRoster creates or updates the cluster roster
+
+   PUT /portal/v1/accounts/:account_id/sites/:site_domain/roster
+*/
+func (h *WebHandler) upsertClusterRoster(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var req opsclient.UpsertResourceRawReq
+	if err := telehttplib.ReadJSON(r, &req); err != nil {
+		return trace.Wrap(err)
+	}
+	roster, err := storage.UnmarshalClusterRoster(req.Resource)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = context.Operator.UpsertClusterRoster(r.Context(), siteKey(p), roster)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("cluster roster updated"))
+	return nil
+}
+
+/* deleteClusterRoster deletes the cluster roster
+
+   DELETE /portal/v1/accounts/:account_id/sites/:site_domain/roster
+*/
+func (h *WebHandler) deleteClusterRoster(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	err := context.Operator.DeleteClusterRoster(r.Context(), siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("cluster roster deleted"))
+	return nil
+}
+
+/* getClusterRosterStatus returns the status of the roster reconciliation
+
+   GET /portal/v1/accounts/:account_id/sites/:site_domain/roster/status
+
+Success response:
+
+   storage.ClusterRosterStatus
+*/
+func (h *WebHandler) getClusterRosterStatus(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	status, err := context.Operator.GetClusterRosterStatus(siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, status)
+	return nil
+}
+
+/* approveClusterRosterChanges approves the pending cluster membership changes
+
+   POST /portal/v1/accounts/:account_id/sites/:site_domain/roster/approve
+
+Input: opsclient.ApproveRosterChangesReq
+*/
+func (h *WebHandler) approveClusterRosterChanges(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var req opsclient.ApproveRosterChangesReq
+	if err := telehttplib.ReadJSON(r, &req); err != nil {
+		return trace.Wrap(err)
+	}
+	err := context.Operator.ApproveClusterRosterChanges(r.Context(), siteKey(p), req.ChangesID)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("cluster roster changes approved"))
+	return nil
+}
+
+/* getImageTrustPolicy returns the image trust policy
+
+   GET /portal/v1/accounts/:account_id/sites/:site_domain/imagetrustpolicy
+
+Success response:
+
+   storage.ImageTrustPolicy
+*/
+func (h *WebHandler) getImageTrustPolicy(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	policy, err := context.Operator.GetImageTrustPolicy(siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	bytes, err := storage.MarshalImageTrustPolicy(policy)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, json.RawMessage(bytes))
+	return nil
+}
+
+/* upsertImageTrustPolicy creates or updates the image trust policy
+
+   PUT /portal/v1/accounts/:account_id/sites/:site_domain/imagetrustpolicy
+*/
+func (h *WebHandler) upsertImageTrustPolicy(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var req opsclient.UpsertResourceRawReq
+	if err := telehttplib.ReadJSON(r, &req); err != nil {
+		return trace.Wrap(err)
+	}
+	policy, err := storage.UnmarshalImageTrustPolicy(req.Resource)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = context.Operator.UpsertImageTrustPolicy(r.Context(), siteKey(p), policy)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("image trust policy updated"))
+	return nil
+}
+
+/* deleteImageTrustPolicy deletes the image trust policy
+
+   DELETE /portal/v1/accounts/:account_id/sites/:site_domain/imagetrustpolicy
+*/
+func (h *WebHandler) deleteImageTrustPolicy(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	err := context.Operator.DeleteImageTrustPolicy(r.Context(), siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("image trust policy deleted"))
+	return nil
+}
+
+/* getOperationApprovalPolicy returns the operation approval policy
+
+   GET /portal/v1/accounts/:account_id/sites/:site_domain/approvalpolicy
+
+Success response:
+
+   storage.OperationApprovalPolicy
+*/
+func (h *WebHandler) getOperationApprovalPolicy(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	policy, err := context.Operator.GetOperationApprovalPolicy(siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	bytes, err := storage.MarshalOperationApprovalPolicy(policy)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, json.RawMessage(bytes))
+	return nil
+}
+
+/* upsertOperationApprovalPolicy creates or updates the operation approval policy
+
+   PUT /portal/v1/accounts/:account_id/sites/:site_domain/approvalpolicy
+*/
+func (h *WebHandler) upsertOperationApprovalPolicy(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var req opsclient.UpsertResourceRawReq
+	if err := telehttplib.ReadJSON(r, &req); err != nil {
+		return trace.Wrap(err)
+	}
+	policy, err := storage.UnmarshalOperationApprovalPolicy(req.Resource)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = context.Operator.UpsertOperationApprovalPolicy(r.Context(), siteKey(p), policy)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("operation approval policy updated"))
+	return nil
+}
+
+/* deleteOperationApprovalPolicy deletes the operation approval policy
+
+   DELETE /portal/v1/accounts/:account_id/sites/:site_domain/approvalpolicy
+*/
+func (h *WebHandler) deleteOperationApprovalPolicy(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	err := context.Operator.DeleteOperationApprovalPolicy(r.Context(), siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("operation approval policy deleted"))
+	return nil
+}
+
+/* getCatalogSyncSchedule returns the catalog sync schedule
+
+   GET /portal/v1/accounts/:account_id/sites/:site_domain/catalogsync
+
+Success response:
+
+   storage.CatalogSyncSchedule
+*/
+func (h *WebHandler) getCatalogSyncSchedule(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	schedule, err := context.Operator.GetCatalogSyncSchedule(siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	bytes, err := storage.MarshalCatalogSyncSchedule(schedule)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, json.RawMessage(bytes))
+	return nil
+}
+
+/* upsertCatalogSyncSchedule creates or updates the catalog sync schedule
+
+   PUT /portal/v1/accounts/:account_id/sites/:site_domain/catalogsync
+*/
+func (h *WebHandler) upsertCatalogSyncSchedule(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var req opsclient.UpsertResourceRawReq
+	if err := telehttplib.ReadJSON(r, &req); err != nil {
+		return trace.Wrap(err)
+	}
+	schedule, err := storage.UnmarshalCatalogSyncSchedule(req.Resource)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = context.Operator.UpsertCatalogSyncSchedule(r.Context(), siteKey(p), schedule)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("catalog sync schedule updated"))
+	return nil
+}
+
+/* deleteCatalogSyncSchedule deletes the catalog sync schedule
+
+   DELETE /portal/v1/accounts/:account_id/sites/:site_domain/catalogsync
+*/
+func (h *WebHandler) deleteCatalogSyncSchedule(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	err := context.Operator.DeleteCatalogSyncSchedule(r.Context(), siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("catalog sync schedule deleted"))
+	return nil
+}
+
+/* getBackupSchedule returns the backup schedule
+
+   GET /portal/v1/accounts/:account_id/sites/:site_domain/backupschedule
+
+Success response:
+
+   storage.BackupSchedule
+*/
+func (h *WebHandler) getBackupSchedule(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	schedule, err := context.Operator.GetBackupSchedule(siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	bytes, err := storage.MarshalBackupSchedule(schedule)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, json.RawMessage(bytes))
+	return nil
+}
+
+/* upsertBackupSchedule creates or updates the backup schedule
+
+   PUT /portal/v1/accounts/:account_id/sites/:site_domain/backupschedule
+*/
+func (h *WebHandler) upsertBackupSchedule(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var req opsclient.UpsertResourceRawReq
+	if err := telehttplib.ReadJSON(r, &req); err != nil {
+		return trace.Wrap(err)
+	}
+	schedule, err := storage.UnmarshalBackupSchedule(req.Resource)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = context.Operator.UpsertBackupSchedule(r.Context(), siteKey(p), schedule)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("backup schedule updated"))
+	return nil
+}
+
+/* deleteBackupSchedule deletes the backup schedule
+
+   DELETE /portal/v1/accounts/:account_id/sites/:site_domain/backupschedule
+*/
+func (h *WebHandler) deleteBackupSchedule(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	err := context.Operator.DeleteBackupSchedule(r.Context(), siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("backup schedule deleted"))
+	return nil
+}
+
+/* getBackupStatus returns the status of the last scheduled backup
+
+   GET /portal/v1/accounts/:account_id/sites/:site_domain/backupstatus
+
+Success response:
+
+   storage.BackupStatus
+*/
+func (h *WebHandler) getBackupStatus(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	status, err := context.Operator.GetBackupStatus(siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, status)
+	return nil
+}
+
+/* getReplication returns the replication of the primary cluster backups
+
+   GET /portal/v1/accounts/:account_id/sites/:site_domain/replication
+
+Success response:
+
+   storage.Replication
+*/
+func (h *WebHandler) getReplication(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	replication, err := context.Operator.GetReplication(siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	bytes, err := storage.MarshalReplication(replication)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, json.RawMessage(bytes))
+	return nil
+}
+
+/* upsertReplication creates or updates the replication
+
+   PUT /portal/v1/accounts/:account_id/sites/:site_domain/replication
+*/
+func (h *WebHandler) upsertReplication(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var req opsclient.UpsertResourceRawReq
+	if err := telehttplib.ReadJSON(r, &req); err != nil {
+		return trace.Wrap(err)
+	}
+	replication, err := storage.UnmarshalReplication(req.Resource)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = context.Operator.UpsertReplication(r.Context(), siteKey(p), replication)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("replication updated"))
+	return nil
+}
+
+/* deleteReplication deletes the replication
+
+   DELETE /portal/v1/accounts/:account_id/sites/:site_domain/replication
+*/
+func (h *WebHandler) deleteReplication(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	err := context.Operator.DeleteReplication(r.Context(), siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, statusOK("replication deleted"))
+	return nil
+}
+
+/* getReplicationStatus returns the status of the replication
+
+   GET /portal/v1/accounts/:account_id/sites/:site_domain/replicationstatus
+
+Success response:
+
+   storage.ReplicationStatus
+*/
+func (h *WebHandler) getReplicationStatus(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	status, err := context.Operator.GetReplicationStatus(siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, status)
+	return nil
+}
+
+/* createRestoreOperation initiates the operation of restoring the cluster
+   from a backup
+
+   POST /portal/v1/accounts/:account_id/sites/:site_domain/operations/restore
+
+   {
+      "backup": "/var/lib/gravity/backups/backup-20190601T020000Z"
+   }
+
+Success response:
+
+   {
+      "account_id": "account id",
+      "site_id": "site_id",
+      "operation_id": "operation id"
+   }
+*/
+func (h *WebHandler) createRestoreOperation(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var req ops.CreateRestoreOperationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return trace.BadParameter(err.Error())
+	}
+	req.ClusterKey = siteKey(p)
+	op, err := context.Operator.CreateRestoreOperation(r.Context(), req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, op)
+	return nil
+}
+
+/* createEtcdRestoreOperation initiates the operation of restoring
+   the etcd database of the cluster from a snapshot
+
+   POST /portal/v1/accounts/:account_id/sites/:site_domain/operations/etcdrestore
+
+   {
+      "snapshot": "/var/lib/gravity/backups/backup-20190601T020000Z/etcd.db",
+      "revision": 1234
+   }
+
+Success response:
+
+   {
+      "account_id": "account id",
+      "site_id": "site_id",
+      "operation_id": "operation id"
+   }
+*/
+func (h *WebHandler) createEtcdRestoreOperation(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var req ops.CreateEtcdRestoreOperationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return trace.BadParameter(err.Error())
+	}
+	req.ClusterKey = siteKey(p)
+	op, err := context.Operator.CreateEtcdRestoreOperation(r.Context(), req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, op)
+	return nil
+}
+
+/* createAppBackupOperation initiates the operation of backing up a single application
+
+   POST /portal/v1/accounts/:account_id/sites/:site_domain/operations/appbackup
+
+   {
+      "release": "wordpress",
+      "path": "/tmp/wordpress.tar.gz"
+   }
+
+Success response:
+
+   {
+      "account_id": "account id",
+      "site_id": "site_id",
+      "operation_id": "operation id"
+   }
+*/
+func (h *WebHandler) createAppBackupOperation(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var req ops.CreateAppBackupOperationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return trace.BadParameter(err.Error())
+	}
+	req.ClusterKey = siteKey(p)
+	op, err := context.Operator.CreateAppBackupOperation(r.Context(), req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, op)
+	return nil
+}
+
+/* createAppRestoreOperation initiates the operation of restoring a single application from its backup
+
+   POST /portal/v1/accounts/:account_id/sites/:site_domain/operations/apprestore
+
+   {
+      "release": "wordpress",
+      "path": "/tmp/wordpress.tar.gz"
+   }
+
+Success response:
+
+   {
+      "account_id": "account id",
+      "site_id": "site_id",
+      "operation_id": "operation id"
+   }
+*/
+func (h *WebHandler) createAppRestoreOperation(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var req ops.CreateAppBackupOperationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return trace.BadParameter(err.Error())
+	}
+	req.ClusterKey = siteKey(p)
+	op, err := context.Operator.CreateAppRestoreOperation(r.Context(), req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, op)
+	return nil
+}
+
+/* getEtcdMaintenanceStatus returns the status of the etcd compaction and defragmentation
+
+   GET /portal/v1/accounts/:account_id/sites/:site_domain/etcd/maintenance
+
+Success response:
+
+   storage.EtcdMaintenanceStatus
+*/
+func (h *WebHandler) getEtcdMaintenanceStatus(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	status, err := context.Operator.GetEtcdMaintenanceStatus(siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, status)
+	return nil
+}
+
+/* getEtcdHealthStatus returns the status of the etcd health and capacity
+
+   GET /portal/v1/accounts/:account_id/sites/:site_domain/etcd/health
+
+Success response:
+
+   storage.EtcdHealthStatus
+*/
+func (h *WebHandler) getEtcdHealthStatus(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	status, err := context.Operator.GetEtcdHealthStatus(siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, status)
+	return nil
+}
+
+/* getApplicationEndpoints returns application endpoints for a deployed cluster
+
+     GET /portal/v1/accounts/:account_id/sites/:site_domain/endpoints
+
+   Success Response:
+
+     []ops.Endpoint
+*/
+func (h *WebHandler) getApplicationEndpoints(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	endpoints, err := context.Operator.GetApplicationEndpoints(siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, endpoints)
+	return nil
+}
+
+/* getAppInstaller returns a standalone installer for the specified application
+
+GET /portal/v1/accounts/:account_id/apps/:repository_id/:package_name/:version/installer
+
+   Success Response:
+
+     binary stream with application tarball
+*/
+func (h *WebHandler) getAppInstaller(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	accountID := p.ByName("account_id")
+	locator, err := loc.NewLocator(p.ByName("repository_id"), p.ByName("package_name"), p.ByName("version"))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	if err := r.ParseForm(); err != nil {
+		return trace.Wrap(err)
+	}
+
+	var installerReq ops.AppInstallerRequest
+	requestBytes := r.Form.Get("request")
+
+	err = json.Unmarshal([]byte(requestBytes), &installerReq)
+	if err != nil {
+		return trace.Wrap(err, "failed to unmarshal `%s`", requestBytes)
+	}
+	installerReq.AccountID = accountID
+
+	reader, err := context.Operator.GetAppInstaller(installerReq)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer reader.Close()
+
+	installerFilename := fmt.Sprintf("%v-%v-installer.tar.gz", locator.Name, locator.Version)
+	w.Header().Set("Content-Type", "application/x-gzip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+installerFilename+`"`)
+	_, err = io.Copy(w, reader)
+	return trace.Wrap(err)
+}
+
+/* getClusterCert returns the cluster certificate
+
+     GET /portal/v1/accounts/:account_id/sites/:site_domain/certificate
+
+   Success Response:
+
+     ops.ClusterCertificate
+*/
+func (h *WebHandler) getClusterCert(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var withSecrets bool
+	var err error
+	if r.URL.Query().Get(constants.WithSecretsParam) != "" {
+		withSecrets, _, err = telehttplib.ParseBool(r.URL.Query(), constants.WithSecretsParam)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
+	cert, err := context.Operator.GetClusterCertificate(siteKey(p), withSecrets)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, cert)
+	return nil
+}
+
+/* getCertificateExpiry returns the expiration status of the cluster certificates
+
+   GET /portal/v1/accounts/:account_id/sites/:site_domain/certificates/expiry
+
+Success response:
+
+   storage.CertificateExpiryStatus
+*/
+func (h *WebHandler) getCertificateExpiry(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	status, err := context.Operator.GetCertificateExpiry(siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, status)
+	return nil
+}
+
+/* createRotateCertificatesOperation initiates the operation of rotating the cluster certificates
+
+   POST /portal/v1/accounts/:account_id/sites/:site_domain/operations/rotatecerts
+
+   {
+      "rotate_ca": false
+   }
+
+
+Success response:
+
+   {
+      "account_id": "account id",
+      "site_id": "site_id",
+      "operation_id": "operation id"
+   }
+*/
+func (h *WebHandler) createRotateCertificatesOperation(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var req ops.CreateRotateCertificatesOperationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return trace.BadParameter(err.Error())
+	}
+	req.ClusterKey = siteKey(p)
+	op, err := context.Operator.CreateRotateCertificatesOperation(r.Context(), req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, op)
+	return nil
+}
+
+/* createRotateSecretsKeyOperation initiates the operation of rotating the key
+   that encrypts Kubernetes secrets at rest
+
+   POST /portal/v1/accounts/:account_id/sites/:site_domain/operations/rotatesecretskey
+
+
+Success response:
+
+   {
+      "account_id": "account id",
+      "site_id": "site_id",
+      "operation_id": "operation id"
+   }
+*/
+func (h *WebHandler) createRotateSecretsKeyOperation(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	req := ops.CreateRotateSecretsKeyOperationRequest{
+		ClusterKey: siteKey(p),
+	}
+	op, err := context.Operator.CreateRotateSecretsKeyOperation(r.Context(), req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, op)
+	return nil
+}
+
+/* createChangeCIDROperation initiates the operation of changing the pod
+   and service subnets of the cluster
+
+   POST /portal/v1/accounts/:account_id/sites/:site_domain/operations/changecidr
+
+   {
+      "pod_cidr": "10.200.0.0/16",
+      "service_cidr": "10.50.0.0/16"
+   }
+
+Success response:
+
+   {
+      "account_id": "account id",
+      "site_id": "site_id",
+      "operation_id": "operation id"
+   }
+*/
+func (h *WebHandler) createChangeCIDROperation(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var req ops.CreateChangeCIDROperationRequest
+	if err := telehttplib.ReadJSON(r, &req); err != nil {
+		return trace.Wrap(err)
+	}
+	req.ClusterKey = siteKey(p)
+	op, err := context.Operator.CreateChangeCIDROperation(r.Context(), req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, op)
+	return nil
+}
+
+/* updateClusterCert updates the cluster certificate
+
+     POST /portal/v1/accounts/:account_id/sites/:site_domain/certificate
+
+   Success Response:
+
+     ops.ClusterCertificate
+*/
+func (h *WebHandler) updateClusterCert(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var req ops.UpdateCertificateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return trace.BadParameter(err.Error())
+	}
+	cert, err := context.Operator.UpdateClusterCertificate(r.Context(), req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, cert)
+	return nil
+}
+
+/* deleteClusterCert deletes the cluster certificate
+
+     DELETE /portal/v1/accounts/:account_id/sites/:site_domain/certificate
+
+   Success Response:
+
+    200 certificate deleted
+*/
+func (h *WebHandler) deleteClusterCert(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	err := context.Operator.DeleteClusterCertificate(r.Context(), siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, message("certificate deleted"))
+	return nil
+}
+
+/* emitAuditEvent saves the provided event in the audit log.
+
+     POST /portal/v1/accounts/:account_id/sites/:site_domain/events
+
+   Success response:
+
+     { "message": "audit log event saved" }
+*/
+func (h *WebHandler) emitAuditEvent(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	var req ops.AuditEventRequest
+	err := telehttplib.ReadJSON(r, &req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(r.Context(), context.Operator, req.Event, events.Fields(req.Fields))
+	roundtrip.ReplyJSON(w, http.StatusOK, message("audit log event saved"))
+	return nil
+}
+
+/* getClusterEvents returns the cluster events recorded after the specified event.
+
+     GET /portal/v2/accounts/:account_id/sites/:site_domain/events?after=<event id>&limit=<limit>
+
+   Success response:
+
+     [{"id": "...", "time": "...", "name": "operation.started", "code": "G0005I", "fields": {...}}, ...]
+*/
+func (h *WebHandler) getClusterEvents(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	req, err := clusterEventsRequest(r, p)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	events, err := context.Operator.GetClusterEvents(r.Context(), *req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if events == nil {
+		events = []storage.ClusterEvent{}
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, events)
+	return nil
+}
+
+/* streamClusterEvents streams the cluster events recorded after the specified
+   event over a websocket connection as the events are recorded.
+   Every websocket message is a single JSON-encoded event.
+
+     GET /portal/v2/accounts/:account_id/sites/:site_domain/events/stream?after=<event id>
+
+   To resume the stream after a disconnect, reconnect with the ID of the
+   last received event.
+*/
+func (h *WebHandler) streamClusterEvents(w http.ResponseWriter, r *http.Request, p httprouter.Params, context *HandlerContext) error {
+	req, err := clusterEventsRequest(r, p)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// Fail before upgrading the connection if the events can not be read
+	if _, err := context.Operator.GetClusterEvents(r.Context(), ops.GetClusterEventsRequest{
+		SiteKey: req.SiteKey,
+		After:   req.After,
+		Limit:   1,
+	}); err != nil {
+		return trace.Wrap(err)
+	}
+	operator := context.Operator
+	handler := func(ws *websocket.Conn) {
+		defer ws.Close()
+		streamClusterEvents(r.Context(), ws, operator, *req)
+	}
+	websocket.Server{Handler: handler}.ServeHTTP(w, r)
+	return nil
+}
+
+// streamClusterEvents sends the cluster events specified with req to
+// the websocket connection until the connection has been closed
+func streamClusterEvents(ctx context.Context, ws *websocket.Conn, operator ops.Operator, req ops.GetClusterEventsRequest) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		// The client does not send anything, the read returns
+		// when the connection has been closed
+		io.Copy(ioutil.Discard, ws)
+		cancel()
+	}()
+	err := ops.StreamClusterEvents(ctx, operator, req, defaults.ClusterEventsPollInterval,
+		func(event storage.ClusterEvent) error {
+			return websocket.JSON.Send(ws, event)
+		})
+	if err != nil && ctx.Err() == nil {
+		log.WithError(err).Warn("Failed to stream cluster events.")
+	}
+}
+
+// clusterEventsRequest returns the cluster events request from the
+// specified HTTP request
+func clusterEventsRequest(r *http.Request, p httprouter.Params) (*ops.GetClusterEventsRequest, error) {
+	query := r.URL.Query()
+	req := ops.GetClusterEventsRequest{
+		SiteKey: siteKey(p),
+		After:   query.Get("after"),
+	}
+	if limit := query.Get("limit"); limit != "" {
+		var err error
+		req.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			return nil, trace.BadParameter("invalid limit %q: %v", limit, err)
+		}
+	}
+	if err := req.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &req, nil
+}
+
+func (s *WebHandler) wrap(fn func(w http.ResponseWriter, r *http.Request, p httprouter.Params) error) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		if err := fn(w, r, p); err != nil {
+			trace.WriteError(w, err)
+		}
+	}
+}
+
+func (s *WebHandler) needsAuth(fn ServiceHandle) httprouter.Handle {
+	return NeedsAuth(s.cfg.Devmode, s.cfg.Backend, s.cfg.Operator, s.cfg.Authenticator, s.cfg.Users, fn)
+}
+
+// GetHandlerContext authenticates the user that made the request and returns
+// the appropriate handler context
+func GetHandlerContext(w http.ResponseWriter, r *http.Request, backend storage.Backend, operator ops.Operator, authenticator users.Authenticator, usersService users.Identity) (*HandlerContext, error) {
+	logger := log.WithFields(fields.FromRequest(r))
+
+	authResult, err := authenticator.Authenticate(w, r)
+	if err != nil {
+		logger.WithError(err).Warn("Authentication error.")
+		return nil, trace.AccessDenied("bad username or password") // Hide the actual error.
+	}
+
+	// Enrich the request context with additional auth info.
+	ctx := r.Context()
+	ctx = context.WithValue(ctx, constants.UserContext, authResult.User.GetName())
+	if authResult.Session != nil {
+		ctx = context.WithValue(ctx, constants.WebSessionContext, authResult.Session.GetWebSession())
+	}
+	if key := r.Header.Get(ops.IdempotencyKeyHeader); key != "" {
+		ctx = ops.WithIdempotencyKey(ctx, key)
 	}
 
 	// create a permission aware wrapper packages service
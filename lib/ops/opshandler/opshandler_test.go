@@ -18,6 +18,9 @@ package opshandler
 
 import (
 	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
 	"net/http/httptest"
 	"os"
 	"strconv"
@@ -38,6 +41,7 @@ import (
 	teleservices "github.com/gravitational/teleport/lib/services"
 
 	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
 	"github.com/mailgun/timetools"
 	log "github.com/sirupsen/logrus"
 	. "gopkg.in/check.v1"
@@ -55,6 +59,7 @@ type OpsHandlerSuite struct {
 	testAppPath string
 	testApp     loc.Locator
 	client      *opsclient.Client
+	services    opsservice.TestServices
 
 	dir string
 }
@@ -73,6 +78,7 @@ func (s *OpsHandlerSuite) SetUpSuite(c *C) {
 func (s *OpsHandlerSuite) SetUpTest(c *C) {
 	services := opsservice.SetupTestServices(c)
 
+	s.services = services
 	s.backend = services.Backend
 	s.users = services.Users
 
@@ -89,8 +95,9 @@ func (s *OpsHandlerSuite) SetUpTest(c *C) {
 	}))
 	c.Assert(err, IsNil)
 
-	_, err = s.suite.SetUpTestPackage(services.Apps, services.Packages, c)
+	testApp, err := s.suite.SetUpTestPackage(services.Apps, services.Packages, c)
 	c.Assert(err, IsNil)
+	s.testApp = *testApp
 
 	handler, err := NewWebHandler(WebHandlerConfig{
 		Users:        s.users,
@@ -359,3 +366,227 @@ func (s *OpsHandlerSuite) TestClusterAuthConfiguration(c *C) {
 	c.Assert(actual.GetType(), Equals, cap.GetType())
 	c.Assert(actual.GetSecondFactor(), Equals, cap.GetSecondFactor())
 }
+
+func (s *OpsHandlerSuite) TestServesVersionedAPI(c *C) {
+	resp, _ := s.get(c, APIPrefix(APIVersion)+"/accounts")
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+	c.Assert(resp.Header.Get("Deprecation"), Equals, "")
+
+	resp, _ = s.get(c, APIPrefix(DeprecatedAPIVersion)+"/accounts")
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+	c.Assert(resp.Header.Get("Deprecation"), Equals, "true")
+	c.Assert(resp.Header.Get("Link"), Equals, `</portal/v2/accounts>; rel="successor-version"`)
+}
+
+func (s *OpsHandlerSuite) TestServesOpenAPIDocument(c *C) {
+	resp, body := s.get(c, APIPrefix(APIVersion)+"/openapi.json")
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+	var doc OpenAPIDocument
+	c.Assert(json.Unmarshal(body, &doc), IsNil)
+	c.Assert(doc.Info.Version, Equals, APIVersion)
+	c.Assert(doc.Servers, DeepEquals, []OpenAPIServer{{URL: "/portal/v2"}})
+
+	operationIDs := make(map[string]string)
+	for path, operations := range doc.Paths {
+		for method, operation := range operations {
+			existing, ok := operationIDs[operation.OperationID]
+			c.Assert(ok, Equals, false, Commentf("%v %v duplicates operation ID of %v",
+				method, path, existing))
+			operationIDs[operation.OperationID] = path
+		}
+	}
+
+	operation := doc.Paths["/accounts/{account_id}/sites/{site_domain}/operations/common/{operation_id}/plan"]["get"]
+	c.Assert(operation.OperationID, Equals, "getOperationPlan")
+	c.Assert(operation.Summary, Equals, "Get operation plan")
+	c.Assert(operation.Tags, DeepEquals, []string{"operations"})
+	c.Assert(operation.Parameters, HasLen, 3)
+	c.Assert(operation.Parameters[2].Name, Equals, "operation_id")
+	c.Assert(operation.Security, Not(HasLen), 0)
+
+	status := doc.Paths["/status"]["get"]
+	c.Assert(status.OperationID, Equals, "getStatus")
+	c.Assert(status.Security, HasLen, 0)
+}
+
+func (s *OpsHandlerSuite) TestFormatsOperationSummary(c *C) {
+	for operationID, expected := range map[string]string{
+		"getSites":               "Get sites",
+		"getSMTPConfig":          "Get SMTP config",
+		"signTLSKey":             "Sign TLS key",
+		"getClusterDNS":          "Get cluster DNS",
+		"updateInstallOperation": "Update install operation",
+	} {
+		c.Assert(summary(operationID), Equals, expected)
+	}
+}
+
+func (s *OpsHandlerSuite) TestClusterEvents(c *C) {
+	cluster, err := s.backend.CreateSite(storage.Site{
+		AccountID: defaults.SystemAccountID,
+		Domain:    "example.com",
+		App: storage.Package{
+			Repository: defaults.SystemAccountOrg,
+			Name:       "example",
+			Version:    "0.0.1",
+		},
+		Created: time.Now(),
+	})
+	c.Assert(err, IsNil)
+	key := ops.SiteKey{AccountID: cluster.AccountID, SiteDomain: cluster.Domain}
+	var created []storage.ClusterEvent
+	for _, name := range []string{"operation.started", "operation.completed"} {
+		event, err := s.backend.CreateClusterEvent(key.SiteDomain, storage.ClusterEvent{
+			Name:   name,
+			Fields: map[string]interface{}{"id": "op-1"},
+		}, time.Hour)
+		c.Assert(err, IsNil)
+		created = append(created, *event)
+	}
+
+	ctx := context.TODO()
+	events, err := s.client.GetClusterEvents(ctx, ops.GetClusterEventsRequest{SiteKey: key})
+	c.Assert(err, IsNil)
+	c.Assert(events, HasLen, 2)
+	c.Assert(events[1].ID, Equals, created[1].ID)
+	c.Assert(events[1].Fields, DeepEquals, created[1].Fields)
+
+	events, err = s.client.GetClusterEvents(ctx, ops.GetClusterEventsRequest{SiteKey: key, After: created[1].ID})
+	c.Assert(err, IsNil)
+	c.Assert(events, HasLen, 0)
+
+	stream, err := s.client.WatchClusterEvents(ctx, ops.GetClusterEventsRequest{SiteKey: key, After: created[0].ID})
+	c.Assert(err, IsNil)
+	defer stream.Close()
+	decoder := json.NewDecoder(stream)
+	var event storage.ClusterEvent
+	c.Assert(decoder.Decode(&event), IsNil)
+	c.Assert(event.ID, Equals, created[1].ID)
+
+	// Events recorded while streaming are sent as well
+	next, err := s.backend.CreateClusterEvent(key.SiteDomain, storage.ClusterEvent{Name: "node.degraded"}, time.Hour)
+	c.Assert(err, IsNil)
+	c.Assert(decoder.Decode(&event), IsNil)
+	c.Assert(event.ID, Equals, next.ID)
+}
+
+func (s *OpsHandlerSuite) TestRecordsRequests(c *C) {
+	cluster, err := s.backend.CreateSite(storage.Site{
+		AccountID: defaults.SystemAccountID,
+		Domain:    "example.com",
+		Local:     true,
+		App: storage.Package{
+			Repository: s.testApp.Repository,
+			Name:       s.testApp.Name,
+			Version:    s.testApp.Version,
+		},
+		Created: time.Now(),
+	})
+	c.Assert(err, IsNil)
+	key := ops.SiteKey{AccountID: cluster.AccountID, SiteDomain: cluster.Domain}
+
+	// read-only requests are not recorded by default
+	_, err = s.client.GetSite(key)
+	c.Assert(err, IsNil)
+	_, err = s.client.CreateAccount(ops.NewAccountRequest{Org: "audited"})
+	c.Assert(err, IsNil)
+	err = s.client.DeleteSiteOperation(ops.SiteOperationKey{
+		AccountID:   key.AccountID,
+		SiteDomain:  key.SiteDomain,
+		OperationID: "missing",
+	})
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%v", err))
+
+	records := s.waitForEvents(c, key, 2)
+	c.Assert(records[0].Code, Equals, "G5000I")
+	c.Assert(records[0].Fields["user"], Equals, s.adminUser)
+	c.Assert(records[0].Fields["verb"], Equals, http.MethodPost)
+	c.Assert(records[0].Fields["resource"], Equals, "createAccount")
+	c.Assert(records[1].Code, Equals, "G5000E")
+	c.Assert(records[1].Fields["verb"], Equals, http.MethodDelete)
+	c.Assert(records[1].Fields["resource"], Equals, "deleteOperation")
+	c.Assert(records[1].Fields["status"], Equals, float64(http.StatusNotFound))
+
+	handler, err := NewWebHandler(WebHandlerConfig{
+		Users:        s.users,
+		Operator:     s.services.Operator,
+		Applications: s.services.Apps,
+		Packages:     s.services.Packages,
+		AuditReads:   true,
+	})
+	c.Assert(err, IsNil)
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+	client, err := opsclient.NewAuthenticatedClient(
+		server.URL, s.adminUser, "admin-password",
+		opsclient.HTTPClient(server.Client()))
+	c.Assert(err, IsNil)
+	_, err = client.GetSite(key)
+	c.Assert(err, IsNil)
+	records = s.waitForEvents(c, key, 3)
+	c.Assert(records[2].Fields["verb"], Equals, http.MethodGet)
+	c.Assert(records[2].Fields["resource"], Equals, "getSite")
+}
+
+// waitForEvents waits until the specified number of events has been
+// recorded in the audit log of the cluster and returns them
+func (s *OpsHandlerSuite) waitForEvents(c *C, key ops.SiteKey, count int) (records []storage.ClusterEvent) {
+	for i := 0; i < 50; i++ {
+		var err error
+		records, err = s.client.GetClusterEvents(context.TODO(), ops.GetClusterEventsRequest{SiteKey: key})
+		c.Assert(err, IsNil)
+		if len(records) >= count {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	c.Assert(records, HasLen, count)
+	return records
+}
+
+func (s *OpsHandlerSuite) TestLimitsRequestRate(c *C) {
+	clock := clockwork.NewFakeClock()
+	handler, err := NewWebHandler(WebHandlerConfig{
+		Users:        s.users,
+		Operator:     s.services.Operator,
+		Applications: s.services.Apps,
+		Packages:     s.services.Packages,
+		RateLimit: RateLimitConfig{
+			RateLimit: RateLimit{Rate: 1, Burst: 2},
+			Users: map[string]RateLimit{
+				"robot@example.com": {},
+			},
+		},
+		Clock: clock,
+	})
+	c.Assert(err, IsNil)
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+	client, err := opsclient.NewAuthenticatedClient(
+		server.URL, s.adminUser, "admin-password",
+		opsclient.HTTPClient(server.Client()))
+	c.Assert(err, IsNil)
+
+	for i := 0; i < 2; i++ {
+		_, err = client.GetAccounts()
+		c.Assert(err, IsNil)
+	}
+	_, err = client.GetAccounts()
+	c.Assert(trace.IsLimitExceeded(err), Equals, true, Commentf("%v", err))
+
+	clock.Advance(time.Second)
+	_, err = client.GetAccounts()
+	c.Assert(err, IsNil)
+}
+
+func (s *OpsHandlerSuite) get(c *C, path string) (*http.Response, []byte) {
+	req, err := http.NewRequest(http.MethodGet, s.webServer.URL+path, nil)
+	c.Assert(err, IsNil)
+	req.SetBasicAuth(s.adminUser, "admin-password")
+	resp, err := s.webServer.Client().Do(req)
+	c.Assert(err, IsNil)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, IsNil)
+	return resp, body
+}
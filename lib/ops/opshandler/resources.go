@@ -233,6 +233,76 @@ func (h *WebHandler) deleteUser(w http.ResponseWriter, r *http.Request, p httpro
 	return nil
 }
 
+/* getServiceAccounts returns all service accounts
+
+     GET /portal/v1/accounts/:account_id/sites/:site_domain/serviceaccounts
+
+   Success Response:
+
+     []storage.ServiceAccount
+*/
+func (h *WebHandler) getServiceAccounts(w http.ResponseWriter, r *http.Request, p httprouter.Params, ctx *HandlerContext) error {
+	accounts, err := ctx.Operator.GetServiceAccounts(siteKey(p))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	items := make([]json.RawMessage, len(accounts))
+	for i, account := range accounts {
+		data, err := storage.MarshalServiceAccount(account)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		items[i] = data
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, items)
+	return nil
+}
+
+/* upsertServiceAccount creates or updates a service account
+
+     PUT /portal/v1/accounts/:account_id/sites/:site_domain/serviceaccounts/:name
+
+   Success Response:
+
+     {
+       "message": "service account updated"
+     }
+*/
+func (h *WebHandler) upsertServiceAccount(w http.ResponseWriter, r *http.Request, p httprouter.Params, ctx *HandlerContext) error {
+	var req opsclient.UpsertResourceRawReq
+	if err := telehttplib.ReadJSON(r, &req); err != nil {
+		return trace.Wrap(err)
+	}
+	account, err := storage.UnmarshalServiceAccount(req.Resource)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := ctx.Operator.UpsertServiceAccount(r.Context(), siteKey(p), account); err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, message("service account updated"))
+	return nil
+}
+
+/* deleteServiceAccount deletes a service account by name
+
+     DELETE /portal/v1/accounts/:account_id/sites/:site_domain/serviceaccounts/:name
+
+   Success Response:
+
+     {
+       "message": "service account deleted"
+     }
+*/
+func (h *WebHandler) deleteServiceAccount(w http.ResponseWriter, r *http.Request, p httprouter.Params, ctx *HandlerContext) error {
+	err := ctx.Operator.DeleteServiceAccount(r.Context(), siteKey(p), p.ByName("name"))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roundtrip.ReplyJSON(w, http.StatusOK, message("service account deleted"))
+	return nil
+}
+
 /* upsertGithubConnector creates or updates a Github connector
 
    POST /portal/v1/accounts/:account_id/sites/:site_domain/github/connectors
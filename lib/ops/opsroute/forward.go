@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"io"
 	"net/url"
+	"time"
 
 	"github.com/gravitational/gravity/lib/clients"
 	"github.com/gravitational/gravity/lib/constants"
@@ -353,6 +354,16 @@ func (r *Router) CreateUpdateEnvarsOperation(ctx context.Context, req ops.Create
 	return r.Local.CreateUpdateEnvarsOperation(ctx, req)
 }
 
+// CreateUpdateNodeRoleOperation creates a new operation to promote or demote a cluster node
+func (r *Router) CreateUpdateNodeRoleOperation(ctx context.Context, req ops.CreateUpdateNodeRoleOperationRequest) (*ops.SiteOperationKey, error) {
+	return r.Local.CreateUpdateNodeRoleOperation(ctx, req)
+}
+
+// CreateReplaceNodeOperation creates a new operation to replace a cluster node with a new one
+func (r *Router) CreateReplaceNodeOperation(ctx context.Context, req ops.CreateReplaceNodeOperationRequest) (*ops.SiteOperationKey, error) {
+	return r.Local.CreateReplaceNodeOperation(ctx, req)
+}
+
 // CreateUpdateConfigOperation creates a new operation to update cluster configuration
 func (r *Router) CreateUpdateConfigOperation(ctx context.Context, req ops.CreateUpdateConfigOperationRequest) (*ops.SiteOperationKey, error) {
 	return r.Local.CreateUpdateConfigOperation(ctx, req)
@@ -603,6 +614,406 @@ func (r *Router) DeleteSMTPConfig(ctx context.Context, key ops.SiteKey) error {
 	return client.DeleteSMTPConfig(ctx, key)
 }
 
+// GetClusterDNS returns the cluster DNS configuration
+func (r *Router) GetClusterDNS(key ops.SiteKey) (storage.ClusterDNS, error) {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetClusterDNS(key)
+}
+
+// UpdateClusterDNS updates the cluster DNS configuration
+func (r *Router) UpdateClusterDNS(ctx context.Context, key ops.SiteKey, config storage.ClusterDNS) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.UpdateClusterDNS(ctx, key, config)
+}
+
+// GetNodePools returns the list of node pools of the cluster
+func (r *Router) GetNodePools(key ops.SiteKey) ([]storage.NodePool, error) {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetNodePools(key)
+}
+
+// UpsertNodePool creates or updates a node pool
+func (r *Router) UpsertNodePool(ctx context.Context, key ops.SiteKey, pool storage.NodePool) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.UpsertNodePool(ctx, key, pool)
+}
+
+// DeleteNodePool deletes the node pool with the specified name
+func (r *Router) DeleteNodePool(ctx context.Context, key ops.SiteKey, name string) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.DeleteNodePool(ctx, key, name)
+}
+
+// GetTrackedReleases returns the application releases recorded for the cluster
+func (r *Router) GetTrackedReleases(key ops.SiteKey) ([]storage.Release, error) {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetTrackedReleases(key)
+}
+
+// TrackRelease records the state of the application release
+func (r *Router) TrackRelease(ctx context.Context, key ops.SiteKey, release storage.Release) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.TrackRelease(ctx, key, release)
+}
+
+// UntrackRelease removes the record of the release with the specified name
+func (r *Router) UntrackRelease(ctx context.Context, key ops.SiteKey, name string) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.UntrackRelease(ctx, key, name)
+}
+
+// GetOperationWebhooks returns the list of operation webhooks of the cluster
+func (r *Router) GetOperationWebhooks(key ops.SiteKey) ([]storage.OperationWebhook, error) {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetOperationWebhooks(key)
+}
+
+// UpsertOperationWebhook creates or updates an operation webhook
+func (r *Router) UpsertOperationWebhook(ctx context.Context, key ops.SiteKey, webhook storage.OperationWebhook) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.UpsertOperationWebhook(ctx, key, webhook)
+}
+
+// DeleteOperationWebhook deletes the operation webhook with the specified name
+func (r *Router) DeleteOperationWebhook(ctx context.Context, key ops.SiteKey, name string) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.DeleteOperationWebhook(ctx, key, name)
+}
+
+// GetHealthChecks returns the list of health checks of the cluster
+func (r *Router) GetHealthChecks(key ops.SiteKey) ([]storage.HealthCheck, error) {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetHealthChecks(key)
+}
+
+// UpsertHealthCheck creates or updates a health check
+func (r *Router) UpsertHealthCheck(ctx context.Context, key ops.SiteKey, check storage.HealthCheck) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.UpsertHealthCheck(ctx, key, check)
+}
+
+// DeleteHealthCheck deletes the health check with the specified name
+func (r *Router) DeleteHealthCheck(ctx context.Context, key ops.SiteKey, name string) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.DeleteHealthCheck(ctx, key, name)
+}
+
+// GetHealthCheckStatus returns the result of the last evaluation
+// of the cluster health checks
+func (r *Router) GetHealthCheckStatus(key ops.SiteKey) (*storage.HealthCheckStatus, error) {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetHealthCheckStatus(key)
+}
+
+// GetHealthHistory returns the cluster health snapshots recorded since
+// the specified time
+func (r *Router) GetHealthHistory(key ops.SiteKey, since time.Time) ([]storage.HealthSnapshot, error) {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetHealthHistory(key, since)
+}
+
+// GetNetworkHealth returns the network health reports of the cluster nodes
+func (r *Router) GetNetworkHealth(key ops.SiteKey) ([]storage.NetworkHealthReport, error) {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetNetworkHealth(key)
+}
+
+// GetClusterRoster returns the cluster roster
+func (r *Router) GetClusterRoster(key ops.SiteKey) (storage.ClusterRoster, error) {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetClusterRoster(key)
+}
+
+// UpsertClusterRoster creates or updates the cluster roster
+func (r *Router) UpsertClusterRoster(ctx context.Context, key ops.SiteKey, roster storage.ClusterRoster) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.UpsertClusterRoster(ctx, key, roster)
+}
+
+// DeleteClusterRoster deletes the cluster roster
+func (r *Router) DeleteClusterRoster(ctx context.Context, key ops.SiteKey) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.DeleteClusterRoster(ctx, key)
+}
+
+// GetClusterRosterStatus returns the status of the roster reconciliation
+func (r *Router) GetClusterRosterStatus(key ops.SiteKey) (*storage.ClusterRosterStatus, error) {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetClusterRosterStatus(key)
+}
+
+// ApproveClusterRosterChanges approves the pending membership changes
+func (r *Router) ApproveClusterRosterChanges(ctx context.Context, key ops.SiteKey, changesID string) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.ApproveClusterRosterChanges(ctx, key, changesID)
+}
+
+// GetImageTrustPolicy returns the image trust policy
+func (r *Router) GetImageTrustPolicy(key ops.SiteKey) (storage.ImageTrustPolicy, error) {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetImageTrustPolicy(key)
+}
+
+// UpsertImageTrustPolicy creates or updates the image trust policy
+func (r *Router) UpsertImageTrustPolicy(ctx context.Context, key ops.SiteKey, policy storage.ImageTrustPolicy) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.UpsertImageTrustPolicy(ctx, key, policy)
+}
+
+// DeleteImageTrustPolicy deletes the image trust policy
+func (r *Router) DeleteImageTrustPolicy(ctx context.Context, key ops.SiteKey) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.DeleteImageTrustPolicy(ctx, key)
+}
+
+// GetOperationApprovalPolicy returns the operation approval policy
+func (r *Router) GetOperationApprovalPolicy(key ops.SiteKey) (storage.OperationApprovalPolicy, error) {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetOperationApprovalPolicy(key)
+}
+
+// UpsertOperationApprovalPolicy creates or updates the operation approval policy
+func (r *Router) UpsertOperationApprovalPolicy(ctx context.Context, key ops.SiteKey, policy storage.OperationApprovalPolicy) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.UpsertOperationApprovalPolicy(ctx, key, policy)
+}
+
+// DeleteOperationApprovalPolicy deletes the operation approval policy
+func (r *Router) DeleteOperationApprovalPolicy(ctx context.Context, key ops.SiteKey) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.DeleteOperationApprovalPolicy(ctx, key)
+}
+
+// ApproveOperation approves the specified operation
+func (r *Router) ApproveOperation(ctx context.Context, key ops.SiteOperationKey) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.ApproveOperation(ctx, key)
+}
+
+// GetCatalogSyncSchedule returns the catalog sync schedule
+func (r *Router) GetCatalogSyncSchedule(key ops.SiteKey) (storage.CatalogSyncSchedule, error) {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetCatalogSyncSchedule(key)
+}
+
+// UpsertCatalogSyncSchedule creates or updates the catalog sync schedule
+func (r *Router) UpsertCatalogSyncSchedule(ctx context.Context, key ops.SiteKey, schedule storage.CatalogSyncSchedule) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.UpsertCatalogSyncSchedule(ctx, key, schedule)
+}
+
+// DeleteCatalogSyncSchedule deletes the catalog sync schedule
+func (r *Router) DeleteCatalogSyncSchedule(ctx context.Context, key ops.SiteKey) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.DeleteCatalogSyncSchedule(ctx, key)
+}
+
+// GetBackupSchedule returns the backup schedule
+func (r *Router) GetBackupSchedule(key ops.SiteKey) (storage.BackupSchedule, error) {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetBackupSchedule(key)
+}
+
+// UpsertBackupSchedule creates or updates the backup schedule
+func (r *Router) UpsertBackupSchedule(ctx context.Context, key ops.SiteKey, schedule storage.BackupSchedule) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.UpsertBackupSchedule(ctx, key, schedule)
+}
+
+// DeleteBackupSchedule deletes the backup schedule
+func (r *Router) DeleteBackupSchedule(ctx context.Context, key ops.SiteKey) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.DeleteBackupSchedule(ctx, key)
+}
+
+// GetBackupStatus returns the status of the last scheduled backup
+func (r *Router) GetBackupStatus(key ops.SiteKey) (*storage.BackupStatus, error) {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetBackupStatus(key)
+}
+
+// GetReplication returns the replication of the primary cluster backups
+func (r *Router) GetReplication(key ops.SiteKey) (storage.Replication, error) {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetReplication(key)
+}
+
+// UpsertReplication creates or updates the replication
+func (r *Router) UpsertReplication(ctx context.Context, key ops.SiteKey, replication storage.Replication) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.UpsertReplication(ctx, key, replication)
+}
+
+// DeleteReplication deletes the replication
+func (r *Router) DeleteReplication(ctx context.Context, key ops.SiteKey) error {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.DeleteReplication(ctx, key)
+}
+
+// GetReplicationStatus returns the status of the replication
+func (r *Router) GetReplicationStatus(key ops.SiteKey) (*storage.ReplicationStatus, error) {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetReplicationStatus(key)
+}
+
+// CreateRestoreOperation creates a new operation to restore the cluster from a backup
+func (r *Router) CreateRestoreOperation(ctx context.Context, req ops.CreateRestoreOperationRequest) (*ops.SiteOperationKey, error) {
+	return r.Local.CreateRestoreOperation(ctx, req)
+}
+
+// CreateEtcdRestoreOperation creates a new operation to restore etcd from a snapshot
+func (r *Router) CreateEtcdRestoreOperation(ctx context.Context, req ops.CreateEtcdRestoreOperationRequest) (*ops.SiteOperationKey, error) {
+	return r.Local.CreateEtcdRestoreOperation(ctx, req)
+}
+
+// CreateAppBackupOperation creates a new operation to back up an application
+func (r *Router) CreateAppBackupOperation(ctx context.Context, req ops.CreateAppBackupOperationRequest) (*ops.SiteOperationKey, error) {
+	return r.Local.CreateAppBackupOperation(ctx, req)
+}
+
+// CreateAppRestoreOperation creates a new operation to restore an application from its backup
+func (r *Router) CreateAppRestoreOperation(ctx context.Context, req ops.CreateAppBackupOperationRequest) (*ops.SiteOperationKey, error) {
+	return r.Local.CreateAppRestoreOperation(ctx, req)
+}
+
+// GetEtcdMaintenanceStatus returns the status of the etcd compaction and defragmentation
+func (r *Router) GetEtcdMaintenanceStatus(key ops.SiteKey) (*storage.EtcdMaintenanceStatus, error) {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetEtcdMaintenanceStatus(key)
+}
+
+// GetEtcdHealthStatus returns the status of the etcd health and capacity
+func (r *Router) GetEtcdHealthStatus(key ops.SiteKey) (*storage.EtcdHealthStatus, error) {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetEtcdHealthStatus(key)
+}
+
 // GetAlerts returns a list of monitoring alerts
 func (r *Router) GetAlerts(key ops.SiteKey) ([]storage.Alert, error) {
 	client, err := r.RemoteClient(key.SiteDomain)
@@ -745,6 +1156,30 @@ func (r *Router) GetClusterCertificate(key ops.SiteKey, withSecrets bool) (*ops.
 	return client.GetClusterCertificate(key, withSecrets)
 }
 
+// GetCertificateExpiry returns the expiration status of the cluster certificates
+func (r *Router) GetCertificateExpiry(key ops.SiteKey) (*storage.CertificateExpiryStatus, error) {
+	client, err := r.RemoteClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetCertificateExpiry(key)
+}
+
+// CreateRotateSecretsKeyOperation creates a new operation to rotate the key that encrypts secrets at rest
+func (r *Router) CreateRotateSecretsKeyOperation(ctx context.Context, req ops.CreateRotateSecretsKeyOperationRequest) (*ops.SiteOperationKey, error) {
+	return r.Local.CreateRotateSecretsKeyOperation(ctx, req)
+}
+
+// CreateChangeCIDROperation creates a new operation to change the pod and service subnets of the cluster
+func (r *Router) CreateChangeCIDROperation(ctx context.Context, req ops.CreateChangeCIDROperationRequest) (*ops.SiteOperationKey, error) {
+	return r.Local.CreateChangeCIDROperation(ctx, req)
+}
+
+// CreateRotateCertificatesOperation creates a new operation to rotate the cluster certificates
+func (r *Router) CreateRotateCertificatesOperation(ctx context.Context, req ops.CreateRotateCertificatesOperationRequest) (*ops.SiteOperationKey, error) {
+	return r.Local.CreateRotateCertificatesOperation(ctx, req)
+}
+
 // UpdateClusterCertificate updates the cluster certificate
 func (r *Router) UpdateClusterCertificate(ctx context.Context, req ops.UpdateCertificateRequest) (*ops.ClusterCertificate, error) {
 	client, err := r.RemoteClient(req.SiteDomain)
@@ -805,6 +1240,33 @@ func (r *Router) DeleteUser(ctx context.Context, key ops.SiteKey, name string) e
 	return client.DeleteUser(ctx, key, name)
 }
 
+// UpsertServiceAccount creates or updates a service account
+func (r *Router) UpsertServiceAccount(ctx context.Context, key ops.SiteKey, account storage.ServiceAccount) error {
+	client, err := r.PickClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.UpsertServiceAccount(ctx, key, account)
+}
+
+// GetServiceAccounts returns all service accounts
+func (r *Router) GetServiceAccounts(key ops.SiteKey) ([]storage.ServiceAccount, error) {
+	client, err := r.PickClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetServiceAccounts(key)
+}
+
+// DeleteServiceAccount deletes the service account with the specified name
+func (r *Router) DeleteServiceAccount(ctx context.Context, key ops.SiteKey, name string) error {
+	client, err := r.PickClient(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.DeleteServiceAccount(ctx, key, name)
+}
+
 // UpsertClusterAuthPreference updates cluster authentication preference
 func (r *Router) UpsertClusterAuthPreference(ctx context.Context, key ops.SiteKey, auth teleservices.AuthPreference) error {
 	client, err := r.PickClient(key.SiteDomain)
@@ -891,6 +1353,52 @@ func (r *Router) EmitAuditEvent(ctx context.Context, req ops.AuditEventRequest)
 	return r.Local.EmitAuditEvent(ctx, req)
 }
 
+// GetClusterEvents returns the cluster events recorded after the
+// event specified in the request.
+func (r *Router) GetClusterEvents(ctx context.Context, req ops.GetClusterEventsRequest) ([]storage.ClusterEvent, error) {
+	client, err := r.PickClient(req.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetClusterEvents(ctx, req)
+}
+
+// CreateJoinToken creates a new short-lived cluster join token
+func (r *Router) CreateJoinToken(ctx context.Context, req ops.CreateJoinTokenRequest) (*storage.ProvisioningToken, error) {
+	client, err := r.PickClient(req.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.CreateJoinToken(ctx, req)
+}
+
+// GetJoinTokens returns the active short-lived join tokens of the cluster
+func (r *Router) GetJoinTokens(ctx context.Context, key ops.SiteKey) ([]storage.ProvisioningToken, error) {
+	client, err := r.PickClient(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.GetJoinTokens(ctx, key)
+}
+
+// DeleteJoinToken deletes the specified short-lived join token
+func (r *Router) DeleteJoinToken(ctx context.Context, req ops.DeleteJoinTokenRequest) error {
+	client, err := r.PickClient(req.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return client.DeleteJoinToken(ctx, req)
+}
+
+// RequestScaleUp requests expansion of the cluster by the specified number of nodes
+func (r *Router) RequestScaleUp(ctx context.Context, req ops.ScaleUpRequest) (*storage.ProvisioningToken, error) {
+	client, err := r.PickClient(req.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client.RequestScaleUp(ctx, req)
+}
+
 // CreateUserInvite creates a new invite token for a user.
 func (r *Router) CreateUserInvite(ctx context.Context, req ops.CreateUserInviteRequest) (*storage.UserToken, error) {
 	client, err := r.PickClient(req.SiteDomain)
@@ -194,9 +194,10 @@ func (r *AgentService) Validate(ctx context.Context, key ops.SiteOperationKey, a
 		// Verify full requirements from the manifest
 		FullRequirements: true,
 		Options: &validationpb.ValidateOptions{
-			VxlanPort: int32(operation.Vars().OnPrem.VxlanPort),
-			DnsAddrs:  cluster.DNSConfig.Addrs,
-			DnsPort:   int32(cluster.DNSConfig.Port),
+			VxlanPort:     int32(operation.Vars().OnPrem.VxlanPort),
+			DnsAddrs:      cluster.DNSConfig.Addrs,
+			DnsPort:       int32(cluster.DNSConfig.Port),
+			WireguardPort: int32(operation.Vars().OnPrem.WireguardPort()),
 		},
 		Docker: &validationpb.Docker{
 			StorageDriver: cluster.ClusterState.Docker.StorageDriver,
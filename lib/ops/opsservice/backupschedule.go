@@ -0,0 +1,115 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/events"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// GetBackupSchedule returns the backup schedule
+func (o *Operator) GetBackupSchedule(key ops.SiteKey) (storage.BackupSchedule, error) {
+	schedule, err := o.backend().GetBackupSchedule(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return schedule, nil
+}
+
+// UpsertBackupSchedule creates or updates the backup schedule.
+// The backup scheduler picks up the new schedule on its next check
+func (o *Operator) UpsertBackupSchedule(ctx context.Context, key ops.SiteKey, schedule storage.BackupSchedule) error {
+	if err := schedule.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := o.backend().UpsertBackupSchedule(key.SiteDomain, schedule); err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.BackupScheduleUpdated, events.Fields{
+		events.FieldName: schedule.GetSchedule(),
+	})
+	return nil
+}
+
+// DeleteBackupSchedule deletes the backup schedule
+func (o *Operator) DeleteBackupSchedule(ctx context.Context, key ops.SiteKey) error {
+	if err := o.backend().DeleteBackupSchedule(key.SiteDomain); err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.BackupScheduleDeleted)
+	return nil
+}
+
+// GetBackupStatus returns the status of the last scheduled backup
+func (o *Operator) GetBackupStatus(key ops.SiteKey) (*storage.BackupStatus, error) {
+	status, err := o.backend().GetBackupStatus(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return status, nil
+}
+
+// GetReplication returns the replication of the primary cluster backups
+func (o *Operator) GetReplication(key ops.SiteKey) (storage.Replication, error) {
+	replication, err := o.backend().GetReplication(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return replication, nil
+}
+
+// UpsertReplication creates or updates the replication.
+// The replicator picks up the new replication on its next check
+func (o *Operator) UpsertReplication(ctx context.Context, key ops.SiteKey, replication storage.Replication) error {
+	if err := replication.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := o.backend().UpsertReplication(key.SiteDomain, replication); err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.ReplicationUpdated, events.Fields{
+		events.FieldName: replication.GetSource().String(),
+	})
+	return nil
+}
+
+// DeleteReplication deletes the replication along with its status
+func (o *Operator) DeleteReplication(ctx context.Context, key ops.SiteKey) error {
+	if err := o.backend().DeleteReplication(key.SiteDomain); err != nil {
+		return trace.Wrap(err)
+	}
+	err := o.backend().DeleteReplicationStatus(key.SiteDomain)
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.ReplicationDeleted)
+	return nil
+}
+
+// GetReplicationStatus returns the status of the replication
+func (o *Operator) GetReplicationStatus(key ops.SiteKey) (*storage.ReplicationStatus, error) {
+	status, err := o.backend().GetReplicationStatus(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return status, nil
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/events"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// GetCatalogSyncSchedule returns the catalog sync schedule
+func (o *Operator) GetCatalogSyncSchedule(key ops.SiteKey) (storage.CatalogSyncSchedule, error) {
+	schedule, err := o.backend().GetCatalogSyncSchedule(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return schedule, nil
+}
+
+// UpsertCatalogSyncSchedule creates or updates the catalog sync schedule.
+// The catalog syncer picks up the new schedule on its next check
+func (o *Operator) UpsertCatalogSyncSchedule(ctx context.Context, key ops.SiteKey, schedule storage.CatalogSyncSchedule) error {
+	if err := o.backend().UpsertCatalogSyncSchedule(key.SiteDomain, schedule); err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.CatalogSyncScheduleUpdated, events.Fields{
+		events.FieldName: schedule.GetRepository(),
+	})
+	return nil
+}
+
+// DeleteCatalogSyncSchedule deletes the catalog sync schedule
+func (o *Operator) DeleteCatalogSyncSchedule(ctx context.Context, key ops.SiteKey) error {
+	if err := o.backend().DeleteCatalogSyncSchedule(key.SiteDomain); err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.CatalogSyncScheduleDeleted)
+	return nil
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"bytes"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/cloudflare/cfssl/csr"
+	"github.com/cloudflare/cfssl/helpers"
+	"github.com/gravitational/license/authority"
+	"github.com/gravitational/trace"
+)
+
+// StageCertAuthority generates a new certificate authority for the specified
+// cluster and adds it to the certificate authority package as pending.
+//
+// The runtime secrets generated while the new certificate authority is pending
+// are still issued by the current certificate authority but trust both
+func StageCertAuthority(packages pack.PackageService, clusterName string) error {
+	return updateCertAuthorityPackage(packages, clusterName, func(archive utils.TLSArchive) error {
+		if _, err := archive.GetKeyPair(constants.PendingRootKeyPair); err == nil {
+			return nil
+		}
+		current, err := archive.GetKeyPair(constants.RootKeyPair)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		selfSigned, err := isSelfSigned(*current)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if !selfSigned {
+			return trace.BadParameter("the cluster certificate authority is issued " +
+				"by an external certificate authority and can't be replaced with a generated one")
+		}
+		certAuthority, err := newCertAuthority(clusterName)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		archive[constants.PendingRootKeyPair] = certAuthority
+		return nil
+	})
+}
+
+// UnstageCertAuthority removes the pending certificate authority
+// from the certificate authority package of the specified cluster
+func UnstageCertAuthority(packages pack.PackageService, clusterName string) error {
+	return updateCertAuthorityPackage(packages, clusterName, func(archive utils.TLSArchive) error {
+		delete(archive, constants.PendingRootKeyPair)
+		return nil
+	})
+}
+
+// PromoteCertAuthority makes the pending certificate authority the certificate
+// authority of the specified cluster.
+//
+// The replaced certificate authority is kept as previous so the nodes still
+// trust the certificates it has issued until they have been rotated
+func PromoteCertAuthority(packages pack.PackageService, clusterName string) error {
+	return updateCertAuthorityPackage(packages, clusterName, promoteCertAuthority)
+}
+
+// DemoteCertAuthority reverts PromoteCertAuthority: it restores the previous
+// certificate authority of the specified cluster and makes the current one pending
+func DemoteCertAuthority(packages pack.PackageService, clusterName string) error {
+	return updateCertAuthorityPackage(packages, clusterName, demoteCertAuthority)
+}
+
+func promoteCertAuthority(archive utils.TLSArchive) error {
+	pending, err := archive.GetKeyPair(constants.PendingRootKeyPair)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	current, err := archive.GetKeyPair(constants.RootKeyPair)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	archive[constants.PreviousRootKeyPair] = current
+	archive[constants.RootKeyPair] = pending
+	delete(archive, constants.PendingRootKeyPair)
+	return trace.Wrap(reissueAPIServerKeyPair(archive))
+}
+
+func demoteCertAuthority(archive utils.TLSArchive) error {
+	previous, err := archive.GetKeyPair(constants.PreviousRootKeyPair)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	current, err := archive.GetKeyPair(constants.RootKeyPair)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	archive[constants.PendingRootKeyPair] = current
+	archive[constants.RootKeyPair] = previous
+	delete(archive, constants.PreviousRootKeyPair)
+	return trace.Wrap(reissueAPIServerKeyPair(archive))
+}
+
+// reissueAPIServerKeyPair issues the apiserver certificate with the current
+// certificate authority keeping the private key shared by all apiservers
+func reissueAPIServerKeyPair(archive utils.TLSArchive) error {
+	certAuthority, err := archive.GetKeyPair(constants.RootKeyPair)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	apiServer, err := archive.GetKeyPair(constants.APIServerKeyPair)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	keyPair, err := newAPIServerKeyPair(certAuthority, apiServer.KeyPEM)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	archive[constants.APIServerKeyPair] = keyPair
+	return nil
+}
+
+// trustedCertAuthority returns the certificate authority from the specified
+// archive with the certificates of the pending and the previous certificate
+// authorities appended, so the nodes trust the certificates issued by either
+// of them while the certificate authority is being rotated
+func trustedCertAuthority(archive utils.TLSArchive) (*authority.TLSKeyPair, error) {
+	certAuthority, err := archive.GetKeyPair(constants.RootKeyPair)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var certs bytes.Buffer
+	certs.Write(certAuthority.CertPEM)
+	for _, name := range []string{constants.PendingRootKeyPair, constants.PreviousRootKeyPair} {
+		keyPair, err := archive.GetKeyPair(name)
+		if err != nil {
+			continue
+		}
+		if certs.Len() != 0 && !bytes.HasSuffix(certs.Bytes(), []byte("\n")) {
+			certs.WriteByte('\n')
+		}
+		certs.Write(keyPair.CertPEM)
+	}
+	return &authority.TLSKeyPair{
+		CertPEM: certs.Bytes(),
+		KeyPEM:  certAuthority.KeyPEM,
+	}, nil
+}
+
+// isSelfSigned returns true if the certificate of the specified
+// certificate authority is self-signed
+func isSelfSigned(keyPair authority.TLSKeyPair) (bool, error) {
+	cert, err := helpers.ParseCertificatePEM(keyPair.CertPEM)
+	if err != nil {
+		return false, trace.Wrap(err)
+	}
+	return cert.CheckSignatureFrom(cert) == nil, nil
+}
+
+// updateCertAuthorityPackage applies the provided update to the certificate
+// authority package of the specified cluster
+func updateCertAuthorityPackage(packages pack.PackageService, clusterName string, update func(utils.TLSArchive) error) error {
+	caPackage, err := PlanetCertAuthorityPackage(clusterName)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	envelope, reader, err := packages.ReadPackage(*caPackage)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	archive, err := utils.ReadTLSArchive(reader)
+	reader.Close()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := update(archive); err != nil {
+		return trace.Wrap(err)
+	}
+	reader, err = utils.CreateTLSArchive(archive)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer reader.Close()
+	_, err = packages.UpsertPackage(*caPackage, reader, pack.WithLabels(envelope.RuntimeLabels))
+	return trace.Wrap(err)
+}
+
+// newCertAuthority generates a new self-signed certificate authority
+// for the specified cluster
+func newCertAuthority(clusterName string) (*authority.TLSKeyPair, error) {
+	return authority.GenerateSelfSignedCA(csr.CertificateRequest{
+		CN: clusterName,
+		CA: &csr.CAConfig{
+			Expiry: defaults.CACertificateExpiry.String(),
+		},
+	})
+}
+
+// newAPIServerKeyPair issues the apiserver certificate with the specified
+// certificate authority. If privateKeyPEM is empty, a new private key is generated
+func newAPIServerKeyPair(certAuthority *authority.TLSKeyPair, privateKeyPEM []byte) (*authority.TLSKeyPair, error) {
+	return authority.GenerateCertificate(csr.CertificateRequest{
+		CN:    constants.APIServerKeyPair,
+		Hosts: []string{"127.0.0.1"},
+		Names: []csr.Name{
+			{
+				O: defaults.SystemAccountOrg,
+			},
+		},
+	}, certAuthority, privateKeyPEM, defaults.CertificateExpiry)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"crypto/x509"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/license/authority"
+	teleutils "github.com/gravitational/teleport/lib/utils"
+	"gopkg.in/check.v1"
+)
+
+type CertAuthoritySuite struct{}
+
+var _ = check.Suite(&CertAuthoritySuite{})
+
+func (s *CertAuthoritySuite) TestRotatesCertAuthority(c *check.C) {
+	current, err := newCertAuthority("example.com")
+	c.Assert(err, check.IsNil)
+	apiServer, err := newAPIServerKeyPair(current, nil)
+	c.Assert(err, check.IsNil)
+	pending, err := newCertAuthority("example.com")
+	c.Assert(err, check.IsNil)
+	archive := utils.TLSArchive{
+		constants.RootKeyPair:        current,
+		constants.APIServerKeyPair:   apiServer,
+		constants.PendingRootKeyPair: pending,
+	}
+
+	// While pending, the new certificate authority is trusted but does not issue certificates
+	trusted, err := trustedCertAuthority(archive)
+	c.Assert(err, check.IsNil)
+	c.Assert(trusted.KeyPEM, check.DeepEquals, current.KeyPEM)
+	assertVerifies(c, trusted, current, pending)
+
+	c.Assert(promoteCertAuthority(archive), check.IsNil)
+	c.Assert(archive[constants.RootKeyPair], check.Equals, pending)
+	c.Assert(archive[constants.PreviousRootKeyPair], check.Equals, current)
+	_, err = archive.GetKeyPair(constants.PendingRootKeyPair)
+	c.Assert(err, check.NotNil)
+	// The apiserver certificate is reissued by the new certificate authority with the same key
+	c.Assert(archive[constants.APIServerKeyPair].KeyPEM, check.DeepEquals, apiServer.KeyPEM)
+	assertIssuedBy(c, archive[constants.APIServerKeyPair], pending)
+
+	trusted, err = trustedCertAuthority(archive)
+	c.Assert(err, check.IsNil)
+	c.Assert(trusted.KeyPEM, check.DeepEquals, pending.KeyPEM)
+	assertVerifies(c, trusted, current, pending)
+
+	c.Assert(demoteCertAuthority(archive), check.IsNil)
+	c.Assert(archive[constants.RootKeyPair], check.Equals, current)
+	c.Assert(archive[constants.PendingRootKeyPair], check.Equals, pending)
+	_, err = archive.GetKeyPair(constants.PreviousRootKeyPair)
+	c.Assert(err, check.NotNil)
+	assertIssuedBy(c, archive[constants.APIServerKeyPair], current)
+}
+
+func (s *CertAuthoritySuite) TestDetectsExternalCertAuthority(c *check.C) {
+	generated, err := newCertAuthority("example.com")
+	c.Assert(err, check.IsNil)
+	selfSigned, err := isSelfSigned(*generated)
+	c.Assert(err, check.IsNil)
+	c.Assert(selfSigned, check.Equals, true)
+
+	issued, err := newAPIServerKeyPair(generated, nil)
+	c.Assert(err, check.IsNil)
+	selfSigned, err = isSelfSigned(*issued)
+	c.Assert(err, check.IsNil)
+	c.Assert(selfSigned, check.Equals, false)
+}
+
+// assertVerifies verifies that the certificates issued by each of the specified
+// certificate authorities are trusted by the given trusted certificate bundle
+func assertVerifies(c *check.C, trusted *authority.TLSKeyPair, issuers ...*authority.TLSKeyPair) {
+	roots := x509.NewCertPool()
+	c.Assert(roots.AppendCertsFromPEM(trusted.CertPEM), check.Equals, true)
+	for _, issuer := range issuers {
+		keyPair, err := newAPIServerKeyPair(issuer, nil)
+		c.Assert(err, check.IsNil)
+		cert, err := teleutils.ParseCertificatePEM(keyPair.CertPEM)
+		c.Assert(err, check.IsNil)
+		_, err = cert.Verify(x509.VerifyOptions{
+			Roots:     roots,
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		})
+		c.Assert(err, check.IsNil)
+	}
+}
+
+func assertIssuedBy(c *check.C, keyPair, issuer *authority.TLSKeyPair) {
+	roots := x509.NewCertPool()
+	c.Assert(roots.AppendCertsFromPEM(issuer.CertPEM), check.Equals, true)
+	cert, err := teleutils.ParseCertificatePEM(keyPair.CertPEM)
+	c.Assert(err, check.IsNil)
+	_, err = cert.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	c.Assert(err, check.IsNil)
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"sort"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/rpc"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/utils"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/trace"
+)
+
+// GetCertificateExpiry returns the expiration of the certificates managed
+// by Gravity: the cluster certificate authority, the runtime certificates
+// of every node, the RPC agent credentials, the web UI certificate and
+// the Teleport certificate authorities
+func (o *Operator) GetCertificateExpiry(key ops.SiteKey) (*storage.CertificateExpiryStatus, error) {
+	cluster, err := o.GetSite(key)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	config, err := o.GetClusterConfiguration(key)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	thresholds := config.GetCertificateExpiry()
+	status := storage.CertificateExpiryStatus{
+		Updated:  o.cfg.Clock.UtcNow(),
+		Warning:  thresholds.WarningPeriod(),
+		Critical: thresholds.CriticalPeriod(),
+	}
+	collect := func(what string, certs []storage.CertificateExpiry, err error) {
+		if err != nil {
+			o.WithError(err).Warnf("Failed to inspect %v certificates.", what)
+			status.Errors = append(status.Errors, trace.UserMessage(err))
+		}
+		status.Certificates = append(status.Certificates, certs...)
+	}
+	certs, err := o.caCertificates(key.SiteDomain)
+	collect(storage.CertificateSourceCA, certs, err)
+	for _, server := range cluster.ClusterState.Servers {
+		certs, err := o.planetCertificates(key.SiteDomain, server)
+		collect(storage.CertificateSourcePlanet, certs, err)
+	}
+	certs, err = o.rpcCertificates()
+	collect(storage.CertificateSourceRPC, certs, err)
+	certs, err = o.webCertificates()
+	collect(storage.CertificateSourceWeb, certs, err)
+	certs, err = o.teleportCertificates()
+	collect(storage.CertificateSourceTeleport, certs, err)
+	sort.Slice(status.Certificates, func(i, j int) bool {
+		certI, certJ := status.Certificates[i], status.Certificates[j]
+		if !certI.NotAfter.Equal(certJ.NotAfter) {
+			return certI.NotAfter.Before(certJ.NotAfter)
+		}
+		return certI.String() < certJ.String()
+	})
+	return &status, nil
+}
+
+// caCertificates returns the certificates of the cluster certificate authority.
+// The certificate authorities kept while the certificate authority is being
+// rotated are skipped as they are not used to issue certificates
+func (o *Operator) caCertificates(clusterName string) ([]storage.CertificateExpiry, error) {
+	archive, err := ReadCertAuthorityPackage(o.packages(), clusterName)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	delete(archive, constants.PendingRootKeyPair)
+	delete(archive, constants.PreviousRootKeyPair)
+	return archiveCertificates(archive, storage.CertificateSourceCA, "")
+}
+
+// planetCertificates returns the runtime certificates of the specified node.
+// The cluster certificate authority found in the runtime secrets is skipped
+// as it is the same for all nodes
+func (o *Operator) planetCertificates(clusterName string, server storage.Server) ([]storage.CertificateExpiry, error) {
+	secretsPackage, err := pack.FindLatestPackageWithLabels(o.packages(), clusterName, map[string]string{
+		pack.PurposeLabel:     pack.PurposePlanetSecrets,
+		pack.AdvertiseIPLabel: server.AdvertiseIP,
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	archive, err := rpc.CredentialsFromPackage(o.packages(), *secretsPackage)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	delete(archive, constants.RootKeyPair)
+	return archiveCertificates(archive, storage.CertificateSourcePlanet, server.Hostname)
+}
+
+func (o *Operator) rpcCertificates() ([]storage.CertificateExpiry, error) {
+	archive, err := rpc.CredentialsFromPackage(o.packages(), loc.RPCSecrets)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return archiveCertificates(archive, storage.CertificateSourceRPC, "")
+}
+
+func (o *Operator) webCertificates() ([]storage.CertificateExpiry, error) {
+	client, err := o.GetKubeClient()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	certPEM, _, err := GetClusterCertificate(client)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	cert, err := certificateExpiry(certPEM, storage.CertificateSourceWeb, constants.ClusterCertificateMap, "")
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return []storage.CertificateExpiry{*cert}, nil
+}
+
+func (o *Operator) teleportCertificates() (certs []storage.CertificateExpiry, err error) {
+	for _, caType := range []teleservices.CertAuthType{teleservices.HostCA, teleservices.UserCA} {
+		authorities, err := o.cfg.TeleportProxy.GetCertAuthorities(caType)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		for _, authority := range authorities {
+			for _, keyPair := range authority.GetTLSKeyPairs() {
+				cert, err := certificateExpiry(keyPair.Cert, storage.CertificateSourceTeleport,
+					string(caType)+"-ca", "")
+				if err != nil {
+					return nil, trace.Wrap(err)
+				}
+				certs = append(certs, *cert)
+			}
+		}
+	}
+	return certs, nil
+}
+
+func archiveCertificates(archive utils.TLSArchive, source, node string) (certs []storage.CertificateExpiry, err error) {
+	for name, keyPair := range archive {
+		if len(keyPair.CertPEM) == 0 {
+			continue
+		}
+		cert, err := certificateExpiry(keyPair.CertPEM, source, name, node)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		certs = append(certs, *cert)
+	}
+	return certs, nil
+}
+
+func certificateExpiry(certPEM []byte, source, name, node string) (*storage.CertificateExpiry, error) {
+	cert, err := utils.ParseCertificate(certPEM)
+	if err != nil {
+		return nil, trace.Wrap(err, "invalid %v %v certificate", source, name)
+	}
+	return &storage.CertificateExpiry{
+		Source:   source,
+		Name:     name,
+		Node:     node,
+		Subject:  cert.IssuedTo.CommonName,
+		NotAfter: cert.Validity.NotAfter,
+	}, nil
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/clusterconfig"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+	"github.com/pborman/uuid"
+)
+
+// CreateChangeCIDROperation creates a new operation to change
+// the pod and service subnets of the cluster
+func (o *Operator) CreateChangeCIDROperation(ctx context.Context, req ops.CreateChangeCIDROperationRequest) (*ops.SiteOperationKey, error) {
+	if err := req.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	cluster, err := o.openSite(req.ClusterKey)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	installOperation, err := ops.GetCompletedInstallOperation(req.ClusterKey, o)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	prevSubnets, err := cluster.getClusterSubnets(*installOperation)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	subnets := *prevSubnets
+	if req.PodCIDR != "" {
+		subnets.Overlay = req.PodCIDR
+	}
+	if req.ServiceCIDR != "" {
+		subnets.Service = req.ServiceCIDR
+	}
+	if subnets == *prevSubnets {
+		return nil, trace.BadParameter("cluster already uses pod subnet %v and service subnet %v",
+			subnets.Overlay, subnets.Service)
+	}
+	if err := utils.ValidateKubernetesSubnets(subnets.Overlay, subnets.Service); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	manifest := cluster.app.Manifest
+	if subnets.Overlay != prevSubnets.Overlay && manifest.Hooks != nil && manifest.Hooks.NetworkInstall != nil {
+		return nil, trace.BadParameter("pod subnet can only be changed for clusters " +
+			"with the built-in overlay network")
+	}
+	prevConfig, err := o.getClusterConfiguration()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	config, err := o.GetClusterConfiguration(req.ClusterKey)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	config.SetSubnets(subnets.Overlay, subnets.Service)
+	configBytes, err := clusterconfig.Marshal(config)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	op := ops.SiteOperation{
+		ID:         uuid.New(),
+		AccountID:  cluster.key.AccountID,
+		SiteDomain: cluster.key.SiteDomain,
+		Type:       ops.OperationChangeCIDR,
+		Created:    cluster.clock().UtcNow(),
+		CreatedBy:  storage.UserFromContext(ctx),
+		Updated:    cluster.clock().UtcNow(),
+		State:      ops.OperationChangeCIDRInProgress,
+		UpdateConfig: &storage.UpdateConfigOperationState{
+			PrevConfig: []byte(prevConfig),
+			Config:     configBytes,
+		},
+		ChangeCIDR: &storage.ChangeCIDROperationState{
+			PrevSubnets: *prevSubnets,
+			Subnets:     subnets,
+		},
+	}
+	key, err := cluster.getOperationGroup().createSiteOperation(op)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return key, nil
+}
+
+// getClusterSubnets returns the pod and service subnets of the cluster
+// installed with the specified operation
+func (s *site) getClusterSubnets(installOperation ops.SiteOperation) (*storage.Subnets, error) {
+	subnets := installOperation.InstallExpand.Subnets
+	if subnets.IsEmpty() {
+		// Subnets are empty when updating an older installation
+		subnets = storage.DefaultSubnets
+	}
+	config, err := s.service.GetClusterConfiguration(s.key)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	subnets = clusterSubnets(subnets, config)
+	return &subnets, nil
+}
+
+// clusterSubnets returns the specified subnets the cluster has been installed
+// with overridden by the subnets from the cluster configuration once they
+// have been changed
+func clusterSubnets(subnets storage.Subnets, config clusterconfig.Interface) storage.Subnets {
+	global := config.GetGlobalConfig()
+	if global == nil {
+		return subnets
+	}
+	if global.PodCIDR != "" {
+		subnets.Overlay = global.PodCIDR
+	}
+	if global.ServiceCIDR != "" {
+		subnets.Service = global.ServiceCIDR
+	}
+	return subnets
+}
@@ -19,6 +19,7 @@ package opsservice
 import (
 	"context"
 
+	"github.com/gravitational/gravity/lib/checks"
 	"github.com/gravitational/gravity/lib/ops"
 
 	"github.com/gravitational/trace"
@@ -44,8 +45,24 @@ func (o *Operator) ValidateServers(ctx context.Context, req ops.ValidateServersR
 		return trace.Wrap(err)
 	}
 
+	manifest := cluster.app.Manifest
+	features := checks.Features{
+		TestBandwidth:    true,
+		TestPorts:        true,
+		TestDockerDevice: true,
+		TestEtcdDisk:     true,
+		TestDisks:        true,
+	}
+	if cluster.backendSite.Demo {
+		log.Info("Relaxing requirements for demo installation.")
+		manifest = checks.DemoManifest(manifest)
+		features.TestBandwidth = false
+		features.TestEtcdDisk = false
+		features.TestDisks = false
+	}
+
 	err = ops.CheckServers(ctx, op.Key(), infos, req.Servers,
-		cluster.agentService(), cluster.app.Manifest)
+		cluster.agentService(), manifest, features)
 	if err != nil {
 		return trace.Wrap(ops.FormatValidationError(err))
 	}
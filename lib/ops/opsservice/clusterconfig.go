@@ -45,10 +45,20 @@ func (o *Operator) CreateUpdateConfigOperation(ctx context.Context, req ops.Crea
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+	existing, err := o.getIdempotentOperation(ctx, req.ClusterKey, ops.OperationUpdateConfig)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if existing != nil {
+		return existing, nil
+	}
 	config, err := o.getClusterConfiguration()
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+	if err := checkEncryptionUpdate([]byte(config), req.Config); err != nil {
+		return nil, trace.Wrap(err)
+	}
 	key, err := cluster.createUpdateConfigOperation(ctx, req, []byte(config))
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -139,23 +149,70 @@ func NewConfigurationConfigMap(config []byte) *v1.ConfigMap {
 	}
 }
 
+// checkEncryptionUpdate validates the update of the encryption of secrets
+// from the specified previous to the new cluster configuration.
+//
+// The encryption can not be disabled once enabled as the apiservers would
+// not be able to read the encrypted secrets
+func checkEncryptionUpdate(prevConfig, config []byte) error {
+	if len(config) == 0 {
+		return nil
+	}
+	next, err := clusterconfig.Unmarshal(config)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if next.GetEncryption() != nil {
+		return nil
+	}
+	if len(prevConfig) == 0 {
+		return nil
+	}
+	prev, err := clusterconfig.Unmarshal(prevConfig)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if prev.GetEncryption() != nil {
+		return trace.BadParameter("encryption of secrets at rest can not be disabled")
+	}
+	return nil
+}
+
 // createUpdateConfigOperation creates a new operation to update cluster configuration
 func (s *site) createUpdateConfigOperation(ctx context.Context, req ops.CreateUpdateConfigOperationRequest, prevConfig []byte) (*ops.SiteOperationKey, error) {
+	operationID := uuid.New()
+	if len(req.Config) != 0 {
+		config, err := clusterconfig.Unmarshal(req.Config)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if config.GetEncryption() != nil {
+			// The encryption configuration needs to exist before the runtime
+			// configuration packages are generated for the masters
+			if err := s.configureEncryptionConfigPackage(operationID); err != nil {
+				return nil, trace.Wrap(err)
+			}
+		}
+	}
 	op := ops.SiteOperation{
-		ID:         uuid.New(),
-		AccountID:  s.key.AccountID,
-		SiteDomain: s.key.SiteDomain,
-		Type:       ops.OperationUpdateConfig,
-		Created:    s.clock().UtcNow(),
-		CreatedBy:  storage.UserFromContext(ctx),
-		Updated:    s.clock().UtcNow(),
-		State:      ops.OperationUpdateConfigInProgress,
+		ID:             operationID,
+		AccountID:      s.key.AccountID,
+		SiteDomain:     s.key.SiteDomain,
+		Type:           ops.OperationUpdateConfig,
+		Created:        s.clock().UtcNow(),
+		CreatedBy:      storage.UserFromContext(ctx),
+		IdempotencyKey: ops.IdempotencyKeyFromContext(ctx),
+		Updated:        s.clock().UtcNow(),
+		State:          ops.OperationUpdateConfigInProgress,
 		UpdateConfig: &storage.UpdateConfigOperationState{
 			PrevConfig: prevConfig,
 			Config:     req.Config,
 		},
 	}
 	key, err := s.getOperationGroup().createSiteOperation(op)
+	if existing, ok := existingOperation(err); ok {
+		return existing, nil
+	}
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
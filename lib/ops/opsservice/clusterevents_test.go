@@ -0,0 +1,132 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"context"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/events"
+	"github.com/gravitational/gravity/lib/status"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+type ClusterEventsSuite struct {
+	operator *Operator
+	cluster  *storage.Site
+}
+
+var _ = Suite(&ClusterEventsSuite{})
+
+func (s *ClusterEventsSuite) SetUpTest(c *C) {
+	services := SetupTestServices(c)
+	s.operator = services.Operator
+	var err error
+	s.cluster, err = services.Backend.CreateSite(storage.Site{
+		AccountID: defaults.SystemAccountID,
+		Domain:    "example.com",
+		Local:     true,
+		App: storage.Package{
+			Repository: defaults.SystemAccountOrg,
+			Name:       "example",
+			Version:    "0.0.1",
+		},
+		Created: time.Now(),
+	})
+	c.Assert(err, IsNil)
+}
+
+func (s *ClusterEventsSuite) TestRecordsAuditEvents(c *C) {
+	ctx := context.TODO()
+	key := ops.SiteKey{AccountID: s.cluster.AccountID, SiteDomain: s.cluster.Domain}
+	events.Emit(ctx, s.operator, events.NodePoolCreated, events.Fields{events.FieldName: "gpu"})
+	events.Emit(ctx, s.operator, events.NodePoolDeleted, events.Fields{events.FieldName: "gpu"})
+
+	recorded, err := s.operator.GetClusterEvents(ctx, ops.GetClusterEventsRequest{SiteKey: key})
+	c.Assert(err, IsNil)
+	c.Assert(recorded, HasLen, 2)
+	c.Assert(recorded[0].Name, Equals, events.NodePoolCreatedEvent)
+	c.Assert(recorded[0].Code, Equals, events.NodePoolCreatedCode)
+	c.Assert(recorded[0].Fields[events.FieldName], Equals, "gpu")
+	c.Assert(recorded[1].Name, Equals, events.NodePoolDeletedEvent)
+
+	// Resume from the first event
+	resumed, err := s.operator.GetClusterEvents(ctx, ops.GetClusterEventsRequest{
+		SiteKey: key,
+		After:   recorded[0].ID,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(resumed, DeepEquals, recorded[1:])
+}
+
+func (s *ClusterEventsSuite) TestStreamsEvents(c *C) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	key := ops.SiteKey{AccountID: s.cluster.AccountID, SiteDomain: s.cluster.Domain}
+	events.Emit(ctx, s.operator, events.NodePoolCreated, events.Fields{events.FieldName: "gpu"})
+
+	var streamed []storage.ClusterEvent
+	err := ops.StreamClusterEvents(ctx, s.operator, ops.GetClusterEventsRequest{SiteKey: key},
+		10*time.Millisecond, func(event storage.ClusterEvent) error {
+			streamed = append(streamed, event)
+			if len(streamed) == 1 {
+				// Emit an event while streaming
+				events.Emit(ctx, s.operator, events.NodePoolDeleted, events.Fields{events.FieldName: "gpu"})
+				return nil
+			}
+			return trace.LimitExceeded("done")
+		})
+	c.Assert(trace.IsLimitExceeded(err), Equals, true, Commentf("%v", err))
+	c.Assert(streamed, HasLen, 2)
+	c.Assert(streamed[0].Name, Equals, events.NodePoolCreatedEvent)
+	c.Assert(streamed[1].Name, Equals, events.NodePoolDeletedEvent)
+}
+
+func (s *ClusterEventsSuite) TestEmitsNodeHealthEvents(c *C) {
+	ctx := context.TODO()
+	key := ops.SiteKey{AccountID: s.cluster.AccountID, SiteDomain: s.cluster.Domain}
+	check := func(statuses ...string) {
+		var nodes []status.ClusterServer
+		for i, nodeStatus := range statuses {
+			nodes = append(nodes, status.ClusterServer{
+				Hostname:     []string{"node-1", "node-2"}[i],
+				AdvertiseIP:  []string{"10.0.0.1", "10.0.0.2"}[i],
+				Status:       nodeStatus,
+				FailedProbes: []string{"docker is not running"},
+			})
+		}
+		s.operator.emitNodeHealthEvents(ctx, nodes)
+	}
+	check(status.NodeHealthy, status.NodeHealthy)
+	check(status.NodeHealthy, status.NodeDegraded)
+	check(status.NodeHealthy, status.NodeOffline)
+	check(status.NodeHealthy, status.NodeHealthy)
+
+	recorded, err := s.operator.GetClusterEvents(ctx, ops.GetClusterEventsRequest{SiteKey: key})
+	c.Assert(err, IsNil)
+	c.Assert(recorded, HasLen, 2)
+	c.Assert(recorded[0].Name, Equals, events.NodeDegradedEvent)
+	c.Assert(recorded[0].Fields[events.FieldNodeIP], Equals, "10.0.0.2")
+	c.Assert(recorded[0].Fields[events.FieldReason], Equals, "docker is not running")
+	c.Assert(recorded[1].Name, Equals, events.NodeHealthyEvent)
+	c.Assert(recorded[1].Fields[events.FieldNodeHostname], Equals, "node-2")
+}
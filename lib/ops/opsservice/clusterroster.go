@@ -0,0 +1,137 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/events"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// GetClusterRoster returns the cluster roster
+func (o *Operator) GetClusterRoster(key ops.SiteKey) (storage.ClusterRoster, error) {
+	roster, err := o.backend().GetClusterRoster(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return roster, nil
+}
+
+// UpsertClusterRoster creates or updates the cluster roster.
+// The roster node roles must be the node profiles of the cluster application
+// and the roster must retain at least one of the current master nodes
+func (o *Operator) UpsertClusterRoster(ctx context.Context, key ops.SiteKey, roster storage.ClusterRoster) error {
+	if err := roster.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := o.openSite(key)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := cluster.validateClusterRoster(roster); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := o.backend().UpsertClusterRoster(key.SiteDomain, roster); err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.ClusterRosterUpdated, events.Fields{
+		events.FieldCount: len(roster.GetNodes()),
+	})
+	return nil
+}
+
+// DeleteClusterRoster deletes the cluster roster which stops
+// the membership reconciliation
+func (o *Operator) DeleteClusterRoster(ctx context.Context, key ops.SiteKey) error {
+	if err := o.backend().DeleteClusterRoster(key.SiteDomain); err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.ClusterRosterDeleted)
+	return nil
+}
+
+// GetClusterRosterStatus returns the status of the roster reconciliation
+func (o *Operator) GetClusterRosterStatus(key ops.SiteKey) (*storage.ClusterRosterStatus, error) {
+	status, err := o.backend().GetClusterRosterStatus(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return status, nil
+}
+
+// ApproveClusterRosterChanges approves the pending membership changes with the specified ID.
+// If the ID is empty, the currently pending changes are approved
+func (o *Operator) ApproveClusterRosterChanges(ctx context.Context, key ops.SiteKey, changesID string) error {
+	status, err := o.backend().GetClusterRosterStatus(key.SiteDomain)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return trace.NotFound("there are no pending cluster roster changes")
+		}
+		return trace.Wrap(err)
+	}
+	if !status.IsPendingApproval() {
+		return trace.NotFound("there are no pending cluster roster changes")
+	}
+	if changesID != "" && changesID != status.ChangesID {
+		return trace.CompareFailed("changes %v are no longer pending, the pending changes are %v",
+			changesID, status.ChangesID)
+	}
+	status.ApprovedID = status.ChangesID
+	if err := o.backend().UpsertClusterRosterStatus(key.SiteDomain, *status); err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.ClusterRosterChangesApproved, events.Fields{
+		events.FieldName:  status.ChangesID,
+		events.FieldCount: len(status.Changes),
+	})
+	return nil
+}
+
+// validateClusterRoster makes sure the roster node roles are valid node profiles
+// and that the roster retains at least one of the current master nodes
+func (s *site) validateClusterRoster(roster storage.ClusterRoster) error {
+	nodes := make(map[string]storage.RosterNode, len(roster.GetNodes()))
+	for _, node := range roster.GetNodes() {
+		if _, err := s.app.Manifest.NodeProfiles.ByName(node.Role); err != nil {
+			return trace.Wrap(err)
+		}
+		nodes[node.Addr] = node
+	}
+	var hasMaster bool
+	for _, server := range s.servers() {
+		node, ok := nodes[server.AdvertiseIP]
+		if !ok {
+			continue
+		}
+		if node.Role != server.Role {
+			return trace.BadParameter("node %v has role %q in the cluster but %q in the roster, "+
+				"changing the role of an existing node is not supported", server.AdvertiseIP,
+				server.Role, node.Role)
+		}
+		if server.IsMaster() {
+			hasMaster = true
+		}
+	}
+	if !hasMaster {
+		return trace.BadParameter("cluster roster should retain at least one of the current master nodes")
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+func (s *NodeRoleSuite) TestValidatesClusterRoster(c *C) {
+	cluster := s.withServers(c,
+		storage.Server{Hostname: "master-1", AdvertiseIP: "10.0.0.1", Role: "node", ClusterRole: "master"},
+		storage.Server{Hostname: "node-1", AdvertiseIP: "10.0.0.2", Role: "node", ClusterRole: "node"},
+	)
+
+	err := cluster.validateClusterRoster(roster(
+		storage.RosterNode{Addr: "10.0.0.1", Role: "node"},
+		storage.RosterNode{Addr: "10.0.0.3", Role: "node"}))
+	c.Assert(err, IsNil)
+
+	err = cluster.validateClusterRoster(roster(
+		storage.RosterNode{Addr: "10.0.0.1", Role: "node"},
+		storage.RosterNode{Addr: "10.0.0.3", Role: "unknown"}))
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%v", err))
+
+	err = cluster.validateClusterRoster(roster(
+		storage.RosterNode{Addr: "10.0.0.2", Role: "node"},
+		storage.RosterNode{Addr: "10.0.0.3", Role: "node"}))
+	c.Assert(trace.IsBadParameter(err), Equals, true, Commentf("%v", err))
+
+	err = cluster.validateClusterRoster(roster(
+		storage.RosterNode{Addr: "10.0.0.1", Role: "knode"}))
+	c.Assert(trace.IsBadParameter(err), Equals, true, Commentf("%v", err))
+}
+
+func (s *NodeRoleSuite) TestApprovesClusterRosterChanges(c *C) {
+	ctx := context.TODO()
+	key := s.cluster.Key()
+	err := s.operator.ApproveClusterRosterChanges(ctx, key, "")
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%v", err))
+
+	err = s.operator.backend().UpsertClusterRosterStatus(key.SiteDomain, storage.ClusterRosterStatus{
+		Changes: []storage.RosterChange{
+			{Action: storage.RosterActionAdd, Addr: "10.0.0.3", Role: "node"},
+		},
+		ChangesID: "changes-1",
+	})
+	c.Assert(err, IsNil)
+
+	err = s.operator.ApproveClusterRosterChanges(ctx, key, "changes-0")
+	c.Assert(trace.IsCompareFailed(err), Equals, true, Commentf("%v", err))
+
+	err = s.operator.ApproveClusterRosterChanges(ctx, key, "changes-1")
+	c.Assert(err, IsNil)
+	status, err := s.operator.GetClusterRosterStatus(key)
+	c.Assert(err, IsNil)
+	c.Assert(status.ApprovedID, Equals, "changes-1")
+	c.Assert(status.IsPendingApproval(), Equals, false)
+}
+
+func roster(nodes ...storage.RosterNode) storage.ClusterRoster {
+	return storage.NewClusterRoster(storage.ClusterRosterSpecV2{
+		Nodes: nodes,
+		SSH:   storage.RosterSSH{User: "root", Secret: "ssh-key"},
+	})
+}
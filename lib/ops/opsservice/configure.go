@@ -32,6 +32,8 @@ import (
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/devicemapper"
+	"github.com/gravitational/gravity/lib/fips"
+	"github.com/gravitational/gravity/lib/hardening"
 	"github.com/gravitational/gravity/lib/loc"
 	"github.com/gravitational/gravity/lib/ops"
 	"github.com/gravitational/gravity/lib/pack"
@@ -198,7 +200,7 @@ func (s *site) configureExpandPackages(ctx context.Context, opCtx *operationCont
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	planetPackage, err := s.app.Manifest.RuntimePackage(provisionedServer.Profile)
+	planetPackage, err := s.app.Manifest.RuntimePackageForServer(provisionedServer.Profile.Name, provisionedServer.GetArch())
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -231,7 +233,7 @@ func (s *site) configureExpandPackages(ctx context.Context, opCtx *operationCont
 		masterParams := planetMasterParams{
 			master:            provisionedServer,
 			secretsPackage:    &secretsPackage,
-			serviceSubnetCIDR: opCtx.operation.InstallExpand.Subnets.Service,
+			serviceSubnetCIDR: clusterSubnets(opCtx.operation.InstallExpand.Subnets, config).Service,
 		}
 		// if we have connection to an Ops Center set up, configure
 		// SNI host so it can dial in
@@ -284,7 +286,7 @@ func (s *site) configurePackages(ctx *operationContext, req ops.ConfigurePackage
 
 	p := ctx.provisionedServers
 
-	if err := s.configurePlanetCertAuthority(ctx); err != nil {
+	if err := s.configurePlanetCertAuthority(ctx, req.CertAuthority); err != nil {
 		return trace.Wrap(err)
 	}
 
@@ -324,10 +326,15 @@ func (s *site) configurePackages(ctx *operationContext, req ops.ConfigurePackage
 		if s.cloudProviderName() != "" {
 			clusterConfig.SetCloudProvider(s.cloudProviderName())
 		}
+		if clusterConfig.GetEncryption() != nil {
+			if err := s.configureEncryptionConfigPackage(ctx.operation.ID); err != nil {
+				return trace.Wrap(err)
+			}
+		}
 	}
 
 	for i, master := range masters {
-		planetPackage, err := s.app.Manifest.RuntimePackage(master.Profile)
+		planetPackage, err := s.app.Manifest.RuntimePackageForServer(master.Profile.Name, master.GetArch())
 		if err != nil {
 			return trace.Wrap(err)
 		}
@@ -395,7 +402,7 @@ func (s *site) configurePackages(ctx *operationContext, req ops.ConfigurePackage
 			return trace.Wrap(err)
 		}
 
-		planetPackage, err := s.app.Manifest.RuntimePackage(node.Profile)
+		planetPackage, err := s.app.Manifest.RuntimePackageForServer(node.Profile.Name, node.GetArch())
 		if err != nil {
 			return trace.Wrap(err)
 		}
@@ -487,7 +494,10 @@ func (s *site) prepareEtcdConfig(ctx *operationContext) clusterEtcdConfig {
 	return config
 }
 
-func (s *site) configurePlanetCertAuthority(ctx *operationContext) error {
+// configurePlanetCertAuthority creates the certificate authority package
+// for the cluster. If certAuthority is specified, it issues the cluster
+// certificates, otherwise a new self-signed certificate authority is generated
+func (s *site) configurePlanetCertAuthority(ctx *operationContext, certAuthority *authority.TLSKeyPair) error {
 	caPackage, err := s.planetCertAuthorityPackage()
 	if err != nil {
 		return trace.Wrap(err)
@@ -498,30 +508,25 @@ func (s *site) configurePlanetCertAuthority(ctx *operationContext) error {
 		return nil
 	}
 
-	s.Debugf("generating certificate authority package")
-	planetCertAuthority, err := authority.GenerateSelfSignedCA(csr.CertificateRequest{
-		CN: s.siteRepoName(),
-		CA: &csr.CAConfig{
-			Expiry: defaults.CACertificateExpiry.String(),
-		},
-	})
-	if err != nil {
-		return trace.Wrap(err)
+	planetCertAuthority := certAuthority
+	if planetCertAuthority != nil {
+		s.Info("Using provided certificate authority.")
+		if err := utils.CheckCertAuthority(*planetCertAuthority, s.clock().UtcNow()); err != nil {
+			return trace.Wrap(err)
+		}
+	} else {
+		s.Debugf("generating certificate authority package")
+		planetCertAuthority, err = newCertAuthority(s.siteRepoName())
+		if err != nil {
+			return trace.Wrap(err)
+		}
 	}
 
 	s.Debugf("generating apiserver keypair key")
 	// we have to share the same private key for various apiservers
 	// due to this issue:
 	// https://github.com/kubernetes/kubernetes/issues/11000#issuecomment-232469678
-	apiServer, err := authority.GenerateCertificate(csr.CertificateRequest{
-		CN:    constants.APIServerKeyPair,
-		Hosts: []string{"127.0.0.1"},
-		Names: []csr.Name{
-			{
-				O: defaults.SystemAccountOrg,
-			},
-		},
-	}, planetCertAuthority, nil, defaults.CertificateExpiry)
+	apiServer, err := newAPIServerKeyPair(planetCertAuthority, nil)
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -599,9 +604,14 @@ func (s *site) getPlanetMasterSecretsPackage(ctx *operationContext, p planetMast
 	}
 	apiServerIP := serviceSubnet.FirstIP().String()
 
+	trustedCA, err := trustedCertAuthority(archive)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
 	newArchive := make(utils.TLSArchive)
 
-	if err := newArchive.AddKeyPair(constants.RootKeyPair, *caKeyPair); err != nil {
+	if err := newArchive.AddKeyPair(constants.RootKeyPair, *trustedCA); err != nil {
 		return nil, trace.Wrap(err)
 	}
 
@@ -629,6 +639,9 @@ func (s *site) getPlanetMasterSecretsPackage(ctx *operationContext, p planetMast
 		req := csr.CertificateRequest{
 			Hosts: []string{constants.LoopbackIP, constants.AlternativeLoopbackIP, p.master.AdvertiseIP, p.master.Hostname},
 		}
+		if p.master.ClusterAddr != "" {
+			req.Hosts = append(req.Hosts, p.master.ClusterAddr)
+		}
 		commonName := config.userName
 		if commonName == "" {
 			commonName = name
@@ -718,9 +731,14 @@ func (s *site) getPlanetNodeSecretsPackage(ctx *operationContext, node *Provisio
 		return nil, trace.Wrap(err)
 	}
 
+	trustedCA, err := trustedCertAuthority(archive)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
 	newArchive := make(utils.TLSArchive)
 
-	caCertKeyPair := *caKeyPair
+	caCertKeyPair := *trustedCA
 	caCertKeyPair.KeyPEM = nil
 
 	if err := newArchive.AddKeyPair(constants.RootKeyPair, caCertKeyPair); err != nil {
@@ -743,6 +761,9 @@ func (s *site) getPlanetNodeSecretsPackage(ctx *operationContext, node *Provisio
 		req := csr.CertificateRequest{
 			Hosts: []string{constants.LoopbackIP, node.AdvertiseIP, node.Hostname},
 		}
+		if node.ClusterAddr != "" {
+			req.Hosts = append(req.Hosts, node.ClusterAddr)
+		}
 		if keyName == constants.ProxyKeyPair {
 			req.Hosts = append(req.Hosts,
 				constants.APIServerDomainNameGravity,
@@ -915,13 +936,22 @@ func (s *site) getPlanetConfig(config planetConfig) (args []string, err error) {
 		"pod-subnet":     config.installExpand.InstallExpand.Subnets.Overlay,
 	}
 
-	for k, v := range config.env {
+	for k, v := range runtimeEnv(config, s.servers()) {
 		args = append(args, fmt.Sprintf("--env=%v=%v", k, strconv.Quote(v)))
 	}
 
 	args = append(args, s.addCloudConfig(config.config)...)
 	args = append(args, s.addClusterConfig(config.config, overrideArgs)...)
 
+	hardened := hardeningProfile(config.config)
+	if node.IsMaster() {
+		apiserverArgs, err := s.addAPIServerConfig(config.config)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		args = append(args, apiserverArgs...)
+	}
+
 	if node.IsMaster() {
 		args = append(args, "--role=master")
 	} else {
@@ -944,6 +974,14 @@ func (s *site) getPlanetConfig(config planetConfig) (args []string, err error) {
 		args = append(args, fmt.Sprintf("--vxlan-port=%v", vxlanPort))
 	}
 
+	onPrem := config.installExpand.InstallExpand.Vars.OnPrem
+	if wireguardPort := onPrem.WireguardPort(); wireguardPort != 0 {
+		args = append(args,
+			fmt.Sprintf("--network-encryption=%v", onPrem.NetworkEncryption),
+			fmt.Sprintf("--wireguard-port=%v", wireguardPort),
+			fmt.Sprintf("--wireguard-secret=%v", defaults.WireguardSecret))
+	}
+
 	dnsConfig := s.dnsConfig()
 	for _, addr := range dnsConfig.Addrs {
 		args = append(args, fmt.Sprintf("--dns-listen-addr=%v", addr))
@@ -957,15 +995,30 @@ func (s *site) getPlanetConfig(config planetConfig) (args []string, err error) {
 	}
 	args = append(args, dockerArgs...)
 
-	etcdArgs := manifest.EtcdArgs(*profile)
+	if node.ClusterAddr != "" {
+		args = append(args, fmt.Sprintf("--cluster-addr=%v", node.ClusterAddr))
+	}
+	if node.ApplicationAddr != "" {
+		args = append(args, fmt.Sprintf("--application-addr=%v", node.ApplicationAddr))
+	}
+
+	// Peer traffic is bound to the cluster interface, arguments
+	// from the manifest come last to take precedence
+	etcdArgs := clusterTrafficEtcdArgs(node.Server)
+	etcdArgs = append(etcdArgs, manifest.EtcdArgs(*profile)...)
 	if len(etcdArgs) != 0 {
 		args = append(args, fmt.Sprintf("--etcd-options=%v", strings.Join(etcdArgs, " ")))
 	}
 
 	var kubeletArgs []string
+	if node.ClusterAddr != "" {
+		kubeletArgs = append(kubeletArgs, fmt.Sprintf("--node-ip=%v", node.ClusterAddr))
+	}
 	if len(manifest.KubeletArgs(*profile)) != 0 {
 		kubeletArgs = append(kubeletArgs, manifest.KubeletArgs(*profile)...)
 	}
+	// Hardening flags come last to take precedence over the manifest
+	kubeletArgs = append(kubeletArgs, hardened.KubeletArgs()...)
 
 	if len(kubeletArgs) > 0 {
 		args = append(args, fmt.Sprintf("--kubelet-options=%v", strings.Join(kubeletArgs, " ")))
@@ -1037,6 +1090,19 @@ func getNodeLabels(node ProvisionedServer, profile *schema.NodeProfile) map[stri
 	return labels
 }
 
+// clusterTrafficEtcdArgs returns the etcd arguments to bind the peer
+// traffic to the interface dedicated to the cluster traffic on the specified server
+func clusterTrafficEtcdArgs(server storage.Server) []string {
+	if server.ClusterAddr == "" {
+		return nil
+	}
+	peerURL := fmt.Sprintf("https://%v:%v", server.ClusterAddr, etcdPeerPort)
+	return []string{
+		fmt.Sprintf("--listen-peer-urls=%v", peerURL),
+		fmt.Sprintf("--initial-advertise-peer-urls=%v", peerURL),
+	}
+}
+
 func (s *site) configurePlanetServer(config planetConfig) error {
 	resp, err := s.getPlanetConfigPackage(config)
 	if err != nil {
@@ -1075,14 +1141,31 @@ func (s *site) getPrincipals(node *ProvisionedServer) []string {
 	return principals
 }
 
+// getTeleportGlobalConfig returns the cryptographic settings for the teleport
+// services. In FIPS mode, the settings are restricted to FIPS-approved algorithms
+func (s *site) getTeleportGlobalConfig() (*telecfg.Global, error) {
+	config, err := s.service.GetClusterConfiguration(s.key)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	global := telecfg.Global{
+		Ciphers:       defaults.TeleportCiphers,
+		KEXAlgorithms: defaults.TeleportKEXAlgorithms,
+		MACAlgorithms: defaults.TeleportMACAlgorithms,
+	}
+	if config.IsFIPS() {
+		global.KEXAlgorithms = fips.TeleportKEXAlgorithms
+		global.CipherSuites = fips.TeleportCipherSuites
+	}
+	return &global, nil
+}
+
 func (s *site) getTeleportMasterConfig(ctx *operationContext, configPackage loc.Locator, master *ProvisionedServer) (*ops.RotatePackageResponse, error) {
-	fileConf := &telecfg.FileConfig{
-		Global: telecfg.Global{
-			Ciphers:       defaults.TeleportCiphers,
-			KEXAlgorithms: defaults.TeleportKEXAlgorithms,
-			MACAlgorithms: defaults.TeleportMACAlgorithms,
-		},
+	global, err := s.getTeleportGlobalConfig()
+	if err != nil {
+		return nil, trace.Wrap(err)
 	}
+	fileConf := &telecfg.FileConfig{Global: *global}
 
 	fileConf.DataDir = defaults.InGravity("site/teleport")
 	fileConf.Storage.Type = teleetcd.GetName()
@@ -1185,13 +1268,11 @@ func (s *site) getTeleportNodeConfig(ctx *operationContext, masterIPs []string,
 		return nil, trace.Wrap(err)
 	}
 
-	fileConf := &telecfg.FileConfig{
-		Global: telecfg.Global{
-			Ciphers:       defaults.TeleportCiphers,
-			KEXAlgorithms: defaults.TeleportKEXAlgorithms,
-			MACAlgorithms: defaults.TeleportMACAlgorithms,
-		},
+	global, err := s.getTeleportGlobalConfig()
+	if err != nil {
+		return nil, trace.Wrap(err)
 	}
+	fileConf := &telecfg.FileConfig{Global: *global}
 
 	fileConf.DataDir = node.InGravity("teleport")
 
@@ -1382,6 +1463,73 @@ func (s *site) siteExportPackage() (*loc.Locator, error) {
 		fmt.Sprintf("%v/%v:0.0.1", s.siteRepoName(), constants.SiteExportPackage))
 }
 
+// addAPIServerConfig returns the runtime container arguments that configure
+// the apiserver audit, the encryption of secrets and the hardening profile
+// from the cluster configuration
+func (s *site) addAPIServerConfig(config clusterconfig.Interface) (args []string, err error) {
+	if config == nil {
+		config = clusterconfig.NewEmpty()
+	}
+	profile := hardeningProfile(config)
+	apiserverArgs := profile.APIServerArgs()
+	audit := config.GetAudit()
+	auditPolicy := profile.AuditPolicy()
+	if len(audit.Policy) != 0 {
+		auditPolicy = audit.Policy
+	}
+	if len(auditPolicy) != 0 {
+		args = append(args, fmt.Sprintf("--audit-policy=%v", base64.StdEncoding.EncodeToString(auditPolicy)))
+		apiserverArgs = append(apiserverArgs, auditArgs(audit)...)
+		if audit.Webhook != nil {
+			args = append(args, fmt.Sprintf("--audit-webhook-config=%v",
+				base64.StdEncoding.EncodeToString([]byte(audit.Webhook.Config))))
+		}
+	}
+	if encryption := config.GetEncryption(); encryption != nil {
+		encryptionConfig, err := s.readEncryptionConfig(*encryption)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		args = append(args, fmt.Sprintf("--encryption-provider-config=%v",
+			base64.StdEncoding.EncodeToString(encryptionConfig)))
+	}
+	if len(apiserverArgs) != 0 {
+		args = append(args, fmt.Sprintf("--apiserver-options=%v", strings.Join(apiserverArgs, " ")))
+	}
+	return args, nil
+}
+
+// auditArgs returns the apiserver flags that configure the audit backends
+func auditArgs(audit clusterconfig.Audit) (args []string) {
+	if audit.Log != nil && !audit.Log.Disabled {
+		args = append(args,
+			fmt.Sprintf("--audit-log-path=%v", defaults.AuditLogPath),
+			fmt.Sprintf("--audit-log-maxage=%v", audit.Log.MaxAge),
+			fmt.Sprintf("--audit-log-maxbackup=%v", audit.Log.MaxBackup),
+			fmt.Sprintf("--audit-log-maxsize=%v", audit.Log.MaxSize),
+			fmt.Sprintf("--audit-log-format=%v", audit.Log.Format),
+		)
+	}
+	if audit.Webhook != nil {
+		if audit.Webhook.Mode != "" {
+			args = append(args, fmt.Sprintf("--audit-webhook-mode=%v", audit.Webhook.Mode))
+		}
+		if audit.Webhook.InitialBackoff != nil {
+			args = append(args, fmt.Sprintf("--audit-webhook-initial-backoff=%v",
+				audit.Webhook.InitialBackoff.Duration))
+		}
+	}
+	return args
+}
+
+// hardeningProfile returns the hardening profile from the specified cluster configuration
+func hardeningProfile(config clusterconfig.Interface) hardening.Profile {
+	if config == nil {
+		return hardening.ProfileNone
+	}
+	return hardening.Profile(config.GetHardeningProfile())
+}
+
 func (s *site) licensePackage() (*loc.Locator, error) {
 	return loc.ParseLocator(
 		fmt.Sprintf("%v/%v:0.0.1", s.siteRepoName(), constants.LicensePackage))
@@ -1407,6 +1555,77 @@ func (s *site) addCloudConfig(config clusterconfig.Interface) (args []string) {
 	return args
 }
 
+// runtimeEnv returns the set of environment variables for the runtime container.
+// Proxy settings from the cluster configuration are used as defaults that
+// the explicitly configured runtime environment can override.
+//
+// If a proxy is configured, the cluster-internal destinations are appended
+// to NO_PROXY so the cluster traffic does not go through the proxy
+func runtimeEnv(config planetConfig, servers []storage.Server) map[string]string {
+	env := make(map[string]string)
+	if config.config != nil {
+		if globalConfig := config.config.GetGlobalConfig(); globalConfig != nil {
+			for k, v := range globalConfig.ProxyEnv() {
+				env[k] = v
+			}
+		}
+	}
+	for k, v := range config.env {
+		env[k] = v
+	}
+	if !hasProxy(env) {
+		return env
+	}
+	noProxy := env[constants.NoProxyEnvVar]
+	if noProxy == "" {
+		noProxy = env[strings.ToLower(constants.NoProxyEnvVar)]
+	}
+	var destinations []string
+	for _, destination := range append(strings.Split(noProxy, ","), clusterDestinations(config, servers)...) {
+		destination = strings.TrimSpace(destination)
+		if destination != "" && !utils.StringInSlice(destinations, destination) {
+			destinations = append(destinations, destination)
+		}
+	}
+	noProxy = strings.Join(destinations, ",")
+	env[constants.NoProxyEnvVar] = noProxy
+	env[strings.ToLower(constants.NoProxyEnvVar)] = noProxy
+	return env
+}
+
+// hasProxy returns true if the specified environment configures a proxy
+func hasProxy(env map[string]string) bool {
+	for _, name := range []string{constants.HTTPProxyEnvVar, constants.HTTPSProxyEnvVar} {
+		if env[name] != "" || env[strings.ToLower(name)] != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// clusterDestinations returns the cluster-internal destinations that
+// should not be proxied: the cluster leader and registry, the service
+// and pod subnets, the node advertise addresses and the cluster domain
+func clusterDestinations(config planetConfig, servers []storage.Server) []string {
+	registryHost, _ := utils.SplitHostPort(constants.DockerRegistry, "")
+	destinations := []string{constants.APIServerDomainName, registryHost}
+	if config.installExpand.InstallExpand != nil {
+		subnets := config.installExpand.InstallExpand.Subnets
+		if config.config != nil {
+			subnets = clusterSubnets(subnets, config.config)
+		}
+		destinations = append(destinations, subnets.Service, subnets.Overlay)
+		for _, server := range config.installExpand.InstallExpand.Servers {
+			destinations = append(destinations, server.AdvertiseIP)
+		}
+	}
+	destinations = append(destinations, config.server.AdvertiseIP)
+	for _, server := range servers {
+		destinations = append(destinations, server.AdvertiseIP)
+	}
+	return append(destinations, "."+constants.LocalClusterCommonName)
+}
+
 func (s *site) addClusterConfig(config clusterconfig.Interface, overrideArgs map[string]string) (args []string) {
 	if config == nil {
 		return nil
@@ -347,3 +347,87 @@ func makeServers(masters int, total int) provisionedServers {
 	}
 	return servers
 }
+
+func (s *ConfigureSuite) TestConfiguresAPIServerAudit(c *check.C) {
+	args, err := s.cluster.addAPIServerConfig(nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(args, check.HasLen, 0, check.Commentf("Audit is disabled without a policy."))
+
+	policy := []byte(`{"apiVersion":"audit.k8s.io/v1","kind":"Policy"}`)
+	args, err = s.cluster.addAPIServerConfig(clusterconfig.New(clusterconfig.Spec{
+		Audit: &clusterconfig.Audit{
+			Policy: policy,
+			Log:    &clusterconfig.AuditLog{MaxAge: 7},
+			Webhook: &clusterconfig.AuditWebhook{
+				Config: "kind: Config",
+				Mode:   clusterconfig.AuditWebhookModeBlocking,
+			},
+		},
+	}))
+	c.Assert(err, check.IsNil)
+	c.Assert(args, check.DeepEquals, []string{
+		fmt.Sprintf("--audit-policy=%v", base64.StdEncoding.EncodeToString(policy)),
+		fmt.Sprintf("--audit-webhook-config=%v", base64.StdEncoding.EncodeToString([]byte("kind: Config"))),
+		"--apiserver-options=--audit-log-path=/var/log/kubernetes/audit.log " +
+			"--audit-log-maxage=7 --audit-log-maxbackup=10 --audit-log-maxsize=100 " +
+			"--audit-log-format=json --audit-webhook-mode=blocking",
+	})
+}
+
+func (s *ConfigureSuite) TestBindsClusterTrafficToClusterInterface(c *check.C) {
+	server := storage.Server{
+		Hostname:    "node-1",
+		AdvertiseIP: "192.168.0.2",
+		ClusterAddr: "10.100.0.2",
+	}
+	c.Assert(clusterTrafficEtcdArgs(server), check.DeepEquals, []string{
+		"--listen-peer-urls=https://10.100.0.2:2380",
+		"--initial-advertise-peer-urls=https://10.100.0.2:2380",
+	})
+	servers := provisionedServers{{Server: server}}
+	c.Assert(servers.InitialCluster(s.cluster.domainName), check.Equals,
+		"192_168_0_2.example.com:10.100.0.2")
+
+	server.ClusterAddr = ""
+	c.Assert(clusterTrafficEtcdArgs(server), check.HasLen, 0)
+}
+
+func (s *ConfigureSuite) TestAppendsClusterDestinationsToNoProxy(c *check.C) {
+	config := planetConfig{
+		server: ProvisionedServer{
+			Server: storage.Server{AdvertiseIP: "192.168.0.3"},
+		},
+		installExpand: ops.SiteOperation{
+			InstallExpand: &storage.InstallExpandOperationState{
+				Servers: []storage.Server{{AdvertiseIP: "192.168.0.3"}},
+				Subnets: storage.Subnets{
+					Service: "10.100.0.0/16",
+					Overlay: "10.244.0.0/16",
+				},
+			},
+		},
+		config: clusterconfig.New(clusterconfig.Spec{
+			Global: &clusterconfig.Global{
+				PodCIDR:   "10.200.0.0/16",
+				HTTPProxy: "http://proxy:3128",
+				NoProxy:   "localhost,example.com",
+			},
+		}),
+	}
+	servers := []storage.Server{
+		{AdvertiseIP: "192.168.0.1"},
+		{AdvertiseIP: "192.168.0.2"},
+	}
+	noProxy := "localhost,example.com,leader.telekube.local,10.100.0.0/16,10.200.0.0/16," +
+		"192.168.0.3,192.168.0.1,192.168.0.2,.cluster.local"
+	c.Assert(runtimeEnv(config, servers), check.DeepEquals, map[string]string{
+		"HTTP_PROXY": "http://proxy:3128",
+		"http_proxy": "http://proxy:3128",
+		"NO_PROXY":   noProxy,
+		"no_proxy":   noProxy,
+	})
+
+	config.config = nil
+	c.Assert(runtimeEnv(config, servers), check.HasLen, 0,
+		check.Commentf("NO_PROXY is left unset without a proxy."))
+}
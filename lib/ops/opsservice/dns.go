@@ -0,0 +1,211 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/events"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/alecthomas/template"
+	"github.com/gravitational/rigging"
+	"github.com/gravitational/trace"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// GetClusterDNS returns the cluster DNS configuration
+func (o *Operator) GetClusterDNS(key ops.SiteKey) (storage.ClusterDNS, error) {
+	cluster, err := o.backend().GetSite(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return storage.NewClusterDNS(cluster.DNSOverrides), nil
+}
+
+// UpdateClusterDNS updates the cluster DNS configuration and regenerates
+// the CoreDNS configuration from it
+func (o *Operator) UpdateClusterDNS(ctx context.Context, key ops.SiteKey, config storage.ClusterDNS) error {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := o.backend().GetSite(key.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	client, err := o.GetKubeClient()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	overrides := config.GetOverrides()
+	err = updateCorednsConfigMap(client.CoreV1().ConfigMaps(defaults.KubeSystemNamespace), overrides)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster.DNSOverrides = overrides
+	if _, err = o.backend().UpdateSite(*cluster); err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.ClusterDNSUpdated)
+	return nil
+}
+
+// NewCorednsConfigMap creates the ConfigMap that hosts the specified CoreDNS configuration
+func NewCorednsConfigMap(corefile string) *v1.ConfigMap {
+	return &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      constants.CoreDNSConfigMap,
+			Namespace: constants.KubeSystemNamespace,
+		},
+		Data: map[string]string{
+			constants.CoreDNSConfigMapKey: corefile,
+		},
+	}
+}
+
+// NewCorednsConfig returns the CoreDNS configuration for the specified DNS overrides.
+// Upstream nameservers configured for the cluster take precedence over the specified
+// default nameservers
+func NewCorednsConfig(overrides storage.DNSOverrides, upstreams []string, rotate bool) CorednsConfig {
+	if len(overrides.Upstreams) != 0 {
+		upstreams = overrides.Upstreams
+	}
+	return CorednsConfig{
+		UpstreamNameservers: upstreams,
+		Rotate:              rotate,
+		Hosts:               overrides.Hosts,
+		Zones:               overrides.Zones,
+	}
+}
+
+// updateCorednsConfigMap regenerates the CoreDNS configuration with the specified overrides.
+// If no upstream nameservers have been configured, the ones from the existing configuration are kept
+func updateCorednsConfigMap(client corev1.ConfigMapInterface, overrides storage.DNSOverrides) error {
+	configMap, err := client.Get(constants.CoreDNSConfigMap, metav1.GetOptions{})
+	err = rigging.ConvertError(err)
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	exists := err == nil
+	var upstreams []string
+	var rotate bool
+	if exists {
+		upstreams, rotate = upstreamsFromCorefile(configMap.Data[constants.CoreDNSConfigMapKey])
+	}
+	corefile, err := GenerateCorefile(NewCorednsConfig(overrides, upstreams, rotate))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if !exists {
+		_, err = client.Create(NewCorednsConfigMap(corefile))
+		return trace.Wrap(rigging.ConvertError(err))
+	}
+	if configMap.Data == nil {
+		configMap.Data = make(map[string]string)
+	}
+	configMap.Data[constants.CoreDNSConfigMapKey] = corefile
+	_, err = client.Update(configMap)
+	return trace.Wrap(rigging.ConvertError(err))
+}
+
+// upstreamsFromCorefile returns the upstream nameservers from the specified
+// CoreDNS configuration generated by GenerateCorefile along with
+// whether the nameservers are load balanced
+func upstreamsFromCorefile(corefile string) (upstreams []string, rotate bool) {
+	scanner := bufio.NewScanner(strings.NewReader(corefile))
+	var inForward bool
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) > 1 && fields[0] == "forward" && fields[1] == ".":
+			for _, field := range fields[2:] {
+				if field == "{" {
+					break
+				}
+				upstreams = append(upstreams, field)
+			}
+			inForward = true
+		case inForward && len(fields) == 2 && fields[0] == "policy":
+			rotate = fields[1] == "random"
+		case inForward && len(fields) == 1 && fields[0] == "}":
+			return upstreams, rotate
+		}
+	}
+	return upstreams, rotate
+}
+
+// GenerateCorefile will generate a coredns configuration file to be used from within the cluster
+func GenerateCorefile(config CorednsConfig) (string, error) {
+	var coredns bytes.Buffer
+	err := coreDNSTemplate.Execute(&coredns, config)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return coredns.String(), nil
+}
+
+// CoreDNSConfig represents the CoreDNS configuration options to apply to our template
+type CorednsConfig struct {
+	// Zones maps a DNS zone to nameservers it will be served by as provided by a user at install time
+	Zones map[string][]string
+	// Hosts  maps a hostname to an IP address it will resolve to as provided by a user at install time
+	Hosts map[string]string
+	// UpstreamNameservers is a list of nameservers to use as resolvers as detected from the system resolv.conf
+	// or as configured for the cluster
+	UpstreamNameservers []string
+	// Rotate indicates whether the upstream servers should be round-robin load balanced as detected from the system
+	// resolv.conf
+	Rotate bool
+}
+
+var coreDNSTemplate = template.Must(template.New("coredns").Parse(coreDNSTemplateText))
+
+const coreDNSTemplateText = `
+.:53 {
+  reload
+  errors
+  health
+  prometheus :9153
+  cache 30
+  loop
+  reload
+  loadbalance
+  hosts { {{range $hostname, $ip := .Hosts}}
+    {{$ip}} {{$hostname}}{{end}}
+    fallthrough
+  }
+  kubernetes cluster.local in-addr.arpa ip6.arpa {
+    pods verified
+    fallthrough in-addr.arpa ip6.arpa
+  }{{range $zone, $servers := .Zones}}
+  proxy {{$zone}} {{range $server := $servers}}{{$server}} {{end}}{
+    policy sequential
+  }{{end}}
+  {{if .UpstreamNameservers}}forward . {{range $server := .UpstreamNameservers}}{{$server}} {{end}}{
+    {{if .Rotate}}policy random{{else}}policy sequential{{end}}
+    health_check 0
+  }{{end}}
+}
+`
@@ -0,0 +1,189 @@
+/*
+Copyright 2018 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"github.com/gravitational/gravity/lib/storage"
+
+	"gopkg.in/check.v1"
+)
+
+type DNSSuite struct{}
+
+var _ = check.Suite(&DNSSuite{})
+
+func (*DNSSuite) TestCoreDNSConf(c *check.C) {
+	var configTable = []struct {
+		config   CorednsConfig
+		expected string
+	}{
+		{
+			CorednsConfig{
+				Zones: map[string][]string{
+					"example.com":  []string{"1.1.1.1", "2.2.2.2"},
+					"example2.com": []string{"1.1.1.1", "2.2.2.2"},
+				},
+				Hosts: map[string]string{
+					"override.com":  "5.5.5.5",
+					"override2.com": "1.2.3.4",
+				},
+				UpstreamNameservers: []string{"1.1.1.1", "8.8.8.8"},
+			},
+			`
+.:53 {
+  reload
+  errors
+  health
+  prometheus :9153
+  cache 30
+  loop
+  reload
+  loadbalance
+  hosts { 
+    5.5.5.5 override.com
+    1.2.3.4 override2.com
+    fallthrough
+  }
+  kubernetes cluster.local in-addr.arpa ip6.arpa {
+    pods verified
+    fallthrough in-addr.arpa ip6.arpa
+  }
+  proxy example.com 1.1.1.1 2.2.2.2 {
+    policy sequential
+  }
+  proxy example2.com 1.1.1.1 2.2.2.2 {
+    policy sequential
+  }
+  forward . 1.1.1.1 8.8.8.8 {
+    policy sequential
+    health_check 0
+  }
+}
+`,
+		},
+		{
+			CorednsConfig{
+				UpstreamNameservers: []string{"1.1.1.1"},
+				Rotate:              true,
+			},
+			`
+.:53 {
+  reload
+  errors
+  health
+  prometheus :9153
+  cache 30
+  loop
+  reload
+  loadbalance
+  hosts { 
+    fallthrough
+  }
+  kubernetes cluster.local in-addr.arpa ip6.arpa {
+    pods verified
+    fallthrough in-addr.arpa ip6.arpa
+  }
+  forward . 1.1.1.1 {
+    policy random
+    health_check 0
+  }
+}
+`,
+		},
+		{
+			CorednsConfig{
+				Rotate: true,
+			},
+			`
+.:53 {
+  reload
+  errors
+  health
+  prometheus :9153
+  cache 30
+  loop
+  reload
+  loadbalance
+  hosts { 
+    fallthrough
+  }
+  kubernetes cluster.local in-addr.arpa ip6.arpa {
+    pods verified
+    fallthrough in-addr.arpa ip6.arpa
+  }
+  
+}
+`,
+		},
+	}
+
+	for _, tt := range configTable {
+		config, err := GenerateCorefile(tt.config)
+
+		c.Assert(err, check.IsNil)
+		c.Assert(config, check.Equals, tt.expected)
+	}
+}
+
+func (*DNSSuite) TestClusterUpstreamsTakePrecedence(c *check.C) {
+	config := NewCorednsConfig(storage.DNSOverrides{
+		Upstreams: []string{"10.0.0.1", "10.0.0.2:5353"},
+	}, []string{"8.8.8.8"}, true)
+	c.Assert(config.UpstreamNameservers, check.DeepEquals, []string{"10.0.0.1", "10.0.0.2:5353"})
+
+	config = NewCorednsConfig(storage.DNSOverrides{}, []string{"8.8.8.8"}, true)
+	c.Assert(config.UpstreamNameservers, check.DeepEquals, []string{"8.8.8.8"})
+}
+
+func (*DNSSuite) TestUpstreamsFromCorefile(c *check.C) {
+	var cases = []struct {
+		config      CorednsConfig
+		upstreams   []string
+		rotate      bool
+		description string
+	}{
+		{
+			config: CorednsConfig{
+				UpstreamNameservers: []string{"1.1.1.1", "8.8.8.8:53"},
+				Zones:               map[string][]string{"example.com": []string{"2.2.2.2"}},
+			},
+			upstreams:   []string{"1.1.1.1", "8.8.8.8:53"},
+			description: "sequential upstreams with zone overrides",
+		},
+		{
+			config: CorednsConfig{
+				UpstreamNameservers: []string{"1.1.1.1"},
+				Rotate:              true,
+			},
+			upstreams:   []string{"1.1.1.1"},
+			rotate:      true,
+			description: "load balanced upstreams",
+		},
+		{
+			config:      CorednsConfig{},
+			description: "no upstreams",
+		},
+	}
+	for _, tt := range cases {
+		comment := check.Commentf(tt.description)
+		corefile, err := GenerateCorefile(tt.config)
+		c.Assert(err, check.IsNil, comment)
+		upstreams, rotate := upstreamsFromCorefile(corefile)
+		c.Assert(upstreams, check.DeepEquals, tt.upstreams, comment)
+		c.Assert(rotate, check.Equals, tt.rotate, comment)
+	}
+}
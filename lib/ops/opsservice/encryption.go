@@ -0,0 +1,171 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/encryptionconfig"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/storage/clusterconfig"
+
+	"github.com/gravitational/trace"
+)
+
+// EncryptionConfigPackage returns the package with the apiserver
+// encryption provider configuration of the specified cluster
+func EncryptionConfigPackage(clusterName string) (*loc.Locator, error) {
+	return loc.ParseLocator(
+		fmt.Sprintf("%v/%v:0.0.1", clusterName, constants.EncryptionConfigPackage))
+}
+
+// EncryptionKeyName returns the name of the encryption key
+// generated by the specified key rotation operation
+func EncryptionKeyName(operationID string) string {
+	return fmt.Sprintf("key-%v", operationID)
+}
+
+// StageEncryptionKey generates a new key to encrypt the secrets of the specified
+// cluster and adds it to the encryption configuration package.
+//
+// The new key only decrypts the secrets until it has been promoted,
+// so that all apiservers are able to read the secrets it encrypts
+// before any of them starts using it
+func StageEncryptionKey(packages pack.PackageService, clusterName, keyName string) error {
+	return updateEncryptionConfigPackage(packages, clusterName, func(keys []encryptionconfig.Key) ([]encryptionconfig.Key, error) {
+		key, err := encryptionconfig.NewKey(keyName)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return encryptionconfig.StageKey(keys, *key), nil
+	})
+}
+
+// UnstageEncryptionKey removes the specified key from the
+// encryption configuration package of the specified cluster
+func UnstageEncryptionKey(packages pack.PackageService, clusterName, keyName string) error {
+	return updateEncryptionConfigPackage(packages, clusterName, func(keys []encryptionconfig.Key) ([]encryptionconfig.Key, error) {
+		return encryptionconfig.UnstageKey(keys, keyName), nil
+	})
+}
+
+// PromoteEncryptionKey makes the specified key encrypt the secrets of the specified cluster.
+// The replaced key is kept to decrypt the secrets until they have been re-encrypted
+func PromoteEncryptionKey(packages pack.PackageService, clusterName, keyName string) error {
+	return updateEncryptionConfigPackage(packages, clusterName, func(keys []encryptionconfig.Key) ([]encryptionconfig.Key, error) {
+		return encryptionconfig.PromoteKey(keys, keyName)
+	})
+}
+
+// DemoteEncryptionKey reverts PromoteEncryptionKey for the specified cluster
+func DemoteEncryptionKey(packages pack.PackageService, clusterName, keyName string) error {
+	return updateEncryptionConfigPackage(packages, clusterName, func(keys []encryptionconfig.Key) ([]encryptionconfig.Key, error) {
+		return encryptionconfig.DemoteKey(keys, keyName)
+	})
+}
+
+// updateEncryptionConfigPackage applies the provided update to the keys
+// in the encryption configuration package of the specified cluster
+func updateEncryptionConfigPackage(packages pack.PackageService, clusterName string, update func([]encryptionconfig.Key) ([]encryptionconfig.Key, error)) error {
+	configPackage, err := EncryptionConfigPackage(clusterName)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	envelope, config, err := readEncryptionConfigPackage(packages, *configPackage)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	keys, err := update(config.Keys())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	data, err := encryptionconfig.FromKeys(keys, clusterconfig.Encryption{}).Marshal()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = packages.UpsertPackage(*configPackage, bytes.NewReader(data), pack.WithLabels(envelope.RuntimeLabels))
+	return trace.Wrap(err)
+}
+
+// configureEncryptionConfigPackage creates the package with the apiserver
+// encryption provider configuration shared by all masters of the cluster.
+// The configuration is generated only once as changing the
+// encryption key would render the existing secrets unreadable
+func (s *site) configureEncryptionConfigPackage(operationID string) error {
+	configPackage, err := EncryptionConfigPackage(s.siteRepoName())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if _, err := s.packages().ReadPackageEnvelope(*configPackage); err == nil {
+		s.Debugf("%v already created", configPackage)
+		return nil
+	}
+	config, err := encryptionconfig.New()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	data, err := config.Marshal()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = s.packages().CreatePackage(*configPackage, bytes.NewReader(data), pack.WithLabels(
+		map[string]string{
+			pack.PurposeLabel:     pack.PurposeEncryptionConfig,
+			pack.OperationIDLabel: operationID,
+		},
+	))
+	return trace.Wrap(err)
+}
+
+// readEncryptionConfig returns the apiserver encryption provider configuration
+// with the cluster keys and the specified encryption settings
+func (s *site) readEncryptionConfig(encryption clusterconfig.Encryption) ([]byte, error) {
+	configPackage, err := EncryptionConfigPackage(s.siteRepoName())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	_, config, err := readEncryptionConfigPackage(s.packages(), *configPackage)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	data, err := encryptionconfig.FromKeys(config.Keys(), encryption).Marshal()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return data, nil
+}
+
+func readEncryptionConfigPackage(packages pack.PackageService, configPackage loc.Locator) (*pack.PackageEnvelope, *encryptionconfig.Config, error) {
+	envelope, reader, err := packages.ReadPackage(configPackage)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	defer reader.Close()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	config, err := encryptionconfig.Parse(data)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	return envelope, config, nil
+}
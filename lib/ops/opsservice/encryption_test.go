@@ -0,0 +1,91 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/gravitational/gravity/lib/encryptionconfig"
+	"github.com/gravitational/gravity/lib/pack"
+
+	"github.com/gravitational/trace"
+	"gopkg.in/check.v1"
+)
+
+type EncryptionSuite struct {
+	packages pack.PackageService
+}
+
+var _ = check.Suite(&EncryptionSuite{})
+
+func (s *EncryptionSuite) SetUpTest(c *check.C) {
+	s.packages = SetupTestServices(c).Packages
+	c.Assert(s.packages.UpsertRepository(clusterName, time.Time{}), check.IsNil)
+	config, err := encryptionconfig.New()
+	c.Assert(err, check.IsNil)
+	data, err := config.Marshal()
+	c.Assert(err, check.IsNil)
+	configPackage, err := EncryptionConfigPackage(clusterName)
+	c.Assert(err, check.IsNil)
+	_, err = s.packages.CreatePackage(*configPackage, bytes.NewReader(data))
+	c.Assert(err, check.IsNil)
+}
+
+func (s *EncryptionSuite) TestRotatesEncryptionKey(c *check.C) {
+	keyName := EncryptionKeyName("operation-id")
+	c.Assert(StageEncryptionKey(s.packages, clusterName, keyName), check.IsNil)
+	c.Assert(s.keyNames(c), check.DeepEquals, []string{"key1", keyName})
+
+	c.Assert(PromoteEncryptionKey(s.packages, clusterName, keyName), check.IsNil)
+	c.Assert(s.keyNames(c), check.DeepEquals, []string{keyName, "key1"})
+
+	c.Assert(DemoteEncryptionKey(s.packages, clusterName, keyName), check.IsNil)
+	c.Assert(UnstageEncryptionKey(s.packages, clusterName, keyName), check.IsNil)
+	c.Assert(s.keyNames(c), check.DeepEquals, []string{"key1"})
+}
+
+func (s *EncryptionSuite) TestRefusesToDisableEncryption(c *check.C) {
+	encrypted := []byte(`kind: clusterconfiguration
+version: v1
+spec:
+  encryption:
+    provider: secretbox`)
+	plain := []byte(`kind: clusterconfiguration
+version: v1
+spec:
+  global:
+    featureGates:
+      AllAlpha: true`)
+	c.Assert(checkEncryptionUpdate(nil, encrypted), check.IsNil)
+	c.Assert(checkEncryptionUpdate(plain, encrypted), check.IsNil)
+	c.Assert(checkEncryptionUpdate(encrypted, encrypted), check.IsNil)
+	c.Assert(trace.IsBadParameter(checkEncryptionUpdate(encrypted, plain)), check.Equals, true)
+}
+
+func (s *EncryptionSuite) keyNames(c *check.C) (names []string) {
+	configPackage, err := EncryptionConfigPackage(clusterName)
+	c.Assert(err, check.IsNil)
+	_, config, err := readEncryptionConfigPackage(s.packages, *configPackage)
+	c.Assert(err, check.IsNil)
+	for _, key := range config.Keys() {
+		names = append(names, key.Name)
+	}
+	return names
+}
+
+const clusterName = "example.com"
@@ -44,6 +44,13 @@ func (o *Operator) CreateUpdateEnvarsOperation(ctx context.Context, r ops.Create
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+	existing, err := o.getIdempotentOperation(ctx, r.ClusterKey, ops.OperationUpdateRuntimeEnviron)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if existing != nil {
+		return existing, nil
+	}
 	env, err := o.getClusterEnvironment()
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -139,20 +146,24 @@ func NewEnvironmentConfigMap(data map[string]string) *v1.ConfigMap {
 // createUpdateEnvarsOperation creates a new operation to update cluster environment variables
 func (s *site) createUpdateEnvarsOperation(ctx context.Context, req ops.CreateUpdateEnvarsOperationRequest, prevEnv map[string]string) (*ops.SiteOperationKey, error) {
 	op := ops.SiteOperation{
-		ID:         uuid.New(),
-		AccountID:  s.key.AccountID,
-		SiteDomain: s.key.SiteDomain,
-		Type:       ops.OperationUpdateRuntimeEnviron,
-		Created:    s.clock().UtcNow(),
-		CreatedBy:  storage.UserFromContext(ctx),
-		Updated:    s.clock().UtcNow(),
-		State:      ops.OperationUpdateRuntimeEnvironInProgress,
+		ID:             uuid.New(),
+		AccountID:      s.key.AccountID,
+		SiteDomain:     s.key.SiteDomain,
+		Type:           ops.OperationUpdateRuntimeEnviron,
+		Created:        s.clock().UtcNow(),
+		CreatedBy:      storage.UserFromContext(ctx),
+		IdempotencyKey: ops.IdempotencyKeyFromContext(ctx),
+		Updated:        s.clock().UtcNow(),
+		State:          ops.OperationUpdateRuntimeEnvironInProgress,
 		UpdateEnviron: &storage.UpdateEnvarsOperationState{
 			PrevEnv: prevEnv,
 			Env:     req.Env,
 		},
 	}
 	key, err := s.getOperationGroup().createSiteOperation(op)
+	if existing, ok := existingOperation(err); ok {
+		return existing, nil
+	}
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -0,0 +1,42 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// GetEtcdMaintenanceStatus returns the status of the etcd compaction and defragmentation
+func (o *Operator) GetEtcdMaintenanceStatus(key ops.SiteKey) (*storage.EtcdMaintenanceStatus, error) {
+	status, err := o.backend().GetEtcdMaintenanceStatus(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return status, nil
+}
+
+// GetEtcdHealthStatus returns the status of the etcd health and capacity
+func (o *Operator) GetEtcdHealthStatus(key ops.SiteKey) (*storage.EtcdHealthStatus, error) {
+	status, err := o.backend().GetEtcdHealthStatus(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return status, nil
+}
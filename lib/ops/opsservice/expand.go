@@ -144,6 +144,10 @@ func (s *site) validateExpand(op *ops.SiteOperation, req *ops.OperationUpdateReq
 		}
 	}
 
+	if err := s.validateNodePools(req.Servers); err != nil {
+		return trace.Wrap(err)
+	}
+
 	labels := map[string]string{
 		schema.ServiceLabelRole: string(schema.ServiceRoleMaster),
 	}
@@ -153,5 +157,10 @@ func (s *site) validateExpand(op *ops.SiteOperation, req *ops.OperationUpdateReq
 	}
 
 	err = setClusterRoles(req.Servers, *s.app, len(masters))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	err = s.setReplacementClusterRoles(req.Servers)
 	return trace.Wrap(err)
 }
@@ -34,17 +34,21 @@ func (s *site) createGarbageCollectOperation(ctx context.Context, req ops.Create
 	}
 
 	op := ops.SiteOperation{
-		ID:         uuid.New(),
-		AccountID:  s.key.AccountID,
-		SiteDomain: s.key.SiteDomain,
-		Type:       ops.OperationGarbageCollect,
-		Created:    s.clock().UtcNow(),
-		CreatedBy:  storage.UserFromContext(ctx),
-		Updated:    s.clock().UtcNow(),
-		State:      ops.OperationGarbageCollectInProgress,
+		ID:             uuid.New(),
+		AccountID:      s.key.AccountID,
+		SiteDomain:     s.key.SiteDomain,
+		Type:           ops.OperationGarbageCollect,
+		Created:        s.clock().UtcNow(),
+		CreatedBy:      storage.UserFromContext(ctx),
+		IdempotencyKey: ops.IdempotencyKeyFromContext(ctx),
+		Updated:        s.clock().UtcNow(),
+		State:          ops.OperationGarbageCollectInProgress,
 	}
 
 	key, err := s.getOperationGroup().createSiteOperation(op)
+	if existing, ok := existingOperation(err); ok {
+		return existing, nil
+	}
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -0,0 +1,281 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/gravitational/gravity/lib/httplib"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/events"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// GetHealthChecks returns the list of health checks of the cluster
+func (o *Operator) GetHealthChecks(key ops.SiteKey) ([]storage.HealthCheck, error) {
+	checks, err := o.backend().GetHealthChecks(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return checks, nil
+}
+
+// UpsertHealthCheck creates or updates a health check
+func (o *Operator) UpsertHealthCheck(ctx context.Context, key ops.SiteKey, check storage.HealthCheck) error {
+	if err := check.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := o.backend().UpsertHealthCheck(key.SiteDomain, check); err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.HealthCheckCreated, events.Fields{
+		events.FieldName: check.GetName(),
+	})
+	return nil
+}
+
+// DeleteHealthCheck deletes the health check with the specified name
+func (o *Operator) DeleteHealthCheck(ctx context.Context, key ops.SiteKey, name string) error {
+	if err := o.backend().DeleteHealthCheck(key.SiteDomain, name); err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.HealthCheckDeleted, events.Fields{
+		events.FieldName: name,
+	})
+	return nil
+}
+
+// GetHealthCheckStatus returns the result of the last evaluation
+// of the cluster health checks
+func (o *Operator) GetHealthCheckStatus(key ops.SiteKey) (*storage.HealthCheckStatus, error) {
+	status, err := o.backend().GetHealthCheckStatus(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return status, nil
+}
+
+// runHealthChecks evaluates the health checks of the cluster
+// and saves the results
+func (o *Operator) runHealthChecks(ctx context.Context, key ops.SiteKey) (*storage.HealthCheckStatus, error) {
+	checks, err := o.backend().GetHealthChecks(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	status := storage.HealthCheckStatus{
+		Results: make([]storage.HealthCheckResult, len(checks)),
+		Updated: o.cfg.Clock.UtcNow(),
+	}
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check storage.HealthCheck) {
+			defer wg.Done()
+			status.Results[i] = o.runHealthCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+	if err := o.backend().UpsertHealthCheckStatus(key.SiteDomain, status); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &status, nil
+}
+
+// checkHealthCheckStatus returns an error if any of the critical
+// health checks failed
+func checkHealthCheckStatus(status storage.HealthCheckStatus) error {
+	if failed := status.Failed(storage.HealthCheckSeverityCritical); len(failed) != 0 {
+		return trace.BadParameter("cluster health checks failed: %v", failed)
+	}
+	return nil
+}
+
+// runHealthCheck evaluates the specified health check
+func (o *Operator) runHealthCheck(ctx context.Context, check storage.HealthCheck) storage.HealthCheckResult {
+	result := storage.HealthCheckResult{
+		Name:     check.GetName(),
+		Type:     check.GetType(),
+		Severity: check.GetSeverity(),
+	}
+	ctx, cancel := context.WithTimeout(ctx, check.GetTimeout())
+	defer cancel()
+	var err error
+	switch {
+	case check.GetHTTP() != nil:
+		err = probeHTTP(ctx, *check.GetHTTP())
+	case check.GetCommand() != nil:
+		err = probeCommand(ctx, *check.GetCommand())
+	case check.GetKubernetes() != nil:
+		var client *kubernetes.Clientset
+		client, err = o.GetKubeClient()
+		if err == nil {
+			err = probeKubernetes(ctx, client, *check.GetKubernetes())
+		}
+	}
+	if err != nil {
+		o.WithError(err).Warnf("Health check %v failed.", check.GetName())
+		result.Message = trace.UserMessage(err)
+		return result
+	}
+	result.Healthy = true
+	return result
+}
+
+// probeHTTP makes sure the endpoint responds with the expected status code
+func probeHTTP(ctx context.Context, probe storage.HealthCheckHTTP) error {
+	var options []httplib.ClientOption
+	if probe.CACert != "" {
+		options = append(options, httplib.WithCA([]byte(probe.CACert)))
+	}
+	client := httplib.GetClient(false, options...)
+	req, err := http.NewRequest(http.MethodGet, probe.URL, nil)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if probe.ExpectedStatus != 0 && resp.StatusCode != probe.ExpectedStatus {
+		return trace.BadParameter("unexpected response status %v, expected %v",
+			resp.StatusCode, probe.ExpectedStatus)
+	}
+	if probe.ExpectedStatus == 0 && (resp.StatusCode < 200 || resp.StatusCode > 299) {
+		return trace.BadParameter("unexpected response status %v", resp.StatusCode)
+	}
+	return nil
+}
+
+// probeCommand makes sure the command exits successfully
+func probeCommand(ctx context.Context, probe storage.HealthCheckCommand) error {
+	out, err := exec.CommandContext(ctx, probe.Command[0], probe.Command[1:]...).CombinedOutput()
+	if err != nil {
+		if ctx.Err() != nil {
+			return trace.LimitExceeded("command timed out")
+		}
+		if output := strings.TrimSpace(string(out)); output != "" {
+			return trace.BadParameter("command failed: %v: %v", err, output)
+		}
+		return trace.BadParameter("command failed: %v", err)
+	}
+	return nil
+}
+
+// probeKubernetes makes sure the Kubernetes resource has the expected condition
+func probeKubernetes(ctx context.Context, client kubernetes.Interface, probe storage.HealthCheckKubernetes) error {
+	conditions, err := getResourceConditions(client, probe)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(checkResourceCondition(probe, conditions))
+}
+
+// resourceCondition is the common part of the Kubernetes resource conditions
+type resourceCondition struct {
+	// Type is the condition type
+	Type string
+	// Status is the condition status
+	Status string
+	// Message describes the condition
+	Message string
+}
+
+// checkResourceCondition makes sure the conditions have the probed
+// condition with the expected status
+func checkResourceCondition(probe storage.HealthCheckKubernetes, conditions []resourceCondition) error {
+	for _, condition := range conditions {
+		if condition.Type != probe.Condition {
+			continue
+		}
+		if condition.Status != probe.GetStatus() {
+			message := fmt.Sprintf("%v %v %v condition is %v, expected %v",
+				probe.Kind, probe.Name, condition.Type, condition.Status, probe.GetStatus())
+			if condition.Message != "" {
+				message = fmt.Sprintf("%v: %v", message, condition.Message)
+			}
+			return trace.BadParameter("%v", message)
+		}
+		return nil
+	}
+	return trace.NotFound("%v %v has no %v condition", probe.Kind, probe.Name, probe.Condition)
+}
+
+// getResourceConditions returns the conditions of the probed Kubernetes resource
+func getResourceConditions(client kubernetes.Interface, probe storage.HealthCheckKubernetes) (conditions []resourceCondition, err error) {
+	namespace := probe.Namespace
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	switch probe.Kind {
+	case "node":
+		node, err := client.CoreV1().Nodes().Get(probe.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		for _, c := range node.Status.Conditions {
+			conditions = append(conditions, resourceCondition{string(c.Type), string(c.Status), c.Message})
+		}
+	case "pod":
+		pod, err := client.CoreV1().Pods(namespace).Get(probe.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		for _, c := range pod.Status.Conditions {
+			conditions = append(conditions, resourceCondition{string(c.Type), string(c.Status), c.Message})
+		}
+	case "deployment":
+		deployment, err := client.AppsV1().Deployments(namespace).Get(probe.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		for _, c := range deployment.Status.Conditions {
+			conditions = append(conditions, resourceCondition{string(c.Type), string(c.Status), c.Message})
+		}
+	case "daemonset":
+		daemonSet, err := client.AppsV1().DaemonSets(namespace).Get(probe.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		for _, c := range daemonSet.Status.Conditions {
+			conditions = append(conditions, resourceCondition{string(c.Type), string(c.Status), c.Message})
+		}
+	case "statefulset":
+		statefulSet, err := client.AppsV1().StatefulSets(namespace).Get(probe.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		for _, c := range statefulSet.Status.Conditions {
+			conditions = append(conditions, resourceCondition{string(c.Type), string(c.Status), c.Message})
+		}
+	default:
+		return nil, trace.BadParameter("unsupported kubernetes kind %q", probe.Kind)
+	}
+	return conditions, nil
+}
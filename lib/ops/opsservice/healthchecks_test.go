@@ -0,0 +1,132 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+
+	. "gopkg.in/check.v1"
+)
+
+type HealthChecksSuite struct {
+	operator *Operator
+	key      ops.SiteKey
+}
+
+var _ = Suite(&HealthChecksSuite{})
+
+func (s *HealthChecksSuite) SetUpTest(c *C) {
+	services := SetupTestServices(c)
+	s.operator = services.Operator
+	cluster, err := services.Backend.CreateSite(storage.Site{
+		AccountID: defaults.SystemAccountID,
+		Domain:    "example.com",
+		Local:     true,
+		App: storage.Package{
+			Repository: defaults.SystemAccountOrg,
+			Name:       "example",
+			Version:    "0.0.1",
+		},
+		Created: time.Now(),
+	})
+	c.Assert(err, IsNil)
+	s.key = ops.SiteKey{AccountID: cluster.AccountID, SiteDomain: cluster.Domain}
+}
+
+func (s *HealthChecksSuite) TestRunsHealthChecks(c *C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx := context.TODO()
+	for _, check := range []storage.HealthCheck{
+		storage.NewHealthCheck("api", storage.HealthCheckSpecV2{
+			HTTP: &storage.HealthCheckHTTP{URL: server.URL},
+		}),
+		storage.NewHealthCheck("maintenance", storage.HealthCheckSpecV2{
+			HTTP:     &storage.HealthCheckHTTP{URL: server.URL, ExpectedStatus: http.StatusServiceUnavailable},
+			Severity: storage.HealthCheckSeverityWarning,
+		}),
+		storage.NewHealthCheck("disk", storage.HealthCheckSpecV2{
+			Command:  &storage.HealthCheckCommand{Command: []string{"false"}},
+			Severity: storage.HealthCheckSeverityWarning,
+		}),
+	} {
+		c.Assert(s.operator.UpsertHealthCheck(ctx, s.key, check), IsNil)
+	}
+
+	status, err := s.operator.runHealthChecks(ctx, s.key)
+	c.Assert(err, IsNil)
+	c.Assert(status.Results, HasLen, 3)
+	c.Assert(status.Results[0].Name, Equals, "api")
+	c.Assert(status.Results[0].Healthy, Equals, false)
+	c.Assert(status.Results[0].Message, Matches, ".*503.*")
+	c.Assert(status.Results[1].Name, Equals, "disk")
+	c.Assert(status.Results[1].Healthy, Equals, false)
+	c.Assert(status.Results[2].Name, Equals, "maintenance")
+	c.Assert(status.Results[2].Healthy, Equals, true)
+	c.Assert(checkHealthCheckStatus(*status), NotNil)
+
+	saved, err := s.operator.GetHealthCheckStatus(s.key)
+	c.Assert(err, IsNil)
+	c.Assert(saved.Results, DeepEquals, status.Results)
+
+	// only warnings remain after the critical check is removed
+	c.Assert(s.operator.DeleteHealthCheck(ctx, s.key, "api"), IsNil)
+	status, err = s.operator.runHealthChecks(ctx, s.key)
+	c.Assert(err, IsNil)
+	c.Assert(status.Results, HasLen, 2)
+	c.Assert(checkHealthCheckStatus(*status), IsNil)
+}
+
+func (s *HealthChecksSuite) TestCommandTimeout(c *C) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	err := probeCommand(ctx, storage.HealthCheckCommand{Command: []string{"sleep", "5"}})
+	c.Assert(err, ErrorMatches, "command timed out")
+	c.Assert(probeCommand(context.TODO(), storage.HealthCheckCommand{Command: []string{"true"}}), IsNil)
+}
+
+func (s *HealthChecksSuite) TestChecksResourceCondition(c *C) {
+	probe := storage.HealthCheckKubernetes{
+		Kind:      "deployment",
+		Namespace: "default",
+		Name:      "payments",
+		Condition: "Available",
+	}
+	c.Assert(checkResourceCondition(probe, []resourceCondition{
+		{Type: "Progressing", Status: "True"},
+		{Type: "Available", Status: "True"},
+	}), IsNil)
+	c.Assert(checkResourceCondition(probe, []resourceCondition{
+		{Type: "Available", Status: "False", Message: "minimum replicas unavailable"},
+	}), ErrorMatches, ".*Available condition is False, expected True: minimum replicas unavailable")
+	c.Assert(checkResourceCondition(probe, nil), ErrorMatches, ".*has no Available condition")
+
+	probe.Status = "False"
+	c.Assert(checkResourceCondition(probe, []resourceCondition{
+		{Type: "Available", Status: "False"},
+	}), IsNil)
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/status"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// GetHealthHistory returns the cluster health snapshots recorded since
+// the specified time, ordered from the oldest to the newest
+func (o *Operator) GetHealthHistory(key ops.SiteKey, since time.Time) ([]storage.HealthSnapshot, error) {
+	snapshots, err := o.backend().GetHealthSnapshots(key.SiteDomain, since)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return snapshots, nil
+}
+
+// recordHealthSnapshot records the snapshot in the cluster health history
+// if the cluster health has changed since the last recorded snapshot or
+// the last snapshot is older than the health history interval
+func (o *Operator) recordHealthSnapshot(key ops.SiteKey, snapshot storage.HealthSnapshot) {
+	snapshot.Time = o.cfg.Clock.UtcNow()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	last := o.lastHealthSnapshot
+	if last != nil && last.Equals(snapshot) &&
+		snapshot.Time.Sub(last.Time) < defaults.HealthHistoryInterval {
+		return
+	}
+	err := o.backend().AddHealthSnapshot(key.SiteDomain, snapshot, defaults.HealthHistoryCapacity)
+	if err != nil {
+		o.WithError(err).Warn("Failed to record cluster health snapshot.")
+		return
+	}
+	o.lastHealthSnapshot = &snapshot
+}
+
+// newHealthSnapshot returns the cluster health snapshot with the results
+// of the cluster status checks
+func newHealthSnapshot(agent *status.Agent, healthChecks *storage.HealthCheckStatus, reason storage.Reason, statusErr error) storage.HealthSnapshot {
+	snapshot := storage.HealthSnapshot{
+		Status: storage.HealthStatusHealthy,
+	}
+	if agent != nil {
+		for _, node := range agent.Nodes {
+			snapshot.Nodes = append(snapshot.Nodes, storage.NodeHealth{
+				Hostname:     node.Hostname,
+				AdvertiseIP:  node.AdvertiseIP,
+				Status:       node.Status,
+				FailedProbes: node.FailedProbes,
+			})
+		}
+	}
+	if healthChecks != nil {
+		for _, result := range healthChecks.Failed(storage.HealthCheckSeverityCritical) {
+			snapshot.FailedHealthChecks = append(snapshot.FailedHealthChecks, result.String())
+		}
+	}
+	if statusErr == nil {
+		return snapshot
+	}
+	snapshot.Status = storage.HealthStatusDegraded
+	snapshot.Reason = reason
+	// the degraded nodes are described by their failed probes so
+	// the message is only needed if the node status is not available
+	if reason != storage.ReasonClusterDegraded || agent == nil {
+		snapshot.Message = trace.UserMessage(statusErr)
+	}
+	return snapshot
+}
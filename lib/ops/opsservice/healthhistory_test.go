@@ -0,0 +1,104 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/status"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	"github.com/mailgun/timetools"
+	. "gopkg.in/check.v1"
+)
+
+type HealthHistorySuite struct {
+	operator *Operator
+	clock    *timetools.FreezedTime
+	key      ops.SiteKey
+}
+
+var _ = Suite(&HealthHistorySuite{})
+
+func (s *HealthHistorySuite) SetUpTest(c *C) {
+	services := SetupTestServices(c)
+	s.operator = services.Operator
+	s.clock = &timetools.FreezedTime{CurrentTime: time.Date(2019, 1, 1, 2, 0, 0, 0, time.UTC)}
+	s.operator.cfg.Clock = s.clock
+	s.key = ops.SiteKey{AccountID: defaults.SystemAccountID, SiteDomain: "example.com"}
+}
+
+func (s *HealthHistorySuite) TestRecordsHealthChanges(c *C) {
+	healthy := newHealthSnapshot(&status.Agent{
+		Nodes: []status.ClusterServer{{
+			Hostname:    "node-1",
+			AdvertiseIP: "192.168.1.1",
+			Status:      status.NodeHealthy,
+		}},
+	}, nil, storage.ReasonClusterDegraded, nil)
+	degraded := newHealthSnapshot(&status.Agent{
+		Nodes: []status.ClusterServer{{
+			Hostname:     "node-1",
+			AdvertiseIP:  "192.168.1.1",
+			Status:       status.NodeDegraded,
+			FailedProbes: []string{"docker"},
+		}},
+	}, nil, storage.ReasonClusterDegraded, trace.BadParameter("cluster is not healthy"))
+	c.Assert(degraded.IsDegraded(), Equals, true)
+	c.Assert(degraded.Reason, Equals, storage.ReasonClusterDegraded)
+	// degraded nodes are described by their failed probes
+	c.Assert(degraded.Message, Equals, "")
+
+	s.operator.recordHealthSnapshot(s.key, healthy)
+	// unchanged health is not recorded until the history interval passes
+	s.clock.Sleep(time.Minute)
+	s.operator.recordHealthSnapshot(s.key, healthy)
+	s.clock.Sleep(time.Minute)
+	s.operator.recordHealthSnapshot(s.key, degraded)
+	s.clock.Sleep(time.Minute)
+	s.operator.recordHealthSnapshot(s.key, degraded)
+	s.clock.Sleep(defaults.HealthHistoryInterval)
+	s.operator.recordHealthSnapshot(s.key, degraded)
+
+	snapshots, err := s.operator.GetHealthHistory(s.key, time.Time{})
+	c.Assert(err, IsNil)
+	c.Assert(snapshots, HasLen, 3)
+	c.Assert(snapshots[0].Status, Equals, storage.HealthStatusHealthy)
+	c.Assert(snapshots[1].Status, Equals, storage.HealthStatusDegraded)
+	c.Assert(snapshots[1].Nodes[0].FailedProbes, DeepEquals, []string{"docker"})
+	c.Assert(snapshots[1].Time.Sub(snapshots[0].Time), Equals, 2*time.Minute)
+	c.Assert(snapshots[2].Status, Equals, storage.HealthStatusDegraded)
+
+	snapshots, err = s.operator.GetHealthHistory(s.key, snapshots[1].Time)
+	c.Assert(err, IsNil)
+	c.Assert(snapshots, HasLen, 2)
+}
+
+func (s *HealthHistorySuite) TestDescribesFailedStatusChecks(c *C) {
+	snapshot := newHealthSnapshot(nil, &storage.HealthCheckStatus{
+		Results: []storage.HealthCheckResult{
+			{Name: "api", Severity: storage.HealthCheckSeverityCritical, Message: "timeout"},
+			{Name: "cache", Severity: storage.HealthCheckSeverityWarning, Message: "not ready"},
+		},
+	}, storage.ReasonHealthCheckFailed, trace.BadParameter("cluster health checks failed"))
+	c.Assert(snapshot.IsDegraded(), Equals, true)
+	c.Assert(snapshot.FailedHealthChecks, DeepEquals, []string{"api (timeout)"})
+	c.Assert(snapshot.Message, Equals, "cluster health checks failed")
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// getIdempotentOperation returns the key of the operation of the specified type
+// created in the cluster by an earlier request of the same user with the idempotency
+// key from the context, or nil if the context has no key or there is no such operation.
+//
+// The operation creating methods check it before any side effects so a retried
+// request returns the original operation instead of creating a duplicate
+func (o *Operator) getIdempotentOperation(ctx context.Context, key ops.SiteKey, operationType string) (*ops.SiteOperationKey, error) {
+	idempotencyKey := ops.IdempotencyKeyFromContext(ctx)
+	if idempotencyKey == "" {
+		return nil, nil
+	}
+	operations, err := o.backend().GetSiteOperations(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return findIdempotentOperation(operations, storage.UserFromContext(ctx), idempotencyKey, operationType)
+}
+
+// operationExistsError is returned by the operation group if an operation with
+// the same idempotency key has already been created by an earlier request.
+// Retried requests can race past the getIdempotentOperation check, the callers
+// use existingOperation to return the original operation without repeating
+// the side effects of creating a new one
+type operationExistsError struct {
+	trace.AlreadyExistsError
+	// key identifies the existing operation
+	key ops.SiteOperationKey
+}
+
+func errOperationExists(key ops.SiteOperationKey, idempotencyKey string) error {
+	return trace.Wrap(&operationExistsError{
+		AlreadyExistsError: trace.AlreadyExistsError{
+			Message: fmt.Sprintf("operation %v has already been created with idempotency key %q",
+				key.OperationID, idempotencyKey),
+		},
+		key: key,
+	})
+}
+
+// existingOperation returns the key of the existing operation if the specified
+// error indicates that the operation has already been created with the same
+// idempotency key
+func existingOperation(err error) (*ops.SiteOperationKey, bool) {
+	if existsErr, ok := trace.Unwrap(err).(*operationExistsError); ok {
+		return &existsErr.key, true
+	}
+	return nil, false
+}
+
+// findIdempotentOperation returns the key of the operation of the specified
+// type created by the specified user with the specified idempotency key,
+// or nil if there is none.
+// Idempotency keys are scoped to the user so a key chosen by one user never
+// matches the operations of another.
+// Returns AlreadyExists if the user has used the key to create an operation
+// of a different type
+func findIdempotentOperation(operations []storage.SiteOperation, createdBy, idempotencyKey, operationType string) (*ops.SiteOperationKey, error) {
+	for _, operation := range operations {
+		if operation.IdempotencyKey != idempotencyKey || operation.CreatedBy != createdBy {
+			continue
+		}
+		if operation.Type != operationType {
+			return nil, trace.AlreadyExists(
+				"idempotency key %q has already been used to create %v operation %v",
+				idempotencyKey, operation.Type, operation.ID)
+		}
+		return &ops.SiteOperationKey{
+			AccountID:   operation.AccountID,
+			SiteDomain:  operation.SiteDomain,
+			OperationID: operation.ID,
+		}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/events"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// GetImageTrustPolicy returns the image trust policy
+func (o *Operator) GetImageTrustPolicy(key ops.SiteKey) (storage.ImageTrustPolicy, error) {
+	policy, err := o.backend().GetImageTrustPolicy()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return policy, nil
+}
+
+// UpsertImageTrustPolicy creates or updates the image trust policy.
+// The policy applies to the images pushed to the cluster registry
+// after it has been updated
+func (o *Operator) UpsertImageTrustPolicy(ctx context.Context, key ops.SiteKey, policy storage.ImageTrustPolicy) error {
+	if err := policy.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := o.backend().UpsertImageTrustPolicy(policy); err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.ImageTrustPolicyUpdated, events.Fields{
+		events.FieldMode: policy.GetMode(),
+	})
+	return nil
+}
+
+// DeleteImageTrustPolicy deletes the image trust policy
+func (o *Operator) DeleteImageTrustPolicy(ctx context.Context, key ops.SiteKey) error {
+	if err := o.backend().DeleteImageTrustPolicy(); err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.ImageTrustPolicyDeleted)
+	return nil
+}
@@ -102,15 +102,16 @@ func (s *site) createInstallExpandOperation(context context.Context, req createI
 	profiles := req.Profiles
 
 	op := &ops.SiteOperation{
-		ID:          uuid.New(),
-		AccountID:   s.key.AccountID,
-		SiteDomain:  s.key.SiteDomain,
-		Type:        operationType,
-		Created:     s.clock().UtcNow(),
-		CreatedBy:   storage.UserFromContext(context),
-		Updated:     s.clock().UtcNow(),
-		State:       operationInitialState,
-		Provisioner: provisioner,
+		ID:             uuid.New(),
+		AccountID:      s.key.AccountID,
+		SiteDomain:     s.key.SiteDomain,
+		Type:           operationType,
+		Created:        s.clock().UtcNow(),
+		CreatedBy:      storage.UserFromContext(context),
+		IdempotencyKey: ops.IdempotencyKeyFromContext(context),
+		Updated:        s.clock().UtcNow(),
+		State:          operationInitialState,
+		Provisioner:    provisioner,
 	}
 
 	token, err := s.newProvisioningToken(*op)
@@ -186,6 +187,9 @@ func (s *site) createInstallExpandOperation(context context.Context, req createI
 	ctx.Debugf("selected subnets: %v", subnets)
 
 	key, err := s.getOperationGroup().createSiteOperation(*op)
+	if existing, ok := existingOperation(err); ok {
+		return existing, nil
+	}
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -270,6 +274,7 @@ func (s *site) updateRequestVars(ctx *operationContext, vars *storage.OperationV
 		if installVars.OnPrem.VxlanPort != 0 {
 			vars.OnPrem.VxlanPort = installVars.OnPrem.VxlanPort
 		}
+		vars.OnPrem.NetworkEncryption = installVars.OnPrem.NetworkEncryption
 	}
 
 	if !isAWSProvisioner(op.Provisioner) {
@@ -676,6 +681,17 @@ func (s *site) configureOnPremServers(ctx *operationContext, servers []storage.S
 		servers[i].Provisioner = schema.ProvisionerOnPrem
 		servers[i].Created = time.Now().UTC()
 
+		profile, err := s.app.Manifest.NodeProfiles.ByName(server.Role)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if interfaces := profile.Requirements.Network.Interfaces; interfaces != nil {
+			err = checks.ConfigureInterfaces(&servers[i], *info, *interfaces)
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+		}
+
 		if info.CloudMetadata != nil {
 			servers[i].Nodename = info.CloudMetadata.NodeName
 			servers[i].InstanceType = info.CloudMetadata.InstanceType
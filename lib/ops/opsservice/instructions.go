@@ -40,8 +40,10 @@ var (
 set -e
 
 CURL_OPTS="--retry 100 --retry-delay 0 --connect-timeout 10 --max-time 300 --tlsv1.2 --silent --show-error --http1.0"
+GRAVITY_URL={{.gravity_url}}{{range $machine, $url := .gravity_arch_urls}}
+if [ "$(uname -m)" = "{{$machine}}" ]; then GRAVITY_URL={{$url}}; fi{{end}}
 echo "$(date) [INFO] Downloading install agent..."
-curl $CURL_OPTS {{if .devmode}}-k{{end}} -H "Authorization: Bearer {{.ops_token}}" {{.gravity_url}} -o {{.gravity_bin_path}}
+curl $CURL_OPTS {{if .devmode}}-k{{end}} -H "Authorization: Bearer {{.ops_token}}" $GRAVITY_URL -o {{.gravity_bin_path}}
 chmod 755 {{.gravity_bin_path}}
 
 echo "$(date) [INFO] Install agent will be using ${TMPDIR:-/tmp} for temporary files"
@@ -110,6 +112,25 @@ func (s *site) getDownloadInstructions(token, serverProfile string) (string, err
 	return out.String(), nil
 }
 
+// gravityArchURLs returns download URLs of the gravity binaries for
+// the CPU architectures other than amd64 the cluster image supports,
+// keyed by the machine hardware name reported by uname
+func (s *site) gravityArchURLs() map[string]string {
+	urls := make(map[string]string)
+	for _, arch := range s.app.Manifest.Architectures() {
+		if machine, ok := unameMachines[arch]; ok {
+			urls[machine] = s.packages().PackageDownloadURL(s.gravityPackage.ForArch(arch))
+		}
+	}
+	return urls
+}
+
+// unameMachines maps supported CPU architectures other than amd64
+// to the machine hardware names reported by uname
+var unameMachines = map[string]string{
+	constants.ArchARM64: "aarch64",
+}
+
 // getJoinInstructions returns a bash script source that starts agents for
 // a wizard installation or expand
 func (s *site) getJoinInstructions(token storage.ProvisioningToken, serverProfile string, params url.Values) (string, error) {
@@ -125,6 +146,7 @@ func (s *site) getJoinInstructions(token storage.ProvisioningToken, serverProfil
 		"service_uid":       s.uid(),
 		"service_gid":       s.gid(),
 		"gravity_url":       s.packages().PackageDownloadURL(s.gravityPackage),
+		"gravity_arch_urls": s.gravityArchURLs(),
 		"advertise_addr":    params.Get(schema.AdvertiseAddr),
 		"install_token":     token.Token,
 		"profile":           serverProfile,
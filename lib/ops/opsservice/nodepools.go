@@ -0,0 +1,124 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/events"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// GetNodePools returns the list of node pools of the cluster
+func (o *Operator) GetNodePools(key ops.SiteKey) ([]storage.NodePool, error) {
+	pools, err := o.backend().GetNodePools(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return pools, nil
+}
+
+// UpsertNodePool creates or updates a node pool.
+// The pool profile must be one of the node profiles of the cluster application
+func (o *Operator) UpsertNodePool(ctx context.Context, key ops.SiteKey, pool storage.NodePool) error {
+	if err := pool.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := o.openSite(key)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if _, err := cluster.app.Manifest.NodeProfiles.ByName(pool.GetProfile()); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := o.backend().UpsertNodePool(key.SiteDomain, pool); err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.NodePoolCreated, events.Fields{
+		events.FieldName: pool.GetName(),
+	})
+	return nil
+}
+
+// DeleteNodePool deletes the node pool with the specified name.
+// A pool cannot be deleted while it still has nodes
+func (o *Operator) DeleteNodePool(ctx context.Context, key ops.SiteKey, name string) error {
+	cluster, err := o.openSite(key)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if nodes := nodePoolServers(cluster.servers(), name); len(nodes) != 0 {
+		return trace.BadParameter("node pool %v still has %v node(-s), "+
+			"remove them from the cluster first", name, len(nodes))
+	}
+	if err := o.backend().DeleteNodePool(key.SiteDomain, name); err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.NodePoolDeleted, events.Fields{
+		events.FieldName: name,
+	})
+	return nil
+}
+
+// validateNodePools makes sure the servers joining a node pool have the profile
+// of the pool and do not exceed its maximum size, and assigns them the pool labels
+func (s *site) validateNodePools(servers []storage.Server) error {
+	existing := s.servers()
+	for i, server := range servers {
+		if server.NodePool == "" {
+			continue
+		}
+		pool, err := s.backend().GetNodePool(s.domainName, server.NodePool)
+		if err != nil {
+			if trace.IsNotFound(err) {
+				return trace.NotFound("node pool %q does not exist", server.NodePool)
+			}
+			return trace.Wrap(err)
+		}
+		if server.Role != pool.GetProfile() {
+			return trace.BadParameter("node pool %v requires profile %q, but node %v has profile %q",
+				pool.GetName(), pool.GetProfile(), server.Hostname, server.Role)
+		}
+		count := len(nodePoolServers(existing, pool.GetName())) +
+			len(nodePoolServers(servers[:i], pool.GetName()))
+		if pool.GetMaxCount() != 0 && count >= pool.GetMaxCount() {
+			return trace.LimitExceeded("node pool %v is full: it already has %v of maximum %v node(-s)",
+				pool.GetName(), count, pool.GetMaxCount())
+		}
+		labels := storage.NodeLabels(pool)
+		for key, value := range server.Labels {
+			if _, ok := labels[key]; !ok {
+				labels[key] = value
+			}
+		}
+		servers[i].Labels = labels
+	}
+	return nil
+}
+
+// nodePoolServers returns the servers that belong to the specified node pool
+func nodePoolServers(servers []storage.Server, pool string) (result []storage.Server) {
+	for _, server := range servers {
+		if server.NodePool == pool {
+			result = append(result, server)
+		}
+	}
+	return result
+}
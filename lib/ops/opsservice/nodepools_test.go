@@ -0,0 +1,117 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/compare"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/suite"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+type NodePoolsSuite struct {
+	operator *Operator
+	cluster  *ops.Site
+}
+
+var _ = Suite(&NodePoolsSuite{})
+
+func (s *NodePoolsSuite) SetUpTest(c *C) {
+	services := SetupTestServices(c)
+	s.operator = services.Operator
+	app := suite.SetUpTestPackage(c, services.Apps, services.Packages)
+
+	account, err := s.operator.CreateAccount(ops.NewAccountRequest{Org: "testing"})
+	c.Assert(err, IsNil)
+
+	s.cluster, err = s.operator.CreateSite(ops.NewSiteRequest{
+		AccountID:  account.ID,
+		AppPackage: app.String(),
+		Provider:   schema.ProvisionerOnPrem,
+		DomainName: "test.localdomain",
+	})
+	c.Assert(err, IsNil)
+}
+
+func (s *NodePoolsSuite) TestNodePoolRequiresKnownProfile(c *C) {
+	ctx := context.TODO()
+	err := s.operator.UpsertNodePool(ctx, s.cluster.Key(),
+		storage.NewNodePool("gpu", storage.NodePoolSpecV2{Profile: "unknown"}))
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%v", err))
+
+	err = s.operator.UpsertNodePool(ctx, s.cluster.Key(),
+		storage.NewNodePool("gpu", storage.NodePoolSpecV2{Profile: "knode"}))
+	c.Assert(err, IsNil)
+
+	pools, err := s.operator.GetNodePools(s.cluster.Key())
+	c.Assert(err, IsNil)
+	c.Assert(pools, HasLen, 1)
+	c.Assert(pools[0].GetName(), Equals, "gpu")
+
+	c.Assert(s.operator.DeleteNodePool(ctx, s.cluster.Key(), "gpu"), IsNil)
+	pools, err = s.operator.GetNodePools(s.cluster.Key())
+	c.Assert(err, IsNil)
+	c.Assert(pools, HasLen, 0)
+}
+
+func (s *NodePoolsSuite) TestValidatesJoiningNodes(c *C) {
+	err := s.operator.UpsertNodePool(context.TODO(), s.cluster.Key(),
+		storage.NewNodePool("gpu", storage.NodePoolSpecV2{
+			Profile:  "knode",
+			Labels:   map[string]string{"accelerator": "nvidia"},
+			MaxCount: 1,
+		}))
+	c.Assert(err, IsNil)
+	cluster, err := s.operator.openSite(s.cluster.Key())
+	c.Assert(err, IsNil)
+
+	servers := []storage.Server{{
+		Hostname: "node-1",
+		Role:     "knode",
+		NodePool: "gpu",
+		Labels:   map[string]string{"rack": "r1", "accelerator": "amd"},
+	}}
+	c.Assert(cluster.validateNodePools(servers), IsNil)
+	// pool labels take precedence over the node labels
+	compare.DeepCompare(c, servers[0].Labels, map[string]string{
+		"accelerator":                    "nvidia",
+		"rack":                           "r1",
+		defaults.KubernetesNodePoolLabel: "gpu",
+	})
+
+	servers = []storage.Server{
+		{Hostname: "node-1", Role: "knode", NodePool: "gpu"},
+		{Hostname: "node-2", Role: "knode", NodePool: "gpu"},
+	}
+	err = cluster.validateNodePools(servers)
+	c.Assert(trace.IsLimitExceeded(err), Equals, true, Commentf("%v", err))
+
+	servers = []storage.Server{{Hostname: "node-1", Role: "master", NodePool: "gpu"}}
+	err = cluster.validateNodePools(servers)
+	c.Assert(trace.IsBadParameter(err), Equals, true, Commentf("%v", err))
+
+	servers = []storage.Server{{Hostname: "node-1", Role: "knode", NodePool: "unknown"}}
+	err = cluster.validateNodePools(servers)
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%v", err))
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	"github.com/pborman/uuid"
+)
+
+// CreateUpdateNodeRoleOperation creates a new operation to promote a regular node
+// to master or demote a master to a regular node
+func (o *Operator) CreateUpdateNodeRoleOperation(ctx context.Context, req ops.CreateUpdateNodeRoleOperationRequest) (*ops.SiteOperationKey, error) {
+	if err := req.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	cluster, err := o.openSite(req.ClusterKey)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	existing, err := o.getIdempotentOperation(ctx, req.ClusterKey, ops.OperationUpdateNodeRole)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if existing != nil {
+		return existing, nil
+	}
+	server, err := cluster.validateNodeRoleChange(req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	op := ops.SiteOperation{
+		ID:             uuid.New(),
+		AccountID:      cluster.key.AccountID,
+		SiteDomain:     cluster.key.SiteDomain,
+		Type:           ops.OperationUpdateNodeRole,
+		Created:        cluster.clock().UtcNow(),
+		CreatedBy:      storage.UserFromContext(ctx),
+		IdempotencyKey: ops.IdempotencyKeyFromContext(ctx),
+		Updated:        cluster.clock().UtcNow(),
+		State:          ops.OperationUpdateNodeRoleInProgress,
+		UpdateNodeRole: &storage.UpdateNodeRoleOperationState{
+			Server:      *server,
+			ClusterRole: req.ClusterRole,
+		},
+	}
+	key, err := cluster.getOperationGroup().createSiteOperation(op)
+	if existing, ok := existingOperation(err); ok {
+		return existing, nil
+	}
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return key, nil
+}
+
+// validateNodeRoleChange verifies that the node from the specified request
+// can be assigned the requested cluster role and returns the node
+func (s *site) validateNodeRoleChange(req ops.CreateUpdateNodeRoleOperationRequest) (*storage.Server, error) {
+	servers := s.servers()
+	var server *storage.Server
+	var masters int
+	for i := range servers {
+		if servers[i].Hostname == req.Server || servers[i].AdvertiseIP == req.Server {
+			server = &servers[i]
+		}
+		if servers[i].IsMaster() {
+			masters++
+		}
+	}
+	if server == nil {
+		return nil, trace.NotFound("node %v is not found in the cluster", req.Server)
+	}
+	if server.ClusterRole == req.ClusterRole {
+		return nil, trace.AlreadyExists("node %v already has cluster role %q",
+			server.Hostname, req.ClusterRole)
+	}
+	profile, err := s.app.Manifest.NodeProfiles.ByName(server.Role)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if profile.ServiceRole != "" && string(profile.ServiceRole) != req.ClusterRole {
+		return nil, trace.BadParameter("node profile %q requires cluster role %q",
+			profile.Name, profile.ServiceRole)
+	}
+	switch schema.ServiceRole(req.ClusterRole) {
+	case schema.ServiceRoleMaster:
+		if masters >= defaults.MaxMasterNodes {
+			return nil, trace.BadParameter("cluster already has the maximum of %v master nodes",
+				defaults.MaxMasterNodes)
+		}
+	case schema.ServiceRoleNode:
+		if masters <= 1 {
+			return nil, trace.BadParameter("node %v is the last master of the cluster and can't be demoted",
+				server.Hostname)
+		}
+	}
+	return server, nil
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/suite"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+type NodeRoleSuite struct {
+	operator *Operator
+	cluster  *ops.Site
+}
+
+var _ = Suite(&NodeRoleSuite{})
+
+func (s *NodeRoleSuite) SetUpTest(c *C) {
+	services := SetupTestServices(c)
+	s.operator = services.Operator
+	app := suite.SetUpTestPackage(c, services.Apps, services.Packages)
+
+	account, err := s.operator.CreateAccount(ops.NewAccountRequest{Org: "testing"})
+	c.Assert(err, IsNil)
+
+	s.cluster, err = s.operator.CreateSite(ops.NewSiteRequest{
+		AccountID:  account.ID,
+		AppPackage: app.String(),
+		Provider:   schema.ProvisionerOnPrem,
+		DomainName: "test.localdomain",
+	})
+	c.Assert(err, IsNil)
+}
+
+func (s *NodeRoleSuite) TestValidatesPromotion(c *C) {
+	cluster := s.withServers(c,
+		storage.Server{Hostname: "master-1", AdvertiseIP: "10.0.0.1", Role: "kmaster", ClusterRole: "master"},
+		storage.Server{Hostname: "node-1", AdvertiseIP: "10.0.0.2", Role: "node", ClusterRole: "node"},
+		storage.Server{Hostname: "node-2", AdvertiseIP: "10.0.0.3", Role: "knode", ClusterRole: "node"},
+	)
+
+	server, err := cluster.validateNodeRoleChange(promote("10.0.0.2"))
+	c.Assert(err, IsNil)
+	c.Assert(server.Hostname, Equals, "node-1")
+
+	_, err = cluster.validateNodeRoleChange(promote("node-2"))
+	c.Assert(trace.IsBadParameter(err), Equals, true, Commentf("%v", err))
+
+	_, err = cluster.validateNodeRoleChange(promote("master-1"))
+	c.Assert(trace.IsAlreadyExists(err), Equals, true, Commentf("%v", err))
+
+	_, err = cluster.validateNodeRoleChange(promote("node-3"))
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%v", err))
+}
+
+func (s *NodeRoleSuite) TestValidatesMasterCount(c *C) {
+	cluster := s.withServers(c,
+		storage.Server{Hostname: "master-1", AdvertiseIP: "10.0.0.1", Role: "node", ClusterRole: "master"},
+		storage.Server{Hostname: "node-1", AdvertiseIP: "10.0.0.2", Role: "node", ClusterRole: "node"},
+	)
+	_, err := cluster.validateNodeRoleChange(demote("master-1"))
+	c.Assert(trace.IsBadParameter(err), Equals, true, Commentf("%v", err))
+
+	cluster = s.withServers(c,
+		storage.Server{Hostname: "master-1", AdvertiseIP: "10.0.0.1", Role: "node", ClusterRole: "master"},
+		storage.Server{Hostname: "master-2", AdvertiseIP: "10.0.0.2", Role: "node", ClusterRole: "master"},
+		storage.Server{Hostname: "master-3", AdvertiseIP: "10.0.0.3", Role: "node", ClusterRole: "master"},
+		storage.Server{Hostname: "node-1", AdvertiseIP: "10.0.0.4", Role: "node", ClusterRole: "node"},
+	)
+	_, err = cluster.validateNodeRoleChange(promote("node-1"))
+	c.Assert(trace.IsBadParameter(err), Equals, true, Commentf("%v", err))
+
+	server, err := cluster.validateNodeRoleChange(demote("master-2"))
+	c.Assert(err, IsNil)
+	c.Assert(server.AdvertiseIP, Equals, "10.0.0.2")
+}
+
+func (s *NodeRoleSuite) withServers(c *C, servers ...storage.Server) *site {
+	backend := s.operator.backend()
+	cluster, err := backend.GetSite(s.cluster.Domain)
+	c.Assert(err, IsNil)
+	cluster.ClusterState.Servers = servers
+	_, err = backend.UpdateSite(*cluster)
+	c.Assert(err, IsNil)
+	result, err := s.operator.openSite(s.cluster.Key())
+	c.Assert(err, IsNil)
+	return result
+}
+
+func promote(server string) ops.CreateUpdateNodeRoleOperationRequest {
+	return ops.CreateUpdateNodeRoleOperationRequest{
+		Server:      server,
+		ClusterRole: string(schema.ServiceRoleMaster),
+	}
+}
+
+func demote(server string) ops.CreateUpdateNodeRoleOperationRequest {
+	return ops.CreateUpdateNodeRoleOperationRequest{
+		Server:      server,
+		ClusterRole: string(schema.ServiceRoleNode),
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/events"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+)
+
+// GetOperationApprovalPolicy returns the operation approval policy
+func (o *Operator) GetOperationApprovalPolicy(key ops.SiteKey) (storage.OperationApprovalPolicy, error) {
+	policy, err := o.backend().GetOperationApprovalPolicy(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return policy, nil
+}
+
+// UpsertOperationApprovalPolicy creates or updates the operation approval policy.
+// The policy applies to the operations created after it has been updated
+func (o *Operator) UpsertOperationApprovalPolicy(ctx context.Context, key ops.SiteKey, policy storage.OperationApprovalPolicy) error {
+	if err := policy.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	for _, operationType := range policy.GetOperations() {
+		if !utils.StringInSlice(ops.ApprovableOperations, operationType) {
+			return trace.BadParameter("operation %q cannot require approval, supported operations are: %v",
+				operationType, ops.ApprovableOperations)
+		}
+	}
+	if err := o.backend().UpsertOperationApprovalPolicy(key.SiteDomain, policy); err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.OperationApprovalPolicyUpdated, events.Fields{
+		events.FieldCount: len(policy.GetOperations()),
+	})
+	return nil
+}
+
+// DeleteOperationApprovalPolicy deletes the operation approval policy
+func (o *Operator) DeleteOperationApprovalPolicy(ctx context.Context, key ops.SiteKey) error {
+	if err := o.backend().DeleteOperationApprovalPolicy(key.SiteDomain); err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.OperationApprovalPolicyDeleted)
+	return nil
+}
+
+// ApproveOperation approves the specified operation on behalf of the user
+// attached to the context. The approving user has to be different from
+// the user who created the operation and has to be listed as an approver
+// if the policy restricts the approvers.
+//
+// Agent users can not approve operations: gravity commands run on the
+// cluster nodes all share the cluster agent identity, so the operation
+// has to be approved by a named user, e.g. from the web UI or after
+// logging in with 'gravity ops connect'.
+//
+// The shrink operation is executed by the cluster controller and is started
+// once it has been approved, other operations check the approval
+// before executing their phases
+func (o *Operator) ApproveOperation(ctx context.Context, key ops.SiteOperationKey) error {
+	site, err := o.openSite(key.SiteKey())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	operation, err := site.getSiteOperation(key.OperationID)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if operation.Approval == nil {
+		return trace.BadParameter("operation %v does not require approval", key.OperationID)
+	}
+	if operation.Approval.IsApproved() {
+		return trace.AlreadyExists("operation %v has already been approved by %v",
+			key.OperationID, operation.Approval.ApprovedBy)
+	}
+	if operation.IsFinished() {
+		return trace.BadParameter("operation %v has already finished", key.OperationID)
+	}
+	username := storage.UserFromContext(ctx)
+	if username == "" {
+		return trace.AccessDenied("failed to determine the user approving the operation")
+	}
+	if username == operation.CreatedBy {
+		return trace.AccessDenied("operation %v has to be approved by a user other than %v who created it",
+			key.OperationID, operation.CreatedBy)
+	}
+	user, err := o.users().GetTelekubeUser(username)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if user.GetType() == storage.AgentUser {
+		return trace.AccessDenied("operation %v can not be approved by the cluster agent %v: "+
+			"gravity commands run on the cluster nodes share the agent identity, "+
+			"approve the operation as a named user from the web UI or after logging in "+
+			"with 'gravity ops connect'", key.OperationID, username)
+	}
+	policy, err := o.backend().GetOperationApprovalPolicy(key.SiteDomain)
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	if policy != nil && !policy.CanApprove(user) {
+		return trace.AccessDenied("user %v is not allowed to approve operations", username)
+	}
+	operation.Approval.ApprovedBy = username
+	operation.Approval.Approved = o.clock().UtcNow()
+	if _, err := site.updateSiteOperation(operation); err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.OperationApproved, events.Fields{
+		events.FieldOperationID:   operation.ID,
+		events.FieldOperationType: operation.Type,
+	})
+	if operation.Type == ops.OperationShrink {
+		return trace.Wrap(site.executeOperation(key, site.shrinkOperationStart))
+	}
+	return nil
+}
+
+// requireApproval marks the specified operation as requiring approval
+// if the operation approval policy of the cluster lists its type
+func (o *Operator) requireApproval(operation *ops.SiteOperation) error {
+	policy, err := o.backend().GetOperationApprovalPolicy(operation.SiteDomain)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil
+		}
+		return trace.Wrap(err)
+	}
+	if policy.Matches(operation.Type) {
+		operation.Approval = &storage.OperationApproval{}
+	}
+	return nil
+}
@@ -63,36 +63,74 @@ func (s swap) Check() error {
 	return nil
 }
 
-// createSiteOperation creates the provided operation if the checks allow it to be created
+// createSiteOperation creates the provided operation if the checks allow it to be created.
+// If the operation has an idempotency key and an operation with the same key already
+// exists, returns an AlreadyExists error with the key of the existing operation
+// which can be extracted with existingOperation
 func (g *operationGroup) createSiteOperation(operation ops.SiteOperation) (*ops.SiteOperationKey, error) {
 	g.Lock()
 	defer g.Unlock()
 
+	if operation.IdempotencyKey != "" {
+		operations, err := g.operator.backend().GetSiteOperations(g.siteKey.SiteDomain)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		existing, err := findIdempotentOperation(operations, operation.CreatedBy, operation.IdempotencyKey, operation.Type)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if existing != nil {
+			return nil, errOperationExists(*existing, operation.IdempotencyKey)
+		}
+	}
+
 	err := g.canCreateOperation(operation)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
 
-	site, err := g.operator.openSite(g.siteKey)
+	nested, err := g.isPartOfNodeReplacement(operation)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
 
-	op, err := site.createSiteOperation(&operation)
-	if err != nil {
-		return nil, trace.Wrap(err)
+	// operations started by the node replacement have been reviewed
+	// and approved as part of it
+	if !nested {
+		err = g.operator.reviewOperation(operation)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		err = g.operator.requireApproval(&operation)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
 	}
 
-	state, err := operation.ClusterState()
+	site, err := g.operator.openSite(g.siteKey)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
 
-	err = site.setSiteState(state)
+	op, err := site.createSiteOperation(&operation)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
 
+	// operations started by the node replacement keep the cluster state
+	if !nested {
+		state, err := operation.ClusterState()
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+
+		err = site.setSiteState(state)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+
 	err = g.emitAuditEvent(context.TODO(), *op)
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -105,7 +143,8 @@ func (g *operationGroup) createSiteOperation(operation ops.SiteOperation) (*ops.
 func (g *operationGroup) emitAuditEvent(ctx context.Context, operation ops.SiteOperation) error {
 	// Audit events for the following operations are emitted by their agents.
 	switch operation.Type {
-	case ops.OperationInstall, ops.OperationUpdate, ops.OperationUpdateConfig, ops.OperationUpdateRuntimeEnviron:
+	case ops.OperationInstall, ops.OperationUpdate, ops.OperationUpdateConfig, ops.OperationUpdateRuntimeEnviron,
+		ops.OperationUpdateNodeRole:
 		return nil
 	}
 	// Expand operation start event is emitted by the joining agent.
@@ -134,10 +173,17 @@ func (g *operationGroup) canCreateOperation(operation ops.SiteOperation) error {
 		if err != nil {
 			return trace.Wrap(err)
 		}
-	case ops.OperationShrink, ops.OperationGarbageCollect, ops.OperationUpdateRuntimeEnviron:
-		// shrink, gc and updating environment are allowed for degraded clusters
+	case ops.OperationShrink, ops.OperationGarbageCollect, ops.OperationUpdateRuntimeEnviron,
+		ops.OperationUpdateNodeRole, ops.OperationReplaceNode, ops.OperationRotateCertificates:
+		// shrink, gc, updating environment, promoting and replacing nodes are allowed for degraded clusters.
+		// Certificate rotation is allowed as the cluster is degraded when certificates are about to expire
 		switch cluster.State {
 		case ops.SiteStateActive, ops.SiteStateDegraded:
+		case ops.SiteStateReplacingNode:
+			// the node replacement removes the replaced node with a shrink operation
+			if operation.Type != ops.OperationShrink {
+				return trace.CompareFailed("the cluster is %v", cluster.State)
+			}
 		default:
 			return trace.CompareFailed("the cluster is %v", cluster.State)
 		}
@@ -167,6 +213,14 @@ func (g *operationGroup) canCreateExpandOperation(site ops.Site, operation ops.S
 		return trace.Wrap(err)
 	}
 
+	// the node replacement joins the replacement node with an expand operation
+	if site.State == ops.SiteStateReplacingNode {
+		if len(operations) != 0 {
+			return trace.CompareFailed("can't launch another expand while the replacement node is joining")
+		}
+		return nil
+	}
+
 	// cluster is not active, but there are no expand operations so there is either
 	// other type of operation is in progress, or it's degraded
 	if len(operations) == 0 {
@@ -281,6 +335,16 @@ func (g *operationGroup) onSiteOperationComplete(key ops.SiteOperationKey) error
 		return nil
 	}
 
+	nested, err := g.isPartOfNodeReplacement(*operation)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	if nested {
+		log.Debugf("Node replacement in progress for %v, keep cluster state.", key.SiteDomain)
+		return nil
+	}
+
 	site, err := g.operator.openSite(g.siteKey)
 	if err != nil {
 		return trace.Wrap(err)
@@ -299,6 +363,21 @@ func (g *operationGroup) onSiteOperationComplete(key ops.SiteOperationKey) error
 	return nil
 }
 
+// isPartOfNodeReplacement returns true if the provided operation
+// is started by the node replacement operation in progress
+func (g *operationGroup) isPartOfNodeReplacement(operation ops.SiteOperation) (bool, error) {
+	switch operation.Type {
+	case ops.OperationExpand, ops.OperationShrink:
+	default:
+		return false, nil
+	}
+	operations, err := ops.GetActiveOperationsByType(g.siteKey, g.operator, ops.OperationReplaceNode)
+	if err != nil && !trace.IsNotFound(err) {
+		return false, trace.Wrap(err)
+	}
+	return len(operations) != 0, nil
+}
+
 // addClusterStateServers adds the provided servers to the cluster state
 func (g *operationGroup) addClusterStateServers(servers []storage.Server) error {
 	g.Lock()
@@ -17,14 +17,21 @@ limitations under the License.
 package opsservice
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
 
+	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/ops"
 	"github.com/gravitational/gravity/lib/ops/suite"
 	"github.com/gravitational/gravity/lib/schema"
 	"github.com/gravitational/gravity/lib/storage"
 
+	"github.com/gravitational/trace"
 	"gopkg.in/check.v1"
 )
 
@@ -287,6 +294,359 @@ func (s *OperationGroupSuite) TestClusterStateModifications(c *check.C) {
 	s.assertServerCount(c, 2)
 }
 
+// Makes sure retried requests with the same idempotency key return the original operation
+func (s *OperationGroupSuite) TestCreatesOperationOncePerIdempotencyKey(c *check.C) {
+	group := s.operator.getOperationGroup(s.cluster.Key())
+
+	// initiate and finalize the install operation
+	key, err := group.createSiteOperation(ops.SiteOperation{
+		AccountID:  s.cluster.AccountID,
+		SiteDomain: s.cluster.Domain,
+		Type:       ops.OperationInstall,
+		State:      ops.OperationStateInstallInitiated,
+	})
+	c.Assert(err, check.IsNil)
+	_, err = group.compareAndSwapOperationState(swap{
+		key:            *key,
+		expectedStates: []string{ops.OperationStateInstallInitiated},
+		newOpState:     ops.OperationStateCompleted,
+	})
+	c.Assert(err, check.IsNil)
+	err = s.operator.CreateProgressEntry(*key, ops.ProgressEntry{
+		SiteDomain:  key.SiteDomain,
+		OperationID: key.OperationID,
+		Completion:  constants.Completed,
+		State:       ops.ProgressStateCompleted,
+		Created:     time.Now(),
+	})
+	c.Assert(err, check.IsNil)
+	s.assertClusterState(c, ops.SiteStateActive)
+
+	ctx := ops.WithIdempotencyKey(context.TODO(), "gc-1")
+	req := ops.CreateClusterGarbageCollectOperationRequest{
+		AccountID:   s.cluster.AccountID,
+		ClusterName: s.cluster.Domain,
+	}
+	first, err := s.operator.CreateClusterGarbageCollectOperation(ctx, req)
+	c.Assert(err, check.IsNil)
+	s.assertClusterState(c, ops.SiteStateGarbageCollecting)
+
+	// the cluster is busy so only the retried request succeeds
+	second, err := s.operator.CreateClusterGarbageCollectOperation(ctx, req)
+	c.Assert(err, check.IsNil)
+	c.Assert(*second, check.DeepEquals, *first)
+	_, err = s.operator.CreateClusterGarbageCollectOperation(context.TODO(), req)
+	c.Assert(trace.IsCompareFailed(err), check.Equals, true, check.Commentf("%v", err))
+
+	// the key can not be reused for a different operation
+	_, err = group.createSiteOperation(ops.SiteOperation{
+		AccountID:      s.cluster.AccountID,
+		SiteDomain:     s.cluster.Domain,
+		Type:           ops.OperationShrink,
+		State:          ops.OperationStateShrinkInProgress,
+		IdempotencyKey: "gc-1",
+	})
+	c.Assert(trace.IsAlreadyExists(err), check.Equals, true, check.Commentf("%v", err))
+
+	operations, err := s.operator.GetSiteOperations(s.cluster.Key())
+	c.Assert(err, check.IsNil)
+	c.Assert(operations, check.HasLen, 2)
+}
+
+// Makes sure concurrent retries with the same idempotency key create a single
+// operation and only one of them is reported as having created it
+func (s *OperationGroupSuite) TestConcurrentRetriesCreateOperationOnce(c *check.C) {
+	group := s.operator.getOperationGroup(s.cluster.Key())
+	s.completeInstall(c, group)
+
+	const retries = 5
+	type result struct {
+		key *ops.SiteOperationKey
+		err error
+	}
+	resultsC := make(chan result, retries)
+	for i := 0; i < retries; i++ {
+		go func() {
+			// bypass the idempotency check of the operator to simulate
+			// the retries racing past it
+			key, err := group.createSiteOperation(ops.SiteOperation{
+				AccountID:      s.cluster.AccountID,
+				SiteDomain:     s.cluster.Domain,
+				Type:           ops.OperationGarbageCollect,
+				State:          ops.OperationGarbageCollectInProgress,
+				IdempotencyKey: "gc-1",
+			})
+			resultsC <- result{key: key, err: err}
+		}()
+	}
+	var created, existing []ops.SiteOperationKey
+	for i := 0; i < retries; i++ {
+		result := <-resultsC
+		if result.err == nil {
+			created = append(created, *result.key)
+			continue
+		}
+		c.Assert(trace.IsAlreadyExists(result.err), check.Equals, true, check.Commentf("%v", result.err))
+		key, ok := existingOperation(result.err)
+		c.Assert(ok, check.Equals, true)
+		existing = append(existing, *key)
+	}
+	c.Assert(created, check.HasLen, 1)
+	for _, key := range existing {
+		c.Assert(key, check.DeepEquals, created[0])
+	}
+
+	// retries through the operator return the original operation
+	key, err := s.operator.CreateClusterGarbageCollectOperation(
+		ops.WithIdempotencyKey(context.TODO(), "gc-1"),
+		ops.CreateClusterGarbageCollectOperationRequest{
+			AccountID:   s.cluster.AccountID,
+			ClusterName: s.cluster.Domain,
+		})
+	c.Assert(err, check.IsNil)
+	c.Assert(*key, check.DeepEquals, created[0])
+
+	operations, err := s.operator.GetSiteOperations(s.cluster.Key())
+	c.Assert(err, check.IsNil)
+	c.Assert(operations, check.HasLen, 2)
+}
+
+// Makes sure an idempotency key only matches the operations created by the same user
+func (s *OperationGroupSuite) TestScopesIdempotencyKeyToUser(c *check.C) {
+	group := s.operator.getOperationGroup(s.cluster.Key())
+	s.completeInstall(c, group)
+
+	req := ops.CreateClusterGarbageCollectOperationRequest{
+		AccountID:   s.cluster.AccountID,
+		ClusterName: s.cluster.Domain,
+	}
+	first, err := s.operator.CreateClusterGarbageCollectOperation(
+		ops.WithIdempotencyKey(withUser("alice@example.com"), "gc-1"), req)
+	c.Assert(err, check.IsNil)
+
+	// the cluster is busy so the request of another user with the same key fails
+	// instead of returning the operation created by the first user
+	_, err = s.operator.CreateClusterGarbageCollectOperation(
+		ops.WithIdempotencyKey(withUser("bob@example.com"), "gc-1"), req)
+	c.Assert(trace.IsCompareFailed(err), check.Equals, true, check.Commentf("%v", err))
+
+	second, err := s.operator.CreateClusterGarbageCollectOperation(
+		ops.WithIdempotencyKey(withUser("alice@example.com"), "gc-1"), req)
+	c.Assert(err, check.IsNil)
+	c.Assert(*second, check.DeepEquals, *first)
+
+	operations, err := s.operator.backend().GetSiteOperations(s.cluster.Domain)
+	c.Assert(err, check.IsNil)
+	existing, err := findIdempotentOperation(operations, "bob@example.com", "gc-1", ops.OperationShrink)
+	c.Assert(err, check.IsNil, check.Commentf("Key of another user does not conflict."))
+	c.Assert(existing, check.IsNil)
+}
+
+func (s *OperationGroupSuite) TestReviewsOperationsWithWebhooks(c *check.C) {
+	var reviewed []string
+	allowed := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var review ops.OperationReview
+		c.Assert(json.NewDecoder(r.Body).Decode(&review), check.IsNil)
+		reviewed = append(reviewed, review.Operation.Type)
+		response := ops.OperationReviewResponse{Allowed: allowed}
+		if !allowed {
+			response.Reason = "cluster is frozen"
+		}
+		c.Assert(json.NewEncoder(w).Encode(response), check.IsNil)
+	}))
+	defer server.Close()
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	ctx := context.TODO()
+	err := s.operator.UpsertOperationWebhook(ctx, s.cluster.Key(),
+		storage.NewOperationWebhook("freeze", storage.OperationWebhookSpecV2{
+			URL: server.URL,
+		}))
+	c.Assert(err, check.IsNil)
+	err = s.operator.UpsertOperationWebhook(ctx, s.cluster.Key(),
+		storage.NewOperationWebhook("optional", storage.OperationWebhookSpecV2{
+			URL:           unreachable.URL,
+			FailurePolicy: storage.WebhookFailurePolicyIgnore,
+		}))
+	c.Assert(err, check.IsNil)
+
+	// install operations are not reviewed
+	group := s.operator.getOperationGroup(s.cluster.Key())
+	key, err := group.createSiteOperation(ops.SiteOperation{
+		AccountID:  s.cluster.AccountID,
+		SiteDomain: s.cluster.Domain,
+		Type:       ops.OperationInstall,
+		State:      ops.OperationStateInstallInitiated,
+	})
+	c.Assert(err, check.IsNil)
+	_, err = group.compareAndSwapOperationState(swap{
+		key:            *key,
+		expectedStates: []string{ops.OperationStateInstallInitiated},
+		newOpState:     ops.OperationStateCompleted,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(reviewed, check.HasLen, 0)
+
+	shrink := ops.SiteOperation{
+		AccountID:  s.cluster.AccountID,
+		SiteDomain: s.cluster.Domain,
+		Type:       ops.OperationShrink,
+		State:      ops.OperationStateShrinkInProgress,
+	}
+	_, err = group.createSiteOperation(shrink)
+	c.Assert(trace.IsAccessDenied(err), check.Equals, true, check.Commentf("%v", err))
+	c.Assert(err.Error(), check.Matches, ".*cluster is frozen.*")
+	s.assertClusterState(c, ops.SiteStateActive)
+
+	allowed = true
+	_, err = group.createSiteOperation(shrink)
+	c.Assert(err, check.IsNil)
+	s.assertClusterState(c, ops.SiteStateShrinking)
+	c.Assert(reviewed, check.DeepEquals, []string{ops.OperationShrink, ops.OperationShrink})
+
+	operations, err := s.operator.GetSiteOperations(s.cluster.Key())
+	c.Assert(err, check.IsNil)
+	c.Assert(operations, check.HasLen, 2)
+}
+
+func (s *OperationGroupSuite) TestRequiresApproval(c *check.C) {
+	err := s.operator.UpsertOperationApprovalPolicy(context.TODO(), s.cluster.Key(),
+		storage.NewOperationApprovalPolicy(storage.OperationApprovalPolicySpecV2{
+			Operations: []string{ops.OperationRotateCertificates},
+			Approvers:  []string{"alice@example.com", "bob@example.com"},
+		}))
+	c.Assert(err, check.IsNil)
+	s.upsertUser(c, "alice@example.com", storage.AdminUser)
+	s.upsertUser(c, "bob@example.com", storage.AdminUser)
+	s.upsertUser(c, "eve@example.com", storage.AdminUser)
+
+	// install operations never require approval
+	group := s.operator.getOperationGroup(s.cluster.Key())
+	key, err := group.createSiteOperation(ops.SiteOperation{
+		AccountID:  s.cluster.AccountID,
+		SiteDomain: s.cluster.Domain,
+		Type:       ops.OperationInstall,
+		State:      ops.OperationStateInstallInitiated,
+	})
+	c.Assert(err, check.IsNil)
+	_, err = group.compareAndSwapOperationState(swap{
+		key:            *key,
+		expectedStates: []string{ops.OperationStateInstallInitiated},
+		newOpState:     ops.OperationStateCompleted,
+	})
+	c.Assert(err, check.IsNil)
+	err = s.operator.ApproveOperation(withUser("bob@example.com"), *key)
+	c.Assert(trace.IsBadParameter(err), check.Equals, true, check.Commentf("%v", err))
+
+	key, err = group.createSiteOperation(ops.SiteOperation{
+		AccountID:  s.cluster.AccountID,
+		SiteDomain: s.cluster.Domain,
+		Type:       ops.OperationRotateCertificates,
+		State:      ops.OperationRotateCertificatesInProgress,
+		CreatedBy:  "alice@example.com",
+	})
+	c.Assert(err, check.IsNil)
+	operation, err := s.operator.GetSiteOperation(*key)
+	c.Assert(err, check.IsNil)
+	c.Assert(operation.IsPendingApproval(), check.Equals, true)
+	c.Assert(trace.IsAccessDenied(operation.CheckApproved()), check.Equals, true)
+
+	err = s.operator.ApproveOperation(withUser("alice@example.com"), *key)
+	c.Assert(trace.IsAccessDenied(err), check.Equals, true, check.Commentf("%v", err))
+	err = s.operator.ApproveOperation(withUser("eve@example.com"), *key)
+	c.Assert(trace.IsAccessDenied(err), check.Equals, true, check.Commentf("%v", err))
+
+	err = s.operator.ApproveOperation(withUser("bob@example.com"), *key)
+	c.Assert(err, check.IsNil)
+	operation, err = s.operator.GetSiteOperation(*key)
+	c.Assert(err, check.IsNil)
+	c.Assert(operation.CheckApproved(), check.IsNil)
+	c.Assert(operation.Approval.ApprovedBy, check.Equals, "bob@example.com")
+
+	err = s.operator.ApproveOperation(withUser("bob@example.com"), *key)
+	c.Assert(trace.IsAlreadyExists(err), check.Equals, true, check.Commentf("%v", err))
+}
+
+func (s *OperationGroupSuite) TestAgentsCanNotApprove(c *check.C) {
+	err := s.operator.UpsertOperationApprovalPolicy(context.TODO(), s.cluster.Key(),
+		storage.NewOperationApprovalPolicy(storage.OperationApprovalPolicySpecV2{
+			Operations: []string{ops.OperationRotateCertificates},
+		}))
+	c.Assert(err, check.IsNil)
+	agent := storage.ClusterAdminAgent(s.cluster.Domain)
+	s.upsertUser(c, agent, storage.AgentUser)
+	s.upsertUser(c, "alice@example.com", storage.AdminUser)
+	s.upsertUser(c, "bob@example.com", storage.AdminUser)
+
+	group := s.operator.getOperationGroup(s.cluster.Key())
+	key, err := group.createSiteOperation(ops.SiteOperation{
+		AccountID:  s.cluster.AccountID,
+		SiteDomain: s.cluster.Domain,
+		Type:       ops.OperationInstall,
+		State:      ops.OperationStateInstallInitiated,
+	})
+	c.Assert(err, check.IsNil)
+	_, err = group.compareAndSwapOperationState(swap{
+		key:            *key,
+		expectedStates: []string{ops.OperationStateInstallInitiated},
+		newOpState:     ops.OperationStateCompleted,
+	})
+	c.Assert(err, check.IsNil)
+
+	// node-local gravity commands share the agent identity so it does not
+	// identify a second operator even if the policy does not list approvers
+	key, err = group.createSiteOperation(ops.SiteOperation{
+		AccountID:  s.cluster.AccountID,
+		SiteDomain: s.cluster.Domain,
+		Type:       ops.OperationRotateCertificates,
+		State:      ops.OperationRotateCertificatesInProgress,
+		CreatedBy:  "alice@example.com",
+	})
+	c.Assert(err, check.IsNil)
+	err = s.operator.ApproveOperation(withUser(agent), *key)
+	c.Assert(trace.IsAccessDenied(err), check.Equals, true, check.Commentf("%v", err))
+
+	err = s.operator.ApproveOperation(withUser("bob@example.com"), *key)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *OperationGroupSuite) upsertUser(c *check.C, name, userType string) {
+	err := s.operator.users().UpsertUser(storage.NewUser(name, storage.UserSpecV2{
+		Type:        userType,
+		Password:    "password",
+		ClusterName: s.cluster.Domain,
+	}))
+	c.Assert(err, check.IsNil)
+}
+
+// completeInstall creates and completes the install operation of the cluster
+func (s *OperationGroupSuite) completeInstall(c *check.C, group *operationGroup) {
+	key, err := group.createSiteOperation(ops.SiteOperation{
+		AccountID:  s.cluster.AccountID,
+		SiteDomain: s.cluster.Domain,
+		Type:       ops.OperationInstall,
+		State:      ops.OperationStateInstallInitiated,
+	})
+	c.Assert(err, check.IsNil)
+	_, err = group.compareAndSwapOperationState(swap{
+		key:            *key,
+		expectedStates: []string{ops.OperationStateInstallInitiated},
+		newOpState:     ops.OperationStateCompleted,
+	})
+	c.Assert(err, check.IsNil)
+	err = s.operator.CreateProgressEntry(*key, ops.ProgressEntry{
+		SiteDomain:  key.SiteDomain,
+		OperationID: key.OperationID,
+		Completion:  constants.Completed,
+		State:       ops.ProgressStateCompleted,
+		Created:     time.Now(),
+	})
+	c.Assert(err, check.IsNil)
+	s.assertClusterState(c, ops.SiteStateActive)
+}
+
 func (s *OperationGroupSuite) assertClusterState(c *check.C, state string) {
 	cluster, err := s.operator.GetSite(s.cluster.Key())
 	c.Assert(err, check.IsNil)
@@ -298,3 +658,7 @@ func (s *OperationGroupSuite) assertServerCount(c *check.C, count int) {
 	c.Assert(err, check.IsNil)
 	c.Assert(len(cluster.ClusterState.Servers), check.Equals, count)
 }
+
+func withUser(user string) context.Context {
+	return context.WithValue(context.TODO(), constants.UserContext, user)
+}
@@ -33,9 +33,10 @@ func (o *Operator) CreateOperationPlan(key ops.SiteOperationKey, plan storage.Op
 	return nil
 }
 
-// CreateOperationPlanChange creates a new changelog entry for a plan
+// CreateOperationPlanChange creates a new changelog entry for a plan.
+// The entry is recorded after all changes already in the changelog
 func (o *Operator) CreateOperationPlanChange(key ops.SiteOperationKey, change storage.PlanChange) error {
-	_, err := o.backend().CreateOperationPlanChange(change)
+	_, err := storage.AppendOperationPlanChange(o.backend(), change)
 	if err != nil {
 		return trace.Wrap(err)
 	}
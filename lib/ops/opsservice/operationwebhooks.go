@@ -0,0 +1,132 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gravitational/gravity/lib/httplib"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/events"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// GetOperationWebhooks returns the list of operation webhooks of the cluster
+func (o *Operator) GetOperationWebhooks(key ops.SiteKey) ([]storage.OperationWebhook, error) {
+	webhooks, err := o.backend().GetOperationWebhooks(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return webhooks, nil
+}
+
+// UpsertOperationWebhook creates or updates an operation webhook
+func (o *Operator) UpsertOperationWebhook(ctx context.Context, key ops.SiteKey, webhook storage.OperationWebhook) error {
+	if err := webhook.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := o.backend().UpsertOperationWebhook(key.SiteDomain, webhook); err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.OperationWebhookCreated, events.Fields{
+		events.FieldName: webhook.GetName(),
+	})
+	return nil
+}
+
+// DeleteOperationWebhook deletes the operation webhook with the specified name
+func (o *Operator) DeleteOperationWebhook(ctx context.Context, key ops.SiteKey, name string) error {
+	if err := o.backend().DeleteOperationWebhook(key.SiteDomain, name); err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.OperationWebhookDeleted, events.Fields{
+		events.FieldName: name,
+	})
+	return nil
+}
+
+// reviewOperation posts the operation to the cluster webhooks matching
+// its type and returns AccessDenied if any of them rejects it.
+//
+// Install operations are not reviewed since the webhooks are configured
+// in the installed cluster
+func (o *Operator) reviewOperation(operation ops.SiteOperation) error {
+	if operation.Type == ops.OperationInstall {
+		return nil
+	}
+	webhooks, err := o.backend().GetOperationWebhooks(operation.SiteDomain)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	for _, webhook := range webhooks {
+		if !webhook.Matches(operation.Type) {
+			continue
+		}
+		response, err := callOperationWebhook(webhook, operation)
+		if err != nil {
+			if webhook.GetFailurePolicy() == storage.WebhookFailurePolicyIgnore {
+				log.WithError(err).Warnf("Operation webhook %v failed, ignoring.", webhook.GetName())
+				continue
+			}
+			return trace.AccessDenied("failed to review %v operation with webhook %v: %v",
+				operation.Type, webhook.GetName(), err)
+		}
+		if !response.Allowed {
+			return trace.AccessDenied("%v operation rejected by webhook %v: %v",
+				operation.Type, webhook.GetName(), response.Reason)
+		}
+	}
+	return nil
+}
+
+// callOperationWebhook posts the operation to the specified webhook
+// and returns its response
+func callOperationWebhook(webhook storage.OperationWebhook, operation ops.SiteOperation) (*ops.OperationReviewResponse, error) {
+	data, err := json.Marshal(ops.OperationReview{Operation: operation})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	options := []httplib.ClientOption{httplib.WithTimeout(webhook.GetTimeout())}
+	if webhook.GetCACert() != "" {
+		options = append(options, httplib.WithCA([]byte(webhook.GetCACert())))
+	}
+	client := httplib.GetClient(false, options...)
+	resp, err := client.Post(webhook.GetURL(), "application/json", bytes.NewReader(data))
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, trace.BadParameter("webhook responded with %v: %s", resp.Status, body)
+	}
+	var response ops.OperationReviewResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, trace.BadParameter("invalid webhook response: %v", err)
+	}
+	return &response, nil
+}
@@ -33,7 +33,7 @@ func (p provisionedServers) InitialCluster(domain string) string {
 	members := make([]string, len(p))
 	for i, s := range p {
 		members[i] = fmt.Sprintf(
-			"%v:%v", s.EtcdMemberName(domain), s.AdvertiseIP)
+			"%v:%v", s.EtcdMemberName(domain), s.GetClusterAddr())
 	}
 	return strings.Join(members, ",")
 }
@@ -17,6 +17,8 @@ limitations under the License.
 package opsservice
 
 import (
+	"context"
+
 	"github.com/gravitational/gravity/lib/helm"
 	"github.com/gravitational/gravity/lib/ops"
 	"github.com/gravitational/gravity/lib/storage"
@@ -57,3 +59,25 @@ func (o *Operator) ListReleases(req ops.ListReleasesRequest) ([]storage.Release,
 	}
 	return releases, nil
 }
+
+// GetTrackedReleases returns the application releases recorded for the cluster
+func (o *Operator) GetTrackedReleases(key ops.SiteKey) ([]storage.Release, error) {
+	releases, err := o.backend().GetReleases(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return releases, nil
+}
+
+// TrackRelease records the state of the application release
+func (o *Operator) TrackRelease(ctx context.Context, key ops.SiteKey, release storage.Release) error {
+	if release.GetName() == "" {
+		return trace.BadParameter("release name is required")
+	}
+	return trace.Wrap(o.backend().UpsertRelease(key.SiteDomain, release))
+}
+
+// UntrackRelease removes the record of the release with the specified name
+func (o *Operator) UntrackRelease(ctx context.Context, key ops.SiteKey, name string) error {
+	return trace.Wrap(o.backend().DeleteRelease(key.SiteDomain, name))
+}
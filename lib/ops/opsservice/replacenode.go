@@ -0,0 +1,128 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	"github.com/pborman/uuid"
+)
+
+// CreateReplaceNodeOperation creates a new operation to replace an existing
+// cluster node with a new one
+func (o *Operator) CreateReplaceNodeOperation(ctx context.Context, req ops.CreateReplaceNodeOperationRequest) (*ops.SiteOperationKey, error) {
+	if err := req.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	cluster, err := o.openSite(req.ClusterKey)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	existing, err := o.getIdempotentOperation(ctx, req.ClusterKey, ops.OperationReplaceNode)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if existing != nil {
+		return existing, nil
+	}
+	server, err := cluster.validateNodeReplacement(req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if req.SSH != nil && req.SSH.Port == 0 {
+		req.SSH.Port = defaults.SSHPort
+	}
+	op := ops.SiteOperation{
+		ID:             uuid.New(),
+		AccountID:      cluster.key.AccountID,
+		SiteDomain:     cluster.key.SiteDomain,
+		Type:           ops.OperationReplaceNode,
+		Created:        cluster.clock().UtcNow(),
+		CreatedBy:      storage.UserFromContext(ctx),
+		IdempotencyKey: ops.IdempotencyKeyFromContext(ctx),
+		Updated:        cluster.clock().UtcNow(),
+		State:          ops.OperationReplaceNodeInProgress,
+		ReplaceNode: &storage.ReplaceNodeOperationState{
+			Server:  *server,
+			NewAddr: req.NewAddr,
+			SSH:     req.SSH,
+			Force:   req.Force,
+		},
+	}
+	key, err := cluster.getOperationGroup().createSiteOperation(op)
+	if existing, ok := existingOperation(err); ok {
+		return existing, nil
+	}
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return key, nil
+}
+
+// validateNodeReplacement verifies that the node from the specified request
+// can be replaced with the new node and returns the node
+func (s *site) validateNodeReplacement(req ops.CreateReplaceNodeOperationRequest) (*storage.Server, error) {
+	servers := s.servers()
+	var server *storage.Server
+	for i := range servers {
+		if servers[i].AdvertiseIP == req.NewAddr {
+			return nil, trace.AlreadyExists("node %v is already a member of the cluster",
+				req.NewAddr)
+		}
+		if servers[i].Hostname == req.Server || servers[i].AdvertiseIP == req.Server {
+			server = &servers[i]
+		}
+	}
+	if server == nil {
+		return nil, trace.NotFound("node %v is not found in the cluster", req.Server)
+	}
+	if len(servers) == 1 {
+		return nil, trace.BadParameter("can't replace the only node of the cluster")
+	}
+	return server, nil
+}
+
+// setReplacementClusterRoles assigns the cluster role of the replaced node
+// to the replacement node joining the cluster as a part of the node replacement
+func (s *site) setReplacementClusterRoles(servers []storage.Server) error {
+	operations, err := ops.GetActiveOperationsByType(s.key, s.service, ops.OperationReplaceNode)
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	for _, operation := range operations {
+		if operation.ReplaceNode == nil {
+			continue
+		}
+		replaced := operation.ReplaceNode.Server
+		for i, server := range servers {
+			if server.AdvertiseIP != operation.ReplaceNode.NewAddr {
+				continue
+			}
+			if server.Role != replaced.Role {
+				return trace.BadParameter("replacement node %v should join with role %q, not %q",
+					server.AdvertiseIP, replaced.Role, server.Role)
+			}
+			servers[i].ClusterRole = replaced.ClusterRole
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+func (s *NodeRoleSuite) TestValidatesReplacement(c *C) {
+	cluster := s.withServers(c,
+		storage.Server{Hostname: "master-1", AdvertiseIP: "10.0.0.1", Role: "node", ClusterRole: "master"},
+		storage.Server{Hostname: "node-1", AdvertiseIP: "10.0.0.2", Role: "node", ClusterRole: "node"},
+	)
+
+	server, err := cluster.validateNodeReplacement(replace("node-1", "10.0.0.3"))
+	c.Assert(err, IsNil)
+	c.Assert(server.AdvertiseIP, Equals, "10.0.0.2")
+
+	_, err = cluster.validateNodeReplacement(replace("node-1", "10.0.0.1"))
+	c.Assert(trace.IsAlreadyExists(err), Equals, true, Commentf("%v", err))
+
+	_, err = cluster.validateNodeReplacement(replace("node-2", "10.0.0.3"))
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%v", err))
+
+	cluster = s.withServers(c,
+		storage.Server{Hostname: "master-1", AdvertiseIP: "10.0.0.1", Role: "node", ClusterRole: "master"},
+	)
+	_, err = cluster.validateNodeReplacement(replace("master-1", "10.0.0.3"))
+	c.Assert(trace.IsBadParameter(err), Equals, true, Commentf("%v", err))
+}
+
+func replace(server, newAddr string) ops.CreateReplaceNodeOperationRequest {
+	return ops.CreateReplaceNodeOperationRequest{
+		Server:  server,
+		NewAddr: newAddr,
+	}
+}
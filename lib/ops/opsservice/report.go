@@ -344,7 +344,7 @@ type collectorFn func(report.FileWriter, site) error
 func getReportWriterForServer(dir string, server remoteServer) report.FileWriter {
 	return report.FileWriterFunc(func(name string) (io.WriteCloser, error) {
 		fileName := filepath.Join(dir, fmt.Sprintf("%s-%s", server.HostName(), name))
-		return report.NewPendingFileWriter(fileName), nil
+		return report.NewRedactingFileWriter(fileName), nil
 	})
 }
 
@@ -0,0 +1,141 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	"github.com/pborman/uuid"
+)
+
+// CreateRestoreOperation creates a new operation to restore the cluster
+// state and the Kubernetes resources from a backup of another cluster
+func (o *Operator) CreateRestoreOperation(ctx context.Context, req ops.CreateRestoreOperationRequest) (*ops.SiteOperationKey, error) {
+	if err := req.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	cluster, err := o.openSite(req.ClusterKey)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if len(cluster.servers()) == 0 {
+		return nil, trace.NotFound("no servers found in cluster state")
+	}
+	op := ops.SiteOperation{
+		ID:         uuid.New(),
+		AccountID:  cluster.key.AccountID,
+		SiteDomain: cluster.key.SiteDomain,
+		Type:       ops.OperationRestore,
+		Created:    cluster.clock().UtcNow(),
+		CreatedBy:  storage.UserFromContext(ctx),
+		Updated:    cluster.clock().UtcNow(),
+		State:      ops.OperationRestoreInProgress,
+		Restore: &storage.RestoreOperationState{
+			Backup: req.Backup,
+		},
+	}
+	key, err := cluster.getOperationGroup().createSiteOperation(op)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return key, nil
+}
+
+// CreateEtcdRestoreOperation creates a new operation to restore
+// the etcd database of the cluster from a snapshot
+func (o *Operator) CreateEtcdRestoreOperation(ctx context.Context, req ops.CreateEtcdRestoreOperationRequest) (*ops.SiteOperationKey, error) {
+	if err := req.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	cluster, err := o.openSite(req.ClusterKey)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if len(cluster.servers()) == 0 {
+		return nil, trace.NotFound("no servers found in cluster state")
+	}
+	op := ops.SiteOperation{
+		ID:         uuid.New(),
+		AccountID:  cluster.key.AccountID,
+		SiteDomain: cluster.key.SiteDomain,
+		Type:       ops.OperationEtcdRestore,
+		Created:    cluster.clock().UtcNow(),
+		CreatedBy:  storage.UserFromContext(ctx),
+		Updated:    cluster.clock().UtcNow(),
+		State:      ops.OperationEtcdRestoreInProgress,
+		EtcdRestore: &storage.EtcdRestoreOperationState{
+			Snapshot: req.Snapshot,
+			Revision: req.Revision,
+		},
+	}
+	key, err := cluster.getOperationGroup().createSiteOperation(op)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return key, nil
+}
+
+// CreateAppBackupOperation creates a new operation to back up
+// the Kubernetes resources and the data of a single application
+func (o *Operator) CreateAppBackupOperation(ctx context.Context, req ops.CreateAppBackupOperationRequest) (*ops.SiteOperationKey, error) {
+	if err := req.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if _, err := o.backend().GetRelease(req.ClusterKey.SiteDomain, req.Release); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.createAppBackupOperation(ctx, req, ops.OperationAppBackup, ops.OperationAppBackupInProgress)
+}
+
+// CreateAppRestoreOperation creates a new operation to restore
+// a single application from its backup
+func (o *Operator) CreateAppRestoreOperation(ctx context.Context, req ops.CreateAppBackupOperationRequest) (*ops.SiteOperationKey, error) {
+	if err := req.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return o.createAppBackupOperation(ctx, req, ops.OperationAppRestore, ops.OperationAppRestoreInProgress)
+}
+
+func (o *Operator) createAppBackupOperation(ctx context.Context, req ops.CreateAppBackupOperationRequest, opType, state string) (*ops.SiteOperationKey, error) {
+	cluster, err := o.openSite(req.ClusterKey)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	op := ops.SiteOperation{
+		ID:         uuid.New(),
+		AccountID:  cluster.key.AccountID,
+		SiteDomain: cluster.key.SiteDomain,
+		Type:       opType,
+		Created:    cluster.clock().UtcNow(),
+		CreatedBy:  storage.UserFromContext(ctx),
+		Updated:    cluster.clock().UtcNow(),
+		State:      state,
+		AppBackup: &storage.AppBackupOperationState{
+			Release: req.Release,
+			Path:    req.Path,
+		},
+	}
+	key, err := cluster.getOperationGroup().createSiteOperation(op)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return key, nil
+}
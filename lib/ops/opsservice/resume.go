@@ -45,7 +45,8 @@ func (s *site) resumeShrink() (*ops.SiteOperationKey, error) {
 		return nil, trace.Wrap(err)
 	}
 
-	if site.State != ops.SiteStateShrinking {
+	// the node replacement removes the replaced node with a shrink operation
+	if site.State != ops.SiteStateShrinking && site.State != ops.SiteStateReplacingNode {
 		return nil, trace.NotFound("cluster is not shrinking")
 	}
 
@@ -59,6 +60,10 @@ func (s *site) resumeShrink() (*ops.SiteOperationKey, error) {
 		return nil, trace.NotFound("shrink operation is not in progress: %v", op)
 	}
 
+	if err := op.CheckApproved(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
 	s.Debugf("resuming shrink operation: %v", op)
 
 	ctx, err := s.newOperationContext(*op)
@@ -0,0 +1,61 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	"github.com/pborman/uuid"
+)
+
+// CreateRotateCertificatesOperation creates a new operation to rotate
+// the runtime certificates of all cluster nodes and optionally
+// the cluster certificate authority
+func (o *Operator) CreateRotateCertificatesOperation(ctx context.Context, req ops.CreateRotateCertificatesOperationRequest) (*ops.SiteOperationKey, error) {
+	if err := req.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	cluster, err := o.openSite(req.ClusterKey)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if len(cluster.servers()) == 0 {
+		return nil, trace.NotFound("no servers found in cluster state")
+	}
+	op := ops.SiteOperation{
+		ID:         uuid.New(),
+		AccountID:  cluster.key.AccountID,
+		SiteDomain: cluster.key.SiteDomain,
+		Type:       ops.OperationRotateCertificates,
+		Created:    cluster.clock().UtcNow(),
+		CreatedBy:  storage.UserFromContext(ctx),
+		Updated:    cluster.clock().UtcNow(),
+		State:      ops.OperationRotateCertificatesInProgress,
+		RotateCertificates: &storage.RotateCertificatesOperationState{
+			RotateCA: req.RotateCA,
+		},
+	}
+	key, err := cluster.getOperationGroup().createSiteOperation(op)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return key, nil
+}
@@ -157,6 +157,13 @@ type Operator struct {
 	// operationGroups maintains operation group for each site
 	operationGroups map[ops.SiteKey]*operationGroup
 
+	// nodeHealth is the health of the cluster nodes as of the last
+	// status check
+	nodeHealth map[string]string
+	// lastHealthSnapshot is the last snapshot recorded in the cluster
+	// health history
+	lastHealthSnapshot *storage.HealthSnapshot
+
 	// FieldLogger allows this operator to log messages
 	log.FieldLogger
 }
@@ -466,7 +473,7 @@ func (o *Operator) validateNewSiteRequest(req *ops.NewSiteRequest) error {
 	}
 
 	switch req.Provider {
-	case schema.ProviderOnPrem, schema.ProviderGeneric, schema.ProviderAWS, schema.ProvisionerAWSTerraform, schema.ProviderGCE:
+	case schema.ProviderOnPrem, schema.ProviderGeneric, schema.ProviderAWS, schema.ProvisionerAWSTerraform, schema.ProviderGCE, schema.ProviderAzure, schema.ProviderVSphere:
 	default:
 		if req.Provider == "" {
 			return trace.BadParameter("missing Provider")
@@ -491,7 +498,7 @@ func (o *Operator) validateNewSiteRequest(req *ops.NewSiteRequest) error {
 	}
 
 	switch app.Manifest.Kind {
-	case schema.KindBundle, schema.KindCluster:
+	case schema.KindBundle, schema.KindCluster, schema.KindPlatform:
 	default:
 		return trace.BadParameter("cannot create cluster with app of type %q", app.Manifest.Kind)
 	}
@@ -626,6 +633,7 @@ func (o *Operator) CreateSite(r ops.NewSiteRequest) (*ops.Site, error) {
 			Docker: dockerConfig,
 		},
 		InstallToken: r.InstallToken,
+		Demo:         r.Demo,
 	}
 	if runtimeLoc := app.Manifest.Base(); runtimeLoc != nil {
 		runtimeApp, err := o.cfg.Apps.GetApp(*runtimeLoc)
@@ -754,6 +762,9 @@ func (o *Operator) GetSiteInstructions(tokenID string, serverProfile string, par
 	if err != nil {
 		return "", trace.Wrap(err)
 	}
+	if err := o.checkInstructionsToken(*token); err != nil {
+		return "", trace.Wrap(err)
+	}
 	s, err := o.openSite(ops.SiteKey{AccountID: token.AccountID, SiteDomain: token.SiteDomain})
 	if err != nil {
 		return "", trace.Wrap(err)
@@ -937,6 +948,13 @@ func (o *Operator) CreateSiteInstallOperation(ctx context.Context, r ops.CreateS
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+	existing, err := o.getIdempotentOperation(ctx, site.key, ops.OperationInstall)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if existing != nil {
+		return existing, nil
+	}
 	key, err := site.createInstallOperation(ctx, r)
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -953,6 +971,16 @@ func (o *Operator) CreateSiteExpandOperation(ctx context.Context, r ops.CreateSi
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+	existing, err := o.getIdempotentOperation(ctx, site.key, ops.OperationExpand)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if existing != nil {
+		return existing, nil
+	}
+	if err := o.useJoinToken(r); err != nil {
+		return nil, trace.Wrap(err)
+	}
 	key, err := site.createExpandOperation(ctx, r)
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -969,6 +997,13 @@ func (o *Operator) CreateSiteShrinkOperation(ctx context.Context, r ops.CreateSi
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+	existing, err := o.getIdempotentOperation(ctx, site.key, ops.OperationShrink)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if existing != nil {
+		return existing, nil
+	}
 	key, err := site.createShrinkOperation(ctx, r)
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -981,6 +1016,13 @@ func (o *Operator) CreateSiteAppUpdateOperation(ctx context.Context, r ops.Creat
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+	existing, err := o.getIdempotentOperation(ctx, site.key, ops.OperationUpdate)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if existing != nil {
+		return existing, nil
+	}
 	key, err := site.createUpdateOperation(ctx, r)
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -997,6 +1039,13 @@ func (o *Operator) CreateSiteUninstallOperation(ctx context.Context, r ops.Creat
 		// if we're a cluster, create uninstall operation in the Ops Center we're connected to
 		return site.requestUninstall(ctx, r)
 	}
+	existing, err := o.getIdempotentOperation(ctx, site.key, ops.OperationUninstall)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if existing != nil {
+		return existing, nil
+	}
 	key, err := site.createUninstallOperation(ctx, r)
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -1016,6 +1065,14 @@ func (o *Operator) CreateClusterGarbageCollectOperation(ctx context.Context, r o
 		return nil, trace.Wrap(err)
 	}
 
+	existing, err := o.getIdempotentOperation(ctx, cluster.key, ops.OperationGarbageCollect)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
 	key, err := cluster.createGarbageCollectOperation(ctx, r)
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -1408,9 +1465,34 @@ func (o *Operator) EmitAuditEvent(ctx context.Context, req ops.AuditEventRequest
 	if err != nil {
 		return trace.Wrap(err)
 	}
+	_, err = o.backend().CreateClusterEvent(req.SiteDomain, storage.ClusterEvent{
+		Name:   req.Event.Name,
+		Code:   req.Event.Code,
+		Fields: req.Fields,
+	}, defaults.ClusterEventsRetention)
+	if err != nil {
+		return trace.Wrap(err)
+	}
 	return nil
 }
 
+// GetClusterEvents returns the cluster events recorded after the
+// event specified in the request.
+func (o *Operator) GetClusterEvents(ctx context.Context, req ops.GetClusterEventsRequest) ([]storage.ClusterEvent, error) {
+	if err := req.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	limit := req.Limit
+	if limit == 0 || limit > defaults.ClusterEventsLimit {
+		limit = defaults.ClusterEventsLimit
+	}
+	events, err := o.backend().GetClusterEvents(req.SiteDomain, req.After, limit)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return events, nil
+}
+
 func (o *Operator) openSite(key ops.SiteKey) (*site, error) {
 	site, err := o.backend().GetSite(key.SiteDomain)
 	if err != nil {
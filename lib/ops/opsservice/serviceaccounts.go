@@ -0,0 +1,102 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/events"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// UpsertServiceAccount creates or updates a service account.
+// Service accounts are stored as users of a dedicated type which cannot
+// log in with a password and authenticate with API keys only
+func (o *Operator) UpsertServiceAccount(ctx context.Context, key ops.SiteKey, account storage.ServiceAccount) error {
+	if err := account.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	existing, err := o.getServiceAccountUser(account.GetName())
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	user := storage.ServiceAccountToUser(account, key.SiteDomain)
+	if existing != nil {
+		user.SetCreatedBy(existing.GetCreatedBy())
+	}
+	if err := o.cfg.Users.UpsertUser(user); err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.ServiceAccountCreated, events.Fields{
+		events.FieldName:  account.GetName(),
+		events.FieldRoles: account.GetRoles(),
+	})
+	return nil
+}
+
+// GetServiceAccounts returns all service accounts
+func (o *Operator) GetServiceAccounts(key ops.SiteKey) ([]storage.ServiceAccount, error) {
+	users, err := o.cfg.Users.GetUsers()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var accounts []storage.ServiceAccount
+	for _, user := range users {
+		user, ok := user.(storage.User)
+		if !ok || user.GetType() != storage.ServiceAccountUser {
+			continue
+		}
+		accounts = append(accounts, storage.NewServiceAccountFromUser(user))
+	}
+	return accounts, nil
+}
+
+// DeleteServiceAccount deletes the service account with the specified name.
+// All API keys of the service account are revoked
+func (o *Operator) DeleteServiceAccount(ctx context.Context, key ops.SiteKey, name string) error {
+	if _, err := o.getServiceAccountUser(name); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := o.cfg.Users.DeleteUser(name); err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.ServiceAccountDeleted, events.Fields{
+		events.FieldName: name,
+	})
+	return nil
+}
+
+// getServiceAccountUser returns the user that represents the service account
+// with the specified name. Returns AlreadyExists if there is a user with the
+// same name that is not a service account
+func (o *Operator) getServiceAccountUser(name string) (storage.User, error) {
+	teleuser, err := o.cfg.Users.GetUser(name)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("service account %q not found", name)
+		}
+		return nil, trace.Wrap(err)
+	}
+	user, ok := teleuser.(storage.User)
+	if !ok || user.GetType() != storage.ServiceAccountUser {
+		return nil, trace.AlreadyExists("user %q exists and is not a service account", name)
+	}
+	return user, nil
+}
@@ -52,15 +52,16 @@ func (s *site) createShrinkOperation(context context.Context, req ops.CreateSite
 	}
 
 	op := &ops.SiteOperation{
-		ID:          uuid.New(),
-		AccountID:   s.key.AccountID,
-		SiteDomain:  s.key.SiteDomain,
-		Type:        ops.OperationShrink,
-		Created:     s.clock().UtcNow(),
-		CreatedBy:   storage.UserFromContext(context),
-		Updated:     s.clock().UtcNow(),
-		State:       ops.OperationStateShrinkInProgress,
-		Provisioner: server.Provisioner,
+		ID:             uuid.New(),
+		AccountID:      s.key.AccountID,
+		SiteDomain:     s.key.SiteDomain,
+		Type:           ops.OperationShrink,
+		Created:        s.clock().UtcNow(),
+		CreatedBy:      storage.UserFromContext(context),
+		IdempotencyKey: ops.IdempotencyKeyFromContext(context),
+		Updated:        s.clock().UtcNow(),
+		State:          ops.OperationStateShrinkInProgress,
+		Provisioner:    server.Provisioner,
 	}
 
 	ctx, err := s.newOperationContext(*op)
@@ -102,10 +103,28 @@ func (s *site) createShrinkOperation(context context.Context, req ops.CreateSite
 	}
 
 	key, err := s.getOperationGroup().createSiteOperation(*op)
+	if existing, ok := existingOperation(err); ok {
+		return existing, nil
+	}
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	operation, err := s.getSiteOperation(key.OperationID)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
 
+	// the operation is started once it has been approved
+	if operation.IsPendingApproval() {
+		s.reportProgress(ctx, ops.ProgressEntry{
+			State:      ops.ProgressStateInProgress,
+			Completion: 0,
+			Message:    "waiting for the operation to be approved",
+		})
+		return key, nil
+	}
+
 	s.reportProgress(ctx, ops.ProgressEntry{
 		State:      ops.ProgressStateInProgress,
 		Completion: 0,
@@ -106,6 +106,10 @@ func (s *site) cloudProviderName() string {
 		return schema.ProviderAWS
 	case schema.ProviderGCE:
 		return schema.ProviderGCE
+	case schema.ProviderAzure:
+		return schema.ProviderAzure
+	case schema.ProviderVSphere:
+		return schema.ProviderVSphere
 	default:
 		return ""
 	}
@@ -187,6 +191,27 @@ func (s *site) agentUser() (storage.User, error) {
 	return s.users().GetTelekubeUser(s.agentUserEmail())
 }
 
+// joinAgentUser returns the agent user the short-lived join tokens of
+// the cluster authenticate as.
+// The user is created on demand for clusters that predate it
+func (s *site) joinAgentUser() (storage.User, error) {
+	user, err := s.users().GetTelekubeUser(storage.ClusterJoinAgent(s.domainName))
+	if err == nil {
+		return user, nil
+	}
+	if !trace.IsNotFound(err) {
+		return nil, trace.Wrap(err)
+	}
+	user, err = s.users().CreateClusterJoinAgent(s.domainName, storage.NewUser(
+		storage.ClusterJoinAgent(s.domainName), storage.UserSpecV2{
+			AccountID: s.key.AccountID,
+		}))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return user, nil
+}
+
 func (s *site) appPackage() (*loc.Locator, error) {
 	site, err := s.backend().GetSite(s.key.SiteDomain)
 	if err != nil {
@@ -697,6 +722,7 @@ func convertSite(in storage.Site, apps appservice.Applications) (*ops.Site, erro
 		DNSOverrides:             in.DNSOverrides,
 		DNSConfig:                in.DNSConfig,
 		InstallToken:             in.InstallToken,
+		Demo:                     in.Demo,
 	}
 	if in.License != "" {
 		parsed, err := license.ParseLicense(in.License)
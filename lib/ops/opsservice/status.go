@@ -18,6 +18,7 @@ package opsservice
 
 import (
 	"context"
+	"strings"
 
 	"github.com/gravitational/gravity/lib/app"
 	"github.com/gravitational/gravity/lib/ops"
@@ -46,12 +47,24 @@ func (o *Operator) CheckSiteStatus(ctx context.Context, key ops.SiteKey) error {
 		return nil
 	}
 
-	statusErr := cluster.checkPlanetStatus(context.TODO())
+	planetStatus, statusErr := cluster.checkPlanetStatus(context.TODO())
+	if planetStatus != nil {
+		o.emitNodeHealthEvents(ctx, planetStatus.Nodes)
+	}
+	healthStatus, err := o.runHealthChecks(ctx, key)
+	if err != nil {
+		o.WithError(err).Warn("Failed to run cluster health checks.")
+	}
 	reason := storage.ReasonClusterDegraded
+	if statusErr == nil && healthStatus != nil {
+		statusErr = checkHealthCheckStatus(*healthStatus)
+		reason = storage.ReasonHealthCheckFailed
+	}
 	if statusErr == nil {
 		statusErr = cluster.checkStatusHook(context.TODO())
 		reason = storage.ReasonStatusCheckFailed
 	}
+	o.recordHealthSnapshot(key, newHealthSnapshot(planetStatus, healthStatus, reason, statusErr))
 
 	if statusErr != nil {
 		err := o.DeactivateSite(ops.DeactivateSiteRequest{
@@ -90,16 +103,47 @@ func (s *site) canActivate() bool {
 		s.backendSite.Reason != storage.ReasonLicenseInvalid
 }
 
+// emitNodeHealthEvents emits an event for every node whose health
+// has changed since the previous status check
+func (o *Operator) emitNodeHealthEvents(ctx context.Context, nodes []status.ClusterServer) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.nodeHealth == nil {
+		o.nodeHealth = make(map[string]string)
+	}
+	for _, node := range nodes {
+		previous, ok := o.nodeHealth[node.AdvertiseIP]
+		o.nodeHealth[node.AdvertiseIP] = node.Status
+		fields := events.Fields{
+			events.FieldNodeIP:       node.AdvertiseIP,
+			events.FieldNodeHostname: node.Hostname,
+		}
+		healthy := node.Status == status.NodeHealthy
+		wasHealthy := !ok || previous == status.NodeHealthy
+		switch {
+		case !healthy && wasHealthy:
+			reason := strings.Join(node.FailedProbes, ", ")
+			if node.Status == status.NodeOffline {
+				reason = node.Status
+			}
+			events.Emit(ctx, o, events.NodeDegraded, fields.WithField(events.FieldReason, reason))
+		case healthy && !wasHealthy:
+			events.Emit(ctx, o, events.NodeHealthy, fields)
+		}
+	}
+}
+
 // checkPlanetStatus checks the cluster health using planet agents
-func (s *site) checkPlanetStatus(ctx context.Context) error {
+// and returns the collected status
+func (s *site) checkPlanetStatus(ctx context.Context) (*status.Agent, error) {
 	planetStatus, err := status.FromPlanetAgent(ctx, nil)
 	if err != nil {
-		return trace.Wrap(err)
+		return nil, trace.Wrap(err)
 	}
 	if planetStatus.GetSystemStatus() != agentpb.SystemStatus_Running {
-		return trace.BadParameter("cluster is not healthy: %#v", planetStatus)
+		return planetStatus, trace.BadParameter("cluster is not healthy: %#v", planetStatus)
 	}
-	return nil
+	return planetStatus, nil
 }
 
 // checkStatusHook executes the application's status hook
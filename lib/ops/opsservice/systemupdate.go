@@ -27,10 +27,9 @@ import (
 // rotateSecrets generates a new set of TLS keys for the given node
 // as a package that will be automatically downloaded during upgrade
 func (s *site) rotateSecrets(ctx *operationContext, secretsPackage loc.Locator, node *ProvisionedServer, installOp ops.SiteOperation) (*ops.RotatePackageResponse, error) {
-	subnets := installOp.InstallExpand.Subnets
-	if subnets.IsEmpty() {
-		// Subnets are empty when updating an older installation
-		subnets = storage.DefaultSubnets
+	subnets, err := s.getClusterSubnets(installOp)
+	if err != nil {
+		return nil, trace.Wrap(err)
 	}
 
 	if !node.IsMaster() {
@@ -0,0 +1,227 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/events"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/users"
+
+	"github.com/gravitational/trace"
+)
+
+// CreateJoinToken creates a new short-lived cluster join token
+func (o *Operator) CreateJoinToken(ctx context.Context, req ops.CreateJoinTokenRequest) (*storage.ProvisioningToken, error) {
+	token, err := o.createJoinToken(req, false)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.TokenCreated, events.Fields{
+		events.FieldOwner: token.UserEmail,
+		events.FieldRoles: req.Roles,
+	})
+	return token, nil
+}
+
+// RequestScaleUp requests expansion of the cluster by the specified number of nodes.
+// It issues a join token limited to the requested profile and node count which
+// the autoscaler publishes for new instances to join the cluster with
+func (o *Operator) RequestScaleUp(ctx context.Context, req ops.ScaleUpRequest) (*storage.ProvisioningToken, error) {
+	if err := req.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	token, err := o.createJoinToken(ops.CreateJoinTokenRequest{
+		SiteKey: req.SiteKey,
+		TTL:     req.TTL,
+		Roles:   []string{req.Profile},
+		MaxUses: req.Count,
+	}, true)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.ScaleUpRequested, events.Fields{
+		events.FieldNodeRole: req.Profile,
+		events.FieldCount:    req.Count,
+	})
+	return token, nil
+}
+
+func (o *Operator) createJoinToken(req ops.CreateJoinTokenRequest, scaleUp bool) (*storage.ProvisioningToken, error) {
+	if err := req.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	cluster, err := o.openSite(req.SiteKey)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, role := range req.Roles {
+		if _, err := cluster.app.Manifest.NodeProfiles.ByName(role); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	// Join tokens authenticate as a dedicated user which can not
+	// manage the join tokens itself
+	joinUser, err := cluster.joinAgentUser()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	tokenID, err := users.CryptoRandomToken(defaults.ProvisioningTokenBytes)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	token, err := o.users().CreateProvisioningToken(storage.ProvisioningToken{
+		Token:      tokenID,
+		Expires:    o.cfg.Clock.UtcNow().Add(req.TTL),
+		Type:       storage.ProvisioningTokenTypeExpand,
+		AccountID:  req.AccountID,
+		SiteDomain: req.SiteDomain,
+		UserEmail:  joinUser.GetName(),
+		Roles:      req.Roles,
+		MaxUses:    req.MaxUses,
+		ScaleUp:    scaleUp,
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return token, nil
+}
+
+// GetJoinTokens returns the active short-lived join tokens of the cluster
+func (o *Operator) GetJoinTokens(ctx context.Context, key ops.SiteKey) ([]storage.ProvisioningToken, error) {
+	tokens, err := o.backend().GetSiteProvisioningTokens(key.SiteDomain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var joinTokens []storage.ProvisioningToken
+	for _, token := range tokens {
+		if isJoinToken(token) {
+			joinTokens = append(joinTokens, token)
+		}
+	}
+	return joinTokens, nil
+}
+
+// DeleteJoinToken deletes the specified short-lived join token
+func (o *Operator) DeleteJoinToken(ctx context.Context, req ops.DeleteJoinTokenRequest) error {
+	if err := req.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	token, err := o.backend().GetProvisioningToken(req.Token)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if token.SiteDomain != req.SiteDomain || !isJoinToken(*token) {
+		return trace.NotFound("join token %v not found", req.Token)
+	}
+	err = o.backend().DeleteProvisioningToken(req.Token)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	events.Emit(ctx, o, events.TokenDeleted, events.Fields{
+		events.FieldOwner: token.UserEmail,
+	})
+	return nil
+}
+
+// useJoinToken validates the join token the expand operation request has
+// been authenticated with and records its use.
+// The long-lived cluster join token is only used internally and can not
+// be used to join nodes.
+// Requests authenticated with other kinds of credentials are passed through
+func (o *Operator) useJoinToken(req ops.CreateSiteExpandOperationRequest) error {
+	if req.JoinToken == "" {
+		return nil
+	}
+	token, err := o.backend().GetProvisioningToken(req.JoinToken)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil
+		}
+		return trace.Wrap(err)
+	}
+	if isLongLivedJoinToken(*token) {
+		return trace.Wrap(errLongLivedJoinToken())
+	}
+	if !isJoinToken(*token) {
+		return nil
+	}
+	if token.SiteDomain != req.SiteDomain {
+		return trace.AccessDenied("join token is not valid for cluster %v", req.SiteDomain)
+	}
+	for role := range req.Servers {
+		if err := token.CheckRole(role); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	_, err = o.backend().UseProvisioningToken(req.JoinToken)
+	if err != nil {
+		if trace.IsLimitExceeded(err) {
+			return trace.AccessDenied("join token has already been used")
+		}
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// checkInstructionsToken verifies that the specified token can be used
+// to download the join instructions.
+// The long-lived cluster join token is the install token and is only
+// accepted until the installation has finished
+func (o *Operator) checkInstructionsToken(token storage.ProvisioningToken) error {
+	if !isLongLivedJoinToken(token) {
+		return nil
+	}
+	if token.OperationID == "" {
+		return trace.Wrap(errLongLivedJoinToken())
+	}
+	operation, err := o.GetSiteOperation(ops.SiteOperationKey{
+		AccountID:   token.AccountID,
+		SiteDomain:  token.SiteDomain,
+		OperationID: token.OperationID,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if operation.IsFinished() {
+		return trace.Wrap(errLongLivedJoinToken())
+	}
+	return nil
+}
+
+func errLongLivedJoinToken() error {
+	return trace.AccessDenied("the cluster join token can not be used to join nodes, " +
+		"create a short-lived join token with 'gravity token create' instead")
+}
+
+// isLongLivedJoinToken returns true if the specified provisioning token is
+// the long-lived cluster join token
+func isLongLivedJoinToken(token storage.ProvisioningToken) bool {
+	return token.Type == storage.ProvisioningTokenTypeExpand && token.Expires.IsZero()
+}
+
+// isJoinToken returns true if the specified provisioning token is
+// a short-lived join token.
+// The long-lived cluster join token and the operation agent tokens
+// are managed by the cluster itself
+func isJoinToken(token storage.ProvisioningToken) bool {
+	return token.Type == storage.ProvisioningTokenTypeExpand &&
+		token.OperationID == "" && !token.Expires.IsZero()
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package opsservice
+
+import (
+	"context"
+	"time"
+
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/suite"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+type TokensSuite struct {
+	operator  *Operator
+	cluster   *ops.Site
+	installOp *ops.SiteOperationKey
+}
+
+var _ = Suite(&TokensSuite{})
+
+func (s *TokensSuite) SetUpTest(c *C) {
+	services := SetupTestServices(c)
+	s.operator = services.Operator
+	app := suite.SetUpTestPackage(c, services.Apps, services.Packages)
+
+	account, err := s.operator.CreateAccount(ops.NewAccountRequest{Org: "testing"})
+	c.Assert(err, IsNil)
+
+	s.cluster, err = s.operator.CreateSite(ops.NewSiteRequest{
+		AccountID:  account.ID,
+		AppPackage: app.String(),
+		Provider:   schema.ProvisionerOnPrem,
+		DomainName: "test.localdomain",
+	})
+	c.Assert(err, IsNil)
+
+	// install operation creates the long-lived join token
+	s.installOp, err = s.operator.CreateSiteInstallOperation(context.TODO(), ops.CreateSiteInstallOperationRequest{
+		AccountID:   account.ID,
+		SiteDomain:  s.cluster.Domain,
+		Variables:   storage.OperationVariables{},
+		Provisioner: schema.ProvisionerOnPrem,
+	})
+	c.Assert(err, IsNil)
+}
+
+func (s *TokensSuite) TestJoinTokenRestrictions(c *C) {
+	ctx := context.TODO()
+	_, err := s.operator.CreateJoinToken(ctx, ops.CreateJoinTokenRequest{
+		SiteKey: s.cluster.Key(),
+		TTL:     time.Hour,
+		Roles:   []string{"unknown"},
+	})
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%v", err))
+
+	token, err := s.operator.CreateJoinToken(ctx, ops.CreateJoinTokenRequest{
+		SiteKey: s.cluster.Key(),
+		TTL:     time.Hour,
+		Roles:   []string{"knode"},
+		MaxUses: 1,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(token.UserEmail, Equals, storage.ClusterJoinAgent(s.cluster.Domain),
+		Commentf("join tokens should authenticate as the dedicated join agent"))
+	agent, err := s.operator.GetClusterAgent(ops.ClusterAgentRequest{
+		AccountID:   s.cluster.AccountID,
+		ClusterName: s.cluster.Domain,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(agent.Email, Equals, storage.ClusterAgent(s.cluster.Domain),
+		Commentf("the join agent should not be used as the cluster agent"))
+
+	tokens, err := s.operator.GetJoinTokens(ctx, s.cluster.Key())
+	c.Assert(err, IsNil)
+	c.Assert(tokens, HasLen, 1, Commentf("the long-lived join token should not be listed"))
+	c.Assert(tokens[0].Token, Equals, token.Token)
+
+	req := ops.CreateSiteExpandOperationRequest{
+		AccountID:  s.cluster.AccountID,
+		SiteDomain: s.cluster.Domain,
+		JoinToken:  token.Token,
+		Servers:    map[string]int{"kmaster": 1},
+	}
+	c.Assert(trace.IsAccessDenied(s.operator.useJoinToken(req)), Equals, true)
+
+	req.Servers = map[string]int{"knode": 1}
+	c.Assert(s.operator.useJoinToken(req), IsNil)
+	c.Assert(trace.IsAccessDenied(s.operator.useJoinToken(req)), Equals, true,
+		Commentf("single-use token should not be usable twice"))
+
+	expandToken, err := s.operator.GetExpandToken(s.cluster.Key())
+	c.Assert(err, IsNil)
+	err = s.operator.DeleteJoinToken(ctx, ops.DeleteJoinTokenRequest{
+		SiteKey: s.cluster.Key(),
+		Token:   expandToken.Token,
+	})
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("the long-lived join token can't be removed"))
+
+	err = s.operator.DeleteJoinToken(ctx, ops.DeleteJoinTokenRequest{
+		SiteKey: s.cluster.Key(),
+		Token:   token.Token,
+	})
+	c.Assert(err, IsNil)
+}
+
+func (s *TokensSuite) TestScaleUpIssuesScopedToken(c *C) {
+	ctx := context.TODO()
+	_, err := s.operator.RequestScaleUp(ctx, ops.ScaleUpRequest{
+		SiteKey: s.cluster.Key(),
+		Profile: "knode",
+	})
+	c.Assert(trace.IsBadParameter(err), Equals, true, Commentf("%v", err))
+
+	token, err := s.operator.RequestScaleUp(ctx, ops.ScaleUpRequest{
+		SiteKey: s.cluster.Key(),
+		Profile: "knode",
+		Count:   2,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(token.ScaleUp, Equals, true)
+	c.Assert(token.Roles, DeepEquals, []string{"knode"})
+	c.Assert(token.MaxUses, Equals, 2)
+
+	req := ops.CreateSiteExpandOperationRequest{
+		AccountID:  s.cluster.AccountID,
+		SiteDomain: s.cluster.Domain,
+		JoinToken:  token.Token,
+		Servers:    map[string]int{"kmaster": 1},
+	}
+	c.Assert(trace.IsAccessDenied(s.operator.useJoinToken(req)), Equals, true)
+}
+
+func (s *TokensSuite) TestRejectsLongLivedJoinToken(c *C) {
+	expandToken, err := s.operator.GetExpandToken(s.cluster.Key())
+	c.Assert(err, IsNil)
+
+	err = s.operator.useJoinToken(ops.CreateSiteExpandOperationRequest{
+		AccountID:  s.cluster.AccountID,
+		SiteDomain: s.cluster.Domain,
+		JoinToken:  expandToken.Token,
+		Servers:    map[string]int{"knode": 1},
+	})
+	c.Assert(trace.IsAccessDenied(err), Equals, true, Commentf("%v", err))
+
+	// The install token doubles as the long-lived join token and
+	// is accepted for join instructions until the installation finishes
+	c.Assert(s.operator.checkInstructionsToken(*expandToken), IsNil)
+	err = s.operator.SetOperationState(*s.installOp, ops.SetOperationStateRequest{
+		State: ops.OperationStateCompleted,
+	})
+	c.Assert(err, IsNil)
+	err = s.operator.checkInstructionsToken(*expandToken)
+	c.Assert(trace.IsAccessDenied(err), Equals, true, Commentf("%v", err))
+}
@@ -80,15 +80,16 @@ func (s *site) createUninstallOperation(context context.Context, req ops.CreateS
 	}
 
 	op := &ops.SiteOperation{
-		ID:          uuid.New(),
-		AccountID:   s.key.AccountID,
-		SiteDomain:  s.key.SiteDomain,
-		Type:        ops.OperationUninstall,
-		Created:     s.clock().UtcNow(),
-		CreatedBy:   storage.UserFromContext(context),
-		Updated:     s.clock().UtcNow(),
-		State:       ops.OperationStateUninstallInProgress,
-		Provisioner: opInstall.Provisioner,
+		ID:             uuid.New(),
+		AccountID:      s.key.AccountID,
+		SiteDomain:     s.key.SiteDomain,
+		Type:           ops.OperationUninstall,
+		Created:        s.clock().UtcNow(),
+		CreatedBy:      storage.UserFromContext(context),
+		IdempotencyKey: ops.IdempotencyKeyFromContext(context),
+		Updated:        s.clock().UtcNow(),
+		State:          ops.OperationStateUninstallInProgress,
+		Provisioner:    opInstall.Provisioner,
 		Uninstall: &storage.UninstallOperationState{
 			Force: req.Force,
 			Vars:  opInstall.InstallExpand.Vars,
@@ -120,6 +121,9 @@ func (s *site) createUninstallOperation(context context.Context, req ops.CreateS
 	}
 
 	key, err := s.getOperationGroup().createSiteOperation(*op)
+	if existing, ok := existingOperation(err); ok {
+		return existing, nil
+	}
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -19,6 +19,7 @@ package opsservice
 import (
 	"context"
 	"path/filepath"
+	"strings"
 
 	"github.com/gravitational/gravity/lib/app"
 	"github.com/gravitational/gravity/lib/checks"
@@ -220,17 +221,26 @@ func (o *Operator) RotatePlanetConfig(req ops.RotatePlanetConfigRequest) (*ops.R
 	}
 
 	var etcd etcdConfig
-	if memberList.HasMember(node.EtcdMemberName(cluster.domainName)) {
+	switch {
+	case !node.IsMaster():
+		etcd = etcdConfig{
+			initialCluster:      initialCluster,
+			initialClusterState: etcdExistingCluster,
+			proxyMode:           etcdProxyOn,
+		}
+	case memberList.HasMember(node.EtcdMemberName(cluster.domainName)):
 		etcd = etcdConfig{
 			initialCluster:      initialCluster,
 			initialClusterState: etcdNewCluster,
 			proxyMode:           etcdProxyOff,
 		}
-	} else {
+	default:
+		// The node is being promoted to master and joins the etcd cluster as a new member
 		etcd = etcdConfig{
-			initialCluster:      initialCluster,
+			initialCluster: strings.Join([]string{initialCluster,
+				provisionedServers{&node}.InitialCluster(cluster.domainName)}, ","),
 			initialClusterState: etcdExistingCluster,
-			proxyMode:           etcdProxyOn,
+			proxyMode:           etcdProxyOff,
 		}
 	}
 
@@ -353,17 +363,22 @@ func (s *site) createUpdateOperation(context context.Context, req ops.CreateSite
 	}
 
 	op := ops.SiteOperation{
-		ID:          uuid.New(),
-		AccountID:   s.key.AccountID,
-		SiteDomain:  s.key.SiteDomain,
-		Type:        ops.OperationUpdate,
-		Created:     s.clock().UtcNow(),
-		CreatedBy:   storage.UserFromContext(context),
-		Updated:     s.clock().UtcNow(),
-		State:       ops.OperationStateUpdateInProgress,
-		Provisioner: installOperation.Provisioner,
+		ID:             uuid.New(),
+		AccountID:      s.key.AccountID,
+		SiteDomain:     s.key.SiteDomain,
+		Type:           ops.OperationUpdate,
+		Created:        s.clock().UtcNow(),
+		CreatedBy:      storage.UserFromContext(context),
+		IdempotencyKey: ops.IdempotencyKeyFromContext(context),
+		Updated:        s.clock().UtcNow(),
+		State:          ops.OperationStateUpdateInProgress,
+		Provisioner:    installOperation.Provisioner,
 		Update: &storage.UpdateOperationState{
 			UpdatePackage: req.App,
+			Strategy:      req.Strategy,
+			AutoRollback:  req.AutoRollback,
+			Schedule:      req.Schedule,
+			Drain:         req.Drain,
 		},
 	}
 
@@ -374,6 +389,9 @@ func (s *site) createUpdateOperation(context context.Context, req ops.CreateSite
 	defer ctx.Close()
 
 	key, err := s.getOperationGroup().createSiteOperation(op)
+	if existing, ok := existingOperation(err); ok {
+		return existing, nil
+	}
 	if err != nil {
 		return nil, trace.Wrap(err, "failed to create update operation")
 	}
@@ -431,6 +449,26 @@ func (s *site) validateUpdateOperationRequest(req ops.CreateSiteAppUpdateOperati
 	if err != nil {
 		return trace.Wrap(err)
 	}
+	if req.Strategy != nil {
+		if err := req.Strategy.Check(); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	if req.AutoRollback != nil {
+		if err := req.AutoRollback.Check(); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	if req.Schedule != nil {
+		if err := req.Schedule.Check(); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	if req.Drain != nil {
+		if err := req.Drain.Check(); err != nil {
+			return trace.Wrap(err)
+		}
+	}
 	err = pack.CheckUpdatePackage(*currentPackage, *updatePackage)
 	if err != nil {
 		return trace.Wrap(err)
@@ -21,6 +21,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -228,6 +230,56 @@ func (c *userCollection) WriteYAML(w io.Writer) error {
 	return utils.WriteYAML(c, w)
 }
 
+type serviceAccountCollection struct {
+	accounts []storage.ServiceAccount
+	// keys maps service account name to the number of its API keys
+	keys map[string]int
+}
+
+// Resources returns the resources collection in the generic format
+func (c *serviceAccountCollection) Resources() (resources []teleservices.UnknownResource, err error) {
+	for _, item := range c.accounts {
+		resource, err := utils.ToUnknownResource(item)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		resources = append(resources, *resource)
+	}
+	return resources, nil
+}
+
+// WriteText serializes collection in human-friendly text format
+func (c *serviceAccountCollection) WriteText(w io.Writer) error {
+	t := goterm.NewTable(0, 10, 5, ' ', 0)
+	common.PrintTableHeader(t, []string{"Name", "Roles", "API Keys"})
+	for _, account := range c.accounts {
+		fmt.Fprintf(t, "%v\t%v\t%v\n",
+			account.GetName(),
+			strings.Join(account.GetRoles(), ", "),
+			c.keys[account.GetName()])
+	}
+	_, err := io.WriteString(w, t.String())
+	return trace.Wrap(err)
+}
+
+// WriteJSON serializes collection into JSON format
+func (c *serviceAccountCollection) WriteJSON(w io.Writer) error {
+	return utils.WriteJSON(c, w)
+}
+
+// WriteYAML serializes collection into YAML format
+func (c *serviceAccountCollection) WriteYAML(w io.Writer) error {
+	return utils.WriteYAML(c, w)
+}
+
+// ToMarshal returns object that should be marshaled.
+func (c *serviceAccountCollection) ToMarshal() interface{} {
+	if len(c.accounts) == 1 {
+		return c.accounts[0]
+	}
+	return c.accounts
+}
+
 type tokenCollection struct {
 	tokens []storage.Token
 }
@@ -507,9 +559,23 @@ type alertCollection []storage.Alert
 // WriteText serializes collection in human-friendly text format
 func (r alertTargetCollection) WriteText(w io.Writer) error {
 	t := goterm.NewTable(0, 10, 5, ' ', 0)
-	common.PrintTableHeader(t, []string{"Email"})
+	common.PrintTableHeader(t, []string{"Email", "Slack", "PagerDuty"})
 	for _, target := range r {
-		fmt.Fprintf(t, "%v\n", target.GetEmail())
+		email, slack, pagerDuty := "-", "-", "-"
+		if target.GetEmail() != "" {
+			email = target.GetEmail()
+		}
+		if target.GetSlack() != nil {
+			// do not display the webhook URL as it contains the secret token
+			slack = "default channel"
+			if target.GetSlack().Channel != "" {
+				slack = target.GetSlack().Channel
+			}
+		}
+		if target.GetPagerDuty() != nil {
+			pagerDuty = "configured"
+		}
+		fmt.Fprintf(t, "%v\t%v\t%v\n", email, slack, pagerDuty)
 	}
 	_, err := io.WriteString(w, t.String())
 	return trace.Wrap(err)
@@ -607,6 +673,508 @@ func (c *authGatewayCollection) ToMarshal() interface{} {
 	return c.item
 }
 
+type clusterDNSCollection struct {
+	item storage.ClusterDNS
+}
+
+// Resources returns the resources collection in the generic format
+func (c *clusterDNSCollection) Resources() ([]teleservices.UnknownResource, error) {
+	resource, err := utils.ToUnknownResource(c.item)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return []teleservices.UnknownResource{*resource}, nil
+}
+
+// WriteText serializes cluster DNS configuration in human-friendly text format
+func (c *clusterDNSCollection) WriteText(w io.Writer) error {
+	t := goterm.NewTable(0, 10, 5, ' ', 0)
+	common.PrintTableHeader(t, []string{"Parameter", "Value"})
+	fmt.Fprintf(t, "Upstream Servers:\t%v\n", formatList(c.item.GetUpstreamServers()))
+	for zone, nameservers := range c.item.GetZones() {
+		fmt.Fprintf(t, "Zone %v:\t%v\n", zone, strings.Join(nameservers, ", "))
+	}
+	for hostname, ip := range c.item.GetHosts() {
+		fmt.Fprintf(t, "Host %v:\t%v\n", hostname, ip)
+	}
+	_, err := io.WriteString(w, t.String())
+	return trace.Wrap(err)
+}
+
+// WriteJSON serializes collection into JSON format
+func (c *clusterDNSCollection) WriteJSON(w io.Writer) error {
+	return utils.WriteJSON(c, w)
+}
+
+// WriteYAML serializes collection into YAML format
+func (c *clusterDNSCollection) WriteYAML(w io.Writer) error {
+	return utils.WriteYAML(c, w)
+}
+
+// ToMarshal returns object that should be marshaled.
+func (c *clusterDNSCollection) ToMarshal() interface{} {
+	return c.item
+}
+
+type nodePoolCollection struct {
+	pools   []storage.NodePool
+	servers []storage.Server
+}
+
+// Resources returns the resources collection in the generic format
+func (c *nodePoolCollection) Resources() (resources []teleservices.UnknownResource, err error) {
+	for _, item := range c.pools {
+		resource, err := utils.ToUnknownResource(item)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		resources = append(resources, *resource)
+	}
+	return resources, nil
+}
+
+// WriteText serializes collection in human-friendly text format
+func (c *nodePoolCollection) WriteText(w io.Writer) error {
+	t := goterm.NewTable(0, 10, 5, ' ', 0)
+	common.PrintTableHeader(t, []string{"Name", "Profile", "Nodes", "Min", "Max", "Labels"})
+	for _, pool := range c.pools {
+		var nodes int
+		for _, server := range c.servers {
+			if server.NodePool == pool.GetName() {
+				nodes++
+			}
+		}
+		maxCount := "-"
+		if pool.GetMaxCount() != 0 {
+			maxCount = strconv.Itoa(pool.GetMaxCount())
+		}
+		fmt.Fprintf(t, "%v\t%v\t%v\t%v\t%v\t%v\n",
+			pool.GetName(),
+			pool.GetProfile(),
+			nodes,
+			pool.GetMinCount(),
+			maxCount,
+			formatNodePoolLabels(pool))
+	}
+	_, err := io.WriteString(w, t.String())
+	return trace.Wrap(err)
+}
+
+func formatNodePoolLabels(pool storage.NodePool) string {
+	if len(pool.GetLabels()) == 0 {
+		return "-"
+	}
+	var labels []string
+	for k, v := range pool.GetLabels() {
+		labels = append(labels, fmt.Sprintf("%v=%v", k, v))
+	}
+	sort.Strings(labels)
+	return strings.Join(labels, ", ")
+}
+
+// WriteJSON serializes collection into JSON format
+func (c *nodePoolCollection) WriteJSON(w io.Writer) error {
+	return utils.WriteJSON(c, w)
+}
+
+// WriteYAML serializes collection into YAML format
+func (c *nodePoolCollection) WriteYAML(w io.Writer) error {
+	return utils.WriteYAML(c, w)
+}
+
+// ToMarshal returns object that should be marshaled.
+func (c *nodePoolCollection) ToMarshal() interface{} {
+	if len(c.pools) == 1 {
+		return c.pools[0]
+	}
+	return c.pools
+}
+
+type clusterRosterCollection struct {
+	roster  storage.ClusterRoster
+	servers []storage.Server
+}
+
+// Resources returns the resources collection in the generic format
+func (c *clusterRosterCollection) Resources() ([]teleservices.UnknownResource, error) {
+	resource, err := utils.ToUnknownResource(c.roster)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return []teleservices.UnknownResource{*resource}, nil
+}
+
+// WriteText serializes the cluster roster in human-friendly text format
+func (c *clusterRosterCollection) WriteText(w io.Writer) error {
+	members := make(map[string]struct{}, len(c.servers))
+	for _, server := range c.servers {
+		members[server.AdvertiseIP] = struct{}{}
+	}
+	t := goterm.NewTable(0, 10, 5, ' ', 0)
+	common.PrintTableHeader(t, []string{"Address", "Role", "Status"})
+	for _, node := range c.roster.GetNodes() {
+		status := "joined"
+		if _, ok := members[node.Addr]; !ok {
+			status = "not joined"
+		}
+		fmt.Fprintf(t, "%v\t%v\t%v\n", node.Addr, node.Role, status)
+	}
+	fmt.Fprintf(t, "\nApproval: %v\n", c.roster.GetApproval())
+	_, err := io.WriteString(w, t.String())
+	return trace.Wrap(err)
+}
+
+// WriteJSON serializes collection into JSON format
+func (c *clusterRosterCollection) WriteJSON(w io.Writer) error {
+	return utils.WriteJSON(c, w)
+}
+
+// WriteYAML serializes collection into YAML format
+func (c *clusterRosterCollection) WriteYAML(w io.Writer) error {
+	return utils.WriteYAML(c, w)
+}
+
+// ToMarshal returns object that should be marshaled.
+func (c *clusterRosterCollection) ToMarshal() interface{} {
+	return c.roster
+}
+
+type imageTrustPolicyCollection struct {
+	policy storage.ImageTrustPolicy
+}
+
+// Resources returns the resources collection in the generic format
+func (c *imageTrustPolicyCollection) Resources() ([]teleservices.UnknownResource, error) {
+	resource, err := utils.ToUnknownResource(c.policy)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return []teleservices.UnknownResource{*resource}, nil
+}
+
+// WriteText serializes the image trust policy in human-friendly text format
+func (c *imageTrustPolicyCollection) WriteText(w io.Writer) error {
+	t := goterm.NewTable(0, 10, 5, ' ', 0)
+	common.PrintTableHeader(t, []string{"Mode", "Public Keys"})
+	fmt.Fprintf(t, "%v\t%v\n", c.policy.GetMode(), len(c.policy.GetPublicKeys()))
+	_, err := io.WriteString(w, t.String())
+	return trace.Wrap(err)
+}
+
+// WriteJSON serializes collection into JSON format
+func (c *imageTrustPolicyCollection) WriteJSON(w io.Writer) error {
+	return utils.WriteJSON(c, w)
+}
+
+// WriteYAML serializes collection into YAML format
+func (c *imageTrustPolicyCollection) WriteYAML(w io.Writer) error {
+	return utils.WriteYAML(c, w)
+}
+
+// ToMarshal returns object that should be marshaled.
+func (c *imageTrustPolicyCollection) ToMarshal() interface{} {
+	return c.policy
+}
+
+type operationApprovalPolicyCollection struct {
+	policy storage.OperationApprovalPolicy
+}
+
+// Resources returns the resources collection in the generic format
+func (c *operationApprovalPolicyCollection) Resources() ([]teleservices.UnknownResource, error) {
+	resource, err := utils.ToUnknownResource(c.policy)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return []teleservices.UnknownResource{*resource}, nil
+}
+
+// WriteText serializes the operation approval policy in human-friendly text format
+func (c *operationApprovalPolicyCollection) WriteText(w io.Writer) error {
+	t := goterm.NewTable(0, 10, 5, ' ', 0)
+	common.PrintTableHeader(t, []string{"Operations", "Approvers"})
+	approvers := "any user"
+	if len(c.policy.GetApprovers()) != 0 {
+		approvers = strings.Join(c.policy.GetApprovers(), ", ")
+	}
+	fmt.Fprintf(t, "%v\t%v\n", strings.Join(c.policy.GetOperations(), ", "), approvers)
+	_, err := io.WriteString(w, t.String())
+	return trace.Wrap(err)
+}
+
+// WriteJSON serializes collection into JSON format
+func (c *operationApprovalPolicyCollection) WriteJSON(w io.Writer) error {
+	return utils.WriteJSON(c, w)
+}
+
+// WriteYAML serializes collection into YAML format
+func (c *operationApprovalPolicyCollection) WriteYAML(w io.Writer) error {
+	return utils.WriteYAML(c, w)
+}
+
+// ToMarshal returns object that should be marshaled.
+func (c *operationApprovalPolicyCollection) ToMarshal() interface{} {
+	return c.policy
+}
+
+type catalogSyncScheduleCollection struct {
+	schedule storage.CatalogSyncSchedule
+}
+
+// Resources returns the resources collection in the generic format
+func (c *catalogSyncScheduleCollection) Resources() ([]teleservices.UnknownResource, error) {
+	resource, err := utils.ToUnknownResource(c.schedule)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return []teleservices.UnknownResource{*resource}, nil
+}
+
+// WriteText serializes the catalog sync schedule in human-friendly text format
+func (c *catalogSyncScheduleCollection) WriteText(w io.Writer) error {
+	t := goterm.NewTable(0, 10, 5, ' ', 0)
+	common.PrintTableHeader(t, []string{"Repository", "Charts", "Interval"})
+	charts := "all"
+	if len(c.schedule.GetCharts()) != 0 {
+		charts = strings.Join(c.schedule.GetCharts(), ", ")
+	}
+	fmt.Fprintf(t, "%v\t%v\t%v\n", c.schedule.GetRepository(), charts, c.schedule.GetInterval())
+	_, err := io.WriteString(w, t.String())
+	return trace.Wrap(err)
+}
+
+// WriteJSON serializes collection into JSON format
+func (c *catalogSyncScheduleCollection) WriteJSON(w io.Writer) error {
+	return utils.WriteJSON(c, w)
+}
+
+// WriteYAML serializes collection into YAML format
+func (c *catalogSyncScheduleCollection) WriteYAML(w io.Writer) error {
+	return utils.WriteYAML(c, w)
+}
+
+// ToMarshal returns object that should be marshaled.
+func (c *catalogSyncScheduleCollection) ToMarshal() interface{} {
+	return c.schedule
+}
+
+type backupScheduleCollection struct {
+	schedule storage.BackupSchedule
+}
+
+// Resources returns the resources collection in the generic format
+func (c *backupScheduleCollection) Resources() ([]teleservices.UnknownResource, error) {
+	resource, err := utils.ToUnknownResource(c.schedule)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return []teleservices.UnknownResource{*resource}, nil
+}
+
+// WriteText serializes the backup schedule in human-friendly text format
+func (c *backupScheduleCollection) WriteText(w io.Writer) error {
+	t := goterm.NewTable(0, 10, 5, ' ', 0)
+	common.PrintTableHeader(t, []string{"Schedule", "Directory", "Retain"})
+	fmt.Fprintf(t, "%v\t%v\t%v\n", c.schedule.GetSchedule(), c.schedule.GetDirectory(), c.schedule.GetRetain())
+	_, err := io.WriteString(w, t.String())
+	return trace.Wrap(err)
+}
+
+// WriteJSON serializes collection into JSON format
+func (c *backupScheduleCollection) WriteJSON(w io.Writer) error {
+	return utils.WriteJSON(c, w)
+}
+
+// WriteYAML serializes collection into YAML format
+func (c *backupScheduleCollection) WriteYAML(w io.Writer) error {
+	return utils.WriteYAML(c, w)
+}
+
+// ToMarshal returns object that should be marshaled.
+func (c *backupScheduleCollection) ToMarshal() interface{} {
+	return c.schedule
+}
+
+type replicationCollection struct {
+	replication storage.Replication
+}
+
+// Resources returns the resources collection in the generic format
+func (c *replicationCollection) Resources() ([]teleservices.UnknownResource, error) {
+	resource, err := utils.ToUnknownResource(c.replication)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return []teleservices.UnknownResource{*resource}, nil
+}
+
+// WriteText serializes the replication in human-friendly text format
+func (c *replicationCollection) WriteText(w io.Writer) error {
+	t := goterm.NewTable(0, 10, 5, ' ', 0)
+	common.PrintTableHeader(t, []string{"Source", "Interval", "Directory", "Retain"})
+	fmt.Fprintf(t, "%v\t%v\t%v\t%v\n", c.replication.GetSource(), c.replication.GetInterval(),
+		c.replication.GetDirectory(), c.replication.GetRetain())
+	_, err := io.WriteString(w, t.String())
+	return trace.Wrap(err)
+}
+
+// WriteJSON serializes collection into JSON format
+func (c *replicationCollection) WriteJSON(w io.Writer) error {
+	return utils.WriteJSON(c, w)
+}
+
+// WriteYAML serializes collection into YAML format
+func (c *replicationCollection) WriteYAML(w io.Writer) error {
+	return utils.WriteYAML(c, w)
+}
+
+// ToMarshal returns object that should be marshaled.
+func (c *replicationCollection) ToMarshal() interface{} {
+	return c.replication
+}
+
+type operationWebhookCollection struct {
+	webhooks []storage.OperationWebhook
+}
+
+// Resources returns the resources collection in the generic format
+func (c *operationWebhookCollection) Resources() (resources []teleservices.UnknownResource, err error) {
+	for _, item := range c.webhooks {
+		resource, err := utils.ToUnknownResource(item)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		resources = append(resources, *resource)
+	}
+	return resources, nil
+}
+
+// WriteText serializes collection in human-friendly text format
+func (c *operationWebhookCollection) WriteText(w io.Writer) error {
+	t := goterm.NewTable(0, 10, 5, ' ', 0)
+	common.PrintTableHeader(t, []string{"Name", "URL", "Operations", "Timeout", "Failure Policy"})
+	for _, webhook := range c.webhooks {
+		operations := "*"
+		if len(webhook.GetOperations()) != 0 {
+			operations = strings.Join(webhook.GetOperations(), ", ")
+		}
+		fmt.Fprintf(t, "%v\t%v\t%v\t%v\t%v\n",
+			webhook.GetName(),
+			webhook.GetURL(),
+			operations,
+			webhook.GetTimeout(),
+			webhook.GetFailurePolicy())
+	}
+	_, err := io.WriteString(w, t.String())
+	return trace.Wrap(err)
+}
+
+// WriteJSON serializes collection into JSON format
+func (c *operationWebhookCollection) WriteJSON(w io.Writer) error {
+	return utils.WriteJSON(c, w)
+}
+
+// WriteYAML serializes collection into YAML format
+func (c *operationWebhookCollection) WriteYAML(w io.Writer) error {
+	return utils.WriteYAML(c, w)
+}
+
+// ToMarshal returns object that should be marshaled.
+func (c *operationWebhookCollection) ToMarshal() interface{} {
+	if len(c.webhooks) == 1 {
+		return c.webhooks[0]
+	}
+	return c.webhooks
+}
+
+type healthCheckCollection struct {
+	checks []storage.HealthCheck
+	// status is the result of the last evaluation of the health checks
+	status *storage.HealthCheckStatus
+}
+
+// Resources returns the resources collection in the generic format
+func (c *healthCheckCollection) Resources() (resources []teleservices.UnknownResource, err error) {
+	for _, item := range c.checks {
+		resource, err := utils.ToUnknownResource(item)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		resources = append(resources, *resource)
+	}
+	return resources, nil
+}
+
+// WriteText serializes collection in human-friendly text format
+func (c *healthCheckCollection) WriteText(w io.Writer) error {
+	t := goterm.NewTable(0, 10, 5, ' ', 0)
+	common.PrintTableHeader(t, []string{"Name", "Type", "Target", "Severity", "Status"})
+	for _, check := range c.checks {
+		fmt.Fprintf(t, "%v\t%v\t%v\t%v\t%v\n",
+			check.GetName(),
+			check.GetType(),
+			healthCheckTarget(check),
+			check.GetSeverity(),
+			c.describeStatus(check.GetName()))
+	}
+	_, err := io.WriteString(w, t.String())
+	return trace.Wrap(err)
+}
+
+// describeStatus returns the result of the last evaluation
+// of the health check with the specified name
+func (c *healthCheckCollection) describeStatus(name string) string {
+	if c.status == nil {
+		return "unknown"
+	}
+	for _, result := range c.status.Results {
+		if result.Name != name {
+			continue
+		}
+		if result.Healthy {
+			return "healthy"
+		}
+		return fmt.Sprintf("failed: %v", result.Message)
+	}
+	return "unknown"
+}
+
+// healthCheckTarget describes what the specified health check probes
+func healthCheckTarget(check storage.HealthCheck) string {
+	switch {
+	case check.GetHTTP() != nil:
+		return check.GetHTTP().URL
+	case check.GetCommand() != nil:
+		return strings.Join(check.GetCommand().Command, " ")
+	case check.GetKubernetes() != nil:
+		probe := check.GetKubernetes()
+		target := fmt.Sprintf("%v/%v", probe.Kind, probe.Name)
+		if probe.Namespace != "" {
+			target = fmt.Sprintf("%v/%v/%v", probe.Kind, probe.Namespace, probe.Name)
+		}
+		return fmt.Sprintf("%v %v=%v", target, probe.Condition, probe.GetStatus())
+	}
+	return ""
+}
+
+// WriteJSON serializes collection into JSON format
+func (c *healthCheckCollection) WriteJSON(w io.Writer) error {
+	return utils.WriteJSON(c, w)
+}
+
+// WriteYAML serializes collection into YAML format
+func (c *healthCheckCollection) WriteYAML(w io.Writer) error {
+	return utils.WriteYAML(c, w)
+}
+
+// ToMarshal returns object that should be marshaled.
+func (c *healthCheckCollection) ToMarshal() interface{} {
+	if len(c.checks) == 1 {
+		return c.checks[0]
+	}
+	return c.checks
+}
+
 // WriteText serializes collection in human-friendly text format
 func (r envCollection) WriteText(w io.Writer) error {
 	t := goterm.NewTable(0, 10, 5, ' ', 0)
@@ -681,6 +1249,21 @@ func (r configCollection) WriteText(w io.Writer) error {
 			fmt.Fprintf(t, "FeatureGates:\t%v\n", formatFeatureGates(config.FeatureGates))
 		}
 	}
+	if audit := r.GetAudit(); len(audit.Policy) != 0 || audit.Webhook != nil {
+		common.PrintCustomTableHeader(t, []string{"Audit"}, "-")
+		if len(audit.Policy) != 0 {
+			fmt.Fprintf(t, "Policy:\tcustom\n")
+		}
+		if audit.Log.Disabled {
+			fmt.Fprintf(t, "Log:\tdisabled\n")
+		} else {
+			fmt.Fprintf(t, "Log:\t%v format, rotated at %vMB, %v backups kept for %v days\n",
+				audit.Log.Format, audit.Log.MaxSize, audit.Log.MaxBackup, audit.Log.MaxAge)
+		}
+		if audit.Webhook != nil {
+			fmt.Fprintf(t, "Webhook:\tenabled\n")
+		}
+	}
 	_, err := io.WriteString(w, t.String())
 	return trace.Wrap(err)
 }
@@ -98,6 +98,27 @@ func (r *Resources) Create(ctx context.Context, req resources.CreateRequest) err
 			return trace.Wrap(err)
 		}
 		r.Printf("Created user %q\n", user.GetName())
+	case storage.KindServiceAccount:
+		account, err := storage.UnmarshalServiceAccount(req.Resource.Raw)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if !req.Upsert {
+			accounts, err := r.Operator.GetServiceAccounts(req.SiteKey)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			for _, existing := range accounts {
+				if existing.GetName() == account.GetName() {
+					return trace.AlreadyExists("service account %q already exists", account.GetName())
+				}
+			}
+		}
+		if err := r.Operator.UpsertServiceAccount(ctx, req.SiteKey, account); err != nil {
+			return trace.Wrap(err)
+		}
+		r.Printf("Created service account %q, use token resource with user %q to issue API keys\n",
+			account.GetName(), account.GetName())
 	case storage.KindToken:
 		token, err := storage.GetTokenMarshaler().UnmarshalToken(req.Resource.Raw)
 		if err != nil {
@@ -191,6 +212,139 @@ func (r *Resources) Create(ctx context.Context, req resources.CreateRequest) err
 			return trace.Wrap(err)
 		}
 		r.Println("Updated cluster SMTP configuration")
+	case storage.KindClusterDNS:
+		config, err := storage.UnmarshalClusterDNS(req.Resource.Raw)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		err = r.Operator.UpdateClusterDNS(ctx, req.SiteKey, config)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		r.Println("Updated cluster DNS configuration")
+	case storage.KindNodePool:
+		pool, err := storage.UnmarshalNodePool(req.Resource.Raw)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if !req.Upsert {
+			pools, err := r.Operator.GetNodePools(req.SiteKey)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			for _, existing := range pools {
+				if existing.GetName() == pool.GetName() {
+					return trace.AlreadyExists("node pool %q already exists", pool.GetName())
+				}
+			}
+		}
+		err = r.Operator.UpsertNodePool(ctx, req.SiteKey, pool)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		r.Printf("Created node pool %q\n", pool.GetName())
+	case storage.KindOperationWebhook:
+		webhook, err := storage.UnmarshalOperationWebhook(req.Resource.Raw)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if !req.Upsert {
+			webhooks, err := r.Operator.GetOperationWebhooks(req.SiteKey)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			for _, existing := range webhooks {
+				if existing.GetName() == webhook.GetName() {
+					return trace.AlreadyExists("operation webhook %q already exists", webhook.GetName())
+				}
+			}
+		}
+		err = r.Operator.UpsertOperationWebhook(ctx, req.SiteKey, webhook)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		r.Printf("Created operation webhook %q\n", webhook.GetName())
+	case storage.KindHealthCheck:
+		check, err := storage.UnmarshalHealthCheck(req.Resource.Raw)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if !req.Upsert {
+			checks, err := r.Operator.GetHealthChecks(req.SiteKey)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			for _, existing := range checks {
+				if existing.GetName() == check.GetName() {
+					return trace.AlreadyExists("health check %q already exists", check.GetName())
+				}
+			}
+		}
+		err = r.Operator.UpsertHealthCheck(ctx, req.SiteKey, check)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		r.Printf("Created health check %q\n", check.GetName())
+	case storage.KindClusterRoster:
+		roster, err := storage.UnmarshalClusterRoster(req.Resource.Raw)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		err = r.Operator.UpsertClusterRoster(ctx, req.SiteKey, roster)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		r.Println("Updated cluster roster")
+	case storage.KindImageTrustPolicy:
+		policy, err := storage.UnmarshalImageTrustPolicy(req.Resource.Raw)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		err = r.Operator.UpsertImageTrustPolicy(ctx, req.SiteKey, policy)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		r.Println("Updated image trust policy")
+	case storage.KindOperationApprovalPolicy:
+		policy, err := storage.UnmarshalOperationApprovalPolicy(req.Resource.Raw)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		err = r.Operator.UpsertOperationApprovalPolicy(ctx, req.SiteKey, policy)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		r.Println("Updated operation approval policy")
+	case storage.KindCatalogSyncSchedule:
+		schedule, err := storage.UnmarshalCatalogSyncSchedule(req.Resource.Raw)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		err = r.Operator.UpsertCatalogSyncSchedule(ctx, req.SiteKey, schedule)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		r.Println("Updated catalog sync schedule")
+	case storage.KindBackupSchedule:
+		schedule, err := storage.UnmarshalBackupSchedule(req.Resource.Raw)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		err = r.Operator.UpsertBackupSchedule(ctx, req.SiteKey, schedule)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		r.Println("Updated backup schedule")
+	case storage.KindReplication:
+		replication, err := storage.UnmarshalReplication(req.Resource.Raw)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		err = r.Operator.UpsertReplication(ctx, req.SiteKey, replication)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		r.Println("Updated replication")
 	case storage.KindAlert:
 		alert, err := storage.UnmarshalAlert(req.Resource.Raw)
 		if err != nil {
@@ -205,18 +359,6 @@ func (r *Resources) Create(ctx context.Context, req resources.CreateRequest) err
 		}
 		r.Printf("Updated monitoring alert %q\n", alert.GetName())
 	case storage.KindAlertTarget:
-		// Alert recipient can be created only if SMTP settings
-		// are present, otherwise it will result into invalid
-		// Alertmanager configuration.
-		if _, err := r.Operator.GetSMTPConfig(req.SiteKey); err != nil {
-			if trace.IsNotFound(err) {
-				return trace.BadParameter("alert target can only " +
-					"be created when cluster SMTP settings " +
-					"are configured, please create SMTP " +
-					"resource first: https://gravitational.com/gravity/docs/cluster/#configuring-monitoring")
-			}
-			return trace.Wrap(err)
-		}
 		target, err := storage.UnmarshalAlertTarget(req.Resource.Raw)
 		if err != nil {
 			return trace.Wrap(err)
@@ -224,6 +366,20 @@ func (r *Resources) Create(ctx context.Context, req resources.CreateRequest) err
 		if err := target.CheckAndSetDefaults(); err != nil {
 			return trace.Wrap(err)
 		}
+		// Email alert recipient can be created only if SMTP settings
+		// are present, otherwise it will result into invalid
+		// Alertmanager configuration.
+		if target.GetEmail() != "" {
+			if _, err := r.Operator.GetSMTPConfig(req.SiteKey); err != nil {
+				if trace.IsNotFound(err) {
+					return trace.BadParameter("email alert target can only " +
+						"be created when cluster SMTP settings " +
+						"are configured, please create SMTP " +
+						"resource first: https://gravitational.com/gravity/docs/cluster/#configuring-monitoring")
+				}
+				return trace.Wrap(err)
+			}
+		}
 		err = r.Operator.UpdateAlertTarget(ctx, req.SiteKey, target)
 		if err != nil {
 			return trace.Wrap(err)
@@ -284,6 +440,27 @@ func (r *Resources) GetCollection(req resources.ListRequest) (resources.Collecti
 			return nil, trace.Wrap(err)
 		}
 		return &userCollection{users: users}, nil
+	case storage.KindServiceAccount:
+		accounts, err := r.Operator.GetServiceAccounts(req.SiteKey)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		collection := serviceAccountCollection{keys: make(map[string]int)}
+		for _, account := range accounts {
+			if req.Name != "" && req.Name != account.GetName() {
+				continue
+			}
+			keys, err := r.Operator.GetAPIKeys(account.GetName())
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			collection.accounts = append(collection.accounts, account)
+			collection.keys[account.GetName()] = len(keys)
+		}
+		if req.Name != "" && len(collection.accounts) == 0 {
+			return nil, trace.NotFound("service account %q is not found", req.Name)
+		}
+		return &collection, nil
 	case storage.KindToken:
 		if req.User == "" {
 			return nil, trace.BadParameter("please specify user via --user flag")
@@ -357,6 +534,105 @@ func (r *Resources) GetCollection(req resources.ListRequest) (resources.Collecti
 			return nil, trace.Wrap(err)
 		}
 		return smtpConfigCollection{config}, nil
+	case storage.KindClusterDNS:
+		config, err := r.Operator.GetClusterDNS(req.SiteKey)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &clusterDNSCollection{item: config}, nil
+	case storage.KindNodePool:
+		pools, err := r.Operator.GetNodePools(req.SiteKey)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		cluster, err := r.Operator.GetSite(req.SiteKey)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		var filtered []storage.NodePool
+		for _, pool := range pools {
+			if req.Name == "" || pool.GetName() == req.Name {
+				filtered = append(filtered, pool)
+			}
+		}
+		if req.Name != "" && len(filtered) == 0 {
+			return nil, trace.NotFound("node pool %q is not found", req.Name)
+		}
+		return &nodePoolCollection{pools: filtered, servers: cluster.ClusterState.Servers}, nil
+	case storage.KindOperationWebhook:
+		webhooks, err := r.Operator.GetOperationWebhooks(req.SiteKey)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		var filtered []storage.OperationWebhook
+		for _, webhook := range webhooks {
+			if req.Name == "" || webhook.GetName() == req.Name {
+				filtered = append(filtered, webhook)
+			}
+		}
+		if req.Name != "" && len(filtered) == 0 {
+			return nil, trace.NotFound("operation webhook %q is not found", req.Name)
+		}
+		return &operationWebhookCollection{webhooks: filtered}, nil
+	case storage.KindHealthCheck:
+		checks, err := r.Operator.GetHealthChecks(req.SiteKey)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		var filtered []storage.HealthCheck
+		for _, check := range checks {
+			if req.Name == "" || check.GetName() == req.Name {
+				filtered = append(filtered, check)
+			}
+		}
+		if req.Name != "" && len(filtered) == 0 {
+			return nil, trace.NotFound("health check %q is not found", req.Name)
+		}
+		status, err := r.Operator.GetHealthCheckStatus(req.SiteKey)
+		if err != nil && !trace.IsNotFound(err) {
+			return nil, trace.Wrap(err)
+		}
+		return &healthCheckCollection{checks: filtered, status: status}, nil
+	case storage.KindClusterRoster:
+		roster, err := r.Operator.GetClusterRoster(req.SiteKey)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		cluster, err := r.Operator.GetSite(req.SiteKey)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &clusterRosterCollection{roster: roster, servers: cluster.ClusterState.Servers}, nil
+	case storage.KindImageTrustPolicy:
+		policy, err := r.Operator.GetImageTrustPolicy(req.SiteKey)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &imageTrustPolicyCollection{policy: policy}, nil
+	case storage.KindOperationApprovalPolicy:
+		policy, err := r.Operator.GetOperationApprovalPolicy(req.SiteKey)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &operationApprovalPolicyCollection{policy: policy}, nil
+	case storage.KindCatalogSyncSchedule:
+		schedule, err := r.Operator.GetCatalogSyncSchedule(req.SiteKey)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &catalogSyncScheduleCollection{schedule: schedule}, nil
+	case storage.KindBackupSchedule:
+		schedule, err := r.Operator.GetBackupSchedule(req.SiteKey)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &backupScheduleCollection{schedule: schedule}, nil
+	case storage.KindReplication:
+		replication, err := r.Operator.GetReplication(req.SiteKey)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &replicationCollection{replication: replication}, nil
 	case storage.KindAlert:
 		alerts, err := r.Operator.GetAlerts(req.SiteKey)
 		if err != nil {
@@ -425,6 +701,14 @@ func (r *Resources) Remove(ctx context.Context, req resources.RemoveRequest) err
 			return trace.Wrap(err)
 		}
 		r.Printf("User %q has been deleted\n", req.Name)
+	case storage.KindServiceAccount:
+		if err := r.Operator.DeleteServiceAccount(ctx, req.SiteKey, req.Name); err != nil {
+			if trace.IsNotFound(err) && req.Force {
+				return nil
+			}
+			return trace.Wrap(err)
+		}
+		r.Printf("Service account %q and its API keys have been deleted\n", req.Name)
 	case storage.KindToken:
 		user := req.Owner
 		if user == "" {
@@ -447,6 +731,78 @@ func (r *Resources) Remove(ctx context.Context, req resources.RemoveRequest) err
 			return trace.Wrap(err)
 		}
 		r.Printf("Log forwarder %q has been deleted\n", req.Name)
+	case storage.KindNodePool:
+		if err := r.Operator.DeleteNodePool(ctx, req.SiteKey, req.Name); err != nil {
+			if trace.IsNotFound(err) && req.Force {
+				return nil
+			}
+			return trace.Wrap(err)
+		}
+		r.Printf("Node pool %q has been deleted\n", req.Name)
+	case storage.KindOperationWebhook:
+		if err := r.Operator.DeleteOperationWebhook(ctx, req.SiteKey, req.Name); err != nil {
+			if trace.IsNotFound(err) && req.Force {
+				return nil
+			}
+			return trace.Wrap(err)
+		}
+		r.Printf("Operation webhook %q has been deleted\n", req.Name)
+	case storage.KindHealthCheck:
+		if err := r.Operator.DeleteHealthCheck(ctx, req.SiteKey, req.Name); err != nil {
+			if trace.IsNotFound(err) && req.Force {
+				return nil
+			}
+			return trace.Wrap(err)
+		}
+		r.Printf("Health check %q has been deleted\n", req.Name)
+	case storage.KindClusterRoster:
+		if err := r.Operator.DeleteClusterRoster(ctx, req.SiteKey); err != nil {
+			if trace.IsNotFound(err) && req.Force {
+				return nil
+			}
+			return trace.Wrap(err)
+		}
+		r.Println("Cluster roster has been deleted")
+	case storage.KindImageTrustPolicy:
+		if err := r.Operator.DeleteImageTrustPolicy(ctx, req.SiteKey); err != nil {
+			if trace.IsNotFound(err) && req.Force {
+				return nil
+			}
+			return trace.Wrap(err)
+		}
+		r.Println("Image trust policy has been deleted")
+	case storage.KindOperationApprovalPolicy:
+		if err := r.Operator.DeleteOperationApprovalPolicy(ctx, req.SiteKey); err != nil {
+			if trace.IsNotFound(err) && req.Force {
+				return nil
+			}
+			return trace.Wrap(err)
+		}
+		r.Println("Operation approval policy has been deleted")
+	case storage.KindCatalogSyncSchedule:
+		if err := r.Operator.DeleteCatalogSyncSchedule(ctx, req.SiteKey); err != nil {
+			if trace.IsNotFound(err) && req.Force {
+				return nil
+			}
+			return trace.Wrap(err)
+		}
+		r.Println("Catalog sync schedule has been deleted")
+	case storage.KindBackupSchedule:
+		if err := r.Operator.DeleteBackupSchedule(ctx, req.SiteKey); err != nil {
+			if trace.IsNotFound(err) && req.Force {
+				return nil
+			}
+			return trace.Wrap(err)
+		}
+		r.Println("Backup schedule has been deleted")
+	case storage.KindReplication:
+		if err := r.Operator.DeleteReplication(ctx, req.SiteKey); err != nil {
+			if trace.IsNotFound(err) && req.Force {
+				return nil
+			}
+			return trace.Wrap(err)
+		}
+		r.Println("Replication has been deleted")
 	case storage.KindTLSKeyPair:
 		if err := r.Operator.DeleteClusterCertificate(ctx, req.SiteKey); err != nil {
 			if trace.IsNotFound(err) && req.Force {
@@ -457,17 +813,19 @@ func (r *Resources) Remove(ctx context.Context, req resources.RemoveRequest) err
 		r.Printf("TLS key pair %q has been deleted\n", req.Name)
 	case storage.KindSMTPConfig:
 		// SMTP configuration can be deleted only if there is no
-		// alert recipient configured, otherwise it will result
+		// email alert recipient configured, otherwise it will result
 		// into invalid Alertmanager configuration.
 		alertTargets, err := r.Operator.GetAlertTargets(req.SiteKey)
 		if err != nil && !trace.IsNotFound(err) {
 			return trace.Wrap(err)
 		}
-		if len(alertTargets) != 0 {
-			return trace.BadParameter("SMTP configuration can " +
-				"only be deleted if there is no alert target, " +
-				"please remove alert target using 'gravity " +
-				"resource rm alerttarget' first")
+		for _, target := range alertTargets {
+			if target.GetEmail() != "" {
+				return trace.BadParameter("SMTP configuration can " +
+					"only be deleted if there is no email alert target, " +
+					"please remove alert target using 'gravity " +
+					"resource rm alerttarget' first")
+			}
 		}
 		if err := r.Operator.DeleteSMTPConfig(ctx, req.SiteKey); err != nil {
 			if trace.IsNotFound(err) && req.Force {
@@ -520,6 +878,8 @@ func Validate(resource storage.UnknownResource) (err error) {
 		_, err = teleservices.GetGithubConnectorMarshaler().Unmarshal(resource.Raw)
 	case teleservices.KindUser:
 		_, err = teleservices.GetUserMarshaler().UnmarshalUser(resource.Raw)
+	case storage.KindServiceAccount:
+		_, err = storage.UnmarshalServiceAccount(resource.Raw)
 	case storage.KindToken:
 		_, err = storage.GetTokenMarshaler().UnmarshalToken(resource.Raw)
 	case storage.KindLogForwarder:
@@ -530,6 +890,26 @@ func Validate(resource storage.UnknownResource) (err error) {
 		_, err = teleservices.GetAuthPreferenceMarshaler().Unmarshal(resource.Raw)
 	case storage.KindSMTPConfig:
 		_, err = storage.UnmarshalSMTPConfig(resource.Raw)
+	case storage.KindClusterDNS:
+		_, err = storage.UnmarshalClusterDNS(resource.Raw)
+	case storage.KindNodePool:
+		_, err = storage.UnmarshalNodePool(resource.Raw)
+	case storage.KindOperationWebhook:
+		_, err = storage.UnmarshalOperationWebhook(resource.Raw)
+	case storage.KindHealthCheck:
+		_, err = storage.UnmarshalHealthCheck(resource.Raw)
+	case storage.KindClusterRoster:
+		_, err = storage.UnmarshalClusterRoster(resource.Raw)
+	case storage.KindImageTrustPolicy:
+		_, err = storage.UnmarshalImageTrustPolicy(resource.Raw)
+	case storage.KindOperationApprovalPolicy:
+		_, err = storage.UnmarshalOperationApprovalPolicy(resource.Raw)
+	case storage.KindCatalogSyncSchedule:
+		_, err = storage.UnmarshalCatalogSyncSchedule(resource.Raw)
+	case storage.KindBackupSchedule:
+		_, err = storage.UnmarshalBackupSchedule(resource.Raw)
+	case storage.KindReplication:
+		_, err = storage.UnmarshalReplication(resource.Raw)
 	case storage.KindAlert:
 		_, err = storage.UnmarshalAlert(resource.Raw)
 	case storage.KindAlertTarget:
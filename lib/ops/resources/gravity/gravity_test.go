@@ -23,6 +23,7 @@ import (
 
 	"github.com/gravitational/gravity/lib/compare"
 	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/httplib"
 	"github.com/gravitational/gravity/lib/localenv"
 	"github.com/gravitational/gravity/lib/ops"
 	"github.com/gravitational/gravity/lib/ops/opsclient"
@@ -109,6 +110,47 @@ func (s *GravityResourcesSuite) TestUser(c *check.C) {
 	c.Assert(err, check.FitsTypeOf, trace.NotFound(""))
 }
 
+func (s *GravityResourcesSuite) TestServiceAccount(c *check.C) {
+	account := storage.NewServiceAccount("jenkins", storage.ServiceAccountSpecV2{
+		Roles: []string{"@teleadmin"},
+	})
+	err := s.r.Create(context.TODO(), resources.CreateRequest{SiteKey: s.cluster.Key(), Resource: toUnknown(c, account)})
+	c.Assert(err, check.IsNil)
+
+	err = s.r.Create(context.TODO(), resources.CreateRequest{SiteKey: s.cluster.Key(), Resource: toUnknown(c, account)})
+	c.Assert(trace.IsAlreadyExists(err), check.Equals, true, check.Commentf("%v", err))
+
+	token := storage.NewToken("jenkins-token", "jenkins")
+	err = s.r.Create(context.TODO(), resources.CreateRequest{SiteKey: s.cluster.Key(), Resource: toUnknown(c, token)})
+	c.Assert(err, check.IsNil)
+
+	collection, err := s.r.GetCollection(resources.ListRequest{SiteKey: s.cluster.Key(), Kind: storage.KindServiceAccount, Name: "jenkins"})
+	c.Assert(err, check.IsNil)
+	compare.DeepCompare(c, collection, &serviceAccountCollection{
+		accounts: []storage.ServiceAccount{account},
+		keys:     map[string]int{"jenkins": 1},
+	})
+
+	user, _, err := s.s.Services.Users.AuthenticateUser(httplib.AuthCreds{
+		Type:     httplib.AuthBasic,
+		Username: "jenkins",
+		Password: "jenkins-token",
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(user.GetType(), check.Equals, storage.ServiceAccountUser)
+
+	err = s.r.Remove(context.TODO(), resources.RemoveRequest{SiteKey: s.cluster.Key(), Kind: storage.KindServiceAccount, Name: "jenkins"})
+	c.Assert(err, check.IsNil)
+
+	_, _, err = s.s.Services.Users.AuthenticateUser(httplib.AuthCreds{
+		Type:     httplib.AuthBearer,
+		Password: "jenkins-token",
+	})
+	c.Assert(err, check.NotNil)
+	_, err = s.r.GetCollection(resources.ListRequest{SiteKey: s.cluster.Key(), Kind: storage.KindServiceAccount, Name: "jenkins"})
+	c.Assert(trace.IsNotFound(err), check.Equals, true, check.Commentf("%v", err))
+}
+
 func (s *GravityResourcesSuite) TestToken(c *check.C) {
 	token := storage.NewToken("test", s.s.Creds.Email)
 
@@ -95,6 +95,7 @@ func ConvertOpsSite(in Site) storage.Site {
 		CloudConfig:     in.CloudConfig,
 		DNSOverrides:    in.DNSOverrides,
 		DNSConfig:       in.DNSConfig,
+		Demo:            in.Demo,
 	}
 	if in.License != nil {
 		cluster.License = in.License.Raw
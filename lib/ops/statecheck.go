@@ -0,0 +1,174 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ops
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// CheckState validates the referential integrity of the state stored in the
+// specified backend and returns the inconsistencies found:
+//
+//   - unfinished operations that cannot be resumed since they have no plan
+//   - references to packages that do not exist
+//   - progress entries of operations that do not exist
+//   - expired tokens
+func CheckState(ctx context.Context, backend storage.Backend) (problems []storage.StateProblem, err error) {
+	clusters, err := backend.GetAllSites()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, cluster := range clusters {
+		if err := ctx.Err(); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		problem, err := checkPackage(backend, cluster.App.Locator(), cluster.Domain)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if problem != nil {
+			problems = append(problems, *problem)
+		}
+		operations, err := backend.GetSiteOperations(cluster.Domain)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		for _, operation := range operations {
+			operationProblems, err := checkOperation(backend, SiteOperation(operation))
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			problems = append(problems, operationProblems...)
+		}
+	}
+	if checker, ok := backend.(storage.StateChecker); ok {
+		backendProblems, err := checker.CheckState(ctx)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		problems = append(problems, backendProblems...)
+	}
+	return problems, nil
+}
+
+// FixState repairs the fixable problems among the specified ones
+// and returns the number of repaired problems
+func FixState(ctx context.Context, backend storage.Backend, problems []storage.StateProblem) (fixed int, err error) {
+	for _, problem := range problems {
+		if !problem.Fixable {
+			continue
+		}
+		switch problem.Kind {
+		case storage.StateProblemOperationWithoutPlan:
+			err = failOperation(backend, problem.Key)
+		default:
+			checker, ok := backend.(storage.StateChecker)
+			if !ok {
+				return fixed, trace.BadParameter("backend %T cannot fix %v", backend, problem)
+			}
+			err = checker.FixStateProblem(ctx, problem)
+		}
+		if err != nil && !trace.IsNotFound(err) {
+			return fixed, trace.Wrap(err, "failed to fix %v", problem)
+		}
+		fixed++
+	}
+	return fixed, nil
+}
+
+func checkOperation(backend storage.Backend, operation SiteOperation) (problems []storage.StateProblem, err error) {
+	if operation.IsFinished() {
+		return nil, nil
+	}
+	key := []string{operation.SiteDomain, operation.ID}
+	if operationsWithPlan[operation.State] {
+		_, err := backend.GetOperationPlan(operation.SiteDomain, operation.ID)
+		if err != nil && !trace.IsNotFound(err) {
+			return nil, trace.Wrap(err)
+		}
+		if trace.IsNotFound(err) {
+			problems = append(problems, storage.StateProblem{
+				Kind:    storage.StateProblemOperationWithoutPlan,
+				Key:     key,
+				Message: fmt.Sprintf("%v cannot be resumed since it has no plan", operation.String()),
+				Fixable: true,
+			})
+		}
+	}
+	if operation.Update != nil && operation.Update.UpdatePackage != "" {
+		locator, err := loc.ParseLocator(operation.Update.UpdatePackage)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		problem, err := checkPackage(backend, *locator, key...)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if problem != nil {
+			problems = append(problems, *problem)
+		}
+	}
+	return problems, nil
+}
+
+// checkPackage returns a problem if the specified package does not exist
+func checkPackage(backend storage.Backend, locator loc.Locator, key ...string) (*storage.StateProblem, error) {
+	_, err := backend.GetPackage(locator.Repository, locator.Name, locator.Version)
+	if err == nil {
+		return nil, nil
+	}
+	if !trace.IsNotFound(err) {
+		return nil, trace.Wrap(err)
+	}
+	return &storage.StateProblem{
+		Kind:    storage.StateProblemDanglingPackage,
+		Key:     key,
+		Message: fmt.Sprintf("package %v does not exist", locator),
+	}, nil
+}
+
+// failOperation marks the operation identified with the specified
+// [cluster name, operation ID] key as failed
+func failOperation(backend storage.Backend, key []string) error {
+	if len(key) != 2 {
+		return trace.BadParameter("expected cluster name and operation ID, got %v", key)
+	}
+	operation, err := backend.GetSiteOperation(key[0], key[1])
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	operation.State = OperationStateFailed
+	_, err = backend.UpdateSiteOperation(*operation)
+	return trace.Wrap(err)
+}
+
+// operationsWithPlan lists the states of the operations that are always
+// driven by an operation plan
+var operationsWithPlan = map[string]bool{
+	OperationStateUpdateInProgress:          true,
+	OperationGarbageCollectInProgress:       true,
+	OperationUpdateRuntimeEnvironInProgress: true,
+	OperationUpdateConfigInProgress:         true,
+	OperationUpdateNodeRoleInProgress:       true,
+	OperationReplaceNodeInProgress:          true,
+}
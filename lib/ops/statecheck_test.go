@@ -0,0 +1,126 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ops
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/keyval"
+
+	"github.com/gravitational/trace"
+	check "gopkg.in/check.v1"
+)
+
+type StateCheckSuite struct {
+	backend storage.Backend
+}
+
+var _ = check.Suite(&StateCheckSuite{})
+
+func (s *StateCheckSuite) SetUpTest(c *check.C) {
+	var err error
+	s.backend, err = keyval.NewBolt(keyval.BoltConfig{
+		Path: filepath.Join(c.MkDir(), "bolt.db"),
+	})
+	c.Assert(err, check.IsNil)
+}
+
+func (s *StateCheckSuite) TearDownTest(c *check.C) {
+	c.Assert(s.backend.Close(), check.IsNil)
+}
+
+func (s *StateCheckSuite) TestFindsAndFixesProblems(c *check.C) {
+	now := time.Now().UTC()
+	account, err := s.backend.CreateAccount(storage.Account{Org: "example.com"})
+	c.Assert(err, check.IsNil)
+	_, err = s.backend.CreateRepository(storage.NewRepository("gravitational.io"))
+	c.Assert(err, check.IsNil)
+	app, err := s.backend.CreatePackage(storage.Package{
+		Repository: "gravitational.io",
+		Name:       "app",
+		Version:    "0.0.1",
+	})
+	c.Assert(err, check.IsNil)
+	_, err = s.backend.CreateSite(storage.Site{
+		Created:   now,
+		AccountID: account.ID,
+		Domain:    "example.com",
+		App:       *app,
+	})
+	c.Assert(err, check.IsNil)
+	operation, err := s.backend.CreateSiteOperation(storage.SiteOperation{
+		AccountID:  account.ID,
+		SiteDomain: "example.com",
+		Type:       OperationUpdate,
+		Created:    now,
+		State:      OperationStateUpdateInProgress,
+		Update: &storage.UpdateOperationState{
+			UpdatePackage: "gravitational.io/app:0.0.2",
+		},
+	})
+	c.Assert(err, check.IsNil)
+	_, err = s.backend.CreateProgressEntry(storage.ProgressEntry{
+		SiteDomain:  "example.com",
+		OperationID: "missing",
+		Created:     now,
+	})
+	c.Assert(err, check.IsNil)
+	_, err = s.backend.CreateProvisioningToken(storage.ProvisioningToken{
+		Token:      "token",
+		Type:       storage.ProvisioningTokenTypeExpand,
+		AccountID:  account.ID,
+		SiteDomain: "example.com",
+		Expires:    now.Add(-time.Hour),
+	})
+	c.Assert(err, check.IsNil)
+
+	problems, err := CheckState(context.TODO(), s.backend)
+	c.Assert(err, check.IsNil)
+	c.Assert(kinds(problems), check.DeepEquals, []string{
+		storage.StateProblemOperationWithoutPlan,
+		storage.StateProblemDanglingPackage,
+		storage.StateProblemOrphanedProgress,
+		storage.StateProblemExpiredToken,
+	})
+
+	fixed, err := FixState(context.TODO(), s.backend, problems)
+	c.Assert(err, check.IsNil)
+	c.Assert(fixed, check.Equals, 3)
+
+	out, err := s.backend.GetSiteOperation("example.com", operation.ID)
+	c.Assert(err, check.IsNil)
+	c.Assert(out.State, check.Equals, OperationStateFailed)
+	_, err = s.backend.GetLastProgressEntry("example.com", "missing")
+	c.Assert(trace.IsNotFound(err), check.Equals, true, check.Commentf("%v", err))
+	_, err = s.backend.GetProvisioningToken("token")
+	c.Assert(trace.IsNotFound(err), check.Equals, true, check.Commentf("%v", err))
+
+	// Finished operations are not checked
+	problems, err = CheckState(context.TODO(), s.backend)
+	c.Assert(err, check.IsNil)
+	c.Assert(problems, check.HasLen, 0)
+}
+
+func kinds(problems []storage.StateProblem) (out []string) {
+	for _, problem := range problems {
+		out = append(out, problem.Kind)
+	}
+	return out
+}
@@ -301,6 +301,27 @@ func FailOperation(key SiteOperationKey, operator OperationStateSetter, message
 	})
 }
 
+// RollbackOperation marks the specified operation as failed and rolled back
+func RollbackOperation(key SiteOperationKey, operator OperationStateSetter, message string) error {
+	if message != "" {
+		message = fmt.Sprintf("Operation failure, rolled back: %v", message)
+	} else {
+		message = "Operation failure, rolled back"
+	}
+	return operator.SetOperationState(key, SetOperationStateRequest{
+		State: OperationStateFailedRolledBack,
+		Progress: &ProgressEntry{
+			SiteDomain:  key.SiteDomain,
+			OperationID: key.OperationID,
+			Step:        constants.FinalStep,
+			Completion:  constants.Completed,
+			State:       ProgressStateFailed,
+			Message:     strings.TrimSpace(message),
+			Created:     time.Now().UTC(),
+		},
+	})
+}
+
 // OperationStateSetter defines an interface to set/update operation state
 type OperationStateSetter interface {
 	// SetOperationState updates state of the operation
@@ -29,6 +29,8 @@ const (
 	AdvertiseIPLabel = "advertise-ip"
 	// OperationIDLabel contains ID of the operation the package was configured for
 	OperationIDLabel = "operation-id"
+	// DeltaBaseLabel contains the cluster image a delta cluster image has been built against
+	DeltaBaseLabel = "delta-base"
 
 	// PurposeCA marks the planet certificate authority package
 	PurposeCA = "ca"
@@ -36,6 +38,8 @@ const (
 	PurposeExport = "export"
 	// PurposeLicense marks the package with cluster license
 	PurposeLicense = "license"
+	// PurposeEncryptionConfig marks the package with the apiserver encryption configuration
+	PurposeEncryptionConfig = "encryption-config"
 	// PurposeResources marks the package with user resources
 	PurposeResources = "resources"
 	// PurposePlanetSecrets marks packages with planet secrets
@@ -271,6 +271,23 @@ func FindPackage(packages PackageService, fn func(e PackageEnvelope) bool) (*Pac
 	return env, trace.Wrap(err)
 }
 
+// IsPackageUpToDate returns true if the package service dst already has
+// the package specified with loc with the same contents as in the package service src
+func IsPackageUpToDate(src, dst PackageService, loc loc.Locator) (bool, error) {
+	srcEnv, err := src.ReadPackageEnvelope(loc)
+	if err != nil {
+		return false, trace.Wrap(err)
+	}
+	dstEnv, err := dst.ReadPackageEnvelope(loc)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return false, nil
+		}
+		return false, trace.Wrap(err)
+	}
+	return srcEnv.SHA512 == dstEnv.SHA512, nil
+}
+
 // ForeachPackage executes function fn for each package in
 // each repository
 func ForeachPackage(packages PackageService, fn func(e PackageEnvelope) error) error {
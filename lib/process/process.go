@@ -33,25 +33,36 @@ import (
 	"time"
 
 	"github.com/gravitational/gravity/lib/app"
+	dockerapp "github.com/gravitational/gravity/lib/app/docker"
 	apphandler "github.com/gravitational/gravity/lib/app/handler"
 	appservice "github.com/gravitational/gravity/lib/app/service"
 	"github.com/gravitational/gravity/lib/autoscale/aws"
+	"github.com/gravitational/gravity/lib/backup"
 	"github.com/gravitational/gravity/lib/blob"
 	blobclient "github.com/gravitational/gravity/lib/blob/client"
 	blobcluster "github.com/gravitational/gravity/lib/blob/cluster"
 	blobfs "github.com/gravitational/gravity/lib/blob/fs"
 	blobhandler "github.com/gravitational/gravity/lib/blob/handler"
+	blobs3 "github.com/gravitational/gravity/lib/blob/s3"
+	"github.com/gravitational/gravity/lib/catalog"
 	"github.com/gravitational/gravity/lib/clients"
 	cloudaws "github.com/gravitational/gravity/lib/cloudprovider/aws"
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/docker"
+	"github.com/gravitational/gravity/lib/etcd"
+	"github.com/gravitational/gravity/lib/expand/roster"
+	"github.com/gravitational/gravity/lib/fips"
 	"github.com/gravitational/gravity/lib/helm"
 	"github.com/gravitational/gravity/lib/httplib"
 	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/metrics"
 	"github.com/gravitational/gravity/lib/modules"
+	"github.com/gravitational/gravity/lib/network/wireguard"
 	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/controller"
 	"github.com/gravitational/gravity/lib/ops/monitoring"
+	"github.com/gravitational/gravity/lib/ops/opsgrpc"
 	"github.com/gravitational/gravity/lib/ops/opshandler"
 	"github.com/gravitational/gravity/lib/ops/opsroute"
 	"github.com/gravitational/gravity/lib/ops/opsservice"
@@ -91,7 +102,9 @@ import (
 	"github.com/julienschmidt/httprouter"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 type Process struct {
@@ -149,6 +162,8 @@ type Handlers struct {
 	Apps *apphandler.WebHandler
 	// Operator is ops service web handler
 	Operator *opshandler.WebHandler
+	// OperatorGRPC is ops service gRPC API handler
+	OperatorGRPC *opsgrpc.Server
 	// Web is web UI handler
 	Web *web.WebHandler
 	// WebProxy is Teleport web API handler
@@ -238,16 +253,28 @@ func New(ctx context.Context, cfg processconfig.Config, tcfg telecfg.FileConfig)
 		return nil, trace.Wrap(err)
 	}
 
-	clusterObjects, err := blobcluster.New(blobcluster.Config{
-		Local:         objects,
-		Backend:       backend,
-		GetPeer:       peerPool.GetPeer,
-		ID:            processID,
-		AdvertiseAddr: fmt.Sprintf("https://%v", peerAddr.Addr),
-		// TODO: set WriteFactor to the number of controller instances
-	})
-	if err != nil {
-		return nil, trace.Wrap(err)
+	var clusterObjects blob.Objects
+	if cfg.Pack.ObjectStorage.IsEnabled() {
+		// The object storage is shared by all controllers so BLOBs
+		// are neither stored locally nor replicated between peers
+		clusterObjects, err = blobs3.New(cfg.Pack.ObjectStorage.BLOBConfig(
+			filepath.Join(cfg.DataDir, defaults.PackagesDir, "tmp")))
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		objects = clusterObjects
+	} else {
+		clusterObjects, err = blobcluster.New(blobcluster.Config{
+			Local:         objects,
+			Backend:       backend,
+			GetPeer:       peerPool.GetPeer,
+			ID:            processID,
+			AdvertiseAddr: fmt.Sprintf("https://%v", peerAddr.Addr),
+			// TODO: set WriteFactor to the number of controller instances
+		})
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
 	}
 
 	packages, err := localpack.New(localpack.Config{
@@ -549,6 +576,366 @@ func (p *Process) startAutoscale(ctx context.Context) error {
 	return nil
 }
 
+// startRosterReconciler registers the service that reconciles the cluster
+// membership against the cluster roster
+func (p *Process) startRosterReconciler(client *kubernetes.Clientset) error {
+	reconciler, err := roster.NewReconciler(roster.ReconcilerConfig{
+		Operator: p.operator,
+		Backend:  p.backend,
+		Client:   client,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	p.RegisterClusterService(func(ctx context.Context) {
+		localCtx := context.WithValue(ctx, constants.UserContext,
+			constants.ServiceRosterReconciler)
+		reconciler.Run(localCtx)
+	})
+	return nil
+}
+
+// startOperationController registers the service that starts cluster
+// operations requested with the gravity custom resources
+func (p *Process) startOperationController() error {
+	config, err := tryGetPrivilegedKubeConfig()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := p.operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	operationController, err := controller.New(controller.Config{
+		Operator:  p.operator,
+		Resources: controller.NewResources(client),
+		Executor:  controller.NewExecutor(p.proxy, cluster.Domain),
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	p.RegisterClusterService(func(ctx context.Context) {
+		localCtx := context.WithValue(ctx, constants.UserContext,
+			constants.ServiceOperationController)
+		operationController.Run(localCtx)
+	})
+	return nil
+}
+
+// startEtcdMaintainer registers the service that periodically compacts
+// and defragments the cluster etcd database
+func (p *Process) startEtcdMaintainer() error {
+	config := p.cfg.EtcdMaintenance
+	if config.Disabled {
+		p.Info("etcd maintenance is disabled.")
+		return nil
+	}
+	if len(p.cfg.ETCD.Nodes) == 0 {
+		p.Info("etcd is not configured, skip etcd maintainer start.")
+		return nil
+	}
+	defragThreshold, err := config.DefragThresholdBytes()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := p.operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	client, err := etcd.NewClient(p.cfg.ETCD)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	maintainer, err := etcd.NewMaintainer(etcd.MaintainerConfig{
+		Client:            client,
+		Backend:           p.backend,
+		ClusterName:       cluster.Domain,
+		Interval:          config.Interval,
+		RetainedRevisions: config.RetainedRevisions,
+		DefragThreshold:   defragThreshold,
+		DefragInterval:    config.DefragInterval,
+	})
+	if err != nil {
+		client.Close()
+		return trace.Wrap(err)
+	}
+	p.RegisterClusterService(maintainer.Run)
+	return nil
+}
+
+// startEtcdMonitor registers the service that periodically checks
+// the health and capacity of the cluster etcd database
+func (p *Process) startEtcdMonitor() error {
+	config := p.cfg.EtcdHealth
+	if config.Disabled {
+		p.Info("etcd health monitoring is disabled.")
+		return nil
+	}
+	if len(p.cfg.ETCD.Nodes) == 0 {
+		p.Info("etcd is not configured, skip etcd health monitor start.")
+		return nil
+	}
+	quota, err := config.QuotaBytes()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := p.operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	client, err := etcd.NewClient(p.cfg.ETCD)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	monitor, err := etcd.NewMonitor(etcd.MonitorConfig{
+		Client:      client,
+		Backend:     p.backend,
+		ClusterName: cluster.Domain,
+		Interval:    config.Interval,
+		QuotaBytes:  quota,
+	})
+	if err != nil {
+		client.Close()
+		return trace.Wrap(err)
+	}
+	p.RegisterClusterService(monitor.Run)
+	return nil
+}
+
+// startCatalogSyncer registers the service that mirrors the charts of the
+// upstream Helm repository specified with the catalog sync schedule
+func (p *Process) startCatalogSyncer() error {
+	cluster, err := p.operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	vendorer, err := appservice.NewVendorer(appservice.VendorerConfig{
+		DockerURL:   constants.DockerEngineURL,
+		RegistryURL: constants.DockerRegistry,
+		Packages:    p.packages,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	syncer, err := catalog.NewSyncer(catalog.SyncerConfig{
+		Apps:        p.applications,
+		Backend:     p.backend,
+		ClusterName: cluster.Domain,
+		Vendorer:    vendorer,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	p.RegisterClusterService(syncer.Run)
+	return nil
+}
+
+// startBackupScheduler registers the service that backs up the cluster
+// etcd database, state and application data according to the backup schedule
+// and uploads the backups to the off-site destination, if configured
+func (p *Process) startBackupScheduler(kubeClient *kubernetes.Clientset) error {
+	if len(p.cfg.ETCD.Nodes) == 0 {
+		p.Info("etcd is not configured, skip backup scheduler start.")
+		return nil
+	}
+	cluster, err := p.operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	var hooks backup.HookRunner
+	if nodeName := os.Getenv(constants.NodeNameEnvVar); nodeName != "" {
+		hooks, err = backup.NewAppHooks(backup.AppHooksConfig{
+			Apps:     p.applications,
+			Cluster:  cluster.App.Package,
+			NodeName: nodeName,
+		})
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	} else {
+		p.Warn("Node name is not set, application data will not be backed up.")
+	}
+	client, err := etcd.NewClient(p.cfg.ETCD)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	scheduler, err := backup.NewScheduler(backup.SchedulerConfig{
+		Etcd:        client,
+		Backend:     p.backend,
+		ClusterName: cluster.Domain,
+		NodeName:    hostname,
+		ListImages: func(ctx context.Context) ([]dockerapp.LocalImage, error) {
+			return dockerapp.ListRegistryImages(ctx, dockerapp.RegistryConnectionRequest{
+				RegistryAddress: constants.LocalRegistryAddr,
+				CertName:        constants.DockerRegistry,
+			})
+		},
+		Hooks:        hooks,
+		Destinations: backup.NewDestinationFactory(kubeClient),
+	})
+	if err != nil {
+		client.Close()
+		return trace.Wrap(err)
+	}
+	p.RegisterClusterService(scheduler.Run)
+	return nil
+}
+
+// startReplicator registers the service that replicates the backups
+// of the primary cluster when this cluster is configured as its standby
+func (p *Process) startReplicator(kubeClient *kubernetes.Clientset) error {
+	cluster, err := p.operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	replicator, err := backup.NewReplicator(backup.ReplicatorConfig{
+		Backend:     p.backend,
+		ClusterName: cluster.Domain,
+		NodeName:    hostname,
+		ListImages: func(ctx context.Context) ([]dockerapp.LocalImage, error) {
+			return dockerapp.ListRegistryImages(ctx, dockerapp.RegistryConnectionRequest{
+				RegistryAddress: constants.LocalRegistryAddr,
+				CertName:        constants.DockerRegistry,
+			})
+		},
+		Destinations: backup.NewDestinationFactory(kubeClient),
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	p.RegisterClusterService(replicator.Run)
+	return nil
+}
+
+// startWireguardRotator registers the service that distributes and rotates
+// the WireGuard keys of the nodes if the cluster overlay network is encrypted
+func (p *Process) startWireguardRotator(kubeClient *kubernetes.Clientset) error {
+	cluster, err := p.operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	operation, _, err := ops.GetInstallOperation(cluster.Key(), p.operator)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	port := operation.GetVars().OnPrem.WireguardPort()
+	if port == 0 {
+		return nil
+	}
+	rotator, err := wireguard.NewRotator(wireguard.RotatorConfig{
+		Client: kubeClient,
+		Port:   port,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	p.RegisterClusterService(rotator.Run)
+	return nil
+}
+
+// runCertificateExpiryMonitor periodically checks the expiration of
+// the cluster certificates, exports it as metrics and logs a warning
+// for every certificate that is about to expire
+func (p *Process) runCertificateExpiryMonitor(ctx context.Context) {
+	p.Info("Starting certificate expiry monitor.")
+	ticker := time.NewTicker(defaults.CertificateExpiryCheckInterval)
+	defer ticker.Stop()
+	for {
+		p.checkCertificateExpiry()
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			p.Info("Stopping certificate expiry monitor.")
+			return
+		}
+	}
+}
+
+func (p *Process) checkCertificateExpiry() {
+	cluster, err := p.operator.GetLocalSite()
+	if err != nil {
+		p.WithError(err).Warn("Failed to get local cluster.")
+		return
+	}
+	status, err := p.operator.GetCertificateExpiry(cluster.Key())
+	if err != nil {
+		p.WithError(err).Warn("Failed to check certificate expiry.")
+		return
+	}
+	metrics.CertificateExpiry.Reset()
+	for _, cert := range status.Certificates {
+		metrics.CertificateExpiry.WithLabelValues(cert.Source, cert.Name, cert.Node).
+			Set(float64(cert.NotAfter.Unix()))
+	}
+	for _, problem := range status.Problems(time.Now()) {
+		p.Warningf("Certificate %v %v.", problem.Certificate, problem.Message)
+	}
+	for _, message := range status.Errors {
+		p.Warningf("Failed to check certificate expiry: %v.", message)
+	}
+	if err := p.rotateExpiringCertificates(*cluster, *status); err != nil {
+		p.WithError(err).Warn("Failed to start certificate rotation.")
+	}
+}
+
+// rotateExpiringCertificates starts the operation to rotate the runtime
+// certificates if automatic rotation is enabled and any of the certificates
+// expires within the configured renewal period
+func (p *Process) rotateExpiringCertificates(cluster ops.Site, status storage.CertificateExpiryStatus) error {
+	config, err := p.operator.GetClusterConfiguration(cluster.Key())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	rotation := config.GetCertificateRotation()
+	if !rotation.Enabled {
+		return nil
+	}
+	var expiring *storage.CertificateExpiry
+	for i, cert := range status.Certificates {
+		if cert.Source == storage.CertificateSourcePlanet &&
+			cert.ExpiresIn(time.Now()) < rotation.RenewPeriod() {
+			expiring = &status.Certificates[i]
+			break
+		}
+	}
+	if expiring == nil {
+		return nil
+	}
+	if cluster.State != ops.SiteStateActive && cluster.State != ops.SiteStateDegraded {
+		p.Infof("Certificate %v is due for rotation but the cluster is %v.", expiring, cluster.State)
+		return nil
+	}
+	_, err = ops.GetActiveOperations(cluster.Key(), p.operator)
+	if err == nil {
+		p.Infof("Certificate %v is due for rotation, will rotate after the active operation.", expiring)
+		return nil
+	}
+	if !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	p.Infof("Rotate certificates: %v expires on %v.", expiring, expiring.NotAfter)
+	unit := fmt.Sprintf("gravity-rotate-certs-%v", time.Now().Unix())
+	node, err := controller.NewExecutor(p.proxy, cluster.Domain).Start(context.TODO(), unit,
+		[]string{defaults.GravityBin, "system", "rotate-certs", "--confirm"})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	p.Infof("Started certificate rotation as %v on %v.", unit, node)
+	return nil
+}
+
 // runApplicationsSynchronizer runs a service that periodically exports
 // Docker images of the cluster's application images to the local Docker
 // registry.
@@ -1303,6 +1690,18 @@ func (p *Process) initService(ctx context.Context) (err error) {
 		Authenticator:       authenticator,
 		Backend:             p.backend,
 		PublicAdvertiseAddr: p.cfg.Pack.GetPublicAddr(),
+		RateLimit:           operatorRateLimit(p.cfg.OpsCenter.RateLimit),
+		DisableAudit:        p.cfg.OpsCenter.DisableAudit,
+		AuditReads:          p.cfg.OpsCenter.AuditReads,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	p.handlers.OperatorGRPC, err = opsgrpc.New(opsgrpc.Config{
+		Operator:    p.operator,
+		Users:       p.identity,
+		FieldLogger: p.WithField(trace.Component, "ops:grpc"),
 	})
 	if err != nil {
 		return trace.Wrap(err)
@@ -1335,6 +1734,40 @@ func (p *Process) initService(ctx context.Context) (err error) {
 			return trace.Wrap(err)
 		}
 
+		if err := p.startRosterReconciler(client); err != nil {
+			return trace.Wrap(err)
+		}
+
+		if err := p.startOperationController(); err != nil {
+			return trace.Wrap(err)
+		}
+
+		if err := p.startEtcdMaintainer(); err != nil {
+			return trace.Wrap(err)
+		}
+
+		if err := p.startEtcdMonitor(); err != nil {
+			return trace.Wrap(err)
+		}
+
+		if err := p.startCatalogSyncer(); err != nil {
+			return trace.Wrap(err)
+		}
+
+		if err := p.startBackupScheduler(client); err != nil {
+			return trace.Wrap(err)
+		}
+
+		if err := p.startReplicator(client); err != nil {
+			return trace.Wrap(err)
+		}
+
+		if err := p.startWireguardRotator(client); err != nil {
+			return trace.Wrap(err)
+		}
+
+		p.RegisterClusterService(p.runCertificateExpiryMonitor)
+
 		if err := p.startElection(); err != nil {
 			return trace.Wrap(err)
 		}
@@ -1482,6 +1915,7 @@ func (p *Process) ServeHealth() error {
 	healthMux := &httprouter.Router{}
 	healthMux.HandlerFunc("GET", "/readyz", p.ReportReadiness)
 	healthMux.HandlerFunc("GET", "/healthz", p.ReportHealth)
+	healthMux.Handler("GET", "/metrics", metrics.Handler())
 	p.healthServer = &http.Server{
 		Addr:    p.cfg.HealthAddr.Addr,
 		Handler: healthMux,
@@ -1495,17 +1929,31 @@ func (p *Process) ServeHealth() error {
 }
 
 func tryGetPrivilegedKubeClient() (client *kubernetes.Clientset, err error) {
+	config, err := tryGetPrivilegedKubeConfig()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	client, err = kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	return client, nil
+}
+
+// tryGetPrivilegedKubeConfig returns the configuration of the privileged
+// Kubernetes client
+func tryGetPrivilegedKubeConfig() (config *rest.Config, err error) {
 	_, err = utils.StatFile(constants.PrivilegedKubeconfig)
 	if err == nil || !trace.IsNotFound(err) {
-		client, _, err = utils.GetKubeClientFromPath(constants.PrivilegedKubeconfig)
+		_, config, err = utils.GetKubeClientFromPath(constants.PrivilegedKubeconfig)
 	} else {
-		client, _, err = utils.GetKubeClient("")
+		_, config, err = utils.GetKubeClient("")
 	}
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
-
-	return client, nil
+	return config, nil
 }
 
 func (p *Process) proxyConfig() (*proxyConfig, error) {
@@ -1571,7 +2019,7 @@ func (p *Process) initMux(ctx context.Context) error {
 	mux.NotFound = p.handlers.Web.NotFound
 
 	return trace.Wrap(p.ServeLocal(ctx, httplib.GRPCHandlerFunc(
-		p.agentServer, mux), p.cfg.Pack.ListenAddr.Addr))
+		p.handlers.OperatorGRPC.Handler(p.agentServer), mux), p.cfg.Pack.ListenAddr.Addr))
 }
 
 // ServeLocal starts serving provided handler mux on the specified address
@@ -1722,6 +2170,14 @@ func (p *Process) newTLSConfig(certPEM, keyPEM []byte) (*tls.Config, error) {
 	}
 
 	config.CipherSuites = teleutils.DefaultCipherSuites()
+	if p.teleportConfig != nil && len(p.teleportConfig.CipherSuites) != 0 {
+		// Use the same cipher suites as teleport which are restricted
+		// to FIPS-approved ones in FIPS mode
+		config.CipherSuites = p.teleportConfig.CipherSuites
+	}
+	if fips.Enabled() {
+		config.CurvePreferences = fips.CurvePreferences()
+	}
 
 	// Prefer the server ciphers, as curl will use invalid h2 ciphers
 	// https://github.com/nghttp2/nghttp2/issues/140
@@ -2287,6 +2743,27 @@ func reverseTunnelsFromTrustedClusters(backend storage.Backend) (tunnels []telec
 	return tunnels, nil
 }
 
+// operatorRateLimit returns the operator API rate limits
+// from the process configuration
+func operatorRateLimit(config processconfig.APIRateLimitConfig) opshandler.RateLimitConfig {
+	limits := opshandler.RateLimitConfig{
+		RateLimit: opshandler.RateLimit{
+			Rate:  config.Rate,
+			Burst: config.Burst,
+		},
+	}
+	for user, limit := range config.Users {
+		if limits.Users == nil {
+			limits.Users = make(map[string]opshandler.RateLimit)
+		}
+		limits.Users[user] = opshandler.RateLimit{
+			Rate:  limit.Rate,
+			Burst: limit.Burst,
+		}
+	}
+	return limits
+}
+
 type proxyConfig struct {
 	host              string
 	webPort           string
@@ -23,18 +23,22 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	blobs3 "github.com/gravitational/gravity/lib/blob/s3"
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/helm"
 	"github.com/gravitational/gravity/lib/loc"
 	"github.com/gravitational/gravity/lib/modules"
 	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/secrets"
 	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/storage/keyval"
 	"github.com/gravitational/gravity/lib/systeminfo"
 	"github.com/gravitational/gravity/lib/utils"
 
+	"github.com/dustin/go-humanize"
 	"github.com/gravitational/configure"
 	telecfg "github.com/gravitational/teleport/lib/config"
 	teleservices "github.com/gravitational/teleport/lib/services"
@@ -141,6 +145,11 @@ type Config struct {
 	// ETCD provides etcd config options
 	ETCD keyval.ETCDConfig `yaml:"etcd"`
 
+	// BackendParams provides configuration options for the storage
+	// backend of BackendType.
+	// The etcd backend uses the ETCD section if unspecified
+	BackendParams storage.BackendParams `yaml:"backend_params"`
+
 	// OpsCenter provides settings for OpsCenter
 	OpsCenter OpsCenterConfig `yaml:"ops"`
 
@@ -150,6 +159,21 @@ type Config struct {
 	// Charts is Helm chart repository configuration.
 	Charts ChartsConfig `yaml:"charts"`
 
+	// EtcdMaintenance configures the periodic compaction and
+	// defragmentation of the cluster etcd database
+	EtcdMaintenance EtcdMaintenanceConfig `yaml:"etcd_maintenance"`
+
+	// EtcdHealth configures the monitoring of the cluster etcd health and capacity
+	EtcdHealth EtcdHealthConfig `yaml:"etcd_health"`
+
+	// StateChangelog configures the recording of the cluster state
+	// changes for the point-in-time restore
+	StateChangelog StateChangelogConfig `yaml:"state_changelog"`
+
+	// Secrets optionally configures the external provider to keep
+	// the private keys and tokens in instead of the storage backend
+	Secrets *secrets.Config `yaml:"secrets"`
+
 	// Users list allows to add registered users to the application
 	// e.g. application admins, what is handy for development purposes
 	Users Users `yaml:"users"`
@@ -206,12 +230,17 @@ func (cfg *Config) CheckAndSetDefaults() error {
 	case "", constants.BoltBackend:
 		cfg.BackendType = constants.BoltBackend
 	case constants.ETCDBackend:
+		if len(cfg.BackendParams) != 0 {
+			break
+		}
 		if err := cfg.ETCD.Check(); err != nil {
 			log.Errorf("error reading config: %#v", cfg.ETCD)
 			return trace.Wrap(err)
 		}
 	default:
-		return trace.BadParameter("unsupported backend type: %v", cfg.BackendType)
+		if !storage.HasBackend(cfg.BackendType) {
+			return trace.BadParameter("unsupported backend type: %v", cfg.BackendType)
+		}
 	}
 
 	// Set default service user if unspecified
@@ -223,21 +252,78 @@ func (cfg *Config) CheckAndSetDefaults() error {
 		return trace.Wrap(err)
 	}
 
+	if err := cfg.EtcdMaintenance.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+
+	if err := cfg.EtcdHealth.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+
+	if cfg.StateChangelog.Retention < 0 {
+		return trace.BadParameter("state changelog retention can not be negative")
+	}
+
+	if cfg.Secrets != nil {
+		if err := cfg.Secrets.CheckAndSetDefaults(); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
+	if cfg.Pack.ObjectStorage.IsEnabled() {
+		config := cfg.Pack.ObjectStorage.BLOBConfig("")
+		if err := config.CheckAndSetDefaults(); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
 	return nil
 }
 
-func (cfg Config) CreateBackend() (backend storage.Backend, err error) {
+// CreateBackend creates the storage backend of the configured type
+func (cfg Config) CreateBackend() (storage.Backend, error) {
+	params, err := cfg.backendParams()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	log.Debugf("Using %v backend.", cfg.BackendType)
+	backend, err := storage.NewBackend(cfg.BackendType, params)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return backend, nil
+}
+
+// backendParams returns the configuration of the storage backend.
+// Unless configured otherwise, the bolt database is stored in the data
+// directory and the etcd backend is configured from the etcd section
+func (cfg Config) backendParams() (storage.BackendParams, error) {
+	params := make(storage.BackendParams)
+	for key, value := range cfg.BackendParams {
+		params[key] = value
+	}
 	switch cfg.BackendType {
 	case constants.BoltBackend:
-		log.Debug("using bolt backend")
-		backend, err = keyval.NewBolt(keyval.BoltConfig{
-			Path: filepath.Join(cfg.DataDir, defaults.GravityDBFile),
-		})
+		if _, ok := params["path"]; !ok {
+			params["path"] = filepath.Join(cfg.DataDir, defaults.GravityDBFile)
+		}
 	case constants.ETCDBackend:
-		log.Debug("using ETCD backend")
-		backend, err = keyval.NewETCD(cfg.ETCD)
+		if len(params) == 0 {
+			if err := teleutils.ObjectToStruct(cfg.ETCD, &params); err != nil {
+				return nil, trace.Wrap(err)
+			}
+		}
+	}
+	if !cfg.StateChangelog.Disabled {
+		params["changelog"] = true
+		if cfg.StateChangelog.Retention != 0 {
+			params["changelog_retention"] = cfg.StateChangelog.Retention
+		}
 	}
-	return backend, trace.Wrap(err)
+	if cfg.Secrets != nil {
+		params["secrets"] = cfg.Secrets
+	}
+	return params, nil
 }
 
 func (cfg Config) ProcessID() string {
@@ -316,6 +402,53 @@ type PackageServiceConfig struct {
 
 	// ReadDir is an optional directory with extra packages
 	ReadDir string `yaml:"read_dir"`
+
+	// ObjectStorage optionally configures the object storage for package BLOBs
+	ObjectStorage ObjectStorageConfig `yaml:"object_storage"`
+}
+
+// ObjectStorageConfig configures the S3-compatible object storage
+// for package BLOBs. If unspecified, BLOBs are stored on the local
+// filesystem of the controller nodes and replicated between them
+type ObjectStorageConfig struct {
+	// Type is the object storage type, either s3 or gcs
+	Type string `yaml:"type"`
+	// Bucket is the name of the bucket with BLOBs
+	Bucket string `yaml:"bucket"`
+	// Prefix is an optional key prefix for BLOBs in the bucket
+	Prefix string `yaml:"prefix"`
+	// Region is the bucket region
+	Region string `yaml:"region"`
+	// Endpoint is an optional custom endpoint of the object storage
+	Endpoint string `yaml:"endpoint"`
+	// AccessKeyID is an optional access key, the default AWS
+	// credentials chain is used if unspecified
+	AccessKeyID string `yaml:"access_key_id"`
+	// SecretAccessKey is the secret for the access key
+	SecretAccessKey string `yaml:"secret_access_key"`
+	// ForcePathStyle forces the path-style bucket addressing
+	ForcePathStyle bool `yaml:"force_path_style"`
+}
+
+// IsEnabled returns true if the object storage is configured
+func (c ObjectStorageConfig) IsEnabled() bool {
+	return c.Type != ""
+}
+
+// BLOBConfig returns the configuration of the object storage BLOB backend
+// that stages the BLOBs in the specified temporary directory
+func (c ObjectStorageConfig) BLOBConfig(tempDir string) blobs3.Config {
+	return blobs3.Config{
+		Type:            c.Type,
+		Bucket:          c.Bucket,
+		Prefix:          c.Prefix,
+		Region:          c.Region,
+		Endpoint:        c.Endpoint,
+		AccessKeyID:     c.AccessKeyID,
+		SecretAccessKey: c.SecretAccessKey,
+		ForcePathStyle:  c.ForcePathStyle,
+		TempDir:         tempDir,
+	}
 }
 
 // PeerAddr returns peer address of the package service instance
@@ -373,10 +506,122 @@ func (c *ChartsConfig) CheckAndSetDefaults() error {
 	return nil
 }
 
+// StateChangelogConfig configures the state changelog that records
+// the changes to the cluster state so it can be restored to a point in time
+type StateChangelogConfig struct {
+	// Disabled turns off the state changelog
+	Disabled bool `yaml:"disabled"`
+	// Retention is how long the changes are kept in the changelog
+	Retention time.Duration `yaml:"retention"`
+}
+
+// EtcdMaintenanceConfig configures the periodic compaction and
+// defragmentation of the cluster etcd database.
+// Unspecified values are set to defaults
+type EtcdMaintenanceConfig struct {
+	// Disabled turns off the maintenance
+	Disabled bool `yaml:"disabled"`
+	// Interval is how often the database is checked for maintenance
+	Interval time.Duration `yaml:"interval"`
+	// RetainedRevisions is the number of the most recent revisions
+	// kept by the compaction
+	RetainedRevisions int64 `yaml:"retained_revisions"`
+	// DefragThreshold is the size of a member database (e.g. "512MB")
+	// above which the member is defragmented
+	DefragThreshold string `yaml:"defrag_threshold"`
+	// DefragInterval is the minimum interval between defragmentations
+	// of the same member
+	DefragInterval time.Duration `yaml:"defrag_interval"`
+}
+
+// Check validates the etcd maintenance configuration
+func (c EtcdMaintenanceConfig) Check() error {
+	if c.Interval < 0 || c.DefragInterval < 0 {
+		return trace.BadParameter("etcd maintenance intervals can not be negative")
+	}
+	if c.RetainedRevisions < 0 {
+		return trace.BadParameter("number of retained etcd revisions can not be negative")
+	}
+	_, err := c.DefragThresholdBytes()
+	return trace.Wrap(err)
+}
+
+// DefragThresholdBytes returns the defragmentation threshold in bytes
+// or 0 if unspecified
+func (c EtcdMaintenanceConfig) DefragThresholdBytes() (int64, error) {
+	if c.DefragThreshold == "" {
+		return 0, nil
+	}
+	bytes, err := humanize.ParseBytes(c.DefragThreshold)
+	if err != nil {
+		return 0, trace.BadParameter("invalid etcd defragmentation threshold %q: %v",
+			c.DefragThreshold, err)
+	}
+	return int64(bytes), nil
+}
+
+// EtcdHealthConfig configures the monitoring of the cluster etcd
+// health and capacity. Unspecified values are set to defaults
+type EtcdHealthConfig struct {
+	// Disabled turns off the monitoring
+	Disabled bool `yaml:"disabled"`
+	// Interval is how often the health is checked
+	Interval time.Duration `yaml:"interval"`
+	// Quota is the etcd space quota (e.g. "8GB") configured with
+	// --quota-backend-bytes
+	Quota string `yaml:"quota"`
+}
+
+// Check validates the etcd health configuration
+func (c EtcdHealthConfig) Check() error {
+	if c.Interval < 0 {
+		return trace.BadParameter("etcd health interval can not be negative")
+	}
+	_, err := c.QuotaBytes()
+	return trace.Wrap(err)
+}
+
+// QuotaBytes returns the etcd space quota in bytes or 0 if unspecified
+func (c EtcdHealthConfig) QuotaBytes() (int64, error) {
+	if c.Quota == "" {
+		return 0, nil
+	}
+	bytes, err := humanize.ParseBytes(c.Quota)
+	if err != nil {
+		return 0, trace.BadParameter("invalid etcd space quota %q: %v", c.Quota, err)
+	}
+	return int64(bytes), nil
+}
+
 // OpsCenterConfig provides settings for access and installation portal
 type OpsCenterConfig struct {
 	// SeedConfig defines optional configuration to apply on OpsCenter start
 	SeedConfig *ops.SeedConfig `yaml:"seed_config"`
+	// RateLimit limits the rate of operator API requests per user
+	RateLimit APIRateLimitConfig `yaml:"rate_limit"`
+	// DisableAudit turns off recording of operator API requests
+	// in the audit log
+	DisableAudit bool `yaml:"disable_audit"`
+	// AuditReads enables recording of the read-only operator API
+	// requests in the audit log
+	AuditReads bool `yaml:"audit_reads"`
+}
+
+// APIRateLimitConfig limits the rate of operator API requests per user
+type APIRateLimitConfig struct {
+	// APIRateLimit is the limit applied to every user
+	APIRateLimit `yaml:",inline"`
+	// Users overrides the limit for specific users
+	Users map[string]APIRateLimit `yaml:"users"`
+}
+
+// APIRateLimit defines the rate of operator API requests
+type APIRateLimit struct {
+	// Rate is the number of requests per second, zero rate
+	// does not limit the requests
+	Rate float64 `yaml:"rate"`
+	// Burst is the maximum number of requests allowed at once
+	Burst int `yaml:"burst"`
 }
 
 type packageLocator loc.Locator
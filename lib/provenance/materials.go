@@ -0,0 +1,163 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/app/docker"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+)
+
+// ManifestMaterial returns the material for the image manifest at the specified path
+func ManifestMaterial(path string) (*Material, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &Material{
+		Type:   MaterialManifest,
+		Name:   filepath.Base(path),
+		Digest: fmt.Sprintf("sha256:%x", hash.Sum(nil)),
+	}, nil
+}
+
+// PackageMaterials returns the materials for the specified packages
+func PackageMaterials(packages pack.PackageService, locators []loc.Locator) (materials []Material, err error) {
+	for _, locator := range locators {
+		envelope, err := packages.ReadPackageEnvelope(locator)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		materials = append(materials, packageMaterial(*envelope))
+	}
+	return materials, nil
+}
+
+// ImageMaterials returns the materials for the container images
+// in the local registry directory dir. The application without
+// container images does not have the registry directory
+func ImageMaterials(ctx context.Context, dir string) (materials []Material, err error) {
+	if ok, _ := utils.IsDirectory(dir); !ok {
+		return nil, nil
+	}
+	images, err := docker.ListImages(ctx, dir)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, image := range images {
+		materials = append(materials, Material{
+			Type:   MaterialContainer,
+			Name:   image.String(),
+			Digest: image.Digest.String(),
+		})
+	}
+	return materials, nil
+}
+
+// SortMaterials sorts the materials by type and name
+func SortMaterials(materials []Material) {
+	sort.Slice(materials, func(i, j int) bool {
+		if materials[i].Type != materials[j].Type {
+			return materials[i].Type < materials[j].Type
+		}
+		return materials[i].Name < materials[j].Name
+	})
+}
+
+// VerifyMaterials verifies the materials recorded in the provenance against
+// the packages in the specified package service and the container images in
+// the local registry directory dir with the application images.
+//
+// The recorded packages that are not available in the package service,
+// e.g. the packages excluded from a delta image, are skipped and returned
+func VerifyMaterials(ctx context.Context, provenance Provenance, packages pack.PackageService, dir string) (skipped []Material, err error) {
+	var errors []string
+	images, err := ImageMaterials(ctx, dir)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	vendored := make(map[string]string, len(images))
+	for _, image := range images {
+		vendored[image.Name] = image.Digest
+	}
+	for _, material := range provenance.Materials {
+		switch material.Type {
+		case MaterialContainer:
+			digest, ok := vendored[material.Name]
+			if !ok {
+				errors = append(errors, fmt.Sprintf("container image %v is missing", material.Name))
+				continue
+			}
+			delete(vendored, material.Name)
+			if digest != material.Digest {
+				errors = append(errors, fmt.Sprintf("container image %v has digest %v, expected %v",
+					material.Name, digest, material.Digest))
+			}
+		case MaterialPackage:
+			locator, err := loc.ParseLocator(material.Name)
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			envelope, err := packages.ReadPackageEnvelope(*locator)
+			if err != nil {
+				if !trace.IsNotFound(err) {
+					return nil, trace.Wrap(err)
+				}
+				skipped = append(skipped, material)
+				continue
+			}
+			if actual := packageMaterial(*envelope); actual.Digest != material.Digest {
+				errors = append(errors, fmt.Sprintf("package %v has digest %v, expected %v",
+					material.Name, actual.Digest, material.Digest))
+			}
+		}
+	}
+	for name := range vendored {
+		errors = append(errors, fmt.Sprintf("container image %v is not recorded in the provenance", name))
+	}
+	if len(errors) != 0 {
+		sort.Strings(errors)
+		return nil, trace.CompareFailed("image contents do not match the provenance:\n%v",
+			strings.Join(errors, "\n"))
+	}
+	return skipped, nil
+}
+
+func packageMaterial(envelope pack.PackageEnvelope) Material {
+	return Material{
+		Type:   MaterialPackage,
+		Name:   envelope.Locator.String(),
+		Digest: fmt.Sprintf("sha512:%v", envelope.SHA512),
+	}
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package provenance records how a cluster image was built in a signed attestation.
+//
+// The provenance lists the builder identity, the source revision, the build
+// arguments and the digests of the build inputs. It is signed with the key
+// of the builder and wrapped into a DSSE envelope that is embedded into
+// the application resources, so the image can be verified before it is installed
+package provenance
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// Provenance describes how a cluster image was built
+type Provenance struct {
+	// Image is the locator of the built image
+	Image string `json:"image"`
+	// Builder identifies the builder of the image
+	Builder Builder `json:"builder"`
+	// Source describes the source the image was built from
+	Source Source `json:"source"`
+	// Arguments lists the build command line arguments
+	Arguments []string `json:"arguments,omitempty"`
+	// Materials lists the build inputs
+	Materials []Material `json:"materials"`
+	// Finished is the time the build finished
+	Finished time.Time `json:"finished"`
+}
+
+// Builder identifies the builder of the image
+type Builder struct {
+	// ID is the builder identity, e.g. user@host or the CI job URL
+	ID string `json:"id"`
+	// Version is the version of the build tool
+	Version string `json:"version"`
+}
+
+// Source describes the source the image was built from
+type Source struct {
+	// URI is the source repository URI
+	URI string `json:"uri,omitempty"`
+	// Revision is the source revision
+	Revision string `json:"revision,omitempty"`
+}
+
+// Material is a single build input
+type Material struct {
+	// Type is the material type
+	Type MaterialType `json:"type"`
+	// Name is the name of the material
+	Name string `json:"name"`
+	// Digest is the material checksum in the form <algorithm>:<hex>
+	Digest string `json:"digest"`
+}
+
+// MaterialType defines the type of build input
+type MaterialType string
+
+const (
+	// MaterialManifest is the image manifest
+	MaterialManifest MaterialType = "manifest"
+	// MaterialPackage is a package or an application package the image depends on
+	MaterialPackage MaterialType = "package"
+	// MaterialContainer is a container image vendored into the image
+	MaterialContainer MaterialType = "container"
+)
+
+// Attestation is the DSSE envelope with the signed provenance
+type Attestation struct {
+	// PayloadType is the type of the payload
+	PayloadType string `json:"payloadType"`
+	// Payload is the serialized provenance
+	Payload []byte `json:"payload"`
+	// Signatures lists the payload signatures
+	Signatures []Signature `json:"signatures"`
+}
+
+// Signature is a single signature of the attestation payload
+type Signature struct {
+	// KeyID identifies the signing key with the SHA256 of its public key
+	KeyID string `json:"keyid"`
+	// Sig is the signature
+	Sig []byte `json:"sig"`
+}
+
+// Sign serializes the provenance and signs it with the specified key
+func Sign(provenance Provenance, key crypto.Signer) (*Attestation, error) {
+	payload, err := json.Marshal(provenance)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	keyID, err := KeyID(key.Public())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	hash := sha256.Sum256(pae(PayloadType, payload))
+	sig, err := key.Sign(rand.Reader, hash[:], crypto.SHA256)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &Attestation{
+		PayloadType: PayloadType,
+		Payload:     payload,
+		Signatures:  []Signature{{KeyID: keyID, Sig: sig}},
+	}, nil
+}
+
+// Verify verifies that the attestation is signed with any of the specified keys
+// and returns the provenance
+func Verify(attestation Attestation, keys []crypto.PublicKey) (*Provenance, error) {
+	if attestation.PayloadType != PayloadType {
+		return nil, trace.BadParameter("unsupported attestation payload type %q",
+			attestation.PayloadType)
+	}
+	hash := sha256.Sum256(pae(attestation.PayloadType, attestation.Payload))
+	for _, signature := range attestation.Signatures {
+		for _, key := range keys {
+			if !verify(key, hash[:], signature.Sig) {
+				continue
+			}
+			var provenance Provenance
+			if err := json.Unmarshal(attestation.Payload, &provenance); err != nil {
+				return nil, trace.BadParameter("invalid provenance: %v", err)
+			}
+			return &provenance, nil
+		}
+	}
+	return nil, trace.AccessDenied("attestation is not signed with any of the trusted keys")
+}
+
+// Encode writes the attestation to w
+func Encode(w io.Writer, attestation Attestation) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return trace.Wrap(encoder.Encode(attestation))
+}
+
+// Decode reads the attestation from r
+func Decode(r io.Reader) (*Attestation, error) {
+	var attestation Attestation
+	if err := json.NewDecoder(r).Decode(&attestation); err != nil {
+		return nil, trace.BadParameter("invalid attestation: %v", err)
+	}
+	return &attestation, nil
+}
+
+// KeyID returns the identifier of the specified public key
+func KeyID(key crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(der)), nil
+}
+
+// ParsePrivateKeyPEM parses the ECDSA or RSA private key in PEM format
+func ParsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, trace.BadParameter("expected a PEM-encoded private key")
+	}
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, trace.BadParameter("failed to parse private key: %v", err)
+	}
+	switch key := key.(type) {
+	case *ecdsa.PrivateKey:
+		return key, nil
+	case *rsa.PrivateKey:
+		return key, nil
+	}
+	return nil, trace.BadParameter("unsupported private key type %T, "+
+		"only ECDSA and RSA keys are supported", key)
+}
+
+// ParsePublicKeyPEM parses the ECDSA or RSA public key in PEM format
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	key, err := storage.ParsePublicKeyPEM(data)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey:
+		return key, nil
+	}
+	return nil, trace.BadParameter("unsupported public key type %T, "+
+		"only ECDSA and RSA keys are supported", key)
+}
+
+func verify(key crypto.PublicKey, hash, sig []byte) bool {
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		var ecdsaSig struct {
+			R, S *big.Int
+		}
+		if _, err := asn1.Unmarshal(sig, &ecdsaSig); err != nil {
+			return false
+		}
+		return ecdsa.Verify(key, hash, ecdsaSig.R, ecdsaSig.S)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, hash, sig) == nil
+	}
+	return false
+}
+
+// pae returns the DSSE pre-authentication encoding of the payload
+func pae(payloadType string, payload []byte) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "DSSEv1 %d %s %d ", len(payloadType), payloadType, len(payload))
+	buf.Write(payload)
+	return buf.Bytes()
+}
+
+// PayloadType is the type of the attestation payload
+const PayloadType = "application/vnd.gravitational.provenance+json"
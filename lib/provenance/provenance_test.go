@@ -0,0 +1,112 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/gravitational/trace"
+	"gopkg.in/check.v1"
+)
+
+func TestProvenance(t *testing.T) { check.TestingT(t) }
+
+type ProvenanceSuite struct{}
+
+var _ = check.Suite(&ProvenanceSuite{})
+
+func (s *ProvenanceSuite) TestSignAndVerify(c *check.C) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, check.IsNil)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	c.Assert(err, check.IsNil)
+	for _, key := range []crypto.Signer{ecKey, rsaKey} {
+		attestation, err := Sign(testProvenance, key)
+		c.Assert(err, check.IsNil)
+
+		var buf bytes.Buffer
+		c.Assert(Encode(&buf, *attestation), check.IsNil)
+		decoded, err := Decode(&buf)
+		c.Assert(err, check.IsNil)
+
+		verified, err := Verify(*decoded, []crypto.PublicKey{ecKey.Public(), rsaKey.Public()})
+		c.Assert(err, check.IsNil)
+		c.Assert(*verified, check.DeepEquals, testProvenance)
+	}
+}
+
+func (s *ProvenanceSuite) TestRejectsUntrustedKey(c *check.C) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, check.IsNil)
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, check.IsNil)
+	attestation, err := Sign(testProvenance, key)
+	c.Assert(err, check.IsNil)
+	_, err = Verify(*attestation, []crypto.PublicKey{other.Public()})
+	c.Assert(trace.IsAccessDenied(err), check.Equals, true, check.Commentf("%v", err))
+}
+
+func (s *ProvenanceSuite) TestRejectsTamperedPayload(c *check.C) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, check.IsNil)
+	attestation, err := Sign(testProvenance, key)
+	c.Assert(err, check.IsNil)
+	attestation.Payload = bytes.Replace(attestation.Payload,
+		[]byte("sha512:aaaa"), []byte("sha512:bbbb"), 1)
+	_, err = Verify(*attestation, []crypto.PublicKey{key.Public()})
+	c.Assert(trace.IsAccessDenied(err), check.Equals, true, check.Commentf("%v", err))
+}
+
+func (s *ProvenanceSuite) TestParsesKeys(c *check.C) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, check.IsNil)
+	der, err := x509.MarshalECPrivateKey(key)
+	c.Assert(err, check.IsNil)
+	signer, err := ParsePrivateKeyPEM(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+	c.Assert(err, check.IsNil)
+
+	der, err = x509.MarshalPKIXPublicKey(key.Public())
+	c.Assert(err, check.IsNil)
+	public, err := ParsePublicKeyPEM(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	c.Assert(err, check.IsNil)
+
+	attestation, err := Sign(testProvenance, signer)
+	c.Assert(err, check.IsNil)
+	_, err = Verify(*attestation, []crypto.PublicKey{public})
+	c.Assert(err, check.IsNil)
+}
+
+var testProvenance = Provenance{
+	Image:     "gravitational.io/app:1.0.0",
+	Builder:   Builder{ID: "builder@example.com", Version: "7.0.0"},
+	Source:    Source{URI: "https://github.com/example/app", Revision: "d670460b4b4aece5915caf5c68d12f560a9fe3e4"},
+	Arguments: []string{"build", "app.yaml"},
+	Materials: []Material{
+		{Type: MaterialManifest, Name: "app.yaml", Digest: "sha256:cccc"},
+		{Type: MaterialPackage, Name: "gravitational.io/planet:1.0.0", Digest: "sha512:aaaa"},
+	},
+	Finished: time.Date(2019, time.June, 1, 0, 0, 0, 0, time.UTC),
+}
@@ -0,0 +1,261 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redact
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+)
+
+// NewWriter returns a writer that masks the secrets in the data
+// before forwarding it to the specified writer.
+// The returned writer has to be closed to flush the buffered data,
+// closing it does not close the underlying writer
+func NewWriter(w io.Writer) io.WriteCloser {
+	return &writer{w: w}
+}
+
+// NewFileWriter returns a writer that masks the secrets in the contents of
+// the file with the specified name before forwarding it to the specified writer.
+//
+// The contents of the gzip-compressed files and the files of the tarballs
+// are redacted as well. The files of the tarballs that are compressed
+// themselves are forwarded as-is.
+// Closing the returned writer closes the underlying writer
+func NewFileWriter(name string, w io.WriteCloser) io.WriteCloser {
+	var redact func(io.Reader, io.Writer) error
+	switch {
+	case hasSuffix(name, ".tar.gz", ".tgz"):
+		redact = func(r io.Reader, w io.Writer) error {
+			return redactGzip(r, w, redactTar)
+		}
+	case hasSuffix(name, ".tar"):
+		redact = redactTar
+	case hasSuffix(name, ".gz"):
+		redact = func(r io.Reader, w io.Writer) error {
+			return redactGzip(r, w, redactText)
+		}
+	default:
+		return &fileWriter{
+			WriteCloser: NewWriter(w),
+			w:           w,
+		}
+	}
+	reader, writer := io.Pipe()
+	errC := make(chan error, 1)
+	go func() {
+		err := redact(reader, w)
+		// Drain the rest of the data so the writes do not block
+		io.Copy(ioutil.Discard, reader)
+		errC <- err
+	}()
+	return &pipeWriter{
+		PipeWriter: writer,
+		w:          w,
+		errC:       errC,
+	}
+}
+
+// Formatter is a logrus formatter that masks the secrets
+// in the log entries formatted by the wrapped formatter
+type Formatter struct {
+	// Formatter is the wrapped formatter
+	logrus.Formatter
+}
+
+// Format formats the specified log entry with the wrapped formatter
+// and masks the secrets in the result
+func (r *Formatter) Format(entry *logrus.Entry) ([]byte, error) {
+	data, err := r.Formatter.Format(entry)
+	if err != nil {
+		return nil, err
+	}
+	return Bytes(data), nil
+}
+
+// Write masks the secrets in the complete lines of the specified data
+// and forwards them to the underlying writer.
+// It implements io.Writer
+func (r *writer) Write(data []byte) (n int, err error) {
+	r.buf = append(r.buf, data...)
+	for {
+		i := bytes.IndexByte(r.buf, '\n')
+		if i < 0 && len(r.buf) < maxLineSize {
+			break
+		}
+		line := r.buf
+		if i >= 0 {
+			line = r.buf[:i+1]
+		}
+		if _, err := r.w.Write(r.redactLine(line)); err != nil {
+			return 0, err
+		}
+		r.buf = r.buf[len(line):]
+		if len(r.buf) == 0 {
+			break
+		}
+	}
+	return len(data), nil
+}
+
+// Close masks the secrets in the buffered data
+// and forwards it to the underlying writer.
+// It implements io.Closer
+func (r *writer) Close() error {
+	if len(r.buf) == 0 {
+		return nil
+	}
+	_, err := r.w.Write(r.redactLine(r.buf))
+	r.buf = nil
+	return err
+}
+
+// writer masks the secrets line by line
+type writer struct {
+	redactor
+	w   io.Writer
+	buf []byte
+}
+
+// Close flushes the redacted data and closes the underlying writer
+func (r *fileWriter) Close() error {
+	err := r.WriteCloser.Close()
+	if errClose := r.w.Close(); err == nil {
+		err = errClose
+	}
+	return trace.Wrap(err)
+}
+
+// fileWriter redacts the contents of a text file
+type fileWriter struct {
+	io.WriteCloser
+	w io.WriteCloser
+}
+
+// Close waits for the redacted data to be written
+// and closes the underlying writer
+func (r *pipeWriter) Close() error {
+	r.PipeWriter.Close()
+	err := <-r.errC
+	if errClose := r.w.Close(); err == nil {
+		err = errClose
+	}
+	return trace.Wrap(err)
+}
+
+// pipeWriter redacts the contents of an archive
+// that are written to the pipe in a separate goroutine
+type pipeWriter struct {
+	*io.PipeWriter
+	w    io.WriteCloser
+	errC chan error
+}
+
+func redactText(r io.Reader, w io.Writer) error {
+	redacted := NewWriter(w)
+	if _, err := io.Copy(redacted, r); err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(redacted.Close())
+}
+
+// redactGzip decompresses the data, redacts it with the specified function
+// and compresses the result. Data that is not compressed is redacted as text
+func redactGzip(r io.Reader, w io.Writer, redact func(io.Reader, io.Writer) error) error {
+	reader := bufio.NewReader(r)
+	magic, err := reader.Peek(len(gzipMagic))
+	if err != nil && err != io.EOF {
+		return trace.Wrap(err)
+	}
+	if len(magic) == 0 {
+		return nil
+	}
+	if !bytes.Equal(magic, gzipMagic) {
+		return redactText(reader, w)
+	}
+	gzReader, err := gzip.NewReader(reader)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer gzReader.Close()
+	gzWriter := gzip.NewWriter(w)
+	if err := redact(gzReader, gzWriter); err != nil {
+		gzWriter.Close()
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(gzWriter.Close())
+}
+
+// redactTar redacts the regular files of the tarball.
+// As redaction does not change the size of the data,
+// the file headers are copied unchanged
+func redactTar(r io.Reader, w io.Writer) error {
+	tarReader := tar.NewReader(r)
+	tarWriter := tar.NewWriter(w)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return trace.Wrap(err)
+		}
+		if !isRegular(header) || isCompressed(header.Name) {
+			_, err = io.Copy(tarWriter, tarReader)
+		} else {
+			err = redactText(tarReader, tarWriter)
+		}
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return trace.Wrap(tarWriter.Close())
+}
+
+func isRegular(header *tar.Header) bool {
+	return header.Typeflag == tar.TypeReg || header.Typeflag == tar.TypeRegA
+}
+
+func isCompressed(name string) bool {
+	return hasSuffix(name, ".gz", ".tgz", ".bz2", ".xz", ".zip")
+}
+
+func hasSuffix(name string, suffixes ...string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxLineSize is the maximum size of the data buffered while waiting
+// for the end of line. Longer lines are redacted in chunks
+const maxLineSize = 64 * 1024
+
+var gzipMagic = []byte{0x1f, 0x8b}
@@ -24,19 +24,29 @@ import (
 	"path/filepath"
 
 	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/redact"
 	"github.com/gravitational/gravity/lib/utils"
 
 	"github.com/gravitational/trace"
 )
 
-// NewFileWriter creates a Writer that writes to a file in the specified directory dir
+// NewFileWriter creates a Writer that writes to a file in the specified directory dir.
+// Secrets are masked in the written data
 func NewFileWriter(dir string) FileWriterFunc {
 	return FileWriterFunc(func(name string) (io.WriteCloser, error) {
 		fileName := filepath.Join(dir, name)
-		return NewPendingFileWriter(fileName), nil
+		return NewRedactingFileWriter(fileName), nil
 	})
 }
 
+// NewRedactingFileWriter returns a writer for the file with the specified path
+// that masks secrets (tokens, passwords, private keys and license payloads)
+// in the written data. Like pendingWriter, it only creates the file if there's
+// data to write
+func NewRedactingFileWriter(path string) io.WriteCloser {
+	return redact.NewFileWriter(filepath.Base(path), NewPendingFileWriter(path))
+}
+
 // NewPendingFileWriter creates a new instance of the pendingWriter
 // for the specified path
 func NewPendingFileWriter(path string) *pendingWriter {
@@ -18,6 +18,7 @@ package server
 
 import (
 	"github.com/gravitational/gravity/lib/cloudprovider/aws"
+	"github.com/gravitational/gravity/lib/cloudprovider/azure"
 	pb "github.com/gravitational/gravity/lib/rpc/proto"
 	"github.com/gravitational/gravity/lib/schema"
 
@@ -32,6 +33,8 @@ func GetCloudMetadata(provider string) (*pb.CloudMetadata, error) {
 		return getAWSMetadata()
 	case schema.ProviderGCE:
 		return getGCEMetadata()
+	case schema.ProviderAzure:
+		return getAzureMetadata()
 	}
 	return nil, trace.BadParameter("unsupported cloud provider %q", provider)
 }
@@ -67,3 +70,15 @@ func getGCEMetadata() (*pb.CloudMetadata, error) {
 		InstanceId:   instanceID,
 	}, nil
 }
+
+func getAzureMetadata() (*pb.CloudMetadata, error) {
+	instance, err := azure.NewLocalInstance()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &pb.CloudMetadata{
+		NodeName:     instance.Name,
+		InstanceType: instance.Size,
+		InstanceId:   instance.ID,
+	}, nil
+}
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/metrics"
 	"github.com/gravitational/gravity/lib/utils"
 
 	"github.com/cenkalti/backoff"
@@ -42,6 +43,8 @@ func newPeers(from []Peer, config peersConfig) (*peers, error) {
 		ps[p.Addr()] = &peer{Peer: p, doneCh: make(chan struct{})}
 	}
 
+	metrics.AgentPeers.Add(float64(len(ps)))
+
 	ctx, cancel := context.WithCancel(context.TODO())
 	r := &peers{
 		FieldLogger:       config.FieldLogger,
@@ -186,6 +189,7 @@ func (r *peers) checkPeer(p Peer, clt Client, reconnectCh chan<- chan clientUpda
 		if err == nil && isPeerHealthy(*resp) {
 			return clt, nil
 		}
+		metrics.AgentHealthCheckFailures.Inc()
 		log.Warnf("Failed health check: %+v (%v).", resp, err)
 	}
 	select {
@@ -231,6 +235,7 @@ func (r *peers) reconnectPeer(p Peer, reqCh <-chan chan clientUpdate, doneCh cha
 				}
 				return nil
 			})
+			metrics.AgentReconnects.WithLabelValues(metrics.Result(err)).Inc()
 			select {
 			case respCh <- clientUpdate{clt, err}:
 				if err == nil {
@@ -266,6 +271,9 @@ func (r *peers) add(p peer) {
 	}
 	doneCh := make(chan struct{})
 	r.peers[p.Addr()] = &peer{Peer: p.Peer, doneCh: doneCh}
+	if !r.closed {
+		metrics.AgentPeers.Inc()
+	}
 	r.Unlock()
 
 	reconnectCh := make(chan chan clientUpdate)
@@ -280,6 +288,9 @@ func (r *peers) delete(p peer) {
 		close(peer.doneCh)
 	}
 	delete(r.peers, p.Addr())
+	if peer != nil && !r.closed {
+		metrics.AgentPeers.Dec()
+	}
 	r.Unlock()
 }
 
@@ -306,6 +317,12 @@ func (r *peers) iterate(handler func(peer) error) error {
 
 func (r *peers) close(ctx context.Context) error {
 	r.cancel()
+	r.Lock()
+	if !r.closed {
+		metrics.AgentPeers.Sub(float64(len(r.peers)))
+		r.closed = true
+	}
+	r.Unlock()
 	var errors []error
 	for _, peer := range r.getPeers() {
 		errors = append(errors, peer.Disconnect(ctx))
@@ -341,6 +358,9 @@ type peers struct {
 	watchCh chan<- WatchEvent
 	sync.RWMutex
 	peers map[string]*peer
+	// closed is set once the peers have been closed and
+	// are no longer counted as connected
+	closed bool
 }
 
 func (r *peersConfig) checkAndSetDefaults() error {
@@ -0,0 +1,162 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gravitational/trace"
+	"github.com/gravitational/version"
+	"github.com/pborman/uuid"
+)
+
+func encodeCycloneDX(w io.Writer, doc Document) error {
+	bom := cycloneDXBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  cycloneDXSpecVersion,
+		SerialNumber: fmt.Sprintf("urn:uuid:%v", uuid.New()),
+		Version:      1,
+		Metadata: cycloneDXMetadata{
+			Timestamp: doc.Created.UTC().Format(time.RFC3339),
+			Tools: []cycloneDXTool{{
+				Vendor:  toolVendor,
+				Name:    toolName,
+				Version: version.Get().Version,
+			}},
+			Component: cycloneDXComponent{
+				Type:    string(ComponentApplication),
+				Name:    doc.Name,
+				Version: doc.Version,
+			},
+		},
+	}
+	for _, component := range doc.Components {
+		c := cycloneDXComponent{
+			Type:    string(component.Type),
+			Name:    component.Name,
+			Version: component.Version,
+			PURL:    component.PURL,
+		}
+		if alg, hex, ok := splitDigest(component.Digest); ok {
+			if name, ok := cycloneDXHashes[alg]; ok {
+				c.Hashes = []cycloneDXHash{{Alg: name, Content: hex}}
+			}
+		}
+		bom.Components = append(bom.Components, c)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return trace.Wrap(encoder.Encode(bom))
+}
+
+func decodeCycloneDX(r io.Reader) (*Document, error) {
+	var bom cycloneDXBOM
+	if err := json.NewDecoder(r).Decode(&bom); err != nil {
+		return nil, trace.BadParameter("failed to decode CycloneDX document: %v", err)
+	}
+	if bom.BOMFormat != "CycloneDX" {
+		return nil, trace.BadParameter("expected CycloneDX document, got %q", bom.BOMFormat)
+	}
+	created, err := time.Parse(time.RFC3339, bom.Metadata.Timestamp)
+	if err != nil {
+		return nil, trace.BadParameter("invalid document timestamp %q", bom.Metadata.Timestamp)
+	}
+	doc := &Document{
+		Name:    bom.Metadata.Component.Name,
+		Version: bom.Metadata.Component.Version,
+		Created: created,
+	}
+	for _, c := range bom.Components {
+		component := Component{
+			Type:    ComponentType(c.Type),
+			Name:    c.Name,
+			Version: c.Version,
+			PURL:    c.PURL,
+		}
+		for _, hash := range c.Hashes {
+			for alg, name := range cycloneDXHashes {
+				if name == hash.Alg {
+					component.Digest = fmt.Sprintf("%v:%v", alg, hash.Content)
+				}
+			}
+		}
+		doc.Components = append(doc.Components, component)
+	}
+	return doc, nil
+}
+
+// splitDigest splits the digest in the form <algorithm>:<hex>
+func splitDigest(digest string) (alg, hex string, ok bool) {
+	parts := strings.SplitN(digest, ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// cycloneDXBOM is the subset of the CycloneDX document
+type cycloneDXBOM struct {
+	BOMFormat    string               `json:"bomFormat"`
+	SpecVersion  string               `json:"specVersion"`
+	SerialNumber string               `json:"serialNumber"`
+	Version      int                  `json:"version"`
+	Metadata     cycloneDXMetadata    `json:"metadata"`
+	Components   []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Tools     []cycloneDXTool    `json:"tools,omitempty"`
+	Component cycloneDXComponent `json:"component"`
+}
+
+type cycloneDXTool struct {
+	Vendor  string `json:"vendor"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type cycloneDXComponent struct {
+	Type    string          `json:"type"`
+	Name    string          `json:"name"`
+	Version string          `json:"version,omitempty"`
+	Hashes  []cycloneDXHash `json:"hashes,omitempty"`
+	PURL    string          `json:"purl,omitempty"`
+}
+
+type cycloneDXHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+// cycloneDXHashes maps digest algorithms to CycloneDX hash algorithm names
+var cycloneDXHashes = map[string]string{
+	"sha256": "SHA-256",
+	"sha512": "SHA-512",
+}
+
+const (
+	cycloneDXSpecVersion = "1.4"
+	// toolVendor is the vendor of the tool that generated the document
+	toolVendor = "Gravitational"
+	// toolName is the name of the tool that generated the document
+	toolName = "tele"
+)
@@ -0,0 +1,170 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sbom generates software bills of materials (SBOM) for cluster images.
+//
+// The SBOM lists all packages, container images and binaries shipped
+// with a cluster image and can be encoded in CycloneDX or SPDX format
+package sbom
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gravitational/gravity/lib/app/docker"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/pack"
+
+	"github.com/gravitational/trace"
+)
+
+// Document is the software bill of materials of a cluster image
+type Document struct {
+	// Name is the name of the cluster image
+	Name string
+	// Version is the version of the cluster image
+	Version string
+	// Created is the time the document was created
+	Created time.Time
+	// Components lists the components shipped with the cluster image
+	Components []Component
+}
+
+// Component describes a single component of a cluster image
+type Component struct {
+	// Type is the component type
+	Type ComponentType
+	// Name is the component name
+	Name string
+	// Version is the component version
+	Version string
+	// Digest is the component checksum in the form <algorithm>:<hex>
+	Digest string
+	// PURL is the package URL of the component
+	PURL string
+}
+
+// ComponentType defines the type of a component
+type ComponentType string
+
+const (
+	// ComponentApplication is an application package
+	ComponentApplication ComponentType = "application"
+	// ComponentContainer is a container image
+	ComponentContainer ComponentType = "container"
+	// ComponentFile is a regular package, e.g. a binary or a configuration package
+	ComponentFile ComponentType = "file"
+)
+
+// Sort sorts the components by type and name
+func (r *Document) Sort() {
+	sort.Slice(r.Components, func(i, j int) bool {
+		a, b := r.Components[i], r.Components[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Version < b.Version
+	})
+}
+
+// PackageComponents returns the components for the specified packages
+func PackageComponents(packages pack.PackageService, locators []loc.Locator) (components []Component, err error) {
+	for _, locator := range locators {
+		envelope, err := packages.ReadPackageEnvelope(locator)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		components = append(components, PackageComponent(*envelope))
+	}
+	return components, nil
+}
+
+// PackageComponent returns the component for the specified package
+func PackageComponent(envelope pack.PackageEnvelope) Component {
+	componentType := ComponentFile
+	if envelope.Type != "" {
+		componentType = ComponentApplication
+	}
+	return Component{
+		Type:    componentType,
+		Name:    envelope.Locator.Name,
+		Version: envelope.Locator.Version,
+		Digest:  fmt.Sprintf("sha512:%v", envelope.SHA512),
+		PURL: fmt.Sprintf("pkg:generic/%v/%v@%v", envelope.Locator.Repository,
+			envelope.Locator.Name, envelope.Locator.Version),
+	}
+}
+
+// ImageComponents returns the components for the container images
+// in the local registry directory dir
+func ImageComponents(ctx context.Context, dir string) (components []Component, err error) {
+	images, err := docker.ListImages(ctx, dir)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, image := range images {
+		name := image.Name
+		if i := strings.LastIndex(name, "/"); i != -1 {
+			name = name[i+1:]
+		}
+		components = append(components, Component{
+			Type:    ComponentContainer,
+			Name:    image.Name,
+			Version: image.Version,
+			Digest:  image.Digest.String(),
+			PURL: fmt.Sprintf("pkg:oci/%v@%v?repository_url=%v&tag=%v",
+				name, strings.Replace(image.Digest.String(), ":", "%3A", 1),
+				image.Name, image.Version),
+		})
+	}
+	return components, nil
+}
+
+// Encode writes the document to w in the specified format
+func Encode(w io.Writer, doc Document, format Format) error {
+	switch format {
+	case FormatCycloneDX:
+		return trace.Wrap(encodeCycloneDX(w, doc))
+	case FormatSPDX:
+		return trace.Wrap(encodeSPDX(w, doc))
+	}
+	return trace.BadParameter("unsupported SBOM format %q, supported are: %v", format, Formats)
+}
+
+// Decode reads the document in CycloneDX format from r
+func Decode(r io.Reader) (*Document, error) {
+	return decodeCycloneDX(r)
+}
+
+// Format defines the SBOM document format
+type Format string
+
+const (
+	// FormatCycloneDX is the CycloneDX JSON format
+	FormatCycloneDX Format = "cyclonedx"
+	// FormatSPDX is the SPDX JSON format
+	FormatSPDX Format = "spdx"
+)
+
+// Formats lists the supported SBOM formats
+var Formats = []Format{FormatCycloneDX, FormatSPDX}
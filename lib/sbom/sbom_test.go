@@ -0,0 +1,116 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gravitational/gravity/lib/compare"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/pack"
+
+	"github.com/gravitational/trace"
+	"gopkg.in/check.v1"
+)
+
+func TestSBOM(t *testing.T) { check.TestingT(t) }
+
+type SBOMSuite struct{}
+
+var _ = check.Suite(&SBOMSuite{})
+
+func (s *SBOMSuite) TestCycloneDXRoundtrip(c *check.C) {
+	doc := newTestDocument()
+	var buf bytes.Buffer
+	c.Assert(Encode(&buf, doc, FormatCycloneDX), check.IsNil)
+
+	decoded, err := Decode(&buf)
+	c.Assert(err, check.IsNil)
+	c.Assert(*decoded, compare.DeepEquals, doc)
+}
+
+func (s *SBOMSuite) TestEncodesSPDX(c *check.C) {
+	var buf bytes.Buffer
+	c.Assert(Encode(&buf, newTestDocument(), FormatSPDX), check.IsNil)
+
+	var spdx spdxDocument
+	c.Assert(json.Unmarshal(buf.Bytes(), &spdx), check.IsNil)
+	c.Assert(spdx.SPDXVersion, check.Equals, spdxVersion)
+	// the cluster image itself and its components
+	c.Assert(spdx.Packages, check.HasLen, 4)
+	c.Assert(spdx.Packages[2].Checksums, compare.DeepEquals, []spdxChecksum{
+		{Algorithm: "SHA256", ChecksumValue: "0123abcd"},
+	})
+	// the document describes the cluster image that contains the components
+	c.Assert(spdx.Relationships, check.HasLen, 4)
+	c.Assert(spdx.Relationships[0].RelationshipType, check.Equals, "DESCRIBES")
+}
+
+func (s *SBOMSuite) TestRejectsUnknownFormat(c *check.C) {
+	var buf bytes.Buffer
+	err := Encode(&buf, newTestDocument(), Format("swid"))
+	c.Assert(trace.IsBadParameter(err), check.Equals, true)
+}
+
+func (s *SBOMSuite) TestPackageComponent(c *check.C) {
+	c.Assert(PackageComponent(pack.PackageEnvelope{
+		Locator: loc.MustParseLocator("gravitational.io/planet:5.5.0"),
+		SHA512:  "abcd",
+	}), compare.DeepEquals, Component{
+		Type:    ComponentFile,
+		Name:    "planet",
+		Version: "5.5.0",
+		Digest:  "sha512:abcd",
+		PURL:    "pkg:generic/gravitational.io/planet@5.5.0",
+	})
+	c.Assert(PackageComponent(pack.PackageEnvelope{
+		Locator: loc.MustParseLocator("gravitational.io/dns-app:0.3.0"),
+		Type:    "app",
+	}).Type, check.Equals, ComponentApplication)
+}
+
+func newTestDocument() Document {
+	return Document{
+		Name:    "cluster",
+		Version: "1.0.0",
+		Created: time.Date(2019, time.May, 1, 10, 0, 0, 0, time.UTC),
+		Components: []Component{
+			{
+				Type:    ComponentApplication,
+				Name:    "dns-app",
+				Version: "0.3.0",
+				Digest:  "sha512:abcd",
+				PURL:    "pkg:generic/gravitational.io/dns-app@0.3.0",
+			},
+			{
+				Type:    ComponentContainer,
+				Name:    "nginx",
+				Version: "1.17",
+				Digest:  "sha256:0123abcd",
+				PURL:    "pkg:oci/nginx@sha256%3A0123abcd?repository_url=nginx&tag=1.17",
+			},
+			{
+				Type:    ComponentFile,
+				Name:    "gravity",
+				Version: "6.1.0",
+			},
+		},
+	}
+}
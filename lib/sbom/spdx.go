@@ -0,0 +1,147 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gravitational/trace"
+	"github.com/gravitational/version"
+	"github.com/pborman/uuid"
+)
+
+func encodeSPDX(w io.Writer, doc Document) error {
+	const rootID = "SPDXRef-ClusterImage"
+	spdx := spdxDocument{
+		SPDXVersion:       spdxVersion,
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              fmt.Sprintf("%v-%v", doc.Name, doc.Version),
+		DocumentNamespace: fmt.Sprintf("%v/%v-%v-%v", spdxNamespace, doc.Name, doc.Version, uuid.New()),
+		CreationInfo: spdxCreationInfo{
+			Created: doc.Created.UTC().Format(time.RFC3339),
+			Creators: []string{
+				fmt.Sprintf("Organization: %v", toolVendor),
+				fmt.Sprintf("Tool: %v-%v", toolName, version.Get().Version),
+			},
+		},
+		Packages: []spdxPackage{{
+			Name:             doc.Name,
+			SPDXID:           rootID,
+			VersionInfo:      doc.Version,
+			DownloadLocation: spdxNoAssertion,
+		}},
+		Relationships: []spdxRelationship{{
+			SPDXElementID:      "SPDXRef-DOCUMENT",
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: rootID,
+		}},
+	}
+	for i, component := range doc.Components {
+		id := fmt.Sprintf("SPDXRef-%v-%v", strings.Title(string(component.Type)), i)
+		p := spdxPackage{
+			Name:             component.Name,
+			SPDXID:           id,
+			VersionInfo:      component.Version,
+			DownloadLocation: spdxNoAssertion,
+			PrimaryPurpose:   spdxPurposes[component.Type],
+		}
+		if alg, hex, ok := splitDigest(component.Digest); ok {
+			p.Checksums = []spdxChecksum{{
+				Algorithm:     strings.ToUpper(alg),
+				ChecksumValue: hex,
+			}}
+		}
+		if component.PURL != "" {
+			p.ExternalRefs = []spdxExternalRef{{
+				ReferenceCategory: "PACKAGE-MANAGER",
+				ReferenceType:     "purl",
+				ReferenceLocator:  component.PURL,
+			}}
+		}
+		spdx.Packages = append(spdx.Packages, p)
+		spdx.Relationships = append(spdx.Relationships, spdxRelationship{
+			SPDXElementID:      rootID,
+			RelationshipType:   "CONTAINS",
+			RelatedSPDXElement: id,
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return trace.Wrap(encoder.Encode(spdx))
+}
+
+// spdxDocument is the subset of the SPDX document
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	PrimaryPurpose   string            `json:"primaryPackagePurpose,omitempty"`
+	Checksums        []spdxChecksum    `json:"checksums,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// spdxPurposes maps component types to SPDX package purposes
+var spdxPurposes = map[ComponentType]string{
+	ComponentApplication: "APPLICATION",
+	ComponentContainer:   "CONTAINER",
+	ComponentFile:        "FILE",
+}
+
+const (
+	spdxVersion     = "SPDX-2.3"
+	spdxNoAssertion = "NOASSERTION"
+	spdxNamespace   = "https://gravitational.io/spdx"
+)
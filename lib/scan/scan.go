@@ -0,0 +1,305 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package scan implements vulnerability scanning of the container
+// images embedded in application packages
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gravitational/gravity/lib/app/docker"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/utils"
+
+	teleutils "github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+)
+
+// Scanner scans container images for known vulnerabilities
+type Scanner interface {
+	// Name returns the name of the scanner
+	Name() string
+	// ScanImage scans the image specified with the reference
+	// and returns the list of found vulnerabilities
+	ScanImage(ctx context.Context, image string) ([]Vulnerability, error)
+}
+
+// Config defines the configuration to scan a local registry directory
+type Config struct {
+	// Dir is the local registry directory with the images to scan
+	Dir string
+	// Scanner is the vulnerability scanner
+	Scanner Scanner
+	// Progress is used to report the scan progress
+	Progress utils.Progress
+	// FieldLogger is used for logging
+	logrus.FieldLogger
+}
+
+// CheckAndSetDefaults validates the configuration and sets defaults
+func (r *Config) CheckAndSetDefaults() error {
+	if r.Dir == "" {
+		return trace.BadParameter("missing registry directory")
+	}
+	if r.Scanner == nil {
+		return trace.BadParameter("missing Scanner")
+	}
+	if r.Progress == nil {
+		r.Progress = utils.DiscardProgress
+	}
+	if r.FieldLogger == nil {
+		r.FieldLogger = logrus.WithField(trace.Component, "scan")
+	}
+	return nil
+}
+
+// ScanDir scans all images in the local registry directory.
+//
+// The directory is served with a temporary registry on the loopback
+// interface for the duration of the scan so the scanner can pull the images
+func ScanDir(ctx context.Context, config Config) (*Report, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	images, err := docker.ListImages(ctx, config.Dir)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	registry, err := docker.NewRegistry(docker.BasicConfiguration("127.0.0.1:0", config.Dir))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := registry.Start(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer registry.Close()
+	report := &Report{
+		Scanner: config.Scanner.Name(),
+		Created: time.Now().UTC(),
+	}
+	for _, image := range images {
+		config.Progress.PrintSubStep("Scanning %v", image.TagSpec)
+		ref := fmt.Sprintf("%v/%v", registry.Addr(), image.TagSpec)
+		vulnerabilities, err := config.Scanner.ScanImage(ctx, ref)
+		if err != nil {
+			return nil, trace.Wrap(err, "failed to scan image %v", image.TagSpec)
+		}
+		config.WithField("image", image.TagSpec).Infof("Found %v vulnerabilities.", len(vulnerabilities))
+		report.Images = append(report.Images, ImageReport{
+			Image:           image.String(),
+			Vulnerabilities: vulnerabilities,
+		})
+	}
+	return report, nil
+}
+
+// Report is the result of scanning a set of images
+type Report struct {
+	// Scanner is the name of the scanner that produced the report
+	Scanner string `json:"scanner"`
+	// Created is the time the report was created
+	Created time.Time `json:"created"`
+	// Images lists scan results for individual images
+	Images []ImageReport `json:"images,omitempty"`
+}
+
+// Counts returns the number of vulnerabilities of each severity in all images
+func (r Report) Counts() map[Severity]int {
+	counts := make(map[Severity]int)
+	for _, image := range r.Images {
+		for severity, count := range image.Counts() {
+			counts[severity] += count
+		}
+	}
+	return counts
+}
+
+// ImageReport is the result of scanning a single image
+type ImageReport struct {
+	// Image is the scanned image reference
+	Image string `json:"image"`
+	// Vulnerabilities lists the vulnerabilities found in the image
+	Vulnerabilities []Vulnerability `json:"vulnerabilities,omitempty"`
+}
+
+// Counts returns the number of vulnerabilities of each severity
+func (r ImageReport) Counts() map[Severity]int {
+	counts := make(map[Severity]int)
+	for _, vulnerability := range r.Vulnerabilities {
+		counts[vulnerability.Severity]++
+	}
+	return counts
+}
+
+// Vulnerability describes a known vulnerability in an image package
+type Vulnerability struct {
+	// ID is the vulnerability identifier, e.g. CVE-2019-5736
+	ID string `json:"id"`
+	// Package is the name of the affected package
+	Package string `json:"package"`
+	// InstalledVersion is the version of the package installed in the image
+	InstalledVersion string `json:"installed_version,omitempty"`
+	// FixedVersion is the version of the package with the fix, if available
+	FixedVersion string `json:"fixed_version,omitempty"`
+	// Severity is the vulnerability severity
+	Severity Severity `json:"severity"`
+	// Title is the short vulnerability description
+	Title string `json:"title,omitempty"`
+}
+
+// Policy defines the vulnerability thresholds that fail the scan
+type Policy struct {
+	// Threshold is the lowest severity that fails the scan.
+	// Empty threshold never fails the scan
+	Threshold Severity
+	// IgnoreUnfixed ignores vulnerabilities without an available fix
+	IgnoreUnfixed bool
+}
+
+// Check returns an error if the report has vulnerabilities
+// at or above the policy threshold
+func (r Policy) Check(report Report) error {
+	if r.Threshold == "" {
+		return nil
+	}
+	var violations []string
+	for _, image := range report.Images {
+		var ids []string
+		for _, vulnerability := range image.Vulnerabilities {
+			if r.IgnoreUnfixed && vulnerability.FixedVersion == "" {
+				continue
+			}
+			if vulnerability.Severity.AtLeast(r.Threshold) {
+				ids = append(ids, vulnerability.ID)
+			}
+		}
+		if len(ids) != 0 {
+			violations = append(violations, fmt.Sprintf("%v (%v)",
+				image.Image, strings.Join(teleutils.Deduplicate(ids), ", ")))
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return trace.CompareFailed("found vulnerabilities with severity %v or higher in: %v",
+		r.Threshold, strings.Join(violations, "; "))
+}
+
+// Severity is the vulnerability severity
+type Severity string
+
+// ParseSeverity parses the severity from the specified string
+func ParseSeverity(value string) (Severity, error) {
+	severity := Severity(strings.ToUpper(value))
+	for _, s := range Severities {
+		if s == severity {
+			return severity, nil
+		}
+	}
+	return "", trace.BadParameter("unknown severity %q, supported are: %v", value, Severities)
+}
+
+// AtLeast returns true if this severity is the same or higher than other
+func (r Severity) AtLeast(other Severity) bool {
+	return r.rank() >= other.rank()
+}
+
+func (r Severity) rank() int {
+	for i, s := range Severities {
+		if s == r {
+			return i
+		}
+	}
+	return 0
+}
+
+// WriteReport writes the report in JSON format to the file at path
+func WriteReport(path string, report Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = ioutil.WriteFile(path, data, defaults.SharedReadMask)
+	return trace.ConvertSystemError(err)
+}
+
+// ReadReport reads the report in JSON format from the file at path
+func ReadReport(path string) (*Report, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &report, nil
+}
+
+// SortedSeverities returns the severities from the specified counts
+// starting with the most severe
+func SortedSeverities(counts map[Severity]int) (result []Severity) {
+	for severity := range counts {
+		result = append(result, severity)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].rank() > result[j].rank()
+	})
+	return result
+}
+
+// FormatCounts formats the vulnerability counts starting with the most severe,
+// e.g. "2 CRITICAL, 5 HIGH"
+func FormatCounts(counts map[Severity]int) string {
+	if len(counts) == 0 {
+		return "none"
+	}
+	var parts []string
+	for _, severity := range SortedSeverities(counts) {
+		parts = append(parts, fmt.Sprintf("%v %v", counts[severity], severity))
+	}
+	return strings.Join(parts, ", ")
+}
+
+const (
+	// SeverityUnknown is the severity of vulnerabilities not yet classified
+	SeverityUnknown Severity = "UNKNOWN"
+	// SeverityLow is the low severity
+	SeverityLow Severity = "LOW"
+	// SeverityMedium is the medium severity
+	SeverityMedium Severity = "MEDIUM"
+	// SeverityHigh is the high severity
+	SeverityHigh Severity = "HIGH"
+	// SeverityCritical is the critical severity
+	SeverityCritical Severity = "CRITICAL"
+)
+
+// Severities lists all severities from the least severe
+var Severities = []Severity{
+	SeverityUnknown,
+	SeverityLow,
+	SeverityMedium,
+	SeverityHigh,
+	SeverityCritical,
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scan
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/gravitational/gravity/lib/compare"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+	"gopkg.in/check.v1"
+)
+
+func TestScan(t *testing.T) { check.TestingT(t) }
+
+type ScanSuite struct{}
+
+var _ = check.Suite(&ScanSuite{})
+
+func (s *ScanSuite) TestParsesTrivyOutput(c *check.C) {
+	var testCases = []struct {
+		comment string
+		output  string
+	}{
+		{
+			comment: "report object",
+			output:  `{"SchemaVersion":2,"Results":[` + trivyResultJSON + `]}`,
+		},
+		{
+			comment: "list of results",
+			output:  `[` + trivyResultJSON + `]`,
+		},
+	}
+	for _, tc := range testCases {
+		comment := check.Commentf(tc.comment)
+		scanner := &trivy{
+			path: TrivyBinary,
+			runner: utils.CommandRunnerFunc(func(ctx context.Context, w io.Writer, args ...string) error {
+				c.Assert(args[len(args)-1], check.Equals, "127.0.0.1:5000/nginx:1.17", comment)
+				_, err := w.Write([]byte(tc.output))
+				return err
+			}),
+		}
+		vulnerabilities, err := scanner.ScanImage(context.TODO(), "127.0.0.1:5000/nginx:1.17")
+		c.Assert(err, check.IsNil, comment)
+		c.Assert(vulnerabilities, compare.DeepEquals, []Vulnerability{
+			{
+				ID:               "CVE-2019-1543",
+				Package:          "openssl",
+				InstalledVersion: "1.1.1a-r1",
+				FixedVersion:     "1.1.1b-r1",
+				Severity:         SeverityHigh,
+				Title:            "openssl: ChaCha20-Poly1305 with long nonces",
+			},
+			{
+				ID:               "CVE-2019-0000",
+				Package:          "musl",
+				InstalledVersion: "1.1.20-r3",
+				Severity:         SeverityUnknown,
+			},
+		}, comment)
+	}
+}
+
+func (s *ScanSuite) TestPolicyThresholds(c *check.C) {
+	report := Report{
+		Images: []ImageReport{
+			{
+				Image: "nginx:1.17",
+				Vulnerabilities: []Vulnerability{
+					{ID: "CVE-1", Severity: SeverityHigh},
+					{ID: "CVE-2", Severity: SeverityMedium, FixedVersion: "1.0.1"},
+				},
+			},
+			{
+				Image: "alpine:3.9",
+				Vulnerabilities: []Vulnerability{
+					{ID: "CVE-3", Severity: SeverityLow, FixedVersion: "2.0.0"},
+				},
+			},
+		},
+	}
+	var testCases = []struct {
+		comment string
+		policy  Policy
+		failed  bool
+	}{
+		{
+			comment: "no threshold",
+			policy:  Policy{},
+		},
+		{
+			comment: "critical threshold",
+			policy:  Policy{Threshold: SeverityCritical},
+		},
+		{
+			comment: "high threshold",
+			policy:  Policy{Threshold: SeverityHigh},
+			failed:  true,
+		},
+		{
+			comment: "high threshold ignoring unfixed",
+			policy:  Policy{Threshold: SeverityHigh, IgnoreUnfixed: true},
+		},
+		{
+			comment: "low threshold ignoring unfixed",
+			policy:  Policy{Threshold: SeverityLow, IgnoreUnfixed: true},
+			failed:  true,
+		},
+	}
+	for _, tc := range testCases {
+		comment := check.Commentf(tc.comment)
+		err := tc.policy.Check(report)
+		if tc.failed {
+			c.Assert(trace.IsCompareFailed(err), check.Equals, true, comment)
+		} else {
+			c.Assert(err, check.IsNil, comment)
+		}
+	}
+	c.Assert(report.Counts(), check.DeepEquals, map[Severity]int{
+		SeverityHigh:   1,
+		SeverityMedium: 1,
+		SeverityLow:    1,
+	})
+}
+
+func (s *ScanSuite) TestParsesSeverity(c *check.C) {
+	severity, err := ParseSeverity("high")
+	c.Assert(err, check.IsNil)
+	c.Assert(severity, check.Equals, SeverityHigh)
+	_, err = ParseSeverity("severe")
+	c.Assert(trace.IsBadParameter(err), check.Equals, true)
+	c.Assert(SortedSeverities(map[Severity]int{SeverityLow: 1, SeverityCritical: 2}),
+		check.DeepEquals, []Severity{SeverityCritical, SeverityLow})
+	c.Assert(FormatCounts(map[Severity]int{SeverityLow: 1, SeverityCritical: 2}),
+		check.Equals, "2 CRITICAL, 1 LOW")
+	c.Assert(FormatCounts(nil), check.Equals, "none")
+}
+
+const trivyResultJSON = `{
+  "Target": "127.0.0.1:5000/nginx:1.17 (alpine 3.9.2)",
+  "Vulnerabilities": [
+    {
+      "VulnerabilityID": "CVE-2019-1543",
+      "PkgName": "openssl",
+      "InstalledVersion": "1.1.1a-r1",
+      "FixedVersion": "1.1.1b-r1",
+      "Severity": "HIGH",
+      "Title": "openssl: ChaCha20-Poly1305 with long nonces"
+    },
+    {
+      "VulnerabilityID": "CVE-2019-0000",
+      "PkgName": "musl",
+      "InstalledVersion": "1.1.20-r3",
+      "Severity": "NEGLIGIBLE"
+    }
+  ]
+}`
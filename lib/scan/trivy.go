@@ -0,0 +1,122 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+)
+
+// NewTrivy returns a scanner that uses the trivy binary at the specified path.
+// If path is empty, trivy is looked up in PATH
+func NewTrivy(path string) *trivy {
+	if path == "" {
+		path = TrivyBinary
+	}
+	return &trivy{
+		path:   path,
+		runner: utils.Runner,
+	}
+}
+
+// Name returns the name of the scanner
+func (r *trivy) Name() string {
+	return TrivyBinary
+}
+
+// ScanImage scans the specified image with trivy
+func (r *trivy) ScanImage(ctx context.Context, image string) ([]Vulnerability, error) {
+	var out bytes.Buffer
+	err := r.runner.RunStream(ctx, &out, r.path, "image",
+		"--quiet", "--no-progress", "--format", "json", image)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to run %v", r.path)
+	}
+	return parseTrivyOutput(out.Bytes())
+}
+
+// parseTrivyOutput parses the JSON output of trivy.
+// Older versions output the list of results while newer versions
+// wrap the results into an object
+func parseTrivyOutput(data []byte) (result []Vulnerability, err error) {
+	data = bytes.TrimSpace(data)
+	var results []trivyResult
+	if len(data) != 0 && data[0] == '[' {
+		err = json.Unmarshal(data, &results)
+	} else {
+		var report trivyReport
+		err = json.Unmarshal(data, &report)
+		results = report.Results
+	}
+	if err != nil {
+		return nil, trace.BadParameter("failed to parse trivy output: %v", err)
+	}
+	for _, r := range results {
+		for _, v := range r.Vulnerabilities {
+			severity, err := ParseSeverity(v.Severity)
+			if err != nil {
+				severity = SeverityUnknown
+			}
+			result = append(result, Vulnerability{
+				ID:               v.VulnerabilityID,
+				Package:          v.PkgName,
+				InstalledVersion: v.InstalledVersion,
+				FixedVersion:     v.FixedVersion,
+				Severity:         severity,
+				Title:            v.Title,
+			})
+		}
+	}
+	return result, nil
+}
+
+type trivy struct {
+	path   string
+	runner utils.CommandRunner
+}
+
+// trivyReport is the subset of the trivy JSON report
+type trivyReport struct {
+	// Results lists scan results per target
+	Results []trivyResult `json:"Results"`
+}
+
+// trivyResult is the scan result of a single target, e.g. OS packages
+type trivyResult struct {
+	// Target is the scanned target
+	Target string `json:"Target"`
+	// Vulnerabilities lists the found vulnerabilities
+	Vulnerabilities []trivyVulnerability `json:"Vulnerabilities"`
+}
+
+// trivyVulnerability describes a single vulnerability in the trivy report
+type trivyVulnerability struct {
+	VulnerabilityID  string `json:"VulnerabilityID"`
+	PkgName          string `json:"PkgName"`
+	InstalledVersion string `json:"InstalledVersion"`
+	FixedVersion     string `json:"FixedVersion"`
+	Severity         string `json:"Severity"`
+	Title            string `json:"Title"`
+}
+
+// TrivyBinary is the name of the trivy scanner binary
+const TrivyBinary = "trivy"
@@ -140,6 +140,10 @@ func ValidateRequirements(reqs Requirements, stateDir string) (failed []*pb.Prob
 		}))
 	}
 
+	if modules := reqs.KernelModuleRequests(); len(modules) != 0 {
+		checkers = append(checkers, monitoring.NewKernelModuleChecker(modules...))
+	}
+
 	for _, check := range reqs.CustomChecks {
 		checkers = append(checkers,
 			monitoring.NewScriptChecker(monitoring.Script{
@@ -155,6 +159,14 @@ func ValidateRequirements(reqs Requirements, stateDir string) (failed []*pb.Prob
 	return probes.GetFailed(), nil
 }
 
+// KernelModuleRequests returns the kernel modules required by these requirements
+func (r Requirements) KernelModuleRequests() (modules []monitoring.ModuleRequest) {
+	for _, name := range r.KernelModules {
+		modules = append(modules, moduleName(name))
+	}
+	return modules
+}
+
 // shouldCheckVolume determines if this volume should be checked
 func shouldCheckVolume(volume Volume) bool {
 	isDir, err := utils.IsDirectory(volume.Path)
@@ -33,6 +33,10 @@ const (
 	ProviderOnPrem = "onprem"
 	// ProviderGCE defines Google Compute Engine provider
 	ProviderGCE = "gce"
+	// ProviderAzure defines Microsoft Azure provider
+	ProviderAzure = "azure"
+	// ProviderVSphere defines VMware vSphere provider
+	ProviderVSphere = "vsphere"
 
 	// ProvisionerAWSTerraform defines an operation provisioner based on terraform
 	ProvisionerAWSTerraform = "aws_terraform"
@@ -70,6 +74,9 @@ const (
 	KindBundle = "Bundle"
 	// KindCluster defines a cluster type (former "Bundle")
 	KindCluster = "Cluster"
+	// KindPlatform defines a runtime-only cluster type: a Kubernetes cluster
+	// without a user application, with applications installed later from the catalog
+	KindPlatform = "Platform"
 	// KindApplication defines a user application type
 	KindApplication = "Application"
 	// KindSystemApplication defines a system application type
@@ -121,6 +128,27 @@ const (
 	ApplicationDefaultNamespace = "default"
 )
 
+const (
+	// UpgradeStrategyRolling upgrades the cluster nodes one after another
+	UpgradeStrategyRolling = "rolling"
+	// UpgradeStrategyCanary upgrades a single canary node first and
+	// proceeds with the remaining nodes once the canary is approved
+	UpgradeStrategyCanary = "canary"
+)
+
+// UpgradeStrategies lists supported upgrade strategies
+var UpgradeStrategies = []string{UpgradeStrategyRolling, UpgradeStrategyCanary}
+
+const (
+	// SmokeTestInstall runs the smoke test at the end of the install operation
+	SmokeTestInstall = "install"
+	// SmokeTestUpgrade runs the smoke test at the end of the upgrade operation
+	SmokeTestUpgrade = "upgrade"
+)
+
+// SmokeTestOperations lists the operations smoke tests can run after
+var SmokeTestOperations = []string{SmokeTestInstall, SmokeTestUpgrade}
+
 var (
 	// APIVersionV2 specifies the current API version
 	APIVersionV2 = fmt.Sprintf("%v/%v", GroupName, Version)
@@ -135,4 +163,6 @@ var SupportedProviders = []string{
 	ProviderGeneric,
 	ProviderAWS,
 	ProviderGCE,
+	ProviderAzure,
+	ProviderVSphere,
 }
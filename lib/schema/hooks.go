@@ -197,6 +197,9 @@ const (
 	HookNetworkUpdate = "networkUpdate"
 	// HookNetworkRollback defines a hook to rollback the overlay network
 	HookNetworkRollback = "networkRollback"
+	// HookSmokeTest defines the hook that runs an application smoke test job.
+	// The smoke tests are defined in the manifest smokeTests section
+	HookSmokeTest HookType = "smokeTest"
 )
 
 // String implements Stringer
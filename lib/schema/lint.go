@@ -0,0 +1,442 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/ghodss/yaml"
+	"github.com/gravitational/trace"
+	"github.com/santhosh-tekuri/jsonschema"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// LintSeverity defines the severity of a manifest lint issue
+type LintSeverity string
+
+const (
+	// LintError is the severity of an issue that fails the build or the installation
+	LintError LintSeverity = "error"
+	// LintWarning is the severity of an issue that is likely a mistake
+	LintWarning LintSeverity = "warning"
+)
+
+// LintIssue describes a problem found in the manifest
+type LintIssue struct {
+	// Severity is the issue severity
+	Severity LintSeverity `json:"severity"`
+	// Path is the JSON pointer to the offending manifest field, e.g. /nodeProfiles/0.
+	// Empty if the issue does not refer to a specific field
+	Path string `json:"path,omitempty"`
+	// Message describes the issue
+	Message string `json:"message"`
+}
+
+// String returns a textual representation of the issue
+func (r LintIssue) String() string {
+	if r.Path == "" {
+		return fmt.Sprintf("%v: %v", r.Severity, r.Message)
+	}
+	return fmt.Sprintf("%v: %v: %v", r.Severity, r.Path, r.Message)
+}
+
+// LintIssues is a list of manifest lint issues
+type LintIssues []LintIssue
+
+// HasErrors returns true if any of the issues is an error
+func (r LintIssues) HasErrors() bool {
+	for _, issue := range r {
+		if issue.Severity == LintError {
+			return true
+		}
+	}
+	return false
+}
+
+// Lint validates the manifest file at the specified path against the
+// manifest schema and runs the semantic checks on it.
+// The returned error only indicates that the file could not be read
+func Lint(path string) (LintIssues, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	return LintManifestYAML(data, path), nil
+}
+
+// LintManifestYAML validates the provided manifest data.
+// manifestPath is used to resolve the file references in the manifest
+func LintManifestYAML(data []byte, manifestPath string) LintIssues {
+	var l linter
+	data = ExpandEnvVars(data)
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		l.errorf("", "invalid YAML: %v", err)
+		return l.issues
+	}
+	var header Header
+	if err := json.Unmarshal(jsonData, &header); err != nil {
+		l.errorf("", "invalid manifest: %v", err)
+		return l.issues
+	}
+	switch header.APIVersion {
+	case APIVersionV2, APIVersionV2Cluster, APIVersionV2App:
+		// the semantic checks require a manifest that matches the schema
+		if l.checkSchema(jsonData); len(l.issues) != 0 {
+			return l.issues
+		}
+	case APIVersionV1:
+	default:
+		l.errorf("/apiVersion", "unknown manifest API version %q", header.APIVersion)
+		return l.issues
+	}
+	manifest, err := parseManifestYAML(data)
+	if err != nil {
+		l.errorf("", "%v", trace.UserMessage(err))
+		return l.issues
+	}
+	l.checkFlavors(*manifest)
+	l.checkHooks(manifest, manifestPath)
+	l.checkPorts(*manifest)
+	if err := ProcessMultiSourceValues(manifest, manifestPath); err != nil {
+		l.errorf("", "%v", trace.UserMessage(err))
+	}
+	// report the rest of the problems found by the regular validation
+	for _, err := range flattenErrors(CheckAndSetDefaults(manifest)) {
+		l.errorf("", "%v", trace.UserMessage(err))
+	}
+	return l.issues
+}
+
+type linter struct {
+	issues LintIssues
+}
+
+func (l *linter) errorf(path, format string, args ...interface{}) {
+	l.add(LintError, path, fmt.Sprintf(format, args...))
+}
+
+func (l *linter) warningf(path, format string, args ...interface{}) {
+	l.add(LintWarning, path, fmt.Sprintf(format, args...))
+}
+
+// add records the issue unless the same problem has already been reported
+func (l *linter) add(severity LintSeverity, path, message string) {
+	for _, issue := range l.issues {
+		if issue.Message == message && (issue.Path == path || path == "") {
+			return
+		}
+	}
+	l.issues = append(l.issues, LintIssue{
+		Severity: severity,
+		Path:     path,
+		Message:  message,
+	})
+}
+
+// checkSchema validates the manifest against the JSON schema and reports
+// every violation
+func (l *linter) checkSchema(data []byte) {
+	doc, err := jsonschema.DecodeJSON(bytes.NewReader(data))
+	if err != nil {
+		l.errorf("", "invalid manifest: %v", err)
+		return
+	}
+	l.validate(schema, doc, "")
+}
+
+// validate validates the value at the specified path against the schema.
+// Since the validator stops at the first violation, the objects and arrays
+// are descended into to find the violations in all of their items
+func (l *linter) validate(s *jsonschema.Schema, value interface{}, path string) {
+	for s.Ref != nil {
+		s = s.Ref
+	}
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if isShallow(s) && utils.StringInSlice(s.Types, "object") {
+			l.validateObject(s, value, path)
+			return
+		}
+	case []interface{}:
+		items, ok := s.Items.(*jsonschema.Schema)
+		if ok && isShallow(s) && s.MinItems < 0 && s.MaxItems < 0 && !s.UniqueItems &&
+			utils.StringInSlice(s.Types, "array") {
+			for i, item := range value {
+				l.validate(items, item, fmt.Sprintf("%v/%v", path, i))
+			}
+			return
+		}
+	}
+	err := s.ValidateInterface(value)
+	if err == nil {
+		return
+	}
+	verr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		l.errorf(path, "%v", err)
+		return
+	}
+	// report the innermost cause
+	for len(verr.Causes) != 0 {
+		verr = verr.Causes[0]
+	}
+	l.errorf(path+strings.TrimPrefix(verr.InstancePtr, "#"), "%v", verr.Message)
+}
+
+func (l *linter) validateObject(s *jsonschema.Schema, object map[string]interface{}, path string) {
+	var missing, unknown []string
+	for _, name := range s.Required {
+		if _, ok := object[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) != 0 {
+		l.errorf(path, "missing properties: %v", strings.Join(missing, ", "))
+	}
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		propertyPath := fmt.Sprintf("%v/%v", path, escapePointer(name))
+		if property, ok := s.Properties[name]; ok {
+			l.validate(property, object[name], propertyPath)
+			continue
+		}
+		switch additional := s.AdditionalProperties.(type) {
+		case *jsonschema.Schema:
+			l.validate(additional, object[name], propertyPath)
+		case bool:
+			if !additional {
+				unknown = append(unknown, strconv.Quote(name))
+			}
+		}
+	}
+	if len(unknown) != 0 {
+		l.errorf(path, "unknown properties: %v", strings.Join(unknown, ", "))
+	}
+}
+
+// isShallow returns true if the schema does not have constraints
+// that apply to the value as a whole
+func isShallow(s *jsonschema.Schema) bool {
+	return s.Always == nil && len(s.Constant) == 0 && len(s.Enum) == 0 && s.Not == nil &&
+		len(s.AllOf) == 0 && len(s.AnyOf) == 0 && len(s.OneOf) == 0 && s.If == nil &&
+		s.MinProperties < 0 && s.MaxProperties < 0 && s.PropertyNames == nil &&
+		len(s.PatternProperties) == 0 && len(s.Dependencies) == 0
+}
+
+// escapePointer escapes the specified JSON pointer token
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+// checkFlavors makes sure the flavors refer to the defined node profiles
+func (l *linter) checkFlavors(manifest Manifest) {
+	if manifest.Installer == nil {
+		return
+	}
+	for i, flavor := range manifest.Installer.Flavors.Items {
+		for j, node := range flavor.Nodes {
+			if _, err := manifest.NodeProfiles.ByName(node.Profile); err != nil {
+				l.errorf(fmt.Sprintf("/installer/flavors/items/%v/nodes/%v/profile", i, j),
+					"flavor %q refers to undefined profile %q", flavor.Name, node.Profile)
+			}
+		}
+	}
+}
+
+// checkHooks makes sure the hook and smoke test jobs can be parsed and
+// that the images they run are vendored into the image.
+// The file references in the jobs are replaced with the file contents
+func (l *linter) checkHooks(manifest *Manifest, manifestPath string) {
+	if manifest.Hooks != nil {
+		for _, hookType := range AllHooks() {
+			hook, err := HookFromString(hookType, *manifest)
+			if err != nil || hook == nil || hook.Empty() {
+				continue
+			}
+			l.checkJob(fmt.Sprintf("/hooks/%v/job", hookType), hookType, &hook.Job, manifestPath)
+		}
+	}
+	for i := range manifest.SmokeTests {
+		if manifest.SmokeTests[i].Job == "" {
+			continue
+		}
+		l.checkJob(fmt.Sprintf("/smokeTests/%v/job", i), HookSmokeTest, &manifest.SmokeTests[i].Job, manifestPath)
+	}
+}
+
+func (l *linter) checkJob(path string, hookType HookType, job *string, manifestPath string) {
+	if err := processText(job, manifestPath); err != nil {
+		l.errorf(path, "failed to read job: %v", trace.UserMessage(err))
+		// do not report the same problem again when validating the manifest
+		*job = ""
+		return
+	}
+	spec, err := Hook{Type: hookType, Job: *job}.GetJob()
+	if err != nil {
+		l.errorf(path, "failed to parse job: %v", trace.UserMessage(err))
+		*job = ""
+		return
+	}
+	containers := append([]corev1.Container{}, spec.Spec.Template.Spec.InitContainers...)
+	containers = append(containers, spec.Spec.Template.Spec.Containers...)
+	if len(containers) == 0 {
+		l.errorf(path, "%v job does not define any containers", hookType)
+		return
+	}
+	for _, container := range containers {
+		if container.Image == "" {
+			l.errorf(path, "container %q of the %v job does not specify an image",
+				container.Name, hookType)
+			continue
+		}
+		image, err := loc.ParseDockerImage(container.Image)
+		if err != nil {
+			l.errorf(path, "invalid image %q: %v", container.Image, trace.UserMessage(err))
+			continue
+		}
+		switch {
+		case isProvisioningHook(hookType):
+			l.warningf(path, "image %v of the %v hook is not included in the image and is pulled "+
+				"from its registry when the hook runs", container.Image, hookType)
+		case image.Tag == "":
+			l.warningf(path, "image %v does not specify a tag: the latest version at the time "+
+				"of the build will be included", container.Image)
+		}
+	}
+}
+
+// checkPorts makes sure the ports required by the node profiles are valid
+// and do not conflict with the ports used by the cluster services
+func (l *linter) checkPorts(manifest Manifest) {
+	reserved := ReservedPorts(defaults.VxlanPort)
+	for i, profile := range manifest.NodeProfiles {
+		seen := make(map[string]struct{})
+		for j, ports := range profile.Requirements.Network.Ports {
+			path := fmt.Sprintf("/nodeProfiles/%v/requirements/network/ports/%v", i, j)
+			if ports.Protocol != "tcp" && ports.Protocol != "udp" {
+				l.errorf(path, "unknown protocol for port: %q", ports.Protocol)
+				continue
+			}
+			for _, portRange := range ports.Ranges {
+				parsed, err := utils.ParsePorts(portRange)
+				if err != nil {
+					l.errorf(path, "invalid port range %q: %v", portRange, trace.UserMessage(err))
+					continue
+				}
+				for _, port := range parsed {
+					key := fmt.Sprintf("%v/%v", port, ports.Protocol)
+					if _, ok := seen[key]; ok {
+						l.warningf(path, "profile %q lists port %v more than once", profile.Name, key)
+						continue
+					}
+					seen[key] = struct{}{}
+					if r := findPortRange(reserved, ports.Protocol, port); r != nil {
+						l.errorf(path, "profile %q requires port %v which is used by %v",
+							profile.Name, key, r.Description)
+					}
+				}
+			}
+		}
+	}
+}
+
+// PortRange describes a range of ports
+type PortRange struct {
+	// Protocol is the port protocol: tcp or udp
+	Protocol string
+	// From is the first port of the range
+	From int
+	// To is the last port of the range
+	To int
+	// Description describes the service using the ports
+	Description string
+}
+
+// Contains returns true if the specified port is within this range
+func (r PortRange) Contains(protocol string, port int) bool {
+	return r.Protocol == protocol && port >= r.From && port <= r.To
+}
+
+// ReservedPorts returns the ports the cluster services listen on every node.
+// vxlanPort specifies the port of the overlay network
+func ReservedPorts(vxlanPort int) []PortRange {
+	return []PortRange{
+		{Protocol: "tcp", From: 7496, To: 7496, Description: "serf (health check agents) peer to peer"},
+		{Protocol: "tcp", From: 7373, To: 7373, Description: "serf (health check agents) peer to peer"},
+		{Protocol: "tcp", From: 2379, To: 2380, Description: "etcd"},
+		{Protocol: "tcp", From: 4001, To: 4001, Description: "etcd"},
+		{Protocol: "tcp", From: 7001, To: 7001, Description: "etcd"},
+		{Protocol: "tcp", From: 6443, To: 6443, Description: "kubernetes API server"},
+		{Protocol: "tcp", From: 10248, To: 10255, Description: "kubernetes internal services range"},
+		{Protocol: "tcp", From: 5000, To: 5000, Description: "docker registry"},
+		{Protocol: "tcp", From: 3022, To: 3025, Description: "teleport internal SSH control panel"},
+		{Protocol: "tcp", From: 3080, To: 3080, Description: "teleport Web UI"},
+		{Protocol: "tcp", From: 3008, To: 3011, Description: "internal Gravity services"},
+		{Protocol: "tcp", From: 32009, To: 32009, Description: "Gravity Hub control panel"},
+		{Protocol: "tcp", From: 7575, To: 7575, Description: "Gravity RPC agent"},
+		{Protocol: "udp", From: vxlanPort, To: vxlanPort, Description: "overlay network"},
+	}
+}
+
+func findPortRange(ranges []PortRange, protocol string, port int) *PortRange {
+	for i := range ranges {
+		if ranges[i].Contains(protocol, port) {
+			return &ranges[i]
+		}
+	}
+	return nil
+}
+
+// isProvisioningHook returns true for the hooks that provision the infrastructure.
+// The images of these hooks are not vendored
+func isProvisioningHook(hookType HookType) bool {
+	switch hookType {
+	case HookClusterProvision, HookClusterDeprovision, HookNodesProvision, HookNodesDeprovision:
+		return true
+	}
+	return false
+}
+
+// flattenErrors returns the list of errors the specified (possibly nested) aggregate consists of
+func flattenErrors(err error) (errors []error) {
+	if err == nil {
+		return nil
+	}
+	aggregate, ok := trace.Unwrap(err).(trace.Aggregate)
+	if !ok {
+		return []error{err}
+	}
+	for _, err := range aggregate.Errors() {
+		errors = append(errors, flattenErrors(err)...)
+	}
+	return errors
+}
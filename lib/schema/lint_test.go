@@ -0,0 +1,181 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+)
+
+type LintSuite struct{}
+
+var _ = Suite(&LintSuite{})
+
+func (s *LintSuite) TestValidManifest(c *C) {
+	const manifest = `
+apiVersion: cluster.gravitational.io/v2
+kind: Cluster
+metadata:
+  name: test
+  resourceVersion: 0.0.1
+installer:
+  flavors:
+    items:
+      - name: one
+        nodes:
+          - profile: node
+            count: 1
+nodeProfiles:
+  - name: node
+    requirements:
+      network:
+        ports:
+          - protocol: tcp
+            ranges: ["8080-8081"]
+hooks:
+  install:
+    job: |
+      apiVersion: batch/v1
+      kind: Job
+      metadata:
+        name: install
+      spec:
+        template:
+          spec:
+            containers:
+              - name: install
+                image: quay.io/gravitational/debian-tall:0.0.1
+`
+	issues := LintManifestYAML([]byte(manifest), "app.yaml")
+	c.Assert(issues, HasLen, 0, Commentf("%v", issues))
+}
+
+func (s *LintSuite) TestReportsSchemaViolations(c *C) {
+	const manifest = `
+apiVersion: cluster.gravitational.io/v2
+kind: Cluster
+metadata:
+  name: test
+  resourceVersion: 0.0.1
+nodeProfiles:
+  - name: node
+    unknownField: value
+    requirements:
+      cpu:
+        min: "two"
+`
+	issues := LintManifestYAML([]byte(manifest), "app.yaml")
+	c.Assert(issues.HasErrors(), Equals, true)
+	c.Assert(issuePaths(issues), DeepEquals, []string{
+		"/nodeProfiles/0/requirements/cpu/min",
+		"/nodeProfiles/0",
+	}, Commentf("%v", issues))
+}
+
+func (s *LintSuite) TestReportsSemanticIssues(c *C) {
+	dir := c.MkDir()
+	job := `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: provision
+spec:
+  template:
+    spec:
+      containers:
+        - name: provision
+          image: example.com/provision:1.0.0
+`
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "provision.yaml"), []byte(job), 0644), IsNil)
+	const manifest = `
+apiVersion: cluster.gravitational.io/v2
+kind: Cluster
+metadata:
+  name: test
+  resourceVersion: 0.0.1
+installer:
+  flavors:
+    items:
+      - name: one
+        nodes:
+          - profile: node
+            count: 1
+          - profile: worker
+            count: 2
+nodeProfiles:
+  - name: node
+    requirements:
+      network:
+        ports:
+          - protocol: tcp
+            ranges: ["2379", "8080"]
+          - protocol: tcp
+            ranges: ["8080"]
+hooks:
+  clusterProvision:
+    job: file://provision.yaml
+  clusterDeprovision:
+    job: file://missing.yaml
+  nodesProvision:
+    job: file://provision.yaml
+  nodesDeprovision:
+    job: file://provision.yaml
+  install:
+    job: |
+      apiVersion: batch/v1
+      kind: Job
+      metadata:
+        name: install
+      spec:
+        template:
+          spec:
+            containers:
+              - name: install
+                image: debian-tall
+              - name: noimage
+`
+	issues := LintManifestYAML([]byte(manifest), filepath.Join(dir, "app.yaml"))
+	c.Assert(issues.HasErrors(), Equals, true)
+	byPath := make(map[string][]LintSeverity)
+	for _, issue := range issues {
+		byPath[issue.Path] = append(byPath[issue.Path], issue.Severity)
+	}
+	c.Assert(byPath, DeepEquals, map[string][]LintSeverity{
+		"/installer/flavors/items/0/nodes/1/profile":   {LintError},
+		"/hooks/clusterProvision/job":                  {LintWarning},
+		"/hooks/clusterDeprovision/job":                {LintError},
+		"/hooks/nodesProvision/job":                    {LintWarning},
+		"/hooks/nodesDeprovision/job":                  {LintWarning},
+		"/hooks/install/job":                           {LintWarning, LintError},
+		"/nodeProfiles/0/requirements/network/ports/0": {LintError},
+		"/nodeProfiles/0/requirements/network/ports/1": {LintWarning},
+	}, Commentf("%v", issues))
+}
+
+func (s *LintSuite) TestReportsInvalidYAML(c *C) {
+	issues := LintManifestYAML([]byte("kind: [Cluster"), "app.yaml")
+	c.Assert(issues, HasLen, 1)
+	c.Assert(issues[0].Severity, Equals, LintError)
+}
+
+func issuePaths(issues LintIssues) (paths []string) {
+	for _, issue := range issues {
+		paths = append(paths, issue.Path)
+	}
+	return paths
+}
@@ -19,7 +19,10 @@ package schema
 import (
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -35,6 +38,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	kubeschema "k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes/scheme"
 )
 
@@ -71,6 +75,15 @@ type Manifest struct {
 	SystemOptions *SystemOptions `json:"systemOptions,omitempty"`
 	// Extensions allows to enable/disable various custom features
 	Extensions *Extensions `json:"extensions,omitempty"`
+	// Upgrade customizes the cluster upgrade behavior
+	Upgrade *Upgrade `json:"upgrade,omitempty"`
+	// SmokeTests lists the application smoke tests run as the final
+	// verification step of the install and upgrade operations
+	SmokeTests []SmokeTest `json:"smokeTests,omitempty"`
+	// Charts lists the Helm charts the application is deployed with.
+	// The charts are vendored along with the images they reference
+	// and each chart is installed as a separate release
+	Charts []Chart `json:"charts,omitempty"`
 	// WebConfig allows to specify config.js used by UI to customize installer
 	WebConfig string `json:"webConfig,omitempty"`
 }
@@ -119,7 +132,7 @@ func (m Manifest) GetObjectKind() kubeschema.ObjectKind {
 // Only cluster images can have runtimes.
 func (m Manifest) Base() *loc.Locator {
 	switch m.Kind {
-	case KindBundle, KindCluster:
+	case KindBundle, KindCluster, KindPlatform:
 	default:
 		return nil
 	}
@@ -238,6 +251,8 @@ func (m Manifest) DescribeKind() string {
 	switch m.Kind {
 	case KindBundle, KindCluster:
 		return "Cluster"
+	case KindPlatform:
+		return "Platform"
 	case KindApplication:
 		return "Application"
 	case KindSystemApplication:
@@ -252,7 +267,7 @@ func (m Manifest) DescribeKind() string {
 // ImageType returns the image type this manifest represents, cluster or application.
 func (m Manifest) ImageType() string {
 	switch m.Kind {
-	case KindBundle, KindCluster:
+	case KindBundle, KindCluster, KindPlatform:
 		return KindCluster
 	case KindApplication:
 		return KindApplication
@@ -321,6 +336,38 @@ func (m Manifest) RuntimePackage(profile NodeProfile) (*loc.Locator, error) {
 	return m.DefaultRuntimePackage()
 }
 
+// RuntimePackageForServer returns the planet package for the specified profile
+// built for the specified CPU architecture
+func (m Manifest) RuntimePackageForServer(profileName, arch string) (*loc.Locator, error) {
+	if err := m.CheckArchitecture(arch); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	runtimePackage, err := m.RuntimePackageForProfile(profileName)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	forArch := runtimePackage.ForArch(arch)
+	return &forArch, nil
+}
+
+// Architectures returns the list of CPU architectures this image supports
+func (m Manifest) Architectures() []string {
+	if m.SystemOptions == nil || len(m.SystemOptions.Architectures) == 0 {
+		return []string{constants.ArchAMD64}
+	}
+	return m.SystemOptions.Architectures
+}
+
+// CheckArchitecture returns an error if the image does not support
+// the specified CPU architecture
+func (m Manifest) CheckArchitecture(arch string) error {
+	if arch == "" || utils.StringInSlice(m.Architectures(), arch) {
+		return nil
+	}
+	return trace.BadParameter("%v does not support %v architecture, supported architectures: %v",
+		m.Locator(), arch, strings.Join(m.Architectures(), ", "))
+}
+
 // DefaultRuntimePackage returns the default runtime package
 func (m Manifest) DefaultRuntimePackage() (*loc.Locator, error) {
 	if m.SystemOptions == nil || m.SystemOptions.Dependencies.Runtime == nil {
@@ -659,6 +706,28 @@ type Requirements struct {
 	Devices []Device `json:"devices,omitempty"`
 	// CustomChecks lists additional preflight checks as inline scripts
 	CustomChecks []CustomCheck `json:"customChecks,omitempty"`
+	// KernelModules lists kernel modules that must be available on the node,
+	// e.g. the GPU driver modules
+	KernelModules []string `json:"kernelModules,omitempty"`
+	// Disks describes the unused block devices the node must have,
+	// e.g. for storage nodes
+	Disks *Disks `json:"disks,omitempty"`
+}
+
+// Disks describes the unused block devices required on a node
+type Disks struct {
+	// Count is the minimum number of unused block devices
+	Count int `json:"count"`
+	// MinCapacity is the minimum capacity of each block device
+	MinCapacity utils.Capacity `json:"minCapacity,omitempty"`
+}
+
+// Check makes sure the disk requirements are correct
+func (r Disks) Check() error {
+	if r.Count <= 0 {
+		return trace.BadParameter("number of required disks should be positive, got %v", r.Count)
+	}
+	return nil
 }
 
 // Device describes a device that should be created inside container
@@ -775,6 +844,84 @@ type Network struct {
 	MinTransferRate utils.TransferRate `json:"minTransferRate,omitempty"`
 	// Ports specifies port ranges that should be available on the server
 	Ports []Port `json:"ports,omitempty"`
+	// Interfaces optionally assigns dedicated network interfaces to
+	// different kinds of traffic
+	Interfaces *NetworkInterfaces `json:"interfaces,omitempty"`
+}
+
+// NetworkInterfaces assigns network interfaces to different kinds of traffic.
+//
+// Traffic without a dedicated interface uses the advertise address of the node
+type NetworkInterfaces struct {
+	// Cluster is the interface for the east-west cluster traffic:
+	// etcd, kubelet, apiserver and overlay network
+	Cluster *NetworkInterface `json:"cluster,omitempty"`
+	// Application is the interface for the application traffic
+	// served on node ports and by ingress controllers
+	Application *NetworkInterface `json:"application,omitempty"`
+}
+
+// Check makes sure the network interfaces are correct
+func (r NetworkInterfaces) Check() error {
+	if r.Cluster != nil {
+		if err := r.Cluster.Check(); err != nil {
+			return trace.Wrap(err, "invalid cluster interface")
+		}
+	}
+	if r.Application != nil {
+		if err := r.Application.Check(); err != nil {
+			return trace.Wrap(err, "invalid application interface")
+		}
+	}
+	return nil
+}
+
+// NetworkInterface selects a network interface on a node either by name
+// or by the subnet of its address
+type NetworkInterface struct {
+	// Name is the name of the interface, e.g. eth1
+	Name string `json:"name,omitempty"`
+	// Subnet is the subnet the address of the interface belongs to, e.g. 10.100.0.0/16
+	Subnet string `json:"subnet,omitempty"`
+}
+
+// Check makes sure the interface selector is correct
+func (r NetworkInterface) Check() error {
+	if r.Name == "" && r.Subnet == "" {
+		return trace.BadParameter("specify either interface name or subnet")
+	}
+	if r.Subnet != "" {
+		if _, _, err := net.ParseCIDR(r.Subnet); err != nil {
+			return trace.BadParameter("invalid subnet %q: %v", r.Subnet, err)
+		}
+	}
+	return nil
+}
+
+// Matches returns true if the interface with the specified name and
+// IPv4 address satisfies this selector
+func (r NetworkInterface) Matches(name, addr string) bool {
+	if r.Name != "" && r.Name != name {
+		return false
+	}
+	if r.Subnet == "" {
+		return true
+	}
+	ip := net.ParseIP(addr)
+	_, subnet, err := net.ParseCIDR(r.Subnet)
+	return ip != nil && err == nil && subnet.Contains(ip)
+}
+
+// String returns a textual representation of the interface selector
+func (r NetworkInterface) String() string {
+	switch {
+	case r.Name != "" && r.Subnet != "":
+		return fmt.Sprintf("%v in subnet %v", r.Name, r.Subnet)
+	case r.Name != "":
+		return r.Name
+	default:
+		return fmt.Sprintf("interface in subnet %v", r.Subnet)
+	}
 }
 
 // Port describes port ranges
@@ -994,6 +1141,9 @@ type SystemOptions struct {
 	// AllowPrivileged controls whether privileged containers will be allowed
 	// in the cluster.
 	AllowPrivileged bool `json:"allowPrivileged,omitempty"`
+	// Architectures lists the CPU architectures the cluster image supports.
+	// Defaults to amd64 if unspecified
+	Architectures []string `json:"architectures,omitempty"`
 }
 
 // Runtime describes the application runtime
@@ -1072,6 +1222,250 @@ type ExternalService struct {
 	Args []string `json:"args,omitempty"`
 }
 
+// Upgrade describes the cluster upgrade settings
+type Upgrade struct {
+	// Strategy specifies how the cluster nodes are upgraded
+	Strategy string `json:"strategy,omitempty"`
+	// Canary configures the canary upgrade strategy
+	Canary *Canary `json:"canary,omitempty"`
+	// BatchSize optionally specifies the number of regular nodes to upgrade
+	// in parallel. Master nodes are always upgraded one at a time
+	BatchSize int `json:"batchSize,omitempty"`
+	// MaxUnavailable optionally specifies the number of regular nodes to upgrade
+	// in parallel as a percentage of all regular nodes, e.g. "25%".
+	// Ignored if BatchSize is set
+	MaxUnavailable string `json:"maxUnavailable,omitempty"`
+}
+
+// GetStrategy returns the upgrade strategy, rolling by default
+func (r *Upgrade) GetStrategy() string {
+	if r == nil || r.Strategy == "" {
+		return UpgradeStrategyRolling
+	}
+	return r.Strategy
+}
+
+// GetMaxUnavailablePercent returns the percentage of regular nodes
+// upgraded in parallel or 0 if unspecified
+func (r *Upgrade) GetMaxUnavailablePercent() (int, error) {
+	if r == nil || r.MaxUnavailable == "" {
+		return 0, nil
+	}
+	return ParseMaxUnavailable(r.MaxUnavailable)
+}
+
+// ParseMaxUnavailable parses the percentage of nodes to upgrade
+// in parallel given in the "<number>%" format
+func ParseMaxUnavailable(value string) (int, error) {
+	if !strings.HasSuffix(value, "%") {
+		return 0, trace.BadParameter("max unavailable should be a percentage, e.g. 25%%, got %q", value)
+	}
+	percent, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+	if err != nil {
+		return 0, trace.BadParameter("invalid max unavailable percentage %q: %v", value, err)
+	}
+	if percent < 1 || percent > 100 {
+		return 0, trace.BadParameter("max unavailable percentage should be between 1%% and 100%%, got %q", value)
+	}
+	return percent, nil
+}
+
+// Canary describes the canary upgrade strategy.
+// With this strategy, a single canary node is upgraded first and the
+// remaining nodes are only upgraded after the application health checks
+// pass and the upgrade is either explicitly approved or the soak period elapses
+type Canary struct {
+	// Node optionally specifies the hostname or the advertise address of the canary node.
+	// If unspecified, the first regular node is used or the lead master node
+	// if the cluster has no regular nodes
+	Node string `json:"node,omitempty"`
+	// SoakPeriod optionally specifies the duration to observe the canary node
+	// before proceeding automatically, e.g. "30m".
+	// If unspecified, the upgrade waits for an explicit approval
+	SoakPeriod string `json:"soakPeriod,omitempty"`
+}
+
+// GetSoakPeriod returns the canary soak period
+func (r Canary) GetSoakPeriod() (time.Duration, error) {
+	if r.SoakPeriod == "" {
+		return 0, nil
+	}
+	period, err := time.ParseDuration(r.SoakPeriod)
+	if err != nil {
+		return 0, trace.BadParameter("invalid canary soak period %q: %v", r.SoakPeriod, err)
+	}
+	if period < 0 {
+		return 0, trace.BadParameter("canary soak period cannot be negative: %v", r.SoakPeriod)
+	}
+	return period, nil
+}
+
+// SmokeTest describes an application smoke test run as the final
+// verification step of an install or upgrade operation: the operation
+// fails if the test does not pass.
+// The test is either a Kubernetes job or an HTTP check
+type SmokeTest struct {
+	// Name is the name of the test
+	Name string `json:"name"`
+	// Operations optionally lists the operations the test runs after:
+	// install and/or upgrade. If unspecified, the test runs after both
+	Operations []string `json:"operations,omitempty"`
+	// Timeout optionally specifies how long the test is allowed to run, e.g. "10m"
+	Timeout string `json:"timeout,omitempty"`
+	// Job is a URL of (file:// or http://) or a literal value of a k8s job
+	// that should complete successfully
+	Job string `json:"job,omitempty"`
+	// HTTP is the endpoint that should respond with the expected status.
+	// The check is retried until it passes or the test times out
+	HTTP *SmokeTestHTTP `json:"http,omitempty"`
+}
+
+// Chart describes a Helm chart the application is deployed with
+type Chart struct {
+	// Name is the name of the release the chart is installed as
+	Name string `json:"name"`
+	// Path is the path to the chart directory relative to the application resources
+	Path string `json:"path"`
+	// Namespace is the namespace to install the release into
+	Namespace string `json:"namespace,omitempty"`
+	// Values lists the values files the chart is installed with,
+	// relative to the application resources
+	Values []string `json:"values,omitempty"`
+	// Flavors maps flavor names to the additional values files
+	// the chart is installed with for the flavor
+	Flavors map[string][]string `json:"flavors,omitempty"`
+}
+
+// ValuesFor returns the values files to install the chart with
+// for the specified flavor
+func (c Chart) ValuesFor(flavor string) []string {
+	values := make([]string, 0, len(c.Values)+len(c.Flavors[flavor]))
+	values = append(values, c.Values...)
+	return append(values, c.Flavors[flavor]...)
+}
+
+// Check makes sure the chart refers to files within the application resources
+func (c Chart) Check() error {
+	if c.Name == "" {
+		return trace.BadParameter("chart name is required")
+	}
+	paths := append([]string{c.Path}, c.Values...)
+	for _, values := range c.Flavors {
+		paths = append(paths, values...)
+	}
+	for _, path := range paths {
+		if path == "" || filepath.IsAbs(path) || strings.HasPrefix(filepath.Clean(path), "..") {
+			return trace.BadParameter("chart %q: path %q should be relative to the application resources",
+				c.Name, path)
+		}
+	}
+	return nil
+}
+
+// SmokeTestHTTP describes an HTTP smoke test
+type SmokeTestHTTP struct {
+	// URL is the URL of the endpoint. It is requested from the master
+	// node that executes the operation
+	URL string `json:"url"`
+	// ExpectedStatus optionally specifies the expected response status code.
+	// Any 2xx status code is accepted if unspecified
+	ExpectedStatus int `json:"expectedStatus,omitempty"`
+	// ExpectedBody optionally specifies the text the response body should contain
+	ExpectedBody string `json:"expectedBody,omitempty"`
+	// InsecureSkipVerify disables the verification of the endpoint certificate
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// GetTimeout returns the smoke test timeout
+func (r SmokeTest) GetTimeout() (time.Duration, error) {
+	if r.Timeout == "" {
+		return defaults.SmokeTestTimeout, nil
+	}
+	timeout, err := time.ParseDuration(r.Timeout)
+	if err != nil {
+		return 0, trace.BadParameter("invalid smoke test %v timeout %q: %v", r.Name, r.Timeout, err)
+	}
+	if timeout <= 0 {
+		return 0, trace.BadParameter("smoke test %v timeout should be positive: %v", r.Name, r.Timeout)
+	}
+	return timeout, nil
+}
+
+// RunsAfter returns true if the test runs after the specified operation: install or upgrade
+func (r SmokeTest) RunsAfter(operation string) bool {
+	return len(r.Operations) == 0 || utils.StringInSlice(r.Operations, operation)
+}
+
+// GetHook returns the hook that runs the smoke test job
+func (r SmokeTest) GetHook() *Hook {
+	return &Hook{Type: HookSmokeTest, Job: r.Job}
+}
+
+// Check makes sure the smoke test is valid
+func (r SmokeTest) Check() error {
+	if r.Name == "" {
+		return trace.BadParameter("smoke test name is required")
+	}
+	if errs := validation.IsDNS1123Label(r.Name); len(errs) != 0 {
+		return trace.BadParameter("invalid smoke test name %q: %v", r.Name, strings.Join(errs, "; "))
+	}
+	if (r.Job == "") == (r.HTTP == nil) {
+		return trace.BadParameter("smoke test %v should specify either job or http", r.Name)
+	}
+	for _, operation := range r.Operations {
+		if !utils.StringInSlice(SmokeTestOperations, operation) {
+			return trace.BadParameter("smoke test %v: unsupported operation %q, supported are: %v",
+				r.Name, operation, SmokeTestOperations)
+		}
+	}
+	if _, err := r.GetTimeout(); err != nil {
+		return trace.Wrap(err)
+	}
+	if r.HTTP != nil {
+		if err := r.HTTP.Check(); err != nil {
+			return trace.Wrap(err, "smoke test %v", r.Name)
+		}
+	}
+	return nil
+}
+
+// Check makes sure the HTTP smoke test is valid
+func (r SmokeTestHTTP) Check() error {
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return trace.BadParameter("invalid url %q: %v", r.URL, err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return trace.BadParameter("url %q must be an absolute http(s) URL", r.URL)
+	}
+	if r.ExpectedStatus != 0 && (r.ExpectedStatus < 100 || r.ExpectedStatus > 599) {
+		return trace.BadParameter("invalid expected status %v", r.ExpectedStatus)
+	}
+	return nil
+}
+
+// SmokeTestsFor returns the smoke tests that run after the specified
+// operation: install or upgrade
+func (m Manifest) SmokeTestsFor(operation string) (tests []SmokeTest) {
+	for _, test := range m.SmokeTests {
+		if test.RunsAfter(operation) {
+			tests = append(tests, test)
+		}
+	}
+	return tests
+}
+
+// GetSmokeTest returns the smoke test with the specified name
+func (m Manifest) GetSmokeTest(name string) (*SmokeTest, error) {
+	for i, test := range m.SmokeTests {
+		if test.Name == name {
+			return &m.SmokeTests[i], nil
+		}
+	}
+	return nil, trace.NotFound("%v:%v does not have smoke test %q",
+		m.Metadata.Name, m.Metadata.ResourceVersion, name)
+}
+
 // Extensions defines various custom application features
 type Extensions struct {
 	// Encryption allows to encrypt installer packages
@@ -1138,6 +1532,7 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypeWithName(SchemeGroupVersion.WithKind(KindCluster), &Manifest{})
 	scheme.AddKnownTypeWithName(SchemeGroupVersion.WithKind(KindApplication), &Manifest{})
 	scheme.AddKnownTypeWithName(ClusterGroupVersion.WithKind(KindCluster), &Manifest{})
+	scheme.AddKnownTypeWithName(ClusterGroupVersion.WithKind(KindPlatform), &Manifest{})
 	scheme.AddKnownTypeWithName(AppGroupVersion.WithKind(KindApplication), &Manifest{})
 	return nil
 }
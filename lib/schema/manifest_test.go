@@ -18,6 +18,7 @@ package schema
 
 import (
 	"testing"
+	"time"
 
 	"github.com/gravitational/gravity/lib/compare"
 	"github.com/gravitational/gravity/lib/constants"
@@ -550,6 +551,365 @@ systemOptions:
 	c.Assert(err, NotNil)
 }
 
+func (s *ManifestSuite) TestParsesKernelModulesAndDisks(c *C) {
+	bytes := []byte(`apiVersion: bundle.gravitational.io/v2
+kind: Bundle
+metadata:
+  name: myapp
+  resourceVersion: 0.0.1
+installer:
+  flavors:
+    items:
+      - name: one
+        nodes:
+          - profile: storage
+            count: 1
+nodeProfiles:
+  - name: storage
+    requirements:
+      kernelModules: [rbd, dm_thin_pool]
+      disks:
+        count: 2
+        minCapacity: 100GB`)
+	manifest, err := ParseManifestYAML(bytes)
+	c.Assert(err, IsNil)
+	requirements := manifest.NodeProfiles[0].Requirements
+	c.Assert(requirements.KernelModules, DeepEquals, []string{"rbd", "dm_thin_pool"})
+	c.Assert(requirements.Disks, DeepEquals, &Disks{
+		Count:       2,
+		MinCapacity: utils.MustParseCapacity("100GB"),
+	})
+}
+
+func (s *ManifestSuite) TestInvalidDisksRequirement(c *C) {
+	bytes := []byte(`apiVersion: bundle.gravitational.io/v2
+kind: Bundle
+metadata:
+  name: myapp
+  resourceVersion: 0.0.1
+installer:
+  flavors:
+    items:
+      - name: one
+        nodes:
+          - profile: storage
+            count: 1
+nodeProfiles:
+  - name: storage
+    requirements:
+      disks:
+        count: 0`)
+	_, err := ParseManifestYAML(bytes)
+	c.Assert(err, NotNil)
+}
+
+func (s *ManifestSuite) TestParsesNetworkInterfaces(c *C) {
+	bytes := []byte(`apiVersion: bundle.gravitational.io/v2
+kind: Bundle
+metadata:
+  name: myapp
+  resourceVersion: 0.0.1
+installer:
+  flavors:
+    items:
+      - name: one
+        nodes:
+          - profile: storage
+            count: 1
+nodeProfiles:
+  - name: storage
+    requirements:
+      network:
+        interfaces:
+          cluster:
+            subnet: 10.100.0.0/16
+          application:
+            name: eth2`)
+	manifest, err := ParseManifestYAML(bytes)
+	c.Assert(err, IsNil)
+	interfaces := manifest.NodeProfiles[0].Requirements.Network.Interfaces
+	c.Assert(interfaces, DeepEquals, &NetworkInterfaces{
+		Cluster:     &NetworkInterface{Subnet: "10.100.0.0/16"},
+		Application: &NetworkInterface{Name: "eth2"},
+	})
+	c.Assert(interfaces.Cluster.Matches("eth1", "10.100.0.5"), Equals, true)
+	c.Assert(interfaces.Cluster.Matches("eth0", "192.168.0.5"), Equals, false)
+	c.Assert(interfaces.Application.Matches("eth2", "172.16.0.5"), Equals, true)
+	c.Assert(interfaces.Application.Matches("eth1", "172.16.0.5"), Equals, false)
+
+	bytes = []byte(`apiVersion: bundle.gravitational.io/v2
+kind: Bundle
+metadata:
+  name: myapp
+  resourceVersion: 0.0.1
+nodeProfiles:
+  - name: storage
+    requirements:
+      network:
+        interfaces:
+          cluster:
+            subnet: 10.100.0.0`)
+	_, err = ParseManifestYAML(bytes)
+	c.Assert(err, NotNil)
+}
+
+func (s *ManifestSuite) TestParsesUpgradeStrategy(c *C) {
+	bytes := []byte(`apiVersion: bundle.gravitational.io/v2
+kind: Bundle
+metadata:
+  name: myapp
+  resourceVersion: 0.0.1
+upgrade:
+  strategy: canary
+  canary:
+    node: node-1
+    soakPeriod: 30m`)
+	manifest, err := ParseManifestYAML(bytes)
+	c.Assert(err, IsNil)
+	c.Assert(manifest.Upgrade.GetStrategy(), Equals, UpgradeStrategyCanary)
+	c.Assert(manifest.Upgrade.Canary.Node, Equals, "node-1")
+	soakPeriod, err := manifest.Upgrade.Canary.GetSoakPeriod()
+	c.Assert(err, IsNil)
+	c.Assert(soakPeriod, Equals, 30*time.Minute)
+
+	var noUpgrade *Upgrade
+	c.Assert(noUpgrade.GetStrategy(), Equals, UpgradeStrategyRolling)
+}
+
+func (s *ManifestSuite) TestInvalidUpgradeStrategy(c *C) {
+	bytes := []byte(`apiVersion: bundle.gravitational.io/v2
+kind: Bundle
+metadata:
+  name: myapp
+  resourceVersion: 0.0.1
+upgrade:
+  strategy: canary
+  canary:
+    soakPeriod: forever`)
+	_, err := ParseManifestYAML(bytes)
+	c.Assert(err, NotNil)
+}
+
+func (s *ManifestSuite) TestParsesSmokeTests(c *C) {
+	bytes := []byte(`apiVersion: bundle.gravitational.io/v2
+kind: Bundle
+metadata:
+  name: myapp
+  resourceVersion: 0.0.1
+smokeTests:
+  - name: frontend
+    operations: [upgrade]
+    timeout: 2m
+    http:
+      url: https://frontend.default.svc.cluster.local/healthz
+      expectedStatus: 200
+      expectedBody: ok
+      insecureSkipVerify: true
+  - name: e2e
+    job: |
+      apiVersion: batch/v1
+      kind: Job
+      metadata:
+        name: e2e
+      spec:
+        template:
+          metadata:
+            name: e2e
+          spec:
+            restartPolicy: OnFailure
+            containers:
+              - name: e2e
+                image: e2e:1.0.0`)
+	manifest, err := ParseManifestYAML(bytes)
+	c.Assert(err, IsNil)
+	c.Assert(manifest.SmokeTests, HasLen, 2)
+	c.Assert(*manifest.SmokeTests[0].HTTP, DeepEquals, SmokeTestHTTP{
+		URL:                "https://frontend.default.svc.cluster.local/healthz",
+		ExpectedStatus:     200,
+		ExpectedBody:       "ok",
+		InsecureSkipVerify: true,
+	})
+	timeout, err := manifest.SmokeTests[0].GetTimeout()
+	c.Assert(err, IsNil)
+	c.Assert(timeout, Equals, 2*time.Minute)
+	timeout, err = manifest.SmokeTests[1].GetTimeout()
+	c.Assert(err, IsNil)
+	c.Assert(timeout, Equals, defaults.SmokeTestTimeout)
+
+	c.Assert(manifest.SmokeTestsFor(SmokeTestInstall), DeepEquals, manifest.SmokeTests[1:])
+	c.Assert(manifest.SmokeTestsFor(SmokeTestUpgrade), DeepEquals, manifest.SmokeTests)
+
+	test, err := manifest.GetSmokeTest("e2e")
+	c.Assert(err, IsNil)
+	job, err := test.GetHook().GetJob()
+	c.Assert(err, IsNil)
+	c.Assert(job.Spec.Template.Spec.Containers[0].Image, Equals, "e2e:1.0.0")
+	_, err = manifest.GetSmokeTest("missing")
+	c.Assert(trace.IsNotFound(err), Equals, true)
+}
+
+func (s *ManifestSuite) TestInvalidSmokeTests(c *C) {
+	tests := []string{
+		// neither job nor http
+		`- name: test`,
+		// both job and http
+		`- name: test
+    job: job.yaml
+    http:
+      url: http://example.com`,
+		// invalid name
+		`- name: Test_1
+    http:
+      url: http://example.com`,
+		// unsupported operation
+		`- name: test
+    operations: [expand]
+    http:
+      url: http://example.com`,
+		// invalid timeout
+		`- name: test
+    timeout: forever
+    http:
+      url: http://example.com`,
+		// relative url
+		`- name: test
+    http:
+      url: /healthz`,
+		// duplicate names
+		`- name: test
+    http:
+      url: http://example.com
+  - name: test
+    http:
+      url: http://example.com`,
+	}
+	for _, test := range tests {
+		bytes := []byte(`apiVersion: bundle.gravitational.io/v2
+kind: Bundle
+metadata:
+  name: myapp
+  resourceVersion: 0.0.1
+smokeTests:
+  ` + test)
+		_, err := ParseManifestYAML(bytes)
+		c.Assert(err, NotNil, Commentf(test))
+	}
+}
+
+func (s *ManifestSuite) TestParsesCharts(c *C) {
+	bytes := []byte(`apiVersion: bundle.gravitational.io/v2
+kind: Bundle
+metadata:
+  name: myapp
+  resourceVersion: 0.0.1
+charts:
+  - name: frontend
+    path: charts/frontend
+    namespace: web
+    values: [charts/values.yaml]
+    flavors:
+      large: [charts/values-large.yaml]
+  - name: backend
+    path: charts/backend`)
+	manifest, err := ParseManifestYAML(bytes)
+	c.Assert(err, IsNil)
+	c.Assert(manifest.Charts, HasLen, 2)
+	c.Assert(manifest.Charts[0].ValuesFor("large"), DeepEquals,
+		[]string{"charts/values.yaml", "charts/values-large.yaml"})
+	c.Assert(manifest.Charts[0].ValuesFor("small"), DeepEquals, []string{"charts/values.yaml"})
+	c.Assert(manifest.Charts[1].ValuesFor("large"), HasLen, 0)
+	c.Assert(manifest.DeepCopy().Charts, DeepEquals, manifest.Charts)
+}
+
+func (s *ManifestSuite) TestInvalidCharts(c *C) {
+	tests := []string{
+		// missing path
+		`- name: app`,
+		// absolute path
+		`- name: app
+    path: /charts/app`,
+		// path outside of resources
+		`- name: app
+    path: ../charts/app`,
+		// values outside of resources
+		`- name: app
+    path: charts/app
+    flavors:
+      large: [../values.yaml]`,
+		// duplicate names
+		`- name: app
+    path: charts/app
+  - name: app
+    path: charts/other`,
+	}
+	for _, test := range tests {
+		bytes := []byte(`apiVersion: bundle.gravitational.io/v2
+kind: Bundle
+metadata:
+  name: myapp
+  resourceVersion: 0.0.1
+charts:
+  ` + test)
+		_, err := ParseManifestYAML(bytes)
+		c.Assert(err, NotNil, Commentf(test))
+	}
+}
+
+func (s *ManifestSuite) TestParsesPlatform(c *C) {
+	bytes := []byte(`apiVersion: cluster.gravitational.io/v2
+kind: Platform
+metadata:
+  name: platform
+  resourceVersion: 1.0.0
+hooks:
+  postInstall:
+    job: |
+      apiVersion: batch/v1
+      kind: Job
+      metadata:
+        name: install-apps`)
+	manifest, err := ParseManifestYAML(bytes)
+	c.Assert(err, IsNil)
+	c.Assert(manifest.ImageType(), Equals, KindCluster)
+	c.Assert(manifest.DescribeKind(), Equals, "Platform")
+	c.Assert(manifest.Base(), NotNil)
+}
+
+func (s *ManifestSuite) TestPlatformHasNoApplication(c *C) {
+	tests := []string{
+		`charts:
+  - name: app
+    path: charts/app`,
+		`hooks:
+  install:
+    job: file://install.yaml`,
+		`hooks:
+  update:
+    job: file://update.yaml`,
+	}
+	for _, test := range tests {
+		bytes := []byte(`apiVersion: cluster.gravitational.io/v2
+kind: Platform
+metadata:
+  name: platform
+  resourceVersion: 1.0.0
+` + test)
+		_, err := ParseManifestYAML(bytes)
+		c.Assert(err, ErrorMatches, "(?s).*Platform image can not.*", Commentf(test))
+	}
+}
+
+func (s *ManifestSuite) TestParsesMaxUnavailable(c *C) {
+	percent, err := ParseMaxUnavailable("25%")
+	c.Assert(err, IsNil)
+	c.Assert(percent, Equals, 25)
+
+	for _, value := range []string{"25", "0%", "101%", "a%"} {
+		_, err := ParseMaxUnavailable(value)
+		c.Assert(trace.IsBadParameter(err), Equals, true, Commentf(value))
+	}
+}
+
 func (s *ManifestSuite) TestCanOverrideBooleans(c *C) {
 	bytes := []byte(`apiVersion: bundle.gravitational.io/v2
 kind: Bundle
@@ -94,11 +94,15 @@ func CheckAndSetDefaults(manifest *Manifest) error {
 	// the rest of the checks apply only to user apps
 	// TODO Do specific checks for Cluster VS Application
 	switch manifest.Kind {
-	case KindBundle, KindCluster, KindApplication:
+	case KindBundle, KindCluster, KindPlatform, KindApplication:
 	default:
 		return trace.NewAggregate(errors...)
 	}
 
+	if manifest.Kind == KindPlatform {
+		errors = append(errors, checkPlatform(*manifest)...)
+	}
+
 	// make sure that if node profiles are defined, there's at least one flavor
 	if len(manifest.NodeProfiles) > 0 && len(manifest.FlavorNames()) == 0 {
 		errors = append(errors, trace.BadParameter(
@@ -160,6 +164,39 @@ func CheckAndSetDefaults(manifest *Manifest) error {
 				errors = append(errors, err)
 			}
 		}
+		if disks := nodeProfile.Requirements.Disks; disks != nil {
+			if err := disks.Check(); err != nil {
+				errors = append(errors, trace.Wrap(err, "invalid disk requirements for profile %q",
+					nodeProfile.Name))
+			}
+		}
+		if interfaces := nodeProfile.Requirements.Network.Interfaces; interfaces != nil {
+			if err := interfaces.Check(); err != nil {
+				errors = append(errors, trace.Wrap(err, "invalid network interfaces for profile %q",
+					nodeProfile.Name))
+			}
+		}
+	}
+
+	if manifest.Upgrade != nil {
+		err = checkUpgrade(*manifest.Upgrade)
+		if err != nil {
+			errors = append(errors, trace.Wrap(err))
+		}
+	}
+
+	if len(manifest.SmokeTests) != 0 {
+		err = checkSmokeTests(manifest.SmokeTests)
+		if err != nil {
+			errors = append(errors, trace.Wrap(err))
+		}
+	}
+
+	if len(manifest.Charts) != 0 {
+		err = checkCharts(manifest.Charts, manifest.FlavorNames())
+		if err != nil {
+			errors = append(errors, trace.Wrap(err))
+		}
 	}
 
 	if manifest.SystemOptions != nil {
@@ -320,6 +357,93 @@ func checkDocker(docker Docker) error {
 	return nil
 }
 
+// checkUpgrade makes sure that the provided upgrade configuration is correct
+func checkUpgrade(upgrade Upgrade) error {
+	if !utils.StringInSlice(UpgradeStrategies, upgrade.GetStrategy()) {
+		return trace.BadParameter("unrecognized upgrade strategy %q, supported are: %v",
+			upgrade.Strategy, UpgradeStrategies)
+	}
+	if upgrade.Canary != nil {
+		if _, err := upgrade.Canary.GetSoakPeriod(); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	if upgrade.BatchSize < 0 {
+		return trace.BadParameter("upgrade batch size cannot be negative: %v", upgrade.BatchSize)
+	}
+	if _, err := upgrade.GetMaxUnavailablePercent(); err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// checkSmokeTests makes sure that the provided smoke tests are correct
+// and have unique names
+func checkSmokeTests(tests []SmokeTest) error {
+	names := make(map[string]struct{}, len(tests))
+	for _, test := range tests {
+		if err := test.Check(); err != nil {
+			return trace.Wrap(err)
+		}
+		if _, exists := names[test.Name]; exists {
+			return trace.BadParameter("duplicate smoke test %q", test.Name)
+		}
+		names[test.Name] = struct{}{}
+	}
+	return nil
+}
+
+func checkCharts(charts []Chart, flavors []string) error {
+	names := make(map[string]struct{}, len(charts))
+	for _, chart := range charts {
+		if err := chart.Check(); err != nil {
+			return trace.Wrap(err)
+		}
+		if _, exists := names[chart.Name]; exists {
+			return trace.BadParameter("duplicate chart %q", chart.Name)
+		}
+		names[chart.Name] = struct{}{}
+		if len(flavors) == 0 {
+			continue
+		}
+		for flavor := range chart.Flavors {
+			if !utils.StringInSlice(flavors, flavor) {
+				return trace.BadParameter("chart %q refers to unknown flavor %q", chart.Name, flavor)
+			}
+		}
+	}
+	return nil
+}
+
+// checkPlatform makes sure that the runtime-only cluster image does not
+// deploy an application. The applications are installed on the cluster
+// later from the catalog
+func checkPlatform(manifest Manifest) (errors []error) {
+	if len(manifest.Charts) != 0 {
+		errors = append(errors, trace.BadParameter("%v image can not include charts, "+
+			"install applications from the catalog instead", KindPlatform))
+	}
+	if manifest.Hooks == nil {
+		return errors
+	}
+	hooks := []struct {
+		name string
+		hook *Hook
+	}{
+		{"install", manifest.Hooks.Install},
+		{"update", manifest.Hooks.Updating},
+		{"rollback", manifest.Hooks.Rollback},
+	}
+	for _, h := range hooks {
+		if h.hook != nil {
+			errors = append(errors, trace.BadParameter("%v image can not define the %v hook "+
+				"as it does not have an application, use the postInstall hook "+
+				"to install applications from the catalog instead", KindPlatform, h.name))
+		}
+	}
+	return errors
+}
+
 // UnmarshalJSON implements encoding/json#Unmarshaler
 func (m *Manifest) UnmarshalJSON(data []byte) error {
 	var header Header
@@ -219,6 +219,28 @@ const manifestSchema = `
                             }
                           }
                         }
+                      },
+                      "interfaces": {
+                        "type": "object",
+                        "additionalProperties": false,
+                        "properties": {
+                          "cluster": {
+                            "type": "object",
+                            "additionalProperties": false,
+                            "properties": {
+                              "name": {"type": "string"},
+                              "subnet": {"type": "string"}
+                            }
+                          },
+                          "application": {
+                            "type": "object",
+                            "additionalProperties": false,
+                            "properties": {
+                              "name": {"type": "string"},
+                              "subnet": {"type": "string"}
+                            }
+                          }
+                        }
                       }
                     }
                   },
@@ -272,6 +294,19 @@ const manifestSchema = `
                         "script": {"type": "string"}
                       }
                     }
+                  },
+                  "kernelModules": {
+                    "type": "array",
+                    "items": {"type": "string"}
+                  },
+                  "disks": {
+                    "type": "object",
+                    "required": ["count"],
+                    "additionalProperties": false,
+                    "properties": {
+                      "count": {"type": "number"},
+                      "minCapacity": {"type": "string"}
+                    }
                   }
                 }
               },
@@ -579,6 +614,66 @@ const manifestSchema = `
             "configuration": {"$ref": "#/definitions/onOff"}
           }
         },
+        "upgrade": {
+          "type": "object",
+          "additionalProperties": false,
+          "properties": {
+            "strategy": {"type": "string", "enum": ["rolling", "canary"]},
+            "canary": {
+              "type": "object",
+              "additionalProperties": false,
+              "properties": {
+                "node": {"type": "string"},
+                "soakPeriod": {"type": "string"}
+              }
+            },
+            "batchSize": {"type": "integer"},
+            "maxUnavailable": {"type": "string"}
+          }
+        },
+        "smokeTests": {
+          "type": "array",
+          "items": {
+            "type": "object",
+            "additionalProperties": false,
+            "required": ["name"],
+            "properties": {
+              "name": {"type": "string"},
+              "operations": {
+                "type": "array",
+                "items": {"type": "string", "enum": ["install", "upgrade"]}
+              },
+              "timeout": {"type": "string"},
+              "job": {"type": "string"},
+              "http": {
+                "type": "object",
+                "additionalProperties": false,
+                "required": ["url"],
+                "properties": {
+                  "url": {"type": "string"},
+                  "expectedStatus": {"type": "integer"},
+                  "expectedBody": {"type": "string"},
+                  "insecureSkipVerify": {"type": "boolean"}
+                }
+              }
+            }
+          }
+        },
+        "charts": {
+          "type": "array",
+          "items": {
+            "type": "object",
+            "additionalProperties": false,
+            "required": ["name", "path"],
+            "properties": {
+              "name": {"type": "string"},
+              "path": {"type": "string"},
+              "namespace": {"type": "string"},
+              "values": {"type": "array", "items": {"type": "string"}},
+              "flavors": {"type": "object"}
+            }
+          }
+        },
         "webConfig": {"type": "string"}
       }
     },
@@ -646,6 +741,10 @@ const manifestSchema = `
       "properties": {
         "baseImage": {"type": "string"},
         "allowPrivileged": {"type": "boolean"},
+        "architectures": {
+          "type": "array",
+          "items": {"type": "string", "enum": ["amd64", "arm64"]}
+        },
         "args": {
           "type": "array",
           "items": {"type": "string"}
@@ -41,6 +41,7 @@ import (
 //   .installer.eula.source
 //   .installer.flavors.description
 //   .hooks.*.job
+//   .smokeTests.*.job
 //   .webConfig
 func ProcessMultiSourceValues(manifest *Manifest, manifestPath string) error {
 	err := processText(&manifest.ReleaseNotes, manifestPath)
@@ -88,6 +89,13 @@ func ProcessMultiSourceValues(manifest *Manifest, manifestPath string) error {
 		}
 	}
 
+	for i := range manifest.SmokeTests {
+		err = processText(&manifest.SmokeTests[i].Job, manifestPath)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
 	return nil
 }
 
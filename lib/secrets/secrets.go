@@ -0,0 +1,76 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package secrets implements the external storage for secret values
+// such as private keys and tokens
+package secrets
+
+import (
+	"context"
+
+	"github.com/gravitational/trace"
+)
+
+// Provider stores secret values
+type Provider interface {
+	// GetSecret returns the secret value stored at the specified path.
+	// Returns trace.NotFound if there is no such secret
+	GetSecret(ctx context.Context, path string) ([]byte, error)
+	// PutSecret stores the secret value at the specified path
+	PutSecret(ctx context.Context, path string, value []byte) error
+	// DeleteSecret removes the secret stored at the specified path
+	DeleteSecret(ctx context.Context, path string) error
+}
+
+// TypeVault is the secrets provider backed by HashiCorp Vault
+const TypeVault = "vault"
+
+// Config configures the secrets provider
+type Config struct {
+	// Type is the type of the secrets provider
+	Type string `json:"type" yaml:"type"`
+	// Vault configures the HashiCorp Vault provider
+	Vault VaultConfig `json:"vault" yaml:"vault"`
+}
+
+// CheckAndSetDefaults validates this configuration and sets defaults
+func (c *Config) CheckAndSetDefaults() error {
+	switch c.Type {
+	case TypeVault:
+		return trace.Wrap(c.Vault.CheckAndSetDefaults())
+	case "":
+		return trace.BadParameter("missing secrets provider type")
+	default:
+		return trace.BadParameter("unsupported secrets provider %q, supported are: %q",
+			c.Type, []string{TypeVault})
+	}
+}
+
+// New returns a new secrets provider for the specified configuration
+func New(config Config) (Provider, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	switch config.Type {
+	case TypeVault:
+		provider, err := NewVault(config.Vault)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return provider, nil
+	}
+	return nil, trace.BadParameter("unsupported secrets provider %q", config.Type)
+}
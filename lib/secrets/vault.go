@@ -0,0 +1,228 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/httplib"
+
+	"github.com/gravitational/trace"
+)
+
+// VaultConfig configures the secrets provider backed by HashiCorp Vault.
+// The secrets are stored in the version 2 of the KV secrets engine
+type VaultConfig struct {
+	// Address is the address of the Vault server, e.g. https://vault.example.com:8200
+	Address string `json:"address" yaml:"address"`
+	// Token is the Vault token to authenticate with
+	Token string `json:"token,omitempty" yaml:"token"`
+	// TokenPath is the path to the file with the Vault token.
+	// If neither token nor token path is set, the token is taken
+	// from the VAULT_TOKEN environment variable
+	TokenPath string `json:"token_path,omitempty" yaml:"token_path"`
+	// Namespace is the optional Vault Enterprise namespace
+	Namespace string `json:"namespace,omitempty" yaml:"namespace"`
+	// Mount is the mount path of the KV secrets engine.
+	// Defaults to defaults.VaultMount
+	Mount string `json:"mount,omitempty" yaml:"mount"`
+	// Prefix is the path prefix of the secrets stored by gravity.
+	// Defaults to defaults.VaultPrefix
+	Prefix string `json:"prefix,omitempty" yaml:"prefix"`
+	// CAFile is the optional path to the CA certificate to verify
+	// the Vault server certificate with
+	CAFile string `json:"ca_file,omitempty" yaml:"ca_file"`
+	// Client is the HTTP client to use, used in tests
+	Client *http.Client `json:"-" yaml:"-"`
+}
+
+// CheckAndSetDefaults validates this configuration and sets defaults
+func (c *VaultConfig) CheckAndSetDefaults() error {
+	if c.Address == "" {
+		return trace.BadParameter("missing Vault address")
+	}
+	if c.Token == "" && c.TokenPath == "" {
+		c.Token = os.Getenv(VaultTokenEnvVar)
+	}
+	if c.Token == "" && c.TokenPath == "" {
+		return trace.BadParameter("missing Vault token, set either token or token_path "+
+			"or the %v environment variable", VaultTokenEnvVar)
+	}
+	if c.Mount == "" {
+		c.Mount = defaults.VaultMount
+	}
+	if c.Prefix == "" {
+		c.Prefix = defaults.VaultPrefix
+	}
+	c.Address = strings.TrimSuffix(c.Address, "/")
+	c.Mount = strings.Trim(c.Mount, "/")
+	c.Prefix = strings.Trim(c.Prefix, "/")
+	return nil
+}
+
+// VaultTokenEnvVar is the environment variable with the Vault token
+const VaultTokenEnvVar = "VAULT_TOKEN"
+
+// NewVault returns a new secrets provider backed by HashiCorp Vault
+func NewVault(config VaultConfig) (*vault, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	token := config.Token
+	if token == "" {
+		data, err := ioutil.ReadFile(config.TokenPath)
+		if err != nil {
+			return nil, trace.ConvertSystemError(err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	client := config.Client
+	if client == nil {
+		options := []httplib.ClientOption{httplib.WithTimeout(defaults.VaultRequestTimeout)}
+		if config.CAFile != "" {
+			ca, err := ioutil.ReadFile(config.CAFile)
+			if err != nil {
+				return nil, trace.ConvertSystemError(err)
+			}
+			options = append(options, httplib.WithCA(ca))
+		}
+		client = httplib.GetClient(false, options...)
+	}
+	return &vault{
+		VaultConfig: config,
+		client:      client,
+		token:       token,
+	}, nil
+}
+
+// GetSecret returns the secret value stored at the specified path
+func (r *vault) GetSecret(ctx context.Context, secretPath string) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Data vaultSecret `json:"data"`
+		} `json:"data"`
+	}
+	err := r.do(ctx, http.MethodGet, r.url("data", secretPath), nil, &resp)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("secret %v not found", secretPath)
+		}
+		return nil, trace.Wrap(err)
+	}
+	value, err := base64.StdEncoding.DecodeString(resp.Data.Data.Value)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to decode secret %v", secretPath)
+	}
+	return value, nil
+}
+
+// PutSecret stores the secret value at the specified path
+func (r *vault) PutSecret(ctx context.Context, secretPath string, value []byte) error {
+	req := struct {
+		Data vaultSecret `json:"data"`
+	}{
+		Data: vaultSecret{Value: base64.StdEncoding.EncodeToString(value)},
+	}
+	return trace.Wrap(r.do(ctx, http.MethodPost, r.url("data", secretPath), req, nil))
+}
+
+// DeleteSecret removes all versions of the secret stored at the specified path
+func (r *vault) DeleteSecret(ctx context.Context, secretPath string) error {
+	err := r.do(ctx, http.MethodDelete, r.url("metadata", secretPath), nil, nil)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return trace.NotFound("secret %v not found", secretPath)
+		}
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+func (r *vault) url(kind, secretPath string) string {
+	return r.Address + "/" + path.Join("v1", r.Mount, kind, r.Prefix, secretPath)
+}
+
+func (r *vault) do(ctx context.Context, method, url string, in, out interface{}) error {
+	var body []byte
+	if in != nil {
+		var err error
+		body, err = json.Marshal(in)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("X-Vault-Token", r.token)
+	if r.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", r.Namespace)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return trace.ReadError(resp.StatusCode, vaultErrors(data))
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return trace.Wrap(json.Unmarshal(data, out))
+}
+
+// vaultErrors extracts the error messages from the Vault error response
+func vaultErrors(data []byte) []byte {
+	var resp struct {
+		Errors []string `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil || len(resp.Errors) == 0 {
+		return data
+	}
+	return []byte(strings.Join(resp.Errors, ", "))
+}
+
+type vault struct {
+	VaultConfig
+	client *http.Client
+	token  string
+}
+
+// vaultSecret is the secret value stored in Vault
+type vaultSecret struct {
+	// Value is the base64-encoded secret value
+	Value string `json:"value"`
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+func TestSecrets(t *testing.T) { TestingT(t) }
+
+type VaultSuite struct {
+	server *httptest.Server
+	kv     *fakeKV
+}
+
+var _ = Suite(&VaultSuite{})
+
+func (s *VaultSuite) SetUpTest(c *C) {
+	s.kv = &fakeKV{token: "token", secrets: make(map[string]json.RawMessage)}
+	s.server = httptest.NewServer(s.kv)
+}
+
+func (s *VaultSuite) TearDownTest(c *C) {
+	s.server.Close()
+}
+
+func (s *VaultSuite) TestStoresSecrets(c *C) {
+	provider, err := NewVault(VaultConfig{
+		Address: s.server.URL,
+		Token:   "token",
+	})
+	c.Assert(err, IsNil)
+	ctx := context.TODO()
+
+	_, err = provider.GetSecret(ctx, "authorities/host/example.com")
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%v", err))
+
+	c.Assert(provider.PutSecret(ctx, "authorities/host/example.com", []byte("private key")), IsNil)
+	c.Assert(s.kv.paths(), DeepEquals, []string{"/v1/secret/data/gravity/authorities/host/example.com"})
+	value, err := provider.GetSecret(ctx, "authorities/host/example.com")
+	c.Assert(err, IsNil)
+	c.Assert(string(value), Equals, "private key")
+
+	c.Assert(provider.DeleteSecret(ctx, "authorities/host/example.com"), IsNil)
+	_, err = provider.GetSecret(ctx, "authorities/host/example.com")
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%v", err))
+}
+
+func (s *VaultSuite) TestReadsTokenFromFile(c *C) {
+	path := filepath.Join(c.MkDir(), "token")
+	c.Assert(ioutil.WriteFile(path, []byte("token\n"), 0600), IsNil)
+	provider, err := NewVault(VaultConfig{
+		Address:   s.server.URL,
+		TokenPath: path,
+		Mount:     "/kv/",
+		Prefix:    "clusters/example.com",
+	})
+	c.Assert(err, IsNil)
+	c.Assert(provider.PutSecret(context.TODO(), "provtokens/token", []byte("secret")), IsNil)
+	c.Assert(s.kv.paths(), DeepEquals, []string{"/v1/kv/data/clusters/example.com/provtokens/token"})
+}
+
+func (s *VaultSuite) TestRejectsInvalidToken(c *C) {
+	provider, err := NewVault(VaultConfig{
+		Address: s.server.URL,
+		Token:   "invalid",
+	})
+	c.Assert(err, IsNil)
+	err = provider.PutSecret(context.TODO(), "provtokens/token", []byte("secret"))
+	c.Assert(trace.IsAccessDenied(err), Equals, true, Commentf("%v", err))
+	c.Assert(err, ErrorMatches, ".*permission denied.*")
+}
+
+func (s *VaultSuite) TestValidatesConfig(c *C) {
+	var tcs = []struct {
+		config  Config
+		comment string
+	}{
+		{config: Config{}, comment: "missing type"},
+		{config: Config{Type: "kms"}, comment: "unsupported type"},
+		{config: Config{Type: TypeVault, Vault: VaultConfig{Token: "token"}}, comment: "missing address"},
+		{config: Config{Type: TypeVault, Vault: VaultConfig{Address: s.server.URL}}, comment: "missing token"},
+	}
+	for _, tc := range tcs {
+		c.Assert(tc.config.CheckAndSetDefaults(), NotNil, Commentf(tc.comment))
+	}
+}
+
+// fakeKV implements the subset of the Vault KV version 2 API
+type fakeKV struct {
+	sync.Mutex
+	token   string
+	secrets map[string]json.RawMessage
+}
+
+func (r *fakeKV) paths() (paths []string) {
+	r.Lock()
+	defer r.Unlock()
+	for path := range r.secrets {
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func (r *fakeKV) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Header.Get("X-Vault-Token") != r.token {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"errors":["permission denied"]}`))
+		return
+	}
+	r.Lock()
+	defer r.Unlock()
+	path := strings.Replace(req.URL.Path, "/metadata/", "/data/", 1)
+	switch req.Method {
+	case http.MethodGet:
+		data, ok := r.secrets[path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": json.RawMessage(data),
+		})
+	case http.MethodPost:
+		data, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.secrets[path] = data
+		w.Write([]byte(`{"data":{"version":1}}`))
+	case http.MethodDelete:
+		delete(r.secrets, path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,350 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package shutdown implements the graceful shutdown and the subsequent
+// startup of the whole cluster.
+//
+// Since the cluster state store is unavailable while the cluster is stopped,
+// the progress of the shutdown is recorded in a state file on the node
+// where the shutdown has been started and the startup is executed from the
+// same node
+package shutdown
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/rpc"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/cenkalti/backoff"
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// Cluster provides the cluster-level actions used to stop and start the cluster
+type Cluster interface {
+	// Cordon marks the specified node unschedulable
+	Cordon(ctx context.Context, server storage.Server) error
+	// Drain evicts the pods from the specified node
+	Drain(ctx context.Context, server storage.Server) error
+	// Uncordon marks the specified node schedulable
+	Uncordon(ctx context.Context, server storage.Server) error
+	// SnapshotEtcd takes a backup of the etcd database and returns
+	// the path to the backup file
+	SnapshotEtcd(ctx context.Context) (path string, err error)
+	// CheckHealth returns an error if any of the specified nodes is not healthy
+	CheckHealth(ctx context.Context, servers []storage.Server) error
+}
+
+// Config describes the configuration for stopping and starting the cluster
+type Config struct {
+	// StateFile is the path to the file with the state of the stopped cluster
+	StateFile string
+	// Cluster executes the cluster-level actions
+	Cluster Cluster
+	// Runner executes commands on the cluster nodes
+	Runner rpc.RemoteRunner
+	// Timeout is the maximum amount of time to wait for the nodes
+	// to become available after they have been started.
+	// Defaults to defaults.ClusterStartTimeout
+	Timeout time.Duration
+	// RetryInterval is the interval between node status checks.
+	// Defaults to defaults.RetryInterval
+	RetryInterval time.Duration
+	// Printer outputs the progress
+	utils.Printer
+	// FieldLogger is used for logging
+	log.FieldLogger
+}
+
+// CheckAndSetDefaults validates the configuration and sets defaults
+func (r *Config) CheckAndSetDefaults() error {
+	if r.StateFile == "" {
+		return trace.BadParameter("path to the state file is required")
+	}
+	if r.Cluster == nil {
+		return trace.BadParameter("cluster is required")
+	}
+	if r.Runner == nil {
+		return trace.BadParameter("remote command runner is required")
+	}
+	if r.Timeout == 0 {
+		r.Timeout = defaults.ClusterStartTimeout
+	}
+	if r.RetryInterval == 0 {
+		r.RetryInterval = defaults.RetryInterval
+	}
+	if r.Printer == nil {
+		r.Printer = utils.DiscardPrinter
+	}
+	if r.FieldLogger == nil {
+		r.FieldLogger = log.WithField(trace.Component, "shutdown")
+	}
+	return nil
+}
+
+// State describes the progress of the cluster shutdown
+type State struct {
+	// ClusterName is the name of the stopped cluster
+	ClusterName string `json:"cluster_name"`
+	// Servers lists all cluster nodes
+	Servers []storage.Server `json:"servers"`
+	// Local is the master node the shutdown has been started on
+	Local storage.Server `json:"local"`
+	// Drained specifies whether the nodes have been drained
+	Drained bool `json:"drained,omitempty"`
+	// EtcdSnapshot is the path to the etcd backup taken before the shutdown
+	EtcdSnapshot string `json:"etcd_snapshot,omitempty"`
+	// Stopped lists the addresses of the stopped nodes
+	Stopped []string `json:"stopped,omitempty"`
+	// Created is the time the shutdown has been started
+	Created time.Time `json:"created"`
+}
+
+// NewState returns the initial state for stopping the cluster with the
+// specified nodes from the specified local master node
+func NewState(clusterName string, servers []storage.Server, local storage.Server) (*State, error) {
+	if !local.IsMaster() {
+		return nil, trace.BadParameter("the cluster can only be stopped from one of the master nodes")
+	}
+	return &State{
+		ClusterName: clusterName,
+		Servers:     servers,
+		Local:       local,
+		Created:     time.Now().UTC(),
+	}, nil
+}
+
+// LoadState reads the state of the stopped cluster from the specified file.
+// Returns NotFound if the cluster has not been stopped from this node
+func LoadState(path string) (*State, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		err = trace.ConvertSystemError(err)
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("no stopped cluster found on this node, " +
+				"make sure to start the cluster from the node it was stopped on")
+		}
+		return nil, trace.Wrap(err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &state, nil
+}
+
+// IsStopped returns true if all cluster nodes have been stopped
+func (r State) IsStopped() bool {
+	for _, server := range r.Servers {
+		if !r.isStopped(server) {
+			return false
+		}
+	}
+	return true
+}
+
+// Stop gracefully stops the cluster.
+// All nodes are cordoned, then drained starting with the regular nodes,
+// the etcd database is backed up and the runtime is stopped on all
+// nodes starting with the regular nodes and finishing with the local master.
+// The progress is recorded in the state file so the shutdown can be
+// resumed if interrupted
+func Stop(ctx context.Context, config Config, state *State) error {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := state.save(config.StateFile); err != nil {
+		return trace.Wrap(err)
+	}
+	servers := state.stopOrder()
+	if !state.Drained {
+		for _, server := range servers {
+			config.PrintStep("Cordoning node %v", server.Hostname)
+			if err := config.Cluster.Cordon(ctx, server); err != nil {
+				return trace.Wrap(err, "failed to cordon node %v", server.Hostname)
+			}
+		}
+		for _, server := range servers {
+			config.PrintStep("Draining node %v", server.Hostname)
+			if err := config.Cluster.Drain(ctx, server); err != nil {
+				return trace.Wrap(err, "failed to drain node %v", server.Hostname)
+			}
+		}
+		state.Drained = true
+		if err := state.save(config.StateFile); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	if state.EtcdSnapshot == "" && len(state.Stopped) == 0 {
+		config.PrintStep("Taking etcd snapshot")
+		path, err := config.Cluster.SnapshotEtcd(ctx)
+		if err != nil {
+			return trace.Wrap(err, "failed to take etcd snapshot")
+		}
+		config.PrintStep("Saved etcd snapshot to %v", path)
+		state.EtcdSnapshot = path
+		if err := state.save(config.StateFile); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	for _, server := range servers {
+		if state.isStopped(server) {
+			continue
+		}
+		config.PrintStep("Stopping node %v", server.Hostname)
+		err := config.Runner.Run(ctx, server, "planet", "stop")
+		if err != nil {
+			return trace.Wrap(err, "failed to stop node %v", server.Hostname)
+		}
+		state.Stopped = append(state.Stopped, server.AdvertiseIP)
+		if err := state.save(config.StateFile); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// Start starts the cluster stopped with Stop.
+// The runtime is started on the master nodes first and, once the masters
+// are healthy, on the regular nodes. After all nodes have become healthy,
+// they are uncordoned and the state file is removed
+func Start(ctx context.Context, config Config, state *State) error {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	masters, nodes := state.startOrder()
+	config.PrintStep("Waiting for agents to become available")
+	for _, server := range append(masters, nodes...) {
+		err := retry(ctx, config, func() error {
+			return trace.Wrap(config.Runner.CanExecute(ctx, server))
+		})
+		if err != nil {
+			return trace.Wrap(err, "agent on node %v is not available", server.Hostname)
+		}
+	}
+	if err := startNodes(ctx, config, state, masters); err != nil {
+		return trace.Wrap(err)
+	}
+	config.PrintStep("Waiting for masters to become healthy")
+	err := retry(ctx, config, func() error {
+		return trace.Wrap(config.Cluster.CheckHealth(ctx, masters))
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := startNodes(ctx, config, state, nodes); err != nil {
+		return trace.Wrap(err)
+	}
+	config.PrintStep("Waiting for the cluster to become healthy")
+	err = retry(ctx, config, func() error {
+		return trace.Wrap(config.Cluster.CheckHealth(ctx, state.Servers))
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	for _, server := range append(masters, nodes...) {
+		config.PrintStep("Uncordoning node %v", server.Hostname)
+		err := retry(ctx, config, func() error {
+			return trace.Wrap(config.Cluster.Uncordon(ctx, server))
+		})
+		if err != nil {
+			return trace.Wrap(err, "failed to uncordon node %v", server.Hostname)
+		}
+	}
+	err = os.Remove(config.StateFile)
+	if err != nil && !os.IsNotExist(err) {
+		return trace.ConvertSystemError(err)
+	}
+	return nil
+}
+
+func startNodes(ctx context.Context, config Config, state *State, servers []storage.Server) error {
+	for _, server := range servers {
+		config.PrintStep("Starting node %v", server.Hostname)
+		err := config.Runner.Run(ctx, server, "planet", "start")
+		if err != nil {
+			return trace.Wrap(err, "failed to start node %v", server.Hostname)
+		}
+		state.removeStopped(server)
+		if err := state.save(config.StateFile); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+func retry(ctx context.Context, config Config, fn func() error) error {
+	ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+	return utils.RetryWithInterval(ctx, backoff.NewConstantBackOff(config.RetryInterval), fn)
+}
+
+// stopOrder returns the nodes in the order they are stopped:
+// regular nodes first, then the masters with the local node last
+func (r State) stopOrder() (servers []storage.Server) {
+	masters, nodes := r.startOrder()
+	servers = append(servers, nodes...)
+	for i := len(masters) - 1; i >= 0; i-- {
+		servers = append(servers, masters[i])
+	}
+	return servers
+}
+
+// startOrder returns the master nodes starting with the local node
+// and the regular nodes in the order they are started
+func (r State) startOrder() (masters, nodes []storage.Server) {
+	masters = append(masters, r.Local)
+	for _, server := range r.Servers {
+		switch {
+		case server.AdvertiseIP == r.Local.AdvertiseIP:
+		case server.IsMaster():
+			masters = append(masters, server)
+		default:
+			nodes = append(nodes, server)
+		}
+	}
+	return masters, nodes
+}
+
+func (r State) isStopped(server storage.Server) bool {
+	return utils.StringInSlice(r.Stopped, server.AdvertiseIP)
+}
+
+func (r *State) removeStopped(server storage.Server) {
+	var stopped []string
+	for _, addr := range r.Stopped {
+		if addr != server.AdvertiseIP {
+			stopped = append(stopped, addr)
+		}
+	}
+	r.Stopped = stopped
+}
+
+func (r State) save(path string) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = ioutil.WriteFile(path, data, defaults.PrivateFileMask)
+	return trace.ConvertSystemError(err)
+}
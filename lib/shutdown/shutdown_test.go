@@ -0,0 +1,172 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shutdown
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+func TestShutdown(t *testing.T) { TestingT(t) }
+
+type ShutdownSuite struct {
+	servers []storage.Server
+}
+
+var _ = Suite(&ShutdownSuite{})
+
+func (s *ShutdownSuite) SetUpTest(c *C) {
+	s.servers = []storage.Server{
+		newServer("master-1", "10.0.0.1", schema.ServiceRoleMaster),
+		newServer("node-1", "10.0.0.2", schema.ServiceRoleNode),
+		newServer("master-2", "10.0.0.3", schema.ServiceRoleMaster),
+		newServer("node-2", "10.0.0.4", schema.ServiceRoleNode),
+	}
+}
+
+func (s *ShutdownSuite) TestStopsAndStartsCluster(c *C) {
+	var actions []string
+	config := newConfig(c, &actions)
+	state, err := NewState("example.com", s.servers, s.servers[0])
+	c.Assert(err, IsNil)
+
+	c.Assert(Stop(context.TODO(), config, state), IsNil)
+	c.Assert(strings.Join(actions, ","), Equals, strings.Join([]string{
+		"cordon node-1", "cordon node-2", "cordon master-2", "cordon master-1",
+		"drain node-1", "drain node-2", "drain master-2", "drain master-1",
+		"snapshot",
+		"stop node-1", "stop node-2", "stop master-2", "stop master-1",
+	}, ","))
+
+	state, err = LoadState(config.StateFile)
+	c.Assert(err, IsNil)
+	c.Assert(state.IsStopped(), Equals, true)
+	c.Assert(state.EtcdSnapshot, Equals, "/etcd.bak")
+
+	actions = nil
+	c.Assert(Start(context.TODO(), config, state), IsNil)
+	c.Assert(strings.Join(actions, ","), Equals, strings.Join([]string{
+		"start master-1", "start master-2",
+		"health master-1,master-2",
+		"start node-1", "start node-2",
+		"health master-1,node-1,master-2,node-2",
+		"uncordon master-1", "uncordon master-2", "uncordon node-1", "uncordon node-2",
+	}, ","))
+
+	_, err = LoadState(config.StateFile)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+}
+
+func (s *ShutdownSuite) TestResumesStop(c *C) {
+	var actions []string
+	config := newConfig(c, &actions)
+	state, err := NewState("example.com", s.servers, s.servers[0])
+	c.Assert(err, IsNil)
+	state.Drained = true
+	state.EtcdSnapshot = "/etcd.bak"
+	state.Stopped = []string{"10.0.0.2", "10.0.0.4"}
+
+	c.Assert(Stop(context.TODO(), config, state), IsNil)
+	c.Assert(actions, DeepEquals, []string{"stop master-2", "stop master-1"})
+}
+
+func (s *ShutdownSuite) TestRequiresMaster(c *C) {
+	_, err := NewState("example.com", s.servers, s.servers[1])
+	c.Assert(trace.IsBadParameter(err), Equals, true)
+}
+
+func newConfig(c *C, actions *[]string) Config {
+	cluster := &testCluster{actions: actions}
+	return Config{
+		StateFile:     filepath.Join(c.MkDir(), "state.json"),
+		Cluster:       cluster,
+		Runner:        &testRunner{testCluster: cluster},
+		RetryInterval: time.Millisecond,
+	}
+}
+
+func newServer(hostname, addr string, role schema.ServiceRole) storage.Server {
+	return storage.Server{
+		Hostname:    hostname,
+		AdvertiseIP: addr,
+		ClusterRole: string(role),
+	}
+}
+
+type testCluster struct {
+	actions *[]string
+}
+
+func (r *testCluster) record(format string, args ...interface{}) {
+	*r.actions = append(*r.actions, fmt.Sprintf(format, args...))
+}
+
+func (r *testCluster) Cordon(ctx context.Context, server storage.Server) error {
+	r.record("cordon %v", server.Hostname)
+	return nil
+}
+
+func (r *testCluster) Drain(ctx context.Context, server storage.Server) error {
+	r.record("drain %v", server.Hostname)
+	return nil
+}
+
+func (r *testCluster) Uncordon(ctx context.Context, server storage.Server) error {
+	r.record("uncordon %v", server.Hostname)
+	return nil
+}
+
+func (r *testCluster) SnapshotEtcd(ctx context.Context) (string, error) {
+	r.record("snapshot")
+	return "/etcd.bak", nil
+}
+
+func (r *testCluster) CheckHealth(ctx context.Context, servers []storage.Server) error {
+	var hostnames []string
+	for _, server := range servers {
+		hostnames = append(hostnames, server.Hostname)
+	}
+	r.record("health %v", strings.Join(hostnames, ","))
+	return nil
+}
+
+type testRunner struct {
+	*testCluster
+}
+
+func (r *testRunner) Run(ctx context.Context, server storage.Server, args ...string) error {
+	r.record("%v %v", args[len(args)-1], server.Hostname)
+	return nil
+}
+
+func (r *testRunner) CanExecute(context.Context, storage.Server) error {
+	return nil
+}
+
+func (r *testRunner) Close() error {
+	return nil
+}
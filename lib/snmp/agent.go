@@ -0,0 +1,353 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package snmp implements an SNMP sub-agent that exposes the cluster health.
+
+The sub-agent connects to the master agent (e.g. net-snmp snmpd) using the
+AgentX protocol (RFC 2741), registers its subtree and answers the read
+requests the master agent forwards to it. Write requests are rejected.
+*/
+package snmp
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+)
+
+// Config defines the sub-agent configuration
+type Config struct {
+	// Addr is the address of the AgentX master agent: either a path to
+	// the Unix socket or tcp:host:port
+	Addr string
+	// Root is the object identifier of the subtree registered by the sub-agent
+	Root OID
+	// Description describes the sub-agent to the master agent
+	Description string
+	// RetryInterval is the interval between attempts to reconnect
+	// to the master agent
+	RetryInterval time.Duration
+	// FieldLogger is used for logging
+	logrus.FieldLogger
+}
+
+// CheckAndSetDefaults validates the configuration and sets default values
+func (r *Config) CheckAndSetDefaults() error {
+	if len(r.Root) == 0 {
+		return trace.BadParameter("missing Root")
+	}
+	if r.Addr == "" {
+		r.Addr = defaults.AgentXAddr
+	}
+	if r.Description == "" {
+		r.Description = "Gravity cluster health"
+	}
+	if r.RetryInterval == 0 {
+		r.RetryInterval = defaults.AgentXRetryInterval
+	}
+	if r.FieldLogger == nil {
+		r.FieldLogger = logrus.WithField(trace.Component, "snmp")
+	}
+	return nil
+}
+
+// NewSubAgent returns a new sub-agent
+func NewSubAgent(config Config) (*SubAgent, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &SubAgent{Config: config}, nil
+}
+
+// SubAgent is an AgentX sub-agent serving a set of variables
+type SubAgent struct {
+	// Config is the sub-agent configuration
+	Config
+	// mu guards the variables
+	mu sync.RWMutex
+	// vars lists the served variables sorted by object identifier
+	vars []Variable
+	// packetID is the last packet ID used in requests to the master agent
+	packetID uint32
+}
+
+// SetVariables replaces the set of variables served by the sub-agent
+func (r *SubAgent) SetVariables(vars []Variable) {
+	sorted := make([]Variable, len(vars))
+	copy(sorted, vars)
+	sortVariables(sorted)
+	r.mu.Lock()
+	r.vars = sorted
+	r.mu.Unlock()
+}
+
+// Serve connects to the master agent and serves requests until the context
+// is cancelled. The sub-agent reconnects if the session with the master
+// agent is lost
+func (r *SubAgent) Serve(ctx context.Context) error {
+	for {
+		conn, err := dial(ctx, r.Addr)
+		if err == nil {
+			err = r.serveConn(ctx, conn)
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		r.WithError(err).Warnf("AgentX session with %v failed, will reconnect in %v.",
+			r.Addr, r.RetryInterval)
+		select {
+		case <-time.After(r.RetryInterval):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// serveConn opens a session over the provided connection and serves
+// the master agent requests until the session is closed
+func (r *SubAgent) serveConn(ctx context.Context, conn net.Conn) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	sessionID, err := r.open(conn)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	r.Infof("Opened AgentX session %v with %v.", sessionID, r.Addr)
+	if err := r.register(conn, sessionID); err != nil {
+		return trace.Wrap(err)
+	}
+	r.Infof("Registered subtree %v.", r.Root)
+	for {
+		p, err := readPDU(conn)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return trace.Wrap(err)
+		}
+		if err := r.handle(conn, *p); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return trace.Wrap(err)
+		}
+	}
+}
+
+// handle responds to the request from the master agent
+func (r *SubAgent) handle(w io.Writer, p pdu) error {
+	var resp response
+	d := newDecoder(p)
+	switch p.Type {
+	case pduGet:
+		d.context(p.Flags)
+		resp.Variables = r.get(d.searchRanges())
+	case pduGetNext:
+		d.context(p.Flags)
+		resp.Variables = r.getNext(d.searchRanges())
+	case pduGetBulk:
+		d.context(p.Flags)
+		nonRepeaters := int(d.uint16())
+		maxRepetitions := int(d.uint16())
+		resp.Variables = r.getBulk(d.searchRanges(), nonRepeaters, maxRepetitions)
+	case pduTestSet:
+		resp.Error, resp.Index = errorNotWritable, 1
+	case pduCommitSet, pduUndoSet:
+		resp.Error, resp.Index = errorGeneric, 1
+	case pduCleanupSet, pduResponse:
+		// no response expected
+		return nil
+	case pduClose:
+		return trace.ConnectionProblem(nil, "master agent closed the session")
+	default:
+		r.Debugf("Ignoring unsupported AgentX PDU type %v.", p.Type)
+		return nil
+	}
+	if d.err != nil {
+		r.WithError(d.err).Warn("Failed to decode AgentX request.")
+		resp = response{Error: errorGeneric}
+	}
+	payload, err := resp.encode()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	hdr := p.header
+	hdr.Type = pduResponse
+	hdr.Flags = 0
+	return trace.Wrap(writePDU(w, hdr, payload))
+}
+
+// open opens a new session with the master agent and returns the session ID
+func (r *SubAgent) open(conn net.Conn) (sessionID uint32, err error) {
+	var e encoder
+	e.uint8(0) // use the default timeout of the master agent
+	e.reserved(3)
+	e.oid(r.Root, false)
+	e.octetString([]byte(r.Description))
+	hdr, err := r.request(conn, header{Type: pduOpen}, e.Bytes())
+	if err != nil {
+		return 0, trace.Wrap(err, "failed to open AgentX session")
+	}
+	return hdr.SessionID, nil
+}
+
+// register registers the sub-agent subtree with the master agent
+func (r *SubAgent) register(conn net.Conn, sessionID uint32) error {
+	var e encoder
+	e.uint8(0) // use the session timeout
+	e.uint8(defaults.AgentXPriority)
+	e.uint8(0) // no range
+	e.reserved(1)
+	e.oid(r.Root, false)
+	_, err := r.request(conn, header{Type: pduRegister, SessionID: sessionID}, e.Bytes())
+	if err != nil {
+		return trace.Wrap(err, "failed to register subtree %v", r.Root)
+	}
+	return nil
+}
+
+// request sends the request to the master agent and waits for the response
+func (r *SubAgent) request(conn net.Conn, hdr header, payload []byte) (*header, error) {
+	r.packetID++
+	hdr.PacketID = r.packetID
+	conn.SetDeadline(time.Now().Add(defaults.AgentXRequestTimeout))
+	defer conn.SetDeadline(time.Time{})
+	if err := writePDU(conn, hdr, payload); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for {
+		p, err := readPDU(conn)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if p.Type != pduResponse || p.PacketID != hdr.PacketID {
+			r.Debugf("Ignoring unexpected AgentX PDU type %v.", p.Type)
+			continue
+		}
+		resp, err := decodeResponse(*p)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if resp.Error != 0 {
+			return nil, trace.BadParameter("master agent returned error %v", resp.Error)
+		}
+		return &p.header, nil
+	}
+}
+
+// get returns the variables with the exact object identifiers requested
+func (r *SubAgent) get(ranges []searchRange) (vars []Variable) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, sr := range ranges {
+		vars = append(vars, r.lookup(sr.Start))
+	}
+	return vars
+}
+
+// getNext returns the variables following each of the requested ranges
+func (r *SubAgent) getNext(ranges []searchRange) (vars []Variable) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, sr := range ranges {
+		vars = append(vars, r.next(sr))
+	}
+	return vars
+}
+
+// getBulk returns the variables following the first nonRepeaters ranges
+// and up to maxRepetitions successive variables for the remaining ranges
+func (r *SubAgent) getBulk(ranges []searchRange, nonRepeaters, maxRepetitions int) (vars []Variable) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if nonRepeaters > len(ranges) {
+		nonRepeaters = len(ranges)
+	}
+	for _, sr := range ranges[:nonRepeaters] {
+		vars = append(vars, r.next(sr))
+	}
+	repeaters := make([]searchRange, len(ranges)-nonRepeaters)
+	copy(repeaters, ranges[nonRepeaters:])
+	for i := 0; i < maxRepetitions && len(repeaters) != 0; i++ {
+		done := true
+		for j, sr := range repeaters {
+			v := r.next(sr)
+			vars = append(vars, v)
+			if v.Type != TypeEndOfMIBView {
+				done = false
+			}
+			repeaters[j] = searchRange{Start: v.OID, End: sr.End}
+		}
+		if done {
+			break
+		}
+	}
+	return vars
+}
+
+func (r *SubAgent) lookup(oid OID) Variable {
+	for _, v := range r.vars {
+		if v.OID.Compare(oid) == 0 {
+			return v
+		}
+	}
+	return Variable{OID: oid, Type: TypeNoSuchObject}
+}
+
+func (r *SubAgent) next(sr searchRange) Variable {
+	for _, v := range r.vars {
+		cmp := v.OID.Compare(sr.Start)
+		if cmp < 0 || (cmp == 0 && !sr.Include) {
+			continue
+		}
+		if len(sr.End) != 0 && v.OID.Compare(sr.End) >= 0 {
+			break
+		}
+		return v
+	}
+	return Variable{OID: sr.Start, Type: TypeEndOfMIBView}
+}
+
+// dial connects to the master agent at the specified address
+func dial(ctx context.Context, addr string) (net.Conn, error) {
+	network := "unix"
+	switch {
+	case strings.HasPrefix(addr, "tcp:"):
+		network, addr = "tcp", strings.TrimPrefix(addr, "tcp:")
+	case strings.HasPrefix(addr, "unix:"):
+		addr = strings.TrimPrefix(addr, "unix:")
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	return conn, nil
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snmp
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/gravitational/gravity/lib/status"
+
+	"gopkg.in/check.v1"
+)
+
+func TestSNMP(t *testing.T) { check.TestingT(t) }
+
+type SNMPSuite struct{}
+
+var _ = check.Suite(&SNMPSuite{})
+
+func (s *SNMPSuite) TestParsesOID(c *check.C) {
+	oid, err := ParseOID(".1.3.6.1.4.1")
+	c.Assert(err, check.IsNil)
+	c.Assert(oid, check.DeepEquals, OID{1, 3, 6, 1, 4, 1})
+	c.Assert(oid.String(), check.Equals, "1.3.6.1.4.1")
+
+	for _, invalid := range []string{"", "1.3.x", "1..3"} {
+		_, err := ParseOID(invalid)
+		c.Assert(err, check.NotNil, check.Commentf(invalid))
+	}
+}
+
+func (s *SNMPSuite) TestComparesOID(c *check.C) {
+	c.Assert(OID{1, 3, 6}.Compare(OID{1, 3, 6}), check.Equals, 0)
+	c.Assert(OID{1, 3}.Compare(OID{1, 3, 6}), check.Equals, -1)
+	c.Assert(OID{1, 3, 10}.Compare(OID{1, 3, 9, 1}), check.Equals, 1)
+}
+
+func (s *SNMPSuite) TestServesRequests(c *check.C) {
+	root := OID{1, 3, 6, 1, 4, 1, 8072, 9999, 9999}
+	agent, err := NewSubAgent(Config{Root: root})
+	c.Assert(err, check.IsNil)
+	agent.SetVariables(ClusterVariables(root, status.Status{
+		Cluster: &status.Cluster{Domain: "example.com", State: "active"},
+		Agent: &status.Agent{
+			SystemStatus: status.SystemStatus(1),
+			Nodes: []status.ClusterServer{{
+				Hostname:    "node-1",
+				AdvertiseIP: "10.0.0.1",
+				Status:      status.NodeHealthy,
+			}},
+		},
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	master, conn := net.Pipe()
+	defer master.Close()
+	errC := make(chan error, 1)
+	go func() {
+		errC <- agent.serveConn(ctx, conn)
+	}()
+
+	open := expectPDU(c, master, pduOpen)
+	respond(c, master, open.header, 42)
+	register := expectPDU(c, master, pduRegister)
+	c.Assert(register.SessionID, check.Equals, uint32(42))
+	d := newDecoder(*register)
+	d.uint32()
+	subtree, _ := d.oid()
+	c.Assert(subtree, check.DeepEquals, root)
+	respond(c, master, register.header, 42)
+
+	vars := query(c, master, pduGet, nil,
+		searchRange{Start: root.Append(clusterStateID, 0)},
+		searchRange{Start: root.Append(42, 0)})
+	c.Assert(vars, check.DeepEquals, []Variable{
+		Integer(root.Append(clusterStateID, 0), int(status.CheckOK)),
+		{OID: root.Append(42, 0), Type: TypeNoSuchObject},
+	})
+
+	vars = query(c, master, pduGetNext, nil,
+		searchRange{Start: root},
+		searchRange{Start: root.Append(clusterNameID, 0), Include: true},
+		searchRange{Start: root.Append(nodeTableID, 1, nodeFailedProbesColumn, 1)},
+		searchRange{Start: root.Append(expiringCertificatesID, 0)})
+	c.Assert(vars, check.DeepEquals, []Variable{
+		OctetString(root.Append(clusterNameID, 0), "example.com"),
+		OctetString(root.Append(clusterNameID, 0), "example.com"),
+		Gauge32(root.Append(networkProblemsID, 0), 0),
+		{OID: root.Append(expiringCertificatesID, 0), Type: TypeEndOfMIBView},
+	})
+
+	vars = query(c, master, pduGetBulk, []uint16{0, 3},
+		searchRange{Start: root.Append(nodeTableID)})
+	c.Assert(vars, check.DeepEquals, []Variable{
+		OctetString(root.Append(nodeTableID, 1, nodeHostnameColumn, 1), "node-1"),
+		OctetString(root.Append(nodeTableID, 1, nodeAddressColumn, 1), "10.0.0.1"),
+		OctetString(root.Append(nodeTableID, 1, nodeStatusColumn, 1), status.NodeHealthy),
+	})
+
+	var e encoder
+	c.Assert(e.variable(Integer(root.Append(clusterStateID, 0), 1)), check.IsNil)
+	c.Assert(writePDU(master, header{Type: pduTestSet, SessionID: 42, PacketID: 10}, e.Bytes()), check.IsNil)
+	resp := expectResponse(c, master)
+	c.Assert(resp.Error, check.Equals, uint16(errorNotWritable))
+
+	cancel()
+	c.Assert(<-errC, check.IsNil)
+}
+
+func query(c *check.C, conn net.Conn, typ pduType, bulk []uint16, ranges ...searchRange) []Variable {
+	var e encoder
+	for _, value := range bulk {
+		e.uint16(value)
+	}
+	for _, sr := range ranges {
+		e.oid(sr.Start, sr.Include)
+		e.oid(sr.End, false)
+	}
+	c.Assert(writePDU(conn, header{Type: typ, SessionID: 42, PacketID: 1}, e.Bytes()), check.IsNil)
+	return expectResponse(c, conn).Variables
+}
+
+func expectResponse(c *check.C, conn net.Conn) *response {
+	p := expectPDU(c, conn, pduResponse)
+	resp, err := decodeResponse(*p)
+	c.Assert(err, check.IsNil)
+	return resp
+}
+
+func expectPDU(c *check.C, conn net.Conn, typ pduType) *pdu {
+	p, err := readPDU(conn)
+	c.Assert(err, check.IsNil)
+	c.Assert(p.Type, check.Equals, typ)
+	return p
+}
+
+func respond(c *check.C, conn net.Conn, hdr header, sessionID uint32) {
+	payload, err := response{}.encode()
+	c.Assert(err, check.IsNil)
+	hdr.Type = pduResponse
+	hdr.SessionID = sessionID
+	c.Assert(writePDU(conn, hdr, payload), check.IsNil)
+}
@@ -0,0 +1,560 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snmp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gravitational/trace"
+)
+
+// OID is an SNMP object identifier
+type OID []uint32
+
+// ParseOID parses the object identifier in dotted notation, e.g. 1.3.6.1.4.1
+func ParseOID(s string) (OID, error) {
+	s = strings.TrimPrefix(s, ".")
+	if s == "" {
+		return nil, trace.BadParameter("object identifier cannot be empty")
+	}
+	var oid OID
+	for _, part := range strings.Split(s, ".") {
+		id, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return nil, trace.BadParameter("invalid object identifier %q", s)
+		}
+		oid = append(oid, uint32(id))
+	}
+	return oid, nil
+}
+
+// String returns the object identifier in dotted notation
+func (r OID) String() string {
+	parts := make([]string, 0, len(r))
+	for _, id := range r {
+		parts = append(parts, strconv.FormatUint(uint64(id), 10))
+	}
+	return strings.Join(parts, ".")
+}
+
+// Append returns a new object identifier with the specified sub-identifiers
+// appended to this one
+func (r OID) Append(ids ...uint32) OID {
+	oid := make(OID, 0, len(r)+len(ids))
+	oid = append(oid, r...)
+	return append(oid, ids...)
+}
+
+// Compare compares this object identifier with other lexicographically.
+// Returns -1, 0 or 1 if this identifier is less than, equal to or greater
+// than other respectively
+func (r OID) Compare(other OID) int {
+	for i := 0; i < len(r) && i < len(other); i++ {
+		switch {
+		case r[i] < other[i]:
+			return -1
+		case r[i] > other[i]:
+			return 1
+		}
+	}
+	switch {
+	case len(r) < len(other):
+		return -1
+	case len(r) > len(other):
+		return 1
+	}
+	return 0
+}
+
+// VarType is the type of the variable value
+type VarType uint16
+
+const (
+	// TypeInteger is a signed 32-bit integer
+	TypeInteger VarType = 2
+	// TypeOctetString is an octet string
+	TypeOctetString VarType = 4
+	// TypeNull is the null value
+	TypeNull VarType = 5
+	// TypeObjectIdentifier is an object identifier
+	TypeObjectIdentifier VarType = 6
+	// TypeIPAddress is an IPv4 address
+	TypeIPAddress VarType = 64
+	// TypeCounter32 is an unsigned 32-bit counter
+	TypeCounter32 VarType = 65
+	// TypeGauge32 is an unsigned 32-bit gauge
+	TypeGauge32 VarType = 66
+	// TypeTimeTicks is the time in hundredths of a second
+	TypeTimeTicks VarType = 67
+	// TypeCounter64 is an unsigned 64-bit counter
+	TypeCounter64 VarType = 70
+	// TypeNoSuchObject indicates that the requested object does not exist
+	TypeNoSuchObject VarType = 128
+	// TypeNoSuchInstance indicates that the requested instance does not exist
+	TypeNoSuchInstance VarType = 129
+	// TypeEndOfMIBView indicates that there are no more objects to walk
+	TypeEndOfMIBView VarType = 130
+)
+
+// Variable is an SNMP variable binding
+type Variable struct {
+	// OID is the variable object identifier
+	OID OID
+	// Type is the type of the variable value
+	Type VarType
+	// Value is the variable value: int32 for integers, uint32 for counters,
+	// gauges and time ticks, uint64 for 64-bit counters, string for octet strings,
+	// OID for object identifiers and net.IP for IP addresses
+	Value interface{}
+}
+
+// Integer returns a new integer variable
+func Integer(oid OID, value int) Variable {
+	return Variable{OID: oid, Type: TypeInteger, Value: int32(value)}
+}
+
+// OctetString returns a new octet string variable
+func OctetString(oid OID, value string) Variable {
+	return Variable{OID: oid, Type: TypeOctetString, Value: value}
+}
+
+// Gauge32 returns a new gauge variable
+func Gauge32(oid OID, value int) Variable {
+	return Variable{OID: oid, Type: TypeGauge32, Value: uint32(value)}
+}
+
+// sortVariables sorts the variables in the lexicographic order of their
+// object identifiers
+func sortVariables(vars []Variable) {
+	sort.Slice(vars, func(i, j int) bool {
+		return vars[i].OID.Compare(vars[j].OID) < 0
+	})
+}
+
+// pduType is the AgentX PDU type as defined in RFC 2741, section 6.1
+type pduType uint8
+
+const (
+	pduOpen       pduType = 1
+	pduClose      pduType = 2
+	pduRegister   pduType = 3
+	pduUnregister pduType = 4
+	pduGet        pduType = 5
+	pduGetNext    pduType = 6
+	pduGetBulk    pduType = 7
+	pduTestSet    pduType = 8
+	pduCommitSet  pduType = 9
+	pduUndoSet    pduType = 10
+	pduCleanupSet pduType = 11
+	pduNotify     pduType = 12
+	pduPing       pduType = 13
+	pduResponse   pduType = 18
+)
+
+const (
+	// agentxVersion is the version of the AgentX protocol
+	agentxVersion = 1
+	// headerSize is the size of the AgentX PDU header
+	headerSize = 20
+	// maxPayloadSize limits the size of the PDU payload accepted
+	// from the master agent
+	maxPayloadSize = 1 << 20
+
+	// flagNonDefaultContext indicates that the PDU contains a context
+	flagNonDefaultContext = 0x08
+	// flagNetworkByteOrder indicates that the PDU is encoded in big-endian
+	// byte order
+	flagNetworkByteOrder = 0x10
+
+	// closeReasonShutdown is the reason for closing the session
+	// when the sub-agent shuts down
+	closeReasonShutdown = 5
+
+	// errorGeneric is the genErr response error
+	errorGeneric = 5
+	// errorNotWritable is the notWritable response error
+	errorNotWritable = 17
+)
+
+// internetPrefix is the object identifier prefix implied by the non-zero
+// prefix field of the encoded object identifier
+var internetPrefix = OID{1, 3, 6, 1}
+
+// header is the AgentX PDU header
+type header struct {
+	// Type is the PDU type
+	Type pduType
+	// Flags is the PDU flags bitmask
+	Flags uint8
+	// SessionID identifies the session
+	SessionID uint32
+	// TransactionID identifies the request transaction
+	TransactionID uint32
+	// PacketID identifies the request packet
+	PacketID uint32
+}
+
+// pdu is an AgentX protocol data unit
+type pdu struct {
+	header
+	// Payload is the encoded PDU payload
+	Payload []byte
+}
+
+// readPDU reads the next PDU from the provided reader
+func readPDU(r io.Reader) (*pdu, error) {
+	var buf [headerSize]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	if buf[0] != agentxVersion {
+		return nil, trace.BadParameter("unsupported AgentX version %v", buf[0])
+	}
+	order := byteOrder(buf[2])
+	p := &pdu{
+		header: header{
+			Type:          pduType(buf[1]),
+			Flags:         buf[2],
+			SessionID:     order.Uint32(buf[4:]),
+			TransactionID: order.Uint32(buf[8:]),
+			PacketID:      order.Uint32(buf[12:]),
+		},
+	}
+	size := order.Uint32(buf[16:])
+	if size > maxPayloadSize {
+		return nil, trace.LimitExceeded("AgentX payload of %v bytes exceeds maximum of %v",
+			size, maxPayloadSize)
+	}
+	p.Payload = make([]byte, size)
+	if _, err := io.ReadFull(r, p.Payload); err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	return p, nil
+}
+
+// writePDU writes the PDU with the specified header and payload.
+// The PDU is always encoded in network byte order
+func writePDU(w io.Writer, hdr header, payload []byte) error {
+	var buf [headerSize]byte
+	buf[0] = agentxVersion
+	buf[1] = byte(hdr.Type)
+	buf[2] = hdr.Flags | flagNetworkByteOrder
+	binary.BigEndian.PutUint32(buf[4:], hdr.SessionID)
+	binary.BigEndian.PutUint32(buf[8:], hdr.TransactionID)
+	binary.BigEndian.PutUint32(buf[12:], hdr.PacketID)
+	binary.BigEndian.PutUint32(buf[16:], uint32(len(payload)))
+	_, err := w.Write(append(buf[:], payload...))
+	return trace.ConvertSystemError(err)
+}
+
+func byteOrder(flags uint8) binary.ByteOrder {
+	if flags&flagNetworkByteOrder != 0 {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}
+
+// encoder encodes the PDU payload in network byte order
+type encoder struct {
+	bytes.Buffer
+}
+
+func (e *encoder) uint8(v uint8) {
+	e.WriteByte(v)
+}
+
+func (e *encoder) uint16(v uint16) {
+	var buf [2]byte
+	binary.BigEndian.PutUint16(buf[:], v)
+	e.Write(buf[:])
+}
+
+func (e *encoder) uint32(v uint32) {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], v)
+	e.Write(buf[:])
+}
+
+func (e *encoder) uint64(v uint64) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	e.Write(buf[:])
+}
+
+func (e *encoder) reserved(n int) {
+	e.Write(make([]byte, n))
+}
+
+// oid encodes the object identifier without prefix compression
+func (e *encoder) oid(oid OID, include bool) {
+	e.uint8(uint8(len(oid)))
+	e.uint8(0)
+	if include {
+		e.uint8(1)
+	} else {
+		e.uint8(0)
+	}
+	e.uint8(0)
+	for _, id := range oid {
+		e.uint32(id)
+	}
+}
+
+// octetString encodes the octet string padded to a 4-byte boundary
+func (e *encoder) octetString(s []byte) {
+	e.uint32(uint32(len(s)))
+	e.Write(s)
+	if pad := len(s) % 4; pad != 0 {
+		e.reserved(4 - pad)
+	}
+}
+
+// variable encodes the variable binding
+func (e *encoder) variable(v Variable) error {
+	e.uint16(uint16(v.Type))
+	e.reserved(2)
+	e.oid(v.OID, false)
+	switch v.Type {
+	case TypeInteger:
+		value, ok := v.Value.(int32)
+		if !ok {
+			return trace.BadParameter("expected int32 value for %v, got %T", v.OID, v.Value)
+		}
+		e.uint32(uint32(value))
+	case TypeCounter32, TypeGauge32, TypeTimeTicks:
+		value, ok := v.Value.(uint32)
+		if !ok {
+			return trace.BadParameter("expected uint32 value for %v, got %T", v.OID, v.Value)
+		}
+		e.uint32(value)
+	case TypeCounter64:
+		value, ok := v.Value.(uint64)
+		if !ok {
+			return trace.BadParameter("expected uint64 value for %v, got %T", v.OID, v.Value)
+		}
+		e.uint64(value)
+	case TypeOctetString:
+		value, ok := v.Value.(string)
+		if !ok {
+			return trace.BadParameter("expected string value for %v, got %T", v.OID, v.Value)
+		}
+		e.octetString([]byte(value))
+	case TypeObjectIdentifier:
+		value, ok := v.Value.(OID)
+		if !ok {
+			return trace.BadParameter("expected OID value for %v, got %T", v.OID, v.Value)
+		}
+		e.oid(value, false)
+	case TypeIPAddress:
+		value, ok := v.Value.(net.IP)
+		if !ok || value.To4() == nil {
+			return trace.BadParameter("expected IPv4 address value for %v, got %v", v.OID, v.Value)
+		}
+		e.octetString(value.To4())
+	case TypeNull, TypeNoSuchObject, TypeNoSuchInstance, TypeEndOfMIBView:
+	default:
+		return trace.BadParameter("unsupported variable type %v", v.Type)
+	}
+	return nil
+}
+
+// decoder decodes the PDU payload.
+//
+// Decoding errors are sticky: once an error is encountered, all subsequent
+// reads return zero values and the error is available via err
+type decoder struct {
+	buf   []byte
+	order binary.ByteOrder
+	err   error
+}
+
+func newDecoder(p pdu) *decoder {
+	return &decoder{buf: p.Payload, order: byteOrder(p.Flags)}
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.buf) < n {
+		d.err = trace.BadParameter("unexpected end of AgentX payload")
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) uint8() uint8 {
+	b := d.next(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (d *decoder) uint16() uint16 {
+	b := d.next(2)
+	if b == nil {
+		return 0
+	}
+	return d.order.Uint16(b)
+}
+
+func (d *decoder) uint32() uint32 {
+	b := d.next(4)
+	if b == nil {
+		return 0
+	}
+	return d.order.Uint32(b)
+}
+
+func (d *decoder) uint64() uint64 {
+	b := d.next(8)
+	if b == nil {
+		return 0
+	}
+	return d.order.Uint64(b)
+}
+
+func (d *decoder) oid() (oid OID, include bool) {
+	count := int(d.uint8())
+	prefix := d.uint8()
+	include = d.uint8() != 0
+	d.uint8()
+	if prefix != 0 {
+		oid = internetPrefix.Append(uint32(prefix))
+	}
+	for i := 0; i < count && d.err == nil; i++ {
+		oid = append(oid, d.uint32())
+	}
+	return oid, include
+}
+
+func (d *decoder) octetString() []byte {
+	size := int(d.uint32())
+	padded := size
+	if pad := size % 4; pad != 0 {
+		padded += 4 - pad
+	}
+	b := d.next(padded)
+	if b == nil {
+		return nil
+	}
+	return b[:size]
+}
+
+// context skips the context octet string if the PDU has one
+func (d *decoder) context(flags uint8) {
+	if flags&flagNonDefaultContext != 0 {
+		d.octetString()
+	}
+}
+
+// variable decodes the variable binding
+func (d *decoder) variable() Variable {
+	var v Variable
+	v.Type = VarType(d.uint16())
+	d.uint16()
+	v.OID, _ = d.oid()
+	switch v.Type {
+	case TypeInteger:
+		v.Value = int32(d.uint32())
+	case TypeCounter32, TypeGauge32, TypeTimeTicks:
+		v.Value = d.uint32()
+	case TypeCounter64:
+		v.Value = d.uint64()
+	case TypeOctetString:
+		v.Value = string(d.octetString())
+	case TypeObjectIdentifier:
+		v.Value, _ = d.oid()
+	case TypeIPAddress:
+		v.Value = net.IP(d.octetString())
+	case TypeNull, TypeNoSuchObject, TypeNoSuchInstance, TypeEndOfMIBView:
+	default:
+		if d.err == nil {
+			d.err = trace.BadParameter("unsupported variable type %v", v.Type)
+		}
+	}
+	return v
+}
+
+// searchRange is the range of object identifiers requested by the master agent
+type searchRange struct {
+	// Start is the start of the range
+	Start OID
+	// Include specifies whether the start of the range is included
+	Include bool
+	// End is the exclusive end of the range. Empty means unbounded
+	End OID
+}
+
+func (d *decoder) searchRanges() (ranges []searchRange) {
+	for len(d.buf) != 0 && d.err == nil {
+		var r searchRange
+		r.Start, r.Include = d.oid()
+		r.End, _ = d.oid()
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// response is the payload of the response PDU
+type response struct {
+	// Error is the response error code
+	Error uint16
+	// Index is the 1-based index of the variable that caused the error
+	Index uint16
+	// Variables lists the response variables
+	Variables []Variable
+}
+
+func (r response) encode() ([]byte, error) {
+	var e encoder
+	// sysUpTime is only meaningful in the responses from the master agent
+	e.uint32(0)
+	e.uint16(r.Error)
+	e.uint16(r.Index)
+	for _, v := range r.Variables {
+		if err := e.variable(v); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	return e.Bytes(), nil
+}
+
+func decodeResponse(p pdu) (*response, error) {
+	d := newDecoder(p)
+	d.uint32()
+	r := &response{
+		Error: d.uint16(),
+		Index: d.uint16(),
+	}
+	for len(d.buf) != 0 && d.err == nil {
+		r.Variables = append(r.Variables, d.variable())
+	}
+	if d.err != nil {
+		return nil, trace.Wrap(d.err)
+	}
+	return r, nil
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snmp
+
+import (
+	"github.com/gravitational/gravity/lib/status"
+)
+
+// Cluster health objects relative to the root of the subtree
+const (
+	// clusterNameID is the cluster name
+	clusterNameID = 1
+	// clusterStateID is the cluster state: 0 (ok), 1 (warning),
+	// 2 (critical) or 3 (unknown)
+	clusterStateID = 2
+	// clusterStateTextID is the textual cluster state
+	clusterStateTextID = 3
+	// clusterSummaryID is the single-line description of the cluster state
+	clusterSummaryID = 4
+	// nodesID is the number of cluster nodes
+	nodesID = 5
+	// degradedNodesID is the number of degraded nodes
+	degradedNodesID = 6
+	// offlineNodesID is the number of offline nodes
+	offlineNodesID = 7
+	// failedHealthChecksID is the number of failed custom health checks
+	failedHealthChecksID = 8
+	// activeAlertsID is the number of active Prometheus alerts
+	activeAlertsID = 9
+	// activeOperationsID is the number of operations in progress
+	activeOperationsID = 10
+	// nodeTableID is the table of cluster nodes indexed by the node number
+	nodeTableID = 11
+	// networkProblemsID is the number of network problems between the nodes
+	networkProblemsID = 12
+	// etcdQuotaUsageID is the percentage of the etcd space quota used
+	etcdQuotaUsageID = 13
+	// expiringCertificatesID is the number of certificates that have expired or are about to
+	expiringCertificatesID = 14
+)
+
+// Node table columns
+const (
+	// nodeHostnameColumn is the node hostname
+	nodeHostnameColumn = 1
+	// nodeAddressColumn is the node advertise address
+	nodeAddressColumn = 2
+	// nodeStatusColumn is the node status: healthy, degraded or offline
+	nodeStatusColumn = 3
+	// nodeFailedProbesColumn is the number of failed node probes
+	nodeFailedProbesColumn = 4
+)
+
+// ClusterVariables returns the variables describing the cluster health
+// in the subtree with the specified root
+func ClusterVariables(root OID, clusterStatus status.Status) []Variable {
+	result := clusterStatus.Check()
+	var clusterName string
+	if clusterStatus.Cluster != nil {
+		clusterName = clusterStatus.Cluster.Domain
+	}
+	vars := []Variable{
+		OctetString(root.Append(clusterNameID, 0), clusterName),
+		Integer(root.Append(clusterStateID, 0), int(result.State)),
+		OctetString(root.Append(clusterStateTextID, 0), result.State.String()),
+		OctetString(root.Append(clusterSummaryID, 0), result.Summary),
+		Gauge32(root.Append(nodesID, 0), result.Nodes),
+		Gauge32(root.Append(degradedNodesID, 0), result.DegradedNodes),
+		Gauge32(root.Append(offlineNodesID, 0), result.OfflineNodes),
+		Gauge32(root.Append(failedHealthChecksID, 0), result.FailedHealthChecks),
+		Gauge32(root.Append(activeAlertsID, 0), result.ActiveAlerts),
+		Gauge32(root.Append(activeOperationsID, 0), result.ActiveOperations),
+		Gauge32(root.Append(networkProblemsID, 0), result.NetworkProblems),
+		Gauge32(root.Append(etcdQuotaUsageID, 0), result.EtcdQuotaUsage),
+		Gauge32(root.Append(expiringCertificatesID, 0), result.ExpiringCertificates),
+	}
+	if clusterStatus.Agent == nil {
+		return vars
+	}
+	table := root.Append(nodeTableID, 1)
+	for i, node := range clusterStatus.Agent.Nodes {
+		index := uint32(i + 1)
+		vars = append(vars,
+			OctetString(table.Append(nodeHostnameColumn, index), node.Hostname),
+			OctetString(table.Append(nodeAddressColumn, index), node.AdvertiseIP),
+			OctetString(table.Append(nodeStatusColumn, index), node.Status),
+			Gauge32(table.Append(nodeFailedProbesColumn, index), len(node.FailedProbes)),
+		)
+	}
+	return vars
+}
@@ -0,0 +1,285 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/storage"
+
+	pb "github.com/gravitational/satellite/agent/proto/agentpb"
+)
+
+// CheckState is the cluster state as reported to external monitoring systems.
+//
+// The values follow the Nagios plugin exit code convention
+type CheckState int
+
+const (
+	// CheckOK means the cluster is healthy
+	CheckOK CheckState = 0
+	// CheckWarning means the cluster is functional but requires attention
+	CheckWarning CheckState = 1
+	// CheckCritical means the cluster is degraded
+	CheckCritical CheckState = 2
+	// CheckUnknown means the cluster status could not be determined
+	CheckUnknown CheckState = 3
+)
+
+// String returns the textual representation of the check state
+func (r CheckState) String() string {
+	switch r {
+	case CheckOK:
+		return "OK"
+	case CheckWarning:
+		return "WARNING"
+	case CheckCritical:
+		return "CRITICAL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// CheckResult summarizes the cluster status for external monitoring systems
+type CheckResult struct {
+	// State is the overall cluster state
+	State CheckState
+	// Summary is a single-line description of the cluster state
+	Summary string
+	// Details lists the individual problems found
+	Details []string
+	// Nodes is the total number of cluster nodes
+	Nodes int
+	// DegradedNodes is the number of degraded nodes
+	DegradedNodes int
+	// OfflineNodes is the number of unreachable nodes
+	OfflineNodes int
+	// FailedHealthChecks is the number of failed custom health checks
+	FailedHealthChecks int
+	// ActiveAlerts is the number of active Prometheus alerts
+	ActiveAlerts int
+	// ActiveOperations is the number of operations in progress
+	ActiveOperations int
+	// NetworkProblems is the number of network problems between the nodes
+	NetworkProblems int
+	// EtcdQuotaUsage is the percentage of the etcd space quota used
+	EtcdQuotaUsage int
+	// ExpiringCertificates is the number of certificates that have expired
+	// or are about to
+	ExpiringCertificates int
+}
+
+// Check evaluates the cluster status for external monitoring systems
+func (r Status) Check() CheckResult {
+	if r.Cluster == nil && r.Agent == nil {
+		return CheckResult{
+			State:   CheckUnknown,
+			Summary: "failed to collect cluster status",
+		}
+	}
+	var result CheckResult
+	var critical, warnings []string
+	var warningNodes int
+	if r.Agent != nil {
+		result.Nodes = len(r.Agent.Nodes)
+		for _, node := range r.Agent.Nodes {
+			switch {
+			case node.Status == NodeDegraded:
+				result.DegradedNodes++
+			case node.Status == NodeOffline:
+				result.OfflineNodes++
+			case len(node.WarnProbes) != 0:
+				warningNodes++
+			}
+			result.Details = append(result.Details, nodeDetails(node)...)
+		}
+	} else {
+		critical = append(critical, "failed to query planet agent")
+	}
+	if warningNodes != 0 {
+		warnings = append(warnings, fmt.Sprintf("%v node(-s) with warnings", warningNodes))
+	}
+	if result.DegradedNodes != 0 {
+		critical = append(critical, fmt.Sprintf("%v of %v node(-s) degraded",
+			result.DegradedNodes, result.Nodes))
+	}
+	if result.OfflineNodes != 0 {
+		critical = append(critical, fmt.Sprintf("%v of %v node(-s) offline",
+			result.OfflineNodes, result.Nodes))
+	}
+	if r.Cluster != nil {
+		result.ActiveOperations = len(r.Cluster.ActiveOperations)
+		if r.Cluster.HealthChecks != nil {
+			failedCritical := r.Cluster.HealthChecks.Failed(storage.HealthCheckSeverityCritical)
+			failedWarning := r.Cluster.HealthChecks.Failed(storage.HealthCheckSeverityWarning)
+			for _, failed := range append(failedCritical, failedWarning...) {
+				result.Details = append(result.Details, fmt.Sprintf("health check %v", failed))
+			}
+			result.FailedHealthChecks = len(failedCritical) + len(failedWarning)
+			if len(failedCritical) != 0 {
+				critical = append(critical, fmt.Sprintf("%v health check(-s) failed", len(failedCritical)))
+			}
+			if len(failedWarning) != 0 {
+				warnings = append(warnings, fmt.Sprintf("%v health check(-s) with warnings", len(failedWarning)))
+			}
+		}
+		for _, pool := range r.Cluster.NodePools {
+			if pool.IsBelowMinimum() {
+				warnings = append(warnings, fmt.Sprintf("node pool %v is below minimum of %v node(-s)",
+					pool.Name, pool.MinCount))
+			}
+		}
+		if r.Cluster.EtcdMaintenance != nil && !r.Cluster.EtcdMaintenance.IsHealthy() {
+			warnings = append(warnings, "etcd maintenance is degraded")
+		}
+		if r.Cluster.Backup != nil && !r.Cluster.Backup.IsHealthy() {
+			warnings = append(warnings, "last scheduled backup failed")
+		}
+		if r.Cluster.EtcdHealth != nil {
+			result.EtcdQuotaUsage = r.Cluster.EtcdHealth.Status.QuotaUsage()
+			var criticalProblems int
+			for _, problem := range r.Cluster.EtcdHealth.Problems {
+				if problem.Critical {
+					criticalProblems++
+				}
+				result.Details = append(result.Details, fmt.Sprintf("etcd %v", problem))
+			}
+			if criticalProblems != 0 {
+				critical = append(critical, fmt.Sprintf("%v critical etcd problem(-s)", criticalProblems))
+			}
+			if other := len(r.Cluster.EtcdHealth.Problems) - criticalProblems; other != 0 {
+				warnings = append(warnings, fmt.Sprintf("%v etcd problem(-s)", other))
+			}
+		}
+		if r.Cluster.Certificates != nil {
+			var criticalProblems int
+			for _, problem := range r.Cluster.Certificates.Problems {
+				if problem.Critical {
+					criticalProblems++
+				}
+				result.Details = append(result.Details, fmt.Sprintf("certificate %v", problem))
+			}
+			result.ExpiringCertificates = len(r.Cluster.Certificates.Problems)
+			if criticalProblems != 0 {
+				critical = append(critical, fmt.Sprintf("%v certificate(-s) expire within %v",
+					criticalProblems, storage.FormatExpiresIn(r.Cluster.Certificates.Status.Critical)))
+			}
+			if other := result.ExpiringCertificates - criticalProblems; other != 0 {
+				warnings = append(warnings, fmt.Sprintf("%v certificate(-s) expire within %v",
+					other, storage.FormatExpiresIn(r.Cluster.Certificates.Status.Warning)))
+			}
+		}
+		if r.Cluster.Hardening != nil {
+			for _, problem := range r.Cluster.Hardening.Problems {
+				result.Details = append(result.Details, fmt.Sprintf("hardening %v", problem))
+			}
+			if len(r.Cluster.Hardening.Problems) != 0 {
+				warnings = append(warnings, fmt.Sprintf("%v deviation(-s) from %v hardening profile",
+					len(r.Cluster.Hardening.Problems), r.Cluster.Hardening.Profile))
+			}
+		}
+		if r.Cluster.Network != nil {
+			var unreachable int
+			for _, problem := range r.Cluster.Network.Problems {
+				if problem.Critical {
+					unreachable++
+				}
+				result.Details = append(result.Details, fmt.Sprintf("network %v", problem))
+			}
+			result.NetworkProblems = len(r.Cluster.Network.Problems)
+			if unreachable != 0 {
+				critical = append(critical, fmt.Sprintf("%v node pair(-s) cannot communicate", unreachable))
+			}
+			if other := result.NetworkProblems - unreachable; other != 0 {
+				warnings = append(warnings, fmt.Sprintf("%v network problem(-s)", other))
+			}
+		}
+	}
+	for _, alert := range r.Alerts {
+		if alert.Status == nil || alert.Status.State == nil || *alert.Status.State != "active" {
+			continue
+		}
+		result.ActiveAlerts++
+		result.Details = append(result.Details, fmt.Sprintf("alert %v: %v",
+			alert.Labels["alertname"], alert.Annotations["message"]))
+	}
+	if result.ActiveAlerts != 0 {
+		warnings = append(warnings, fmt.Sprintf("%v active alert(-s)", result.ActiveAlerts))
+	}
+	if len(critical) == 0 && r.IsDegraded() {
+		critical = append(critical, r.degradedReason())
+	}
+	switch {
+	case len(critical) != 0:
+		result.State = CheckCritical
+		result.Summary = fmt.Sprintf("cluster %v is degraded: %v", r.domain(),
+			strings.Join(append(critical, warnings...), ", "))
+	case len(warnings) != 0:
+		result.State = CheckWarning
+		result.Summary = fmt.Sprintf("cluster %v is %v: %v", r.domain(), r.Cluster.State,
+			strings.Join(warnings, ", "))
+	default:
+		result.State = CheckOK
+		result.Summary = fmt.Sprintf("cluster %v is %v, %v node(-s) healthy",
+			r.domain(), r.Cluster.State, result.Nodes)
+	}
+	return result
+}
+
+func (r Status) domain() string {
+	if r.Cluster == nil || r.Cluster.Domain == "" {
+		return "<unknown>"
+	}
+	return r.Cluster.Domain
+}
+
+func (r Status) degradedReason() string {
+	switch {
+	case r.Cluster == nil:
+		return "failed to query cluster state"
+	case r.Cluster.Reason != "":
+		return r.Cluster.Reason.Description()
+	case r.Cluster.Domain == "":
+		return "failed to collect cluster status"
+	case r.Agent != nil && r.Agent.GetSystemStatus() != pb.SystemStatus_Running:
+		return fmt.Sprintf("system status is %v", r.Agent.SystemStatus)
+	default:
+		return fmt.Sprintf("cluster state is %v", r.Cluster.State)
+	}
+}
+
+func nodeDetails(node ClusterServer) (details []string) {
+	name := fmt.Sprintf("node %v (%v)", unknownName(node.Hostname), node.AdvertiseIP)
+	if node.Status == NodeOffline {
+		return []string{fmt.Sprintf("%v is offline", name)}
+	}
+	for _, probe := range node.FailedProbes {
+		details = append(details, fmt.Sprintf("%v: %v", name, probe))
+	}
+	for _, probe := range node.WarnProbes {
+		details = append(details, fmt.Sprintf("%v: %v", name, probe))
+	}
+	return details
+}
+
+func unknownName(name string) string {
+	if name == "" {
+		return "<unknown>"
+	}
+	return name
+}
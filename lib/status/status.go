@@ -22,10 +22,12 @@ import (
 	"fmt"
 	"io"
 	"net/url"
+	"sort"
 	"time"
 
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/hardening"
 	"github.com/gravitational/gravity/lib/httplib"
 	"github.com/gravitational/gravity/lib/loc"
 	"github.com/gravitational/gravity/lib/ops"
@@ -39,6 +41,7 @@ import (
 	"github.com/gravitational/trace"
 	"github.com/prometheus/alertmanager/api/v2/models"
 	"github.com/sirupsen/logrus"
+	"k8s.io/helm/pkg/proto/hapi/release"
 )
 
 // FromCluster collects cluster status information.
@@ -51,18 +54,11 @@ func FromCluster(ctx context.Context, operator ops.Operator, cluster ops.Site, o
 			State:     ops.SiteStateDegraded,
 			Reason:    cluster.Reason,
 			App:       cluster.App.Package,
+			Demo:      cluster.Demo,
 			Extension: newExtension(),
 		},
 	}
 
-	token, err := operator.GetExpandToken(cluster.Key())
-	if err != nil && !trace.IsNotFound(err) {
-		return status, trace.Wrap(err)
-	}
-	if token != nil {
-		status.Token = *token
-	}
-
 	// Collect application endpoints.
 	appEndpoints, err := operator.GetApplicationEndpoints(cluster.Key())
 	if err != nil {
@@ -89,6 +85,83 @@ func FromCluster(ctx context.Context, operator ops.Operator, cluster ops.Site, o
 		status.Endpoints.Cluster.UI = clusterEndpoints.ManagementURLs()
 	}
 
+	clusterConfig, err := operator.GetClusterConfiguration(cluster.Key())
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to fetch cluster configuration.")
+	}
+	if clusterConfig != nil {
+		status.FIPS = clusterConfig.IsFIPS()
+		profile := hardening.Profile(clusterConfig.GetHardeningProfile())
+		if profile.IsEnabled() {
+			status.Hardening = fromHardeningProfile(profile, cluster.DNSConfig.Addr())
+		}
+	}
+
+	pools, err := operator.GetNodePools(cluster.Key())
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to fetch node pools.")
+	}
+	status.NodePools = fromNodePools(pools, cluster.ClusterState.Servers)
+
+	releases, err := operator.GetTrackedReleases(cluster.Key())
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to fetch application releases.")
+	}
+	status.Releases = fromReleases(releases)
+
+	status.HealthChecks, err = operator.GetHealthCheckStatus(cluster.Key())
+	if err != nil && !trace.IsNotFound(err) {
+		logrus.WithError(err).Warn("Failed to fetch health check status.")
+	}
+
+	reports, err := operator.GetNetworkHealth(cluster.Key())
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to fetch network health.")
+	}
+	if len(reports) != 0 {
+		status.Network = &Network{
+			Reports:  reports,
+			Problems: storage.NetworkProblems(reports, time.Now()),
+		}
+	}
+
+	status.EtcdMaintenance, err = operator.GetEtcdMaintenanceStatus(cluster.Key())
+	if err != nil && !trace.IsNotFound(err) {
+		logrus.WithError(err).Warn("Failed to fetch etcd maintenance status.")
+	}
+
+	status.Backup, err = operator.GetBackupStatus(cluster.Key())
+	if err != nil && !trace.IsNotFound(err) {
+		logrus.WithError(err).Warn("Failed to fetch backup status.")
+	}
+
+	status.Replication, err = operator.GetReplicationStatus(cluster.Key())
+	if err != nil && !trace.IsNotFound(err) {
+		logrus.WithError(err).Warn("Failed to fetch replication status.")
+	}
+
+	etcdHealth, err := operator.GetEtcdHealthStatus(cluster.Key())
+	if err != nil && !trace.IsNotFound(err) {
+		logrus.WithError(err).Warn("Failed to fetch etcd health status.")
+	}
+	if etcdHealth != nil {
+		status.EtcdHealth = &EtcdHealth{
+			Status:   *etcdHealth,
+			Problems: etcdHealth.Problems(time.Now()),
+		}
+	}
+
+	certificates, err := operator.GetCertificateExpiry(cluster.Key())
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to fetch certificate expiry.")
+	}
+	if certificates != nil {
+		status.Certificates = &Certificates{
+			Status:   *certificates,
+			Problems: certificates.Problems(time.Now()),
+		}
+	}
+
 	// FIXME: have status extension accept the operator/environment
 	err = status.Cluster.Extension.Collect()
 	if err != nil {
@@ -173,6 +246,7 @@ func fromPlanetAgent(ctx context.Context, local bool, servers []storage.Server)
 func (r Status) IsDegraded() bool {
 	return (r.Cluster == nil ||
 		r.Cluster.State == ops.SiteStateDegraded ||
+		r.Cluster.HasFailedHealthChecks() ||
 		r.Agent == nil ||
 		r.Agent.GetSystemStatus() != pb.SystemStatus_Running)
 }
@@ -197,8 +271,13 @@ type Cluster struct {
 	Reason storage.Reason `json:"reason,omitempty"`
 	// Domain provides the name of the cluster domain
 	Domain string `json:"domain"`
-	// Token specifies the provisioning token used for joining nodes to cluster if any
-	Token storage.ProvisioningToken `json:"token"`
+	// Demo indicates that the cluster has been installed in demo mode
+	// and is not suitable for production use
+	Demo bool `json:"demo,omitempty"`
+	// FIPS indicates that the cluster runs in FIPS 140-2 mode
+	FIPS bool `json:"fips,omitempty"`
+	// Hardening describes the hardening profile of the cluster
+	Hardening *Hardening `json:"hardening,omitempty"`
 	// Operation describes a cluster operation.
 	// This can either refer to the last completed or a specific operation
 	Operation *ClusterOperation `json:"operation,omitempty"`
@@ -206,10 +285,188 @@ type Cluster struct {
 	ActiveOperations []*ClusterOperation `json:"active_operations,omitempty"`
 	// Endpoints contains cluster and application endpoints.
 	Endpoints Endpoints `json:"endpoints"`
+	// NodePools describes the capacity of the cluster node pools
+	NodePools []NodePool `json:"node_pools,omitempty"`
+	// Releases lists the applications deployed into the cluster
+	// with gravity app install
+	Releases []Release `json:"releases,omitempty"`
+	// EtcdMaintenance is the status of the etcd compaction and defragmentation
+	EtcdMaintenance *storage.EtcdMaintenanceStatus `json:"etcd_maintenance,omitempty"`
+	// Backup is the status of the scheduled cluster backups
+	Backup *storage.BackupStatus `json:"backup,omitempty"`
+	// Replication is the status of the replication of the primary
+	// cluster backups if this cluster is a standby cluster
+	Replication *storage.ReplicationStatus `json:"replication,omitempty"`
+	// EtcdHealth is the health and capacity of the etcd database
+	EtcdHealth *EtcdHealth `json:"etcd_health,omitempty"`
+	// HealthChecks is the result of the last evaluation of the custom
+	// cluster health checks
+	HealthChecks *storage.HealthCheckStatus `json:"health_checks,omitempty"`
+	// Network is the network health between the cluster nodes
+	Network *Network `json:"network,omitempty"`
+	// Certificates is the expiration status of the cluster certificates
+	Certificates *Certificates `json:"certificates,omitempty"`
 	// Extension is a cluster status extension
 	Extension `json:",inline,omitempty"`
 }
 
+// Hardening describes the hardening profile of the cluster
+type Hardening struct {
+	// Profile is the hardening profile the cluster has been installed with
+	Profile hardening.Profile `json:"profile"`
+	// Problems lists the deviations from the hardening profile
+	Problems []string `json:"problems,omitempty"`
+}
+
+// fromHardeningProfile verifies that the cluster conforms to the specified
+// hardening profile. The checks that cannot be performed from this node
+// are skipped
+func fromHardeningProfile(profile hardening.Profile, dnsAddr string) *Hardening {
+	config := hardening.VerifyConfig{
+		Profile:             profile,
+		KubeletReadOnlyAddr: fmt.Sprintf("%v:%v", constants.LoopbackIP, defaults.KubeletReadOnlyPort),
+	}
+	client, _, err := httplib.GetClusterKubeClient(dnsAddr)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to create Kubernetes client.")
+	} else {
+		config.Client = client
+	}
+	anonymousClient, _, err := httplib.GetAnonymousKubeClient(dnsAddr)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to create anonymous Kubernetes client.")
+	} else {
+		config.AnonymousClient = anonymousClient
+	}
+	return &Hardening{
+		Profile:  profile,
+		Problems: hardening.Verify(config),
+	}
+}
+
+// HasFailedHealthChecks returns true if any of the critical custom
+// cluster health checks failed
+func (r Cluster) HasFailedHealthChecks() bool {
+	return r.HealthChecks != nil &&
+		len(r.HealthChecks.Failed(storage.HealthCheckSeverityCritical)) != 0
+}
+
+// Network describes the network health between the cluster nodes
+type Network struct {
+	// Reports are the network health reports of the cluster nodes
+	Reports []storage.NetworkHealthReport `json:"reports"`
+	// Problems lists the network problems found in the reports
+	Problems []storage.NetworkProblem `json:"problems,omitempty"`
+}
+
+// EtcdHealth describes the health and capacity of the etcd database
+type EtcdHealth struct {
+	// Status is the last recorded etcd health status
+	Status storage.EtcdHealthStatus `json:"status"`
+	// Problems lists the problems found in the status
+	Problems []storage.EtcdProblem `json:"problems,omitempty"`
+}
+
+// Certificates describes the expiration of the cluster certificates
+type Certificates struct {
+	// Status is the expiration status of the certificates
+	Status storage.CertificateExpiryStatus `json:"status"`
+	// Problems lists the certificates that have expired or are about to
+	Problems []storage.CertificateProblem `json:"problems,omitempty"`
+}
+
+// NodePool describes the capacity of a node pool
+type NodePool struct {
+	// Name is the node pool name
+	Name string `json:"name"`
+	// Profile is the node profile of the pool
+	Profile string `json:"profile"`
+	// Nodes is the number of nodes in the pool
+	Nodes int `json:"nodes"`
+	// MinCount is the minimum number of nodes in the pool
+	MinCount int `json:"min_count,omitempty"`
+	// MaxCount is the maximum number of nodes in the pool.
+	// Zero means the number of nodes is not limited
+	MaxCount int `json:"max_count,omitempty"`
+}
+
+// IsBelowMinimum returns true if the pool has fewer nodes than required
+func (r NodePool) IsBelowMinimum() bool {
+	return r.Nodes < r.MinCount
+}
+
+// Capacity returns the number of nodes that can still join the pool
+// or -1 if the number of nodes in the pool is not limited
+func (r NodePool) Capacity() int {
+	if r.MaxCount == 0 {
+		return -1
+	}
+	if r.Nodes >= r.MaxCount {
+		return 0
+	}
+	return r.MaxCount - r.Nodes
+}
+
+func fromNodePools(pools []storage.NodePool, servers []storage.Server) (result []NodePool) {
+	for _, pool := range pools {
+		var nodes int
+		for _, server := range servers {
+			if server.NodePool == pool.GetName() {
+				nodes++
+			}
+		}
+		result = append(result, NodePool{
+			Name:     pool.GetName(),
+			Profile:  pool.GetProfile(),
+			Nodes:    nodes,
+			MinCount: pool.GetMinCount(),
+			MaxCount: pool.GetMaxCount(),
+		})
+	}
+	return result
+}
+
+// Release describes an application release deployed into the cluster
+type Release struct {
+	// Name is the release name
+	Name string `json:"name"`
+	// Application is the application image the release has been deployed from
+	Application loc.Locator `json:"application"`
+	// Chart is the chart name and version
+	Chart string `json:"chart"`
+	// Namespace is the namespace the release is deployed into
+	Namespace string `json:"namespace"`
+	// Status is the release status
+	Status string `json:"status"`
+	// Revision is the release revision
+	Revision int `json:"revision"`
+	// Updated is the time the release was last updated
+	Updated time.Time `json:"updated"`
+}
+
+// IsDeployed returns true if the release has been successfully deployed
+func (r Release) IsDeployed() bool {
+	return r.Status == release.Status_DEPLOYED.String()
+}
+
+func fromReleases(releases []storage.Release) (result []Release) {
+	for _, r := range releases {
+		result = append(result, Release{
+			Name:        r.GetName(),
+			Application: r.GetLocator(),
+			Chart:       r.GetChart(),
+			Namespace:   r.GetNamespace(),
+			Status:      r.GetStatus(),
+			Revision:    r.GetRevision(),
+			Updated:     r.GetUpdated(),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
 // Endpoints contains information about cluster and application endpoints.
 type Endpoints struct {
 	// Applications contains endpoints for installed applications.
@@ -338,6 +595,29 @@ func (r Agent) GetSystemStatus() pb.SystemStatus_Type {
 	return pb.SystemStatus_Type(r.SystemStatus)
 }
 
+// AddNodeProbes records the specified failed probes in the status
+// of the node with the given advertise address.
+// Critical probes mark the node as degraded
+func (r *Agent) AddNodeProbes(advertiseIP string, probes []*pb.Probe) {
+	for i, node := range r.Nodes {
+		if node.AdvertiseIP != advertiseIP {
+			continue
+		}
+		for _, probe := range probes {
+			if probe.Severity == pb.Probe_Warning {
+				node.WarnProbes = append(node.WarnProbes, probeErrorDetail(*probe))
+				continue
+			}
+			node.FailedProbes = append(node.FailedProbes, probeErrorDetail(*probe))
+			if node.Status == NodeHealthy {
+				node.Status = NodeDegraded
+			}
+		}
+		r.Nodes[i] = node
+		return
+	}
+}
+
 // Agent specifies the status of the system and individual nodes
 type Agent struct {
 	// SystemStatus defines the health status of the whole cluster
@@ -365,7 +645,7 @@ type ClusterServer struct {
 }
 
 func (r ClusterOperation) isFailed() bool {
-	return r.State == ops.OperationStateFailed
+	return r.State == ops.OperationStateFailed || r.State == ops.OperationStateFailedRolledBack
 }
 
 func fromOperationAndProgress(operation ops.SiteOperation, progress ops.ProgressEntry) *ClusterOperation {
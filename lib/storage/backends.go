@@ -0,0 +1,80 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/gravitational/trace"
+)
+
+// BackendParams defines the backend-specific configuration
+type BackendParams map[string]interface{}
+
+// BackendFactory creates a new storage backend with the specified configuration
+type BackendFactory func(params BackendParams) (Backend, error)
+
+// RegisterBackend registers the factory for the storage backends
+// of the specified type.
+// The backend implementations register themselves during package
+// initialization
+func RegisterBackend(backendType string, factory BackendFactory) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	backends[backendType] = factory
+}
+
+// NewBackend creates a new storage backend of the specified type
+func NewBackend(backendType string, params BackendParams) (Backend, error) {
+	backendsMu.RLock()
+	factory, ok := backends[backendType]
+	backendsMu.RUnlock()
+	if !ok {
+		return nil, trace.BadParameter("unsupported backend type %q, supported types are: %v",
+			backendType, BackendTypes())
+	}
+	backend, err := factory(params)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return backend, nil
+}
+
+// HasBackend returns true if the backend of the specified type has been registered
+func HasBackend(backendType string) bool {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	_, ok := backends[backendType]
+	return ok
+}
+
+// BackendTypes returns the sorted list of registered backend types
+func BackendTypes() (types []string) {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	for backendType := range backends {
+		types = append(types, backendType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+var (
+	backendsMu sync.RWMutex
+	backends   = make(map[string]BackendFactory)
+)
@@ -0,0 +1,425 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/utils"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	teleutils "github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/trace"
+)
+
+// BackupSchedule defines the schedule of the periodic backups of
+// the cluster etcd database, the cluster state and the registry metadata
+type BackupSchedule interface {
+	// Resource provides common resource methods
+	teleservices.Resource
+	// CheckAndSetDefaults verifies that the object is valid
+	CheckAndSetDefaults() error
+	// GetSchedule returns the backup schedule in cron format
+	GetSchedule() string
+	// GetDirectory returns the directory the backups are written to
+	GetDirectory() string
+	// GetRetain returns the number of the most recent backups to keep
+	GetRetain() int
+	// GetDestination returns the optional off-site destination
+	// the backups are uploaded to
+	GetDestination() *BackupDestination
+	// Next returns the time of the first backup after t
+	Next(t time.Time) time.Time
+}
+
+// NewBackupSchedule creates a new backup schedule resource
+func NewBackupSchedule(spec BackupScheduleSpecV2) BackupSchedule {
+	return &BackupScheduleV2{
+		Kind:    KindBackupSchedule,
+		Version: teleservices.V2,
+		Metadata: teleservices.Metadata{
+			Name:      KindBackupSchedule,
+			Namespace: defaults.Namespace,
+		},
+		Spec: spec,
+	}
+}
+
+// BackupScheduleV2 defines the backup schedule resource
+type BackupScheduleV2 struct {
+	// Metadata is resource metadata
+	teleservices.Metadata `json:"metadata"`
+	// Kind is a resource kind
+	Kind string `json:"kind"`
+	// Version is a resource version
+	Version string `json:"version"`
+	// Spec defines the backup schedule
+	Spec BackupScheduleSpecV2 `json:"spec"`
+}
+
+// GetSchedule returns the backup schedule in cron format
+func (r *BackupScheduleV2) GetSchedule() string {
+	return r.Spec.Schedule
+}
+
+// GetDirectory returns the directory the backups are written to
+func (r *BackupScheduleV2) GetDirectory() string {
+	if r.Spec.Directory == "" {
+		return defaults.ScheduledBackupDir
+	}
+	return r.Spec.Directory
+}
+
+// GetRetain returns the number of the most recent backups to keep
+func (r *BackupScheduleV2) GetRetain() int {
+	if r.Spec.Retain == 0 {
+		return defaults.BackupRetain
+	}
+	return r.Spec.Retain
+}
+
+// GetDestination returns the optional off-site destination
+// the backups are uploaded to
+func (r *BackupScheduleV2) GetDestination() *BackupDestination {
+	return r.Spec.Destination
+}
+
+// Next returns the time of the first backup after t.
+// The schedule is evaluated in UTC
+func (r *BackupScheduleV2) Next(t time.Time) time.Time {
+	schedule, err := utils.ParseCronSchedule(r.Spec.Schedule)
+	if err != nil {
+		return time.Time{}
+	}
+	return schedule.Next(t.UTC())
+}
+
+// CheckAndSetDefaults checks validity of all parameters and sets defaults
+func (r *BackupScheduleV2) CheckAndSetDefaults() error {
+	if r.Metadata.Name == "" {
+		r.Metadata.Name = KindBackupSchedule
+	}
+	if err := r.Metadata.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	if r.Spec.Schedule == "" {
+		return trace.BadParameter("backup schedule should specify the schedule in cron format")
+	}
+	if _, err := utils.ParseCronSchedule(r.Spec.Schedule); err != nil {
+		return trace.Wrap(err)
+	}
+	if r.Spec.Directory != "" && !filepath.IsAbs(r.Spec.Directory) {
+		return trace.BadParameter("backup directory %q must be an absolute path", r.Spec.Directory)
+	}
+	if r.Spec.Retain < 0 {
+		return trace.BadParameter("number of retained backups can not be negative")
+	}
+	if r.Spec.Destination != nil {
+		if err := r.Spec.Destination.Check(); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// UnmarshalBackupSchedule unmarshals backup schedule from JSON
+func UnmarshalBackupSchedule(data []byte) (BackupSchedule, error) {
+	if len(data) == 0 {
+		return nil, trace.BadParameter("empty backup schedule")
+	}
+
+	jsonData, err := teleutils.ToJSON(data)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var hdr teleservices.ResourceHeader
+	err = json.Unmarshal(jsonData, &hdr)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	switch hdr.Version {
+	case teleservices.V2:
+		var schedule BackupScheduleV2
+		err := teleutils.UnmarshalWithSchema(GetBackupScheduleSchema(), &schedule, jsonData)
+		if err != nil {
+			return nil, trace.BadParameter("%v", err)
+		}
+		if err := schedule.CheckAndSetDefaults(); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &schedule, nil
+	}
+	return nil, trace.BadParameter(
+		"%v resource version %q is not supported", KindBackupSchedule, hdr.Version)
+}
+
+// MarshalBackupSchedule marshals backup schedule into JSON
+func MarshalBackupSchedule(schedule BackupSchedule, opts ...teleservices.MarshalOption) ([]byte, error) {
+	return json.Marshal(schedule)
+}
+
+// BackupScheduleSpecV2 defines the backup schedule
+type BackupScheduleSpecV2 struct {
+	// Schedule is the backup schedule in the standard cron format, e.g. "0 2 * * *"
+	Schedule string `json:"schedule"`
+	// Directory is the directory on the master nodes the backups are written to
+	Directory string `json:"directory,omitempty"`
+	// Retain is the number of the most recent backups to keep
+	Retain int `json:"retain,omitempty"`
+	// Destination is the optional off-site destination the backups
+	// are uploaded to in addition to the local directory
+	Destination *BackupDestination `json:"destination,omitempty"`
+}
+
+// BackupDestination describes the off-site storage the backups
+// are uploaded to. The uploaded backups are encrypted with the key
+// from the destination secret
+type BackupDestination struct {
+	// Type is the destination type, one of s3, sftp or nfs
+	Type string `json:"type"`
+	// Secret is the name of the Kubernetes secret in the kube-system namespace
+	// with the encryption key and the destination credentials
+	Secret string `json:"secret"`
+	// S3 describes the bucket in S3-compatible object storage
+	S3 *BackupDestinationS3 `json:"s3,omitempty"`
+	// SFTP describes the directory on the SFTP server
+	SFTP *BackupDestinationSFTP `json:"sftp,omitempty"`
+	// NFS describes the directory on the NFS share
+	NFS *BackupDestinationNFS `json:"nfs,omitempty"`
+}
+
+// BackupDestinationS3 describes the bucket in S3-compatible object storage
+type BackupDestinationS3 struct {
+	// Bucket is the name of the bucket
+	Bucket string `json:"bucket"`
+	// Prefix is an optional key prefix for the backups in the bucket
+	Prefix string `json:"prefix,omitempty"`
+	// Region is the bucket region
+	Region string `json:"region,omitempty"`
+	// Endpoint is an optional endpoint of the S3-compatible storage
+	Endpoint string `json:"endpoint,omitempty"`
+	// ForcePathStyle forces the path-style bucket addressing which
+	// is required by some S3-compatible storage
+	ForcePathStyle bool `json:"force_path_style,omitempty"`
+}
+
+// BackupDestinationSFTP describes the directory on the SFTP server
+type BackupDestinationSFTP struct {
+	// Addr is the address of the SFTP server in the host:port format
+	Addr string `json:"addr"`
+	// User is the name of the SSH user
+	User string `json:"user"`
+	// Path is the directory on the server to upload the backups to
+	Path string `json:"path"`
+	// HostKey is the optional SSH host key of the server
+	// in the authorized_keys format
+	HostKey string `json:"host_key,omitempty"`
+}
+
+// BackupDestinationNFS describes the directory on the NFS share
+type BackupDestinationNFS struct {
+	// Path is the directory on the NFS share mounted on the master nodes
+	Path string `json:"path"`
+}
+
+// Check makes sure the destination is valid
+func (r BackupDestination) Check() error {
+	if r.Secret == "" {
+		return trace.BadParameter("backup destination should specify the secret with the encryption key")
+	}
+	switch r.Type {
+	case BackupDestinationS3Type:
+		if r.S3 == nil || r.S3.Bucket == "" {
+			return trace.BadParameter("s3 backup destination should specify the bucket")
+		}
+	case BackupDestinationSFTPType:
+		if r.SFTP == nil || r.SFTP.Addr == "" || r.SFTP.User == "" || r.SFTP.Path == "" {
+			return trace.BadParameter("sftp backup destination should specify the server address, user and path")
+		}
+	case BackupDestinationNFSType:
+		if r.NFS == nil || !filepath.IsAbs(r.NFS.Path) {
+			return trace.BadParameter("nfs backup destination should specify the absolute path to the share")
+		}
+	default:
+		return trace.BadParameter("unsupported backup destination type %q, supported are: %v, %v, %v",
+			r.Type, BackupDestinationS3Type, BackupDestinationSFTPType, BackupDestinationNFSType)
+	}
+	return nil
+}
+
+// String returns the location of the destination
+func (r BackupDestination) String() string {
+	switch {
+	case r.S3 != nil:
+		return fmt.Sprintf("s3://%v", path.Join(r.S3.Bucket, r.S3.Prefix))
+	case r.SFTP != nil:
+		return fmt.Sprintf("sftp://%v@%v%v", r.SFTP.User, r.SFTP.Addr, path.Join("/", r.SFTP.Path))
+	case r.NFS != nil:
+		return fmt.Sprintf("nfs:%v", r.NFS.Path)
+	}
+	return r.Type
+}
+
+const (
+	// BackupDestinationS3Type is the S3-compatible object storage destination
+	BackupDestinationS3Type = "s3"
+	// BackupDestinationSFTPType is the SFTP server destination
+	BackupDestinationSFTPType = "sftp"
+	// BackupDestinationNFSType is the NFS share destination
+	BackupDestinationNFSType = "nfs"
+)
+
+// BackupScheduleSpecV2Schema is JSON schema for the backup schedule
+const BackupScheduleSpecV2Schema = `{
+  "type": "object",
+  "additionalProperties": false,
+  "required": ["schedule"],
+  "properties": {
+    "schedule": {"type": "string"},
+    "directory": {"type": "string"},
+    "retain": {"type": "integer"},
+    "destination": ` + BackupDestinationSchema + `
+  }
+}`
+
+// BackupDestinationSchema is JSON schema for the backup destination
+const BackupDestinationSchema = `{
+  "type": "object",
+  "additionalProperties": false,
+  "required": ["type", "secret"],
+  "properties": {
+    "type": {"type": "string"},
+    "secret": {"type": "string"},
+    "s3": {
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["bucket"],
+      "properties": {
+        "bucket": {"type": "string"},
+        "prefix": {"type": "string"},
+        "region": {"type": "string"},
+        "endpoint": {"type": "string"},
+        "force_path_style": {"type": "boolean"}
+      }
+    },
+    "sftp": {
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["addr", "user", "path"],
+      "properties": {
+        "addr": {"type": "string"},
+        "user": {"type": "string"},
+        "path": {"type": "string"},
+        "host_key": {"type": "string"}
+      }
+    },
+    "nfs": {
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["path"],
+      "properties": {
+        "path": {"type": "string"}
+      }
+    }
+  }
+}`
+
+// GetBackupScheduleSchema returns backup schedule schema for version V2
+func GetBackupScheduleSchema() string {
+	return fmt.Sprintf(teleservices.V2SchemaTemplate, MetadataSchema,
+		BackupScheduleSpecV2Schema, "")
+}
+
+// BackupStatus describes the outcome of the scheduled cluster backups
+type BackupStatus struct {
+	// LastAttempt is the time of the last backup attempt
+	LastAttempt time.Time `json:"last_attempt"`
+	// LastBackup is the time the last successful backup completed
+	LastBackup time.Time `json:"last_backup,omitempty"`
+	// Path is the path to the last successful backup
+	Path string `json:"path,omitempty"`
+	// Size is the size of the last successful backup in bytes
+	Size int64 `json:"size,omitempty"`
+	// Node is the name of the node the last successful backup is stored on
+	Node string `json:"node,omitempty"`
+	// Message describes the error encountered during the last attempt
+	Message string `json:"message,omitempty"`
+	// Apps lists the outcome of the application backup hooks
+	// during the last attempt
+	Apps []BackupAppStatus `json:"apps,omitempty"`
+	// Uploaded is the location of the last backup uploaded
+	// to the off-site destination
+	Uploaded string `json:"uploaded,omitempty"`
+	// UploadMessage describes the error encountered uploading
+	// the last backup to the off-site destination
+	UploadMessage string `json:"upload_message,omitempty"`
+}
+
+// IsHealthy returns true if the last backup attempt succeeded,
+// backed up the data of all applications and uploaded the backup
+func (r BackupStatus) IsHealthy() bool {
+	if r.Message != "" || r.UploadMessage != "" {
+		return false
+	}
+	for _, app := range r.Apps {
+		if app.Message != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// BackupAppStatus describes the outcome of the backup hook
+// of a single application
+type BackupAppStatus struct {
+	// App is the application package
+	App string `json:"app"`
+	// Message describes the error encountered by the backup hook
+	Message string `json:"message,omitempty"`
+}
+
+// Age returns the age of the last successful backup at the specified time
+// or zero if no backup has completed yet
+func (r BackupStatus) Age(now time.Time) time.Duration {
+	if r.LastBackup.IsZero() {
+		return 0
+	}
+	return now.Sub(r.LastBackup)
+}
+
+// BackupSchedules defines the interface to manage the backup schedule
+// and the status of the scheduled backups
+type BackupSchedules interface {
+	// UpsertBackupSchedule creates or updates the backup schedule
+	// of the specified cluster
+	UpsertBackupSchedule(clusterName string, schedule BackupSchedule) error
+	// GetBackupSchedule returns the backup schedule of the specified cluster
+	GetBackupSchedule(clusterName string) (BackupSchedule, error)
+	// DeleteBackupSchedule deletes the backup schedule of the specified cluster
+	DeleteBackupSchedule(clusterName string) error
+	// UpsertBackupStatus updates the status of the scheduled backups
+	UpsertBackupStatus(clusterName string, status BackupStatus) error
+	// GetBackupStatus returns the status of the scheduled backups
+	GetBackupStatus(clusterName string) (*BackupStatus, error)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"time"
+
+	"github.com/gravitational/gravity/lib/compare"
+	"github.com/gravitational/gravity/lib/defaults"
+
+	"github.com/gravitational/trace"
+	check "gopkg.in/check.v1"
+)
+
+type BackupScheduleSuite struct{}
+
+var _ = check.Suite(&BackupScheduleSuite{})
+
+func (s *BackupScheduleSuite) TestResourceParsing(c *check.C) {
+	spec := `kind: backupschedule
+version: v2
+spec:
+  schedule: "0 2 * * *"
+  directory: /backups
+  retain: 14
+`
+	schedule, err := UnmarshalBackupSchedule([]byte(spec))
+	c.Assert(err, check.IsNil)
+	c.Assert(schedule, compare.DeepEquals, NewBackupSchedule(BackupScheduleSpecV2{
+		Schedule:  "0 2 * * *",
+		Directory: "/backups",
+		Retain:    14,
+	}))
+	now := time.Date(2019, time.June, 1, 10, 30, 0, 0, time.UTC)
+	c.Assert(schedule.Next(now), check.DeepEquals, time.Date(2019, time.June, 2, 2, 0, 0, 0, time.UTC))
+
+	data, err := MarshalBackupSchedule(schedule)
+	c.Assert(err, check.IsNil)
+	decoded, err := UnmarshalBackupSchedule(data)
+	c.Assert(err, check.IsNil)
+	c.Assert(decoded, compare.DeepEquals, schedule)
+}
+
+func (s *BackupScheduleSuite) TestParsesDestination(c *check.C) {
+	spec := `kind: backupschedule
+version: v2
+spec:
+  schedule: "0 2 * * *"
+  destination:
+    type: s3
+    secret: backups
+    s3:
+      bucket: backups
+      prefix: example.com
+      region: us-east-1
+`
+	schedule, err := UnmarshalBackupSchedule([]byte(spec))
+	c.Assert(err, check.IsNil)
+	c.Assert(schedule.GetDestination(), compare.DeepEquals, &BackupDestination{
+		Type:   BackupDestinationS3Type,
+		Secret: "backups",
+		S3: &BackupDestinationS3{
+			Bucket: "backups",
+			Prefix: "example.com",
+			Region: "us-east-1",
+		},
+	})
+	c.Assert(schedule.GetDestination().String(), check.Equals, "s3://backups/example.com")
+}
+
+func (s *BackupScheduleSuite) TestDefaults(c *check.C) {
+	schedule := NewBackupSchedule(BackupScheduleSpecV2{Schedule: "@daily"})
+	c.Assert(schedule.CheckAndSetDefaults(), check.IsNil)
+	c.Assert(schedule.GetDirectory(), check.Equals, defaults.ScheduledBackupDir)
+	c.Assert(schedule.GetRetain(), check.Equals, defaults.BackupRetain)
+}
+
+func (s *BackupScheduleSuite) TestValidatesResource(c *check.C) {
+	for _, spec := range []string{
+		`{directory: /backups}`,
+		`{schedule: "0 2 * *"}`,
+		`{schedule: "0 2 * * *", directory: backups}`,
+		`{schedule: "0 2 * * *", retain: -1}`,
+		`{schedule: "0 2 * * *", destination: {type: s3, s3: {bucket: backups}}}`,
+		`{schedule: "0 2 * * *", destination: {type: s3, secret: backups}}`,
+		`{schedule: "0 2 * * *", destination: {type: sftp, secret: backups, sftp: {addr: "sftp:22"}}}`,
+		`{schedule: "0 2 * * *", destination: {type: nfs, secret: backups, nfs: {path: backups}}}`,
+		`{schedule: "0 2 * * *", destination: {type: ftp, secret: backups}}`,
+	} {
+		_, err := UnmarshalBackupSchedule([]byte(`kind: backupschedule
+version: v2
+spec: ` + spec))
+		c.Assert(trace.IsBadParameter(err), check.Equals, true, check.Commentf("%v: %v", spec, err))
+	}
+}
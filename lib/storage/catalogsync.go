@@ -0,0 +1,189 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/utils"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	teleutils "github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/trace"
+)
+
+// CatalogSyncSchedule defines the schedule to mirror the charts of an upstream
+// Helm repository into the local application catalog
+type CatalogSyncSchedule interface {
+	// Resource provides common resource methods
+	teleservices.Resource
+	// CheckAndSetDefaults verifies that the object is valid
+	CheckAndSetDefaults() error
+	// GetRepository returns the URL of the upstream Helm repository
+	GetRepository() string
+	// GetCharts returns the names of the charts to mirror.
+	// All charts are mirrored if the list is empty
+	GetCharts() []string
+	// GetInterval returns how often the repository is synchronized
+	GetInterval() time.Duration
+	// Matches returns true if the chart with the specified name should be mirrored
+	Matches(chart string) bool
+}
+
+// NewCatalogSyncSchedule creates a new catalog sync schedule resource
+func NewCatalogSyncSchedule(spec CatalogSyncScheduleSpecV2) CatalogSyncSchedule {
+	return &CatalogSyncScheduleV2{
+		Kind:    KindCatalogSyncSchedule,
+		Version: teleservices.V2,
+		Metadata: teleservices.Metadata{
+			Name:      KindCatalogSyncSchedule,
+			Namespace: defaults.Namespace,
+		},
+		Spec: spec,
+	}
+}
+
+// CatalogSyncScheduleV2 defines the catalog sync schedule resource
+type CatalogSyncScheduleV2 struct {
+	// Metadata is resource metadata
+	teleservices.Metadata `json:"metadata"`
+	// Kind is a resource kind
+	Kind string `json:"kind"`
+	// Version is a resource version
+	Version string `json:"version"`
+	// Spec defines the catalog sync schedule
+	Spec CatalogSyncScheduleSpecV2 `json:"spec"`
+}
+
+// GetRepository returns the URL of the upstream Helm repository
+func (r *CatalogSyncScheduleV2) GetRepository() string {
+	return r.Spec.Repository
+}
+
+// GetCharts returns the names of the charts to mirror
+func (r *CatalogSyncScheduleV2) GetCharts() []string {
+	return r.Spec.Charts
+}
+
+// GetInterval returns how often the repository is synchronized
+func (r *CatalogSyncScheduleV2) GetInterval() time.Duration {
+	if r.Spec.Interval == nil {
+		return defaults.CatalogSyncInterval
+	}
+	return r.Spec.Interval.Duration
+}
+
+// Matches returns true if the chart with the specified name should be mirrored
+func (r *CatalogSyncScheduleV2) Matches(chart string) bool {
+	return len(r.Spec.Charts) == 0 || utils.StringInSlice(r.Spec.Charts, chart)
+}
+
+// CheckAndSetDefaults checks validity of all parameters and sets defaults
+func (r *CatalogSyncScheduleV2) CheckAndSetDefaults() error {
+	if r.Metadata.Name == "" {
+		r.Metadata.Name = KindCatalogSyncSchedule
+	}
+	if err := r.Metadata.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	if r.Spec.Repository == "" {
+		return trace.BadParameter("catalog sync schedule should specify the repository URL")
+	}
+	u, err := url.Parse(r.Spec.Repository)
+	if err != nil {
+		return trace.BadParameter("invalid repository URL %q: %v", r.Spec.Repository, err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return trace.BadParameter("repository URL %q must be an absolute http(s) URL", r.Spec.Repository)
+	}
+	if r.Spec.Interval != nil && r.Spec.Interval.Duration < defaults.CatalogSyncMinInterval {
+		return trace.BadParameter("catalog sync interval can not be less than %v",
+			defaults.CatalogSyncMinInterval)
+	}
+	return nil
+}
+
+// UnmarshalCatalogSyncSchedule unmarshals catalog sync schedule from JSON
+func UnmarshalCatalogSyncSchedule(data []byte) (CatalogSyncSchedule, error) {
+	if len(data) == 0 {
+		return nil, trace.BadParameter("empty catalog sync schedule")
+	}
+
+	jsonData, err := teleutils.ToJSON(data)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var hdr teleservices.ResourceHeader
+	err = json.Unmarshal(jsonData, &hdr)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	switch hdr.Version {
+	case teleservices.V2:
+		var schedule CatalogSyncScheduleV2
+		err := teleutils.UnmarshalWithSchema(GetCatalogSyncScheduleSchema(), &schedule, jsonData)
+		if err != nil {
+			return nil, trace.BadParameter("%v", err)
+		}
+		if err := schedule.CheckAndSetDefaults(); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &schedule, nil
+	}
+	return nil, trace.BadParameter(
+		"%v resource version %q is not supported", KindCatalogSyncSchedule, hdr.Version)
+}
+
+// MarshalCatalogSyncSchedule marshals catalog sync schedule into JSON
+func MarshalCatalogSyncSchedule(schedule CatalogSyncSchedule, opts ...teleservices.MarshalOption) ([]byte, error) {
+	return json.Marshal(schedule)
+}
+
+// CatalogSyncScheduleSpecV2 defines the catalog sync schedule
+type CatalogSyncScheduleSpecV2 struct {
+	// Repository is the URL of the upstream Helm repository
+	Repository string `json:"repository"`
+	// Charts optionally lists the names of the charts to mirror.
+	// The latest version of every chart in the repository is mirrored if unspecified
+	Charts []string `json:"charts,omitempty"`
+	// Interval is how often the repository is synchronized
+	Interval *teleservices.Duration `json:"interval,omitempty"`
+}
+
+// CatalogSyncScheduleSpecV2Schema is JSON schema for the catalog sync schedule
+const CatalogSyncScheduleSpecV2Schema = `{
+  "type": "object",
+  "additionalProperties": false,
+  "required": ["repository"],
+  "properties": {
+    "repository": {"type": "string"},
+    "charts": {"type": "array", "items": {"type": "string"}},
+    "interval": {"type": "string"}
+  }
+}`
+
+// GetCatalogSyncScheduleSchema returns catalog sync schedule schema for version V2
+func GetCatalogSyncScheduleSchema() string {
+	return fmt.Sprintf(teleservices.V2SchemaTemplate, MetadataSchema,
+		CatalogSyncScheduleSpecV2Schema, "")
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"time"
+
+	"github.com/gravitational/gravity/lib/compare"
+	"github.com/gravitational/gravity/lib/defaults"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/trace"
+	check "gopkg.in/check.v1"
+)
+
+type CatalogSyncScheduleSuite struct{}
+
+var _ = check.Suite(&CatalogSyncScheduleSuite{})
+
+func (s *CatalogSyncScheduleSuite) TestResourceParsing(c *check.C) {
+	spec := `kind: catalogsync
+version: v2
+spec:
+  repository: https://charts.example.com/stable
+  charts: ["nginx", "redis"]
+  interval: 6h
+`
+	schedule, err := UnmarshalCatalogSyncSchedule([]byte(spec))
+	c.Assert(err, check.IsNil)
+	interval := teleservices.NewDuration(6 * time.Hour)
+	c.Assert(schedule, compare.DeepEquals, NewCatalogSyncSchedule(CatalogSyncScheduleSpecV2{
+		Repository: "https://charts.example.com/stable",
+		Charts:     []string{"nginx", "redis"},
+		Interval:   &interval,
+	}))
+	c.Assert(schedule.GetInterval(), check.Equals, 6*time.Hour)
+	c.Assert(schedule.Matches("redis"), check.Equals, true)
+	c.Assert(schedule.Matches("mysql"), check.Equals, false)
+
+	data, err := MarshalCatalogSyncSchedule(schedule)
+	c.Assert(err, check.IsNil)
+	decoded, err := UnmarshalCatalogSyncSchedule(data)
+	c.Assert(err, check.IsNil)
+	c.Assert(decoded, compare.DeepEquals, schedule)
+}
+
+func (s *CatalogSyncScheduleSuite) TestMirrorsAllChartsByDefault(c *check.C) {
+	schedule := NewCatalogSyncSchedule(CatalogSyncScheduleSpecV2{
+		Repository: "https://charts.example.com",
+	})
+	c.Assert(schedule.CheckAndSetDefaults(), check.IsNil)
+	c.Assert(schedule.Matches("mysql"), check.Equals, true)
+	c.Assert(schedule.GetInterval(), check.Equals, defaults.CatalogSyncInterval)
+}
+
+func (s *CatalogSyncScheduleSuite) TestValidatesResource(c *check.C) {
+	for _, spec := range []string{
+		`{repository: "charts.example.com"}`,
+		`{repository: "ftp://charts.example.com"}`,
+		`{repository: "https://charts.example.com", interval: 1m}`,
+	} {
+		_, err := UnmarshalCatalogSyncSchedule([]byte(`kind: catalogsync
+version: v2
+spec: ` + spec))
+		c.Assert(trace.IsBadParameter(err), check.Equals, true, check.Commentf("%v: %v", spec, err))
+	}
+}
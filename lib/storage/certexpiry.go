@@ -0,0 +1,154 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gravitational/gravity/lib/constants"
+)
+
+// CertificateExpiryStatus describes the expiration of the certificates
+// managed by Gravity
+type CertificateExpiryStatus struct {
+	// Updated is the time the certificates were inspected
+	Updated time.Time `json:"updated"`
+	// Warning is the time before the expiration the certificate
+	// is reported as expiring
+	Warning time.Duration `json:"warning"`
+	// Critical is the time before the expiration the cluster
+	// is considered degraded
+	Critical time.Duration `json:"critical"`
+	// Certificates lists the inspected certificates
+	Certificates []CertificateExpiry `json:"certificates,omitempty"`
+	// Errors lists the errors encountered inspecting the certificates
+	Errors []string `json:"errors,omitempty"`
+}
+
+// CertificateExpiry describes the expiration of a single certificate
+type CertificateExpiry struct {
+	// Source is where the certificate is used: ca, planet, rpc, web or teleport
+	Source string `json:"source"`
+	// Name is the name of the certificate within the source, e.g. apiserver
+	Name string `json:"name"`
+	// Node is the hostname of the node the certificate is issued
+	// for, empty for the cluster-wide certificates
+	Node string `json:"node,omitempty"`
+	// Subject is the certificate subject common name
+	Subject string `json:"subject,omitempty"`
+	// NotAfter is the certificate expiration time
+	NotAfter time.Time `json:"not_after"`
+}
+
+// String returns a textual representation of the certificate
+func (r CertificateExpiry) String() string {
+	if r.Node == "" {
+		return fmt.Sprintf("%v %v", r.Source, r.Name)
+	}
+	return fmt.Sprintf("%v %v on %v", r.Source, r.Name, r.Node)
+}
+
+// ExpiresIn returns the time left until the certificate expires
+// as of the specified time
+func (r CertificateExpiry) ExpiresIn(now time.Time) time.Duration {
+	return r.NotAfter.Sub(now)
+}
+
+// Expiring returns the certificates that expire within the warning
+// period as of the specified time, sorted by the expiration time
+func (r CertificateExpiryStatus) Expiring(now time.Time) (certificates []CertificateExpiry) {
+	for _, cert := range r.Certificates {
+		if cert.ExpiresIn(now) < r.Warning {
+			certificates = append(certificates, cert)
+		}
+	}
+	sort.Slice(certificates, func(i, j int) bool {
+		return certificates[i].NotAfter.Before(certificates[j].NotAfter)
+	})
+	return certificates
+}
+
+// Next returns the certificate that expires first or nil
+// if there are no certificates
+func (r CertificateExpiryStatus) Next() *CertificateExpiry {
+	var next *CertificateExpiry
+	for i, cert := range r.Certificates {
+		if next == nil || cert.NotAfter.Before(next.NotAfter) {
+			next = &r.Certificates[i]
+		}
+	}
+	return next
+}
+
+// CertificateProblem describes a certificate that has expired or is about to
+type CertificateProblem struct {
+	// Certificate is the expiring certificate
+	Certificate CertificateExpiry `json:"certificate"`
+	// Critical is true if the certificate expires within the critical period
+	Critical bool `json:"critical,omitempty"`
+	// Message describes the problem
+	Message string `json:"message"`
+}
+
+// String returns a textual representation of the problem
+func (r CertificateProblem) String() string {
+	return fmt.Sprintf("%v: %v", r.Certificate, r.Message)
+}
+
+// Problems returns the problems with the certificates that expire
+// within the warning period as of the specified time
+func (r CertificateExpiryStatus) Problems(now time.Time) (problems []CertificateProblem) {
+	for _, cert := range r.Expiring(now) {
+		expiresIn := cert.ExpiresIn(now)
+		problem := CertificateProblem{
+			Certificate: cert,
+			Critical:    expiresIn < r.Critical,
+			Message: fmt.Sprintf("expires in %v on %v", FormatExpiresIn(expiresIn),
+				cert.NotAfter.UTC().Format(constants.HumanDateFormat)),
+		}
+		if expiresIn <= 0 {
+			problem.Message = fmt.Sprintf("expired on %v",
+				cert.NotAfter.UTC().Format(constants.HumanDateFormat))
+		}
+		problems = append(problems, problem)
+	}
+	return problems
+}
+
+// FormatExpiresIn formats the time left until a certificate expires
+// in days, or hours if less than a day is left
+func FormatExpiresIn(d time.Duration) string {
+	if d < 24*time.Hour {
+		return fmt.Sprintf("%vh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%vd", int(d.Hours()/24))
+}
+
+const (
+	// CertificateSourceCA is the source of the cluster certificate authority
+	CertificateSourceCA = "ca"
+	// CertificateSourcePlanet is the source of the node runtime certificates
+	CertificateSourcePlanet = "planet"
+	// CertificateSourceRPC is the source of the RPC agent credentials
+	CertificateSourceRPC = "rpc"
+	// CertificateSourceWeb is the source of the web UI and API certificate
+	CertificateSourceWeb = "web"
+	// CertificateSourceTeleport is the source of the Teleport certificate authorities
+	CertificateSourceTeleport = "teleport"
+)
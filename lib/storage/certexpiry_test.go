@@ -0,0 +1,52 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"time"
+
+	check "gopkg.in/check.v1"
+)
+
+type CertificateExpirySuite struct{}
+
+var _ = check.Suite(&CertificateExpirySuite{})
+
+func (s *CertificateExpirySuite) TestFindsProblems(c *check.C) {
+	now := time.Date(2019, time.June, 1, 12, 0, 0, 0, time.UTC)
+	status := CertificateExpiryStatus{
+		Updated:  now,
+		Warning:  30 * 24 * time.Hour,
+		Critical: 7 * 24 * time.Hour,
+		Certificates: []CertificateExpiry{
+			{Source: CertificateSourceCA, Name: "root", NotAfter: now.Add(10 * 365 * 24 * time.Hour)},
+			{Source: CertificateSourcePlanet, Name: "apiserver", Node: "node-1", NotAfter: now.Add(20 * 24 * time.Hour)},
+			{Source: CertificateSourceWeb, Name: "cluster-tls", NotAfter: now.Add(5 * time.Hour)},
+			{Source: CertificateSourceRPC, Name: "server", NotAfter: now.Add(-time.Hour)},
+		},
+	}
+	c.Assert(status.Next().String(), check.Equals, "rpc server")
+
+	problems := status.Problems(now)
+	c.Assert(problems, check.HasLen, 3)
+	c.Assert(problems[0].String(), check.Equals, "rpc server: expired on Sat Jun  1 11:00 UTC")
+	c.Assert(problems[0].Critical, check.Equals, true)
+	c.Assert(problems[1].Message, check.Matches, "expires in 5h on .*")
+	c.Assert(problems[1].Critical, check.Equals, true)
+	c.Assert(problems[2].String(), check.Matches, "planet apiserver on node-1: expires in 20d on .*")
+	c.Assert(problems[2].Critical, check.Equals, false)
+}
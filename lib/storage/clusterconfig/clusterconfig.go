@@ -19,6 +19,8 @@ package clusterconfig
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/gravitational/gravity/lib/constants"
@@ -41,6 +43,34 @@ type Interface interface {
 	GetGlobalConfig() *Global
 	// SetCloudProvider sets the cloud provider for this configuration
 	SetCloudProvider(provider string)
+	// SetSubnets sets the pod and service subnets for this configuration
+	SetSubnets(podCIDR, serviceCIDR string)
+	// IsFIPS returns true if the cluster runs in FIPS mode
+	IsFIPS() bool
+	// SetFIPS sets the FIPS mode for this configuration
+	SetFIPS(enabled bool)
+	// GetHardeningProfile returns the name of the hardening profile
+	// the cluster has been installed with
+	GetHardeningProfile() string
+	// SetHardeningProfile sets the hardening profile for this configuration
+	SetHardeningProfile(profile string)
+	// IsNetworkPolicyEnabled returns true if the cluster has been installed
+	// with the network policy baseline
+	IsNetworkPolicyEnabled() bool
+	// SetNetworkPolicy sets whether the cluster is installed with the
+	// network policy baseline
+	SetNetworkPolicy(enabled bool)
+	// GetResourcePressure returns the node resource pressure thresholds
+	GetResourcePressure() ResourcePressure
+	// GetCertificateExpiry returns the certificate expiration thresholds
+	GetCertificateExpiry() CertificateExpiry
+	// GetCertificateRotation returns the automatic certificate rotation configuration
+	GetCertificateRotation() CertificateRotation
+	// GetAudit returns the Kubernetes API audit configuration
+	GetAudit() Audit
+	// GetEncryption returns the configuration of the encryption of
+	// Kubernetes secrets at rest or nil if the secrets are not encrypted
+	GetEncryption() *Encryption
 }
 
 // New returns a new instance of the resource initialized to specified spec
@@ -109,6 +139,33 @@ func (r *Resource) GetGlobalConfig() *Global {
 	return r.Spec.Global
 }
 
+// GetResourcePressure returns the node resource pressure thresholds
+// with defaults applied to the thresholds that are not configured
+func (r *Resource) GetResourcePressure() ResourcePressure {
+	if r.Spec.ResourcePressure == nil {
+		return DefaultResourcePressure()
+	}
+	return r.Spec.ResourcePressure.withDefaults()
+}
+
+// GetCertificateExpiry returns the certificate expiration thresholds
+// with defaults applied to the thresholds that are not configured
+func (r *Resource) GetCertificateExpiry() CertificateExpiry {
+	if r.Spec.CertificateExpiry == nil {
+		return DefaultCertificateExpiry()
+	}
+	return r.Spec.CertificateExpiry.withDefaults()
+}
+
+// GetCertificateRotation returns the automatic certificate rotation
+// configuration with defaults applied
+func (r *Resource) GetCertificateRotation() CertificateRotation {
+	if r.Spec.CertificateRotation == nil {
+		return DefaultCertificateRotation()
+	}
+	return r.Spec.CertificateRotation.withDefaults()
+}
+
 // SetCloudProvider sets the cloud provider for this configuration
 func (r *Resource) SetCloudProvider(provider string) {
 	if r.Spec.Global == nil {
@@ -117,6 +174,63 @@ func (r *Resource) SetCloudProvider(provider string) {
 	r.Spec.Global.CloudProvider = provider
 }
 
+// SetSubnets sets the pod and service subnets for this configuration
+func (r *Resource) SetSubnets(podCIDR, serviceCIDR string) {
+	if r.Spec.Global == nil {
+		r.Spec.Global = &Global{}
+	}
+	r.Spec.Global.PodCIDR = podCIDR
+	r.Spec.Global.ServiceCIDR = serviceCIDR
+}
+
+// IsFIPS returns true if the cluster runs in FIPS mode
+func (r *Resource) IsFIPS() bool {
+	return r.Spec.Global != nil && r.Spec.Global.FIPS
+}
+
+// SetFIPS sets the FIPS mode for this configuration
+func (r *Resource) SetFIPS(enabled bool) {
+	if r.Spec.Global == nil {
+		r.Spec.Global = &Global{}
+	}
+	r.Spec.Global.FIPS = enabled
+}
+
+// GetHardeningProfile returns the name of the hardening profile
+// the cluster has been installed with
+func (r *Resource) GetHardeningProfile() string {
+	if r.Spec.Global == nil {
+		return ""
+	}
+	return r.Spec.Global.Hardening
+}
+
+// SetHardeningProfile sets the hardening profile for this configuration
+func (r *Resource) SetHardeningProfile(profile string) {
+	if r.Spec.Global == nil {
+		r.Spec.Global = &Global{}
+	}
+	r.Spec.Global.Hardening = profile
+}
+
+// IsNetworkPolicyEnabled returns true if the cluster has been installed
+// with the network policy baseline
+func (r *Resource) IsNetworkPolicyEnabled() bool {
+	if r.Spec.Global == nil {
+		return false
+	}
+	return r.Spec.Global.NetworkPolicy
+}
+
+// SetNetworkPolicy sets whether the cluster is installed with the
+// network policy baseline
+func (r *Resource) SetNetworkPolicy(enabled bool) {
+	if r.Spec.Global == nil {
+		r.Spec.Global = &Global{}
+	}
+	r.Spec.Global.NetworkPolicy = enabled
+}
+
 // Unmarshal unmarshals the resource from either YAML- or JSON-encoded data
 func Unmarshal(data []byte) (*Resource, error) {
 	if len(data) == 0 {
@@ -145,6 +259,36 @@ func Unmarshal(data []byte) (*Resource, error) {
 		if config.Metadata.Expires != nil {
 			teleutils.UTC(config.Metadata.Expires)
 		}
+		if config.Spec.Global != nil {
+			if err := config.Spec.Global.CheckProxy(); err != nil {
+				return nil, trace.Wrap(err)
+			}
+		}
+		if config.Spec.ResourcePressure != nil {
+			if err := config.Spec.ResourcePressure.Check(); err != nil {
+				return nil, trace.Wrap(err)
+			}
+		}
+		if config.Spec.CertificateExpiry != nil {
+			if err := config.Spec.CertificateExpiry.Check(); err != nil {
+				return nil, trace.Wrap(err)
+			}
+		}
+		if config.Spec.CertificateRotation != nil {
+			if err := config.Spec.CertificateRotation.Check(); err != nil {
+				return nil, trace.Wrap(err)
+			}
+		}
+		if config.Spec.Audit != nil {
+			if err := config.Spec.Audit.Check(); err != nil {
+				return nil, trace.Wrap(err)
+			}
+		}
+		if config.Spec.Encryption != nil {
+			if err := config.Spec.Encryption.Check(); err != nil {
+				return nil, trace.Wrap(err)
+			}
+		}
 		return &config, nil
 	}
 	return nil, trace.BadParameter(
@@ -180,8 +324,426 @@ type Spec struct {
 	// TODO: Scheduler, ControllerManager, Proxy
 	// Global describes global configuration
 	Global *Global `json:"global,omitempty"`
+	// ResourcePressure defines the node resource pressure thresholds
+	ResourcePressure *ResourcePressure `json:"resourcePressure,omitempty"`
+	// CertificateExpiry defines the certificate expiration thresholds
+	CertificateExpiry *CertificateExpiry `json:"certificateExpiry,omitempty"`
+	// CertificateRotation defines the automatic rotation of the certificates
+	CertificateRotation *CertificateRotation `json:"certificateRotation,omitempty"`
+	// Audit defines the Kubernetes API audit configuration
+	Audit *Audit `json:"audit,omitempty"`
+	// Encryption defines the encryption of Kubernetes secrets at rest
+	Encryption *Encryption `json:"encryption,omitempty"`
+}
+
+// ResourcePressure defines the thresholds of the node resource usage
+// reported by the pre-flight checks and cluster status
+type ResourcePressure struct {
+	// Disk is the usage of the disk space on the state directories
+	Disk *Threshold `json:"disk,omitempty"`
+	// Inodes is the usage of the inodes on the state directories
+	Inodes *Threshold `json:"inodes,omitempty"`
+	// Conntrack is the usage of the connection tracking table
+	Conntrack *Threshold `json:"conntrack,omitempty"`
+	// PIDs is the usage of the process IDs
+	PIDs *Threshold `json:"pids,omitempty"`
+}
+
+// Threshold defines the resource usage thresholds in percent
+type Threshold struct {
+	// Warning is the usage above which the resource is reported as under pressure
+	Warning int `json:"warning,omitempty"`
+	// Critical is the usage above which the node is considered degraded
+	Critical int `json:"critical,omitempty"`
+}
+
+// DefaultResourcePressure returns the default node resource pressure thresholds
+func DefaultResourcePressure() ResourcePressure {
+	return ResourcePressure{
+		Disk:      &Threshold{Warning: 80, Critical: 90},
+		Inodes:    &Threshold{Warning: 80, Critical: 90},
+		Conntrack: &Threshold{Warning: 75, Critical: 90},
+		PIDs:      &Threshold{Warning: 75, Critical: 90},
+	}
+}
+
+// Check validates the thresholds
+func (r ResourcePressure) Check() error {
+	for name, threshold := range map[string]*Threshold{
+		"disk":      r.Disk,
+		"inodes":    r.Inodes,
+		"conntrack": r.Conntrack,
+		"pids":      r.PIDs,
+	} {
+		if threshold == nil {
+			continue
+		}
+		if threshold.Warning < 0 || threshold.Warning > 100 ||
+			threshold.Critical < 0 || threshold.Critical > 100 {
+			return trace.BadParameter("%v thresholds should be between 0 and 100 percent", name)
+		}
+		if threshold.Warning != 0 && threshold.Critical != 0 && threshold.Warning > threshold.Critical {
+			return trace.BadParameter("%v warning threshold %v%% exceeds critical threshold %v%%",
+				name, threshold.Warning, threshold.Critical)
+		}
+	}
+	return nil
+}
+
+// withDefaults returns a copy of the thresholds with defaults applied
+// to the thresholds that are not configured
+func (r ResourcePressure) withDefaults() ResourcePressure {
+	defaults := DefaultResourcePressure()
+	return ResourcePressure{
+		Disk:      r.Disk.withDefaults(*defaults.Disk),
+		Inodes:    r.Inodes.withDefaults(*defaults.Inodes),
+		Conntrack: r.Conntrack.withDefaults(*defaults.Conntrack),
+		PIDs:      r.PIDs.withDefaults(*defaults.PIDs),
+	}
+}
+
+func (r *Threshold) withDefaults(defaults Threshold) *Threshold {
+	if r == nil {
+		return &defaults
+	}
+	threshold := *r
+	if threshold.Warning == 0 {
+		threshold.Warning = defaults.Warning
+	}
+	if threshold.Critical == 0 {
+		threshold.Critical = defaults.Critical
+	}
+	return &threshold
+}
+
+// CertificateExpiry defines how long before the expiration the certificates
+// managed by Gravity are reported by the cluster status
+type CertificateExpiry struct {
+	// Warning is the time before the expiration the certificate
+	// is reported as expiring
+	Warning *teleservices.Duration `json:"warning,omitempty"`
+	// Critical is the time before the expiration the cluster
+	// is considered degraded
+	Critical *teleservices.Duration `json:"critical,omitempty"`
+}
+
+// DefaultCertificateExpiry returns the default certificate expiration thresholds
+func DefaultCertificateExpiry() CertificateExpiry {
+	warning := teleservices.NewDuration(defaults.CertificateExpiryWarning)
+	critical := teleservices.NewDuration(defaults.CertificateExpiryCritical)
+	return CertificateExpiry{
+		Warning:  &warning,
+		Critical: &critical,
+	}
+}
+
+// Check validates the thresholds
+func (r CertificateExpiry) Check() error {
+	if (r.Warning != nil && r.Warning.Duration < 0) ||
+		(r.Critical != nil && r.Critical.Duration < 0) {
+		return trace.BadParameter("certificate expiration thresholds can not be negative")
+	}
+	if r.Warning != nil && r.Critical != nil && r.Warning.Duration < r.Critical.Duration {
+		return trace.BadParameter("certificate expiration warning threshold %v is shorter than critical threshold %v",
+			r.Warning.Duration, r.Critical.Duration)
+	}
+	return nil
+}
+
+// WarningPeriod returns the time before the expiration the certificate
+// is reported as expiring
+func (r CertificateExpiry) WarningPeriod() time.Duration {
+	if r.Warning == nil {
+		return 0
+	}
+	return r.Warning.Duration
+}
+
+// CriticalPeriod returns the time before the expiration the cluster
+// is considered degraded
+func (r CertificateExpiry) CriticalPeriod() time.Duration {
+	if r.Critical == nil {
+		return 0
+	}
+	return r.Critical.Duration
+}
+
+// withDefaults returns a copy of the thresholds with defaults applied
+// to the thresholds that are not configured
+func (r CertificateExpiry) withDefaults() CertificateExpiry {
+	defaults := DefaultCertificateExpiry()
+	if r.Warning == nil {
+		r.Warning = defaults.Warning
+	}
+	if r.Critical == nil {
+		r.Critical = defaults.Critical
+	}
+	return r
+}
+
+// CertificateRotation defines the automatic rotation of the runtime
+// certificates of the cluster nodes
+type CertificateRotation struct {
+	// Enabled specifies whether the certificates are rotated automatically
+	Enabled bool `json:"enabled,omitempty"`
+	// RenewBefore is the time before the expiration the certificates are rotated
+	RenewBefore *teleservices.Duration `json:"renewBefore,omitempty"`
+}
+
+// DefaultCertificateRotation returns the default certificate rotation configuration
+func DefaultCertificateRotation() CertificateRotation {
+	renewBefore := teleservices.NewDuration(defaults.CertificateRenewBefore)
+	return CertificateRotation{
+		RenewBefore: &renewBefore,
+	}
+}
+
+// Check validates the configuration
+func (r CertificateRotation) Check() error {
+	if r.RenewBefore != nil && r.RenewBefore.Duration <= 0 {
+		return trace.BadParameter("certificate renewal period should be positive, got %v",
+			r.RenewBefore.Duration)
+	}
+	return nil
+}
+
+// RenewPeriod returns the time before the expiration the certificates are rotated
+func (r CertificateRotation) RenewPeriod() time.Duration {
+	if r.RenewBefore == nil {
+		return 0
+	}
+	return r.RenewBefore.Duration
+}
+
+// withDefaults returns a copy of the configuration with defaults applied
+func (r CertificateRotation) withDefaults() CertificateRotation {
+	if r.RenewBefore == nil {
+		r.RenewBefore = DefaultCertificateRotation().RenewBefore
+	}
+	return r
+}
+
+// Audit defines the Kubernetes API audit policy and backends
+type Audit struct {
+	// Policy is the audit.k8s.io Policy that defines which events
+	// are recorded. Auditing is disabled unless a policy is configured
+	// or implied by the hardening profile
+	Policy json.RawMessage `json:"policy,omitempty"`
+	// Log configures the log file backend
+	Log *AuditLog `json:"log,omitempty"`
+	// Webhook configures the webhook backend
+	Webhook *AuditWebhook `json:"webhook,omitempty"`
+}
+
+// AuditLog configures the audit log file backend
+type AuditLog struct {
+	// Disabled disables the log file backend
+	Disabled bool `json:"disabled,omitempty"`
+	// MaxAge is the maximum number of days to retain the rotated log files
+	MaxAge int `json:"maxAge,omitempty"`
+	// MaxBackup is the maximum number of rotated log files to retain
+	MaxBackup int `json:"maxBackup,omitempty"`
+	// MaxSize is the maximum size in megabytes of the log file before it is rotated
+	MaxSize int `json:"maxSize,omitempty"`
+	// Format is the format of the log file: json or legacy
+	Format string `json:"format,omitempty"`
+}
+
+// AuditWebhook configures the audit webhook backend
+type AuditWebhook struct {
+	// Config is the kubeconfig-formatted configuration of the remote
+	// service the audit events are sent to
+	Config string `json:"config"`
+	// Mode is the strategy for sending the audit events: batch or blocking
+	Mode string `json:"mode,omitempty"`
+	// InitialBackoff is the time to wait before retrying the first failed request
+	InitialBackoff *teleservices.Duration `json:"initialBackoff,omitempty"`
+}
+
+// GetAudit returns the Kubernetes API audit configuration
+// with defaults applied to the settings that are not configured
+func (r *Resource) GetAudit() Audit {
+	if r.Spec.Audit == nil {
+		return Audit{Log: DefaultAuditLog()}
+	}
+	return r.Spec.Audit.withDefaults()
 }
 
+// DefaultAuditLog returns the default audit log file backend configuration
+func DefaultAuditLog() *AuditLog {
+	return &AuditLog{
+		MaxAge:    defaults.AuditLogMaxAge,
+		MaxBackup: defaults.AuditLogMaxBackup,
+		MaxSize:   defaults.AuditLogMaxSize,
+		Format:    AuditLogFormatJSON,
+	}
+}
+
+// Check validates the audit configuration
+func (r Audit) Check() error {
+	if len(r.Policy) != 0 {
+		var policy struct {
+			Kind string `json:"kind"`
+		}
+		if err := json.Unmarshal(r.Policy, &policy); err != nil {
+			return trace.BadParameter("invalid audit policy: %v", err)
+		}
+		if policy.Kind != auditPolicyKind {
+			return trace.BadParameter("audit policy should be of kind %v, got %q",
+				auditPolicyKind, policy.Kind)
+		}
+	}
+	if r.Log != nil {
+		if r.Log.MaxAge < 0 || r.Log.MaxBackup < 0 || r.Log.MaxSize < 0 {
+			return trace.BadParameter("audit log rotation settings can not be negative")
+		}
+		if r.Log.Format != "" && r.Log.Format != AuditLogFormatJSON && r.Log.Format != AuditLogFormatLegacy {
+			return trace.BadParameter("audit log format should be either %v or %v, got %q",
+				AuditLogFormatJSON, AuditLogFormatLegacy, r.Log.Format)
+		}
+	}
+	if r.Webhook != nil {
+		if strings.TrimSpace(r.Webhook.Config) == "" {
+			return trace.BadParameter("audit webhook requires a kubeconfig-formatted configuration")
+		}
+		if r.Webhook.Mode != "" && r.Webhook.Mode != AuditWebhookModeBatch && r.Webhook.Mode != AuditWebhookModeBlocking {
+			return trace.BadParameter("audit webhook mode should be either %v or %v, got %q",
+				AuditWebhookModeBatch, AuditWebhookModeBlocking, r.Webhook.Mode)
+		}
+		if r.Webhook.InitialBackoff != nil && r.Webhook.InitialBackoff.Duration < 0 {
+			return trace.BadParameter("audit webhook initial backoff can not be negative")
+		}
+	}
+	return nil
+}
+
+// withDefaults returns a copy of the configuration with defaults applied
+func (r Audit) withDefaults() Audit {
+	defaultLog := DefaultAuditLog()
+	if r.Log == nil {
+		r.Log = defaultLog
+		return r
+	}
+	log := *r.Log
+	if log.MaxAge == 0 {
+		log.MaxAge = defaultLog.MaxAge
+	}
+	if log.MaxBackup == 0 {
+		log.MaxBackup = defaultLog.MaxBackup
+	}
+	if log.MaxSize == 0 {
+		log.MaxSize = defaultLog.MaxSize
+	}
+	if log.Format == "" {
+		log.Format = defaultLog.Format
+	}
+	r.Log = &log
+	return r
+}
+
+const (
+	// AuditLogFormatJSON logs audit events as JSON, one per line
+	AuditLogFormatJSON = "json"
+	// AuditLogFormatLegacy logs audit events as text, one per line
+	AuditLogFormatLegacy = "legacy"
+	// AuditWebhookModeBatch buffers the events and sends them asynchronously
+	AuditWebhookModeBatch = "batch"
+	// AuditWebhookModeBlocking sends the events synchronously with the API requests
+	AuditWebhookModeBlocking = "blocking"
+
+	auditPolicyKind = "Policy"
+)
+
+// Encryption defines the encryption of Kubernetes secrets at rest
+type Encryption struct {
+	// Provider is the encryption provider: aescbc, secretbox or kms
+	Provider string `json:"provider,omitempty"`
+	// KMS configures the KMS plugin that encrypts the data encryption keys.
+	// Required with the kms provider
+	KMS *KMS `json:"kms,omitempty"`
+}
+
+// KMS configures the envelope encryption with a KMS plugin
+type KMS struct {
+	// Name is the name of the KMS plugin
+	Name string `json:"name"`
+	// Endpoint is the unix socket the KMS plugin listens on,
+	// e.g. unix:///var/run/kms-plugin/socket.sock
+	Endpoint string `json:"endpoint"`
+	// CacheSize is the number of data encryption keys cached in memory
+	CacheSize int `json:"cacheSize,omitempty"`
+	// Timeout is the timeout of the requests to the KMS plugin
+	Timeout *teleservices.Duration `json:"timeout,omitempty"`
+}
+
+// GetEncryption returns the configuration of the encryption of Kubernetes
+// secrets at rest or nil if the secrets are not encrypted.
+// The secrets of hardened clusters are always encrypted
+func (r *Resource) GetEncryption() *Encryption {
+	if r.Spec.Encryption != nil {
+		encryption := r.Spec.Encryption.withDefaults()
+		return &encryption
+	}
+	if r.GetHardeningProfile() != "" {
+		encryption := Encryption{}.withDefaults()
+		return &encryption
+	}
+	return nil
+}
+
+// Check validates the encryption configuration
+func (r Encryption) Check() error {
+	switch r.Provider {
+	case "", EncryptionProviderAESCBC, EncryptionProviderSecretbox:
+		if r.KMS != nil {
+			return trace.BadParameter("KMS plugin can only be configured with the %v provider",
+				EncryptionProviderKMS)
+		}
+	case EncryptionProviderKMS:
+		if r.KMS == nil || r.KMS.Name == "" || r.KMS.Endpoint == "" {
+			return trace.BadParameter("%v provider requires KMS plugin name and endpoint",
+				EncryptionProviderKMS)
+		}
+		if !strings.HasPrefix(r.KMS.Endpoint, "unix://") {
+			return trace.BadParameter("KMS plugin endpoint should be a unix socket, got %q",
+				r.KMS.Endpoint)
+		}
+		if r.KMS.CacheSize < 0 {
+			return trace.BadParameter("KMS plugin cache size can not be negative")
+		}
+		if r.KMS.Timeout != nil && r.KMS.Timeout.Duration < 0 {
+			return trace.BadParameter("KMS plugin timeout can not be negative")
+		}
+	default:
+		return trace.BadParameter("encryption provider should be one of %v, %v or %v, got %q",
+			EncryptionProviderAESCBC, EncryptionProviderSecretbox, EncryptionProviderKMS, r.Provider)
+	}
+	return nil
+}
+
+// IsKMS returns true if the secrets are encrypted with a KMS plugin
+func (r Encryption) IsKMS() bool {
+	return r.Provider == EncryptionProviderKMS
+}
+
+// withDefaults returns a copy of the configuration with defaults applied
+func (r Encryption) withDefaults() Encryption {
+	if r.Provider == "" {
+		r.Provider = EncryptionProviderAESCBC
+	}
+	return r
+}
+
+const (
+	// EncryptionProviderAESCBC encrypts the secrets with AES-CBC
+	EncryptionProviderAESCBC = "aescbc"
+	// EncryptionProviderSecretbox encrypts the secrets with XSalsa20 and Poly1305
+	EncryptionProviderSecretbox = "secretbox"
+	// EncryptionProviderKMS encrypts the secrets with data encryption keys
+	// that are encrypted by a KMS plugin
+	EncryptionProviderKMS = "kms"
+)
+
 // ComponentsConfigs groups component configurations
 type ComponentConfigs struct {
 	// Kubelet defines kubelet configuration
@@ -228,6 +790,80 @@ type Global struct {
 	// FeatureGates defines the set of key=value pairs that describe feature gates for alpha/experimental features.
 	// Targets: all components
 	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+	// HTTPProxy specifies the proxy server to use for outbound HTTP connections.
+	// Targets: runtime container, docker
+	HTTPProxy string `json:"httpProxy,omitempty"`
+	// HTTPSProxy specifies the proxy server to use for outbound HTTPS connections.
+	// Targets: runtime container, docker
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+	// NoProxy specifies the comma-separated list of hosts, domains and subnets
+	// to exclude from proxying.
+	// Targets: runtime container, docker
+	NoProxy string `json:"noProxy,omitempty"`
+	// FIPS indicates that the cluster runs in FIPS 140-2 mode.
+	// It is set during installation and cannot be changed afterwards.
+	// Targets: gravity-site, teleport
+	FIPS bool `json:"fips,omitempty"`
+	// Hardening names the hardened configuration profile, e.g. cis.
+	// It is set during installation and cannot be changed afterwards.
+	// Targets: runtime container, gravity-site
+	Hardening string `json:"hardening,omitempty"`
+	// NetworkPolicy enables the baseline of network policies that deny
+	// all ingress traffic except for the traffic of the system components.
+	// It is set during installation and cannot be changed afterwards.
+	// Targets: kubernetes network policies
+	NetworkPolicy bool `json:"networkPolicy,omitempty"`
+}
+
+// HasProxy returns true if this configuration specifies any proxy settings
+func (r Global) HasProxy() bool {
+	return r.HTTPProxy != "" || r.HTTPSProxy != "" || r.NoProxy != ""
+}
+
+// CheckProxy validates the proxy settings in this configuration
+func (r Global) CheckProxy() error {
+	for _, proxy := range []string{r.HTTPProxy, r.HTTPSProxy} {
+		if proxy == "" {
+			continue
+		}
+		if err := checkProxyURL(proxy); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// ProxyEnv returns the proxy configuration as a set of environment variables.
+// Both upper- and lower-case variants are set since programs differ in which
+// one they consult
+func (r Global) ProxyEnv() map[string]string {
+	env := make(map[string]string)
+	for name, value := range map[string]string{
+		constants.HTTPProxyEnvVar:  r.HTTPProxy,
+		constants.HTTPSProxyEnvVar: r.HTTPSProxy,
+		constants.NoProxyEnvVar:    r.NoProxy,
+	} {
+		if value == "" {
+			continue
+		}
+		env[name] = value
+		env[strings.ToLower(name)] = value
+	}
+	return env
+}
+
+func checkProxyURL(proxy string) error {
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return trace.BadParameter("invalid proxy URL %q: %v", proxy, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return trace.BadParameter("invalid proxy URL %q: scheme should be either http or https", proxy)
+	}
+	if u.Host == "" {
+		return trace.BadParameter("invalid proxy URL %q: missing host", proxy)
+	}
+	return nil
 }
 
 // specSchemaTemplate is JSON schema for the cluster configuration resource
@@ -269,6 +905,12 @@ const specSchemaTemplate = `{
             "serviceNodePortRange": {"type": "string"},
             "poxyPortRange": {"type": "string"},
             "podCIDR": {"type": "string"},
+            "httpProxy": {"type": "string"},
+            "httpsProxy": {"type": "string"},
+            "noProxy": {"type": "string"},
+            "fips": {"type": "boolean"},
+            "hardening": {"type": "string", "enum": ["cis"]},
+            "networkPolicy": {"type": "boolean"},
             "featureGates": {
               "type": "object",
               "patternProperties": {
@@ -277,6 +919,78 @@ const specSchemaTemplate = `{
             }
           }
         },
+        "resourcePressure": {
+          "type": "object",
+          "additionalProperties": false,
+          "properties": {
+            "disk": %[3]v,
+            "inodes": %[3]v,
+            "conntrack": %[3]v,
+            "pids": %[3]v
+          }
+        },
+        "certificateExpiry": {
+          "type": "object",
+          "additionalProperties": false,
+          "properties": {
+            "warning": {"type": "string"},
+            "critical": {"type": "string"}
+          }
+        },
+        "certificateRotation": {
+          "type": "object",
+          "additionalProperties": false,
+          "properties": {
+            "enabled": {"type": "boolean"},
+            "renewBefore": {"type": "string"}
+          }
+        },
+        "audit": {
+          "type": "object",
+          "additionalProperties": false,
+          "properties": {
+            "policy": {"type": "object"},
+            "log": {
+              "type": "object",
+              "additionalProperties": false,
+              "properties": {
+                "disabled": {"type": "boolean"},
+                "maxAge": {"type": "integer"},
+                "maxBackup": {"type": "integer"},
+                "maxSize": {"type": "integer"},
+                "format": {"type": "string"}
+              }
+            },
+            "webhook": {
+              "type": "object",
+              "additionalProperties": false,
+              "required": ["config"],
+              "properties": {
+                "config": {"type": "string"},
+                "mode": {"type": "string"},
+                "initialBackoff": {"type": "string"}
+              }
+            }
+          }
+        },
+        "encryption": {
+          "type": "object",
+          "additionalProperties": false,
+          "properties": {
+            "provider": {"type": "string"},
+            "kms": {
+              "type": "object",
+              "additionalProperties": false,
+              "required": ["name", "endpoint"],
+              "properties": {
+                "name": {"type": "string"},
+                "endpoint": {"type": "string"},
+                "cacheSize": {"type": "integer"},
+                "timeout": {"type": "string"}
+              }
+            }
+          }
+        },
         "kubelet": {
           "type": "object",
           "additionalProperties": false,
@@ -382,9 +1096,19 @@ const specSchemaTemplate = `{
 // getSpecSchema returns the formatted JSON schema for the cluster configuration resource
 func getSpecSchema() string {
 	return fmt.Sprintf(specSchemaTemplate,
-		constants.ClusterConfigurationMap, defaults.KubeSystemNamespace)
+		constants.ClusterConfigurationMap, defaults.KubeSystemNamespace, thresholdSchema)
 }
 
+// thresholdSchema is JSON schema for the resource usage thresholds
+const thresholdSchema = `{
+              "type": "object",
+              "additionalProperties": false,
+              "properties": {
+                "warning": {"type": "integer"},
+                "critical": {"type": "integer"}
+              }
+            }`
+
 func newEmpty() *Resource {
 	return &Resource{
 		Kind:    storage.KindClusterConfiguration,
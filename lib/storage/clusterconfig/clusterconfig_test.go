@@ -18,7 +18,9 @@ package clusterconfig
 
 import (
 	"encoding/json"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/gravitational/gravity/lib/compare"
 	"github.com/gravitational/gravity/lib/constants"
@@ -187,6 +189,82 @@ spec:
 			},
 			comment: "consumes global configuration",
 		},
+		{
+			in: `kind: clusterconfiguration
+version: v1
+spec:
+  global:
+    httpProxy: http://proxy.example.com:3128
+    httpsProxy: https://proxy.example.com:3129
+    noProxy: localhost,.example.com`,
+			resource: &Resource{
+				Kind:    storage.KindClusterConfiguration,
+				Version: "v1",
+				Metadata: teleservices.Metadata{
+					Name:      constants.ClusterConfigurationMap,
+					Namespace: defaults.KubeSystemNamespace,
+				},
+				Spec: Spec{
+					Global: &Global{
+						HTTPProxy:  "http://proxy.example.com:3128",
+						HTTPSProxy: "https://proxy.example.com:3129",
+						NoProxy:    "localhost,.example.com",
+					},
+				},
+			},
+			comment: "consumes proxy configuration",
+		},
+		{
+			in: `kind: clusterconfiguration
+version: v1
+spec:
+  global:
+    fips: true`,
+			resource: &Resource{
+				Kind:    storage.KindClusterConfiguration,
+				Version: "v1",
+				Metadata: teleservices.Metadata{
+					Name:      constants.ClusterConfigurationMap,
+					Namespace: defaults.KubeSystemNamespace,
+				},
+				Spec: Spec{
+					Global: &Global{
+						FIPS: true,
+					},
+				},
+			},
+			comment: "consumes FIPS mode",
+		},
+		{
+			in: `kind: clusterconfiguration
+version: v1
+spec:
+  global:
+    hardening: cis`,
+			resource: &Resource{
+				Kind:    storage.KindClusterConfiguration,
+				Version: "v1",
+				Metadata: teleservices.Metadata{
+					Name:      constants.ClusterConfigurationMap,
+					Namespace: defaults.KubeSystemNamespace,
+				},
+				Spec: Spec{
+					Global: &Global{
+						Hardening: "cis",
+					},
+				},
+			},
+			comment: "consumes hardening profile",
+		},
+		{
+			in: `kind: clusterconfiguration
+version: v1
+spec:
+  global:
+    httpProxy: proxy.example.com:3128`,
+			error:   trace.BadParameter(`invalid proxy URL "proxy.example.com:3128": scheme should be either http or https`),
+			comment: "validates proxy configuration",
+		},
 	}
 	for _, tc := range testCases {
 		comment := Commentf(tc.comment)
@@ -233,3 +311,178 @@ type kubeletConfiguration struct {
 	metav1.TypeMeta `json:",inline"`
 	Address         string `json:"address"`
 }
+
+func (*S) TestProxyEnv(c *C) {
+	config := Global{
+		HTTPProxy: "http://proxy.example.com:3128",
+		NoProxy:   "localhost",
+	}
+	c.Assert(config.ProxyEnv(), compare.DeepEquals, map[string]string{
+		"HTTP_PROXY": "http://proxy.example.com:3128",
+		"http_proxy": "http://proxy.example.com:3128",
+		"NO_PROXY":   "localhost",
+		"no_proxy":   "localhost",
+	})
+	c.Assert(Global{}.ProxyEnv(), HasLen, 0)
+}
+
+func (*S) TestResourcePressure(c *C) {
+	config, err := Unmarshal([]byte(`kind: clusterconfiguration
+version: v1
+spec:
+  resourcePressure:
+    disk:
+      warning: 70
+    conntrack:
+      warning: 60
+      critical: 80
+`))
+	c.Assert(err, IsNil)
+	c.Assert(config.GetResourcePressure(), compare.DeepEquals, ResourcePressure{
+		Disk:      &Threshold{Warning: 70, Critical: 90},
+		Inodes:    &Threshold{Warning: 80, Critical: 90},
+		Conntrack: &Threshold{Warning: 60, Critical: 80},
+		PIDs:      &Threshold{Warning: 75, Critical: 90},
+	})
+	c.Assert(NewEmpty().GetResourcePressure(), compare.DeepEquals, DefaultResourcePressure())
+
+	_, err = Unmarshal([]byte(`kind: clusterconfiguration
+version: v1
+spec:
+  resourcePressure:
+    pids:
+      warning: 95
+      critical: 90
+`))
+	c.Assert(trace.IsBadParameter(err), Equals, true)
+}
+
+func (*S) TestCertificateExpiry(c *C) {
+	config, err := Unmarshal([]byte(`kind: clusterconfiguration
+version: v1
+spec:
+  certificateExpiry:
+    warning: 1440h
+`))
+	c.Assert(err, IsNil)
+	thresholds := config.GetCertificateExpiry()
+	c.Assert(thresholds.WarningPeriod(), Equals, 60*24*time.Hour)
+	c.Assert(thresholds.CriticalPeriod(), Equals, defaults.CertificateExpiryCritical)
+	c.Assert(NewEmpty().GetCertificateExpiry(), compare.DeepEquals, DefaultCertificateExpiry())
+
+	_, err = Unmarshal([]byte(`kind: clusterconfiguration
+version: v1
+spec:
+  certificateExpiry:
+    warning: 24h
+    critical: 48h
+`))
+	c.Assert(trace.IsBadParameter(err), Equals, true)
+}
+
+func (*S) TestCertificateRotation(c *C) {
+	config, err := Unmarshal([]byte(`kind: clusterconfiguration
+version: v1
+spec:
+  certificateRotation:
+    enabled: true
+`))
+	c.Assert(err, IsNil)
+	rotation := config.GetCertificateRotation()
+	c.Assert(rotation.Enabled, Equals, true)
+	c.Assert(rotation.RenewPeriod(), Equals, defaults.CertificateRenewBefore)
+	c.Assert(NewEmpty().GetCertificateRotation().Enabled, Equals, false)
+
+	_, err = Unmarshal([]byte(`kind: clusterconfiguration
+version: v1
+spec:
+  certificateRotation:
+    enabled: true
+    renewBefore: -24h
+`))
+	c.Assert(trace.IsBadParameter(err), Equals, true)
+}
+
+func (*S) TestAudit(c *C) {
+	config, err := Unmarshal([]byte(`kind: clusterconfiguration
+version: v1
+spec:
+  audit:
+    policy:
+      apiVersion: audit.k8s.io/v1
+      kind: Policy
+      rules:
+      - level: Metadata
+    log:
+      maxAge: 7
+    webhook:
+      config: "apiVersion: v1\nkind: Config"
+      mode: batch
+      initialBackoff: 10s
+`))
+	c.Assert(err, IsNil)
+	audit := config.GetAudit()
+	c.Assert(string(audit.Policy), Equals, `{"apiVersion":"audit.k8s.io/v1","kind":"Policy","rules":[{"level":"Metadata"}]}`)
+	c.Assert(*audit.Log, DeepEquals, AuditLog{
+		MaxAge:    7,
+		MaxBackup: defaults.AuditLogMaxBackup,
+		MaxSize:   defaults.AuditLogMaxSize,
+		Format:    AuditLogFormatJSON,
+	})
+	c.Assert(audit.Webhook.Mode, Equals, AuditWebhookModeBatch)
+	c.Assert(audit.Webhook.InitialBackoff.Duration, Equals, 10*time.Second)
+	c.Assert(NewEmpty().GetAudit().Log, DeepEquals, DefaultAuditLog())
+
+	for _, spec := range []string{
+		"policy:\n      kind: ConfigMap",
+		"log:\n      maxSize: -1",
+		"log:\n      format: xml",
+		"webhook:\n      config: ''",
+		"webhook:\n      config: test\n      mode: async",
+	} {
+		_, err = Unmarshal([]byte(fmt.Sprintf(`kind: clusterconfiguration
+version: v1
+spec:
+  audit:
+    %v
+`, spec)))
+		c.Assert(trace.IsBadParameter(err), Equals, true, Commentf(spec))
+	}
+}
+
+func (*S) TestEncryption(c *C) {
+	config, err := Unmarshal([]byte(`kind: clusterconfiguration
+version: v1
+spec:
+  encryption:
+    provider: kms
+    kms:
+      name: vault
+      endpoint: unix:///var/run/kms-plugin/socket.sock
+      timeout: 3s
+`))
+	c.Assert(err, IsNil)
+	encryption := config.GetEncryption()
+	c.Assert(encryption.IsKMS(), Equals, true)
+	c.Assert(encryption.KMS.Timeout.Duration, Equals, 3*time.Second)
+
+	c.Assert(NewEmpty().GetEncryption(), IsNil)
+	hardened := NewEmpty()
+	hardened.SetHardeningProfile("cis")
+	c.Assert(*hardened.GetEncryption(), DeepEquals, Encryption{Provider: EncryptionProviderAESCBC})
+
+	for _, spec := range []string{
+		"provider: des",
+		"provider: kms",
+		"provider: kms\n    kms:\n      name: vault\n      endpoint: localhost:8080",
+		"provider: aescbc\n    kms:\n      name: vault\n      endpoint: unix:///socket",
+	} {
+		_, err = Unmarshal([]byte(fmt.Sprintf(`kind: clusterconfiguration
+version: v1
+spec:
+  encryption:
+    %v
+`, spec)))
+		c.Assert(trace.IsBadParameter(err), Equals, true, Commentf(spec))
+	}
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gravitational/gravity/lib/defaults"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	teleutils "github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/trace"
+)
+
+// ClusterDNS describes the cluster DNS configuration: upstream nameservers
+// and per-zone forwarders used by the cluster DNS service
+type ClusterDNS interface {
+	// Resource provides common resource methods
+	teleservices.Resource
+	// CheckAndSetDefaults verifies that the object is valid
+	CheckAndSetDefaults() error
+	// GetUpstreamServers returns the list of upstream nameservers
+	GetUpstreamServers() []string
+	// GetZones returns the per-zone nameservers
+	GetZones() map[string][]string
+	// GetHosts returns the host overrides
+	GetHosts() map[string]string
+	// GetOverrides returns this configuration in the cluster DNS overrides format
+	GetOverrides() DNSOverrides
+}
+
+// NewClusterDNS creates a new cluster DNS configuration resource
+// from the specified DNS overrides
+func NewClusterDNS(overrides DNSOverrides) ClusterDNS {
+	return &ClusterDNSV2{
+		Kind:    KindClusterDNS,
+		Version: teleservices.V2,
+		Metadata: teleservices.Metadata{
+			Name:      KindClusterDNS,
+			Namespace: defaults.Namespace,
+		},
+		Spec: ClusterDNSSpecV2{
+			UpstreamServers: overrides.Upstreams,
+			Zones:           overrides.Zones,
+			Hosts:           overrides.Hosts,
+		},
+	}
+}
+
+// ClusterDNSV2 defines the cluster DNS configuration resource
+type ClusterDNSV2 struct {
+	// Metadata is resource metadata
+	teleservices.Metadata `json:"metadata"`
+	// Kind is a resource kind
+	Kind string `json:"kind"`
+	// Version is a resource version
+	Version string `json:"version"`
+	// Spec defines the cluster DNS configuration
+	Spec ClusterDNSSpecV2 `json:"spec"`
+}
+
+// GetUpstreamServers returns the list of upstream nameservers
+func (r *ClusterDNSV2) GetUpstreamServers() []string {
+	return r.Spec.UpstreamServers
+}
+
+// GetZones returns the per-zone nameservers
+func (r *ClusterDNSV2) GetZones() map[string][]string {
+	return r.Spec.Zones
+}
+
+// GetHosts returns the host overrides
+func (r *ClusterDNSV2) GetHosts() map[string]string {
+	return r.Spec.Hosts
+}
+
+// GetOverrides returns this configuration in the cluster DNS overrides format
+func (r *ClusterDNSV2) GetOverrides() DNSOverrides {
+	return DNSOverrides{
+		Upstreams: r.Spec.UpstreamServers,
+		Zones:     r.Spec.Zones,
+		Hosts:     r.Spec.Hosts,
+	}
+}
+
+// CheckAndSetDefaults checks validity of all parameters and sets defaults
+func (r *ClusterDNSV2) CheckAndSetDefaults() error {
+	if r.Metadata.Name == "" {
+		r.Metadata.Name = KindClusterDNS
+	}
+	if err := r.Metadata.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	overrides := r.GetOverrides()
+	if err := overrides.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// UnmarshalClusterDNS unmarshals cluster DNS configuration from JSON
+func UnmarshalClusterDNS(data []byte) (ClusterDNS, error) {
+	if len(data) == 0 {
+		return nil, trace.BadParameter("empty configuration")
+	}
+
+	jsonData, err := teleutils.ToJSON(data)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var hdr teleservices.ResourceHeader
+	err = json.Unmarshal(jsonData, &hdr)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	switch hdr.Version {
+	case teleservices.V2:
+		var config ClusterDNSV2
+		err := teleutils.UnmarshalWithSchema(GetClusterDNSSchema(), &config, jsonData)
+		if err != nil {
+			return nil, trace.BadParameter("%v", err)
+		}
+		if err := config.CheckAndSetDefaults(); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &config, nil
+	}
+	return nil, trace.BadParameter(
+		"%v resource version %q is not supported", KindClusterDNS, hdr.Version)
+}
+
+// MarshalClusterDNS marshals cluster DNS configuration into JSON
+func MarshalClusterDNS(config ClusterDNS, opts ...teleservices.MarshalOption) ([]byte, error) {
+	return json.Marshal(config)
+}
+
+// ClusterDNSSpecV2 defines the cluster DNS configuration
+type ClusterDNSSpecV2 struct {
+	// UpstreamServers lists nameservers to forward queries for external names to.
+	// If empty, nameservers from the host's resolv.conf are used
+	UpstreamServers []string `json:"upstream_servers,omitempty"`
+	// Zones maps a DNS zone to nameservers it will be served by
+	Zones map[string][]string `json:"zones,omitempty"`
+	// Hosts maps a hostname to an IP address it will resolve to
+	Hosts map[string]string `json:"hosts,omitempty"`
+}
+
+// ClusterDNSSpecV2Schema is JSON schema for cluster DNS configuration
+const ClusterDNSSpecV2Schema = `{
+  "type": "object",
+  "additionalProperties": false,
+  "properties": {
+    "upstream_servers": {"type": "array", "items": {"type": "string"}},
+    "zones": {
+      "type": "object",
+      "patternProperties": {
+        "^.*$": {"type": "array", "items": {"type": "string"}}
+      }
+    },
+    "hosts": {
+      "type": "object",
+      "patternProperties": {
+        "^.*$": {"type": "string"}
+      }
+    }
+  }
+}`
+
+// GetClusterDNSSchema returns cluster DNS configuration schema for version V2
+func GetClusterDNSSchema() string {
+	return fmt.Sprintf(teleservices.V2SchemaTemplate, MetadataSchema,
+		ClusterDNSSpecV2Schema, "")
+}
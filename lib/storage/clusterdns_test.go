@@ -0,0 +1,88 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"github.com/gravitational/gravity/lib/compare"
+
+	"github.com/gravitational/trace"
+	check "gopkg.in/check.v1"
+)
+
+type ClusterDNSSuite struct{}
+
+var _ = check.Suite(&ClusterDNSSuite{})
+
+func (s *ClusterDNSSuite) TestResourceParsing(c *check.C) {
+	spec := `kind: clusterdns
+version: v2
+spec:
+  upstream_servers: ["10.0.0.1", "10.0.0.2:5353"]
+  zones:
+    example.com: ["10.0.1.1"]
+`
+	config, err := UnmarshalClusterDNS([]byte(spec))
+	c.Assert(err, check.IsNil)
+	c.Assert(config, compare.DeepEquals, NewClusterDNS(DNSOverrides{
+		Upstreams: []string{"10.0.0.1", "10.0.0.2:5353"},
+		Zones:     map[string][]string{"example.com": []string{"10.0.1.1"}},
+	}))
+
+	data, err := MarshalClusterDNS(config)
+	c.Assert(err, check.IsNil)
+	decoded, err := UnmarshalClusterDNS(data)
+	c.Assert(err, check.IsNil)
+	c.Assert(decoded, compare.DeepEquals, config)
+}
+
+func (s *ClusterDNSSuite) TestValidatesResource(c *check.C) {
+	var specs = []struct {
+		spec        string
+		description string
+	}{
+		{
+			spec: `kind: clusterdns
+version: v2
+spec:
+  upstream_servers: ["dns.example.com"]
+`,
+			description: "upstream server is not an IP address",
+		},
+		{
+			spec: `kind: clusterdns
+version: v2
+spec:
+  upstream_servers: ["10.0.0.1:99999"]
+`,
+			description: "upstream server port is out of range",
+		},
+		{
+			spec: `kind: clusterdns
+version: v2
+spec:
+  zones:
+    example.com: ["example.com"]
+`,
+			description: "zone nameserver is not an IP address",
+		},
+	}
+	for _, tt := range specs {
+		_, err := UnmarshalClusterDNS([]byte(tt.spec))
+		c.Assert(err, check.NotNil, check.Commentf(tt.description))
+		c.Assert(trace.IsBadParameter(err), check.Equals, true, check.Commentf(tt.description))
+	}
+}
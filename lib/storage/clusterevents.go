@@ -0,0 +1,51 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"time"
+)
+
+// ClusterEvent is an entry in the cluster events table. The table records
+// the cluster audit events: operation lifecycle, cluster and node health
+// changes and resource modifications
+type ClusterEvent struct {
+	// ID uniquely identifies the event, the events are ordered by ID.
+	// The ID of the last received event is the cursor to resume
+	// receiving the events from
+	ID string `json:"id"`
+	// Time is the time of the event
+	Time time.Time `json:"time"`
+	// Name is the event name, e.g. operation.started
+	Name string `json:"name"`
+	// Code is the event code, e.g. G0001I
+	Code string `json:"code"`
+	// Fields is the event details
+	Fields map[string]interface{} `json:"fields,omitempty"`
+}
+
+// ClusterEvents defines the interface to the cluster events table
+type ClusterEvents interface {
+	// CreateClusterEvent adds the event to the events of the specified cluster.
+	// The event is assigned a new ID and kept for the specified time
+	CreateClusterEvent(clusterName string, event ClusterEvent, ttl time.Duration) (*ClusterEvent, error)
+	// GetClusterEvents returns at most limit events of the specified cluster
+	// recorded after the event with the specified ID, ordered from the oldest
+	// to the newest. The empty ID returns the events from the beginning of
+	// the table
+	GetClusterEvents(clusterName, after string, limit int) ([]ClusterEvent, error)
+}
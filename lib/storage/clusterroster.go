@@ -0,0 +1,293 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	teleutils "github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/trace"
+)
+
+// ClusterRoster describes the desired set of cluster nodes.
+// When the roster is defined, the cluster continuously reconciles its
+// membership against it by joining the missing nodes and removing the
+// nodes not in the roster
+type ClusterRoster interface {
+	// Resource provides common resource methods
+	teleservices.Resource
+	// CheckAndSetDefaults verifies that the object is valid
+	CheckAndSetDefaults() error
+	// GetNodes returns the desired cluster nodes
+	GetNodes() []RosterNode
+	// GetSSH returns the SSH configuration used to join new nodes
+	GetSSH() RosterSSH
+	// GetApproval returns the approval policy for membership changes
+	GetApproval() string
+}
+
+// NewClusterRoster creates a new cluster roster resource
+func NewClusterRoster(spec ClusterRosterSpecV2) ClusterRoster {
+	return &ClusterRosterV2{
+		Kind:    KindClusterRoster,
+		Version: teleservices.V2,
+		Metadata: teleservices.Metadata{
+			Name:      KindClusterRoster,
+			Namespace: defaults.Namespace,
+		},
+		Spec: spec,
+	}
+}
+
+// ClusterRosterV2 defines the cluster roster resource
+type ClusterRosterV2 struct {
+	// Metadata is resource metadata
+	teleservices.Metadata `json:"metadata"`
+	// Kind is a resource kind
+	Kind string `json:"kind"`
+	// Version is a resource version
+	Version string `json:"version"`
+	// Spec defines the cluster roster
+	Spec ClusterRosterSpecV2 `json:"spec"`
+}
+
+// GetNodes returns the desired cluster nodes
+func (r *ClusterRosterV2) GetNodes() []RosterNode {
+	return r.Spec.Nodes
+}
+
+// GetSSH returns the SSH configuration used to join new nodes
+func (r *ClusterRosterV2) GetSSH() RosterSSH {
+	return r.Spec.SSH
+}
+
+// GetApproval returns the approval policy for membership changes
+func (r *ClusterRosterV2) GetApproval() string {
+	return r.Spec.Approval
+}
+
+// CheckAndSetDefaults checks validity of all parameters and sets defaults
+func (r *ClusterRosterV2) CheckAndSetDefaults() error {
+	if r.Metadata.Name == "" {
+		r.Metadata.Name = KindClusterRoster
+	}
+	if err := r.Metadata.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	if len(r.Spec.Nodes) == 0 {
+		return trace.BadParameter("cluster roster should list at least one node")
+	}
+	addrs := make(map[string]struct{}, len(r.Spec.Nodes))
+	for _, node := range r.Spec.Nodes {
+		if net.ParseIP(node.Addr) == nil {
+			return trace.BadParameter("node address %q is not a valid IP address", node.Addr)
+		}
+		if node.Role == "" {
+			return trace.BadParameter("node %v is missing role", node.Addr)
+		}
+		if _, ok := addrs[node.Addr]; ok {
+			return trace.BadParameter("node %v is listed more than once", node.Addr)
+		}
+		addrs[node.Addr] = struct{}{}
+	}
+	if r.Spec.SSH.User == "" {
+		return trace.BadParameter("SSH user is required")
+	}
+	if r.Spec.SSH.Secret == "" {
+		return trace.BadParameter("name of the secret with the SSH private key is required")
+	}
+	if r.Spec.SSH.Port == 0 {
+		r.Spec.SSH.Port = defaults.SSHPort
+	}
+	switch r.Spec.Approval {
+	case "":
+		r.Spec.Approval = RosterApprovalManual
+	case RosterApprovalManual, RosterApprovalAutomatic:
+	default:
+		return trace.BadParameter("unsupported approval policy %q, supported are: %q, %q",
+			r.Spec.Approval, RosterApprovalManual, RosterApprovalAutomatic)
+	}
+	return nil
+}
+
+// UnmarshalClusterRoster unmarshals cluster roster from JSON
+func UnmarshalClusterRoster(data []byte) (ClusterRoster, error) {
+	if len(data) == 0 {
+		return nil, trace.BadParameter("empty cluster roster")
+	}
+
+	jsonData, err := teleutils.ToJSON(data)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var hdr teleservices.ResourceHeader
+	err = json.Unmarshal(jsonData, &hdr)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	switch hdr.Version {
+	case teleservices.V2:
+		var roster ClusterRosterV2
+		err := teleutils.UnmarshalWithSchema(GetClusterRosterSchema(), &roster, jsonData)
+		if err != nil {
+			return nil, trace.BadParameter("%v", err)
+		}
+		if err := roster.CheckAndSetDefaults(); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &roster, nil
+	}
+	return nil, trace.BadParameter(
+		"%v resource version %q is not supported", KindClusterRoster, hdr.Version)
+}
+
+// MarshalClusterRoster marshals cluster roster into JSON
+func MarshalClusterRoster(roster ClusterRoster, opts ...teleservices.MarshalOption) ([]byte, error) {
+	return json.Marshal(roster)
+}
+
+// ClusterRosterSpecV2 defines the cluster roster
+type ClusterRosterSpecV2 struct {
+	// Nodes lists the desired cluster nodes
+	Nodes []RosterNode `json:"nodes"`
+	// SSH defines how to connect to the new nodes to join them
+	SSH RosterSSH `json:"ssh"`
+	// Approval is the approval policy for membership changes:
+	// with manual approval, the changes are only applied after they
+	// have been approved with `gravity roster approve`
+	Approval string `json:"approval,omitempty"`
+}
+
+// RosterNode describes a single node in the cluster roster
+type RosterNode struct {
+	// Addr is the advertise IP address of the node
+	Addr string `json:"addr"`
+	// Role is the name of the node profile from the application manifest
+	Role string `json:"role"`
+	// HostKey is the optional public SSH host key of the node
+	// in the authorized_keys format
+	HostKey string `json:"host_key,omitempty"`
+}
+
+// RosterSSH defines how to connect to the new nodes over SSH
+type RosterSSH struct {
+	// User is the name of the SSH user.
+	// The user must be root or be able to run sudo without a password
+	User string `json:"user"`
+	// Port is the SSH port. Defaults to 22
+	Port int `json:"port,omitempty"`
+	// Secret is the name of the Kubernetes secret of type kubernetes.io/ssh-auth
+	// in the kube-system namespace with the SSH private key
+	Secret string `json:"secret"`
+}
+
+// ClusterRosterStatus describes the state of the cluster roster reconciliation
+type ClusterRosterStatus struct {
+	// Changes lists the membership changes required to bring
+	// the cluster in sync with the roster
+	Changes []RosterChange `json:"changes,omitempty"`
+	// ChangesID identifies the set of pending changes
+	ChangesID string `json:"changes_id,omitempty"`
+	// ApprovedID identifies the set of changes that has been approved
+	ApprovedID string `json:"approved_id,omitempty"`
+	// Message describes the result of the last reconciliation
+	Message string `json:"message,omitempty"`
+	// Updated is the time of the last reconciliation
+	Updated time.Time `json:"updated"`
+}
+
+// IsPendingApproval returns true if the status has changes that
+// have not been approved yet
+func (r ClusterRosterStatus) IsPendingApproval() bool {
+	return len(r.Changes) != 0 && r.ChangesID != r.ApprovedID
+}
+
+// RosterChange describes a single membership change
+type RosterChange struct {
+	// Action is the change action: add or remove
+	Action string `json:"action"`
+	// Addr is the advertise IP address of the node
+	Addr string `json:"addr"`
+	// Role is the node profile
+	Role string `json:"role"`
+	// Hostname is the hostname of the node being removed
+	Hostname string `json:"hostname,omitempty"`
+}
+
+// String returns a textual representation of this change
+func (r RosterChange) String() string {
+	return fmt.Sprintf("%v %v(%v)", r.Action, r.Addr, r.Role)
+}
+
+const (
+	// RosterApprovalManual requires an explicit approval of membership changes
+	RosterApprovalManual = "manual"
+	// RosterApprovalAutomatic applies membership changes without approval
+	RosterApprovalAutomatic = "automatic"
+
+	// RosterActionAdd denotes a node to join to the cluster
+	RosterActionAdd = "add"
+	// RosterActionRemove denotes a node to remove from the cluster
+	RosterActionRemove = "remove"
+)
+
+// ClusterRosterSpecV2Schema is JSON schema for the cluster roster
+const ClusterRosterSpecV2Schema = `{
+  "type": "object",
+  "additionalProperties": false,
+  "required": ["nodes", "ssh"],
+  "properties": {
+    "nodes": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "additionalProperties": false,
+        "required": ["addr", "role"],
+        "properties": {
+          "addr": {"type": "string"},
+          "role": {"type": "string"},
+          "host_key": {"type": "string"}
+        }
+      }
+    },
+    "ssh": {
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["user", "secret"],
+      "properties": {
+        "user": {"type": "string"},
+        "port": {"type": "integer"},
+        "secret": {"type": "string"}
+      }
+    },
+    "approval": {"type": "string"}
+  }
+}`
+
+// GetClusterRosterSchema returns cluster roster schema for version V2
+func GetClusterRosterSchema() string {
+	return fmt.Sprintf(teleservices.V2SchemaTemplate, MetadataSchema,
+		ClusterRosterSpecV2Schema, "")
+}
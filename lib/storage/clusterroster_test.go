@@ -0,0 +1,124 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"github.com/gravitational/gravity/lib/compare"
+	"github.com/gravitational/gravity/lib/defaults"
+
+	"github.com/gravitational/trace"
+	check "gopkg.in/check.v1"
+)
+
+type ClusterRosterSuite struct{}
+
+var _ = check.Suite(&ClusterRosterSuite{})
+
+func (s *ClusterRosterSuite) TestResourceParsing(c *check.C) {
+	spec := `kind: clusterroster
+version: v2
+spec:
+  nodes:
+  - addr: 10.0.0.1
+    role: master
+  - addr: 10.0.0.2
+    role: node
+    host_key: "ssh-ed25519 AAAA"
+  ssh:
+    user: ubuntu
+    secret: roster-ssh-key
+`
+	roster, err := UnmarshalClusterRoster([]byte(spec))
+	c.Assert(err, check.IsNil)
+	c.Assert(roster, compare.DeepEquals, NewClusterRoster(ClusterRosterSpecV2{
+		Nodes: []RosterNode{
+			{Addr: "10.0.0.1", Role: "master"},
+			{Addr: "10.0.0.2", Role: "node", HostKey: "ssh-ed25519 AAAA"},
+		},
+		SSH: RosterSSH{
+			User:   "ubuntu",
+			Port:   defaults.SSHPort,
+			Secret: "roster-ssh-key",
+		},
+		Approval: RosterApprovalManual,
+	}))
+
+	data, err := MarshalClusterRoster(roster)
+	c.Assert(err, check.IsNil)
+	decoded, err := UnmarshalClusterRoster(data)
+	c.Assert(err, check.IsNil)
+	c.Assert(decoded, compare.DeepEquals, roster)
+}
+
+func (s *ClusterRosterSuite) TestValidatesResource(c *check.C) {
+	var specs = []struct {
+		spec        string
+		description string
+	}{
+		{
+			spec: `kind: clusterroster
+version: v2
+spec:
+  nodes: []
+  ssh: {user: root, secret: key}
+`,
+			description: "no nodes",
+		},
+		{
+			spec: `kind: clusterroster
+version: v2
+spec:
+  nodes: [{addr: node-1, role: node}]
+  ssh: {user: root, secret: key}
+`,
+			description: "invalid address",
+		},
+		{
+			spec: `kind: clusterroster
+version: v2
+spec:
+  nodes: [{addr: 10.0.0.1, role: node}, {addr: 10.0.0.1, role: node}]
+  ssh: {user: root, secret: key}
+`,
+			description: "duplicate address",
+		},
+		{
+			spec: `kind: clusterroster
+version: v2
+spec:
+  nodes: [{addr: 10.0.0.1, role: node}]
+  ssh: {user: root, secret: ""}
+`,
+			description: "missing secret",
+		},
+		{
+			spec: `kind: clusterroster
+version: v2
+spec:
+  nodes: [{addr: 10.0.0.1, role: node}]
+  ssh: {user: root, secret: key}
+  approval: sometimes
+`,
+			description: "invalid approval policy",
+		},
+	}
+	for _, tt := range specs {
+		_, err := UnmarshalClusterRoster([]byte(tt.spec))
+		c.Assert(err, check.NotNil, check.Commentf(tt.description))
+		c.Assert(trace.IsBadParameter(err), check.Equals, true, check.Commentf(tt.description))
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// DeviceAuthRequest is a pending single sign-on login of a command line
+// client that follows the device authorization flow.
+//
+// The client creates the request and polls it with the device code while
+// the user authenticates with the identity provider in a browser using
+// the user code. Once authenticated, the request carries the login response
+// with the certificates issued for the client public key
+type DeviceAuthRequest struct {
+	// DeviceCode is the secret code the client polls the request with
+	DeviceCode string `json:"device_code"`
+	// UserCode is the short code the user confirms the login with in the browser
+	UserCode string `json:"user_code"`
+	// ConnectorID is the name of the auth connector to authenticate with
+	ConnectorID string `json:"connector_id"`
+	// ConnectorKind is the kind of the auth connector: oidc, saml or github
+	ConnectorKind string `json:"connector_kind"`
+	// PublicKey is the client public key to issue the certificates for
+	PublicKey []byte `json:"public_key"`
+	// CertTTL is the requested TTL of the certificates
+	CertTTL time.Duration `json:"cert_ttl"`
+	// Expires is when the request expires
+	Expires time.Time `json:"expires"`
+	// Response is the marshaled login response, set once the user
+	// has authenticated
+	Response []byte `json:"response,omitempty"`
+}
+
+// Check validates this request
+func (r DeviceAuthRequest) Check() error {
+	if r.DeviceCode == "" {
+		return trace.BadParameter("missing device code")
+	}
+	if r.UserCode == "" {
+		return trace.BadParameter("missing user code")
+	}
+	if r.ConnectorID == "" {
+		return trace.BadParameter("missing connector ID")
+	}
+	if len(r.PublicKey) == 0 {
+		return trace.BadParameter("missing public key")
+	}
+	if r.Expires.IsZero() {
+		return trace.BadParameter("missing expiration time")
+	}
+	return nil
+}
+
+// IsCompleted returns true if the user has authenticated
+func (r DeviceAuthRequest) IsCompleted() bool {
+	return len(r.Response) != 0
+}
+
+// DeviceAuthRequests manages the single sign-on requests of command line clients
+type DeviceAuthRequests interface {
+	// CreateDeviceAuthRequest creates a new device auth request
+	CreateDeviceAuthRequest(DeviceAuthRequest) error
+	// UpdateDeviceAuthRequest updates the existing device auth request
+	UpdateDeviceAuthRequest(DeviceAuthRequest) error
+	// GetDeviceAuthRequest returns the device auth request with the specified device code
+	GetDeviceAuthRequest(deviceCode string) (*DeviceAuthRequest, error)
+	// GetDeviceAuthRequestByUserCode returns the device auth request with the specified user code
+	GetDeviceAuthRequestByUserCode(userCode string) (*DeviceAuthRequest, error)
+	// DeleteDeviceAuthRequest deletes the device auth request with the specified device code
+	DeleteDeviceAuthRequest(deviceCode string) error
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package encryption implements retrieval of the key used to encrypt
+// the local state databases on a node.
+//
+// The key can be read from a file, from an AWS Systems Manager SecureString
+// parameter or unsealed from a TPM
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/state"
+	"github.com/gravitational/gravity/lib/storage/keyval"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ProviderFile reads the key from a file
+	ProviderFile = "file"
+	// ProviderSSM reads the key from an AWS Systems Manager
+	// SecureString parameter. The parameter is decrypted by
+	// Systems Manager with the KMS key it has been stored with
+	ProviderSSM = "ssm"
+	// ProviderTPM unseals the key from the TPM
+	ProviderTPM = "tpm"
+)
+
+// Providers lists the supported key providers
+var Providers = []string{ProviderFile, ProviderSSM, ProviderTPM}
+
+// Config describes the source of the encryption key
+type Config struct {
+	// Provider specifies the key provider
+	Provider string `json:"provider"`
+	// KeyFile is the path to the file with the key.
+	// Used by the file provider
+	KeyFile string `json:"keyFile,omitempty"`
+	// Parameter is the name of the parameter with the key.
+	// Used by the ssm provider
+	Parameter string `json:"parameter,omitempty"`
+	// Region is the AWS region of the parameter with the key.
+	// Used by the ssm provider, defaults to the region from the environment
+	Region string `json:"region,omitempty"`
+	// Handle is the persistent handle of the object with the sealed key.
+	// Used by the tpm provider
+	Handle string `json:"handle,omitempty"`
+}
+
+// Check validates this configuration
+func (r Config) Check() error {
+	switch r.Provider {
+	case ProviderFile:
+		if r.KeyFile == "" {
+			return trace.BadParameter("path to the key file is required")
+		}
+	case ProviderSSM:
+		if r.Parameter == "" {
+			return trace.BadParameter("name of the parameter with the key is required")
+		}
+	case ProviderTPM:
+		if r.Handle == "" {
+			return trace.BadParameter("handle of the sealed key is required")
+		}
+	default:
+		return trace.BadParameter("unsupported key provider %q, supported are: %v",
+			r.Provider, Providers)
+	}
+	return nil
+}
+
+// GetKey retrieves the encryption key from the configured provider
+func (r Config) GetKey(ctx context.Context) ([]byte, error) {
+	if err := r.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var data []byte
+	var err error
+	switch r.Provider {
+	case ProviderFile:
+		data, err = ioutil.ReadFile(r.KeyFile)
+		err = trace.ConvertSystemError(err)
+	case ProviderSSM:
+		data, err = r.getParameter(ctx)
+	case ProviderTPM:
+		data, err = utils.RunCommand(ctx, logrus.WithField(trace.Component, "encryption"),
+			"tpm2_unseal", "-c", r.Handle)
+	}
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to retrieve encryption key from %v provider", r.Provider)
+	}
+	return ParseKey(data)
+}
+
+func (r Config) getParameter(ctx context.Context) ([]byte, error) {
+	config := &aws.Config{}
+	if r.Region != "" {
+		config.Region = aws.String(r.Region)
+	}
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	resp, err := ssm.New(sess).GetParameterWithContext(ctx, &ssm.GetParameterInput{
+		Name:           aws.String(r.Parameter),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == ssm.ErrCodeParameterNotFound {
+			return nil, trace.NotFound("parameter %v not found", r.Parameter)
+		}
+		return nil, trace.Wrap(err)
+	}
+	return []byte(aws.StringValue(resp.Parameter.Value)), nil
+}
+
+// ParseKey returns the encryption key from the specified data.
+// The key can be specified either as raw bytes or hex- or base64-encoded
+func ParseKey(data []byte) ([]byte, error) {
+	if len(data) == keyval.EncryptionKeySize {
+		return data, nil
+	}
+	data = bytes.TrimSpace(data)
+	if key, err := hex.DecodeString(string(data)); err == nil && len(key) == keyval.EncryptionKeySize {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(string(data)); err == nil && len(key) == keyval.EncryptionKeySize {
+		return key, nil
+	}
+	return nil, trace.BadParameter("encryption key should be %v bytes long "+
+		"either raw or hex- or base64-encoded", keyval.EncryptionKeySize)
+}
+
+// ReadConfig reads the encryption configuration from the specified
+// state directory.
+// Returns NotFound if the encryption has not been configured
+func ReadConfig(stateDir string) (*Config, error) {
+	data, err := ioutil.ReadFile(configPath(stateDir))
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := config.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &config, nil
+}
+
+// WriteConfig saves the encryption configuration in the specified state directory
+func WriteConfig(stateDir string, config Config) error {
+	if err := config.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = ioutil.WriteFile(configPath(stateDir), data, defaults.PrivateFileMask)
+	return trace.ConvertSystemError(err)
+}
+
+// LoadKey retrieves the key to encrypt the local state databases with
+// as configured for this node.
+// Returns nil if the encryption has not been configured
+func LoadKey(ctx context.Context) ([]byte, error) {
+	stateDir, err := state.GetStateDir()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	config, err := ReadConfig(stateDir)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, trace.Wrap(err)
+	}
+	key, err := config.GetKey(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return key, nil
+}
+
+func configPath(stateDir string) string {
+	return filepath.Join(stateDir, defaults.EncryptionConfigFile)
+}
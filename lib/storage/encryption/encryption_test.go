@@ -0,0 +1,75 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+func TestEncryption(t *testing.T) { TestingT(t) }
+
+type EncryptionSuite struct{}
+
+var _ = Suite(&EncryptionSuite{})
+
+func (s *EncryptionSuite) TestParsesKey(c *C) {
+	key := bytes.Repeat([]byte{0xab}, 32)
+	testCases := []struct {
+		data    []byte
+		comment string
+	}{
+		{data: key, comment: "raw key"},
+		{data: []byte(hex.EncodeToString(key) + "\n"), comment: "hex-encoded key"},
+		{data: []byte(base64.StdEncoding.EncodeToString(key)), comment: "base64-encoded key"},
+	}
+	for _, tc := range testCases {
+		out, err := ParseKey(tc.data)
+		c.Assert(err, IsNil, Commentf(tc.comment))
+		c.Assert(out, DeepEquals, key, Commentf(tc.comment))
+	}
+	_, err := ParseKey([]byte("too short"))
+	c.Assert(trace.IsBadParameter(err), Equals, true)
+}
+
+func (s *EncryptionSuite) TestReadsKeyFromFile(c *C) {
+	dir := c.MkDir()
+	_, err := ReadConfig(dir)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+
+	key := bytes.Repeat([]byte{0xcd}, 32)
+	keyFile := filepath.Join(dir, "key")
+	c.Assert(ioutil.WriteFile(keyFile, []byte(hex.EncodeToString(key)), 0600), IsNil)
+
+	c.Assert(WriteConfig(dir, Config{Provider: ProviderSSM}), NotNil)
+	c.Assert(WriteConfig(dir, Config{Provider: ProviderFile, KeyFile: keyFile}), IsNil)
+
+	config, err := ReadConfig(dir)
+	c.Assert(err, IsNil)
+	c.Assert(*config, DeepEquals, Config{Provider: ProviderFile, KeyFile: keyFile})
+	out, err := config.GetKey(context.TODO())
+	c.Assert(err, IsNil)
+	c.Assert(out, DeepEquals, key)
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
+
+	"github.com/dustin/go-humanize"
+)
+
+// EtcdHealthStatus describes the health and capacity of the cluster etcd
+// database that keeps both the Kubernetes runtime state and the Gravity
+// cluster state
+type EtcdHealthStatus struct {
+	// Updated is the time of the last health check
+	Updated time.Time `json:"updated"`
+	// Interval is how often the health is checked
+	Interval time.Duration `json:"interval"`
+	// QuotaBytes is the etcd space quota: the database size above which
+	// the members stop accepting writes
+	QuotaBytes int64 `json:"quota_bytes"`
+	// Leader is the name of the leader member, empty if the cluster has no leader
+	Leader string `json:"leader,omitempty"`
+	// RaftTerm is the current raft term, incremented with every leader election
+	RaftTerm uint64 `json:"raft_term,omitempty"`
+	// LeaderChanges lists the times the leader changes were observed
+	// within the last defaults.EtcdLeaderChangesWindow
+	LeaderChanges []time.Time `json:"leader_changes,omitempty"`
+	// ReadLatency is the latency of a linearizable read. The read waits for
+	// the member to apply the committed entries so slow applies show up here
+	ReadLatency time.Duration `json:"read_latency,omitempty"`
+	// Alarms lists the active etcd alarms
+	Alarms []EtcdAlarm `json:"alarms,omitempty"`
+	// Stores describes the usage of the database by the state stores
+	Stores []EtcdStoreUsage `json:"stores,omitempty"`
+	// Members lists the health of individual etcd members
+	Members []EtcdMemberHealth `json:"members,omitempty"`
+	// Message describes the error encountered during the last check
+	Message string `json:"message,omitempty"`
+}
+
+// EtcdAlarm describes an active etcd alarm
+type EtcdAlarm struct {
+	// Member is the name of the member that raised the alarm
+	Member string `json:"member"`
+	// Type is the alarm type: NOSPACE or CORRUPT
+	Type string `json:"type"`
+}
+
+// EtcdStoreUsage describes the number of keys in a state store
+type EtcdStoreUsage struct {
+	// Name is the store name, e.g. kubernetes or gravity
+	Name string `json:"name"`
+	// Prefix is the key prefix of the store
+	Prefix string `json:"prefix"`
+	// Keys is the number of keys in the store
+	Keys int64 `json:"keys"`
+}
+
+// EtcdMemberHealth describes the health of a single etcd member
+type EtcdMemberHealth struct {
+	// Name is the member name
+	Name string `json:"name"`
+	// Endpoint is the member client URL
+	Endpoint string `json:"endpoint"`
+	// DBSize is the size of the member database in bytes
+	DBSize int64 `json:"db_size"`
+	// RaftIndex is the raft index of the member
+	RaftIndex uint64 `json:"raft_index,omitempty"`
+	// RaftIndexLag is the number of raft entries the member is behind the leader
+	RaftIndexLag uint64 `json:"raft_index_lag,omitempty"`
+	// Message describes the error encountered querying the member
+	Message string `json:"message,omitempty"`
+}
+
+// QuotaUsage returns the percentage of the space quota used
+// by the largest member database
+func (r EtcdHealthStatus) QuotaUsage() int {
+	if r.QuotaBytes <= 0 {
+		return 0
+	}
+	var size int64
+	for _, member := range r.Members {
+		if member.DBSize > size {
+			size = member.DBSize
+		}
+	}
+	return int(size * 100 / r.QuotaBytes)
+}
+
+// IsStale returns true if the status has not been updated
+// for several check intervals as of the specified time
+func (r EtcdHealthStatus) IsStale(now time.Time) bool {
+	interval := r.Interval
+	if interval == 0 {
+		interval = defaults.EtcdHealthInterval
+	}
+	return now.Sub(r.Updated) > 3*interval
+}
+
+// EtcdProblem describes a problem with the etcd database
+type EtcdProblem struct {
+	// Member is the name of the member with the problem,
+	// empty if the problem concerns the whole cluster
+	Member string `json:"member,omitempty"`
+	// Critical is true if the problem makes the cluster unavailable
+	// or is about to
+	Critical bool `json:"critical,omitempty"`
+	// Message describes the problem
+	Message string `json:"message"`
+}
+
+// String returns a textual representation of the problem
+func (r EtcdProblem) String() string {
+	if r.Member == "" {
+		return r.Message
+	}
+	return fmt.Sprintf("member %v: %v", r.Member, r.Message)
+}
+
+// Problems returns the problems found in the status as of the specified time
+func (r EtcdHealthStatus) Problems(now time.Time) (problems []EtcdProblem) {
+	if r.IsStale(now) {
+		return []EtcdProblem{{
+			Message: fmt.Sprintf("etcd health has not been checked since %v",
+				r.Updated.UTC().Format(constants.HumanDateFormatSeconds)),
+		}}
+	}
+	if r.Message != "" {
+		problems = append(problems, EtcdProblem{Message: r.Message})
+	}
+	if r.Leader == "" && r.Message == "" {
+		problems = append(problems, EtcdProblem{
+			Critical: true,
+			Message:  "etcd cluster has no leader",
+		})
+	}
+	for _, alarm := range r.Alarms {
+		problem := EtcdProblem{
+			Member:   alarm.Member,
+			Critical: true,
+			Message:  fmt.Sprintf("%v alarm is active", alarm.Type),
+		}
+		if alarm.Type == EtcdAlarmNoSpace {
+			problem.Message = "NOSPACE alarm is active: the database has exceeded the space " +
+				"quota and only accepts reads and deletes"
+		}
+		problems = append(problems, problem)
+	}
+	for _, member := range r.Members {
+		problems = append(problems, r.memberProblems(member)...)
+	}
+	if len(r.LeaderChanges) >= defaults.EtcdLeaderChangesThreshold {
+		problems = append(problems, EtcdProblem{
+			Message: fmt.Sprintf("%v leader changes in the last %v",
+				len(r.LeaderChanges), defaults.EtcdLeaderChangesWindow),
+		})
+	}
+	if r.ReadLatency > defaults.EtcdSlowReadThreshold {
+		problems = append(problems, EtcdProblem{
+			Message: fmt.Sprintf("slow linearizable read (%v): the members may be "+
+				"applying entries slowly, check the disk latency", r.ReadLatency.Round(time.Millisecond)),
+		})
+	}
+	return problems
+}
+
+func (r EtcdHealthStatus) memberProblems(member EtcdMemberHealth) []EtcdProblem {
+	if member.Message != "" {
+		return []EtcdProblem{{Member: member.Name, Message: member.Message}}
+	}
+	var problems []EtcdProblem
+	if r.QuotaBytes > 0 {
+		usage := int(member.DBSize * 100 / r.QuotaBytes)
+		if usage >= defaults.EtcdQuotaWarningThreshold {
+			problems = append(problems, EtcdProblem{
+				Member:   member.Name,
+				Critical: usage >= defaults.EtcdQuotaCriticalThreshold,
+				Message: fmt.Sprintf("database is %v%% of the space quota (%v of %v), "+
+					"the members stop accepting writes when the quota is exceeded",
+					usage, humanize.Bytes(uint64(member.DBSize)), humanize.Bytes(uint64(r.QuotaBytes))),
+			})
+		}
+	}
+	if member.RaftIndexLag > defaults.EtcdRaftIndexLagThreshold {
+		problems = append(problems, EtcdProblem{
+			Member:  member.Name,
+			Message: fmt.Sprintf("%v raft entries behind the leader", member.RaftIndexLag),
+		})
+	}
+	return problems
+}
+
+const (
+	// EtcdAlarmNoSpace is raised when the database exceeds the space quota
+	EtcdAlarmNoSpace = "NOSPACE"
+)
+
+// EtcdHealth defines the interface to manage the etcd health status
+type EtcdHealth interface {
+	// UpsertEtcdHealthStatus updates the etcd health status
+	UpsertEtcdHealthStatus(clusterName string, status EtcdHealthStatus) error
+	// GetEtcdHealthStatus returns the etcd health status
+	GetEtcdHealthStatus(clusterName string) (*EtcdHealthStatus, error)
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"time"
+)
+
+// EtcdMaintenanceStatus describes the result of the periodic
+// compaction and defragmentation of the cluster etcd database
+type EtcdMaintenanceStatus struct {
+	// Updated is the time of the last maintenance run
+	Updated time.Time `json:"updated"`
+	// Revision is the etcd revision observed during the last run
+	Revision int64 `json:"revision,omitempty"`
+	// CompactedRevision is the revision the keyspace has been compacted to
+	CompactedRevision int64 `json:"compacted_revision,omitempty"`
+	// LastCompaction is the time of the last compaction
+	LastCompaction time.Time `json:"last_compaction,omitempty"`
+	// Members lists the maintenance status of individual etcd members
+	Members []EtcdMemberMaintenanceStatus `json:"members,omitempty"`
+	// Message describes the error encountered during the last run
+	Message string `json:"message,omitempty"`
+}
+
+// IsHealthy returns true if the last maintenance run
+// completed without errors
+func (r EtcdMaintenanceStatus) IsHealthy() bool {
+	if r.Message != "" {
+		return false
+	}
+	for _, member := range r.Members {
+		if member.Message != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// FindMember returns the status of the member with the specified name
+func (r EtcdMaintenanceStatus) FindMember(name string) *EtcdMemberMaintenanceStatus {
+	for i, member := range r.Members {
+		if member.Name == name {
+			return &r.Members[i]
+		}
+	}
+	return nil
+}
+
+// EtcdMemberMaintenanceStatus describes the maintenance status of a single etcd member
+type EtcdMemberMaintenanceStatus struct {
+	// Name is the member name
+	Name string `json:"name"`
+	// Endpoint is the member client URL
+	Endpoint string `json:"endpoint"`
+	// DBSize is the size of the member database in bytes
+	DBSize int64 `json:"db_size"`
+	// LastDefragmentation is the time of the last defragmentation
+	LastDefragmentation time.Time `json:"last_defragmentation,omitempty"`
+	// ReclaimedBytes is the number of bytes reclaimed by the last defragmentation
+	ReclaimedBytes int64 `json:"reclaimed_bytes,omitempty"`
+	// Message describes the error encountered for this member during the last run
+	Message string `json:"message,omitempty"`
+}
+
+// EtcdMaintenance defines the interface to manage the etcd maintenance status
+type EtcdMaintenance interface {
+	// UpsertEtcdMaintenanceStatus updates the etcd maintenance status
+	UpsertEtcdMaintenanceStatus(clusterName string, status EtcdMaintenanceStatus) error
+	// GetEtcdMaintenanceStatus returns the etcd maintenance status
+	GetEtcdMaintenanceStatus(clusterName string) (*EtcdMaintenanceStatus, error)
+}
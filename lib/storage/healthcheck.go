@@ -0,0 +1,416 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/utils"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	teleutils "github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/trace"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// HealthCheck describes a custom cluster health probe that is evaluated
+// along with the built-in cluster health checks
+type HealthCheck interface {
+	// Resource provides common resource methods
+	teleservices.Resource
+	// CheckAndSetDefaults verifies that the object is valid
+	CheckAndSetDefaults() error
+	// GetType returns the type of the probe: http, command or kubernetes
+	GetType() string
+	// GetSeverity returns the severity of the probe failure
+	GetSeverity() string
+	// GetTimeout returns the timeout of the probe
+	GetTimeout() time.Duration
+	// GetHTTP returns the HTTP probe or nil
+	GetHTTP() *HealthCheckHTTP
+	// GetCommand returns the command probe or nil
+	GetCommand() *HealthCheckCommand
+	// GetKubernetes returns the Kubernetes resource condition probe or nil
+	GetKubernetes() *HealthCheckKubernetes
+}
+
+// NewHealthCheck creates a new health check resource
+func NewHealthCheck(name string, spec HealthCheckSpecV2) HealthCheck {
+	return &HealthCheckV2{
+		Kind:    KindHealthCheck,
+		Version: teleservices.V2,
+		Metadata: teleservices.Metadata{
+			Name:      name,
+			Namespace: defaults.Namespace,
+		},
+		Spec: spec,
+	}
+}
+
+// HealthCheckV2 defines the health check resource
+type HealthCheckV2 struct {
+	// Metadata is resource metadata
+	teleservices.Metadata `json:"metadata"`
+	// Kind is a resource kind
+	Kind string `json:"kind"`
+	// Version is a resource version
+	Version string `json:"version"`
+	// Spec defines the health check
+	Spec HealthCheckSpecV2 `json:"spec"`
+}
+
+// GetType returns the type of the probe: http, command or kubernetes
+func (r *HealthCheckV2) GetType() string {
+	switch {
+	case r.Spec.HTTP != nil:
+		return HealthCheckHTTPType
+	case r.Spec.Command != nil:
+		return HealthCheckCommandType
+	case r.Spec.Kubernetes != nil:
+		return HealthCheckKubernetesType
+	}
+	return ""
+}
+
+// GetSeverity returns the severity of the probe failure
+func (r *HealthCheckV2) GetSeverity() string {
+	if r.Spec.Severity == "" {
+		return HealthCheckSeverityCritical
+	}
+	return r.Spec.Severity
+}
+
+// GetTimeout returns the timeout of the probe
+func (r *HealthCheckV2) GetTimeout() time.Duration {
+	if r.Spec.Timeout == nil || r.Spec.Timeout.Duration == 0 {
+		return defaults.HealthCheckTimeout
+	}
+	return r.Spec.Timeout.Duration
+}
+
+// GetHTTP returns the HTTP probe or nil
+func (r *HealthCheckV2) GetHTTP() *HealthCheckHTTP {
+	return r.Spec.HTTP
+}
+
+// GetCommand returns the command probe or nil
+func (r *HealthCheckV2) GetCommand() *HealthCheckCommand {
+	return r.Spec.Command
+}
+
+// GetKubernetes returns the Kubernetes resource condition probe or nil
+func (r *HealthCheckV2) GetKubernetes() *HealthCheckKubernetes {
+	return r.Spec.Kubernetes
+}
+
+// CheckAndSetDefaults checks validity of all parameters and sets defaults
+func (r *HealthCheckV2) CheckAndSetDefaults() error {
+	if r.Metadata.Name == "" {
+		return trace.BadParameter("health check name is required")
+	}
+	if errs := validation.IsDNS1123Label(r.Metadata.Name); len(errs) != 0 {
+		return trace.BadParameter("invalid health check name %q: %v",
+			r.Metadata.Name, strings.Join(errs, "; "))
+	}
+	if err := r.Metadata.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	var probes int
+	for _, set := range []bool{r.Spec.HTTP != nil, r.Spec.Command != nil, r.Spec.Kubernetes != nil} {
+		if set {
+			probes++
+		}
+	}
+	if probes != 1 {
+		return trace.BadParameter("health check %v: exactly one of http, command or kubernetes probes is required",
+			r.Metadata.Name)
+	}
+	if r.Spec.HTTP != nil {
+		if err := r.Spec.HTTP.check(); err != nil {
+			return trace.BadParameter("health check %v: %v", r.Metadata.Name, err)
+		}
+	}
+	if r.Spec.Command != nil && len(r.Spec.Command.Command) == 0 {
+		return trace.BadParameter("health check %v: command is required", r.Metadata.Name)
+	}
+	if r.Spec.Kubernetes != nil {
+		if err := r.Spec.Kubernetes.check(); err != nil {
+			return trace.BadParameter("health check %v: %v", r.Metadata.Name, err)
+		}
+	}
+	if r.Spec.Timeout != nil {
+		if r.Spec.Timeout.Duration < 0 || r.Spec.Timeout.Duration > defaults.HealthCheckMaxTimeout {
+			return trace.BadParameter("health check %v: timeout must be between 0 and %v",
+				r.Metadata.Name, defaults.HealthCheckMaxTimeout)
+		}
+	}
+	switch r.Spec.Severity {
+	case "", HealthCheckSeverityCritical, HealthCheckSeverityWarning:
+	default:
+		return trace.BadParameter("health check %v: unsupported severity %q, expected one of %v",
+			r.Metadata.Name, r.Spec.Severity,
+			[]string{HealthCheckSeverityCritical, HealthCheckSeverityWarning})
+	}
+	return nil
+}
+
+// UnmarshalHealthCheck unmarshals health check from JSON
+func UnmarshalHealthCheck(data []byte) (HealthCheck, error) {
+	if len(data) == 0 {
+		return nil, trace.BadParameter("empty health check")
+	}
+
+	jsonData, err := teleutils.ToJSON(data)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var hdr teleservices.ResourceHeader
+	err = json.Unmarshal(jsonData, &hdr)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	switch hdr.Version {
+	case teleservices.V2:
+		var check HealthCheckV2
+		err := teleutils.UnmarshalWithSchema(GetHealthCheckSchema(), &check, jsonData)
+		if err != nil {
+			return nil, trace.BadParameter("%v", err)
+		}
+		if err := check.CheckAndSetDefaults(); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &check, nil
+	}
+	return nil, trace.BadParameter(
+		"%v resource version %q is not supported", KindHealthCheck, hdr.Version)
+}
+
+// MarshalHealthCheck marshals health check into JSON
+func MarshalHealthCheck(check HealthCheck, opts ...teleservices.MarshalOption) ([]byte, error) {
+	return json.Marshal(check)
+}
+
+// HealthCheckSpecV2 defines the health check.
+// Exactly one of the probes must be specified
+type HealthCheckSpecV2 struct {
+	// HTTP probes an HTTP endpoint
+	HTTP *HealthCheckHTTP `json:"http,omitempty"`
+	// Command probes by running a command
+	Command *HealthCheckCommand `json:"command,omitempty"`
+	// Kubernetes probes a condition of a Kubernetes resource
+	Kubernetes *HealthCheckKubernetes `json:"kubernetes,omitempty"`
+	// Severity defines the effect of the probe failure: critical failures
+	// degrade the cluster, warnings are only reported
+	Severity string `json:"severity,omitempty"`
+	// Timeout is the timeout of the probe
+	Timeout *teleservices.Duration `json:"timeout,omitempty"`
+}
+
+// HealthCheckHTTP probes an HTTP endpoint
+type HealthCheckHTTP struct {
+	// URL is the URL of the endpoint
+	URL string `json:"url"`
+	// ExpectedStatus is the expected response status code.
+	// Any 2xx status code is accepted if unspecified
+	ExpectedStatus int `json:"expected_status,omitempty"`
+	// CACert is the PEM-encoded certificate authority used to verify
+	// the endpoint certificate. System roots are used if unspecified
+	CACert string `json:"ca_cert,omitempty"`
+}
+
+func (r HealthCheckHTTP) check() error {
+	if r.URL == "" {
+		return trace.BadParameter("url is required")
+	}
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return trace.BadParameter("invalid url %q: %v", r.URL, err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return trace.BadParameter("url %q must be an absolute http(s) URL", r.URL)
+	}
+	if r.ExpectedStatus != 0 && (r.ExpectedStatus < 100 || r.ExpectedStatus > 599) {
+		return trace.BadParameter("invalid expected status %v", r.ExpectedStatus)
+	}
+	return nil
+}
+
+// HealthCheckCommand probes by running a command.
+// The probe fails if the command exits with a non-zero code
+type HealthCheckCommand struct {
+	// Command is the command with arguments to run
+	Command []string `json:"command"`
+}
+
+// HealthCheckKubernetes probes a condition of a Kubernetes resource
+type HealthCheckKubernetes struct {
+	// Kind is the kind of the resource: node, pod, deployment,
+	// daemonset or statefulset
+	Kind string `json:"kind"`
+	// Namespace is the namespace of the namespaced resources
+	Namespace string `json:"namespace,omitempty"`
+	// Name is the name of the resource
+	Name string `json:"name"`
+	// Condition is the type of the condition, for example Ready
+	Condition string `json:"condition"`
+	// Status is the expected condition status, True if unspecified
+	Status string `json:"status,omitempty"`
+}
+
+// GetStatus returns the expected condition status
+func (r HealthCheckKubernetes) GetStatus() string {
+	if r.Status == "" {
+		return "True"
+	}
+	return r.Status
+}
+
+func (r HealthCheckKubernetes) check() error {
+	if !utils.StringInSlice(HealthCheckKubernetesKinds, r.Kind) {
+		return trace.BadParameter("unsupported kubernetes kind %q, expected one of %v",
+			r.Kind, HealthCheckKubernetesKinds)
+	}
+	if r.Name == "" {
+		return trace.BadParameter("kubernetes resource name is required")
+	}
+	if r.Condition == "" {
+		return trace.BadParameter("kubernetes resource condition is required")
+	}
+	switch r.Status {
+	case "", "True", "False", "Unknown":
+	default:
+		return trace.BadParameter("unsupported condition status %q, expected one of True, False or Unknown",
+			r.Status)
+	}
+	return nil
+}
+
+// HealthCheckSpecV2Schema is JSON schema for the health check
+const HealthCheckSpecV2Schema = `{
+  "type": "object",
+  "additionalProperties": false,
+  "properties": {
+    "http": {
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["url"],
+      "properties": {
+        "url": {"type": "string"},
+        "expected_status": {"type": "number"},
+        "ca_cert": {"type": "string"}
+      }
+    },
+    "command": {
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["command"],
+      "properties": {
+        "command": {"type": "array", "items": {"type": "string"}}
+      }
+    },
+    "kubernetes": {
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["kind", "name", "condition"],
+      "properties": {
+        "kind": {"type": "string"},
+        "namespace": {"type": "string"},
+        "name": {"type": "string"},
+        "condition": {"type": "string"},
+        "status": {"type": "string"}
+      }
+    },
+    "severity": {"type": "string"},
+    "timeout": {"type": "string"}
+  }
+}`
+
+// GetHealthCheckSchema returns health check schema for version V2
+func GetHealthCheckSchema() string {
+	return fmt.Sprintf(teleservices.V2SchemaTemplate, MetadataSchema,
+		HealthCheckSpecV2Schema, "")
+}
+
+// HealthCheckStatus is the result of the last evaluation of the cluster
+// health checks
+type HealthCheckStatus struct {
+	// Results lists the results of the individual health checks
+	Results []HealthCheckResult `json:"results,omitempty"`
+	// Updated is the time of the last evaluation
+	Updated time.Time `json:"updated"`
+}
+
+// Failed returns the results of the failed health checks
+// with the specified severity
+func (r HealthCheckStatus) Failed(severity string) (failed []HealthCheckResult) {
+	for _, result := range r.Results {
+		if !result.Healthy && result.Severity == severity {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// HealthCheckResult is the result of a single health check
+type HealthCheckResult struct {
+	// Name is the name of the health check
+	Name string `json:"name"`
+	// Type is the type of the health check probe
+	Type string `json:"type"`
+	// Severity is the severity of the health check failure
+	Severity string `json:"severity"`
+	// Healthy is whether the probe succeeded
+	Healthy bool `json:"healthy"`
+	// Message describes the probe failure
+	Message string `json:"message,omitempty"`
+}
+
+// String returns a textual representation of the failed health check
+func (r HealthCheckResult) String() string {
+	return fmt.Sprintf("%v (%v)", r.Name, r.Message)
+}
+
+const (
+	// HealthCheckHTTPType is the type of the HTTP endpoint probe
+	HealthCheckHTTPType = "http"
+	// HealthCheckCommandType is the type of the command probe
+	HealthCheckCommandType = "command"
+	// HealthCheckKubernetesType is the type of the Kubernetes resource condition probe
+	HealthCheckKubernetesType = "kubernetes"
+
+	// HealthCheckSeverityCritical marks the cluster degraded if the probe fails
+	HealthCheckSeverityCritical = "critical"
+	// HealthCheckSeverityWarning only reports the probe failure
+	HealthCheckSeverityWarning = "warning"
+)
+
+// HealthCheckKubernetesKinds lists the kinds of Kubernetes resources
+// supported by the health check probes
+var HealthCheckKubernetesKinds = []string{
+	"node",
+	"pod",
+	"deployment",
+	"daemonset",
+	"statefulset",
+}
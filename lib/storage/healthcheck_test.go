@@ -0,0 +1,174 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"time"
+
+	"github.com/gravitational/gravity/lib/compare"
+	"github.com/gravitational/gravity/lib/defaults"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/trace"
+	check "gopkg.in/check.v1"
+)
+
+type HealthCheckSuite struct{}
+
+var _ = check.Suite(&HealthCheckSuite{})
+
+func (s *HealthCheckSuite) TestResourceParsing(c *check.C) {
+	spec := `kind: healthcheck
+version: v2
+metadata:
+  name: payments
+spec:
+  kubernetes:
+    kind: deployment
+    namespace: default
+    name: payments
+    condition: Available
+  severity: warning
+  timeout: 5s
+`
+	healthCheck, err := UnmarshalHealthCheck([]byte(spec))
+	c.Assert(err, check.IsNil)
+	timeout := teleservices.NewDuration(5 * time.Second)
+	c.Assert(healthCheck, compare.DeepEquals, NewHealthCheck("payments", HealthCheckSpecV2{
+		Kubernetes: &HealthCheckKubernetes{
+			Kind:      "deployment",
+			Namespace: "default",
+			Name:      "payments",
+			Condition: "Available",
+		},
+		Severity: HealthCheckSeverityWarning,
+		Timeout:  &timeout,
+	}))
+	c.Assert(healthCheck.GetType(), check.Equals, HealthCheckKubernetesType)
+	c.Assert(healthCheck.GetKubernetes().GetStatus(), check.Equals, "True")
+
+	data, err := MarshalHealthCheck(healthCheck)
+	c.Assert(err, check.IsNil)
+	decoded, err := UnmarshalHealthCheck(data)
+	c.Assert(err, check.IsNil)
+	c.Assert(decoded, compare.DeepEquals, healthCheck)
+}
+
+func (s *HealthCheckSuite) TestDefaults(c *check.C) {
+	healthCheck := NewHealthCheck("disk", HealthCheckSpecV2{
+		Command: &HealthCheckCommand{Command: []string{"test", "-d", "/mnt/data"}},
+	})
+	c.Assert(healthCheck.CheckAndSetDefaults(), check.IsNil)
+	c.Assert(healthCheck.GetType(), check.Equals, HealthCheckCommandType)
+	c.Assert(healthCheck.GetTimeout(), check.Equals, defaults.HealthCheckTimeout)
+	c.Assert(healthCheck.GetSeverity(), check.Equals, HealthCheckSeverityCritical)
+}
+
+func (s *HealthCheckSuite) TestValidatesResource(c *check.C) {
+	var specs = []struct {
+		spec        string
+		description string
+	}{
+		{
+			spec: `kind: healthcheck
+version: v2
+metadata:
+  name: check
+spec: {}
+`,
+			description: "missing probe",
+		},
+		{
+			spec: `kind: healthcheck
+version: v2
+metadata:
+  name: check
+spec:
+  http:
+    url: https://api.example.com/healthz
+  command:
+    command: ["true"]
+`,
+			description: "multiple probes",
+		},
+		{
+			spec: `kind: healthcheck
+version: v2
+metadata:
+  name: check
+spec:
+  http:
+    url: /healthz
+`,
+			description: "relative url",
+		},
+		{
+			spec: `kind: healthcheck
+version: v2
+metadata:
+  name: check
+spec:
+  kubernetes:
+    kind: service
+    name: api
+    condition: Ready
+`,
+			description: "unsupported kubernetes kind",
+		},
+		{
+			spec: `kind: healthcheck
+version: v2
+metadata:
+  name: check
+spec:
+  command:
+    command: ["true"]
+  severity: fatal
+`,
+			description: "unsupported severity",
+		},
+		{
+			spec: `kind: healthcheck
+version: v2
+metadata:
+  name: check
+spec:
+  command:
+    command: ["true"]
+  timeout: 5m
+`,
+			description: "timeout too long",
+		},
+	}
+	for _, tt := range specs {
+		_, err := UnmarshalHealthCheck([]byte(tt.spec))
+		c.Assert(err, check.NotNil, check.Commentf(tt.description))
+		c.Assert(trace.IsBadParameter(err), check.Equals, true, check.Commentf(tt.description))
+	}
+}
+
+func (s *HealthCheckSuite) TestSelectsFailedResults(c *check.C) {
+	status := HealthCheckStatus{
+		Results: []HealthCheckResult{
+			{Name: "api", Severity: HealthCheckSeverityCritical, Healthy: true},
+			{Name: "queue", Severity: HealthCheckSeverityCritical, Message: "timeout"},
+			{Name: "cache", Severity: HealthCheckSeverityWarning, Message: "not ready"},
+		},
+	}
+	c.Assert(status.Failed(HealthCheckSeverityCritical), check.DeepEquals, []HealthCheckResult{status.Results[1]})
+	c.Assert(status.Failed(HealthCheckSeverityWarning), check.DeepEquals, []HealthCheckResult{status.Results[2]})
+}
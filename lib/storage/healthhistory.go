@@ -0,0 +1,86 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"reflect"
+	"time"
+)
+
+// HealthSnapshot is a point-in-time record of the cluster health.
+// The snapshots make up the cluster health history that explains when
+// and why the cluster became degraded
+type HealthSnapshot struct {
+	// Time is the time of the snapshot
+	Time time.Time `json:"time"`
+	// Status is the overall cluster health: healthy or degraded
+	Status string `json:"status"`
+	// Reason is the reason the cluster is degraded
+	Reason Reason `json:"reason,omitempty"`
+	// Message describes the failed cluster status check
+	Message string `json:"message,omitempty"`
+	// Nodes is the health of the individual cluster nodes
+	Nodes []NodeHealth `json:"nodes,omitempty"`
+	// FailedHealthChecks lists the failed custom cluster health checks
+	FailedHealthChecks []string `json:"failed_health_checks,omitempty"`
+}
+
+// IsDegraded returns true if the cluster is degraded as of this snapshot
+func (r HealthSnapshot) IsDegraded() bool {
+	return r.Status == HealthStatusDegraded
+}
+
+// Equals returns true if the snapshot records the same cluster
+// health as the other snapshot, regardless of the time
+func (r HealthSnapshot) Equals(other HealthSnapshot) bool {
+	return r.Status == other.Status &&
+		r.Reason == other.Reason &&
+		reflect.DeepEqual(r.Nodes, other.Nodes) &&
+		reflect.DeepEqual(r.FailedHealthChecks, other.FailedHealthChecks)
+}
+
+// NodeHealth is the health of a cluster node
+type NodeHealth struct {
+	// Hostname is the node hostname
+	Hostname string `json:"hostname"`
+	// AdvertiseIP is the node advertise IP address
+	AdvertiseIP string `json:"advertise_ip"`
+	// Status is the node health: healthy, degraded or offline
+	Status string `json:"status"`
+	// FailedProbes lists the failed node health probes
+	FailedProbes []string `json:"failed_probes,omitempty"`
+}
+
+// HealthHistory defines the interface to the cluster health history.
+// The history is a ring buffer of the most recent health snapshots
+type HealthHistory interface {
+	// AddHealthSnapshot records the snapshot in the health history of the
+	// specified cluster. The history keeps at most the specified number
+	// of the most recent snapshots, the oldest snapshot is overwritten
+	// when the history is full
+	AddHealthSnapshot(clusterName string, snapshot HealthSnapshot, capacity int) error
+	// GetHealthSnapshots returns the snapshots of the specified cluster
+	// recorded since the specified time, ordered from the oldest to the newest
+	GetHealthSnapshots(clusterName string, since time.Time) ([]HealthSnapshot, error)
+}
+
+const (
+	// HealthStatusHealthy is the status of the healthy cluster
+	HealthStatusHealthy = "healthy"
+	// HealthStatusDegraded is the status of the degraded cluster
+	HealthStatusDegraded = "degraded"
+)
@@ -0,0 +1,193 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/gravitational/gravity/lib/defaults"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	teleutils "github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/trace"
+)
+
+// ImageTrustPolicy defines how the signatures of container images
+// are verified before the images are imported or pushed to the cluster registry
+type ImageTrustPolicy interface {
+	// Resource provides common resource methods
+	teleservices.Resource
+	// CheckAndSetDefaults verifies that the object is valid
+	CheckAndSetDefaults() error
+	// GetMode returns the verification mode
+	GetMode() string
+	// GetPublicKeys returns the trusted public keys in PEM format
+	GetPublicKeys() []string
+}
+
+// NewImageTrustPolicy creates a new image trust policy resource
+func NewImageTrustPolicy(spec ImageTrustPolicySpecV2) ImageTrustPolicy {
+	return &ImageTrustPolicyV2{
+		Kind:    KindImageTrustPolicy,
+		Version: teleservices.V2,
+		Metadata: teleservices.Metadata{
+			Name:      KindImageTrustPolicy,
+			Namespace: defaults.Namespace,
+		},
+		Spec: spec,
+	}
+}
+
+// ImageTrustPolicyV2 defines the image trust policy resource
+type ImageTrustPolicyV2 struct {
+	// Metadata is resource metadata
+	teleservices.Metadata `json:"metadata"`
+	// Kind is a resource kind
+	Kind string `json:"kind"`
+	// Version is a resource version
+	Version string `json:"version"`
+	// Spec defines the image trust policy
+	Spec ImageTrustPolicySpecV2 `json:"spec"`
+}
+
+// GetMode returns the verification mode
+func (r *ImageTrustPolicyV2) GetMode() string {
+	return r.Spec.Mode
+}
+
+// GetPublicKeys returns the trusted public keys in PEM format
+func (r *ImageTrustPolicyV2) GetPublicKeys() []string {
+	return r.Spec.PublicKeys
+}
+
+// CheckAndSetDefaults checks validity of all parameters and sets defaults
+func (r *ImageTrustPolicyV2) CheckAndSetDefaults() error {
+	if r.Metadata.Name == "" {
+		r.Metadata.Name = KindImageTrustPolicy
+	}
+	if err := r.Metadata.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	switch r.Spec.Mode {
+	case "":
+		r.Spec.Mode = ImageTrustModePermissive
+	case ImageTrustModeDisabled, ImageTrustModePermissive, ImageTrustModeEnforcing:
+	default:
+		return trace.BadParameter("unsupported image trust policy mode %q, supported are: %q, %q, %q",
+			r.Spec.Mode, ImageTrustModeDisabled, ImageTrustModePermissive, ImageTrustModeEnforcing)
+	}
+	if r.Spec.Mode != ImageTrustModeDisabled && len(r.Spec.PublicKeys) == 0 {
+		return trace.BadParameter("image trust policy should list at least one public key")
+	}
+	for i, key := range r.Spec.PublicKeys {
+		if _, err := ParsePublicKeyPEM([]byte(key)); err != nil {
+			return trace.Wrap(err, "invalid public key #%v", i+1)
+		}
+	}
+	return nil
+}
+
+// ParsePublicKeyPEM parses the public key in PEM format
+func ParsePublicKeyPEM(data []byte) (interface{}, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, trace.BadParameter("expected a PEM-encoded public key")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, trace.BadParameter("failed to parse public key: %v", err)
+	}
+	return key, nil
+}
+
+// UnmarshalImageTrustPolicy unmarshals image trust policy from JSON
+func UnmarshalImageTrustPolicy(data []byte) (ImageTrustPolicy, error) {
+	if len(data) == 0 {
+		return nil, trace.BadParameter("empty image trust policy")
+	}
+
+	jsonData, err := teleutils.ToJSON(data)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var hdr teleservices.ResourceHeader
+	err = json.Unmarshal(jsonData, &hdr)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	switch hdr.Version {
+	case teleservices.V2:
+		var policy ImageTrustPolicyV2
+		err := teleutils.UnmarshalWithSchema(GetImageTrustPolicySchema(), &policy, jsonData)
+		if err != nil {
+			return nil, trace.BadParameter("%v", err)
+		}
+		if err := policy.CheckAndSetDefaults(); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &policy, nil
+	}
+	return nil, trace.BadParameter(
+		"%v resource version %q is not supported", KindImageTrustPolicy, hdr.Version)
+}
+
+// MarshalImageTrustPolicy marshals image trust policy into JSON
+func MarshalImageTrustPolicy(policy ImageTrustPolicy, opts ...teleservices.MarshalOption) ([]byte, error) {
+	return json.Marshal(policy)
+}
+
+// ImageTrustPolicySpecV2 defines the image trust policy
+type ImageTrustPolicySpecV2 struct {
+	// Mode is the verification mode:
+	//  - disabled: signatures are not verified
+	//  - permissive: images without a valid signature are reported but accepted
+	//  - enforcing: images without a valid signature are refused
+	Mode string `json:"mode,omitempty"`
+	// PublicKeys lists the PEM-encoded public keys trusted to sign images.
+	// An image is trusted if it has a valid signature from any of the keys
+	PublicKeys []string `json:"public_keys,omitempty"`
+}
+
+const (
+	// ImageTrustModeDisabled disables image signature verification
+	ImageTrustModeDisabled = "disabled"
+	// ImageTrustModePermissive reports images without a valid signature
+	ImageTrustModePermissive = "permissive"
+	// ImageTrustModeEnforcing refuses images without a valid signature
+	ImageTrustModeEnforcing = "enforcing"
+)
+
+// ImageTrustPolicySpecV2Schema is JSON schema for the image trust policy
+const ImageTrustPolicySpecV2Schema = `{
+  "type": "object",
+  "additionalProperties": false,
+  "properties": {
+    "mode": {"type": "string"},
+    "public_keys": {"type": "array", "items": {"type": "string"}}
+  }
+}`
+
+// GetImageTrustPolicySchema returns image trust policy schema for version V2
+func GetImageTrustPolicySchema() string {
+	return fmt.Sprintf(teleservices.V2SchemaTemplate, MetadataSchema,
+		ImageTrustPolicySpecV2Schema, "")
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"github.com/gravitational/gravity/lib/compare"
+
+	"github.com/gravitational/trace"
+	check "gopkg.in/check.v1"
+)
+
+type ImageTrustPolicySuite struct{}
+
+var _ = check.Suite(&ImageTrustPolicySuite{})
+
+func (s *ImageTrustPolicySuite) TestResourceParsing(c *check.C) {
+	spec := `kind: imagetrustpolicy
+version: v2
+spec:
+  mode: enforcing
+  public_keys:
+  - |
+    -----BEGIN PUBLIC KEY-----
+    MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAErj2DK52nklOOt5Ig3f0mHWdehZbS
+    udEGw6WtS1E3Xj9FXfd6MUX7NcrHr0Fk4P3A8vJGJMjqvMKGtD4jQWNPog==
+    -----END PUBLIC KEY-----
+`
+	policy, err := UnmarshalImageTrustPolicy([]byte(spec))
+	c.Assert(err, check.IsNil)
+	c.Assert(policy, compare.DeepEquals, NewImageTrustPolicy(ImageTrustPolicySpecV2{
+		Mode:       ImageTrustModeEnforcing,
+		PublicKeys: []string{testPublicKey + "\n"},
+	}))
+
+	data, err := MarshalImageTrustPolicy(policy)
+	c.Assert(err, check.IsNil)
+	decoded, err := UnmarshalImageTrustPolicy(data)
+	c.Assert(err, check.IsNil)
+	c.Assert(decoded, compare.DeepEquals, policy)
+}
+
+func (s *ImageTrustPolicySuite) TestDefaultsToPermissiveMode(c *check.C) {
+	policy := NewImageTrustPolicy(ImageTrustPolicySpecV2{
+		PublicKeys: []string{testPublicKey},
+	})
+	c.Assert(policy.CheckAndSetDefaults(), check.IsNil)
+	c.Assert(policy.GetMode(), check.Equals, ImageTrustModePermissive)
+}
+
+func (s *ImageTrustPolicySuite) TestValidatesResource(c *check.C) {
+	var specs = []struct {
+		spec        string
+		description string
+	}{
+		{
+			spec: `kind: imagetrustpolicy
+version: v2
+spec:
+  mode: enforcing
+`,
+			description: "no public keys",
+		},
+		{
+			spec: `kind: imagetrustpolicy
+version: v2
+spec:
+  mode: strict
+  public_keys: ["key"]
+`,
+			description: "invalid mode",
+		},
+		{
+			spec: `kind: imagetrustpolicy
+version: v2
+spec:
+  public_keys: ["not a key"]
+`,
+			description: "invalid public key",
+		},
+	}
+	for _, tt := range specs {
+		_, err := UnmarshalImageTrustPolicy([]byte(tt.spec))
+		c.Assert(err, check.NotNil, check.Commentf(tt.description))
+		c.Assert(trace.IsBadParameter(err), check.Equals, true, check.Commentf(tt.description))
+	}
+}
+
+const testPublicKey = `-----BEGIN PUBLIC KEY-----
+MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAErj2DK52nklOOt5Ig3f0mHWdehZbS
+udEGw6WtS1E3Xj9FXfd6MUX7NcrHr0Fk4P3A8vJGJMjqvMKGtD4jQWNPog==
+-----END PUBLIC KEY-----`
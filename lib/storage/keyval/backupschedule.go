@@ -0,0 +1,86 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// UpsertBackupSchedule creates or updates the backup schedule
+// of the specified cluster
+func (b *backend) UpsertBackupSchedule(clusterName string, schedule storage.BackupSchedule) error {
+	if err := schedule.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	data, err := storage.MarshalBackupSchedule(schedule)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = b.upsertValBytes(b.key(sitesP, clusterName, backupScheduleP), data, forever)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// GetBackupSchedule returns the backup schedule of the specified cluster
+func (b *backend) GetBackupSchedule(clusterName string) (storage.BackupSchedule, error) {
+	data, err := b.getValBytes(b.key(sitesP, clusterName, backupScheduleP))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("backup schedule not found")
+		}
+		return nil, trace.Wrap(err)
+	}
+	return storage.UnmarshalBackupSchedule(data)
+}
+
+// DeleteBackupSchedule deletes the backup schedule of the specified cluster
+func (b *backend) DeleteBackupSchedule(clusterName string) error {
+	err := b.deleteKey(b.key(sitesP, clusterName, backupScheduleP))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return trace.NotFound("backup schedule not found")
+		}
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// UpsertBackupStatus updates the status of the scheduled backups
+func (b *backend) UpsertBackupStatus(clusterName string, status storage.BackupStatus) error {
+	err := b.upsertVal(b.key(sitesP, clusterName, backupStatusP), status, forever)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// GetBackupStatus returns the status of the scheduled backups
+func (b *backend) GetBackupStatus(clusterName string) (*storage.BackupStatus, error) {
+	var status storage.BackupStatus
+	err := b.getVal(b.key(sitesP, clusterName, backupStatusP), &status)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("backup status not found")
+		}
+		return nil, trace.Wrap(err)
+	}
+	return &status, nil
+}
@@ -25,6 +25,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/utils"
@@ -41,6 +42,12 @@ func NewBolt(cfg BoltConfig) (storage.Backend, error) {
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+	if len(cfg.EncryptionKey) != 0 && !cfg.Readonly {
+		err = encryptValues(cfg)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
 	var engine kvengine
 	if cfg.Multi {
 		engine, err = newMultiBolt(cfg)
@@ -54,9 +61,14 @@ func NewBolt(cfg BoltConfig) (storage.Backend, error) {
 	if clock == nil {
 		clock = clockwork.NewRealClock()
 	}
+	wrapped, err := cfg.SecretsConfig.wrap(clock, cfg.ChangelogConfig.wrap(newMetricsEngine(engine, constants.BoltBackend), clock))
+	if err != nil {
+		engine.Close()
+		return nil, trace.Wrap(err)
+	}
 	return &backend{
 		Clock:    clock,
-		kvengine: engine,
+		kvengine: wrapped,
 	}, nil
 }
 
@@ -75,6 +87,14 @@ type BoltConfig struct {
 	// This option is only available on Darwin and Linux.
 	// Use NoTimeout to make the operation non-blocking
 	Timeout time.Duration
+	// EncryptionKey is the optional key to encrypt the stored values with.
+	// Values written before the encryption has been enabled are encrypted
+	// when the database is opened in read-write mode
+	EncryptionKey []byte `json:"-"`
+	// ChangelogConfig optionally enables the state changelog
+	ChangelogConfig
+	// SecretsConfig optionally configures the external storage of secrets
+	SecretsConfig
 }
 
 // NoTimeout defines a special duration value indicating that the blocking operation
@@ -101,6 +121,10 @@ func (b *BoltConfig) CheckAndSetDefaults() error {
 	if b.Timeout == 0 {
 		b.Timeout = defaults.DBOpenTimeout
 	}
+	if len(b.EncryptionKey) != 0 && len(b.EncryptionKey) != EncryptionKeySize {
+		return trace.BadParameter("encryption key should be %v bytes long, got %v",
+			EncryptionKeySize, len(b.EncryptionKey))
+	}
 	return nil
 }
 
@@ -114,6 +138,8 @@ type blt struct {
 	clock clockwork.Clock
 	path  string
 	locks map[string]time.Time
+	// cipher encrypts the stored values if the encryption is enabled
+	cipher *valueCipher
 }
 
 // newBolt returns a new instance of BoltDB backend
@@ -136,6 +162,12 @@ func newBolt(cfg BoltConfig, codec Codec) (*blt, error) {
 	if b.clock == nil {
 		b.clock = clockwork.NewRealClock()
 	}
+	if len(cfg.EncryptionKey) != 0 {
+		b.cipher, err = newValueCipher(cfg.EncryptionKey)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
 
 	// When opening bolt in read-only mode, make sure bolt properly initializes
 	// the database file in case no database file exists before applying
@@ -284,7 +316,7 @@ func (b *blt) createValBytes(k key, data []byte, ttl time.Duration) error {
 		if val != nil {
 			return trace.AlreadyExists("%v already exists", key)
 		}
-		return bkt.Put([]byte(key), data)
+		return b.put(bkt, k, data)
 	})
 }
 
@@ -303,18 +335,18 @@ func (b *blt) createVal(k key, val interface{}, ttl time.Duration) error {
 		if val != nil {
 			return trace.AlreadyExists("'%v' already exists", key)
 		}
-		return bkt.Put([]byte(key), encoded)
+		return b.put(bkt, k, encoded)
 	})
 }
 
 func (b *blt) upsertValBytes(k key, encoded []byte, ttl time.Duration) error {
-	buckets, key := b.split(k)
+	buckets, _ := b.split(k)
 	return b.db.Update(func(tx *bolt.Tx) error {
 		bkt, err := upsertBucket(tx, buckets)
 		if err != nil {
 			return trace.Wrap(err)
 		}
-		return bkt.Put([]byte(key), encoded)
+		return b.put(bkt, k, encoded)
 	})
 }
 
@@ -323,13 +355,13 @@ func (b *blt) upsertVal(k key, val interface{}, ttl time.Duration) error {
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	buckets, key := b.split(k)
+	buckets, _ := b.split(k)
 	return b.db.Update(func(tx *bolt.Tx) error {
 		bkt, err := upsertBucket(tx, buckets)
 		if err != nil {
 			return trace.Wrap(err)
 		}
-		return bkt.Put([]byte(key), encoded)
+		return b.put(bkt, k, encoded)
 	})
 }
 
@@ -344,7 +376,7 @@ func (b *blt) updateValBytes(k key, data []byte, ttl time.Duration) error {
 		if val == nil {
 			return trace.NotFound("%q not found", key)
 		}
-		return bkt.Put([]byte(key), data)
+		return b.put(bkt, k, data)
 	})
 }
 
@@ -363,7 +395,7 @@ func (b *blt) updateVal(k key, val interface{}, ttl time.Duration) error {
 		if val == nil {
 			return trace.NotFound("%q not found", key)
 		}
-		return bkt.Put([]byte(key), encoded)
+		return b.put(bkt, k, encoded)
 	})
 }
 
@@ -405,12 +437,15 @@ func (b *blt) compareAndSwapBytes(k key, val, prevVal []byte, outVal *[]byte, tt
 		if err != nil {
 			return trace.Wrap(err)
 		}
-		currentVal := bkt.Get([]byte(key))
+		currentVal, err := b.get(bkt, k)
+		if err != nil {
+			return trace.Wrap(err)
+		}
 		if prevVal == nil { // we don't expect the value to exist
 			if currentVal != nil {
 				return trace.AlreadyExists("key %q already exists", key)
 			}
-			return trace.Wrap(bkt.Put([]byte(key), val))
+			return trace.Wrap(b.put(bkt, k, val))
 		} else { // we expect the previous value to exist
 			if val == nil {
 				return trace.NotFound("key %q not found", key)
@@ -419,7 +454,7 @@ func (b *blt) compareAndSwapBytes(k key, val, prevVal []byte, outVal *[]byte, tt
 				return trace.CompareFailed("expected %q got %q",
 					string(prevVal), string(currentVal))
 			}
-			err = bkt.Put([]byte(key), val)
+			err = b.put(bkt, k, val)
 			if err != nil {
 				return trace.Wrap(err)
 			}
@@ -437,7 +472,10 @@ func (b *blt) getValBytes(k key) ([]byte, error) {
 		if err != nil {
 			return trace.Wrap(err)
 		}
-		bytes := bkt.Get([]byte(key))
+		bytes, err := b.get(bkt, k)
+		if err != nil {
+			return trace.Wrap(err)
+		}
 		if bytes == nil {
 			_, err := getBucket(tx, append(buckets, key))
 			if err == nil {
@@ -462,7 +500,10 @@ func (b *blt) getVal(k key, outVal interface{}) error {
 		if err != nil {
 			return trace.Wrap(err)
 		}
-		bytes := bkt.Get([]byte(key))
+		bytes, err := b.get(bkt, k)
+		if err != nil {
+			return trace.Wrap(err)
+		}
 		if bytes == nil {
 			_, err := getBucket(tx, append(buckets, key))
 			if err == nil {
@@ -481,7 +522,10 @@ func (b *blt) compareAndDelete(k key, prevVal interface{}) error {
 		if err != nil {
 			return trace.Wrap(err)
 		}
-		bytes := bkt.Get([]byte(key))
+		bytes, err := b.get(bkt, k)
+		if err != nil {
+			return trace.Wrap(err)
+		}
 		if bytes == nil {
 			return trace.NotFound("%v is not found", key)
 		}
@@ -587,6 +631,178 @@ func (b *blt) Close() error {
 	return nil
 }
 
+// put stores the value with the specified key in the bucket encrypting it
+// if the encryption is enabled.
+// The key is the full path to the value and the bucket is its parent
+func (b *blt) put(bkt *bolt.Bucket, k key, value []byte) error {
+	_, key := b.split(k)
+	if b.cipher == nil {
+		return bkt.Put([]byte(key), value)
+	}
+	encrypted, err := b.cipher.seal(value, additionalData(k))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return bkt.Put([]byte(key), encrypted)
+}
+
+// get returns the value with the specified key from the bucket decrypting it
+// if necessary. Returns nil if there is no value with the specified key.
+//
+// Once the values in the database have been encrypted, plaintext values
+// are rejected so they can't be used to replace the encrypted ones
+func (b *blt) get(bkt *bolt.Bucket, k key) ([]byte, error) {
+	_, key := b.split(k)
+	value := bkt.Get([]byte(key))
+	if value == nil {
+		return nil, nil
+	}
+	if !isEncrypted(value) {
+		if isMigrated(bkt.Tx()) {
+			return nil, trace.AccessDenied("value %q is not encrypted", key)
+		}
+		return value, nil
+	}
+	if b.cipher == nil {
+		return nil, trace.AccessDenied("value %q is encrypted but "+
+			"no encryption key has been configured", key)
+	}
+	return b.cipher.open(value, additionalData(k))
+}
+
+// encryptValues encrypts the values in the database that have been
+// written before the encryption has been enabled.
+//
+// The values are encrypted in place in a single transaction while holding
+// the database file lock, so other users of the database, including those
+// in multi-client mode, wait for the migration to complete instead of
+// writing to a replaced file. The free pages are zeroed out afterwards
+// since they would otherwise retain the replaced plaintext values
+func encryptValues(cfg BoltConfig) error {
+	b, err := newBolt(cfg, &v1codec{})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer b.Close()
+	var count int
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		if isMigrated(tx) {
+			return nil
+		}
+		err := tx.ForEach(func(name []byte, bkt *bolt.Bucket) error {
+			encrypted, err := b.encryptBucket(bkt, key{string(name)})
+			count += encrypted
+			return trace.Wrap(err)
+		})
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		return trace.Wrap(setMigrated(tx))
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if count == 0 {
+		return nil
+	}
+	if err := zeroFreePages(b.db); err != nil {
+		return trace.Wrap(err)
+	}
+	b.Debugf("Encrypted %v values.", count)
+	return nil
+}
+
+// encryptBucket encrypts the plaintext values in the bucket with the specified
+// path and its nested buckets and returns the number of encrypted values
+func (b *blt) encryptBucket(bkt *bolt.Bucket, path key) (count int, err error) {
+	// the bucket can't be modified while iterating over it, and the keys
+	// have to be copied as the update can remap the database
+	var keys, buckets [][]byte
+	err = bkt.ForEach(func(key, value []byte) error {
+		switch {
+		case value == nil:
+			buckets = append(buckets, append([]byte(nil), key...))
+		case !isEncrypted(value):
+			keys = append(keys, append([]byte(nil), key...))
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, trace.Wrap(err)
+	}
+	for _, key := range keys {
+		if err := b.put(bkt, subkey(path, key), bkt.Get(key)); err != nil {
+			return count, trace.Wrap(err)
+		}
+		count++
+	}
+	for _, key := range buckets {
+		encrypted, err := b.encryptBucket(bkt.Bucket(key), subkey(path, key))
+		count += encrypted
+		if err != nil {
+			return count, trace.Wrap(err)
+		}
+	}
+	return count, nil
+}
+
+// subkey returns the path to the specified key in the bucket with the given path
+func subkey(path key, name []byte) key {
+	return append(append(key(nil), path...), string(name))
+}
+
+// isMigrated returns true if the values in the database have been encrypted
+func isMigrated(tx *bolt.Tx) bool {
+	bkt := tx.Bucket([]byte(encryptionBucket))
+	return bkt != nil && bkt.Get([]byte(migratedKey)) != nil
+}
+
+// setMigrated marks the values in the database as encrypted
+func setMigrated(tx *bolt.Tx) error {
+	bkt, err := tx.CreateBucketIfNotExists([]byte(encryptionBucket))
+	if err != nil {
+		return trace.Wrap(boltErr(err))
+	}
+	return trace.Wrap(bkt.Put([]byte(migratedKey), []byte("true")))
+}
+
+// zeroFreePages overwrites the free pages of the database with zeros.
+//
+// The writable transaction releases the pages freed by the earlier
+// transactions and blocks the writers until the free pages have been zeroed.
+// It is rolled back so none of the zeroed pages is reused by its commit
+func zeroFreePages(db *bolt.DB) error {
+	tx, err := db.Begin(true)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer tx.Rollback()
+	f, err := os.OpenFile(db.Path(), os.O_WRONLY, 0)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	pageSize := db.Info().PageSize
+	zeros := make([]byte, pageSize)
+	// the first two pages are the meta pages
+	for id := 2; ; id++ {
+		page, err := tx.Page(id)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if page == nil {
+			break
+		}
+		if page.Type != "free" {
+			continue
+		}
+		if _, err := f.WriteAt(zeros, int64(id)*int64(pageSize)); err != nil {
+			return trace.ConvertSystemError(err)
+		}
+	}
+	return trace.ConvertSystemError(f.Sync())
+}
+
 func boltErr(err error) error {
 	if err == bolt.ErrBucketNotFound {
 		return trace.NotFound(err.Error())
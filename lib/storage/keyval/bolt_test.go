@@ -169,6 +169,10 @@ func (s *BSuite) TestUpdatesAppImportOperation(c *C) {
 	s.suite.UpdatesAppImportOperation(c)
 }
 
+func (s *BSuite) TestOperationPlanChangelog(c *C) {
+	s.suite.OperationPlanChangelog(c)
+}
+
 func (s *BSuite) TestConnectors(c *C) {
 	s.suite.ConnectorsCRUD(c)
 }
@@ -240,3 +244,63 @@ func (s *BSuite) TestClusterLogin(c *C) {
 func (s *BSuite) TestIndexFile(c *C) {
 	s.suite.IndexFile(c)
 }
+
+func (s *BSuite) TestNodePoolsCRUD(c *C) {
+	s.suite.NodePoolsCRUD(c)
+}
+
+func (s *BSuite) TestReleasesCRUD(c *C) {
+	s.suite.ReleasesCRUD(c)
+}
+
+func (s *BSuite) TestClusterRosterCRUD(c *C) {
+	s.suite.ClusterRosterCRUD(c)
+}
+
+func (s *BSuite) TestEtcdMaintenanceStatusCRUD(c *C) {
+	s.suite.EtcdMaintenanceStatusCRUD(c)
+}
+
+func (s *BSuite) TestEtcdHealthStatusCRUD(c *C) {
+	s.suite.EtcdHealthStatusCRUD(c)
+}
+
+func (s *BSuite) TestClusterEventsCRUD(c *C) {
+	s.suite.ClusterEventsCRUD(c)
+}
+
+func (s *BSuite) TestOperationWebhooksCRUD(c *C) {
+	s.suite.OperationWebhooksCRUD(c)
+}
+
+func (s *BSuite) TestHealthChecksCRUD(c *C) {
+	s.suite.HealthChecksCRUD(c)
+}
+
+func (s *BSuite) TestHealthHistory(c *C) {
+	s.suite.HealthHistory(c)
+}
+
+func (s *BSuite) TestImageTrustPolicyCRUD(c *C) {
+	s.suite.ImageTrustPolicyCRUD(c)
+}
+
+func (s *BSuite) TestOperationApprovalPolicyCRUD(c *C) {
+	s.suite.OperationApprovalPolicyCRUD(c)
+}
+
+func (s *BSuite) TestCatalogSyncScheduleCRUD(c *C) {
+	s.suite.CatalogSyncScheduleCRUD(c)
+}
+
+func (s *BSuite) TestBackupScheduleCRUD(c *C) {
+	s.suite.BackupScheduleCRUD(c)
+}
+
+func (s *BSuite) TestReplicationCRUD(c *C) {
+	s.suite.ReplicationCRUD(c)
+}
+
+func (s *BSuite) TestDeviceAuthRequestCRUD(c *C) {
+	s.suite.DeviceAuthRequestCRUD(c)
+}
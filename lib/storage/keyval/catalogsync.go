@@ -0,0 +1,64 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// UpsertCatalogSyncSchedule creates or updates the catalog sync schedule
+// of the specified cluster
+func (b *backend) UpsertCatalogSyncSchedule(clusterName string, schedule storage.CatalogSyncSchedule) error {
+	if err := schedule.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	data, err := storage.MarshalCatalogSyncSchedule(schedule)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = b.upsertValBytes(b.key(sitesP, clusterName, catalogSyncP), data, forever)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// GetCatalogSyncSchedule returns the catalog sync schedule of the specified cluster
+func (b *backend) GetCatalogSyncSchedule(clusterName string) (storage.CatalogSyncSchedule, error) {
+	data, err := b.getValBytes(b.key(sitesP, clusterName, catalogSyncP))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("catalog sync schedule not found")
+		}
+		return nil, trace.Wrap(err)
+	}
+	return storage.UnmarshalCatalogSyncSchedule(data)
+}
+
+// DeleteCatalogSyncSchedule deletes the catalog sync schedule of the specified cluster
+func (b *backend) DeleteCatalogSyncSchedule(clusterName string) error {
+	err := b.deleteKey(b.key(sitesP, clusterName, catalogSyncP))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return trace.NotFound("catalog sync schedule not found")
+		}
+		return trace.Wrap(err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/gravitational/gravity/lib/storage"
+
+	teleutils "github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/trace"
+)
+
+// CreateClusterEvent adds the event to the events of the specified cluster.
+// The event ID starts with the event time so the events are ordered
+// by the time they were recorded
+func (b *backend) CreateClusterEvent(clusterName string, event storage.ClusterEvent, ttl time.Duration) (*storage.ClusterEvent, error) {
+	if event.Name == "" {
+		return nil, trace.BadParameter("missing event name")
+	}
+	suffix, err := teleutils.CryptoRandomHex(4)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	event.Time = b.Now().UTC()
+	event.ID = fmt.Sprintf("%020d-%010d-%v", event.Time.UnixNano(), atomic.AddUint64(&eventSeq, 1), suffix)
+	err = b.createVal(b.key(sitesP, clusterName, eventsP, event.ID), event, ttl)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &event, nil
+}
+
+// GetClusterEvents returns at most limit events of the specified cluster
+// recorded after the event with the specified ID
+func (b *backend) GetClusterEvents(clusterName, after string, limit int) ([]storage.ClusterEvent, error) {
+	ids, err := b.getKeys(b.key(sitesP, clusterName, eventsP))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, trace.Wrap(err)
+	}
+	sort.Strings(ids)
+	start := sort.SearchStrings(ids, after)
+	if start < len(ids) && ids[start] == after {
+		start++
+	}
+	var events []storage.ClusterEvent
+	for _, id := range ids[start:] {
+		if limit > 0 && len(events) >= limit {
+			break
+		}
+		var event storage.ClusterEvent
+		err := b.getVal(b.key(sitesP, clusterName, eventsP, id), &event)
+		if err != nil {
+			if trace.IsNotFound(err) {
+				// the event has expired
+				continue
+			}
+			return nil, trace.Wrap(err)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// eventSeq orders the events recorded within the same clock tick
+var eventSeq uint64
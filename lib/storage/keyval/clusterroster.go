@@ -0,0 +1,89 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// UpsertClusterRoster creates or updates the roster of the specified cluster
+func (b *backend) UpsertClusterRoster(clusterName string, roster storage.ClusterRoster) error {
+	if err := roster.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	data, err := storage.MarshalClusterRoster(roster)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = b.upsertValBytes(b.key(sitesP, clusterName, rosterP), data, forever)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// GetClusterRoster returns the roster of the specified cluster
+func (b *backend) GetClusterRoster(clusterName string) (storage.ClusterRoster, error) {
+	data, err := b.getValBytes(b.key(sitesP, clusterName, rosterP))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("cluster roster not found")
+		}
+		return nil, trace.Wrap(err)
+	}
+	return storage.UnmarshalClusterRoster(data)
+}
+
+// DeleteClusterRoster deletes the roster of the specified cluster and its status
+func (b *backend) DeleteClusterRoster(clusterName string) error {
+	err := b.deleteKey(b.key(sitesP, clusterName, rosterP))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return trace.NotFound("cluster roster not found")
+		}
+		return trace.Wrap(err)
+	}
+	err = b.deleteKey(b.key(sitesP, clusterName, rosterStatusP))
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// UpsertClusterRosterStatus updates the roster reconciliation status
+func (b *backend) UpsertClusterRosterStatus(clusterName string, status storage.ClusterRosterStatus) error {
+	err := b.upsertVal(b.key(sitesP, clusterName, rosterStatusP), status, forever)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// GetClusterRosterStatus returns the roster reconciliation status
+func (b *backend) GetClusterRosterStatus(clusterName string) (*storage.ClusterRosterStatus, error) {
+	var status storage.ClusterRosterStatus
+	err := b.getVal(b.key(sitesP, clusterName, rosterStatusP), &status)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("cluster roster status not found")
+		}
+		return nil, trace.Wrap(err)
+	}
+	return &status, nil
+}
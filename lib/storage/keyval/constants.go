@@ -37,6 +37,7 @@ const (
 	operationsP                 = "ops"
 	appOperationsP              = "appops"
 	changelogP                  = "changelog"
+	stateChangelogP             = "statechangelog"
 	activeOperationsP           = "activeops"
 	repositoriesP               = "repos"
 	packagesP                   = "packages"
@@ -77,6 +78,28 @@ const (
 	dnsP                        = "dns"
 	chartsP                     = "charts"
 	indexP                      = "index"
+	nodePoolsP                  = "nodepools"
+	operationWebhooksP          = "operationwebhooks"
+	healthChecksP               = "healthchecks"
+	healthCheckStatusP          = "healthcheckstatus"
+	healthHistoryP              = "healthhistory"
+	healthHistoryHeadP          = "healthhistoryhead"
+	rosterP                     = "roster"
+	rosterStatusP               = "rosterstatus"
+	leaderP                     = "leader"
+	etcdMaintenanceP            = "etcdmaintenance"
+	etcdHealthP                 = "etcdhealth"
+	eventsP                     = "events"
+	imageTrustPolicyP           = "imagetrustpolicy"
+	approvalPolicyP             = "approvalpolicy"
+	catalogSyncP                = "catalogsync"
+	backupScheduleP             = "backupschedule"
+	backupStatusP               = "backupstatus"
+	replicationP                = "replication"
+	replicationStatusP          = "replicationstatus"
+	deviceP                     = "device"
+	releasesP                   = "releases"
+	secretLeasesP               = "secretleases"
 
 	// AllCollectionIDs identifies a collection without a specification (an ID)
 	AllCollectionIDs = "__all__"
@@ -0,0 +1,105 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// CreateDeviceAuthRequest creates a new device auth request
+func (b *backend) CreateDeviceAuthRequest(req storage.DeviceAuthRequest) error {
+	if err := req.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	err := b.createVal(b.key(authRequestsP, deviceP, req.DeviceCode), req, b.ttl(req.Expires))
+	if err != nil {
+		if trace.IsAlreadyExists(err) {
+			return trace.AlreadyExists("device auth request already exists")
+		}
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// UpdateDeviceAuthRequest updates the existing device auth request
+func (b *backend) UpdateDeviceAuthRequest(req storage.DeviceAuthRequest) error {
+	if err := req.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	err := b.updateVal(b.key(authRequestsP, deviceP, req.DeviceCode), req, b.ttl(req.Expires))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return trace.NotFound("device auth request not found")
+		}
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// GetDeviceAuthRequest returns the device auth request with the specified device code
+func (b *backend) GetDeviceAuthRequest(deviceCode string) (*storage.DeviceAuthRequest, error) {
+	if deviceCode == "" {
+		return nil, trace.BadParameter("missing device code")
+	}
+	var req storage.DeviceAuthRequest
+	err := b.getVal(b.key(authRequestsP, deviceP, deviceCode), &req)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("device auth request not found")
+		}
+		return nil, trace.Wrap(err)
+	}
+	return &req, nil
+}
+
+// GetDeviceAuthRequestByUserCode returns the device auth request with the specified user code
+func (b *backend) GetDeviceAuthRequestByUserCode(userCode string) (*storage.DeviceAuthRequest, error) {
+	if userCode == "" {
+		return nil, trace.BadParameter("missing user code")
+	}
+	deviceCodes, err := b.getKeys(b.key(authRequestsP, deviceP))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, deviceCode := range deviceCodes {
+		req, err := b.GetDeviceAuthRequest(deviceCode)
+		if err != nil {
+			if trace.IsNotFound(err) {
+				continue
+			}
+			return nil, trace.Wrap(err)
+		}
+		if req.UserCode == userCode {
+			return req, nil
+		}
+	}
+	return nil, trace.NotFound("device auth request not found")
+}
+
+// DeleteDeviceAuthRequest deletes the device auth request with the specified device code
+func (b *backend) DeleteDeviceAuthRequest(deviceCode string) error {
+	err := b.deleteKey(b.key(authRequestsP, deviceP, deviceCode))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return trace.NotFound("device auth request not found")
+		}
+		return trace.Wrap(err)
+	}
+	return nil
+}
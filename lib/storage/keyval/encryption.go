@@ -0,0 +1,119 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+
+	"github.com/gravitational/trace"
+)
+
+// EncryptionKeySize is the size of the database encryption key in bytes
+const EncryptionKeySize = 32
+
+// valueCipher encrypts and decrypts the stored values with AES-256-GCM.
+//
+// Encrypted values are prefixed with a header so the values written
+// before the encryption has been enabled can be told apart and encrypted.
+// The path to each value is authenticated along with it, so encrypted values
+// can't be moved between records
+type valueCipher struct {
+	aead cipher.AEAD
+}
+
+func newValueCipher(key []byte) (*valueCipher, error) {
+	if len(key) != EncryptionKeySize {
+		return nil, trace.BadParameter("encryption key should be %v bytes long, got %v",
+			EncryptionKeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &valueCipher{aead: aead}, nil
+}
+
+// seal encrypts the specified value binding it to the additional data
+func (r *valueCipher) seal(value, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, r.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	out := make([]byte, 0, len(encryptedHeader)+len(nonce)+len(value)+r.aead.Overhead())
+	out = append(out, encryptedHeader...)
+	out = append(out, nonce...)
+	return r.aead.Seal(out, nonce, value, additionalData), nil
+}
+
+// open decrypts the specified value verifying it has been sealed
+// with the same additional data.
+// Values that have not been encrypted are returned as-is
+func (r *valueCipher) open(value, additionalData []byte) ([]byte, error) {
+	if !isEncrypted(value) {
+		return value, nil
+	}
+	value = value[len(encryptedHeader):]
+	if len(value) < r.aead.NonceSize() {
+		return nil, trace.BadParameter("encrypted value is too short")
+	}
+	nonce, ciphertext := value[:r.aead.NonceSize()], value[r.aead.NonceSize():]
+	out, err := r.aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, trace.AccessDenied("failed to decrypt value, is the encryption key correct " +
+			"and does the value belong to this record?")
+	}
+	return out, nil
+}
+
+func isEncrypted(value []byte) bool {
+	return bytes.HasPrefix(value, encryptedHeader)
+}
+
+// additionalData returns the data authenticated along with the value
+// with the specified path.
+// Each path element is prefixed with its length so distinct paths
+// can't produce the same data
+func additionalData(path key) []byte {
+	var out []byte
+	buf := make([]byte, binary.MaxVarintLen64)
+	for _, elem := range path {
+		n := binary.PutUvarint(buf, uint64(len(elem)))
+		out = append(out, buf[:n]...)
+		out = append(out, elem...)
+	}
+	return out
+}
+
+// encryptedHeader prefixes the encrypted values
+var encryptedHeader = []byte("\x00gravity:aes-gcm:v1\x00")
+
+const (
+	// encryptionBucket is the top-level bucket with the encryption state
+	encryptionBucket = "encryption"
+	// migratedKey marks the database whose values have all been encrypted.
+	// Plaintext values are rejected afterwards
+	migratedKey = "migrated"
+)
@@ -0,0 +1,167 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/boltdb/bolt"
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+type EncryptionSuite struct {
+	path string
+	key  []byte
+}
+
+var _ = Suite(&EncryptionSuite{})
+
+func (s *EncryptionSuite) SetUpTest(c *C) {
+	s.path = filepath.Join(c.MkDir(), "bolt.db")
+	s.key = bytes.Repeat([]byte("k"), EncryptionKeySize)
+}
+
+func (s *EncryptionSuite) TestEncryptsValues(c *C) {
+	backend, err := NewBolt(BoltConfig{Path: s.path, EncryptionKey: s.key})
+	c.Assert(err, IsNil)
+	account, err := backend.CreateAccount(storage.Account{Org: "secret-org"})
+	c.Assert(err, IsNil)
+	out, err := backend.GetAccount(account.ID)
+	c.Assert(err, IsNil)
+	c.Assert(out.Org, Equals, "secret-org")
+	c.Assert(backend.Close(), IsNil)
+
+	s.assertNotPlaintext(c, "secret-org")
+
+	backend, err = NewBolt(BoltConfig{Path: s.path})
+	c.Assert(err, IsNil)
+	defer backend.Close()
+	_, err = backend.GetAccount(account.ID)
+	c.Assert(trace.IsAccessDenied(err), Equals, true, Commentf("%v", err))
+}
+
+func (s *EncryptionSuite) TestEncryptsExistingValues(c *C) {
+	backend, err := NewBolt(BoltConfig{Path: s.path})
+	c.Assert(err, IsNil)
+	account, err := backend.CreateAccount(storage.Account{Org: "secret-org"})
+	c.Assert(err, IsNil)
+	c.Assert(backend.Close(), IsNil)
+
+	backend, err = NewBolt(BoltConfig{Path: s.path, EncryptionKey: s.key})
+	c.Assert(err, IsNil)
+	defer backend.Close()
+	out, err := backend.GetAccount(account.ID)
+	c.Assert(err, IsNil)
+	c.Assert(out.Org, Equals, "secret-org")
+
+	s.assertNotPlaintext(c, "secret-org")
+}
+
+func (s *EncryptionSuite) TestEncryptsExistingValuesInPlace(c *C) {
+	// multi-client backends open the database file on every operation
+	// and have to keep writing to the same file after the migration
+	multi, err := NewBolt(BoltConfig{Path: s.path, Multi: true})
+	c.Assert(err, IsNil)
+	defer multi.Close()
+	account, err := multi.CreateAccount(storage.Account{Org: "secret-org"})
+	c.Assert(err, IsNil)
+	before, err := os.Stat(s.path)
+	c.Assert(err, IsNil)
+
+	backend, err := NewBolt(BoltConfig{Path: s.path, EncryptionKey: s.key, Multi: true})
+	c.Assert(err, IsNil)
+	defer backend.Close()
+	after, err := os.Stat(s.path)
+	c.Assert(err, IsNil)
+	c.Assert(os.SameFile(before, after), Equals, true, Commentf("the database file should not be replaced"))
+	s.assertNotPlaintext(c, "secret-org")
+
+	other, err := backend.CreateAccount(storage.Account{Org: "other-org"})
+	c.Assert(err, IsNil)
+	for _, id := range []string{account.ID, other.ID} {
+		_, err = backend.GetAccount(id)
+		c.Assert(err, IsNil)
+	}
+	s.assertNotPlaintext(c, "other-org")
+}
+
+func (s *EncryptionSuite) TestRejectsMovedValues(c *C) {
+	b, err := newBolt(BoltConfig{Path: s.path, EncryptionKey: s.key}, &v1codec{})
+	c.Assert(err, IsNil)
+	defer b.Close()
+	c.Assert(b.upsertValBytes(key{"root", "a"}, []byte("a"), forever), IsNil)
+	c.Assert(b.upsertValBytes(key{"root", "b"}, []byte("b"), forever), IsNil)
+
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		bkt := tx.Bucket([]byte("root"))
+		return bkt.Put([]byte("b"), append([]byte(nil), bkt.Get([]byte("a"))...))
+	})
+	c.Assert(err, IsNil)
+	_, err = b.getValBytes(key{"root", "b"})
+	c.Assert(trace.IsAccessDenied(err), Equals, true, Commentf("%v", err))
+}
+
+func (s *EncryptionSuite) TestRejectsPlaintextAfterMigration(c *C) {
+	c.Assert(encryptValues(BoltConfig{Path: s.path, EncryptionKey: s.key}), IsNil)
+	b, err := newBolt(BoltConfig{Path: s.path, EncryptionKey: s.key}, &v1codec{})
+	c.Assert(err, IsNil)
+	defer b.Close()
+
+	// the value is written bypassing the encryption
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		bkt, err := upsertBucket(tx, []string{"root"})
+		if err != nil {
+			return err
+		}
+		return bkt.Put([]byte("a"), []byte("plaintext"))
+	})
+	c.Assert(err, IsNil)
+	_, err = b.getValBytes(key{"root", "a"})
+	c.Assert(trace.IsAccessDenied(err), Equals, true, Commentf("%v", err))
+}
+
+func (s *EncryptionSuite) TestRejectsWrongKey(c *C) {
+	backend, err := NewBolt(BoltConfig{Path: s.path, EncryptionKey: s.key})
+	c.Assert(err, IsNil)
+	account, err := backend.CreateAccount(storage.Account{Org: "secret-org"})
+	c.Assert(err, IsNil)
+	c.Assert(backend.Close(), IsNil)
+
+	backend, err = NewBolt(BoltConfig{
+		Path:          s.path,
+		EncryptionKey: bytes.Repeat([]byte("x"), EncryptionKeySize),
+	})
+	c.Assert(err, IsNil)
+	defer backend.Close()
+	_, err = backend.GetAccount(account.ID)
+	c.Assert(trace.IsAccessDenied(err), Equals, true, Commentf("%v", err))
+
+	_, err = NewBolt(BoltConfig{Path: s.path, EncryptionKey: []byte("short")})
+	c.Assert(trace.IsBadParameter(err), Equals, true)
+}
+
+func (s *EncryptionSuite) assertNotPlaintext(c *C, value string) {
+	data, err := ioutil.ReadFile(s.path)
+	c.Assert(err, IsNil)
+	c.Assert(bytes.Contains(data, []byte(value)), Equals, false)
+}
@@ -24,6 +24,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/state"
 	"github.com/gravitational/gravity/lib/utils"
@@ -54,15 +55,21 @@ func NewETCD(cfg ETCDConfig) (*electingBackend, error) {
 		clock = clockwork.NewRealClock()
 	}
 
+	wrapped, err := cfg.SecretsConfig.wrap(clock, cfg.ChangelogConfig.wrap(newMetricsEngine(engine, constants.ETCDBackend), clock))
+	if err != nil {
+		engine.Close()
+		return nil, trace.Wrap(err)
+	}
+
 	leader, err := leader.NewClient(leader.Config{Client: engine.client, Clock: clock})
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
 
 	return &electingBackend{
-		Backend: &backend{
+		backend: &backend{
 			Clock:    clock,
-			kvengine: engine,
+			kvengine: wrapped,
 		},
 		Leader: leader,
 		client: engine.client,
@@ -78,6 +85,10 @@ type ETCDConfig struct {
 	TLSCertFile   string          `json:"tls_cert_file" yaml:"tls_cert_file"`
 	TLSCAFile     string          `json:"tls_ca_file" yaml:"tls_ca_file"`
 	RetryInterval time.Duration   `json:"retry_interval" yaml:"retry_interval"`
+	// ChangelogConfig optionally enables the state changelog
+	ChangelogConfig `yaml:",inline"`
+	// SecretsConfig optionally configures the external storage of secrets
+	SecretsConfig `yaml:",inline"`
 }
 
 // LocalEtcdConfig returns config for local etcd
@@ -193,6 +193,10 @@ func (s *ESuite) TestUpdatesAppImportOperation(c *C) {
 	s.suite.UpdatesAppImportOperation(c)
 }
 
+func (s *ESuite) TestOperationPlanChangelog(c *C) {
+	s.suite.OperationPlanChangelog(c)
+}
+
 func (s *ESuite) TestConnectors(c *C) {
 	s.suite.ConnectorsCRUD(c)
 }
@@ -264,3 +268,63 @@ func (s *ESuite) TestClusterLogin(c *C) {
 func (s *ESuite) TestIndexFile(c *C) {
 	s.suite.IndexFile(c)
 }
+
+func (s *ESuite) TestNodePoolsCRUD(c *C) {
+	s.suite.NodePoolsCRUD(c)
+}
+
+func (s *ESuite) TestReleasesCRUD(c *C) {
+	s.suite.ReleasesCRUD(c)
+}
+
+func (s *ESuite) TestClusterRosterCRUD(c *C) {
+	s.suite.ClusterRosterCRUD(c)
+}
+
+func (s *ESuite) TestEtcdMaintenanceStatusCRUD(c *C) {
+	s.suite.EtcdMaintenanceStatusCRUD(c)
+}
+
+func (s *ESuite) TestEtcdHealthStatusCRUD(c *C) {
+	s.suite.EtcdHealthStatusCRUD(c)
+}
+
+func (s *ESuite) TestClusterEventsCRUD(c *C) {
+	s.suite.ClusterEventsCRUD(c)
+}
+
+func (s *ESuite) TestOperationWebhooksCRUD(c *C) {
+	s.suite.OperationWebhooksCRUD(c)
+}
+
+func (s *ESuite) TestHealthChecksCRUD(c *C) {
+	s.suite.HealthChecksCRUD(c)
+}
+
+func (s *ESuite) TestHealthHistory(c *C) {
+	s.suite.HealthHistory(c)
+}
+
+func (s *ESuite) TestImageTrustPolicyCRUD(c *C) {
+	s.suite.ImageTrustPolicyCRUD(c)
+}
+
+func (s *ESuite) TestOperationApprovalPolicyCRUD(c *C) {
+	s.suite.OperationApprovalPolicyCRUD(c)
+}
+
+func (s *ESuite) TestCatalogSyncScheduleCRUD(c *C) {
+	s.suite.CatalogSyncScheduleCRUD(c)
+}
+
+func (s *ESuite) TestBackupScheduleCRUD(c *C) {
+	s.suite.BackupScheduleCRUD(c)
+}
+
+func (s *ESuite) TestReplicationCRUD(c *C) {
+	s.suite.ReplicationCRUD(c)
+}
+
+func (s *ESuite) TestDeviceAuthRequestCRUD(c *C) {
+	s.suite.DeviceAuthRequestCRUD(c)
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// UpsertEtcdHealthStatus updates the etcd health status
+func (b *backend) UpsertEtcdHealthStatus(clusterName string, status storage.EtcdHealthStatus) error {
+	err := b.upsertVal(b.key(sitesP, clusterName, etcdHealthP), status, forever)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// GetEtcdHealthStatus returns the etcd health status
+func (b *backend) GetEtcdHealthStatus(clusterName string) (*storage.EtcdHealthStatus, error) {
+	var status storage.EtcdHealthStatus
+	err := b.getVal(b.key(sitesP, clusterName, etcdHealthP), &status)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("etcd health status not found")
+		}
+		return nil, trace.Wrap(err)
+	}
+	return &status, nil
+}
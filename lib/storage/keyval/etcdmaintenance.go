@@ -0,0 +1,45 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// UpsertEtcdMaintenanceStatus updates the etcd maintenance status
+func (b *backend) UpsertEtcdMaintenanceStatus(clusterName string, status storage.EtcdMaintenanceStatus) error {
+	err := b.upsertVal(b.key(sitesP, clusterName, etcdMaintenanceP), status, forever)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// GetEtcdMaintenanceStatus returns the etcd maintenance status
+func (b *backend) GetEtcdMaintenanceStatus(clusterName string) (*storage.EtcdMaintenanceStatus, error) {
+	var status storage.EtcdMaintenanceStatus
+	err := b.getVal(b.key(sitesP, clusterName, etcdMaintenanceP), &status)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("etcd maintenance status not found")
+		}
+		return nil, trace.Wrap(err)
+	}
+	return &status, nil
+}
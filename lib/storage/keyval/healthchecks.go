@@ -0,0 +1,107 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// UpsertHealthCheck creates or updates the health check for the specified cluster
+func (b *backend) UpsertHealthCheck(clusterName string, check storage.HealthCheck) error {
+	if err := check.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	data, err := storage.MarshalHealthCheck(check)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = b.upsertValBytes(b.key(sitesP, clusterName, healthChecksP, check.GetName()), data, forever)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// GetHealthCheck returns the health check with the specified name
+func (b *backend) GetHealthCheck(clusterName, name string) (storage.HealthCheck, error) {
+	data, err := b.getValBytes(b.key(sitesP, clusterName, healthChecksP, name))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("health check %q not found", name)
+		}
+		return nil, trace.Wrap(err)
+	}
+	return storage.UnmarshalHealthCheck(data)
+}
+
+// GetHealthChecks returns all health checks of the specified cluster
+func (b *backend) GetHealthChecks(clusterName string) ([]storage.HealthCheck, error) {
+	names, err := b.getKeys(b.key(sitesP, clusterName, healthChecksP))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var checks []storage.HealthCheck
+	for _, name := range names {
+		check, err := b.GetHealthCheck(clusterName, name)
+		if err != nil {
+			if trace.IsNotFound(err) {
+				continue
+			}
+			return nil, trace.Wrap(err)
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// DeleteHealthCheck deletes the health check with the specified name
+func (b *backend) DeleteHealthCheck(clusterName, name string) error {
+	err := b.deleteKey(b.key(sitesP, clusterName, healthChecksP, name))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return trace.NotFound("health check %q not found", name)
+		}
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// UpsertHealthCheckStatus updates the result of the last evaluation
+// of the health checks of the specified cluster
+func (b *backend) UpsertHealthCheckStatus(clusterName string, status storage.HealthCheckStatus) error {
+	err := b.upsertVal(b.key(sitesP, clusterName, healthCheckStatusP), status, forever)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// GetHealthCheckStatus returns the result of the last evaluation
+// of the health checks of the specified cluster
+func (b *backend) GetHealthCheckStatus(clusterName string) (*storage.HealthCheckStatus, error) {
+	var status storage.HealthCheckStatus
+	err := b.getVal(b.key(sitesP, clusterName, healthCheckStatusP), &status)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("health check status not found")
+		}
+		return nil, trace.Wrap(err)
+	}
+	return &status, nil
+}
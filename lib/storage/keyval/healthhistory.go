@@ -0,0 +1,85 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// AddHealthSnapshot records the snapshot in the health history of the
+// specified cluster.
+//
+// The history is a ring buffer of the specified capacity: the snapshots are
+// written into the slots numbered by the head counter modulo capacity so
+// the oldest snapshot is overwritten once the history is full
+func (b *backend) AddHealthSnapshot(clusterName string, snapshot storage.HealthSnapshot, capacity int) error {
+	if capacity <= 0 {
+		return trace.BadParameter("health history capacity must be positive")
+	}
+	var head int64
+	err := b.getVal(b.key(sitesP, clusterName, healthHistoryHeadP), &head)
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	slot := fmt.Sprintf("%010d", head%int64(capacity))
+	err = b.upsertVal(b.key(sitesP, clusterName, healthHistoryP, slot), snapshot, forever)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = b.upsertVal(b.key(sitesP, clusterName, healthHistoryHeadP), head+1, forever)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// GetHealthSnapshots returns the snapshots of the specified cluster
+// recorded since the specified time, ordered from the oldest to the newest
+func (b *backend) GetHealthSnapshots(clusterName string, since time.Time) ([]storage.HealthSnapshot, error) {
+	slots, err := b.getKeys(b.key(sitesP, clusterName, healthHistoryP))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, trace.Wrap(err)
+	}
+	var snapshots []storage.HealthSnapshot
+	for _, slot := range slots {
+		var snapshot storage.HealthSnapshot
+		err := b.getVal(b.key(sitesP, clusterName, healthHistoryP, slot), &snapshot)
+		if err != nil {
+			if trace.IsNotFound(err) {
+				continue
+			}
+			return nil, trace.Wrap(err)
+		}
+		if snapshot.Time.Before(since) {
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Time.Before(snapshots[j].Time)
+	})
+	return snapshots, nil
+}
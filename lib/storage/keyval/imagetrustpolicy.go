@@ -0,0 +1,63 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// UpsertImageTrustPolicy creates or updates the image trust policy
+func (b *backend) UpsertImageTrustPolicy(policy storage.ImageTrustPolicy) error {
+	if err := policy.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	data, err := storage.MarshalImageTrustPolicy(policy)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = b.upsertValBytes(b.key(imageTrustPolicyP), data, forever)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// GetImageTrustPolicy returns the image trust policy
+func (b *backend) GetImageTrustPolicy() (storage.ImageTrustPolicy, error) {
+	data, err := b.getValBytes(b.key(imageTrustPolicyP))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("image trust policy not found")
+		}
+		return nil, trace.Wrap(err)
+	}
+	return storage.UnmarshalImageTrustPolicy(data)
+}
+
+// DeleteImageTrustPolicy deletes the image trust policy
+func (b *backend) DeleteImageTrustPolicy() error {
+	err := b.deleteKey(b.key(imageTrustPolicyP))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return trace.NotFound("image trust policy not found")
+		}
+		return trace.Wrap(err)
+	}
+	return nil
+}
@@ -25,7 +25,7 @@ import (
 )
 
 type electingBackend struct {
-	storage.Backend
+	*backend
 	storage.Leader
 	client etcd.Client
 }
@@ -0,0 +1,140 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"time"
+
+	"github.com/gravitational/gravity/lib/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metricsEngine records the latency of the requests to the wrapped engine.
+// Locks are not measured since acquiring a lock includes waiting for it
+type metricsEngine struct {
+	kvengine
+	// backend is the type of the wrapped backend
+	backend string
+}
+
+func newMetricsEngine(engine kvengine, backend string) *metricsEngine {
+	return &metricsEngine{
+		kvengine: engine,
+		backend:  backend,
+	}
+}
+
+func (e *metricsEngine) createVal(k key, val interface{}, ttl time.Duration) error {
+	defer e.observe(requestPut, time.Now())
+	return e.kvengine.createVal(k, val, ttl)
+}
+
+func (e *metricsEngine) createValBytes(k key, data []byte, ttl time.Duration) error {
+	defer e.observe(requestPut, time.Now())
+	return e.kvengine.createValBytes(k, data, ttl)
+}
+
+func (e *metricsEngine) upsertVal(k key, val interface{}, ttl time.Duration) error {
+	defer e.observe(requestPut, time.Now())
+	return e.kvengine.upsertVal(k, val, ttl)
+}
+
+func (e *metricsEngine) upsertValBytes(k key, data []byte, ttl time.Duration) error {
+	defer e.observe(requestPut, time.Now())
+	return e.kvengine.upsertValBytes(k, data, ttl)
+}
+
+func (e *metricsEngine) updateVal(k key, val interface{}, ttl time.Duration) error {
+	defer e.observe(requestPut, time.Now())
+	return e.kvengine.updateVal(k, val, ttl)
+}
+
+func (e *metricsEngine) updateValBytes(k key, data []byte, ttl time.Duration) error {
+	defer e.observe(requestPut, time.Now())
+	return e.kvengine.updateValBytes(k, data, ttl)
+}
+
+func (e *metricsEngine) updateTTL(k key, ttl time.Duration) error {
+	defer e.observe(requestPut, time.Now())
+	return e.kvengine.updateTTL(k, ttl)
+}
+
+func (e *metricsEngine) compareAndSwap(k key, val, prevVal, outVal interface{}, ttl time.Duration) error {
+	defer e.observe(requestCompareAndSwap, time.Now())
+	return e.kvengine.compareAndSwap(k, val, prevVal, outVal, ttl)
+}
+
+func (e *metricsEngine) compareAndSwapBytes(k key, val, prevVal []byte, outVal *[]byte, ttl time.Duration) error {
+	defer e.observe(requestCompareAndSwap, time.Now())
+	return e.kvengine.compareAndSwapBytes(k, val, prevVal, outVal, ttl)
+}
+
+func (e *metricsEngine) compareAndDelete(k key, prevVal interface{}) error {
+	defer e.observe(requestCompareAndSwap, time.Now())
+	return e.kvengine.compareAndDelete(k, prevVal)
+}
+
+func (e *metricsEngine) getVal(k key, val interface{}) error {
+	defer e.observe(requestGet, time.Now())
+	return e.kvengine.getVal(k, val)
+}
+
+func (e *metricsEngine) getValBytes(k key) ([]byte, error) {
+	defer e.observe(requestGet, time.Now())
+	return e.kvengine.getValBytes(k)
+}
+
+func (e *metricsEngine) getKeys(k key) ([]string, error) {
+	defer e.observe(requestList, time.Now())
+	return e.kvengine.getKeys(k)
+}
+
+func (e *metricsEngine) deleteKey(k key) error {
+	defer e.observe(requestDelete, time.Now())
+	return e.kvengine.deleteKey(k)
+}
+
+func (e *metricsEngine) createDir(k key, ttl time.Duration) error {
+	defer e.observe(requestPut, time.Now())
+	return e.kvengine.createDir(k, ttl)
+}
+
+func (e *metricsEngine) upsertDir(k key, ttl time.Duration) error {
+	defer e.observe(requestPut, time.Now())
+	return e.kvengine.upsertDir(k, ttl)
+}
+
+func (e *metricsEngine) deleteDir(k key) error {
+	defer e.observe(requestDelete, time.Now())
+	return e.kvengine.deleteDir(k)
+}
+
+func (e *metricsEngine) observe(request string, start time.Time) {
+	metrics.BackendRequestDuration.With(prometheus.Labels{
+		metrics.LabelBackend: e.backend,
+		metrics.LabelRequest: request,
+	}).Observe(metrics.Since(start))
+}
+
+const (
+	requestGet            = "get"
+	requestList           = "list"
+	requestPut            = "put"
+	requestCompareAndSwap = "compare_and_swap"
+	requestDelete         = "delete"
+)
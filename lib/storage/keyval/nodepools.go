@@ -0,0 +1,83 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// UpsertNodePool creates or updates the node pool for the specified cluster
+func (b *backend) UpsertNodePool(clusterName string, pool storage.NodePool) error {
+	if err := pool.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	data, err := storage.MarshalNodePool(pool)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = b.upsertValBytes(b.key(sitesP, clusterName, nodePoolsP, pool.GetName()), data, forever)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// GetNodePool returns the node pool with the specified name
+func (b *backend) GetNodePool(clusterName, name string) (storage.NodePool, error) {
+	data, err := b.getValBytes(b.key(sitesP, clusterName, nodePoolsP, name))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("node pool %q not found", name)
+		}
+		return nil, trace.Wrap(err)
+	}
+	return storage.UnmarshalNodePool(data)
+}
+
+// GetNodePools returns all node pools of the specified cluster
+func (b *backend) GetNodePools(clusterName string) ([]storage.NodePool, error) {
+	names, err := b.getKeys(b.key(sitesP, clusterName, nodePoolsP))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var pools []storage.NodePool
+	for _, name := range names {
+		pool, err := b.GetNodePool(clusterName, name)
+		if err != nil {
+			if trace.IsNotFound(err) {
+				continue
+			}
+			return nil, trace.Wrap(err)
+		}
+		pools = append(pools, pool)
+	}
+	return pools, nil
+}
+
+// DeleteNodePool deletes the node pool with the specified name
+func (b *backend) DeleteNodePool(clusterName, name string) error {
+	err := b.deleteKey(b.key(sitesP, clusterName, nodePoolsP, name))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return trace.NotFound("node pool %q not found", name)
+		}
+		return trace.Wrap(err)
+	}
+	return nil
+}
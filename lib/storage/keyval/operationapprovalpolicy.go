@@ -0,0 +1,64 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// UpsertOperationApprovalPolicy creates or updates the operation approval policy
+// of the specified cluster
+func (b *backend) UpsertOperationApprovalPolicy(clusterName string, policy storage.OperationApprovalPolicy) error {
+	if err := policy.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	data, err := storage.MarshalOperationApprovalPolicy(policy)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = b.upsertValBytes(b.key(sitesP, clusterName, approvalPolicyP), data, forever)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// GetOperationApprovalPolicy returns the operation approval policy of the specified cluster
+func (b *backend) GetOperationApprovalPolicy(clusterName string) (storage.OperationApprovalPolicy, error) {
+	data, err := b.getValBytes(b.key(sitesP, clusterName, approvalPolicyP))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("operation approval policy not found")
+		}
+		return nil, trace.Wrap(err)
+	}
+	return storage.UnmarshalOperationApprovalPolicy(data)
+}
+
+// DeleteOperationApprovalPolicy deletes the operation approval policy of the specified cluster
+func (b *backend) DeleteOperationApprovalPolicy(clusterName string) error {
+	err := b.deleteKey(b.key(sitesP, clusterName, approvalPolicyP))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return trace.NotFound("operation approval policy not found")
+		}
+		return trace.Wrap(err)
+	}
+	return nil
+}
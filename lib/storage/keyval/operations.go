@@ -17,7 +17,10 @@ limitations under the License.
 package keyval
 
 import (
+	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/utils"
@@ -171,17 +174,43 @@ func (b *backend) GetOperationPlan(clusterName, operationID string) (*storage.Op
 	return &plan, nil
 }
 
-// CreateOperationPlanChange creates a new state transition entry for a plan
+// CreateOperationPlanChange creates a new state transition entry for a plan.
+//
+// The change is assigned the next revision of the plan changelog unless
+// it specifies a revision explicitly in which case the change is only
+// accepted if the revision immediately follows the latest revision of
+// the changelog and trace.CompareFailed is returned otherwise
 func (b *backend) CreateOperationPlanChange(ch storage.PlanChange) (*storage.PlanChange, error) {
 	if ch.ID == "" {
 		ch.ID = uuid.New()
 	}
-	err := b.upsertVal(b.key(
-		sitesP, ch.ClusterName, operationsP, ch.OperationID, changelogP, ch.ID, valP), ch, forever)
-	if err != nil {
-		return nil, trace.Wrap(err)
+	for {
+		revision, err := b.getOperationPlanRevision(ch.ClusterName, ch.OperationID)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if ch.Revision > revision+1 {
+			return nil, trace.BadParameter("revision %v of the plan for operation %v does not follow latest revision %v",
+				ch.Revision, ch.OperationID, revision)
+		}
+		change := ch
+		if change.Revision == 0 {
+			change.Revision = revision + 1
+		}
+		err = b.createVal(b.key(sitesP, ch.ClusterName, operationsP, ch.OperationID,
+			changelogP, planRevisionKey(change.Revision), valP), change, forever)
+		if err == nil {
+			return &change, nil
+		}
+		if !trace.IsAlreadyExists(err) {
+			return nil, trace.Wrap(err)
+		}
+		if ch.Revision != 0 {
+			return nil, trace.CompareFailed("plan for operation %v has been updated concurrently, "+
+				"revision %v already exists", ch.OperationID, ch.Revision)
+		}
+		// Another writer has taken the revision, try the next one
 	}
-	return &ch, nil
 }
 
 // GetOperationPlanChangelog returns all state transition entries for a plan
@@ -204,6 +233,38 @@ func (b *backend) GetOperationPlanChangelog(clusterName, operationID string) (st
 	return storage.PlanChangelog(out), nil
 }
 
+// getOperationPlanRevision returns the latest revision of the plan changelog
+// for the specified operation
+func (b *backend) getOperationPlanRevision(clusterName, operationID string) (revision int64, err error) {
+	ids, err := b.getKeys(b.key(sitesP, clusterName, operationsP, operationID, changelogP))
+	if err != nil && !trace.IsNotFound(err) {
+		return 0, trace.Wrap(err)
+	}
+	for _, id := range ids {
+		// Changes created before revisions were introduced are keyed by ID
+		if !strings.HasPrefix(id, planRevisionPrefix) {
+			continue
+		}
+		r, err := strconv.ParseInt(strings.TrimPrefix(id, planRevisionPrefix), 10, 64)
+		if err != nil {
+			continue
+		}
+		if r > revision {
+			revision = r
+		}
+	}
+	return revision, nil
+}
+
+// planRevisionKey returns the changelog key for the change with the specified revision.
+// Revisions are used as keys so that two changes cannot claim the same revision
+func planRevisionKey(revision int64) string {
+	return fmt.Sprintf("%v%020d", planRevisionPrefix, revision)
+}
+
+// planRevisionPrefix prefixes changelog keys of changes with a revision
+const planRevisionPrefix = "r"
+
 // CreateAppOperation creates a new application operation
 func (b *backend) CreateAppOperation(op storage.AppOperation) (*storage.AppOperation, error) {
 	err := op.Check()
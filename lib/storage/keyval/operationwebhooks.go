@@ -0,0 +1,83 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// UpsertOperationWebhook creates or updates the operation webhook for the specified cluster
+func (b *backend) UpsertOperationWebhook(clusterName string, webhook storage.OperationWebhook) error {
+	if err := webhook.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	data, err := storage.MarshalOperationWebhook(webhook)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = b.upsertValBytes(b.key(sitesP, clusterName, operationWebhooksP, webhook.GetName()), data, forever)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// GetOperationWebhook returns the operation webhook with the specified name
+func (b *backend) GetOperationWebhook(clusterName, name string) (storage.OperationWebhook, error) {
+	data, err := b.getValBytes(b.key(sitesP, clusterName, operationWebhooksP, name))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("operation webhook %q not found", name)
+		}
+		return nil, trace.Wrap(err)
+	}
+	return storage.UnmarshalOperationWebhook(data)
+}
+
+// GetOperationWebhooks returns all operation webhooks of the specified cluster
+func (b *backend) GetOperationWebhooks(clusterName string) ([]storage.OperationWebhook, error) {
+	names, err := b.getKeys(b.key(sitesP, clusterName, operationWebhooksP))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var webhooks []storage.OperationWebhook
+	for _, name := range names {
+		webhook, err := b.GetOperationWebhook(clusterName, name)
+		if err != nil {
+			if trace.IsNotFound(err) {
+				continue
+			}
+			return nil, trace.Wrap(err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, nil
+}
+
+// DeleteOperationWebhook deletes the operation webhook with the specified name
+func (b *backend) DeleteOperationWebhook(clusterName, name string) error {
+	err := b.deleteKey(b.key(sitesP, clusterName, operationWebhooksP, name))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return trace.NotFound("operation webhook %q not found", name)
+		}
+		return trace.Wrap(err)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+)
+
+// pollingBackend is a backend with the leader election capability
+// for engines that support TTL and compare-and-swap but not watches
+type pollingBackend struct {
+	*backend
+	*pollingLeader
+}
+
+func newPollingBackend(b *backend) *pollingBackend {
+	return &pollingBackend{
+		backend: b,
+		pollingLeader: &pollingLeader{
+			FieldLogger: logrus.WithField(trace.Component, "leader"),
+			backend:     b,
+			doneC:       make(chan struct{}),
+		},
+	}
+}
+
+// Close stops the election and closes the backend
+func (b *pollingBackend) Close() error {
+	b.pollingLeader.close()
+	return b.backend.Close()
+}
+
+// pollingLeader implements leader election on top of the key/value engine
+// by periodically refreshing and polling the leader key
+type pollingLeader struct {
+	logrus.FieldLogger
+	backend *backend
+	mu      sync.Mutex
+	voters  []voter
+	paused  time.Time
+	once    sync.Once
+	doneC   chan struct{}
+}
+
+type voter struct {
+	key   key
+	value string
+	term  time.Duration
+}
+
+// AddWatch starts watching the key for changes and sending them
+// to the valuesC
+func (l *pollingLeader) AddWatch(key string, retry time.Duration, valuesC chan string) {
+	go func() {
+		ticker := l.backend.NewTicker(retry)
+		defer ticker.Stop()
+		var current string
+		for {
+			var value string
+			err := l.backend.getVal(l.leaderKey(key), &value)
+			if err != nil && !trace.IsNotFound(err) {
+				l.WithError(err).Warnf("Failed to read %v.", key)
+			}
+			if err == nil && value != current {
+				current = value
+				select {
+				case valuesC <- value:
+				case <-l.doneC:
+					return
+				}
+			}
+			select {
+			case <-ticker.Chan():
+			case <-l.doneC:
+				return
+			}
+		}
+	}()
+}
+
+// AddVoter adds a voter that tries to elect given value
+// by attempting to set the key to the value for a given term duration
+// it also attempts to hold the lease indefinitely
+func (l *pollingLeader) AddVoter(ctx context.Context, key, value string, term time.Duration) error {
+	if value == "" {
+		return trace.BadParameter("voter value can not be empty")
+	}
+	if term < time.Second {
+		return trace.BadParameter("term can not be less than a second")
+	}
+	v := voter{key: l.leaderKey(key), value: value, term: term}
+	l.mu.Lock()
+	l.voters = append(l.voters, v)
+	l.mu.Unlock()
+	go func() {
+		ticker := l.backend.NewTicker(term / 3)
+		defer ticker.Stop()
+		for {
+			if !l.isPaused() {
+				if err := l.vote(v); err != nil {
+					l.WithError(err).Warnf("Failed to vote for %v.", value)
+				}
+			}
+			select {
+			case <-ticker.Chan():
+			case <-ctx.Done():
+				return
+			case <-l.doneC:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// StepDown tells the voter to pause election so it can give up its leadership
+func (l *pollingLeader) StepDown() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var term time.Duration
+	for _, v := range l.voters {
+		err := l.backend.compareAndDelete(v.key, v.value)
+		if err != nil && !trace.IsNotFound(err) && !trace.IsCompareFailed(err) {
+			l.WithError(err).Warnf("Failed to step down %v.", v.value)
+		}
+		if v.term > term {
+			term = v.term
+		}
+	}
+	l.paused = l.backend.Now().Add(term)
+}
+
+// vote attempts to acquire the leader key or extends the lease
+// if the voter already holds it
+func (l *pollingLeader) vote(v voter) error {
+	err := l.backend.createVal(v.key, v.value, v.term)
+	if err == nil {
+		l.Infof("%v has been elected.", v.value)
+		return nil
+	}
+	if !trace.IsAlreadyExists(err) {
+		return trace.Wrap(err)
+	}
+	var prevValue string
+	err = l.backend.compareAndSwap(v.key, v.value, v.value, &prevValue, v.term)
+	if err != nil && !trace.IsCompareFailed(err) && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+func (l *pollingLeader) isPaused() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.backend.Now().Before(l.paused)
+}
+
+func (l *pollingLeader) leaderKey(key string) key {
+	return l.backend.key(leaderP, key)
+}
+
+func (l *pollingLeader) close() {
+	l.once.Do(func() {
+		close(l.doneC)
+	})
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/jonboulle/clockwork"
+	. "gopkg.in/check.v1"
+)
+
+type PollingLeaderSuite struct {
+	backend *backend
+}
+
+var _ = Suite(&PollingLeaderSuite{})
+
+func (s *PollingLeaderSuite) SetUpTest(c *C) {
+	engine, err := newBolt(BoltConfig{Path: filepath.Join(c.MkDir(), "bolt.db")}, &v1codec{})
+	c.Assert(err, IsNil)
+	s.backend = &backend{Clock: clockwork.NewRealClock(), kvengine: engine}
+}
+
+func (s *PollingLeaderSuite) TearDownTest(c *C) {
+	c.Assert(s.backend.Close(), IsNil)
+}
+
+func (s *PollingLeaderSuite) TestElectsLeader(c *C) {
+	first := newPollingBackend(s.backend).pollingLeader
+	defer first.close()
+	second := newPollingBackend(s.backend).pollingLeader
+	defer second.close()
+
+	valuesC := make(chan string, 1)
+	first.AddWatch("/leader", 10*time.Millisecond, valuesC)
+	c.Assert(first.AddVoter(context.TODO(), "/leader", "node-1", time.Second), IsNil)
+	c.Assert(receiveValue(c, valuesC), Equals, "node-1")
+
+	c.Assert(second.AddVoter(context.TODO(), "/leader", "node-2", time.Second), IsNil)
+	first.StepDown()
+	c.Assert(receiveValue(c, valuesC), Equals, "node-2")
+}
+
+func (s *PollingLeaderSuite) TestCreatesBackendFromParams(c *C) {
+	backend, err := storage.NewBackend(constants.BoltBackend, storage.BackendParams{
+		"path": filepath.Join(c.MkDir(), "bolt.db"),
+	})
+	c.Assert(err, IsNil)
+	c.Assert(backend.Close(), IsNil)
+
+	_, err = storage.NewBackend("unknown", nil)
+	c.Assert(err, NotNil)
+	c.Assert(storage.BackendTypes(), DeepEquals, []string{
+		constants.BoltBackend, constants.ETCDBackend, constants.PostgresBackend,
+	})
+}
+
+func receiveValue(c *C, valuesC chan string) string {
+	select {
+	case value := <-valuesC:
+		return value
+	case <-time.After(5 * time.Second):
+		c.Fatal("timeout waiting for value")
+	}
+	return ""
+}
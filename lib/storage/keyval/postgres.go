@@ -0,0 +1,561 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// NewPostgres returns new PostgreSQL-backed engine
+func NewPostgres(cfg PostgresConfig) (*pollingBackend, error) {
+	if err := cfg.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	engine, err := newPostgres(cfg, &v1codec{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	wrapped, err := cfg.SecretsConfig.wrap(cfg.Clock, cfg.ChangelogConfig.wrap(newMetricsEngine(engine, constants.PostgresBackend), cfg.Clock))
+	if err != nil {
+		engine.Close()
+		return nil, trace.Wrap(err)
+	}
+	return newPollingBackend(&backend{
+		Clock:    cfg.Clock,
+		kvengine: wrapped,
+	}), nil
+}
+
+// PostgresConfig is a PostgreSQL backend configuration
+type PostgresConfig struct {
+	// ConnectionString specifies the database connection parameters
+	// either as a URL or as a list of key/value pairs, for example:
+	// postgres://gravity@db.example.com/gravity?sslmode=verify-full
+	ConnectionString string `json:"connection_string" yaml:"connection_string"`
+	// Table is the name of the table with the cluster state.
+	// Defaults to defaults.PostgresTable
+	Table string `json:"table,omitempty" yaml:"table"`
+	// MaxOpenConnections is the maximum number of open database connections.
+	// Defaults to defaults.PostgresMaxOpenConnections
+	MaxOpenConnections int `json:"max_open_connections,omitempty" yaml:"max_open_connections"`
+	// Clock is a clock interface, used in tests
+	Clock clockwork.Clock `json:"-" yaml:"-"`
+	// ChangelogConfig optionally enables the state changelog
+	ChangelogConfig `yaml:",inline"`
+	// SecretsConfig optionally configures the external storage of secrets
+	SecretsConfig `yaml:",inline"`
+}
+
+// CheckAndSetDefaults validates this configuration and sets defaults
+func (cfg *PostgresConfig) CheckAndSetDefaults() error {
+	if cfg.ConnectionString == "" {
+		return trace.BadParameter("missing connection_string parameter")
+	}
+	if cfg.Table == "" {
+		cfg.Table = defaults.PostgresTable
+	}
+	if !tableNameRegexp.MatchString(cfg.Table) {
+		return trace.BadParameter("invalid table name %q, expected lowercase "+
+			"letters, digits and underscores", cfg.Table)
+	}
+	if cfg.MaxOpenConnections == 0 {
+		cfg.MaxOpenConnections = defaults.PostgresMaxOpenConnections
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = clockwork.NewRealClock()
+	}
+	return nil
+}
+
+// pg is a PostgreSQL-backed engine.
+//
+// All keys are stored in a single table where a key is a slash-separated
+// path. Directories are represented either implicitly by the keys below
+// them or explicitly by rows with the dir flag set.
+// The expiration time of the keys with TTL is computed using the engine
+// clock and expired keys are periodically removed from the table
+type pg struct {
+	logrus.FieldLogger
+	codec Codec
+	db    *sql.DB
+	clock clockwork.Clock
+	table string
+	once  sync.Once
+	doneC chan struct{}
+}
+
+// newPostgres returns a new instance of PostgreSQL backend
+func newPostgres(cfg PostgresConfig, codec Codec) (*pg, error) {
+	db, err := sql.Open(postgresDriver, cfg.ConnectionString)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConnections)
+	p := &pg{
+		FieldLogger: logrus.WithFields(logrus.Fields{
+			trace.Component: "postgres",
+			"table":         cfg.Table,
+		}),
+		codec: codec,
+		db:    db,
+		clock: cfg.Clock,
+		table: cfg.Table,
+		doneC: make(chan struct{}),
+	}
+	if err := p.init(); err != nil {
+		db.Close()
+		return nil, trace.Wrap(err)
+	}
+	go p.expireKeys()
+	return p, nil
+}
+
+func (p *pg) init() error {
+	_, err := p.db.Exec(p.query(`CREATE TABLE IF NOT EXISTS {table} (
+		key TEXT COLLATE "C" PRIMARY KEY,
+		value BYTEA,
+		dir BOOLEAN NOT NULL DEFAULT FALSE,
+		expires TIMESTAMPTZ
+	)`))
+	if err != nil {
+		return trace.Wrap(convertPostgresErr(err), "failed to initialize table %v", p.table)
+	}
+	return nil
+}
+
+// expireKeys periodically removes expired keys from the database
+func (p *pg) expireKeys() {
+	ticker := p.clock.NewTicker(defaults.PostgresExpiryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.Chan():
+			_, err := p.db.Exec(p.query(`DELETE FROM {table} WHERE expires <= $1`), p.now())
+			if err != nil {
+				p.WithError(err).Warn("Failed to remove expired keys.")
+			}
+		case <-p.doneC:
+			return
+		}
+	}
+}
+
+func (p *pg) key(prefix string, keys ...string) key {
+	key := make([]string, 0, len(keys)+1)
+	key = append(key, prefix)
+	key = append(key, keys...)
+	for i := range key {
+		key[i] = strings.Replace(key[i], "/", "%2F", -1)
+	}
+	return key
+}
+
+func (p *pg) createDir(key key, ttl time.Duration) error {
+	return p.insert(key, nil, true, ttl)
+}
+
+func (p *pg) upsertDir(key key, ttl time.Duration) error {
+	_, err := p.db.Exec(p.query(`INSERT INTO {table} (key, dir, expires) VALUES ($1, TRUE, $2)
+		ON CONFLICT (key) DO UPDATE SET expires = EXCLUDED.expires`),
+		ekey(key), p.expires(ttl))
+	return trace.Wrap(convertPostgresErr(err))
+}
+
+func (p *pg) createValBytes(key key, data []byte, ttl time.Duration) error {
+	return p.insert(key, data, false, ttl)
+}
+
+func (p *pg) createVal(key key, val interface{}, ttl time.Duration) error {
+	encoded, err := p.codec.EncodeToBytes(val)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return p.insert(key, encoded, false, ttl)
+}
+
+func (p *pg) upsertValBytes(key key, data []byte, ttl time.Duration) error {
+	_, err := p.db.Exec(p.query(`INSERT INTO {table} (key, value, expires) VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, dir = FALSE, expires = EXCLUDED.expires`),
+		ekey(key), data, p.expires(ttl))
+	return trace.Wrap(convertPostgresErr(err))
+}
+
+func (p *pg) upsertVal(key key, val interface{}, ttl time.Duration) error {
+	encoded, err := p.codec.EncodeToBytes(val)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return p.upsertValBytes(key, encoded, ttl)
+}
+
+func (p *pg) updateValBytes(key key, data []byte, ttl time.Duration) error {
+	result, err := p.db.Exec(p.query(`UPDATE {table} SET value = $2, expires = $3
+		WHERE key = $1 AND NOT dir AND (expires IS NULL OR expires > $4)`),
+		ekey(key), data, p.expires(ttl), p.now())
+	if err != nil {
+		return trace.Wrap(convertPostgresErr(err))
+	}
+	if !affected(result) {
+		return trace.NotFound("%q not found", ekey(key))
+	}
+	return nil
+}
+
+func (p *pg) updateVal(key key, val interface{}, ttl time.Duration) error {
+	encoded, err := p.codec.EncodeToBytes(val)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return p.updateValBytes(key, encoded, ttl)
+}
+
+func (p *pg) updateTTL(key key, ttl time.Duration) error {
+	result, err := p.db.Exec(p.query(`UPDATE {table} SET expires = $2
+		WHERE key = $1 AND (expires IS NULL OR expires > $3)`),
+		ekey(key), p.expires(ttl), p.now())
+	if err != nil {
+		return trace.Wrap(convertPostgresErr(err))
+	}
+	if !affected(result) {
+		return trace.NotFound("%q not found", ekey(key))
+	}
+	return nil
+}
+
+func (p *pg) compareAndSwap(key key, val interface{}, prevVal interface{}, outVal interface{}, ttl time.Duration) error {
+	encoded, err := p.codec.EncodeToBytes(val)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	var prevEncoded []byte
+	if prevVal != nil {
+		prevEncoded, err = p.codec.EncodeToBytes(prevVal)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	var outEncoded []byte
+	err = p.compareAndSwapBytes(key, encoded, prevEncoded, &outEncoded, ttl)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if prevVal != nil {
+		err = p.codec.DecodeFromBytes(outEncoded, outVal)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+func (p *pg) compareAndSwapBytes(key key, val, prevVal []byte, outVal *[]byte, ttl time.Duration) error {
+	if prevVal == nil { // we don't expect the value to exist
+		return p.insert(key, val, false, ttl)
+	}
+	return p.inTx(func(tx *sql.Tx) error {
+		var currentVal []byte
+		err := tx.QueryRow(p.query(`SELECT value FROM {table}
+			WHERE key = $1 AND NOT dir AND (expires IS NULL OR expires > $2) FOR UPDATE`),
+			ekey(key), p.now()).Scan(&currentVal)
+		if err == sql.ErrNoRows {
+			return trace.NotFound("key %q not found", ekey(key))
+		}
+		if err != nil {
+			return trace.Wrap(convertPostgresErr(err))
+		}
+		if !bytes.Equal(currentVal, prevVal) {
+			return trace.CompareFailed("expected %q got %q",
+				string(prevVal), string(currentVal))
+		}
+		_, err = tx.Exec(p.query(`UPDATE {table} SET value = $2, expires = $3 WHERE key = $1`),
+			ekey(key), val, p.expires(ttl))
+		if err != nil {
+			return trace.Wrap(convertPostgresErr(err))
+		}
+		*outVal = currentVal
+		return nil
+	})
+}
+
+func (p *pg) getValBytes(key key) ([]byte, error) {
+	var data []byte
+	var dir bool
+	err := p.db.QueryRow(p.query(`SELECT value, dir FROM {table}
+		WHERE key = $1 AND (expires IS NULL OR expires > $2)`),
+		ekey(key), p.now()).Scan(&data, &dir)
+	if err == sql.ErrNoRows {
+		hasKeys, err := p.hasKeys(key)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if hasKeys {
+			return nil, trace.BadParameter("key %q is a directory", ekey(key))
+		}
+		return nil, trace.NotFound("%q not found", ekey(key))
+	}
+	if err != nil {
+		return nil, trace.Wrap(convertPostgresErr(err))
+	}
+	if dir {
+		return nil, trace.BadParameter("key %q is a directory", ekey(key))
+	}
+	return data, nil
+}
+
+func (p *pg) getVal(key key, val interface{}) error {
+	data, err := p.getValBytes(key)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return p.codec.DecodeFromBytes(data, val)
+}
+
+func (p *pg) compareAndDelete(key key, prevVal interface{}) error {
+	encoded, err := p.codec.EncodeToBytes(prevVal)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return p.inTx(func(tx *sql.Tx) error {
+		var currentVal []byte
+		err := tx.QueryRow(p.query(`SELECT value FROM {table}
+			WHERE key = $1 AND NOT dir AND (expires IS NULL OR expires > $2) FOR UPDATE`),
+			ekey(key), p.now()).Scan(&currentVal)
+		if err == sql.ErrNoRows {
+			return trace.NotFound("%q is not found", ekey(key))
+		}
+		if err != nil {
+			return trace.Wrap(convertPostgresErr(err))
+		}
+		if !bytes.Equal(currentVal, encoded) {
+			return trace.CompareFailed("%v: expected %s, but got %s",
+				ekey(key), encoded, currentVal)
+		}
+		_, err = tx.Exec(p.query(`DELETE FROM {table} WHERE key = $1`), ekey(key))
+		return trace.Wrap(convertPostgresErr(err))
+	})
+}
+
+func (p *pg) deleteKey(key key) error {
+	var live bool
+	err := p.db.QueryRow(p.query(`DELETE FROM {table} WHERE key = $1 AND NOT dir
+		RETURNING (expires IS NULL OR expires > $2)`),
+		ekey(key), p.now()).Scan(&live)
+	if err == sql.ErrNoRows || (err == nil && !live) {
+		return trace.NotFound("%q is not found", ekey(key))
+	}
+	return trace.Wrap(convertPostgresErr(err))
+}
+
+func (p *pg) deleteDir(key key) error {
+	from, to := keyRange(key)
+	result, err := p.db.Exec(p.query(`DELETE FROM {table}
+		WHERE key = $1 OR (key >= $2 AND key < $3)`),
+		ekey(key), from, to)
+	if err != nil {
+		return trace.Wrap(convertPostgresErr(err))
+	}
+	if !affected(result) {
+		return trace.NotFound("%q is not found", ekey(key))
+	}
+	return nil
+}
+
+func (p *pg) acquireLock(token key, ttl time.Duration) error {
+	for {
+		err := p.tryAcquireLock(token, ttl)
+		if err != nil {
+			if !trace.IsCompareFailed(err) && !trace.IsAlreadyExists(err) {
+				return trace.Wrap(err)
+			}
+			time.Sleep(delayBetweenLockAttempts)
+		} else {
+			return nil
+		}
+	}
+}
+
+func (p *pg) tryAcquireLock(key key, ttl time.Duration) error {
+	return p.createVal(key, "locked", ttl)
+}
+
+func (p *pg) releaseLock(key key) error {
+	return p.deleteKey(key)
+}
+
+// getKeys returns the names of the immediate children of the specified key
+func (p *pg) getKeys(key key) ([]string, error) {
+	from, to := keyRange(key)
+	rows, err := p.db.Query(p.query(`SELECT key FROM {table}
+		WHERE key >= $1 AND key < $2 AND (expires IS NULL OR expires > $3)`),
+		from, to, p.now())
+	if err != nil {
+		return nil, trace.Wrap(convertPostgresErr(err))
+	}
+	defer rows.Close()
+	names := make(map[string]struct{})
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, trace.Wrap(convertPostgresErr(err))
+		}
+		name = strings.SplitN(strings.TrimPrefix(name, from), "/", 2)[0]
+		names[name] = struct{}{}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, trace.Wrap(convertPostgresErr(err))
+	}
+	out := make([]string, 0, len(names))
+	for name := range names {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// Close closes the database connections
+func (p *pg) Close() error {
+	p.once.Do(func() {
+		close(p.doneC)
+	})
+	return trace.Wrap(p.db.Close())
+}
+
+// insert creates the specified key if it does not exist
+func (p *pg) insert(key key, data []byte, dir bool, ttl time.Duration) error {
+	return p.inTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(p.query(`DELETE FROM {table} WHERE key = $1 AND expires <= $2`),
+			ekey(key), p.now())
+		if err != nil {
+			return trace.Wrap(convertPostgresErr(err))
+		}
+		result, err := tx.Exec(p.query(`INSERT INTO {table} (key, value, dir, expires)
+			VALUES ($1, $2, $3, $4) ON CONFLICT (key) DO NOTHING`),
+			ekey(key), data, dir, p.expires(ttl))
+		if err != nil {
+			return trace.Wrap(convertPostgresErr(err))
+		}
+		if !affected(result) {
+			return trace.AlreadyExists("%q already exists", ekey(key))
+		}
+		return nil
+	})
+}
+
+// hasKeys returns true if the specified key has children
+func (p *pg) hasKeys(key key) (exists bool, err error) {
+	from, to := keyRange(key)
+	err = p.db.QueryRow(p.query(`SELECT EXISTS (SELECT 1 FROM {table}
+		WHERE key >= $1 AND key < $2 AND (expires IS NULL OR expires > $3))`),
+		from, to, p.now()).Scan(&exists)
+	if err != nil {
+		return false, trace.Wrap(convertPostgresErr(err))
+	}
+	return exists, nil
+}
+
+// inTx executes the specified function in a transaction which is committed
+// if the function succeeds and rolled back otherwise
+func (p *pg) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return trace.Wrap(convertPostgresErr(err))
+	}
+	if err := fn(tx); err != nil {
+		if errRollback := tx.Rollback(); errRollback != nil {
+			p.WithError(errRollback).Warn("Failed to roll back transaction.")
+		}
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(convertPostgresErr(tx.Commit()))
+}
+
+// query returns the specified query with the table name substituted
+func (p *pg) query(query string) string {
+	return strings.Replace(query, "{table}", p.table, -1)
+}
+
+func (p *pg) now() time.Time {
+	return p.clock.Now().UTC()
+}
+
+// expires returns the expiration time for the specified TTL
+// or nil if the TTL is not set
+func (p *pg) expires(ttl time.Duration) *time.Time {
+	if ttl == forever {
+		return nil
+	}
+	expires := p.now().Add(ttl)
+	return &expires
+}
+
+// keyRange returns the range of keys with the specified key as a prefix
+func keyRange(key key) (from, to string) {
+	if len(key) == 0 {
+		// the root key spans the whole table
+		return "", string(utf8.MaxRune)
+	}
+	prefix := ekey(key)
+	// '0' follows '/' in the byte order used by the "C" collation
+	return fmt.Sprintf("%v/", prefix), fmt.Sprintf("%v0", prefix)
+}
+
+func affected(result sql.Result) bool {
+	rows, err := result.RowsAffected()
+	return err == nil && rows != 0
+}
+
+func convertPostgresErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch {
+		case pqErr.Code == uniqueViolation:
+			return trace.AlreadyExists(pqErr.Message)
+		case pqErr.Code.Class() == connectionExceptionClass:
+			return trace.ConnectionProblem(pqErr, "failed to connect to the database")
+		}
+	}
+	return err
+}
+
+const (
+	// postgresDriver is the name of the PostgreSQL database driver
+	postgresDriver = "postgres"
+	// uniqueViolation is the PostgreSQL error code for unique constraint violations
+	uniqueViolation pq.ErrorCode = "23505"
+	// connectionExceptionClass is the PostgreSQL error class of connection errors
+	connectionExceptionClass pq.ErrorClass = "08"
+)
+
+var tableNameRegexp = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
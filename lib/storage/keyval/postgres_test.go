@@ -0,0 +1,290 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/storage/suite"
+
+	teleutils "github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	log "github.com/sirupsen/logrus"
+	. "gopkg.in/check.v1"
+)
+
+type PSuite struct {
+	backend *pollingBackend
+	engine  *pg
+	clock   clockwork.FakeClock
+	suite   suite.StorageSuite
+}
+
+var _ = Suite(&PSuite{})
+
+func (s *PSuite) SetUpTest(c *C) {
+	log.SetOutput(os.Stderr)
+
+	if ok, _ := strconv.ParseBool(os.Getenv(defaults.TestPostgres)); !ok {
+		c.Skip("Skipping test suite for PostgreSQL")
+		return
+	}
+
+	token, err := teleutils.CryptoRandomHex(6)
+	c.Assert(err, IsNil)
+
+	s.clock = clockwork.NewFakeClock()
+	s.backend, err = NewPostgres(PostgresConfig{
+		ConnectionString: os.Getenv(defaults.TestPostgresURI),
+		Table:            fmt.Sprintf("test_%v", token),
+		Clock:            s.clock,
+	})
+	c.Assert(err, IsNil)
+	s.engine = s.backend.kvengine.(*metricsEngine).kvengine.(*pg)
+
+	s.suite.Backend = s.backend
+	s.suite.Clock = s.clock
+}
+
+func (s *PSuite) TearDownTest(c *C) {
+	if s.backend != nil {
+		_, err := s.engine.db.Exec(s.engine.query(`DROP TABLE {table}`))
+		if err != nil {
+			log.Error(trace.DebugReport(err))
+		}
+		c.Assert(err, IsNil)
+		c.Assert(s.backend.Close(), IsNil)
+	}
+}
+
+func (s *PSuite) TestKeysAndDirectories(c *C) {
+	c.Assert(s.engine.upsertVal(s.engine.key("a", "b", "c"), "value", forever), IsNil)
+	c.Assert(s.engine.createDir(s.engine.key("a", "d"), forever), IsNil)
+	c.Assert(trace.IsAlreadyExists(s.engine.createVal(s.engine.key("a", "b", "c"), "value", forever)),
+		Equals, true)
+
+	keys, err := s.engine.getKeys(s.engine.key("a"))
+	c.Assert(err, IsNil)
+	c.Assert(keys, DeepEquals, []string{"b", "d"})
+
+	var value string
+	err = s.engine.getVal(s.engine.key("a", "b"), &value)
+	c.Assert(trace.IsBadParameter(err), Equals, true)
+
+	c.Assert(s.engine.deleteDir(s.engine.key("a")), IsNil)
+	keys, err = s.engine.getKeys(s.engine.key("a"))
+	c.Assert(err, IsNil)
+	c.Assert(keys, HasLen, 0)
+}
+
+func (s *PSuite) TestExpiresKeys(c *C) {
+	key := s.engine.key("a")
+	c.Assert(s.engine.createVal(key, "value", time.Minute), IsNil)
+	s.clock.Advance(2 * time.Minute)
+
+	var value string
+	c.Assert(trace.IsNotFound(s.engine.getVal(key, &value)), Equals, true)
+	c.Assert(s.engine.createVal(key, "value", forever), IsNil)
+}
+
+func (s *PSuite) TestCompareAndSwap(c *C) {
+	key := s.engine.key("a")
+	var prev string
+	c.Assert(s.engine.compareAndSwap(key, "one", nil, &prev, forever), IsNil)
+	err := s.engine.compareAndSwap(key, "two", "three", &prev, forever)
+	c.Assert(trace.IsCompareFailed(err), Equals, true)
+	c.Assert(s.engine.compareAndSwap(key, "two", "one", &prev, forever), IsNil)
+	c.Assert(prev, Equals, "one")
+	c.Assert(trace.IsCompareFailed(s.engine.compareAndDelete(key, "one")), Equals, true)
+	c.Assert(s.engine.compareAndDelete(key, "two"), IsNil)
+}
+func (s *PSuite) TestAccountsCRUD(c *C) {
+	s.suite.AccountsCRUD(c)
+}
+
+func (s *PSuite) TestRepositoriesCRUD(c *C) {
+	s.suite.RepositoriesCRUD(c)
+}
+
+func (s *PSuite) TestSitesCRUD(c *C) {
+	s.suite.SitesCRUD(c)
+}
+
+func (s *PSuite) TestProgressEntriesCRUD(c *C) {
+	s.suite.ProgressEntriesCRUD(c)
+}
+
+func (s *PSuite) TestConnectorsCRUD(c *C) {
+	s.suite.ConnectorsCRUD(c)
+}
+
+func (s *PSuite) TestUsersCRUD(c *C) {
+	s.suite.UsersCRUD(c)
+}
+
+func (s *PSuite) TestUserTokensCRUD(c *C) {
+	s.suite.UserTokensCRUD(c)
+}
+
+func (s *PSuite) TestProvisioningTokensCRUD(c *C) {
+	s.suite.ProvisioningTokensCRUD(c)
+}
+
+func (s *PSuite) TestAPIKeys(c *C) {
+	s.suite.APIKeysCRUD(c)
+}
+
+func (s *PSuite) TestUserInvites(c *C) {
+	s.suite.UserInvitesCRUD(c)
+}
+
+func (s *PSuite) TestLoginEntriesCRUD(c *C) {
+	s.suite.LoginEntriesCRUD(c)
+}
+
+func (s *PSuite) TestPermissionsCRUD(c *C) {
+	s.suite.PermissionsCRUD(c)
+}
+
+func (s *PSuite) TestOperationsCRUD(c *C) {
+	s.suite.OperationsCRUD(c)
+}
+
+func (s *PSuite) TestCreatesApplication(c *C) {
+	s.suite.CreatesApplication(c)
+}
+
+func (s *PSuite) TestDeletesApplication(c *C) {
+	s.suite.DeletesApplication(c)
+}
+
+func (s *PSuite) TestRetrievesApplications(c *C) {
+	s.suite.RetrievesApplications(c)
+}
+
+func (s *PSuite) TestCreatesAppImportOperation(c *C) {
+	s.suite.CreatesAppImportOperation(c)
+}
+
+func (s *PSuite) TestUpdatesAppImportOperation(c *C) {
+	s.suite.UpdatesAppImportOperation(c)
+}
+
+func (s *PSuite) TestOperationPlanChangelog(c *C) {
+	s.suite.OperationPlanChangelog(c)
+}
+
+func (s *PSuite) TestConnectors(c *C) {
+	s.suite.ConnectorsCRUD(c)
+}
+
+func (s *PSuite) TestWebSessions(c *C) {
+	s.suite.WebSessionsCRUD(c)
+}
+
+func (s *PSuite) TestAuthoritiesCRUD(c *C) {
+	s.suite.AuthoritiesCRUD(c)
+}
+
+func (s *PSuite) TestNodesCRUD(c *C) {
+	s.suite.NodesCRUD(c)
+}
+
+func (s *PSuite) TestReverseTunnelsCRUD(c *C) {
+	s.suite.ReverseTunnelsCRUD(c)
+}
+
+func (s *PSuite) TestLocksCRUD(c *C) {
+	s.suite.LocksCRUD(c)
+}
+
+func (s *PSuite) TestPeersCRUD(c *C) {
+	s.suite.PeersCRUD(c)
+}
+
+func (s *PSuite) TestObjectsCRUD(c *C) {
+	s.suite.ObjectsCRUD(c)
+}
+
+func (s *PSuite) TestChangesetsCRUD(c *C) {
+	s.suite.ChangesetsCRUD(c)
+}
+
+func (s *PSuite) TestOpsCenterLinksCRUD(c *C) {
+	s.suite.OpsCenterLinksCRUD(c)
+}
+
+func (s *PSuite) TestRolesCRUD(c *C) {
+	s.suite.RolesCRUD(c)
+}
+
+func (s *PSuite) TestNamespacesCRUD(c *C) {
+	s.suite.NamespacesCRUD(c)
+}
+
+func (s *PSuite) TestLoginAttempts(c *C) {
+	s.suite.LoginAttempts(c)
+}
+
+func (s *PSuite) TestLocalCluster(c *C) {
+	s.suite.LocalCluster(c)
+}
+
+func (s *PSuite) TestSAMLCRUD(c *C) {
+	s.suite.SAMLCRUD(c)
+}
+
+func (s *PSuite) TestClusterAgentCreds(c *C) {
+	s.suite.ClusterAgentCreds(c)
+}
+
+func (s *PSuite) TestClusterLogin(c *C) {
+	s.suite.ClusterLogin(c)
+}
+
+func (s *PSuite) TestIndexFile(c *C) {
+	s.suite.IndexFile(c)
+}
+
+func (s *PSuite) TestNodePoolsCRUD(c *C) {
+	s.suite.NodePoolsCRUD(c)
+}
+
+func (s *PSuite) TestReleasesCRUD(c *C) {
+	s.suite.ReleasesCRUD(c)
+}
+
+func (s *PSuite) TestClusterRosterCRUD(c *C) {
+	s.suite.ClusterRosterCRUD(c)
+}
+
+func (s *PSuite) TestEtcdMaintenanceStatusCRUD(c *C) {
+	s.suite.EtcdMaintenanceStatusCRUD(c)
+}
+
+func (s *PSuite) TestEtcdHealthStatusCRUD(c *C) {
+	s.suite.EtcdHealthStatusCRUD(c)
+}
+
+func (s *PSuite) TestClusterEventsCRUD(c *C) {
+	s.suite.ClusterEventsCRUD(c)
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/storage"
+
+	teleutils "github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/trace"
+)
+
+// init registers the key/value storage backends
+func init() {
+	storage.RegisterBackend(constants.BoltBackend, func(params storage.BackendParams) (storage.Backend, error) {
+		var cfg BoltConfig
+		if err := teleutils.ObjectToStruct(params, &cfg); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		backend, err := NewBolt(cfg)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return backend, nil
+	})
+	storage.RegisterBackend(constants.ETCDBackend, func(params storage.BackendParams) (storage.Backend, error) {
+		var cfg ETCDConfig
+		if err := teleutils.ObjectToStruct(params, &cfg); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		backend, err := NewETCD(cfg)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return backend, nil
+	})
+	storage.RegisterBackend(constants.PostgresBackend, func(params storage.BackendParams) (storage.Backend, error) {
+		var cfg PostgresConfig
+		if err := teleutils.ObjectToStruct(params, &cfg); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		backend, err := NewPostgres(cfg)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return backend, nil
+	})
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// UpsertRelease creates or updates the release record of the specified cluster
+func (b *backend) UpsertRelease(clusterName string, release storage.Release) error {
+	data, err := storage.MarshalRelease(release)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = b.upsertValBytes(b.key(sitesP, clusterName, releasesP, release.GetName()), data, forever)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// GetRelease returns the release record with the specified name
+func (b *backend) GetRelease(clusterName, name string) (storage.Release, error) {
+	data, err := b.getValBytes(b.key(sitesP, clusterName, releasesP, name))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("release %q not found", name)
+		}
+		return nil, trace.Wrap(err)
+	}
+	return storage.UnmarshalRelease(data)
+}
+
+// GetReleases returns all release records of the specified cluster
+func (b *backend) GetReleases(clusterName string) ([]storage.Release, error) {
+	names, err := b.getKeys(b.key(sitesP, clusterName, releasesP))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var releases []storage.Release
+	for _, name := range names {
+		release, err := b.GetRelease(clusterName, name)
+		if err != nil {
+			if trace.IsNotFound(err) {
+				continue
+			}
+			return nil, trace.Wrap(err)
+		}
+		releases = append(releases, release)
+	}
+	return releases, nil
+}
+
+// DeleteRelease deletes the release record with the specified name
+func (b *backend) DeleteRelease(clusterName, name string) error {
+	err := b.deleteKey(b.key(sitesP, clusterName, releasesP, name))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return trace.NotFound("release %q not found", name)
+		}
+		return trace.Wrap(err)
+	}
+	return nil
+}
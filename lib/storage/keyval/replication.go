@@ -0,0 +1,98 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// UpsertReplication creates or updates the replication
+// of the specified cluster
+func (b *backend) UpsertReplication(clusterName string, replication storage.Replication) error {
+	if err := replication.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	data, err := storage.MarshalReplication(replication)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = b.upsertValBytes(b.key(sitesP, clusterName, replicationP), data, forever)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// GetReplication returns the replication of the specified cluster
+func (b *backend) GetReplication(clusterName string) (storage.Replication, error) {
+	data, err := b.getValBytes(b.key(sitesP, clusterName, replicationP))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("replication not found")
+		}
+		return nil, trace.Wrap(err)
+	}
+	return storage.UnmarshalReplication(data)
+}
+
+// DeleteReplication deletes the replication of the specified cluster
+func (b *backend) DeleteReplication(clusterName string) error {
+	err := b.deleteKey(b.key(sitesP, clusterName, replicationP))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return trace.NotFound("replication not found")
+		}
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// UpsertReplicationStatus updates the status of the replication
+func (b *backend) UpsertReplicationStatus(clusterName string, status storage.ReplicationStatus) error {
+	err := b.upsertVal(b.key(sitesP, clusterName, replicationStatusP), status, forever)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// GetReplicationStatus returns the status of the replication
+func (b *backend) GetReplicationStatus(clusterName string) (*storage.ReplicationStatus, error) {
+	var status storage.ReplicationStatus
+	err := b.getVal(b.key(sitesP, clusterName, replicationStatusP), &status)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("replication status not found")
+		}
+		return nil, trace.Wrap(err)
+	}
+	return &status, nil
+}
+
+// DeleteReplicationStatus deletes the status of the replication
+func (b *backend) DeleteReplicationStatus(clusterName string) error {
+	err := b.deleteKey(b.key(sitesP, clusterName, replicationStatusP))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return trace.NotFound("replication status not found")
+		}
+		return trace.Wrap(err)
+	}
+	return nil
+}
@@ -0,0 +1,559 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/secrets"
+
+	teleutils "github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"github.com/sirupsen/logrus"
+)
+
+// SecretsConfig configures the external storage of private keys and tokens
+type SecretsConfig struct {
+	// Secrets optionally configures the secrets provider to keep
+	// the private keys and tokens in instead of the backend
+	Secrets *secrets.Config `json:"secrets,omitempty" yaml:"secrets"`
+	// SecretsProvider overrides the secrets provider, used in tests
+	SecretsProvider secrets.Provider `json:"-" yaml:"-"`
+}
+
+// wrap returns the engine that keeps the secret records of the specified
+// engine with the secrets provider if the provider is configured
+func (r SecretsConfig) wrap(clock clockwork.Clock, engine kvengine) (kvengine, error) {
+	provider := r.SecretsProvider
+	if provider == nil && r.Secrets != nil {
+		var err error
+		provider, err = secrets.New(*r.Secrets)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	if provider == nil {
+		return engine, nil
+	}
+	if clock == nil {
+		clock = clockwork.NewRealClock()
+	}
+	root := engine.key("")
+	ctx, cancel := context.WithCancel(context.Background())
+	secretsEngine := &secretsEngine{
+		kvengine:    engine,
+		provider:    provider,
+		clock:       clock,
+		root:        root[:len(root)-1],
+		cancel:      cancel,
+		FieldLogger: logrus.WithField(trace.Component, "secrets"),
+	}
+	go secretsEngine.expireLoop(ctx)
+	return secretsEngine, nil
+}
+
+// secretsEngine keeps the values of the secret records - cert authorities
+// with their private keys and tokens - with the secrets provider.
+//
+// The wrapped engine only stores a reference to the secret. Every write
+// creates a new secret that replaces the previous one once the reference
+// has been updated, so compare-and-swap semantics are preserved.
+// Records written before the provider has been configured are read as-is.
+//
+// The secrets of the records written with a TTL are leased for the same
+// duration and removed from the provider once the lease has expired since
+// the backend expires the records without notice
+type secretsEngine struct {
+	kvengine
+	logrus.FieldLogger
+	provider secrets.Provider
+	clock    clockwork.Clock
+	// root is the key all records of the backend are stored under
+	root key
+	// cancel stops the removal of the expired secrets
+	cancel context.CancelFunc
+}
+
+// Close stops the removal of the expired secrets and closes the wrapped engine
+func (e *secretsEngine) Close() error {
+	e.cancel()
+	return e.kvengine.Close()
+}
+
+func (e *secretsEngine) createVal(k key, val interface{}, ttl time.Duration) error {
+	if !e.isSecret(k) {
+		return e.kvengine.createVal(k, val, ttl)
+	}
+	data, err := json.Marshal(val)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return e.createValBytes(k, data, ttl)
+}
+
+func (e *secretsEngine) createValBytes(k key, data []byte, ttl time.Duration) error {
+	if !e.isSecret(k) {
+		return e.kvengine.createValBytes(k, data, ttl)
+	}
+	return e.write(k, data, nil, ttl, func(ref []byte) error {
+		return e.kvengine.createValBytes(k, ref, ttl)
+	})
+}
+
+func (e *secretsEngine) upsertVal(k key, val interface{}, ttl time.Duration) error {
+	if !e.isSecret(k) {
+		return e.kvengine.upsertVal(k, val, ttl)
+	}
+	data, err := json.Marshal(val)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return e.upsertValBytes(k, data, ttl)
+}
+
+func (e *secretsEngine) upsertValBytes(k key, data []byte, ttl time.Duration) error {
+	if !e.isSecret(k) {
+		return e.kvengine.upsertValBytes(k, data, ttl)
+	}
+	prev, err := e.getRef(k)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return e.write(k, data, prev, ttl, func(ref []byte) error {
+		return e.kvengine.upsertValBytes(k, ref, ttl)
+	})
+}
+
+func (e *secretsEngine) updateVal(k key, val interface{}, ttl time.Duration) error {
+	if !e.isSecret(k) {
+		return e.kvengine.updateVal(k, val, ttl)
+	}
+	data, err := json.Marshal(val)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return e.updateValBytes(k, data, ttl)
+}
+
+func (e *secretsEngine) updateValBytes(k key, data []byte, ttl time.Duration) error {
+	if !e.isSecret(k) {
+		return e.kvengine.updateValBytes(k, data, ttl)
+	}
+	prev, err := e.getRef(k)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return e.write(k, data, prev, ttl, func(ref []byte) error {
+		return e.kvengine.updateValBytes(k, ref, ttl)
+	})
+}
+
+func (e *secretsEngine) compareAndSwap(k key, val, prevVal, outVal interface{}, ttl time.Duration) error {
+	if !e.isSecret(k) {
+		return e.kvengine.compareAndSwap(k, val, prevVal, outVal, ttl)
+	}
+	data, err := json.Marshal(val)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	var prevData []byte
+	if prevVal != nil {
+		prevData, err = json.Marshal(prevVal)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	var outData []byte
+	if err := e.compareAndSwapBytes(k, data, prevData, &outData, ttl); err != nil {
+		return trace.Wrap(err)
+	}
+	if prevVal != nil {
+		return trace.Wrap(json.Unmarshal(outData, outVal))
+	}
+	return nil
+}
+
+func (e *secretsEngine) compareAndSwapBytes(k key, val, prevVal []byte, outVal *[]byte, ttl time.Duration) error {
+	if !e.isSecret(k) {
+		return e.kvengine.compareAndSwapBytes(k, val, prevVal, outVal, ttl)
+	}
+	if prevVal == nil {
+		return e.write(k, val, nil, ttl, func(ref []byte) error {
+			var out []byte
+			return e.kvengine.compareAndSwapBytes(k, ref, nil, &out, ttl)
+		})
+	}
+	current, err := e.kvengine.getValBytes(k)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	currentVal, err := e.open(current)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if !bytes.Equal(currentVal, prevVal) {
+		return trace.CompareFailed("value of %q has been updated concurrently", k)
+	}
+	err = e.write(k, val, current, ttl, func(ref []byte) error {
+		var out []byte
+		return e.kvengine.compareAndSwapBytes(k, ref, current, &out, ttl)
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	*outVal = currentVal
+	return nil
+}
+
+func (e *secretsEngine) updateTTL(k key, ttl time.Duration) error {
+	if !e.isSecret(k) {
+		return e.kvengine.updateTTL(k, ttl)
+	}
+	if err := e.kvengine.updateTTL(k, ttl); err != nil {
+		return err
+	}
+	ref, err := e.getRef(k)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(e.lease(ref, ttl))
+}
+
+func (e *secretsEngine) getVal(k key, val interface{}) error {
+	if !e.isSecret(k) {
+		return e.kvengine.getVal(k, val)
+	}
+	data, err := e.getValBytes(k)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(json.Unmarshal(data, val))
+}
+
+func (e *secretsEngine) getValBytes(k key) ([]byte, error) {
+	data, err := e.kvengine.getValBytes(k)
+	if err != nil || !e.isSecret(k) {
+		return data, err
+	}
+	return e.open(data)
+}
+
+func (e *secretsEngine) deleteKey(k key) error {
+	if !e.isSecret(k) {
+		return e.kvengine.deleteKey(k)
+	}
+	prev, err := e.getRef(k)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := e.kvengine.deleteKey(k); err != nil {
+		return err
+	}
+	e.deleteSecret(prev)
+	return nil
+}
+
+func (e *secretsEngine) compareAndDelete(k key, prevVal interface{}) error {
+	if !e.isSecret(k) {
+		return e.kvengine.compareAndDelete(k, prevVal)
+	}
+	prevData, err := json.Marshal(prevVal)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	current, err := e.kvengine.getValBytes(k)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	currentVal, err := e.open(current)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if !bytes.Equal(currentVal, prevData) {
+		return trace.CompareFailed("value of %q has been updated concurrently", k)
+	}
+	if err := e.kvengine.compareAndDelete(k, json.RawMessage(current)); err != nil {
+		return err
+	}
+	e.deleteSecret(current)
+	return nil
+}
+
+func (e *secretsEngine) deleteDir(k key) error {
+	relative := e.relative(k)
+	if len(relative) == 0 || !isSecretKey(relative) {
+		return e.kvengine.deleteDir(k)
+	}
+	var refs [][]byte
+	if err := e.collectRefs(k, &refs); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := e.kvengine.deleteDir(k); err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		e.deleteSecret(ref)
+	}
+	return nil
+}
+
+// write stores the value of the record with the specified key as a new secret
+// and then updates the record with the reference to the secret using fn.
+// The new secret is leased for the specified TTL of the record.
+// The new secret is removed if fn fails, otherwise the previous secret
+// referenced by prev is removed
+func (e *secretsEngine) write(k key, data, prev []byte, ttl time.Duration, fn func(ref []byte) error) error {
+	if isSecretRef(data) {
+		// the reference is being restored, e.g. by the state import
+		if err := e.lease(data, ttl); err != nil {
+			return trace.Wrap(err)
+		}
+		return fn(data)
+	}
+	suffix, err := teleutils.CryptoRandomHex(8)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	path := strings.Join(append(e.relative(k), suffix), "/")
+	if err := e.provider.PutSecret(context.TODO(), path, data); err != nil {
+		return trace.Wrap(err, "failed to store secret %v", path)
+	}
+	ref, err := json.Marshal(secretRef{Path: path})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// the lease is created before the record so the secret is removed
+	// even if the record is never written
+	if err := e.lease(ref, ttl); err != nil {
+		e.deleteSecret(ref)
+		return trace.Wrap(err)
+	}
+	if err := fn(ref); err != nil {
+		e.deleteSecret(ref)
+		return err
+	}
+	e.deleteSecret(prev)
+	return nil
+}
+
+// open returns the value of the secret referenced by the specified
+// record value. Values that are not references are returned as-is
+func (e *secretsEngine) open(data []byte) ([]byte, error) {
+	ref, ok := parseSecretRef(data)
+	if !ok {
+		return data, nil
+	}
+	value, err := e.provider.GetSecret(context.TODO(), ref.Path)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to read secret %v", ref.Path)
+	}
+	return value, nil
+}
+
+// getRef returns the raw value of the record with the specified key,
+// or nil if the record does not exist
+func (e *secretsEngine) getRef(k key) ([]byte, error) {
+	data, err := e.kvengine.getValBytes(k)
+	if err != nil && !trace.IsNotFound(err) && !trace.IsBadParameter(err) {
+		return nil, trace.Wrap(err)
+	}
+	return data, nil
+}
+
+// deleteSecret removes the secret referenced by the specified record value
+func (e *secretsEngine) deleteSecret(data []byte) {
+	ref, ok := parseSecretRef(data)
+	if !ok {
+		return
+	}
+	err := e.provider.DeleteSecret(context.TODO(), ref.Path)
+	if err != nil && !trace.IsNotFound(err) {
+		e.WithError(err).Warnf("Failed to delete secret %v.", ref.Path)
+		return
+	}
+	err = e.kvengine.deleteKey(e.leaseKey(ref.Path))
+	if err != nil && !trace.IsNotFound(err) {
+		e.WithError(err).Warnf("Failed to delete lease of secret %v.", ref.Path)
+	}
+}
+
+// lease records that the secret referenced by the specified record value
+// expires after the specified TTL, or removes the lease if the TTL is not set
+func (e *secretsEngine) lease(data []byte, ttl time.Duration) error {
+	ref, ok := parseSecretRef(data)
+	if !ok {
+		return nil
+	}
+	if ttl == forever {
+		err := e.kvengine.deleteKey(e.leaseKey(ref.Path))
+		if err != nil && !trace.IsNotFound(err) {
+			return trace.Wrap(err)
+		}
+		return nil
+	}
+	return trace.Wrap(e.kvengine.upsertVal(e.leaseKey(ref.Path), secretLease{
+		Path:    ref.Path,
+		Expires: e.clock.Now().UTC().Add(ttl),
+	}, forever))
+}
+
+// expireLoop periodically removes the secrets with expired leases
+func (e *secretsEngine) expireLoop(ctx context.Context) {
+	for {
+		select {
+		case <-e.clock.After(defaults.SecretLeaseCheckInterval):
+			if err := e.expireSecrets(); err != nil {
+				e.WithError(err).Warn("Failed to remove expired secrets.")
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// expireSecrets removes the secrets with expired leases.
+// The records referencing them have been expired by the backend
+func (e *secretsEngine) expireSecrets() error {
+	ids, err := e.kvengine.getKeys(e.key(secretLeasesP))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil
+		}
+		return trace.Wrap(err)
+	}
+	now := e.clock.Now().UTC()
+	for _, id := range ids {
+		var lease secretLease
+		err := e.kvengine.getVal(e.key(secretLeasesP, id), &lease)
+		if err != nil {
+			if trace.IsNotFound(err) {
+				continue
+			}
+			return trace.Wrap(err)
+		}
+		if now.Before(lease.Expires) {
+			continue
+		}
+		ref, err := json.Marshal(secretRef{Path: lease.Path})
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		e.deleteSecret(ref)
+	}
+	return nil
+}
+
+// leaseKey returns the key of the lease of the secret with the specified path
+func (e *secretsEngine) leaseKey(path string) key {
+	return e.key(secretLeasesP, base64.RawURLEncoding.EncodeToString([]byte(path)))
+}
+
+// collectRefs saves the references to the secrets stored in the
+// directory with the specified key to refs
+func (e *secretsEngine) collectRefs(dir key, refs *[][]byte) error {
+	names, err := e.kvengine.getKeys(dir)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	for _, name := range names {
+		child := append(append(key{}, dir...), name)
+		data, err := e.kvengine.getValBytes(child)
+		if err == nil {
+			*refs = append(*refs, data)
+			continue
+		}
+		if trace.IsNotFound(err) {
+			continue
+		}
+		if !trace.IsBadParameter(err) {
+			return trace.Wrap(err)
+		}
+		// the key is a directory
+		if err := e.collectRefs(child, refs); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// isSecret returns true if the record with the specified key is secret
+func (e *secretsEngine) isSecret(k key) bool {
+	return isSecretKey(e.relative(k))
+}
+
+// relative returns the specified key relative to the root key
+func (e *secretsEngine) relative(k key) []string {
+	if len(k) < len(e.root) {
+		return nil
+	}
+	return append([]string{}, k[len(e.root):]...)
+}
+
+func isSecretKey(relative []string) bool {
+	if len(relative) == 0 {
+		return false
+	}
+	_, ok := secretKeys[relative[0]]
+	return ok
+}
+
+// secretKeys lists the top-level keys of the records that contain
+// private keys, tokens and credentials
+var secretKeys = map[string]struct{}{
+	authoritiesP:        {},
+	provisioningTokensP: {},
+	installTokensP:      {},
+	apikeysP:            {},
+	loginsP:             {},
+}
+
+// secretRef is the value stored in the backend in place of the secret value
+type secretRef struct {
+	// Path is the path of the secret with the secrets provider
+	Path string `json:"gravity_secret_ref"`
+}
+
+// secretLease records when the secret of a record written with a TTL expires
+type secretLease struct {
+	// Path is the path of the secret with the secrets provider
+	Path string `json:"path"`
+	// Expires is when the record referencing the secret expires
+	Expires time.Time `json:"expires"`
+}
+
+func isSecretRef(data []byte) bool {
+	_, ok := parseSecretRef(data)
+	return ok
+}
+
+func parseSecretRef(data []byte) (*secretRef, bool) {
+	if !bytes.Contains(data, []byte(`"gravity_secret_ref"`)) {
+		return nil, false
+	}
+	var ref secretRef
+	if err := json.Unmarshal(data, &ref); err != nil || ref.Path == "" {
+		return nil, false
+	}
+	return &ref, true
+}
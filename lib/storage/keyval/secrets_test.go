@@ -0,0 +1,263 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	. "gopkg.in/check.v1"
+)
+
+type SecretsSuite struct {
+	path     string
+	provider *memorySecrets
+	clock    clockwork.FakeClock
+}
+
+var _ = Suite(&SecretsSuite{})
+
+func (s *SecretsSuite) SetUpTest(c *C) {
+	s.path = filepath.Join(c.MkDir(), "bolt.db")
+	s.provider = &memorySecrets{secrets: make(map[string][]byte)}
+	s.clock = clockwork.NewFakeClockAt(time.Date(2019, time.June, 1, 10, 0, 0, 0, time.UTC))
+}
+
+func (s *SecretsSuite) newBackend(c *C, provider *memorySecrets) storage.Backend {
+	config := BoltConfig{
+		Path:            s.path,
+		Clock:           s.clock,
+		ChangelogConfig: ChangelogConfig{Changelog: true},
+	}
+	if provider != nil {
+		config.SecretsProvider = provider
+	}
+	backend, err := NewBolt(config)
+	c.Assert(err, IsNil)
+	return backend
+}
+
+func (s *SecretsSuite) TestKeepsTokensWithProvider(c *C) {
+	backend := s.newBackend(c, s.provider)
+	defer backend.Close()
+
+	_, err := backend.CreateProvisioningToken(storage.ProvisioningToken{
+		Token:      "join-token",
+		Type:       storage.ProvisioningTokenTypeExpand,
+		AccountID:  "account",
+		SiteDomain: "example.com",
+	})
+	c.Assert(err, IsNil)
+	c.Assert(s.provider.count(), Equals, 1)
+	c.Assert(s.rawValue(c, backend, provisioningTokensP, "join-token"), Not(Matches), ".*example.com.*")
+
+	used, err := backend.UseProvisioningToken("join-token")
+	c.Assert(err, IsNil)
+	c.Assert(used.Uses, Equals, 1)
+	// the previous secret has been replaced
+	c.Assert(s.provider.count(), Equals, 1)
+	token, err := backend.GetProvisioningToken("join-token")
+	c.Assert(err, IsNil)
+	c.Assert(token.Uses, Equals, 1)
+
+	// the changelog only records the references
+	changes, err := backend.(storage.StateChangelog).GetStateChanges(time.Time{})
+	c.Assert(err, IsNil)
+	for _, change := range changes {
+		c.Assert(bytes.Contains(change.PrevValue, []byte("example.com")), Equals, false)
+	}
+
+	c.Assert(backend.DeleteProvisioningToken("join-token"), IsNil)
+	c.Assert(s.provider.count(), Equals, 0)
+	_, err = backend.GetProvisioningToken("join-token")
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%v", err))
+}
+
+func (s *SecretsSuite) TestReadsRecordsWrittenWithoutProvider(c *C) {
+	backend := s.newBackend(c, nil)
+	_, err := backend.CreateProvisioningToken(storage.ProvisioningToken{
+		Token:      "join-token",
+		Type:       storage.ProvisioningTokenTypeExpand,
+		AccountID:  "account",
+		SiteDomain: "example.com",
+	})
+	c.Assert(err, IsNil)
+	c.Assert(backend.Close(), IsNil)
+
+	backend = s.newBackend(c, s.provider)
+	defer backend.Close()
+	token, err := backend.GetProvisioningToken("join-token")
+	c.Assert(err, IsNil)
+	c.Assert(token.SiteDomain, Equals, "example.com")
+
+	// the record is moved to the provider once updated
+	_, err = backend.UseProvisioningToken("join-token")
+	c.Assert(err, IsNil)
+	c.Assert(s.provider.count(), Equals, 1)
+	c.Assert(s.rawValue(c, backend, provisioningTokensP, "join-token"), Not(Matches), ".*example.com.*")
+}
+
+func (s *SecretsSuite) TestDiscardsSecretOfFailedWrite(c *C) {
+	backend := s.newBackend(c, s.provider)
+	defer backend.Close()
+	token := storage.ProvisioningToken{
+		Token:      "join-token",
+		Type:       storage.ProvisioningTokenTypeExpand,
+		AccountID:  "account",
+		SiteDomain: "example.com",
+	}
+	_, err := backend.CreateProvisioningToken(token)
+	c.Assert(err, IsNil)
+	_, err = backend.CreateProvisioningToken(token)
+	c.Assert(trace.IsAlreadyExists(err), Equals, true, Commentf("%v", err))
+	c.Assert(s.provider.count(), Equals, 1)
+}
+
+func (s *SecretsSuite) TestRemovesSecretsOfExpiredRecords(c *C) {
+	backend := s.newBackend(c, s.provider)
+	defer backend.Close()
+	_, err := backend.CreateProvisioningToken(storage.ProvisioningToken{
+		Token:      "join-token",
+		Type:       storage.ProvisioningTokenTypeExpand,
+		AccountID:  "account",
+		SiteDomain: "example.com",
+		Expires:    s.clock.Now().Add(time.Hour),
+	})
+	c.Assert(err, IsNil)
+	_, err = backend.CreateProvisioningToken(storage.ProvisioningToken{
+		Token:      "install-token",
+		Type:       storage.ProvisioningTokenTypeInstall,
+		AccountID:  "account",
+		SiteDomain: "example.com",
+	})
+	c.Assert(err, IsNil)
+	c.Assert(s.provider.count(), Equals, 2)
+
+	engine := s.engine(backend)
+	c.Assert(engine.expireSecrets(), IsNil)
+	c.Assert(s.provider.count(), Equals, 2)
+
+	s.clock.Advance(2 * time.Hour)
+	c.Assert(engine.expireSecrets(), IsNil)
+	c.Assert(s.provider.count(), Equals, 1, Commentf("only the secret of the expired token should be removed"))
+	_, err = backend.GetProvisioningToken("join-token")
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%v", err))
+	_, err = backend.GetProvisioningToken("install-token")
+	c.Assert(err, IsNil)
+}
+
+func (s *SecretsSuite) TestKeepsLoginsWithProvider(c *C) {
+	backend := s.newBackend(c, s.provider)
+	defer backend.Close()
+	_, err := backend.UpsertLoginEntry(storage.LoginEntry{
+		Email:        "alice@example.com",
+		Password:     "registry-password",
+		OpsCenterURL: "hub.example.com",
+	})
+	c.Assert(err, IsNil)
+	c.Assert(s.provider.count(), Equals, 1)
+	c.Assert(s.rawValue(c, backend, loginsP, "hub.example.com"), Not(Matches), ".*registry-password.*")
+	entry, err := backend.GetLoginEntry("hub.example.com")
+	c.Assert(err, IsNil)
+	c.Assert(entry.Password, Equals, "registry-password")
+}
+
+func (s *SecretsSuite) TestExportsReferences(c *C) {
+	src := s.newBackend(c, s.provider)
+	defer src.Close()
+	_, err := src.CreateProvisioningToken(storage.ProvisioningToken{
+		Token:      "join-token",
+		Type:       storage.ProvisioningTokenTypeExpand,
+		AccountID:  "account",
+		SiteDomain: "example.com",
+	})
+	c.Assert(err, IsNil)
+
+	var buf bytes.Buffer
+	_, err = storage.WriteStateArchive(context.TODO(), src, &buf)
+	c.Assert(err, IsNil)
+
+	dst, err := NewBolt(BoltConfig{
+		Path:          filepath.Join(c.MkDir(), "dst.db"),
+		SecretsConfig: SecretsConfig{SecretsProvider: s.provider},
+	})
+	c.Assert(err, IsNil)
+	defer dst.Close()
+	_, err = storage.ReadStateArchive(context.TODO(), dst, &buf)
+	c.Assert(err, IsNil)
+	c.Assert(s.provider.count(), Equals, 1)
+	c.Assert(s.rawValue(c, dst, provisioningTokensP, "join-token"), Not(Matches), ".*example.com.*")
+	token, err := dst.GetProvisioningToken("join-token")
+	c.Assert(err, IsNil)
+	c.Assert(token.SiteDomain, Equals, "example.com")
+}
+
+// rawValue returns the value of the record as stored in the database
+func (s *SecretsSuite) rawValue(c *C, b storage.Backend, keys ...string) string {
+	engine := s.engine(b)
+	data, err := engine.kvengine.getValBytes(engine.key(keys[0], keys[1:]...))
+	c.Assert(err, IsNil)
+	return string(data)
+}
+
+// engine returns the secrets engine of the specified backend
+func (s *SecretsSuite) engine(b storage.Backend) *secretsEngine {
+	return b.(*backend).kvengine.(*secretsEngine)
+}
+
+// memorySecrets is a secrets provider that keeps the secrets in memory
+type memorySecrets struct {
+	sync.Mutex
+	secrets map[string][]byte
+}
+
+func (r *memorySecrets) count() int {
+	r.Lock()
+	defer r.Unlock()
+	return len(r.secrets)
+}
+
+func (r *memorySecrets) GetSecret(ctx context.Context, path string) ([]byte, error) {
+	r.Lock()
+	defer r.Unlock()
+	value, ok := r.secrets[path]
+	if !ok {
+		return nil, trace.NotFound("secret %v not found", path)
+	}
+	return value, nil
+}
+
+func (r *memorySecrets) PutSecret(ctx context.Context, path string, value []byte) error {
+	r.Lock()
+	defer r.Unlock()
+	r.secrets[path] = value
+	return nil
+}
+
+func (r *memorySecrets) DeleteSecret(ctx context.Context, path string) error {
+	r.Lock()
+	defer r.Unlock()
+	delete(r.secrets, path)
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+var (
+	_ storage.StateExporter = (*electingBackend)(nil)
+	_ storage.StateExporter = (*pollingBackend)(nil)
+)
+
+// ExportState calls fn for every record stored in the backend.
+// Locks and leader election keys are not exported since they
+// are only meaningful to the running processes, neither is
+// the state changelog. Secrets kept with the secrets provider are
+// exported as references
+func (b *backend) ExportState(ctx context.Context, fn func(storage.StateItem) error) error {
+	engine := b.kvengine
+	if secrets, ok := engine.(*secretsEngine); ok {
+		engine = secrets.kvengine
+	}
+	return trace.Wrap(exportDir(ctx, engine, b.rootKey(), nil, fn))
+}
+
+// ImportState stores the specified record in the backend
+func (b *backend) ImportState(ctx context.Context, item storage.StateItem) error {
+	if err := ctx.Err(); err != nil {
+		return trace.Wrap(err)
+	}
+	key := b.key(item.Key[0], item.Key[1:]...)
+	if item.Dir {
+		return trace.Wrap(b.upsertDir(key, forever))
+	}
+	return trace.Wrap(b.upsertValBytes(key, item.Value, forever))
+}
+
+func exportDir(ctx context.Context, engine kvengine, dir key, path []string, fn func(storage.StateItem) error) error {
+	names, err := engine.getKeys(dir)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return trace.Wrap(err)
+		}
+		if len(path) == 0 && (name == locksP || name == leaderP || name == stateChangelogP) {
+			continue
+		}
+		childKey := append(append(key{}, dir...), name)
+		childPath := append(append([]string{}, path...), unescapeKey(name))
+		data, err := engine.getValBytes(childKey)
+		if err == nil {
+			err = fn(storage.StateItem{Key: childPath, Value: data})
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			continue
+		}
+		if trace.IsNotFound(err) {
+			// the record has expired or been removed during the export
+			continue
+		}
+		if !trace.IsBadParameter(err) {
+			return trace.Wrap(err)
+		}
+		// the key is a directory
+		if err := fn(storage.StateItem{Key: childPath, Dir: true}); err != nil {
+			return trace.Wrap(err)
+		}
+		if err := exportDir(ctx, engine, childKey, childPath, fn); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// rootKey returns the key all records of this backend are stored under
+func (b *backend) rootKey() key {
+	key := b.key("")
+	return key[:len(key)-1]
+}
+
+// unescapeKey reverts the escaping of path separators done
+// by some engines so the keys are portable between engines
+func unescapeKey(name string) string {
+	return strings.Replace(name, "%2F", "/", -1)
+}
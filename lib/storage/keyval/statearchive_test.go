@@ -0,0 +1,104 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"time"
+
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+type StateArchiveSuite struct{}
+
+var _ = Suite(&StateArchiveSuite{})
+
+func (s *StateArchiveSuite) TestExportsAndImportsState(c *C) {
+	dir := c.MkDir()
+	src, err := NewBolt(BoltConfig{Path: filepath.Join(dir, "src.db")})
+	c.Assert(err, IsNil)
+	defer src.Close()
+	account, err := src.CreateAccount(storage.Account{Org: "example.com"})
+	c.Assert(err, IsNil)
+	_, err = src.CreateRepository(storage.NewRepository("example.com"))
+	c.Assert(err, IsNil)
+	_, err = src.CreatePackage(storage.Package{
+		Repository: "example.com",
+		Name:       "app",
+		Version:    "0.0.1",
+		SHA512:     "checksum",
+	})
+	c.Assert(err, IsNil)
+	c.Assert(src.AcquireLock("lock", time.Minute), IsNil)
+
+	var buf bytes.Buffer
+	exported, err := storage.WriteStateArchive(context.TODO(), src, &buf)
+	c.Assert(err, IsNil)
+	c.Assert(exported > 0, Equals, true)
+
+	dst, err := NewBolt(BoltConfig{Path: filepath.Join(dir, "dst.db")})
+	c.Assert(err, IsNil)
+	defer dst.Close()
+	imported, err := storage.ReadStateArchive(context.TODO(), dst, &buf)
+	c.Assert(err, IsNil)
+	c.Assert(imported, Equals, exported)
+
+	out, err := dst.GetAccount(account.ID)
+	c.Assert(err, IsNil)
+	c.Assert(out.Org, Equals, "example.com")
+	pkg, err := dst.GetPackage("example.com", "app", "0.0.1")
+	c.Assert(err, IsNil)
+	c.Assert(pkg.SHA512, Equals, "checksum")
+	// locks are not carried over
+	c.Assert(dst.TryAcquireLock("lock", time.Minute), IsNil)
+}
+
+func (s *StateArchiveSuite) TestRejectsUnsupportedVersion(c *C) {
+	backend, err := NewBolt(BoltConfig{Path: filepath.Join(c.MkDir(), "bolt.db")})
+	c.Assert(err, IsNil)
+	defer backend.Close()
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	c.Assert(json.NewEncoder(w).Encode(storage.StateArchiveHeader{Version: 2}), IsNil)
+	c.Assert(w.Close(), IsNil)
+	_, err = storage.ReadStateArchive(context.TODO(), backend, &buf)
+	c.Assert(trace.IsBadParameter(err), Equals, true, Commentf("%v", err))
+
+	_, err = storage.ReadStateArchive(context.TODO(), backend, bytes.NewReader([]byte("not an archive")))
+	c.Assert(err, NotNil)
+}
+
+func (s *StateArchiveSuite) TestBackendsImplementStateExporter(c *C) {
+	// Callers only see storage.Backend and discover the archive
+	// support with a type assertion
+	backends := []storage.Backend{
+		&electingBackend{backend: &backend{}},
+		newPollingBackend(&backend{}),
+	}
+	for _, b := range backends {
+		_, ok := b.(storage.StateExporter)
+		c.Assert(ok, Equals, true, Commentf("%T does not implement storage.StateExporter", b))
+	}
+}
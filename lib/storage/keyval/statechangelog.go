@@ -0,0 +1,430 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/storage"
+
+	teleutils "github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	"github.com/sirupsen/logrus"
+)
+
+// ChangelogConfig configures the recording of the backend
+// mutations to the state changelog
+type ChangelogConfig struct {
+	// Changelog enables the state changelog
+	Changelog bool `json:"changelog,omitempty" yaml:"changelog"`
+	// ChangelogRetention is how long the changes are kept in the changelog.
+	// Defaults to defaults.StateChangelogRetention
+	ChangelogRetention time.Duration `json:"changelog_retention,omitempty" yaml:"changelog_retention"`
+}
+
+// wrap returns the engine that records the mutations of the specified
+// engine to the changelog if the changelog is enabled
+func (r ChangelogConfig) wrap(engine kvengine, clock clockwork.Clock) kvengine {
+	if !r.Changelog {
+		return engine
+	}
+	return newChangelogEngine(engine, clock, r.ChangelogRetention)
+}
+
+var (
+	_ storage.StateChangelog = (*electingBackend)(nil)
+	_ storage.StateChangelog = (*pollingBackend)(nil)
+)
+
+// GetStateChanges returns the changes recorded after the specified time
+// ordered from the oldest to the newest
+func (b *backend) GetStateChanges(since time.Time) ([]storage.StateChange, error) {
+	return b.changelog().getChanges(since)
+}
+
+// RestoreState reverts the changes recorded after the specified time
+// in the reverse order. The reverting writes are recorded as well so the
+// restore can itself be reverted
+func (b *backend) RestoreState(ctx context.Context, to time.Time) (count int, err error) {
+	changelog := b.changelog()
+	changes, err := changelog.getChanges(to)
+	if err != nil {
+		return 0, trace.Wrap(err)
+	}
+	for i := len(changes) - 1; i >= 0; i-- {
+		if err := ctx.Err(); err != nil {
+			return count, trace.Wrap(err)
+		}
+		if err := changelog.revert(changes[i]); err != nil {
+			return count, trace.Wrap(err, "failed to revert change %v of %v",
+				changes[i].ID, changes[i].Path())
+		}
+		count++
+	}
+	return count, nil
+}
+
+// changelog returns the engine that records the mutations to the changelog.
+// If the backend has not been configured with the changelog, the changes
+// are recorded with the default retention.
+// The changelog is kept below the secrets engine so it only records
+// the references to the secrets
+func (b *backend) changelog() *changelogEngine {
+	engine := b.kvengine
+	if secrets, ok := engine.(*secretsEngine); ok {
+		engine = secrets.kvengine
+	}
+	if changelog, ok := engine.(*changelogEngine); ok {
+		return changelog
+	}
+	return newChangelogEngine(engine, b.Clock, 0)
+}
+
+func newChangelogEngine(engine kvengine, clock clockwork.Clock, retention time.Duration) *changelogEngine {
+	if clock == nil {
+		clock = clockwork.NewRealClock()
+	}
+	if retention == 0 {
+		retention = defaults.StateChangelogRetention
+	}
+	root := engine.key("")
+	return &changelogEngine{
+		kvengine:    engine,
+		clock:       clock,
+		retention:   retention,
+		root:        root[:len(root)-1],
+		FieldLogger: logrus.WithField(trace.Component, "changelog"),
+	}
+}
+
+// changelogEngine records the previous value of every record before
+// it is changed by the wrapped engine (write-ahead) so the change can be
+// reverted later. Records with TTL and records that are only meaningful to
+// the running processes (locks, heartbeats, sessions, etc.) are not recorded
+type changelogEngine struct {
+	kvengine
+	logrus.FieldLogger
+	clock     clockwork.Clock
+	retention time.Duration
+	// root is the key all records of the backend are stored under
+	root key
+	// seq orders the changes recorded within the same clock tick
+	seq uint64
+}
+
+func (e *changelogEngine) createVal(k key, val interface{}, ttl time.Duration) error {
+	return e.record(k, storage.StateChangePut, ttl, func() error {
+		return e.kvengine.createVal(k, val, ttl)
+	})
+}
+
+func (e *changelogEngine) createValBytes(k key, data []byte, ttl time.Duration) error {
+	return e.record(k, storage.StateChangePut, ttl, func() error {
+		return e.kvengine.createValBytes(k, data, ttl)
+	})
+}
+
+func (e *changelogEngine) upsertVal(k key, val interface{}, ttl time.Duration) error {
+	return e.record(k, storage.StateChangePut, ttl, func() error {
+		return e.kvengine.upsertVal(k, val, ttl)
+	})
+}
+
+func (e *changelogEngine) upsertValBytes(k key, data []byte, ttl time.Duration) error {
+	return e.record(k, storage.StateChangePut, ttl, func() error {
+		return e.kvengine.upsertValBytes(k, data, ttl)
+	})
+}
+
+func (e *changelogEngine) updateVal(k key, val interface{}, ttl time.Duration) error {
+	return e.record(k, storage.StateChangePut, ttl, func() error {
+		return e.kvengine.updateVal(k, val, ttl)
+	})
+}
+
+func (e *changelogEngine) updateValBytes(k key, data []byte, ttl time.Duration) error {
+	return e.record(k, storage.StateChangePut, ttl, func() error {
+		return e.kvengine.updateValBytes(k, data, ttl)
+	})
+}
+
+func (e *changelogEngine) compareAndSwap(k key, val, prevVal, outVal interface{}, ttl time.Duration) error {
+	return e.record(k, storage.StateChangePut, ttl, func() error {
+		return e.kvengine.compareAndSwap(k, val, prevVal, outVal, ttl)
+	})
+}
+
+func (e *changelogEngine) compareAndSwapBytes(k key, val, prevVal []byte, outVal *[]byte, ttl time.Duration) error {
+	return e.record(k, storage.StateChangePut, ttl, func() error {
+		return e.kvengine.compareAndSwapBytes(k, val, prevVal, outVal, ttl)
+	})
+}
+
+func (e *changelogEngine) deleteKey(k key) error {
+	return e.record(k, storage.StateChangeDelete, forever, func() error {
+		return e.kvengine.deleteKey(k)
+	})
+}
+
+func (e *changelogEngine) compareAndDelete(k key, prevVal interface{}) error {
+	return e.record(k, storage.StateChangeDelete, forever, func() error {
+		return e.kvengine.compareAndDelete(k, prevVal)
+	})
+}
+
+func (e *changelogEngine) deleteDir(k key) error {
+	if !e.isRecorded(k, forever) {
+		return e.kvengine.deleteDir(k)
+	}
+	var changes []storage.StateChange
+	if err := e.collectDir(k, &changes); err != nil {
+		return trace.Wrap(err)
+	}
+	ids, err := e.appendChanges(changes...)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := e.kvengine.deleteDir(k); err != nil {
+		e.discard(ids)
+		return err
+	}
+	return nil
+}
+
+// record saves the current value of the record with the specified key
+// to the changelog and then executes the change with fn.
+// The saved value is discarded if fn fails
+func (e *changelogEngine) record(k key, op string, ttl time.Duration, fn func() error) error {
+	if !e.isRecorded(k, ttl) {
+		return fn()
+	}
+	change := storage.StateChange{
+		Op:  op,
+		Key: e.relative(k),
+	}
+	prev, err := e.kvengine.getValBytes(k)
+	if trace.IsBadParameter(err) {
+		// the key is a directory, values can not be written over it
+		return fn()
+	}
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	if err == nil {
+		change.PrevExists = true
+		change.PrevValue = prev
+	}
+	ids, err := e.appendChanges(change)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := fn(); err != nil {
+		e.discard(ids)
+		return err
+	}
+	return nil
+}
+
+// collectDir saves the records in the directory with the specified key
+// to changes. Directories are saved after their contents so the directories
+// are recreated before their contents when the changes are reverted
+func (e *changelogEngine) collectDir(dir key, changes *[]storage.StateChange) error {
+	names, err := e.kvengine.getKeys(dir)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	for _, name := range names {
+		child := append(append(key{}, dir...), name)
+		if !e.isRecorded(child, forever) {
+			continue
+		}
+		data, err := e.kvengine.getValBytes(child)
+		if err == nil {
+			*changes = append(*changes, storage.StateChange{
+				Op:         storage.StateChangeDelete,
+				Key:        e.relative(child),
+				PrevExists: true,
+				PrevValue:  data,
+			})
+			continue
+		}
+		if trace.IsNotFound(err) {
+			continue
+		}
+		if !trace.IsBadParameter(err) {
+			return trace.Wrap(err)
+		}
+		// the key is a directory
+		if err := e.collectDir(child, changes); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	*changes = append(*changes, storage.StateChange{
+		Op:         storage.StateChangeDelete,
+		Key:        e.relative(dir),
+		Dir:        true,
+		PrevExists: true,
+	})
+	return nil
+}
+
+// appendChanges adds the changes to the changelog and returns their IDs
+func (e *changelogEngine) appendChanges(changes ...storage.StateChange) (ids []string, err error) {
+	now := e.clock.Now().UTC()
+	suffix, err := teleutils.CryptoRandomHex(4)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, change := range changes {
+		change.Time = now
+		change.ID = fmt.Sprintf("%020d-%010d-%v", now.UnixNano(), atomic.AddUint64(&e.seq, 1), suffix)
+		data, err := json.Marshal(change)
+		if err != nil {
+			e.discard(ids)
+			return nil, trace.Wrap(err)
+		}
+		err = e.kvengine.createValBytes(e.key(stateChangelogP, change.ID), data, e.retention)
+		if err != nil {
+			e.discard(ids)
+			return nil, trace.Wrap(err)
+		}
+		ids = append(ids, change.ID)
+	}
+	return ids, nil
+}
+
+// discard removes the changes that have not been applied from the changelog
+func (e *changelogEngine) discard(ids []string) {
+	for _, id := range ids {
+		err := e.kvengine.deleteKey(e.key(stateChangelogP, id))
+		if err != nil && !trace.IsNotFound(err) {
+			e.WithError(err).Warnf("Failed to discard change %v.", id)
+		}
+	}
+}
+
+// getChanges returns the changes recorded after the specified time
+func (e *changelogEngine) getChanges(since time.Time) ([]storage.StateChange, error) {
+	ids, err := e.kvengine.getKeys(e.key(stateChangelogP))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, trace.Wrap(err)
+	}
+	sort.Strings(ids)
+	var changes []storage.StateChange
+	for _, id := range ids {
+		if !since.IsZero() && id < fmt.Sprintf("%020d", since.UnixNano()) {
+			// the ID starts with the time of the change
+			continue
+		}
+		data, err := e.kvengine.getValBytes(e.key(stateChangelogP, id))
+		if err != nil {
+			if trace.IsNotFound(err) {
+				// the change has expired
+				continue
+			}
+			return nil, trace.Wrap(err)
+		}
+		var change storage.StateChange
+		if err := json.Unmarshal(data, &change); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if change.Time.After(since) {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// revert restores the record changed by the specified change
+// to its previous state
+func (e *changelogEngine) revert(change storage.StateChange) error {
+	k := append(append(key{}, e.root...), change.Key...)
+	switch {
+	case change.PrevExists && change.Dir:
+		return trace.Wrap(e.upsertDir(k, forever))
+	case change.PrevExists:
+		return trace.Wrap(e.upsertValBytes(k, change.PrevValue, forever))
+	default:
+		err := e.deleteKey(k)
+		if err != nil && !trace.IsNotFound(err) {
+			return trace.Wrap(err)
+		}
+		return nil
+	}
+}
+
+// isRecorded returns true if the changes to the record with the specified
+// key and TTL should be recorded
+func (e *changelogEngine) isRecorded(k key, ttl time.Duration) bool {
+	if ttl != forever {
+		return false
+	}
+	relative := e.relative(k)
+	if len(relative) == 0 {
+		return false
+	}
+	if _, ok := unrecordedKeys[relative[0]]; ok {
+		return false
+	}
+	if relative[0] == usersP && len(relative) > 2 {
+		if _, ok := unrecordedUserKeys[relative[2]]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// relative returns the specified key relative to the root key
+func (e *changelogEngine) relative(k key) []string {
+	if len(k) < len(e.root) {
+		return nil
+	}
+	return append([]string{}, k[len(e.root):]...)
+}
+
+// unrecordedKeys lists the top-level keys of the records that are
+// either short-lived or only meaningful to the running processes and
+// hence not recorded in the changelog
+var unrecordedKeys = map[string]struct{}{
+	stateChangelogP:    {},
+	locksP:             {},
+	leaderP:            {},
+	peersP:             {},
+	objectsP:           {},
+	nodesP:             {},
+	tunnelsP:           {},
+	tunnelConnectionsP: {},
+	userTokensP:        {},
+	authRequestsP:      {},
+}
+
+// unrecordedUserKeys lists the keys of the short-lived user records
+var unrecordedUserKeys = map[string]struct{}{
+	webSessionsP:          {},
+	attemptsP:             {},
+	userU2fSignChallengeP: {},
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+	. "gopkg.in/check.v1"
+)
+
+type StateChangelogSuite struct {
+	backend storage.Backend
+	clock   clockwork.FakeClock
+}
+
+var _ = Suite(&StateChangelogSuite{})
+
+func (s *StateChangelogSuite) SetUpTest(c *C) {
+	s.clock = clockwork.NewFakeClockAt(time.Date(2019, time.June, 1, 10, 0, 0, 0, time.UTC))
+	var err error
+	s.backend, err = NewBolt(BoltConfig{
+		Path:            filepath.Join(c.MkDir(), "bolt.db"),
+		Clock:           s.clock,
+		ChangelogConfig: ChangelogConfig{Changelog: true},
+	})
+	c.Assert(err, IsNil)
+}
+
+func (s *StateChangelogSuite) TearDownTest(c *C) {
+	c.Assert(s.backend.Close(), IsNil)
+}
+
+func (s *StateChangelogSuite) TestRestoresPointInTime(c *C) {
+	changelog := s.backend.(storage.StateChangelog)
+	account, err := s.backend.CreateAccount(storage.Account{Org: "example.com"})
+	c.Assert(err, IsNil)
+	_, err = s.backend.CreateRepository(storage.NewRepository("example.com"))
+	c.Assert(err, IsNil)
+	_, err = s.backend.CreatePackage(storage.Package{
+		Repository: "example.com",
+		Name:       "app",
+		Version:    "0.0.1",
+		SHA512:     "checksum",
+	})
+	c.Assert(err, IsNil)
+
+	s.clock.Advance(time.Minute)
+	restorePoint := s.clock.Now()
+	s.clock.Advance(time.Minute)
+
+	c.Assert(s.backend.DeleteRepository("example.com"), IsNil)
+	_, err = s.backend.CreateAccount(storage.Account{ID: "other", Org: "other.com"})
+	c.Assert(err, IsNil)
+	c.Assert(s.backend.AcquireLock("lock", time.Minute), IsNil)
+
+	changes, err := changelog.GetStateChanges(restorePoint)
+	c.Assert(err, IsNil)
+	c.Assert(len(changes) > 0, Equals, true)
+	for _, change := range changes {
+		c.Assert(change.Key[0], Not(Equals), locksP, Commentf("locks are not recorded"))
+	}
+
+	s.clock.Advance(time.Minute)
+	beforeRestore := s.clock.Now().Add(-30 * time.Second)
+	count, err := changelog.RestoreState(context.TODO(), restorePoint)
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, len(changes))
+
+	out, err := s.backend.GetAccount(account.ID)
+	c.Assert(err, IsNil)
+	c.Assert(out.Org, Equals, "example.com")
+	_, err = s.backend.GetAccount("other")
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%v", err))
+	pkg, err := s.backend.GetPackage("example.com", "app", "0.0.1")
+	c.Assert(err, IsNil)
+	c.Assert(pkg.SHA512, Equals, "checksum")
+
+	// The restore can itself be reverted
+	count, err = changelog.RestoreState(context.TODO(), beforeRestore)
+	c.Assert(err, IsNil)
+	c.Assert(count > 0, Equals, true)
+	_, err = s.backend.GetRepository("example.com")
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%v", err))
+	_, err = s.backend.GetAccount("other")
+	c.Assert(err, IsNil)
+}
+
+func (s *StateChangelogSuite) TestDiscardsFailedChanges(c *C) {
+	changelog := s.backend.(storage.StateChangelog)
+	_, err := s.backend.CreateAccount(storage.Account{ID: "id", Org: "example.com"})
+	c.Assert(err, IsNil)
+	changes, err := changelog.GetStateChanges(time.Time{})
+	c.Assert(err, IsNil)
+	c.Assert(changes, HasLen, 1)
+
+	_, err = s.backend.CreateAccount(storage.Account{ID: "id", Org: "example.com"})
+	c.Assert(trace.IsAlreadyExists(err), Equals, true, Commentf("%v", err))
+	changes, err = changelog.GetStateChanges(time.Time{})
+	c.Assert(err, IsNil)
+	c.Assert(changes, HasLen, 1)
+	c.Assert(changes[0].Op, Equals, storage.StateChangePut)
+	c.Assert(changes[0].PrevExists, Equals, false)
+}
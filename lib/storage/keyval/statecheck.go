@@ -0,0 +1,169 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyval
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+var (
+	_ storage.StateChecker = (*electingBackend)(nil)
+	_ storage.StateChecker = (*pollingBackend)(nil)
+)
+
+// CheckState returns the records of operations that do not exist
+// and the tokens that have expired but have not been removed by the backend
+func (b *backend) CheckState(ctx context.Context) (problems []storage.StateProblem, err error) {
+	clusters, err := b.getKeys(b.key(sitesP))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, cluster := range clusters {
+		orphaned, err := b.checkOperations(ctx, []string{sitesP, cluster, operationsP})
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		problems = append(problems, orphaned...)
+	}
+	orphaned, err := b.checkOperations(ctx, []string{appOperationsP})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	problems = append(problems, orphaned...)
+	expired, err := b.checkTokens(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return append(problems, expired...), nil
+}
+
+// FixStateProblem removes the records of the specified problem
+func (b *backend) FixStateProblem(ctx context.Context, problem storage.StateProblem) error {
+	if err := ctx.Err(); err != nil {
+		return trace.Wrap(err)
+	}
+	if len(problem.Key) == 0 {
+		return trace.BadParameter("missing key of %v", problem)
+	}
+	key := b.key(problem.Key[0], problem.Key[1:]...)
+	switch problem.Kind {
+	case storage.StateProblemOrphanedProgress:
+		return trace.Wrap(b.deleteDir(key))
+	case storage.StateProblemExpiredToken:
+		if problem.Key[0] == provisioningTokensP {
+			return trace.Wrap(b.deleteKey(key))
+		}
+		return trace.Wrap(b.deleteDir(key))
+	}
+	return trace.BadParameter("backend cannot fix %v", problem)
+}
+
+// checkOperations returns the operation records under the specified
+// prefix that exist without the operation itself
+func (b *backend) checkOperations(ctx context.Context, prefix []string) (problems []storage.StateProblem, err error) {
+	ids, err := b.getKeys(b.key(prefix[0], prefix[1:]...))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		key := append(append([]string{}, prefix...), id)
+		_, err := b.getValBytes(b.key(key[0], append(key[1:], valP)...))
+		if err == nil {
+			continue
+		}
+		if !trace.IsNotFound(err) {
+			return nil, trace.Wrap(err)
+		}
+		problems = append(problems, storage.StateProblem{
+			Kind:    storage.StateProblemOrphanedProgress,
+			Key:     key,
+			Message: fmt.Sprintf("progress entries of missing operation %v", id),
+			Fixable: true,
+		})
+	}
+	return problems, nil
+}
+
+// checkTokens returns the provisioning and user tokens that have expired
+func (b *backend) checkTokens(ctx context.Context) (problems []storage.StateProblem, err error) {
+	now := b.Now()
+	tokens, err := b.getKeys(b.key(provisioningTokensP))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, token := range tokens {
+		if err := ctx.Err(); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		var t storage.ProvisioningToken
+		err := b.getVal(b.key(provisioningTokensP, token), &t)
+		if err != nil {
+			if trace.IsNotFound(err) {
+				continue
+			}
+			return nil, trace.Wrap(err)
+		}
+		if isExpired(t.Expires, now) {
+			problems = append(problems, expiredToken(
+				[]string{provisioningTokensP, token}, "provisioning", t.Expires))
+		}
+	}
+	tokens, err = b.getKeys(b.key(userTokensP))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, token := range tokens {
+		if err := ctx.Err(); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		var t storage.UserToken
+		err := b.getVal(b.key(userTokensP, token, valP), &t)
+		if err != nil {
+			if trace.IsNotFound(err) {
+				continue
+			}
+			return nil, trace.Wrap(err)
+		}
+		if isExpired(t.Expires, now) {
+			problems = append(problems, expiredToken(
+				[]string{userTokensP, token}, t.Type, t.Expires))
+		}
+	}
+	return problems, nil
+}
+
+func isExpired(expires, now time.Time) bool {
+	return !expires.IsZero() && expires.Before(now)
+}
+
+func expiredToken(key []string, kind string, expires time.Time) storage.StateProblem {
+	return storage.StateProblem{
+		Kind:    storage.StateProblemExpiredToken,
+		Key:     key,
+		Message: fmt.Sprintf("%v token expired at %v", kind, expires.Format(time.RFC3339)),
+		Fixable: true,
+	}
+}
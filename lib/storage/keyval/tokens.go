@@ -106,6 +106,29 @@ func (b *backend) GetSiteProvisioningTokens(siteDomain string) ([]storage.Provis
 	return out, nil
 }
 
+// UseProvisioningToken records a use of the specified provisioning token
+func (b *backend) UseProvisioningToken(token string) (*storage.ProvisioningToken, error) {
+	existing, err := b.GetProvisioningToken(token)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if existing.IsExhausted() {
+		return nil, trace.LimitExceeded("provisioning token(%v) has been used %v times out of %v",
+			token, existing.Uses, existing.MaxUses)
+	}
+	used := *existing
+	used.Uses++
+	var out storage.ProvisioningToken
+	err = b.compareAndSwap(b.key(provisioningTokensP, token), used, existing, &out, b.ttl(existing.Expires))
+	if err != nil {
+		if trace.IsCompareFailed(err) {
+			return nil, trace.CompareFailed("provisioning token(%v) is being used concurrently, try again", token)
+		}
+		return nil, trace.Wrap(err)
+	}
+	return &used, nil
+}
+
 func (b *backend) CreateInstallToken(t storage.InstallToken) (*storage.InstallToken, error) {
 	if err := t.Check(); err != nil {
 		return nil, trace.Wrap(err)
@@ -0,0 +1,146 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// MaintenanceWindow defines a weekly time window during which
+// node-disruptive phases of an update operation can be executed.
+// The window is defined in the local time of the node executing the operation
+type MaintenanceWindow struct {
+	// Weekday specifies the day of the week the window starts on
+	Weekday time.Weekday `json:"weekday"`
+	// Start specifies the start of the window as the offset from midnight
+	Start time.Duration `json:"start"`
+	// Duration specifies the length of the window.
+	// A window can extend past midnight into the next day
+	Duration time.Duration `json:"duration"`
+}
+
+// ParseMaintenanceWindow parses the maintenance window in the "<weekday> <HH:MM>-<HH:MM>"
+// format, e.g. "Sat 02:00-06:00"
+func ParseMaintenanceWindow(value string) (*MaintenanceWindow, error) {
+	fields := strings.Fields(value)
+	if len(fields) != 2 {
+		return nil, trace.BadParameter("invalid maintenance window %q, "+
+			"expected format is \"<weekday> <HH:MM>-<HH:MM>\", e.g. \"Sat 02:00-06:00\"", value)
+	}
+	weekday, err := parseWeekday(fields[0])
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	bounds := strings.Split(fields[1], "-")
+	if len(bounds) != 2 {
+		return nil, trace.BadParameter("invalid maintenance window time range %q, "+
+			"expected format is \"<HH:MM>-<HH:MM>\"", fields[1])
+	}
+	start, err := parseTimeOfDay(bounds[0])
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	end, err := parseTimeOfDay(bounds[1])
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if start == end {
+		return nil, trace.BadParameter("maintenance window %q is empty", value)
+	}
+	duration := end - start
+	if end < start {
+		duration += 24 * time.Hour
+	}
+	return &MaintenanceWindow{
+		Weekday:  weekday,
+		Start:    start,
+		Duration: duration,
+	}, nil
+}
+
+// Check validates this maintenance window
+func (r MaintenanceWindow) Check() error {
+	if r.Weekday < time.Sunday || r.Weekday > time.Saturday {
+		return trace.BadParameter("invalid maintenance window weekday: %v", int(r.Weekday))
+	}
+	if r.Start < 0 || r.Start >= 24*time.Hour {
+		return trace.BadParameter("maintenance window should start within a day, got %v", r.Start)
+	}
+	if r.Duration <= 0 || r.Duration > 24*time.Hour {
+		return trace.BadParameter("maintenance window should last up to a day, got %v", r.Duration)
+	}
+	return nil
+}
+
+// Contains returns true if the specified time falls within this maintenance window
+func (r MaintenanceWindow) Contains(t time.Time) bool {
+	return t.Before(r.lastStart(t).Add(r.Duration))
+}
+
+// Next returns the time the maintenance window opens next after the specified time.
+// If the window is open at the specified time, the time is returned as-is
+func (r MaintenanceWindow) Next(t time.Time) time.Time {
+	if r.Contains(t) {
+		return t
+	}
+	return r.lastStart(t).AddDate(0, 0, 7)
+}
+
+// String returns the maintenance window in the format accepted by ParseMaintenanceWindow
+func (r MaintenanceWindow) String() string {
+	end := (r.Start + r.Duration) % (24 * time.Hour)
+	return fmt.Sprintf("%v %v-%v", r.Weekday.String()[:3],
+		formatTimeOfDay(r.Start), formatTimeOfDay(end))
+}
+
+// lastStart returns the most recent time the maintenance window
+// has opened before or at the specified time
+func (r MaintenanceWindow) lastStart(t time.Time) time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	days := (int(t.Weekday()) - int(r.Weekday) + 7) % 7
+	start := midnight.AddDate(0, 0, -days).Add(r.Start)
+	if start.After(t) {
+		start = start.AddDate(0, 0, -7)
+	}
+	return start
+}
+
+func parseWeekday(value string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := day.String()
+		if strings.EqualFold(value, name) || strings.EqualFold(value, name[:3]) {
+			return day, nil
+		}
+	}
+	return 0, trace.BadParameter("invalid weekday %q, expected one of Sun, Mon, Tue, Wed, Thu, Fri, Sat", value)
+}
+
+func parseTimeOfDay(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, trace.BadParameter("invalid time of day %q, expected format is \"HH:MM\"", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func formatTimeOfDay(offset time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(offset/time.Hour), int(offset%time.Hour/time.Minute))
+}
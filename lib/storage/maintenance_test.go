@@ -0,0 +1,102 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"time"
+
+	check "gopkg.in/check.v1"
+)
+
+type MaintenanceWindowSuite struct{}
+
+var _ = check.Suite(&MaintenanceWindowSuite{})
+
+func (s *MaintenanceWindowSuite) TestParsesMaintenanceWindow(c *check.C) {
+	window, err := ParseMaintenanceWindow("Sat 02:00-06:00")
+	c.Assert(err, check.IsNil)
+	c.Assert(*window, check.DeepEquals, MaintenanceWindow{
+		Weekday:  time.Saturday,
+		Start:    2 * time.Hour,
+		Duration: 4 * time.Hour,
+	})
+	c.Assert(window.String(), check.Equals, "Sat 02:00-06:00")
+	c.Assert(window.Check(), check.IsNil)
+
+	window, err = ParseMaintenanceWindow("sunday 22:30-01:00")
+	c.Assert(err, check.IsNil)
+	c.Assert(*window, check.DeepEquals, MaintenanceWindow{
+		Weekday:  time.Sunday,
+		Start:    22*time.Hour + 30*time.Minute,
+		Duration: 2*time.Hour + 30*time.Minute,
+	})
+	c.Assert(window.String(), check.Equals, "Sun 22:30-01:00")
+
+	for _, value := range []string{"", "Sat", "Someday 02:00-06:00", "Sat 02:00", "Sat 2am-6am", "Sat 02:00-02:00"} {
+		_, err := ParseMaintenanceWindow(value)
+		c.Assert(err, check.NotNil, check.Commentf(value))
+	}
+}
+
+func (s *MaintenanceWindowSuite) TestMaintenanceWindowBoundaries(c *check.C) {
+	window := MaintenanceWindow{
+		Weekday:  time.Saturday,
+		Start:    22 * time.Hour,
+		Duration: 4 * time.Hour,
+	}
+	// 2019-06-01 is a Saturday
+	start := time.Date(2019, time.June, 1, 22, 0, 0, 0, time.UTC)
+	var testCases = []struct {
+		time     time.Time
+		contains bool
+		next     time.Time
+		comment  string
+	}{
+		{
+			time:    start.Add(-time.Minute),
+			next:    start,
+			comment: "right before the window",
+		},
+		{
+			time:     start,
+			contains: true,
+			next:     start,
+			comment:  "window opens",
+		},
+		{
+			time:     start.Add(3 * time.Hour),
+			contains: true,
+			next:     start.Add(3 * time.Hour),
+			comment:  "window extends into the next day",
+		},
+		{
+			time:    start.Add(4 * time.Hour),
+			next:    start.AddDate(0, 0, 7),
+			comment: "window closes",
+		},
+		{
+			time:    start.AddDate(0, 0, -3),
+			next:    start,
+			comment: "earlier in the week",
+		},
+	}
+	for _, tc := range testCases {
+		comment := check.Commentf(tc.comment)
+		c.Assert(window.Contains(tc.time), check.Equals, tc.contains, comment)
+		c.Assert(window.Next(tc.time), check.Equals, tc.next, comment)
+	}
+}
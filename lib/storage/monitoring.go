@@ -19,6 +19,7 @@ package storage
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"time"
 
 	teleservices "github.com/gravitational/teleport/lib/services"
@@ -188,6 +189,10 @@ type AlertTarget interface {
 	CheckAndSetDefaults() error
 	// GetEmail returns the recipient's email
 	GetEmail() string
+	// GetSlack returns the Slack alerts receiver
+	GetSlack() *AlertTargetSlack
+	// GetPagerDuty returns the PagerDuty alerts receiver
+	GetPagerDuty() *AlertTargetPagerDuty
 }
 
 // AlertTargetV2 defines a monitoring alert target
@@ -207,10 +212,32 @@ func (r *AlertTargetV2) GetEmail() string {
 	return r.Spec.Email
 }
 
+// GetSlack returns the Slack alerts receiver
+func (r *AlertTargetV2) GetSlack() *AlertTargetSlack {
+	return r.Spec.Slack
+}
+
+// GetPagerDuty returns the PagerDuty alerts receiver
+func (r *AlertTargetV2) GetPagerDuty() *AlertTargetPagerDuty {
+	return r.Spec.PagerDuty
+}
+
 // CheckAndSetDefaults checks validity of all parameters and sets defaults
 func (r *AlertTargetV2) CheckAndSetDefaults() error {
-	if r.Spec.Email == "" {
-		return trace.BadParameter("missing parameter Email")
+	if r.Spec.Email == "" && r.Spec.Slack == nil && r.Spec.PagerDuty == nil {
+		return trace.BadParameter("alert target must specify at least one " +
+			"of email, slack or pagerduty")
+	}
+
+	if r.Spec.Slack != nil {
+		webhookURL, err := url.Parse(r.Spec.Slack.WebhookURL)
+		if err != nil || webhookURL.Scheme != "https" || webhookURL.Host == "" {
+			return trace.BadParameter("slack webhook_url must be an https URL")
+		}
+	}
+
+	if r.Spec.PagerDuty != nil && r.Spec.PagerDuty.RoutingKey == "" {
+		return trace.BadParameter("missing parameter pagerduty routing_key")
 	}
 
 	return nil
@@ -255,16 +282,50 @@ func MarshalAlertTarget(target AlertTarget, opts ...teleservices.MarshalOption)
 // AlertTargetSpecV2 defines a monitoring alert target
 type AlertTargetSpecV2 struct {
 	// Email specifies recipient's email
-	Email string `json:"email"`
+	Email string `json:"email,omitempty"`
+	// Slack specifies the Slack channel to deliver alerts to
+	Slack *AlertTargetSlack `json:"slack,omitempty"`
+	// PagerDuty specifies the PagerDuty service to deliver alerts to
+	PagerDuty *AlertTargetPagerDuty `json:"pagerduty,omitempty"`
+}
+
+// AlertTargetSlack defines the Slack alerts receiver
+type AlertTargetSlack struct {
+	// WebhookURL is the Slack incoming webhook URL
+	WebhookURL string `json:"webhook_url"`
+	// Channel optionally overrides the default channel of the webhook
+	Channel string `json:"channel,omitempty"`
+}
+
+// AlertTargetPagerDuty defines the PagerDuty alerts receiver
+type AlertTargetPagerDuty struct {
+	// RoutingKey is the integration key of the PagerDuty service
+	RoutingKey string `json:"routing_key"`
 }
 
 // AlertTargetSpecV2Schema is JSON schema for a monitoring alert target
 const AlertTargetSpecV2Schema = `{
   "type": "object",
   "additionalProperties": false,
-  "required": ["email"],
   "properties": {
-    "email": {"type": "string"}
+    "email": {"type": "string"},
+    "slack": {
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["webhook_url"],
+      "properties": {
+        "webhook_url": {"type": "string"},
+        "channel": {"type": "string"}
+      }
+    },
+    "pagerduty": {
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["routing_key"],
+      "properties": {
+        "routing_key": {"type": "string"}
+      }
+    }
   }
 }`
 
@@ -0,0 +1,83 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"github.com/gravitational/gravity/lib/compare"
+
+	"github.com/gravitational/trace"
+	check "gopkg.in/check.v1"
+)
+
+type MonitoringSuite struct{}
+
+var _ = check.Suite(&MonitoringSuite{})
+
+func (s *MonitoringSuite) TestAlertTargetParsing(c *check.C) {
+	spec := `kind: alerttarget
+version: v2
+metadata:
+  name: alerts
+spec:
+  email: triage@example.com
+  slack:
+    webhook_url: https://hooks.slack.com/services/T0/B0/XXX
+    channel: "#ops"
+  pagerduty:
+    routing_key: 0123456789abcdef
+`
+	target, err := UnmarshalAlertTarget([]byte(spec))
+	c.Assert(err, check.IsNil)
+	c.Assert(target.CheckAndSetDefaults(), check.IsNil)
+	c.Assert(target.Spec, compare.DeepEquals, AlertTargetSpecV2{
+		Email: "triage@example.com",
+		Slack: &AlertTargetSlack{
+			WebhookURL: "https://hooks.slack.com/services/T0/B0/XXX",
+			Channel:    "#ops",
+		},
+		PagerDuty: &AlertTargetPagerDuty{
+			RoutingKey: "0123456789abcdef",
+		},
+	})
+}
+
+func (s *MonitoringSuite) TestAlertTargetValidation(c *check.C) {
+	var testCases = []struct {
+		spec    string
+		comment string
+	}{
+		{
+			spec:    "{}",
+			comment: "no receivers",
+		},
+		{
+			spec:    `{"slack": {"webhook_url": "http://hooks.slack.com/services/T0/B0/XXX"}}`,
+			comment: "plain text webhook URL",
+		},
+		{
+			spec:    `{"pagerduty": {"routing_key": ""}}`,
+			comment: "empty routing key",
+		},
+	}
+	for _, tc := range testCases {
+		spec := `{"kind": "alerttarget", "version": "v2", "metadata": {"name": "alerts"}, "spec": ` + tc.spec + `}`
+		target, err := UnmarshalAlertTarget([]byte(spec))
+		c.Assert(err, check.IsNil, check.Commentf(tc.comment))
+		err = target.CheckAndSetDefaults()
+		c.Assert(trace.IsBadParameter(err), check.Equals, true, check.Commentf(tc.comment))
+	}
+}
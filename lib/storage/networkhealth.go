@@ -0,0 +1,166 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
+)
+
+// NetworkHealthReport describes the network health between a cluster node
+// and the other cluster nodes as measured by the network monitor running
+// on the node
+type NetworkHealthReport struct {
+	// Node is the name of the node
+	Node string `json:"node"`
+	// AdvertiseIP is the node advertise IP address
+	AdvertiseIP string `json:"advertise_ip"`
+	// MTU is the size of the probes that check the packets of the maximum
+	// size are delivered without fragmentation
+	MTU int `json:"mtu"`
+	// Interval is how often the node probes the other nodes
+	Interval time.Duration `json:"interval"`
+	// Updated is the time of the report
+	Updated time.Time `json:"updated"`
+	// Peers is the network health between the node and the other nodes
+	Peers []NetworkPeerHealth `json:"peers,omitempty"`
+}
+
+// IsStale returns true if the report has not been updated for several
+// probe intervals as of the specified time
+func (r NetworkHealthReport) IsStale(now time.Time) bool {
+	interval := r.Interval
+	if interval == 0 {
+		interval = defaults.NetworkMonitorInterval
+	}
+	return now.Sub(r.Updated) > 3*interval
+}
+
+// NetworkPeerHealth describes the network health between the node and another node
+type NetworkPeerHealth struct {
+	// Node is the name of the other node
+	Node string `json:"node"`
+	// AdvertiseIP is the advertise IP address of the other node
+	AdvertiseIP string `json:"advertise_ip"`
+	// Host is the result of probing the other node on its advertise address
+	Host NetworkPathHealth `json:"host"`
+	// Overlay is the result of probing the other node across the overlay
+	// network. It is not set if the overlay address of the node is unknown
+	Overlay *NetworkPathHealth `json:"overlay,omitempty"`
+	// MTUExceeded is true if the probes of the maximum size do not reach
+	// the other node while the smaller probes do
+	MTUExceeded bool `json:"mtu_exceeded,omitempty"`
+}
+
+// NetworkPathHealth describes the result of probing a node address
+type NetworkPathHealth struct {
+	// Addr is the probed address
+	Addr string `json:"addr"`
+	// RTT is the average round-trip time of the answered probes
+	RTT time.Duration `json:"rtt"`
+	// PacketLoss is the percentage of the unanswered probes
+	PacketLoss float64 `json:"packet_loss"`
+}
+
+// IsUnreachable returns true if none of the probes have been answered
+func (r NetworkPathHealth) IsUnreachable() bool {
+	return r.PacketLoss >= 100
+}
+
+// NetworkProblem describes a network problem between a pair of nodes
+type NetworkProblem struct {
+	// From is the name of the node that has detected the problem
+	From string `json:"from"`
+	// To is the name of the other node, empty if the problem concerns
+	// the node itself
+	To string `json:"to,omitempty"`
+	// Critical is true if the nodes cannot communicate
+	Critical bool `json:"critical,omitempty"`
+	// Message describes the problem
+	Message string `json:"message"`
+}
+
+// String returns a textual representation of the problem
+func (r NetworkProblem) String() string {
+	if r.To == "" {
+		return fmt.Sprintf("%v: %v", r.From, r.Message)
+	}
+	return fmt.Sprintf("%v -> %v: %v", r.From, r.To, r.Message)
+}
+
+// NetworkProblems returns the network problems found in the specified
+// reports as of the specified time
+func NetworkProblems(reports []NetworkHealthReport, now time.Time) (problems []NetworkProblem) {
+	for _, report := range reports {
+		if report.IsStale(now) {
+			problems = append(problems, NetworkProblem{
+				From: report.Node,
+				Message: fmt.Sprintf("network monitor has not reported since %v",
+					report.Updated.UTC().Format(constants.HumanDateFormatSeconds)),
+			})
+			continue
+		}
+		for _, peer := range report.Peers {
+			for _, problem := range peer.problems() {
+				problem.From = report.Node
+				problem.To = peer.Node
+				problems = append(problems, problem)
+			}
+		}
+	}
+	return problems
+}
+
+func (r NetworkPeerHealth) problems() (problems []NetworkProblem) {
+	if r.Host.IsUnreachable() {
+		return []NetworkProblem{{
+			Critical: true,
+			Message:  fmt.Sprintf("%v is unreachable", r.Host.Addr),
+		}}
+	}
+	if r.Overlay != nil && r.Overlay.IsUnreachable() {
+		return []NetworkProblem{{
+			Critical: true,
+			Message: fmt.Sprintf("overlay network address %v is unreachable, check that the VXLAN port is open",
+				r.Overlay.Addr),
+		}}
+	}
+	for _, path := range []*NetworkPathHealth{&r.Host, r.Overlay} {
+		if path == nil {
+			continue
+		}
+		if path.PacketLoss > defaults.NetworkMonitorPacketLossThreshold {
+			problems = append(problems, NetworkProblem{
+				Message: fmt.Sprintf("%.0f%% packet loss to %v", path.PacketLoss, path.Addr),
+			})
+		}
+		if path.RTT > defaults.NetworkMonitorLatencyThreshold {
+			problems = append(problems, NetworkProblem{
+				Message: fmt.Sprintf("high latency to %v: %v", path.Addr, path.RTT.Round(time.Millisecond)),
+			})
+		}
+	}
+	if r.MTUExceeded {
+		problems = append(problems, NetworkProblem{
+			Message: fmt.Sprintf("packets of the maximum size are dropped by %v, check the path MTU", r.Host.Addr),
+		})
+	}
+	return problems
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"time"
+
+	check "gopkg.in/check.v1"
+)
+
+type NetworkHealthSuite struct{}
+
+var _ = check.Suite(&NetworkHealthSuite{})
+
+func (s *NetworkHealthSuite) TestFindsProblems(c *check.C) {
+	now := time.Date(2019, time.June, 1, 12, 0, 0, 0, time.UTC)
+	reports := []NetworkHealthReport{
+		{
+			Node:     "node-1",
+			Interval: 30 * time.Second,
+			Updated:  now.Add(-10 * time.Second),
+			Peers: []NetworkPeerHealth{
+				{
+					Node: "node-2",
+					Host: NetworkPathHealth{Addr: "10.0.0.2:3014", RTT: time.Millisecond},
+					Overlay: &NetworkPathHealth{
+						Addr: "10.244.2.0:3014", RTT: 200 * time.Millisecond, PacketLoss: 20,
+					},
+					MTUExceeded: true,
+				},
+				{
+					Node:    "node-3",
+					Host:    NetworkPathHealth{Addr: "10.0.0.3:3014", RTT: time.Millisecond},
+					Overlay: &NetworkPathHealth{Addr: "10.244.3.0:3014", PacketLoss: 100},
+				},
+				{
+					Node: "node-4",
+					Host: NetworkPathHealth{Addr: "10.0.0.4:3014", PacketLoss: 100},
+				},
+				{
+					Node: "node-5",
+					Host: NetworkPathHealth{Addr: "10.0.0.5:3014", RTT: time.Millisecond},
+				},
+			},
+		},
+		{
+			Node:     "node-2",
+			Interval: 30 * time.Second,
+			Updated:  now.Add(-2 * time.Minute),
+			Peers: []NetworkPeerHealth{
+				{
+					Node: "node-1",
+					Host: NetworkPathHealth{Addr: "10.0.0.1:3014", PacketLoss: 100},
+				},
+			},
+		},
+	}
+	var problems []string
+	var critical int
+	for _, problem := range NetworkProblems(reports, now) {
+		problems = append(problems, problem.String())
+		if problem.Critical {
+			critical++
+		}
+	}
+	c.Assert(problems, check.DeepEquals, []string{
+		"node-1 -> node-2: 20% packet loss to 10.244.2.0:3014",
+		"node-1 -> node-2: high latency to 10.244.2.0:3014: 200ms",
+		"node-1 -> node-2: packets of the maximum size are dropped by 10.0.0.2:3014, check the path MTU",
+		"node-1 -> node-3: overlay network address 10.244.3.0:3014 is unreachable, check that the VXLAN port is open",
+		"node-1 -> node-4: 10.0.0.4:3014 is unreachable",
+		"node-2: network monitor has not reported since Sat Jun  1 11:58:00 UTC",
+	})
+	c.Assert(critical, check.Equals, 2)
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/defaults"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	teleutils "github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/trace"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// NodePool describes a named group of cluster nodes of the same profile.
+// Nodes join a pool with `gravity join --pool=<name>`
+type NodePool interface {
+	// Resource provides common resource methods
+	teleservices.Resource
+	// CheckAndSetDefaults verifies that the object is valid
+	CheckAndSetDefaults() error
+	// GetProfile returns the node profile the nodes of this pool must have
+	GetProfile() string
+	// GetLabels returns the labels applied to the nodes of this pool
+	GetLabels() map[string]string
+	// GetMinCount returns the minimum number of nodes in this pool
+	GetMinCount() int
+	// GetMaxCount returns the maximum number of nodes in this pool.
+	// Zero means the number of nodes is not limited
+	GetMaxCount() int
+}
+
+// NewNodePool creates a new node pool resource
+func NewNodePool(name string, spec NodePoolSpecV2) NodePool {
+	return &NodePoolV2{
+		Kind:    KindNodePool,
+		Version: teleservices.V2,
+		Metadata: teleservices.Metadata{
+			Name:      name,
+			Namespace: defaults.Namespace,
+		},
+		Spec: spec,
+	}
+}
+
+// NodePoolV2 defines the node pool resource
+type NodePoolV2 struct {
+	// Metadata is resource metadata
+	teleservices.Metadata `json:"metadata"`
+	// Kind is a resource kind
+	Kind string `json:"kind"`
+	// Version is a resource version
+	Version string `json:"version"`
+	// Spec defines the node pool
+	Spec NodePoolSpecV2 `json:"spec"`
+}
+
+// GetProfile returns the node profile the nodes of this pool must have
+func (r *NodePoolV2) GetProfile() string {
+	return r.Spec.Profile
+}
+
+// GetLabels returns the labels applied to the nodes of this pool
+func (r *NodePoolV2) GetLabels() map[string]string {
+	return r.Spec.Labels
+}
+
+// GetMinCount returns the minimum number of nodes in this pool
+func (r *NodePoolV2) GetMinCount() int {
+	return r.Spec.MinCount
+}
+
+// GetMaxCount returns the maximum number of nodes in this pool
+func (r *NodePoolV2) GetMaxCount() int {
+	return r.Spec.MaxCount
+}
+
+// CheckAndSetDefaults checks validity of all parameters and sets defaults
+func (r *NodePoolV2) CheckAndSetDefaults() error {
+	if r.Metadata.Name == "" {
+		return trace.BadParameter("node pool name is required")
+	}
+	if errs := validation.IsDNS1123Label(r.Metadata.Name); len(errs) != 0 {
+		return trace.BadParameter("invalid node pool name %q: %v",
+			r.Metadata.Name, strings.Join(errs, "; "))
+	}
+	if err := r.Metadata.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	if r.Spec.Profile == "" {
+		return trace.BadParameter("node pool %v: profile is required", r.Metadata.Name)
+	}
+	if r.Spec.MinCount < 0 || r.Spec.MaxCount < 0 {
+		return trace.BadParameter("node pool %v: node count cannot be negative", r.Metadata.Name)
+	}
+	if r.Spec.MaxCount != 0 && r.Spec.MinCount > r.Spec.MaxCount {
+		return trace.BadParameter("node pool %v: min_count (%v) cannot exceed max_count (%v)",
+			r.Metadata.Name, r.Spec.MinCount, r.Spec.MaxCount)
+	}
+	for key, value := range r.Spec.Labels {
+		if errs := validation.IsQualifiedName(key); len(errs) != 0 {
+			return trace.BadParameter("invalid label key %q: %v", key, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) != 0 {
+			return trace.BadParameter("invalid label value %q: %v", value, strings.Join(errs, "; "))
+		}
+	}
+	if _, ok := r.Spec.Labels[defaults.KubernetesNodePoolLabel]; ok {
+		return trace.BadParameter("label %v is reserved", defaults.KubernetesNodePoolLabel)
+	}
+	return nil
+}
+
+// NodeLabels returns the labels to apply to a node joining this pool
+func NodeLabels(pool NodePool) map[string]string {
+	labels := make(map[string]string, len(pool.GetLabels())+1)
+	for key, value := range pool.GetLabels() {
+		labels[key] = value
+	}
+	labels[defaults.KubernetesNodePoolLabel] = pool.GetName()
+	return labels
+}
+
+// UnmarshalNodePool unmarshals node pool from JSON
+func UnmarshalNodePool(data []byte) (NodePool, error) {
+	if len(data) == 0 {
+		return nil, trace.BadParameter("empty node pool")
+	}
+
+	jsonData, err := teleutils.ToJSON(data)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var hdr teleservices.ResourceHeader
+	err = json.Unmarshal(jsonData, &hdr)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	switch hdr.Version {
+	case teleservices.V2:
+		var pool NodePoolV2
+		err := teleutils.UnmarshalWithSchema(GetNodePoolSchema(), &pool, jsonData)
+		if err != nil {
+			return nil, trace.BadParameter("%v", err)
+		}
+		if err := pool.CheckAndSetDefaults(); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &pool, nil
+	}
+	return nil, trace.BadParameter(
+		"%v resource version %q is not supported", KindNodePool, hdr.Version)
+}
+
+// MarshalNodePool marshals node pool into JSON
+func MarshalNodePool(pool NodePool, opts ...teleservices.MarshalOption) ([]byte, error) {
+	return json.Marshal(pool)
+}
+
+// NodePoolSpecV2 defines the node pool
+type NodePoolSpecV2 struct {
+	// Profile is the node profile from the application manifest
+	// the nodes of this pool must have
+	Profile string `json:"profile"`
+	// Labels are additional Kubernetes labels applied to the nodes of this pool
+	Labels map[string]string `json:"labels,omitempty"`
+	// MinCount is the minimum number of nodes in this pool
+	MinCount int `json:"min_count,omitempty"`
+	// MaxCount is the maximum number of nodes in this pool.
+	// Zero means the number of nodes is not limited
+	MaxCount int `json:"max_count,omitempty"`
+}
+
+// NodePoolSpecV2Schema is JSON schema for the node pool
+const NodePoolSpecV2Schema = `{
+  "type": "object",
+  "additionalProperties": false,
+  "required": ["profile"],
+  "properties": {
+    "profile": {"type": "string"},
+    "labels": {
+      "type": "object",
+      "patternProperties": {
+        "^.*$": {"type": "string"}
+      }
+    },
+    "min_count": {"type": "integer"},
+    "max_count": {"type": "integer"}
+  }
+}`
+
+// GetNodePoolSchema returns node pool schema for version V2
+func GetNodePoolSchema() string {
+	return fmt.Sprintf(teleservices.V2SchemaTemplate, MetadataSchema,
+		NodePoolSpecV2Schema, "")
+}
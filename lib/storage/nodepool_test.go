@@ -0,0 +1,128 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"github.com/gravitational/gravity/lib/compare"
+	"github.com/gravitational/gravity/lib/defaults"
+
+	"github.com/gravitational/trace"
+	check "gopkg.in/check.v1"
+)
+
+type NodePoolSuite struct{}
+
+var _ = check.Suite(&NodePoolSuite{})
+
+func (s *NodePoolSuite) TestResourceParsing(c *check.C) {
+	spec := `kind: nodepool
+version: v2
+metadata:
+  name: gpu
+spec:
+  profile: gpu-worker
+  labels:
+    accelerator: nvidia
+  min_count: 1
+  max_count: 3
+`
+	pool, err := UnmarshalNodePool([]byte(spec))
+	c.Assert(err, check.IsNil)
+	c.Assert(pool, compare.DeepEquals, NewNodePool("gpu", NodePoolSpecV2{
+		Profile:  "gpu-worker",
+		Labels:   map[string]string{"accelerator": "nvidia"},
+		MinCount: 1,
+		MaxCount: 3,
+	}))
+	c.Assert(NodeLabels(pool), compare.DeepEquals, map[string]string{
+		"accelerator":                    "nvidia",
+		defaults.KubernetesNodePoolLabel: "gpu",
+	})
+
+	data, err := MarshalNodePool(pool)
+	c.Assert(err, check.IsNil)
+	decoded, err := UnmarshalNodePool(data)
+	c.Assert(err, check.IsNil)
+	c.Assert(decoded, compare.DeepEquals, pool)
+}
+
+func (s *NodePoolSuite) TestValidatesResource(c *check.C) {
+	var specs = []struct {
+		spec        string
+		description string
+	}{
+		{
+			spec: `kind: nodepool
+version: v2
+spec:
+  profile: worker
+`,
+			description: "missing name",
+		},
+		{
+			spec: `kind: nodepool
+version: v2
+metadata:
+  name: GPU_Pool
+spec:
+  profile: worker
+`,
+			description: "invalid name",
+		},
+		{
+			spec: `kind: nodepool
+version: v2
+metadata:
+  name: gpu
+spec:
+  profile: worker
+  min_count: 3
+  max_count: 1
+`,
+			description: "min count exceeds max count",
+		},
+		{
+			spec: `kind: nodepool
+version: v2
+metadata:
+  name: gpu
+spec:
+  profile: worker
+  labels:
+    gravitational.io/node-pool: other
+`,
+			description: "reserved label",
+		},
+		{
+			spec: `kind: nodepool
+version: v2
+metadata:
+  name: gpu
+spec:
+  profile: worker
+  labels:
+    "invalid key!": value
+`,
+			description: "invalid label key",
+		},
+	}
+	for _, tt := range specs {
+		_, err := UnmarshalNodePool([]byte(tt.spec))
+		c.Assert(err, check.NotNil, check.Commentf(tt.description))
+		c.Assert(trace.IsBadParameter(err), check.Equals, true, check.Commentf(tt.description))
+	}
+}
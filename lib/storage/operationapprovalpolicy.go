@@ -0,0 +1,179 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/utils"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	teleutils "github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/trace"
+)
+
+// OperationApprovalPolicy defines the operations that require sign-off
+// from a second user before they are executed
+type OperationApprovalPolicy interface {
+	// Resource provides common resource methods
+	teleservices.Resource
+	// CheckAndSetDefaults verifies that the object is valid
+	CheckAndSetDefaults() error
+	// GetOperations returns the types of operations that require approval
+	GetOperations() []string
+	// GetApprovers returns the users allowed to approve operations
+	GetApprovers() []string
+	// Matches returns true if the specified operation type requires approval
+	Matches(operationType string) bool
+	// CanApprove returns true if the specified user is allowed to approve operations
+	CanApprove(user User) bool
+}
+
+// NewOperationApprovalPolicy creates a new operation approval policy resource
+func NewOperationApprovalPolicy(spec OperationApprovalPolicySpecV2) OperationApprovalPolicy {
+	return &OperationApprovalPolicyV2{
+		Kind:    KindOperationApprovalPolicy,
+		Version: teleservices.V2,
+		Metadata: teleservices.Metadata{
+			Name:      KindOperationApprovalPolicy,
+			Namespace: defaults.Namespace,
+		},
+		Spec: spec,
+	}
+}
+
+// OperationApprovalPolicyV2 defines the operation approval policy resource
+type OperationApprovalPolicyV2 struct {
+	// Metadata is resource metadata
+	teleservices.Metadata `json:"metadata"`
+	// Kind is a resource kind
+	Kind string `json:"kind"`
+	// Version is a resource version
+	Version string `json:"version"`
+	// Spec defines the operation approval policy
+	Spec OperationApprovalPolicySpecV2 `json:"spec"`
+}
+
+// GetOperations returns the types of operations that require approval
+func (r *OperationApprovalPolicyV2) GetOperations() []string {
+	return r.Spec.Operations
+}
+
+// GetApprovers returns the users allowed to approve operations
+func (r *OperationApprovalPolicyV2) GetApprovers() []string {
+	return r.Spec.Approvers
+}
+
+// Matches returns true if the specified operation type requires approval
+func (r *OperationApprovalPolicyV2) Matches(operationType string) bool {
+	return utils.StringInSlice(r.Spec.Operations, operationType)
+}
+
+// CanApprove returns true if the specified user is allowed to approve operations.
+// Any user with the permission to update operations can approve them
+// if the policy does not list the approvers.
+//
+// Agent users can never approve operations: all gravity commands run on
+// the cluster nodes share the cluster agent identity so an approval from
+// an agent does not identify a second person
+func (r *OperationApprovalPolicyV2) CanApprove(user User) bool {
+	if user.GetType() == AgentUser {
+		return false
+	}
+	return len(r.Spec.Approvers) == 0 || utils.StringInSlice(r.Spec.Approvers, user.GetName())
+}
+
+// CheckAndSetDefaults checks validity of all parameters and sets defaults
+func (r *OperationApprovalPolicyV2) CheckAndSetDefaults() error {
+	if r.Metadata.Name == "" {
+		r.Metadata.Name = KindOperationApprovalPolicy
+	}
+	if err := r.Metadata.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	if len(r.Spec.Operations) == 0 {
+		return trace.BadParameter("operation approval policy should list at least one operation")
+	}
+	return nil
+}
+
+// UnmarshalOperationApprovalPolicy unmarshals operation approval policy from JSON
+func UnmarshalOperationApprovalPolicy(data []byte) (OperationApprovalPolicy, error) {
+	if len(data) == 0 {
+		return nil, trace.BadParameter("empty operation approval policy")
+	}
+
+	jsonData, err := teleutils.ToJSON(data)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var hdr teleservices.ResourceHeader
+	err = json.Unmarshal(jsonData, &hdr)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	switch hdr.Version {
+	case teleservices.V2:
+		var policy OperationApprovalPolicyV2
+		err := teleutils.UnmarshalWithSchema(GetOperationApprovalPolicySchema(), &policy, jsonData)
+		if err != nil {
+			return nil, trace.BadParameter("%v", err)
+		}
+		if err := policy.CheckAndSetDefaults(); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &policy, nil
+	}
+	return nil, trace.BadParameter(
+		"%v resource version %q is not supported", KindOperationApprovalPolicy, hdr.Version)
+}
+
+// MarshalOperationApprovalPolicy marshals operation approval policy into JSON
+func MarshalOperationApprovalPolicy(policy OperationApprovalPolicy, opts ...teleservices.MarshalOption) ([]byte, error) {
+	return json.Marshal(policy)
+}
+
+// OperationApprovalPolicySpecV2 defines the operation approval policy
+type OperationApprovalPolicySpecV2 struct {
+	// Operations lists the types of operations that require approval,
+	// e.g. operation_update or operation_shrink
+	Operations []string `json:"operations"`
+	// Approvers optionally lists the users allowed to approve operations.
+	// The user who created an operation can never approve it
+	Approvers []string `json:"approvers,omitempty"`
+}
+
+// OperationApprovalPolicySpecV2Schema is JSON schema for the operation approval policy
+const OperationApprovalPolicySpecV2Schema = `{
+  "type": "object",
+  "additionalProperties": false,
+  "required": ["operations"],
+  "properties": {
+    "operations": {"type": "array", "items": {"type": "string"}},
+    "approvers": {"type": "array", "items": {"type": "string"}}
+  }
+}`
+
+// GetOperationApprovalPolicySchema returns operation approval policy schema for version V2
+func GetOperationApprovalPolicySchema() string {
+	return fmt.Sprintf(teleservices.V2SchemaTemplate, MetadataSchema,
+		OperationApprovalPolicySpecV2Schema, "")
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"github.com/gravitational/gravity/lib/compare"
+
+	"github.com/gravitational/trace"
+	check "gopkg.in/check.v1"
+)
+
+type OperationApprovalPolicySuite struct{}
+
+var _ = check.Suite(&OperationApprovalPolicySuite{})
+
+func (s *OperationApprovalPolicySuite) TestResourceParsing(c *check.C) {
+	spec := `kind: operationapprovalpolicy
+version: v2
+spec:
+  operations: ["operation_update", "operation_shrink"]
+  approvers: ["alice@example.com"]
+`
+	policy, err := UnmarshalOperationApprovalPolicy([]byte(spec))
+	c.Assert(err, check.IsNil)
+	c.Assert(policy, compare.DeepEquals, NewOperationApprovalPolicy(OperationApprovalPolicySpecV2{
+		Operations: []string{"operation_update", "operation_shrink"},
+		Approvers:  []string{"alice@example.com"},
+	}))
+	c.Assert(policy.Matches("operation_shrink"), check.Equals, true)
+	c.Assert(policy.Matches("operation_expand"), check.Equals, false)
+	c.Assert(policy.CanApprove(newTestUser("alice@example.com", RegularUser)), check.Equals, true)
+	c.Assert(policy.CanApprove(newTestUser("bob@example.com", RegularUser)), check.Equals, false)
+
+	data, err := MarshalOperationApprovalPolicy(policy)
+	c.Assert(err, check.IsNil)
+	decoded, err := UnmarshalOperationApprovalPolicy(data)
+	c.Assert(err, check.IsNil)
+	c.Assert(decoded, compare.DeepEquals, policy)
+}
+
+func (s *OperationApprovalPolicySuite) TestAnyUserCanApproveByDefault(c *check.C) {
+	policy := NewOperationApprovalPolicy(OperationApprovalPolicySpecV2{
+		Operations: []string{"operation_update"},
+	})
+	c.Assert(policy.CheckAndSetDefaults(), check.IsNil)
+	c.Assert(policy.CanApprove(newTestUser("bob@example.com", RegularUser)), check.Equals, true)
+}
+
+func (s *OperationApprovalPolicySuite) TestAgentsCanNotApprove(c *check.C) {
+	policy := NewOperationApprovalPolicy(OperationApprovalPolicySpecV2{
+		Operations: []string{"operation_update"},
+	})
+	c.Assert(policy.CheckAndSetDefaults(), check.IsNil)
+	c.Assert(policy.CanApprove(newTestUser(ClusterAdminAgent("example.com"), AgentUser)), check.Equals, false)
+
+	policy = NewOperationApprovalPolicy(OperationApprovalPolicySpecV2{
+		Operations: []string{"operation_update"},
+		Approvers:  []string{ClusterAgent("example.com")},
+	})
+	c.Assert(policy.CheckAndSetDefaults(), check.IsNil)
+	c.Assert(policy.CanApprove(newTestUser(ClusterAgent("example.com"), AgentUser)), check.Equals, false,
+		check.Commentf("agents can not approve even if listed as approvers"))
+}
+
+func newTestUser(name, userType string) User {
+	return NewUser(name, UserSpecV2{Type: userType})
+}
+
+func (s *OperationApprovalPolicySuite) TestValidatesResource(c *check.C) {
+	_, err := UnmarshalOperationApprovalPolicy([]byte(`kind: operationapprovalpolicy
+version: v2
+spec:
+  operations: []
+`))
+	c.Assert(trace.IsBadParameter(err), check.Equals, true, check.Commentf("%v", err))
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/utils"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	teleutils "github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/trace"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// OperationWebhook describes a validating webhook that is called before
+// a cluster operation is created and can reject it
+type OperationWebhook interface {
+	// Resource provides common resource methods
+	teleservices.Resource
+	// CheckAndSetDefaults verifies that the object is valid
+	CheckAndSetDefaults() error
+	// GetURL returns the URL the operations are posted to for review
+	GetURL() string
+	// GetOperations returns the types of operations reviewed by this webhook.
+	// Empty list means all operations
+	GetOperations() []string
+	// GetCACert returns the PEM-encoded certificate authority used to verify
+	// the webhook server certificate
+	GetCACert() string
+	// GetTimeout returns the timeout of the review request
+	GetTimeout() time.Duration
+	// GetFailurePolicy returns how to handle the failed review requests
+	GetFailurePolicy() string
+	// Matches returns true if the specified operation type is reviewed
+	// by this webhook
+	Matches(operationType string) bool
+}
+
+// NewOperationWebhook creates a new operation webhook resource
+func NewOperationWebhook(name string, spec OperationWebhookSpecV2) OperationWebhook {
+	return &OperationWebhookV2{
+		Kind:    KindOperationWebhook,
+		Version: teleservices.V2,
+		Metadata: teleservices.Metadata{
+			Name:      name,
+			Namespace: defaults.Namespace,
+		},
+		Spec: spec,
+	}
+}
+
+// OperationWebhookV2 defines the operation webhook resource
+type OperationWebhookV2 struct {
+	// Metadata is resource metadata
+	teleservices.Metadata `json:"metadata"`
+	// Kind is a resource kind
+	Kind string `json:"kind"`
+	// Version is a resource version
+	Version string `json:"version"`
+	// Spec defines the operation webhook
+	Spec OperationWebhookSpecV2 `json:"spec"`
+}
+
+// GetURL returns the URL the operations are posted to for review
+func (r *OperationWebhookV2) GetURL() string {
+	return r.Spec.URL
+}
+
+// GetOperations returns the types of operations reviewed by this webhook
+func (r *OperationWebhookV2) GetOperations() []string {
+	return r.Spec.Operations
+}
+
+// GetCACert returns the PEM-encoded certificate authority used to verify
+// the webhook server certificate
+func (r *OperationWebhookV2) GetCACert() string {
+	return r.Spec.CACert
+}
+
+// GetTimeout returns the timeout of the review request
+func (r *OperationWebhookV2) GetTimeout() time.Duration {
+	if r.Spec.Timeout == nil || r.Spec.Timeout.Duration == 0 {
+		return defaults.OperationWebhookTimeout
+	}
+	return r.Spec.Timeout.Duration
+}
+
+// GetFailurePolicy returns how to handle the failed review requests
+func (r *OperationWebhookV2) GetFailurePolicy() string {
+	if r.Spec.FailurePolicy == "" {
+		return WebhookFailurePolicyFail
+	}
+	return r.Spec.FailurePolicy
+}
+
+// Matches returns true if the specified operation type is reviewed
+// by this webhook
+func (r *OperationWebhookV2) Matches(operationType string) bool {
+	return len(r.Spec.Operations) == 0 || utils.StringInSlice(r.Spec.Operations, operationType)
+}
+
+// CheckAndSetDefaults checks validity of all parameters and sets defaults
+func (r *OperationWebhookV2) CheckAndSetDefaults() error {
+	if r.Metadata.Name == "" {
+		return trace.BadParameter("operation webhook name is required")
+	}
+	if errs := validation.IsDNS1123Label(r.Metadata.Name); len(errs) != 0 {
+		return trace.BadParameter("invalid operation webhook name %q: %v",
+			r.Metadata.Name, strings.Join(errs, "; "))
+	}
+	if err := r.Metadata.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	if r.Spec.URL == "" {
+		return trace.BadParameter("operation webhook %v: url is required", r.Metadata.Name)
+	}
+	u, err := url.Parse(r.Spec.URL)
+	if err != nil {
+		return trace.BadParameter("operation webhook %v: invalid url %q: %v",
+			r.Metadata.Name, r.Spec.URL, err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return trace.BadParameter("operation webhook %v: url %q must be an absolute http(s) URL",
+			r.Metadata.Name, r.Spec.URL)
+	}
+	if r.Spec.Timeout != nil {
+		if r.Spec.Timeout.Duration < 0 || r.Spec.Timeout.Duration > defaults.OperationWebhookMaxTimeout {
+			return trace.BadParameter("operation webhook %v: timeout must be between 0 and %v",
+				r.Metadata.Name, defaults.OperationWebhookMaxTimeout)
+		}
+	}
+	switch r.Spec.FailurePolicy {
+	case "", WebhookFailurePolicyFail, WebhookFailurePolicyIgnore:
+	default:
+		return trace.BadParameter("operation webhook %v: unsupported failure policy %q, expected one of %v",
+			r.Metadata.Name, r.Spec.FailurePolicy,
+			[]string{WebhookFailurePolicyFail, WebhookFailurePolicyIgnore})
+	}
+	return nil
+}
+
+// UnmarshalOperationWebhook unmarshals operation webhook from JSON
+func UnmarshalOperationWebhook(data []byte) (OperationWebhook, error) {
+	if len(data) == 0 {
+		return nil, trace.BadParameter("empty operation webhook")
+	}
+
+	jsonData, err := teleutils.ToJSON(data)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var hdr teleservices.ResourceHeader
+	err = json.Unmarshal(jsonData, &hdr)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	switch hdr.Version {
+	case teleservices.V2:
+		var webhook OperationWebhookV2
+		err := teleutils.UnmarshalWithSchema(GetOperationWebhookSchema(), &webhook, jsonData)
+		if err != nil {
+			return nil, trace.BadParameter("%v", err)
+		}
+		if err := webhook.CheckAndSetDefaults(); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &webhook, nil
+	}
+	return nil, trace.BadParameter(
+		"%v resource version %q is not supported", KindOperationWebhook, hdr.Version)
+}
+
+// MarshalOperationWebhook marshals operation webhook into JSON
+func MarshalOperationWebhook(webhook OperationWebhook, opts ...teleservices.MarshalOption) ([]byte, error) {
+	return json.Marshal(webhook)
+}
+
+// OperationWebhookSpecV2 defines the operation webhook
+type OperationWebhookSpecV2 struct {
+	// URL is the URL the operations are posted to for review
+	URL string `json:"url"`
+	// Operations lists the types of operations reviewed by this webhook.
+	// Empty list means all operations
+	Operations []string `json:"operations,omitempty"`
+	// CACert is the PEM-encoded certificate authority used to verify
+	// the webhook server certificate. System roots are used if unspecified
+	CACert string `json:"ca_cert,omitempty"`
+	// Timeout is the timeout of the review request
+	Timeout *teleservices.Duration `json:"timeout,omitempty"`
+	// FailurePolicy defines how to handle the review requests that failed
+	// or timed out: Fail rejects the operation, Ignore allows it
+	FailurePolicy string `json:"failure_policy,omitempty"`
+}
+
+// OperationWebhookSpecV2Schema is JSON schema for the operation webhook
+const OperationWebhookSpecV2Schema = `{
+  "type": "object",
+  "additionalProperties": false,
+  "required": ["url"],
+  "properties": {
+    "url": {"type": "string"},
+    "operations": {"type": "array", "items": {"type": "string"}},
+    "ca_cert": {"type": "string"},
+    "timeout": {"type": "string"},
+    "failure_policy": {"type": "string"}
+  }
+}`
+
+// GetOperationWebhookSchema returns operation webhook schema for version V2
+func GetOperationWebhookSchema() string {
+	return fmt.Sprintf(teleservices.V2SchemaTemplate, MetadataSchema,
+		OperationWebhookSpecV2Schema, "")
+}
+
+const (
+	// WebhookFailurePolicyFail rejects the operation if the webhook
+	// cannot be reached
+	WebhookFailurePolicyFail = "Fail"
+	// WebhookFailurePolicyIgnore allows the operation if the webhook
+	// cannot be reached
+	WebhookFailurePolicyIgnore = "Ignore"
+)
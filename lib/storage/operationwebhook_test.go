@@ -0,0 +1,126 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"time"
+
+	"github.com/gravitational/gravity/lib/compare"
+	"github.com/gravitational/gravity/lib/defaults"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/trace"
+	check "gopkg.in/check.v1"
+)
+
+type OperationWebhookSuite struct{}
+
+var _ = check.Suite(&OperationWebhookSuite{})
+
+func (s *OperationWebhookSuite) TestResourceParsing(c *check.C) {
+	spec := `kind: operationwebhook
+version: v2
+metadata:
+  name: business-hours
+spec:
+  url: https://hooks.example.com/review
+  operations: ["operation_update"]
+  timeout: 5s
+  failure_policy: Ignore
+`
+	webhook, err := UnmarshalOperationWebhook([]byte(spec))
+	c.Assert(err, check.IsNil)
+	timeout := teleservices.NewDuration(5 * time.Second)
+	c.Assert(webhook, compare.DeepEquals, NewOperationWebhook("business-hours", OperationWebhookSpecV2{
+		URL:           "https://hooks.example.com/review",
+		Operations:    []string{"operation_update"},
+		Timeout:       &timeout,
+		FailurePolicy: WebhookFailurePolicyIgnore,
+	}))
+	c.Assert(webhook.Matches("operation_update"), check.Equals, true)
+	c.Assert(webhook.Matches("operation_expand"), check.Equals, false)
+
+	data, err := MarshalOperationWebhook(webhook)
+	c.Assert(err, check.IsNil)
+	decoded, err := UnmarshalOperationWebhook(data)
+	c.Assert(err, check.IsNil)
+	c.Assert(decoded, compare.DeepEquals, webhook)
+}
+
+func (s *OperationWebhookSuite) TestDefaults(c *check.C) {
+	webhook := NewOperationWebhook("all", OperationWebhookSpecV2{
+		URL: "https://hooks.example.com/review",
+	})
+	c.Assert(webhook.CheckAndSetDefaults(), check.IsNil)
+	c.Assert(webhook.GetTimeout(), check.Equals, defaults.OperationWebhookTimeout)
+	c.Assert(webhook.GetFailurePolicy(), check.Equals, WebhookFailurePolicyFail)
+	c.Assert(webhook.Matches("operation_expand"), check.Equals, true)
+}
+
+func (s *OperationWebhookSuite) TestValidatesResource(c *check.C) {
+	var specs = []struct {
+		spec        string
+		description string
+	}{
+		{
+			spec: `kind: operationwebhook
+version: v2
+metadata:
+  name: hook
+spec: {}
+`,
+			description: "missing url",
+		},
+		{
+			spec: `kind: operationwebhook
+version: v2
+metadata:
+  name: hook
+spec:
+  url: /review
+`,
+			description: "relative url",
+		},
+		{
+			spec: `kind: operationwebhook
+version: v2
+metadata:
+  name: hook
+spec:
+  url: https://hooks.example.com/review
+  timeout: 5m
+`,
+			description: "timeout too long",
+		},
+		{
+			spec: `kind: operationwebhook
+version: v2
+metadata:
+  name: hook
+spec:
+  url: https://hooks.example.com/review
+  failure_policy: Retry
+`,
+			description: "unsupported failure policy",
+		},
+	}
+	for _, tt := range specs {
+		_, err := UnmarshalOperationWebhook([]byte(tt.spec))
+		c.Assert(err, check.NotNil, check.Commentf(tt.description))
+		c.Assert(trace.IsBadParameter(err), check.Equals, true, check.Commentf(tt.description))
+	}
+}
@@ -125,6 +125,8 @@ type OperationPhaseData struct {
 	Update *UpdateOperationData `json:"update,omitempty" yaml:"garbage_collect,omitempty"`
 	// Install specifies configuration specific to install operation
 	Install *InstallOperationData `json:"install,omitempty" yaml:"install,omitempty"`
+	// Drain optionally specifies how the server is drained
+	Drain *DrainPolicy `json:"drain,omitempty" yaml:"drain,omitempty"`
 }
 
 // ElectionChange describes changes to make to cluster elections
@@ -216,6 +218,24 @@ type InstallOperationData struct {
 	Resources []byte `json:"resources,omitempty"`
 	// GravityResources specifies optional Gravity resources to create upon successful installation
 	GravityResources []UnknownResource `json:"gravity_resources,omitempty"`
+	// CertAuthority specifies the optional certificate authority
+	// to issue the cluster certificates
+	CertAuthority *CertAuthorityFiles `json:"cert_authority,omitempty"`
+	// ImageTrustPolicy specifies the optional image trust policy resource
+	// to verify the application images with before they are exported
+	ImageTrustPolicy []byte `json:"image_trust_policy,omitempty"`
+}
+
+// CertAuthorityFiles references the certificate and the private key
+// of a certificate authority on the installer node.
+//
+// Only the paths are recorded so the private key does not become part
+// of the operation plan
+type CertAuthorityFiles struct {
+	// CertPath is the path to the certificate authority certificate
+	CertPath string `json:"cert_path"`
+	// KeyPath is the path to the certificate authority private key
+	KeyPath string `json:"key_path"`
 }
 
 // Application describes an application for the package cleaner
@@ -242,6 +262,21 @@ type PlanChange struct {
 	Created time.Time `json:"created"`
 	// Error is the error that happened during phase execution
 	Error *trace.RawTrace `json:"error"`
+	// Revision is the position of the change in the plan changelog.
+	// Revisions are assigned by the backend and are consecutive for
+	// a single operation
+	Revision int64 `json:"revision,omitempty"`
+}
+
+// isAfter returns true if this change supersedes the other change
+func (r PlanChange) isAfter(other PlanChange) bool {
+	if !r.Created.Equal(other.Created) {
+		return r.Created.After(other.Created)
+	}
+	if r.Revision != other.Revision {
+		return r.Revision > other.Revision
+	}
+	return r.ID > other.ID
 }
 
 // PlanChangelog is a list of plan state changes
@@ -254,13 +289,68 @@ func (c PlanChangelog) Latest(phaseID string) *PlanChange {
 		if change.PhaseID != phaseID {
 			continue
 		}
-		if latest == nil || change.Created.After(latest.Created) {
+		if latest == nil || change.isAfter(*latest) {
 			latest = &(c[i])
 		}
 	}
 	return latest
 }
 
+// Revision returns the latest revision of the changelog
+func (c PlanChangelog) Revision() (revision int64) {
+	for _, change := range c {
+		if change.Revision > revision {
+			revision = change.Revision
+		}
+	}
+	return revision
+}
+
+// PlanChangelogBackend stores operation plan changelogs
+type PlanChangelogBackend interface {
+	// CreateOperationPlanChange creates a new state transition entry for a plan
+	CreateOperationPlanChange(PlanChange) (*PlanChange, error)
+	// GetOperationPlanChangelog returns all state transition entries for a plan
+	GetOperationPlanChangelog(clusterName, operationID string) (PlanChangelog, error)
+}
+
+// AppendOperationPlanChange records the specified change as the latest
+// change of the plan.
+//
+// The change is created with the revision that follows the latest revision
+// of the changelog so concurrent writers cannot interleave their updates:
+// if another change has been recorded in the meantime, the changelog is
+// re-read and the change is re-applied on top of it
+func AppendOperationPlanChange(backend PlanChangelogBackend, change PlanChange) (*PlanChange, error) {
+	var err error
+	for i := 0; i < maxPlanChangeAttempts; i++ {
+		var changelog PlanChangelog
+		changelog, err = backend.GetOperationPlanChangelog(change.ClusterName, change.OperationID)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		change.Revision = changelog.Revision() + 1
+		// Make sure the change supersedes the changes of the phase already
+		// recorded by other writers even if their clocks are ahead
+		if latest := changelog.Latest(change.PhaseID); latest != nil && !change.Created.After(latest.Created) {
+			change.Created = latest.Created.Add(time.Millisecond)
+		}
+		var out *PlanChange
+		out, err = backend.CreateOperationPlanChange(change)
+		if err == nil {
+			return out, nil
+		}
+		if !trace.IsCompareFailed(err) {
+			return nil, trace.Wrap(err)
+		}
+	}
+	return nil, trace.Wrap(err)
+}
+
+// maxPlanChangeAttempts limits the number of attempts to record a plan
+// change while the changelog is being concurrently updated
+const maxPlanChangeAttempts = 10
+
 // HasSubphases returns true if the phase has 1 or more subphases
 func (p OperationPhase) HasSubphases() bool {
 	return len(p.Phases) > 0
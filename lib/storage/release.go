@@ -57,6 +57,9 @@ type Release interface {
 	GetUpdated() time.Time
 	// GetLocator returns locator of the corresponding application package.
 	GetLocator() loc.Locator
+	// SetLocator sets the locator of the application image the release
+	// has been deployed from.
+	SetLocator(loc.Locator)
 }
 
 // NewRelease creates a new release resource from the provided Helm release.
@@ -105,6 +108,9 @@ type ReleaseSpecV1 struct {
 	ChartVersion string `json:"chart_version"`
 	// ChartIcon is the chart application icon.
 	ChartIcon string `json:"chart_icon,omitempty"`
+	// Image is the locator of the application image the release has been
+	// deployed from. Empty if the image is named after the chart.
+	Image string `json:"image,omitempty"`
 	// AppVersion is the application version (may be empty).
 	AppVersion string `json:"app_version"`
 	// Namespace is the namespace where release is deployed.
@@ -177,6 +183,11 @@ func (r *ReleaseV1) GetUpdated() time.Time {
 
 // GetLocator returns locator of the corresponding application package.
 func (r *ReleaseV1) GetLocator() loc.Locator {
+	if r.Spec.Image != "" {
+		if locator, err := loc.ParseLocator(r.Spec.Image); err == nil {
+			return *locator
+		}
+	}
 	return loc.Locator{
 		Repository: defaults.SystemAccountOrg,
 		Name:       r.Spec.ChartName,
@@ -184,6 +195,12 @@ func (r *ReleaseV1) GetLocator() loc.Locator {
 	}
 }
 
+// SetLocator sets the locator of the application image the release
+// has been deployed from.
+func (r *ReleaseV1) SetLocator(locator loc.Locator) {
+	r.Spec.Image = locator.String()
+}
+
 // GetName returns the resource name.
 func (r *ReleaseV1) GetName() string {
 	return r.Metadata.Name
@@ -257,6 +274,7 @@ var ReleaseV1Schema = fmt.Sprintf(`{
     "chart_name": {"type": "string"},
     "chart_version": {"type": "string"},
     "chart_icon": {"type": "string"},
+    "image": {"type": "string"},
     "app_version": {"type": "string"},
     "namespace": {"type": "string"}
   }
@@ -0,0 +1,253 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	teleutils "github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/trace"
+)
+
+// Replication configures the standby cluster to replicate the backups
+// the primary cluster uploads to its off-site destination, so the standby
+// cluster can take over with gravity cluster promote
+type Replication interface {
+	// Resource provides common resource methods
+	teleservices.Resource
+	// CheckAndSetDefaults verifies that the object is valid
+	CheckAndSetDefaults() error
+	// GetSource returns the off-site destination of the primary cluster backups
+	GetSource() BackupDestination
+	// GetInterval returns how often the latest backup is replicated
+	GetInterval() time.Duration
+	// GetDirectory returns the directory the replicated backups are written to
+	GetDirectory() string
+	// GetRetain returns the number of the most recent replicated backups to keep
+	GetRetain() int
+}
+
+// NewReplication creates a new replication resource
+func NewReplication(spec ReplicationSpecV2) Replication {
+	return &ReplicationV2{
+		Kind:    KindReplication,
+		Version: teleservices.V2,
+		Metadata: teleservices.Metadata{
+			Name:      KindReplication,
+			Namespace: defaults.Namespace,
+		},
+		Spec: spec,
+	}
+}
+
+// ReplicationV2 defines the replication resource
+type ReplicationV2 struct {
+	// Metadata is resource metadata
+	teleservices.Metadata `json:"metadata"`
+	// Kind is a resource kind
+	Kind string `json:"kind"`
+	// Version is a resource version
+	Version string `json:"version"`
+	// Spec defines the replication
+	Spec ReplicationSpecV2 `json:"spec"`
+}
+
+// GetSource returns the off-site destination of the primary cluster backups
+func (r *ReplicationV2) GetSource() BackupDestination {
+	return r.Spec.Source
+}
+
+// GetInterval returns how often the latest backup is replicated
+func (r *ReplicationV2) GetInterval() time.Duration {
+	if r.Spec.Interval == nil {
+		return defaults.ReplicationInterval
+	}
+	return r.Spec.Interval.Duration
+}
+
+// GetDirectory returns the directory the replicated backups are written to
+func (r *ReplicationV2) GetDirectory() string {
+	if r.Spec.Directory == "" {
+		return defaults.ReplicationDir
+	}
+	return r.Spec.Directory
+}
+
+// GetRetain returns the number of the most recent replicated backups to keep
+func (r *ReplicationV2) GetRetain() int {
+	if r.Spec.Retain == 0 {
+		return defaults.ReplicationRetain
+	}
+	return r.Spec.Retain
+}
+
+// CheckAndSetDefaults checks validity of all parameters and sets defaults
+func (r *ReplicationV2) CheckAndSetDefaults() error {
+	if r.Metadata.Name == "" {
+		r.Metadata.Name = KindReplication
+	}
+	if err := r.Metadata.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := r.Spec.Source.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	if r.Spec.Interval != nil && r.Spec.Interval.Duration < defaults.ReplicationMinInterval {
+		return trace.BadParameter("replication interval can not be less than %v",
+			defaults.ReplicationMinInterval)
+	}
+	if r.Spec.Directory != "" && !filepath.IsAbs(r.Spec.Directory) {
+		return trace.BadParameter("replication directory %q must be an absolute path", r.Spec.Directory)
+	}
+	if r.Spec.Retain < 0 {
+		return trace.BadParameter("number of retained backups can not be negative")
+	}
+	return nil
+}
+
+// UnmarshalReplication unmarshals replication from JSON
+func UnmarshalReplication(data []byte) (Replication, error) {
+	if len(data) == 0 {
+		return nil, trace.BadParameter("empty replication")
+	}
+
+	jsonData, err := teleutils.ToJSON(data)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var hdr teleservices.ResourceHeader
+	err = json.Unmarshal(jsonData, &hdr)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	switch hdr.Version {
+	case teleservices.V2:
+		var replication ReplicationV2
+		err := teleutils.UnmarshalWithSchema(GetReplicationSchema(), &replication, jsonData)
+		if err != nil {
+			return nil, trace.BadParameter("%v", err)
+		}
+		if err := replication.CheckAndSetDefaults(); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &replication, nil
+	}
+	return nil, trace.BadParameter(
+		"%v resource version %q is not supported", KindReplication, hdr.Version)
+}
+
+// MarshalReplication marshals replication into JSON
+func MarshalReplication(replication Replication, opts ...teleservices.MarshalOption) ([]byte, error) {
+	return json.Marshal(replication)
+}
+
+// ReplicationSpecV2 defines the replication
+type ReplicationSpecV2 struct {
+	// Source is the off-site destination the primary cluster uploads
+	// its scheduled backups to
+	Source BackupDestination `json:"source"`
+	// Interval is how often the latest backup is replicated
+	Interval *teleservices.Duration `json:"interval,omitempty"`
+	// Directory is the directory on the master nodes the replicated
+	// backups are written to
+	Directory string `json:"directory,omitempty"`
+	// Retain is the number of the most recent replicated backups to keep
+	Retain int `json:"retain,omitempty"`
+}
+
+// ReplicationSpecV2Schema is JSON schema for the replication
+const ReplicationSpecV2Schema = `{
+  "type": "object",
+  "additionalProperties": false,
+  "required": ["source"],
+  "properties": {
+    "source": ` + BackupDestinationSchema + `,
+    "interval": {"type": "string"},
+    "directory": {"type": "string"},
+    "retain": {"type": "integer"}
+  }
+}`
+
+// GetReplicationSchema returns replication schema for version V2
+func GetReplicationSchema() string {
+	return fmt.Sprintf(teleservices.V2SchemaTemplate, MetadataSchema,
+		ReplicationSpecV2Schema, "")
+}
+
+// ReplicationStatus describes the outcome of the replication
+// of the primary cluster backups
+type ReplicationStatus struct {
+	// LastAttempt is the time of the last replication attempt
+	LastAttempt time.Time `json:"last_attempt"`
+	// LastReplicated is the time the last backup has been replicated
+	LastReplicated time.Time `json:"last_replicated,omitempty"`
+	// Backup is the name of the last replicated backup
+	Backup string `json:"backup,omitempty"`
+	// Taken is the time the last replicated backup
+	// has been taken on the primary cluster
+	Taken time.Time `json:"taken,omitempty"`
+	// Path is the path to the last replicated backup
+	Path string `json:"path,omitempty"`
+	// Node is the name of the node the last replicated backup is stored on
+	Node string `json:"node,omitempty"`
+	// MissingImages lists the images of the last replicated backup
+	// that are not in the registry of the standby cluster
+	MissingImages []string `json:"missing_images,omitempty"`
+	// Message describes the error encountered during the last attempt
+	Message string `json:"message,omitempty"`
+}
+
+// IsHealthy returns true if the last replication attempt succeeded
+// and the registry of the standby cluster has all images of the primary
+func (r ReplicationStatus) IsHealthy() bool {
+	return r.Message == "" && len(r.MissingImages) == 0
+}
+
+// Lag returns how far the standby cluster is behind the primary
+// at the specified time or zero if no backup has been replicated yet
+func (r ReplicationStatus) Lag(now time.Time) time.Duration {
+	if r.Taken.IsZero() {
+		return 0
+	}
+	return now.Sub(r.Taken)
+}
+
+// Replications defines the interface to manage the replication
+// of the primary cluster backups to the standby cluster
+type Replications interface {
+	// UpsertReplication creates or updates the replication
+	// of the specified cluster
+	UpsertReplication(clusterName string, replication Replication) error
+	// GetReplication returns the replication of the specified cluster
+	GetReplication(clusterName string) (Replication, error)
+	// DeleteReplication deletes the replication of the specified cluster
+	DeleteReplication(clusterName string) error
+	// UpsertReplicationStatus updates the status of the replication
+	UpsertReplicationStatus(clusterName string, status ReplicationStatus) error
+	// GetReplicationStatus returns the status of the replication
+	GetReplicationStatus(clusterName string) (*ReplicationStatus, error)
+	// DeleteReplicationStatus deletes the status of the replication
+	DeleteReplicationStatus(clusterName string) error
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"time"
+
+	"github.com/gravitational/gravity/lib/compare"
+	"github.com/gravitational/gravity/lib/defaults"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/trace"
+	check "gopkg.in/check.v1"
+)
+
+type ReplicationSuite struct{}
+
+var _ = check.Suite(&ReplicationSuite{})
+
+func (s *ReplicationSuite) TestResourceParsing(c *check.C) {
+	spec := `kind: replication
+version: v2
+spec:
+  source:
+    type: sftp
+    secret: backups
+    sftp:
+      addr: backups.example.com:22
+      user: backup
+      path: /srv/backups/example.com
+  interval: 5m
+  directory: /replicas
+  retain: 3
+`
+	replication, err := UnmarshalReplication([]byte(spec))
+	c.Assert(err, check.IsNil)
+	c.Assert(replication, compare.DeepEquals, NewReplication(ReplicationSpecV2{
+		Source: BackupDestination{
+			Type:   BackupDestinationSFTPType,
+			Secret: "backups",
+			SFTP: &BackupDestinationSFTP{
+				Addr: "backups.example.com:22",
+				User: "backup",
+				Path: "/srv/backups/example.com",
+			},
+		},
+		Interval:  &teleservices.Duration{Duration: 5 * time.Minute},
+		Directory: "/replicas",
+		Retain:    3,
+	}))
+	c.Assert(replication.GetInterval(), check.Equals, 5*time.Minute)
+
+	data, err := MarshalReplication(replication)
+	c.Assert(err, check.IsNil)
+	decoded, err := UnmarshalReplication(data)
+	c.Assert(err, check.IsNil)
+	c.Assert(decoded, compare.DeepEquals, replication)
+}
+
+func (s *ReplicationSuite) TestDefaults(c *check.C) {
+	replication := NewReplication(ReplicationSpecV2{
+		Source: BackupDestination{
+			Type:   BackupDestinationNFSType,
+			Secret: "backups",
+			NFS:    &BackupDestinationNFS{Path: "/mnt/backups"},
+		},
+	})
+	c.Assert(replication.CheckAndSetDefaults(), check.IsNil)
+	c.Assert(replication.GetInterval(), check.Equals, defaults.ReplicationInterval)
+	c.Assert(replication.GetDirectory(), check.Equals, defaults.ReplicationDir)
+	c.Assert(replication.GetRetain(), check.Equals, defaults.ReplicationRetain)
+}
+
+func (s *ReplicationSuite) TestValidatesResource(c *check.C) {
+	for _, spec := range []string{
+		`{interval: 5m}`,
+		`{source: {type: nfs, nfs: {path: /mnt/backups}}}`,
+		`{source: {type: nfs, secret: backups, nfs: {path: backups}}}`,
+		`{source: {type: nfs, secret: backups, nfs: {path: /mnt/backups}}, interval: 10s}`,
+		`{source: {type: nfs, secret: backups, nfs: {path: /mnt/backups}}, directory: replicas}`,
+		`{source: {type: nfs, secret: backups, nfs: {path: /mnt/backups}}, retain: -1}`,
+	} {
+		_, err := UnmarshalReplication([]byte(`kind: replication
+version: v2
+spec: ` + spec))
+		c.Assert(trace.IsBadParameter(err), check.Equals, true, check.Commentf("%v: %v", spec, err))
+	}
+}
+
+func (s *ReplicationSuite) TestStatus(c *check.C) {
+	now := time.Date(2019, time.June, 1, 3, 0, 0, 0, time.UTC)
+	var status ReplicationStatus
+	c.Assert(status.IsHealthy(), check.Equals, true)
+	c.Assert(status.Lag(now), check.Equals, time.Duration(0))
+
+	status.Taken = time.Date(2019, time.June, 1, 2, 0, 0, 0, time.UTC)
+	c.Assert(status.Lag(now), check.Equals, time.Hour)
+	status.MissingImages = []string{"nginx:1.17"}
+	c.Assert(status.IsHealthy(), check.Equals, false)
+}
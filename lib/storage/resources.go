@@ -73,6 +73,37 @@ const (
 	KindRelease = "release"
 	// KindInvite defines the user invite token.
 	KindInvite = "invite"
+	// KindClusterDNS defines the cluster DNS configuration resource type
+	KindClusterDNS = "clusterdns"
+	// KindNodePool defines the node pool resource type
+	KindNodePool = "nodepool"
+	// KindClusterRoster defines the cluster roster resource type
+	KindClusterRoster = "clusterroster"
+	// KindOperation defines the resource that controls access to cluster operations,
+	// their progress and logs
+	KindOperation = "operation"
+	// KindOperationPlan defines the resource that controls access to operation plans
+	KindOperationPlan = "operationplan"
+	// KindUpgrade defines the resource that controls access to cluster upgrades
+	KindUpgrade = "upgrade"
+	// KindServiceAccount defines the non-interactive service account resource type
+	KindServiceAccount = "serviceaccount"
+	// KindOperationWebhook defines the operation admission webhook resource type
+	KindOperationWebhook = "operationwebhook"
+	// KindHealthCheck defines the custom cluster health check resource type
+	KindHealthCheck = "healthcheck"
+	// KindImageTrustPolicy defines the container image trust policy resource type
+	KindImageTrustPolicy = "imagetrustpolicy"
+	// KindOperationApprovalPolicy defines the operation approval policy resource type
+	KindOperationApprovalPolicy = "operationapprovalpolicy"
+	// KindCatalogSyncSchedule defines the catalog sync schedule resource type
+	KindCatalogSyncSchedule = "catalogsync"
+	// KindBackupSchedule defines the backup schedule resource type
+	KindBackupSchedule = "backupschedule"
+	// KindReplication defines the standby cluster replication resource type
+	KindReplication = "replication"
+	// KindJoinToken defines the resource that controls access to the cluster join tokens
+	KindJoinToken = "jointoken"
 )
 
 // CanonicalKind translates the specified kind to canonical form.
@@ -105,6 +136,28 @@ func CanonicalKind(kind string) string {
 		return KindClusterConfiguration
 	case KindAuthGateway, "gw":
 		return KindAuthGateway
+	case KindClusterDNS, "dns":
+		return KindClusterDNS
+	case KindNodePool, "nodepools", "pool", "pools":
+		return KindNodePool
+	case KindClusterRoster, "roster":
+		return KindClusterRoster
+	case KindServiceAccount, "serviceaccounts", "sa":
+		return KindServiceAccount
+	case KindOperationWebhook, "operationwebhooks", "webhook", "webhooks":
+		return KindOperationWebhook
+	case KindHealthCheck, "healthchecks", "hc":
+		return KindHealthCheck
+	case KindImageTrustPolicy, "trustpolicy":
+		return KindImageTrustPolicy
+	case KindOperationApprovalPolicy, "approvalpolicy":
+		return KindOperationApprovalPolicy
+	case KindCatalogSyncSchedule, "catalogsyncschedule":
+		return KindCatalogSyncSchedule
+	case KindBackupSchedule, "backupschedules":
+		return KindBackupSchedule
+	case KindReplication, "replications":
+		return KindReplication
 	}
 	return kind
 }
@@ -171,6 +224,17 @@ var SupportedGravityResources = []string{
 	KindAuthGateway,
 	KindRuntimeEnvironment,
 	KindClusterConfiguration,
+	KindClusterDNS,
+	KindNodePool,
+	KindClusterRoster,
+	KindServiceAccount,
+	KindOperationWebhook,
+	KindHealthCheck,
+	KindImageTrustPolicy,
+	KindOperationApprovalPolicy,
+	KindCatalogSyncSchedule,
+	KindBackupSchedule,
+	KindReplication,
 }
 
 // SupportedGravityResourcesToRemove is a list of resources supported by
@@ -186,6 +250,16 @@ var SupportedGravityResourcesToRemove = []string{
 	KindTLSKeyPair,
 	KindRuntimeEnvironment,
 	KindClusterConfiguration,
+	KindNodePool,
+	KindClusterRoster,
+	KindServiceAccount,
+	KindOperationWebhook,
+	KindHealthCheck,
+	KindImageTrustPolicy,
+	KindOperationApprovalPolicy,
+	KindCatalogSyncSchedule,
+	KindBackupSchedule,
+	KindReplication,
 }
 
 // MetadataSchema is a copy of teleport/lib/services.MetadataSchema but with
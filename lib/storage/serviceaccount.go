@@ -0,0 +1,173 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/defaults"
+
+	teleservices "github.com/gravitational/teleport/lib/services"
+	teleutils "github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/trace"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ServiceAccount describes a non-interactive account used by automation
+// (e.g. CI systems). Service accounts cannot log in with a password and
+// authenticate with API keys (tokens) only. The access of a service account
+// is scoped by its roles
+type ServiceAccount interface {
+	// Resource provides common resource methods
+	teleservices.Resource
+	// CheckAndSetDefaults verifies that the object is valid
+	CheckAndSetDefaults() error
+	// GetRoles returns the roles assigned to this service account
+	GetRoles() []string
+}
+
+// NewServiceAccount creates a new service account resource
+func NewServiceAccount(name string, spec ServiceAccountSpecV2) ServiceAccount {
+	return &ServiceAccountV2{
+		Kind:    KindServiceAccount,
+		Version: teleservices.V2,
+		Metadata: teleservices.Metadata{
+			Name:      name,
+			Namespace: defaults.Namespace,
+		},
+		Spec: spec,
+	}
+}
+
+// NewServiceAccountFromUser returns the service account described by the
+// specified user
+func NewServiceAccountFromUser(user User) ServiceAccount {
+	return NewServiceAccount(user.GetName(), ServiceAccountSpecV2{
+		Roles: user.GetRoles(),
+	})
+}
+
+// ServiceAccountToUser returns the user that represents the specified
+// service account in the users backend
+func ServiceAccountToUser(account ServiceAccount, clusterName string) User {
+	return NewUser(account.GetName(), UserSpecV2{
+		Type:        ServiceAccountUser,
+		Roles:       account.GetRoles(),
+		ClusterName: clusterName,
+	})
+}
+
+// ServiceAccountV2 defines the service account resource
+type ServiceAccountV2 struct {
+	// Metadata is resource metadata
+	teleservices.Metadata `json:"metadata"`
+	// Kind is a resource kind
+	Kind string `json:"kind"`
+	// Version is a resource version
+	Version string `json:"version"`
+	// Spec defines the service account
+	Spec ServiceAccountSpecV2 `json:"spec"`
+}
+
+// GetRoles returns the roles assigned to this service account
+func (r *ServiceAccountV2) GetRoles() []string {
+	return r.Spec.Roles
+}
+
+// CheckAndSetDefaults checks validity of all parameters and sets defaults
+func (r *ServiceAccountV2) CheckAndSetDefaults() error {
+	if r.Metadata.Name == "" {
+		return trace.BadParameter("service account name is required")
+	}
+	if errs := validation.IsDNS1123Subdomain(r.Metadata.Name); len(errs) != 0 {
+		return trace.BadParameter("invalid service account name %q: %v",
+			r.Metadata.Name, strings.Join(errs, "; "))
+	}
+	if err := r.Metadata.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	if len(r.Spec.Roles) == 0 {
+		return trace.BadParameter("service account %v: at least one role is required",
+			r.Metadata.Name)
+	}
+	return nil
+}
+
+// UnmarshalServiceAccount unmarshals service account from JSON
+func UnmarshalServiceAccount(data []byte) (ServiceAccount, error) {
+	if len(data) == 0 {
+		return nil, trace.BadParameter("empty service account")
+	}
+
+	jsonData, err := teleutils.ToJSON(data)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var hdr teleservices.ResourceHeader
+	err = json.Unmarshal(jsonData, &hdr)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	switch hdr.Version {
+	case teleservices.V2:
+		var account ServiceAccountV2
+		err := teleutils.UnmarshalWithSchema(GetServiceAccountSchema(), &account, jsonData)
+		if err != nil {
+			return nil, trace.BadParameter("%v", err)
+		}
+		if err := account.CheckAndSetDefaults(); err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &account, nil
+	}
+	return nil, trace.BadParameter(
+		"%v resource version %q is not supported", KindServiceAccount, hdr.Version)
+}
+
+// MarshalServiceAccount marshals service account into JSON
+func MarshalServiceAccount(account ServiceAccount, opts ...teleservices.MarshalOption) ([]byte, error) {
+	return json.Marshal(account)
+}
+
+// ServiceAccountSpecV2 defines the service account
+type ServiceAccountSpecV2 struct {
+	// Roles is a list of roles assigned to the service account
+	Roles []string `json:"roles"`
+}
+
+// ServiceAccountSpecV2Schema is JSON schema for the service account
+const ServiceAccountSpecV2Schema = `{
+  "type": "object",
+  "additionalProperties": false,
+  "required": ["roles"],
+  "properties": {
+    "roles": {
+      "type": "array",
+      "items": {"type": "string"}
+    }
+  }
+}`
+
+// GetServiceAccountSchema returns service account schema for version V2
+func GetServiceAccountSchema() string {
+	return fmt.Sprintf(teleservices.V2SchemaTemplate, MetadataSchema,
+		ServiceAccountSpecV2Schema, "")
+}
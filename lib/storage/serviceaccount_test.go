@@ -0,0 +1,93 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"github.com/gravitational/gravity/lib/compare"
+
+	check "gopkg.in/check.v1"
+)
+
+type ServiceAccountSuite struct{}
+
+var _ = check.Suite(&ServiceAccountSuite{})
+
+func (s *ServiceAccountSuite) TestResourceParsing(c *check.C) {
+	spec := `kind: serviceaccount
+version: v2
+metadata:
+  name: jenkins
+spec:
+  roles: ["ci"]
+`
+	account, err := UnmarshalServiceAccount([]byte(spec))
+	c.Assert(err, check.IsNil)
+	c.Assert(account, compare.DeepEquals, NewServiceAccount("jenkins", ServiceAccountSpecV2{
+		Roles: []string{"ci"},
+	}))
+
+	user := ServiceAccountToUser(account, "example.com")
+	c.Assert(user.GetType(), check.Equals, ServiceAccountUser)
+	c.Assert(user.CheckAndSetDefaults(), check.IsNil)
+	c.Assert(NewServiceAccountFromUser(user), compare.DeepEquals, account)
+
+	data, err := MarshalServiceAccount(account)
+	c.Assert(err, check.IsNil)
+	decoded, err := UnmarshalServiceAccount(data)
+	c.Assert(err, check.IsNil)
+	c.Assert(decoded, compare.DeepEquals, account)
+}
+
+func (s *ServiceAccountSuite) TestValidatesResource(c *check.C) {
+	var specs = []struct {
+		spec        string
+		description string
+	}{
+		{
+			spec: `kind: serviceaccount
+version: v2
+spec:
+  roles: ["ci"]
+`,
+			description: "missing name",
+		},
+		{
+			spec: `kind: serviceaccount
+version: v2
+metadata:
+  name: jenkins
+spec:
+  roles: []
+`,
+			description: "no roles",
+		},
+		{
+			spec: `kind: serviceaccount
+version: v2
+metadata:
+  name: "jenkins ci"
+spec:
+  roles: ["ci"]
+`,
+			description: "invalid name",
+		},
+	}
+	for _, spec := range specs {
+		_, err := UnmarshalServiceAccount([]byte(spec.spec))
+		c.Assert(err, check.NotNil, check.Commentf(spec.description))
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// StateArchiveVersion is the version of the state archive format
+const StateArchiveVersion = 1
+
+// StateItem is a single record of the backend state
+type StateItem struct {
+	// Key is the path of the record relative to the backend root
+	Key []string `json:"key"`
+	// Value is the record value, empty for directories
+	Value []byte `json:"value,omitempty"`
+	// Dir is set for directory records
+	Dir bool `json:"dir,omitempty"`
+}
+
+// StateExporter is implemented by the backends that can export
+// and import their state as raw records
+type StateExporter interface {
+	// ExportState calls fn for every record stored in the backend
+	ExportState(ctx context.Context, fn func(StateItem) error) error
+	// ImportState stores the specified record in the backend
+	ImportState(ctx context.Context, item StateItem) error
+}
+
+// StateArchiveHeader starts the state archive
+type StateArchiveHeader struct {
+	// Version is the archive format version
+	Version int `json:"version"`
+	// Created is the time the archive was created
+	Created time.Time `json:"created"`
+}
+
+// WriteStateArchive writes the state of the specified backend as a gzip-compressed
+// stream of JSON records preceded by the archive header.
+// Returns the number of exported records
+func WriteStateArchive(ctx context.Context, backend Backend, w io.Writer) (count int, err error) {
+	exporter, ok := backend.(StateExporter)
+	if !ok {
+		return 0, trace.BadParameter("backend %T does not support state export", backend)
+	}
+	gzw := gzip.NewWriter(w)
+	enc := json.NewEncoder(gzw)
+	err = enc.Encode(StateArchiveHeader{
+		Version: StateArchiveVersion,
+		Created: time.Now().UTC(),
+	})
+	if err != nil {
+		return 0, trace.Wrap(err)
+	}
+	err = exporter.ExportState(ctx, func(item StateItem) error {
+		count++
+		return trace.Wrap(enc.Encode(item))
+	})
+	if err != nil {
+		return 0, trace.Wrap(err)
+	}
+	return count, trace.Wrap(gzw.Close())
+}
+
+// ReadStateArchive restores the state from the archive created
+// with WriteStateArchive in the specified backend.
+// Returns the number of imported records
+func ReadStateArchive(ctx context.Context, backend Backend, r io.Reader) (count int, err error) {
+	importer, ok := backend.(StateExporter)
+	if !ok {
+		return 0, trace.BadParameter("backend %T does not support state import", backend)
+	}
+	return ScanStateArchive(r, func(item StateItem) error {
+		return trace.Wrap(importer.ImportState(ctx, item))
+	})
+}
+
+// ScanStateArchive calls fn for every record of the archive created
+// with WriteStateArchive.
+// Returns the number of records fn has been called for
+func ScanStateArchive(r io.Reader, fn func(StateItem) error) (count int, err error) {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return 0, trace.Wrap(err, "state archive is not a gzip stream")
+	}
+	defer gzr.Close()
+	dec := json.NewDecoder(gzr)
+	var header StateArchiveHeader
+	if err := dec.Decode(&header); err != nil {
+		return 0, trace.Wrap(err, "failed to read state archive header")
+	}
+	if header.Version != StateArchiveVersion {
+		return 0, trace.BadParameter("unsupported state archive version %v, expected %v",
+			header.Version, StateArchiveVersion)
+	}
+	for {
+		var item StateItem
+		err := dec.Decode(&item)
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, trace.Wrap(err)
+		}
+		if len(item.Key) == 0 {
+			return count, trace.BadParameter("state archive record is missing a key")
+		}
+		if err := fn(item); err != nil {
+			return count, trace.Wrap(err)
+		}
+		count++
+	}
+}
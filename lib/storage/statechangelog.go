@@ -0,0 +1,66 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+const (
+	// StateChangePut is a change that creates or updates a record
+	StateChangePut = "put"
+	// StateChangeDelete is a change that deletes a record
+	StateChangeDelete = "delete"
+)
+
+// StateChange is an entry in the state changelog. It describes
+// a single mutation of the backend along with the previous value of
+// the record which allows to revert the mutation
+type StateChange struct {
+	// ID uniquely identifies the change, the changes are ordered by ID
+	ID string `json:"id"`
+	// Time is the time of the change
+	Time time.Time `json:"time"`
+	// Op is the type of the change
+	Op string `json:"op"`
+	// Key is the key of the changed record
+	Key []string `json:"key"`
+	// Dir is true if the changed record is a directory
+	Dir bool `json:"dir,omitempty"`
+	// PrevExists is true if the record existed before the change
+	PrevExists bool `json:"prev_exists,omitempty"`
+	// PrevValue is the value of the record before the change
+	PrevValue []byte `json:"prev_value,omitempty"`
+}
+
+// Path returns the key of the changed record as a path
+func (r StateChange) Path() string {
+	return strings.Join(r.Key, "/")
+}
+
+// StateChangelog is implemented by backends that record their
+// mutations to an append-only changelog
+type StateChangelog interface {
+	// GetStateChanges returns the changes recorded after the specified
+	// time ordered from the oldest to the newest
+	GetStateChanges(since time.Time) ([]StateChange, error)
+	// RestoreState reverts the changes recorded after the specified time
+	// and returns the number of reverted changes
+	RestoreState(ctx context.Context, to time.Time) (int, error)
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	// StateProblemOperationWithoutPlan is an unfinished operation that
+	// has no operation plan. The key is [cluster name, operation ID]
+	StateProblemOperationWithoutPlan = "operation_without_plan"
+	// StateProblemOrphanedProgress is a progress entry of an operation
+	// that does not exist. The key is the raw backend key of the operation
+	StateProblemOrphanedProgress = "orphaned_progress"
+	// StateProblemDanglingPackage is a reference to a package that does
+	// not exist. The key is [cluster name] or [cluster name, operation ID]
+	StateProblemDanglingPackage = "dangling_package"
+	// StateProblemExpiredToken is a token that has expired but has not been
+	// removed from the backend. The key is the raw backend key of the token
+	StateProblemExpiredToken = "expired_token"
+)
+
+// StateProblem describes an inconsistency found in the backend state
+type StateProblem struct {
+	// Kind is the kind of the problem
+	Kind string `json:"kind"`
+	// Key identifies the inconsistent record, the format depends on the kind
+	Key []string `json:"key"`
+	// Message describes the problem
+	Message string `json:"message"`
+	// Fixable is true if the problem can be repaired automatically
+	Fixable bool `json:"fixable,omitempty"`
+}
+
+// String returns a textual representation of this problem
+func (r StateProblem) String() string {
+	return fmt.Sprintf("%v(%v): %v", r.Kind, strings.Join(r.Key, "/"), r.Message)
+}
+
+// StateChecker is implemented by backends that can find and repair
+// inconsistent records not reachable with the regular storage API
+type StateChecker interface {
+	// CheckState returns the inconsistencies found in the backend state
+	CheckState(ctx context.Context) ([]StateProblem, error)
+	// FixStateProblem repairs the specified problem
+	FixStateProblem(ctx context.Context, problem StateProblem) error
+}
@@ -26,6 +26,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -42,6 +43,7 @@ import (
 	teleservices "github.com/gravitational/teleport/lib/services"
 	teleutils "github.com/gravitational/teleport/lib/utils"
 
+	"github.com/gravitational/configure/cstrings"
 	"github.com/gravitational/trace"
 	"github.com/jonboulle/clockwork"
 	"github.com/tstranex/u2f"
@@ -416,6 +418,9 @@ type SiteOperation struct {
 	Created time.Time `json:"created"`
 	// CreatedBy specifies the user who created the operation
 	CreatedBy string `json:"created_by,omitempty"`
+	// IdempotencyKey is the key of the request that created the operation.
+	// Retried requests with the same key return this operation
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
 	// Updated is a time when this operation was last updated
 	Updated time.Time `json:"updated"`
 	// State represents current operation state
@@ -439,6 +444,36 @@ type SiteOperation struct {
 	UpdateEnviron *UpdateEnvarsOperationState `json:"update_environ,omitempty"`
 	// UpdateConfig defines the state of the cluster configuration update operation
 	UpdateConfig *UpdateConfigOperationState `json:"update_config,omitempty"`
+	// UpdateNodeRole defines the state of the operation to promote or demote a node
+	UpdateNodeRole *UpdateNodeRoleOperationState `json:"update_node_role,omitempty"`
+	// ReplaceNode defines the state of the operation to replace a node
+	ReplaceNode *ReplaceNodeOperationState `json:"replace_node,omitempty"`
+	// RotateCertificates defines the state of the certificate rotation operation
+	RotateCertificates *RotateCertificatesOperationState `json:"rotate_certs,omitempty"`
+	// Restore defines the state of the disaster recovery restore operation
+	Restore *RestoreOperationState `json:"restore,omitempty"`
+	// EtcdRestore defines the state of the etcd snapshot restore operation
+	EtcdRestore *EtcdRestoreOperationState `json:"etcd_restore,omitempty"`
+	// AppBackup defines the state of the application backup or restore operation
+	AppBackup *AppBackupOperationState `json:"app_backup,omitempty"`
+	// ChangeCIDR defines the state of the operation to change the pod and service subnets
+	ChangeCIDR *ChangeCIDROperationState `json:"change_cidr,omitempty"`
+	// Approval is set when the operation requires approval from a second user
+	Approval *OperationApproval `json:"approval,omitempty"`
+}
+
+// OperationApproval defines the approval state of an operation
+// that requires sign-off from a second user before it is executed
+type OperationApproval struct {
+	// ApprovedBy is the user who approved the operation
+	ApprovedBy string `json:"approved_by,omitempty"`
+	// Approved is the time the operation was approved
+	Approved time.Time `json:"approved,omitempty"`
+}
+
+// IsApproved returns true if the operation has been approved
+func (r OperationApproval) IsApproved() bool {
+	return r.ApprovedBy != ""
 }
 
 func (s *SiteOperation) Check() error {
@@ -500,6 +535,8 @@ const (
 	ReasonStatusCheckFailed Reason = "status_check_failed"
 	// ReasonClusterDegraded means one or more of cluster nodes are degraded
 	ReasonClusterDegraded Reason = "cluster_degraded"
+	// ReasonHealthCheckFailed means one or more of custom cluster health checks failed
+	ReasonHealthCheckFailed Reason = "health_check_failed"
 )
 
 // Description returns human-readable description of the reason
@@ -511,6 +548,8 @@ func (r *Reason) Description() string {
 		return "application status check failed"
 	case ReasonClusterDegraded:
 		return "one or more of cluster nodes are not healthy"
+	case ReasonHealthCheckFailed:
+		return "one or more of cluster health checks failed"
 	default:
 		return "unknown reason"
 	}
@@ -518,7 +557,7 @@ func (r *Reason) Description() string {
 
 func (r *Reason) Check() error {
 	switch *r {
-	case "", ReasonLicenseInvalid, ReasonStatusCheckFailed, ReasonClusterDegraded:
+	case "", ReasonLicenseInvalid, ReasonStatusCheckFailed, ReasonClusterDegraded, ReasonHealthCheckFailed:
 		return nil
 	}
 	return trace.BadParameter("unsupported reason: %s", *r)
@@ -583,6 +622,9 @@ type Site struct {
 	DNSConfig DNSConfig `json:"dns_config"`
 	// InstallToken specifies the original token the cluster was installed with
 	InstallToken string `json:"install_token"`
+	// Demo indicates that the cluster has been installed in demo mode
+	// with relaxed requirements and is not suitable for production use
+	Demo bool `json:"demo,omitempty"`
 }
 
 func (s *Site) Check() error {
@@ -1205,6 +1247,33 @@ type ProvisioningToken struct {
 	// UserEmail links this token to the user with permissions,
 	// usually it's a site agent user
 	UserEmail string `json:"user_email"`
+	// Roles optionally limits the node roles that can join
+	// the cluster with this token
+	Roles []string `json:"roles,omitempty"`
+	// MaxUses is the maximum number of expand operations this token
+	// can start, zero if unlimited
+	MaxUses int `json:"max_uses,omitempty"`
+	// Uses is the number of expand operations started with this token
+	Uses int `json:"uses,omitempty"`
+	// ScaleUp is set for join tokens issued on request of an autoscaler.
+	// These tokens are published to the cloud metadata store so new
+	// instances can discover them and join the cluster unattended
+	ScaleUp bool `json:"scale_up,omitempty"`
+}
+
+// CheckRole returns an error if the token does not allow
+// nodes with the specified role to join the cluster
+func (p *ProvisioningToken) CheckRole(role string) error {
+	if len(p.Roles) == 0 || utils.StringInSlice(p.Roles, role) {
+		return nil
+	}
+	return trace.AccessDenied("token does not allow joining as %q, allowed roles: %v",
+		role, strings.Join(p.Roles, ", "))
+}
+
+// IsExhausted returns true if the token has no uses left
+func (p *ProvisioningToken) IsExhausted() bool {
+	return p.MaxUses != 0 && p.Uses >= p.MaxUses
 }
 
 func (p *ProvisioningToken) Check() error {
@@ -1220,6 +1289,9 @@ func (p *ProvisioningToken) Check() error {
 	if p.SiteDomain == "" {
 		return trace.BadParameter("missing SiteDomain")
 	}
+	if p.MaxUses < 0 {
+		return trace.BadParameter("MaxUses can't be negative")
+	}
 	return nil
 }
 
@@ -1258,6 +1330,9 @@ type Tokens interface {
 	// GetSiteProvisioningTokens returns a list of tokens for the site specified with siteDomain
 	// that have not expired yet
 	GetSiteProvisioningTokens(siteDomain string) ([]ProvisioningToken, error)
+	// UseProvisioningToken records a use of the specified token and returns the
+	// updated token. Returns LimitExceeded if the token has no uses left
+	UseProvisioningToken(token string) (*ProvisioningToken, error)
 	// CreateInstallToken creates a token for a one-time install operation
 	CreateInstallToken(InstallToken) (*InstallToken, error)
 	// GetInstallToken returns an active install token with the specified ID
@@ -1484,6 +1559,21 @@ type Backend interface {
 	LegacyRoles
 	SystemMetadata
 	Charts
+	NodePools
+	OperationWebhooks
+	HealthChecks
+	HealthHistory
+	ClusterRosters
+	ClusterEvents
+	EtcdMaintenance
+	EtcdHealth
+	ImageTrustPolicies
+	OperationApprovalPolicies
+	CatalogSyncSchedules
+	DeviceAuthRequests
+	Releases
+	BackupSchedules
+	Replications
 }
 
 const (
@@ -1555,6 +1645,8 @@ type Server struct {
 	Provisioner string `json:"provisioner"`
 	// OSInfo identifies the host operating system
 	OSInfo OSInfo `json:"os"`
+	// Arch is the CPU architecture of the server, e.g. amd64
+	Arch string `json:"arch,omitempty"`
 	// Mounts lists mount configurations for a server profile instance
 	Mounts []Mount `json:"mounts"`
 	// SystemState defines the system configuration for gravity - location
@@ -1568,6 +1660,27 @@ type Server struct {
 	User OSUser `json:"user"`
 	// Created is the timestamp when the server was created
 	Created time.Time `json:"created"`
+	// Labels specifies additional labels to apply to the Kubernetes node
+	Labels map[string]string `json:"labels,omitempty"`
+	// Taints specifies additional taints to apply to the Kubernetes node
+	// in the "key=value:effect" format
+	Taints []string `json:"taints,omitempty"`
+	// NodePool is the name of the node pool this server belongs to
+	NodePool string `json:"node_pool,omitempty"`
+	// ClusterAddr is the address of the interface dedicated to the cluster
+	// traffic if the node profile declares one
+	ClusterAddr string `json:"cluster_addr,omitempty"`
+	// ApplicationAddr is the address of the interface dedicated to the
+	// application traffic if the node profile declares one
+	ApplicationAddr string `json:"application_addr,omitempty"`
+}
+
+// GetClusterAddr returns the address the server uses for the cluster traffic
+func (s *Server) GetClusterAddr() string {
+	if s.ClusterAddr != "" {
+		return s.ClusterAddr
+	}
+	return s.AdvertiseIP
 }
 
 // IsEqualTo returns true if this and the provided server are the same server.
@@ -1602,6 +1715,15 @@ func (s *Server) KubeNodeID() string {
 	return s.AdvertiseIP
 }
 
+// GetArch returns the CPU architecture of the server.
+// Servers that predate architecture support are amd64
+func (s *Server) GetArch() string {
+	if s.Arch == "" {
+		return constants.ArchAMD64
+	}
+	return s.Arch
+}
+
 // IsMaster returns true if the server has a master role
 func (s *Server) IsMaster() bool {
 	return s.ClusterRole == string(schema.ServiceRoleMaster)
@@ -1630,6 +1752,36 @@ type DNSOverrides struct {
 	Hosts map[string]string `json:"hosts"`
 	// Zones maps a DNS zone to nameservers it will be served by
 	Zones map[string][]string `json:"zones"`
+	// Upstreams lists nameservers to forward queries for external names to
+	Upstreams []string `json:"upstreams,omitempty"`
+}
+
+// Check validates the DNS overrides
+func (d DNSOverrides) Check() error {
+	for hostname, ip := range d.Hosts {
+		if !cstrings.IsValidDomainName(hostname) {
+			return trace.BadParameter("%q is not a valid domain name", hostname)
+		}
+		if net.ParseIP(ip) == nil {
+			return trace.BadParameter("%q is not a valid IP address", ip)
+		}
+	}
+	for zone, nameservers := range d.Zones {
+		if !cstrings.IsValidDomainName(zone) {
+			return trace.BadParameter("%q is not a valid domain name", zone)
+		}
+		for _, nameserver := range nameservers {
+			if err := utils.CheckNameserver(nameserver); err != nil {
+				return trace.Wrap(err)
+			}
+		}
+	}
+	for _, nameserver := range d.Upstreams {
+		if err := utils.CheckNameserver(nameserver); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
 }
 
 // FormatHosts formats host overrides to a string
@@ -1851,6 +2003,18 @@ type OnPremVariables struct {
 	ServiceCIDR string `json:"service_cidr"`
 	// VxlanPort is the overlay network port
 	VxlanPort int `json:"vxlan_port"`
+	// NetworkEncryption is the encryption mode of the pod traffic
+	// between the nodes, empty if the traffic is not encrypted
+	NetworkEncryption string `json:"network_encryption,omitempty"`
+}
+
+// WireguardPort returns the port of the WireGuard tunnels between the nodes
+// if the pod traffic is encrypted with WireGuard, 0 otherwise
+func (r OnPremVariables) WireguardPort() int {
+	if r.NetworkEncryption != constants.NetworkEncryptionWireguard {
+		return 0
+	}
+	return defaults.WireguardPort
 }
 
 // AWSVariables is a set of operation variables specific to AWS provider
@@ -2040,6 +2204,219 @@ type UpdateOperationState struct {
 	ServerUpdates []ServerUpdate `json:"server_updates,omitempty"`
 	// Manual specifies whether this update operation was created in manual mode
 	Manual bool `json:"manual"`
+	// Strategy optionally overrides the upgrade strategy from the application manifest
+	Strategy *UpdateStrategy `json:"strategy,omitempty"`
+	// AutoRollback optionally enables automatic rollback of the operation
+	// if it fails
+	AutoRollback *AutoRollbackPolicy `json:"auto_rollback,omitempty"`
+	// Schedule optionally restricts node-disruptive phases of the operation
+	// to the specified maintenance window
+	Schedule *MaintenanceWindow `json:"schedule,omitempty"`
+	// Drain optionally overrides how the nodes are drained
+	Drain *DrainPolicy `json:"drain,omitempty"`
+}
+
+// AutoRollbackPolicy defines when a failed update operation is rolled back automatically
+type AutoRollbackPolicy struct {
+	// Retries specifies how many times a failed operation plan is resumed
+	// before it is rolled back
+	Retries int `json:"retries"`
+}
+
+// Check validates this rollback policy
+func (r AutoRollbackPolicy) Check() error {
+	if r.Retries < 0 {
+		return trace.BadParameter("number of retries cannot be negative: %v", r.Retries)
+	}
+	return nil
+}
+
+// UpdateStrategy describes how the cluster nodes are updated
+type UpdateStrategy struct {
+	// Type specifies the strategy type, see schema.UpgradeStrategies
+	Type string `json:"type,omitempty"`
+	// CanaryNode specifies the hostname or the advertise address of the canary node
+	CanaryNode string `json:"canary_node,omitempty"`
+	// SoakPeriod specifies the duration to observe the canary node before proceeding.
+	// If zero, the update waits for an explicit approval
+	SoakPeriod time.Duration `json:"soak_period,omitempty"`
+	// BatchSize specifies the number of regular nodes to update in parallel
+	BatchSize int `json:"batch_size,omitempty"`
+	// MaxUnavailablePercent specifies the number of regular nodes to update
+	// in parallel as a percentage of all regular nodes.
+	// Ignored if BatchSize is set
+	MaxUnavailablePercent int `json:"max_unavailable_percent,omitempty"`
+}
+
+// Check validates this update strategy
+func (r UpdateStrategy) Check() error {
+	if r.Type != "" && !utils.StringInSlice(schema.UpgradeStrategies, r.Type) {
+		return trace.BadParameter("unrecognized upgrade strategy %q, supported are: %v",
+			r.Type, schema.UpgradeStrategies)
+	}
+	if r.SoakPeriod < 0 {
+		return trace.BadParameter("canary soak period cannot be negative: %v", r.SoakPeriod)
+	}
+	if r.BatchSize < 0 {
+		return trace.BadParameter("batch size cannot be negative: %v", r.BatchSize)
+	}
+	if r.MaxUnavailablePercent < 0 || r.MaxUnavailablePercent > 100 {
+		return trace.BadParameter("max unavailable percentage should be between 1%% and 100%%, got %v%%",
+			r.MaxUnavailablePercent)
+	}
+	return nil
+}
+
+// GetBatchSize returns the number of the specified regular nodes to update in parallel
+func (r UpdateStrategy) GetBatchSize(nodes int) int {
+	if r.BatchSize > 0 {
+		return r.BatchSize
+	}
+	if r.MaxUnavailablePercent > 0 {
+		if size := nodes * r.MaxUnavailablePercent / 100; size > 1 {
+			return size
+		}
+	}
+	return 1
+}
+
+// DrainPolicy describes how the cluster nodes are drained during update
+type DrainPolicy struct {
+	// GracePeriod overrides the termination grace period of the evicted pods.
+	// If zero, the grace period defined for each pod is used
+	GracePeriod time.Duration `json:"grace_period,omitempty"`
+	// Timeout specifies the total time to drain a single node.
+	// If zero, defaults.DrainTimeout is used
+	Timeout time.Duration `json:"timeout,omitempty"`
+	// ForceAfter specifies the duration after which the pods that could not
+	// be evicted are deleted regardless of their pod disruption budgets.
+	// If zero, the pods are never deleted forcibly
+	ForceAfter time.Duration `json:"force_after,omitempty"`
+	// IgnorePodDisruptionBudgets specifies whether to delete pods right away
+	// instead of evicting them with respect to their pod disruption budgets
+	IgnorePodDisruptionBudgets bool `json:"ignore_pod_disruption_budgets,omitempty"`
+	// EvictDaemonSets specifies whether to also evict pods managed by daemon sets
+	EvictDaemonSets bool `json:"evict_daemon_sets,omitempty"`
+	// SkipNodes lists hostnames or advertise addresses of the nodes not to drain
+	SkipNodes []string `json:"skip_nodes,omitempty"`
+}
+
+// Check validates this drain policy
+func (r DrainPolicy) Check() error {
+	if r.GracePeriod < 0 {
+		return trace.BadParameter("drain grace period cannot be negative: %v", r.GracePeriod)
+	}
+	if r.Timeout < 0 {
+		return trace.BadParameter("drain timeout cannot be negative: %v", r.Timeout)
+	}
+	if r.ForceAfter < 0 {
+		return trace.BadParameter("drain force timeout cannot be negative: %v", r.ForceAfter)
+	}
+	if r.Timeout != 0 && r.ForceAfter >= r.Timeout {
+		return trace.BadParameter("drain force timeout (%v) should be less than drain timeout (%v)",
+			r.ForceAfter, r.Timeout)
+	}
+	return nil
+}
+
+// ShouldSkip returns true if the specified server should not be drained
+func (r DrainPolicy) ShouldSkip(server Server) bool {
+	for _, node := range r.SkipNodes {
+		if server.Hostname == node || server.AdvertiseIP == node {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateNodeRoleOperationState describes the state of the operation
+// to promote a regular node to master or demote a master to a regular node
+type UpdateNodeRoleOperationState struct {
+	// Server is the server being promoted or demoted with its current cluster role
+	Server Server `json:"server"`
+	// ClusterRole is the new cluster role of the server
+	ClusterRole string `json:"cluster_role"`
+}
+
+// IsPromotion returns true if the operation promotes the server to master
+func (s UpdateNodeRoleOperationState) IsPromotion() bool {
+	return s.ClusterRole == string(schema.ServiceRoleMaster)
+}
+
+// RestoreOperationState describes the state of the operation
+// to restore the cluster from a backup
+type RestoreOperationState struct {
+	// Backup is the path to the backup directory on the node
+	// the operation has been started on
+	Backup string `json:"backup"`
+}
+
+// EtcdRestoreOperationState describes the state of the operation
+// to restore the etcd database from a snapshot
+type EtcdRestoreOperationState struct {
+	// Snapshot is the path to the etcd snapshot on the node
+	// the operation has been started on
+	Snapshot string `json:"snapshot"`
+	// Revision is the revision of the etcd database in the snapshot
+	Revision int64 `json:"revision"`
+}
+
+// AppBackupOperationState describes the state of the operation
+// to back up a single application or to restore it from its backup
+type AppBackupOperationState struct {
+	// Release is the name of the application release
+	Release string `json:"release"`
+	// Path is the path to the backup tarball on the node
+	// the operation has been started on
+	Path string `json:"path"`
+}
+
+// ChangeCIDROperationState describes the state of the operation
+// to change the pod and service subnets of the cluster
+type ChangeCIDROperationState struct {
+	// PrevSubnets specifies the subnets the cluster is changed from
+	PrevSubnets Subnets `json:"prev_subnets"`
+	// Subnets specifies the subnets the cluster is changed to
+	Subnets Subnets `json:"subnets"`
+}
+
+// ServiceChanged returns true if the operation changes the service subnet
+func (r ChangeCIDROperationState) ServiceChanged() bool {
+	return r.PrevSubnets.Service != r.Subnets.Service
+}
+
+// RotateCertificatesOperationState describes the state of the operation
+// to rotate the certificates of the cluster nodes
+type RotateCertificatesOperationState struct {
+	// RotateCA specifies whether the cluster certificate authority
+	// is replaced as well
+	RotateCA bool `json:"rotate_ca"`
+}
+
+// ReplaceNodeOperationState describes the state of the operation
+// to replace an existing node with a new one
+type ReplaceNodeOperationState struct {
+	// Server is the server being replaced
+	Server Server `json:"server"`
+	// NewAddr is the advertise address of the replacement node
+	NewAddr string `json:"new_addr"`
+	// SSH specifies the credentials to connect to the replacement node with.
+	// If unspecified, the join is executed by the agent already running on the node
+	SSH *SSHCredentials `json:"ssh,omitempty"`
+	// Force specifies whether the replaced node is removed even if it is offline
+	Force bool `json:"force,omitempty"`
+}
+
+// SSHCredentials describes how to connect to a node over SSH
+type SSHCredentials struct {
+	// User is the name of the SSH user
+	User string `json:"user"`
+	// Port is the SSH port
+	Port int `json:"port"`
+	// PrivateKeyPath is the path to the private key to authenticate with
+	PrivateKeyPath string `json:"private_key_path"`
+	// HostKey is the optional public host key of the node in the authorized_keys format
+	HostKey string `json:"host_key,omitempty"`
 }
 
 // UpdateEnvarsOperationState describes the state of the operation to update cluster environment variables.
@@ -2134,6 +2511,112 @@ type CloudConfig struct {
 	GCENodeTags []string `json:"gce_node_tags,omitempty"`
 }
 
+// NodePools defines the interface to manage cluster node pools
+type NodePools interface {
+	// UpsertNodePool creates or updates the node pool for the specified cluster
+	UpsertNodePool(clusterName string, pool NodePool) error
+	// GetNodePool returns the node pool with the specified name
+	GetNodePool(clusterName, name string) (NodePool, error)
+	// GetNodePools returns all node pools of the specified cluster
+	GetNodePools(clusterName string) ([]NodePool, error)
+	// DeleteNodePool deletes the node pool with the specified name
+	DeleteNodePool(clusterName, name string) error
+}
+
+// Releases defines the interface to track the application releases
+// deployed into the cluster
+type Releases interface {
+	// UpsertRelease creates or updates the release record of the specified cluster
+	UpsertRelease(clusterName string, release Release) error
+	// GetRelease returns the release record with the specified name
+	GetRelease(clusterName, name string) (Release, error)
+	// GetReleases returns all release records of the specified cluster
+	GetReleases(clusterName string) ([]Release, error)
+	// DeleteRelease deletes the release record with the specified name
+	DeleteRelease(clusterName, name string) error
+}
+
+// ClusterRosters defines the interface to manage the cluster roster
+type ClusterRosters interface {
+	// UpsertClusterRoster creates or updates the roster of the specified cluster
+	UpsertClusterRoster(clusterName string, roster ClusterRoster) error
+	// GetClusterRoster returns the roster of the specified cluster
+	GetClusterRoster(clusterName string) (ClusterRoster, error)
+	// DeleteClusterRoster deletes the roster and its status
+	DeleteClusterRoster(clusterName string) error
+	// UpsertClusterRosterStatus updates the roster reconciliation status
+	UpsertClusterRosterStatus(clusterName string, status ClusterRosterStatus) error
+	// GetClusterRosterStatus returns the roster reconciliation status
+	GetClusterRosterStatus(clusterName string) (*ClusterRosterStatus, error)
+}
+
+// OperationWebhooks defines the interface to manage cluster operation webhooks
+type OperationWebhooks interface {
+	// UpsertOperationWebhook creates or updates the operation webhook for the specified cluster
+	UpsertOperationWebhook(clusterName string, webhook OperationWebhook) error
+	// GetOperationWebhook returns the operation webhook with the specified name
+	GetOperationWebhook(clusterName, name string) (OperationWebhook, error)
+	// GetOperationWebhooks returns all operation webhooks of the specified cluster
+	GetOperationWebhooks(clusterName string) ([]OperationWebhook, error)
+	// DeleteOperationWebhook deletes the operation webhook with the specified name
+	DeleteOperationWebhook(clusterName, name string) error
+}
+
+// HealthChecks defines the interface to manage custom cluster health checks
+type HealthChecks interface {
+	// UpsertHealthCheck creates or updates the health check for the specified cluster
+	UpsertHealthCheck(clusterName string, check HealthCheck) error
+	// GetHealthCheck returns the health check with the specified name
+	GetHealthCheck(clusterName, name string) (HealthCheck, error)
+	// GetHealthChecks returns all health checks of the specified cluster
+	GetHealthChecks(clusterName string) ([]HealthCheck, error)
+	// DeleteHealthCheck deletes the health check with the specified name
+	DeleteHealthCheck(clusterName, name string) error
+	// UpsertHealthCheckStatus updates the result of the last evaluation
+	// of the health checks of the specified cluster
+	UpsertHealthCheckStatus(clusterName string, status HealthCheckStatus) error
+	// GetHealthCheckStatus returns the result of the last evaluation
+	// of the health checks of the specified cluster
+	GetHealthCheckStatus(clusterName string) (*HealthCheckStatus, error)
+}
+
+// ImageTrustPolicies defines the interface to manage the container image trust policy.
+//
+// The policy is not scoped to a cluster since it applies to all images
+// imported into the local application service
+type ImageTrustPolicies interface {
+	// UpsertImageTrustPolicy creates or updates the image trust policy
+	UpsertImageTrustPolicy(ImageTrustPolicy) error
+	// GetImageTrustPolicy returns the image trust policy
+	GetImageTrustPolicy() (ImageTrustPolicy, error)
+	// DeleteImageTrustPolicy deletes the image trust policy
+	DeleteImageTrustPolicy() error
+}
+
+// OperationApprovalPolicies defines the interface to manage the policy
+// of the operations that require approval
+type OperationApprovalPolicies interface {
+	// UpsertOperationApprovalPolicy creates or updates the operation approval policy
+	// of the specified cluster
+	UpsertOperationApprovalPolicy(clusterName string, policy OperationApprovalPolicy) error
+	// GetOperationApprovalPolicy returns the operation approval policy of the specified cluster
+	GetOperationApprovalPolicy(clusterName string) (OperationApprovalPolicy, error)
+	// DeleteOperationApprovalPolicy deletes the operation approval policy of the specified cluster
+	DeleteOperationApprovalPolicy(clusterName string) error
+}
+
+// CatalogSyncSchedules defines the interface to manage the schedule
+// to mirror an upstream Helm repository into the local catalog
+type CatalogSyncSchedules interface {
+	// UpsertCatalogSyncSchedule creates or updates the catalog sync schedule
+	// of the specified cluster
+	UpsertCatalogSyncSchedule(clusterName string, schedule CatalogSyncSchedule) error
+	// GetCatalogSyncSchedule returns the catalog sync schedule of the specified cluster
+	GetCatalogSyncSchedule(clusterName string) (CatalogSyncSchedule, error)
+	// DeleteCatalogSyncSchedule deletes the catalog sync schedule of the specified cluster
+	DeleteCatalogSyncSchedule(clusterName string) error
+}
+
 // Charts defines methods related to Helm chart repository functionality.
 type Charts interface {
 	// GetIndexFile returns the chart repository index file.
@@ -1236,6 +1236,51 @@ func (s *StorageSuite) UpdatesAppImportOperation(c *C) {
 	c.Assert(updatedOp.State, Equals, "finished")
 }
 
+func (s *StorageSuite) OperationPlanChangelog(c *C) {
+	const clusterName = "example.com"
+	const operationID = "operation-1"
+
+	change, err := s.Backend.CreateOperationPlanChange(storage.PlanChange{
+		ClusterName: clusterName,
+		OperationID: operationID,
+		PhaseID:     "/init",
+		NewState:    storage.OperationPhaseStateInProgress,
+		Created:     now,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(change.Revision, Equals, int64(1))
+
+	// A writer that has not seen the latest change is rejected
+	_, err = s.Backend.CreateOperationPlanChange(storage.PlanChange{
+		ClusterName: clusterName,
+		OperationID: operationID,
+		PhaseID:     "/init",
+		NewState:    storage.OperationPhaseStateFailed,
+		Created:     now,
+		Revision:    1,
+	})
+	c.Assert(trace.IsCompareFailed(err), Equals, true, Commentf("%v", err))
+
+	// Changes are appended after all recorded changes of the phase,
+	// even if the writer's clock is behind
+	completed, err := storage.AppendOperationPlanChange(s.Backend, storage.PlanChange{
+		ClusterName: clusterName,
+		OperationID: operationID,
+		PhaseID:     "/init",
+		NewState:    storage.OperationPhaseStateCompleted,
+		Created:     now.Add(-time.Minute),
+	})
+	c.Assert(err, IsNil)
+	c.Assert(completed.Revision, Equals, int64(2))
+	c.Assert(completed.Created.After(now), Equals, true)
+
+	changelog, err := s.Backend.GetOperationPlanChangelog(clusterName, operationID)
+	c.Assert(err, IsNil)
+	c.Assert(changelog, HasLen, 2)
+	c.Assert(changelog.Revision(), Equals, int64(2))
+	c.Assert(changelog.Latest("/init").NewState, Equals, storage.OperationPhaseStateCompleted)
+}
+
 func (s *StorageSuite) APIKeysCRUD(c *C) {
 	u := storage.NewUser("testagent@example.com", storage.UserSpecV2{
 		Type: "agent",
@@ -1394,6 +1439,30 @@ func (s *StorageSuite) ProvisioningTokensCRUD(c *C) {
 
 	_, err = s.Backend.GetProvisioningToken(token2.Token)
 	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%#v"))
+
+	// token3 is a short-lived single-use join token
+	token3 := storage.ProvisioningToken{
+		Token:      "tok3",
+		Expires:    now.Add(time.Hour),
+		Type:       storage.ProvisioningTokenTypeExpand,
+		AccountID:  a.ID,
+		SiteDomain: sa.Domain,
+		UserEmail:  u.GetName(),
+		Roles:      []string{"worker"},
+		MaxUses:    1,
+	}
+	_, err = s.Backend.CreateProvisioningToken(token3)
+	c.Assert(err, IsNil)
+	c.Assert(token3.CheckRole("worker"), IsNil)
+	c.Assert(token3.CheckRole("master"), NotNil)
+
+	tokout, err = s.Backend.UseProvisioningToken(token3.Token)
+	c.Assert(err, IsNil)
+	c.Assert(tokout.Uses, Equals, 1)
+	c.Assert(tokout.IsExhausted(), Equals, true)
+
+	_, err = s.Backend.UseProvisioningToken(token3.Token)
+	c.Assert(trace.IsLimitExceeded(err), Equals, true, Commentf("%v", err))
 }
 
 func (s *StorageSuite) SchemaVersionPresent(c *C) {
@@ -1957,6 +2026,549 @@ func (s *StorageSuite) IndexFile(c *C) {
 	compare.DeepCompare(c, retrievedFile, updatedIndex2)
 }
 
+func (s *StorageSuite) NodePoolsCRUD(c *C) {
+	const clusterName = "example.com"
+
+	// No node pools initially.
+	pools, err := s.Backend.GetNodePools(clusterName)
+	c.Assert(err, IsNil)
+	c.Assert(pools, HasLen, 0)
+
+	gpu := storage.NewNodePool("gpu", storage.NodePoolSpecV2{
+		Profile:  "gpu-worker",
+		Labels:   map[string]string{"accelerator": "nvidia"},
+		MaxCount: 3,
+	})
+	c.Assert(s.Backend.UpsertNodePool(clusterName, gpu), IsNil)
+	storagePool := storage.NewNodePool("storage", storage.NodePoolSpecV2{
+		Profile:  "storage",
+		MinCount: 3,
+	})
+	c.Assert(s.Backend.UpsertNodePool(clusterName, storagePool), IsNil)
+
+	out, err := s.Backend.GetNodePool(clusterName, "gpu")
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, out, gpu)
+
+	pools, err = s.Backend.GetNodePools(clusterName)
+	c.Assert(err, IsNil)
+	c.Assert(pools, HasLen, 2)
+
+	// Pools are scoped to the cluster.
+	pools, err = s.Backend.GetNodePools("other.example.com")
+	c.Assert(err, IsNil)
+	c.Assert(pools, HasLen, 0)
+
+	c.Assert(s.Backend.DeleteNodePool(clusterName, "gpu"), IsNil)
+	_, err = s.Backend.GetNodePool(clusterName, "gpu")
+	c.Assert(trace.IsNotFound(err), Equals, true)
+	err = s.Backend.DeleteNodePool(clusterName, "gpu")
+	c.Assert(trace.IsNotFound(err), Equals, true)
+}
+
+func (s *StorageSuite) ReleasesCRUD(c *C) {
+	const clusterName = "example.com"
+
+	releases, err := s.Backend.GetReleases(clusterName)
+	c.Assert(err, IsNil)
+	c.Assert(releases, HasLen, 0)
+
+	release := &storage.ReleaseV1{
+		Kind:     storage.KindRelease,
+		Version:  teleservices.V1,
+		Metadata: teleservices.Metadata{Name: "frontend"},
+		Spec: storage.ReleaseSpecV1{
+			ChartName:    "frontend",
+			ChartVersion: "1.0.0",
+			Namespace:    "web",
+		},
+		Status: storage.ReleaseStatusV1{
+			Status:   "DEPLOYED",
+			Revision: 1,
+			Updated:  s.Clock.Now().UTC(),
+		},
+	}
+	release.SetLocator(loc.MustParseLocator("example.com/shop:2.0.0"))
+	c.Assert(s.Backend.UpsertRelease(clusterName, release), IsNil)
+
+	out, err := s.Backend.GetRelease(clusterName, "frontend")
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, out, release)
+	c.Assert(out.GetLocator(), Equals, loc.MustParseLocator("example.com/shop:2.0.0"))
+
+	releases, err = s.Backend.GetReleases(clusterName)
+	c.Assert(err, IsNil)
+	c.Assert(releases, HasLen, 1)
+
+	// Releases are scoped to the cluster.
+	releases, err = s.Backend.GetReleases("other.example.com")
+	c.Assert(err, IsNil)
+	c.Assert(releases, HasLen, 0)
+
+	c.Assert(s.Backend.DeleteRelease(clusterName, "frontend"), IsNil)
+	_, err = s.Backend.GetRelease(clusterName, "frontend")
+	c.Assert(trace.IsNotFound(err), Equals, true)
+	err = s.Backend.DeleteRelease(clusterName, "frontend")
+	c.Assert(trace.IsNotFound(err), Equals, true)
+}
+
+func (s *StorageSuite) ClusterRosterCRUD(c *C) {
+	const clusterName = "example.com"
+
+	_, err := s.Backend.GetClusterRoster(clusterName)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+
+	roster := storage.NewClusterRoster(storage.ClusterRosterSpecV2{
+		Nodes: []storage.RosterNode{{Addr: "10.0.0.1", Role: "node"}},
+		SSH:   storage.RosterSSH{User: "root", Secret: "ssh-key"},
+	})
+	c.Assert(s.Backend.UpsertClusterRoster(clusterName, roster), IsNil)
+	out, err := s.Backend.GetClusterRoster(clusterName)
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, out, roster)
+
+	status := storage.ClusterRosterStatus{
+		Changes: []storage.RosterChange{
+			{Action: storage.RosterActionAdd, Addr: "10.0.0.1", Role: "node"},
+		},
+		ChangesID: "1234",
+		Updated:   s.Clock.Now().UTC(),
+	}
+	c.Assert(s.Backend.UpsertClusterRosterStatus(clusterName, status), IsNil)
+	outStatus, err := s.Backend.GetClusterRosterStatus(clusterName)
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, outStatus, &status)
+
+	// Deleting the roster also deletes its status.
+	c.Assert(s.Backend.DeleteClusterRoster(clusterName), IsNil)
+	_, err = s.Backend.GetClusterRoster(clusterName)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+	_, err = s.Backend.GetClusterRosterStatus(clusterName)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+	err = s.Backend.DeleteClusterRoster(clusterName)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+}
+
+func (s *StorageSuite) EtcdMaintenanceStatusCRUD(c *C) {
+	const clusterName = "example.com"
+
+	_, err := s.Backend.GetEtcdMaintenanceStatus(clusterName)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+
+	status := storage.EtcdMaintenanceStatus{
+		Updated:           s.Clock.Now().UTC(),
+		Revision:          12000,
+		CompactedRevision: 2000,
+		LastCompaction:    s.Clock.Now().UTC(),
+		Members: []storage.EtcdMemberMaintenanceStatus{{
+			Name:                "node-1",
+			Endpoint:            "https://10.0.0.1:2379",
+			DBSize:              1024,
+			LastDefragmentation: s.Clock.Now().UTC(),
+			ReclaimedBytes:      512,
+		}},
+	}
+	c.Assert(s.Backend.UpsertEtcdMaintenanceStatus(clusterName, status), IsNil)
+	out, err := s.Backend.GetEtcdMaintenanceStatus(clusterName)
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, out, &status)
+}
+
+func (s *StorageSuite) EtcdHealthStatusCRUD(c *C) {
+	const clusterName = "example.com"
+
+	_, err := s.Backend.GetEtcdHealthStatus(clusterName)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+
+	status := storage.EtcdHealthStatus{
+		Updated:       s.Clock.Now().UTC(),
+		Interval:      time.Minute,
+		QuotaBytes:    2048,
+		Leader:        "node-1",
+		RaftTerm:      3,
+		LeaderChanges: []time.Time{s.Clock.Now().UTC()},
+		ReadLatency:   time.Millisecond,
+		Alarms:        []storage.EtcdAlarm{{Member: "node-1", Type: storage.EtcdAlarmNoSpace}},
+		Stores:        []storage.EtcdStoreUsage{{Name: "gravity", Prefix: "/gravity", Keys: 100}},
+		Members: []storage.EtcdMemberHealth{{
+			Name:         "node-1",
+			Endpoint:     "https://10.0.0.1:2379",
+			DBSize:       1024,
+			RaftIndex:    1200,
+			RaftIndexLag: 10,
+		}},
+	}
+	c.Assert(s.Backend.UpsertEtcdHealthStatus(clusterName, status), IsNil)
+	out, err := s.Backend.GetEtcdHealthStatus(clusterName)
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, out, &status)
+}
+
+func (s *StorageSuite) ClusterEventsCRUD(c *C) {
+	const clusterName = "example.com"
+
+	events, err := s.Backend.GetClusterEvents(clusterName, "", 0)
+	c.Assert(err, IsNil)
+	c.Assert(events, HasLen, 0)
+
+	var created []storage.ClusterEvent
+	for _, name := range []string{"operation.started", "node.degraded", "operation.completed"} {
+		event, err := s.Backend.CreateClusterEvent(clusterName, storage.ClusterEvent{
+			Name:   name,
+			Code:   "G0000I",
+			Fields: map[string]interface{}{"name": name},
+		}, time.Hour)
+		c.Assert(err, IsNil)
+		c.Assert(event.ID, Not(Equals), "")
+		created = append(created, *event)
+	}
+	_, err = s.Backend.CreateClusterEvent(clusterName, storage.ClusterEvent{}, time.Hour)
+	c.Assert(trace.IsBadParameter(err), Equals, true)
+
+	events, err = s.Backend.GetClusterEvents(clusterName, "", 0)
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, events, created)
+
+	// Resume after the first event
+	events, err = s.Backend.GetClusterEvents(clusterName, created[0].ID, 1)
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, events, created[1:2])
+
+	events, err = s.Backend.GetClusterEvents(clusterName, created[2].ID, 0)
+	c.Assert(err, IsNil)
+	c.Assert(events, HasLen, 0)
+}
+
+func (s *StorageSuite) OperationWebhooksCRUD(c *C) {
+	const clusterName = "example.com"
+
+	// No webhooks initially.
+	webhooks, err := s.Backend.GetOperationWebhooks(clusterName)
+	c.Assert(err, IsNil)
+	c.Assert(webhooks, HasLen, 0)
+
+	hours := storage.NewOperationWebhook("business-hours", storage.OperationWebhookSpecV2{
+		URL:           "https://hooks.example.com/hours",
+		Operations:    []string{"operation_update"},
+		FailurePolicy: storage.WebhookFailurePolicyIgnore,
+	})
+	c.Assert(s.Backend.UpsertOperationWebhook(clusterName, hours), IsNil)
+	images := storage.NewOperationWebhook("images", storage.OperationWebhookSpecV2{
+		URL: "https://hooks.example.com/images",
+	})
+	c.Assert(s.Backend.UpsertOperationWebhook(clusterName, images), IsNil)
+
+	out, err := s.Backend.GetOperationWebhook(clusterName, "business-hours")
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, out, hours)
+
+	webhooks, err = s.Backend.GetOperationWebhooks(clusterName)
+	c.Assert(err, IsNil)
+	c.Assert(webhooks, HasLen, 2)
+
+	// Webhooks are scoped to the cluster.
+	webhooks, err = s.Backend.GetOperationWebhooks("other.example.com")
+	c.Assert(err, IsNil)
+	c.Assert(webhooks, HasLen, 0)
+
+	c.Assert(s.Backend.DeleteOperationWebhook(clusterName, "business-hours"), IsNil)
+	_, err = s.Backend.GetOperationWebhook(clusterName, "business-hours")
+	c.Assert(trace.IsNotFound(err), Equals, true)
+	err = s.Backend.DeleteOperationWebhook(clusterName, "business-hours")
+	c.Assert(trace.IsNotFound(err), Equals, true)
+}
+
+func (s *StorageSuite) HealthChecksCRUD(c *C) {
+	const clusterName = "example.com"
+
+	// No health checks initially.
+	checks, err := s.Backend.GetHealthChecks(clusterName)
+	c.Assert(err, IsNil)
+	c.Assert(checks, HasLen, 0)
+	_, err = s.Backend.GetHealthCheckStatus(clusterName)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+
+	api := storage.NewHealthCheck("api", storage.HealthCheckSpecV2{
+		HTTP: &storage.HealthCheckHTTP{
+			URL:            "https://api.example.com/healthz",
+			ExpectedStatus: 204,
+		},
+	})
+	c.Assert(s.Backend.UpsertHealthCheck(clusterName, api), IsNil)
+	queue := storage.NewHealthCheck("queue", storage.HealthCheckSpecV2{
+		Kubernetes: &storage.HealthCheckKubernetes{
+			Kind:      "deployment",
+			Namespace: "default",
+			Name:      "queue",
+			Condition: "Available",
+		},
+		Severity: storage.HealthCheckSeverityWarning,
+	})
+	c.Assert(s.Backend.UpsertHealthCheck(clusterName, queue), IsNil)
+
+	out, err := s.Backend.GetHealthCheck(clusterName, "api")
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, out, api)
+
+	checks, err = s.Backend.GetHealthChecks(clusterName)
+	c.Assert(err, IsNil)
+	c.Assert(checks, HasLen, 2)
+
+	// Health checks are scoped to the cluster.
+	checks, err = s.Backend.GetHealthChecks("other.example.com")
+	c.Assert(err, IsNil)
+	c.Assert(checks, HasLen, 0)
+
+	status := storage.HealthCheckStatus{
+		Results: []storage.HealthCheckResult{{
+			Name:     "api",
+			Type:     storage.HealthCheckHTTPType,
+			Severity: storage.HealthCheckSeverityCritical,
+			Message:  "unexpected response status 503",
+		}},
+		Updated: s.Clock.Now().UTC(),
+	}
+	c.Assert(s.Backend.UpsertHealthCheckStatus(clusterName, status), IsNil)
+	outStatus, err := s.Backend.GetHealthCheckStatus(clusterName)
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, outStatus, &status)
+
+	c.Assert(s.Backend.DeleteHealthCheck(clusterName, "api"), IsNil)
+	_, err = s.Backend.GetHealthCheck(clusterName, "api")
+	c.Assert(trace.IsNotFound(err), Equals, true)
+	err = s.Backend.DeleteHealthCheck(clusterName, "api")
+	c.Assert(trace.IsNotFound(err), Equals, true)
+}
+
+func (s *StorageSuite) HealthHistory(c *C) {
+	const clusterName = "example.com"
+
+	// No snapshots initially.
+	snapshots, err := s.Backend.GetHealthSnapshots(clusterName, time.Time{})
+	c.Assert(err, IsNil)
+	c.Assert(snapshots, HasLen, 0)
+
+	start := s.Clock.Now().UTC()
+	var recorded []storage.HealthSnapshot
+	for i := 0; i < 5; i++ {
+		snapshot := storage.HealthSnapshot{
+			Time:   start.Add(time.Duration(i) * time.Minute),
+			Status: storage.HealthStatusHealthy,
+			Nodes: []storage.NodeHealth{{
+				Hostname:    "node-1",
+				AdvertiseIP: "192.168.1.1",
+				Status:      "healthy",
+			}},
+		}
+		if i%2 == 1 {
+			snapshot.Status = storage.HealthStatusDegraded
+			snapshot.Reason = storage.ReasonClusterDegraded
+			snapshot.Nodes[0].Status = "degraded"
+			snapshot.Nodes[0].FailedProbes = []string{"docker"}
+		}
+		c.Assert(s.Backend.AddHealthSnapshot(clusterName, snapshot, 3), IsNil)
+		recorded = append(recorded, snapshot)
+	}
+
+	// Only the most recent snapshots are kept.
+	snapshots, err = s.Backend.GetHealthSnapshots(clusterName, time.Time{})
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, snapshots, recorded[2:])
+
+	snapshots, err = s.Backend.GetHealthSnapshots(clusterName, start.Add(3*time.Minute))
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, snapshots, recorded[3:])
+
+	// History is scoped to the cluster.
+	snapshots, err = s.Backend.GetHealthSnapshots("other.example.com", time.Time{})
+	c.Assert(err, IsNil)
+	c.Assert(snapshots, HasLen, 0)
+}
+
+func (s *StorageSuite) ImageTrustPolicyCRUD(c *C) {
+	_, err := s.Backend.GetImageTrustPolicy()
+	c.Assert(trace.IsNotFound(err), Equals, true)
+
+	policy := storage.NewImageTrustPolicy(storage.ImageTrustPolicySpecV2{
+		Mode:       storage.ImageTrustModeEnforcing,
+		PublicKeys: []string{testPublicKey},
+	})
+	c.Assert(s.Backend.UpsertImageTrustPolicy(policy), IsNil)
+	out, err := s.Backend.GetImageTrustPolicy()
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, out, policy)
+
+	c.Assert(s.Backend.DeleteImageTrustPolicy(), IsNil)
+	_, err = s.Backend.GetImageTrustPolicy()
+	c.Assert(trace.IsNotFound(err), Equals, true)
+	err = s.Backend.DeleteImageTrustPolicy()
+	c.Assert(trace.IsNotFound(err), Equals, true)
+}
+
+func (s *StorageSuite) DeviceAuthRequestCRUD(c *C) {
+	req := storage.DeviceAuthRequest{
+		DeviceCode:    "device-code",
+		UserCode:      "ABCD-EFGH",
+		ConnectorID:   "okta",
+		ConnectorKind: "saml",
+		PublicKey:     []byte("public key"),
+		CertTTL:       time.Hour,
+		Expires:       s.Clock.Now().UTC().Add(10 * time.Minute),
+	}
+	c.Assert(s.Backend.CreateDeviceAuthRequest(req), IsNil)
+	err := s.Backend.CreateDeviceAuthRequest(req)
+	c.Assert(trace.IsAlreadyExists(err), Equals, true)
+
+	out, err := s.Backend.GetDeviceAuthRequest(req.DeviceCode)
+	c.Assert(err, IsNil)
+	c.Assert(out.IsCompleted(), Equals, false)
+	compare.DeepCompare(c, out, &req)
+
+	out, err = s.Backend.GetDeviceAuthRequestByUserCode(req.UserCode)
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, out, &req)
+	_, err = s.Backend.GetDeviceAuthRequestByUserCode("WXYZ-WXYZ")
+	c.Assert(trace.IsNotFound(err), Equals, true)
+
+	req.Response = []byte("response")
+	c.Assert(s.Backend.UpdateDeviceAuthRequest(req), IsNil)
+	out, err = s.Backend.GetDeviceAuthRequest(req.DeviceCode)
+	c.Assert(err, IsNil)
+	c.Assert(out.IsCompleted(), Equals, true)
+	compare.DeepCompare(c, out, &req)
+
+	c.Assert(s.Backend.DeleteDeviceAuthRequest(req.DeviceCode), IsNil)
+	_, err = s.Backend.GetDeviceAuthRequest(req.DeviceCode)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+	err = s.Backend.UpdateDeviceAuthRequest(req)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+}
+
+func (s *StorageSuite) OperationApprovalPolicyCRUD(c *C) {
+	const clusterName = "example.com"
+
+	_, err := s.Backend.GetOperationApprovalPolicy(clusterName)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+
+	policy := storage.NewOperationApprovalPolicy(storage.OperationApprovalPolicySpecV2{
+		Operations: []string{"operation_update", "operation_shrink"},
+		Approvers:  []string{"alice@example.com"},
+	})
+	c.Assert(s.Backend.UpsertOperationApprovalPolicy(clusterName, policy), IsNil)
+	out, err := s.Backend.GetOperationApprovalPolicy(clusterName)
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, out, policy)
+
+	c.Assert(s.Backend.DeleteOperationApprovalPolicy(clusterName), IsNil)
+	_, err = s.Backend.GetOperationApprovalPolicy(clusterName)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+	err = s.Backend.DeleteOperationApprovalPolicy(clusterName)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+}
+
+func (s *StorageSuite) CatalogSyncScheduleCRUD(c *C) {
+	const clusterName = "example.com"
+
+	_, err := s.Backend.GetCatalogSyncSchedule(clusterName)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+
+	schedule := storage.NewCatalogSyncSchedule(storage.CatalogSyncScheduleSpecV2{
+		Repository: "https://charts.example.com",
+		Charts:     []string{"nginx"},
+	})
+	c.Assert(s.Backend.UpsertCatalogSyncSchedule(clusterName, schedule), IsNil)
+	out, err := s.Backend.GetCatalogSyncSchedule(clusterName)
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, out, schedule)
+
+	c.Assert(s.Backend.DeleteCatalogSyncSchedule(clusterName), IsNil)
+	_, err = s.Backend.GetCatalogSyncSchedule(clusterName)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+	err = s.Backend.DeleteCatalogSyncSchedule(clusterName)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+}
+
+func (s *StorageSuite) BackupScheduleCRUD(c *C) {
+	const clusterName = "example.com"
+
+	_, err := s.Backend.GetBackupSchedule(clusterName)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+
+	schedule := storage.NewBackupSchedule(storage.BackupScheduleSpecV2{
+		Schedule:  "0 2 * * *",
+		Directory: "/backups",
+		Retain:    14,
+	})
+	c.Assert(s.Backend.UpsertBackupSchedule(clusterName, schedule), IsNil)
+	out, err := s.Backend.GetBackupSchedule(clusterName)
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, out, schedule)
+
+	c.Assert(s.Backend.DeleteBackupSchedule(clusterName), IsNil)
+	_, err = s.Backend.GetBackupSchedule(clusterName)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+	err = s.Backend.DeleteBackupSchedule(clusterName)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+
+	_, err = s.Backend.GetBackupStatus(clusterName)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+	status := storage.BackupStatus{
+		LastAttempt: s.Clock.Now().UTC(),
+		LastBackup:  s.Clock.Now().UTC(),
+		Path:        "/backups/backup-20190601T020000Z",
+		Size:        1024,
+		Node:        "node-1",
+	}
+	c.Assert(s.Backend.UpsertBackupStatus(clusterName, status), IsNil)
+	outStatus, err := s.Backend.GetBackupStatus(clusterName)
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, *outStatus, status)
+}
+
+func (s *StorageSuite) ReplicationCRUD(c *C) {
+	const clusterName = "example.com"
+
+	_, err := s.Backend.GetReplication(clusterName)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+
+	replication := storage.NewReplication(storage.ReplicationSpecV2{
+		Source: storage.BackupDestination{
+			Type:   storage.BackupDestinationNFSType,
+			Secret: "backups",
+			NFS:    &storage.BackupDestinationNFS{Path: "/mnt/backups"},
+		},
+		Retain: 3,
+	})
+	c.Assert(s.Backend.UpsertReplication(clusterName, replication), IsNil)
+	out, err := s.Backend.GetReplication(clusterName)
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, out, replication)
+
+	c.Assert(s.Backend.DeleteReplication(clusterName), IsNil)
+	_, err = s.Backend.GetReplication(clusterName)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+	err = s.Backend.DeleteReplication(clusterName)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+
+	_, err = s.Backend.GetReplicationStatus(clusterName)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+	status := storage.ReplicationStatus{
+		LastAttempt:    s.Clock.Now().UTC(),
+		LastReplicated: s.Clock.Now().UTC(),
+		Backup:         "backup-20190601T020000Z",
+		Path:           "/var/lib/gravity/backups/replicas/backup-20190601T020000Z",
+		Node:           "node-1",
+		MissingImages:  []string{"nginx:1.17"},
+	}
+	c.Assert(s.Backend.UpsertReplicationStatus(clusterName, status), IsNil)
+	outStatus, err := s.Backend.GetReplicationStatus(clusterName)
+	c.Assert(err, IsNil)
+	compare.DeepCompare(c, *outStatus, status)
+	c.Assert(s.Backend.DeleteReplicationStatus(clusterName), IsNil)
+	_, err = s.Backend.GetReplicationStatus(clusterName)
+	c.Assert(trace.IsNotFound(err), Equals, true)
+}
+
 func newIndex() *repo.IndexFile {
 	return &repo.IndexFile{
 		APIVersion: repo.APIVersionV1,
@@ -1973,3 +2585,8 @@ func addToIndex(indexFile repo.IndexFile, name, version string) (updated, previo
 	}}
 	return indexCopy, &indexFile
 }
+
+const testPublicKey = `-----BEGIN PUBLIC KEY-----
+MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAErj2DK52nklOOt5Ig3f0mHWdehZbS
+udEGw6WtS1E3Xj9FXfd6MUX7NcrHr0Fk4P3A8vJGJMjqvMKGtD4jQWNPog==
+-----END PUBLIC KEY-----`
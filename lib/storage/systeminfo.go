@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
 	teledefaults "github.com/gravitational/teleport/lib/defaults"
 	teleservices "github.com/gravitational/teleport/lib/services"
@@ -72,6 +73,8 @@ type System interface {
 	GetLVMSystemDirectory() string
 	// GetUser returns the information about the user the agent is running under
 	GetUser() OSUser
+	// GetArch returns the CPU architecture of the host, e.g. amd64
+	GetArch() string
 }
 
 // UnmarshalSystemInfo unmarshals system info from JSON specified with data
@@ -180,6 +183,15 @@ func (r *SystemV2) GetUser() OSUser {
 	return r.Spec.User
 }
 
+// GetArch returns the CPU architecture of the host, e.g. amd64.
+// Hosts that do not report the architecture are assumed to be amd64
+func (r *SystemV2) GetArch() string {
+	if r.Spec.Arch == "" {
+		return constants.ArchAMD64
+	}
+	return r.Spec.Arch
+}
+
 // SystemV2 describes a system
 type SystemV2 struct {
 	// Kind is resource kind, "systeminfo"
@@ -223,6 +235,8 @@ type SystemSpecV2 struct {
 	LVMSystemDirectory string `json:"lvm_system_dir"`
 	// User specifies the agent's user identity
 	User OSUser `json:"user"`
+	// Arch specifies the CPU architecture of the host, e.g. amd64
+	Arch string `json:"arch,omitempty"`
 }
 
 // String returns a textual representation of this system info
@@ -364,7 +378,8 @@ const SystemSpecV2Schema = `{
         "uid": {"type": "string"},
         "gid": {"type": "string"}
       }
-    }
+    },
+    "arch": {"type": "string"}
   }
 }`
 
@@ -42,9 +42,12 @@ const (
 	AdminUser = "admin"
 	// Regular user is standard interactive user
 	RegularUser = "regular"
+	// ServiceAccountUser defines a non-interactive user used by automation
+	// that authenticates with API keys only
+	ServiceAccountUser = "serviceaccount"
 )
 
-var SupportedUserTypes = []string{AgentUser, AdminUser, RegularUser}
+var SupportedUserTypes = []string{AgentUser, AdminUser, RegularUser, ServiceAccountUser}
 
 // Users collection provides operations on users - both humans and bots
 type Users interface {
@@ -529,7 +532,7 @@ func (u *UserV2) Check() error {
 		return trace.BadParameter("unsupported user type %q, supported are: %v",
 			u.GetType(), SupportedUserTypes)
 	}
-	if u.GetType() != AgentUser && u.GetPassword() == "" {
+	if u.GetType() != AgentUser && u.GetType() != ServiceAccountUser && u.GetPassword() == "" {
 		return trace.BadParameter("parameter 'password' is not set")
 	}
 	for _, id := range u.Spec.OIDCIdentities {
@@ -784,6 +787,18 @@ func ClusterAdminAgent(clusterName string) string {
 	return fmt.Sprintf("adminagent@%v", clusterName)
 }
 
+// ClusterJoinAgent generates the name of the agent user the short-lived
+// join tokens of the specified cluster authenticate as
+func ClusterJoinAgent(clusterName string) string {
+	return fmt.Sprintf("joiner@%v", clusterName)
+}
+
+// IsClusterJoinAgent returns true if the specified user is the join agent
+// of its cluster
+func IsClusterJoinAgent(user User) bool {
+	return user.GetType() == AgentUser && user.GetName() == ClusterJoinAgent(user.GetClusterName())
+}
+
 // UserFromContext extracts name of the user attached to the provided context.
 //
 // Returns an empty string if no user is attached.
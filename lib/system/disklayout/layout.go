@@ -0,0 +1,323 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package disklayout plans the placement of the system state directory,
+// Docker storage and etcd data on the block devices of a node
+package disklayout
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/dustin/go-humanize"
+	"github.com/gravitational/trace"
+)
+
+// Role identifies the data placed on a device
+type Role string
+
+const (
+	// RoleSystem identifies the system state directory
+	RoleSystem Role = "system"
+	// RoleDocker identifies the Docker storage
+	RoleDocker Role = "docker"
+	// RoleEtcd identifies the etcd data directory
+	RoleEtcd Role = "etcd"
+)
+
+// Config defines the input for the disk layout planner
+type Config struct {
+	// Devices lists unused block devices available on the node
+	Devices []storage.Device
+	// SystemDevice optionally specifies the device for the system state directory
+	SystemDevice string
+	// DockerDevice optionally specifies the device for Docker storage
+	DockerDevice string
+	// EtcdDevice optionally specifies the device for etcd data
+	EtcdDevice string
+	// Devicemapper specifies whether Docker uses the devicemapper storage driver.
+	// With overlay, Docker data is kept in the system state directory
+	Devicemapper bool
+	// DockerCapacity specifies the minimum size of the Docker device
+	DockerCapacity utils.Capacity
+	// Etcd specifies whether the node runs etcd
+	Etcd bool
+	// AutoPartition specifies whether devices are automatically selected
+	// for the roles without an explicitly specified device.
+	// If there are not enough devices, the system state directory and etcd
+	// are placed on logical volumes on a single device
+	AutoPartition bool
+}
+
+func (r *Config) checkAndSetDefaults() error {
+	if r.EtcdDevice != "" && !r.Etcd {
+		return trace.BadParameter("etcd device can only be used on master nodes")
+	}
+	return nil
+}
+
+// Plan computes the disk layout for the specified configuration.
+// Explicitly specified devices are validated to be unused and large enough
+// and, if automatic partitioning is enabled, the remaining roles are placed
+// on unused devices
+func Plan(config Config) (*Layout, error) {
+	if err := config.checkAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	planner := &planner{
+		Config: config,
+		used:   make(map[string]Role),
+	}
+	for _, role := range config.roles() {
+		name := config.device(role)
+		if name == "" {
+			continue
+		}
+		if err := planner.placeDevice(role, storage.DeviceName(name)); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	if config.AutoPartition {
+		planner.autoPartition()
+	}
+	return &planner.layout, nil
+}
+
+// Layout describes the planned placement of node data on devices
+type Layout struct {
+	// Placements lists placements for roles assigned a device.
+	// Roles without a placement use the root filesystem
+	Placements []Placement `json:"placements,omitempty"`
+}
+
+// Placement describes the device for a particular role
+type Placement struct {
+	// Role identifies the data placed on the device
+	Role Role `json:"role"`
+	// Device is the block device
+	Device storage.Device `json:"device"`
+	// Volume optionally names the logical volume created on the device
+	// in the volume group defaults.DiskLayoutVolumeGroup
+	Volume string `json:"volume,omitempty"`
+	// SizeMB is the size of the logical volume in MB.
+	// Zero means the remaining free space in the volume group
+	SizeMB uint64 `json:"size_mb,omitempty"`
+}
+
+// Path returns the path of the device to use for this placement
+func (r Placement) Path() string {
+	if r.Volume != "" {
+		return filepath.Join("/dev", defaults.DiskLayoutVolumeGroup, r.Volume)
+	}
+	return r.Device.Name.Path()
+}
+
+// DevicePath returns the path of the device planned for the specified role
+// or an empty string if the role is placed on the root filesystem
+func (r Layout) DevicePath(role Role) string {
+	for _, placement := range r.Placements {
+		if placement.Role == role {
+			return placement.Path()
+		}
+	}
+	return ""
+}
+
+// Volumes returns the placements that require a logical volume
+func (r Layout) Volumes() (result []Placement) {
+	for _, placement := range r.Placements {
+		if placement.Volume != "" {
+			result = append(result, placement)
+		}
+	}
+	return result
+}
+
+// String formats this layout as a table
+func (r Layout) String() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 8, 1, '\t', 0)
+	for _, role := range []Role{RoleSystem, RoleDocker, RoleEtcd} {
+		placement := r.placement(role)
+		switch {
+		case placement == nil:
+			fmt.Fprintf(w, "%v:\troot filesystem\n", role)
+		case placement.Volume != "" && placement.SizeMB == 0:
+			fmt.Fprintf(w, "%v:\t%v (logical volume on %v, remaining space)\n",
+				role, placement.Path(), placement.Device.Name.Path())
+		case placement.Volume != "":
+			fmt.Fprintf(w, "%v:\t%v (logical volume on %v, %v)\n",
+				role, placement.Path(), placement.Device.Name.Path(),
+				humanize.IBytes(placement.SizeMB<<20))
+		default:
+			fmt.Fprintf(w, "%v:\t%v (%v)\n", role, placement.Path(),
+				humanize.IBytes(placement.Device.SizeMB<<20))
+		}
+	}
+	w.Flush()
+	return buf.String()
+}
+
+func (r Layout) placement(role Role) *Placement {
+	for _, placement := range r.Placements {
+		if placement.Role == role {
+			return &placement
+		}
+	}
+	return nil
+}
+
+// placeDevice places the role on the explicitly specified device
+func (r *planner) placeDevice(role Role, name storage.DeviceName) error {
+	device := storage.Devices(r.Devices).GetByName(name)
+	if device.Name.Path() == "" {
+		return trace.BadParameter("%v device %v is not an unused block device on this node",
+			role, name.Path())
+	}
+	if other, ok := r.used[device.Name.Path()]; ok {
+		return trace.BadParameter("device %v is specified for both %v and %v",
+			device.Name.Path(), other, role)
+	}
+	if min := r.minSizeMB(role); device.SizeMB < min {
+		return trace.BadParameter("%v device %v has %v which is less than required %v",
+			role, device.Name.Path(), humanize.IBytes(device.SizeMB<<20), humanize.IBytes(min<<20))
+	}
+	r.place(Placement{Role: role, Device: device})
+	return nil
+}
+
+// autoPartition places the roles without a device on the unused devices
+func (r *planner) autoPartition() {
+	free := r.freeDevices()
+	var pending []Role
+	for _, role := range r.roles() {
+		if r.layout.placement(role) == nil {
+			pending = append(pending, role)
+		}
+	}
+	for len(pending) != 0 {
+		role := pending[0]
+		// With a single device left for the system state directory and etcd,
+		// place both on logical volumes
+		etcdSizeMB := r.minSizeMB(RoleEtcd)
+		if len(pending) == 2 && role == RoleSystem && len(free) == 1 &&
+			free[0].SizeMB >= etcdSizeMB+r.minSizeMB(RoleSystem) {
+			device := free[0]
+			r.place(Placement{Role: RoleEtcd, Device: device, Volume: string(RoleEtcd), SizeMB: etcdSizeMB})
+			r.place(Placement{Role: RoleSystem, Device: device, Volume: string(RoleSystem)})
+			return
+		}
+		pending = pending[1:]
+		device, ok := r.selectDevice(role, free)
+		if !ok {
+			continue
+		}
+		r.place(Placement{Role: role, Device: device})
+		free = r.freeDevices()
+	}
+}
+
+// selectDevice selects a device for the specified role among free devices
+// sorted by size in descending order. etcd gets the smallest suitable device
+// while other roles get the largest one
+func (r *planner) selectDevice(role Role, free []storage.Device) (device storage.Device, ok bool) {
+	min := r.minSizeMB(role)
+	var candidates []storage.Device
+	for _, device := range free {
+		if device.SizeMB >= min {
+			candidates = append(candidates, device)
+		}
+	}
+	if len(candidates) == 0 {
+		return storage.Device{}, false
+	}
+	if role == RoleEtcd {
+		return candidates[len(candidates)-1], true
+	}
+	return candidates[0], true
+}
+
+// freeDevices returns the devices not used by any placement
+// sorted by size in descending order
+func (r *planner) freeDevices() (free []storage.Device) {
+	for _, device := range r.Devices {
+		if _, ok := r.used[device.Name.Path()]; !ok {
+			free = append(free, device)
+		}
+	}
+	sort.SliceStable(free, func(i, j int) bool {
+		return free[i].SizeMB > free[j].SizeMB
+	})
+	return free
+}
+
+func (r *planner) place(placement Placement) {
+	r.used[placement.Device.Name.Path()] = placement.Role
+	r.layout.Placements = append(r.layout.Placements, placement)
+}
+
+func (r *planner) minSizeMB(role Role) uint64 {
+	switch role {
+	case RoleDocker:
+		return r.DockerCapacity.Bytes() >> 20
+	case RoleEtcd:
+		return etcdCapacity.Bytes() >> 20
+	default:
+		return systemCapacity.Bytes() >> 20
+	}
+}
+
+// roles returns the roles to plan in the order of priority
+func (r Config) roles() (roles []Role) {
+	if r.Devicemapper || r.DockerDevice != "" {
+		roles = append(roles, RoleDocker)
+	}
+	roles = append(roles, RoleSystem)
+	if r.Etcd {
+		roles = append(roles, RoleEtcd)
+	}
+	return roles
+}
+
+func (r Config) device(role Role) string {
+	switch role {
+	case RoleDocker:
+		return r.DockerDevice
+	case RoleEtcd:
+		return r.EtcdDevice
+	default:
+		return r.SystemDevice
+	}
+}
+
+type planner struct {
+	Config
+	layout Layout
+	// used maps device path to the role placed on it
+	used map[string]Role
+}
+
+var (
+	systemCapacity = utils.MustParseCapacity(defaults.SystemDeviceCapacity)
+	etcdCapacity   = utils.MustParseCapacity(defaults.EtcdDeviceCapacity)
+)
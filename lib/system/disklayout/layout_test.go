@@ -0,0 +1,122 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disklayout
+
+import (
+	"testing"
+
+	"github.com/gravitational/gravity/lib/compare"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+	"gopkg.in/check.v1"
+)
+
+func TestLayout(t *testing.T) { check.TestingT(t) }
+
+type LayoutSuite struct{}
+
+var _ = check.Suite(&LayoutSuite{})
+
+func (s *LayoutSuite) TestValidatesExplicitDevices(c *check.C) {
+	devices := []storage.Device{disk("sdb", 20<<10), disk("sdc", 1<<10)}
+	var testCases = []struct {
+		config  Config
+		comment string
+	}{
+		{
+			config:  Config{Devices: devices, SystemDevice: "/dev/sdd"},
+			comment: "device is not available",
+		},
+		{
+			config:  Config{Devices: devices, SystemDevice: "/dev/sdc"},
+			comment: "device is too small",
+		},
+		{
+			config:  Config{Devices: devices, SystemDevice: "/dev/sdb", EtcdDevice: "/dev/sdb", Etcd: true},
+			comment: "device is used twice",
+		},
+		{
+			config:  Config{Devices: devices, EtcdDevice: "/dev/sdb"},
+			comment: "etcd device on a regular node",
+		},
+	}
+	for _, tc := range testCases {
+		_, err := Plan(tc.config)
+		c.Assert(err, check.NotNil, check.Commentf(tc.comment))
+		c.Assert(trace.IsBadParameter(err), check.Equals, true, check.Commentf(tc.comment))
+	}
+}
+
+func (s *LayoutSuite) TestPlacesRolesOnSeparateDevices(c *check.C) {
+	layout, err := Plan(Config{
+		Devices:        []storage.Device{disk("sdb", 10<<10), disk("sdc", 50<<10), disk("sdd", 100<<10), disk("sde", 20<<10)},
+		Devicemapper:   true,
+		DockerCapacity: utils.MustParseCapacity("10GB"),
+		Etcd:           true,
+		AutoPartition:  true,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(layout, compare.DeepEquals, &Layout{
+		Placements: []Placement{
+			{Role: RoleDocker, Device: disk("sdd", 100<<10)},
+			{Role: RoleSystem, Device: disk("sdc", 50<<10)},
+			{Role: RoleEtcd, Device: disk("sdb", 10<<10)},
+		},
+	})
+}
+
+func (s *LayoutSuite) TestPlacesSystemAndEtcdOnVolumes(c *check.C) {
+	layout, err := Plan(Config{
+		Devices:       []storage.Device{disk("sdb", 50<<10)},
+		Etcd:          true,
+		AutoPartition: true,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(layout, compare.DeepEquals, &Layout{
+		Placements: []Placement{
+			{Role: RoleEtcd, Device: disk("sdb", 50<<10), Volume: "etcd", SizeMB: etcdCapacity.Bytes() >> 20},
+			{Role: RoleSystem, Device: disk("sdb", 50<<10), Volume: "system"},
+		},
+	})
+	c.Assert(layout.DevicePath(RoleSystem), check.Equals, "/dev/gravity/system")
+	c.Assert(layout.DevicePath(RoleEtcd), check.Equals, "/dev/gravity/etcd")
+	c.Assert(layout.DevicePath(RoleDocker), check.Equals, "")
+}
+
+func (s *LayoutSuite) TestKeepsExplicitDevices(c *check.C) {
+	layout, err := Plan(Config{
+		Devices:       []storage.Device{disk("sdb", 50<<10), disk("sdc", 100<<10)},
+		SystemDevice:  "sdb",
+		AutoPartition: true,
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(layout, compare.DeepEquals, &Layout{
+		Placements: []Placement{
+			{Role: RoleSystem, Device: disk("sdb", 50<<10)},
+		},
+	})
+}
+
+func disk(name string, sizeMB uint64) storage.Device {
+	return storage.Device{
+		Name:   storage.DeviceName("/dev/" + name),
+		Type:   storage.DeviceDisk,
+		SizeMB: sizeMB,
+	}
+}
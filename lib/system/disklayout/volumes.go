@@ -0,0 +1,113 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disklayout
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// CreateVolumes creates the logical volumes planned in the specified layout.
+// Volumes are created in the order of placements, a volume without size
+// takes the remaining space in the volume group
+func CreateVolumes(layout Layout, out io.Writer, logger log.FieldLogger) error {
+	volumes := layout.Volumes()
+	if len(volumes) == 0 {
+		return nil
+	}
+	existing, err := volumeGroupDisk(logger)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if existing != "" {
+		logger.WithField("disk", existing).Info("Volume group already exists.")
+		return nil
+	}
+	disk := volumes[0].Device.Name.Path()
+	logger.WithField("disk", disk).Info("Create volume group.")
+	if err := utils.ExecL(exec.Command("pvcreate", disk), out, logger); err != nil {
+		return trace.Wrap(err, "failed to create physical volume on disk %v", disk)
+	}
+	err = utils.ExecL(exec.Command("vgcreate", defaults.DiskLayoutVolumeGroup, disk), out, logger)
+	if err != nil {
+		return trace.Wrap(err, "failed to create volume group on disk %v", disk)
+	}
+	for _, volume := range volumes {
+		size := []string{"-l", "100%FREE"}
+		if volume.SizeMB != 0 {
+			size = []string{"-L", fmt.Sprintf("%vM", volume.SizeMB)}
+		}
+		args := append([]string{"--wipesignatures", "y", "-n", volume.Volume}, size...)
+		args = append(args, defaults.DiskLayoutVolumeGroup)
+		logger.WithField("volume", volume.Path()).Info("Create logical volume.")
+		if err := utils.ExecL(exec.Command("lvcreate", args...), out, logger); err != nil {
+			return trace.Wrap(err, "failed to create logical volume %v", volume.Path())
+		}
+	}
+	return nil
+}
+
+// RemoveVolumes removes the volume group created by CreateVolumes
+// along with its physical volume if the volume group exists
+func RemoveVolumes(out io.Writer, logger log.FieldLogger) error {
+	disk, err := volumeGroupDisk(logger)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if disk == "" {
+		return nil
+	}
+	logger.WithField("disk", disk).Info("Remove volume group.")
+	err = utils.ExecL(exec.Command("vgremove", "-f", defaults.DiskLayoutVolumeGroup), out, logger)
+	if err != nil {
+		return trace.Wrap(err, "failed to remove volume group %v", defaults.DiskLayoutVolumeGroup)
+	}
+	if err := utils.ExecL(exec.Command("pvremove", disk), out, logger); err != nil {
+		return trace.Wrap(err, "failed to remove physical volume on disk %v", disk)
+	}
+	return nil
+}
+
+// HasVolumes returns whether the volume group created by CreateVolumes exists
+func HasVolumes(logger log.FieldLogger) (bool, error) {
+	disk, err := volumeGroupDisk(logger)
+	if err != nil {
+		return false, trace.Wrap(err)
+	}
+	return disk != "", nil
+}
+
+// volumeGroupDisk returns the disk of the volume group created by CreateVolumes
+// or an empty string if the volume group does not exist
+func volumeGroupDisk(logger log.FieldLogger) (disk string, err error) {
+	var out bytes.Buffer
+	cmd := exec.Command("vgs", "-o", "pv_name", "--noheadings", "-S",
+		fmt.Sprintf("vg_name=%v", defaults.DiskLayoutVolumeGroup))
+	if err := utils.ExecL(cmd, &out, logger); err != nil {
+		return "", trace.Wrap(err, "failed to query volume group %v", defaults.DiskLayoutVolumeGroup)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environ
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/devicemapper"
+	"github.com/gravitational/gravity/lib/state"
+	"github.com/gravitational/gravity/lib/systemservice"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// DetectPreviousInstallation returns the list of artifacts left on the host
+// by a previous cluster installation: package services, state directories
+// and the docker devicemapper volume group.
+// Returns an empty list if the host is clean
+func DetectPreviousInstallation(logger log.FieldLogger) (remnants []string, err error) {
+	svm, err := systemservice.New()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	stateDir, err := state.GetStateDir()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return detectRemnants(remnantsConfig{
+		services:            svm,
+		locatorPaths:        state.StateLocatorPaths,
+		stateDir:            stateDir,
+		queryPhysicalVolume: devicemapper.QueryPhysicalVolume,
+		logger:              logger,
+	})
+}
+
+func detectRemnants(config remnantsConfig) (remnants []string, err error) {
+	services, err := config.services.ListPackageServices()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, service := range services {
+		remnants = append(remnants, fmt.Sprintf("system service %v", service.Package))
+	}
+	for _, path := range config.locatorPaths {
+		if ok, _ := utils.IsFile(path); ok {
+			remnants = append(remnants, fmt.Sprintf("state directory pointer %v", path))
+		}
+	}
+	for _, dir := range []string{defaults.PlanetDir, defaults.SiteDir} {
+		path := filepath.Join(config.stateDir, dir)
+		if ok, _ := utils.IsDirectory(path); ok {
+			remnants = append(remnants, fmt.Sprintf("state directory %v", path))
+		}
+	}
+	disk, err := config.queryPhysicalVolume(config.logger)
+	if err != nil {
+		// LVM tools might not be available on the host
+		config.logger.WithError(err).Debug("Failed to query devicemapper physical volume.")
+	}
+	if disk != "" {
+		remnants = append(remnants, fmt.Sprintf("devicemapper volume group on %v", disk))
+	}
+	return remnants, nil
+}
+
+// remnantsConfig defines where to look for the remnants of a previous installation
+type remnantsConfig struct {
+	// services lists the installed package services
+	services packageServices
+	// locatorPaths lists the paths of the state directory pointer files
+	locatorPaths []string
+	// stateDir is the state directory
+	stateDir string
+	// queryPhysicalVolume returns the disk of the devicemapper volume group
+	queryPhysicalVolume func(log.FieldLogger) (string, error)
+	logger              log.FieldLogger
+}
+
+// packageServices lists the installed package services
+type packageServices interface {
+	// ListPackageServices lists installed package services
+	ListPackageServices() ([]systemservice.PackageServiceStatus, error)
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environ
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/systemservice"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/check.v1"
+)
+
+func TestEnviron(t *testing.T) { check.TestingT(t) }
+
+type RemnantsSuite struct {
+	dir string
+}
+
+var _ = check.Suite(&RemnantsSuite{})
+
+func (s *RemnantsSuite) SetUpTest(c *check.C) {
+	s.dir = c.MkDir()
+}
+
+func (s *RemnantsSuite) TestCleanHost(c *check.C) {
+	remnants, err := detectRemnants(s.config(nil, nil))
+	c.Assert(err, check.IsNil)
+	c.Assert(remnants, check.HasLen, 0)
+}
+
+func (s *RemnantsSuite) TestDetectsRemnants(c *check.C) {
+	config := s.config(
+		[]systemservice.PackageServiceStatus{{
+			Package: loc.MustParseLocator("gravitational.io/planet:0.0.1"),
+		}},
+		func(log.FieldLogger) (string, error) {
+			return "/dev/sdb", nil
+		})
+	locator := filepath.Join(s.dir, "gravity.state")
+	c.Assert(ioutil.WriteFile(locator, nil, defaults.SharedReadMask), check.IsNil)
+	config.locatorPaths = []string{locator, filepath.Join(s.dir, "missing")}
+	stateDir := filepath.Join(s.dir, "state")
+	c.Assert(os.MkdirAll(filepath.Join(stateDir, defaults.PlanetDir), defaults.SharedDirMask), check.IsNil)
+	config.stateDir = stateDir
+
+	remnants, err := detectRemnants(config)
+	c.Assert(err, check.IsNil)
+	c.Assert(remnants, check.DeepEquals, []string{
+		"system service gravitational.io/planet:0.0.1",
+		"state directory pointer " + locator,
+		"state directory " + filepath.Join(stateDir, defaults.PlanetDir),
+		"devicemapper volume group on /dev/sdb",
+	})
+}
+
+func (s *RemnantsSuite) TestIgnoresMissingLVMTools(c *check.C) {
+	remnants, err := detectRemnants(s.config(nil, func(log.FieldLogger) (string, error) {
+		return "", trace.NotFound("pvs not found")
+	}))
+	c.Assert(err, check.IsNil)
+	c.Assert(remnants, check.HasLen, 0)
+}
+
+func (s *RemnantsSuite) config(services []systemservice.PackageServiceStatus, queryPhysicalVolume func(log.FieldLogger) (string, error)) remnantsConfig {
+	if queryPhysicalVolume == nil {
+		queryPhysicalVolume = func(log.FieldLogger) (string, error) {
+			return "", nil
+		}
+	}
+	return remnantsConfig{
+		services:            testServices(services),
+		stateDir:            s.dir,
+		queryPhysicalVolume: queryPhysicalVolume,
+		logger:              log.WithField("test", "remnants"),
+	}
+}
+
+type testServices []systemservice.PackageServiceStatus
+
+func (r testServices) ListPackageServices() ([]systemservice.PackageServiceStatus, error) {
+	return r, nil
+}
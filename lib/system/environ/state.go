@@ -26,6 +26,7 @@ import (
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/system"
+	"github.com/gravitational/gravity/lib/system/disklayout"
 	"github.com/gravitational/gravity/lib/system/mount"
 	"github.com/gravitational/gravity/lib/systemservice"
 	"github.com/gravitational/gravity/lib/utils"
@@ -54,16 +55,52 @@ func ConfigureStateDirectory(stateDir, devicePath string) (err error) {
 		return nil
 	}
 
-	_, err = os.Stat(devicePath)
+	// Even if the directory exists, mount it on the specified device.
+	// If this is not possible, the operation will fail as expected.
+	err = mountDevice(devicePath, stateDir, defaults.GravityMountService)
+	if err != nil {
+		return trace.Wrap(err, "failed to mount %q on %q", stateDir, devicePath)
+	}
+
+	return nil
+}
+
+// ConfigureDiskLayout creates the logical volumes planned in layout and sets up
+// the state directory stateDir and the etcd data directory on the planned devices
+func ConfigureDiskLayout(stateDir string, layout disklayout.Layout, logger log.FieldLogger) error {
+	var out bytes.Buffer
+	if err := disklayout.CreateVolumes(layout, &out, logger); err != nil {
+		return trace.Wrap(err)
+	}
+	err := ConfigureStateDirectory(stateDir, layout.DevicePath(disklayout.RoleSystem))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	devicePath := layout.DevicePath(disklayout.RoleEtcd)
+	if devicePath == "" {
+		return nil
+	}
+	etcdDir := filepath.Join(stateDir, defaults.PlanetDir, defaults.EtcdDir)
+	if err := os.MkdirAll(etcdDir, defaults.SharedDirMask); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	err = mountDevice(devicePath, etcdDir, defaults.GravityEtcdMountService)
+	if err != nil {
+		return trace.Wrap(err, "failed to mount %q on %q", etcdDir, devicePath)
+	}
+	return nil
+}
+
+// mountDevice formats the device at devicePath unless it already has a file system
+// and mounts it on dir with the mount service named service
+func mountDevice(devicePath, dir, service string) error {
+	_, err := os.Stat(devicePath)
 	if err != nil {
 		return trace.Wrap(trace.ConvertSystemError(err),
 			"failed to query device at %q", devicePath)
 	}
 
-	// Even if the directory exists, mount it on the specified device.
-	// If this is not possible, the operation will fail as expected.
-	var filesystem string
-	filesystem, err = formatDevice(devicePath)
+	filesystem, err := formatDevice(devicePath)
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -75,16 +112,11 @@ func ConfigureStateDirectory(stateDir, devicePath string) (err error) {
 
 	config := mount.ServiceConfig{
 		What:       storage.DeviceName(devicePath),
-		Where:      stateDir,
+		Where:      dir,
 		Filesystem: filesystem,
 		Options:    []string{"defaults"},
 	}
-	err = mount.MountService(config, defaults.GravityMountService, services)
-	if err != nil {
-		return trace.Wrap(err, "failed to mount %q on %q", stateDir, devicePath)
-	}
-
-	return nil
+	return trace.Wrap(mount.MountService(config, service, services))
 }
 
 // GetServiceName returns the name of the service configured in the specified state directory stateDir
@@ -28,6 +28,9 @@ import (
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/devicemapper"
 	"github.com/gravitational/gravity/lib/state"
+	"github.com/gravitational/gravity/lib/system/disklayout"
+	"github.com/gravitational/gravity/lib/system/mount"
+	"github.com/gravitational/gravity/lib/system/selinux"
 	"github.com/gravitational/gravity/lib/systemservice"
 	"github.com/gravitational/gravity/lib/utils"
 
@@ -48,9 +51,15 @@ func UninstallSystem(printer utils.Printer, logger log.FieldLogger) (err error)
 	if err := uninstallPackageServices(svm, printer, logger); err != nil {
 		errors = append(errors, err)
 	}
+	if err := removeDiskLayout(svm, printer, logger); err != nil {
+		errors = append(errors, err)
+	}
 	if err := removeInterfaces(printer); err != nil {
 		errors = append(errors, err)
 	}
+	if err := unloadSELinuxPolicy(printer, logger); err != nil {
+		errors = append(errors, err)
+	}
 	pathsToRemove := getPathsToRemove()
 	if err := removePaths(printer, logger, pathsToRemove...); err != nil {
 		errors = append(errors, err)
@@ -178,6 +187,59 @@ func unmountDevicemapper(printer utils.Printer, logger log.FieldLogger) error {
 	return nil
 }
 
+// unloadSELinuxPolicy removes the gravity SELinux policy module
+// if SELinux is enabled
+func unloadSELinuxPolicy(printer utils.Printer, logger log.FieldLogger) error {
+	mode, err := selinux.GetMode()
+	if err != nil || !mode.IsEnabled() {
+		return trace.Wrap(err)
+	}
+	stateDir, err := state.GetStateDir()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	printer.PrintStep("Removing SELinux policy")
+	return trace.Wrap(selinux.Unload(selinux.BootstrapConfig{
+		StateDir:    stateDir,
+		FieldLogger: logger,
+	}))
+}
+
+// removeDiskLayout unmounts the etcd device and removes the logical volumes
+// created by the disk layout planner
+func removeDiskLayout(svm systemservice.ServiceManager, printer utils.Printer, logger log.FieldLogger) error {
+	if err := uninstallMountService(svm, defaults.GravityEtcdMountService, printer); err != nil {
+		return trace.Wrap(err)
+	}
+	exists, err := disklayout.HasVolumes(logger)
+	if err != nil || !exists {
+		return trace.Wrap(err)
+	}
+	// The state directory is on a logical volume, unmount it before removing the volume
+	if err := uninstallMountService(svm, defaults.GravityMountService, printer); err != nil {
+		return trace.Wrap(err)
+	}
+	printer.PrintStep("Removing logical volumes")
+	var out bytes.Buffer
+	if err := disklayout.RemoveVolumes(&out, logger); err != nil {
+		log.WithFields(log.Fields{
+			log.ErrorKey: err,
+			"stdout":     out.String(),
+		}).Warn("Failed to remove logical volumes.")
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+func uninstallMountService(svm systemservice.ServiceManager, service string, printer utils.Printer) error {
+	status, err := svm.StatusService(service)
+	if err != nil || (status != systemservice.ServiceStatusActive && status != systemservice.ServiceStatusFailed) {
+		return nil
+	}
+	printer.PrintStep("Uninstalling mount service %v", service)
+	return trace.Wrap(mount.UnmountService(service, svm))
+}
+
 func removePaths(printer utils.Printer, logger log.FieldLogger, paths ...string) error {
 	var errors []error
 	// remove all files and directories gravity might have created on the system
@@ -0,0 +1,56 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package selinux
+
+// policy is the gravity SELinux policy module in the Common Intermediate
+// Language (CIL) which semodule installs without a separate compilation step.
+//
+// The module is based on the container types from the container-selinux
+// policy: gravity runs in the container runtime domain, the state directory
+// is labeled as container runtime state and the planet rootfs and data
+// directories as container content.
+//
+// The file contexts refer to the default state directory, a custom state
+// directory is labeled with an equivalence rule
+const policy = `; Gravity SELinux policy module
+(typeattributeset cil_gen_require container_runtime_t)
+(typeattributeset cil_gen_require container_runtime_exec_t)
+(typeattributeset cil_gen_require container_var_lib_t)
+(typeattributeset cil_gen_require container_file_t)
+(typeattributeset cil_gen_require container_log_t)
+
+; gravity binary starts the planet container and runs the agents
+(filecon "/usr/bin/gravity" file (system_u object_r container_runtime_exec_t ((s0) (s0))))
+(filecon "/writable/bin/gravity" file (system_u object_r container_runtime_exec_t ((s0) (s0))))
+
+; state directory
+(filecon "/var/lib/gravity(/.*)?" any (system_u object_r container_var_lib_t ((s0) (s0))))
+
+; unpacked packages include the planet rootfs
+(filecon "/var/lib/gravity/local/packages/unpacked(/.*)?" any (system_u object_r container_file_t ((s0) (s0))))
+(filecon "/var/lib/gravity/site/packages/unpacked(/.*)?" any (system_u object_r container_file_t ((s0) (s0))))
+
+; planet state, etcd, docker and registry data mounted into the container
+(filecon "/var/lib/gravity/planet(/.*)?" any (system_u object_r container_file_t ((s0) (s0))))
+(filecon "/var/lib/gravity/planet/log(/.*)?" any (system_u object_r container_log_t ((s0) (s0))))
+
+; the container runtime manages the content of the state directory
+(allow container_runtime_t container_var_lib_t (dir (create add_name remove_name write search read open getattr setattr rmdir reparent rename)))
+(allow container_runtime_t container_var_lib_t (file (create open read write append getattr setattr unlink rename lock ioctl)))
+(allow container_runtime_t container_file_t (dir (create add_name remove_name write search read open getattr setattr rmdir reparent rename relabelfrom relabelto)))
+(allow container_runtime_t container_file_t (file (create open read write append getattr setattr unlink rename lock ioctl execute execute_no_trans relabelfrom relabelto)))
+`
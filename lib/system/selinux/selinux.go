@@ -0,0 +1,235 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package selinux installs the gravity SELinux policy module and labels
+// the gravity state directories so the cluster can run on hosts with SELinux
+// in enforcing mode
+package selinux
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// Mode describes the SELinux mode of the host
+type Mode string
+
+const (
+	// ModeEnforcing means the SELinux policy is enforced
+	ModeEnforcing Mode = "enforcing"
+	// ModePermissive means the SELinux policy violations are only logged
+	ModePermissive Mode = "permissive"
+	// ModeDisabled means SELinux is disabled
+	ModeDisabled Mode = "disabled"
+)
+
+// IsEnabled returns true if SELinux is enabled, i.e. either
+// in enforcing or permissive mode
+func (r Mode) IsEnabled() bool {
+	return r == ModeEnforcing || r == ModePermissive
+}
+
+// GetMode returns the current SELinux mode of the host
+func GetMode() (Mode, error) {
+	return getMode(selinuxfsPath)
+}
+
+func getMode(selinuxfs string) (Mode, error) {
+	data, err := ioutil.ReadFile(filepath.Join(selinuxfs, "enforce"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ModeDisabled, nil
+		}
+		return "", trace.ConvertSystemError(err)
+	}
+	switch strings.TrimSpace(string(data)) {
+	case "1":
+		return ModeEnforcing, nil
+	case "0":
+		return ModePermissive, nil
+	}
+	return "", trace.BadParameter("unexpected SELinux mode %q", data)
+}
+
+// BootstrapConfig describes the configuration of the SELinux bootstrap
+type BootstrapConfig struct {
+	// StateDir is the gravity state directory
+	StateDir string
+	// Out optionally receives the output of the executed commands
+	Out io.Writer
+	// FieldLogger is used for logging
+	log.FieldLogger
+}
+
+func (r *BootstrapConfig) checkAndSetDefaults() error {
+	if r.StateDir == "" {
+		return trace.BadParameter("state directory is required")
+	}
+	if r.Out == nil {
+		r.Out = ioutil.Discard
+	}
+	if r.FieldLogger == nil {
+		r.FieldLogger = log.WithField(trace.Component, "selinux")
+	}
+	return nil
+}
+
+// Bootstrap installs the gravity policy module and labels the state directory
+// and the gravity binaries according to the policy.
+// A custom state directory is labeled the same way as the default one
+func Bootstrap(config BootstrapConfig) error {
+	if err := config.checkAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	dir, err := ioutil.TempDir("", "selinux")
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, PolicyModule+".cil")
+	if err := ioutil.WriteFile(path, []byte(policy), defaults.SharedReadMask); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	config.Info("Install SELinux policy module.")
+	if err := utils.ExecL(exec.Command("semodule", "-i", path), config.Out, config.FieldLogger); err != nil {
+		return trace.Wrap(err, "failed to install SELinux policy module %v", PolicyModule)
+	}
+	if isCustomStateDir(config.StateDir) {
+		if err := addStateDirEquivalence(config); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	paths := []string{config.StateDir}
+	for _, path := range []string{defaults.GravityBin, defaults.GravityBinAlternate} {
+		if _, err := os.Stat(path); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	args := append([]string{"-R"}, paths...)
+	if err := utils.ExecL(exec.Command("restorecon", args...), config.Out, config.FieldLogger); err != nil {
+		return trace.Wrap(err, "failed to label %v", strings.Join(paths, ", "))
+	}
+	return nil
+}
+
+// Unload removes the gravity policy module and the file context
+// equivalence rule for a custom state directory
+func Unload(config BootstrapConfig) error {
+	if err := config.checkAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	modules, err := ListModules()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if !utils.StringInSlice(modules, PolicyModule) {
+		return nil
+	}
+	var errors []error
+	if isCustomStateDir(config.StateDir) {
+		err := utils.ExecL(exec.Command("semanage", "fcontext", "-d", config.StateDir), config.Out, config.FieldLogger)
+		if err != nil {
+			errors = append(errors, trace.Wrap(err, "failed to remove file context equivalence for %v", config.StateDir))
+		}
+	}
+	config.Info("Remove SELinux policy module.")
+	if err := utils.ExecL(exec.Command("semodule", "-r", PolicyModule), config.Out, config.FieldLogger); err != nil {
+		errors = append(errors, trace.Wrap(err, "failed to remove SELinux policy module %v", PolicyModule))
+	}
+	return trace.NewAggregate(errors...)
+}
+
+// ListModules returns the names of the installed SELinux policy modules
+func ListModules() ([]string, error) {
+	var out bytes.Buffer
+	cmd := exec.Command("semodule", "-l")
+	if err := utils.Exec(cmd, &out); err != nil {
+		return nil, trace.Wrap(err, "failed to list SELinux policy modules: %s", out.String())
+	}
+	return parseModules(&out), nil
+}
+
+// parseModules parses the output of 'semodule -l'. Depending on the version,
+// each line is either the module name or the module name followed
+// by the module version
+func parseModules(r io.Reader) (modules []string) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 0 {
+			modules = append(modules, fields[0])
+		}
+	}
+	return modules
+}
+
+// addStateDirEquivalence labels the custom state directory
+// the same way as the default state directory
+func addStateDirEquivalence(config BootstrapConfig) error {
+	for _, flag := range []string{"-a", "-m"} {
+		err := utils.ExecL(exec.Command("semanage", "fcontext", flag, "-e", defaults.GravityDir, config.StateDir),
+			config.Out, config.FieldLogger)
+		if err == nil {
+			return nil
+		}
+		// the rule exists if the directory has been labeled before,
+		// in which case it is modified instead
+	}
+	return trace.BadParameter("failed to add file context equivalence for %v", config.StateDir)
+}
+
+// isCustomStateDir returns true if the specified state directory
+// is not the default one
+func isCustomStateDir(stateDir string) bool {
+	return filepath.Clean(stateDir) != defaults.GravityDir
+}
+
+// RequiredModules lists the SELinux policy modules the gravity policy depends on
+var RequiredModules = []string{
+	// container module is provided by the container-selinux package and
+	// defines the container types the gravity policy is based on
+	"container",
+}
+
+// RequiredTools returns the SELinux management tools that are required
+// to install the policy for the specified state directory
+func RequiredTools(stateDir string) []string {
+	tools := []string{"semodule", "restorecon"}
+	if isCustomStateDir(stateDir) {
+		tools = append(tools, "semanage")
+	}
+	return tools
+}
+
+const (
+	// PolicyModule is the name of the gravity SELinux policy module
+	PolicyModule = "gravity"
+
+	// selinuxfsPath is the mount point of the SELinux filesystem
+	selinuxfsPath = "/sys/fs/selinux"
+)
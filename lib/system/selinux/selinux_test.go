@@ -0,0 +1,61 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package selinux
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/check.v1"
+)
+
+func TestSELinux(t *testing.T) { check.TestingT(t) }
+
+type SELinuxSuite struct{}
+
+var _ = check.Suite(&SELinuxSuite{})
+
+func (s *SELinuxSuite) TestGetsMode(c *check.C) {
+	dir := c.MkDir()
+	mode, err := getMode(filepath.Join(dir, "missing"))
+	c.Assert(err, check.IsNil)
+	c.Assert(mode, check.Equals, ModeDisabled)
+	c.Assert(mode.IsEnabled(), check.Equals, false)
+
+	for value, expected := range map[string]Mode{"1": ModeEnforcing, "0\n": ModePermissive} {
+		err = ioutil.WriteFile(filepath.Join(dir, "enforce"), []byte(value), os.ModePerm)
+		c.Assert(err, check.IsNil)
+		mode, err = getMode(dir)
+		c.Assert(err, check.IsNil)
+		c.Assert(mode, check.Equals, expected)
+		c.Assert(mode.IsEnabled(), check.Equals, true)
+	}
+}
+
+func (s *SELinuxSuite) TestParsesModules(c *check.C) {
+	// older versions of semodule print the module version
+	modules := parseModules(strings.NewReader("container\t2.55.0\ngravity\t\n\nsandbox\n"))
+	c.Assert(modules, check.DeepEquals, []string{"container", "gravity", "sandbox"})
+}
+
+func (s *SELinuxSuite) TestRequiredTools(c *check.C) {
+	c.Assert(RequiredTools("/var/lib/gravity/"), check.DeepEquals, []string{"semodule", "restorecon"})
+	c.Assert(RequiredTools("/data/gravity"), check.DeepEquals, []string{"semodule", "restorecon", "semanage"})
+}
@@ -20,6 +20,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strconv"
 
 	"github.com/gravitational/gravity/lib/devicemapper"
@@ -145,6 +146,7 @@ func collect() (*storage.SystemSpecV2, error) {
 		return nil, trace.Wrap(err, "failed to query operating system details")
 	}
 	info.OS = storage.OSInfo(*osInfo)
+	info.Arch = runtime.GOARCH
 
 	info.Processes, err = queryProcesses()
 	if err != nil {
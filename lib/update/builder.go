@@ -90,6 +90,18 @@ func RootPhase(sub Phase) Phase {
 	return sub
 }
 
+// Sequential makes the specified phases root phases
+// that are executed one after another
+func Sequential(phases ...Phase) Phases {
+	for i := range phases {
+		phases[i] = RootPhase(phases[i])
+		if i > 0 {
+			phases[i].Require(phases[i-1])
+		}
+	}
+	return phases
+}
+
 // GetID returns the ID of the phase.
 // implements PhaseIder
 func (r PhaseRef) GetID() string {
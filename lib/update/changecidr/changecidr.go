@@ -0,0 +1,115 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package changecidr implements the operation to change
+// the pod and service subnets of an installed cluster
+package changecidr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/update"
+	"github.com/gravitational/gravity/lib/update/changecidr/phases"
+	"github.com/gravitational/gravity/lib/update/internal/rollingupdate"
+	libphase "github.com/gravitational/gravity/lib/update/internal/rollingupdate/phases"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
+)
+
+// New returns a new updater to change the cluster subnets
+// for the specified configuration
+func New(ctx context.Context, config Config) (*update.Updater, error) {
+	dispatcher := &dispatcher{
+		Dispatcher: rollingupdate.NewDefaultDispatcher(),
+	}
+	machine, err := rollingupdate.NewMachine(ctx, rollingupdate.Config{
+		Config:            config.Config,
+		Apps:              config.Apps,
+		ClusterPackages:   config.ClusterPackages,
+		HostLocalPackages: config.HostLocalPackages,
+		Client:            config.Client,
+		Dispatcher:        dispatcher,
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	updater, err := update.NewUpdater(ctx, config.Config, machine)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return updater, nil
+}
+
+// Config describes configuration for changing the cluster subnets
+type Config struct {
+	update.Config
+	// HostLocalPackages specifies the package service on local host
+	HostLocalPackages update.LocalPackageService
+	// Apps is the cluster application service
+	Apps app.Applications
+	// ClusterPackages specifies the cluster package service
+	ClusterPackages pack.PackageService
+	// Client specifies the optional kubernetes client
+	Client *kubernetes.Clientset
+}
+
+// Dispatch returns the appropriate phase executor based on the provided parameters
+func (r *dispatcher) Dispatch(config rollingupdate.Config, params fsm.ExecutorParams, remote fsm.Remote, logger log.FieldLogger) (fsm.PhaseExecutor, error) {
+	switch params.Phase.Executor {
+	case phases.Config:
+		return phases.NewConfig(config.Operator, *config.Operation, logger)
+	case libphase.Packages:
+		return libphase.NewPackages(params,
+			config.Operator, *config.Operation, config.Apps,
+			config.ClusterPackages, config.HostLocalPackages,
+			logger)
+	case phases.Services:
+		return phases.NewServices(params, config.Client, *config.Operation, logger)
+	case libphase.RestartContainer:
+		return libphase.NewRestart(params, config.Operator,
+			changesetID(config.Operation.ID, params.Phase.ID),
+			config.Apps, config.LocalBackend,
+			config.ClusterPackages, config.HostLocalPackages,
+			logger)
+	default:
+		return r.Dispatcher.Dispatch(config, params, remote, logger)
+	}
+}
+
+type dispatcher struct {
+	rollingupdate.Dispatcher
+}
+
+// changesetID returns the ID of the package changeset recorded when
+// the runtime container is restarted by the specified phase.
+//
+// The runtime container on each master is restarted once per pass
+// so each pass records its own changeset
+func changesetID(operationID, phaseID string) string {
+	pass := strings.SplitN(strings.TrimPrefix(phaseID, "/"), "/", 2)[0]
+	switch pass {
+	case controlPlanePhase, clusterPhase:
+		return fmt.Sprintf("%v-%v", operationID, pass)
+	}
+	return operationID
+}
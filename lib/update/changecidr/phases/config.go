@@ -0,0 +1,90 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/ops"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Config defines the phase to update the subnets
+	// in the cluster configuration
+	Config = "config"
+	// Services defines the phase to reallocate the IPs
+	// of the services from the new service subnet
+	Services = "services"
+)
+
+// NewConfig returns a new executor to update the subnets
+// in the cluster configuration
+func NewConfig(operator configUpdater, operation ops.SiteOperation, logger log.FieldLogger) (*configExecutor, error) {
+	if operation.UpdateConfig == nil || len(operation.UpdateConfig.Config) == 0 {
+		return nil, trace.BadParameter("operation %v does not update cluster configuration", operation.ID)
+	}
+	return &configExecutor{
+		FieldLogger: logger,
+		operator:    operator,
+		operation:   operation,
+	}, nil
+}
+
+// Execute updates the cluster configuration with the new subnets.
+// The runtime packages generated afterwards configure the new subnets
+func (r *configExecutor) Execute(context.Context) error {
+	r.Info("Update cluster configuration.")
+	err := r.operator.UpdateClusterConfiguration(ops.UpdateClusterConfigRequest{
+		ClusterKey: r.operation.ClusterKey(),
+		Config:     r.operation.UpdateConfig.Config,
+	})
+	return trace.Wrap(err)
+}
+
+// Rollback resets the cluster configuration to the previous value
+func (r *configExecutor) Rollback(context.Context) error {
+	r.Info("Restore cluster configuration.")
+	err := r.operator.UpdateClusterConfiguration(ops.UpdateClusterConfigRequest{
+		ClusterKey: r.operation.ClusterKey(),
+		Config:     r.operation.UpdateConfig.PrevConfig,
+	})
+	return trace.Wrap(err)
+}
+
+// PreCheck is a no-op
+func (*configExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*configExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+type configExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	operator  configUpdater
+	operation ops.SiteOperation
+}
+
+type configUpdater interface {
+	UpdateClusterConfiguration(ops.UpdateClusterConfigRequest) error
+}
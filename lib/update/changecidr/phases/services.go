@@ -0,0 +1,165 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+	"net"
+
+	libfsm "github.com/gravitational/gravity/lib/fsm"
+	libkubernetes "github.com/gravitational/gravity/lib/kubernetes"
+	"github.com/gravitational/gravity/lib/ops"
+
+	"github.com/gravitational/rigging"
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// NewServices returns a new executor to reallocate the IPs
+// of the services from the new service subnet
+func NewServices(
+	params libfsm.ExecutorParams,
+	client *kubernetes.Clientset,
+	operation ops.SiteOperation,
+	logger log.FieldLogger,
+) (*servicesExecutor, error) {
+	if client == nil {
+		return nil, trace.BadParameter("phase %q requires a Kubernetes client", params.Phase.ID)
+	}
+	if operation.ChangeCIDR == nil {
+		return nil, trace.BadParameter("operation %v does not change cluster subnets", operation.ID)
+	}
+	_, subnet, err := net.ParseCIDR(operation.ChangeCIDR.Subnets.Service)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &servicesExecutor{
+		FieldLogger: logger,
+		client:      client,
+		subnet:      subnet,
+	}, nil
+}
+
+// Execute recreates the services with a cluster IP outside of the new
+// service subnet so the apiserver allocates them a new IP from the subnet.
+//
+// The kubernetes service in the default namespace is only removed
+// as the apiserver recreates it with the first IP of the subnet
+func (r *servicesExecutor) Execute(ctx context.Context) error {
+	r.Infof("Reallocate service IPs from subnet %v.", r.subnet)
+	var count int
+	opts := metav1.ListOptions{Limit: listLimit}
+	for {
+		services, err := r.client.CoreV1().Services(metav1.NamespaceAll).List(opts)
+		if err != nil {
+			return trace.Wrap(rigging.ConvertError(err))
+		}
+		for _, service := range services.Items {
+			if !r.needsNewIP(service) {
+				continue
+			}
+			if err := r.reallocate(ctx, service); err != nil {
+				return trace.Wrap(err)
+			}
+			count++
+		}
+		if services.Continue == "" {
+			break
+		}
+		opts.Continue = services.Continue
+	}
+	r.Infof("Reallocated IPs of %v services.", count)
+	return nil
+}
+
+// Rollback is a no-op: the services keep the IPs from the new subnet
+// until they are recreated after the previous subnet has been restored
+func (r *servicesExecutor) Rollback(context.Context) error {
+	r.Warn("Services keep the IPs allocated from the new subnet.")
+	return nil
+}
+
+// PreCheck is a no-op
+func (*servicesExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*servicesExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+func (r *servicesExecutor) needsNewIP(service v1.Service) bool {
+	if service.Spec.ClusterIP == "" || service.Spec.ClusterIP == v1.ClusterIPNone {
+		return false
+	}
+	ip := net.ParseIP(service.Spec.ClusterIP)
+	return ip != nil && !r.subnet.Contains(ip)
+}
+
+func (r *servicesExecutor) reallocate(ctx context.Context, service v1.Service) error {
+	r.Infof("Reallocate IP %v of service %v/%v.", service.Spec.ClusterIP, service.Namespace, service.Name)
+	services := r.client.CoreV1().Services(service.Namespace)
+	err := libkubernetes.Retry(ctx, func() error {
+		err := services.Delete(service.Name, &metav1.DeleteOptions{})
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return trace.Wrap(rigging.ConvertError(trace.Unwrap(err)))
+	}
+	if service.Namespace == metav1.NamespaceDefault && service.Name == kubernetesService {
+		return nil
+	}
+	service.ObjectMeta = metav1.ObjectMeta{
+		Name:            service.Name,
+		Namespace:       service.Namespace,
+		Labels:          service.Labels,
+		Annotations:     service.Annotations,
+		OwnerReferences: service.OwnerReferences,
+	}
+	service.Spec.ClusterIP = ""
+	service.Status = v1.ServiceStatus{}
+	err = libkubernetes.Retry(ctx, func() error {
+		_, err := services.Create(&service)
+		if errors.IsAlreadyExists(err) {
+			return nil
+		}
+		return err
+	})
+	return trace.Wrap(rigging.ConvertError(trace.Unwrap(err)))
+}
+
+type servicesExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	client kubernetes.Interface
+	subnet *net.IPNet
+}
+
+const (
+	// kubernetesService is the name of the apiserver service
+	kubernetesService = "kubernetes"
+	// listLimit is the maximum number of services to query at once
+	listLimit = 500
+)
@@ -0,0 +1,178 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changecidr
+
+import (
+	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/update"
+	"github.com/gravitational/gravity/lib/update/changecidr/phases"
+	"github.com/gravitational/gravity/lib/update/internal/rollingupdate"
+
+	"github.com/gravitational/trace"
+)
+
+// NewOperationPlan creates a new operation plan for the specified operation
+func NewOperationPlan(
+	operator ops.Operator,
+	apps app.Applications,
+	operation ops.SiteOperation,
+	servers []storage.Server,
+) (plan *storage.OperationPlan, err error) {
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	app, err := apps.GetApp(cluster.App.Package)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to query installed application")
+	}
+	plan, err = newOperationPlan(*app, cluster.DNSConfig, operator, operation, servers)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	err = operator.CreateOperationPlan(operation.Key(), *plan)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotImplemented(
+				"cluster operator does not implement the API required to change cluster subnets. " +
+					"Please make sure you're running the command on a compatible cluster.")
+		}
+		return nil, trace.Wrap(err)
+	}
+	return plan, nil
+}
+
+// newOperationPlan returns a new plan for the specified operation
+// and the given set of servers.
+//
+// The subnets are first updated in the cluster configuration so the
+// runtime packages generated afterwards configure the new subnets.
+//
+// When the service subnet changes, the masters are restarted first with
+// the apiserver certificate issued for the new apiserver service IP and
+// the services are recreated with IPs from the new subnet.
+//
+// Then the runtime container is restarted on every node, one node at a time,
+// so the overlay network, kube-proxy and kubelet pick up the new subnets and
+// the new IP of the cluster DNS service. Draining the nodes reschedules the pods
+// with IPs from the new pod subnet
+func newOperationPlan(
+	app app.Application,
+	dnsConfig storage.DNSConfig,
+	operator packageRotator,
+	operation ops.SiteOperation,
+	servers []storage.Server,
+) (*storage.OperationPlan, error) {
+	if operation.ChangeCIDR == nil || operation.UpdateConfig == nil {
+		return nil, trace.BadParameter("operation %v does not change cluster subnets", operation.ID)
+	}
+	updates, err := rollingupdate.RuntimeConfigUpdates(app.Manifest, operator, operation.Key(), servers)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	masters, _ := update.SplitServers(updates)
+	if len(masters) == 0 {
+		return nil, trace.NotFound("no master servers found in cluster state")
+	}
+	builder := rollingupdate.Builder{App: app.Package}
+	config := update.Phase{
+		ID:          phases.Config,
+		Executor:    phases.Config,
+		Description: "Update subnets in cluster configuration",
+	}
+	plan := update.Phases{config}
+	if operation.ChangeCIDR.ServiceChanged() {
+		masters, err = withSecrets(operator, operation, masters)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		controlPlane := update.Phase{
+			ID:          controlPlanePhase,
+			Description: "Move control plane to new service subnet",
+		}
+		controlPlane.AddSequential(builder.Rotation(masters,
+			"Restart masters with new service subnet",
+			"Restart node %q with new service subnet")...)
+		services := update.Phase{
+			ID:          phases.Services,
+			Executor:    phases.Services,
+			Description: "Reallocate service IPs from new service subnet",
+		}
+		plan = append(plan, controlPlane, services)
+		// The masters are restarted again in the second pass
+		updates, err = rollingupdate.NextPassUpdates(updates)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	cluster := update.Phase{
+		ID:          clusterPhase,
+		Description: "Move nodes to new subnets",
+	}
+	cluster.AddSequential(builder.Rotation(updates,
+		"Restart nodes with new subnets",
+		"Restart node %q with new subnets")...)
+	plan = append(plan, cluster)
+
+	result := &storage.OperationPlan{
+		OperationID:   operation.ID,
+		OperationType: operation.Type,
+		AccountID:     operation.AccountID,
+		ClusterName:   operation.SiteDomain,
+		Phases:        update.Sequential(plan...).AsPhases(),
+		Servers:       servers,
+		DNSConfig:     dnsConfig,
+	}
+	update.ResolvePlan(result)
+
+	return result, nil
+}
+
+// withSecrets returns the specified master updates with new secrets packages
+// that issue the apiserver certificate for the new service subnet
+func withSecrets(operator packageRotator, operation ops.SiteOperation, masters []storage.UpdateServer) (result []storage.UpdateServer, err error) {
+	for _, server := range masters {
+		secretsUpdate, err := operator.RotateSecrets(ops.RotateSecretsRequest{
+			Key:            operation.ClusterKey(),
+			Server:         server.Server,
+			RuntimePackage: server.Runtime.Update.Package,
+			DryRun:         true,
+		})
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		server.Runtime.SecretsPackage = &secretsUpdate.Locator
+		result = append(result, server)
+	}
+	return result, nil
+}
+
+type packageRotator interface {
+	rollingupdate.ConfigPackageRotator
+	RotateSecrets(ops.RotateSecretsRequest) (*ops.RotatePackageResponse, error)
+}
+
+const (
+	// controlPlanePhase is the ID of the phase that restarts
+	// the masters with the new service subnet
+	controlPlanePhase = "control-plane"
+	// clusterPhase is the ID of the phase that restarts
+	// all nodes with the new subnets
+	clusterPhase = "cluster"
+)
@@ -0,0 +1,151 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package changecidr
+
+import (
+	"testing"
+
+	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/update/changecidr/phases"
+	"github.com/gravitational/gravity/lib/update/internal/testutils"
+
+	. "gopkg.in/check.v1"
+)
+
+func TestChangeCIDR(t *testing.T) { TestingT(t) }
+
+type S struct {
+	app     app.Application
+	servers []storage.Server
+}
+
+var _ = Suite(&S{})
+
+func (s *S) SetUpTest(c *C) {
+	s.servers = []storage.Server{
+		{Hostname: "node-1", AdvertiseIP: "10.0.0.1", Role: "node", ClusterRole: string(schema.ServiceRoleMaster)},
+		{Hostname: "node-2", AdvertiseIP: "10.0.0.2", Role: "node", ClusterRole: string(schema.ServiceRoleNode)},
+	}
+	s.app = app.Application{
+		Package: loc.MustParseLocator("gravitational.io/app:0.0.1"),
+		Manifest: schema.Manifest{
+			NodeProfiles: schema.NodeProfiles{{Name: "node"}},
+			SystemOptions: &schema.SystemOptions{
+				Dependencies: schema.SystemDependencies{
+					Runtime: &schema.Dependency{Locator: runtimeLoc},
+				},
+			},
+		},
+	}
+}
+
+func (s *S) TestChangesPodSubnet(c *C) {
+	operation := newOperation(storage.Subnets{Overlay: "10.200.0.0/16", Service: storage.DefaultSubnets.Service})
+	plan, err := newOperationPlan(s.app, storage.DefaultDNSConfig, testOperator, operation, s.servers)
+	c.Assert(err, IsNil)
+
+	c.Assert(testutils.PhaseIDs(plan.Phases), DeepEquals, []string{"/config", "/cluster"})
+	c.Assert(plan.Phases[0].Executor, Equals, phases.Config)
+	c.Assert(plan.Phases[1].Requires, DeepEquals, []string{"/config"})
+
+	cluster := plan.Phases[1]
+	c.Assert(testutils.PhaseIDs(cluster.Phases), DeepEquals, []string{"/cluster/packages", "/cluster/masters", "/cluster/nodes"})
+	packages := cluster.Phases[0]
+	c.Assert(packages.Data.Update.Servers, HasLen, 2)
+	update := packages.Data.Update.Servers[0]
+	c.Assert(update.Runtime.Update.ConfigPackage, Equals, testOperator.runtimeConfigPackage)
+	c.Assert(update.Runtime.SecretsPackage, IsNil)
+
+	restart := cluster.Phases[1].Phases[0].Phases[1]
+	c.Assert(restart.ID, Equals, "/cluster/masters/node-1/restart")
+	c.Assert(changesetID("1", restart.ID), Equals, "1-cluster")
+}
+
+func (s *S) TestChangesServiceSubnetInTwoPasses(c *C) {
+	operation := newOperation(storage.Subnets{Overlay: storage.DefaultSubnets.Overlay, Service: "10.50.0.0/16"})
+	plan, err := newOperationPlan(s.app, storage.DefaultDNSConfig, testOperator, operation, s.servers)
+	c.Assert(err, IsNil)
+
+	c.Assert(testutils.PhaseIDs(plan.Phases), DeepEquals, []string{"/config", "/control-plane", "/services", "/cluster"})
+	c.Assert(plan.Phases[1].Requires, DeepEquals, []string{"/config"})
+	c.Assert(plan.Phases[2].Requires, DeepEquals, []string{"/control-plane"})
+	c.Assert(plan.Phases[2].Executor, Equals, phases.Services)
+	c.Assert(plan.Phases[3].Requires, DeepEquals, []string{"/services"})
+
+	controlPlane := plan.Phases[1]
+	c.Assert(testutils.PhaseIDs(controlPlane.Phases), DeepEquals, []string{"/control-plane/packages", "/control-plane/masters"})
+	first := controlPlane.Phases[0].Data.Update.Servers
+	c.Assert(first, HasLen, 1)
+	c.Assert(first[0].Runtime.Update.ConfigPackage, Equals, testOperator.runtimeConfigPackage)
+	c.Assert(*first[0].Runtime.SecretsPackage, Equals, testOperator.secretsPackage)
+
+	cluster := plan.Phases[3]
+	c.Assert(testutils.PhaseIDs(cluster.Phases), DeepEquals, []string{"/cluster/packages", "/cluster/masters", "/cluster/nodes"})
+	// The second pass restarts the masters with its own packages
+	second := cluster.Phases[0].Data.Update.Servers
+	c.Assert(second, HasLen, 2)
+	c.Assert(second[0].Runtime.Update.ConfigPackage.String(), Equals, "gravitational.io/planet-config:0.0.1+1")
+	c.Assert(second[0].Runtime.SecretsPackage, IsNil)
+
+	controlPlaneRestart := controlPlane.Phases[1].Phases[0].Phases[1]
+	c.Assert(controlPlaneRestart.ID, Equals, "/control-plane/masters/node-1/restart")
+	c.Assert(changesetID("1", controlPlaneRestart.ID), Equals, "1-control-plane")
+}
+
+func (s *S) TestRequiresSubnets(c *C) {
+	operation := newOperation(storage.DefaultSubnets)
+	operation.ChangeCIDR = nil
+	_, err := newOperationPlan(s.app, storage.DefaultDNSConfig, testOperator, operation, s.servers)
+	c.Assert(err, NotNil)
+}
+
+func newOperation(subnets storage.Subnets) ops.SiteOperation {
+	return testutils.NewOperation(ops.SiteOperation{
+		Type: ops.OperationChangeCIDR,
+		UpdateConfig: &storage.UpdateConfigOperationState{
+			Config: []byte("config"),
+		},
+		ChangeCIDR: &storage.ChangeCIDROperationState{
+			PrevSubnets: storage.DefaultSubnets,
+			Subnets:     subnets,
+		},
+	})
+}
+
+func (r testRotator) RotatePlanetConfig(ops.RotatePlanetConfigRequest) (*ops.RotatePackageResponse, error) {
+	return &ops.RotatePackageResponse{Locator: r.runtimeConfigPackage}, nil
+}
+
+func (r testRotator) RotateSecrets(ops.RotateSecretsRequest) (*ops.RotatePackageResponse, error) {
+	return &ops.RotatePackageResponse{Locator: r.secretsPackage}, nil
+}
+
+var runtimeLoc = loc.Locator{Repository: "foo", Name: "runtime", Version: "0.0.1"}
+
+var testOperator = testRotator{
+	runtimeConfigPackage: loc.Locator{Repository: "gravitational.io", Name: "planet-config", Version: "0.0.1"},
+	secretsPackage:       loc.Locator{Repository: "gravitational.io", Name: "planet-secrets", Version: "0.0.1"},
+}
+
+type testRotator struct {
+	runtimeConfigPackage loc.Locator
+	secretsPackage       loc.Locator
+}
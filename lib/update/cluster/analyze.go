@@ -0,0 +1,493 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/gravitational/rigging"
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+// AnalyzeConfig defines the configuration for analyzing an upgrade
+type AnalyzeConfig struct {
+	// Packages is the cluster package service
+	Packages pack.PackageService
+	// Client is the cluster Kubernetes client
+	Client *kubernetes.Clientset
+	// Servers lists the cluster servers
+	Servers []storage.Server
+	// InstalledApp is the installed cluster image
+	InstalledApp app.Application
+	// UpdateApp is the cluster image to upgrade to
+	UpdateApp app.Application
+}
+
+// CheckAndSetDefaults validates the configuration
+func (r *AnalyzeConfig) CheckAndSetDefaults() error {
+	if r.Packages == nil {
+		return trace.BadParameter("package service is required")
+	}
+	if r.Client == nil {
+		return trace.BadParameter("Kubernetes client is required")
+	}
+	if len(r.Servers) == 0 {
+		return trace.BadParameter("at least one server is required")
+	}
+	return nil
+}
+
+// UpgradeReport describes the impact of upgrading the cluster to a new cluster image
+type UpgradeReport struct {
+	// InstalledPackage is the installed cluster image
+	InstalledPackage loc.Locator `json:"installed_package"`
+	// UpdatePackage is the cluster image to upgrade to
+	UpdatePackage loc.Locator `json:"update_package"`
+	// KubernetesVersion is the Kubernetes version of the update.
+	// Empty if the version could not be determined
+	KubernetesVersion string `json:"kubernetes_version,omitempty"`
+	// DeprecatedAPIs lists the cluster objects managed via Kubernetes APIs
+	// that are no longer served by the update
+	DeprecatedAPIs []DeprecatedAPIUsage `json:"deprecated_apis,omitempty"`
+	// RemovedFeatures lists the features of the installed cluster image
+	// missing from the update
+	RemovedFeatures []string `json:"removed_features,omitempty"`
+	// Nodes describes the impact of the upgrade on each cluster node
+	Nodes []NodeUpgradeReport `json:"nodes"`
+}
+
+// DeprecatedAPIUsage describes an object managed via a removed Kubernetes API
+type DeprecatedAPIUsage struct {
+	// Kind is the object kind
+	Kind string `json:"kind"`
+	// Namespace is the object namespace
+	Namespace string `json:"namespace,omitempty"`
+	// Name is the object name
+	Name string `json:"name"`
+	// APIVersion is the removed API version the object is managed with
+	APIVersion string `json:"api_version"`
+	// Replacement is the API version to use instead
+	Replacement string `json:"replacement"`
+	// RemovedIn is the Kubernetes version the API has been removed in
+	RemovedIn string `json:"removed_in"`
+}
+
+// NodeUpgradeReport describes the impact of the upgrade on a single node
+type NodeUpgradeReport struct {
+	// Hostname is the node hostname
+	Hostname string `json:"hostname"`
+	// AdvertiseIP is the node advertise address
+	AdvertiseIP string `json:"advertise_ip"`
+	// Role is the node profile
+	Role string `json:"role"`
+	// StagingBytes is the estimated disk space required to stage the update on the node
+	StagingBytes uint64 `json:"staging_bytes"`
+	// Downtime is the estimated time the node is unavailable for workloads
+	Downtime time.Duration `json:"downtime"`
+}
+
+// Analyze inspects the cluster and the update cluster image and returns
+// the report describing the impact of the upgrade.
+// The cluster is not modified
+func Analyze(config AnalyzeConfig) (*UpgradeReport, error) {
+	if err := config.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	report := UpgradeReport{
+		InstalledPackage: config.InstalledApp.Package,
+		UpdatePackage:    config.UpdateApp.Package,
+		RemovedFeatures: removedFeatures(config.InstalledApp.Manifest,
+			config.UpdateApp.Manifest, config.Servers),
+	}
+	kubeVersion, err := kubernetesVersion(config.UpdateApp.Manifest, config.Packages)
+	if err != nil {
+		if !trace.IsNotFound(err) {
+			return nil, trace.Wrap(err)
+		}
+		log.WithError(err).Warn("Failed to determine Kubernetes version of the update.")
+	}
+	if kubeVersion != nil {
+		report.KubernetesVersion = kubeVersion.String()
+	}
+	objects, err := listManagedObjects(config.Client)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	report.DeprecatedAPIs = deprecatedAPIUsages(objects, kubeVersion)
+	updateEtcd, err := etcdChanged(config.InstalledApp.Manifest, config.UpdateApp.Manifest, config.Packages)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, server := range config.Servers {
+		node, err := analyzeNode(config, server, updateEtcd)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		report.Nodes = append(report.Nodes, *node)
+	}
+	return &report, nil
+}
+
+func analyzeNode(config AnalyzeConfig, server storage.Server, updateEtcd bool) (*NodeUpgradeReport, error) {
+	node := NodeUpgradeReport{
+		Hostname:    server.Hostname,
+		AdvertiseIP: server.AdvertiseIP,
+		Role:        server.Role,
+	}
+	packages, runtimeUpdate, err := stagedPackages(config.InstalledApp.Manifest,
+		config.UpdateApp.Manifest, server.Role)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, locator := range packages {
+		envelope, err := config.Packages.ReadPackageEnvelope(locator)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		// The package is stored both as an archive and unpacked
+		node.StagingBytes += 2 * uint64(envelope.SizeBytes)
+	}
+	if !runtimeUpdate {
+		return &node, nil
+	}
+	pods, err := config.Client.CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{
+		FieldSelector: fields.SelectorFromSet(fields.Set{"spec.nodeName": server.KubeNodeID()}).String(),
+	})
+	if err != nil {
+		return nil, rigging.ConvertError(err)
+	}
+	master := server.ClusterRole == string(schema.ServiceRoleMaster)
+	node.Downtime = estimateDowntime(pods.Items, master && updateEtcd)
+	return &node, nil
+}
+
+// StagedPackages returns the system packages that need to be downloaded to a node
+// with the specified profile to upgrade it from the installed to the update cluster image
+func StagedPackages(installed, update schema.Manifest, profile string) ([]loc.Locator, error) {
+	packages, _, err := stagedPackages(installed, update, profile)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return packages, nil
+}
+
+// stagedPackages returns the packages that need to be downloaded to a node
+// with the specified profile and whether the node's runtime is updated
+func stagedPackages(installed, update schema.Manifest, profile string) (packages []loc.Locator, runtimeUpdate bool, err error) {
+	updateRuntime, err := update.RuntimePackageForProfile(profile)
+	if err != nil && trace.IsNotFound(err) {
+		// The profile has been removed from the update
+		updateRuntime, err = update.DefaultRuntimePackage()
+	}
+	if err != nil {
+		return nil, false, trace.Wrap(err)
+	}
+	installedRuntime, err := installed.RuntimePackageForProfile(profile)
+	if err != nil && !trace.IsNotFound(err) {
+		return nil, false, trace.Wrap(err)
+	}
+	if installedRuntime == nil || !installedRuntime.IsEqualTo(*updateRuntime) {
+		packages = append(packages, *updateRuntime)
+		runtimeUpdate = true
+	}
+	for _, name := range []string{constants.GravityPackage, constants.TeleportPackage} {
+		updatePackage, err := update.Dependencies.ByName(name)
+		if err != nil {
+			if trace.IsNotFound(err) {
+				continue
+			}
+			return nil, false, trace.Wrap(err)
+		}
+		installedPackage, err := installed.Dependencies.ByName(name)
+		if err == nil && installedPackage.IsEqualTo(*updatePackage) {
+			continue
+		}
+		packages = append(packages, *updatePackage)
+	}
+	return packages, runtimeUpdate, nil
+}
+
+// estimateDowntime estimates how long a node running the specified pods
+// is unavailable during the upgrade
+func estimateDowntime(pods []v1.Pod, updateEtcd bool) time.Duration {
+	var drain time.Duration
+	for _, pod := range pods {
+		if wait := terminationGracePeriod(pod); wait > drain {
+			drain = wait
+		}
+	}
+	downtime := drain + systemUpdateDuration
+	if updateEtcd {
+		downtime += etcdUpdateDuration
+	}
+	return downtime
+}
+
+func terminationGracePeriod(pod v1.Pod) time.Duration {
+	if pod.Spec.TerminationGracePeriodSeconds == nil {
+		return v1.DefaultTerminationGracePeriodSeconds * time.Second
+	}
+	return time.Duration(*pod.Spec.TerminationGracePeriodSeconds) * time.Second
+}
+
+// removedFeatures returns the features of the installed manifest
+// that are no longer available in the update manifest
+func removedFeatures(installed, update schema.Manifest, servers []storage.Server) (features []string) {
+	profiles := make(map[string][]string)
+	for _, server := range servers {
+		profiles[server.Role] = append(profiles[server.Role], server.Hostname)
+	}
+	var names []string
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := update.NodeProfiles.ByName(name); err != nil {
+			features = append(features, fmt.Sprintf("node profile %q used by nodes %v",
+				name, strings.Join(profiles[name], ", ")))
+		}
+	}
+	for _, hook := range schema.AllHooks() {
+		if installed.HasHook(hook) && !update.HasHook(hook) {
+			features = append(features, fmt.Sprintf("%v hook", hook))
+		}
+	}
+	for _, installedApp := range installed.Dependencies.GetApps() {
+		if _, err := update.Dependencies.ByName(installedApp.Name); err != nil {
+			features = append(features, fmt.Sprintf("application %v", installedApp.Name))
+		}
+	}
+	return features
+}
+
+// kubernetesVersion returns the Kubernetes version of the runtime
+// used by the specified manifest
+func kubernetesVersion(manifest schema.Manifest, packages pack.PackageService) (*semver.Version, error) {
+	runtimePackage, err := manifest.DefaultRuntimePackage()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return getEtcdVersion("version-k8s", *runtimePackage, packages)
+}
+
+// etcdChanged returns true if the update manifest uses a different
+// version of etcd than the installed one
+func etcdChanged(installed, update schema.Manifest, packages pack.PackageService) (bool, error) {
+	installedRuntime, err := installed.DefaultRuntimePackage()
+	if err != nil {
+		return false, trace.Wrap(err)
+	}
+	updateRuntime, err := update.DefaultRuntimePackage()
+	if err != nil {
+		return false, trace.Wrap(err)
+	}
+	installedVersion, err := getEtcdVersion("version-etcd", *installedRuntime, packages)
+	if err != nil && !trace.IsNotFound(err) {
+		return false, trace.Wrap(err)
+	}
+	updateVersion, err := getEtcdVersion("version-etcd", *updateRuntime, packages)
+	if err != nil && !trace.IsNotFound(err) {
+		return false, trace.Wrap(err)
+	}
+	if installedVersion == nil || updateVersion == nil {
+		return !installedRuntime.IsEqualTo(*updateRuntime), nil
+	}
+	return !installedVersion.Equal(*updateVersion), nil
+}
+
+// deprecatedAPIUsages returns the objects managed via Kubernetes APIs
+// no longer served by the specified Kubernetes version.
+// If the version is unknown, all objects managed via deprecated APIs are returned
+func deprecatedAPIUsages(objects []managedObject, kubeVersion *semver.Version) (usages []DeprecatedAPIUsage) {
+	for _, object := range objects {
+		for _, api := range removedAPIs {
+			if api.kind != object.kind || api.apiVersion != object.apiVersion {
+				continue
+			}
+			if kubeVersion != nil && kubeVersion.LessThan(api.removedIn) {
+				continue
+			}
+			usages = append(usages, DeprecatedAPIUsage{
+				Kind:        object.kind,
+				Namespace:   object.namespace,
+				Name:        object.name,
+				APIVersion:  object.apiVersion,
+				Replacement: api.replacement,
+				RemovedIn:   api.removedIn.String(),
+			})
+		}
+	}
+	return usages
+}
+
+// listManagedObjects returns the cluster objects along with the API version
+// they have last been applied with
+func listManagedObjects(client *kubernetes.Clientset) (objects []managedObject, err error) {
+	add := func(kind string, meta metav1.ObjectMeta) {
+		for _, ref := range meta.OwnerReferences {
+			if ref.Controller != nil && *ref.Controller {
+				// Objects managed by controllers are not applied directly
+				return
+			}
+		}
+		apiVersion := lastAppliedAPIVersion(meta)
+		if apiVersion == "" {
+			return
+		}
+		objects = append(objects, managedObject{
+			kind:       kind,
+			namespace:  meta.Namespace,
+			name:       meta.Name,
+			apiVersion: apiVersion,
+		})
+	}
+	apps := client.AppsV1()
+	deployments, err := apps.Deployments(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, rigging.ConvertError(err)
+	}
+	for _, object := range deployments.Items {
+		add(rigging.KindDeployment, object.ObjectMeta)
+	}
+	daemonSets, err := apps.DaemonSets(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, rigging.ConvertError(err)
+	}
+	for _, object := range daemonSets.Items {
+		add(rigging.KindDaemonSet, object.ObjectMeta)
+	}
+	statefulSets, err := apps.StatefulSets(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, rigging.ConvertError(err)
+	}
+	for _, object := range statefulSets.Items {
+		add(rigging.KindStatefulSet, object.ObjectMeta)
+	}
+	replicaSets, err := apps.ReplicaSets(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, rigging.ConvertError(err)
+	}
+	for _, object := range replicaSets.Items {
+		add(rigging.KindReplicaSet, object.ObjectMeta)
+	}
+	networkPolicies, err := client.NetworkingV1().NetworkPolicies(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, rigging.ConvertError(err)
+	}
+	for _, object := range networkPolicies.Items {
+		add(kindNetworkPolicy, object.ObjectMeta)
+	}
+	podSecurityPolicies, err := client.PolicyV1beta1().PodSecurityPolicies().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, rigging.ConvertError(err)
+	}
+	for _, object := range podSecurityPolicies.Items {
+		add(rigging.KindPodSecurityPolicy, object.ObjectMeta)
+	}
+	ingresses, err := client.ExtensionsV1beta1().Ingresses(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, rigging.ConvertError(err)
+	}
+	for _, object := range ingresses.Items {
+		add(kindIngress, object.ObjectMeta)
+	}
+	return objects, nil
+}
+
+// lastAppliedAPIVersion returns the API version the object has last been
+// applied with or an empty string if the object has not been applied
+func lastAppliedAPIVersion(meta metav1.ObjectMeta) string {
+	config, ok := meta.Annotations[v1.LastAppliedConfigAnnotation]
+	if !ok {
+		return ""
+	}
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal([]byte(config), &typeMeta); err != nil {
+		log.WithError(err).Warnf("Failed to parse last applied configuration of %v/%v.",
+			meta.Namespace, meta.Name)
+		return ""
+	}
+	return typeMeta.APIVersion
+}
+
+// managedObject describes a cluster object and the API version
+// it has last been applied with
+type managedObject struct {
+	kind       string
+	namespace  string
+	name       string
+	apiVersion string
+}
+
+// removedAPI describes a Kubernetes API version no longer served for a kind
+type removedAPI struct {
+	kind        string
+	apiVersion  string
+	replacement string
+	removedIn   semver.Version
+}
+
+// removedAPIs lists the Kubernetes APIs removed in recent Kubernetes versions
+var removedAPIs = []removedAPI{
+	{kind: rigging.KindDeployment, apiVersion: "extensions/v1beta1", replacement: "apps/v1", removedIn: kubernetes116},
+	{kind: rigging.KindDaemonSet, apiVersion: "extensions/v1beta1", replacement: "apps/v1", removedIn: kubernetes116},
+	{kind: rigging.KindReplicaSet, apiVersion: "extensions/v1beta1", replacement: "apps/v1", removedIn: kubernetes116},
+	{kind: kindNetworkPolicy, apiVersion: "extensions/v1beta1", replacement: "networking.k8s.io/v1", removedIn: kubernetes116},
+	{kind: rigging.KindPodSecurityPolicy, apiVersion: "extensions/v1beta1", replacement: "policy/v1beta1", removedIn: kubernetes116},
+	{kind: rigging.KindDeployment, apiVersion: "apps/v1beta1", replacement: "apps/v1", removedIn: kubernetes116},
+	{kind: rigging.KindStatefulSet, apiVersion: "apps/v1beta1", replacement: "apps/v1", removedIn: kubernetes116},
+	{kind: rigging.KindDeployment, apiVersion: "apps/v1beta2", replacement: "apps/v1", removedIn: kubernetes116},
+	{kind: rigging.KindDaemonSet, apiVersion: "apps/v1beta2", replacement: "apps/v1", removedIn: kubernetes116},
+	{kind: rigging.KindStatefulSet, apiVersion: "apps/v1beta2", replacement: "apps/v1", removedIn: kubernetes116},
+	{kind: rigging.KindReplicaSet, apiVersion: "apps/v1beta2", replacement: "apps/v1", removedIn: kubernetes116},
+	{kind: kindIngress, apiVersion: "extensions/v1beta1", replacement: "networking.k8s.io/v1", removedIn: kubernetes122},
+}
+
+var (
+	kubernetes116 = *semver.New("1.16.0")
+	kubernetes122 = *semver.New("1.22.0")
+)
+
+const (
+	kindNetworkPolicy = "NetworkPolicy"
+	kindIngress       = "Ingress"
+
+	// systemUpdateDuration is the estimated time to update
+	// and restart the system software on a node
+	systemUpdateDuration = 5 * time.Minute
+	// etcdUpdateDuration is the estimated time etcd is unavailable
+	// when it is upgraded
+	etcdUpdateDuration = 2 * time.Minute
+)
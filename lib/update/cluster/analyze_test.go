@@ -0,0 +1,130 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"time"
+
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/gravitational/rigging"
+	"gopkg.in/check.v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+type AnalyzeSuite struct{}
+
+var _ = check.Suite(&AnalyzeSuite{})
+
+func (s *AnalyzeSuite) TestReportsDeprecatedAPIs(c *check.C) {
+	objects := []managedObject{
+		{kind: rigging.KindDeployment, namespace: "default", name: "web", apiVersion: "extensions/v1beta1"},
+		{kind: rigging.KindDeployment, namespace: "default", name: "api", apiVersion: "apps/v1"},
+		{kind: kindIngress, namespace: "default", name: "web", apiVersion: "extensions/v1beta1"},
+	}
+	c.Assert(deprecatedAPIUsages(objects, semver.New("1.15.7")), check.IsNil)
+	c.Assert(deprecatedAPIUsages(objects, semver.New("1.16.2")), check.DeepEquals, []DeprecatedAPIUsage{
+		{
+			Kind:        rigging.KindDeployment,
+			Namespace:   "default",
+			Name:        "web",
+			APIVersion:  "extensions/v1beta1",
+			Replacement: "apps/v1",
+			RemovedIn:   "1.16.0",
+		},
+	})
+	// with unknown version, all usages are reported
+	c.Assert(deprecatedAPIUsages(objects, nil), check.HasLen, 2)
+}
+
+func (s *AnalyzeSuite) TestReportsRemovedFeatures(c *check.C) {
+	installed := schema.Manifest{
+		NodeProfiles: schema.NodeProfiles{{Name: "master"}, {Name: "db"}},
+		Hooks: &schema.Hooks{
+			Backup:  &schema.Hook{Type: schema.HookBackup, Job: "job"},
+			Restore: &schema.Hook{Type: schema.HookRestore, Job: "job"},
+		},
+		Dependencies: schema.Dependencies{
+			Apps: []schema.Dependency{
+				{Locator: loc.MustParseLocator("gravitational.io/dns-app:0.0.1")},
+				{Locator: loc.MustParseLocator("gravitational.io/logging-app:0.0.1")},
+			},
+		},
+	}
+	update := schema.Manifest{
+		NodeProfiles: schema.NodeProfiles{{Name: "master"}},
+		Hooks: &schema.Hooks{
+			Restore: &schema.Hook{Type: schema.HookRestore, Job: "job"},
+		},
+		Dependencies: schema.Dependencies{
+			Apps: []schema.Dependency{
+				{Locator: loc.MustParseLocator("gravitational.io/dns-app:0.0.2")},
+			},
+		},
+	}
+	nodes := []storage.Server{servers[0], servers[1], servers[2]}
+	nodes[0].Role = "master"
+	nodes[1].Role = "master"
+	nodes[2].Role = "db"
+	c.Assert(removedFeatures(installed, update, nodes), check.DeepEquals, []string{
+		`node profile "db" used by nodes node-3`,
+		"backup hook",
+		"application logging-app",
+	})
+}
+
+func (s *AnalyzeSuite) TestEstimatesDowntime(c *check.C) {
+	pods := []v1.Pod{
+		{},
+		{Spec: v1.PodSpec{TerminationGracePeriodSeconds: utils.Int64Ptr(300)}},
+	}
+	c.Assert(estimateDowntime(pods, false), check.Equals, 5*time.Minute+systemUpdateDuration)
+	c.Assert(estimateDowntime(nil, true), check.Equals, systemUpdateDuration+etcdUpdateDuration)
+}
+
+func (s *AnalyzeSuite) TestStagesOnlyChangedSystemPackages(c *check.C) {
+	installed := schema.Manifest{
+		NodeProfiles: schema.NodeProfiles{{Name: "node"}},
+		SystemOptions: &schema.SystemOptions{
+			Dependencies: schema.SystemDependencies{
+				Runtime: &schema.Dependency{Locator: loc.MustParseLocator("gravitational.io/planet:1.0.0")},
+			},
+		},
+		Dependencies: schema.Dependencies{
+			Packages: []schema.Dependency{
+				{Locator: loc.MustParseLocator("gravitational.io/gravity:1.0.0")},
+				{Locator: loc.MustParseLocator("gravitational.io/teleport:1.0.0")},
+			},
+		},
+	}
+	update := installed
+	update.Dependencies = schema.Dependencies{
+		Packages: []schema.Dependency{
+			{Locator: loc.MustParseLocator("gravitational.io/gravity:2.0.0")},
+			{Locator: loc.MustParseLocator("gravitational.io/teleport:1.0.0")},
+		},
+	}
+	packages, err := StagedPackages(installed, update, "node")
+	c.Assert(err, check.IsNil)
+	c.Assert(packages, check.DeepEquals, []loc.Locator{
+		loc.MustParseLocator("gravitational.io/gravity:2.0.0"),
+	})
+}
@@ -19,21 +19,33 @@ package cluster
 import (
 	"context"
 
+	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/fsm"
 	"github.com/gravitational/gravity/lib/localenv"
 	"github.com/gravitational/gravity/lib/ops"
 	"github.com/gravitational/gravity/lib/rpc"
 	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/update"
+	"github.com/gravitational/gravity/lib/utils"
 	"github.com/gravitational/gravity/lib/utils/kubectl"
 
 	"github.com/gravitational/trace"
 	log "github.com/sirupsen/logrus"
 )
 
-// AutomaticUpgrade starts automatic upgrade process
+// AutomaticUpgrade starts automatic upgrade process.
+// The agent running the upgrade is restarted after the node reboots in which case
+// the operation is resumed from the last persisted phase once the cluster is available
 func AutomaticUpgrade(ctx context.Context, localEnv, updateEnv *localenv.LocalEnvironment) (err error) {
-	clusterEnv, err := localEnv.NewClusterEnvironment()
+	var clusterEnv *localenv.ClusterEnvironment
+	err = utils.RetryFor(ctx, defaults.AgentRebootTimeout, func() (err error) {
+		clusterEnv, err = localEnv.NewClusterEnvironment()
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		_, err = clusterEnv.Operator.GetLocalSite()
+		return trace.Wrap(err)
+	})
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -63,6 +75,8 @@ func AutomaticUpgrade(ctx context.Context, localEnv, updateEnv *localenv.LocalEn
 		Apps:              clusterEnv.Apps,
 		Client:            clusterEnv.Client,
 		Users:             clusterEnv.Users,
+		// Resume the plan once the agents on rebooted nodes reconnect
+		AgentReconnectTimeout: defaults.AgentRebootTimeout,
 	}
 	fsm, err := New(ctx, config)
 	if err != nil {
@@ -71,6 +85,10 @@ func AutomaticUpgrade(ctx context.Context, localEnv, updateEnv *localenv.LocalEn
 	defer fsm.Close()
 
 	fsmErr := fsm.Run(ctx)
+	if update.IsApprovalRequired(fsmErr) {
+		log.WithError(fsmErr).Info("Upgrade is waiting for approval.")
+		return nil
+	}
 	if fsmErr != nil {
 		log.WithError(fsmErr).Warn("Failed to execute plan.")
 		// fallthrough
@@ -26,9 +26,11 @@ import (
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/loc"
 	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/schema"
 	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/update"
 	libphase "github.com/gravitational/gravity/lib/update/cluster/phases"
+	"github.com/gravitational/gravity/lib/utils"
 
 	"github.com/coreos/go-semver/semver"
 	"github.com/gravitational/rigging"
@@ -136,6 +138,33 @@ func (r phaseBuilder) app(updates []loc.Locator) *update.Phase {
 	return &root
 }
 
+// smokeTests returns the phase that runs the application smoke tests
+// after the upgrade.
+//
+// If the application has no smoke tests to run after upgrade, returns nil.
+func (r phaseBuilder) smokeTests() *update.Phase {
+	tests := r.updateApp.Manifest.SmokeTestsFor(schema.SmokeTestUpgrade)
+	if len(tests) == 0 {
+		return nil
+	}
+	root := update.RootPhase(update.Phase{
+		ID:          "smoke-tests",
+		Description: "Run application smoke tests",
+	})
+	for _, test := range tests {
+		root.AddSequential(update.Phase{
+			ID:          test.Name,
+			Executor:    smokeTest,
+			Description: fmt.Sprintf("Run smoke test %v", test.Name),
+			Data: &storage.OperationPhaseData{
+				Package: &r.updateApp.Package,
+				Data:    test.Name,
+			},
+		})
+	}
+	return &root
+}
+
 // migration constructs a migration phase based on the plan params.
 //
 // If there are no migrations to perform, returns nil.
@@ -279,6 +308,10 @@ func (r phaseBuilder) masters(leadMaster storage.UpdateServer, otherMasters []st
 		root.AddSequential(setLeaderElection(enable(leadMaster), disable(otherMasters...), leadMaster, "elect", "Make node %q Kubernetes leader"))
 	}
 
+	if r.isCanary(leadMaster) {
+		root.AddSequential(r.canaryPhase(leadMaster, leadMaster, &root))
+	}
+
 	for i, server := range otherMasters {
 		node = r.node(server.Server, &root, "Update system software on master node %q")
 		node.AddSequential(r.commonNode(otherMasters[i], leadMaster, supportsTaints,
@@ -296,15 +329,126 @@ func (r phaseBuilder) nodes(leadMaster storage.UpdateServer, nodes []storage.Upd
 		Description: "Update regular nodes",
 	})
 
-	for i, server := range nodes {
-		node := r.node(server.Server, &root, "Update system software on node %q")
-		node.AddSequential(r.commonNode(nodes[i], leadMaster, supportsTaints,
-			waitsForEndpoints(true))...)
+	batchSize := r.strategy.GetBatchSize(len(nodes))
+	if batchSize > 1 {
+		return r.nodeBatches(leadMaster, nodes, batchSize, supportsTaints)
+	}
+
+	// The canary node is updated first and the remaining nodes
+	// wait for the canary to be approved
+	var canary *update.Phase
+	for i, server := range r.canaryFirst(nodes) {
+		node := r.regularNode(server, leadMaster, &root, supportsTaints)
+		if canary != nil {
+			node.Require(*canary)
+		}
 		root.AddParallel(node)
+		if i == 0 && r.isCanary(server) {
+			phase := r.canaryPhase(server, leadMaster, &root)
+			phase.Require(node)
+			root.Add(phase)
+			canary = &phase
+		}
+	}
+	return &root
+}
+
+// nodeBatches returns a new phase that updates regular nodes in batches of the
+// specified size. Nodes within a batch are updated in parallel while the batches
+// are updated one after another
+func (r phaseBuilder) nodeBatches(leadMaster storage.UpdateServer, nodes []storage.UpdateServer, batchSize int, supportsTaints bool) *update.Phase {
+	root := update.RootPhase(update.Phase{
+		ID:          "nodes",
+		Description: "Update regular nodes",
+	})
+
+	servers := r.canaryFirst(nodes)
+	if len(servers) != 0 && r.isCanary(servers[0]) {
+		node := r.regularNode(servers[0], leadMaster, &root, supportsTaints)
+		root.Add(node)
+		canary := r.canaryPhase(servers[0], leadMaster, &root)
+		canary.Require(node)
+		root.Add(canary)
+		servers = servers[1:]
+	}
+
+	for i := 0; len(servers) != 0; i++ {
+		size := utils.Min(batchSize, len(servers))
+		batch := update.Phase{
+			ID:          root.ChildLiteral(fmt.Sprintf("batch-%v", i+1)),
+			Description: fmt.Sprintf("Update regular nodes in batch %v", i+1),
+			Parallel:    true,
+		}
+		for _, server := range servers[:size] {
+			batch.Add(r.regularNode(server, leadMaster, &batch, supportsTaints))
+		}
+		root.AddSequential(batch)
+		servers = servers[size:]
 	}
 	return &root
 }
 
+// regularNode returns a new phase that updates the specified regular node
+func (r phaseBuilder) regularNode(server, leadMaster storage.UpdateServer, parent update.ParentPhase, supportsTaints bool) update.Phase {
+	node := r.node(server.Server, parent, "Update system software on node %q")
+	node.AddSequential(r.commonNode(server, leadMaster, supportsTaints,
+		waitsForEndpoints(true))...)
+	return node
+}
+
+// canaryPhase returns a new phase that verifies the health of the cluster
+// after the canary node has been updated and holds off the update of the remaining
+// nodes until either the soak period elapses or the update is explicitly approved
+func (r phaseBuilder) canaryPhase(server, leadMaster storage.UpdateServer, parent update.ParentPhase) update.Phase {
+	root := update.Phase{
+		ID:          parent.ChildLiteral("canary"),
+		Description: fmt.Sprintf("Verify canary node %q", server.Hostname),
+	}
+	root.AddSequential(update.Phase{
+		ID:          "health",
+		Executor:    canaryHealth,
+		Description: fmt.Sprintf("Run health checks on canary node %q", server.Hostname),
+		Data: &storage.OperationPhaseData{
+			Server:     &server.Server,
+			ExecServer: &leadMaster.Server,
+			Package:    &r.installedApp.Package,
+		},
+	})
+	approve := update.Phase{
+		ID:          "approve",
+		Executor:    canaryApprove,
+		Description: "Wait for approval to update the remaining nodes",
+		Data: &storage.OperationPhaseData{
+			Server:     &server.Server,
+			ExecServer: &leadMaster.Server,
+			Package:    &r.installedApp.Package,
+		},
+	}
+	if r.strategy.SoakPeriod != 0 {
+		approve.Description = fmt.Sprintf("Observe canary node %q for %v",
+			server.Hostname, r.strategy.SoakPeriod)
+		approve.Data.Data = r.strategy.SoakPeriod.String()
+	}
+	root.AddSequential(approve)
+	return root
+}
+
+// canaryFirst returns the list of nodes with the canary node first
+func (r phaseBuilder) canaryFirst(nodes []storage.UpdateServer) (result []storage.UpdateServer) {
+	for _, node := range nodes {
+		if r.isCanary(node) {
+			result = append([]storage.UpdateServer{node}, result...)
+		} else {
+			result = append(result, node)
+		}
+	}
+	return result
+}
+
+func (r phaseBuilder) isCanary(server storage.UpdateServer) bool {
+	return r.canary != nil && r.canary.AdvertiseIP == server.AdvertiseIP
+}
+
 func (r phaseBuilder) etcdPlan(
 	leadMaster storage.Server,
 	otherMasters []storage.Server,
@@ -475,6 +619,15 @@ func (r phaseBuilder) node(server storage.Server, parent update.ParentPhase, for
 	}
 }
 
+// drainPolicy returns the node drain policy of the operation
+// or nil to use the default policy
+func (r phaseBuilder) drainPolicy() *storage.DrainPolicy {
+	if r.operation.Update == nil {
+		return nil
+	}
+	return r.operation.Update.Drain
+}
+
 // commonNode returns a list of operations required for any node role to upgrade its system software
 func (r phaseBuilder) commonNode(server, leadMaster storage.UpdateServer, supportsTaints bool,
 	waitsForEndpoints waitsForEndpoints) []update.Phase {
@@ -486,6 +639,7 @@ func (r phaseBuilder) commonNode(server, leadMaster storage.UpdateServer, suppor
 			Data: &storage.OperationPhaseData{
 				Server:     &server.Server,
 				ExecServer: &leadMaster.Server,
+				Drain:      r.drainPolicy(),
 			},
 		},
 		{
@@ -563,6 +717,9 @@ func (r phaseBuilder) cleanup() *update.Phase {
 
 type phaseBuilder struct {
 	planConfig
+	// canary optionally specifies the node to update first
+	// with the canary upgrade strategy
+	canary *storage.UpdateServer
 }
 
 func shouldUpdateCoreDNS(client *kubernetes.Clientset) (bool, error) {
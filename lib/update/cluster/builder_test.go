@@ -19,11 +19,14 @@ package cluster
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gravitational/gravity/lib/app"
 	apptest "github.com/gravitational/gravity/lib/app/service/test"
 	"github.com/gravitational/gravity/lib/archive"
 	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/fsm"
 	"github.com/gravitational/gravity/lib/loc"
 	"github.com/gravitational/gravity/lib/ops"
 	"github.com/gravitational/gravity/lib/ops/opsservice"
@@ -33,6 +36,7 @@ import (
 	"github.com/gravitational/gravity/lib/update"
 
 	teleservices "github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/trace"
 	"gopkg.in/check.v1"
 )
 
@@ -148,6 +152,292 @@ func (s *PlanSuite) TestPlanWithoutRuntimeUpdate(c *check.C) {
 	})
 }
 
+func (s *PlanSuite) TestPlanWithUnchangedSystemSoftware(c *check.C) {
+	// setup
+	params := params{
+		installedRuntime:         loc.MustParseLocator("gravitational.io/runtime:1.0.0"),
+		installedApp:             loc.MustParseLocator("gravitational.io/app:1.0.0"),
+		updateRuntime:            loc.MustParseLocator("gravitational.io/runtime:2.0.0"),
+		updateApp:                loc.MustParseLocator("gravitational.io/app:2.0.0"),
+		installedRuntimeManifest: installedRuntimeManifest,
+		installedAppManifest:     installedAppManifest,
+		// same gravity package on purpose
+		updateRuntimeManifest: strings.Replace(updateRuntimeManifest,
+			"gravity:2.0.0", "gravity:1.0.0", 1),
+		updateAppManifest: updateAppManifest,
+		dnsConfig:         storage.DefaultDNSConfig,
+		leadMaster:        updates[0],
+	}
+	config := newTestPlan(c, params)
+	config.shouldUpdateEtcd = func(planConfig) (bool, string, string, error) {
+		return false, "", "", nil
+	}
+	config.servers = nil
+	for _, server := range updates {
+		server.Runtime.Update = nil
+		config.servers = append(config.servers, server)
+	}
+	config.leadMaster = config.servers[0]
+
+	// exercise
+	obtainedPlan, err := newOperationPlan(config)
+	c.Assert(err, check.IsNil)
+	update.ResolvePlan(obtainedPlan)
+
+	// verify
+	var phases []string
+	for _, phase := range obtainedPlan.Phases {
+		phases = append(phases, phase.ID)
+	}
+	c.Assert(phases, check.DeepEquals, []string{
+		"/init", "/checks", "/pre-update", "/bootstrap",
+		"/migration", "/config", "/runtime", "/app", "/gc",
+	})
+	c.Assert(obtainedPlan.Phases[5].Requires, check.DeepEquals,
+		[]string{"/checks", "/bootstrap", "/pre-update"})
+	c.Assert(obtainedPlan.Phases[6].Requires, check.DeepEquals,
+		[]string{"/checks", "/bootstrap", "/pre-update"})
+}
+
+func (s *PlanSuite) TestPlanWithCanaryStrategy(c *check.C) {
+	// setup
+	params := params{
+		installedRuntime:         loc.MustParseLocator("gravitational.io/runtime:1.0.0"),
+		installedApp:             loc.MustParseLocator("gravitational.io/app:1.0.0"),
+		updateRuntime:            loc.MustParseLocator("gravitational.io/runtime:2.0.0"),
+		updateApp:                loc.MustParseLocator("gravitational.io/app:2.0.0"),
+		installedRuntimeManifest: installedRuntimeManifest,
+		installedAppManifest:     installedAppManifest,
+		updateRuntimeManifest:    updateRuntimeManifest,
+		updateAppManifest:        updateAppManifest,
+		dnsConfig:                storage.DefaultDNSConfig,
+		leadMaster:               updates[0],
+	}
+	config := newTestPlan(c, params)
+	config.strategy = storage.UpdateStrategy{
+		Type:       schema.UpgradeStrategyCanary,
+		SoakPeriod: 30 * time.Minute,
+	}
+
+	// exercise
+	obtainedPlan, err := newOperationPlan(config)
+	c.Assert(err, check.IsNil)
+	update.ResolvePlan(obtainedPlan)
+
+	// verify
+	nodes, err := fsm.FindPhase(obtainedPlan, "/nodes")
+	c.Assert(err, check.IsNil)
+	c.Assert(*nodes, check.DeepEquals, storage.OperationPhase{
+		ID:          "/nodes",
+		Description: "Update regular nodes",
+		Requires:    []string{"/masters"},
+		Phases: []storage.OperationPhase{
+			params.nodePhase(updates[2]),
+			params.canary("/nodes", updates[2], []string{"/nodes/node-3"}, "30m0s"),
+		},
+	})
+}
+
+func (s *PlanSuite) TestPlanWithSmokeTests(c *check.C) {
+	// setup
+	params := params{
+		installedRuntime:         loc.MustParseLocator("gravitational.io/runtime:1.0.0"),
+		installedApp:             loc.MustParseLocator("gravitational.io/app:1.0.0"),
+		updateRuntime:            loc.MustParseLocator("gravitational.io/runtime:2.0.0"),
+		updateApp:                loc.MustParseLocator("gravitational.io/app:2.0.0"),
+		installedRuntimeManifest: installedRuntimeManifest,
+		installedAppManifest:     installedAppManifest,
+		updateRuntimeManifest:    updateRuntimeManifest,
+		updateAppManifest: updateAppManifest + `smokeTests:
+  - name: frontend
+    http:
+      url: http://frontend.default.svc.cluster.local
+  - name: install-only
+    operations: [install]
+    http:
+      url: http://frontend.default.svc.cluster.local
+`,
+		dnsConfig:  storage.DefaultDNSConfig,
+		leadMaster: updates[0],
+	}
+	config := newTestPlan(c, params)
+
+	// exercise
+	obtainedPlan, err := newOperationPlan(config)
+	c.Assert(err, check.IsNil)
+	update.ResolvePlan(obtainedPlan)
+
+	// verify
+	smokeTests, err := fsm.FindPhase(obtainedPlan, "/smoke-tests")
+	c.Assert(err, check.IsNil)
+	c.Assert(*smokeTests, check.DeepEquals, storage.OperationPhase{
+		ID:          "/smoke-tests",
+		Description: "Run application smoke tests",
+		Requires:    []string{"/app"},
+		Phases: []storage.OperationPhase{
+			{
+				ID:          "/smoke-tests/frontend",
+				Executor:    smokeTest,
+				Description: "Run smoke test frontend",
+				Data: &storage.OperationPhaseData{
+					Package: &params.updateApp,
+					Data:    "frontend",
+				},
+			},
+		},
+	})
+	gc, err := fsm.FindPhase(obtainedPlan, "/gc")
+	c.Assert(err, check.IsNil)
+	c.Assert(gc.Requires, check.DeepEquals, []string{"/smoke-tests"})
+}
+
+func (s *PlanSuite) TestPlanWithCanaryMaster(c *check.C) {
+	// setup
+	params := params{
+		installedRuntime:         loc.MustParseLocator("gravitational.io/runtime:1.0.0"),
+		installedApp:             loc.MustParseLocator("gravitational.io/app:1.0.0"),
+		updateRuntime:            loc.MustParseLocator("gravitational.io/runtime:2.0.0"),
+		updateApp:                loc.MustParseLocator("gravitational.io/app:2.0.0"),
+		installedRuntimeManifest: installedRuntimeManifest,
+		installedAppManifest:     installedAppManifest,
+		updateRuntimeManifest:    updateRuntimeManifest,
+		updateAppManifest:        updateAppManifest,
+		dnsConfig:                storage.DefaultDNSConfig,
+		leadMaster:               updates[0],
+	}
+	config := newTestPlan(c, params)
+	config.strategy = storage.UpdateStrategy{
+		Type:       schema.UpgradeStrategyCanary,
+		CanaryNode: "node-1",
+	}
+
+	// exercise
+	obtainedPlan, err := newOperationPlan(config)
+	c.Assert(err, check.IsNil)
+	update.ResolvePlan(obtainedPlan)
+
+	// verify
+	masters, err := fsm.FindPhase(obtainedPlan, "/masters")
+	c.Assert(err, check.IsNil)
+	c.Assert(masters.Phases[2], check.DeepEquals,
+		params.canary("/masters", updates[0], []string{"/masters/elect-node-1"}, ""))
+	c.Assert(masters.Phases[3].Requires, check.DeepEquals, []string{"/masters/canary"})
+
+	// another master cannot be a canary
+	config.strategy.CanaryNode = "node-2"
+	_, err = newOperationPlan(config)
+	c.Assert(trace.IsNotFound(err), check.Equals, true)
+}
+
+func (s *PlanSuite) TestUpdatesRegularNodesInBatches(c *check.C) {
+	var nodes []storage.UpdateServer
+	for i := 3; i <= 7; i++ {
+		node := updates[2]
+		node.Hostname = fmt.Sprintf("node-%v", i)
+		node.AdvertiseIP = fmt.Sprintf("192.168.0.%v", i)
+		nodes = append(nodes, node)
+	}
+	builder := phaseBuilder{
+		planConfig: planConfig{
+			strategy: storage.UpdateStrategy{MaxUnavailablePercent: 40},
+		},
+		canary: &nodes[4],
+	}
+
+	phase := builder.nodes(updates[0], nodes, true)
+	plan := storage.OperationPlan{Phases: []storage.OperationPhase{storage.OperationPhase(*phase)}}
+	update.ResolvePlan(&plan)
+
+	type phaseSummary struct {
+		id       string
+		parallel bool
+		requires []string
+	}
+	var obtained []phaseSummary
+	for _, phase := range plan.Phases[0].Phases {
+		obtained = append(obtained, phaseSummary{
+			id:       phase.ID,
+			parallel: phase.Parallel,
+			requires: phase.Requires,
+		})
+	}
+	c.Assert(obtained, check.DeepEquals, []phaseSummary{
+		{id: "/nodes/node-7"},
+		{id: "/nodes/canary", requires: []string{"/nodes/node-7"}},
+		{id: "/nodes/batch-1", parallel: true, requires: []string{"/nodes/canary"}},
+		{id: "/nodes/batch-2", parallel: true, requires: []string{"/nodes/batch-1"}},
+	})
+	batch := plan.Phases[0].Phases[2]
+	c.Assert(batch.Phases, check.HasLen, 2)
+	c.Assert(batch.Phases[0].ID, check.Equals, "/nodes/batch-1/node-3")
+	c.Assert(batch.Phases[1].ID, check.Equals, "/nodes/batch-1/node-4")
+	c.Assert(batch.Phases[1].Requires, check.IsNil)
+	c.Assert(batch.Phases[1].Phases[0].ID, check.Equals, "/nodes/batch-1/node-4/drain")
+	batch = plan.Phases[0].Phases[3]
+	c.Assert(batch.Phases, check.HasLen, 2)
+	c.Assert(batch.Phases[1].ID, check.Equals, "/nodes/batch-2/node-6")
+}
+
+func (s *PlanSuite) TestDrainPhaseUsesOperationDrainPolicy(c *check.C) {
+	policy := storage.DrainPolicy{
+		GracePeriod: time.Minute,
+		ForceAfter:  10 * time.Minute,
+		SkipNodes:   []string{"node-3"},
+	}
+	op := operation
+	op.Update = &storage.UpdateOperationState{Drain: &policy}
+	builder := phaseBuilder{planConfig: planConfig{operation: op}}
+
+	phases := builder.commonNode(updates[2], updates[0], true, waitsForEndpoints(true))
+	c.Assert(phases[0].ID, check.Equals, "drain")
+	c.Assert(phases[0].Data.Drain, check.DeepEquals, &policy)
+	c.Assert(policy.ShouldSkip(updates[2].Server), check.Equals, true)
+	c.Assert(policy.ShouldSkip(updates[1].Server), check.Equals, false)
+
+	builder = phaseBuilder{planConfig: planConfig{operation: operation}}
+	phases = builder.commonNode(updates[2], updates[0], true, waitsForEndpoints(true))
+	c.Assert(phases[0].Data.Drain, check.IsNil)
+}
+
+func (s *PlanSuite) TestResolvesUpdateStrategy(c *check.C) {
+	manifest := schema.MustParseManifestYAML([]byte(updateAppManifest))
+	strategy, err := updateStrategy(operation, manifest)
+	c.Assert(err, check.IsNil)
+	c.Assert(*strategy, check.DeepEquals, storage.UpdateStrategy{
+		Type: schema.UpgradeStrategyRolling,
+	})
+
+	manifest.Upgrade = &schema.Upgrade{
+		Strategy: schema.UpgradeStrategyCanary,
+		Canary: &schema.Canary{
+			Node:       "node-3",
+			SoakPeriod: "1h",
+		},
+		MaxUnavailable: "25%",
+	}
+	strategy, err = updateStrategy(operation, manifest)
+	c.Assert(err, check.IsNil)
+	c.Assert(*strategy, check.DeepEquals, storage.UpdateStrategy{
+		Type:                  schema.UpgradeStrategyCanary,
+		CanaryNode:            "node-3",
+		SoakPeriod:            time.Hour,
+		MaxUnavailablePercent: 25,
+	})
+
+	op := operation
+	op.Update = &storage.UpdateOperationState{
+		Strategy: &storage.UpdateStrategy{SoakPeriod: time.Minute, BatchSize: 5},
+	}
+	strategy, err = updateStrategy(op, manifest)
+	c.Assert(err, check.IsNil)
+	c.Assert(*strategy, check.DeepEquals, storage.UpdateStrategy{
+		Type:       schema.UpgradeStrategyCanary,
+		CanaryNode: "node-3",
+		SoakPeriod: time.Minute,
+		BatchSize:  5,
+	})
+}
+
 func (s *PlanSuite) TestUpdatesEtcdFromManifestWithoutLabels(c *check.C) {
 	services := opsservice.SetupTestServices(c)
 	files := []*archive.Item{
@@ -633,6 +923,45 @@ func (r *params) nodePhase(server storage.UpdateServer) storage.OperationPhase {
 	}
 }
 
+func (r params) canary(parent string, server storage.UpdateServer, requires []string, soakPeriod string) storage.OperationPhase {
+	t := func(format string) string {
+		return fmt.Sprintf(format, parent)
+	}
+	approve := storage.OperationPhase{
+		ID:          t("%v/canary/approve"),
+		Executor:    canaryApprove,
+		Description: "Wait for approval to update the remaining nodes",
+		Data: &storage.OperationPhaseData{
+			Server:     &server.Server,
+			ExecServer: &r.leadMaster.Server,
+			Package:    &r.installedApp,
+		},
+		Requires: []string{t("%v/canary/health")},
+	}
+	if soakPeriod != "" {
+		approve.Description = fmt.Sprintf("Observe canary node %q for %v", server.Hostname, soakPeriod)
+		approve.Data.Data = soakPeriod
+	}
+	return storage.OperationPhase{
+		ID:          t("%v/canary"),
+		Description: fmt.Sprintf("Verify canary node %q", server.Hostname),
+		Requires:    requires,
+		Phases: []storage.OperationPhase{
+			{
+				ID:          t("%v/canary/health"),
+				Executor:    canaryHealth,
+				Description: fmt.Sprintf("Run health checks on canary node %q", server.Hostname),
+				Data: &storage.OperationPhaseData{
+					Server:     &server.Server,
+					ExecServer: &r.leadMaster.Server,
+					Package:    &r.installedApp,
+				},
+			},
+			approve,
+		},
+	}
+}
+
 func (r params) etcd(otherMasters []storage.UpdateServer) storage.OperationPhase {
 	return storage.OperationPhase{
 		ID:          "/etcd",
@@ -19,10 +19,13 @@ package cluster
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/gravitational/gravity/lib/app"
 	"github.com/gravitational/gravity/lib/checks"
+	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/fsm"
 	"github.com/gravitational/gravity/lib/ops"
@@ -85,6 +88,9 @@ type Config struct {
 	// Spec is used to retrieve a phase executor, allows
 	// plugging different phase executors during tests
 	Spec fsm.FSMSpecFunc
+	// AgentReconnectTimeout specifies how long to wait for the agent on a node
+	// to reconnect after the node has rebooted before failing the phase
+	AgentReconnectTimeout time.Duration
 }
 
 // newMachine returns a new FSM instance
@@ -101,13 +107,15 @@ func newMachine(ctx context.Context, c Config) (*fsm.FSM, error) {
 		return nil, trace.Wrap(err)
 	}
 	fsm, err := fsm.New(fsm.Config{
-		Engine: engine,
-		Logger: logger,
-		Runner: c.Runner,
+		Engine:                engine,
+		Logger:                logger,
+		Runner:                c.Runner,
+		AgentReconnectTimeout: c.AgentReconnectTimeout,
 	})
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+	fsm.SetPreExec(engine.checkApproval)
 	return fsm, nil
 }
 
@@ -117,6 +125,8 @@ type engine struct {
 	Config
 	// FieldLogger is used for logging
 	logrus.FieldLogger
+	// mu guards the plan as phases can be executed in parallel
+	mu sync.Mutex
 	// plan is the update operation plan
 	plan       storage.OperationPlan
 	reconciler update.Reconciler
@@ -160,7 +170,11 @@ func (f *engine) GetExecutor(p fsm.ExecutorParams, remote fsm.Remote) (fsm.Phase
 // RunCommand executes the phase specified by params on the specified server
 // using the provided runner
 func (f *engine) RunCommand(ctx context.Context, runner rpc.RemoteRunner, server storage.Server, p fsm.Params) error {
-	args := []string{"plan", "execute",
+	command := "execute"
+	if p.Rollback {
+		command = "rollback"
+	}
+	args := []string{"plan", command,
 		"--phase", p.PhaseID,
 		"--operation-id", f.plan.OperationID,
 	}
@@ -180,6 +194,70 @@ func (f *engine) PostExecute(ctx context.Context, p fsm.Params) error {
 	return nil
 }
 
+// checkApproval prevents the execution of phases until the operation
+// has been approved if it requires approval
+func (f *engine) checkApproval(ctx context.Context, p fsm.Params) error {
+	if err := f.CheckApproval(); err != nil {
+		return trace.Wrap(err)
+	}
+	return f.waitForMaintenanceWindow(ctx, p)
+}
+
+// waitForMaintenanceWindow holds off the execution of a node-disruptive phase
+// until the maintenance window of the operation opens.
+// The window is only enforced when the plan is executed as a whole
+func (f *engine) waitForMaintenanceWindow(ctx context.Context, p fsm.Params) error {
+	if !p.Resume || f.Operation.Update == nil || f.Operation.Update.Schedule == nil {
+		return nil
+	}
+	plan, err := f.GetPlan()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	phase, err := fsm.FindPhase(plan, p.PhaseID)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if !utils.StringInSlice(nodeDisruptivePhases, phase.Executor) {
+		return nil
+	}
+	window := *f.Operation.Update.Schedule
+	now := time.Now()
+	if window.Contains(now) {
+		return nil
+	}
+	next := window.Next(now)
+	message := fmt.Sprintf("Waiting for maintenance window %v to execute phase %v at %v",
+		window, phase.ID, next.UTC().Format(constants.HumanDateFormat))
+	f.Info(message)
+	f.setProgressMessage(message)
+	select {
+	case <-time.After(next.Sub(now)):
+		return nil
+	case <-ctx.Done():
+		return trace.Wrap(ctx.Err())
+	}
+}
+
+// setProgressMessage updates the progress of the operation with the specified message
+func (f *engine) setProgressMessage(message string) {
+	key := f.Operation.Key()
+	entry := ops.ProgressEntry{
+		SiteDomain:  key.SiteDomain,
+		OperationID: key.OperationID,
+		State:       ops.ProgressStateInProgress,
+		Message:     message,
+		Created:     time.Now().UTC(),
+	}
+	if progress, err := f.Operator.GetSiteOperationProgress(key); err == nil {
+		entry.Completion = progress.Completion
+		entry.Step = progress.Step
+	}
+	if err := f.Operator.CreateProgressEntry(key, entry); err != nil {
+		f.WithError(err).Warn("Failed to create progress entry.")
+	}
+}
+
 // PreRollback is no-op for the update engine
 func (f *engine) PreRollback(ctx context.Context, p fsm.Params) error {
 	return nil
@@ -201,16 +279,20 @@ func (f *engine) Complete(fsmErr error) error {
 
 	stateSetter := fsm.OperationStateSetter(opKey, f.Operator, f.LocalBackend)
 	completed := fsm.IsCompleted(plan)
-	if completed {
+	rolledBack := !completed && fsm.IsRolledBack(plan)
+	switch {
+	case completed:
 		err = ops.CompleteOperation(opKey, stateSetter)
-	} else {
+	case rolledBack:
+		err = ops.RollbackOperation(opKey, stateSetter, trace.Unwrap(fsmErr).Error())
+	default:
 		err = ops.FailOperation(opKey, stateSetter, trace.Unwrap(fsmErr).Error())
 	}
 	if err != nil {
 		return trace.Wrap(err)
 	}
 
-	if !completed {
+	if !completed && !rolledBack {
 		return nil
 	}
 
@@ -219,6 +301,11 @@ func (f *engine) Complete(fsmErr error) error {
 		return trace.Wrap(err)
 	}
 
+	if rolledBack {
+		// The cluster has been restored to the state before the update
+		return trace.Wrap(f.activateCluster(*cluster))
+	}
+
 	err = f.commitClusterChanges(cluster, *op)
 	if err != nil {
 		return trace.Wrap(err)
@@ -230,7 +317,10 @@ func (f *engine) Complete(fsmErr error) error {
 
 // GetPlan returns an up-to-date plan
 func (f *engine) GetPlan() (*storage.OperationPlan, error) {
-	return &f.plan, nil
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	plan := copyPlan(f.plan)
+	return &plan, nil
 }
 
 func (f *engine) commitClusterChanges(cluster *storage.Site, op ops.SiteOperation) error {
@@ -280,7 +370,7 @@ func (f *engine) activateCluster(cluster storage.Site) error {
 func (f *engine) ChangePhaseState(ctx context.Context, change fsm.StateChange) error {
 	f.WithField("change", change).Debug("Apply.")
 
-	_, err := f.LocalBackend.CreateOperationPlanChange(storage.PlanChange{
+	_, err := storage.AppendOperationPlanChange(f.LocalBackend, storage.PlanChange{
 		ID:          uuid.New(),
 		ClusterName: f.plan.ClusterName,
 		OperationID: f.plan.OperationID,
@@ -303,17 +393,39 @@ func (f *engine) ChangePhaseState(ctx context.Context, change fsm.StateChange) e
 }
 
 func (f *engine) reconcilePlan(ctx context.Context) error {
-	plan, err := f.reconciler.ReconcilePlan(ctx, f.plan)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	plan, err := f.reconciler.ReconcilePlan(ctx, copyPlan(f.plan))
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	f.plan = *plan
+	// Only the phase states change as a result of reconciliation
+	f.plan.Phases = plan.Phases
 	var buf bytes.Buffer
 	fsm.FormatOperationPlanText(&buf, f.plan)
 	f.Debugf("Reconciled plan: %v.", buf.String())
 	return nil
 }
 
+// copyPlan returns a copy of the specified plan with its own phase tree
+// so the phase states can be updated without affecting the original plan
+func copyPlan(plan storage.OperationPlan) storage.OperationPlan {
+	plan.Phases = copyPhases(plan.Phases)
+	return plan
+}
+
+func copyPhases(phases []storage.OperationPhase) []storage.OperationPhase {
+	if phases == nil {
+		return nil
+	}
+	result := make([]storage.OperationPhase, len(phases))
+	for i, phase := range phases {
+		result[i] = phase
+		result[i].Phases = copyPhases(phase.Phases)
+	}
+	return result
+}
+
 func loadPlan(backend storage.Backend, opKey ops.SiteOperationKey, logger logrus.FieldLogger) (*storage.OperationPlan, error) {
 	plan, err := backend.GetOperationPlan(opKey.SiteDomain, opKey.OperationID)
 	if err != nil && !trace.IsNotFound(err) {
@@ -25,6 +25,7 @@ import (
 	"github.com/gravitational/gravity/lib/ops/opsservice"
 	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/update"
+	"github.com/gravitational/gravity/lib/utils"
 
 	"github.com/gravitational/trace"
 	"github.com/sirupsen/logrus"
@@ -59,7 +60,7 @@ func (s *FSMSuite) SetUpTest(c *check.C) {
 			Spec:     getTestExecutor(),
 		},
 		FieldLogger: logger,
-		reconciler:  &testReconciler{},
+		reconciler:  &testReconciler{backend: services.Backend},
 	}
 	s.fsm = &fsm.FSM{
 		Config:      fsm.Config{Engine: s.engine},
@@ -167,6 +168,41 @@ func (s *FSMSuite) TestFSMExecutePhaseWithSubphases(c *check.C) {
 	})
 }
 
+func (s *FSMSuite) TestFSMRollbackPlan(c *check.C) {
+	plan := storage.OperationPlan{
+		OperationID:   operationID,
+		OperationType: "test_operation",
+		ClusterName:   clusterName,
+		Phases: []storage.OperationPhase{
+			{ID: "/phase1", Phases: []storage.OperationPhase{
+				{ID: "/phase1/sub1"},
+				{ID: "/phase1/sub2"},
+			}},
+			{ID: "/phase2", Requires: []string{"/phase1"}},
+		},
+	}
+
+	s.engine.plan = plan
+	ctx := context.TODO()
+
+	err := s.fsm.ExecutePhase(ctx, fsm.Params{
+		PhaseID: "/phase1",
+	})
+	c.Assert(err, check.IsNil)
+
+	err = s.fsm.RollbackPlan(ctx, utils.DiscardProgress)
+	c.Assert(err, check.IsNil)
+
+	resolvedPlan := s.resolvePlan(c, plan)
+	checkStates(c, resolvedPlan, map[string]string{
+		"/phase1":      storage.OperationPhaseStateRolledBack,
+		"/phase1/sub1": storage.OperationPhaseStateRolledBack,
+		"/phase1/sub2": storage.OperationPhaseStateRolledBack,
+		"/phase2":      storage.OperationPhaseStateUnstarted,
+	})
+	c.Assert(fsm.IsRolledBack(resolvedPlan), check.Equals, true)
+}
+
 func (s *FSMSuite) resolvePlan(c *check.C, plan storage.OperationPlan) *storage.OperationPlan {
 	changelog, err := s.engine.LocalBackend.GetOperationPlanChangelog(plan.ClusterName, plan.OperationID)
 	c.Assert(err, check.IsNil)
@@ -232,7 +268,14 @@ func (p *testPhase2) Rollback(context.Context) error {
 }
 
 func (r *testReconciler) ReconcilePlan(ctx context.Context, plan storage.OperationPlan) (*storage.OperationPlan, error) {
-	return &plan, nil
+	changelog, err := r.backend.GetOperationPlanChangelog(plan.ClusterName, plan.OperationID)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return fsm.ResolvePlan(plan, changelog), nil
 }
 
-type testReconciler struct{}
+// testReconciler resolves the plan using the changelog from the local backend
+type testReconciler struct {
+	backend storage.Backend
+}
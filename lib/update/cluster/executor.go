@@ -77,8 +77,29 @@ const (
 	updateEtcdRestartGravity = "etcd_restart_gravity"
 	// cleanupNode is the phase to clean up a node after the upgrade
 	cleanupNode = "cleanup_node"
+	// canaryHealth is the phase to run health checks after the canary node has been upgraded
+	canaryHealth = "canary_health"
+	// canaryApprove is the phase to wait for the canary node to be approved
+	canaryApprove = "canary_approve"
+	// smokeTest is the phase to run an application smoke test
+	smokeTest = "smoke_test"
 )
 
+// nodeDisruptivePhases lists the phases that disrupt workloads or services
+// on the nodes. With a maintenance window, these phases are only executed
+// while the window is open
+var nodeDisruptivePhases = []string{
+	electionStatus,
+	taintNode,
+	drainNode,
+	updateSystem,
+	updateEtcdShutdown,
+	updateEtcdMaster,
+	updateEtcdRestore,
+	updateEtcdRestart,
+	updateEtcdRestartGravity,
+}
+
 // fsmSpec returns the function that returns an appropriate phase executor
 func fsmSpec(c Config) fsm.FSMSpecFunc {
 	return func(p fsm.ExecutorParams, remote fsm.Remote) (fsm.PhaseExecutor, error) {
@@ -158,6 +179,12 @@ func fsmSpec(c Config) fsm.FSMSpecFunc {
 			return libphase.NewPhaseUpgradeGravitySiteRestart(p.Phase, c.Client, logger)
 		case cleanupNode:
 			return libphase.NewGarbageCollectPhase(p, remote, logger)
+		case canaryHealth:
+			return libphase.NewPhaseCanaryHealth(p, c.Operator, c.Apps, c.Client, logger)
+		case canaryApprove:
+			return libphase.NewPhaseCanaryApprove(p, c.Operator, c.Apps, c.Client, logger)
+		case smokeTest:
+			return libphase.NewPhaseSmokeTest(p, c.Operator, c.Apps, logger)
 		default:
 			return nil, trace.BadParameter(
 				"phase %q requires executor %q (potential mismatch between upgrade versions)",
@@ -0,0 +1,143 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// Release describes a cluster image that can be upgraded to
+type Release struct {
+	// Package identifies the cluster image
+	Package loc.Locator
+	// RuntimeVersion is the version of the runtime the cluster image is based on
+	RuntimeVersion semver.Version
+}
+
+// NewRelease returns a new release for the specified cluster image
+func NewRelease(image app.Application) (*Release, error) {
+	runtimePackage, err := image.Manifest.Dependencies.ByName(constants.GravityPackage)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	runtimeVersion, err := runtimePackage.SemVer()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &Release{
+		Package:        image.Package,
+		RuntimeVersion: *runtimeVersion,
+	}, nil
+}
+
+// GetUpgradePath returns the list of cluster images to upgrade through in order
+// to upgrade the cluster from the installed image to the specified target image.
+// Intermediate images are looked up among the versions of the target image
+// available in the cluster
+func GetUpgradePath(apps app.Applications, installed, target app.Application) ([]Release, error) {
+	installedRelease, err := NewRelease(installed)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	targetRelease, err := NewRelease(target)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if IsDirectUpgrade(installedRelease.RuntimeVersion, targetRelease.RuntimeVersion) {
+		return []Release{*targetRelease}, nil
+	}
+	images, err := apps.ListApps(app.ListAppsRequest{
+		Repository: target.Package.Repository,
+		Type:       storage.AppUser,
+		Pattern:    target.Package.Name,
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var available []Release
+	for _, image := range images {
+		if image.Package.Name != target.Package.Name {
+			continue
+		}
+		release, err := NewRelease(image)
+		if err != nil {
+			log.WithError(err).Warnf("Skip cluster image %v.", image.Package)
+			continue
+		}
+		available = append(available, *release)
+	}
+	return UpgradePath(installedRelease.RuntimeVersion, *targetRelease, available)
+}
+
+// UpgradePath returns the list of releases to upgrade through in order to upgrade
+// from the installed runtime version to the target release.
+// The target release is always the last element of the path.
+// For each release line between the installed and the target versions,
+// the latest release of that line from available is selected
+func UpgradePath(installed semver.Version, target Release, available []Release) ([]Release, error) {
+	from, to := releaseLine(installed), releaseLine(target.RuntimeVersion)
+	if to <= from+1 {
+		return []Release{target}, nil
+	}
+	var path []Release
+	for line := from + 1; line < to; line++ {
+		var latest *Release
+		for i, release := range available {
+			if releaseLine(release.RuntimeVersion) != line {
+				continue
+			}
+			if latest == nil || latest.RuntimeVersion.LessThan(release.RuntimeVersion) {
+				latest = &available[i]
+			}
+		}
+		if latest == nil {
+			return nil, trace.NotFound("upgrade from runtime version %v to %v requires "+
+				"an intermediate cluster image based on the %v release line. "+
+				"Upload a cluster image %v based on runtime version %v or later to the cluster and retry.",
+				installed, target.RuntimeVersion, defaults.UpgradeReleaseLines[line],
+				target.Package.Name, defaults.UpgradeReleaseLines[line])
+		}
+		path = append(path, *latest)
+	}
+	return append(path, target), nil
+}
+
+// IsDirectUpgrade returns true if the runtime of the installed version
+// can be upgraded to the target version without intermediate upgrades
+func IsDirectUpgrade(installed, target semver.Version) bool {
+	return releaseLine(target) <= releaseLine(installed)+1
+}
+
+// releaseLine returns the index of the release line the specified version
+// belongs to or -1 if the version predates all known release lines
+func releaseLine(version semver.Version) int {
+	line := -1
+	for i, start := range defaults.UpgradeReleaseLines {
+		if start.Compare(version) <= 0 {
+			line = i
+		}
+	}
+	return line
+}
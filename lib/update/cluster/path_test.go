@@ -0,0 +1,74 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"github.com/gravitational/gravity/lib/loc"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/gravitational/trace"
+	"gopkg.in/check.v1"
+)
+
+type PathSuite struct{}
+
+var _ = check.Suite(&PathSuite{})
+
+func (s *PathSuite) TestDirectUpgrade(c *check.C) {
+	target := newRelease("2.0.0", "5.0.3")
+	path, err := UpgradePath(*semver.New("4.68.0"), target, nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(path, check.DeepEquals, []Release{target})
+
+	c.Assert(IsDirectUpgrade(*semver.New("5.0.0"), *semver.New("5.2.1")), check.Equals, true)
+	c.Assert(IsDirectUpgrade(*semver.New("4.68.0"), *semver.New("5.2.1")), check.Equals, false)
+}
+
+func (s *PathSuite) TestUpgradesThroughIntermediateReleases(c *check.C) {
+	target := newRelease("3.0.0", "5.5.2")
+	available := []Release{
+		newRelease("2.0.0", "5.0.3"),
+		newRelease("2.1.0", "5.0.12"),
+		newRelease("2.2.0", "5.2.4"),
+		newRelease("1.0.0", "4.68.0"),
+		target,
+	}
+	path, err := UpgradePath(*semver.New("4.68.0"), target, available)
+	c.Assert(err, check.IsNil)
+	c.Assert(path, check.DeepEquals, []Release{
+		newRelease("2.1.0", "5.0.12"),
+		newRelease("2.2.0", "5.2.4"),
+		target,
+	})
+}
+
+func (s *PathSuite) TestFailsWithoutIntermediateRelease(c *check.C) {
+	target := newRelease("3.0.0", "5.5.2")
+	available := []Release{
+		newRelease("2.0.0", "5.0.3"),
+		target,
+	}
+	_, err := UpgradePath(*semver.New("4.68.0"), target, available)
+	c.Assert(trace.IsNotFound(err), check.Equals, true, check.Commentf("%v", err))
+}
+
+func newRelease(version, runtimeVersion string) Release {
+	return Release{
+		Package:        loc.MustCreateLocator("gravitational.io", "app", version),
+		RuntimeVersion: *semver.New(runtimeVersion),
+	}
+}
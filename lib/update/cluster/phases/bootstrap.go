@@ -232,6 +232,15 @@ func (p *updatePhaseBootstrap) pullSystemUpdates(ctx context.Context) error {
 		}
 	}
 	for _, update := range updates {
+		upToDate, err := pack.IsPackageUpToDate(p.Packages, p.LocalPackages, update)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if upToDate {
+			// The package has been staged with 'gravity upgrade --stage'
+			p.Infof("Package %v has already been pulled.", update)
+			continue
+		}
 		p.Infof("Pulling package update: %v.", update)
 		existingLabels, err := queryPackageLabels(update, p.LocalPackages)
 		if err != nil {
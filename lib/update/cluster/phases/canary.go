@@ -0,0 +1,198 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/schema"
+	libstatus "github.com/gravitational/gravity/lib/status"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/update"
+
+	pb "github.com/gravitational/satellite/agent/proto/agentpb"
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
+)
+
+// phaseCanaryHealth is the executor that verifies the health of the cluster
+// after the canary node has been upgraded
+type phaseCanaryHealth struct {
+	canaryChecker
+}
+
+// NewPhaseCanaryHealth returns a new executor for the canary health checks
+func NewPhaseCanaryHealth(
+	p fsm.ExecutorParams,
+	operator ops.Operator,
+	apps app.Applications,
+	client *kubernetes.Clientset,
+	logger log.FieldLogger,
+) (*phaseCanaryHealth, error) {
+	checker, err := newCanaryChecker(p, operator, apps, client, logger)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &phaseCanaryHealth{
+		canaryChecker: *checker,
+	}, nil
+}
+
+// Execute runs the health checks
+func (p *phaseCanaryHealth) Execute(ctx context.Context) error {
+	return trace.Wrap(p.checkHealth(ctx))
+}
+
+// Rollback is a no-op for this phase
+func (p *phaseCanaryHealth) Rollback(context.Context) error {
+	return nil
+}
+
+// phaseCanaryApprove is the executor that holds off the upgrade of the remaining
+// nodes until the canary node is approved
+type phaseCanaryApprove struct {
+	canaryChecker
+	// SoakPeriod specifies the duration to observe the canary node before
+	// proceeding automatically. If zero, an explicit approval is required
+	SoakPeriod time.Duration
+}
+
+// NewPhaseCanaryApprove returns a new executor for the canary approval phase
+func NewPhaseCanaryApprove(
+	p fsm.ExecutorParams,
+	operator ops.Operator,
+	apps app.Applications,
+	client *kubernetes.Clientset,
+	logger log.FieldLogger,
+) (*phaseCanaryApprove, error) {
+	checker, err := newCanaryChecker(p, operator, apps, client, logger)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var soakPeriod time.Duration
+	if p.Phase.Data.Data != "" {
+		soakPeriod, err = time.ParseDuration(p.Phase.Data.Data)
+		if err != nil {
+			return nil, trace.Wrap(err, "invalid soak period %q", p.Phase.Data.Data)
+		}
+	}
+	return &phaseCanaryApprove{
+		canaryChecker: *checker,
+		SoakPeriod:    soakPeriod,
+	}, nil
+}
+
+// Execute waits for the soak period to elapse and re-runs the health checks.
+// Without a soak period, it pauses the operation until explicitly approved
+func (p *phaseCanaryApprove) Execute(ctx context.Context) error {
+	if p.SoakPeriod == 0 {
+		return update.NewApprovalRequiredError("canary node %v has been upgraded, "+
+			"verify the cluster and run 'gravity upgrade --approve' to upgrade the remaining nodes",
+			p.Server.Hostname)
+	}
+	p.Infof("Observe canary node %v for %v.", p.Server.Hostname, p.SoakPeriod)
+	select {
+	case <-time.After(p.SoakPeriod):
+	case <-ctx.Done():
+		return trace.Wrap(ctx.Err())
+	}
+	return trace.Wrap(p.checkHealth(ctx))
+}
+
+// Rollback is a no-op for this phase
+func (p *phaseCanaryApprove) Rollback(context.Context) error {
+	return nil
+}
+
+func newCanaryChecker(
+	p fsm.ExecutorParams,
+	operator ops.Operator,
+	apps app.Applications,
+	client *kubernetes.Clientset,
+	logger log.FieldLogger,
+) (*canaryChecker, error) {
+	if p.Phase.Data == nil || p.Phase.Data.Server == nil {
+		return nil, trace.NotFound("no server specified for phase %q", p.Phase.ID)
+	}
+	if p.Phase.Data.Package == nil {
+		return nil, trace.NotFound("no package specified for phase %q", p.Phase.ID)
+	}
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &canaryChecker{
+		phaseApp: phaseApp{
+			FieldLogger:    logger,
+			Apps:           apps,
+			Client:         client,
+			GravityPackage: p.Plan.GravityPackage,
+			Package:        *p.Phase.Data.Package,
+			Servers:        p.Plan.Servers,
+			ServiceUser:    cluster.ServiceUser,
+		},
+		Server: *p.Phase.Data.Server,
+	}, nil
+}
+
+// canaryChecker verifies the health of the cluster after the canary node upgrade
+type canaryChecker struct {
+	phaseApp
+	// Server is the canary node
+	Server storage.Server
+}
+
+// checkHealth waits for the cluster and the canary node to become healthy
+// and runs the application status hook
+func (p *canaryChecker) checkHealth(ctx context.Context) error {
+	p.Infof("Wait for canary node %v to become healthy.", p.Server.Hostname)
+	err := update.Retry(ctx, func() error {
+		return trace.Wrap(p.checkNodeStatus(ctx))
+	}, defaults.CanaryHealthTimeout)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(p.runHooks(ctx, schema.HookStatus))
+}
+
+func (p *canaryChecker) checkNodeStatus(ctx context.Context) error {
+	status, err := libstatus.FromPlanetAgent(ctx, p.Servers)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if status.GetSystemStatus() != pb.SystemStatus_Running {
+		return trace.BadParameter("cluster is %v", status.SystemStatus)
+	}
+	for _, node := range status.Nodes {
+		if node.AdvertiseIP != p.Server.AdvertiseIP {
+			continue
+		}
+		if node.Status != libstatus.NodeHealthy {
+			return trace.BadParameter("canary node %v is %v: %v", p.Server.Hostname,
+				node.Status, strings.Join(node.FailedProbes, ", "))
+		}
+		return nil
+	}
+	return trace.NotFound("no status for canary node %v", p.Server.Hostname)
+}
@@ -22,15 +22,14 @@ import (
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/fsm"
-	libinstall "github.com/gravitational/gravity/lib/install/phases"
 	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/opsservice"
 	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/systeminfo"
 
 	"github.com/gravitational/rigging"
 	"github.com/gravitational/trace"
 	log "github.com/sirupsen/logrus"
-	v1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -138,26 +137,15 @@ func (p *updatePhaseCoreDNS) generateCorefile(context.Context) error {
 		return trace.Wrap(err)
 	}
 
-	conf, err := libinstall.GenerateCorefile(libinstall.CorednsConfig{
-		UpstreamNameservers: resolvConf.Servers,
-		Rotate:              resolvConf.Rotate,
-		Hosts:               p.DNSOverrides.Hosts,
-		Zones:               p.DNSOverrides.Zones,
-	})
+	conf, err := opsservice.GenerateCorefile(opsservice.NewCorednsConfig(
+		p.DNSOverrides, resolvConf.Servers, resolvConf.Rotate))
 	if err != nil {
 		return trace.Wrap(err)
 	}
 	p.Debug("Generated corefile: ", conf)
 
-	_, err = p.Client.CoreV1().ConfigMaps(constants.KubeSystemNamespace).Create(&v1.ConfigMap{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "coredns",
-			Namespace: constants.KubeSystemNamespace,
-		},
-		Data: map[string]string{
-			"Corefile": conf,
-		},
-	})
+	_, err = p.Client.CoreV1().ConfigMaps(constants.KubeSystemNamespace).Create(
+		opsservice.NewCorednsConfigMap(conf))
 	err = rigging.ConvertError(err)
 	if err != nil && !trace.IsAlreadyExists(err) {
 		return trace.Wrap(err)
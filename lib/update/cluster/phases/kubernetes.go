@@ -105,6 +105,8 @@ func (p *phaseUntaint) Rollback(context.Context) error {
 // phaseDrain defines the operation of draining a node
 type phaseDrain struct {
 	kubernetesOperation
+	// Policy specifies how the node is drained
+	Policy storage.DrainPolicy
 }
 
 // NewPhaseDrain returns a new executor for draining a node
@@ -113,18 +115,31 @@ func NewPhaseDrain(p fsm.ExecutorParams, client *kubeapi.Clientset, logger log.F
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+	var policy storage.DrainPolicy
+	if p.Phase.Data.Drain != nil {
+		policy = *p.Phase.Data.Drain
+	}
 	return &phaseDrain{
 		kubernetesOperation: *op,
+		Policy:              policy,
 	}, nil
 }
 
 // Execute drains the specified node
 func (p *phaseDrain) Execute(ctx context.Context) error {
+	if p.Policy.ShouldSkip(p.Server) {
+		p.Infof("Skip draining %v.", p.Server)
+		return nil
+	}
 	p.Infof("Drain %v.", p.Server)
-	ctx, cancel := context.WithTimeout(ctx, defaults.DrainTimeout)
+	timeout := defaults.DrainTimeout
+	if p.Policy.Timeout != 0 {
+		timeout = p.Policy.Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 	err := update.Retry(ctx, func() error {
-		return trace.Wrap(drain(ctx, p.Client, p.Server.KubeNodeID()))
+		return trace.Wrap(drain(ctx, p.Client, p.Server.KubeNodeID(), p.Policy))
 	}, defaults.DrainErrorTimeout)
 	return trace.Wrap(err)
 }
@@ -288,8 +303,8 @@ func taint(ctx context.Context, client corev1.NodeInterface, node string, add ad
 	return nil
 }
 
-func drain(ctx context.Context, client *kubeapi.Clientset, node string) error {
-	err := kubernetes.Drain(ctx, client, node)
+func drain(ctx context.Context, client *kubeapi.Clientset, node string, policy storage.DrainPolicy) error {
+	err := kubernetes.DrainWithPolicy(ctx, client, node, policy)
 	return trace.Wrap(err)
 }
 
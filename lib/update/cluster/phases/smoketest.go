@@ -0,0 +1,118 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/events"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// phaseSmokeTest is the executor that runs an application smoke test
+// after the cluster has been upgraded
+type phaseSmokeTest struct {
+	// FieldLogger is used for logging
+	log.FieldLogger
+	// Operator is the cluster operator the test results are reported to
+	Operator ops.Operator
+	// Apps is the cluster application service
+	Apps app.Applications
+	// Package is the application package with the smoke test
+	Package loc.Locator
+	// Name is the name of the smoke test
+	Name string
+	// OperationID is the ID of the upgrade operation
+	OperationID string
+	// ServiceUser is the cluster system user
+	ServiceUser storage.OSUser
+}
+
+// NewPhaseSmokeTest returns a new executor for the smoke test phase
+func NewPhaseSmokeTest(
+	p fsm.ExecutorParams,
+	operator ops.Operator,
+	apps app.Applications,
+	logger log.FieldLogger,
+) (*phaseSmokeTest, error) {
+	if p.Phase.Data == nil || p.Phase.Data.Package == nil || p.Phase.Data.Data == "" {
+		return nil, trace.NotFound("no smoke test specified for phase %q", p.Phase.ID)
+	}
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &phaseSmokeTest{
+		FieldLogger: logger,
+		Operator:    operator,
+		Apps:        apps,
+		Package:     *p.Phase.Data.Package,
+		Name:        p.Phase.Data.Data,
+		OperationID: p.Plan.OperationID,
+		ServiceUser: cluster.ServiceUser,
+	}, nil
+}
+
+// Execute runs the smoke test and fails if the test does not pass
+func (p *phaseSmokeTest) Execute(ctx context.Context) error {
+	p.Infof("Run smoke test %v for %v.", p.Name, p.Package)
+	reader, writer := io.Pipe()
+	go streamHook(schema.HookSmokeTest, reader, p.FieldLogger)
+	start := time.Now()
+	err := app.RunSmokeTest(ctx, p.Apps, app.HookRunRequest{
+		Application: p.Package,
+		SmokeTest:   p.Name,
+		ServiceUser: p.ServiceUser,
+	}, writer)
+	fields := events.Fields{
+		events.FieldName:        p.Name,
+		events.FieldOperationID: p.OperationID,
+		events.FieldLatency:     time.Since(start).String(),
+	}
+	if err != nil {
+		events.Emit(ctx, p.Operator, events.SmokeTestFailed,
+			fields.WithField(events.FieldError, trace.UserMessage(err)))
+		return trace.Wrap(err, "smoke test %v failed", p.Name)
+	}
+	events.Emit(ctx, p.Operator, events.SmokeTestPassed, fields)
+	return nil
+}
+
+// Rollback is a no-op for this phase
+func (p *phaseSmokeTest) Rollback(context.Context) error {
+	return nil
+}
+
+// PreCheck is a no-op for this phase
+func (p *phaseSmokeTest) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op for this phase
+func (p *phaseSmokeTest) PostCheck(context.Context) error {
+	return nil
+}
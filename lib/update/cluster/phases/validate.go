@@ -48,6 +48,7 @@ func validate(ctx context.Context,
 		Requirements: requirements,
 		Features: checks.Features{
 			TestPorts: true,
+			TestDisks: true,
 		},
 	})
 	if err != nil {
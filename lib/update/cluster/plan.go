@@ -28,6 +28,7 @@ import (
 	"github.com/gravitational/gravity/lib/archive"
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/fsm"
 	"github.com/gravitational/gravity/lib/loc"
 	"github.com/gravitational/gravity/lib/localenv"
 	"github.com/gravitational/gravity/lib/ops"
@@ -193,6 +194,11 @@ func NewOperationPlan(config PlanConfig) (*storage.OperationPlan, error) {
 		return nil, trace.Wrap(err)
 	}
 
+	strategy, err := updateStrategy(*config.Operation, updateApp.Manifest)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
 	plan, err := newOperationPlan(planConfig{
 		plan: storage.OperationPlan{
 			OperationID:    config.Operation.ID,
@@ -218,6 +224,7 @@ func NewOperationPlan(config PlanConfig) (*storage.OperationPlan, error) {
 		updateDNSAppEarly: updateDNSAppEarly,
 		roles:             roles,
 		leadMaster:        *leader,
+		strategy:          *strategy,
 	})
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -298,6 +305,8 @@ type planConfig struct {
 	roles []teleservices.Role
 	// leader refers to the master server running the update operation
 	leadMaster storage.UpdateServer
+	// strategy specifies how the cluster nodes are updated
+	strategy storage.UpdateStrategy
 }
 
 func newOperationPlan(p planConfig) (*storage.OperationPlan, error) {
@@ -307,6 +316,14 @@ func newOperationPlan(p planConfig) (*storage.OperationPlan, error) {
 	}
 	otherMasters := filterServer(masters, p.leadMaster)
 	builder := phaseBuilder{planConfig: p}
+	if p.strategy.Type == schema.UpgradeStrategyCanary {
+		canary, err := findCanaryServer(p.strategy.CanaryNode, p.leadMaster, nodes)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		log.WithField("node", canary.Hostname).Info("Use canary upgrade strategy.")
+		builder.canary = canary
+	}
 	initPhase := *builder.init(p.leadMaster.Server)
 	checksPhase := *builder.checks().Require(initPhase)
 	preUpdatePhase := *builder.preUpdate().Require(initPhase)
@@ -355,12 +372,24 @@ func newOperationPlan(p planConfig) (*storage.OperationPlan, error) {
 		return nil, trace.Wrap(err)
 	}
 
+	updateGravityPackage, err := p.updateRuntime.Manifest.Dependencies.ByName(
+		constants.GravityPackage)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	updateSystem := updateEtcd || requiresSystemUpdate(p.servers,
+		*installedGravityPackage, *updateGravityPackage)
+
 	var root update.Phase
 	root.Add(initPhase, checksPhase, preUpdatePhase)
 	if len(runtimeUpdates) > 0 {
+		// runtimeRequires lists the phases the runtime configuration
+		// and application updates depend on
+		runtimeRequires := []update.PhaseIder{checksPhase, bootstrapPhase, preUpdatePhase}
 		if p.updateCoreDNS {
 			corednsPhase := *builder.corednsPhase(p.leadMaster.Server)
 			mastersPhase = *mastersPhase.Require(corednsPhase)
+			runtimeRequires = append(runtimeRequires, corednsPhase)
 			root.Add(corednsPhase)
 		}
 
@@ -369,14 +398,23 @@ func newOperationPlan(p planConfig) (*storage.OperationPlan, error) {
 				if update.Name == constants.DNSAppPackage {
 					earlyDNSAppPhase := *builder.earlyDNSApp(update)
 					mastersPhase = *mastersPhase.Require(earlyDNSAppPhase)
+					runtimeRequires = append(runtimeRequires, earlyDNSAppPhase)
 					root.Add(earlyDNSAppPhase)
 				}
 			}
 		}
 
-		root.Add(bootstrapPhase, mastersPhase)
-		if len(nodesPhase.Phases) > 0 {
-			root.Add(nodesPhase)
+		root.Add(bootstrapPhase)
+		if updateSystem {
+			root.Add(mastersPhase)
+			if len(nodesPhase.Phases) > 0 {
+				root.Add(nodesPhase)
+			}
+			runtimeRequires = []update.PhaseIder{mastersPhase}
+		} else {
+			// With system software unchanged, nodes neither need to be drained
+			// nor have their runtime restarted
+			log.Info("Runtime is unchanged, skip node updates.")
 		}
 
 		if updateEtcd {
@@ -399,12 +437,16 @@ func newOperationPlan(p planConfig) (*storage.OperationPlan, error) {
 		// upgrade phase to make sure that old gravity-sites start up fine
 		// in case new configuration is incompatible, but *before* runtime
 		// phase so new gravity-sites can find it after they start
-		configPhase := *builder.config(serversToStorage(masters...)).Require(mastersPhase)
-		runtimePhase := *builder.runtime(runtimeUpdates).Require(mastersPhase)
+		configPhase := *builder.config(serversToStorage(masters...)).Require(runtimeRequires...)
+		runtimePhase := *builder.runtime(runtimeUpdates).Require(runtimeRequires...)
 		root.Add(configPhase, runtimePhase)
 	}
 
-	root.AddSequential(*builder.app(appUpdates), *builder.cleanup())
+	root.AddSequential(*builder.app(appUpdates))
+	if smokeTestsPhase := builder.smokeTests(); smokeTestsPhase != nil {
+		root.AddSequential(*smokeTestsPhase)
+	}
+	root.AddSequential(*builder.cleanup())
 	plan := p.plan
 	plan.Phases = root.Phases
 	update.ResolvePlan(&plan)
@@ -412,6 +454,22 @@ func newOperationPlan(p planConfig) (*storage.OperationPlan, error) {
 	return &plan, nil
 }
 
+// requiresSystemUpdate returns true if the system software (gravity, planet
+// or teleport) needs to be updated on any of the specified servers.
+// Otherwise, the upgrade only needs to update the applications and the nodes
+// are neither drained nor restarted
+func requiresSystemUpdate(servers []storage.UpdateServer, installedGravity, updateGravity loc.Locator) bool {
+	if !installedGravity.IsEqualTo(updateGravity) {
+		return true
+	}
+	for _, server := range servers {
+		if server.Runtime.Update != nil || server.Teleport.Update != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // configUpdates computes the configuration updates for the specified list of servers
 func configUpdates(
 	installed, update schema.Manifest,
@@ -681,6 +739,84 @@ func getRuntimePackage(manifest schema.Manifest, profileName string, clusterRole
 	return runtimePackage, nil
 }
 
+// FindCanaryApproval returns the phase that holds off the upgrade of the remaining
+// nodes until the canary node has been approved
+func FindCanaryApproval(plan storage.OperationPlan) (*storage.OperationPhase, error) {
+	for _, phase := range fsm.FlattenPlan(&plan) {
+		if phase.Executor == canaryApprove {
+			return phase, nil
+		}
+	}
+	return nil, trace.NotFound("operation does not use canary upgrade strategy")
+}
+
+// updateStrategy returns the upgrade strategy for the specified operation.
+// The strategy given for the operation takes precedence over the one
+// from the update application manifest
+func updateStrategy(operation storage.SiteOperation, manifest schema.Manifest) (*storage.UpdateStrategy, error) {
+	strategy := storage.UpdateStrategy{
+		Type: manifest.Upgrade.GetStrategy(),
+	}
+	if manifest.Upgrade != nil && manifest.Upgrade.Canary != nil {
+		soakPeriod, err := manifest.Upgrade.Canary.GetSoakPeriod()
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		strategy.CanaryNode = manifest.Upgrade.Canary.Node
+		strategy.SoakPeriod = soakPeriod
+	}
+	if manifest.Upgrade != nil {
+		maxUnavailable, err := manifest.Upgrade.GetMaxUnavailablePercent()
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		strategy.BatchSize = manifest.Upgrade.BatchSize
+		strategy.MaxUnavailablePercent = maxUnavailable
+	}
+	if operation.Update == nil || operation.Update.Strategy == nil {
+		return &strategy, nil
+	}
+	override := operation.Update.Strategy
+	if override.Type != "" {
+		strategy.Type = override.Type
+	}
+	if override.CanaryNode != "" {
+		strategy.CanaryNode = override.CanaryNode
+	}
+	if override.SoakPeriod != 0 {
+		strategy.SoakPeriod = override.SoakPeriod
+	}
+	if override.BatchSize != 0 || override.MaxUnavailablePercent != 0 {
+		strategy.BatchSize = override.BatchSize
+		strategy.MaxUnavailablePercent = override.MaxUnavailablePercent
+	}
+	return &strategy, nil
+}
+
+// findCanaryServer returns the server to upgrade first with the canary strategy.
+// node specifies the hostname or the advertise address of the canary node.
+// If unspecified, the first regular node is selected or the lead master
+// if there are no regular nodes.
+// The lead master is always upgraded first so another master cannot be a canary
+func findCanaryServer(node string, leadMaster storage.UpdateServer, nodes []storage.UpdateServer) (*storage.UpdateServer, error) {
+	if node == "" {
+		if len(nodes) != 0 {
+			return &nodes[0], nil
+		}
+		return &leadMaster, nil
+	}
+	if leadMaster.Hostname == node || leadMaster.AdvertiseIP == node {
+		return &leadMaster, nil
+	}
+	for i, server := range nodes {
+		if server.Hostname == node || server.AdvertiseIP == node {
+			return &nodes[i], nil
+		}
+	}
+	return nil, trace.NotFound("canary node %v is neither the lead master node %v nor a regular node",
+		node, leadMaster.Hostname)
+}
+
 func findServer(input storage.Server, servers []storage.UpdateServer) (*storage.UpdateServer, error) {
 	for _, server := range servers {
 		if server.AdvertiseIP == input.AdvertiseIP {
@@ -78,7 +78,11 @@ func newOperationPlan(
 	if len(masters) == 0 {
 		return nil, trace.NotFound("no master servers found in cluster state")
 	}
-	shouldUpdateNodes := shouldUpdateNodes(clusterConfig, len(nodes))
+	prevConfig, err := previousConfig(operation)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	shouldUpdateNodes := shouldUpdateNodes(prevConfig, clusterConfig, len(nodes))
 	updateServers := updates
 	if !shouldUpdateNodes {
 		updateServers = masters
@@ -114,10 +118,44 @@ func newOperationPlan(
 	return plan, nil
 }
 
-func shouldUpdateNodes(clusterConfig clusterconfig.Interface, numNodes int) bool {
+func shouldUpdateNodes(prevConfig, clusterConfig clusterconfig.Interface, numNodes int) bool {
 	var hasComponentUpdate bool
 	if config := clusterConfig.GetGlobalConfig(); config != nil && len(config.FeatureGates) != 0 {
 		hasComponentUpdate = true
 	}
+	// Proxy settings are applied to the runtime container on all nodes,
+	// so nodes need to be updated when the settings are changed or removed
+	if proxyChanged(prevConfig, clusterConfig) {
+		hasComponentUpdate = true
+	}
 	return (clusterConfig.GetKubeletConfig() != nil || hasComponentUpdate) && numNodes != 0
 }
+
+// proxyChanged returns true if the proxy settings differ between
+// the specified configurations
+func proxyChanged(prevConfig, clusterConfig clusterconfig.Interface) bool {
+	prev, next := proxySettings(prevConfig), proxySettings(clusterConfig)
+	return prev.HTTPProxy != next.HTTPProxy ||
+		prev.HTTPSProxy != next.HTTPSProxy ||
+		prev.NoProxy != next.NoProxy
+}
+
+func proxySettings(config clusterconfig.Interface) clusterconfig.Global {
+	if global := config.GetGlobalConfig(); global != nil {
+		return *global
+	}
+	return clusterconfig.Global{}
+}
+
+// previousConfig returns the cluster configuration as it was
+// before the specified operation
+func previousConfig(operation ops.SiteOperation) (clusterconfig.Interface, error) {
+	if operation.UpdateConfig == nil || len(operation.UpdateConfig.PrevConfig) == 0 {
+		return clusterconfig.NewEmpty(), nil
+	}
+	config, err := clusterconfig.Unmarshal(operation.UpdateConfig.PrevConfig)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return config, nil
+}
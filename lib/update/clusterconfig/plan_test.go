@@ -709,6 +709,83 @@ address: "0.0.0.0"`),
 	})
 }
 
+func (S) TestUpdatesNodesWhenProxyChanges(c *C) {
+	servers := []storage.Server{
+		{Hostname: "node-1", Role: "node", ClusterRole: string(schema.ServiceRoleMaster)},
+		{Hostname: "node-2", Role: "knode", ClusterRole: string(schema.ServiceRoleNode)},
+	}
+	runtimeLoc := loc.Locator{Repository: "foo", Name: "runtime", Version: "0.0.1"}
+	app := app.Application{
+		Package: loc.MustParseLocator("gravitational.io/app:0.0.1"),
+		Manifest: schema.Manifest{
+			NodeProfiles: schema.NodeProfiles{
+				{
+					Name:        "node",
+					ServiceRole: "master",
+				},
+				{
+					Name:        "knode",
+					ServiceRole: "node",
+				},
+			},
+			SystemOptions: &schema.SystemOptions{
+				Dependencies: schema.SystemDependencies{
+					Runtime: &schema.Dependency{Locator: runtimeLoc},
+				},
+			},
+		},
+	}
+	withProxy := clusterconfig.NewEmpty()
+	withProxy.Spec.Global = &clusterconfig.Global{HTTPProxy: "http://proxy.example.com:3128"}
+
+	var testCases = []struct {
+		prevConfig    clusterconfig.Interface
+		clusterConfig clusterconfig.Interface
+		phases        []string
+		comment       string
+	}{
+		{
+			prevConfig:    clusterconfig.NewEmpty(),
+			clusterConfig: withProxy,
+			phases:        []string{"/update-config", "/masters", "/nodes"},
+			comment:       "proxy is configured",
+		},
+		{
+			prevConfig:    withProxy,
+			clusterConfig: clusterconfig.NewEmpty(),
+			phases:        []string{"/update-config", "/masters", "/nodes"},
+			comment:       "proxy is removed",
+		},
+		{
+			prevConfig:    withProxy,
+			clusterConfig: withProxy,
+			phases:        []string{"/update-config", "/masters"},
+			comment:       "proxy is unchanged",
+		},
+	}
+	for _, tc := range testCases {
+		comment := Commentf(tc.comment)
+		prevConfig, err := clusterconfig.Marshal(tc.prevConfig)
+		c.Assert(err, IsNil, comment)
+		operation := ops.SiteOperation{
+			ID:         "1",
+			AccountID:  "0",
+			Type:       ops.OperationUpdateConfig,
+			SiteDomain: "cluster",
+			UpdateConfig: &storage.UpdateConfigOperationState{
+				PrevConfig: prevConfig,
+			},
+		}
+		plan, err := newOperationPlan(app, storage.DefaultDNSConfig, testOperator, operation, tc.clusterConfig, servers)
+		c.Assert(err, IsNil, comment)
+		var phases []string
+		for _, phase := range plan.Phases {
+			phases = append(phases, phase.ID)
+		}
+		c.Assert(phases, DeepEquals, tc.phases, comment)
+	}
+}
+
 func (r testRotator) RotatePlanetConfig(ops.RotatePlanetConfigRequest) (*ops.RotatePackageResponse, error) {
 	return &ops.RotatePackageResponse{Locator: r.runtimeConfigPackage}, nil
 }
@@ -46,7 +46,7 @@ func NewMachine(ctx context.Context, config Config, engine *Engine) (*fsm.FSM, e
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
-	machine.SetPreExec(engine.UpdateProgress)
+	machine.SetPreExec(engine.preExec)
 	return machine, nil
 }
 
@@ -76,6 +76,15 @@ func NewEngine(ctx context.Context, config Config, dispatcher Dispatcher) (*Engi
 	}, nil
 }
 
+// preExec prevents the execution of phases until the operation
+// has been approved if it requires approval
+func (r *Engine) preExec(ctx context.Context, params fsm.Params) error {
+	if err := r.CheckApproval(); err != nil {
+		return trace.Wrap(err)
+	}
+	return r.UpdateProgress(ctx, params)
+}
+
 // UpdateProgress creates an appropriate progress entry in the operator
 func (r *Engine) UpdateProgress(ctx context.Context, params fsm.Params) error {
 	plan, err := r.GetPlan()
@@ -122,7 +131,11 @@ func (r *Engine) Complete(fsmErr error) error {
 		if fsmErr != nil {
 			msg = trace.Unwrap(fsmErr).Error()
 		}
-		err = ops.FailOperation(r.Operation.Key(), r.operator, msg)
+		if fsm.IsRolledBack(plan) {
+			err = ops.RollbackOperation(r.Operation.Key(), r.operator, msg)
+		} else {
+			err = ops.FailOperation(r.Operation.Key(), r.operator, msg)
+		}
 	}
 	if err != nil {
 		return trace.Wrap(err)
@@ -141,7 +154,7 @@ func (r *Engine) Complete(fsmErr error) error {
 // ChangePhaseState creates a new changelog entry
 func (r *Engine) ChangePhaseState(ctx context.Context, change fsm.StateChange) error {
 	r.WithField("change", change).Debug("Apply.")
-	_, err := r.LocalBackend.CreateOperationPlanChange(storage.PlanChange{
+	_, err := storage.AppendOperationPlanChange(r.LocalBackend, storage.PlanChange{
 		ID:          uuid.New(),
 		ClusterName: r.Operation.SiteDomain,
 		OperationID: r.Operation.ID,
@@ -164,7 +177,11 @@ func (r *Engine) ChangePhaseState(ctx context.Context, change fsm.StateChange) e
 // RunCommand executes the phase specified by params on the specified server
 // using the provided runner
 func (r *Engine) RunCommand(ctx context.Context, runner rpc.RemoteRunner, server storage.Server, params fsm.Params) error {
-	args := []string{"plan", "execute",
+	command := "execute"
+	if params.Rollback {
+		command = "rollback"
+	}
+	args := []string{"plan", command,
 		"--phase", params.PhaseID,
 		"--operation-id", r.Operation.ID,
 	}
@@ -0,0 +1,430 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/gravitational/gravity/lib/backup"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/etcd"
+	libfsm "github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/state"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/keyval"
+	"github.com/gravitational/gravity/lib/update"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// NewShutdown returns a new executor to stop etcd on a master node
+func NewShutdown(params libfsm.ExecutorParams, logger log.FieldLogger) (*shutdownExecutor, error) {
+	return &shutdownExecutor{
+		FieldLogger: logger,
+	}, nil
+}
+
+// Execute stops etcd and the Kubernetes API server
+func (r *shutdownExecutor) Execute(ctx context.Context) error {
+	r.Info("Stop etcd.")
+	return trace.Wrap(disableEtcd(ctx, r.FieldLogger))
+}
+
+// Rollback starts etcd
+func (r *shutdownExecutor) Rollback(ctx context.Context) error {
+	r.Info("Start etcd.")
+	return trace.Wrap(enableEtcd(ctx, r.FieldLogger))
+}
+
+// PreCheck is a no-op
+func (*shutdownExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*shutdownExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+type shutdownExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+}
+
+// NewRestore returns a new executor to restore the etcd data
+// of a master node from the staged snapshot
+func NewRestore(
+	params libfsm.ExecutorParams,
+	operation ops.SiteOperation,
+	logger log.FieldLogger,
+) (*restoreExecutor, error) {
+	if params.Phase.Data == nil || params.Phase.Data.Server == nil {
+		return nil, trace.BadParameter("phase %q requires a server", params.Phase.ID)
+	}
+	name, err := memberName(params.Phase.Data.Data, *params.Phase.Data.Server)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	stateDir, err := state.GetStateDir()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &restoreExecutor{
+		FieldLogger:    logger,
+		stateDir:       stateDir,
+		name:           name,
+		peerURL:        fmt.Sprintf("https://%v:%v", params.Phase.Data.Server.GetClusterAddr(), defaults.EtcdPeerPort),
+		initialCluster: params.Phase.Data.Data,
+		operationID:    operation.ID,
+	}, nil
+}
+
+// Execute restores the snapshot into a new data directory and swaps it
+// with the current data directory which is kept for rollback.
+//
+// All members are restored with the same initial cluster so they
+// form a new cluster from the snapshot once started
+func (r *restoreExecutor) Execute(ctx context.Context) error {
+	snapshot := state.InEtcdDir(r.stateDir, defaults.EtcdRestoreSnapshotFile)
+	status, err := backup.GetSnapshotStatus(snapshot)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	version, err := readEtcdVersion(r.stateDir)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	dataDir, restoreDir, backupDir := r.dirs(version)
+	if err := os.RemoveAll(restoreDir); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	args := []string{"/usr/bin/env", "ETCDCTL_API=3", defaults.EtcdCtlBin,
+		"snapshot", "restore", path.Join(planetEtcdDir, defaults.EtcdRestoreSnapshotFile),
+		"--name", r.name,
+		"--initial-cluster", r.initialCluster,
+		"--initial-cluster-token", fmt.Sprintf("etcd-restore-%v", r.operationID),
+		"--initial-advertise-peer-urls", r.peerURL,
+		"--data-dir", path.Join(planetEtcdDir, filepath.Base(restoreDir)),
+	}
+	if !status.HasChecksum {
+		// snapshots copied from the database file have no checksum
+		args = append(args, "--skip-hash-check")
+	}
+	r.Infof("Restore etcd data at revision %v.", status.Revision)
+	out, err := utils.RunInPlanetCommand(ctx, r.FieldLogger, args...)
+	if err != nil {
+		return trace.Wrap(err, "failed to restore etcd snapshot: %s", out)
+	}
+	if _, err := os.Stat(backupDir); os.IsNotExist(err) {
+		r.Infof("Keep current etcd data in %v.", backupDir)
+		if err := os.Rename(dataDir, backupDir); err != nil {
+			return trace.ConvertSystemError(err)
+		}
+	} else if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	if err := chownLike(restoreDir, backupDir); err != nil {
+		return trace.Wrap(err)
+	}
+	// the data directory is left from a previous attempt if the
+	// current data has already been moved aside
+	if err := os.RemoveAll(dataDir); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	return trace.ConvertSystemError(os.Rename(restoreDir, dataDir))
+}
+
+// Rollback replaces the restored data directory with the
+// data directory etcd has been running with
+func (r *restoreExecutor) Rollback(context.Context) error {
+	version, err := readEtcdVersion(r.stateDir)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	dataDir, restoreDir, backupDir := r.dirs(version)
+	if err := os.RemoveAll(restoreDir); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	if _, err := os.Stat(backupDir); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return trace.ConvertSystemError(err)
+	}
+	r.Infof("Restore etcd data from %v.", backupDir)
+	if err := os.RemoveAll(dataDir); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	return trace.ConvertSystemError(os.Rename(backupDir, dataDir))
+}
+
+// PreCheck is a no-op
+func (*restoreExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*restoreExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+// dirs returns the etcd data directory for the specified etcd version,
+// the directory the snapshot is restored into and the directory
+// the current data is kept in
+func (r *restoreExecutor) dirs(version string) (dataDir, restoreDir, backupDir string) {
+	dataDir = state.InEtcdDir(r.stateDir, version)
+	return dataDir, dataDir + ".restore", fmt.Sprintf("%v.%v", dataDir, r.operationID)
+}
+
+type restoreExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	stateDir       string
+	name           string
+	peerURL        string
+	initialCluster string
+	operationID    string
+}
+
+// NewStart returns a new executor to start etcd on a master node
+func NewStart(params libfsm.ExecutorParams, logger log.FieldLogger) (*startExecutor, error) {
+	return &startExecutor{
+		FieldLogger: logger,
+	}, nil
+}
+
+// Execute starts etcd and the Kubernetes API server
+func (r *startExecutor) Execute(ctx context.Context) error {
+	r.Info("Start etcd.")
+	return trace.Wrap(enableEtcd(ctx, r.FieldLogger))
+}
+
+// Rollback stops etcd
+func (r *startExecutor) Rollback(ctx context.Context) error {
+	r.Info("Stop etcd.")
+	return trace.Wrap(disableEtcd(ctx, r.FieldLogger))
+}
+
+// PreCheck is a no-op
+func (*startExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*startExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+type startExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+}
+
+// NewVerify returns a new executor to verify the restored etcd cluster
+func NewVerify(
+	params libfsm.ExecutorParams,
+	operation ops.SiteOperation,
+	backend, localBackend storage.Backend,
+	logger log.FieldLogger,
+) (*verifyExecutor, error) {
+	if operation.EtcdRestore == nil {
+		return nil, trace.BadParameter("operation %v does not restore etcd", operation.ID)
+	}
+	var endpoints []string
+	for _, master := range storage.Servers(params.Plan.Servers).Masters() {
+		endpoints = append(endpoints, fmt.Sprintf("https://%v:%v", master.AdvertiseIP, defaults.EtcdAPIPort))
+	}
+	return &verifyExecutor{
+		FieldLogger:  logger,
+		backend:      backend,
+		localBackend: localBackend,
+		operation:    operation,
+		plan:         params.Plan,
+		endpoints:    endpoints,
+		revision:     operation.EtcdRestore.Revision,
+	}, nil
+}
+
+// Execute waits for the restored etcd cluster to become healthy and
+// verifies that all members have restored the snapshot.
+//
+// The restored database predates the operation so the operation
+// and its plan are recorded in it again
+func (r *verifyExecutor) Execute(ctx context.Context) error {
+	r.Info("Wait for etcd cluster to become healthy.")
+	out, err := utils.RunCommand(ctx, r.FieldLogger, utils.PlanetCommandArgs(defaults.WaitForEtcdScript)...)
+	if err != nil {
+		return trace.Wrap(err, "etcd cluster is not healthy: %s", out)
+	}
+	config, err := keyval.LocalEtcdConfig(0)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	config.Nodes = r.endpoints
+	client, err := etcd.NewClient(*config)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer client.Close()
+	err = update.Retry(ctx, func() error {
+		return trace.Wrap(r.verify(ctx, client))
+	}, defaults.NodeStatusTimeout)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	r.Info("Record operation in the restored etcd database.")
+	return trace.Wrap(update.SyncOperationPlan(r.localBackend, r.backend, r.plan,
+		(storage.SiteOperation)(r.operation)))
+}
+
+func (r *verifyExecutor) verify(ctx context.Context, client *clientv3.Client) error {
+	var clusterID uint64
+	for _, endpoint := range r.endpoints {
+		ctx, cancel := context.WithTimeout(ctx, defaults.EtcdHealthTimeout)
+		resp, err := client.Status(ctx, endpoint)
+		cancel()
+		if err != nil {
+			return trace.Wrap(err, "failed to query etcd member %v", endpoint)
+		}
+		if clusterID != 0 && resp.Header.ClusterId != clusterID {
+			return trace.BadParameter("etcd member %v has not joined the restored cluster", endpoint)
+		}
+		clusterID = resp.Header.ClusterId
+		if resp.Header.Revision < r.revision {
+			return trace.BadParameter("etcd member %v is at revision %v, expected at least %v",
+				endpoint, resp.Header.Revision, r.revision)
+		}
+		r.Infof("Etcd member %v is at revision %v.", endpoint, resp.Header.Revision)
+	}
+	return nil
+}
+
+// Rollback is a no-op for this phase
+func (*verifyExecutor) Rollback(context.Context) error {
+	return nil
+}
+
+// PreCheck is a no-op
+func (*verifyExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*verifyExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+type verifyExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	backend      storage.Backend
+	localBackend storage.Backend
+	operation    ops.SiteOperation
+	plan         storage.OperationPlan
+	endpoints    []string
+	revision     int64
+}
+
+func disableEtcd(ctx context.Context, logger log.FieldLogger) error {
+	out, err := utils.RunPlanetCommand(ctx, logger, "etcd", "disable", "--stop-api")
+	if err != nil {
+		return trace.Wrap(err, "failed to stop etcd: %s", out)
+	}
+	return nil
+}
+
+func enableEtcd(ctx context.Context, logger log.FieldLogger) error {
+	out, err := utils.RunPlanetCommand(ctx, logger, "etcd", "enable")
+	if err != nil {
+		return trace.Wrap(err, "failed to start etcd: %s", out)
+	}
+	return nil
+}
+
+// memberName returns the name of the etcd member running on the specified
+// server from the initial cluster configuration
+func memberName(initialCluster string, server storage.Server) (string, error) {
+	for _, member := range strings.Split(initialCluster, ",") {
+		parts := strings.SplitN(member, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		u, err := url.Parse(parts[1])
+		if err == nil && u.Hostname() == server.AdvertiseIP {
+			return parts[0], nil
+		}
+	}
+	return "", trace.NotFound("no etcd member for node %v in initial cluster %q",
+		server.Hostname, initialCluster)
+}
+
+// readEtcdVersion returns the version of etcd running on this node
+func readEtcdVersion(stateDir string) (string, error) {
+	data, err := ioutil.ReadFile(state.InEtcdDir(stateDir, defaults.EtcdVersionFile))
+	if err != nil {
+		return "", trace.ConvertSystemError(err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		parts := strings.SplitN(strings.TrimSpace(scanner.Text()), "=", 2)
+		if len(parts) == 2 && parts[0] == etcdVersionKey && parts[1] != "" {
+			return parts[1], nil
+		}
+	}
+	return "", trace.NotFound("etcd version not found in %v", defaults.EtcdVersionFile)
+}
+
+// chownLike changes the owner of the directory tree at dir
+// to the owner of the reference path
+func chownLike(dir, reference string) error {
+	fi, err := os.Stat(reference)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	err = filepath.Walk(dir, func(path string, _ os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, int(stat.Uid), int(stat.Gid))
+	})
+	return trace.ConvertSystemError(err)
+}
+
+const (
+	// planetEtcdDir is the etcd directory inside planet
+	planetEtcdDir = "/ext/etcd"
+	// etcdVersionKey names the etcd version in the version file
+	etcdVersionKey = "PLANET_ETCD_VERSION"
+)
@@ -0,0 +1,237 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+	"os"
+
+	"github.com/gravitational/gravity/lib/backup"
+	"github.com/gravitational/gravity/lib/defaults"
+	libfsm "github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/state"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Validate defines the phase to verify the snapshot
+	Validate = "validate"
+	// Snapshot defines the phase to upload the snapshot to the cluster
+	Snapshot = "snapshot"
+	// Stage defines the phase to download the snapshot on a master node
+	Stage = "stage"
+	// Shutdown defines the phase to stop etcd on a master node
+	Shutdown = "shutdown"
+	// Restore defines the phase to restore the etcd data on a master node
+	Restore = "restore"
+	// Start defines the phase to start etcd on a master node
+	Start = "start"
+	// Verify defines the phase to verify the restored etcd cluster
+	Verify = "verify"
+	// Controller defines the phase to restart the cluster controller
+	Controller = "controller"
+
+	// SnapshotPackageName is the name of the package with the snapshot
+	SnapshotPackageName = "etcd-snapshot"
+)
+
+// NewValidate returns a new executor to verify the snapshot
+// before etcd is stopped
+func NewValidate(
+	params libfsm.ExecutorParams,
+	operation ops.SiteOperation,
+	logger log.FieldLogger,
+) (*validateExecutor, error) {
+	if operation.EtcdRestore == nil {
+		return nil, trace.BadParameter("operation %v does not restore etcd", operation.ID)
+	}
+	return &validateExecutor{
+		FieldLogger: logger,
+		path:        operation.EtcdRestore.Snapshot,
+		revision:    operation.EtcdRestore.Revision,
+	}, nil
+}
+
+// Execute verifies the integrity of the snapshot and that it has not
+// changed since the operation has been created
+func (r *validateExecutor) Execute(context.Context) error {
+	r.Infof("Verify snapshot %v.", r.path)
+	status, err := backup.GetSnapshotStatus(r.path)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if r.revision != 0 && status.Revision != r.revision {
+		return trace.BadParameter("snapshot %v has revision %v, expected %v, "+
+			"the snapshot has changed after the operation has been created",
+			r.path, status.Revision, r.revision)
+	}
+	r.Infof("Snapshot at revision %v with %v keys.", status.Revision, status.Keys)
+	return nil
+}
+
+// Rollback is a no-op for this phase
+func (*validateExecutor) Rollback(context.Context) error {
+	return nil
+}
+
+// PreCheck is a no-op
+func (*validateExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*validateExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+type validateExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	path     string
+	revision int64
+}
+
+// NewSnapshot returns a new executor to upload the snapshot to the
+// cluster package service for the master nodes to download it
+func NewSnapshot(
+	params libfsm.ExecutorParams,
+	operation ops.SiteOperation,
+	packages pack.PackageService,
+	logger log.FieldLogger,
+) (*snapshotExecutor, error) {
+	if operation.EtcdRestore == nil {
+		return nil, trace.BadParameter("operation %v does not restore etcd", operation.ID)
+	}
+	if params.Phase.Data == nil || params.Phase.Data.Package == nil {
+		return nil, trace.BadParameter("phase %q requires a snapshot package", params.Phase.ID)
+	}
+	return &snapshotExecutor{
+		FieldLogger: logger,
+		packages:    packages,
+		path:        operation.EtcdRestore.Snapshot,
+		locator:     *params.Phase.Data.Package,
+	}, nil
+}
+
+// Execute uploads the snapshot as a package
+func (r *snapshotExecutor) Execute(context.Context) error {
+	f, err := os.Open(r.path)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	r.Infof("Upload snapshot %v as %v.", r.path, r.locator)
+	_, err = r.packages.UpsertPackage(r.locator, f)
+	return trace.Wrap(err)
+}
+
+// Rollback removes the snapshot package
+func (r *snapshotExecutor) Rollback(context.Context) error {
+	err := r.packages.DeletePackage(r.locator)
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// PreCheck is a no-op
+func (*snapshotExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*snapshotExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+type snapshotExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	packages pack.PackageService
+	path     string
+	locator  loc.Locator
+}
+
+// NewStage returns a new executor to download the snapshot into
+// the etcd directory of a master node
+func NewStage(
+	params libfsm.ExecutorParams,
+	packages pack.PackageService,
+	logger log.FieldLogger,
+) (*stageExecutor, error) {
+	if params.Phase.Data == nil || params.Phase.Data.Package == nil {
+		return nil, trace.BadParameter("phase %q requires a snapshot package", params.Phase.ID)
+	}
+	stateDir, err := state.GetStateDir()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &stageExecutor{
+		FieldLogger: logger,
+		packages:    packages,
+		locator:     *params.Phase.Data.Package,
+		path:        state.InEtcdDir(stateDir, defaults.EtcdRestoreSnapshotFile),
+	}, nil
+}
+
+// Execute downloads the snapshot package and verifies the snapshot
+func (r *stageExecutor) Execute(context.Context) error {
+	_, reader, err := r.packages.ReadPackage(r.locator)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer reader.Close()
+	r.Infof("Stage snapshot %v.", r.path)
+	err = utils.CopyReaderWithPerms(r.path, reader, defaults.PrivateFileMask)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = backup.GetSnapshotStatus(r.path)
+	return trace.Wrap(err)
+}
+
+// Rollback removes the staged snapshot
+func (r *stageExecutor) Rollback(context.Context) error {
+	if err := os.Remove(r.path); err != nil && !os.IsNotExist(err) {
+		return trace.ConvertSystemError(err)
+	}
+	return nil
+}
+
+// PreCheck is a no-op
+func (*stageExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*stageExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+type stageExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	packages pack.PackageService
+	locator  loc.Locator
+	path     string
+}
@@ -0,0 +1,246 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdrestore
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/etcd"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/keyval"
+	"github.com/gravitational/gravity/lib/update"
+	"github.com/gravitational/gravity/lib/update/etcdrestore/phases"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/gravitational/trace"
+)
+
+// NewOperationPlan creates a new operation plan for the specified operation
+func NewOperationPlan(
+	ctx context.Context,
+	operator ops.Operator,
+	operation ops.SiteOperation,
+	servers []storage.Server,
+	leader storage.Server,
+) (plan *storage.OperationPlan, err error) {
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	members, err := listMembers(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	plan, err = newOperationPlan(cluster.DNSConfig, operation, servers, leader, members)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	err = operator.CreateOperationPlan(operation.Key(), *plan)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotImplemented(
+				"cluster operator does not implement the API required to restore etcd. " +
+					"Please make sure you're running the command on a compatible cluster.")
+		}
+		return nil, trace.Wrap(err)
+	}
+	return plan, nil
+}
+
+// newOperationPlan returns a new plan for the specified operation.
+//
+// The snapshot is staged on all master nodes while etcd is still running.
+// Then etcd is stopped on all masters at once and each member restores
+// its database from the snapshot with the same initial cluster, so the
+// members form a new cluster with the same membership once they are started
+func newOperationPlan(
+	dnsConfig storage.DNSConfig,
+	operation ops.SiteOperation,
+	servers []storage.Server,
+	leader storage.Server,
+	members []clientv3.Member,
+) (*storage.OperationPlan, error) {
+	if operation.EtcdRestore == nil {
+		return nil, trace.BadParameter("operation %v does not restore etcd", operation.ID)
+	}
+	if !leader.IsMaster() {
+		return nil, trace.BadParameter("etcd can only be restored from a master node, "+
+			"node %v is not a master", leader.Hostname)
+	}
+	masters := storage.Servers(servers).Masters()
+	initialCluster, err := formatInitialCluster(masters, members)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	snapshot := snapshotPackage(operation)
+	data := func() *storage.OperationPhaseData {
+		return &storage.OperationPhaseData{ExecServer: &leader}
+	}
+	perMaster := func(phase update.Phase, description string, data storage.OperationPhaseData) update.Phase {
+		phase.Parallel = true
+		for i := range masters {
+			data := data
+			data.Server = &masters[i]
+			phase.AddParallel(update.Phase{
+				ID:          masters[i].Hostname,
+				Executor:    phase.ID,
+				Description: fmt.Sprintf(description, masters[i].Hostname),
+				Data:        &data,
+			})
+		}
+		return phase
+	}
+	plan := update.Sequential(
+		update.Phase{
+			ID:          phases.Validate,
+			Executor:    phases.Validate,
+			Description: fmt.Sprintf("Verify snapshot %v", operation.EtcdRestore.Snapshot),
+			Data:        data(),
+		},
+		update.Phase{
+			ID:          phases.Snapshot,
+			Executor:    phases.Snapshot,
+			Description: "Upload snapshot to the cluster package service",
+			Data: &storage.OperationPhaseData{
+				ExecServer: &leader,
+				Package:    &snapshot,
+			},
+		},
+		perMaster(update.Phase{
+			ID:          phases.Stage,
+			Description: "Stage snapshot on master nodes",
+		}, "Stage snapshot on node %q", storage.OperationPhaseData{Package: &snapshot}),
+		perMaster(update.Phase{
+			ID:          phases.Shutdown,
+			Description: "Stop etcd on master nodes",
+		}, "Stop etcd on node %q", storage.OperationPhaseData{}),
+		perMaster(update.Phase{
+			ID:          phases.Restore,
+			Description: "Restore etcd data from snapshot on master nodes",
+		}, "Restore etcd data on node %q", storage.OperationPhaseData{Data: initialCluster}),
+		perMaster(update.Phase{
+			ID:          phases.Start,
+			Description: "Start etcd on master nodes",
+		}, "Start etcd on node %q", storage.OperationPhaseData{}),
+		update.Phase{
+			ID:          phases.Verify,
+			Executor:    phases.Verify,
+			Description: "Verify etcd revisions on master nodes",
+			Data:        data(),
+		},
+		update.Phase{
+			ID:          phases.Controller,
+			Executor:    phases.Controller,
+			Description: "Restart cluster controller",
+			Data:        data(),
+		},
+	)
+
+	result := &storage.OperationPlan{
+		OperationID:   operation.ID,
+		OperationType: operation.Type,
+		AccountID:     operation.AccountID,
+		ClusterName:   operation.SiteDomain,
+		Phases:        plan.AsPhases(),
+		Servers:       servers,
+		DNSConfig:     dnsConfig,
+	}
+	update.ResolvePlan(result)
+
+	return result, nil
+}
+
+// formatInitialCluster returns the etcd initial cluster configuration
+// with the members running on the specified master nodes.
+// Every master node is expected to run a member of the cluster
+func formatInitialCluster(masters []storage.Server, members []clientv3.Member) (string, error) {
+	var cluster []string
+	for _, master := range masters {
+		member, err := findMember(master, members)
+		if err != nil {
+			return "", trace.Wrap(err)
+		}
+		cluster = append(cluster, fmt.Sprintf("%v=%v", member.Name, peerURL(master)))
+	}
+	if len(cluster) != len(members) {
+		return "", trace.BadParameter("etcd cluster has %v members but there are %v master nodes, "+
+			"make sure the etcd cluster membership is consistent with the cluster state",
+			len(members), len(masters))
+	}
+	sort.Strings(cluster)
+	return strings.Join(cluster, ","), nil
+}
+
+func findMember(master storage.Server, members []clientv3.Member) (*clientv3.Member, error) {
+	for i, member := range members {
+		for _, peer := range member.PeerURLs {
+			u, err := url.Parse(peer)
+			if err == nil && u.Hostname() == master.GetClusterAddr() {
+				if member.Name == "" {
+					return nil, trace.BadParameter("etcd member on master node %v has not started",
+						master.Hostname)
+				}
+				return &members[i], nil
+			}
+		}
+	}
+	return nil, trace.NotFound("no etcd member found for master node %v", master.Hostname)
+}
+
+func peerURL(server storage.Server) string {
+	return fmt.Sprintf("https://%v:%v", server.GetClusterAddr(), defaults.EtcdPeerPort)
+}
+
+// snapshotPackage returns the locator of the package the snapshot
+// is uploaded to for the master nodes to fetch it
+func snapshotPackage(operation ops.SiteOperation) loc.Locator {
+	return loc.Locator{
+		Repository: operation.SiteDomain,
+		Name:       phases.SnapshotPackageName,
+		Version:    fmt.Sprintf("0.0.%v", operation.Created.Unix()),
+	}
+}
+
+func listMembers(ctx context.Context) ([]clientv3.Member, error) {
+	config, err := keyval.LocalEtcdConfig(0)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	client, err := etcd.NewClient(*config)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(ctx, defaults.EtcdHealthTimeout)
+	defer cancel()
+	resp, err := client.MemberList(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to list etcd members")
+	}
+	var members []clientv3.Member
+	for _, member := range resp.Members {
+		members = append(members, clientv3.Member(*member))
+	}
+	return members, nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcdrestore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/update/internal/testutils"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+func TestEtcdRestore(t *testing.T) { TestingT(t) }
+
+type S struct {
+	servers []storage.Server
+	members []clientv3.Member
+}
+
+var _ = Suite(&S{})
+
+func (s *S) SetUpTest(c *C) {
+	s.servers = []storage.Server{
+		{Hostname: "node-1", AdvertiseIP: "10.0.0.1", Role: "node", ClusterRole: string(schema.ServiceRoleMaster)},
+		{Hostname: "node-2", AdvertiseIP: "10.0.0.2", Role: "node", ClusterRole: string(schema.ServiceRoleMaster)},
+		{Hostname: "node-3", AdvertiseIP: "10.0.0.3", Role: "node", ClusterRole: string(schema.ServiceRoleNode)},
+	}
+	s.members = []clientv3.Member{
+		{Name: "10_0_0_2.cluster", PeerURLs: []string{"https://10.0.0.2:2380"}},
+		{Name: "10_0_0_1.cluster", PeerURLs: []string{"https://10.0.0.1:2380"}},
+	}
+}
+
+func (s *S) TestRestoresAllMasters(c *C) {
+	plan, err := newOperationPlan(storage.DefaultDNSConfig, operation, s.servers, s.servers[0], s.members)
+	c.Assert(err, IsNil)
+	c.Assert(testutils.PhaseIDs(plan.Phases), DeepEquals, []string{
+		"/validate", "/snapshot", "/stage", "/shutdown", "/restore", "/start", "/verify", "/controller",
+	})
+	for i, phase := range plan.Phases {
+		if i > 0 {
+			c.Assert(phase.Requires, DeepEquals, []string{plan.Phases[i-1].ID})
+		}
+	}
+	c.Assert(plan.Phases[1].Data.Package.String(), Equals, "cluster/etcd-snapshot:0.0.1559354400")
+
+	restore := plan.Phases[4]
+	c.Assert(restore.Parallel, Equals, true)
+	c.Assert(testutils.PhaseIDs(restore.Phases), DeepEquals, []string{"/restore/node-1", "/restore/node-2"})
+	for i, phase := range restore.Phases {
+		c.Assert(phase.Executor, Equals, "restore")
+		c.Assert(phase.Data.Server.Hostname, Equals, s.servers[i].Hostname)
+		c.Assert(phase.Data.Data, Equals,
+			"10_0_0_1.cluster=https://10.0.0.1:2380,10_0_0_2.cluster=https://10.0.0.2:2380")
+	}
+	for _, phase := range plan.Phases[2].Phases {
+		c.Assert(phase.Data.Package.String(), Equals, "cluster/etcd-snapshot:0.0.1559354400")
+	}
+}
+
+func (s *S) TestRequiresMemberOnEveryMaster(c *C) {
+	_, err := newOperationPlan(storage.DefaultDNSConfig, operation, s.servers, s.servers[0], s.members[:1])
+	c.Assert(trace.IsNotFound(err), Equals, true, Commentf("%v", err))
+
+	members := append(s.members, clientv3.Member{
+		Name: "10_0_0_4.cluster", PeerURLs: []string{"https://10.0.0.4:2380"},
+	})
+	_, err = newOperationPlan(storage.DefaultDNSConfig, operation, s.servers, s.servers[0], members)
+	c.Assert(trace.IsBadParameter(err), Equals, true, Commentf("%v", err))
+}
+
+func (s *S) TestRequiresMaster(c *C) {
+	_, err := newOperationPlan(storage.DefaultDNSConfig, operation, s.servers, s.servers[2], s.members)
+	c.Assert(trace.IsBadParameter(err), Equals, true)
+}
+
+var operation = testutils.NewOperation(ops.SiteOperation{
+	Type:    ops.OperationEtcdRestore,
+	Created: time.Date(2019, time.June, 1, 2, 0, 0, 0, time.UTC),
+	EtcdRestore: &storage.EtcdRestoreOperationState{
+		Snapshot: "/var/lib/gravity/backups/backup-20190601T020000Z/etcd.db",
+		Revision: 7,
+	},
+})
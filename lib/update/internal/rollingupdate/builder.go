@@ -50,6 +50,45 @@ func RuntimeConfigUpdates(
 	return updates, nil
 }
 
+// NextPassUpdates returns the runtime updates for the second pass over the
+// specified servers.
+// The configuration and secrets packages of the second pass extend the version
+// of the packages of the first pass so both passes can be executed on the same node
+func NextPassUpdates(updates []storage.UpdateServer) (result []storage.UpdateServer, err error) {
+	for _, server := range updates {
+		configPackage, err := nextPassPackage(server.Runtime.Update.ConfigPackage)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		runtimeUpdate := *server.Runtime.Update
+		runtimeUpdate.ConfigPackage = *configPackage
+		server.Runtime.Update = &runtimeUpdate
+		if server.Runtime.SecretsPackage != nil {
+			server.Runtime.SecretsPackage, err = nextPassPackage(*server.Runtime.SecretsPackage)
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+		}
+		result = append(result, server)
+	}
+	return result, nil
+}
+
+// nextPassPackage returns the locator of the package with the version
+// of the specified package extended with an increment
+func nextPassPackage(locator loc.Locator) (*loc.Locator, error) {
+	version, err := locator.SemVer()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if version.Metadata != "" {
+		version.Metadata += "."
+	}
+	version.Metadata += "1"
+	next := locator.WithVersion(version)
+	return &next, nil
+}
+
 // ConfigPackageRotator defines the subset of Operator for updating package configuration
 type ConfigPackageRotator interface {
 	RotatePlanetConfig(ops.RotatePlanetConfigRequest) (*ops.RotatePackageResponse, error)
@@ -73,6 +112,33 @@ func (r Builder) Config(rootText string, servers []storage.UpdateServer) *update
 	return &phase
 }
 
+// Rotation returns the phases to generate the runtime packages for the
+// specified servers and restart the runtime container on every server.
+// The phases have relative IDs and no dependencies
+func (r Builder) Rotation(updates []storage.UpdateServer, rootText, nodeTextFormat string) []update.Phase {
+	masters, nodes := update.SplitServers(updates)
+	packages := update.Phase{
+		ID:          libphase.Packages,
+		Executor:    libphase.Packages,
+		Description: "Generate new runtime packages",
+		Data: &storage.OperationPhaseData{
+			Package: &r.App,
+			Update: &storage.UpdateOperationData{
+				Servers: updates,
+			},
+		},
+	}
+	updateMasters := *r.Masters(masters, rootText, nodeTextFormat)
+	updateMasters.ID = "masters"
+	result := []update.Phase{packages, updateMasters}
+	if len(nodes) != 0 {
+		updateNodes := *r.Nodes(nodes, masters[0].Server, rootText, nodeTextFormat)
+		updateNodes.ID = "nodes"
+		result = append(result, updateNodes)
+	}
+	return result
+}
+
 // Masters returns a new phase to execute a rolling update of the specified list of master servers
 func (r Builder) Masters(servers []storage.UpdateServer, rootText, nodeTextFormat string) *update.Phase {
 	root := update.RootPhase(update.Phase{
@@ -214,6 +280,18 @@ type Builder struct {
 	App loc.Locator
 }
 
+// Drain returns a new phase to drain the specified server.
+// execer specifies the optional server to execute the phase on
+func (r Builder) Drain(server, execer *storage.Server) update.Phase {
+	return r.drain(server, execer)
+}
+
+// LeaderElection returns a new phase to change the leader election state in the cluster.
+// See setLeaderElection for details
+func (r Builder) LeaderElection(enable, disable []storage.Server, server storage.UpdateServer, id, format string) update.Phase {
+	return setLeaderElection(enable, disable, server, id, format)
+}
+
 // setLeaderElection creates a phase that will change the leader election state in the cluster
 // enable - the list of servers to enable election on
 // disable - the list of servers to disable election on
@@ -0,0 +1,196 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+	"io"
+
+	libfsm "github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/clusterconfig"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// NewPackages returns a new executor to generate the runtime configuration
+// packages and, for the servers that have one specified, the secrets packages.
+//
+// The configuration packages are generated with the cluster configuration
+// updated by the operation, if any, and the current cluster configuration otherwise
+func NewPackages(
+	params libfsm.ExecutorParams,
+	operator packageRotator,
+	operation ops.SiteOperation,
+	apps appGetter,
+	packages, hostPackages packageService,
+	logger log.FieldLogger,
+) (*packagesExecutor, error) {
+	if params.Phase.Data == nil || params.Phase.Data.Package == nil {
+		return nil, trace.NotFound("no installed application package specified for phase %q",
+			params.Phase.ID)
+	}
+	if params.Phase.Data.Update == nil || len(params.Phase.Data.Update.Servers) == 0 {
+		return nil, trace.NotFound("no servers specified for phase %q",
+			params.Phase.ID)
+	}
+	app, err := apps.GetApp(*params.Phase.Data.Package)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to query installed application")
+	}
+	env, err := operator.GetClusterEnvironmentVariables(operation.ClusterKey())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	config, err := clusterConfig(operator, operation)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &packagesExecutor{
+		FieldLogger:  logger,
+		operator:     operator,
+		operation:    operation,
+		packages:     packages,
+		hostPackages: hostPackages,
+		servers:      params.Phase.Data.Update.Servers,
+		manifest:     app.Manifest,
+		env:          env.GetKeyValues(),
+		config:       config,
+	}, nil
+}
+
+// Execute generates new runtime packages for the servers
+func (r *packagesExecutor) Execute(ctx context.Context) error {
+	for _, server := range r.servers {
+		if err := r.generatePackages(server); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// Rollback removes the generated packages
+func (r *packagesExecutor) Rollback(context.Context) error {
+	for _, server := range r.servers {
+		locators := []loc.Locator{server.Runtime.Update.ConfigPackage}
+		if server.Runtime.SecretsPackage != nil {
+			locators = append(locators, *server.Runtime.SecretsPackage)
+		}
+		for _, locator := range locators {
+			for _, packages := range []packageService{r.packages, r.hostPackages} {
+				err := packages.DeletePackage(locator)
+				if err != nil && !trace.IsNotFound(err) {
+					return trace.Wrap(err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// PreCheck is a no-op
+func (r *packagesExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (r *packagesExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+func (r *packagesExecutor) generatePackages(server storage.UpdateServer) error {
+	if server.Runtime.SecretsPackage != nil {
+		r.Infof("Generate new secrets package for %v.", server.Server)
+		resp, err := r.operator.RotateSecrets(ops.RotateSecretsRequest{
+			Key:            r.operation.ClusterKey(),
+			Server:         server.Server,
+			RuntimePackage: server.Runtime.Update.Package,
+			Package:        server.Runtime.SecretsPackage,
+		})
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		_, err = r.packages.UpsertPackage(resp.Locator, resp.Reader,
+			pack.WithLabels(resp.Labels))
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	r.Infof("Generate new runtime configuration package for %v.", server.Server)
+	resp, err := r.operator.RotatePlanetConfig(ops.RotatePlanetConfigRequest{
+		Key:            r.operation.Key(),
+		Server:         server.Server,
+		Manifest:       r.manifest,
+		RuntimePackage: server.Runtime.Update.Package,
+		Package:        &server.Runtime.Update.ConfigPackage,
+		Config:         r.config,
+		Env:            r.env,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = r.packages.UpsertPackage(resp.Locator, resp.Reader,
+		pack.WithLabels(resp.Labels))
+	return trace.Wrap(err)
+}
+
+// clusterConfig returns the serialized cluster configuration
+// to generate the runtime configuration packages with
+func clusterConfig(operator packageRotator, operation ops.SiteOperation) ([]byte, error) {
+	if operation.UpdateConfig != nil && len(operation.UpdateConfig.Config) != 0 {
+		return operation.UpdateConfig.Config, nil
+	}
+	config, err := operator.GetClusterConfiguration(operation.ClusterKey())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	configBytes, err := clusterconfig.Marshal(config)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return configBytes, nil
+}
+
+type packagesExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	operator     packageRotator
+	operation    ops.SiteOperation
+	packages     packageService
+	hostPackages packageService
+	servers      []storage.UpdateServer
+	manifest     schema.Manifest
+	env          map[string]string
+	config       []byte
+}
+
+type packageRotator interface {
+	RotateSecrets(ops.RotateSecretsRequest) (*ops.RotatePackageResponse, error)
+	RotatePlanetConfig(ops.RotatePlanetConfigRequest) (*ops.RotatePackageResponse, error)
+	GetClusterEnvironmentVariables(ops.SiteKey) (storage.EnvironmentVariables, error)
+	GetClusterConfiguration(ops.SiteKey) (clusterconfig.Interface, error)
+}
+
+type packageService interface {
+	UpsertPackage(loc.Locator, io.Reader, ...pack.PackageOption) (*pack.PackageEnvelope, error)
+	DeletePackage(loc.Locator) error
+}
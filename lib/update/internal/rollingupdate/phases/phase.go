@@ -22,6 +22,8 @@ import (
 )
 
 const (
+	// Packages defines the phase to generate new runtime packages
+	Packages = "packages"
 	// UpdateConfig defines the phase to update runtime configuration package
 	UpdateConfig = "update-config"
 	// RestartContainer defines the phase to restart runtime container to make the
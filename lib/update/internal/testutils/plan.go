@@ -0,0 +1,40 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package testutils implements helpers shared by the operation plan tests
+package testutils
+
+import (
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+)
+
+// NewOperation returns the specified operation with the key
+// of the test operation in the test cluster
+func NewOperation(operation ops.SiteOperation) ops.SiteOperation {
+	operation.ID = "1"
+	operation.AccountID = "0"
+	operation.SiteDomain = "cluster"
+	return operation
+}
+
+// PhaseIDs returns the IDs of the specified phases
+func PhaseIDs(phases []storage.OperationPhase) (ids []string) {
+	for _, phase := range phases {
+		ids = append(ids, phase.ID)
+	}
+	return ids
+}
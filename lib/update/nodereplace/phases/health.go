@@ -0,0 +1,111 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	libfsm "github.com/gravitational/gravity/lib/fsm"
+	libkubernetes "github.com/gravitational/gravity/lib/kubernetes"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/cenkalti/backoff"
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// NewHealth returns a new executor that waits for the replacement node
+// to become ready before workloads are migrated to it
+func NewHealth(
+	params libfsm.ExecutorParams,
+	operation ops.SiteOperation,
+	backend storage.Backend,
+	client *kubernetes.Clientset,
+	logger log.FieldLogger,
+) (*healthExecutor, error) {
+	if operation.ReplaceNode == nil {
+		return nil, trace.BadParameter("operation %v does not replace a node", operation.ID)
+	}
+	if client == nil {
+		return nil, trace.BadParameter("phase %q requires a Kubernetes client", params.Phase.ID)
+	}
+	return &healthExecutor{
+		FieldLogger: logger,
+		backend:     backend,
+		client:      client,
+		addr:        operation.ReplaceNode.NewAddr,
+	}, nil
+}
+
+// Execute waits for the Kubernetes node of the replacement node to become ready
+func (r *healthExecutor) Execute(ctx context.Context) error {
+	server, err := findServer(r.backend, r.addr)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	r.Infof("Wait for %v to become ready.", server)
+	ctx, cancel := context.WithTimeout(ctx, defaults.NodeStatusTimeout)
+	defer cancel()
+	err = utils.RetryWithInterval(ctx, backoff.NewConstantBackOff(defaults.RetryInterval), func() error {
+		node, err := libkubernetes.GetNode(r.client, *server)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if !isNodeReady(*node) {
+			return trace.Retry(nil, "node %v is not ready", node.Name)
+		}
+		return nil
+	})
+	return trace.Wrap(err)
+}
+
+// Rollback is a no-op for this phase
+func (*healthExecutor) Rollback(context.Context) error {
+	return nil
+}
+
+// PreCheck is a no-op
+func (*healthExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*healthExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+type healthExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	backend storage.Backend
+	client  *kubernetes.Clientset
+	addr    string
+}
+
+func isNodeReady(node v1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
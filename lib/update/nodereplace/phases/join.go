@@ -0,0 +1,174 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/expand/roster"
+	libfsm "github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/rpc"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Join defines the phase to join the replacement node to the cluster
+	Join = "join"
+	// Labels defines the phase to apply the labels and taints of the replaced node
+	// to the replacement node
+	Labels = "labels"
+	// Health defines the phase to wait for the replacement node to become ready
+	Health = "health"
+	// Remove defines the phase to remove the replaced node from the cluster
+	Remove = "remove"
+)
+
+// NewJoin returns a new executor that joins the replacement node
+// to the cluster with the role of the replaced node
+func NewJoin(
+	params libfsm.ExecutorParams,
+	operator operator,
+	operation ops.SiteOperation,
+	backend storage.Backend,
+	agents rpc.AgentRepository,
+	logger log.FieldLogger,
+) (*joinExecutor, error) {
+	if params.Phase.Data == nil || params.Phase.Data.ExecServer == nil {
+		return nil, trace.NotFound("no server specified for phase %q", params.Phase.ID)
+	}
+	if operation.ReplaceNode == nil {
+		return nil, trace.BadParameter("operation %v does not replace a node", operation.ID)
+	}
+	return &joinExecutor{
+		FieldLogger: logger,
+		operator:    operator,
+		operation:   operation,
+		backend:     backend,
+		agents:      agents,
+		master:      *params.Phase.Data.ExecServer,
+	}, nil
+}
+
+// Execute joins the replacement node to the cluster.
+// Blocks until the node has joined
+func (r *joinExecutor) Execute(ctx context.Context) error {
+	state := r.operation.ReplaceNode
+	if server, err := findServer(r.backend, state.NewAddr); err == nil {
+		r.Infof("Node %v has already joined the cluster.", server)
+		return nil
+	}
+	node := roster.Node{
+		Addr:  state.NewAddr,
+		Role:  state.Server.Role,
+		Agent: state.SSH == nil,
+	}
+	token, err := roster.NewJoinToken(ctx, r.operator, r.operation.ClusterKey(), []roster.Node{node})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	joiner, err := roster.NewJoiner(roster.JoinerConfig{
+		PortalURL: fmt.Sprintf("https://%v:%v/portal/v1",
+			r.master.AdvertiseIP, defaults.GravitySiteNodePort),
+		Token:       token,
+		Agents:      r.agents,
+		FieldLogger: r.FieldLogger,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if state.SSH != nil {
+		node.SSH = &roster.SSHCredentials{
+			User:           state.SSH.User,
+			Port:           state.SSH.Port,
+			PrivateKeyPath: state.SSH.PrivateKeyPath,
+			HostKey:        state.SSH.HostKey,
+		}
+	}
+	r.Infof("Join %v.", node)
+	err = joiner.Join(ctx, node)
+	if err != nil {
+		return trace.Wrap(err, "failed to join node %v", state.NewAddr)
+	}
+	server, err := findServer(r.backend, state.NewAddr)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if server.ClusterRole != state.Server.ClusterRole {
+		return trace.BadParameter("node %v has joined with cluster role %q instead of %q",
+			server, server.ClusterRole, state.Server.ClusterRole)
+	}
+	return nil
+}
+
+// Rollback removes the replacement node from the cluster if it has joined
+func (r *joinExecutor) Rollback(ctx context.Context) error {
+	server, err := findServer(r.backend, r.operation.ReplaceNode.NewAddr)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil
+		}
+		return trace.Wrap(err)
+	}
+	r.Infof("Remove %v.", server)
+	return trace.Wrap(removeServer(ctx, r.operator, r.operation.ClusterKey(), *server, true))
+}
+
+// PreCheck is a no-op
+func (*joinExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*joinExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+type joinExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	operator  operator
+	operation ops.SiteOperation
+	backend   storage.Backend
+	agents    rpc.AgentRepository
+	master    storage.Server
+}
+
+// findServer returns the server with the specified advertise address from the cluster state
+func findServer(backend storage.Backend, addr string) (*storage.Server, error) {
+	cluster, err := backend.GetLocalSite(defaults.SystemAccountID)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, server := range cluster.ClusterState.Servers {
+		if server.AdvertiseIP == addr {
+			return &server, nil
+		}
+	}
+	return nil, trace.NotFound("node %v is not a member of the cluster", addr)
+}
+
+type operator interface {
+	CreateJoinToken(context.Context, ops.CreateJoinTokenRequest) (*storage.ProvisioningToken, error)
+	CreateSiteShrinkOperation(context.Context, ops.CreateSiteShrinkOperationRequest) (*ops.SiteOperationKey, error)
+	GetSiteOperation(ops.SiteOperationKey) (*ops.SiteOperation, error)
+}
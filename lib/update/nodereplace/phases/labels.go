@@ -0,0 +1,110 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	libfsm "github.com/gravitational/gravity/lib/fsm"
+	libkubernetes "github.com/gravitational/gravity/lib/kubernetes"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
+)
+
+// NewLabels returns a new executor that applies the custom labels and taints
+// of the replaced node to the replacement node
+func NewLabels(
+	params libfsm.ExecutorParams,
+	operation ops.SiteOperation,
+	backend storage.Backend,
+	client *kubernetes.Clientset,
+	logger log.FieldLogger,
+) (*labelsExecutor, error) {
+	if operation.ReplaceNode == nil {
+		return nil, trace.BadParameter("operation %v does not replace a node", operation.ID)
+	}
+	if client == nil {
+		return nil, trace.BadParameter("phase %q requires a Kubernetes client", params.Phase.ID)
+	}
+	return &labelsExecutor{
+		FieldLogger: logger,
+		backend:     backend,
+		client:      client,
+		replaced:    operation.ReplaceNode.Server,
+		addr:        operation.ReplaceNode.NewAddr,
+	}, nil
+}
+
+// Execute records the labels and taints of the replaced node for the replacement
+// node in the cluster state and applies them to the Kubernetes node
+func (r *labelsExecutor) Execute(ctx context.Context) error {
+	if len(r.replaced.Labels) == 0 && len(r.replaced.Taints) == 0 {
+		r.Info("Replaced node has no custom labels or taints.")
+		return nil
+	}
+	cluster, err := r.backend.GetLocalSite(defaults.SystemAccountID)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	var server *storage.Server
+	for i := range cluster.ClusterState.Servers {
+		if cluster.ClusterState.Servers[i].AdvertiseIP == r.addr {
+			server = &cluster.ClusterState.Servers[i]
+		}
+	}
+	if server == nil {
+		return trace.NotFound("node %v is not a member of the cluster", r.addr)
+	}
+	server.Labels = r.replaced.Labels
+	server.Taints = r.replaced.Taints
+	_, err = r.backend.UpdateSite(*cluster)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	r.Infof("Apply labels %v and taints %v to %v.", server.Labels, server.Taints, server)
+	return trace.Wrap(libkubernetes.ApplyServerLabelsAndTaints(ctx, r.client, *server))
+}
+
+// Rollback is a no-op for this phase as the replacement node
+// is removed by the rollback of the join phase
+func (*labelsExecutor) Rollback(context.Context) error {
+	return nil
+}
+
+// PreCheck is a no-op
+func (*labelsExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*labelsExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+type labelsExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	backend  storage.Backend
+	client   *kubernetes.Clientset
+	replaced storage.Server
+	addr     string
+}
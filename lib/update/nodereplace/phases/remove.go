@@ -0,0 +1,119 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	libfsm "github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/cenkalti/backoff"
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// NewRemove returns a new executor that removes the replaced node from the cluster
+func NewRemove(
+	params libfsm.ExecutorParams,
+	operator operator,
+	operation ops.SiteOperation,
+	backend storage.Backend,
+	logger log.FieldLogger,
+) (*removeExecutor, error) {
+	if operation.ReplaceNode == nil {
+		return nil, trace.BadParameter("operation %v does not replace a node", operation.ID)
+	}
+	return &removeExecutor{
+		FieldLogger: logger,
+		operator:    operator,
+		operation:   operation,
+		backend:     backend,
+	}, nil
+}
+
+// Execute removes the replaced node from the cluster.
+// Blocks until the node has been removed
+func (r *removeExecutor) Execute(ctx context.Context) error {
+	state := r.operation.ReplaceNode
+	server, err := findServer(r.backend, state.Server.AdvertiseIP)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			r.Infof("Node %v has already been removed.", state.Server)
+			return nil
+		}
+		return trace.Wrap(err)
+	}
+	r.Infof("Remove %v.", server)
+	return trace.Wrap(removeServer(ctx, r.operator, r.operation.ClusterKey(), *server, state.Force))
+}
+
+// Rollback is not supported as the removed node can't be restored
+func (r *removeExecutor) Rollback(context.Context) error {
+	return trace.BadParameter("node %v can't be restored once it has been removed, "+
+		"join it to the cluster again instead", r.operation.ReplaceNode.Server.Hostname)
+}
+
+// PreCheck is a no-op
+func (*removeExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*removeExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+type removeExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	operator  operator
+	operation ops.SiteOperation
+	backend   storage.Backend
+}
+
+// removeServer removes the specified server from the cluster with a shrink operation
+// and waits for the operation to finish
+func removeServer(ctx context.Context, operator operator, key ops.SiteKey, server storage.Server, force bool) error {
+	shrinkKey, err := operator.CreateSiteShrinkOperation(ctx, ops.CreateSiteShrinkOperationRequest{
+		AccountID:  key.AccountID,
+		SiteDomain: key.SiteDomain,
+		Servers:    []string{server.Hostname},
+		Force:      force,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = utils.RetryWithInterval(ctx, backoff.NewConstantBackOff(defaults.RetryInterval), func() error {
+		operation, err := operator.GetSiteOperation(*shrinkKey)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		switch {
+		case operation.IsCompleted():
+			return nil
+		case operation.IsFailed():
+			return &backoff.PermanentError{Err: trace.BadParameter("failed to remove node %v, "+
+				"see 'gravity status' for details", server.Hostname)}
+		}
+		return trace.Retry(nil, "node %v is being removed", server.Hostname)
+	})
+	return trace.Wrap(err)
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodereplace
+
+import (
+	"fmt"
+
+	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/update"
+	"github.com/gravitational/gravity/lib/update/internal/rollingupdate"
+	"github.com/gravitational/gravity/lib/update/nodereplace/phases"
+
+	"github.com/gravitational/trace"
+)
+
+// NewOperationPlan creates a new operation plan for the specified operation
+func NewOperationPlan(
+	operator ops.Operator,
+	apps app.Applications,
+	operation ops.SiteOperation,
+	servers []storage.Server,
+	leader storage.Server,
+) (plan *storage.OperationPlan, err error) {
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	app, err := apps.GetApp(cluster.App.Package)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to query installed application")
+	}
+	plan, err = newOperationPlan(*app, cluster.DNSConfig, operation, servers, leader)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	err = operator.CreateOperationPlan(operation.Key(), *plan)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotImplemented(
+				"cluster operator does not implement the API required to replace a node. " +
+					"Please make sure you're running the command on a compatible cluster.")
+		}
+		return nil, trace.Wrap(err)
+	}
+	return plan, nil
+}
+
+// newOperationPlan returns a new plan for the specified operation
+// executed on the leader node
+func newOperationPlan(
+	app app.Application,
+	dnsConfig storage.DNSConfig,
+	operation ops.SiteOperation,
+	servers []storage.Server,
+	leader storage.Server,
+) (*storage.OperationPlan, error) {
+	if operation.ReplaceNode == nil {
+		return nil, trace.BadParameter("operation %v does not replace a node", operation.ID)
+	}
+	state := operation.ReplaceNode
+	target := state.Server
+	if target.AdvertiseIP == leader.AdvertiseIP {
+		return nil, trace.BadParameter("node %v can't be replaced from itself, "+
+			"run the operation on another master node", target.Hostname)
+	}
+	builder := rollingupdate.Builder{App: app.Package}
+	join := update.RootPhase(update.Phase{
+		ID:       "join",
+		Executor: phases.Join,
+		Description: fmt.Sprintf("Join node %v with role %q to replace node %q",
+			state.NewAddr, target.Role, target.Hostname),
+		Data: &storage.OperationPhaseData{
+			ExecServer: &leader,
+		},
+	})
+	labels := update.RootPhase(update.Phase{
+		ID:          "labels",
+		Executor:    phases.Labels,
+		Description: fmt.Sprintf("Apply labels and taints of node %q to node %v", target.Hostname, state.NewAddr),
+		Data: &storage.OperationPhaseData{
+			ExecServer: &leader,
+		},
+	})
+	health := update.RootPhase(update.Phase{
+		ID:          "health",
+		Executor:    phases.Health,
+		Description: fmt.Sprintf("Wait for node %v to become ready", state.NewAddr),
+		Data: &storage.OperationPhaseData{
+			ExecServer: &leader,
+		},
+	})
+	remove := update.RootPhase(update.Phase{
+		ID:          "remove",
+		Executor:    phases.Remove,
+		Description: fmt.Sprintf("Remove node %q from the cluster", target.Hostname),
+		Data: &storage.OperationPhaseData{
+			Server:     &target,
+			ExecServer: &leader,
+		},
+	})
+
+	labels.Require(join)
+	health.Require(labels)
+	plan := update.Phases{join, labels, health}
+	if state.Force {
+		// The replaced node might be offline and can't be drained:
+		// its workloads are rescheduled once it has been removed
+		remove.Require(health)
+	} else {
+		drain := update.RootPhase(builder.Drain(&target, &leader))
+		drain.Require(health)
+		remove.Require(drain)
+		plan = append(plan, drain)
+	}
+	plan = append(plan, remove)
+
+	result := &storage.OperationPlan{
+		OperationID:   operation.ID,
+		OperationType: operation.Type,
+		AccountID:     operation.AccountID,
+		ClusterName:   operation.SiteDomain,
+		Phases:        plan.AsPhases(),
+		Servers:       servers,
+		DNSConfig:     dnsConfig,
+	}
+	update.ResolvePlan(result)
+
+	return result, nil
+}
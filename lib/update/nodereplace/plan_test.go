@@ -0,0 +1,105 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodereplace
+
+import (
+	"testing"
+
+	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+	libphase "github.com/gravitational/gravity/lib/update/internal/rollingupdate/phases"
+	"github.com/gravitational/gravity/lib/update/internal/testutils"
+	"github.com/gravitational/gravity/lib/update/nodereplace/phases"
+
+	. "gopkg.in/check.v1"
+)
+
+func TestNodeReplace(t *testing.T) { TestingT(t) }
+
+type S struct {
+	app     app.Application
+	servers []storage.Server
+}
+
+var _ = Suite(&S{})
+
+func (s *S) SetUpTest(c *C) {
+	s.servers = []storage.Server{
+		{Hostname: "node-1", AdvertiseIP: "10.0.0.1", Role: "node", ClusterRole: string(schema.ServiceRoleMaster)},
+		{Hostname: "node-2", AdvertiseIP: "10.0.0.2", Role: "node", ClusterRole: string(schema.ServiceRoleNode)},
+	}
+	s.app = app.Application{
+		Package: loc.MustParseLocator("gravitational.io/app:0.0.1"),
+		Manifest: schema.Manifest{
+			NodeProfiles: schema.NodeProfiles{{Name: "node"}},
+		},
+	}
+}
+
+func (s *S) TestReplacementPlan(c *C) {
+	operation := newOperation(s.servers[1], false)
+	plan, err := newOperationPlan(s.app, storage.DefaultDNSConfig, operation, s.servers, s.servers[0])
+	c.Assert(err, IsNil)
+
+	c.Assert(testutils.PhaseIDs(plan.Phases), DeepEquals, []string{"/join", "/labels", "/health", "/drain", "/remove"})
+	for i := 1; i < len(plan.Phases); i++ {
+		c.Assert(plan.Phases[i].Requires, DeepEquals, []string{plan.Phases[i-1].ID})
+	}
+	c.Assert(plan.Phases[0].Executor, Equals, phases.Join)
+
+	drain := plan.Phases[3]
+	c.Assert(drain.Executor, Equals, libphase.Drain)
+	c.Assert(drain.Data.Server.Hostname, Equals, "node-2")
+
+	remove := plan.Phases[4]
+	c.Assert(remove.Executor, Equals, phases.Remove)
+	c.Assert(remove.Data.Server.Hostname, Equals, "node-2")
+
+	// All phases are executed on the node that runs the operation
+	for _, phase := range plan.Phases {
+		c.Assert(phase.Data.ExecServer.Hostname, Equals, "node-1", Commentf(phase.ID))
+	}
+}
+
+func (s *S) TestForcedReplacementSkipsDrain(c *C) {
+	operation := newOperation(s.servers[1], true)
+	plan, err := newOperationPlan(s.app, storage.DefaultDNSConfig, operation, s.servers, s.servers[0])
+	c.Assert(err, IsNil)
+
+	c.Assert(testutils.PhaseIDs(plan.Phases), DeepEquals, []string{"/join", "/labels", "/health", "/remove"})
+	c.Assert(plan.Phases[3].Requires, DeepEquals, []string{"/health"})
+}
+
+func (s *S) TestCannotReplaceLocalNode(c *C) {
+	operation := newOperation(s.servers[0], false)
+	_, err := newOperationPlan(s.app, storage.DefaultDNSConfig, operation, s.servers, s.servers[0])
+	c.Assert(err, NotNil)
+}
+
+func newOperation(server storage.Server, force bool) ops.SiteOperation {
+	return testutils.NewOperation(ops.SiteOperation{
+		Type: ops.OperationReplaceNode,
+		ReplaceNode: &storage.ReplaceNodeOperationState{
+			Server:  server,
+			NewAddr: "10.0.0.3",
+			Force:   force,
+		},
+	})
+}
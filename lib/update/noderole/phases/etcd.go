@@ -0,0 +1,159 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gravitational/gravity/lib/clients"
+	"github.com/gravitational/gravity/lib/defaults"
+	libfsm "github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/state"
+	"github.com/gravitational/gravity/lib/storage"
+
+	etcd "github.com/coreos/etcd/client"
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// NewEtcd returns a new executor that adds the node being promoted to the etcd cluster
+// or removes the node being demoted from it
+func NewEtcd(params libfsm.ExecutorParams, operation ops.SiteOperation, logger log.FieldLogger) (*etcdExecutor, error) {
+	if params.Phase.Data == nil || params.Phase.Data.Server == nil {
+		return nil, trace.NotFound("no server specified for phase %q", params.Phase.ID)
+	}
+	if operation.UpdateNodeRole == nil {
+		return nil, trace.BadParameter("operation %v does not update node role", operation.ID)
+	}
+	server := *params.Phase.Data.Server
+	var endpoints []string
+	for _, master := range params.Plan.Servers {
+		if master.IsMaster() && master.AdvertiseIP != server.AdvertiseIP {
+			endpoints = append(endpoints, fmt.Sprintf("https://%v:%v",
+				master.AdvertiseIP, defaults.EtcdAPIPort))
+		}
+	}
+	stateDir, err := state.GetStateDir()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	client, err := clients.EtcdMembers(&clients.EtcdConfig{
+		Endpoints:  endpoints,
+		SecretsDir: state.SecretDir(stateDir),
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &etcdExecutor{
+		FieldLogger: logger,
+		etcd:        client,
+		server:      server,
+		promote:     operation.UpdateNodeRole.IsPromotion(),
+	}, nil
+}
+
+// Execute adds the node to or removes it from the etcd cluster
+func (r *etcdExecutor) Execute(ctx context.Context) error {
+	if r.promote {
+		return trace.Wrap(r.addMember(ctx))
+	}
+	return trace.Wrap(r.removeMember(ctx))
+}
+
+// Rollback reverts the etcd cluster membership change.
+// Note, the etcd data of a demoted member is not restored and the member
+// needs to resync from the cluster after it has been added back
+func (r *etcdExecutor) Rollback(ctx context.Context) error {
+	if r.promote {
+		return trace.Wrap(r.removeMember(ctx))
+	}
+	return trace.Wrap(r.addMember(ctx))
+}
+
+// PreCheck is a no-op
+func (*etcdExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*etcdExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+func (r *etcdExecutor) addMember(ctx context.Context) error {
+	member, err := r.findMember(ctx)
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	if member != nil {
+		r.Infof("Node %v is already an etcd member: %v.", r.server, member)
+		return nil
+	}
+	member, err = r.etcd.Add(ctx, r.peerURL())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	r.Infof("Added etcd member: %v.", member)
+	return nil
+}
+
+func (r *etcdExecutor) removeMember(ctx context.Context) error {
+	member, err := r.findMember(ctx)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			r.Infof("Node %v is not an etcd member.", r.server)
+			return nil
+		}
+		return trace.Wrap(err)
+	}
+	err = r.etcd.Remove(ctx, member.ID)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	r.Infof("Removed etcd member: %v.", member)
+	return nil
+}
+
+func (r *etcdExecutor) findMember(ctx context.Context) (*etcd.Member, error) {
+	members, err := r.etcd.List(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	peerURL := r.peerURL()
+	for _, member := range members {
+		for _, url := range member.PeerURLs {
+			if url == peerURL {
+				return &member, nil
+			}
+		}
+	}
+	return nil, trace.NotFound("no etcd member with peer URL %v", peerURL)
+}
+
+func (r *etcdExecutor) peerURL() string {
+	return fmt.Sprintf("https://%v:%v", r.server.GetClusterAddr(), defaults.EtcdPeerPort)
+}
+
+type etcdExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	etcd    etcd.MembersAPI
+	server  storage.Server
+	promote bool
+}
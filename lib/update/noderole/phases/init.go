@@ -0,0 +1,184 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+	"io"
+
+	"github.com/gravitational/gravity/lib/app"
+	libfsm "github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/clusterconfig"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Init defines the phase to generate runtime packages for the new node role
+	Init = "init"
+	// Etcd defines the phase to update the etcd cluster membership of the node
+	Etcd = "etcd"
+	// State defines the phase to record the new node role in the cluster state
+	State = "state"
+)
+
+// NewInit returns a new executor to generate the runtime configuration
+// and secrets packages for the node with the new cluster role
+func NewInit(
+	params libfsm.ExecutorParams,
+	operator operator,
+	operation ops.SiteOperation,
+	apps appGetter,
+	packages, hostPackages packageService,
+	logger log.FieldLogger,
+) (*initExecutor, error) {
+	if params.Phase.Data == nil || params.Phase.Data.Package == nil {
+		return nil, trace.NotFound("no installed application package specified for phase %q",
+			params.Phase.ID)
+	}
+	if params.Phase.Data.Update == nil || len(params.Phase.Data.Update.Servers) != 1 {
+		return nil, trace.NotFound("no server specified for phase %q",
+			params.Phase.ID)
+	}
+	if operation.UpdateNodeRole == nil {
+		return nil, trace.BadParameter("operation %v does not update node role", operation.ID)
+	}
+	app, err := apps.GetApp(*params.Phase.Data.Package)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to query installed application")
+	}
+	env, err := operator.GetClusterEnvironmentVariables(operation.ClusterKey())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	config, err := operator.GetClusterConfiguration(operation.ClusterKey())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	configBytes, err := clusterconfig.Marshal(config)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &initExecutor{
+		FieldLogger:  logger,
+		operator:     operator,
+		operation:    operation,
+		packages:     packages,
+		hostPackages: hostPackages,
+		update:       params.Phase.Data.Update.Servers[0],
+		manifest:     app.Manifest,
+		env:          env.GetKeyValues(),
+		config:       configBytes,
+	}, nil
+}
+
+// Execute generates new runtime configuration and secrets packages for the node
+func (r *initExecutor) Execute(ctx context.Context) error {
+	r.Infof("Generate new secrets package for %v.", r.update.Server)
+	resp, err := r.operator.RotateSecrets(ops.RotateSecretsRequest{
+		Key:            r.operation.ClusterKey(),
+		Server:         r.update.Server,
+		RuntimePackage: r.update.Runtime.Update.Package,
+		Package:        r.update.Runtime.SecretsPackage,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = r.packages.UpsertPackage(resp.Locator, resp.Reader,
+		pack.WithLabels(resp.Labels))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	r.Infof("Generate new runtime configuration package for %v.", r.update.Server)
+	resp, err = r.operator.RotatePlanetConfig(ops.RotatePlanetConfigRequest{
+		Key:            r.operation.Key(),
+		Server:         r.update.Server,
+		Manifest:       r.manifest,
+		RuntimePackage: r.update.Runtime.Update.Package,
+		Package:        &r.update.Runtime.Update.ConfigPackage,
+		Config:         r.config,
+		Env:            r.env,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = r.packages.UpsertPackage(resp.Locator, resp.Reader,
+		pack.WithLabels(resp.Labels))
+	return trace.Wrap(err)
+}
+
+// Rollback removes the generated packages
+func (r *initExecutor) Rollback(context.Context) error {
+	locators := []loc.Locator{r.update.Runtime.Update.ConfigPackage}
+	if r.update.Runtime.SecretsPackage != nil {
+		locators = append(locators, *r.update.Runtime.SecretsPackage)
+	}
+	for _, locator := range locators {
+		for _, packages := range []packageService{r.packages, r.hostPackages} {
+			err := packages.DeletePackage(locator)
+			if err != nil && !trace.IsNotFound(err) {
+				return trace.Wrap(err)
+			}
+		}
+	}
+	return nil
+}
+
+// PreCheck is a no-op
+func (r *initExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (r *initExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+type initExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	operator     operator
+	operation    ops.SiteOperation
+	packages     packageService
+	hostPackages packageService
+	update       storage.UpdateServer
+	manifest     schema.Manifest
+	env          map[string]string
+	config       []byte
+}
+
+type operator interface {
+	RotateSecrets(ops.RotateSecretsRequest) (*ops.RotatePackageResponse, error)
+	RotatePlanetConfig(ops.RotatePlanetConfigRequest) (*ops.RotatePackageResponse, error)
+	GetClusterEnvironmentVariables(ops.SiteKey) (storage.EnvironmentVariables, error)
+	GetClusterConfiguration(ops.SiteKey) (clusterconfig.Interface, error)
+}
+
+type appGetter interface {
+	GetApp(loc.Locator) (*app.Application, error)
+}
+
+type packageService interface {
+	UpsertPackage(loc.Locator, io.Reader, ...pack.PackageOption) (*pack.PackageEnvelope, error)
+	DeletePackage(loc.Locator) error
+}
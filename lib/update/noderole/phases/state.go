@@ -0,0 +1,117 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	libfsm "github.com/gravitational/gravity/lib/fsm"
+	libkubernetes "github.com/gravitational/gravity/lib/kubernetes"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
+)
+
+// NewState returns a new executor that records the new cluster role
+// of the node in the cluster state and on the Kubernetes node
+func NewState(
+	params libfsm.ExecutorParams,
+	operation ops.SiteOperation,
+	backend storage.Backend,
+	client *kubernetes.Clientset,
+	logger log.FieldLogger,
+) (*stateExecutor, error) {
+	if operation.UpdateNodeRole == nil {
+		return nil, trace.BadParameter("operation %v does not update node role", operation.ID)
+	}
+	return &stateExecutor{
+		FieldLogger: logger,
+		backend:     backend,
+		client:      client,
+		server:      operation.UpdateNodeRole.Server,
+		role:        operation.UpdateNodeRole.ClusterRole,
+	}, nil
+}
+
+// Execute assigns the new cluster role to the node
+func (r *stateExecutor) Execute(ctx context.Context) error {
+	return trace.Wrap(r.setRole(ctx, r.role))
+}
+
+// Rollback restores the previous cluster role of the node
+func (r *stateExecutor) Rollback(ctx context.Context) error {
+	return trace.Wrap(r.setRole(ctx, r.server.ClusterRole))
+}
+
+// PreCheck is a no-op
+func (*stateExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*stateExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+func (r *stateExecutor) setRole(ctx context.Context, role string) error {
+	r.Infof("Set cluster role of %v to %q.", r.server, role)
+	cluster, err := r.backend.GetLocalSite(defaults.SystemAccountID)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	var found bool
+	for i, server := range cluster.ClusterState.Servers {
+		if server.AdvertiseIP == r.server.AdvertiseIP {
+			cluster.ClusterState.Servers[i].ClusterRole = role
+			found = true
+		}
+	}
+	if !found {
+		return trace.NotFound("couldn't find server %v in cluster state", r.server)
+	}
+	_, err = r.backend.UpdateSite(*cluster)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if r.client == nil {
+		return nil
+	}
+	node, err := libkubernetes.GetNode(r.client, r.server)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// Only nodes that have registered with the role label need an update
+	if _, ok := node.Labels[defaults.KubernetesRoleLabel]; !ok {
+		return nil
+	}
+	err = libkubernetes.UpdateLabels(ctx, r.client.CoreV1().Nodes(), node.Name,
+		map[string]string{defaults.KubernetesRoleLabel: role})
+	return trace.Wrap(err)
+}
+
+type stateExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	backend storage.Backend
+	client  *kubernetes.Clientset
+	server  storage.Server
+	role    string
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderole
+
+import (
+	"fmt"
+
+	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/update"
+	"github.com/gravitational/gravity/lib/update/internal/rollingupdate"
+	"github.com/gravitational/gravity/lib/update/noderole/phases"
+
+	"github.com/gravitational/trace"
+)
+
+// NewOperationPlan creates a new operation plan for the specified operation
+func NewOperationPlan(
+	operator ops.Operator,
+	apps app.Applications,
+	operation ops.SiteOperation,
+	servers []storage.Server,
+) (plan *storage.OperationPlan, err error) {
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	app, err := apps.GetApp(cluster.App.Package)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to query installed application")
+	}
+	plan, err = newOperationPlan(*app, cluster.DNSConfig, operator, operation, servers)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	err = operator.CreateOperationPlan(operation.Key(), *plan)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotImplemented(
+				"cluster operator does not implement the API required to update node role. " +
+					"Please make sure you're running the command on a compatible cluster.")
+		}
+		return nil, trace.Wrap(err)
+	}
+	return plan, nil
+}
+
+// newOperationPlan returns a new plan for the specified operation
+// and the given set of servers
+func newOperationPlan(
+	app app.Application,
+	dnsConfig storage.DNSConfig,
+	operator packageRotator,
+	operation ops.SiteOperation,
+	servers []storage.Server,
+) (*storage.OperationPlan, error) {
+	if operation.UpdateNodeRole == nil {
+		return nil, trace.BadParameter("operation %v does not update node role", operation.ID)
+	}
+	target := operation.UpdateNodeRole.Server
+	var master *storage.Server
+	for i, server := range servers {
+		if server.IsMaster() && server.AdvertiseIP != target.AdvertiseIP {
+			master = &servers[i]
+			break
+		}
+	}
+	if master == nil {
+		return nil, trace.NotFound("no other master servers found in cluster state")
+	}
+	// The node is configured as if it already had the new role
+	server := target
+	server.ClusterRole = operation.UpdateNodeRole.ClusterRole
+	updates, err := rollingupdate.RuntimeConfigUpdates(app.Manifest, operator, operation.Key(),
+		[]storage.Server{server})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	serverUpdate := updates[0]
+	secretsUpdate, err := operator.RotateSecrets(ops.RotateSecretsRequest{
+		Key:            operation.ClusterKey(),
+		Server:         server,
+		RuntimePackage: serverUpdate.Runtime.Update.Package,
+		DryRun:         true,
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	serverUpdate.Runtime.SecretsPackage = &secretsUpdate.Locator
+
+	builder := rollingupdate.Builder{App: app.Package}
+	var rootText, nodeFormat string
+	if operation.UpdateNodeRole.IsPromotion() {
+		rootText, nodeFormat = "Promote node to master", "Promote node %q to master"
+	} else {
+		rootText, nodeFormat = "Demote master to regular node", "Demote node %q to regular node"
+	}
+	init := update.RootPhase(update.Phase{
+		ID:          "init",
+		Executor:    phases.Init,
+		Description: fmt.Sprintf("Generate runtime packages for node %q", target.Hostname),
+		Data: &storage.OperationPhaseData{
+			Package: &app.Package,
+			Update: &storage.UpdateOperationData{
+				Servers: []storage.UpdateServer{serverUpdate},
+			},
+		},
+	})
+	etcd := update.RootPhase(update.Phase{
+		ID:          "etcd",
+		Executor:    phases.Etcd,
+		Description: etcdDescription(operation.UpdateNodeRole.IsPromotion(), target),
+		Data: &storage.OperationPhaseData{
+			Server:     &target,
+			ExecServer: master,
+		},
+	})
+	nodes := *builder.Nodes([]storage.UpdateServer{serverUpdate}, *master, rootText, nodeFormat)
+	var elections update.Phase
+	if operation.UpdateNodeRole.IsPromotion() {
+		elections = update.RootPhase(builder.LeaderElection(
+			[]storage.Server{server}, nil, serverUpdate,
+			"elections", "Enable leader election on node %q"))
+	} else {
+		elections = update.RootPhase(builder.LeaderElection(
+			nil, []storage.Server{target}, serverUpdate,
+			"elections", "Disable leader election on node %q"))
+	}
+	state := update.RootPhase(update.Phase{
+		ID:          "state",
+		Executor:    phases.State,
+		Description: fmt.Sprintf("Update cluster role of node %q", target.Hostname),
+		Data: &storage.OperationPhaseData{
+			Server:     &target,
+			ExecServer: master,
+		},
+	})
+
+	var plan update.Phases
+	if operation.UpdateNodeRole.IsPromotion() {
+		// The node joins etcd as a new member before it is restarted
+		// and only takes part in leader election once it runs the control plane
+		etcd.Require(init)
+		nodes.Require(etcd)
+		elections.Require(nodes)
+		state.Require(elections)
+		plan = update.Phases{init, etcd, nodes, elections, state}
+	} else {
+		// The node stops taking part in leader election and leaves etcd
+		// before it is restarted as a regular node
+		elections.Require(init)
+		etcd.Require(elections)
+		nodes.Require(etcd)
+		state.Require(nodes)
+		plan = update.Phases{init, elections, etcd, nodes, state}
+	}
+
+	result := &storage.OperationPlan{
+		OperationID:   operation.ID,
+		OperationType: operation.Type,
+		AccountID:     operation.AccountID,
+		ClusterName:   operation.SiteDomain,
+		Phases:        plan.AsPhases(),
+		Servers:       servers,
+		DNSConfig:     dnsConfig,
+	}
+	update.ResolvePlan(result)
+
+	return result, nil
+}
+
+func etcdDescription(promote bool, server storage.Server) string {
+	if promote {
+		return fmt.Sprintf("Add node %q to etcd cluster", server.Hostname)
+	}
+	return fmt.Sprintf("Remove node %q from etcd cluster", server.Hostname)
+}
+
+type packageRotator interface {
+	rollingupdate.ConfigPackageRotator
+	RotateSecrets(ops.RotateSecretsRequest) (*ops.RotatePackageResponse, error)
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderole
+
+import (
+	"testing"
+
+	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+	libphase "github.com/gravitational/gravity/lib/update/internal/rollingupdate/phases"
+	"github.com/gravitational/gravity/lib/update/internal/testutils"
+	"github.com/gravitational/gravity/lib/update/noderole/phases"
+
+	. "gopkg.in/check.v1"
+)
+
+func TestNodeRole(t *testing.T) { TestingT(t) }
+
+type S struct {
+	app     app.Application
+	servers []storage.Server
+}
+
+var _ = Suite(&S{})
+
+func (s *S) SetUpTest(c *C) {
+	s.servers = []storage.Server{
+		{Hostname: "node-1", AdvertiseIP: "10.0.0.1", Role: "node", ClusterRole: string(schema.ServiceRoleMaster)},
+		{Hostname: "node-2", AdvertiseIP: "10.0.0.2", Role: "node", ClusterRole: string(schema.ServiceRoleMaster)},
+		{Hostname: "node-3", AdvertiseIP: "10.0.0.3", Role: "node", ClusterRole: string(schema.ServiceRoleNode)},
+	}
+	s.app = app.Application{
+		Package: loc.MustParseLocator("gravitational.io/app:0.0.1"),
+		Manifest: schema.Manifest{
+			NodeProfiles: schema.NodeProfiles{{Name: "node"}},
+			SystemOptions: &schema.SystemOptions{
+				Dependencies: schema.SystemDependencies{
+					Runtime: &schema.Dependency{Locator: runtimeLoc},
+				},
+			},
+		},
+	}
+}
+
+func (s *S) TestPromotionPlan(c *C) {
+	operation := newOperation(s.servers[2], schema.ServiceRoleMaster)
+	plan, err := newOperationPlan(s.app, storage.DefaultDNSConfig, testOperator, operation, s.servers)
+	c.Assert(err, IsNil)
+
+	c.Assert(testutils.PhaseIDs(plan.Phases), DeepEquals, []string{"/init", "/etcd", "/nodes", "/elections", "/state"})
+	c.Assert(plan.Phases[1].Requires, DeepEquals, []string{"/init"})
+	c.Assert(plan.Phases[2].Requires, DeepEquals, []string{"/etcd"})
+	c.Assert(plan.Phases[3].Requires, DeepEquals, []string{"/nodes"})
+	c.Assert(plan.Phases[4].Requires, DeepEquals, []string{"/elections"})
+
+	init := plan.Phases[0]
+	c.Assert(init.Executor, Equals, phases.Init)
+	update := init.Data.Update.Servers[0]
+	c.Assert(update.ClusterRole, Equals, string(schema.ServiceRoleMaster))
+	c.Assert(update.Runtime.Update.ConfigPackage, Equals, testOperator.runtimeConfigPackage)
+	c.Assert(*update.Runtime.SecretsPackage, Equals, testOperator.secretsPackage)
+
+	etcd := plan.Phases[1]
+	c.Assert(etcd.Executor, Equals, phases.Etcd)
+	c.Assert(etcd.Data.Server.Hostname, Equals, "node-3")
+	c.Assert(etcd.Data.ExecServer.Hostname, Equals, "node-1")
+
+	restart := plan.Phases[2].Phases[0].Phases[1]
+	c.Assert(restart.ID, Equals, "/nodes/node-3/restart")
+	c.Assert(restart.Executor, Equals, libphase.RestartContainer)
+	c.Assert(restart.Data.Update.Servers, DeepEquals, []storage.UpdateServer{update})
+
+	elections := plan.Phases[3]
+	c.Assert(elections.Executor, Equals, libphase.Elections)
+	c.Assert(elections.Data.ElectionChange.EnableServers, HasLen, 1)
+	c.Assert(elections.Data.ElectionChange.EnableServers[0].Hostname, Equals, "node-3")
+	c.Assert(elections.Data.ElectionChange.DisableServers, HasLen, 0)
+}
+
+func (s *S) TestDemotionPlan(c *C) {
+	operation := newOperation(s.servers[0], schema.ServiceRoleNode)
+	plan, err := newOperationPlan(s.app, storage.DefaultDNSConfig, testOperator, operation, s.servers)
+	c.Assert(err, IsNil)
+
+	c.Assert(testutils.PhaseIDs(plan.Phases), DeepEquals, []string{"/init", "/elections", "/etcd", "/nodes", "/state"})
+	c.Assert(plan.Phases[1].Requires, DeepEquals, []string{"/init"})
+	c.Assert(plan.Phases[2].Requires, DeepEquals, []string{"/elections"})
+	c.Assert(plan.Phases[3].Requires, DeepEquals, []string{"/etcd"})
+	c.Assert(plan.Phases[4].Requires, DeepEquals, []string{"/nodes"})
+
+	update := plan.Phases[0].Data.Update.Servers[0]
+	c.Assert(update.ClusterRole, Equals, string(schema.ServiceRoleNode))
+
+	elections := plan.Phases[1]
+	c.Assert(elections.Data.ElectionChange.DisableServers, HasLen, 1)
+	c.Assert(elections.Data.ElectionChange.DisableServers[0].Hostname, Equals, "node-1")
+
+	// The phases that need a working control plane are executed on the remaining master
+	c.Assert(plan.Phases[2].Data.ExecServer.Hostname, Equals, "node-2")
+	c.Assert(plan.Phases[4].Data.ExecServer.Hostname, Equals, "node-2")
+}
+
+func (s *S) TestRequiresAnotherMaster(c *C) {
+	operation := newOperation(s.servers[0], schema.ServiceRoleNode)
+	_, err := newOperationPlan(s.app, storage.DefaultDNSConfig, testOperator, operation, s.servers[:1])
+	c.Assert(err, NotNil)
+}
+
+func newOperation(server storage.Server, role schema.ServiceRole) ops.SiteOperation {
+	return testutils.NewOperation(ops.SiteOperation{
+		Type: ops.OperationUpdateNodeRole,
+		UpdateNodeRole: &storage.UpdateNodeRoleOperationState{
+			Server:      server,
+			ClusterRole: string(role),
+		},
+	})
+}
+
+func (r testRotator) RotatePlanetConfig(ops.RotatePlanetConfigRequest) (*ops.RotatePackageResponse, error) {
+	return &ops.RotatePackageResponse{Locator: r.runtimeConfigPackage}, nil
+}
+
+func (r testRotator) RotateSecrets(ops.RotateSecretsRequest) (*ops.RotatePackageResponse, error) {
+	return &ops.RotatePackageResponse{Locator: r.secretsPackage}, nil
+}
+
+var runtimeLoc = loc.Locator{Repository: "foo", Name: "runtime", Version: "0.0.1"}
+
+var testOperator = testRotator{
+	runtimeConfigPackage: loc.Locator{Repository: "gravitational.io", Name: "planet-config", Version: "0.0.1"},
+	secretsPackage:       loc.Locator{Repository: "gravitational.io", Name: "planet-secrets", Version: "0.0.1"},
+}
+
+type testRotator struct {
+	runtimeConfigPackage loc.Locator
+	secretsPackage       loc.Locator
+}
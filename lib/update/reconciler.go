@@ -116,6 +116,9 @@ func SyncChangelog(src storage.Backend, dst storage.Backend, clusterName string,
 
 	diff := fsm.DiffChangelog(srcChangeLog, dstChangeLog)
 	for _, entry := range diff {
+		// Revisions are specific to the backend, so let the destination
+		// assign the next one
+		entry.Revision = 0
 		_, err = dst.CreateOperationPlanChange(entry)
 		if err != nil {
 			return trace.Wrap(err)
@@ -0,0 +1,154 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/backup"
+	libfsm "github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/state"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// NewApps returns a new executor to restore the application data
+// by running the restore hooks of the applications
+func NewApps(
+	params libfsm.ExecutorParams,
+	operation ops.SiteOperation,
+	operator ops.Operator,
+	apps app.Applications,
+	logger log.FieldLogger,
+) (*appsExecutor, error) {
+	if operation.Restore == nil {
+		return nil, trace.BadParameter("operation %v does not restore the cluster", operation.ID)
+	}
+	if apps == nil {
+		return nil, trace.BadParameter("phase %q requires the application service", params.Phase.ID)
+	}
+	if params.Phase.Data == nil || params.Phase.Data.ExecServer == nil {
+		return nil, trace.BadParameter("phase %q has no server", params.Phase.ID)
+	}
+	return &appsExecutor{
+		FieldLogger: logger,
+		operator:    operator,
+		apps:        apps,
+		server:      *params.Phase.Data.ExecServer,
+		dir:         operation.Restore.Backup,
+	}, nil
+}
+
+// Execute runs the restore hooks of the cluster applications whose data
+// is included in the backup.
+//
+// The hook jobs run on this node with the application data copied
+// into the planet state directory to be mounted into the hook container
+func (r *appsExecutor) Execute(ctx context.Context) error {
+	b, err := backup.Open(r.dir)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := r.operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	hooks, err := backup.NewAppHooks(backup.AppHooksConfig{
+		Apps:        r.apps,
+		Cluster:     cluster.App.Package,
+		NodeName:    r.server.KubeNodeID(),
+		FieldLogger: r.FieldLogger,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	apps, err := hooks.Applications(ctx, schema.HookRestore)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	for _, app := range apps {
+		dir, err := b.AppDir(app.Name)
+		if err != nil {
+			if trace.IsNotFound(err) {
+				r.Warnf("Backup has no data of application %v, skip restore.", app)
+				continue
+			}
+			return trace.Wrap(err)
+		}
+		if err := r.restoreApp(ctx, hooks, app, dir); err != nil {
+			return trace.Wrap(err, "failed to restore application %v", app)
+		}
+	}
+	return nil
+}
+
+func (r *appsExecutor) restoreApp(ctx context.Context, hooks *backup.AppHooks, app loc.Locator, dir string) error {
+	stateDir, err := state.GetStateDir()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// the planet state directory on host is mounted as /ext/state inside planet
+	// where the hook jobs are scheduled
+	stagingDir := filepath.Join(stateDir, "planet", "state", restoreStagingDir, app.Name)
+	if err := os.RemoveAll(stagingDir); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer os.RemoveAll(stagingDir)
+	if err := utils.CopyDirContents(dir, stagingDir); err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(hooks.Run(ctx, app, schema.HookRestore,
+		filepath.Join("/ext/state", restoreStagingDir, app.Name), ioutil.Discard))
+}
+
+// Rollback is a no-op as the restore hooks are not reversible
+func (*appsExecutor) Rollback(context.Context) error {
+	return nil
+}
+
+// PreCheck is a no-op
+func (*appsExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*appsExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+type appsExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	operator ops.Operator
+	apps     app.Applications
+	server   storage.Server
+	dir      string
+}
+
+// restoreStagingDir is the directory under the planet state directory
+// with the application data passed to the restore hooks
+const restoreStagingDir = "restore"
@@ -0,0 +1,225 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/backup"
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
+	libfsm "github.com/gravitational/gravity/lib/fsm"
+	libkubernetes "github.com/gravitational/gravity/lib/kubernetes"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/update"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/cenkalti/backoff"
+	"github.com/gravitational/rigging"
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// NewController returns a new executor to restart the cluster controller
+// so it picks up the restored cluster state
+func NewController(
+	params libfsm.ExecutorParams,
+	client *kubernetes.Clientset,
+	logger log.FieldLogger,
+) (*controllerExecutor, error) {
+	if client == nil {
+		return nil, trace.BadParameter("phase %q requires a Kubernetes client", params.Phase.ID)
+	}
+	return &controllerExecutor{
+		FieldLogger: logger,
+		client:      client,
+	}, nil
+}
+
+// Execute deletes the cluster controller pods to have them restarted
+func (r *controllerExecutor) Execute(ctx context.Context) error {
+	label := map[string]string{"app": constants.GravityServiceName}
+	r.Info("Restart cluster controller.")
+	err := update.Retry(ctx, func() error {
+		return trace.Wrap(libkubernetes.DeletePods(r.client, constants.KubeSystemNamespace, label))
+	}, defaults.DrainErrorTimeout)
+	return trace.Wrap(err)
+}
+
+// Rollback is a no-op for this phase
+func (*controllerExecutor) Rollback(context.Context) error {
+	return nil
+}
+
+// PreCheck is a no-op
+func (*controllerExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*controllerExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+type controllerExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	client *kubernetes.Clientset
+}
+
+// NewWorkers returns a new executor that prepares the worker nodes
+// of the backed up cluster to join the restored cluster
+func NewWorkers(
+	params libfsm.ExecutorParams,
+	operation ops.SiteOperation,
+	operator ops.Operator,
+	logger log.FieldLogger,
+) (*workersExecutor, error) {
+	if operation.Restore == nil {
+		return nil, trace.BadParameter("operation %v does not restore the cluster", operation.ID)
+	}
+	return &workersExecutor{
+		FieldLogger: logger,
+		operator:    operator,
+		dir:         operation.Restore.Backup,
+	}, nil
+}
+
+// Execute logs the commands to join the restored cluster for the worker
+// nodes of the backed up cluster that have not joined it yet.
+//
+// The surviving workers are still configured for the backed up cluster
+// so they are re-joined from the workers themselves
+func (r *workersExecutor) Execute(ctx context.Context) error {
+	b, err := backup.Open(r.dir)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := r.operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	workers := b.Workers(cluster.ClusterState.Servers)
+	if len(workers) == 0 {
+		r.Info("All worker nodes have joined the cluster.")
+		return nil
+	}
+	masters := cluster.ClusterState.Servers.Masters()
+	if len(masters) == 0 {
+		return trace.NotFound("cluster %v has no master nodes", cluster.Domain)
+	}
+	token, err := backup.JoinToken(ctx, r.operator, cluster.Key(), workers)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	for _, worker := range workers {
+		r.Infof("Re-join worker node %v by executing on it: %v", worker,
+			backup.JoinCommand(worker, masters[0].AdvertiseIP, token))
+	}
+	return nil
+}
+
+// Rollback is a no-op for this phase
+func (*workersExecutor) Rollback(context.Context) error {
+	return nil
+}
+
+// PreCheck is a no-op
+func (*workersExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*workersExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+type workersExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	operator ops.Operator
+	dir      string
+}
+
+// NewHealth returns a new executor that waits for the cluster nodes
+// to become ready after the restore
+func NewHealth(
+	params libfsm.ExecutorParams,
+	client *kubernetes.Clientset,
+	logger log.FieldLogger,
+) (*healthExecutor, error) {
+	if client == nil {
+		return nil, trace.BadParameter("phase %q requires a Kubernetes client", params.Phase.ID)
+	}
+	return &healthExecutor{
+		FieldLogger: logger,
+		client:      client,
+	}, nil
+}
+
+// Execute waits for all Kubernetes nodes to become ready
+func (r *healthExecutor) Execute(ctx context.Context) error {
+	r.Info("Wait for cluster nodes to become ready.")
+	ctx, cancel := context.WithTimeout(ctx, defaults.NodeStatusTimeout)
+	defer cancel()
+	err := utils.RetryWithInterval(ctx, backoff.NewConstantBackOff(defaults.RetryInterval), func() error {
+		nodes, err := r.client.CoreV1().Nodes().List(metav1.ListOptions{})
+		if err != nil {
+			return trace.Wrap(rigging.ConvertError(err))
+		}
+		for _, node := range nodes.Items {
+			if !isNodeReady(node) {
+				return trace.Retry(nil, "node %v is not ready", node.Name)
+			}
+		}
+		return nil
+	})
+	return trace.Wrap(err)
+}
+
+// Rollback is a no-op for this phase
+func (*healthExecutor) Rollback(context.Context) error {
+	return nil
+}
+
+// PreCheck is a no-op
+func (*healthExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*healthExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+type healthExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	client *kubernetes.Clientset
+}
+
+func isNodeReady(node v1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
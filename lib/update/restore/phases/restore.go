@@ -0,0 +1,350 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gravitational/gravity/lib/backup"
+	"github.com/gravitational/gravity/lib/etcd"
+	libfsm "github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/keyval"
+
+	"github.com/gravitational/rigging"
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// Validate defines the phase to verify the backup
+	Validate = "validate"
+	// Kubernetes defines the phase to restore the Kubernetes resources
+	Kubernetes = "kubernetes"
+	// Tokens defines the phase to regenerate the restored service account tokens
+	Tokens = "tokens"
+	// State defines the phase to restore the cluster state
+	State = "state"
+	// Controller defines the phase to restart the cluster controller
+	Controller = "controller"
+	// Workers defines the phase to re-join the worker nodes of the backed up cluster
+	Workers = "workers"
+	// Health defines the phase to wait for the cluster nodes to become ready
+	Health = "health"
+	// Apps defines the phase to restore the application data
+	Apps = "apps"
+)
+
+// NewValidate returns a new executor to verify the backup
+// before anything is restored from it
+func NewValidate(
+	params libfsm.ExecutorParams,
+	operation ops.SiteOperation,
+	logger log.FieldLogger,
+) (*validateExecutor, error) {
+	if operation.Restore == nil {
+		return nil, trace.BadParameter("operation %v does not restore the cluster", operation.ID)
+	}
+	return &validateExecutor{
+		FieldLogger: logger,
+		clusterName: operation.SiteDomain,
+		dir:         operation.Restore.Backup,
+	}, nil
+}
+
+// Execute verifies the integrity of the backup and that it has been
+// taken on the cluster with the same name
+func (r *validateExecutor) Execute(context.Context) error {
+	r.Infof("Verify backup %v.", r.dir)
+	backup, err := backup.Open(r.dir)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if backup.ClusterName != r.clusterName {
+		return trace.BadParameter("backup %v has been taken on cluster %v, "+
+			"the cluster to restore must be installed with the same name",
+			r.dir, backup.ClusterName)
+	}
+	r.Infof("Backup of %v with %v nodes and %v registry images.",
+		backup.ClusterName, len(backup.Servers), len(backup.Images))
+	return nil
+}
+
+// Rollback is a no-op for this phase
+func (*validateExecutor) Rollback(context.Context) error {
+	return nil
+}
+
+// PreCheck is a no-op
+func (*validateExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*validateExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+type validateExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	clusterName string
+	dir         string
+}
+
+// NewKubernetes returns a new executor to restore the Kubernetes
+// resources from the etcd snapshot of the backup
+func NewKubernetes(
+	params libfsm.ExecutorParams,
+	operation ops.SiteOperation,
+	logger log.FieldLogger,
+) (*kubernetesExecutor, error) {
+	if operation.Restore == nil {
+		return nil, trace.BadParameter("operation %v does not restore the cluster", operation.ID)
+	}
+	return &kubernetesExecutor{
+		FieldLogger: logger,
+		dir:         operation.Restore.Backup,
+	}, nil
+}
+
+// Execute writes the Kubernetes resources from the backup into the local etcd
+func (r *kubernetesExecutor) Execute(ctx context.Context) error {
+	backup, err := backup.Open(r.dir)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	config, err := keyval.LocalEtcdConfig(0)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	client, err := etcd.NewClient(*config)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer client.Close()
+	r.Info("Restore Kubernetes resources.")
+	count, err := backup.RestoreKubernetes(ctx, client)
+	if err != nil {
+		return trace.Wrap(err, "failed after restoring %v resources", count)
+	}
+	r.Infof("Restored %v Kubernetes resources.", count)
+	return nil
+}
+
+// Rollback is a no-op as the restored resources have replaced
+// the resources of the new cluster
+func (*kubernetesExecutor) Rollback(context.Context) error {
+	return nil
+}
+
+// PreCheck is a no-op
+func (*kubernetesExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*kubernetesExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+type kubernetesExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	dir string
+}
+
+// NewTokens returns a new executor to regenerate the restored
+// service account tokens
+func NewTokens(
+	params libfsm.ExecutorParams,
+	operation ops.SiteOperation,
+	client *kubernetes.Clientset,
+	logger log.FieldLogger,
+) (*tokensExecutor, error) {
+	if operation.Restore == nil {
+		return nil, trace.BadParameter("operation %v does not restore the cluster", operation.ID)
+	}
+	if client == nil {
+		return nil, trace.BadParameter("phase %q requires a Kubernetes client", params.Phase.ID)
+	}
+	return &tokensExecutor{
+		FieldLogger: logger,
+		client:      client,
+		dir:         operation.Restore.Backup,
+	}, nil
+}
+
+// Execute removes the restored service account tokens as they have been
+// signed with the key of the backed up cluster. The tokens controller
+// generates new tokens for the service accounts.
+// The pods that have mounted the removed tokens are recreated
+func (r *tokensExecutor) Execute(ctx context.Context) error {
+	backup, err := backup.Open(r.dir)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	names, err := backup.Secrets()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	removed := make(map[string]struct{})
+	for _, name := range names {
+		parts := strings.SplitN(name, "/", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		namespace, name := parts[0], parts[1]
+		secrets := r.client.CoreV1().Secrets(namespace)
+		secret, err := secrets.Get(name, metav1.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return trace.Wrap(rigging.ConvertError(err))
+		}
+		if secret.Type != v1.SecretTypeServiceAccountToken {
+			continue
+		}
+		r.Debugf("Remove service account token %v/%v.", namespace, name)
+		err = secrets.Delete(name, nil)
+		if err != nil && !errors.IsNotFound(err) {
+			return trace.Wrap(rigging.ConvertError(err))
+		}
+		removed[namespace+"/"+name] = struct{}{}
+	}
+	r.Infof("Removed %v service account tokens.", len(removed))
+	return trace.Wrap(r.recreatePods(removed))
+}
+
+func (r *tokensExecutor) recreatePods(tokens map[string]struct{}) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	pods, err := r.client.CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return trace.Wrap(rigging.ConvertError(err))
+	}
+	for _, pod := range pods.Items {
+		if !mountsSecret(pod, tokens) {
+			continue
+		}
+		r.Infof("Recreate pod %v/%v with the removed service account token.", pod.Namespace, pod.Name)
+		err := r.client.CoreV1().Pods(pod.Namespace).Delete(pod.Name, nil)
+		if err != nil && !errors.IsNotFound(err) {
+			return trace.Wrap(rigging.ConvertError(err))
+		}
+	}
+	return nil
+}
+
+func mountsSecret(pod v1.Pod, secrets map[string]struct{}) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Secret == nil {
+			continue
+		}
+		if _, ok := secrets[pod.Namespace+"/"+volume.Secret.SecretName]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Rollback is a no-op as the removed tokens are invalid in the new cluster
+func (*tokensExecutor) Rollback(context.Context) error {
+	return nil
+}
+
+// PreCheck is a no-op
+func (*tokensExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*tokensExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+type tokensExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	client kubernetes.Interface
+	dir    string
+}
+
+// NewState returns a new executor to restore the cluster state
+// from the state archive of the backup
+func NewState(
+	params libfsm.ExecutorParams,
+	operation ops.SiteOperation,
+	backend storage.Backend,
+	logger log.FieldLogger,
+) (*stateExecutor, error) {
+	if operation.Restore == nil {
+		return nil, trace.BadParameter("operation %v does not restore the cluster", operation.ID)
+	}
+	return &stateExecutor{
+		FieldLogger: logger,
+		backend:     backend,
+		dir:         operation.Restore.Backup,
+	}, nil
+}
+
+// Execute imports the users, the authentication settings and the
+// cluster resources from the backup into the cluster backend
+func (r *stateExecutor) Execute(ctx context.Context) error {
+	backup, err := backup.Open(r.dir)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	r.Info("Restore cluster state.")
+	count, err := backup.RestoreState(ctx, r.backend)
+	if err != nil {
+		return trace.Wrap(err, "failed after restoring %v records", count)
+	}
+	r.Infof("Restored %v cluster state records.", count)
+	return nil
+}
+
+// Rollback is a no-op as the restored records have replaced
+// the records of the new cluster
+func (*stateExecutor) Rollback(context.Context) error {
+	return nil
+}
+
+// PreCheck is a no-op
+func (*stateExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*stateExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+type stateExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	backend storage.Backend
+	dir     string
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"fmt"
+
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/update"
+	"github.com/gravitational/gravity/lib/update/restore/phases"
+
+	"github.com/gravitational/trace"
+)
+
+// NewOperationPlan creates a new operation plan for the specified operation
+func NewOperationPlan(
+	operator ops.Operator,
+	operation ops.SiteOperation,
+	servers []storage.Server,
+	leader storage.Server,
+) (plan *storage.OperationPlan, err error) {
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	plan, err = newOperationPlan(cluster.DNSConfig, operation, servers, leader)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	err = operator.CreateOperationPlan(operation.Key(), *plan)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotImplemented(
+				"cluster operator does not implement the API required to restore the cluster. " +
+					"Please make sure you're running the command on a compatible cluster.")
+		}
+		return nil, trace.Wrap(err)
+	}
+	return plan, nil
+}
+
+// newOperationPlan returns a new plan for the specified operation.
+//
+// All phases are executed on the master node that stores the backup.
+// The Kubernetes resources are restored before the cluster state so the
+// cluster controller restarted afterwards observes both. The application
+// data is restored last, once the applications are running again
+func newOperationPlan(
+	dnsConfig storage.DNSConfig,
+	operation ops.SiteOperation,
+	servers []storage.Server,
+	leader storage.Server,
+) (*storage.OperationPlan, error) {
+	if operation.Restore == nil {
+		return nil, trace.BadParameter("operation %v does not restore the cluster", operation.ID)
+	}
+	if !leader.IsMaster() {
+		return nil, trace.BadParameter("cluster can only be restored from a master node, "+
+			"node %v is not a master", leader.Hostname)
+	}
+	data := func() *storage.OperationPhaseData {
+		return &storage.OperationPhaseData{ExecServer: &leader}
+	}
+	plan := update.Sequential(
+		update.Phase{
+			ID:          phases.Validate,
+			Executor:    phases.Validate,
+			Description: fmt.Sprintf("Verify backup %v", operation.Restore.Backup),
+			Data:        data(),
+		},
+		update.Phase{
+			ID:          phases.Kubernetes,
+			Executor:    phases.Kubernetes,
+			Description: "Restore Kubernetes resources",
+			Data:        data(),
+		},
+		update.Phase{
+			ID:          phases.Tokens,
+			Executor:    phases.Tokens,
+			Description: "Regenerate service account tokens",
+			Data:        data(),
+		},
+		update.Phase{
+			ID:          phases.State,
+			Executor:    phases.State,
+			Description: "Restore cluster state",
+			Data:        data(),
+		},
+		update.Phase{
+			ID:          phases.Controller,
+			Executor:    phases.Controller,
+			Description: "Restart cluster controller",
+			Data:        data(),
+		},
+		update.Phase{
+			ID:          phases.Workers,
+			Executor:    phases.Workers,
+			Description: "Re-join worker nodes of the backed up cluster",
+			Data:        data(),
+		},
+		update.Phase{
+			ID:          phases.Health,
+			Executor:    phases.Health,
+			Description: "Wait for cluster nodes to become ready",
+			Data:        data(),
+		},
+		update.Phase{
+			ID:          phases.Apps,
+			Executor:    phases.Apps,
+			Description: "Restore application data",
+			Data:        data(),
+		},
+	)
+
+	result := &storage.OperationPlan{
+		OperationID:   operation.ID,
+		OperationType: operation.Type,
+		AccountID:     operation.AccountID,
+		ClusterName:   operation.SiteDomain,
+		Phases:        plan.AsPhases(),
+		Servers:       servers,
+		DNSConfig:     dnsConfig,
+	}
+	update.ResolvePlan(result)
+
+	return result, nil
+}
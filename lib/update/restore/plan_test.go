@@ -0,0 +1,70 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"testing"
+
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/update/internal/testutils"
+
+	"github.com/gravitational/trace"
+	. "gopkg.in/check.v1"
+)
+
+func TestRestore(t *testing.T) { TestingT(t) }
+
+type S struct {
+	servers []storage.Server
+}
+
+var _ = Suite(&S{})
+
+func (s *S) SetUpTest(c *C) {
+	s.servers = []storage.Server{
+		{Hostname: "node-1", AdvertiseIP: "10.0.0.1", Role: "node", ClusterRole: string(schema.ServiceRoleMaster)},
+		{Hostname: "node-2", AdvertiseIP: "10.0.0.2", Role: "node", ClusterRole: string(schema.ServiceRoleNode)},
+	}
+}
+
+func (s *S) TestRestoresOnLeader(c *C) {
+	plan, err := newOperationPlan(storage.DefaultDNSConfig, operation, s.servers, s.servers[0])
+	c.Assert(err, IsNil)
+	c.Assert(testutils.PhaseIDs(plan.Phases), DeepEquals, []string{
+		"/validate", "/kubernetes", "/tokens", "/state", "/controller", "/workers", "/health", "/apps",
+	})
+	for i, phase := range plan.Phases {
+		c.Assert(phase.Data.ExecServer.Hostname, Equals, "node-1")
+		if i > 0 {
+			c.Assert(phase.Requires, DeepEquals, []string{plan.Phases[i-1].ID})
+		}
+	}
+}
+
+func (s *S) TestRequiresMaster(c *C) {
+	_, err := newOperationPlan(storage.DefaultDNSConfig, operation, s.servers, s.servers[1])
+	c.Assert(trace.IsBadParameter(err), Equals, true)
+}
+
+var operation = testutils.NewOperation(ops.SiteOperation{
+	Type: ops.OperationRestore,
+	Restore: &storage.RestoreOperationState{
+		Backup: "/var/lib/gravity/backups/backup-20190601T020000Z",
+	},
+})
@@ -0,0 +1,129 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+
+	libfsm "github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/opsservice"
+	"github.com/gravitational/gravity/lib/pack"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// CertAuthority defines the phase to generate a new cluster certificate authority
+	CertAuthority = "ca"
+	// PromoteCertAuthority defines the phase to make the new cluster certificate
+	// authority active
+	PromoteCertAuthority = "promote-ca"
+)
+
+// NewCertAuthority returns a new executor to generate a new cluster
+// certificate authority.
+//
+// The new certificate authority is only trusted by the nodes
+// until it is promoted with the PromoteCertAuthority phase
+func NewCertAuthority(
+	params libfsm.ExecutorParams,
+	operation ops.SiteOperation,
+	packages pack.PackageService,
+	logger log.FieldLogger,
+) (*certAuthorityExecutor, error) {
+	return &certAuthorityExecutor{
+		FieldLogger: logger,
+		clusterName: operation.SiteDomain,
+		packages:    packages,
+	}, nil
+}
+
+// Execute generates a new certificate authority and adds it
+// to the certificate authority package as pending
+func (r *certAuthorityExecutor) Execute(context.Context) error {
+	r.Info("Generate new cluster certificate authority.")
+	return trace.Wrap(opsservice.StageCertAuthority(r.packages, r.clusterName))
+}
+
+// Rollback removes the new certificate authority
+func (r *certAuthorityExecutor) Rollback(context.Context) error {
+	r.Info("Remove new cluster certificate authority.")
+	return trace.Wrap(opsservice.UnstageCertAuthority(r.packages, r.clusterName))
+}
+
+// PreCheck is a no-op
+func (*certAuthorityExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*certAuthorityExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+type certAuthorityExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	clusterName string
+	packages    pack.PackageService
+}
+
+// NewPromoteCertAuthority returns a new executor to make the new cluster
+// certificate authority issue the runtime certificates
+func NewPromoteCertAuthority(
+	params libfsm.ExecutorParams,
+	operation ops.SiteOperation,
+	packages pack.PackageService,
+	logger log.FieldLogger,
+) (*promoteExecutor, error) {
+	return &promoteExecutor{
+		FieldLogger: logger,
+		clusterName: operation.SiteDomain,
+		packages:    packages,
+	}, nil
+}
+
+// Execute makes the new certificate authority active
+func (r *promoteExecutor) Execute(context.Context) error {
+	r.Info("Promote new cluster certificate authority.")
+	return trace.Wrap(opsservice.PromoteCertAuthority(r.packages, r.clusterName))
+}
+
+// Rollback restores the replaced certificate authority
+func (r *promoteExecutor) Rollback(context.Context) error {
+	r.Info("Restore previous cluster certificate authority.")
+	return trace.Wrap(opsservice.DemoteCertAuthority(r.packages, r.clusterName))
+}
+
+// PreCheck is a no-op
+func (*promoteExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*promoteExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+type promoteExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	clusterName string
+	packages    pack.PackageService
+}
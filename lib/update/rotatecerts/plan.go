@@ -0,0 +1,177 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rotatecerts
+
+import (
+	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/update"
+	"github.com/gravitational/gravity/lib/update/internal/rollingupdate"
+	"github.com/gravitational/gravity/lib/update/rotatecerts/phases"
+
+	"github.com/gravitational/trace"
+)
+
+// NewOperationPlan creates a new operation plan for the specified operation
+func NewOperationPlan(
+	operator ops.Operator,
+	apps app.Applications,
+	operation ops.SiteOperation,
+	servers []storage.Server,
+) (plan *storage.OperationPlan, err error) {
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	app, err := apps.GetApp(cluster.App.Package)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to query installed application")
+	}
+	plan, err = newOperationPlan(*app, cluster.DNSConfig, operator, operation, servers)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	err = operator.CreateOperationPlan(operation.Key(), *plan)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotImplemented(
+				"cluster operator does not implement the API required to rotate certificates. " +
+					"Please make sure you're running the command on a compatible cluster.")
+		}
+		return nil, trace.Wrap(err)
+	}
+	return plan, nil
+}
+
+// newOperationPlan returns a new plan for the specified operation
+// and the given set of servers.
+//
+// Rotating the runtime certificates amounts to generating new secrets and
+// configuration packages for every node and restarting the runtime container
+// on one node at a time, masters first.
+//
+// The certificate authority is rotated in two passes so the nodes trust each
+// other at all times: the first pass distributes the new certificate authority
+// as trusted while the certificates are still issued by the current one and
+// the second pass rotates the certificates after the new certificate authority
+// has been promoted
+func newOperationPlan(
+	app app.Application,
+	dnsConfig storage.DNSConfig,
+	operator packageRotator,
+	operation ops.SiteOperation,
+	servers []storage.Server,
+) (*storage.OperationPlan, error) {
+	if operation.RotateCertificates == nil {
+		return nil, trace.BadParameter("operation %v does not rotate certificates", operation.ID)
+	}
+	updates, err := runtimeUpdates(app, operator, operation, servers)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	masters, _ := update.SplitServers(updates)
+	if len(masters) == 0 {
+		return nil, trace.NotFound("no master servers found in cluster state")
+	}
+	builder := rollingupdate.Builder{App: app.Package}
+
+	var plan update.Phases
+	if !operation.RotateCertificates.RotateCA {
+		plan = update.Sequential(builder.Rotation(updates,
+			"Rotate certificates", "Rotate certificates on node %q")...)
+	} else {
+		nextUpdates, err := rollingupdate.NextPassUpdates(updates)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		ca := update.Phase{
+			ID:          phases.CertAuthority,
+			Executor:    phases.CertAuthority,
+			Description: "Generate new cluster certificate authority",
+		}
+		trust := update.Phase{
+			ID:          trustPhase,
+			Description: "Distribute new certificate authority",
+		}
+		trust.AddSequential(builder.Rotation(updates,
+			"Trust new certificate authority",
+			"Trust new certificate authority on node %q")...)
+		promote := update.Phase{
+			ID:          phases.PromoteCertAuthority,
+			Executor:    phases.PromoteCertAuthority,
+			Description: "Promote new cluster certificate authority",
+		}
+		rotate := update.Phase{
+			ID:          rotatePhase,
+			Description: "Rotate certificates",
+		}
+		rotate.AddSequential(builder.Rotation(nextUpdates,
+			"Rotate certificates",
+			"Rotate certificates on node %q")...)
+		plan = update.Sequential(ca, trust, promote, rotate)
+	}
+
+	result := &storage.OperationPlan{
+		OperationID:   operation.ID,
+		OperationType: operation.Type,
+		AccountID:     operation.AccountID,
+		ClusterName:   operation.SiteDomain,
+		Phases:        plan.AsPhases(),
+		Servers:       servers,
+		DNSConfig:     dnsConfig,
+	}
+	update.ResolvePlan(result)
+
+	return result, nil
+}
+
+// runtimeUpdates returns the runtime updates with new configuration
+// and secrets packages for the specified servers
+func runtimeUpdates(app app.Application, operator packageRotator, operation ops.SiteOperation, servers []storage.Server) ([]storage.UpdateServer, error) {
+	updates, err := rollingupdate.RuntimeConfigUpdates(app.Manifest, operator, operation.Key(), servers)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for i, server := range updates {
+		secretsUpdate, err := operator.RotateSecrets(ops.RotateSecretsRequest{
+			Key:            operation.ClusterKey(),
+			Server:         server.Server,
+			RuntimePackage: server.Runtime.Update.Package,
+			DryRun:         true,
+		})
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		updates[i].Runtime.SecretsPackage = &secretsUpdate.Locator
+	}
+	return updates, nil
+}
+
+type packageRotator interface {
+	rollingupdate.ConfigPackageRotator
+	RotateSecrets(ops.RotateSecretsRequest) (*ops.RotatePackageResponse, error)
+}
+
+const (
+	// trustPhase is the ID of the phase that distributes
+	// the new certificate authority to the nodes
+	trustPhase = "trust"
+	// rotatePhase is the ID of the phase that rotates the certificates
+	// after the new certificate authority has been promoted
+	rotatePhase = "rotate"
+)
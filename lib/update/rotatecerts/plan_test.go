@@ -0,0 +1,139 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rotatecerts
+
+import (
+	"testing"
+
+	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+	libphase "github.com/gravitational/gravity/lib/update/internal/rollingupdate/phases"
+	"github.com/gravitational/gravity/lib/update/internal/testutils"
+
+	. "gopkg.in/check.v1"
+)
+
+func TestRotateCerts(t *testing.T) { TestingT(t) }
+
+type S struct {
+	app     app.Application
+	servers []storage.Server
+}
+
+var _ = Suite(&S{})
+
+func (s *S) SetUpTest(c *C) {
+	s.servers = []storage.Server{
+		{Hostname: "node-1", AdvertiseIP: "10.0.0.1", Role: "node", ClusterRole: string(schema.ServiceRoleMaster)},
+		{Hostname: "node-2", AdvertiseIP: "10.0.0.2", Role: "node", ClusterRole: string(schema.ServiceRoleNode)},
+	}
+	s.app = app.Application{
+		Package: loc.MustParseLocator("gravitational.io/app:0.0.1"),
+		Manifest: schema.Manifest{
+			NodeProfiles: schema.NodeProfiles{{Name: "node"}},
+			SystemOptions: &schema.SystemOptions{
+				Dependencies: schema.SystemDependencies{
+					Runtime: &schema.Dependency{Locator: runtimeLoc},
+				},
+			},
+		},
+	}
+}
+
+func (s *S) TestRotatesLeafCertificates(c *C) {
+	plan, err := newOperationPlan(s.app, storage.DefaultDNSConfig, testOperator, newOperation(false), s.servers)
+	c.Assert(err, IsNil)
+
+	c.Assert(testutils.PhaseIDs(plan.Phases), DeepEquals, []string{"/packages", "/masters", "/nodes"})
+	c.Assert(plan.Phases[1].Requires, DeepEquals, []string{"/packages"})
+	c.Assert(plan.Phases[2].Requires, DeepEquals, []string{"/masters"})
+
+	packages := plan.Phases[0]
+	c.Assert(packages.Executor, Equals, libphase.Packages)
+	c.Assert(packages.Data.Update.Servers, HasLen, 2)
+	update := packages.Data.Update.Servers[0]
+	c.Assert(update.Runtime.Update.ConfigPackage, Equals, testOperator.runtimeConfigPackage)
+	c.Assert(*update.Runtime.SecretsPackage, Equals, testOperator.secretsPackage)
+
+	restart := plan.Phases[1].Phases[0].Phases[1]
+	c.Assert(restart.ID, Equals, "/masters/node-1/restart")
+	c.Assert(restart.Executor, Equals, libphase.RestartContainer)
+	c.Assert(restart.Data.Update.Servers, DeepEquals, []storage.UpdateServer{update})
+	c.Assert(changesetID("1", restart.ID), Equals, "1")
+}
+
+func (s *S) TestRotatesCertAuthorityInTwoPasses(c *C) {
+	plan, err := newOperationPlan(s.app, storage.DefaultDNSConfig, testOperator, newOperation(true), s.servers)
+	c.Assert(err, IsNil)
+
+	c.Assert(testutils.PhaseIDs(plan.Phases), DeepEquals, []string{"/ca", "/trust", "/promote-ca", "/rotate"})
+	c.Assert(plan.Phases[1].Requires, DeepEquals, []string{"/ca"})
+	c.Assert(plan.Phases[2].Requires, DeepEquals, []string{"/trust"})
+	c.Assert(plan.Phases[3].Requires, DeepEquals, []string{"/promote-ca"})
+
+	trust := plan.Phases[1]
+	c.Assert(testutils.PhaseIDs(trust.Phases), DeepEquals, []string{"/trust/packages", "/trust/masters", "/trust/nodes"})
+	c.Assert(trust.Phases[1].Requires, DeepEquals, []string{"/trust/packages"})
+	rotate := plan.Phases[3]
+	c.Assert(testutils.PhaseIDs(rotate.Phases), DeepEquals, []string{"/rotate/packages", "/rotate/masters", "/rotate/nodes"})
+
+	// Each pass restarts the runtime container with its own packages
+	first := trust.Phases[0].Data.Update.Servers[0].Runtime
+	second := rotate.Phases[0].Data.Update.Servers[0].Runtime
+	c.Assert(second.Update.ConfigPackage.String(), Equals, "gravitational.io/planet-config:0.0.1+1")
+	c.Assert(second.SecretsPackage.String(), Equals, "gravitational.io/planet-secrets:0.0.1+1")
+	c.Assert(first.Update.ConfigPackage, Equals, testOperator.runtimeConfigPackage)
+
+	trustRestart := trust.Phases[1].Phases[0].Phases[1]
+	c.Assert(trustRestart.ID, Equals, "/trust/masters/node-1/restart")
+	c.Assert(changesetID("1", trustRestart.ID), Equals, "1-trust")
+	rotateRestart := rotate.Phases[1].Phases[0].Phases[1]
+	c.Assert(rotateRestart.ID, Equals, "/rotate/masters/node-1/restart")
+	c.Assert(changesetID("1", rotateRestart.ID), Equals, "1-rotate")
+}
+
+func newOperation(rotateCA bool) ops.SiteOperation {
+	return testutils.NewOperation(ops.SiteOperation{
+		Type: ops.OperationRotateCertificates,
+		RotateCertificates: &storage.RotateCertificatesOperationState{
+			RotateCA: rotateCA,
+		},
+	})
+}
+
+func (r testRotator) RotatePlanetConfig(ops.RotatePlanetConfigRequest) (*ops.RotatePackageResponse, error) {
+	return &ops.RotatePackageResponse{Locator: r.runtimeConfigPackage}, nil
+}
+
+func (r testRotator) RotateSecrets(ops.RotateSecretsRequest) (*ops.RotatePackageResponse, error) {
+	return &ops.RotatePackageResponse{Locator: r.secretsPackage}, nil
+}
+
+var runtimeLoc = loc.Locator{Repository: "foo", Name: "runtime", Version: "0.0.1"}
+
+var testOperator = testRotator{
+	runtimeConfigPackage: loc.Locator{Repository: "gravitational.io", Name: "planet-config", Version: "0.0.1"},
+	secretsPackage:       loc.Locator{Repository: "gravitational.io", Name: "planet-secrets", Version: "0.0.1"},
+}
+
+type testRotator struct {
+	runtimeConfigPackage loc.Locator
+	secretsPackage       loc.Locator
+}
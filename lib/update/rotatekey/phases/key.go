@@ -0,0 +1,134 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+
+	libfsm "github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/opsservice"
+	"github.com/gravitational/gravity/lib/pack"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// StageKey defines the phase to generate a new encryption key
+	StageKey = "stage-key"
+	// PromoteKey defines the phase to make the new encryption key
+	// encrypt the secrets
+	PromoteKey = "promote-key"
+	// Reencrypt defines the phase to re-encrypt the existing secrets
+	Reencrypt = "reencrypt"
+)
+
+// NewStageKey returns a new executor to generate a new encryption key.
+//
+// The new key only decrypts the secrets until it is promoted
+// with the PromoteKey phase
+func NewStageKey(
+	params libfsm.ExecutorParams,
+	operation ops.SiteOperation,
+	packages pack.PackageService,
+	logger log.FieldLogger,
+) (*stageKeyExecutor, error) {
+	return &stageKeyExecutor{
+		FieldLogger: logger,
+		clusterName: operation.SiteDomain,
+		keyName:     opsservice.EncryptionKeyName(operation.ID),
+		packages:    packages,
+	}, nil
+}
+
+// Execute generates a new encryption key and adds it
+// to the encryption configuration package
+func (r *stageKeyExecutor) Execute(context.Context) error {
+	r.Infof("Generate new encryption key %v.", r.keyName)
+	return trace.Wrap(opsservice.StageEncryptionKey(r.packages, r.clusterName, r.keyName))
+}
+
+// Rollback removes the new encryption key
+func (r *stageKeyExecutor) Rollback(context.Context) error {
+	r.Infof("Remove new encryption key %v.", r.keyName)
+	return trace.Wrap(opsservice.UnstageEncryptionKey(r.packages, r.clusterName, r.keyName))
+}
+
+// PreCheck is a no-op
+func (*stageKeyExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*stageKeyExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+type stageKeyExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	clusterName string
+	keyName     string
+	packages    pack.PackageService
+}
+
+// NewPromoteKey returns a new executor to make the new
+// encryption key encrypt the secrets
+func NewPromoteKey(
+	params libfsm.ExecutorParams,
+	operation ops.SiteOperation,
+	packages pack.PackageService,
+	logger log.FieldLogger,
+) (*promoteKeyExecutor, error) {
+	return &promoteKeyExecutor{
+		FieldLogger: logger,
+		clusterName: operation.SiteDomain,
+		keyName:     opsservice.EncryptionKeyName(operation.ID),
+		packages:    packages,
+	}, nil
+}
+
+// Execute makes the new encryption key active
+func (r *promoteKeyExecutor) Execute(context.Context) error {
+	r.Infof("Promote new encryption key %v.", r.keyName)
+	return trace.Wrap(opsservice.PromoteEncryptionKey(r.packages, r.clusterName, r.keyName))
+}
+
+// Rollback restores the replaced encryption key
+func (r *promoteKeyExecutor) Rollback(context.Context) error {
+	r.Info("Restore previous encryption key.")
+	return trace.Wrap(opsservice.DemoteEncryptionKey(r.packages, r.clusterName, r.keyName))
+}
+
+// PreCheck is a no-op
+func (*promoteKeyExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*promoteKeyExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+type promoteKeyExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	clusterName string
+	keyName     string
+	packages    pack.PackageService
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package phases
+
+import (
+	"context"
+
+	libfsm "github.com/gravitational/gravity/lib/fsm"
+	libkubernetes "github.com/gravitational/gravity/lib/kubernetes"
+
+	"github.com/gravitational/rigging"
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// NewReencrypt returns a new executor to re-encrypt the existing secrets
+// with the active encryption key
+func NewReencrypt(
+	params libfsm.ExecutorParams,
+	client *kubernetes.Clientset,
+	logger log.FieldLogger,
+) (*reencryptExecutor, error) {
+	if client == nil {
+		return nil, trace.BadParameter("phase %q requires a Kubernetes client", params.Phase.ID)
+	}
+	return &reencryptExecutor{
+		FieldLogger: logger,
+		client:      client,
+	}, nil
+}
+
+// Execute rewrites every secret in the cluster.
+// The apiservers encrypt the secrets with the active key on write
+func (r *reencryptExecutor) Execute(ctx context.Context) error {
+	r.Info("Re-encrypt secrets.")
+	var count int
+	opts := metav1.ListOptions{Limit: listLimit}
+	for {
+		secrets, err := r.client.CoreV1().Secrets(metav1.NamespaceAll).List(opts)
+		if err != nil {
+			return trace.Wrap(rigging.ConvertError(err))
+		}
+		for _, secret := range secrets.Items {
+			if err := r.rewrite(ctx, secret); err != nil {
+				return trace.Wrap(err)
+			}
+			count++
+		}
+		if secrets.Continue == "" {
+			break
+		}
+		opts.Continue = secrets.Continue
+	}
+	r.Infof("Re-encrypted %v secrets.", count)
+	return nil
+}
+
+// Rollback is a no-op as the previous key is kept
+// to decrypt the re-encrypted secrets
+func (*reencryptExecutor) Rollback(context.Context) error {
+	return nil
+}
+
+// PreCheck is a no-op
+func (*reencryptExecutor) PreCheck(context.Context) error {
+	return nil
+}
+
+// PostCheck is a no-op
+func (*reencryptExecutor) PostCheck(context.Context) error {
+	return nil
+}
+
+func (r *reencryptExecutor) rewrite(ctx context.Context, secret v1.Secret) error {
+	secrets := r.client.CoreV1().Secrets(secret.Namespace)
+	err := libkubernetes.Retry(ctx, func() error {
+		_, err := secrets.Update(&secret)
+		if !errors.IsConflict(err) {
+			return err
+		}
+		// The secret has been updated concurrently, re-read it and retry
+		latest, getErr := secrets.Get(secret.Name, metav1.GetOptions{})
+		if getErr != nil {
+			return getErr
+		}
+		secret = *latest
+		return err
+	})
+	if errors.IsNotFound(trace.Unwrap(err)) {
+		r.Debugf("Secret %v/%v has been deleted.", secret.Namespace, secret.Name)
+		return nil
+	}
+	return trace.Wrap(rigging.ConvertError(trace.Unwrap(err)))
+}
+
+type reencryptExecutor struct {
+	// FieldLogger specifies the logger for the phase
+	log.FieldLogger
+	client kubernetes.Interface
+}
+
+// listLimit is the maximum number of secrets to query at once
+const listLimit = 500
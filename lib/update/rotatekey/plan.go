@@ -0,0 +1,160 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rotatekey
+
+import (
+	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/clusterconfig"
+	"github.com/gravitational/gravity/lib/update"
+	"github.com/gravitational/gravity/lib/update/internal/rollingupdate"
+	"github.com/gravitational/gravity/lib/update/rotatekey/phases"
+
+	"github.com/gravitational/trace"
+)
+
+// NewOperationPlan creates a new operation plan for the specified operation
+func NewOperationPlan(
+	operator ops.Operator,
+	apps app.Applications,
+	operation ops.SiteOperation,
+	servers []storage.Server,
+) (plan *storage.OperationPlan, err error) {
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	app, err := apps.GetApp(cluster.App.Package)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to query installed application")
+	}
+	config, err := operator.GetClusterConfiguration(operation.ClusterKey())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	encryption := config.GetEncryption()
+	if encryption == nil {
+		return nil, trace.BadParameter("encryption of secrets at rest is not enabled")
+	}
+	plan, err = newOperationPlan(*app, cluster.DNSConfig, operator, operation, *encryption, servers)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	err = operator.CreateOperationPlan(operation.Key(), *plan)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotImplemented(
+				"cluster operator does not implement the API required to rotate the secrets key. " +
+					"Please make sure you're running the command on a compatible cluster.")
+		}
+		return nil, trace.Wrap(err)
+	}
+	return plan, nil
+}
+
+// newOperationPlan returns a new plan for the specified operation
+// and the given set of servers.
+//
+// The key is rotated in two passes over the masters so the apiservers
+// are able to read the secrets at all times: the first pass distributes
+// the new key that only decrypts the secrets and the second pass makes
+// the new key encrypt the secrets after it has been promoted.
+// The existing secrets are then re-encrypted with the new key.
+//
+// With the KMS provider the key encryption key is managed by the KMS plugin
+// and the rotation amounts to re-encrypting the secrets with new data
+// encryption keys
+func newOperationPlan(
+	app app.Application,
+	dnsConfig storage.DNSConfig,
+	operator rollingupdate.ConfigPackageRotator,
+	operation ops.SiteOperation,
+	encryption clusterconfig.Encryption,
+	servers []storage.Server,
+) (*storage.OperationPlan, error) {
+	reencrypt := update.Phase{
+		ID:          phases.Reencrypt,
+		Executor:    phases.Reencrypt,
+		Description: "Re-encrypt secrets",
+	}
+	var plan update.Phases
+	if encryption.IsKMS() {
+		plan = update.Sequential(reencrypt)
+	} else {
+		updates, err := rollingupdate.RuntimeConfigUpdates(app.Manifest, operator, operation.Key(), servers)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		masters, _ := update.SplitServers(updates)
+		if len(masters) == 0 {
+			return nil, trace.NotFound("no master servers found in cluster state")
+		}
+		nextMasters, err := rollingupdate.NextPassUpdates(masters)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		builder := rollingupdate.Builder{App: app.Package}
+		stage := update.Phase{
+			ID:          phases.StageKey,
+			Executor:    phases.StageKey,
+			Description: "Generate new encryption key",
+		}
+		trust := update.Phase{
+			ID:          trustPhase,
+			Description: "Distribute new encryption key",
+		}
+		trust.AddSequential(builder.Rotation(masters,
+			"Decrypt secrets with new key",
+			"Decrypt secrets with new key on node %q")...)
+		promote := update.Phase{
+			ID:          phases.PromoteKey,
+			Executor:    phases.PromoteKey,
+			Description: "Promote new encryption key",
+		}
+		rotate := update.Phase{
+			ID:          rotatePhase,
+			Description: "Rotate encryption key",
+		}
+		rotate.AddSequential(builder.Rotation(nextMasters,
+			"Encrypt secrets with new key",
+			"Encrypt secrets with new key on node %q")...)
+		plan = update.Sequential(stage, trust, promote, rotate, reencrypt)
+	}
+
+	result := &storage.OperationPlan{
+		OperationID:   operation.ID,
+		OperationType: operation.Type,
+		AccountID:     operation.AccountID,
+		ClusterName:   operation.SiteDomain,
+		Phases:        plan.AsPhases(),
+		Servers:       servers,
+		DNSConfig:     dnsConfig,
+	}
+	update.ResolvePlan(result)
+
+	return result, nil
+}
+
+const (
+	// trustPhase is the ID of the phase that distributes
+	// the new key to the masters
+	trustPhase = "trust"
+	// rotatePhase is the ID of the phase that makes the masters
+	// encrypt the secrets with the new key
+	rotatePhase = "rotate"
+)
@@ -0,0 +1,116 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rotatekey
+
+import (
+	"testing"
+
+	"github.com/gravitational/gravity/lib/app"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/clusterconfig"
+	libphase "github.com/gravitational/gravity/lib/update/internal/rollingupdate/phases"
+	"github.com/gravitational/gravity/lib/update/internal/testutils"
+
+	. "gopkg.in/check.v1"
+)
+
+func TestRotateKey(t *testing.T) { TestingT(t) }
+
+type S struct {
+	app     app.Application
+	servers []storage.Server
+}
+
+var _ = Suite(&S{})
+
+func (s *S) SetUpTest(c *C) {
+	s.servers = []storage.Server{
+		{Hostname: "node-1", AdvertiseIP: "10.0.0.1", Role: "node", ClusterRole: string(schema.ServiceRoleMaster)},
+		{Hostname: "node-2", AdvertiseIP: "10.0.0.2", Role: "node", ClusterRole: string(schema.ServiceRoleNode)},
+	}
+	s.app = app.Application{
+		Package: loc.MustParseLocator("gravitational.io/app:0.0.1"),
+		Manifest: schema.Manifest{
+			NodeProfiles: schema.NodeProfiles{{Name: "node"}},
+			SystemOptions: &schema.SystemOptions{
+				Dependencies: schema.SystemDependencies{
+					Runtime: &schema.Dependency{Locator: runtimeLoc},
+				},
+			},
+		},
+	}
+}
+
+func (s *S) TestRotatesKeyInTwoPasses(c *C) {
+	plan, err := newOperationPlan(s.app, storage.DefaultDNSConfig, testOperator, operation,
+		clusterconfig.Encryption{Provider: clusterconfig.EncryptionProviderAESCBC}, s.servers)
+	c.Assert(err, IsNil)
+
+	c.Assert(testutils.PhaseIDs(plan.Phases), DeepEquals, []string{"/stage-key", "/trust", "/promote-key", "/rotate", "/reencrypt"})
+	c.Assert(plan.Phases[4].Requires, DeepEquals, []string{"/rotate"})
+
+	trust := plan.Phases[1]
+	c.Assert(testutils.PhaseIDs(trust.Phases), DeepEquals, []string{"/trust/packages", "/trust/masters"},
+		Commentf("Only the masters run the apiserver."))
+	c.Assert(trust.Phases[0].Executor, Equals, libphase.Packages)
+	c.Assert(trust.Phases[0].Data.Update.Servers, HasLen, 1)
+	rotate := plan.Phases[3]
+	c.Assert(testutils.PhaseIDs(rotate.Phases), DeepEquals, []string{"/rotate/packages", "/rotate/masters"})
+
+	// Each pass restarts the runtime container with its own packages
+	first := trust.Phases[0].Data.Update.Servers[0].Runtime
+	second := rotate.Phases[0].Data.Update.Servers[0].Runtime
+	c.Assert(first.Update.ConfigPackage, Equals, testOperator.runtimeConfigPackage)
+	c.Assert(second.Update.ConfigPackage.String(), Equals, "gravitational.io/planet-config:0.0.1+1")
+
+	trustRestart := trust.Phases[1].Phases[0].Phases[1]
+	c.Assert(trustRestart.ID, Equals, "/trust/masters/node-1/restart")
+	c.Assert(changesetID("1", trustRestart.ID), Equals, "1-trust")
+	rotateRestart := rotate.Phases[1].Phases[0].Phases[1]
+	c.Assert(changesetID("1", rotateRestart.ID), Equals, "1-rotate")
+}
+
+func (s *S) TestReencryptsWithKMSProvider(c *C) {
+	plan, err := newOperationPlan(s.app, storage.DefaultDNSConfig, testOperator, operation,
+		clusterconfig.Encryption{
+			Provider: clusterconfig.EncryptionProviderKMS,
+			KMS:      &clusterconfig.KMS{Name: "vault", Endpoint: "unix:///socket"},
+		}, s.servers)
+	c.Assert(err, IsNil)
+	c.Assert(testutils.PhaseIDs(plan.Phases), DeepEquals, []string{"/reencrypt"})
+}
+
+func (r testRotator) RotatePlanetConfig(ops.RotatePlanetConfigRequest) (*ops.RotatePackageResponse, error) {
+	return &ops.RotatePackageResponse{Locator: r.runtimeConfigPackage}, nil
+}
+
+var operation = testutils.NewOperation(ops.SiteOperation{
+	Type: ops.OperationRotateSecretsKey,
+})
+
+var runtimeLoc = loc.Locator{Repository: "foo", Name: "runtime", Version: "0.0.1"}
+
+var testOperator = testRotator{
+	runtimeConfigPackage: loc.Locator{Repository: "gravitational.io", Name: "planet-config", Version: "0.0.1"},
+}
+
+type testRotator struct {
+	runtimeConfigPackage loc.Locator
+}
@@ -171,8 +171,14 @@ func (r *Updater) executePlan(ctx context.Context) error {
 	defer progress.Stop()
 
 	planErr := r.machine.ExecutePlan(ctx, progress)
-	if planErr != nil {
+	if planErr != nil && !IsApprovalRequired(planErr) {
 		r.WithError(planErr).Warn("Failed to execute plan.")
+		planErr = r.retryOrRollback(ctx, progress, planErr)
+	}
+	if IsApprovalRequired(planErr) {
+		// Keep the operation active until it has been approved
+		r.WithError(planErr).Info("Operation is waiting for approval.")
+		return trace.Wrap(planErr)
 	}
 
 	err := r.machine.Complete(planErr)
@@ -181,7 +187,7 @@ func (r *Updater) executePlan(ctx context.Context) error {
 	}
 
 	// Keep the agents running as long as the operation can be resumed
-	if planErr != nil {
+	if planErr != nil && !r.isRolledBack() {
 		return trace.Wrap(err)
 	}
 
@@ -192,7 +198,53 @@ func (r *Updater) executePlan(ctx context.Context) error {
 	if errShutdown := rpc.ShutdownAgents(ctx, addrs, r.FieldLogger, r.Runner); errShutdown != nil {
 		r.WithError(errShutdown).Warn("Failed to shutdown agents.")
 	}
-	return nil
+	return trace.Wrap(planErr)
+}
+
+// retryOrRollback applies the automatic rollback policy of the operation
+// to the failed plan: the plan is resumed up to the configured number of times
+// and is rolled back if it still fails.
+// Returns the last plan execution error
+func (r *Updater) retryOrRollback(ctx context.Context, progress utils.Progress, planErr error) error {
+	policy := r.autoRollbackPolicy()
+	if policy == nil {
+		return planErr
+	}
+	for attempt := 1; attempt <= policy.Retries && planErr != nil; attempt++ {
+		if ctx.Err() != nil {
+			return planErr
+		}
+		r.WithField("attempt", attempt).Info("Resume failed plan.")
+		planErr = r.machine.ExecutePlan(ctx, progress)
+		if planErr != nil && !IsApprovalRequired(planErr) {
+			r.WithError(planErr).Warn("Failed to execute plan.")
+		}
+	}
+	if planErr == nil || IsApprovalRequired(planErr) || ctx.Err() != nil {
+		return planErr
+	}
+	r.Info("Roll back failed plan.")
+	if err := r.machine.RollbackPlan(ctx, progress); err != nil {
+		// The operation is left failed and can be rolled back manually
+		r.WithError(err).Warn("Failed to roll back plan.")
+	}
+	return planErr
+}
+
+func (r *Updater) autoRollbackPolicy() *storage.AutoRollbackPolicy {
+	if r.Operation.Update == nil {
+		return nil
+	}
+	return r.Operation.Update.AutoRollback
+}
+
+func (r *Updater) isRolledBack() bool {
+	plan, err := r.machine.GetPlan()
+	if err != nil {
+		r.WithError(err).Warn("Failed to query operation plan.")
+		return false
+	}
+	return fsm.IsRolledBack(plan)
 }
 
 func (r *Updater) updateProgress(lastProgress *ops.ProgressEntry) *ops.ProgressEntry {
@@ -231,6 +283,34 @@ func (r *Config) CheckAndSetDefaults() error {
 	return nil
 }
 
+// CheckApproval makes sure that the operation can be executed if it requires
+// approval from a second user.
+// The approval is looked up in the cluster backend and, once verified, is
+// recorded in the local backend so the operation can be resumed
+// while the cluster backend is unavailable
+func (r *Config) CheckApproval() error {
+	if !r.Operation.IsPendingApproval() {
+		return nil
+	}
+	operation, err := r.Backend.GetSiteOperation(r.Operation.SiteDomain, r.Operation.ID)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := (*ops.SiteOperation)(operation).CheckApproved(); err != nil {
+		return trace.Wrap(err)
+	}
+	r.Operation.Approval = operation.Approval
+	localOperation, err := r.LocalBackend.GetSiteOperation(r.Operation.SiteDomain, r.Operation.ID)
+	if err == nil {
+		localOperation.Approval = operation.Approval
+		_, err = r.LocalBackend.UpdateSiteOperation(*localOperation)
+	}
+	if err != nil {
+		r.WithError(err).Warn("Failed to record operation approval in local backend.")
+	}
+	return nil
+}
+
 // Config describes configuration for executing an update operation
 type Config struct {
 	// Operation references the update operation
@@ -93,6 +93,28 @@ func Retry(ctx context.Context, fn func() error, timeout time.Duration) error {
 	return trace.Wrap(utils.RetryWithInterval(ctx, b, fn))
 }
 
+// NewApprovalRequiredError returns a new error that indicates that the operation
+// is paused until it is explicitly approved
+func NewApprovalRequiredError(format string, args ...interface{}) error {
+	return trace.Wrap(&approvalRequiredError{message: fmt.Sprintf(format, args...)})
+}
+
+// IsApprovalRequired returns true if the specified error indicates that
+// the operation is paused until it is explicitly approved
+func IsApprovalRequired(err error) bool {
+	_, ok := trace.Unwrap(err).(*approvalRequiredError)
+	return ok
+}
+
+type approvalRequiredError struct {
+	message string
+}
+
+// Error returns the error message
+func (r *approvalRequiredError) Error() string {
+	return r.message
+}
+
 // SplitServers splits the specified server list into servers with master cluster role
 // and regular nodes.
 func SplitServers(servers []storage.UpdateServer) (masters, nodes []storage.UpdateServer) {
@@ -531,6 +531,15 @@ func (i *IdentityACL) CreateClusterAdminAgent(clusterName string, agent storage.
 	return i.identity.CreateClusterAdminAgent(clusterName, agent)
 }
 
+// CreateClusterJoinAgent creates a new cluster agent user the short-lived
+// join tokens authenticate as. It can only join nodes to the cluster
+func (i *IdentityACL) CreateClusterJoinAgent(clusterName string, agent storage.User) (storage.User, error) {
+	if err := i.usersAction(teleservices.VerbCreate); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return i.identity.CreateClusterJoinAgent(clusterName, agent)
+}
+
 // CreateAdmin creates a new admin user for the locally running site.
 func (i *IdentityACL) CreateAdmin(email, password string) error {
 	if err := i.usersAction(teleservices.VerbCreate); err != nil {
@@ -265,59 +265,79 @@ func NewClusterAgentRole(name string, clusterName string) (teleservices.Role, er
 		Allow: teleservices.RoleConditions{
 			Namespaces: []string{defaults.Namespace},
 			KubeGroups: GetAdminKubernetesGroups(),
-			Rules: []teleservices.Rule{
-				{
-					Resources: []string{storage.KindCluster},
-					Verbs: []string{
-						teleservices.VerbRead,
-						teleservices.VerbUpdate,
-						storage.VerbConnect,
-					},
-					Where: storage.EqualsExpr{
-						Left:  storage.ResourceNameExpr,
-						Right: storage.StringExpr(clusterName),
-					}.String(),
-				},
-				{
-					Resources: []string{storage.KindApp},
-					Verbs: []string{
-						teleservices.VerbList,
-						teleservices.VerbRead,
-					},
-				},
-				{
-					Resources: []string{storage.KindRepository},
-					Where: storage.EqualsExpr{
-						Left:  storage.ResourceNameExpr,
-						Right: storage.StringExpr(clusterName),
-					}.String(),
-					Verbs: []string{teleservices.Wildcard},
+			Rules: append(clusterAgentRules(clusterName), teleservices.Rule{
+				Resources: []string{teleservices.KindTrustedCluster},
+				Verbs: []string{
+					teleservices.VerbRead,
+					teleservices.VerbList,
+					teleservices.VerbCreate,
+					teleservices.VerbUpdate,
 				},
-				{
-					Resources: []string{storage.KindRepository},
-					Where: storage.EqualsExpr{
-						Left:  storage.ResourceNameExpr,
-						Right: storage.StringExpr(defaults.SystemAccountOrg),
-					}.String(),
-					Verbs: []string{
-						teleservices.VerbRead,
-						teleservices.VerbList,
-					},
-				},
-				{
-					Resources: []string{teleservices.KindTrustedCluster},
-					Verbs: []string{
-						teleservices.VerbRead,
-						teleservices.VerbList,
-						teleservices.VerbCreate,
-						teleservices.VerbUpdate,
-					},
-				},
-			},
+			}),
 		},
 	})
 }
 
+// NewClusterJoinRole returns new role for the nodes joining the cluster
+// with a short-lived join token.
+// Unlike the agent role, it does not grant access to the trusted clusters
+// and, as neither role grants access to join tokens, the nodes can not
+// issue new join tokens or look up the existing ones
+func NewClusterJoinRole(name string, clusterName string) (teleservices.Role, error) {
+	return NewSystemRole(name, teleservices.RoleSpecV3{
+		Allow: teleservices.RoleConditions{
+			Namespaces: []string{defaults.Namespace},
+			KubeGroups: GetAdminKubernetesGroups(),
+			Rules:      clusterAgentRules(clusterName),
+		},
+	})
+}
+
+// clusterAgentRules returns the rules the agents need to run
+// operations on the specified cluster
+func clusterAgentRules(clusterName string) []teleservices.Rule {
+	return []teleservices.Rule{
+		{
+			Resources: []string{storage.KindCluster},
+			Verbs: []string{
+				teleservices.VerbRead,
+				teleservices.VerbUpdate,
+				storage.VerbConnect,
+			},
+			Where: storage.EqualsExpr{
+				Left:  storage.ResourceNameExpr,
+				Right: storage.StringExpr(clusterName),
+			}.String(),
+		},
+		{
+			Resources: []string{storage.KindApp},
+			Verbs: []string{
+				teleservices.VerbList,
+				teleservices.VerbRead,
+			},
+		},
+		{
+			Resources: []string{storage.KindRepository},
+			Where: storage.EqualsExpr{
+				Left:  storage.ResourceNameExpr,
+				Right: storage.StringExpr(clusterName),
+			}.String(),
+			Verbs: []string{teleservices.Wildcard},
+		},
+		{
+			Resources: []string{storage.KindRepository},
+			Where: storage.EqualsExpr{
+				Left:  storage.ResourceNameExpr,
+				Right: storage.StringExpr(defaults.SystemAccountOrg),
+			}.String(),
+			Verbs: []string{
+				teleservices.VerbRead,
+				teleservices.VerbList,
+			},
+		},
+	}
+}
+
 // NewObjectStorageRole specifies role for the object storage
 func NewObjectStorageRole(name string) (teleservices.Role, error) {
 	return NewSystemRole(name, teleservices.RoleSpecV3{
@@ -131,6 +131,10 @@ type Users interface {
 	// e.g. create and delete roles, set up OIDC connectors
 	CreateClusterAdminAgent(cluster string, agent storage.User) (storage.User, error)
 
+	// CreateClusterJoinAgent creates a new cluster agent user the short-lived
+	// join tokens authenticate as. It can only join nodes to the cluster
+	CreateClusterJoinAgent(cluster string, agent storage.User) (storage.User, error)
+
 	// CreateLocalAdmin creates a new admin user for the locally running site
 	CreateAdmin(email, password string) error
 
@@ -384,7 +388,7 @@ func GetSiteAgent(siteName string, backend storage.Backend) (*storage.APIKey, er
 	}
 	var user storage.User
 	for i := range users {
-		if users[i].GetType() == storage.AgentUser {
+		if users[i].GetType() == storage.AgentUser && !storage.IsClusterJoinAgent(users[i]) {
 			user = users[i]
 			break
 		}
@@ -410,7 +414,8 @@ func GetOpsCenterAgent(opsCenter, clusterName string, backend storage.Backend) (
 	}
 	var user storage.User
 	for i := range users {
-		if users[i].GetType() == storage.AgentUser && users[i].GetOpsCenter() == opsCenter {
+		if users[i].GetType() == storage.AgentUser && !storage.IsClusterJoinAgent(users[i]) &&
+			users[i].GetOpsCenter() == opsCenter {
 			user = users[i]
 			break
 		}
@@ -355,7 +355,7 @@ func (c *UsersService) AuthenticateUserBasicAuth(username, password string) (sto
 	}
 
 	switch user.GetType() {
-	case storage.AgentUser:
+	case storage.AgentUser, storage.ServiceAccountUser:
 		// check the provided password against agent api keys (it may have a few)
 		keys, err := c.backend.GetAPIKeys(user.GetName())
 		if err != nil {
@@ -537,6 +537,22 @@ func (c *UsersService) CreateClusterAdminAgent(clusterName string, agent storage
 	return c.createClusterAgent(agent, clusterName, true, nil)
 }
 
+// CreateClusterJoinAgent creates the agent user the short-lived join tokens
+// authenticate as
+func (c *UsersService) CreateClusterJoinAgent(clusterName string, agent storage.User) (storage.User, error) {
+	agent.SetClusterName(clusterName)
+	agent.SetType(storage.AgentUser)
+	joinRole, err := users.NewClusterJoinRole(agent.GetName(), clusterName)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	readerRole, err := users.NewReaderRole()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return c.createUserWithRoles(agent, []teleservices.Role{readerRole, joinRole}, nil)
+}
+
 func (c *UsersService) createClusterAgent(agent storage.User, clusterName string, admin bool, key *storage.APIKey) (storage.User, error) {
 	agent.SetClusterName(clusterName)
 	agent.SetType(storage.AgentUser)
@@ -690,14 +706,18 @@ func (c *UsersService) UpsertUser(teleuser teleservices.User) error {
 		}
 	}
 	var keys []storage.APIKey
-	if u.GetType() == storage.AgentUser {
+	switch u.GetType() {
+	case storage.AgentUser:
 		// generate a unique api key for the agent
 		token, err := users.CryptoRandomToken(defaults.AgentTokenBytes)
 		if err != nil {
 			return trace.Wrap(err)
 		}
 		keys = []storage.APIKey{{Token: token, UserEmail: u.GetName()}}
-	} else {
+	case storage.ServiceAccountUser:
+		// service accounts do not have passwords and their API keys
+		// are managed explicitly
+	default:
 		err := teleservices.VerifyPassword([]byte(u.GetPassword()))
 		if err != nil {
 			return trace.Wrap(err)
@@ -0,0 +1,157 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gravitational/trace"
+)
+
+// CronSchedule is a parsed schedule in the standard five-field cron format:
+// minute, hour, day of month, month and day of week
+type CronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar are set when the respective field is unrestricted
+	domStar, dowStar bool
+}
+
+// ParseCronSchedule parses the schedule in the standard five-field cron format,
+// e.g. "0 2 * * *". Each field accepts "*", single values, ranges, lists and
+// steps, e.g. "1-5", "0,30" or "*/15". Days of week are numbered from 0
+// (Sunday) to 7 (also Sunday). The descriptors @hourly, @daily, @weekly,
+// @monthly and @yearly are supported as well
+func ParseCronSchedule(spec string) (*CronSchedule, error) {
+	if descriptor, ok := cronDescriptors[strings.TrimSpace(spec)]; ok {
+		spec = descriptor
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, trace.BadParameter("cron schedule %q should have 5 fields: "+
+			"minute, hour, day of month, month and day of week", spec)
+	}
+	var schedule CronSchedule
+	var err error
+	for i, field := range []struct {
+		bits     *uint64
+		min, max int
+	}{
+		{bits: &schedule.minute, min: 0, max: 59},
+		{bits: &schedule.hour, min: 0, max: 23},
+		{bits: &schedule.dom, min: 1, max: 31},
+		{bits: &schedule.month, min: 1, max: 12},
+		{bits: &schedule.dow, min: 0, max: 7},
+	} {
+		*field.bits, err = parseCronField(fields[i], field.min, field.max)
+		if err != nil {
+			return nil, trace.BadParameter("invalid cron schedule %q: %v", spec, err)
+		}
+	}
+	// 7 is an alias for Sunday
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+	}
+	schedule.domStar = fields[2] == "*"
+	schedule.dowStar = fields[4] == "*"
+	return &schedule, nil
+}
+
+// Next returns the earliest time after t that matches the schedule
+// or zero time if the schedule never matches, e.g. "0 0 30 2 *".
+// The schedule is evaluated in the location of t
+func (r CronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case r.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !r.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case r.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case r.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// matchesDay returns true if the day of t matches the schedule.
+// If both the day of month and the day of week are restricted,
+// the day matches if either of them matches
+func (r CronSchedule) matchesDay(t time.Time) bool {
+	domMatch := r.dom&(1<<uint(t.Day())) != 0
+	dowMatch := r.dow&(1<<uint(t.Weekday())) != 0
+	if r.domStar || r.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+func parseCronField(field string, min, max int) (bits uint64, err error) {
+	for _, expr := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(expr, "/"); i != -1 {
+			step, err = strconv.Atoi(expr[i+1:])
+			if err != nil || step <= 0 {
+				return 0, trace.BadParameter("invalid step in %q", expr)
+			}
+			expr = expr[:i]
+		}
+		var from, to int
+		switch i := strings.Index(expr, "-"); {
+		case expr == "*":
+			from, to = min, max
+		case i != -1:
+			if from, err = strconv.Atoi(expr[:i]); err != nil {
+				return 0, trace.BadParameter("invalid range %q", expr)
+			}
+			if to, err = strconv.Atoi(expr[i+1:]); err != nil {
+				return 0, trace.BadParameter("invalid range %q", expr)
+			}
+		default:
+			if from, err = strconv.Atoi(expr); err != nil {
+				return 0, trace.BadParameter("invalid value %q", expr)
+			}
+			to = from
+			if step != 1 {
+				to = max
+			}
+		}
+		if from < min || to > max || from > to {
+			return 0, trace.BadParameter("%q is out of range %v-%v", expr, min, max)
+		}
+		for value := from; value <= to; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+	return bits, nil
+}
+
+var cronDescriptors = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+	"@yearly":  "0 0 1 1 *",
+}
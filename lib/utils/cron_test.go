@@ -0,0 +1,69 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"time"
+
+	"gopkg.in/check.v1"
+)
+
+type CronSuite struct{}
+
+var _ = check.Suite(&CronSuite{})
+
+func (s *CronSuite) TestNext(c *check.C) {
+	// Saturday
+	now := time.Date(2019, time.June, 1, 10, 30, 0, 0, time.UTC)
+	testCases := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{spec: "0 2 * * *", expected: time.Date(2019, time.June, 2, 2, 0, 0, 0, time.UTC)},
+		{spec: "*/15 * * * *", expected: time.Date(2019, time.June, 1, 10, 45, 0, 0, time.UTC)},
+		{spec: "30 10 * * *", expected: time.Date(2019, time.June, 2, 10, 30, 0, 0, time.UTC)},
+		{spec: "0 9-17 * * 1-5", expected: time.Date(2019, time.June, 3, 9, 0, 0, 0, time.UTC)},
+		{spec: "0 0 * * 7", expected: time.Date(2019, time.June, 2, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 1,15 * *", expected: time.Date(2019, time.June, 15, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 13 * 5", expected: time.Date(2019, time.June, 7, 0, 0, 0, 0, time.UTC)},
+		{spec: "@monthly", expected: time.Date(2019, time.July, 1, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 29 2 *", expected: time.Date(2020, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 30 2 *", expected: time.Time{}},
+	}
+	for _, tc := range testCases {
+		schedule, err := ParseCronSchedule(tc.spec)
+		c.Assert(err, check.IsNil, check.Commentf(tc.spec))
+		c.Assert(schedule.Next(now), check.DeepEquals, tc.expected, check.Commentf(tc.spec))
+	}
+}
+
+func (s *CronSuite) TestRejectsInvalidSchedule(c *check.C) {
+	for _, spec := range []string{
+		"",
+		"0 2 * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+	} {
+		_, err := ParseCronSchedule(spec)
+		c.Assert(err, check.NotNil, check.Commentf(spec))
+	}
+}
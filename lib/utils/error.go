@@ -385,6 +385,23 @@ func NewExitCodeError(exitCode int) error {
 	return exitCodeError{code: exitCode}
 }
 
+// NewSilentExitCodeError returns a new error with the specified exit code
+// that is not reported to the user since the command has already
+// communicated the outcome
+func NewSilentExitCodeError(exitCode int) error {
+	if exitCode == 0 {
+		return nil
+	}
+	return exitCodeError{code: exitCode, silent: true}
+}
+
+// IsSilentExitCodeError returns true if the specified error is an exit code
+// error that should not be reported to the user
+func IsSilentExitCodeError(err error) bool {
+	errCode, ok := trace.Unwrap(err).(exitCodeError)
+	return ok && errCode.silent
+}
+
 // NewExitCodeError returns a new error that wraps a specific exit code and message
 func NewExitCodeErrorWithMessage(exitCode int, message string) error {
 	return exitCodeError{
@@ -434,6 +451,8 @@ type exitCodeError struct {
 	message string
 	// err specifies optional original error
 	err error
+	// silent specifies whether the error is not reported to the user
+	silent bool
 }
 
 func isPeerDeniedError(message string) bool {
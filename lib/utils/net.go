@@ -159,6 +159,33 @@ func PickAdvertiseIP() (string, error) {
 	return ip.String(), nil
 }
 
+// PickLocalIP returns the first non-loopback IPv4 address among the host's
+// interfaces that are up. Unlike PickAdvertiseIP, it does not require
+// the host to have a default route
+func PickLocalIP() (string, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return "", trace.Wrap(err)
+		}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || ipNet.IP.To4() == nil || ipNet.IP.IsLoopback() {
+				continue
+			}
+			return ipNet.IP.String(), nil
+		}
+	}
+	return "", trace.NotFound("no suitable IPv4 address found among the host's network interfaces")
+}
+
 // LocalIPNetworks returns the list of all local IP networks
 func LocalIPNetworks() (blocks []net.IPNet, err error) {
 	ifaces, err := net.Interfaces()
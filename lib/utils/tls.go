@@ -18,6 +18,8 @@ package utils
 
 import (
 	"archive/tar"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -194,6 +196,31 @@ func (ta TLSArchive) GetKeyPair(name string) (*authority.TLSKeyPair, error) {
 	return keyPair, nil
 }
 
+// CheckCertAuthority verifies that the specified key pair can issue certificates:
+// the certificate is a single valid certificate authority certificate
+// and the private key matches it
+func CheckCertAuthority(keyPair authority.TLSKeyPair, now time.Time) error {
+	cert, err := cfsslhelpers.ParseCertificatePEM(keyPair.CertPEM)
+	if err != nil {
+		return trace.BadParameter("failed to parse certificate authority certificate: %v", err)
+	}
+	name := cert.Subject.CommonName
+	if !cert.BasicConstraintsValid || !cert.IsCA {
+		return trace.BadParameter("certificate %q is not a certificate authority", name)
+	}
+	if cert.KeyUsage != 0 && cert.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return trace.BadParameter("certificate authority %q is not allowed to sign certificates", name)
+	}
+	if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		return trace.BadParameter("certificate authority %q is only valid from %v to %v",
+			name, cert.NotBefore.Format(time.RFC3339), cert.NotAfter.Format(time.RFC3339))
+	}
+	if _, err := tls.X509KeyPair(keyPair.CertPEM, keyPair.KeyPEM); err != nil {
+		return trace.BadParameter("invalid private key for certificate authority %q: %v", name, err)
+	}
+	return nil
+}
+
 const (
 	// KeySuffix is the standard extension used for x509 key files generated by gravity
 	KeySuffix = "key"
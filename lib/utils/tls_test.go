@@ -17,8 +17,11 @@ limitations under the License.
 package utils
 
 import (
+	"time"
+
 	"github.com/cloudflare/cfssl/csr"
 	"github.com/gravitational/license/authority"
+	"github.com/gravitational/trace"
 	. "gopkg.in/check.v1"
 )
 
@@ -71,3 +74,30 @@ func (s *TLSSuite) TestCertArchive(c *C) {
 	c.Assert(string(okeyPair.CertPEM), Equals, string(keyPair.CertPEM))
 	c.Assert(string(okeyPair.KeyPEM), Equals, string(keyPair.KeyPEM))
 }
+
+func (s *TLSSuite) TestChecksCertAuthority(c *C) {
+	ca, err := authority.GenerateSelfSignedCA(csr.CertificateRequest{
+		CN: "cluster.local",
+		CA: &csr.CAConfig{Expiry: "1h"},
+	})
+	c.Assert(err, IsNil)
+	other, err := authority.GenerateSelfSignedCA(csr.CertificateRequest{
+		CN: "other.local",
+	})
+	c.Assert(err, IsNil)
+	leaf, err := authority.GenerateCertificate(csr.CertificateRequest{
+		CN:    "apiserver",
+		Hosts: []string{"127.0.0.1"},
+	}, ca, nil, 0)
+	c.Assert(err, IsNil)
+
+	now := time.Now()
+	c.Assert(CheckCertAuthority(*ca, now), IsNil)
+	c.Assert(trace.IsBadParameter(CheckCertAuthority(*leaf, now)), Equals, true,
+		Commentf("Leaf certificate can't issue certificates."))
+	mismatched := authority.TLSKeyPair{CertPEM: ca.CertPEM, KeyPEM: other.KeyPEM}
+	c.Assert(trace.IsBadParameter(CheckCertAuthority(mismatched, now)), Equals, true,
+		Commentf("Private key does not match the certificate."))
+	c.Assert(trace.IsBadParameter(CheckCertAuthority(*ca, now.Add(2*time.Hour))), Equals, true,
+		Commentf("Certificate authority has expired."))
+}
@@ -0,0 +1,400 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/httplib"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/roundtrip"
+	teleauth "github.com/gravitational/teleport/lib/auth"
+	teleclient "github.com/gravitational/teleport/lib/client"
+	telehttplib "github.com/gravitational/teleport/lib/httplib"
+	teleservices "github.com/gravitational/teleport/lib/services"
+	teleutils "github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/trace"
+	"github.com/julienschmidt/httprouter"
+)
+
+// DeviceLoginRequest starts the single sign-on login of a command line client
+type DeviceLoginRequest struct {
+	// ConnectorID is the optional name of the auth connector,
+	// defaults to the connector of the cluster auth preference
+	ConnectorID string `json:"connector_id"`
+	// PublicKey is the client public key to issue the certificates for
+	PublicKey []byte `json:"public_key"`
+	// CertTTL is the requested TTL of the certificates
+	CertTTL time.Duration `json:"cert_ttl"`
+}
+
+// DeviceLoginResponse tells the client how the user completes the login
+type DeviceLoginResponse struct {
+	// DeviceCode is the secret code the client polls the login with
+	DeviceCode string `json:"device_code"`
+	// UserCode is the code the user confirms the login with in the browser
+	UserCode string `json:"user_code"`
+	// VerificationURL is the URL the user opens in the browser
+	VerificationURL string `json:"verification_url"`
+	// ExpiresIn is the number of seconds before the login expires
+	ExpiresIn int `json:"expires_in"`
+	// Interval is the number of seconds the client waits between the polls
+	Interval int `json:"interval"`
+}
+
+// DeviceTokenRequest polls the single sign-on login of a command line client
+type DeviceTokenRequest struct {
+	// DeviceCode is the device code of the login
+	DeviceCode string `json:"device_code"`
+}
+
+// createDeviceLogin starts the single sign-on login of a command line client
+// with the device authorization flow
+//
+// POST /portalapi/v1/sso/device
+//
+// Input: DeviceLoginRequest
+//
+// Output: DeviceLoginResponse
+func (m *Handler) createDeviceLogin(w http.ResponseWriter, r *http.Request, p httprouter.Params) (interface{}, error) {
+	var req DeviceLoginRequest
+	if err := telehttplib.ReadJSON(r, &req); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if len(req.PublicKey) == 0 {
+		return nil, trace.BadParameter("missing public key")
+	}
+	connectorID, connectorKind, err := m.getAuthConnector(req.ConnectorID)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	deviceCode, err := teleutils.CryptoRandomHex(defaults.DeviceCodeBytes)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	userCode, err := newUserCode()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	err = m.cfg.Backend.CreateDeviceAuthRequest(storage.DeviceAuthRequest{
+		DeviceCode:    deviceCode,
+		UserCode:      userCode,
+		ConnectorID:   connectorID,
+		ConnectorKind: connectorKind,
+		PublicKey:     req.PublicKey,
+		CertTTL:       req.CertTTL,
+		Expires:       m.cfg.Backend.Now().UTC().Add(defaults.DeviceLoginTTL),
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	m.Infof("Created device login with %v connector %v.", connectorKind, connectorID)
+	return &DeviceLoginResponse{
+		DeviceCode: deviceCode,
+		UserCode:   userCode,
+		VerificationURL: fmt.Sprintf("%v/sso/device/verify?%v", m.cfg.PrefixURL,
+			url.Values{"user_code": []string{userCode}}.Encode()),
+		ExpiresIn: int(defaults.DeviceLoginTTL / time.Second),
+		Interval:  int(defaults.DeviceLoginPollInterval / time.Second),
+	}, nil
+}
+
+// verifyDeviceLogin redirects the browser of the user to the identity
+// provider to authenticate the command line client login with the
+// specified user code
+//
+// GET /portalapi/v1/sso/device/verify?user_code=<user-code>
+func (m *Handler) verifyDeviceLogin(w http.ResponseWriter, r *http.Request, p httprouter.Params) (interface{}, error) {
+	userCode := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("user_code")))
+	req, err := m.cfg.Backend.GetDeviceAuthRequestByUserCode(userCode)
+	if err != nil {
+		m.Warnf("Failed to find device login: %v.", err)
+		http.Redirect(w, r, "/web/msg/error/login_failed", http.StatusFound)
+		return nil, nil
+	}
+	clientRedirectURL := fmt.Sprintf("%v/sso/device/done?%v", m.cfg.PrefixURL,
+		url.Values{"user_code": []string{userCode}}.Encode())
+	if req.IsCompleted() {
+		http.Redirect(w, r, clientRedirectURL, http.StatusFound)
+		return nil, nil
+	}
+	redirectURL, err := m.createAuthRequest(*req, clientRedirectURL)
+	if err != nil {
+		m.Warnf("Failed to create auth request: %v.", trace.DebugReport(err))
+		http.Redirect(w, r, "/web/msg/error/login_failed", http.StatusFound)
+		return nil, nil
+	}
+	http.Redirect(w, r, redirectURL, http.StatusFound)
+	return nil, nil
+}
+
+// deviceLoginDone tells the user to return to the command line client
+//
+// GET /portalapi/v1/sso/device/done
+func (m *Handler) deviceLoginDone(w http.ResponseWriter, r *http.Request, p httprouter.Params) (interface{}, error) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, "Login successful. You can close this window and return to the terminal.")
+	return nil, nil
+}
+
+// getDeviceToken returns the certificates issued for the command line client
+// once the user has authenticated
+//
+// POST /portalapi/v1/sso/device/token
+//
+// Input: DeviceTokenRequest
+//
+// Output: SSH login response with the user certificates
+func (m *Handler) getDeviceToken(w http.ResponseWriter, r *http.Request, p httprouter.Params) (interface{}, error) {
+	var req DeviceTokenRequest
+	if err := telehttplib.ReadJSON(r, &req); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	authRequest, err := m.cfg.Backend.GetDeviceAuthRequest(req.DeviceCode)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if !authRequest.IsCompleted() {
+		return nil, trace.CompareFailed("authorization pending")
+	}
+	// The certificates are handed out once
+	err = m.cfg.Backend.DeleteDeviceAuthRequest(req.DeviceCode)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return json.RawMessage(authRequest.Response), nil
+}
+
+// completeDeviceLogin records the certificates issued after the user has
+// authenticated the command line client login with the identity provider
+func (m *Handler) completeDeviceLogin(w http.ResponseWriter, r *http.Request, p CallbackParams) error {
+	redirectURL, err := url.Parse(p.ClientRedirectURL)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	req, err := m.cfg.Backend.GetDeviceAuthRequestByUserCode(redirectURL.Query().Get("user_code"))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if !bytes.Equal(req.PublicKey, p.PublicKey) {
+		return trace.AccessDenied("public key mismatch")
+	}
+	req.Response, err = json.Marshal(teleauth.SSHLoginResponse{
+		Username:    p.Username,
+		Cert:        p.Cert,
+		TLSCert:     p.TLSCert,
+		HostSigners: teleauth.AuthoritiesToTrustedCerts(p.HostSigners),
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = m.cfg.Backend.UpdateDeviceAuthRequest(*req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	m.Infof("Completed device login for %v.", p.Username)
+	http.Redirect(w, r, p.ClientRedirectURL, http.StatusFound)
+	return nil
+}
+
+// getAuthConnector returns the name and the kind of the specified auth
+// connector, or of the connector of the cluster auth preference
+func (m *Handler) getAuthConnector(connectorID string) (id, kind string, err error) {
+	if connectorID == "" {
+		preference, err := m.cfg.Auth.GetAuthPreference()
+		if err != nil {
+			return "", "", trace.Wrap(err)
+		}
+		connectorID = preference.GetConnectorName()
+	}
+	if connectorID == "" {
+		return "", "", trace.BadParameter("no auth connector specified and " +
+			"the cluster auth preference does not name a connector")
+	}
+	if _, err := m.cfg.Auth.GetOIDCConnector(connectorID, false); err == nil {
+		return connectorID, teleservices.KindOIDC, nil
+	}
+	if _, err := m.cfg.Auth.GetSAMLConnector(connectorID, false); err == nil {
+		return connectorID, teleservices.KindSAML, nil
+	}
+	if _, err := m.cfg.Auth.GetGithubConnector(connectorID, false); err == nil {
+		return connectorID, teleservices.KindGithub, nil
+	}
+	return "", "", trace.NotFound("auth connector %q not found", connectorID)
+}
+
+// createAuthRequest creates the auth request for the connector of the specified
+// device login and returns the URL of the identity provider to redirect the user to
+func (m *Handler) createAuthRequest(req storage.DeviceAuthRequest, clientRedirectURL string) (string, error) {
+	switch req.ConnectorKind {
+	case teleservices.KindOIDC:
+		response, err := m.cfg.Auth.CreateOIDCAuthRequest(teleservices.OIDCAuthRequest{
+			ConnectorID:       req.ConnectorID,
+			Type:              deviceLoginType,
+			CheckUser:         true,
+			PublicKey:         req.PublicKey,
+			CertTTL:           req.CertTTL,
+			ClientRedirectURL: clientRedirectURL,
+		})
+		if err != nil {
+			return "", trace.Wrap(err)
+		}
+		return response.RedirectURL, nil
+	case teleservices.KindSAML:
+		response, err := m.cfg.Auth.CreateSAMLAuthRequest(teleservices.SAMLAuthRequest{
+			ConnectorID:       req.ConnectorID,
+			Type:              deviceLoginType,
+			CheckUser:         true,
+			PublicKey:         req.PublicKey,
+			CertTTL:           req.CertTTL,
+			ClientRedirectURL: clientRedirectURL,
+		})
+		if err != nil {
+			return "", trace.Wrap(err)
+		}
+		return response.RedirectURL, nil
+	case teleservices.KindGithub:
+		response, err := m.cfg.Auth.CreateGithubAuthRequest(teleservices.GithubAuthRequest{
+			ConnectorID:       req.ConnectorID,
+			Type:              deviceLoginType,
+			PublicKey:         req.PublicKey,
+			CertTTL:           req.CertTTL,
+			ClientRedirectURL: clientRedirectURL,
+		})
+		if err != nil {
+			return "", trace.Wrap(err)
+		}
+		return response.RedirectURL, nil
+	}
+	return "", trace.BadParameter("unsupported auth connector kind %q", req.ConnectorKind)
+}
+
+// DeviceLoginConfig defines the single sign-on login of a command line client
+type DeviceLoginConfig struct {
+	// URL is the URL of the Gravity Hub or cluster to log into
+	URL string
+	// ConnectorID is the optional name of the auth connector
+	ConnectorID string
+	// CertTTL is the requested TTL of the certificates
+	CertTTL time.Duration
+	// Insecure turns off TLS verification
+	Insecure bool
+	// Prompt displays the verification URL and the user code to the user
+	Prompt func(DeviceLoginResponse)
+}
+
+// DeviceLoginResult is the result of a successful single sign-on login
+type DeviceLoginResult struct {
+	// Username is the name of the authenticated user
+	Username string
+	// Key is the client key with the issued certificates
+	Key teleclient.Key
+}
+
+// DeviceLogin logs into the Gravity Hub or cluster with the device authorization
+// flow: the user authenticates with the identity provider in a browser, possibly
+// on another machine, while the client waits for the short-lived certificates
+// issued for its newly generated key
+func DeviceLogin(ctx context.Context, config DeviceLoginConfig) (*DeviceLoginResult, error) {
+	key, err := teleclient.NewKey()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	clt, err := roundtrip.NewClient(config.URL, defaults.PortalAPIVersion,
+		roundtrip.HTTPClient(httplib.GetClient(config.Insecure)))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	out, err := telehttplib.ConvertResponse(clt.PostJSON(ctx, clt.Endpoint("sso", "device"),
+		DeviceLoginRequest{
+			ConnectorID: config.ConnectorID,
+			PublicKey:   key.Pub,
+			CertTTL:     config.CertTTL,
+		}))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var login DeviceLoginResponse
+	if err := json.Unmarshal(out.Bytes(), &login); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if config.Prompt != nil {
+		config.Prompt(login)
+	}
+	ticker := time.NewTicker(time.Duration(login.Interval) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			out, err := telehttplib.ConvertResponse(clt.PostJSON(ctx, clt.Endpoint("sso", "device", "token"),
+				DeviceTokenRequest{DeviceCode: login.DeviceCode}))
+			if trace.IsCompareFailed(err) {
+				continue
+			}
+			if trace.IsNotFound(err) {
+				return nil, trace.LimitExceeded("login has expired")
+			}
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			var response teleauth.SSHLoginResponse
+			if err := json.Unmarshal(out.Bytes(), &response); err != nil {
+				return nil, trace.Wrap(err)
+			}
+			key.Cert = response.Cert
+			key.TLSCert = response.TLSCert
+			key.TrustedCA = response.HostSigners
+			return &DeviceLoginResult{
+				Username: response.Username,
+				Key:      *key,
+			}, nil
+		case <-ctx.Done():
+			return nil, trace.ConnectionProblem(ctx.Err(), "login canceled")
+		}
+	}
+}
+
+// newUserCode returns a new random user code in the XXXX-XXXX format.
+// The alphabet omits vowels and easily confused letters
+func newUserCode() (string, error) {
+	const alphabet = "BCDFGHJKLMNPQRSTVWXZ"
+	random := make([]byte, 8)
+	if _, err := rand.Read(random); err != nil {
+		return "", trace.Wrap(err)
+	}
+	code := make([]byte, 0, len(random)+1)
+	for i, b := range random {
+		if i == len(random)/2 {
+			code = append(code, '-')
+		}
+		code = append(code, alphabet[int(b)%len(alphabet)])
+	}
+	return string(code), nil
+}
+
+// deviceLoginType identifies the auth requests of the device logins
+const deviceLoginType = "device"
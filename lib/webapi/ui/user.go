@@ -77,6 +77,12 @@ type userACL struct {
 	Apps access `json:"apps"`
 	// Events defines access to audit events
 	Events access `json:"events"`
+	// Operations defines access to cluster operations
+	Operations access `json:"operations"`
+	// OperationPlans defines access to operation plans
+	OperationPlans access `json:"operationPlans"`
+	// Upgrades defines access to cluster upgrades
+	Upgrades access `json:"upgrades"`
 	// SSHLogins defines access to servers
 	SSHLogins []string `json:"sshLogins"`
 }
@@ -133,6 +139,9 @@ func NewUserACL(storageUser storage.User, userRoles teleservices.RoleSet, cluste
 	logForwarderAccess := newAccess(userRoles, ctx, storage.KindLogForwarder)
 	appAccess := newAccess(userRoles, ctx, storage.KindApp)
 	eventAccess := newAccess(userRoles, ctx, teleservices.KindEvent)
+	operationAccess := newClusterResourceAccess(userRoles, ctx, storage.KindOperation, clusterAccess)
+	operationPlanAccess := newClusterResourceAccess(userRoles, ctx, storage.KindOperationPlan, clusterAccess)
+	upgradeAccess := newClusterResourceAccess(userRoles, ctx, storage.KindUpgrade, clusterAccess)
 	logins := getLogins(userRoles)
 
 	acl := userACL{
@@ -147,6 +156,9 @@ func NewUserACL(storageUser storage.User, userRoles teleservices.RoleSet, cluste
 		LogForwarders:   logForwarderAccess,
 		Apps:            appAccess,
 		Events:          eventAccess,
+		Operations:      operationAccess,
+		OperationPlans:  operationPlanAccess,
+		Upgrades:        upgradeAccess,
 		SSHLogins:       logins,
 	}
 
@@ -225,6 +237,19 @@ func newAccess(roleSet teleservices.RoleSet, ctx *teleservices.Context, kind str
 	}
 }
 
+// newClusterResourceAccess returns access to the specified resource kind scoped
+// to the cluster. Same as with the operator API, reading the cluster implies
+// reading the resource and updating the cluster implies any modification of it
+func newClusterResourceAccess(roleSet teleservices.RoleSet, ctx *teleservices.Context, kind string, cluster access) access {
+	access := newAccess(roleSet, ctx, kind)
+	access.List = access.List || cluster.Read
+	access.Read = access.Read || cluster.Read
+	access.Edit = access.Edit || cluster.Edit
+	access.Create = access.Create || cluster.Edit
+	access.Delete = access.Delete || cluster.Edit
+	return access
+}
+
 func hasAccess(roleSet teleservices.RoleSet, ctx *teleservices.Context, kind string, verbs ...string) bool {
 	for _, verb := range verbs {
 		err := roleSet.CheckAccessToRule(ctx, defaults.Namespace, kind, verb, false)
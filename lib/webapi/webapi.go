@@ -202,6 +202,12 @@ func NewAPI(cfg Config) (*Handler, error) {
 	// OAuth2 callbacks
 	h.GET("/github/callback", telehttplib.MakeHandler(h.githubCallback))
 
+	// Single sign-on for command line clients
+	h.POST("/sso/device", telehttplib.MakeHandler(h.createDeviceLogin))
+	h.GET("/sso/device/verify", telehttplib.MakeHandler(h.verifyDeviceLogin))
+	h.GET("/sso/device/done", telehttplib.MakeHandler(h.deviceLoginDone))
+	h.POST("/sso/device/token", telehttplib.MakeHandler(h.getDeviceToken))
+
 	// Users
 	h.GET("/sites/:domain/users", h.needsAuth(h.getUsers))
 	h.PUT("/sites/:domain/users", h.needsAuth(h.updateUser))
@@ -301,6 +307,9 @@ func (m *Handler) GetConfig() Config {
 
 // CallbackHandler is the generic OAuth2 provider callback handler
 func (m *Handler) CallbackHandler(w http.ResponseWriter, r *http.Request, p CallbackParams) error {
+	if p.Type == deviceLoginType {
+		return trace.Wrap(m.completeDeviceLogin(w, r, p))
+	}
 	if p.CreateWebSession {
 		err := csrf.VerifyToken(p.CSRFToken, r)
 		if err != nil {
@@ -1703,7 +1712,7 @@ type webJoinToken struct {
 	Token string `json:"token"`
 }
 
-// getJoinToken returns join token for the specified cluster.
+// getJoinToken issues a new short-lived join token for the specified cluster.
 //
 //   GET /portalapi/v1/sites/:domain/tokens/join
 //
@@ -1711,7 +1720,10 @@ type webJoinToken struct {
 //
 //   webJoinToken
 func (m *Handler) getJoinToken(w http.ResponseWriter, r *http.Request, p httprouter.Params, ctx *AuthContext) (interface{}, error) {
-	token, err := ctx.Operator.GetExpandToken(clusterKey(ctx, p))
+	token, err := ctx.Operator.CreateJoinToken(r.Context(), ops.CreateJoinTokenRequest{
+		SiteKey: clusterKey(ctx, p),
+		TTL:     defaults.JoinTokenTTL,
+	})
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -0,0 +1,310 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gravitational/gravity/lib/archive"
+	libbackup "github.com/gravitational/gravity/lib/backup"
+	"github.com/gravitational/gravity/lib/etcd"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/storage/keyval"
+
+	"github.com/coreos/etcd/clientv3"
+	dockerarchive "github.com/docker/docker/pkg/archive"
+	teleutils "github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/trace"
+)
+
+type appBackupConfig struct {
+	// release is the name of the application release
+	release string
+	// tarball is the path to the backup tarball
+	tarball string
+	// timeout is the maximum duration of the operation
+	timeout time.Duration
+	// follow specifies whether to output the hook logs to the stdout
+	follow bool
+	// silent suppresses the hook logs
+	silent bool
+	// confirmed specifies whether the user has confirmed the restore
+	confirmed bool
+}
+
+// backupApp backs up the Kubernetes resources of a single application
+// release and the data written by its backup hook into the tarball
+func backupApp(env *localenv.LocalEnvironment, config appBackupConfig) error {
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	releases, err := operator.GetTrackedReleases(cluster.Key())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	var release *libbackup.AppRelease
+	for _, r := range releases {
+		if r.GetName() == config.release {
+			release = &libbackup.AppRelease{
+				Name:      r.GetName(),
+				Namespace: r.GetNamespace(),
+				Image:     r.GetLocator().String(),
+			}
+		}
+	}
+	if release == nil {
+		return trace.NotFound("release %v not found", config.release)
+	}
+	if err := release.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	stage, err := newAppBackupStage()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer stage.remove()
+	key, err := operator.CreateAppBackupOperation(context.TODO(), ops.CreateAppBackupOperationRequest{
+		ClusterKey: cluster.Key(),
+		Release:    config.release,
+		Path:       config.tarball,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return runAppBackupOperation(operator, *key, config.timeout, func(ctx context.Context) error {
+		client, err := newLocalEtcdClient()
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		defer client.Close()
+		backup, count, err := libbackup.BackupApp(ctx, client, *release, stage.hostDir)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		env.Printf("Backed up %v Kubernetes resources of release %v.\n", count, release.Name)
+		err = runAppBackupHook(ctx, env, *cluster, release.Image, schema.HookBackup,
+			stage.planetPath(libbackup.AppDataDir), config)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if backup.HasData() {
+			env.Printf("Backed up data of release %v.\n", release.Name)
+		}
+		if err := compressDirectory(stage.hostDir, config.tarball); err != nil {
+			return trace.Wrap(err)
+		}
+		env.Printf("Backup is written to %v.\n", config.tarball)
+		return nil
+	})
+}
+
+// restoreApp restores the Kubernetes resources of a single application
+// release from its backup and runs its restore hook with the backed up data.
+// The resources of the other applications are not modified
+func restoreApp(env *localenv.LocalEnvironment, config appBackupConfig) error {
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	stage, err := newAppBackupStage()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer stage.remove()
+	f, err := os.Open(config.tarball)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	if err := dockerarchive.Untar(f, stage.hostDir, archive.DefaultOptions()); err != nil {
+		return trace.Wrap(err)
+	}
+	backup, err := libbackup.OpenApp(stage.hostDir)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if backup.Release.Name != config.release {
+		return trace.BadParameter("%v is the backup of release %v, not %v",
+			config.tarball, backup.Release.Name, config.release)
+	}
+	if !config.confirmed {
+		env.Printf(restoreAppBanner+"\n", backup.Release.Name, backup.Release.Namespace)
+		resp, err := confirm()
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if !resp {
+			env.Println("Action cancelled by user.")
+			return nil
+		}
+	}
+	key, err := operator.CreateAppRestoreOperation(context.TODO(), ops.CreateAppBackupOperationRequest{
+		ClusterKey: cluster.Key(),
+		Release:    config.release,
+		Path:       config.tarball,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return runAppBackupOperation(operator, *key, config.timeout, func(ctx context.Context) error {
+		client, err := newLocalEtcdClient()
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		defer client.Close()
+		count, err := backup.RestoreResources(ctx, client)
+		if err != nil {
+			return trace.Wrap(err, "failed after restoring %v resources", count)
+		}
+		env.Printf("Restored %v Kubernetes resources of release %v.\n", count, backup.Release.Name)
+		if !backup.HasData() {
+			return nil
+		}
+		err = runAppBackupHook(ctx, env, *cluster, backup.Release.Image, schema.HookRestore,
+			stage.planetPath(libbackup.AppDataDir), config)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		env.Printf("Restored data of release %v.\n", backup.Release.Name)
+		return nil
+	})
+}
+
+// runAppBackupOperation runs fn as the application backup or restore
+// operation with the specified key and completes the operation
+func runAppBackupOperation(operator ops.Operator, key ops.SiteOperationKey, timeout time.Duration,
+	fn func(context.Context) error) (err error) {
+	defer func() {
+		if err != nil {
+			if failErr := ops.FailOperation(key, operator, trace.UserMessage(err)); failErr != nil {
+				log.WithError(failErr).Warn("Failed to mark operation failed.")
+			}
+			return
+		}
+		err = ops.CompleteOperation(key, operator)
+	}()
+	ctx := context.Background()
+	if timeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return trace.Wrap(fn(ctx))
+}
+
+// runAppBackupHook runs the hook of the application image on this node if
+// the application defines it, with the specified directory inside planet
+// mounted as the backup directory of the hook
+func runAppBackupHook(ctx context.Context, env *localenv.LocalEnvironment, cluster ops.Site, image string,
+	hook schema.HookType, dir string, config appBackupConfig) error {
+	locator, err := loc.ParseLocator(image)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	apps, err := env.SiteApps()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	application, err := apps.GetApp(*locator)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if !application.Manifest.HasHook(hook) {
+		log.Infof("Application %v has no %v hook.", locator, hook)
+		return nil
+	}
+	node, err := findLocalServer(cluster)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	hooks, err := libbackup.NewAppHooks(libbackup.AppHooksConfig{
+		Apps:     apps,
+		Cluster:  cluster.App.Package,
+		NodeName: node.KubeNodeID(),
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(hooks.Run(ctx, *locator, hook, dir, getStreamingWriter(config.silent, config.follow)))
+}
+
+func newLocalEtcdClient() (*clientv3.Client, error) {
+	config, err := keyval.LocalEtcdConfig(0)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	client, err := etcd.NewClient(*config)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return client, nil
+}
+
+// appBackupStage is the directory with the application backup
+// in the planet state directory, so the hook jobs can mount it
+type appBackupStage struct {
+	// id identifies the directory
+	id string
+	// hostDir is the path to the directory on host
+	hostDir string
+}
+
+func newAppBackupStage() (appBackupStage, error) {
+	id, err := teleutils.CryptoRandomHex(3)
+	if err != nil {
+		return appBackupStage{}, trace.Wrap(err, "failed to generate random ID")
+	}
+	dir, err := localenv.InGravity(fmt.Sprintf("planet/state/%v/backup", id))
+	if err != nil {
+		return appBackupStage{}, trace.Wrap(err)
+	}
+	return appBackupStage{id: id, hostDir: dir}, nil
+}
+
+// planetPath returns the path to the specified directory
+// of the backup inside planet
+func (r appBackupStage) planetPath(name string) string {
+	return filepath.Join("/ext/state", r.id, "backup", name)
+}
+
+func (r appBackupStage) remove() {
+	if err := os.RemoveAll(filepath.Dir(r.hostDir)); err != nil {
+		log.WithError(err).Warnf("Failed to remove directory %v.", r.hostDir)
+	}
+}
+
+const restoreAppBanner = `The Kubernetes resources of release %v in namespace %v will be replaced
+with the resources from the backup and the application data will be restored
+with the restore hook of the application. Other applications are not affected.
+
+Are you sure?`
@@ -0,0 +1,141 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/ops/events"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// auditFilter selects the API request audit records to display
+type auditFilter struct {
+	// user selects the requests of the specified user
+	user string
+	// since selects the requests made within the specified duration
+	since time.Duration
+	// failed selects the failed requests
+	failed bool
+	// limit is the maximum number of the most recent records to select
+	limit int
+}
+
+// match returns true if the specified cluster event is an API request
+// audit record selected by this filter
+func (r auditFilter) match(event storage.ClusterEvent, now time.Time) bool {
+	if event.Name != events.APIRequestEvent {
+		return false
+	}
+	if r.user != "" && events.Fields(event.Fields).GetString(events.FieldUser) != r.user {
+		return false
+	}
+	if r.since != 0 && event.Time.Before(now.Add(-r.since)) {
+		return false
+	}
+	if r.failed && event.Code != events.APIRequestFailureCode {
+		return false
+	}
+	return true
+}
+
+func showAuditRecords(env *localenv.LocalEnvironment, filter auditFilter, format constants.Format) error {
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	records, err := getAuditRecords(operator, cluster.Key(), filter)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	switch format {
+	case constants.EncodingJSON:
+		bytes, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		fmt.Println(string(bytes))
+		return nil
+	}
+
+	if len(records) == 0 {
+		env.Println("No API requests have been recorded.")
+		return nil
+	}
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 1, '\t', 0)
+	fmt.Fprintf(w, "Time\tUser\tVerb\tResource\tStatus\tLatency\n")
+	fmt.Fprintf(w, "----\t----\t----\t--------\t------\t-------\n")
+	for _, record := range records {
+		fields := events.Fields(record.Fields)
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%vms\n",
+			record.Time.Format(constants.HumanDateFormatSeconds),
+			fields.GetString(events.FieldUser),
+			fields.GetString(events.FieldVerb),
+			fields.GetString(events.FieldResource),
+			record.Fields[events.FieldStatus],
+			record.Fields[events.FieldLatency])
+	}
+	return trace.Wrap(w.Flush())
+}
+
+// getAuditRecords returns the most recent API request audit records
+// of the specified cluster selected by the filter, from the oldest to the newest
+func getAuditRecords(operator ops.Operator, key ops.SiteKey, filter auditFilter) ([]storage.ClusterEvent, error) {
+	now := time.Now()
+	var records []storage.ClusterEvent
+	var after string
+	for {
+		batch, err := operator.GetClusterEvents(context.TODO(), ops.GetClusterEventsRequest{
+			SiteKey: key,
+			After:   after,
+		})
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+		for _, event := range batch {
+			if filter.match(event, now) {
+				records = append(records, event)
+			}
+		}
+		after = batch[len(batch)-1].ID
+	}
+	if filter.limit > 0 && len(records) > filter.limit {
+		records = records[len(records)-filter.limit:]
+	}
+	return records, nil
+}
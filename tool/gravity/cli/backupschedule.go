@@ -0,0 +1,241 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	libbackup "github.com/gravitational/gravity/lib/backup"
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/dustin/go-humanize"
+	"github.com/gravitational/trace"
+)
+
+// backupSchedule configures the scheduled backups of the cluster.
+// Without the schedule, it displays the current backup schedule
+// and the status of the last scheduled backup
+func backupSchedule(env *localenv.LocalEnvironment, schedule, dir string, retain int, remove bool) error {
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	switch {
+	case remove:
+		err := operator.DeleteBackupSchedule(context.TODO(), cluster.Key())
+		if err != nil {
+			if trace.IsNotFound(err) {
+				return trace.NotFound("scheduled backups are not configured")
+			}
+			return trace.Wrap(err)
+		}
+		env.Println("Scheduled backups have been disabled.")
+		return nil
+	case schedule == "":
+		return trace.Wrap(showBackupSchedule(env, operator, cluster.Key()))
+	}
+	spec := storage.BackupScheduleSpecV2{
+		Schedule:  schedule,
+		Directory: dir,
+		Retain:    retain,
+	}
+	// keep the off-site destination configured with the resource
+	existing, err := operator.GetBackupSchedule(cluster.Key())
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	if existing != nil {
+		spec.Destination = existing.GetDestination()
+	}
+	backupSchedule := storage.NewBackupSchedule(spec)
+	if err := backupSchedule.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	err = operator.UpsertBackupSchedule(context.TODO(), cluster.Key(), backupSchedule)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	env.Printf("Cluster will be backed up to %v on schedule %q, keeping %v latest backups.\n",
+		backupSchedule.GetDirectory(), backupSchedule.GetSchedule(), backupSchedule.GetRetain())
+	if destination := backupSchedule.GetDestination(); destination != nil {
+		env.Printf("Backups will be uploaded to %v.\n", destination)
+	}
+	env.Printf("Next backup at %v.\n",
+		backupSchedule.Next(time.Now()).Format(constants.HumanDateFormat))
+	return nil
+}
+
+// unpackBackup decrypts the backup archive uploaded to the off-site
+// destination with the key from the specified file and extracts it
+// into the directory so the cluster can be restored from it
+func unpackBackup(env *localenv.LocalEnvironment, archivePath, dir, keyFile string) error {
+	key, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	if err := os.MkdirAll(dir, defaults.PrivateDirMask); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	if err := libbackup.ExtractArchive(f, dir, key); err != nil {
+		return trace.Wrap(err)
+	}
+	env.Printf("Backup has been unpacked into %v, restore the cluster with:\n", dir)
+	env.Printf("gravity restore --from=%v\n", dir)
+	return nil
+}
+
+// verifyBackup verifies that the backup is restorable. The backup is either
+// a backup directory or an archive uploaded to the off-site destination
+// which is decrypted and extracted into a temporary directory first.
+// With testRestore, the backup is also restored into a temporary
+// data directory without modifying the cluster
+func verifyBackup(env *localenv.LocalEnvironment, path, keyFile string, testRestore bool) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	var verification *libbackup.Verification
+	if fi.IsDir() {
+		verification, err = libbackup.Verify(path)
+	} else {
+		var dir string
+		dir, err = ioutil.TempDir("", "backup")
+		if err != nil {
+			return trace.ConvertSystemError(err)
+		}
+		defer os.RemoveAll(dir)
+		verification, err = verifyBackupArchive(path, dir, keyFile)
+	}
+	if err != nil {
+		return trace.Wrap(err, "backup %v is not restorable", path)
+	}
+	env.Printf("Cluster:\t%v\n", verification.ClusterName)
+	env.Printf("Servers:\t%v\n", len(verification.Servers))
+	env.Printf("Snapshot:\trevision %v, %v keys, %v Kubernetes resources\n",
+		verification.Snapshot.Revision, verification.Snapshot.Keys, verification.Resources)
+	env.Printf("Images:\t\t%v\n", len(verification.Images))
+	if len(verification.Apps) != 0 {
+		env.Printf("Apps:\t\t%v\n", strings.Join(verification.Apps, ", "))
+	}
+	for _, warning := range verification.Warnings {
+		env.Printf("Warning:\t%v\n", warning)
+	}
+	if testRestore {
+		dataDir, err := ioutil.TempDir("", "restore")
+		if err != nil {
+			return trace.ConvertSystemError(err)
+		}
+		defer os.RemoveAll(dataDir)
+		result, err := verification.TestRestore(context.TODO(), dataDir)
+		if err != nil {
+			return trace.Wrap(err, "test restore of backup %v failed", path)
+		}
+		env.Printf("Test restore:\t%v Kubernetes resources, %v cluster state records\n",
+			result.Resources, result.Records)
+	}
+	env.Printf("Backup %v has been verified.\n", path)
+	return nil
+}
+
+// verifyBackupArchive decrypts and extracts the backup archive
+// into the specified directory and verifies the extracted backup
+func verifyBackupArchive(archivePath, dir, keyFile string) (*libbackup.Verification, error) {
+	if keyFile == "" {
+		return nil, trace.BadParameter("verifying backup archive requires --key-file")
+	}
+	key, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	verification, err := libbackup.VerifyArchive(f, dir, key)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return verification, nil
+}
+
+func showBackupSchedule(env *localenv.LocalEnvironment, operator ops.Operator, key ops.SiteKey) error {
+	schedule, err := operator.GetBackupSchedule(key)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			env.Println("Scheduled backups are not configured.")
+			return nil
+		}
+		return trace.Wrap(err)
+	}
+	env.Printf("Schedule:\t%v\n", schedule.GetSchedule())
+	env.Printf("Directory:\t%v\n", schedule.GetDirectory())
+	env.Printf("Retain:\t\t%v\n", schedule.GetRetain())
+	if destination := schedule.GetDestination(); destination != nil {
+		env.Printf("Upload to:\t%v\n", destination)
+	}
+	env.Printf("Next backup:\t%v\n", schedule.Next(time.Now()).Format(constants.HumanDateFormat))
+	status, err := operator.GetBackupStatus(key)
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	if status == nil || status.LastBackup.IsZero() {
+		env.Println("Last backup:\tnone")
+	} else {
+		env.Printf("Last backup:\t%v, %v on %v (%v)\n",
+			humanize.RelTime(status.LastBackup, time.Now(), "ago", ""),
+			status.Path, status.Node, humanize.Bytes(uint64(status.Size)))
+	}
+	if status == nil {
+		return nil
+	}
+	if status.Message != "" {
+		env.Printf("Last attempt:\t%v, failed: %v\n",
+			humanize.RelTime(status.LastAttempt, time.Now(), "ago", ""), status.Message)
+	}
+	if status.Uploaded != "" {
+		env.Printf("Last upload:\t%v\n", status.Uploaded)
+	}
+	if status.UploadMessage != "" {
+		env.Printf("Last upload:\tfailed: %v\n", status.UploadMessage)
+	}
+	for _, app := range status.Apps {
+		if app.Message != "" {
+			env.Printf("    * %v:\tfailed: %v\n", app.App, app.Message)
+		} else {
+			env.Printf("    * %v:\tok\n", app.App)
+		}
+	}
+	return nil
+}
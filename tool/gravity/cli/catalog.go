@@ -0,0 +1,107 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/app/service"
+	"github.com/gravitational/gravity/lib/catalog"
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/helm"
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// catalogSync mirrors the latest versions of the charts from the specified
+// Helm repository into the cluster catalog.
+// The repository and the charts of the catalog sync schedule are used
+// unless specified explicitly
+func catalogSync(env *localenv.LocalEnvironment, repository string, charts []string) error {
+	clusterEnv, err := env.NewClusterEnvironment()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := clusterEnv.Operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	spec := storage.CatalogSyncScheduleSpecV2{
+		Repository: repository,
+		Charts:     charts,
+	}
+	if repository == "" {
+		schedule, err := clusterEnv.Backend.GetCatalogSyncSchedule(cluster.Domain)
+		if err != nil {
+			if trace.IsNotFound(err) {
+				return trace.NotFound("catalog sync schedule is not configured, " +
+					"specify the repository to mirror with --repo")
+			}
+			return trace.Wrap(err)
+		}
+		spec.Repository = schedule.GetRepository()
+		if len(charts) == 0 {
+			spec.Charts = schedule.GetCharts()
+		}
+	}
+	schedule := storage.NewCatalogSyncSchedule(spec)
+	if err := schedule.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	vendorer, err := service.NewVendorer(service.VendorerConfig{
+		DockerURL:   constants.DockerEngineURL,
+		RegistryURL: constants.DockerRegistry,
+		Packages:    clusterEnv.Packages,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	syncer, err := catalog.NewSyncer(catalog.SyncerConfig{
+		Apps:        clusterEnv.Apps,
+		ClusterName: cluster.Domain,
+		Vendorer:    vendorer,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	env.PrintStep("Synchronizing catalog with %v", schedule.GetRepository())
+	result, err := syncer.Sync(context.TODO(), schedule)
+	if result == nil {
+		return trace.Wrap(err)
+	}
+	// the applications imported outside of the cluster controller
+	// have to be added to the chart repository index explicitly
+	index, indexErr := helm.NewRepository(helm.Config{
+		Packages: clusterEnv.Packages,
+		Backend:  clusterEnv.Backend,
+	})
+	if indexErr != nil {
+		return trace.NewAggregate(err, indexErr)
+	}
+	for _, locator := range result.Imported {
+		if indexErr := index.AddToIndex(locator, true); indexErr != nil {
+			return trace.NewAggregate(err, indexErr)
+		}
+		env.PrintStep("Imported %v", locator)
+	}
+	for _, locator := range result.Skipped {
+		env.PrintStep("%v is up-to-date", locator)
+	}
+	return trace.Wrap(err)
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+
+	libfsm "github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/rpc"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/update"
+	"github.com/gravitational/gravity/lib/update/changecidr"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+)
+
+type changeCIDRConfig struct {
+	// podCIDR specifies the new pod subnet
+	podCIDR string
+	// serviceCIDR specifies the new service subnet
+	serviceCIDR string
+	// manual specifies whether the operation is executed manually
+	manual bool
+	// confirmed specifies whether the user has confirmed the operation
+	confirmed bool
+}
+
+func changeCIDR(localEnv *localenv.LocalEnvironment, environ LocalEnvironmentFactory, config changeCIDRConfig) error {
+	if config.podCIDR == "" && config.serviceCIDR == "" {
+		return trace.BadParameter("specify the new pod subnet with --pod-network-cidr " +
+			"and/or the new service subnet with --service-cidr")
+	}
+	if !config.confirmed {
+		localEnv.Println(changeCIDRBanner)
+		resp, err := confirm()
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if !resp {
+			localEnv.Println("Action cancelled by user.")
+			return nil
+		}
+	}
+	updateEnv, err := environ.NewUpdateEnv()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updateEnv.Close()
+	ctx := context.TODO()
+	updater, err := newUpdater(ctx, localEnv, updateEnv, changeCIDRInitializer{
+		podCIDR:     config.podCIDR,
+		serviceCIDR: config.serviceCIDR,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updater.Close()
+	if !config.manual {
+		err = updater.Run(ctx)
+		return trace.Wrap(err)
+	}
+	localEnv.Println(updateEnvironManualOperationBanner)
+	return nil
+}
+
+func executeChangeCIDRPhase(env *localenv.LocalEnvironment, environ LocalEnvironmentFactory, params PhaseParams, operation ops.SiteOperation) error {
+	updateEnv, err := environ.NewUpdateEnv()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updateEnv.Close()
+	updater, err := getChangeCIDRUpdater(env, updateEnv, operation)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updater.Close()
+	err = updater.RunPhase(context.TODO(), params.PhaseID, params.Timeout, params.Force)
+	return trace.Wrap(err)
+}
+
+func setChangeCIDRPhase(env *localenv.LocalEnvironment, environ LocalEnvironmentFactory, params SetPhaseParams, operation ops.SiteOperation) error {
+	updateEnv, err := environ.NewUpdateEnv()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updateEnv.Close()
+	updater, err := getChangeCIDRUpdater(env, updateEnv, operation)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updater.Close()
+	return updater.SetPhase(context.TODO(), params.PhaseID, params.State)
+}
+
+func rollbackChangeCIDRPhase(env *localenv.LocalEnvironment, environ LocalEnvironmentFactory, params PhaseParams, operation ops.SiteOperation) error {
+	updateEnv, err := environ.NewUpdateEnv()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updateEnv.Close()
+	updater, err := getChangeCIDRUpdater(env, updateEnv, operation)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updater.Close()
+	err = updater.RollbackPhase(context.TODO(), params.PhaseID, params.Timeout, params.Force)
+	return trace.Wrap(err)
+}
+
+func completeChangeCIDRPlan(env *localenv.LocalEnvironment, environ LocalEnvironmentFactory, operation ops.SiteOperation) error {
+	updateEnv, err := environ.NewUpdateEnv()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updateEnv.Close()
+	updater, err := getChangeCIDRUpdater(env, updateEnv, operation)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updater.Close()
+	return trace.Wrap(updater.Complete(nil))
+}
+
+func getChangeCIDRUpdater(env, updateEnv *localenv.LocalEnvironment, operation ops.SiteOperation) (*update.Updater, error) {
+	clusterEnv, err := env.NewClusterEnvironment()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	creds, err := libfsm.GetClientCredentials()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	runner := libfsm.NewAgentRunner(creds)
+	return changeCIDRInitializer{}.newUpdater(context.TODO(), clusterEnv.Operator, operation,
+		env, updateEnv, clusterEnv, runner)
+}
+
+func (changeCIDRInitializer) validatePreconditions(*localenv.LocalEnvironment, ops.Operator, ops.Site) error {
+	return nil
+}
+
+func (r changeCIDRInitializer) newOperation(operator ops.Operator, cluster ops.Site) (*ops.SiteOperationKey, error) {
+	key, err := operator.CreateChangeCIDROperation(context.TODO(),
+		ops.CreateChangeCIDROperationRequest{
+			ClusterKey:  cluster.Key(),
+			PodCIDR:     r.podCIDR,
+			ServiceCIDR: r.serviceCIDR,
+		},
+	)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return key, nil
+}
+
+func (changeCIDRInitializer) newOperationPlan(
+	ctx context.Context,
+	operator ops.Operator,
+	cluster ops.Site,
+	operation ops.SiteOperation,
+	localEnv, updateEnv *localenv.LocalEnvironment,
+	clusterEnv *localenv.ClusterEnvironment,
+	leader *storage.Server,
+) (*storage.OperationPlan, error) {
+	plan, err := changecidr.NewOperationPlan(operator, clusterEnv.Apps, operation, cluster.ClusterState.Servers)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return plan, nil
+}
+
+func (changeCIDRInitializer) newUpdater(
+	ctx context.Context,
+	operator ops.Operator,
+	operation ops.SiteOperation,
+	localEnv, updateEnv *localenv.LocalEnvironment,
+	clusterEnv *localenv.ClusterEnvironment,
+	runner rpc.AgentRepository,
+) (*update.Updater, error) {
+	config := changecidr.Config{
+		Config: update.Config{
+			Operation:    &operation,
+			Operator:     operator,
+			Backend:      clusterEnv.Backend,
+			LocalBackend: updateEnv.Backend,
+			Silent:       localEnv.Silent,
+			Runner:       runner,
+			FieldLogger: logrus.WithFields(logrus.Fields{
+				trace.Component: "update:changecidr",
+				"operation":     operation,
+			}),
+		},
+		Apps:              clusterEnv.Apps,
+		Client:            clusterEnv.Client,
+		ClusterPackages:   clusterEnv.ClusterPackages,
+		HostLocalPackages: localEnv.Packages,
+	}
+	return changecidr.New(ctx, config)
+}
+
+func (changeCIDRInitializer) updateDeployRequest(req deployAgentsRequest) deployAgentsRequest {
+	return req
+}
+
+type changeCIDRInitializer struct {
+	podCIDR     string
+	serviceCIDR string
+}
+
+const changeCIDRBanner = `The pod and service subnets of the cluster will be changed.
+The runtime container is restarted on every node, one node at a time, and the
+nodes are drained so the pods are rescheduled with IPs from the new pod subnet.
+If the service subnet changes, the masters are restarted twice and all services
+are recreated with IPs from the new service subnet which interrupts the traffic
+to the services until the nodes have been restarted.
+
+Are you sure?`
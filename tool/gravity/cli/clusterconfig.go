@@ -42,6 +42,9 @@ func updateConfig(ctx context.Context, localEnv, updateEnv *localenv.LocalEnviro
 	if err := validateCloudConfig(localEnv, config); err != nil {
 		return trace.Wrap(err)
 	}
+	if err := preserveInstallSettings(localEnv, config); err != nil {
+		return trace.Wrap(err)
+	}
 	if !confirmed {
 		if manual {
 			localEnv.Println(updateConfigBannerManual)
@@ -253,6 +256,40 @@ type configInitializer struct {
 	config   libclusterconfig.Interface
 }
 
+// preserveInstallSettings carries over the FIPS mode, the hardening profile
+// and the network policy baseline of the cluster to the specified
+// configuration. All are set during installation and cannot be changed
+func preserveInstallSettings(localEnv *localenv.LocalEnvironment, config libclusterconfig.Interface) error {
+	operator, err := localEnv.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	clusterConfig, err := operator.GetClusterConfiguration(cluster.Key())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if config.IsFIPS() && !clusterConfig.IsFIPS() {
+		return trace.BadParameter("cannot enable FIPS mode: FIPS mode can only be enabled during installation")
+	}
+	if clusterConfig.IsFIPS() {
+		config.SetFIPS(true)
+	}
+	if profile := config.GetHardeningProfile(); profile != "" && profile != clusterConfig.GetHardeningProfile() {
+		return trace.BadParameter("cannot change hardening profile: hardening profile can only be set during installation")
+	}
+	if profile := clusterConfig.GetHardeningProfile(); profile != "" {
+		config.SetHardeningProfile(profile)
+	}
+	if clusterConfig.IsNetworkPolicyEnabled() {
+		config.SetNetworkPolicy(true)
+	}
+	return nil
+}
+
 func validateCloudConfig(localEnv *localenv.LocalEnvironment, config libclusterconfig.Interface) error {
 	if newGlobalConfig := config.GetGlobalConfig(); !isCloudConfigEmpty(newGlobalConfig) {
 		// TODO(dmitri): require cloud provider if cloud-config is being updated
@@ -0,0 +1,104 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gravitational/gravity/lib/localenv"
+
+	"github.com/dustin/go-humanize"
+	"github.com/gravitational/trace"
+)
+
+type promoteClusterConfig struct {
+	// manual specifies whether the restore operation is executed manually
+	manual bool
+	// confirmed specifies whether the user has confirmed the operation
+	confirmed bool
+}
+
+// promoteCluster promotes the standby cluster to take over from the primary.
+// It stops the replication and restores the cluster from the latest
+// replicated backup of the primary cluster.
+// Must be executed on the node the backup has been replicated to
+func promoteCluster(localEnv *localenv.LocalEnvironment, environ LocalEnvironmentFactory, config promoteClusterConfig) error {
+	operator, err := localEnv.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	status, err := operator.GetReplicationStatus(cluster.Key())
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	if status == nil || status.Path == "" {
+		return trace.NotFound("no backup of the primary cluster has been replicated to this cluster, " +
+			"configure the replication with the replication resource")
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	if status.Node != hostname {
+		return trace.BadParameter("the latest backup of the primary cluster has been replicated to node %v, "+
+			"run this command on that node", status.Node)
+	}
+	if !config.confirmed {
+		localEnv.Printf(promoteClusterBanner+"\n", status.Backup,
+			humanize.RelTime(status.Taken, time.Now(), "ago", ""))
+		if len(status.MissingImages) != 0 {
+			localEnv.Printf("\nThe following images of the primary cluster are missing from the registry "+
+				"and need to be uploaded after the promotion:\n  %v\n",
+				strings.Join(status.MissingImages, "\n  "))
+		}
+		resp, err := confirm()
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if !resp {
+			localEnv.Println("Action cancelled by user.")
+			return nil
+		}
+	}
+	// stop the replication first so the backup being restored
+	// is not replaced by the replicator
+	err = operator.DeleteReplication(context.TODO(), cluster.Key())
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	localEnv.Println("Replication has been stopped.")
+	return trace.Wrap(restoreCluster(localEnv, environ, restoreClusterConfig{
+		backup:    status.Path,
+		manual:    config.manual,
+		confirmed: true,
+	}))
+}
+
+const promoteClusterBanner = `This cluster will be promoted to take over from the primary cluster.
+The replication will be stopped and the cluster will be restored from
+the backup %v of the primary cluster taken %v.
+All changes made on the primary cluster after the backup has been taken
+will not be present on this cluster.
+
+Are you sure?`
@@ -0,0 +1,298 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
+	libfsm "github.com/gravitational/gravity/lib/fsm"
+	libkubernetes "github.com/gravitational/gravity/lib/kubernetes"
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/rpc"
+	"github.com/gravitational/gravity/lib/shutdown"
+	"github.com/gravitational/gravity/lib/state"
+	libstatus "github.com/gravitational/gravity/lib/status"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/systemservice"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/credentials"
+)
+
+// stopCluster gracefully stops the cluster.
+// If the cluster has been partially stopped, the shutdown is resumed
+func stopCluster(env *localenv.LocalEnvironment, confirmed bool) error {
+	stateFile, err := clusterStopStateFile()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	stopState, err := shutdown.LoadState(stateFile)
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	if stopState != nil && stopState.IsStopped() {
+		env.Println("Cluster is already stopped, use 'gravity cluster start' to start it.")
+		return nil
+	}
+	if !confirmed {
+		env.Println(stopClusterBanner)
+		resp, err := confirm()
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if !resp {
+			env.Println("Action cancelled by user.")
+			return nil
+		}
+	}
+	ctx := context.TODO()
+	var creds credentials.TransportCredentials
+	if stopState == nil {
+		stopState, creds, err = newClusterStopState(ctx, env)
+	} else {
+		creds, err = libfsm.GetClientCredentials()
+	}
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	runner := libfsm.NewAgentRunner(creds)
+	defer runner.Close()
+	err = shutdown.Stop(ctx, shutdown.Config{
+		StateFile: stateFile,
+		Cluster: &clusterController{
+			env:         env,
+			FieldLogger: logrus.WithField(trace.Component, "cluster:stop"),
+		},
+		Runner:  runner,
+		Printer: env,
+	}, stopState)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	env.Printf("Cluster %v has been stopped, use 'gravity cluster start' on this node to start it.\n",
+		stopState.ClusterName)
+	return nil
+}
+
+// startCluster starts the cluster stopped with stopCluster
+func startCluster(env *localenv.LocalEnvironment) error {
+	stateFile, err := clusterStopStateFile()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	stopState, err := shutdown.LoadState(stateFile)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	creds, err := libfsm.GetClientCredentials()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	runner := libfsm.NewAgentRunner(creds)
+	defer runner.Close()
+	ctx := context.TODO()
+	logger := logrus.WithField(trace.Component, "cluster:start")
+	err = shutdown.Start(ctx, shutdown.Config{
+		StateFile: stateFile,
+		Cluster:   &clusterController{env: env, FieldLogger: logger},
+		Runner:    runner,
+		Printer:   env,
+	}, stopState)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	env.PrintStep("Shutting down agents")
+	var addrs []string
+	for _, server := range stopState.Servers {
+		addrs = append(addrs, server.AdvertiseIP)
+	}
+	if err := rpc.ShutdownAgents(ctx, addrs, logger, runner); err != nil {
+		logger.WithError(err).Warn("Failed to shut down agents.")
+	}
+	env.Printf("Cluster %v has been started.\n", stopState.ClusterName)
+	return nil
+}
+
+// newClusterStopState returns the initial state for stopping the active cluster
+// and deploys the agents used to execute commands on cluster nodes
+func newClusterStopState(ctx context.Context, env *localenv.LocalEnvironment) (*shutdown.State, credentials.TransportCredentials, error) {
+	clusterEnv, err := env.NewClusterEnvironment()
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	if clusterEnv.Client == nil {
+		return nil, nil, trace.BadParameter("this operation can only be executed on one of the master nodes")
+	}
+	cluster, err := clusterEnv.Operator.GetLocalSite()
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	if cluster.State != ops.SiteStateActive {
+		return nil, nil, trace.BadParameter("cluster is %v, it can only be stopped when active", cluster.State)
+	}
+	local, err := findLocalServer(*cluster)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	stopState, err := shutdown.NewState(cluster.Domain, cluster.ClusterState.Servers, *local)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	teleportClient, err := env.TeleportClient(constants.Localhost)
+	if err != nil {
+		return nil, nil, trace.Wrap(err, "failed to create a teleport client")
+	}
+	proxy, err := teleportClient.ConnectToProxy(ctx)
+	if err != nil {
+		return nil, nil, trace.Wrap(err, "failed to connect to teleport proxy")
+	}
+	deployCtx, cancel := context.WithTimeout(ctx, defaults.AgentDeployTimeout)
+	defer cancel()
+	env.PrintStep("Deploying agents on the nodes")
+	creds, err := deployAgents(deployCtx, deployAgentsRequest{
+		clusterState: cluster.ClusterState,
+		clusterName:  cluster.Domain,
+		clusterEnv:   clusterEnv,
+		proxy:        proxy,
+		leader:       local,
+	})
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	return stopState, creds, nil
+}
+
+func clusterStopStateFile() (string, error) {
+	stateDir, err := state.GetStateDir()
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return filepath.Join(state.GravityUpdateDir(stateDir), defaults.ClusterStopStateFile), nil
+}
+
+// clusterController implements the cluster-level actions
+// used to stop and start the local cluster
+type clusterController struct {
+	logrus.FieldLogger
+	env *localenv.LocalEnvironment
+}
+
+// Cordon marks the specified node unschedulable
+func (r *clusterController) Cordon(ctx context.Context, server storage.Server) error {
+	client, err := r.env.KubeClient()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(libkubernetes.SetUnschedulable(ctx, client.CoreV1().Nodes(), server.KubeNodeID(), true))
+}
+
+// Drain evicts the pods from the specified node.
+// Pod disruption budgets are not respected since all nodes are drained
+func (r *clusterController) Drain(ctx context.Context, server storage.Server) error {
+	client, err := r.env.KubeClient()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(libkubernetes.DrainWithPolicy(ctx, client, server.KubeNodeID(), storage.DrainPolicy{
+		IgnorePodDisruptionBudgets: true,
+	}))
+}
+
+// Uncordon marks the specified node schedulable
+func (r *clusterController) Uncordon(ctx context.Context, server storage.Server) error {
+	client, err := r.env.KubeClient()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(libkubernetes.SetUnschedulable(ctx, client.CoreV1().Nodes(), server.KubeNodeID(), false))
+}
+
+// SnapshotEtcd backs up the etcd database on the local master node
+func (r *clusterController) SnapshotEtcd(ctx context.Context) (path string, err error) {
+	stateDir, err := state.GetStateDir()
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	path = filepath.Join(state.GravityUpdateDir(stateDir), defaults.EtcdShutdownBackupFile)
+	_, err = utils.RunPlanetCommand(ctx, r.FieldLogger, "etcd", "backup", path)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+	return path, nil
+}
+
+// CheckHealth returns an error if any of the specified nodes is not healthy
+// as reported by the planet agents
+func (r *clusterController) CheckHealth(ctx context.Context, servers []storage.Server) error {
+	status, err := libstatus.FromPlanetAgent(ctx, servers)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	for _, node := range status.Nodes {
+		if node.Status != libstatus.NodeHealthy {
+			return trace.BadParameter("node %v is %v", node.Hostname, node.Status)
+		}
+	}
+	return nil
+}
+
+// planetStop stops the runtime container on this node and disables
+// its service so it does not start automatically after the node reboots
+func planetStop(env *localenv.LocalEnvironment) error {
+	services, err := systemservice.New()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	runtimePackage, err := pack.FindRuntimePackage(env.Packages)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := services.StopPackageService(*runtimePackage); err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(services.DisablePackageService(*runtimePackage))
+}
+
+// planetStart enables and starts the runtime container on this node
+func planetStart(env *localenv.LocalEnvironment) error {
+	services, err := systemservice.New()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	runtimePackage, err := pack.FindRuntimePackage(env.Packages)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := services.EnablePackageService(*runtimePackage); err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(services.StartPackageService(*runtimePackage, false))
+}
+
+const stopClusterBanner = `The cluster will be stopped: all nodes are drained, the etcd database
+is backed up and the runtime is stopped on all nodes. Applications will be
+unavailable until the cluster is started again with 'gravity cluster start'
+executed on this node.
+
+Are you sure?`
@@ -18,6 +18,9 @@ package cli
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/gravitational/gravity/lib/app"
 	"github.com/gravitational/gravity/lib/constants"
@@ -58,9 +61,13 @@ func updateTrigger(
 	updateEnv *localenv.LocalEnvironment,
 	updatePackage string,
 	manual, noValidateVersion bool,
+	strategy *storage.UpdateStrategy,
+	autoRollback *storage.AutoRollbackPolicy,
+	schedule *storage.MaintenanceWindow,
+	drain *storage.DrainPolicy,
 ) error {
 	ctx := context.TODO()
-	updater, err := newClusterUpdater(ctx, localEnv, updateEnv, updatePackage, manual, noValidateVersion)
+	updater, err := newClusterUpdater(ctx, localEnv, updateEnv, updatePackage, manual, noValidateVersion, strategy, autoRollback, schedule, drain)
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -73,15 +80,204 @@ func updateTrigger(
 	return nil
 }
 
+// upgradeCluster upgrades the cluster to the specified cluster image.
+// If the installed runtime cannot be upgraded to the image directly, the cluster
+// is upgraded through the intermediate releases as a chain of upgrade operations
+func upgradeCluster(
+	localEnv *localenv.LocalEnvironment,
+	updateEnv *localenv.LocalEnvironment,
+	updatePackage string,
+	manual, noValidateVersion bool,
+	strategy *storage.UpdateStrategy,
+	autoRollback *storage.AutoRollbackPolicy,
+	schedule *storage.MaintenanceWindow,
+	drain *storage.DrainPolicy,
+) error {
+	path, err := getUpgradePath(localEnv, updatePackage)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if len(path) == 1 {
+		return updateTrigger(localEnv, updateEnv, updatePackage, manual, noValidateVersion, strategy, autoRollback, schedule, drain)
+	}
+	if manual {
+		return trace.BadParameter("upgrade to %v requires %v intermediate upgrades "+
+			"and cannot be started in manual mode. Either upgrade to each intermediate "+
+			"release separately or retry without --manual.",
+			path[len(path)-1].Package, len(path)-1)
+	}
+	localEnv.PrintStep("Upgrading cluster through intermediate releases: %v", formatUpgradePath(path))
+	ctx := context.TODO()
+	for i, release := range path {
+		last := i == len(path)-1
+		localEnv.PrintStep("[%v/%v] Upgrading to %v", i+1, len(path), release.Package)
+		// The binary version only matches the operation plan of the final upgrade
+		key, err := triggerUpgrade(ctx, localEnv, updateEnv, release.Package.String(),
+			noValidateVersion || !last, strategy, autoRollback, schedule, drain)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		err = waitForUpgrade(ctx, localEnv, *key, fmt.Sprintf("[%v/%v]", i+1, len(path)))
+		if err != nil {
+			if trace.IsCompareFailed(err) && !last {
+				localEnv.Printf("Run 'gravity upgrade %v' to continue with the remaining "+
+					"releases once the operation has completed.\n", updatePackage)
+			}
+			return trace.Wrap(err)
+		}
+	}
+	localEnv.PrintStep("Cluster has been upgraded to %v", path[len(path)-1].Package)
+	return nil
+}
+
+// getUpgradePath returns the list of cluster images to upgrade the cluster through
+// in order to upgrade to the specified update package
+func getUpgradePath(env *localenv.LocalEnvironment, updatePackage string) ([]clusterupdate.Release, error) {
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if updatePackage == "" {
+		updatePackage = cluster.App.Package.Name
+	}
+	updateLoc, err := loc.MakeLocator(updatePackage)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	apps, err := env.AppServiceCluster()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	installedApp, err := apps.GetApp(cluster.App.Package)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	updateApp, err := apps.GetApp(*updateLoc)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	path, err := clusterupdate.GetUpgradePath(apps, *installedApp, *updateApp)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return path, nil
+}
+
+// triggerUpgrade starts the unattended upgrade operation to the specified
+// cluster image and returns the key of the created operation
+func triggerUpgrade(
+	ctx context.Context,
+	localEnv, updateEnv *localenv.LocalEnvironment,
+	updatePackage string,
+	noValidateVersion bool,
+	strategy *storage.UpdateStrategy,
+	autoRollback *storage.AutoRollbackPolicy,
+	schedule *storage.MaintenanceWindow,
+	drain *storage.DrainPolicy,
+) (*ops.SiteOperationKey, error) {
+	updater, err := newClusterUpdater(ctx, localEnv, updateEnv, updatePackage, false, noValidateVersion, strategy, autoRollback, schedule, drain)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer updater.Close()
+	plan, err := updater.GetPlan()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &ops.SiteOperationKey{
+		AccountID:   plan.AccountID,
+		SiteDomain:  plan.ClusterName,
+		OperationID: plan.OperationID,
+	}, nil
+}
+
+// waitForUpgrade blocks until the specified upgrade operation completes,
+// printing its progress with the given prefix.
+// Returns a CompareFailed error if the operation is waiting for canary approval
+func waitForUpgrade(ctx context.Context, env *localenv.LocalEnvironment, key ops.SiteOperationKey, prefix string) error {
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	var lastMessage string
+	ticker := time.NewTicker(defaults.WaitStatusInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			operation, err := operator.GetSiteOperation(key)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			progress, err := operator.GetSiteOperationProgress(key)
+			if err != nil && !trace.IsNotFound(err) {
+				return trace.Wrap(err)
+			}
+			if progress != nil && progress.Message != lastMessage {
+				lastMessage = progress.Message
+				env.Printf("%v %v\n", prefix, progress.Message)
+			}
+			switch {
+			case operation.IsCompleted():
+				return nil
+			case operation.IsRolledBack():
+				return trace.BadParameter("operation %v has been rolled back", operation)
+			case operation.IsFailed():
+				return trace.BadParameter("operation %v has failed", operation)
+			}
+			plan, err := operator.GetOperationPlan(key)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			if isAwaitingApproval(*plan) {
+				return trace.CompareFailed("operation %v is waiting for canary approval. "+
+					"Run 'gravity upgrade --approve' to upgrade the remaining nodes.", operation)
+			}
+		case <-ctx.Done():
+			return trace.Wrap(ctx.Err())
+		}
+	}
+}
+
+// isAwaitingApproval returns true if the upgraded canary node
+// of the specified operation plan is waiting for approval
+func isAwaitingApproval(plan storage.OperationPlan) bool {
+	phase, err := clusterupdate.FindCanaryApproval(plan)
+	if err != nil {
+		return false
+	}
+	return phase.IsFailed()
+}
+
+func formatUpgradePath(path []clusterupdate.Release) string {
+	versions := make([]string, 0, len(path))
+	for _, release := range path {
+		versions = append(versions, release.Package.Version)
+	}
+	return strings.Join(versions, " -> ")
+}
+
 func newClusterUpdater(
 	ctx context.Context,
 	localEnv, updateEnv *localenv.LocalEnvironment,
 	updatePackage string,
 	manual, noValidateVersion bool,
-) (updater, error) {
+	strategy *storage.UpdateStrategy,
+	autoRollback *storage.AutoRollbackPolicy,
+	schedule *storage.MaintenanceWindow,
+	drain *storage.DrainPolicy,
+) (*update.Updater, error) {
 	init := &clusterInitializer{
 		updatePackage: updatePackage,
 		unattended:    !manual,
+		strategy:      strategy,
+		autoRollback:  autoRollback,
+		schedule:      schedule,
+		drain:         drain,
 	}
 	updater, err := newUpdater(ctx, localEnv, updateEnv, init)
 	if err != nil {
@@ -96,6 +292,115 @@ func newClusterUpdater(
 	return updater, nil
 }
 
+// newUpdateStrategy returns the upgrade strategy specified on the command line
+// or nil to use the strategy from the cluster image manifest
+func newUpdateStrategy(strategy, canaryNode string, soakPeriod time.Duration, batchSize int, maxUnavailable string) (*storage.UpdateStrategy, error) {
+	if strategy == "" && canaryNode == "" && soakPeriod == 0 && batchSize == 0 && maxUnavailable == "" {
+		return nil, nil
+	}
+	var maxUnavailablePercent int
+	if maxUnavailable != "" {
+		var err error
+		maxUnavailablePercent, err = schema.ParseMaxUnavailable(maxUnavailable)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	return &storage.UpdateStrategy{
+		Type:                  strategy,
+		CanaryNode:            canaryNode,
+		SoakPeriod:            soakPeriod,
+		BatchSize:             batchSize,
+		MaxUnavailablePercent: maxUnavailablePercent,
+	}, nil
+}
+
+// newAutoRollbackPolicy returns the automatic rollback policy specified
+// on the command line or nil if automatic rollback is not enabled
+func newAutoRollbackPolicy(enabled bool, retries int) *storage.AutoRollbackPolicy {
+	if !enabled {
+		return nil
+	}
+	return &storage.AutoRollbackPolicy{Retries: retries}
+}
+
+// newMaintenanceWindow returns the maintenance window specified on the command line
+// or nil if the upgrade is not restricted to a maintenance window
+func newMaintenanceWindow(schedule string) (*storage.MaintenanceWindow, error) {
+	if schedule == "" {
+		return nil, nil
+	}
+	window, err := storage.ParseMaintenanceWindow(schedule)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return window, nil
+}
+
+// newDrainPolicy returns the node drain policy specified on the command line
+// or nil to use the default policy
+func newDrainPolicy(gracePeriod, timeout, forceAfter time.Duration, ignorePDBs, skipDaemonSets bool, skipNodes []string) (*storage.DrainPolicy, error) {
+	if gracePeriod == 0 && timeout == 0 && forceAfter == 0 && !ignorePDBs && skipDaemonSets && len(skipNodes) == 0 {
+		return nil, nil
+	}
+	policy := storage.DrainPolicy{
+		GracePeriod:                gracePeriod,
+		Timeout:                    timeout,
+		ForceAfter:                 forceAfter,
+		IgnorePodDisruptionBudgets: ignorePDBs,
+		EvictDaemonSets:            !skipDaemonSets,
+		SkipNodes:                  skipNodes,
+	}
+	if err := policy.Check(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &policy, nil
+}
+
+// approveUpdate approves the upgraded canary node of the active update operation.
+// Unless manual is set, the operation is resumed to upgrade the remaining nodes
+func approveUpdate(localEnv *localenv.LocalEnvironment, environ LocalEnvironmentFactory, manual, noValidateVersion bool) error {
+	operation, err := getActiveOperation(localEnv, environ, "")
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if operation.Type != ops.OperationUpdate {
+		return trace.BadParameter("active operation %v is not an upgrade", operation)
+	}
+	updateEnv, err := environ.NewUpdateEnv()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updateEnv.Close()
+	updater, err := getClusterUpdater(localEnv, updateEnv, *operation, noValidateVersion)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updater.Close()
+	plan, err := updater.GetPlan()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	phase, err := clusterupdate.FindCanaryApproval(*plan)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if phase.IsCompleted() {
+		return trace.AlreadyExists("canary node has already been approved")
+	}
+	ctx := context.TODO()
+	err = updater.SetPhase(ctx, phase.ID, storage.OperationPhaseStateCompleted)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	localEnv.Println("Canary node has been approved.")
+	if manual {
+		localEnv.Println("Run 'gravity plan resume' to upgrade the remaining nodes.")
+		return nil
+	}
+	return trace.Wrap(updater.Run(ctx))
+}
+
 func executeUpdatePhase(env *localenv.LocalEnvironment, environ LocalEnvironmentFactory, params PhaseParams, operation ops.SiteOperation) error {
 	updateEnv, err := environ.NewUpdateEnv()
 	if err != nil {
@@ -205,15 +510,24 @@ func (r *clusterInitializer) validatePreconditions(localEnv *localenv.LocalEnvir
 	if err != nil {
 		return trace.Wrap(err)
 	}
+	if base, ok := updateApp.PackageEnvelope.RuntimeLabels[pack.DeltaBaseLabel]; ok && base != cluster.App.Package.String() {
+		return trace.BadParameter("%v has been uploaded from a delta image built against %v "+
+			"and cannot be applied to the cluster running %v.",
+			updateApp.Package, base, cluster.App.Package)
+	}
 	r.updateLoc = updateApp.Package
 	return nil
 }
 
 func (r clusterInitializer) newOperation(operator ops.Operator, cluster ops.Site) (*ops.SiteOperationKey, error) {
 	return operator.CreateSiteAppUpdateOperation(context.TODO(), ops.CreateSiteAppUpdateOperationRequest{
-		AccountID:  cluster.AccountID,
-		SiteDomain: cluster.Domain,
-		App:        r.updateLoc.String(),
+		AccountID:    cluster.AccountID,
+		SiteDomain:   cluster.Domain,
+		App:          r.updateLoc.String(),
+		Strategy:     r.strategy,
+		AutoRollback: r.autoRollback,
+		Schedule:     r.schedule,
+		Drain:        r.drain,
 	})
 }
 
@@ -273,6 +587,10 @@ type clusterInitializer struct {
 	updateLoc     loc.Locator
 	updatePackage string
 	unattended    bool
+	strategy      *storage.UpdateStrategy
+	autoRollback  *storage.AutoRollbackPolicy
+	schedule      *storage.MaintenanceWindow
+	drain         *storage.DrainPolicy
 }
 
 const (
@@ -296,6 +614,24 @@ Installed runtime version (%q) is too old and cannot be updated by this package.
 Please update this installation to a minimum required runtime version (%q) before using this update.`,
 			existingGravityPackage.Version, defaults.BaseUpdateVersion)
 	}
+	gravityPackage, err := manifest.Dependencies.ByName(constants.GravityPackage)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	existingVersion, err := existingGravityPackage.SemVer()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	updateVersion, err := gravityPackage.SemVer()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if !clusterupdate.IsDirectUpgrade(*existingVersion, *updateVersion) {
+		return trace.BadParameter(`
+Installed runtime version (%q) cannot be upgraded to runtime version (%q) directly.
+Use 'gravity upgrade' to upgrade the cluster through the intermediate releases.`,
+			existingGravityPackage.Version, gravityPackage.Version)
+	}
 	return nil
 }
 
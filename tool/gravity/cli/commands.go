@@ -50,6 +50,8 @@ type Application struct {
 	ProfileEndpoint *string
 	// ProfileTo is the location for periodic profiling snapshots
 	ProfileTo *string
+	// MetricsAddr is the address to serve the Prometheus metrics on
+	MetricsAddr *string
 	// UserLogFile is the path to the user-friendly log file
 	UserLogFile *string
 	// SystemLogFile is the path to the system log file
@@ -66,6 +68,14 @@ type Application struct {
 	LeaveCmd LeaveCmd
 	// RemoveCmd removes the specified node from the cluster
 	RemoveCmd RemoveCmd
+	// NodeCmd manages cluster nodes
+	NodeCmd NodeCmd
+	// NodePromoteCmd promotes a regular node to master
+	NodePromoteCmd NodePromoteCmd
+	// NodeDemoteCmd demotes a master to a regular node
+	NodeDemoteCmd NodeDemoteCmd
+	// NodeReplaceCmd replaces a node with a new one
+	NodeReplaceCmd NodeReplaceCmd
 	// PlanCmd manages an operation plan
 	PlanCmd PlanCmd
 	// UpdatePlanInitCmd creates a new update operation plan
@@ -100,11 +110,25 @@ type Application struct {
 	UpgradeCmd UpgradeCmd
 	// StatusCmd displays cluster status
 	StatusCmd StatusCmd
+	// StatusClusterCmd displays overall cluster status
+	StatusClusterCmd StatusClusterCmd
+	// StatusHistoryCmd displays the cluster health history
+	StatusHistoryCmd StatusHistoryCmd
+	// StatusSNMPAgentCmd runs the SNMP sub-agent exposing the cluster health
+	StatusSNMPAgentCmd StatusSNMPAgentCmd
 	// StatusResetCmd resets the cluster to active state
 	StatusResetCmd StatusResetCmd
-	// BackupCmd launches app backup hook
+	// BackupCmd combines the cluster backup subcommands
 	BackupCmd BackupCmd
-	// RestoreCmd launches app restore hook
+	// BackupHookCmd launches app backup hook
+	BackupHookCmd BackupHookCmd
+	// BackupScheduleCmd configures the scheduled cluster backups
+	BackupScheduleCmd BackupScheduleCmd
+	// BackupUnpackCmd decrypts and extracts the uploaded backup
+	BackupUnpackCmd BackupUnpackCmd
+	// BackupVerifyCmd verifies that the backup is restorable
+	BackupVerifyCmd BackupVerifyCmd
+	// RestoreCmd launches app restore hook or restores the cluster from a backup
 	RestoreCmd RestoreCmd
 	// CheckCmd checks that the host satisfies app manifest requirements
 	CheckCmd CheckCmd
@@ -132,6 +156,10 @@ type Application struct {
 	AppIndexCmd AppIndexCmd
 	// AppImportCmd imports an app into cluster
 	AppImportCmd AppImportCmd
+	// CatalogCmd combines subcommands for the application catalog
+	CatalogCmd CatalogCmd
+	// CatalogSyncCmd mirrors the charts of a Helm repository into the catalog
+	CatalogSyncCmd CatalogSyncCmd
 	// AppExportCmd exports specified app into registry
 	AppExportCmd AppExportCmd
 	// AppDeleteCmd deletes the specified app
@@ -142,6 +170,12 @@ type Application struct {
 	AppPackageUninstallCmd AppPackageUninstallCmd
 	// AppStatusCmd output app status
 	AppStatusCmd AppStatusCmd
+	// AppScanCmd scans application images for vulnerabilities
+	AppScanCmd AppScanCmd
+	// AppSBOMCmd outputs the application software bill of materials
+	AppSBOMCmd AppSBOMCmd
+	// AppVerifyCmd verifies the application build provenance
+	AppVerifyCmd AppVerifyCmd
 	// AppPullCmd pulls app from specified cluster
 	AppPullCmd AppPullCmd
 	// AppPushCmd pushes app to specified cluster
@@ -198,6 +232,34 @@ type Application struct {
 	UsersInviteCmd UsersInviteCmd
 	// UsersResetCmd generates a user password reset link
 	UsersResetCmd UsersResetCmd
+	// TokenCmd combines cluster join token subcommands
+	TokenCmd TokenCmd
+	// TokenCreateCmd creates a new short-lived join token
+	TokenCreateCmd TokenCreateCmd
+	// TokenListCmd lists active short-lived join tokens
+	TokenListCmd TokenListCmd
+	// TokenRemoveCmd removes a short-lived join token
+	TokenRemoveCmd TokenRemoveCmd
+	// ClusterCmd combines whole cluster subcommands
+	ClusterCmd ClusterCmd
+	// ClusterStopCmd gracefully stops the cluster
+	ClusterStopCmd ClusterStopCmd
+	// ClusterStartCmd starts the stopped cluster
+	ClusterStartCmd ClusterStartCmd
+	// ClusterPromoteCmd promotes the standby cluster
+	ClusterPromoteCmd ClusterPromoteCmd
+	// RosterCmd combines cluster roster subcommands
+	RosterCmd RosterCmd
+	// RosterStatusCmd shows the cluster roster reconciliation status
+	RosterStatusCmd RosterStatusCmd
+	// RosterApproveCmd approves pending cluster roster changes
+	RosterApproveCmd RosterApproveCmd
+	// OperationCmd combines cluster operation subcommands
+	OperationCmd OperationCmd
+	// OperationApproveCmd approves an operation that requires approval
+	OperationApproveCmd OperationApproveCmd
+	// AuditCmd displays the operator API audit records
+	AuditCmd AuditCmd
 	// APIKeyCmd combines subcommands for API tokens
 	APIKeyCmd APIKeyCmd
 	// APIKeyCreateCmd creates a new token
@@ -238,14 +300,20 @@ type Application struct {
 	RPCAgentRunCmd RPCAgentRunCmd
 	// SystemCmd combines system subcommands
 	SystemCmd SystemCmd
-	// SystemRotateCertsCmd renews cluster certificates on local node
+	// SystemRotateCertsCmd rotates cluster certificates
 	SystemRotateCertsCmd SystemRotateCertsCmd
+	// SystemRotateSecretsKeyCmd rotates the key that encrypts secrets at rest
+	SystemRotateSecretsKeyCmd SystemRotateSecretsKeyCmd
+	// SystemChangeCIDRCmd changes the pod and service subnets of the cluster
+	SystemChangeCIDRCmd SystemChangeCIDRCmd
 	// SystemExportCACmd exports cluster CA
 	SystemExportCACmd SystemExportCACmd
 	// SystemUninstallCmd uninstalls all gravity services from local node
 	SystemUninstallCmd SystemUninstallCmd
 	// SystemPullUpdatesCmd pulls updates for system packages
 	SystemPullUpdatesCmd SystemPullUpdatesCmd
+	// SystemStageCmd downloads system packages ahead of the upgrade
+	SystemStageCmd SystemStageCmd
 	// SystemUpdateCmd updates system packages
 	SystemUpdateCmd SystemUpdateCmd
 	// SystemReinstallCmd reinstalls specified system package
@@ -270,6 +338,26 @@ type Application struct {
 	SystemReportCmd SystemReportCmd
 	// SystemStateDirCmd shows local state directory
 	SystemStateDirCmd SystemStateDirCmd
+	// SystemExportStateCmd exports cluster state into an archive
+	SystemExportStateCmd SystemExportStateCmd
+	// SystemImportStateCmd imports cluster state from an archive
+	SystemImportStateCmd SystemImportStateCmd
+	// SystemStateChangesCmd lists the recorded changes to the cluster state
+	SystemStateChangesCmd SystemStateChangesCmd
+	// SystemRestoreStateCmd restores cluster state to a point in time
+	SystemRestoreStateCmd SystemRestoreStateCmd
+	// SystemEtcdCmd combines etcd related subcommands
+	SystemEtcdCmd SystemEtcdCmd
+	// SystemEtcdRestoreCmd restores etcd on all master nodes from a snapshot
+	SystemEtcdRestoreCmd SystemEtcdRestoreCmd
+	// SystemCheckStateCmd validates consistency of the cluster state
+	SystemCheckStateCmd SystemCheckStateCmd
+	// SystemEncryptionCmd combines local state encryption subcommands
+	SystemEncryptionCmd SystemEncryptionCmd
+	// SystemEncryptionEnableCmd enables encryption of local state
+	SystemEncryptionEnableCmd SystemEncryptionEnableCmd
+	// SystemEncryptionStatusCmd displays local state encryption status
+	SystemEncryptionStatusCmd SystemEncryptionStatusCmd
 	// SystemDevicemapperCmd combines devicemapper related subcommands
 	SystemDevicemapperCmd SystemDevicemapperCmd
 	// SystemDevicemapperMountCmd configures devicemapper environment
@@ -284,6 +372,8 @@ type Application struct {
 	SystemStreamRuntimeJournalCmd SystemStreamRuntimeJournalCmd
 	// SystemGCJournalCmd cleans up stale journal files
 	SystemGCJournalCmd SystemGCJournalCmd
+	// SystemNetworkMonitorCmd runs the network monitor
+	SystemNetworkMonitorCmd SystemNetworkMonitorCmd
 	// SystemGCPackageCmd removes unused packages
 	SystemGCPackageCmd SystemGCPackageCmd
 	// SystemGCRegistryCmd removes unused docker images
@@ -301,6 +391,10 @@ type Application struct {
 	ShellCmd ShellCmd
 	// PlanetStatusCmd displays planet status
 	PlanetStatusCmd PlanetStatusCmd
+	// PlanetStopCmd stops planet on this node
+	PlanetStopCmd PlanetStopCmd
+	// PlanetStartCmd starts planet on this node
+	PlanetStartCmd PlanetStartCmd
 	// EnterCmd enters planet container
 	EnterCmd EnterCmd
 	// ResourceCmd combines resource related subcommands
@@ -313,6 +407,14 @@ type Application struct {
 	ResourceGetCmd ResourceGetCmd
 	// TopCmd displays cluster metrics in terminal
 	TopCmd TopCmd
+	// MirrorCmd combines air-gapped mirror related subcommands
+	MirrorCmd MirrorCmd
+	// MirrorExportCmd exports cluster image contents into a mirror directory
+	MirrorExportCmd MirrorExportCmd
+	// MirrorServeCmd serves a mirror directory
+	MirrorServeCmd MirrorServeCmd
+	// ExpandCmd provisions new nodes and adds them to the cluster
+	ExpandCmd ExpandCmd
 }
 
 // VersionCmd displays the binary version
@@ -360,6 +462,11 @@ type InstallCmd struct {
 	DockerDevice *string
 	// SystemDevice is device to use for system data
 	SystemDevice *string
+	// EtcdDevice is device to use for etcd data
+	EtcdDevice *string
+	// AutoPartition enables automatic placement of system data,
+	// Docker storage and etcd data on unused devices
+	AutoPartition *bool
 	// Mounts is a list of additional app mounts
 	Mounts *configure.KeyVal
 	// PodCIDR overrides default pod network
@@ -368,6 +475,8 @@ type InstallCmd struct {
 	ServiceCIDR *string
 	// VxlanPort overrides default overlay network port
 	VxlanPort *int
+	// NetworkEncryption specifies the encryption mode of the pod traffic between the nodes
+	NetworkEncryption *string
 	// DNSListenAddrs specifies listen addresses for planet DNS.
 	DNSListenAddrs *[]net.IP
 	// DNSPort overrides default DNS port for planet DNS.
@@ -382,12 +491,68 @@ type InstallCmd struct {
 	ServiceGID *string
 	// GCENodeTags lists additional node tags on GCE
 	GCENodeTags *[]string
+	// GCESubnetwork is the GCE subnetwork for internal load balancers
+	GCESubnetwork *string
+	// GCEStateDisk is the device name of the GCE persistent disk
+	// to use for the system state directory
+	GCEStateDisk *string
+	// AzureVnet is the name of the Azure virtual network of the cluster
+	AzureVnet *string
+	// AzureVnetResourceGroup is the resource group of the Azure virtual network
+	AzureVnetResourceGroup *string
+	// AzureSubnet is the name of the Azure subnet of the cluster
+	AzureSubnet *string
+	// AzureSecurityGroup is the name of the Azure network security group
+	AzureSecurityGroup *string
+	// AzureRouteTable is the name of the Azure route table for pod routes
+	AzureRouteTable *string
+	// AzureAvailabilitySet is the name of the Azure availability set with master nodes
+	AzureAvailabilitySet *string
+	// VSphereConfig is the path to the vSphere environment configuration
+	VSphereConfig *string
 	// DNSHosts is a list of DNS host overrides
 	DNSHosts *[]string
 	// DNSZones is a list of DNS zone overrides
 	DNSZones *[]string
+	// DNSUpstreams is a list of upstream nameservers for the cluster DNS
+	DNSUpstreams *[]string
 	// Remote specifies whether the host should not be part of the cluster
 	Remote *bool
+	// Labels is a list of custom labels for the node
+	Labels *[]string
+	// Taints is a list of custom taints for the node
+	Taints *[]string
+	// HTTPProxy specifies the proxy for outbound HTTP connections
+	HTTPProxy *string
+	// HTTPSProxy specifies the proxy for outbound HTTPS connections
+	HTTPSProxy *string
+	// NoProxy specifies the list of destinations to exclude from proxying
+	NoProxy *string
+	// Provision specifies whether to provision cluster nodes
+	// with the cloud provider integration
+	Provision *bool
+	// ProvisionSpec is the path to the spec describing the nodes to provision
+	ProvisionSpec *string
+	// Wipe removes the remnants of a previous cluster installation
+	// from the host before installing
+	Wipe *bool
+	// Demo installs the cluster in demo mode with relaxed requirements
+	Demo *bool
+	// FIPS installs the cluster in FIPS 140-2 mode
+	FIPS *bool
+	// Hardening specifies the hardened configuration profile
+	Hardening *string
+	// NetworkPolicy installs the network policy baseline
+	NetworkPolicy *bool
+	// CACert is the path to the certificate of the certificate authority
+	// to issue the cluster certificates
+	CACert *string
+	// CAKey is the path to the private key of the certificate authority
+	// to issue the cluster certificates
+	CAKey *string
+	// BaseImages lists the paths to the tarballs of the cluster images
+	// the installed layered cluster image is built on top of
+	BaseImages *[]string
 	// FromService specifies whether this process runs in service mode.
 	//
 	// The installer runs the main installer code in service mode, while
@@ -411,6 +576,11 @@ type JoinCmd struct {
 	DockerDevice *string
 	// SystemDevice is device to use for system data
 	SystemDevice *string
+	// EtcdDevice is device to use for etcd data
+	EtcdDevice *string
+	// AutoPartition enables automatic placement of system data,
+	// Docker storage and etcd data on unused devices
+	AutoPartition *bool
 	// ServerAddr is RPC server address
 	ServerAddr *string
 	// Mounts is additional app mounts
@@ -419,6 +589,12 @@ type JoinCmd struct {
 	CloudProvider *string
 	// OperationID is the ID of the operation created via UI
 	OperationID *string
+	// Labels is a list of custom labels for the node
+	Labels *[]string
+	// Taints is a list of custom taints for the node
+	Taints *[]string
+	// NodePool is the name of the node pool to join
+	NodePool *string
 	// FromService specifies whether this process runs in service mode.
 	//
 	// The agent runs the install/join code in service mode, while
@@ -472,6 +648,58 @@ type RemoveCmd struct {
 	Force *bool
 	// Confirm suppresses confirmation prompt
 	Confirm *bool
+	// CheckOnly only reports issues that block the node removal
+	CheckOnly *bool
+}
+
+// NodeCmd manages cluster nodes
+type NodeCmd struct {
+	*kingpin.CmdClause
+}
+
+// NodePromoteCmd promotes a regular node to master
+type NodePromoteCmd struct {
+	*kingpin.CmdClause
+	// Node is the node to promote
+	Node *string
+	// Manual is whether the operation is not executed automatically
+	Manual *bool
+	// Confirm suppresses confirmation prompt
+	Confirm *bool
+}
+
+// NodeDemoteCmd demotes a master to a regular node
+type NodeDemoteCmd struct {
+	*kingpin.CmdClause
+	// Node is the node to demote
+	Node *string
+	// Manual is whether the operation is not executed automatically
+	Manual *bool
+	// Confirm suppresses confirmation prompt
+	Confirm *bool
+}
+
+// NodeReplaceCmd replaces a node with a new one
+type NodeReplaceCmd struct {
+	*kingpin.CmdClause
+	// Node is the node to replace
+	Node *string
+	// With is the advertise address of the replacement node
+	With *string
+	// SSHUser is the SSH user to connect to the replacement node with
+	SSHUser *string
+	// SSHKey is the path to the SSH private key to connect to the replacement node with
+	SSHKey *string
+	// SSHPort is the SSH port of the replacement node
+	SSHPort *int
+	// SSHHostKey is the SSH host key of the replacement node
+	SSHHostKey *string
+	// Force forces removal of the replaced node if it is offline
+	Force *bool
+	// Manual is whether the operation is not executed automatically
+	Manual *bool
+	// Confirm suppresses confirmation prompt
+	Confirm *bool
 }
 
 // ResumeCmd resumes active operation
@@ -646,6 +874,41 @@ type UpgradeCmd struct {
 	Resume *bool
 	// SkipVersionCheck suppresses version mismatch errors
 	SkipVersionCheck *bool
+	// Strategy specifies the upgrade strategy
+	Strategy *string
+	// CanaryNode specifies the node to upgrade first with the canary strategy
+	CanaryNode *string
+	// SoakPeriod specifies the duration to observe the canary node before proceeding
+	SoakPeriod *time.Duration
+	// Approve approves the upgraded canary node and resumes the upgrade
+	Approve *bool
+	// AutoRollback enables automatic rollback of a failed upgrade
+	AutoRollback *bool
+	// AutoRollbackRetries specifies how many times a failed upgrade is resumed
+	// before it is rolled back
+	AutoRollbackRetries *int
+	// BatchSize specifies the number of regular nodes to upgrade in parallel
+	BatchSize *int
+	// MaxUnavailable specifies the percentage of regular nodes to upgrade in parallel
+	MaxUnavailable *string
+	// Schedule specifies the maintenance window for node-disruptive upgrade steps
+	Schedule *string
+	// DrainGracePeriod overrides the termination grace period of evicted pods
+	DrainGracePeriod *time.Duration
+	// DrainTimeout specifies the total time to drain a single node
+	DrainTimeout *time.Duration
+	// DrainForceAfter specifies the time after which remaining pods are deleted
+	DrainForceAfter *time.Duration
+	// DrainIgnorePDB specifies whether to ignore pod disruption budgets when draining
+	DrainIgnorePDB *bool
+	// DrainSkipDaemonSets specifies whether to leave daemon set pods on drained nodes
+	DrainSkipDaemonSets *bool
+	// DrainSkipNodes lists the nodes not to drain
+	DrainSkipNodes *[]string
+	// PlanOnly reports the impact of the upgrade without starting it
+	PlanOnly *bool
+	// Stage downloads the upgrade packages to the nodes without starting the upgrade
+	Stage *bool
 }
 
 // StatusCmd displays cluster status
@@ -663,23 +926,94 @@ type StatusCmd struct {
 	Output *constants.Format
 }
 
+// StatusClusterCmd displays overall cluster status
+type StatusClusterCmd struct {
+	*kingpin.CmdClause
+}
+
+// StatusHistoryCmd displays the cluster health history
+type StatusHistoryCmd struct {
+	*kingpin.CmdClause
+	// Since is the duration of the displayed history
+	Since *time.Duration
+}
+
+// StatusSNMPAgentCmd runs the SNMP sub-agent exposing the cluster health
+type StatusSNMPAgentCmd struct {
+	*kingpin.CmdClause
+	// Addr is the address of the AgentX master agent
+	Addr *string
+	// RootOID is the object identifier of the cluster health subtree
+	RootOID *string
+	// Interval is how often the cluster status is refreshed
+	Interval *time.Duration
+}
+
 // StatusResetCmd resets cluster to active state
 type StatusResetCmd struct {
 	*kingpin.CmdClause
 }
 
-// BackupCmd launches app backup hook
+// BackupCmd combines the cluster backup subcommands
 type BackupCmd struct {
 	*kingpin.CmdClause
+}
+
+// BackupHookCmd launches app backup hook
+type BackupHookCmd struct {
+	*kingpin.CmdClause
 	// Tarball is backup tarball name
 	Tarball *string
 	// Timeout is operation timeout
 	Timeout *time.Duration
 	// Follow tails operation logs
 	Follow *bool
+	// App is the name of the application release to back up
+	// separately from the rest of the cluster
+	App *string
+}
+
+// BackupScheduleCmd configures the scheduled backups of the cluster
+// etcd database and state
+type BackupScheduleCmd struct {
+	*kingpin.CmdClause
+	// Schedule is the backup schedule in cron format
+	Schedule *string
+	// Directory is the directory on the master node to store the backups in
+	Directory *string
+	// Retain is the number of the latest backups to keep
+	Retain *int
+	// Remove removes the backup schedule
+	Remove *bool
+}
+
+// BackupUnpackCmd decrypts and extracts the backup archive
+// uploaded to the off-site destination
+type BackupUnpackCmd struct {
+	*kingpin.CmdClause
+	// Archive is the path to the backup archive
+	Archive *string
+	// Directory is the directory to extract the backup into
+	Directory *string
+	// KeyFile is the path to the file with the encryption key
+	KeyFile *string
+}
+
+// BackupVerifyCmd verifies the backup and optionally
+// restores it into a temporary data directory
+type BackupVerifyCmd struct {
+	*kingpin.CmdClause
+	// Backup is the path to the backup directory or archive
+	Backup *string
+	// KeyFile is the path to the file with the encryption key of the archive
+	KeyFile *string
+	// TestRestore specifies whether to restore the backup
+	// into a temporary data directory
+	TestRestore *bool
 }
 
-// RestoreCmd launches app restore hook
+// RestoreCmd launches app restore hook or restores
+// the cluster from a scheduled backup
 type RestoreCmd struct {
 	*kingpin.CmdClause
 	// Tarball is tarball to restore from
@@ -688,6 +1022,15 @@ type RestoreCmd struct {
 	Timeout *time.Duration
 	// Follow tails operation logs
 	Follow *bool
+	// From is the scheduled backup directory to restore the cluster from
+	From *string
+	// Manual specifies whether the restore operation is not started automatically
+	Manual *bool
+	// Confirm suppresses the confirmation prompt
+	Confirm *bool
+	// App is the name of the application release to restore
+	// from its backup without modifying the rest of the cluster
+	App *string
 }
 
 // CheckCmd checks that the host satisfies app manifest requirements
@@ -715,6 +1058,8 @@ type AppInstallCmd struct {
 	Name *string
 	// Namespace is a namespace to install release into.
 	Namespace *string
+	// Flavor selects the values files of the charts declared in the image.
+	Flavor *string
 	// Set is a list of values set on the CLI.
 	Set *[]string
 	// Values is a list of YAML files with values.
@@ -743,6 +1088,8 @@ type AppUpgradeCmd struct {
 	Release *string
 	// Image specifies the application image to upgrade to.
 	Image *string
+	// Flavor selects the values files of the charts declared in the image.
+	Flavor *string
 	// Set is a list of values set on the CLI.
 	Set *[]string
 	// Values is a list of YAML files with values.
@@ -806,6 +1153,20 @@ type AppSearchCmd struct {
 	All *bool
 }
 
+// CatalogCmd combines subcommands for the application catalog.
+type CatalogCmd struct {
+	*kingpin.CmdClause
+}
+
+// CatalogSyncCmd mirrors the charts of a Helm repository into the catalog.
+type CatalogSyncCmd struct {
+	*kingpin.CmdClause
+	// Repository is the URL of the Helm repository to mirror.
+	Repository *string
+	// Charts lists the names of the charts to mirror.
+	Charts *[]string
+}
+
 // AppRebuildIndexCmd rebuilds Helm chart repository index.
 type AppRebuildIndexCmd struct {
 	*kingpin.CmdClause
@@ -906,6 +1267,47 @@ type AppStatusCmd struct {
 	OpsCenterURL *string
 }
 
+// AppScanCmd scans application images for vulnerabilities
+type AppScanCmd struct {
+	*kingpin.CmdClause
+	// Locator is app locator
+	Locator *loc.Locator
+	// OpsCenterURL is the optional Gravity Hub URL
+	OpsCenterURL *string
+	// ScannerPath is the path to the vulnerability scanner binary
+	ScannerPath *string
+	// Threshold is the lowest vulnerability severity that fails the scan
+	Threshold *string
+	// IgnoreUnfixed ignores vulnerabilities without an available fix
+	IgnoreUnfixed *bool
+	// Format is the output format
+	Format *constants.Format
+}
+
+// AppSBOMCmd outputs the application software bill of materials
+type AppSBOMCmd struct {
+	*kingpin.CmdClause
+	// Locator is app locator
+	Locator *loc.Locator
+	// OpsCenterURL is the optional Gravity Hub URL
+	OpsCenterURL *string
+	// Format is the SBOM format
+	Format *string
+}
+
+// AppVerifyCmd verifies the application build provenance
+type AppVerifyCmd struct {
+	*kingpin.CmdClause
+	// Locator is app locator
+	Locator *loc.Locator
+	// Keys lists the paths to the trusted public keys
+	Keys *[]string
+	// OpsCenterURL is the optional Gravity Hub URL
+	OpsCenterURL *string
+	// InstallerDir is the optional unpacked installer directory
+	InstallerDir *string
+}
+
 // AppPullCmd pulls app from specified cluster
 type AppPullCmd struct {
 	*kingpin.CmdClause
@@ -992,6 +1394,12 @@ type OpsConnectCmd struct {
 	Username *string
 	// Password is agent password
 	Password *string
+	// SSO enables single sign-on login
+	SSO *bool
+	// Connector is the name of the auth connector to log in with
+	Connector *string
+	// TTL is the validity period of the certificates issued with single sign-on
+	TTL *time.Duration
 }
 
 // OpsDisconnectCmd logs out of specified cluster
@@ -1204,6 +1612,105 @@ type UsersResetCmd struct {
 	TTL *time.Duration
 }
 
+// TokenCmd combines cluster join token subcommands
+type TokenCmd struct {
+	*kingpin.CmdClause
+}
+
+// TokenCreateCmd creates a new short-lived join token
+type TokenCreateCmd struct {
+	*kingpin.CmdClause
+	// TTL is the token TTL
+	TTL *time.Duration
+	// Roles limits the node roles that can join with the token
+	Roles *[]string
+	// Uses is the maximum number of nodes that can join with the token
+	Uses *int
+}
+
+// TokenListCmd lists active short-lived join tokens
+type TokenListCmd struct {
+	*kingpin.CmdClause
+}
+
+// TokenRemoveCmd removes a short-lived join token
+type TokenRemoveCmd struct {
+	*kingpin.CmdClause
+	// Token is the token to remove
+	Token *string
+}
+
+// ClusterCmd combines whole cluster subcommands
+type ClusterCmd struct {
+	*kingpin.CmdClause
+}
+
+// ClusterStopCmd gracefully stops the cluster
+type ClusterStopCmd struct {
+	*kingpin.CmdClause
+	// Confirm suppresses confirmation prompt
+	Confirm *bool
+}
+
+// ClusterStartCmd starts the stopped cluster
+type ClusterStartCmd struct {
+	*kingpin.CmdClause
+}
+
+// ClusterPromoteCmd promotes the standby cluster to take over
+// from the primary cluster
+type ClusterPromoteCmd struct {
+	*kingpin.CmdClause
+	// Manual specifies whether the restore operation is executed manually
+	Manual *bool
+	// Confirm suppresses confirmation prompt
+	Confirm *bool
+}
+
+// RosterCmd combines cluster roster subcommands
+type RosterCmd struct {
+	*kingpin.CmdClause
+}
+
+// RosterStatusCmd shows the cluster roster reconciliation status
+type RosterStatusCmd struct {
+	*kingpin.CmdClause
+}
+
+// RosterApproveCmd approves pending cluster roster changes
+type RosterApproveCmd struct {
+	*kingpin.CmdClause
+	// ChangesID identifies the changes to approve
+	ChangesID *string
+}
+
+// OperationCmd combines cluster operation subcommands
+type OperationCmd struct {
+	*kingpin.CmdClause
+}
+
+// OperationApproveCmd approves an operation that requires approval
+type OperationApproveCmd struct {
+	*kingpin.CmdClause
+	// OperationID is the ID of the operation to approve
+	OperationID *string
+}
+
+// AuditCmd displays the operator API audit records
+type AuditCmd struct {
+	*kingpin.CmdClause
+	// User displays only the requests of the specified user
+	User *string
+	// Since displays only the requests made within the specified duration
+	Since *time.Duration
+	// Failed displays only the failed requests
+	Failed *bool
+	// Limit is the maximum number of the most recent requests to display
+	Limit *int
+	// Output is output format
+	Output *constants.Format
+}
+
 // APIKeyCmd combines subcommands for API tokens
 type APIKeyCmd struct {
 	*kingpin.CmdClause
@@ -1344,17 +1851,47 @@ type SystemCmd struct {
 	*kingpin.CmdClause
 }
 
-// SystemRotateCertsCmd renews cluster certificates on local node
+// SystemRotateCertsCmd rotates cluster certificates
 type SystemRotateCertsCmd struct {
 	*kingpin.CmdClause
 	// ClusterName is local cluster name
 	ClusterName *string
+	// CA specifies whether to rotate the cluster certificate authority
+	CA *bool
+	// Manual is whether the operation is not executed automatically
+	Manual *bool
+	// Confirm suppresses confirmation prompt
+	Confirm *bool
+	// Local specifies whether to renew certificates on local node only
+	Local *bool
 	// ValidFor is validity period for new certificates
 	ValidFor *time.Duration
 	// CAPath is CA to use
 	CAPath *string
 }
 
+// SystemRotateSecretsKeyCmd rotates the key that encrypts secrets at rest
+type SystemRotateSecretsKeyCmd struct {
+	*kingpin.CmdClause
+	// Manual is whether the operation is not executed automatically
+	Manual *bool
+	// Confirm suppresses confirmation prompt
+	Confirm *bool
+}
+
+// SystemChangeCIDRCmd changes the pod and service subnets of the cluster
+type SystemChangeCIDRCmd struct {
+	*kingpin.CmdClause
+	// PodCIDR is the new pod subnet
+	PodCIDR *string
+	// ServiceCIDR is the new service subnet
+	ServiceCIDR *string
+	// Manual is whether the operation is not executed automatically
+	Manual *bool
+	// Confirm suppresses confirmation prompt
+	Confirm *bool
+}
+
 // SystemExportCACmd exports cluster CA
 type SystemExportCACmd struct {
 	*kingpin.CmdClause
@@ -1380,6 +1917,13 @@ type SystemPullUpdatesCmd struct {
 	RuntimePackage *loc.Locator
 }
 
+// SystemStageCmd downloads system packages ahead of the upgrade
+type SystemStageCmd struct {
+	*kingpin.CmdClause
+	// Packages lists the packages to download
+	Packages *[]string
+}
+
 // SystemUpdateCmd updates system packages
 type SystemUpdateCmd struct {
 	*kingpin.CmdClause
@@ -1498,6 +2042,106 @@ type SystemStateDirCmd struct {
 	*kingpin.CmdClause
 }
 
+// SystemExportStateCmd exports cluster state into an archive
+type SystemExportStateCmd struct {
+	*kingpin.CmdClause
+	// Output is the path to the archive, "-" for stdout
+	Output *string
+	// Backend is the type of the backend to export the state from
+	Backend *string
+	// BackendParams is the backend-specific configuration
+	BackendParams *map[string]string
+}
+
+// SystemImportStateCmd imports cluster state from an archive
+type SystemImportStateCmd struct {
+	*kingpin.CmdClause
+	// Path is the path to the archive
+	Path *string
+	// Backend is the type of the backend to import the state into
+	Backend *string
+	// BackendParams is the backend-specific configuration
+	BackendParams *map[string]string
+	// Confirmed suppresses confirmation prompt
+	Confirmed *bool
+}
+
+// SystemStateChangesCmd lists the recorded changes to the cluster state
+type SystemStateChangesCmd struct {
+	*kingpin.CmdClause
+	// Since is the point in time to list the changes after
+	Since *string
+	// Backend is the type of the backend with the changelog
+	Backend *string
+	// BackendParams is the backend-specific configuration
+	BackendParams *map[string]string
+}
+
+// SystemRestoreStateCmd restores cluster state to a point in time
+type SystemRestoreStateCmd struct {
+	*kingpin.CmdClause
+	// To is the point in time to restore the state to
+	To *string
+	// Backend is the type of the backend to restore the state in
+	Backend *string
+	// BackendParams is the backend-specific configuration
+	BackendParams *map[string]string
+	// Confirmed suppresses confirmation prompt
+	Confirmed *bool
+}
+
+// SystemCheckStateCmd validates consistency of the cluster state
+type SystemCheckStateCmd struct {
+	*kingpin.CmdClause
+	// Fix enables repair of the found inconsistencies
+	Fix *bool
+	// Backend is the type of the backend to check
+	Backend *string
+	// BackendParams is the backend-specific configuration
+	BackendParams *map[string]string
+}
+
+// SystemEncryptionCmd combines local state encryption subcommands
+type SystemEncryptionCmd struct {
+	*kingpin.CmdClause
+}
+
+// SystemEncryptionEnableCmd enables encryption of local state
+type SystemEncryptionEnableCmd struct {
+	*kingpin.CmdClause
+	// Provider specifies the encryption key provider
+	Provider *string
+	// KeyFile is the path to the file with the key
+	KeyFile *string
+	// Parameter is the name of the AWS parameter with the key
+	Parameter *string
+	// Region is the AWS region of the parameter with the key
+	Region *string
+	// Handle is the TPM handle of the sealed key
+	Handle *string
+}
+
+// SystemEncryptionStatusCmd displays local state encryption status
+type SystemEncryptionStatusCmd struct {
+	*kingpin.CmdClause
+}
+
+// SystemEtcdCmd combines etcd related subcommands
+type SystemEtcdCmd struct {
+	*kingpin.CmdClause
+}
+
+// SystemEtcdRestoreCmd restores etcd on all master nodes from a snapshot
+type SystemEtcdRestoreCmd struct {
+	*kingpin.CmdClause
+	// Snapshot is the path to the etcd snapshot
+	Snapshot *string
+	// Manual is whether the operation is not executed automatically
+	Manual *bool
+	// Confirm suppresses confirmation prompt
+	Confirm *bool
+}
+
 // SystemDevicemapperCmd combines devicemapper related subcommands
 type SystemDevicemapperCmd struct {
 	*kingpin.CmdClause
@@ -1532,6 +2176,20 @@ type SystemStreamRuntimeJournalCmd struct {
 	*kingpin.CmdClause
 }
 
+// SystemNetworkMonitorCmd runs the network monitor that continuously
+// probes the network between this node and the other cluster nodes
+type SystemNetworkMonitorCmd struct {
+	*kingpin.CmdClause
+	// Node is the name of this node
+	Node *string
+	// Addr is the UDP address to exchange the probes on
+	Addr *string
+	// Interval is how often the other nodes are probed
+	Interval *time.Duration
+	// MTU is the size of the probes checking the path MTU
+	MTU *int
+}
+
 // SystemGCJournalCmd manages cleanup of journal files
 type SystemGCJournalCmd struct {
 	*kingpin.CmdClause
@@ -1597,6 +2255,16 @@ type PlanetStatusCmd struct {
 	*kingpin.CmdClause
 }
 
+// PlanetStopCmd stops planet on this node
+type PlanetStopCmd struct {
+	*kingpin.CmdClause
+}
+
+// PlanetStartCmd starts planet on this node
+type PlanetStartCmd struct {
+	*kingpin.CmdClause
+}
+
 // EnterCmd enters planet container
 type EnterCmd struct {
 	*kingpin.CmdClause
@@ -1686,3 +2354,45 @@ type TopCmd struct {
 	// Step is the max time b/w two datapoints.
 	Step *time.Duration
 }
+
+// MirrorCmd combines air-gapped mirror related subcommands
+type MirrorCmd struct {
+	*kingpin.CmdClause
+}
+
+// MirrorExportCmd exports packages and container images of a cluster image
+// into a directory that can be served with MirrorServeCmd
+type MirrorExportCmd struct {
+	*kingpin.CmdClause
+	// Path is the path to the cluster image tarball or unpacked installer directory
+	Path *string
+	// OutputDir is the mirror output directory
+	OutputDir *string
+}
+
+// MirrorServeCmd serves the docker registry and packages from a mirror directory
+type MirrorServeCmd struct {
+	*kingpin.CmdClause
+	// Dir is the mirror directory
+	Dir *string
+	// RegistryAddr is the address to serve the docker registry on
+	RegistryAddr *string
+	// PackagesAddr is the address to serve the packages on
+	PackagesAddr *string
+}
+
+// ExpandCmd adds new nodes to the cluster either by provisioning them with
+// the cloud provider integration or by joining the nodes listed in a roster file
+type ExpandCmd struct {
+	*kingpin.CmdClause
+	// ProvisionSpec is the path to the spec describing the nodes to provision
+	ProvisionSpec *string
+	// Role is the node profile of the new nodes
+	Role *string
+	// Count is the number of nodes to add
+	Count *int
+	// Roster is the path to the file listing existing nodes to join
+	Roster *string
+	// Parallel is the maximum number of nodes from the roster joining concurrently
+	Parallel *int
+}
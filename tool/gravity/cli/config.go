@@ -34,17 +34,24 @@ import (
 	autoscaleaws "github.com/gravitational/gravity/lib/autoscale/aws"
 	awscloud "github.com/gravitational/gravity/lib/cloudprovider/aws"
 	cloudaws "github.com/gravitational/gravity/lib/cloudprovider/aws"
+	"github.com/gravitational/gravity/lib/cloudprovider/aws/provisioner"
+	cloudazure "github.com/gravitational/gravity/lib/cloudprovider/azure"
 	cloudgce "github.com/gravitational/gravity/lib/cloudprovider/gce"
+	cloudvsphere "github.com/gravitational/gravity/lib/cloudprovider/vsphere"
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/expand"
+	"github.com/gravitational/gravity/lib/fips"
+	"github.com/gravitational/gravity/lib/hardening"
 	"github.com/gravitational/gravity/lib/httplib"
 	"github.com/gravitational/gravity/lib/install"
 	installerclient "github.com/gravitational/gravity/lib/install/client"
 	installpb "github.com/gravitational/gravity/lib/install/proto"
+	"github.com/gravitational/gravity/lib/kubernetes"
 	"github.com/gravitational/gravity/lib/loc"
 	"github.com/gravitational/gravity/lib/localenv"
 	"github.com/gravitational/gravity/lib/modules"
+	"github.com/gravitational/gravity/lib/networkpolicy"
 	"github.com/gravitational/gravity/lib/ops"
 	"github.com/gravitational/gravity/lib/ops/opsclient"
 	"github.com/gravitational/gravity/lib/ops/resources"
@@ -57,6 +64,7 @@ import (
 	"github.com/gravitational/gravity/lib/state"
 	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/storage/clusterconfig"
+	"github.com/gravitational/gravity/lib/system/disklayout"
 	"github.com/gravitational/gravity/lib/system/environ"
 	"github.com/gravitational/gravity/lib/system/signals"
 	"github.com/gravitational/gravity/lib/systeminfo"
@@ -67,6 +75,7 @@ import (
 	"github.com/cenkalti/backoff"
 	"github.com/docker/docker/pkg/namesgenerator"
 	"github.com/gravitational/configure"
+	"github.com/gravitational/license/authority"
 	teledefaults "github.com/gravitational/teleport/lib/defaults"
 	"github.com/gravitational/trace"
 	"github.com/sirupsen/logrus"
@@ -109,6 +118,11 @@ type InstallConfig struct {
 	SystemDevice string
 	// DockerDevice is a device for docker
 	DockerDevice string
+	// EtcdDevice is a device for etcd data
+	EtcdDevice string
+	// AutoPartition enables automatic placement of system data,
+	// Docker storage and etcd data on unused devices
+	AutoPartition bool
 	// Mounts is a list of mount points (name -> source pairs)
 	Mounts map[string]string
 	// DNSOverrides contains installer node DNS overrides
@@ -119,6 +133,8 @@ type InstallConfig struct {
 	ServiceCIDR string
 	// VxlanPort is the overlay network port
 	VxlanPort int
+	// NetworkEncryption is the encryption mode of the pod traffic between the nodes
+	NetworkEncryption string
 	// DNSConfig overrides the local cluster DNS configuration
 	DNSConfig storage.DNSConfig
 	// Docker specifies docker configuration
@@ -133,6 +149,15 @@ type InstallConfig struct {
 	LocalBackend storage.Backend
 	// GCENodeTags defines the VM instance tags on GCE
 	GCENodeTags []string
+	// GCESubnetwork is the GCE subnetwork for internal load balancers
+	GCESubnetwork string
+	// GCEStateDisk is the device name of the GCE persistent disk
+	// to use for the system state directory
+	GCEStateDisk string
+	// AzureNetwork describes the Azure network resources of the cluster
+	AzureNetwork cloudazure.NetworkConfig
+	// VSphereConfig is the path to the vSphere environment configuration
+	VSphereConfig string
 	// LocalClusterClient is a factory for creating client to the installed cluster
 	LocalClusterClient func() (*opsclient.Client, error)
 	// Mode specifies the installer mode
@@ -141,6 +166,8 @@ type InstallConfig struct {
 	DNSHosts []string
 	// DNSZones is a list of DNS zone overrides
 	DNSZones []string
+	// DNSUpstreams is a list of upstream nameservers for the cluster DNS
+	DNSUpstreams []string
 	// ResourcesPath is the additional Kubernetes resources to create
 	ResourcesPath string
 	// ServiceUID is the ID of the service user as configured externally
@@ -150,6 +177,39 @@ type InstallConfig struct {
 	// Remote specifies whether the installer executes the operation remotely
 	// (i.e. installer node will not be part of cluster)
 	Remote bool
+	// Labels is a list of custom labels for this node in the key=value format
+	Labels []string
+	// Taints is a list of custom taints for this node in the key=value:effect format
+	Taints []string
+	// HTTPProxy specifies the proxy for outbound HTTP connections
+	HTTPProxy string
+	// HTTPSProxy specifies the proxy for outbound HTTPS connections
+	HTTPSProxy string
+	// NoProxy specifies the list of destinations to exclude from proxying
+	NoProxy string
+	// Provision specifies whether to provision cluster nodes
+	// with the cloud provider integration
+	Provision bool
+	// ProvisionSpec is the path to the spec describing the nodes to provision
+	ProvisionSpec string
+	// Demo specifies whether to install the cluster in demo mode
+	// with relaxed requirements
+	Demo bool
+	// FIPS specifies whether to install the cluster in FIPS 140-2 mode
+	FIPS bool
+	// Hardening specifies the hardened configuration profile
+	Hardening hardening.Profile
+	// NetworkPolicy specifies whether to install the network policy baseline
+	NetworkPolicy bool
+	// CACertPath is the path to the certificate of the certificate authority
+	// to issue the cluster certificates
+	CACertPath string
+	// CAKeyPath is the path to the private key of the certificate authority
+	// to issue the cluster certificates
+	CAKeyPath string
+	// BaseImages lists the paths to the tarballs of the cluster images
+	// the installed layered cluster image is built on top of
+	BaseImages []string
 	// Printer specifies the output for progress messages
 	utils.Printer
 	// ProcessConfig specifies the Gravity process configuration
@@ -172,27 +232,41 @@ func NewInstallConfig(env *localenv.LocalEnvironment, g *Application) InstallCon
 		mode = constants.InstallModeInteractive
 	}
 	return InstallConfig{
-		Insecure:      *g.Insecure,
-		StateDir:      *g.InstallCmd.Path,
-		UserLogFile:   *g.UserLogFile,
-		SystemLogFile: *g.SystemLogFile,
-		AdvertiseAddr: *g.InstallCmd.AdvertiseAddr,
-		Token:         *g.InstallCmd.Token,
-		CloudProvider: *g.InstallCmd.CloudProvider,
-		SiteDomain:    *g.InstallCmd.Cluster,
-		Role:          *g.InstallCmd.Role,
-		SystemDevice:  *g.InstallCmd.SystemDevice,
-		DockerDevice:  *g.InstallCmd.DockerDevice,
-		Mounts:        *g.InstallCmd.Mounts,
-		PodCIDR:       *g.InstallCmd.PodCIDR,
-		ServiceCIDR:   *g.InstallCmd.ServiceCIDR,
-		VxlanPort:     *g.InstallCmd.VxlanPort,
+		Insecure:          *g.Insecure,
+		StateDir:          *g.InstallCmd.Path,
+		UserLogFile:       *g.UserLogFile,
+		SystemLogFile:     *g.SystemLogFile,
+		AdvertiseAddr:     *g.InstallCmd.AdvertiseAddr,
+		Token:             *g.InstallCmd.Token,
+		CloudProvider:     *g.InstallCmd.CloudProvider,
+		SiteDomain:        *g.InstallCmd.Cluster,
+		Role:              *g.InstallCmd.Role,
+		SystemDevice:      *g.InstallCmd.SystemDevice,
+		DockerDevice:      *g.InstallCmd.DockerDevice,
+		EtcdDevice:        *g.InstallCmd.EtcdDevice,
+		AutoPartition:     *g.InstallCmd.AutoPartition,
+		Mounts:            *g.InstallCmd.Mounts,
+		PodCIDR:           *g.InstallCmd.PodCIDR,
+		ServiceCIDR:       *g.InstallCmd.ServiceCIDR,
+		VxlanPort:         *g.InstallCmd.VxlanPort,
+		NetworkEncryption: *g.InstallCmd.NetworkEncryption,
 		Docker: storage.DockerConfig{
 			StorageDriver: g.InstallCmd.DockerStorageDriver.value,
 			Args:          *g.InstallCmd.DockerArgs,
 		},
-		DNSConfig:          g.InstallCmd.DNSConfig(),
-		GCENodeTags:        *g.InstallCmd.GCENodeTags,
+		DNSConfig:     g.InstallCmd.DNSConfig(),
+		GCENodeTags:   *g.InstallCmd.GCENodeTags,
+		GCESubnetwork: *g.InstallCmd.GCESubnetwork,
+		GCEStateDisk:  *g.InstallCmd.GCEStateDisk,
+		VSphereConfig: *g.InstallCmd.VSphereConfig,
+		AzureNetwork: cloudazure.NetworkConfig{
+			VnetName:          *g.InstallCmd.AzureVnet,
+			VnetResourceGroup: *g.InstallCmd.AzureVnetResourceGroup,
+			SubnetName:        *g.InstallCmd.AzureSubnet,
+			SecurityGroupName: *g.InstallCmd.AzureSecurityGroup,
+			RouteTableName:    *g.InstallCmd.AzureRouteTable,
+			AvailabilitySet:   *g.InstallCmd.AzureAvailabilitySet,
+		},
 		LocalPackages:      env.Packages,
 		LocalApps:          env.Apps,
 		LocalBackend:       env.Backend,
@@ -204,8 +278,23 @@ func NewInstallConfig(env *localenv.LocalEnvironment, g *Application) InstallCon
 		ResourcesPath:      *g.InstallCmd.ResourcesPath,
 		DNSHosts:           *g.InstallCmd.DNSHosts,
 		DNSZones:           *g.InstallCmd.DNSZones,
+		DNSUpstreams:       *g.InstallCmd.DNSUpstreams,
 		Flavor:             *g.InstallCmd.Flavor,
 		Remote:             *g.InstallCmd.Remote,
+		Labels:             *g.InstallCmd.Labels,
+		Taints:             *g.InstallCmd.Taints,
+		HTTPProxy:          *g.InstallCmd.HTTPProxy,
+		HTTPSProxy:         *g.InstallCmd.HTTPSProxy,
+		NoProxy:            *g.InstallCmd.NoProxy,
+		Provision:          *g.InstallCmd.Provision,
+		ProvisionSpec:      *g.InstallCmd.ProvisionSpec,
+		Demo:               *g.InstallCmd.Demo,
+		FIPS:               *g.InstallCmd.FIPS,
+		Hardening:          hardening.Profile(*g.InstallCmd.Hardening),
+		NetworkPolicy:      *g.InstallCmd.NetworkPolicy,
+		CACertPath:         *g.InstallCmd.CACert,
+		CAKeyPath:          *g.InstallCmd.CAKey,
+		BaseImages:         *g.InstallCmd.BaseImages,
 		FromService:        *g.InstallCmd.FromService,
 		Printer:            env,
 	}
@@ -265,8 +354,38 @@ func (i *InstallConfig) CheckAndSetDefaults() (err error) {
 	if err := i.validateDNSConfig(); err != nil {
 		return trace.Wrap(err)
 	}
+	if err := i.proxyConfig().CheckProxy(); err != nil {
+		return trace.Wrap(err)
+	}
+	if i.FIPS {
+		if err := fips.CheckEnabled(); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	if err := i.checkCertAuthority(); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := checkLabelsAndTaints(i.Labels, i.Taints); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := i.checkProvision(); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := i.checkGCEConfig(); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := i.checkAzureConfig(); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := i.checkVSphereConfig(); err != nil {
+		return trace.Wrap(err)
+	}
 	if i.AdvertiseAddr == "" {
 		i.AdvertiseAddr, err = selectAdvertiseAddr()
+		if err != nil && i.Demo {
+			// Demo hosts (laptops, CI runners) frequently lack a default route
+			i.AdvertiseAddr, err = utils.PickLocalIP()
+		}
 		if err != nil {
 			return trace.Wrap(err)
 		}
@@ -336,7 +455,7 @@ func (i *InstallConfig) NewInstallerConfig(
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
-	dnsOverrides, err := i.getDNSOverrides()
+	dnsOverrides, gravityResources, err := i.getDNSOverrides(gravityResources)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -344,7 +463,12 @@ func (i *InstallConfig) NewInstallerConfig(
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
-	flavor, err := getFlavor(i.Flavor, app.Manifest, i.FieldLogger)
+	flavorName := i.Flavor
+	if flavorName == "" && i.Demo {
+		flavorName = smallestFlavor(app.Manifest)
+		i.WithField("flavor", flavorName).Info("Picking smallest flavor for demo installation.")
+	}
+	flavor, err := getFlavor(flavorName, app.Manifest, i.FieldLogger)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -352,11 +476,21 @@ func (i *InstallConfig) NewInstallerConfig(
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+	diskLayout, err := i.planDiskLayout(app.Manifest)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
 	if !i.Remote {
 		if err := i.validateCloudConfig(app.Manifest); err != nil {
 			return nil, trace.Wrap(err)
 		}
 	}
+	if err := i.validateNetworkPolicy(app.Manifest); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := i.validateNetworkEncryption(app.Manifest); err != nil {
+		return nil, trace.Wrap(err)
+	}
 	token, err := generateInstallToken(wizard.Operator, i.Token)
 	if err != nil && !trace.IsAlreadyExists(err) {
 		return nil, trace.Wrap(err)
@@ -383,11 +517,15 @@ func (i *InstallConfig) NewInstallerConfig(
 		GCENodeTags:        i.GCENodeTags,
 		SystemDevice:       i.SystemDevice,
 		DockerDevice:       i.DockerDevice,
+		DiskLayout:         diskLayout,
 		Mounts:             i.Mounts,
+		Labels:             i.Labels,
+		Taints:             i.Taints,
 		DNSConfig:          i.DNSConfig,
 		PodCIDR:            i.PodCIDR,
 		ServiceCIDR:        i.ServiceCIDR,
 		VxlanPort:          i.VxlanPort,
+		NetworkEncryption:  i.NetworkEncryption,
 		Docker:             i.Docker,
 		Insecure:           i.Insecure,
 		LocalClusterClient: i.LocalClusterClient,
@@ -404,6 +542,8 @@ func (i *InstallConfig) NewInstallerConfig(
 		Packages:           wizard.Packages,
 		Operator:           wizard.Operator,
 		LocalAgent:         !i.Remote,
+		Demo:               i.Demo,
+		CertAuthority:      i.certAuthority(),
 	}, nil
 
 }
@@ -413,6 +553,119 @@ func (i *InstallConfig) validateApplicationDir() error {
 	return trace.Wrap(err)
 }
 
+// checkCertAuthority validates the certificate authority
+// to issue the cluster certificates, if specified
+func (i *InstallConfig) checkCertAuthority() (err error) {
+	if i.CACertPath == "" && i.CAKeyPath == "" {
+		return nil
+	}
+	if i.CACertPath == "" || i.CAKeyPath == "" {
+		return trace.BadParameter("both --ca-cert and --ca-key are required to use a custom certificate authority")
+	}
+	// The paths are recorded in the operation plan
+	// so they should not depend on the working directory
+	if i.CACertPath, err = filepath.Abs(i.CACertPath); err != nil {
+		return trace.Wrap(err)
+	}
+	if i.CAKeyPath, err = filepath.Abs(i.CAKeyPath); err != nil {
+		return trace.Wrap(err)
+	}
+	keyPair, err := authority.NewTLSKeyPair(i.CAKeyPath, i.CACertPath)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(utils.CheckCertAuthority(*keyPair, time.Now()))
+}
+
+// certAuthority returns the certificate authority to issue
+// the cluster certificates or nil, if not specified
+func (i *InstallConfig) certAuthority() *storage.CertAuthorityFiles {
+	if i.CACertPath == "" {
+		return nil
+	}
+	return &storage.CertAuthorityFiles{
+		CertPath: i.CACertPath,
+		KeyPath:  i.CAKeyPath,
+	}
+}
+
+// checkProvision validates the node provisioning configuration
+func (i *InstallConfig) checkProvision() error {
+	if !i.Provision {
+		return nil
+	}
+	if i.CloudProvider != schema.ProviderAWS {
+		return trace.BadParameter("node provisioning is only supported with --cloud-provider=%v",
+			schema.ProviderAWS)
+	}
+	if i.SiteDomain == "" {
+		return trace.BadParameter("node provisioning requires the cluster name, " +
+			"please provide it with --cluster")
+	}
+	if i.ProvisionSpec == "" {
+		return trace.BadParameter("node provisioning requires the spec, " +
+			"please provide it with --provision-spec")
+	}
+	_, err := provisioner.ReadSpec(i.ProvisionSpec)
+	return trace.Wrap(err)
+}
+
+// checkGCEConfig validates the GCE specific configuration
+func (i *InstallConfig) checkGCEConfig() error {
+	if i.GCESubnetwork == "" && i.GCEStateDisk == "" {
+		return nil
+	}
+	if i.CloudProvider != "" && i.CloudProvider != schema.ProviderGCE {
+		return trace.BadParameter("--gce-subnetwork and --gce-state-disk "+
+			"require the %v cloud provider", schema.ProviderGCE)
+	}
+	if i.GCEStateDisk == "" {
+		return nil
+	}
+	if i.SystemDevice != "" {
+		return trace.BadParameter("--gce-state-disk and --system-device " +
+			"are mutually exclusive")
+	}
+	var err error
+	i.SystemDevice, err = cloudgce.DiskDevicePath(i.GCEStateDisk)
+	return trace.Wrap(err)
+}
+
+// checkAzureConfig validates the Azure specific configuration.
+// Azure network flags imply the Azure cloud provider
+func (i *InstallConfig) checkAzureConfig() error {
+	if i.AzureNetwork == (cloudazure.NetworkConfig{}) {
+		return nil
+	}
+	switch i.CloudProvider {
+	case "":
+		i.CloudProvider = schema.ProviderAzure
+	case schema.ProviderAzure:
+	default:
+		return trace.BadParameter("--azure-* flags require the %v cloud provider",
+			schema.ProviderAzure)
+	}
+	return nil
+}
+
+// checkVSphereConfig validates the vSphere configuration.
+// The configuration implies the vSphere cloud provider
+func (i *InstallConfig) checkVSphereConfig() error {
+	if i.VSphereConfig == "" {
+		return nil
+	}
+	switch i.CloudProvider {
+	case "":
+		i.CloudProvider = schema.ProviderVSphere
+	case schema.ProviderVSphere:
+	default:
+		return trace.BadParameter("--vsphere-config requires the %v cloud provider",
+			schema.ProviderVSphere)
+	}
+	_, err := cloudvsphere.ReadConfig(i.VSphereConfig)
+	return trace.Wrap(err)
+}
+
 // getAdvertiseAddr returns the advertise address to use for the ???
 // asks the user to choose it among the host's interfaces
 func (i *InstallConfig) getAdvertiseAddr() (string, error) {
@@ -465,27 +718,80 @@ func (i *InstallConfig) getApp() (app *app.Application, err error) {
 	return app, nil
 }
 
-// getDNSOverrides converts DNS overrides specified on CLI to the storage format
-func (i *InstallConfig) getDNSOverrides() (*storage.DNSOverrides, error) {
+// planDiskLayout plans the disk layout of the installer node if automatic
+// partitioning or a dedicated etcd device has been requested.
+// System and Docker devices are updated to the planned ones
+func (i *InstallConfig) planDiskLayout(manifest schema.Manifest) (*disklayout.Layout, error) {
+	if !i.AutoPartition && i.EtcdDevice == "" {
+		return nil, nil
+	}
+	layout, err := install.PlanDiskLayout(install.DiskLayoutConfig{
+		Manifest:      manifest,
+		Profile:       i.Role,
+		Docker:        i.Docker,
+		SystemDevice:  i.SystemDevice,
+		DockerDevice:  i.DockerDevice,
+		EtcdDevice:    i.EtcdDevice,
+		AutoPartition: i.AutoPartition,
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	i.SystemDevice = layout.DevicePath(disklayout.RoleSystem)
+	i.DockerDevice = layout.DevicePath(disklayout.RoleDocker)
+	i.Printer.Printf("Planned disk layout:\n%v", layout)
+	return layout, nil
+}
+
+// getDNSOverrides converts DNS overrides specified on CLI and with the optional
+// cluster DNS resource to the storage format.
+// The cluster DNS resource is removed from the returned list of resources
+func (i *InstallConfig) getDNSOverrides(resources []storage.UnknownResource) (*storage.DNSOverrides, []storage.UnknownResource, error) {
 	overrides := &storage.DNSOverrides{
 		Hosts: make(map[string]string),
 		Zones: make(map[string][]string),
 	}
+	updated := resources[:0]
+	for _, res := range resources {
+		if res.Kind != storage.KindClusterDNS {
+			updated = append(updated, res)
+			continue
+		}
+		config, err := storage.UnmarshalClusterDNS(res.Raw)
+		if err != nil {
+			return nil, nil, trace.Wrap(err)
+		}
+		for host, ip := range config.GetHosts() {
+			overrides.Hosts[host] = ip
+		}
+		for zone, nameservers := range config.GetZones() {
+			overrides.Zones[zone] = append(overrides.Zones[zone], nameservers...)
+		}
+		overrides.Upstreams = config.GetUpstreamServers()
+	}
 	for _, hostOverride := range i.DNSHosts {
 		host, ip, err := utils.ParseHostOverride(hostOverride)
 		if err != nil {
-			return nil, trace.Wrap(err)
+			return nil, nil, trace.Wrap(err)
 		}
 		overrides.Hosts[host] = ip
 	}
 	for _, zoneOverride := range i.DNSZones {
 		zone, nameserver, err := utils.ParseZoneOverride(zoneOverride)
 		if err != nil {
-			return nil, trace.Wrap(err)
+			return nil, nil, trace.Wrap(err)
 		}
 		overrides.Zones[zone] = append(overrides.Zones[zone], nameserver)
 	}
-	return overrides, nil
+	// Upstream nameservers given on the command line take precedence
+	// over the ones from the configuration resource
+	if len(i.DNSUpstreams) != 0 {
+		overrides.Upstreams = i.DNSUpstreams
+	}
+	if err := overrides.Check(); err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	return overrides, updated, nil
 }
 
 // splitResources validates the resources specified in ResourcePath
@@ -523,11 +829,12 @@ func (i *InstallConfig) updateClusterConfig(resources []storage.UnknownResource)
 		}
 		updated = append(updated, res)
 	}
-	if clusterConfig == nil && i.CloudProvider == "" {
+	proxyConfig := i.proxyConfig()
+	if clusterConfig == nil && i.CloudProvider == "" && !proxyConfig.HasProxy() && i.GCESubnetwork == "" && !i.FIPS && !i.Hardening.IsEnabled() && !i.NetworkPolicy {
 		// Return the resources unchanged
 		return resources, nil
 	}
-	var config clusterconfig.Interface
+	var config *clusterconfig.Resource
 	if clusterConfig == nil {
 		config = clusterconfig.New(clusterconfig.Spec{
 			Global: &clusterconfig.Global{CloudProvider: i.CloudProvider},
@@ -538,6 +845,40 @@ func (i *InstallConfig) updateClusterConfig(resources []storage.UnknownResource)
 			return nil, trace.Wrap(err)
 		}
 	}
+	if proxyConfig.HasProxy() {
+		// Proxy settings given on the command line take precedence
+		// over the ones from the configuration resource
+		if config.Spec.Global == nil {
+			config.Spec.Global = &clusterconfig.Global{}
+		}
+		config.Spec.Global.HTTPProxy = proxyConfig.HTTPProxy
+		config.Spec.Global.HTTPSProxy = proxyConfig.HTTPSProxy
+		config.Spec.Global.NoProxy = proxyConfig.NoProxy
+	}
+	if i.FIPS {
+		config.SetFIPS(true)
+	}
+	if i.Hardening.IsEnabled() {
+		config.SetHardeningProfile(string(i.Hardening))
+	}
+	if i.NetworkPolicy {
+		config.SetNetworkPolicy(true)
+	}
+	if i.GCESubnetwork != "" {
+		if err := i.setGCECloudConfig(config); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	if i.CloudProvider == schema.ProviderAzure {
+		if err := i.setAzureCloudConfig(config); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	if i.CloudProvider == schema.ProviderVSphere {
+		if err := i.setVSphereCloudConfig(config); err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
 	if config := config.GetGlobalConfig(); config != nil {
 		if config.CloudProvider != "" {
 			i.CloudProvider = config.CloudProvider
@@ -552,6 +893,88 @@ func (i *InstallConfig) updateClusterConfig(resources []storage.UnknownResource)
 	return updated, nil
 }
 
+// setGCECloudConfig generates the GCE cloud provider configuration for
+// the configured subnetwork unless the cluster configuration already has one
+func (i *InstallConfig) setGCECloudConfig(config *clusterconfig.Resource) error {
+	if config.Spec.Global == nil {
+		config.Spec.Global = &clusterconfig.Global{}
+	}
+	if config.Spec.Global.CloudConfig != "" {
+		i.Info("Use cloud configuration from the cluster configuration resource.")
+		return nil
+	}
+	instance, err := cloudgce.NewLocalInstance()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	config.Spec.Global.CloudProvider = schema.ProviderGCE
+	config.Spec.Global.CloudConfig = cloudgce.NewCloudConfig(*instance,
+		i.GCESubnetwork, i.GCENodeTags).String()
+	return nil
+}
+
+// setAzureCloudConfig generates the Azure cloud provider configuration
+// unless the cluster configuration already has one
+func (i *InstallConfig) setAzureCloudConfig(config *clusterconfig.Resource) error {
+	if config.Spec.Global == nil {
+		config.Spec.Global = &clusterconfig.Global{}
+	}
+	if config.Spec.Global.CloudConfig != "" {
+		i.Info("Use cloud configuration from the cluster configuration resource.")
+		return nil
+	}
+	instance, err := cloudazure.NewLocalInstance()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cloudConfig, err := cloudazure.NewCloudConfig(*instance, i.AzureNetwork)
+	if err != nil {
+		return trace.Wrap(err, "Azure cloud provider requires either the cloud "+
+			"configuration in the cluster configuration resource or the "+
+			"--azure-vnet, --azure-subnet and --azure-security-group flags")
+	}
+	config.Spec.Global.CloudProvider = schema.ProviderAzure
+	config.Spec.Global.CloudConfig = cloudConfig.String()
+	return nil
+}
+
+// setVSphereCloudConfig generates the vSphere cloud provider configuration
+// unless the cluster configuration already has one
+func (i *InstallConfig) setVSphereCloudConfig(config *clusterconfig.Resource) error {
+	if config.Spec.Global == nil {
+		config.Spec.Global = &clusterconfig.Global{}
+	}
+	if config.Spec.Global.CloudConfig != "" {
+		i.Info("Use cloud configuration from the cluster configuration resource.")
+		return nil
+	}
+	if i.VSphereConfig == "" {
+		return trace.BadParameter("vSphere cloud provider requires either the " +
+			"cloud configuration in the cluster configuration resource or --vsphere-config")
+	}
+	vsphereConfig, err := cloudvsphere.ReadConfig(i.VSphereConfig)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if vsphereConfig.Masters != nil {
+		// Anti-affinity rules can only be managed with the vSphere SOAP API
+		i.Infof("Make sure master virtual machines are kept on separate hosts, e.g.: %v.",
+			vsphereConfig.Masters.Command(vsphereConfig.Datacenter))
+	}
+	config.Spec.Global.CloudProvider = schema.ProviderVSphere
+	config.Spec.Global.CloudConfig = vsphereConfig.CloudConfig()
+	return nil
+}
+
+// proxyConfig returns the proxy configuration specified on the command line
+func (i *InstallConfig) proxyConfig() clusterconfig.Global {
+	return clusterconfig.Global{
+		HTTPProxy:  i.HTTPProxy,
+		HTTPSProxy: i.HTTPSProxy,
+		NoProxy:    i.NoProxy,
+	}
+}
+
 func (i *InstallConfig) validateDNSConfig() error {
 	blocks, err := utils.LocalIPNetworks()
 	if err != nil {
@@ -567,6 +990,36 @@ func (i *InstallConfig) validateDNSConfig() error {
 	return nil
 }
 
+// validateNetworkPolicy makes sure the network policy baseline is only
+// requested for the applications with the network type that enforces
+// network policies
+func (i *InstallConfig) validateNetworkPolicy(manifest schema.Manifest) error {
+	if !i.NetworkPolicy {
+		return nil
+	}
+	networkType := manifest.GetNetworkType(i.CloudProvider, "")
+	if !networkpolicy.IsSupported(networkType) {
+		return trace.BadParameter("network type %q of the application does not enforce network policies, "+
+			"--network-policy requires %v networking", networkType, schema.NetworkingCalico)
+	}
+	return nil
+}
+
+// validateNetworkEncryption makes sure the encryption of the pod traffic
+// is only requested for the applications with the overlay network
+// the WireGuard tunnels replace
+func (i *InstallConfig) validateNetworkEncryption(manifest schema.Manifest) error {
+	if i.NetworkEncryption == "" {
+		return nil
+	}
+	networkType := manifest.GetNetworkType(i.CloudProvider, "")
+	if networkType != schema.NetworkingFlannel {
+		return trace.BadParameter("network type %q of the application does not use the overlay network, "+
+			"--network-encryption requires %v networking", networkType, schema.NetworkingFlannel)
+	}
+	return nil
+}
+
 func (i *InstallConfig) validateCloudConfig(manifest schema.Manifest) (err error) {
 	i.CloudProvider, err = i.validateOrDetectCloudProvider(i.CloudProvider, manifest)
 	if err != nil {
@@ -648,6 +1101,11 @@ type JoinConfig struct {
 	SystemDevice string
 	// DockerDevice is device for docker data
 	DockerDevice string
+	// EtcdDevice is device for etcd data
+	EtcdDevice string
+	// AutoPartition enables automatic placement of system data,
+	// Docker storage and etcd data on unused devices
+	AutoPartition bool
 	// Mounts is a list of additional mounts
 	Mounts map[string]string
 	// CloudProvider is the node cloud provider
@@ -658,6 +1116,12 @@ type JoinConfig struct {
 	Phase string
 	// OperationID is ID of existing expand operation
 	OperationID string
+	// Labels is a list of custom labels for this node in the key=value format
+	Labels []string
+	// Taints is a list of custom taints for this node in the key=value:effect format
+	Taints []string
+	// NodePool is the name of the node pool to join
+	NodePool string
 	// FromService specifies whether the process runs in service mode
 	FromService bool
 	// SkipWizard specifies to the join agents that this join request is not too a wizard,
@@ -677,8 +1141,13 @@ func NewJoinConfig(g *Application) JoinConfig {
 		Role:          *g.JoinCmd.Role,
 		SystemDevice:  *g.JoinCmd.SystemDevice,
 		DockerDevice:  *g.JoinCmd.DockerDevice,
+		EtcdDevice:    *g.JoinCmd.EtcdDevice,
+		AutoPartition: *g.JoinCmd.AutoPartition,
 		Mounts:        *g.JoinCmd.Mounts,
 		OperationID:   *g.JoinCmd.OperationID,
+		Labels:        *g.JoinCmd.Labels,
+		Taints:        *g.JoinCmd.Taints,
+		NodePool:      *g.JoinCmd.NodePool,
 		FromService:   *g.JoinCmd.FromService,
 	}
 }
@@ -694,6 +1163,9 @@ func (j *JoinConfig) CheckAndSetDefaults() (err error) {
 	if err := checkLocalAddr(j.AdvertiseAddr); err != nil {
 		return trace.Wrap(err)
 	}
+	if err := checkLabelsAndTaints(j.Labels, j.Taints); err != nil {
+		return trace.Wrap(err)
+	}
 	return nil
 }
 
@@ -718,6 +1190,8 @@ func (j *JoinConfig) NewPeerConfig(env, joinEnv *localenv.LocalEnvironment) (con
 		StateDir:           joinEnv.StateDir,
 		OperationID:        j.OperationID,
 		SkipWizard:         j.SkipWizard,
+		EtcdDevice:         j.EtcdDevice,
+		AutoPartition:      j.AutoPartition,
 	}, nil
 }
 
@@ -741,7 +1215,28 @@ func (j *JoinConfig) GetRuntimeConfig() proto.RuntimeConfig {
 		SystemDevice: j.SystemDevice,
 		DockerDevice: j.DockerDevice,
 		Mounts:       convertMounts(j.Mounts),
+		KeyValues:    j.keyValues(),
+	}
+}
+
+// keyValues returns the agent runtime parameters for the joining node
+func (j *JoinConfig) keyValues() map[string]string {
+	keyValues := install.NodeKeyValues(j.Labels, j.Taints)
+	if j.NodePool != "" {
+		keyValues[ops.AgentNodePool] = j.NodePool
 	}
+	return keyValues
+}
+
+// checkLabelsAndTaints validates the custom node labels and taints
+func checkLabelsAndTaints(labels, taints []string) error {
+	for _, label := range labels {
+		if _, _, err := kubernetes.ParseLabel(label); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	_, err := kubernetes.ParseTaints(taints)
+	return trace.Wrap(err)
 }
 
 func (r *removeConfig) checkAndSetDefaults() error {
@@ -755,6 +1250,8 @@ type removeConfig struct {
 	server    string
 	force     bool
 	confirmed bool
+	// checkOnly only reports issues that block the node removal
+	checkOnly bool
 }
 
 func (r *autojoinConfig) newJoinConfig() JoinConfig {
@@ -922,6 +1419,18 @@ func updateJoinConfigFromCloudMetadata(ctx context.Context, config *autojoinConf
 		return trace.Wrap(err)
 	}
 
+	if config.role != "" {
+		// Prefer the token issued on a scale up request for this node profile
+		config.token, err = autoscaler.GetScaleUpToken(ctx, config.role)
+		if err == nil {
+			return nil
+		}
+		if !trace.IsNotFound(err) {
+			return trace.Wrap(err)
+		}
+		log.WithField("role", config.role).Debug("No scale up token published, using cluster join token.")
+	}
+
 	config.token, err = autoscaler.GetJoinToken(ctx)
 	if err != nil {
 		return trace.Wrap(err)
@@ -959,6 +1468,25 @@ func getFlavor(name string, manifest schema.Manifest, logger logrus.FieldLogger)
 	return flavor, nil
 }
 
+// smallestFlavor returns the name of the install flavor with the fewest nodes
+// or an empty string if the manifest defines no flavors
+func smallestFlavor(manifest schema.Manifest) (name string) {
+	if manifest.Installer == nil {
+		return ""
+	}
+	var nodes int
+	for _, flavor := range manifest.Installer.Flavors.Items {
+		count := 0
+		for _, node := range flavor.Nodes {
+			count += node.Count
+		}
+		if name == "" || count < nodes {
+			name, nodes = flavor.Name, count
+		}
+	}
+	return name
+}
+
 func validateRole(role string, flavor schema.Flavor, profiles schema.NodeProfiles, logger logrus.FieldLogger) (string, error) {
 	if role == "" {
 		for _, node := range flavor.Nodes {
@@ -1191,6 +1719,15 @@ func (i *InstallConfig) validateOrDetectCloudProvider(cloudProvider string, mani
 				"instance", cloudProvider)
 		}
 		return schema.ProviderGCE, nil
+	case schema.ProviderAzure:
+		if !cloudazure.IsRunningOnAzure() {
+			return "", trace.BadParameter("cloud provider %q was specified "+
+				"but the process does not appear to be running on an Azure "+
+				"virtual machine", cloudProvider)
+		}
+		return schema.ProviderAzure, nil
+	case schema.ProviderVSphere:
+		return schema.ProviderVSphere, nil
 	case ops.ProviderGeneric, schema.ProvisionerOnPrem:
 		return schema.ProviderOnPrem, nil
 	case "":
@@ -1204,6 +1741,11 @@ func (i *InstallConfig) validateOrDetectCloudProvider(cloudProvider string, mani
 			log.Info("Detected GCE cloud provider.")
 			return schema.ProviderGCE, nil
 		}
+		if cloudazure.IsRunningOnAzure() {
+			// Azure integration requires the network configuration
+			// that cannot be detected, so it needs to be explicitly enabled
+			log.Info("Detected Azure, use --cloud-provider=azure to enable the integration.")
+		}
 		log.Info("No cloud provider detected, will use generic.")
 		return schema.ProviderOnPrem, nil
 	default:
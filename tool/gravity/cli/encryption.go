@@ -0,0 +1,94 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/state"
+	"github.com/gravitational/gravity/lib/storage/encryption"
+	"github.com/gravitational/gravity/lib/storage/keyval"
+
+	"github.com/gravitational/trace"
+)
+
+// enableEncryption configures encryption of the local state databases
+// on this node with the key from the specified provider and encrypts
+// the values already stored in them
+func enableEncryption(g *Application, config encryption.Config) error {
+	key, err := config.GetKey(context.TODO())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	stateDir, err := state.GetStateDir()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	localStateDir, err := getLocalStateDir(*g.StateDir)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	dirs := []string{localStateDir, state.GravityUpdateDir(stateDir)}
+	for _, dir := range dirs {
+		path := filepath.Join(dir, defaults.GravityDBFile)
+		if _, err := os.Stat(path); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return trace.ConvertSystemError(err)
+		}
+		backend, err := keyval.NewBolt(keyval.BoltConfig{
+			Path:          path,
+			EncryptionKey: key,
+		})
+		if err != nil {
+			return trace.Wrap(err, "failed to encrypt %v", path)
+		}
+		backend.Close()
+		log.Debugf("Encrypted %v.", path)
+	}
+	err = encryption.WriteConfig(stateDir, config)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	fmt.Printf("Local state encryption has been enabled with the %v key provider.\n",
+		config.Provider)
+	return nil
+}
+
+// encryptionStatus displays whether the local state on this node is encrypted
+func encryptionStatus() error {
+	stateDir, err := state.GetStateDir()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	config, err := encryption.ReadConfig(stateDir)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			fmt.Println("Local state encryption is not configured.")
+			return nil
+		}
+		return trace.Wrap(err)
+	}
+	fmt.Printf("Local state is encrypted with the key from the %v provider.\n",
+		config.Provider)
+	return nil
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"path/filepath"
+
+	libbackup "github.com/gravitational/gravity/lib/backup"
+	libfsm "github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/rpc"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/update"
+	"github.com/gravitational/gravity/lib/update/etcdrestore"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+)
+
+type etcdRestoreConfig struct {
+	// snapshot is the path to the etcd snapshot
+	snapshot string
+	// manual specifies whether the operation is executed manually
+	manual bool
+	// confirmed specifies whether the user has confirmed the operation
+	confirmed bool
+}
+
+// restoreEtcd restores the etcd database of the cluster
+// on all master nodes from the specified snapshot
+func restoreEtcd(localEnv *localenv.LocalEnvironment, environ LocalEnvironmentFactory, config etcdRestoreConfig) error {
+	path, err := filepath.Abs(config.snapshot)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	status, err := libbackup.GetSnapshotStatus(path)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if !config.confirmed {
+		localEnv.Printf(etcdRestoreBanner+"\n", status.Revision, status.Keys)
+		resp, err := confirm()
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if !resp {
+			localEnv.Println("Action cancelled by user.")
+			return nil
+		}
+	}
+	updateEnv, err := environ.NewUpdateEnv()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updateEnv.Close()
+	ctx := context.TODO()
+	updater, err := newUpdater(ctx, localEnv, updateEnv, etcdRestoreInitializer{
+		snapshot: path,
+		revision: status.Revision,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updater.Close()
+	if config.manual {
+		localEnv.Println(updateEnvironManualOperationBanner)
+		return nil
+	}
+	return trace.Wrap(updater.Run(ctx))
+}
+
+func executeEtcdRestorePhase(env *localenv.LocalEnvironment, environ LocalEnvironmentFactory, params PhaseParams, operation ops.SiteOperation) error {
+	updateEnv, err := environ.NewUpdateEnv()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updateEnv.Close()
+	updater, err := getEtcdRestoreUpdater(env, updateEnv, operation)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updater.Close()
+	err = updater.RunPhase(context.TODO(), params.PhaseID, params.Timeout, params.Force)
+	return trace.Wrap(err)
+}
+
+func setEtcdRestorePhase(env *localenv.LocalEnvironment, environ LocalEnvironmentFactory, params SetPhaseParams, operation ops.SiteOperation) error {
+	updateEnv, err := environ.NewUpdateEnv()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updateEnv.Close()
+	updater, err := getEtcdRestoreUpdater(env, updateEnv, operation)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updater.Close()
+	return updater.SetPhase(context.TODO(), params.PhaseID, params.State)
+}
+
+func rollbackEtcdRestorePhase(env *localenv.LocalEnvironment, environ LocalEnvironmentFactory, params PhaseParams, operation ops.SiteOperation) error {
+	updateEnv, err := environ.NewUpdateEnv()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updateEnv.Close()
+	updater, err := getEtcdRestoreUpdater(env, updateEnv, operation)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updater.Close()
+	err = updater.RollbackPhase(context.TODO(), params.PhaseID, params.Timeout, params.Force)
+	return trace.Wrap(err)
+}
+
+func completeEtcdRestorePlan(env *localenv.LocalEnvironment, environ LocalEnvironmentFactory, operation ops.SiteOperation) error {
+	updateEnv, err := environ.NewUpdateEnv()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updateEnv.Close()
+	updater, err := getEtcdRestoreUpdater(env, updateEnv, operation)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updater.Close()
+	return trace.Wrap(updater.Complete(nil))
+}
+
+func getEtcdRestoreUpdater(env, updateEnv *localenv.LocalEnvironment, operation ops.SiteOperation) (*update.Updater, error) {
+	clusterEnv, err := env.NewClusterEnvironment()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	creds, err := libfsm.GetClientCredentials()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	runner := libfsm.NewAgentRunner(creds)
+	return etcdRestoreInitializer{}.newUpdater(context.TODO(), clusterEnv.Operator, operation,
+		env, updateEnv, clusterEnv, runner)
+}
+
+func (etcdRestoreInitializer) validatePreconditions(*localenv.LocalEnvironment, ops.Operator, ops.Site) error {
+	return nil
+}
+
+func (r etcdRestoreInitializer) newOperation(operator ops.Operator, cluster ops.Site) (*ops.SiteOperationKey, error) {
+	key, err := operator.CreateEtcdRestoreOperation(context.TODO(),
+		ops.CreateEtcdRestoreOperationRequest{
+			ClusterKey: cluster.Key(),
+			Snapshot:   r.snapshot,
+			Revision:   r.revision,
+		},
+	)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return key, nil
+}
+
+func (etcdRestoreInitializer) newOperationPlan(
+	ctx context.Context,
+	operator ops.Operator,
+	cluster ops.Site,
+	operation ops.SiteOperation,
+	localEnv, updateEnv *localenv.LocalEnvironment,
+	clusterEnv *localenv.ClusterEnvironment,
+	leader *storage.Server,
+) (*storage.OperationPlan, error) {
+	if leader == nil {
+		return nil, trace.NotFound("etcd can only be restored from a master node")
+	}
+	plan, err := etcdrestore.NewOperationPlan(ctx, operator, operation, cluster.ClusterState.Servers, *leader)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return plan, nil
+}
+
+func (etcdRestoreInitializer) newUpdater(
+	ctx context.Context,
+	operator ops.Operator,
+	operation ops.SiteOperation,
+	localEnv, updateEnv *localenv.LocalEnvironment,
+	clusterEnv *localenv.ClusterEnvironment,
+	runner rpc.AgentRepository,
+) (*update.Updater, error) {
+	config := etcdrestore.Config{
+		Config: update.Config{
+			Operation:    &operation,
+			Operator:     operator,
+			Backend:      clusterEnv.Backend,
+			LocalBackend: updateEnv.Backend,
+			Silent:       localEnv.Silent,
+			Runner:       runner,
+			FieldLogger: logrus.WithFields(logrus.Fields{
+				trace.Component: "update:etcdrestore",
+				"operation":     operation,
+			}),
+		},
+		Apps:              clusterEnv.Apps,
+		Client:            clusterEnv.Client,
+		ClusterPackages:   clusterEnv.ClusterPackages,
+		HostLocalPackages: localEnv.Packages,
+	}
+	return etcdrestore.New(ctx, config)
+}
+
+func (etcdRestoreInitializer) updateDeployRequest(req deployAgentsRequest) deployAgentsRequest {
+	return req
+}
+
+type etcdRestoreInitializer struct {
+	// snapshot is the path to the etcd snapshot
+	snapshot string
+	// revision is the revision of the etcd database in the snapshot
+	revision int64
+}
+
+const etcdRestoreBanner = `The etcd database of the cluster will be restored on all master nodes
+from the snapshot at revision %v with %v keys. etcd and the Kubernetes API
+will be unavailable until the restore completes and all changes made
+after the snapshot has been taken will be lost.
+
+Are you sure?`
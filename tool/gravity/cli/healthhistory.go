@@ -0,0 +1,156 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/localenv"
+	statusapi "github.com/gravitational/gravity/lib/status"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/fatih/color"
+	"github.com/gravitational/trace"
+)
+
+func showHealthHistory(env *localenv.LocalEnvironment, since time.Duration, format constants.Format) error {
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	now := time.Now().UTC()
+	snapshots, err := operator.GetHealthHistory(cluster.Key(), now.Add(-since))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	switch format {
+	case constants.EncodingJSON:
+		if snapshots == nil {
+			snapshots = []storage.HealthSnapshot{}
+		}
+		bytes, err := json.MarshalIndent(snapshots, "", "  ")
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		fmt.Println(string(bytes))
+		return nil
+	}
+
+	if len(snapshots) == 0 {
+		env.Printf("No cluster health has been recorded in the last %v.\n", since)
+		return nil
+	}
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 1, '\t', 0)
+	fmt.Fprintf(w, "Time\tStatus\tDetails\n")
+	fmt.Fprintf(w, "----\t------\t-------\n")
+	for _, snapshot := range healthChanges(snapshots) {
+		status := color.GreenString(snapshot.Status)
+		if snapshot.IsDegraded() {
+			status = color.RedString(snapshot.Status)
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\n",
+			snapshot.Time.Format(constants.HumanDateFormatSeconds),
+			status,
+			describeHealthSnapshot(snapshot))
+	}
+	if err := w.Flush(); err != nil {
+		return trace.Wrap(err)
+	}
+	count, duration := degradedDuration(snapshots, now)
+	if count == 0 {
+		env.Printf("\nCluster has not been degraded in the last %v.\n", since)
+	} else {
+		env.Printf("\nCluster has been degraded %v time(s) for a total of %v in the last %v.\n",
+			count, duration.Round(time.Second), since)
+	}
+	return nil
+}
+
+// healthChanges returns the snapshots that record a change
+// in the cluster health
+func healthChanges(snapshots []storage.HealthSnapshot) (changes []storage.HealthSnapshot) {
+	for i, snapshot := range snapshots {
+		if i == 0 || !snapshot.Equals(snapshots[i-1]) {
+			changes = append(changes, snapshot)
+		}
+	}
+	return changes
+}
+
+// degradedDuration returns the number of times the cluster became degraded
+// and the total time it was degraded according to the specified snapshots.
+// Each snapshot is assumed to be valid until the next one
+func degradedDuration(snapshots []storage.HealthSnapshot, now time.Time) (count int, total time.Duration) {
+	for i, snapshot := range snapshots {
+		if !snapshot.IsDegraded() {
+			continue
+		}
+		if i == 0 || !snapshots[i-1].IsDegraded() {
+			count++
+		}
+		end := now
+		if i+1 < len(snapshots) {
+			end = snapshots[i+1].Time
+		}
+		total += end.Sub(snapshot.Time)
+	}
+	return count, total
+}
+
+// describeHealthSnapshot describes why the cluster was degraded
+// as of the specified snapshot
+func describeHealthSnapshot(snapshot storage.HealthSnapshot) string {
+	if !snapshot.IsDegraded() {
+		return "all status checks passed"
+	}
+	var details []string
+	for _, node := range snapshot.Nodes {
+		switch {
+		case len(node.FailedProbes) != 0:
+			details = append(details, fmt.Sprintf("%v (%v): %v",
+				node.Hostname, node.AdvertiseIP, strings.Join(node.FailedProbes, ", ")))
+		case node.Status != statusapi.NodeHealthy:
+			details = append(details, fmt.Sprintf("%v (%v): %v",
+				node.Hostname, node.AdvertiseIP, node.Status))
+		}
+	}
+	if len(snapshot.FailedHealthChecks) != 0 {
+		details = append(details, fmt.Sprintf("health checks: %v",
+			strings.Join(snapshot.FailedHealthChecks, ", ")))
+	}
+	if snapshot.Message != "" {
+		details = append(details, snapshot.Message)
+	}
+	if len(details) == 0 {
+		return snapshot.Reason.Description()
+	}
+	return strings.Join(details, "; ")
+}
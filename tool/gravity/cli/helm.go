@@ -35,6 +35,7 @@ import (
 	"github.com/gravitational/gravity/lib/ops/events"
 	"github.com/gravitational/gravity/lib/pack"
 	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/utils"
 	helmutils "github.com/gravitational/gravity/lib/utils/helm"
 
 	"github.com/ghodss/yaml"
@@ -49,6 +50,8 @@ type releaseInstallConfig struct {
 	Name string
 	// Namespace is a namespace to install release into.
 	Namespace string
+	// Flavor selects the values files of the charts declared in the image manifest.
+	Flavor string
 	// valuesConfig combines values set on the CLI.
 	valuesConfig
 	// registryConfig is registry configuration.
@@ -68,6 +71,8 @@ type releaseUpgradeConfig struct {
 	Release string
 	// Image is an application image to upgrade to, can be path or locator.
 	Image string
+	// Flavor selects the values files of the charts declared in the image manifest.
+	Flavor string
 	// valuesConfig combines values set on the CLI.
 	valuesConfig
 	// registryConfig is registry configuration.
@@ -172,6 +177,9 @@ func releaseInstall(env *localenv.LocalEnvironment, conf releaseInstallConfig) e
 		return trace.Wrap(err)
 	}
 	defer helmClient.Close()
+	if len(imageEnv.Manifest.Charts) != 0 {
+		return installCharts(env, helmClient, *imageEnv.Manifest, filepath.Join(tmp, "resources"), conf)
+	}
 	release, err := helmClient.Install(helm.InstallParameters{
 		Path:      filepath.Join(tmp, "resources"),
 		Values:    conf.Files,
@@ -183,10 +191,74 @@ func releaseInstall(env *localenv.LocalEnvironment, conf releaseInstallConfig) e
 		return trace.Wrap(err)
 	}
 	env.EmitAuditEvent(context.TODO(), events.ApplicationInstall, events.FieldsForRelease(release))
+	release.SetLocator(imageEnv.Manifest.Locator())
+	env.TrackRelease(context.TODO(), release)
 	env.PrintStep("Installed release %v", release.GetName())
 	return nil
 }
 
+// installCharts installs each chart declared in the application manifest
+// as a separate release named after the chart
+func installCharts(env *localenv.LocalEnvironment, helmClient helm.Client, manifest schema.Manifest, resourcesDir string, conf releaseInstallConfig) error {
+	if conf.Name != "" {
+		return trace.BadParameter("release name cannot be set for an image with charts, "+
+			"releases are named after the charts: %v", chartNames(manifest.Charts))
+	}
+	if err := checkFlavor(manifest, conf.Flavor); err != nil {
+		return trace.Wrap(err)
+	}
+	for _, chart := range manifest.Charts {
+		namespace := chart.Namespace
+		if namespace == "" {
+			namespace = conf.Namespace
+		}
+		release, err := helmClient.Install(helm.InstallParameters{
+			Path:      filepath.Join(resourcesDir, chart.Path),
+			Values:    chartValues(chart, resourcesDir, conf.Flavor, conf.Files),
+			Set:       conf.Values,
+			Name:      chart.Name,
+			Namespace: namespace,
+		})
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		env.EmitAuditEvent(context.TODO(), events.ApplicationInstall, events.FieldsForRelease(release))
+		release.SetLocator(manifest.Locator())
+		env.TrackRelease(context.TODO(), release)
+		env.PrintStep("Installed release %v", release.GetName())
+	}
+	return nil
+}
+
+// chartValues returns the list of values files to deploy the chart with.
+// Values files from the image come first so the files specified on
+// the command line take precedence
+func chartValues(chart schema.Chart, resourcesDir, flavor string, files []string) (values []string) {
+	for _, path := range chart.ValuesFor(flavor) {
+		values = append(values, filepath.Join(resourcesDir, path))
+	}
+	return append(values, files...)
+}
+
+// checkFlavor verifies that the flavor, if specified, is defined in the manifest
+func checkFlavor(manifest schema.Manifest, flavor string) error {
+	if flavor == "" {
+		return nil
+	}
+	if !utils.StringInSlice(manifest.FlavorNames(), flavor) {
+		return trace.NotFound("flavor %q is not defined in the application manifest, available flavors: %v",
+			flavor, manifest.FlavorNames())
+	}
+	return nil
+}
+
+func chartNames(charts []schema.Chart) (names []string) {
+	for _, chart := range charts {
+		names = append(names, chart.Name)
+	}
+	return names
+}
+
 func releaseList(env *localenv.LocalEnvironment, all bool) error {
 	helmClient, err := helm.NewClient(helm.ClientConfig{
 		DNSAddress: env.DNS.Addr(),
@@ -269,21 +341,46 @@ func releaseUpgrade(env *localenv.LocalEnvironment, conf releaseUpgradeConfig) e
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	release, err = helmClient.Upgrade(helm.UpgradeParameters{
+	params := helm.UpgradeParameters{
 		Release: release.GetName(),
 		Path:    filepath.Join(tmp, "resources"),
 		Values:  conf.Files,
 		Set:     conf.Values,
-	})
+	}
+	if len(imageEnv.Manifest.Charts) != 0 {
+		chart, err := findChart(imageEnv.Manifest.Charts, release.GetName())
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if err := checkFlavor(*imageEnv.Manifest, conf.Flavor); err != nil {
+			return trace.Wrap(err)
+		}
+		params.Path = filepath.Join(tmp, "resources", chart.Path)
+		params.Values = chartValues(*chart, filepath.Join(tmp, "resources"), conf.Flavor, conf.Files)
+	}
+	release, err = helmClient.Upgrade(params)
 	if err != nil {
 		return trace.Wrap(err)
 	}
 	env.EmitAuditEvent(context.TODO(), events.ApplicationUpgrade, events.FieldsForRelease(release))
+	release.SetLocator(imageEnv.Manifest.Locator())
+	env.TrackRelease(context.TODO(), release)
 	env.PrintStep("Upgraded release %v to version %v", release.GetName(),
 		imageEnv.Manifest.Metadata.ResourceVersion)
 	return nil
 }
 
+// findChart returns the chart the specified release has been installed from
+func findChart(charts []schema.Chart, release string) (*schema.Chart, error) {
+	for _, chart := range charts {
+		if chart.Name == release {
+			return &chart, nil
+		}
+	}
+	return nil, trace.NotFound("release %v does not match any chart in the application image, "+
+		"available charts: %v", release, chartNames(charts))
+}
+
 func releaseRollback(env *localenv.LocalEnvironment, conf releaseRollbackConfig) error {
 	helmClient, err := helm.NewClient(helm.ClientConfig{
 		DNSAddress: env.DNS.Addr(),
@@ -300,6 +397,7 @@ func releaseRollback(env *localenv.LocalEnvironment, conf releaseRollbackConfig)
 		return trace.Wrap(err)
 	}
 	env.EmitAuditEvent(context.TODO(), events.ApplicationRollback, events.FieldsForRelease(release))
+	env.TrackRelease(context.TODO(), release)
 	env.PrintStep("Rolled back release %v to %v", release.GetName(), release.GetChart())
 	return nil
 }
@@ -317,6 +415,7 @@ func releaseUninstall(env *localenv.LocalEnvironment, conf releaseUninstallConfi
 		return trace.Wrap(err)
 	}
 	env.EmitAuditEvent(context.TODO(), events.ApplicationUninstall, events.FieldsForRelease(release))
+	env.UntrackRelease(context.TODO(), release.GetName())
 	env.PrintStep("Uninstalled release %v", release.GetName())
 	return nil
 }
@@ -68,6 +68,14 @@ func startInstall(env *localenv.LocalEnvironment, config InstallConfig) error {
 		}
 		return trace.Wrap(err)
 	}
+	if err := importBaseImages(env, config); err != nil {
+		return trace.Wrap(err)
+	}
+	if config.Provision {
+		if err := provisionInstallNodes(env, config); err != nil {
+			return trace.Wrap(err)
+		}
+	}
 	strategy, err := NewInstallerConnectStrategy(env, config, cli.CommandArgs{
 		Parser: cli.ArgsParserFunc(parseArgs),
 	})
@@ -88,6 +96,80 @@ func startInstall(env *localenv.LocalEnvironment, config InstallConfig) error {
 	return trace.Wrap(err)
 }
 
+// importBaseImages makes sure that the cluster images the installed layered
+// cluster image is built on top of are available in the installer directory
+// and imports them from the tarballs specified with --base-image otherwise
+func importBaseImages(env *localenv.LocalEnvironment, config InstallConfig) error {
+	err := checkBaseImages(config.StateDir)
+	if err == nil || !trace.IsNotFound(err) || len(config.BaseImages) == 0 {
+		return trace.Wrap(err)
+	}
+	env.PrintStep("Importing base cluster images")
+	err = install.ImportBaseImages(config.StateDir, config.BaseImages, config.FieldLogger)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(checkBaseImages(config.StateDir))
+}
+
+// checkBaseImages makes sure that the base images of the cluster images
+// in the specified installer directory are available
+func checkBaseImages(stateDir string) error {
+	env, err := localenv.NewLocalEnvironment(localenv.LocalEnvironmentArgs{
+		StateDir:        stateDir,
+		ReadonlyBackend: true,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer env.Close()
+	return trace.Wrap(install.CheckBaseImages(env.Packages))
+}
+
+// checkPreviousInstallation makes sure that the host does not have the remnants
+// of a previous cluster installation.
+// If wipe is set, the remnants are removed the same way as with `gravity system uninstall`
+func checkPreviousInstallation(printer utils.Printer, wipe bool) error {
+	installDir, err := state.GravityInstallDir()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if ok, _ := utils.IsDirectory(installDir); ok && !wipe {
+		// The installer has already been started from this directory,
+		// the existing state is validated when connecting to the installer service
+		return nil
+	}
+	logger := log.WithField(trace.Component, "installer")
+	remnants, err := environ.DetectPreviousInstallation(logger)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if len(remnants) == 0 {
+		return nil
+	}
+	if !wipe {
+		return trace.BadParameter("detected the remnants of a previous cluster installation on this host:\n  %v\n"+
+			"Restart the installer with --wipe to remove them or clean up the host "+
+			"with `gravity system uninstall` before proceeding.",
+			strings.Join(remnants, "\n  "))
+	}
+	if utils.StringInSlice(state.GravityBinPaths, utils.Exe.Path) {
+		return trace.BadParameter("--wipe removes %v, run the installer from the installer "+
+			"tarball directory instead", utils.Exe.Path)
+	}
+	printer.PrintStep("Removing the remnants of a previous cluster installation")
+	logger.WithField("remnants", remnants).Info("Wipe host state.")
+	if err := environ.UninstallServices(printer, logger); err != nil {
+		return trace.Wrap(err, "failed to uninstall agent services, "+
+			"clean up the host with `gravity system uninstall` before proceeding")
+	}
+	if err := environ.UninstallSystem(printer, logger); err != nil {
+		return trace.Wrap(err, "failed to remove the previous installation, "+
+			"clean up the host with `gravity system uninstall` before proceeding")
+	}
+	return nil
+}
+
 func startInstallFromService(env *localenv.LocalEnvironment, config InstallConfig) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	interrupt := signals.NewInterruptHandler(ctx, cancel, InterruptSignals)
@@ -373,6 +455,32 @@ func remove(env *localenv.LocalEnvironment, c removeConfig) error {
 		return trace.Wrap(err)
 	}
 
+	wait := !c.checkOnly && !c.force
+	if wait {
+		env.PrintStep("Waiting for applications to replicate data from %v", server.Hostname)
+	}
+	blockers, err := checkNodeRemoval(context.TODO(), env, *server, wait)
+	if err != nil {
+		if !c.force {
+			return trace.Wrap(err, "failed to verify node removal, use --force to skip the checks")
+		}
+		log.Warnf("Failed to verify node removal: %v.", trace.DebugReport(err))
+	}
+	for _, blocker := range blockers {
+		env.PrintStep("[!] %v", blocker)
+	}
+	if c.checkOnly {
+		if len(blockers) != 0 {
+			return trace.BadParameter("node %v cannot be safely removed", server.Hostname)
+		}
+		env.PrintStep("Node %v can be safely removed", server.Hostname)
+		return nil
+	}
+	if len(blockers) != 0 && !c.force {
+		return trace.BadParameter("node %v cannot be safely removed, "+
+			"resolve the issues above or use --force to remove it anyway", server.Hostname)
+	}
+
 	if !c.confirmed {
 		err = enforceConfirmation(
 			"Please confirm removing %v (%v) from the cluster", server.Hostname, server.AdvertiseIP)
@@ -393,6 +501,14 @@ func remove(env *localenv.LocalEnvironment, c removeConfig) error {
 	}
 
 	fmt.Printf("launched operation %q, use 'gravity status' to poll its progress\n", key.OperationID)
+	operation, err := operator.GetSiteOperation(*key)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if operation.IsPendingApproval() {
+		fmt.Printf("the operation requires approval, it will start once another user "+
+			"approves it with 'gravity operation approve %v'\n", key.OperationID)
+	}
 	return nil
 }
 
@@ -822,6 +938,10 @@ press Ctrl+C two times in a row.
 If the you get disconnected from the terminal, you can reconnect to the installer
 agent by issuing 'gravity resume' command.
 
+If the node reboots during the installation, the installer service restarts
+automatically and continues the operation from the last completed phase.
+Use 'gravity resume' to reconnect to it.
+
 If the installation fails, use 'gravity plan' to inspect the state and
 'gravity resume' to continue the operation.
 See https://gravitational.com/gravity/docs/cluster/#managing-an-ongoing-operation for details.
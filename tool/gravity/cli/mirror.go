@@ -0,0 +1,111 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+
+	"github.com/gravitational/gravity/lib/archive"
+	"github.com/gravitational/gravity/lib/install"
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/mirror"
+	"github.com/gravitational/gravity/lib/system/signals"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+)
+
+// mirrorExport exports packages and container images of the cluster image
+// at the specified path (either a tarball or an unpacked installer directory)
+// into the output directory
+func mirrorExport(env *localenv.LocalEnvironment, path, outputDir string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupt := signals.WatchTerminationSignals(ctx, cancel, env)
+	defer interrupt.Close()
+
+	stateDir := path
+	if isDir, _ := utils.IsDirectory(path); !isDir {
+		env.PrintStep("Unpacking %v", path)
+		unpackedDir, err := unpackClusterImage(path)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		defer os.RemoveAll(unpackedDir)
+		stateDir = unpackedDir
+	}
+
+	tarballEnv, err := localenv.NewTarballEnvironment(localenv.TarballEnvironmentArgs{
+		StateDir: stateDir,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer tarballEnv.Close()
+
+	app, err := install.GetApp(tarballEnv.Apps)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	err = mirror.Export(ctx, mirror.ExportConfig{
+		Packages:    tarballEnv.Packages,
+		Apps:        tarballEnv.Apps,
+		Application: app.Package,
+		Dir:         outputDir,
+		Progress:    env,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	env.PrintStep("Mirror of %v has been exported to %v", app.Package, outputDir)
+	return nil
+}
+
+// mirrorServe serves the mirror directory created with mirrorExport
+func mirrorServe(env *localenv.LocalEnvironment, dir, registryAddr, packagesAddr string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupt := signals.WatchTerminationSignals(ctx, cancel, env)
+	defer interrupt.Close()
+
+	env.PrintStep("Serving docker registry on %v and packages on %v", registryAddr, packagesAddr)
+	return mirror.Serve(ctx, mirror.ServeConfig{
+		Dir:          dir,
+		RegistryAddr: registryAddr,
+		PackagesAddr: packagesAddr,
+	})
+}
+
+// unpackClusterImage unpacks the cluster image tarball at the specified
+// path into a temporary directory and returns the directory path
+func unpackClusterImage(path string) (dir string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	dir, err = ioutil.TempDir("", "mirror")
+	if err != nil {
+		return "", trace.ConvertSystemError(err)
+	}
+	if err := archive.Extract(f, dir); err != nil {
+		os.RemoveAll(dir)
+		return "", trace.Wrap(err)
+	}
+	return dir, nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gravitational/gravity/lib/localenv"
+	statusapi "github.com/gravitational/gravity/lib/status"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+)
+
+// statusNagios displays the cluster status as a Nagios plugin would.
+//
+// The command exits with the plugin exit code corresponding to the cluster
+// state and reports errors collecting the status as UNKNOWN
+func statusNagios(env *localenv.LocalEnvironment, printOptions printOptions) error {
+	err := status(env, printOptions)
+	if err == nil || utils.IsSilentExitCodeError(err) {
+		return err
+	}
+	log.WithError(err).Warn("Failed to collect cluster status.")
+	fmt.Fprintf(os.Stdout, "GRAVITY %v - %v\n", statusapi.CheckUnknown, trace.UserMessage(err))
+	return utils.NewSilentExitCodeError(int(statusapi.CheckUnknown))
+}
+
+// printStatusNagios outputs the cluster status in the Nagios plugin format:
+// the first line contains the state, the summary and the performance data
+// and the following lines contain the details about each problem found.
+//
+// Returns an exit code error if the cluster is not healthy
+func printStatusNagios(status clusterStatus, w io.Writer) error {
+	result := status.Check()
+	for _, probe := range status.FailedLocalProbes {
+		result.Details = append(result.Details, fmt.Sprintf("local check %v: %v", probe.Checker, probe.Detail))
+	}
+	fmt.Fprintf(w, "GRAVITY %v - %v | nodes=%v degraded_nodes=%v offline_nodes=%v "+
+		"failed_health_checks=%v alerts=%v active_operations=%v network_problems=%v etcd_quota_usage=%v%% "+
+		"expiring_certificates=%v\n",
+		result.State, result.Summary, result.Nodes, result.DegradedNodes, result.OfflineNodes,
+		result.FailedHealthChecks, result.ActiveAlerts, result.ActiveOperations, result.NetworkProblems,
+		result.EtcdQuotaUsage, result.ExpiringCertificates)
+	for _, detail := range result.Details {
+		fmt.Fprintln(w, detail)
+	}
+	return utils.NewSilentExitCodeError(int(result.State))
+}
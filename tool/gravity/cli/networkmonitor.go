@@ -0,0 +1,48 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/network/monitor"
+	"github.com/gravitational/gravity/lib/system/signals"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+)
+
+// networkMonitor runs the network monitor on this node until interrupted
+func networkMonitor(config monitor.Config) error {
+	client, _, err := utils.GetKubeClient("")
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	kubernetes := monitor.NewKubernetes(client)
+	config.Peers = kubernetes
+	config.Publisher = kubernetes
+	m, err := monitor.New(config)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupt := signals.WatchTerminationSignals(ctx, cancel, localenv.Silent(false))
+	defer interrupt.Close()
+	log.WithField("addr", m.Addr()).Info("Starting network monitor.")
+	return trace.Wrap(m.Run(ctx))
+}
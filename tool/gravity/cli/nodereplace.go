@@ -0,0 +1,246 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	libfsm "github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/rpc"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/update"
+	"github.com/gravitational/gravity/lib/update/nodereplace"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+)
+
+type nodeReplaceConfig struct {
+	// server is the hostname or IP address of the node to replace
+	server string
+	// newAddr is the advertise address of the replacement node
+	newAddr string
+	// sshUser is the SSH user to connect to the replacement node with
+	sshUser string
+	// sshKey is the path to the SSH private key
+	sshKey string
+	// sshPort is the SSH port of the replacement node
+	sshPort int
+	// sshHostKey is the optional SSH host key of the replacement node
+	sshHostKey string
+	// force specifies whether to remove the replaced node without draining it
+	force bool
+	// manual specifies whether the operation is executed manually
+	manual bool
+	// confirmed specifies whether the user has confirmed the operation
+	confirmed bool
+}
+
+func replaceNode(localEnv *localenv.LocalEnvironment, environ LocalEnvironmentFactory, config nodeReplaceConfig) error {
+	if !config.confirmed {
+		localEnv.Println(fmt.Sprintf(replaceNodeBanner, config.server, config.newAddr))
+		resp, err := confirm()
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if !resp {
+			localEnv.Println("Action cancelled by user.")
+			return nil
+		}
+	}
+	updateEnv, err := environ.NewUpdateEnv()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updateEnv.Close()
+	ctx := context.TODO()
+	updater, err := newUpdater(ctx, localEnv, updateEnv, nodeReplaceInitializer{config: config})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updater.Close()
+	if !config.manual {
+		err = updater.Run(ctx)
+		return trace.Wrap(err)
+	}
+	localEnv.Println(updateEnvironManualOperationBanner)
+	return nil
+}
+
+func executeNodeReplacePhase(env *localenv.LocalEnvironment, environ LocalEnvironmentFactory, params PhaseParams, operation ops.SiteOperation) error {
+	updateEnv, err := environ.NewUpdateEnv()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updateEnv.Close()
+	updater, err := getNodeReplaceUpdater(env, updateEnv, operation)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updater.Close()
+	err = updater.RunPhase(context.TODO(), params.PhaseID, params.Timeout, params.Force)
+	return trace.Wrap(err)
+}
+
+func setNodeReplacePhase(env *localenv.LocalEnvironment, environ LocalEnvironmentFactory, params SetPhaseParams, operation ops.SiteOperation) error {
+	updateEnv, err := environ.NewUpdateEnv()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updateEnv.Close()
+	updater, err := getNodeReplaceUpdater(env, updateEnv, operation)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updater.Close()
+	return updater.SetPhase(context.TODO(), params.PhaseID, params.State)
+}
+
+func rollbackNodeReplacePhase(env *localenv.LocalEnvironment, environ LocalEnvironmentFactory, params PhaseParams, operation ops.SiteOperation) error {
+	updateEnv, err := environ.NewUpdateEnv()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updateEnv.Close()
+	updater, err := getNodeReplaceUpdater(env, updateEnv, operation)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updater.Close()
+	err = updater.RollbackPhase(context.TODO(), params.PhaseID, params.Timeout, params.Force)
+	return trace.Wrap(err)
+}
+
+func completeNodeReplacePlan(env *localenv.LocalEnvironment, environ LocalEnvironmentFactory, operation ops.SiteOperation) error {
+	updateEnv, err := environ.NewUpdateEnv()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updateEnv.Close()
+	updater, err := getNodeReplaceUpdater(env, updateEnv, operation)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updater.Close()
+	return trace.Wrap(updater.Complete(nil))
+}
+
+func getNodeReplaceUpdater(env, updateEnv *localenv.LocalEnvironment, operation ops.SiteOperation) (*update.Updater, error) {
+	clusterEnv, err := env.NewClusterEnvironment()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	creds, err := libfsm.GetClientCredentials()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	runner := libfsm.NewAgentRunner(creds)
+	return nodeReplaceInitializer{}.newUpdater(context.TODO(), clusterEnv.Operator, operation,
+		env, updateEnv, clusterEnv, runner)
+}
+
+func (r nodeReplaceInitializer) validatePreconditions(*localenv.LocalEnvironment, ops.Operator, ops.Site) error {
+	return nil
+}
+
+func (r nodeReplaceInitializer) newOperation(operator ops.Operator, cluster ops.Site) (*ops.SiteOperationKey, error) {
+	req := ops.CreateReplaceNodeOperationRequest{
+		ClusterKey: cluster.Key(),
+		Server:     r.config.server,
+		NewAddr:    r.config.newAddr,
+		Force:      r.config.force,
+	}
+	if r.config.sshUser != "" || r.config.sshKey != "" {
+		req.SSH = &storage.SSHCredentials{
+			User:           r.config.sshUser,
+			Port:           r.config.sshPort,
+			PrivateKeyPath: r.config.sshKey,
+			HostKey:        r.config.sshHostKey,
+		}
+	}
+	key, err := operator.CreateReplaceNodeOperation(context.TODO(), req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return key, nil
+}
+
+func (nodeReplaceInitializer) newOperationPlan(
+	ctx context.Context,
+	operator ops.Operator,
+	cluster ops.Site,
+	operation ops.SiteOperation,
+	localEnv, updateEnv *localenv.LocalEnvironment,
+	clusterEnv *localenv.ClusterEnvironment,
+	leader *storage.Server,
+) (*storage.OperationPlan, error) {
+	plan, err := nodereplace.NewOperationPlan(operator, clusterEnv.Apps, operation,
+		cluster.ClusterState.Servers, *leader)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return plan, nil
+}
+
+func (nodeReplaceInitializer) newUpdater(
+	ctx context.Context,
+	operator ops.Operator,
+	operation ops.SiteOperation,
+	localEnv, updateEnv *localenv.LocalEnvironment,
+	clusterEnv *localenv.ClusterEnvironment,
+	runner rpc.AgentRepository,
+) (*update.Updater, error) {
+	config := nodereplace.Config{
+		Config: update.Config{
+			Operation:    &operation,
+			Operator:     operator,
+			Backend:      clusterEnv.Backend,
+			LocalBackend: updateEnv.Backend,
+			Silent:       localEnv.Silent,
+			Runner:       runner,
+			FieldLogger: logrus.WithFields(logrus.Fields{
+				trace.Component: "update:nodereplace",
+				"operation":     operation,
+			}),
+		},
+		Apps:              clusterEnv.Apps,
+		Client:            clusterEnv.Client,
+		ClusterPackages:   clusterEnv.ClusterPackages,
+		HostLocalPackages: localEnv.Packages,
+	}
+	return nodereplace.New(ctx, config)
+}
+
+// updateDeployRequest limits the agent deployment to the leader node
+// since all phases of the operation are executed there
+func (nodeReplaceInitializer) updateDeployRequest(req deployAgentsRequest) deployAgentsRequest {
+	req.clusterState.Servers = []storage.Server{*req.leader}
+	return req
+}
+
+type nodeReplaceInitializer struct {
+	config nodeReplaceConfig
+}
+
+const replaceNodeBanner = `Node %v will be replaced with node %v.
+The new node joins the cluster with the same role and labels, workloads are
+migrated from the replaced node which is then removed from the cluster.
+
+Are you sure?`
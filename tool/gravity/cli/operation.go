@@ -100,6 +100,20 @@ func executePhase(localEnv *localenv.LocalEnvironment, environ LocalEnvironmentF
 		return executeEnvironPhase(localEnv, environ, params, *op)
 	case ops.OperationUpdateConfig:
 		return executeConfigPhase(localEnv, environ, params, *op)
+	case ops.OperationUpdateNodeRole:
+		return executeNodeRolePhase(localEnv, environ, params, *op)
+	case ops.OperationReplaceNode:
+		return executeNodeReplacePhase(localEnv, environ, params, *op)
+	case ops.OperationRotateCertificates:
+		return executeRotateCertsPhase(localEnv, environ, params, *op)
+	case ops.OperationRotateSecretsKey:
+		return executeRotateSecretsKeyPhase(localEnv, environ, params, *op)
+	case ops.OperationChangeCIDR:
+		return executeChangeCIDRPhase(localEnv, environ, params, *op)
+	case ops.OperationRestore:
+		return executeRestorePhase(localEnv, environ, params, *op)
+	case ops.OperationEtcdRestore:
+		return executeEtcdRestorePhase(localEnv, environ, params, *op)
 	case ops.OperationGarbageCollect:
 		return executeGarbageCollectPhase(localEnv, params, op)
 	default:
@@ -122,6 +136,20 @@ func setPhase(env *localenv.LocalEnvironment, environ LocalEnvironmentFactory, p
 		err = setEnvironPhase(env, environ, params, *op)
 	case ops.OperationUpdateConfig:
 		err = setConfigPhase(env, environ, params, *op)
+	case ops.OperationUpdateNodeRole:
+		err = setNodeRolePhase(env, environ, params, *op)
+	case ops.OperationReplaceNode:
+		err = setNodeReplacePhase(env, environ, params, *op)
+	case ops.OperationRotateCertificates:
+		err = setRotateCertsPhase(env, environ, params, *op)
+	case ops.OperationRotateSecretsKey:
+		err = setRotateSecretsKeyPhase(env, environ, params, *op)
+	case ops.OperationChangeCIDR:
+		err = setChangeCIDRPhase(env, environ, params, *op)
+	case ops.OperationRestore:
+		err = setRestorePhase(env, environ, params, *op)
+	case ops.OperationEtcdRestore:
+		err = setEtcdRestorePhase(env, environ, params, *op)
 	case ops.OperationGarbageCollect:
 		err = setGarbageCollectPhase(env, params, op)
 	default:
@@ -151,6 +179,20 @@ func rollbackPhase(localEnv *localenv.LocalEnvironment, environ LocalEnvironment
 		return rollbackEnvironPhase(localEnv, environ, params, *op)
 	case ops.OperationUpdateConfig:
 		return rollbackConfigPhase(localEnv, environ, params, *op)
+	case ops.OperationUpdateNodeRole:
+		return rollbackNodeRolePhase(localEnv, environ, params, *op)
+	case ops.OperationReplaceNode:
+		return rollbackNodeReplacePhase(localEnv, environ, params, *op)
+	case ops.OperationRotateCertificates:
+		return rollbackRotateCertsPhase(localEnv, environ, params, *op)
+	case ops.OperationRotateSecretsKey:
+		return rollbackRotateSecretsKeyPhase(localEnv, environ, params, *op)
+	case ops.OperationChangeCIDR:
+		return rollbackChangeCIDRPhase(localEnv, environ, params, *op)
+	case ops.OperationRestore:
+		return rollbackRestorePhase(localEnv, environ, params, *op)
+	case ops.OperationEtcdRestore:
+		return rollbackEtcdRestorePhase(localEnv, environ, params, *op)
 	default:
 		return trace.BadParameter("operation type %q does not support plan rollback", op.Type)
 	}
@@ -172,6 +214,20 @@ func completeOperationPlan(localEnv *localenv.LocalEnvironment, environ LocalEnv
 		err = completeEnvironPlan(localEnv, environ, *op)
 	case ops.OperationUpdateConfig:
 		err = completeConfigPlan(localEnv, environ, *op)
+	case ops.OperationUpdateNodeRole:
+		err = completeNodeRolePlan(localEnv, environ, *op)
+	case ops.OperationReplaceNode:
+		err = completeNodeReplacePlan(localEnv, environ, *op)
+	case ops.OperationRotateCertificates:
+		err = completeRotateCertsPlan(localEnv, environ, *op)
+	case ops.OperationRotateSecretsKey:
+		err = completeRotateSecretsKeyPlan(localEnv, environ, *op)
+	case ops.OperationChangeCIDR:
+		err = completeChangeCIDRPlan(localEnv, environ, *op)
+	case ops.OperationRestore:
+		err = completeRestorePlan(localEnv, environ, *op)
+	case ops.OperationEtcdRestore:
+		err = completeEtcdRestorePlan(localEnv, environ, *op)
 	default:
 		return trace.BadParameter("operation type %q does not support plan completion", op.Type)
 	}
@@ -196,6 +252,29 @@ func completeClusterOperationPlan(localEnv *localenv.LocalEnvironment, operation
 	return ops.FailOperation(operation.Key(), clusterEnv.Operator, "completed manually")
 }
 
+// approveOperation approves the specified operation on behalf of the current user
+func approveOperation(env *localenv.LocalEnvironment, operationID string) error {
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	key := ops.SiteOperationKey{
+		AccountID:   cluster.AccountID,
+		SiteDomain:  cluster.Domain,
+		OperationID: operationID,
+	}
+	err = operator.ApproveOperation(context.TODO(), key)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	env.Printf("Operation %v has been approved.\n", operationID)
+	return nil
+}
+
 func getLastOperation(localEnv *localenv.LocalEnvironment, environ LocalEnvironmentFactory, operationID string) (*ops.SiteOperation, error) {
 	operations, err := getBackendOperations(localEnv, environ, operationID)
 	if err != nil {
@@ -392,7 +471,7 @@ type backendOperations struct {
 
 func getActiveOperationFromList(operations []ops.SiteOperation) (*ops.SiteOperation, error) {
 	for _, op := range operations {
-		if !op.IsCompleted() {
+		if !op.IsCompleted() && !op.IsRolledBack() {
 			return &op, nil
 		}
 	}
@@ -400,7 +479,7 @@ func getActiveOperationFromList(operations []ops.SiteOperation) (*ops.SiteOperat
 }
 
 func isActiveOperation(op ops.SiteOperation) bool {
-	return op.IsFailed() || !op.IsCompleted()
+	return !op.IsCompleted() && !op.IsRolledBack()
 }
 
 func (r oplist) String() string {
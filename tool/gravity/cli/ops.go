@@ -22,12 +22,14 @@ import (
 	"fmt"
 	_ "net/http/pprof"
 	"strings"
+	"time"
 
 	"github.com/gravitational/gravity/lib/app/docker"
 	appservice "github.com/gravitational/gravity/lib/app/service"
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/install"
+	"github.com/gravitational/gravity/lib/loc"
 	"github.com/gravitational/gravity/lib/localenv"
 	"github.com/gravitational/gravity/lib/ops"
 	"github.com/gravitational/gravity/lib/pack"
@@ -35,6 +37,7 @@ import (
 	"github.com/gravitational/gravity/lib/state"
 	"github.com/gravitational/gravity/lib/storage"
 	"github.com/gravitational/gravity/lib/utils"
+	"github.com/gravitational/gravity/lib/webapi"
 	"github.com/gravitational/gravity/tool/common"
 
 	"github.com/gravitational/license"
@@ -117,6 +120,11 @@ func uploadUpdate(env *localenv.LocalEnvironment, opsURL string) error {
 			"attempting again.")
 	}
 
+	trustPolicy, err := clusterOperator.GetImageTrustPolicy(cluster.Key())
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+
 	var tarballPackages pack.PackageService = env.Packages
 	if cluster.License != nil {
 		parsed, err := license.ParseLicense(cluster.License.Raw)
@@ -152,13 +160,25 @@ func uploadUpdate(env *localenv.LocalEnvironment, opsURL string) error {
 		return trace.Wrap(err)
 	}
 
+	deltaBase, err := checkDeltaImage(tarballPackages, *appPackage, cluster.App.Package)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	var labels map[string]string
+	if deltaBase != nil {
+		env.PrintStep("Image is a delta against the installed %v v%v", deltaBase.Name, deltaBase.Version)
+		labels = map[string]string{pack.DeltaBaseLabel: deltaBase.String()}
+	}
+
 	env.PrintStep("Importing application %v v%v", appPackage.Name, appPackage.Version)
 	_, err = appservice.PullApp(appservice.AppPullRequest{
-		SrcPack: tarballPackages,
-		SrcApp:  tarballApps,
-		DstPack: clusterPackages,
-		DstApp:  clusterApps,
-		Package: *appPackage,
+		SrcPack:     tarballPackages,
+		SrcApp:      tarballApps,
+		DstPack:     clusterPackages,
+		DstApp:      clusterApps,
+		Package:     *appPackage,
+		Labels:      labels,
+		SkipMissing: deltaBase != nil,
 	})
 	if err != nil {
 		if !trace.IsAlreadyExists(err) {
@@ -200,6 +220,8 @@ func uploadUpdate(env *localenv.LocalEnvironment, opsURL string) error {
 			AppService:   tarballApps,
 			ImageService: imageService,
 			Package:      *appPackage,
+			SkipMissing:  deltaBase != nil,
+			TrustPolicy:  trustPolicy,
 		})
 		if err != nil {
 			return trace.Wrap(err)
@@ -210,6 +232,31 @@ func uploadUpdate(env *localenv.LocalEnvironment, opsURL string) error {
 	return nil
 }
 
+// checkDeltaImage returns the cluster image the specified application package
+// has been built against if it is a delta image.
+// A delta image can only be applied to the cluster running its base image
+func checkDeltaImage(packages pack.PackageService, appPackage, installedPackage loc.Locator) (*loc.Locator, error) {
+	envelope, err := packages.ReadPackageEnvelope(appPackage)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	base, ok := envelope.RuntimeLabels[pack.DeltaBaseLabel]
+	if !ok {
+		return nil, nil
+	}
+	baseLoc, err := loc.ParseLocator(base)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if !baseLoc.IsEqualTo(installedPackage) {
+		return nil, trace.BadParameter("%v is a delta image built against %v "+
+			"and cannot be applied to the cluster running %v. "+
+			"Use a full cluster image or a delta image built against the installed version.",
+			appPackage, baseLoc, installedPackage)
+	}
+	return baseLoc, nil
+}
+
 // getRegistries returns a list of registry addresses in the cluster
 func getRegistries(ctx context.Context, env *localenv.LocalEnvironment, servers []storage.Server) ([]string, error) {
 	// in planets before certain version registry was running only on active master
@@ -252,6 +299,33 @@ func connectToOpsCenter(env *localenv.LocalEnvironment, opsCenterURL, username,
 	return nil
 }
 
+// connectToOpsCenterSSO logs into the specified Gravity Hub with single sign-on.
+// The user authenticates in a browser, possibly on another machine, and
+// the short-lived certificates issued for the user are saved on local disk
+func connectToOpsCenterSSO(env *localenv.LocalEnvironment, opsCenterURL, connectorID string, ttl time.Duration) error {
+	opsCenterURL = utils.ParseOpsCenterAddress(opsCenterURL, defaults.HTTPSPort)
+	result, err := webapi.DeviceLogin(context.TODO(), webapi.DeviceLoginConfig{
+		URL:         opsCenterURL,
+		ConnectorID: connectorID,
+		CertTTL:     ttl,
+		Insecure:    env.Insecure,
+		Prompt: func(login webapi.DeviceLoginResponse) {
+			env.Printf("To log in, open the following URL in a browser and confirm the code %v:\n\n    %v\n\n",
+				login.UserCode, login.VerificationURL)
+			env.Println("Waiting for the login to complete...")
+		},
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = env.Credentials.UpsertKey(opsCenterURL, result.Username, result.Key)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	env.Printf("Logged in to %v as %v.\n", opsCenterURL, result.Username)
+	return nil
+}
+
 // disconnectFromOpsCenter
 func disconnectFromOpsCenter(env *localenv.LocalEnvironment, opsCenterURL string) error {
 	err := env.Backend.DeleteLoginEntry(opsCenterURL)
@@ -90,6 +90,20 @@ To restart the installation, use 'gravity resume' after fixing the issues.
 		err = displayUpdateOperationPlan(localEnv, environ, op.Key(), format)
 	case ops.OperationUpdateConfig:
 		err = displayUpdateOperationPlan(localEnv, environ, op.Key(), format)
+	case ops.OperationUpdateNodeRole:
+		err = displayUpdateOperationPlan(localEnv, environ, op.Key(), format)
+	case ops.OperationReplaceNode:
+		err = displayUpdateOperationPlan(localEnv, environ, op.Key(), format)
+	case ops.OperationRotateCertificates:
+		err = displayUpdateOperationPlan(localEnv, environ, op.Key(), format)
+	case ops.OperationRotateSecretsKey:
+		err = displayUpdateOperationPlan(localEnv, environ, op.Key(), format)
+	case ops.OperationChangeCIDR:
+		err = displayUpdateOperationPlan(localEnv, environ, op.Key(), format)
+	case ops.OperationRestore:
+		err = displayUpdateOperationPlan(localEnv, environ, op.Key(), format)
+	case ops.OperationEtcdRestore:
+		err = displayUpdateOperationPlan(localEnv, environ, op.Key(), format)
 	case ops.OperationGarbageCollect:
 		err = displayClusterOperationPlan(localEnv, op.Key(), format)
 	default:
@@ -0,0 +1,219 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/gravitational/gravity/lib/cloudprovider/aws/provisioner"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/expand/roster"
+	libfsm "github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/rpc"
+	rpcclient "github.com/gravitational/gravity/lib/rpc/client"
+	"github.com/gravitational/gravity/lib/schema"
+	"github.com/gravitational/gravity/lib/system/signals"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+)
+
+// provisionInstallNodes provisions the nodes described by the provisioning spec
+// for the install operation. The provisioned nodes join the installer running
+// on this node automatically
+func provisionInstallNodes(env *localenv.LocalEnvironment, config InstallConfig) error {
+	spec, err := provisioner.ReadSpec(config.ProvisionSpec)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	portalURL := fmt.Sprintf("https://%v/portal/v1", defaults.InstallerAddr(config.AdvertiseAddr))
+	p, err := provisioner.New(provisioner.Config{
+		Spec:        *spec,
+		ClusterName: config.SiteDomain,
+		UserData:    provisioner.JoinUserData(portalURL, config.Token),
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	env.PrintStep("Provisioning nodes for cluster %v in %v", config.SiteDomain, spec.Region)
+	instances, err := p.Provision(context.TODO())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	printInstances(env, instances)
+	return nil
+}
+
+// expandCluster provisions count new nodes with the specified profile and
+// adds them to the local cluster
+func expandCluster(env *localenv.LocalEnvironment, specPath, role string, count int) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupt := signals.WatchTerminationSignals(ctx, cancel, env)
+	defer interrupt.Close()
+
+	spec, err := provisioner.ReadSpec(specPath)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, portalURL, token, err := getExpandParams(ctx, env, []string{role}, count)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	p, err := provisioner.New(provisioner.Config{
+		Spec:        *spec,
+		ClusterName: cluster.Domain,
+		UserData:    provisioner.JoinUserData(portalURL, token),
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	env.PrintStep("Provisioning %v %q node(s) for cluster %v", count, role, cluster.Domain)
+	instances, err := p.AddInstances(ctx, role, count)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	printInstances(env, instances)
+	env.PrintStep("Nodes will join the cluster once they have booted, use 'gravity status' to monitor progress")
+	return nil
+}
+
+// expandFromRoster joins the nodes listed in the roster file at the specified
+// path to the local cluster running at most parallel joins at a time
+func expandFromRoster(env *localenv.LocalEnvironment, rosterPath string, parallel int) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupt := signals.WatchTerminationSignals(ctx, cancel, env)
+	defer interrupt.Close()
+
+	nodes, err := roster.ReadRoster(rosterPath)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	var roles []string
+	for _, node := range nodes.Nodes {
+		if !utils.StringInSlice(roles, node.Role) {
+			roles = append(roles, node.Role)
+		}
+	}
+	cluster, portalURL, token, err := getExpandParams(ctx, env, roles, len(nodes.Nodes))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	joiner, err := roster.NewJoiner(roster.JoinerConfig{
+		PortalURL: portalURL,
+		Token:     token,
+		Agents:    &lazyAgentRepository{},
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	env.PrintStep("Joining %v node(s) to cluster %v", len(nodes.Nodes), cluster.Domain)
+	results, err := roster.Join(ctx, roster.Config{
+		Nodes:    nodes.Nodes,
+		Parallel: parallel,
+		IsMaster: func(role string) bool {
+			profile, err := cluster.App.Manifest.NodeProfiles.ByName(role)
+			return err == nil && profile.ServiceRole == schema.ServiceRoleMaster
+		},
+		Joiner:  joiner,
+		Printer: env,
+	})
+	env.Printf("%v of %v node(s) have joined the cluster\n",
+		len(results)-countFailed(results), len(nodes.Nodes))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// getExpandParams returns the local cluster along with the cluster portal URL
+// and a new short-lived token for the specified number of nodes with
+// the specified roles to join the cluster
+func getExpandParams(ctx context.Context, env *localenv.LocalEnvironment, roles []string, count int) (cluster *ops.Site, portalURL, token string, err error) {
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return nil, "", "", trace.Wrap(err)
+	}
+	cluster, err = operator.GetLocalSite()
+	if err != nil {
+		return nil, "", "", trace.Wrap(err)
+	}
+	masters := cluster.ClusterState.Servers.Masters()
+	if len(masters) == 0 {
+		return nil, "", "", trace.NotFound("cluster %v has no master nodes", cluster.Domain)
+	}
+	for _, role := range roles {
+		if _, err := cluster.App.Manifest.NodeProfiles.ByName(role); err != nil {
+			return nil, "", "", trace.BadParameter("unknown node role %q", role)
+		}
+	}
+	joinToken, err := operator.CreateJoinToken(ctx, ops.CreateJoinTokenRequest{
+		SiteKey: cluster.Key(),
+		TTL:     defaults.InternalJoinTokenTTL,
+		Roles:   roles,
+		MaxUses: count,
+	})
+	if err != nil {
+		return nil, "", "", trace.Wrap(err)
+	}
+	portalURL = fmt.Sprintf("https://%v:%v/portal/v1",
+		masters[0].AdvertiseIP, defaults.GravitySiteNodePort)
+	return cluster, portalURL, joinToken.Token, nil
+}
+
+func countFailed(results []roster.JoinResult) (failed int) {
+	for _, result := range results {
+		if result.Error != nil {
+			failed++
+		}
+	}
+	return failed
+}
+
+// lazyAgentRepository creates the agent client repository on first use
+// so the agent credentials are only required if the roster has nodes
+// with pre-started agents
+type lazyAgentRepository struct {
+	rpc.AgentRepository
+	once sync.Once
+	err  error
+}
+
+// GetClient returns a client to the agent running on the node with the specified address
+func (r *lazyAgentRepository) GetClient(ctx context.Context, addr string) (rpcclient.Client, error) {
+	r.once.Do(func() {
+		creds, err := libfsm.GetClientCredentials()
+		if err != nil {
+			r.err = trace.Wrap(err)
+			return
+		}
+		r.AgentRepository = libfsm.NewAgentRunner(creds)
+	})
+	if r.err != nil {
+		return nil, trace.Wrap(r.err)
+	}
+	return r.AgentRepository.GetClient(ctx, addr)
+}
+
+func printInstances(env *localenv.LocalEnvironment, instances []provisioner.Instance) {
+	for _, instance := range instances {
+		env.Printf("\t%v (%v) with profile %v\n", instance.ID, instance.PrivateIP, instance.Profile)
+	}
+}
@@ -24,10 +24,14 @@ import (
 
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/hardening"
 	"github.com/gravitational/gravity/lib/loc"
 	"github.com/gravitational/gravity/lib/modules"
+	"github.com/gravitational/gravity/lib/sbom"
+	"github.com/gravitational/gravity/lib/scan"
 	"github.com/gravitational/gravity/lib/schema"
 	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/encryption"
 	"github.com/gravitational/gravity/lib/utils"
 	"github.com/gravitational/gravity/tool/common"
 
@@ -49,6 +53,7 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.GID = g.Flag("gid", "Effective group ID for this operation. Must be >= 0.").Default(strconv.Itoa(defaults.PlaceholderGroupID)).Hidden().Int()
 	g.ProfileEndpoint = g.Flag("httpprofile", "Enable profiling endpoint on specified host/port i.e. localhost:6060.").Hidden().String()
 	g.ProfileTo = g.Flag("profile-dir", "Store periodic state snapshots in the specified directory.").Hidden().String()
+	g.MetricsAddr = g.Flag("metrics-addr", "Serve Prometheus metrics on the specified host/port i.e. :3013.").String()
 	g.UserLogFile = g.Flag("log-file", "Path to the log file with diagnostic information.").Default(defaults.GravityUserLog).String()
 	g.SystemLogFile = g.Flag("system-log-file", "Path to the log file with system level logs.").Default(defaults.GravitySystemLog).Hidden().String()
 
@@ -70,10 +75,15 @@ func RegisterCommands(app *kingpin.Application) *Application {
 		modules.Get().InstallModes())).Default(constants.InstallModeCLI).Hidden().String()
 	g.InstallCmd.DockerDevice = g.InstallCmd.Flag("docker-device", "Device to use for docker storage.").Hidden().String()
 	g.InstallCmd.SystemDevice = g.InstallCmd.Flag("system-device", "Device to use for system data directory.").Hidden().String()
+	g.InstallCmd.EtcdDevice = g.InstallCmd.Flag("etcd-device", "Device to use for etcd data directory.").Hidden().String()
+	g.InstallCmd.AutoPartition = g.InstallCmd.Flag("auto-partition", "Place system data, Docker devicemapper storage and etcd data on unused block devices of this node, creating logical volumes and file systems as necessary.").Bool()
 	g.InstallCmd.Mounts = configure.KeyValParam(g.InstallCmd.Flag("mount", "One or several mount overrides in the following format: <mount-name>:<path>, e.g. data:/var/lib/data."))
 	g.InstallCmd.PodCIDR = g.InstallCmd.Flag("pod-network-cidr", "Subnet range for Kubernetes pods network. Must be a minimum of /16.").Default(defaults.PodSubnet).String()
 	g.InstallCmd.ServiceCIDR = g.InstallCmd.Flag("service-cidr", "Subnet range for Kubernetes service networ.").Default(defaults.ServiceSubnet).String()
 	g.InstallCmd.VxlanPort = g.InstallCmd.Flag("vxlan-port", "Custom overlay network port.").Default(strconv.Itoa(defaults.VxlanPort)).Int()
+	g.InstallCmd.NetworkEncryption = g.InstallCmd.Flag("network-encryption",
+		fmt.Sprintf("Encrypt the pod traffic between the nodes. Recognized are: %v.", strings.Join(constants.NetworkEncryptionModes, ", "))).
+		Enum(constants.NetworkEncryptionModes...)
 	g.InstallCmd.DNSListenAddrs = g.InstallCmd.Flag("dns-listen-addr", "Custom listen address for in-cluster DNS.").
 		Default(defaults.DNSListenAddr).IPList()
 	g.InstallCmd.DNSPort = g.InstallCmd.Flag("dns-port", "Custom listen port for in-cluster DNS.").
@@ -92,9 +102,34 @@ func RegisterCommands(app *kingpin.Application) *Application {
 		OverrideDefaultFromEnvar(constants.ServiceGroupEnvVar).
 		String()
 	g.InstallCmd.GCENodeTags = g.InstallCmd.Flag("gce-node-tag", "Override node tag on the instance in GCE required for load balanacing. Defaults to the cluster name.").Strings()
+	g.InstallCmd.GCESubnetwork = g.InstallCmd.Flag("gce-subnetwork", "GCE subnetwork to create internal load balancers in. Generates the GCE cloud provider configuration unless one is provided in the cluster configuration.").String()
+	g.InstallCmd.GCEStateDisk = g.InstallCmd.Flag("gce-state-disk", "Device name of the attached GCE persistent disk to use for the system state directory.").String()
+	g.InstallCmd.AzureVnet = g.InstallCmd.Flag("azure-vnet", "Azure virtual network of the cluster. Used to generate the Azure cloud provider configuration.").String()
+	g.InstallCmd.AzureVnetResourceGroup = g.InstallCmd.Flag("azure-vnet-resource-group", "Resource group of the Azure virtual network. Defaults to the resource group of the installer node.").String()
+	g.InstallCmd.AzureSubnet = g.InstallCmd.Flag("azure-subnet", "Azure subnet of the cluster.").String()
+	g.InstallCmd.AzureSecurityGroup = g.InstallCmd.Flag("azure-security-group", "Azure network security group attached to the cluster subnet.").String()
+	g.InstallCmd.AzureRouteTable = g.InstallCmd.Flag("azure-route-table", "Azure route table for pod network routes.").String()
+	g.InstallCmd.VSphereConfig = g.InstallCmd.Flag("vsphere-config", "Path to the file with the vSphere environment configuration (vCenter server, datacenter, datastore, folder and master anti-affinity rule). Implies --cloud-provider=vsphere.").String()
+	g.InstallCmd.AzureAvailabilitySet = g.InstallCmd.Flag("azure-availability-set", "Azure availability set with master nodes. Load balancers created by the cloud provider, including the internal API server load balancer, use its nodes as backends.").String()
 	g.InstallCmd.DNSHosts = g.InstallCmd.Flag("dns-host", "Specify an IP address that will be returned for the given domain within the cluster. Accepts <domain>/<ip> format. Can be specified multiple times.").Hidden().Strings()
 	g.InstallCmd.DNSZones = g.InstallCmd.Flag("dns-zone", "Specify an upstream server for the given zone within the cluster. Accepts <zone>/<nameserver> format where <nameserver> can be either <ip> or <ip>:<port>. Can be specified multiple times.").Strings()
+	g.InstallCmd.DNSUpstreams = g.InstallCmd.Flag("dns-upstream", "Specify an upstream nameserver the cluster DNS will forward queries for external names to, instead of the ones from the host's resolv.conf. Accepts <ip> or <ip>:<port> format. Can be specified multiple times.").Strings()
 	g.InstallCmd.Remote = g.InstallCmd.Flag("remote", "Do not use this node in the cluster.").Bool()
+	g.InstallCmd.Labels = g.InstallCmd.Flag("label", "Custom label to apply to this node in the key=value format. Can be specified multiple times.").Strings()
+	g.InstallCmd.Taints = g.InstallCmd.Flag("taint", "Custom taint to apply to this node in the key=value:effect format. Can be specified multiple times.").Strings()
+	g.InstallCmd.HTTPProxy = g.InstallCmd.Flag("http-proxy", "Proxy server for outbound HTTP connections, e.g. http://proxy.example.com:3128. Persisted in the cluster configuration.").String()
+	g.InstallCmd.HTTPSProxy = g.InstallCmd.Flag("https-proxy", "Proxy server for outbound HTTPS connections, e.g. http://proxy.example.com:3128. Persisted in the cluster configuration.").String()
+	g.InstallCmd.NoProxy = g.InstallCmd.Flag("no-proxy", "Comma-separated list of hosts, domains and subnets to exclude from proxying in addition to the cluster-internal destinations. Persisted in the cluster configuration.").String()
+	g.InstallCmd.Provision = g.InstallCmd.Flag("provision", "Provision the cluster nodes with the cloud provider integration. Requires --cloud-provider=aws, --cluster and --provision-spec.").Bool()
+	g.InstallCmd.ProvisionSpec = g.InstallCmd.Flag("provision-spec", "Path to the spec describing the nodes to provision.").String()
+	g.InstallCmd.Demo = g.InstallCmd.Flag("demo", "Install a non-production cluster for evaluation on a laptop or a CI machine. Relaxes CPU, RAM and disk preflight requirements and picks the smallest install flavor unless --flavor is given.").Bool()
+	g.InstallCmd.FIPS = g.InstallCmd.Flag("fips", "Install the cluster in FIPS 140-2 mode. Requires a FIPS build of gravity. Persisted in the cluster configuration.").Bool()
+	g.InstallCmd.Hardening = g.InstallCmd.Flag("hardening", fmt.Sprintf("Install the cluster with the hardened configuration profile, one of %v. Persisted in the cluster configuration.", hardening.Profiles)).Enum(hardening.ProfilesAsStrings()...)
+	g.InstallCmd.NetworkPolicy = g.InstallCmd.Flag("network-policy", "Install the baseline of network policies that deny all ingress traffic except for the traffic of the system components. Requires calico networking. Persisted in the cluster configuration.").Bool()
+	g.InstallCmd.CACert = g.InstallCmd.Flag("ca-cert", "Path to the PEM-encoded certificate of an intermediate certificate authority to issue the cluster certificates instead of a generated one. Requires --ca-key.").String()
+	g.InstallCmd.CAKey = g.InstallCmd.Flag("ca-key", "Path to the PEM-encoded private key of the certificate authority given with --ca-cert.").String()
+	g.InstallCmd.BaseImages = g.InstallCmd.Flag("base-image", "Path to the tarball of the cluster image the installed layered cluster image is built on top of. Repeat for every image in the chain, starting from the bottom one.").Strings()
+	g.InstallCmd.Wipe = g.InstallCmd.Flag("wipe", "Remove the remnants of a previous cluster installation from this host before installing. Performs the same cleanup as 'gravity system uninstall'.").Bool()
 	g.InstallCmd.FromService = g.InstallCmd.Flag("from-service", "Run in service mode.").Hidden().Bool()
 
 	g.JoinCmd.CmdClause = g.Command("join", "Join the existing cluster or an on-going install operation.")
@@ -104,10 +139,15 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.JoinCmd.Role = g.JoinCmd.Flag("role", "Role of this node.").String()
 	g.JoinCmd.DockerDevice = g.JoinCmd.Flag("docker-device", "Docker device to use.").Hidden().String()
 	g.JoinCmd.SystemDevice = g.JoinCmd.Flag("system-device", "Device to use for system data directory.").Hidden().String()
+	g.JoinCmd.EtcdDevice = g.JoinCmd.Flag("etcd-device", "Device to use for etcd data directory.").Hidden().String()
+	g.JoinCmd.AutoPartition = g.JoinCmd.Flag("auto-partition", "Place system data, Docker devicemapper storage and etcd data on unused block devices of this node, creating logical volumes and file systems as necessary.").Bool()
 	g.JoinCmd.ServerAddr = g.JoinCmd.Flag("server-addr", "Address of the agent server.").Hidden().String()
 	g.JoinCmd.Mounts = configure.KeyValParam(g.JoinCmd.Flag("mount", "One or several mounts in form <mount-name>:<path>, e.g. data:/var/lib/data."))
 	g.JoinCmd.CloudProvider = g.JoinCmd.Flag("cloud-provider", "[DEPRECATED] This flag has no effect and will be removed in a future version.").String()
 	g.JoinCmd.OperationID = g.JoinCmd.Flag("operation-id", "ID of the operation that was created via UI.").Hidden().String()
+	g.JoinCmd.Labels = g.JoinCmd.Flag("label", "Custom label to apply to this node in the key=value format. Can be specified multiple times.").Strings()
+	g.JoinCmd.Taints = g.JoinCmd.Flag("taint", "Custom taint to apply to this node in the key=value:effect format. Can be specified multiple times.").Strings()
+	g.JoinCmd.NodePool = g.JoinCmd.Flag("pool", "Name of the node pool to join. The node role must match the profile of the pool.").String()
 	g.JoinCmd.FromService = g.JoinCmd.Flag("from-service", "Run in service mode.").Hidden().Bool()
 
 	g.AutoJoinCmd.CmdClause = g.Command("autojoin", "Use cloud provider data to join a node to existing cluster.")
@@ -128,8 +168,32 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.RemoveCmd.CmdClause = g.Command("remove", "Remove a node from the cluster.")
 	g.RemoveCmd.Node = g.RemoveCmd.Arg("node", "Node to remove: can be IP address, hostname or name from `kubectl get nodes` output).").
 		Required().String()
-	g.RemoveCmd.Force = g.RemoveCmd.Flag("force", "Force removal of an offline node.").Bool()
+	g.RemoveCmd.Force = g.RemoveCmd.Flag("force", "Force removal of an offline node or a node that fails safety checks.").Bool()
 	g.RemoveCmd.Confirm = g.RemoveCmd.Flag("confirm", "Do not ask for confirmation.").Bool()
+	g.RemoveCmd.CheckOnly = g.RemoveCmd.Flag("check-only", "Only report issues that block the node removal without removing the node.").Bool()
+
+	g.NodeCmd.CmdClause = g.Command("node", "Manage cluster nodes.")
+
+	g.NodePromoteCmd.CmdClause = g.NodeCmd.Command("promote", "Promote a regular node to master.")
+	g.NodePromoteCmd.Node = g.NodePromoteCmd.Arg("node", "Node to promote: can be IP address or hostname.").Required().String()
+	g.NodePromoteCmd.Manual = g.NodePromoteCmd.Flag("manual", "Do not start the operation automatically.").Short('m').Bool()
+	g.NodePromoteCmd.Confirm = g.NodePromoteCmd.Flag("confirm", "Do not ask for confirmation.").Bool()
+
+	g.NodeDemoteCmd.CmdClause = g.NodeCmd.Command("demote", "Demote a master to a regular node.")
+	g.NodeDemoteCmd.Node = g.NodeDemoteCmd.Arg("node", "Node to demote: can be IP address or hostname.").Required().String()
+	g.NodeDemoteCmd.Manual = g.NodeDemoteCmd.Flag("manual", "Do not start the operation automatically.").Short('m').Bool()
+	g.NodeDemoteCmd.Confirm = g.NodeDemoteCmd.Flag("confirm", "Do not ask for confirmation.").Bool()
+
+	g.NodeReplaceCmd.CmdClause = g.NodeCmd.Command("replace", "Replace a node with a new node with the same role and labels.")
+	g.NodeReplaceCmd.Node = g.NodeReplaceCmd.Arg("node", "Node to replace: can be IP address or hostname.").Required().String()
+	g.NodeReplaceCmd.With = g.NodeReplaceCmd.Flag("with", "Advertise address of the replacement node.").Required().String()
+	g.NodeReplaceCmd.SSHUser = g.NodeReplaceCmd.Flag("ssh-user", "SSH user to connect to the replacement node with. If unspecified, the join is executed by the agent running on the replacement node.").String()
+	g.NodeReplaceCmd.SSHKey = g.NodeReplaceCmd.Flag("ssh-key", "Path to the SSH private key to connect to the replacement node with.").String()
+	g.NodeReplaceCmd.SSHPort = g.NodeReplaceCmd.Flag("ssh-port", "SSH port of the replacement node.").Default(strconv.Itoa(defaults.SSHPort)).Int()
+	g.NodeReplaceCmd.SSHHostKey = g.NodeReplaceCmd.Flag("ssh-host-key", "Public SSH host key of the replacement node in the authorized_keys format. If unspecified, the host key is not verified.").String()
+	g.NodeReplaceCmd.Force = g.NodeReplaceCmd.Flag("force", "Remove the replaced node even if it is offline, without draining it.").Bool()
+	g.NodeReplaceCmd.Manual = g.NodeReplaceCmd.Flag("manual", "Do not start the operation automatically.").Short('m').Bool()
+	g.NodeReplaceCmd.Confirm = g.NodeReplaceCmd.Flag("confirm", "Do not ask for confirmation.").Bool()
 
 	g.ResumeCmd.CmdClause = g.Command("resume", "Resume the last aborted operation.")
 	g.ResumeCmd.OperationID = g.ResumeCmd.Flag("operation-id", "ID of the active operation. It not specified, the last operation will be used.").Hidden().String()
@@ -186,6 +250,23 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.UpgradeCmd.Force = g.UpgradeCmd.Flag("force", "Force phase execution even if pre-conditions are not satisfied.").Bool()
 	g.UpgradeCmd.Resume = g.UpgradeCmd.Flag("resume", "Resume upgrade from the last failed step.").Bool()
 	g.UpgradeCmd.SkipVersionCheck = g.UpgradeCmd.Flag("skip-version-check", "Bypass version compatibility check.").Hidden().Bool()
+	g.UpgradeCmd.Strategy = g.UpgradeCmd.Flag("strategy", fmt.Sprintf("Upgrade strategy, one of %v. Overrides the strategy from the cluster image manifest.", schema.UpgradeStrategies)).Enum(schema.UpgradeStrategies...)
+	g.UpgradeCmd.CanaryNode = g.UpgradeCmd.Flag("canary-node", "Hostname or advertise address of the node to upgrade first with the canary strategy.").String()
+	g.UpgradeCmd.SoakPeriod = g.UpgradeCmd.Flag("soak-period", "Duration to observe the canary node before upgrading the remaining nodes. If unspecified, the upgrade waits for an explicit approval.").Duration()
+	g.UpgradeCmd.BatchSize = g.UpgradeCmd.Flag("batch-size", "Number of regular nodes to upgrade in parallel. Master nodes are always upgraded one at a time.").Int()
+	g.UpgradeCmd.MaxUnavailable = g.UpgradeCmd.Flag("max-unavailable", "Percentage of regular nodes to upgrade in parallel, e.g. 25%. Ignored if --batch-size is specified.").String()
+	g.UpgradeCmd.Approve = g.UpgradeCmd.Flag("approve", "Approve the upgraded canary node and upgrade the remaining nodes.").Bool()
+	g.UpgradeCmd.AutoRollback = g.UpgradeCmd.Flag("auto-rollback", "Automatically roll back the upgrade if it fails.").Bool()
+	g.UpgradeCmd.AutoRollbackRetries = g.UpgradeCmd.Flag("auto-rollback-retries", "Number of times to resume a failed upgrade before rolling it back.").Default("1").Int()
+	g.UpgradeCmd.Schedule = g.UpgradeCmd.Flag("schedule", `Weekly maintenance window to execute node-disruptive upgrade steps in, e.g. "Sat 02:00-06:00" (in local time).`).String()
+	g.UpgradeCmd.PlanOnly = g.UpgradeCmd.Flag("plan-only", "Report deprecated API usage, removed features, required disk space and estimated downtime of the upgrade without starting it.").Bool()
+	g.UpgradeCmd.Stage = g.UpgradeCmd.Flag("stage", "Download the system packages of the upgrade to all nodes without starting it.").Bool()
+	g.UpgradeCmd.DrainGracePeriod = g.UpgradeCmd.Flag("drain-grace-period", "Termination grace period for pods evicted from drained nodes. If unspecified, the grace period of each pod is used.").Duration()
+	g.UpgradeCmd.DrainTimeout = g.UpgradeCmd.Flag("drain-timeout", fmt.Sprintf("Maximum time to drain a single node. Defaults to %v.", defaults.DrainTimeout)).Duration()
+	g.UpgradeCmd.DrainForceAfter = g.UpgradeCmd.Flag("drain-force-after", "Delete the pods that could not be evicted from a node within the specified time regardless of their disruption budgets.").Duration()
+	g.UpgradeCmd.DrainIgnorePDB = g.UpgradeCmd.Flag("drain-ignore-pdb", "Delete pods from drained nodes without respecting pod disruption budgets.").Bool()
+	g.UpgradeCmd.DrainSkipDaemonSets = g.UpgradeCmd.Flag("drain-skip-daemonsets", "Leave pods managed by daemon sets on drained nodes.").Default("true").Bool()
+	g.UpgradeCmd.DrainSkipNodes = g.UpgradeCmd.Flag("drain-skip-node", "Hostname or advertise address of the node not to drain. Can be specified multiple times.").Strings()
 
 	g.UpdateUploadCmd.CmdClause = g.UpdateCmd.Command("upload", "Upload update package to locally running site").Hidden()
 	g.UpdateUploadCmd.OpsCenterURL = g.UpdateUploadCmd.Flag("ops-url", "Optional Gravity Hub URL to upload new packages to (defaults to local gravity site)").Default(defaults.GravityServiceURL).String()
@@ -202,20 +283,50 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.UpdateSystemCmd.RuntimePackage = Locator(g.UpdateSystemCmd.Flag("runtime-package", "The name of the runtime package to update to").Required())
 
 	g.StatusCmd.CmdClause = g.Command("status", "Display overall cluster status.")
-	g.StatusCmd.Token = g.StatusCmd.Flag("token", "Display only the cluster join token.").Bool()
+	g.StatusCmd.Token = g.StatusCmd.Flag("token", "Issue a new short-lived cluster join token and display only the token.").Bool()
 	g.StatusCmd.Tail = g.StatusCmd.Flag("tail", "Tail logs of the currently running operation until it completes.").Bool()
 	g.StatusCmd.OperationID = g.StatusCmd.Flag("operation-id", "Check status of the operation with the given ID.").Short('o').String()
 	g.StatusCmd.Seconds = g.StatusCmd.Flag("seconds", "Continuously display status every N seconds.").Short('s').Int()
-	g.StatusCmd.Output = common.Format(g.StatusCmd.Flag("output", "Output format: json or text.").Default(string(constants.EncodingText)))
+	g.StatusCmd.Output = common.Format(g.StatusCmd.Flag("output", "Output format: json, text or nagios.").Default(string(constants.EncodingText)))
+	g.StatusCmd.Flag("format", "Alias for --output.").Hidden().SetValue(g.StatusCmd.Output)
+
+	g.StatusClusterCmd.CmdClause = g.StatusCmd.Command("cluster", "Display overall cluster status.").Default()
+
+	g.StatusHistoryCmd.CmdClause = g.StatusCmd.Command("history", "Display the cluster health history.")
+	g.StatusHistoryCmd.Since = g.StatusHistoryCmd.Flag("since", "Display the health history for the specified duration, e.g. 24h.").Default(defaults.HealthHistorySince.String()).Duration()
+
+	g.StatusSNMPAgentCmd.CmdClause = g.StatusCmd.Command("snmp-agent", "Run the SNMP sub-agent exposing the cluster health via AgentX.")
+	g.StatusSNMPAgentCmd.Addr = g.StatusSNMPAgentCmd.Flag("agentx-addr", "Address of the AgentX master agent: path to the Unix socket or tcp:host:port.").Default(defaults.AgentXAddr).String()
+	g.StatusSNMPAgentCmd.RootOID = g.StatusSNMPAgentCmd.Flag("root-oid", "Object identifier of the cluster health subtree.").Default(defaults.SNMPRootOID).String()
+	g.StatusSNMPAgentCmd.Interval = g.StatusSNMPAgentCmd.Flag("interval", "How often to refresh the cluster status.").Default(defaults.SNMPStatusInterval.String()).Duration()
 
 	// reset cluster state, for debugging/emergencies
 	g.StatusResetCmd.CmdClause = g.Command("status-reset", "Reset the cluster state to 'active'").Hidden()
 
 	// backup
-	g.BackupCmd.CmdClause = g.Command("backup", "Launch the cluster's backup hook.")
-	g.BackupCmd.Tarball = g.BackupCmd.Arg("to", "Tarball to create with results of the backup hook.").Required().String()
-	g.BackupCmd.Timeout = g.BackupCmd.Flag("timeout", "Active deadline for the backup job, in Go duration format (e.g. 30s, 5m, etc.). If not specified, the value from manifest is used. If that is not specified as well, the default value of 20 minutes is used.").Duration()
-	g.BackupCmd.Follow = g.BackupCmd.Flag("follow", "Output backup job logs to the stdout.").Bool()
+	g.BackupCmd.CmdClause = g.Command("backup", "Back up the cluster.")
+
+	g.BackupHookCmd.CmdClause = g.BackupCmd.Command("hook", "Launch the cluster's backup hook.").Default()
+	g.BackupHookCmd.Tarball = g.BackupHookCmd.Arg("to", "Tarball to create with results of the backup hook.").Required().String()
+	g.BackupHookCmd.Timeout = g.BackupHookCmd.Flag("timeout", "Active deadline for the backup job, in Go duration format (e.g. 30s, 5m, etc.). If not specified, the value from manifest is used. If that is not specified as well, the default value of 20 minutes is used.").Duration()
+	g.BackupHookCmd.Follow = g.BackupHookCmd.Flag("follow", "Output backup job logs to the stdout.").Bool()
+	g.BackupHookCmd.App = g.BackupHookCmd.Flag("app", "Back up only the Kubernetes resources and the data of the specified application release.").String()
+
+	g.BackupScheduleCmd.CmdClause = g.BackupCmd.Command("schedule", "Configure scheduled backups of the cluster etcd database, state and registry metadata. Displays the current schedule if no schedule is specified.")
+	g.BackupScheduleCmd.Schedule = g.BackupScheduleCmd.Arg("schedule", `Backup schedule in cron format, e.g. "0 2 * * *" to back up daily at 02:00 UTC.`).String()
+	g.BackupScheduleCmd.Directory = g.BackupScheduleCmd.Flag("to", fmt.Sprintf("Directory on the master node to store the backups in. Defaults to %v.", defaults.ScheduledBackupDir)).String()
+	g.BackupScheduleCmd.Retain = g.BackupScheduleCmd.Flag("retain", fmt.Sprintf("Number of the latest backups to keep. Defaults to %v.", defaults.BackupRetain)).Int()
+	g.BackupScheduleCmd.Remove = g.BackupScheduleCmd.Flag("remove", "Remove the backup schedule.").Bool()
+
+	g.BackupUnpackCmd.CmdClause = g.BackupCmd.Command("unpack", "Decrypt and extract the backup archive uploaded to the off-site destination.")
+	g.BackupUnpackCmd.Archive = g.BackupUnpackCmd.Arg("archive", "Path to the backup archive.").Required().String()
+	g.BackupUnpackCmd.Directory = g.BackupUnpackCmd.Arg("dir", "Directory to extract the backup into.").Required().String()
+	g.BackupUnpackCmd.KeyFile = g.BackupUnpackCmd.Flag("key-file", "Path to the file with the encryption key of the backup.").Required().String()
+
+	g.BackupVerifyCmd.CmdClause = g.BackupCmd.Command("verify", "Verify that the backup is restorable.")
+	g.BackupVerifyCmd.Backup = g.BackupVerifyCmd.Arg("backup", "Path to the backup directory or the backup archive uploaded to the off-site destination.").Required().String()
+	g.BackupVerifyCmd.KeyFile = g.BackupVerifyCmd.Flag("key-file", "Path to the file with the encryption key of the backup archive.").String()
+	g.BackupVerifyCmd.TestRestore = g.BackupVerifyCmd.Flag("test-restore", "Restore the backup into a temporary data directory without modifying the cluster.").Bool()
 
 	g.CheckCmd.CmdClause = g.Command("check", "Check the node environment to satisfy cluster manifest requirements.")
 	g.CheckCmd.ManifestFile = g.CheckCmd.Arg("manifest", "Path to the cluster manifest file.").Default(defaults.ManifestFileName).String()
@@ -223,9 +334,13 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.CheckCmd.AutoFix = g.CheckCmd.Flag("autofix", "Attempt to auto-fix some of the problems.").Bool()
 
 	// restore
-	g.RestoreCmd.CmdClause = g.Command("restore", "Launch the cluster's restore hook.")
-	g.RestoreCmd.Tarball = g.RestoreCmd.Arg("from", "Tarball with backup data to restore from.").Required().String()
+	g.RestoreCmd.CmdClause = g.Command("restore", "Launch the cluster's restore hook or restore the cluster from a scheduled backup.")
+	g.RestoreCmd.Tarball = g.RestoreCmd.Arg("tarball", "Tarball with backup data to restore from with the restore hook.").String()
+	g.RestoreCmd.From = g.RestoreCmd.Flag("from", "Scheduled backup directory to restore the cluster from.").String()
+	g.RestoreCmd.Manual = g.RestoreCmd.Flag("manual", "Do not start the restore operation automatically.").Short('m').Bool()
+	g.RestoreCmd.Confirm = g.RestoreCmd.Flag("confirm", "Do not ask for confirmation.").Bool()
 	g.RestoreCmd.Follow = g.RestoreCmd.Flag("follow", "Output restore job logs to the stdout.").Bool()
+	g.RestoreCmd.App = g.RestoreCmd.Flag("app", "Restore only the specified application release from its backup created with gravity backup --app.").String()
 	g.RestoreCmd.Timeout = g.RestoreCmd.Flag("timeout", fmt.Sprintf("Maximum time a restore job is active. Defaults to the value from the manifest or %v if unspecified.", defaults.HookJobDeadline)).Duration()
 
 	// operations on gravity applications
@@ -236,6 +351,7 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.AppInstallCmd.Image = g.AppInstallCmd.Arg("image", "Specifies application image to install. Can be an image tarball, an unpacked image tarball, or an image name in the form of <name>:<version>.").Required().String()
 	g.AppInstallCmd.Name = g.AppInstallCmd.Flag("name", "Release name. If not specified, will be auto-generated.").String()
 	g.AppInstallCmd.Namespace = g.AppInstallCmd.Flag("namespace", "Namespace to install release into.").Default(defaults.Namespace).String()
+	g.AppInstallCmd.Flavor = g.AppInstallCmd.Flag("flavor", "Flavor to select the chart values files with if the image declares charts.").String()
 	g.AppInstallCmd.Set = g.AppInstallCmd.Flag("set", "Set values on the command line. Can specify multiple or comma-separated: key1=val1,key2=val2.").Strings()
 	g.AppInstallCmd.Values = g.AppInstallCmd.Flag("values", "Set values from the provided YAML file.").Strings()
 	g.AppInstallCmd.Registry = g.AppInstallCmd.Flag("registry", "Address of Docker registry to push application images to.").String()
@@ -249,6 +365,7 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.AppUpgradeCmd.CmdClause = g.AppCmd.Command("upgrade", "Upgrade a release using the specified application image.")
 	g.AppUpgradeCmd.Release = g.AppUpgradeCmd.Arg("release", "Release name to upgrade.").Required().String()
 	g.AppUpgradeCmd.Image = g.AppUpgradeCmd.Arg("image", "Specifies application image to install. Can be an image tarball, an unpacked image tarball, or an image name in the form of <name>:<version>.").Required().String()
+	g.AppUpgradeCmd.Flavor = g.AppUpgradeCmd.Flag("flavor", "Flavor to select the chart values files with if the image declares charts.").String()
 	g.AppUpgradeCmd.Set = g.AppUpgradeCmd.Flag("set", "Set values on the command line. Can specify multiple or comma-separated: key1=val1,key2=val2.").Strings()
 	g.AppUpgradeCmd.Values = g.AppUpgradeCmd.Flag("values", "Set values from the provided YAML file.").Strings()
 	g.AppUpgradeCmd.Registry = g.AppUpgradeCmd.Flag("registry", "Address of Docker registry to push application images to.").String()
@@ -283,6 +400,11 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.AppIndexCmd.CmdClause = g.AppCmd.Command("index", "Generate an index file for application/cluster images.").Hidden()
 	g.AppIndexCmd.MergeInto = g.AppIndexCmd.Flag("merge-into", "Merge generated index file into specified index file.").String()
 
+	g.CatalogCmd.CmdClause = g.Command("catalog", "Operations with the application catalog.")
+	g.CatalogSyncCmd.CmdClause = g.CatalogCmd.Command("sync", "Mirror the latest versions of the charts from a Helm repository into the cluster catalog.")
+	g.CatalogSyncCmd.Repository = g.CatalogSyncCmd.Flag("repo", "URL of the Helm repository to mirror. Defaults to the repository of the catalog sync schedule.").String()
+	g.CatalogSyncCmd.Charts = g.CatalogSyncCmd.Flag("chart", "Name of the chart to mirror, can be repeated. Defaults to the charts of the catalog sync schedule.").Strings()
+
 	// import gravity application
 	g.AppImportCmd.CmdClause = g.AppCmd.Command("import", "Import application into gravity").Hidden()
 	g.AppImportCmd.Source = g.AppImportCmd.Arg("src", "path to application resources (directory / file)").Required().String()
@@ -330,6 +452,25 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.AppStatusCmd.Locator = Locator(g.AppStatusCmd.Arg("pkg", "application package").Required())
 	g.AppStatusCmd.OpsCenterURL = g.AppStatusCmd.Flag("ops-url", "optional remote Gravity Hub").String()
 
+	g.AppScanCmd.CmdClause = g.AppCmd.Command("scan", "Scan application container images for vulnerabilities.")
+	g.AppScanCmd.Locator = Locator(g.AppScanCmd.Arg("pkg", "Application package to scan, e.g. gravitational.io/app:1.0.0.").Required())
+	g.AppScanCmd.OpsCenterURL = g.AppScanCmd.Flag("ops-url", "Optional Gravity Hub URL with the application package. Defaults to the local cluster.").String()
+	g.AppScanCmd.ScannerPath = g.AppScanCmd.Flag("scanner-path", "Path to the trivy vulnerability scanner binary. Looked up in PATH if unspecified.").String()
+	g.AppScanCmd.Threshold = g.AppScanCmd.Flag("threshold", fmt.Sprintf("Fail if vulnerabilities of this or higher severity are found. One of %v.", scan.Severities)).String()
+	g.AppScanCmd.IgnoreUnfixed = g.AppScanCmd.Flag("ignore-unfixed", "Ignore vulnerabilities without an available fix when applying --threshold.").Bool()
+	g.AppScanCmd.Format = common.Format(g.AppScanCmd.Flag("output", "Output format: text or json.").Short('o').Default(string(constants.EncodingText)))
+
+	g.AppSBOMCmd.CmdClause = g.AppCmd.Command("sbom", "Output the software bill of materials of an application.")
+	g.AppSBOMCmd.Locator = Locator(g.AppSBOMCmd.Arg("pkg", "Application package, e.g. gravitational.io/app:1.0.0.").Required())
+	g.AppSBOMCmd.OpsCenterURL = g.AppSBOMCmd.Flag("ops-url", "Optional Gravity Hub URL with the application package. Defaults to the local cluster.").String()
+	g.AppSBOMCmd.Format = g.AppSBOMCmd.Flag("format", fmt.Sprintf("SBOM format, one of %v.", sbom.Formats)).Default(string(sbom.FormatCycloneDX)).Enum(string(sbom.FormatCycloneDX), string(sbom.FormatSPDX))
+
+	g.AppVerifyCmd.CmdClause = g.AppCmd.Command("verify", "Verify the signed build provenance of an application against the trusted keys and the application contents.")
+	g.AppVerifyCmd.Locator = Locator(g.AppVerifyCmd.Arg("pkg", "Application package, e.g. gravitational.io/app:1.0.0.").Required())
+	g.AppVerifyCmd.Keys = g.AppVerifyCmd.Flag("key", "Path to the PEM-encoded public key trusted to sign the build provenance. Can be repeated.").Required().Strings()
+	g.AppVerifyCmd.OpsCenterURL = g.AppVerifyCmd.Flag("ops-url", "Optional Gravity Hub URL with the application package. Defaults to the local cluster.").String()
+	g.AppVerifyCmd.InstallerDir = g.AppVerifyCmd.Flag("installer-dir", "Optional directory with the unpacked installer tarball to verify the application before it is installed.").String()
+
 	// pull an application from a remote OpsCenter
 	g.AppPullCmd.CmdClause = g.AppCmd.Command("pull", "pull an application package from remote Gravity Hub").Hidden()
 	g.AppPullCmd.Package = Locator(g.AppPullCmd.Arg("pkg", "application package").Required())
@@ -372,6 +513,9 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.OpsConnectCmd.OpsCenterURL = g.OpsConnectCmd.Arg("ops-url", "remote Gravity Hub URL").Default(defaults.GravityServiceURL).String()
 	g.OpsConnectCmd.Username = g.OpsConnectCmd.Arg("username", "remote Gravity Hub username").String()
 	g.OpsConnectCmd.Password = g.OpsConnectCmd.Arg("password", "remote Gravity Hub password").String()
+	g.OpsConnectCmd.SSO = g.OpsConnectCmd.Flag("sso", "log in with single sign-on in a browser instead of username and password").Bool()
+	g.OpsConnectCmd.Connector = g.OpsConnectCmd.Flag("auth", "name of the auth connector to log in with, defaults to the connector of the Gravity Hub auth preference").String()
+	g.OpsConnectCmd.TTL = g.OpsConnectCmd.Flag("ttl", "validity period of the certificates issued with single sign-on").Default(defaults.CertTTL.String()).Duration()
 
 	g.OpsDisconnectCmd.CmdClause = g.OpsCmd.Command("disconnect", "disconnect and log out from Gravity Hub").Hidden()
 	g.OpsDisconnectCmd.OpsCenterURL = g.OpsDisconnectCmd.Arg("ops-url", "remote Gravity Hub URL").Required().String()
@@ -493,6 +637,56 @@ func RegisterCommands(app *kingpin.Application) *Application {
 			int(defaults.MaxUserResetTokenTTL/time.Hour))).
 		Default(fmt.Sprintf("%v", defaults.UserResetTokenTTL)).Duration()
 
+	// short-lived cluster join tokens
+	g.TokenCmd.CmdClause = g.Command("token", "Manage cluster join tokens.")
+
+	g.TokenCreateCmd.CmdClause = g.TokenCmd.Command("create", "Create a new short-lived join token.")
+	g.TokenCreateCmd.TTL = g.TokenCreateCmd.Flag("ttl",
+		fmt.Sprintf("Set expiration time for token. Defaults to %v hour. Maximum is %v hours.",
+			int(defaults.JoinTokenTTL/time.Hour),
+			int(defaults.MaxJoinTokenTTL/time.Hour))).
+		Default(fmt.Sprintf("%v", defaults.JoinTokenTTL)).Duration()
+	g.TokenCreateCmd.Roles = g.TokenCreateCmd.Flag("role", "Node role allowed to join with the token. Can be repeated. Any role is allowed if unspecified.").Strings()
+	g.TokenCreateCmd.Uses = g.TokenCreateCmd.Flag("uses", "Number of nodes that can join with the token, 0 for unlimited.").Default("1").Int()
+
+	g.TokenListCmd.CmdClause = g.TokenCmd.Command("ls", "Show active short-lived join tokens.").Alias("list")
+
+	g.TokenRemoveCmd.CmdClause = g.TokenCmd.Command("rm", "Remove a short-lived join token.").Alias("remove")
+	g.TokenRemoveCmd.Token = g.TokenRemoveCmd.Arg("token", "Token to remove.").Required().String()
+
+	g.ClusterCmd.CmdClause = g.Command("cluster", "Manage the whole cluster.")
+
+	g.ClusterStopCmd.CmdClause = g.ClusterCmd.Command("stop", "Gracefully stop the cluster: drain all nodes, back up etcd and stop the runtime on all nodes.")
+	g.ClusterStopCmd.Confirm = g.ClusterStopCmd.Flag("confirm", "Do not ask for confirmation.").Bool()
+
+	g.ClusterStartCmd.CmdClause = g.ClusterCmd.Command("start", "Start the cluster stopped with 'gravity cluster stop'. Must be executed on the node the cluster was stopped on.")
+
+	g.ClusterPromoteCmd.CmdClause = g.ClusterCmd.Command("promote", "Promote the standby cluster: stop the replication and restore the cluster from the latest replicated backup of the primary cluster. Must be executed on the node the backup was replicated to.")
+	g.ClusterPromoteCmd.Manual = g.ClusterPromoteCmd.Flag("manual", "Do not start the restore operation automatically.").Short('m').Bool()
+	g.ClusterPromoteCmd.Confirm = g.ClusterPromoteCmd.Flag("confirm", "Do not ask for confirmation.").Bool()
+
+	// declarative cluster membership
+	g.RosterCmd.CmdClause = g.Command("roster", "Manage cluster membership changes from the cluster roster.")
+
+	g.RosterStatusCmd.CmdClause = g.RosterCmd.Command("status", "Show the membership changes required to bring the cluster in sync with the roster.")
+
+	g.RosterApproveCmd.CmdClause = g.RosterCmd.Command("approve", "Approve pending membership changes.")
+	g.RosterApproveCmd.ChangesID = g.RosterApproveCmd.Arg("id", "ID of the changes to approve as shown by 'gravity roster status'. Defaults to the pending changes.").String()
+
+	// cluster operations
+	g.OperationCmd.CmdClause = g.Command("operation", "Manage cluster operations.")
+
+	g.OperationApproveCmd.CmdClause = g.OperationCmd.Command("approve", "Approve an operation that requires approval from another user as configured by the operation approval policy.")
+	g.OperationApproveCmd.OperationID = g.OperationApproveCmd.Arg("id", "ID of the operation to approve.").Required().String()
+
+	// operator API audit records
+	g.AuditCmd.CmdClause = g.Command("audit", "Display the cluster API requests audit records.")
+	g.AuditCmd.User = g.AuditCmd.Flag("user", "Display only the requests made by this user.").String()
+	g.AuditCmd.Since = g.AuditCmd.Flag("since", "Display only the requests made within this duration, e.g. 1h.").Duration()
+	g.AuditCmd.Failed = g.AuditCmd.Flag("failed", "Display only the failed requests.").Bool()
+	g.AuditCmd.Limit = g.AuditCmd.Flag("limit", "Maximum number of the most recent requests to display.").Default("50").Int()
+	g.AuditCmd.Output = common.Format(g.AuditCmd.Flag("output", "Output format: json or text.").Default(string(constants.EncodingText)))
+
 	// operations with api keys
 	g.APIKeyCmd.CmdClause = g.Command("apikey", "operations with api keys")
 
@@ -567,10 +761,24 @@ func RegisterCommands(app *kingpin.Application) *Application {
 
 	g.SystemCmd.CmdClause = g.Command("system", "operations on system components")
 
-	g.SystemRotateCertsCmd.CmdClause = g.SystemCmd.Command("rotate-certs", "Renew cluster certificates on a node").Hidden()
-	g.SystemRotateCertsCmd.ClusterName = g.SystemRotateCertsCmd.Arg("cluster-name", "Name of the local cluster").Required().String()
-	g.SystemRotateCertsCmd.ValidFor = g.SystemRotateCertsCmd.Flag("valid-for", "Validity duration in Go format").Default("26280h").Duration()
-	g.SystemRotateCertsCmd.CAPath = g.SystemRotateCertsCmd.Flag("ca-path", "Use previously exported CA file instead of package").String()
+	g.SystemRotateCertsCmd.CmdClause = g.SystemCmd.Command("rotate-certs", "Rotate cluster certificates on all nodes")
+	g.SystemRotateCertsCmd.ClusterName = g.SystemRotateCertsCmd.Arg("cluster-name", "Name of the local cluster, only used with --local").String()
+	g.SystemRotateCertsCmd.CA = g.SystemRotateCertsCmd.Flag("ca", "Also rotate the cluster certificate authority.").Bool()
+	g.SystemRotateCertsCmd.Manual = g.SystemRotateCertsCmd.Flag("manual", "Do not start the operation automatically.").Short('m').Bool()
+	g.SystemRotateCertsCmd.Confirm = g.SystemRotateCertsCmd.Flag("confirm", "Do not ask for confirmation.").Bool()
+	g.SystemRotateCertsCmd.Local = g.SystemRotateCertsCmd.Flag("local", "Renew certificates on this node only, without the cluster API. Use when the certificates have already expired.").Bool()
+	g.SystemRotateCertsCmd.ValidFor = g.SystemRotateCertsCmd.Flag("valid-for", "Validity duration in Go format, only used with --local").Default("26280h").Duration()
+	g.SystemRotateCertsCmd.CAPath = g.SystemRotateCertsCmd.Flag("ca-path", "Use previously exported CA file instead of package, only used with --local").String()
+
+	g.SystemRotateSecretsKeyCmd.CmdClause = g.SystemCmd.Command("rotate-secrets-key", "Rotate the key that encrypts Kubernetes secrets at rest and re-encrypt the secrets")
+	g.SystemRotateSecretsKeyCmd.Manual = g.SystemRotateSecretsKeyCmd.Flag("manual", "Do not start the operation automatically.").Short('m').Bool()
+	g.SystemRotateSecretsKeyCmd.Confirm = g.SystemRotateSecretsKeyCmd.Flag("confirm", "Do not ask for confirmation.").Bool()
+
+	g.SystemChangeCIDRCmd.CmdClause = g.SystemCmd.Command("change-cidr", "Change the pod and service subnets of the cluster")
+	g.SystemChangeCIDRCmd.PodCIDR = g.SystemChangeCIDRCmd.Flag("pod-network-cidr", "New subnet range for Kubernetes pods network. Must be a minimum of /16.").String()
+	g.SystemChangeCIDRCmd.ServiceCIDR = g.SystemChangeCIDRCmd.Flag("service-cidr", "New subnet range for Kubernetes service network.").String()
+	g.SystemChangeCIDRCmd.Manual = g.SystemChangeCIDRCmd.Flag("manual", "Do not start the operation automatically.").Short('m').Bool()
+	g.SystemChangeCIDRCmd.Confirm = g.SystemChangeCIDRCmd.Flag("confirm", "Do not ask for confirmation.").Bool()
 
 	g.SystemExportCACmd.CmdClause = g.SystemCmd.Command("export-ca", "Export cluster CA, must be run on a master node").Hidden()
 	g.SystemExportCACmd.ClusterName = g.SystemExportCACmd.Arg("cluster-name", "Name of the local cluster").Required().String()
@@ -583,6 +791,9 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.SystemPullUpdatesCmd.OpsCenterURL = g.SystemPullUpdatesCmd.Flag("ops-url", "remote Gravity Hub URL").String()
 	g.SystemPullUpdatesCmd.RuntimePackage = Locator(g.SystemPullUpdatesCmd.Flag("runtime-package", "The name of the runtime package to update to").Required())
 
+	g.SystemStageCmd.CmdClause = g.SystemCmd.Command("stage", "Download system packages ahead of the upgrade").Hidden()
+	g.SystemStageCmd.Packages = g.SystemStageCmd.Flag("package", "Package to download. Can be specified multiple times").Strings()
+
 	g.SystemUpdateCmd.CmdClause = g.SystemCmd.Command("update", "Update this system by installing newer version of system packages").Hidden()
 	g.SystemUpdateCmd.ChangesetID = g.SystemUpdateCmd.Flag("changeset-id", "Assign ID to this update operation (will be autogenerated if missing)").String()
 	g.SystemUpdateCmd.ServiceName = g.SystemUpdateCmd.Flag("service-name", "The name of the service to run update as a systemd unit").String()
@@ -642,6 +853,49 @@ func RegisterCommands(app *kingpin.Application) *Application {
 
 	g.SystemStateDirCmd.CmdClause = g.SystemCmd.Command("state-dir", "show where all gravity data is stored on the node").Hidden()
 
+	g.SystemExportStateCmd.CmdClause = g.SystemCmd.Command("export-state", "export cluster state into a versioned archive")
+	g.SystemExportStateCmd.Output = g.SystemExportStateCmd.Flag("output", "path to the archive, '-' to write to stdout").Short('o').Default("-").String()
+	g.SystemExportStateCmd.Backend = g.SystemExportStateCmd.Flag("backend", fmt.Sprintf("type of the backend to export the state from, one of %v. Defaults to the local cluster etcd", storage.BackendTypes())).String()
+	g.SystemExportStateCmd.BackendParams = g.SystemExportStateCmd.Flag("backend-param", "backend configuration as key=value pairs. Can be specified multiple times").StringMap()
+
+	g.SystemImportStateCmd.CmdClause = g.SystemCmd.Command("import-state", "import cluster state from the archive created with export-state")
+	g.SystemImportStateCmd.Path = g.SystemImportStateCmd.Arg("path", "path to the archive").Required().String()
+	g.SystemImportStateCmd.Backend = g.SystemImportStateCmd.Flag("backend", fmt.Sprintf("type of the backend to import the state into, one of %v. Defaults to the local cluster etcd", storage.BackendTypes())).String()
+	g.SystemImportStateCmd.BackendParams = g.SystemImportStateCmd.Flag("backend-param", "backend configuration as key=value pairs. Can be specified multiple times").StringMap()
+	g.SystemImportStateCmd.Confirmed = g.SystemImportStateCmd.Flag("confirm", "do not ask for confirmation").Bool()
+
+	g.SystemStateChangesCmd.CmdClause = g.SystemCmd.Command("state-changes", "list the recorded changes to the cluster state")
+	g.SystemStateChangesCmd.Since = g.SystemStateChangesCmd.Flag("since", "list changes after this point in time, either a timestamp in RFC3339 format or a duration relative to now").Default("1h").String()
+	g.SystemStateChangesCmd.Backend = g.SystemStateChangesCmd.Flag("backend", fmt.Sprintf("type of the backend with the changelog, one of %v. Defaults to the local cluster etcd", storage.BackendTypes())).String()
+	g.SystemStateChangesCmd.BackendParams = g.SystemStateChangesCmd.Flag("backend-param", "backend configuration as key=value pairs. Can be specified multiple times").StringMap()
+
+	g.SystemRestoreStateCmd.CmdClause = g.SystemCmd.Command("restore-state", "restore cluster state to a point in time by reverting the recorded changes")
+	g.SystemRestoreStateCmd.To = g.SystemRestoreStateCmd.Flag("to", "point in time to restore the state to, either a timestamp in RFC3339 format or a duration relative to now").Required().String()
+	g.SystemRestoreStateCmd.Backend = g.SystemRestoreStateCmd.Flag("backend", fmt.Sprintf("type of the backend to restore the state in, one of %v. Defaults to the local cluster etcd", storage.BackendTypes())).String()
+	g.SystemRestoreStateCmd.BackendParams = g.SystemRestoreStateCmd.Flag("backend-param", "backend configuration as key=value pairs. Can be specified multiple times").StringMap()
+	g.SystemRestoreStateCmd.Confirmed = g.SystemRestoreStateCmd.Flag("confirm", "do not ask for confirmation").Bool()
+
+	g.SystemEtcdCmd.CmdClause = g.SystemCmd.Command("etcd", "manage the cluster etcd database")
+	g.SystemEtcdRestoreCmd.CmdClause = g.SystemEtcdCmd.Command("restore", "restore the etcd database on all master nodes from a snapshot")
+	g.SystemEtcdRestoreCmd.Snapshot = g.SystemEtcdRestoreCmd.Flag("snapshot", "path to the etcd snapshot, e.g. the etcd.db file of a scheduled backup").Required().String()
+	g.SystemEtcdRestoreCmd.Manual = g.SystemEtcdRestoreCmd.Flag("manual", "do not start the operation automatically").Short('m').Bool()
+	g.SystemEtcdRestoreCmd.Confirm = g.SystemEtcdRestoreCmd.Flag("confirm", "do not ask for confirmation").Bool()
+
+	g.SystemCheckStateCmd.CmdClause = g.SystemCmd.Command("check-state", "validate consistency of the cluster state")
+	g.SystemCheckStateCmd.Fix = g.SystemCheckStateCmd.Flag("fix", "repair the inconsistencies that can be fixed automatically").Bool()
+	g.SystemCheckStateCmd.Backend = g.SystemCheckStateCmd.Flag("backend", fmt.Sprintf("type of the backend to check, one of %v. Defaults to the local cluster etcd", storage.BackendTypes())).String()
+	g.SystemCheckStateCmd.BackendParams = g.SystemCheckStateCmd.Flag("backend-param", "backend configuration as key=value pairs. Can be specified multiple times").StringMap()
+
+	// manage encryption of local state
+	g.SystemEncryptionCmd.CmdClause = g.SystemCmd.Command("encryption", "manage encryption of the local state on the node")
+	g.SystemEncryptionEnableCmd.CmdClause = g.SystemEncryptionCmd.Command("enable", "encrypt the local state with the key from the specified provider")
+	g.SystemEncryptionEnableCmd.Provider = g.SystemEncryptionEnableCmd.Flag("provider", fmt.Sprintf("encryption key provider, one of %v", encryption.Providers)).Default(encryption.ProviderFile).Enum(encryption.Providers...)
+	g.SystemEncryptionEnableCmd.KeyFile = g.SystemEncryptionEnableCmd.Flag("key-file", "path to the file with the key, for the file provider").String()
+	g.SystemEncryptionEnableCmd.Parameter = g.SystemEncryptionEnableCmd.Flag("parameter", "name of the AWS Systems Manager parameter with the key, for the ssm provider").String()
+	g.SystemEncryptionEnableCmd.Region = g.SystemEncryptionEnableCmd.Flag("region", "AWS region of the parameter with the key, for the ssm provider").String()
+	g.SystemEncryptionEnableCmd.Handle = g.SystemEncryptionEnableCmd.Flag("handle", "persistent handle of the sealed key, for the tpm provider").String()
+	g.SystemEncryptionStatusCmd.CmdClause = g.SystemEncryptionCmd.Command("status", "show whether the local state is encrypted")
+
 	// manage docker devicemapper environment
 	g.SystemDevicemapperCmd.CmdClause = g.SystemCmd.Command("devicemapper", "manage docker devicemapper environment").Hidden()
 	g.SystemDevicemapperMountCmd.CmdClause = g.SystemDevicemapperCmd.Command("mount", "configure devicemapper environment").Hidden()
@@ -655,6 +909,12 @@ func RegisterCommands(app *kingpin.Application) *Application {
 
 	g.SystemStreamRuntimeJournalCmd.CmdClause = g.SystemCmd.Command("stream-runtime-journal", "Stream runtime journal to stdout").Hidden()
 
+	g.SystemNetworkMonitorCmd.CmdClause = g.SystemCmd.Command("network-monitor", "Continuously probe the network between this node and the other cluster nodes").Hidden()
+	g.SystemNetworkMonitorCmd.Node = g.SystemNetworkMonitorCmd.Flag("node-name", "Kubernetes name of this node").Envar(constants.NodeNameEnvVar).Required().String()
+	g.SystemNetworkMonitorCmd.Addr = g.SystemNetworkMonitorCmd.Flag("listen-addr", "UDP address to exchange the probes on").Default(defaults.NetworkMonitorAddr).String()
+	g.SystemNetworkMonitorCmd.Interval = g.SystemNetworkMonitorCmd.Flag("interval", "How often to probe the other nodes").Default(defaults.NetworkMonitorInterval.String()).Duration()
+	g.SystemNetworkMonitorCmd.MTU = g.SystemNetworkMonitorCmd.Flag("mtu", "Size of the probes checking the path MTU, defaults to the MTU of the interface with the node advertise address").Int()
+
 	// pruning cluster resources
 	g.GarbageCollectCmd.CmdClause = g.Command("gc", "Prune cluster resources")
 	g.GarbageCollectCmd.Manual = g.GarbageCollectCmd.Flag("manual", "Do not start the operation automatically").Short('m').Bool()
@@ -688,6 +948,10 @@ func RegisterCommands(app *kingpin.Application) *Application {
 
 	g.PlanetStatusCmd.CmdClause = g.PlanetCmd.Command("status", "calls status for currently installed planet").Hidden()
 
+	g.PlanetStopCmd.CmdClause = g.PlanetCmd.Command("stop", "stop currently installed planet and prevent it from starting on boot").Hidden()
+
+	g.PlanetStartCmd.CmdClause = g.PlanetCmd.Command("start", "start currently installed planet and enable it to start on boot").Hidden()
+
 	g.EnterCmd.CmdClause = g.Command("enter", "enter planet").Hidden()
 	g.EnterCmd.Args = g.EnterCmd.Arg("arg", "additional arguments to the container").Strings()
 
@@ -734,6 +998,22 @@ func RegisterCommands(app *kingpin.Application) *Application {
 	g.TopCmd.Interval = g.TopCmd.Flag("interval", "Interval to display data for, in Go duration format.").Default(defaults.MetricsInterval.String()).Duration()
 	g.TopCmd.Step = g.TopCmd.Flag("step", "Max time b/w two datapoints, in Go duration format.").Default(defaults.MetricsStep.String()).Duration()
 
+	g.ExpandCmd.CmdClause = g.Command("expand", "Add new nodes to the cluster, either provisioned with the cloud provider integration or listed in a roster file.")
+	g.ExpandCmd.ProvisionSpec = g.ExpandCmd.Flag("provision-spec", "Path to the spec describing the nodes to provision.").String()
+	g.ExpandCmd.Role = g.ExpandCmd.Flag("role", "Node profile of the new nodes. Required with --provision-spec.").String()
+	g.ExpandCmd.Count = g.ExpandCmd.Flag("count", "Number of nodes to add.").Default("1").Int()
+	g.ExpandCmd.Roster = g.ExpandCmd.Flag("roster", "Path to the file listing the nodes to join, with their roles and SSH credentials or pre-started agents.").String()
+	g.ExpandCmd.Parallel = g.ExpandCmd.Flag("parallel", "Maximum number of nodes from the roster joining concurrently.").Default(strconv.Itoa(defaults.MaxExpandConcurrency)).Int()
+
+	g.MirrorCmd.CmdClause = g.Command("mirror", "Manage air-gapped mirrors of cluster images.")
+	g.MirrorExportCmd.CmdClause = g.MirrorCmd.Command("export", "Export packages and container images of a cluster image into a mirror directory.")
+	g.MirrorExportCmd.Path = g.MirrorExportCmd.Arg("path", "Path to the cluster image tarball or unpacked installer directory.").Required().String()
+	g.MirrorExportCmd.OutputDir = g.MirrorExportCmd.Flag("output", "Mirror output directory.").Short('o').Required().String()
+	g.MirrorServeCmd.CmdClause = g.MirrorCmd.Command("serve", "Serve docker registry and packages from a mirror directory.")
+	g.MirrorServeCmd.Dir = g.MirrorServeCmd.Arg("dir", "Mirror directory created with 'gravity mirror export'.").Required().String()
+	g.MirrorServeCmd.RegistryAddr = g.MirrorServeCmd.Flag("registry-addr", "Address to serve docker registry on.").Default(defaults.MirrorRegistryAddr).String()
+	g.MirrorServeCmd.PackagesAddr = g.MirrorServeCmd.Flag("packages-addr", "Address to serve packages on.").Default(defaults.MirrorPackagesAddr).String()
+
 	return g
 }
 
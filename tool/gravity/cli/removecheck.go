@@ -0,0 +1,95 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gravitational/gravity/lib/clients"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/kubernetes"
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+	kubeclient "k8s.io/client-go/kubernetes"
+)
+
+// checkNodeRemoval returns the list of issues that make removing
+// the specified server from the cluster unsafe.
+// If wait is set, it waits for stateful applications to finish
+// replicating their data before reporting them as blockers
+func checkNodeRemoval(ctx context.Context, env *localenv.LocalEnvironment, server storage.Server, wait bool) (blockers []string, err error) {
+	if server.IsMaster() {
+		health, err := clients.EtcdHealth(ctx, clients.EtcdConfig{})
+		if err != nil {
+			return nil, trace.Wrap(err, "failed to query etcd cluster health")
+		}
+		blockers = append(blockers, clients.CheckEtcdMemberRemoval(health, server.GetClusterAddr())...)
+	}
+	client, err := env.KubeClient()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	pods, err := kubernetes.LocalVolumePods(client, server.KubeNodeID())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, pod := range pods {
+		blockers = append(blockers, pod.String())
+	}
+	var timeout time.Duration
+	if wait {
+		timeout = defaults.RemovalReplicationTimeout
+	}
+	unready, err := waitForReplication(ctx, client, timeout)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return append(blockers, unready...), nil
+}
+
+// waitForReplication waits up to the specified timeout for all stateful sets
+// in the cluster to have their replicas ready and returns the ones that did not
+func waitForReplication(ctx context.Context, client *kubeclient.Clientset, timeout time.Duration) (unready []string, err error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(defaults.RetryInterval)
+	defer ticker.Stop()
+	for {
+		statefulSets, err := kubernetes.UnreadyStatefulSets(client)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		if len(statefulSets) == 0 {
+			return nil, nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			for _, statefulSet := range statefulSets {
+				unready = append(unready, fmt.Sprintf(
+					"stateful set %v/%v has not finished replicating data: %v of %v replica(-s) ready",
+					statefulSet.Namespace, statefulSet.Name,
+					statefulSet.Status.ReadyReplicas, statefulSet.Status.Replicas))
+			}
+			return unready, nil
+		}
+	}
+}
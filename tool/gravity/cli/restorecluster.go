@@ -0,0 +1,267 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"path/filepath"
+
+	libbackup "github.com/gravitational/gravity/lib/backup"
+	libfsm "github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/rpc"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/update"
+	clusterrestore "github.com/gravitational/gravity/lib/update/restore"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+)
+
+type restoreClusterConfig struct {
+	// backup is the path to the backup directory
+	backup string
+	// manual specifies whether the operation is executed manually
+	manual bool
+	// confirmed specifies whether the user has confirmed the operation
+	confirmed bool
+}
+
+// restoreCluster restores the cluster from the scheduled backup
+// of another cluster with the same name
+func restoreCluster(localEnv *localenv.LocalEnvironment, environ LocalEnvironmentFactory, config restoreClusterConfig) error {
+	dir, err := filepath.Abs(config.backup)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	b, err := libbackup.Open(dir)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if !config.confirmed {
+		localEnv.Printf(restoreClusterBanner+"\n", b.ClusterName, len(b.Servers))
+		resp, err := confirm()
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if !resp {
+			localEnv.Println("Action cancelled by user.")
+			return nil
+		}
+	}
+	updateEnv, err := environ.NewUpdateEnv()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updateEnv.Close()
+	ctx := context.TODO()
+	updater, err := newUpdater(ctx, localEnv, updateEnv, restoreClusterInitializer{backup: dir})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updater.Close()
+	if config.manual {
+		localEnv.Println(updateEnvironManualOperationBanner)
+		return nil
+	}
+	if err := updater.Run(ctx); err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(printRejoinCommands(localEnv, b))
+}
+
+// printRejoinCommands prints the commands to re-join the restored
+// cluster for the worker nodes of the backed up cluster
+func printRejoinCommands(env *localenv.LocalEnvironment, b *libbackup.Backup) error {
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	workers := b.Workers(cluster.ClusterState.Servers)
+	masters := cluster.ClusterState.Servers.Masters()
+	if len(workers) == 0 || len(masters) == 0 {
+		return nil
+	}
+	token, err := libbackup.JoinToken(context.TODO(), operator, cluster.Key(), workers)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	env.Println("Execute the following command on each surviving worker node to re-join the cluster:")
+	for _, worker := range workers {
+		env.Printf("  %v: %v\n", worker.Hostname,
+			libbackup.JoinCommand(worker, masters[0].AdvertiseIP, token))
+	}
+	return nil
+}
+
+func executeRestorePhase(env *localenv.LocalEnvironment, environ LocalEnvironmentFactory, params PhaseParams, operation ops.SiteOperation) error {
+	updateEnv, err := environ.NewUpdateEnv()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updateEnv.Close()
+	updater, err := getRestoreUpdater(env, updateEnv, operation)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updater.Close()
+	err = updater.RunPhase(context.TODO(), params.PhaseID, params.Timeout, params.Force)
+	return trace.Wrap(err)
+}
+
+func setRestorePhase(env *localenv.LocalEnvironment, environ LocalEnvironmentFactory, params SetPhaseParams, operation ops.SiteOperation) error {
+	updateEnv, err := environ.NewUpdateEnv()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updateEnv.Close()
+	updater, err := getRestoreUpdater(env, updateEnv, operation)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updater.Close()
+	return updater.SetPhase(context.TODO(), params.PhaseID, params.State)
+}
+
+func rollbackRestorePhase(env *localenv.LocalEnvironment, environ LocalEnvironmentFactory, params PhaseParams, operation ops.SiteOperation) error {
+	updateEnv, err := environ.NewUpdateEnv()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updateEnv.Close()
+	updater, err := getRestoreUpdater(env, updateEnv, operation)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updater.Close()
+	err = updater.RollbackPhase(context.TODO(), params.PhaseID, params.Timeout, params.Force)
+	return trace.Wrap(err)
+}
+
+func completeRestorePlan(env *localenv.LocalEnvironment, environ LocalEnvironmentFactory, operation ops.SiteOperation) error {
+	updateEnv, err := environ.NewUpdateEnv()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updateEnv.Close()
+	updater, err := getRestoreUpdater(env, updateEnv, operation)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updater.Close()
+	return trace.Wrap(updater.Complete(nil))
+}
+
+func getRestoreUpdater(env, updateEnv *localenv.LocalEnvironment, operation ops.SiteOperation) (*update.Updater, error) {
+	clusterEnv, err := env.NewClusterEnvironment()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	creds, err := libfsm.GetClientCredentials()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	runner := libfsm.NewAgentRunner(creds)
+	return restoreClusterInitializer{}.newUpdater(context.TODO(), clusterEnv.Operator, operation,
+		env, updateEnv, clusterEnv, runner)
+}
+
+func (restoreClusterInitializer) validatePreconditions(*localenv.LocalEnvironment, ops.Operator, ops.Site) error {
+	return nil
+}
+
+func (r restoreClusterInitializer) newOperation(operator ops.Operator, cluster ops.Site) (*ops.SiteOperationKey, error) {
+	key, err := operator.CreateRestoreOperation(context.TODO(),
+		ops.CreateRestoreOperationRequest{
+			ClusterKey: cluster.Key(),
+			Backup:     r.backup,
+		},
+	)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return key, nil
+}
+
+func (restoreClusterInitializer) newOperationPlan(
+	ctx context.Context,
+	operator ops.Operator,
+	cluster ops.Site,
+	operation ops.SiteOperation,
+	localEnv, updateEnv *localenv.LocalEnvironment,
+	clusterEnv *localenv.ClusterEnvironment,
+	leader *storage.Server,
+) (*storage.OperationPlan, error) {
+	if leader == nil {
+		return nil, trace.NotFound("cluster can only be restored from a master node")
+	}
+	plan, err := clusterrestore.NewOperationPlan(operator, operation, cluster.ClusterState.Servers, *leader)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return plan, nil
+}
+
+func (restoreClusterInitializer) newUpdater(
+	ctx context.Context,
+	operator ops.Operator,
+	operation ops.SiteOperation,
+	localEnv, updateEnv *localenv.LocalEnvironment,
+	clusterEnv *localenv.ClusterEnvironment,
+	runner rpc.AgentRepository,
+) (*update.Updater, error) {
+	config := clusterrestore.Config{
+		Config: update.Config{
+			Operation:    &operation,
+			Operator:     operator,
+			Backend:      clusterEnv.Backend,
+			LocalBackend: updateEnv.Backend,
+			Silent:       localEnv.Silent,
+			Runner:       runner,
+			FieldLogger: logrus.WithFields(logrus.Fields{
+				trace.Component: "update:restore",
+				"operation":     operation,
+			}),
+		},
+		Apps:              clusterEnv.Apps,
+		Client:            clusterEnv.Client,
+		ClusterPackages:   clusterEnv.ClusterPackages,
+		HostLocalPackages: localEnv.Packages,
+	}
+	return clusterrestore.New(ctx, config)
+}
+
+func (restoreClusterInitializer) updateDeployRequest(req deployAgentsRequest) deployAgentsRequest {
+	return req
+}
+
+type restoreClusterInitializer struct {
+	// backup is the path to the backup directory
+	backup string
+}
+
+const restoreClusterBanner = `The Kubernetes resources and the cluster state of cluster %v will be
+restored from the backup, replacing the resources of this cluster with the same names.
+The cluster controller will be restarted. The %v nodes of the backed up cluster
+are not restored: the surviving worker nodes will need to re-join this cluster.
+
+Are you sure?`
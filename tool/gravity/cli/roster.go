@@ -0,0 +1,103 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gravitational/gravity/lib/localenv"
+
+	"github.com/gravitational/trace"
+)
+
+func rosterStatus(env *localenv.LocalEnvironment) error {
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	roster, err := operator.GetClusterRoster(cluster.Key())
+	if err != nil {
+		if trace.IsNotFound(err) {
+			env.Println("Cluster roster is not defined.")
+			return nil
+		}
+		return trace.Wrap(err)
+	}
+
+	status, err := operator.GetClusterRosterStatus(cluster.Key())
+	if err != nil {
+		if trace.IsNotFound(err) {
+			env.Println("Cluster roster has not been reconciled yet.")
+			return nil
+		}
+		return trace.Wrap(err)
+	}
+
+	fmt.Printf("Approval:\t%v\n", roster.GetApproval())
+	fmt.Printf("Updated:\t%v\n", status.Updated.Format(time.RFC3339))
+	fmt.Printf("Status:\t\t%v\n", status.Message)
+	if len(status.Changes) == 0 {
+		return nil
+	}
+	fmt.Printf("\nChanges %v", status.ChangesID)
+	if status.IsPendingApproval() {
+		fmt.Print(" (pending approval)")
+	}
+	fmt.Print(":\n")
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 1, '\t', 0)
+	fmt.Fprintf(w, "Action\tAddress\tRole\tHostname\n")
+	fmt.Fprintf(w, "------\t-------\t----\t--------\n")
+	for _, change := range status.Changes {
+		hostname := change.Hostname
+		if hostname == "" {
+			hostname = "-"
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", change.Action, change.Addr, change.Role, hostname)
+	}
+	return trace.Wrap(w.Flush())
+}
+
+func approveRosterChanges(env *localenv.LocalEnvironment, changesID string) error {
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	err = operator.ApproveClusterRosterChanges(context.TODO(), cluster.Key(), changesID)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	env.Println("Cluster roster changes have been approved and will be applied shortly.")
+	return nil
+}
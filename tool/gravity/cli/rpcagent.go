@@ -36,6 +36,7 @@ import (
 	pb "github.com/gravitational/gravity/lib/rpc/proto"
 	rpcserver "github.com/gravitational/gravity/lib/rpc/server"
 	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/systemservice"
 	"github.com/gravitational/gravity/lib/update"
 	clusterupdate "github.com/gravitational/gravity/lib/update/cluster"
 	"github.com/gravitational/gravity/lib/utils"
@@ -61,12 +62,15 @@ func rpcAgentInstall(env *localenv.LocalEnvironment, args []string) error {
 		append([]string{gravityPath, "--debug", "agent", "run"}, args...)))
 }
 
-// rpcAgentRun runs a local agent executing the function specified with optional args
+// rpcAgentRun runs a local agent executing the function specified with optional args.
+// The agent service is restarted if the node reboots while the agent is running
+// and is disabled once the agent exits
 func rpcAgentRun(localEnv, updateEnv *localenv.LocalEnvironment, args []string) error {
 	agent, err := newAgent()
 	if err != nil {
 		return trace.Wrap(err)
 	}
+	defer disableAgentService()
 	if len(args) == 0 {
 		return trace.Wrap(agent.Serve())
 	}
@@ -117,6 +121,22 @@ func runAgentFunction(
 	return trace.Wrap(err)
 }
 
+// disableAgentService prevents the agent service from starting
+// after the node reboots once the agent has exited
+func disableAgentService() {
+	services, err := systemservice.New()
+	if err != nil {
+		log.WithError(err).Warn("Failed to access system services.")
+		return
+	}
+	err = services.DisableService(systemservice.DisableServiceRequest{
+		Name: defaults.GravityRPCAgentServiceName,
+	})
+	if err != nil {
+		log.WithError(err).Warn("Failed to disable agent service.")
+	}
+}
+
 func newAgent() (rpcserver.Server, error) {
 	secretsDir, err := fsm.AgentSecretsDir()
 	if err != nil {
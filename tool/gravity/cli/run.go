@@ -33,9 +33,14 @@ import (
 	"github.com/gravitational/gravity/lib/fsm"
 	"github.com/gravitational/gravity/lib/httplib"
 	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/metrics"
+	"github.com/gravitational/gravity/lib/network/monitor"
 	"github.com/gravitational/gravity/lib/process"
+	"github.com/gravitational/gravity/lib/redact"
+	"github.com/gravitational/gravity/lib/sbom"
 	"github.com/gravitational/gravity/lib/schema"
 	"github.com/gravitational/gravity/lib/state"
+	"github.com/gravitational/gravity/lib/storage/encryption"
 	"github.com/gravitational/gravity/lib/systemservice"
 	"github.com/gravitational/gravity/lib/utils"
 
@@ -104,7 +109,7 @@ func InitAndCheck(g *Application, cmd string) error {
 	default:
 		teleutils.InitLogger(teleutils.LoggingForCLI, level)
 	}
-	logrus.SetFormatter(&trace.TextFormatter{})
+	logrus.SetFormatter(&redact.Formatter{Formatter: &trace.TextFormatter{}})
 
 	// the following commands write logs to the system log file (in
 	// addition to journald)
@@ -119,6 +124,15 @@ func InitAndCheck(g *Application, cmd string) error {
 		g.RPCAgentRunCmd.FullCommand(),
 		g.LeaveCmd.FullCommand(),
 		g.RemoveCmd.FullCommand(),
+		g.NodePromoteCmd.FullCommand(),
+		g.NodeDemoteCmd.FullCommand(),
+		g.NodeReplaceCmd.FullCommand(),
+		g.SystemRotateCertsCmd.FullCommand(),
+		g.SystemRotateSecretsKeyCmd.FullCommand(),
+		g.SystemChangeCIDRCmd.FullCommand(),
+		g.SystemEtcdRestoreCmd.FullCommand(),
+		g.RestoreCmd.FullCommand(),
+		g.ClusterPromoteCmd.FullCommand(),
 		g.ResumeCmd.FullCommand(),
 		g.PlanResumeCmd.FullCommand(),
 		g.PlanExecuteCmd.FullCommand(),
@@ -145,13 +159,42 @@ func InitAndCheck(g *Application, cmd string) error {
 		}
 	}
 
+	if addr := metricsAddr(g, cmd); addr != "" {
+		if err := metrics.Serve(addr); err != nil {
+			log.WithError(err).Warn("Failed to serve metrics.")
+		}
+	}
+
 	utils.DetectPlanetEnvironment()
 
 	// the following commands must be run inside deployed cluster
 	switch cmd {
 	case g.UpdateCompleteCmd.FullCommand(),
 		g.UpdateTriggerCmd.FullCommand(),
-		g.RemoveCmd.FullCommand():
+		g.RemoveCmd.FullCommand(),
+		g.NodePromoteCmd.FullCommand(),
+		g.NodeDemoteCmd.FullCommand(),
+		g.NodeReplaceCmd.FullCommand(),
+		g.SystemRotateSecretsKeyCmd.FullCommand(),
+		g.SystemChangeCIDRCmd.FullCommand(),
+		g.SystemEtcdRestoreCmd.FullCommand(),
+		g.ClusterStopCmd.FullCommand(),
+		g.ClusterPromoteCmd.FullCommand():
+		if err := checkRunningInGravity(g); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	if cmd == g.SystemRotateCertsCmd.FullCommand() && !*g.SystemRotateCertsCmd.Local {
+		if err := checkRunningInGravity(g); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	if cmd == g.RestoreCmd.FullCommand() && (*g.RestoreCmd.From != "" || *g.RestoreCmd.App != "") {
+		if err := checkRunningInGravity(g); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	if cmd == g.BackupHookCmd.FullCommand() && *g.BackupHookCmd.App != "" {
 		if err := checkRunningInGravity(g); err != nil {
 			return trace.Wrap(err)
 		}
@@ -184,12 +227,30 @@ func InitAndCheck(g *Application, cmd string) error {
 		g.AutoJoinCmd.FullCommand(),
 		g.SystemDevicemapperMountCmd.FullCommand(),
 		g.SystemDevicemapperUnmountCmd.FullCommand(),
-		g.BackupCmd.FullCommand(),
+		g.BackupHookCmd.FullCommand(),
 		g.RestoreCmd.FullCommand(),
 		g.GarbageCollectCmd.FullCommand(),
+		g.NodePromoteCmd.FullCommand(),
+		g.NodeDemoteCmd.FullCommand(),
+		g.NodeReplaceCmd.FullCommand(),
+		g.SystemRotateCertsCmd.FullCommand(),
+		g.SystemRotateSecretsKeyCmd.FullCommand(),
+		g.SystemChangeCIDRCmd.FullCommand(),
+		g.SystemEtcdRestoreCmd.FullCommand(),
+		g.ClusterStopCmd.FullCommand(),
+		g.ClusterStartCmd.FullCommand(),
+		g.ClusterPromoteCmd.FullCommand(),
+		g.PlanetStopCmd.FullCommand(),
+		g.PlanetStartCmd.FullCommand(),
 		g.SystemGCRegistryCmd.FullCommand(),
 		g.OpsAgentCmd.FullCommand(),
 		g.CheckCmd.FullCommand(),
+		g.SystemEncryptionEnableCmd.FullCommand(),
+		g.SystemExportStateCmd.FullCommand(),
+		g.SystemImportStateCmd.FullCommand(),
+		g.SystemStateChangesCmd.FullCommand(),
+		g.SystemRestoreStateCmd.FullCommand(),
+		g.SystemCheckStateCmd.FullCommand(),
 		g.ReportCmd.FullCommand():
 		if err := checkRunningAsRoot(); err != nil {
 			return trace.Wrap(err)
@@ -203,7 +264,11 @@ func InitAndCheck(g *Application, cmd string) error {
 		g.UpgradeCmd.FullCommand(),
 		g.SystemGCRegistryCmd.FullCommand(),
 		g.PlanetEnterCmd.FullCommand(),
-		g.EnterCmd.FullCommand():
+		g.EnterCmd.FullCommand(),
+		g.ClusterStopCmd.FullCommand(),
+		g.ClusterStartCmd.FullCommand(),
+		g.PlanetStopCmd.FullCommand(),
+		g.PlanetStartCmd.FullCommand():
 		if utils.CheckInPlanet() {
 			return trace.BadParameter("this command must be run outside of planet container")
 		}
@@ -220,6 +285,39 @@ func InitAndCheck(g *Application, cmd string) error {
 	return nil
 }
 
+// metricsAddr returns the address the process running the specified command
+// serves its metrics on, or an empty string if the metrics are not served
+func metricsAddr(g *Application, cmd string) string {
+	switch cmd {
+	case g.InstallCmd.FullCommand():
+		// the metrics are served by the installer service
+		// rather than the client that has started it
+		return serviceMetricsAddr(g, *g.InstallCmd.FromService)
+	case g.JoinCmd.FullCommand():
+		return serviceMetricsAddr(g, *g.JoinCmd.FromService)
+	case g.AutoJoinCmd.FullCommand():
+		return serviceMetricsAddr(g, *g.AutoJoinCmd.FromService)
+	case g.WizardCmd.FullCommand():
+		return serviceMetricsAddr(g, *g.WizardCmd.FromService)
+	case g.RPCAgentRunCmd.FullCommand():
+		if *g.MetricsAddr == "" {
+			return defaults.GravityRPCAgentMetricsAddr
+		}
+	case g.SystemNetworkMonitorCmd.FullCommand():
+		if *g.MetricsAddr == "" {
+			return defaults.NetworkMonitorMetricsAddr
+		}
+	}
+	return *g.MetricsAddr
+}
+
+func serviceMetricsAddr(g *Application, fromService bool) string {
+	if !fromService {
+		return ""
+	}
+	return *g.MetricsAddr
+}
+
 // Execute executes the gravity command given with cmd
 func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 	switch cmd {
@@ -231,11 +329,38 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 		return initCluster(*g.SiteInitCmd.ConfigPath, *g.SiteInitCmd.InitPath)
 	case g.SiteStatusCmd.FullCommand():
 		return statusSite()
+	// encryption commands open the local state themselves
+	case g.SystemEncryptionEnableCmd.FullCommand():
+		return enableEncryption(g, encryption.Config{
+			Provider:  *g.SystemEncryptionEnableCmd.Provider,
+			KeyFile:   *g.SystemEncryptionEnableCmd.KeyFile,
+			Parameter: *g.SystemEncryptionEnableCmd.Parameter,
+			Region:    *g.SystemEncryptionEnableCmd.Region,
+			Handle:    *g.SystemEncryptionEnableCmd.Handle,
+		})
+	case g.SystemEncryptionStatusCmd.FullCommand():
+		return encryptionStatus()
+	// network monitor runs in a pod without access to the local state
+	case g.SystemNetworkMonitorCmd.FullCommand():
+		return networkMonitor(monitor.Config{
+			Node:     *g.SystemNetworkMonitorCmd.Node,
+			Addr:     *g.SystemNetworkMonitorCmd.Addr,
+			Interval: *g.SystemNetworkMonitorCmd.Interval,
+			MTU:      *g.SystemNetworkMonitorCmd.MTU,
+		})
 	}
 
 	var localEnv *localenv.LocalEnvironment
 	switch cmd {
 	case g.InstallCmd.FullCommand(), g.JoinCmd.FullCommand():
+		if cmd == g.InstallCmd.FullCommand() && !*g.InstallCmd.FromService {
+			// Check the host before the install environment is created
+			// since the cleanup removes the state directory
+			err := checkPreviousInstallation(localenv.Silent(*g.Silent), *g.InstallCmd.Wipe)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+		}
 		if *g.StateDir != "" {
 			if err := state.SetStateDir(*g.StateDir); err != nil {
 				return trace.Wrap(err)
@@ -318,6 +443,7 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 			*g.UpdateTriggerCmd.App,
 			*g.UpdateTriggerCmd.Manual,
 			*g.UpdateTriggerCmd.SkipVersionCheck,
+			nil, nil, nil, nil,
 		)
 	case g.UpdatePlanInitCmd.FullCommand():
 		updateEnv, err := g.NewUpdateEnv()
@@ -332,6 +458,9 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 			return trace.Wrap(err)
 		}
 		defer updateEnv.Close()
+		if *g.UpgradeCmd.Approve {
+			return approveUpdate(localEnv, g, *g.UpgradeCmd.Manual, *g.UpgradeCmd.SkipVersionCheck)
+		}
 		if *g.UpgradeCmd.Resume {
 			*g.UpgradeCmd.Phase = fsm.RootPhase
 		}
@@ -344,10 +473,35 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 					SkipVersionCheck: *g.UpgradeCmd.SkipVersionCheck,
 				})
 		}
-		return updateTrigger(localEnv, updateEnv,
+		if *g.UpgradeCmd.PlanOnly {
+			return analyzeUpgrade(localEnv, *g.UpgradeCmd.App)
+		}
+		if *g.UpgradeCmd.Stage {
+			return stageUpgrade(localEnv, *g.UpgradeCmd.App)
+		}
+		strategy, err := newUpdateStrategy(*g.UpgradeCmd.Strategy, *g.UpgradeCmd.CanaryNode,
+			*g.UpgradeCmd.SoakPeriod, *g.UpgradeCmd.BatchSize, *g.UpgradeCmd.MaxUnavailable)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		schedule, err := newMaintenanceWindow(*g.UpgradeCmd.Schedule)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		drain, err := newDrainPolicy(*g.UpgradeCmd.DrainGracePeriod, *g.UpgradeCmd.DrainTimeout,
+			*g.UpgradeCmd.DrainForceAfter, *g.UpgradeCmd.DrainIgnorePDB,
+			*g.UpgradeCmd.DrainSkipDaemonSets, *g.UpgradeCmd.DrainSkipNodes)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		return upgradeCluster(localEnv, updateEnv,
 			*g.UpgradeCmd.App,
 			*g.UpgradeCmd.Manual,
 			*g.UpgradeCmd.SkipVersionCheck,
+			strategy,
+			newAutoRollbackPolicy(*g.UpgradeCmd.AutoRollback, *g.UpgradeCmd.AutoRollbackRetries),
+			schedule,
+			drain,
 		)
 	case g.ResumeCmd.FullCommand():
 		return resumeOperation(localEnv, g,
@@ -408,8 +562,35 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 			server:    *g.RemoveCmd.Node,
 			force:     *g.RemoveCmd.Force,
 			confirmed: *g.RemoveCmd.Confirm,
+			checkOnly: *g.RemoveCmd.CheckOnly,
+		})
+	case g.NodePromoteCmd.FullCommand():
+		return updateNodeRole(localEnv, g, nodeRoleConfig{
+			server:    *g.NodePromoteCmd.Node,
+			role:      schema.ServiceRoleMaster,
+			manual:    *g.NodePromoteCmd.Manual,
+			confirmed: *g.NodePromoteCmd.Confirm,
+		})
+	case g.NodeDemoteCmd.FullCommand():
+		return updateNodeRole(localEnv, g, nodeRoleConfig{
+			server:    *g.NodeDemoteCmd.Node,
+			role:      schema.ServiceRoleNode,
+			manual:    *g.NodeDemoteCmd.Manual,
+			confirmed: *g.NodeDemoteCmd.Confirm,
+		})
+	case g.NodeReplaceCmd.FullCommand():
+		return replaceNode(localEnv, g, nodeReplaceConfig{
+			server:     *g.NodeReplaceCmd.Node,
+			newAddr:    *g.NodeReplaceCmd.With,
+			sshUser:    *g.NodeReplaceCmd.SSHUser,
+			sshKey:     *g.NodeReplaceCmd.SSHKey,
+			sshPort:    *g.NodeReplaceCmd.SSHPort,
+			sshHostKey: *g.NodeReplaceCmd.SSHHostKey,
+			force:      *g.NodeReplaceCmd.Force,
+			manual:     *g.NodeReplaceCmd.Manual,
+			confirmed:  *g.NodeReplaceCmd.Confirm,
 		})
-	case g.StatusCmd.FullCommand():
+	case g.StatusClusterCmd.FullCommand():
 		printOptions := printOptions{
 			token:       *g.StatusCmd.Token,
 			operationID: *g.StatusCmd.OperationID,
@@ -421,9 +602,19 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 		}
 		if *g.StatusCmd.Seconds != 0 {
 			return statusPeriodic(localEnv, printOptions, *g.StatusCmd.Seconds)
+		} else if printOptions.format == constants.EncodingNagios {
+			return statusNagios(localEnv, printOptions)
 		} else {
 			return status(localEnv, printOptions)
 		}
+	case g.StatusHistoryCmd.FullCommand():
+		return showHealthHistory(localEnv, *g.StatusHistoryCmd.Since, *g.StatusCmd.Output)
+	case g.StatusSNMPAgentCmd.FullCommand():
+		return snmpAgent(localEnv, snmpAgentConfig{
+			addr:     *g.StatusSNMPAgentCmd.Addr,
+			rootOID:  *g.StatusSNMPAgentCmd.RootOID,
+			interval: *g.StatusSNMPAgentCmd.Interval,
+		})
 	case g.UpdateUploadCmd.FullCommand():
 		return uploadUpdate(localEnv, *g.UpdateUploadCmd.OpsCenterURL)
 	case g.AppPackageCmd.FullCommand():
@@ -434,6 +625,7 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 			Image:     *g.AppInstallCmd.Image,
 			Name:      *g.AppInstallCmd.Name,
 			Namespace: *g.AppInstallCmd.Namespace,
+			Flavor:    *g.AppInstallCmd.Flavor,
 			valuesConfig: valuesConfig{
 				Values: *g.AppInstallCmd.Set,
 				Files:  *g.AppInstallCmd.Values,
@@ -452,6 +644,7 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 		return releaseUpgrade(localEnv, releaseUpgradeConfig{
 			Release: *g.AppUpgradeCmd.Release,
 			Image:   *g.AppUpgradeCmd.Image,
+			Flavor:  *g.AppUpgradeCmd.Flavor,
 			valuesConfig: valuesConfig{
 				Values: *g.AppUpgradeCmd.Set,
 				Files:  *g.AppUpgradeCmd.Values,
@@ -493,6 +686,10 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 			*g.AppSearchCmd.All)
 	case g.AppRebuildIndexCmd.FullCommand():
 		return appRebuildIndex(localEnv)
+	case g.CatalogSyncCmd.FullCommand():
+		return catalogSync(localEnv,
+			*g.CatalogSyncCmd.Repository,
+			*g.CatalogSyncCmd.Charts)
 	case g.AppIndexCmd.FullCommand():
 		return appIndex(localEnv,
 			*g.AppIndexCmd.MergeInto)
@@ -548,6 +745,27 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 		return statusApp(localEnv,
 			*g.AppStatusCmd.Locator,
 			*g.AppStatusCmd.OpsCenterURL)
+	case g.AppScanCmd.FullCommand():
+		return scanApp(localEnv, appScanConfig{
+			Package:       *g.AppScanCmd.Locator,
+			OpsCenterURL:  *g.AppScanCmd.OpsCenterURL,
+			ScannerPath:   *g.AppScanCmd.ScannerPath,
+			Threshold:     *g.AppScanCmd.Threshold,
+			IgnoreUnfixed: *g.AppScanCmd.IgnoreUnfixed,
+			Format:        *g.AppScanCmd.Format,
+		})
+	case g.AppSBOMCmd.FullCommand():
+		return showAppSBOM(localEnv,
+			*g.AppSBOMCmd.Locator,
+			*g.AppSBOMCmd.OpsCenterURL,
+			sbom.Format(*g.AppSBOMCmd.Format))
+	case g.AppVerifyCmd.FullCommand():
+		return verifyApp(localEnv, appVerifyConfig{
+			Package:      *g.AppVerifyCmd.Locator,
+			KeyPaths:     *g.AppVerifyCmd.Keys,
+			OpsCenterURL: *g.AppVerifyCmd.OpsCenterURL,
+			InstallerDir: *g.AppVerifyCmd.InstallerDir,
+		})
 	case g.AppPackageUninstallCmd.FullCommand():
 		return uninstallAppPackage(localEnv,
 			*g.AppPackageUninstallCmd.Locator)
@@ -636,6 +854,12 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 			*g.PackLabelsCmd.Remove)
 		// OpsCenter commands
 	case g.OpsConnectCmd.FullCommand():
+		if *g.OpsConnectCmd.SSO {
+			return connectToOpsCenterSSO(localEnv,
+				*g.OpsConnectCmd.OpsCenterURL,
+				*g.OpsConnectCmd.Connector,
+				*g.OpsConnectCmd.TTL)
+		}
 		return connectToOpsCenter(localEnv,
 			*g.OpsConnectCmd.OpsCenterURL,
 			*g.OpsConnectCmd.Username,
@@ -687,10 +911,32 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 		return getLocalSite(localEnv)
 	// system service commands
 	case g.SystemRotateCertsCmd.FullCommand():
-		return rotateCertificates(localEnv, rotateOptions{
-			clusterName: *g.SystemRotateCertsCmd.ClusterName,
-			validFor:    *g.SystemRotateCertsCmd.ValidFor,
-			caPath:      *g.SystemRotateCertsCmd.CAPath,
+		if *g.SystemRotateCertsCmd.Local {
+			if *g.SystemRotateCertsCmd.ClusterName == "" {
+				return trace.BadParameter("cluster name is required with --local")
+			}
+			return rotateCertificates(localEnv, rotateOptions{
+				clusterName: *g.SystemRotateCertsCmd.ClusterName,
+				validFor:    *g.SystemRotateCertsCmd.ValidFor,
+				caPath:      *g.SystemRotateCertsCmd.CAPath,
+			})
+		}
+		return rotateCerts(localEnv, g, rotateCertsConfig{
+			rotateCA:  *g.SystemRotateCertsCmd.CA,
+			manual:    *g.SystemRotateCertsCmd.Manual,
+			confirmed: *g.SystemRotateCertsCmd.Confirm,
+		})
+	case g.SystemRotateSecretsKeyCmd.FullCommand():
+		return rotateSecretsKey(localEnv, g, rotateSecretsKeyConfig{
+			manual:    *g.SystemRotateSecretsKeyCmd.Manual,
+			confirmed: *g.SystemRotateSecretsKeyCmd.Confirm,
+		})
+	case g.SystemChangeCIDRCmd.FullCommand():
+		return changeCIDR(localEnv, g, changeCIDRConfig{
+			podCIDR:     *g.SystemChangeCIDRCmd.PodCIDR,
+			serviceCIDR: *g.SystemChangeCIDRCmd.ServiceCIDR,
+			manual:      *g.SystemChangeCIDRCmd.Manual,
+			confirmed:   *g.SystemChangeCIDRCmd.Confirm,
 		})
 	case g.SystemExportCACmd.FullCommand():
 		return exportCertificateAuthority(localEnv,
@@ -708,6 +954,8 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 		return systemPullUpdates(localEnv,
 			*g.SystemPullUpdatesCmd.OpsCenterURL,
 			*g.SystemPullUpdatesCmd.RuntimePackage)
+	case g.SystemStageCmd.FullCommand():
+		return systemStage(localEnv, *g.SystemStageCmd.Packages)
 	case g.SystemUpdateCmd.FullCommand():
 		return systemUpdate(localEnv,
 			*g.SystemUpdateCmd.ChangesetID,
@@ -727,13 +975,64 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 			*g.SystemRollbackCmd.WithStatus)
 	case g.SystemStepDownCmd.FullCommand():
 		return stepDown(localEnv)
-	case g.BackupCmd.FullCommand():
+	case g.BackupHookCmd.FullCommand():
+		if *g.BackupHookCmd.App != "" {
+			return backupApp(localEnv, appBackupConfig{
+				release: *g.BackupHookCmd.App,
+				tarball: *g.BackupHookCmd.Tarball,
+				timeout: *g.BackupHookCmd.Timeout,
+				follow:  *g.BackupHookCmd.Follow,
+				silent:  *g.Silent,
+			})
+		}
 		return backup(localEnv,
-			*g.BackupCmd.Tarball,
-			*g.BackupCmd.Timeout,
-			*g.BackupCmd.Follow,
+			*g.BackupHookCmd.Tarball,
+			*g.BackupHookCmd.Timeout,
+			*g.BackupHookCmd.Follow,
 			*g.Silent)
+	case g.BackupScheduleCmd.FullCommand():
+		return backupSchedule(localEnv,
+			*g.BackupScheduleCmd.Schedule,
+			*g.BackupScheduleCmd.Directory,
+			*g.BackupScheduleCmd.Retain,
+			*g.BackupScheduleCmd.Remove)
+	case g.BackupUnpackCmd.FullCommand():
+		return unpackBackup(localEnv,
+			*g.BackupUnpackCmd.Archive,
+			*g.BackupUnpackCmd.Directory,
+			*g.BackupUnpackCmd.KeyFile)
+	case g.BackupVerifyCmd.FullCommand():
+		return verifyBackup(localEnv,
+			*g.BackupVerifyCmd.Backup,
+			*g.BackupVerifyCmd.KeyFile,
+			*g.BackupVerifyCmd.TestRestore)
 	case g.RestoreCmd.FullCommand():
+		if *g.RestoreCmd.App != "" {
+			if *g.RestoreCmd.From != "" || *g.RestoreCmd.Tarball == "" {
+				return trace.BadParameter("specify the tarball with the application backup to restore from")
+			}
+			return restoreApp(localEnv, appBackupConfig{
+				release:   *g.RestoreCmd.App,
+				tarball:   *g.RestoreCmd.Tarball,
+				timeout:   *g.RestoreCmd.Timeout,
+				follow:    *g.RestoreCmd.Follow,
+				silent:    *g.Silent,
+				confirmed: *g.RestoreCmd.Confirm,
+			})
+		}
+		if *g.RestoreCmd.From != "" {
+			if *g.RestoreCmd.Tarball != "" {
+				return trace.BadParameter("specify either the tarball for the restore hook or the backup directory with --from")
+			}
+			return restoreCluster(localEnv, g, restoreClusterConfig{
+				backup:    *g.RestoreCmd.From,
+				manual:    *g.RestoreCmd.Manual,
+				confirmed: *g.RestoreCmd.Confirm,
+			})
+		}
+		if *g.RestoreCmd.Tarball == "" {
+			return trace.BadParameter("specify the tarball to restore from with the restore hook or the backup directory with --from")
+		}
 		return restore(localEnv,
 			*g.RestoreCmd.Tarball,
 			*g.RestoreCmd.Timeout,
@@ -776,6 +1075,37 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 			os.Stdout)
 	case g.SystemStateDirCmd.FullCommand():
 		return printStateDir()
+	case g.SystemExportStateCmd.FullCommand():
+		return exportState(localEnv, stateBackendConfig{
+			backendType: *g.SystemExportStateCmd.Backend,
+			params:      *g.SystemExportStateCmd.BackendParams,
+		}, *g.SystemExportStateCmd.Output)
+	case g.SystemImportStateCmd.FullCommand():
+		return importState(localEnv, stateBackendConfig{
+			backendType: *g.SystemImportStateCmd.Backend,
+			params:      *g.SystemImportStateCmd.BackendParams,
+		}, *g.SystemImportStateCmd.Path, *g.SystemImportStateCmd.Confirmed)
+	case g.SystemStateChangesCmd.FullCommand():
+		return listStateChanges(localEnv, stateBackendConfig{
+			backendType: *g.SystemStateChangesCmd.Backend,
+			params:      *g.SystemStateChangesCmd.BackendParams,
+		}, *g.SystemStateChangesCmd.Since)
+	case g.SystemEtcdRestoreCmd.FullCommand():
+		return restoreEtcd(localEnv, g, etcdRestoreConfig{
+			snapshot:  *g.SystemEtcdRestoreCmd.Snapshot,
+			manual:    *g.SystemEtcdRestoreCmd.Manual,
+			confirmed: *g.SystemEtcdRestoreCmd.Confirm,
+		})
+	case g.SystemRestoreStateCmd.FullCommand():
+		return restoreState(localEnv, stateBackendConfig{
+			backendType: *g.SystemRestoreStateCmd.Backend,
+			params:      *g.SystemRestoreStateCmd.BackendParams,
+		}, *g.SystemRestoreStateCmd.To, *g.SystemRestoreStateCmd.Confirmed)
+	case g.SystemCheckStateCmd.FullCommand():
+		return checkState(localEnv, stateBackendConfig{
+			backendType: *g.SystemCheckStateCmd.Backend,
+			params:      *g.SystemCheckStateCmd.BackendParams,
+		}, *g.SystemCheckStateCmd.Fix)
 	case g.SystemExportRuntimeJournalCmd.FullCommand():
 		return exportRuntimeJournal(localEnv, *g.SystemExportRuntimeJournalCmd.OutputFile)
 	case g.SystemStreamRuntimeJournalCmd.FullCommand():
@@ -806,6 +1136,19 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 		return planetShell(localEnv)
 	case g.PlanetStatusCmd.FullCommand():
 		return getPlanetStatus(localEnv, extraArgs)
+	case g.PlanetStopCmd.FullCommand():
+		return planetStop(localEnv)
+	case g.PlanetStartCmd.FullCommand():
+		return planetStart(localEnv)
+	case g.ClusterStopCmd.FullCommand():
+		return stopCluster(localEnv, *g.ClusterStopCmd.Confirm)
+	case g.ClusterPromoteCmd.FullCommand():
+		return promoteCluster(localEnv, g, promoteClusterConfig{
+			manual:    *g.ClusterPromoteCmd.Manual,
+			confirmed: *g.ClusterPromoteCmd.Confirm,
+		})
+	case g.ClusterStartCmd.FullCommand():
+		return startCluster(localEnv)
 	case g.SystemDevicemapperMountCmd.FullCommand():
 		return devicemapperMount(*g.SystemDevicemapperMountCmd.Disk)
 	case g.SystemDevicemapperUnmountCmd.FullCommand():
@@ -821,6 +1164,28 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 		return resetUser(localEnv,
 			*g.UsersResetCmd.Name,
 			*g.UsersResetCmd.TTL)
+	case g.TokenCreateCmd.FullCommand():
+		return createJoinToken(localEnv,
+			*g.TokenCreateCmd.TTL,
+			*g.TokenCreateCmd.Roles,
+			*g.TokenCreateCmd.Uses)
+	case g.TokenListCmd.FullCommand():
+		return listJoinTokens(localEnv)
+	case g.TokenRemoveCmd.FullCommand():
+		return removeJoinToken(localEnv, *g.TokenRemoveCmd.Token)
+	case g.RosterStatusCmd.FullCommand():
+		return rosterStatus(localEnv)
+	case g.RosterApproveCmd.FullCommand():
+		return approveRosterChanges(localEnv, *g.RosterApproveCmd.ChangesID)
+	case g.OperationApproveCmd.FullCommand():
+		return approveOperation(localEnv, *g.OperationApproveCmd.OperationID)
+	case g.AuditCmd.FullCommand():
+		return showAuditRecords(localEnv, auditFilter{
+			user:   *g.AuditCmd.User,
+			since:  *g.AuditCmd.Since,
+			failed: *g.AuditCmd.Failed,
+			limit:  *g.AuditCmd.Limit,
+		}, *g.AuditCmd.Output)
 	case g.ResourceCreateCmd.FullCommand():
 		return createResource(localEnv, g,
 			*g.ResourceCreateCmd.Filename,
@@ -873,6 +1238,31 @@ func Execute(g *Application, cmd string, extraArgs []string) (err error) {
 		return top(localEnv,
 			*g.TopCmd.Interval,
 			*g.TopCmd.Step)
+	case g.ExpandCmd.FullCommand():
+		if *g.ExpandCmd.Roster != "" {
+			if *g.ExpandCmd.ProvisionSpec != "" {
+				return trace.BadParameter("--roster and --provision-spec are mutually exclusive")
+			}
+			return expandFromRoster(localEnv,
+				*g.ExpandCmd.Roster,
+				*g.ExpandCmd.Parallel)
+		}
+		if *g.ExpandCmd.ProvisionSpec == "" || *g.ExpandCmd.Role == "" {
+			return trace.BadParameter("either --roster or --provision-spec with --role is required")
+		}
+		return expandCluster(localEnv,
+			*g.ExpandCmd.ProvisionSpec,
+			*g.ExpandCmd.Role,
+			*g.ExpandCmd.Count)
+	case g.MirrorExportCmd.FullCommand():
+		return mirrorExport(localEnv,
+			*g.MirrorExportCmd.Path,
+			*g.MirrorExportCmd.OutputDir)
+	case g.MirrorServeCmd.FullCommand():
+		return mirrorServe(localEnv,
+			*g.MirrorServeCmd.Dir,
+			*g.MirrorServeCmd.RegistryAddr,
+			*g.MirrorServeCmd.PackagesAddr)
 	}
 	return trace.NotFound("unknown command %v", cmd)
 }
@@ -0,0 +1,68 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+
+	"github.com/gravitational/gravity/lib/archive"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/sbom"
+
+	dockerarchive "github.com/docker/docker/pkg/archive"
+	"github.com/gravitational/trace"
+)
+
+// showAppSBOM outputs the software bill of materials embedded
+// into the specified application package in the requested format
+func showAppSBOM(env *localenv.LocalEnvironment, locator loc.Locator, opsCenterURL string, format sbom.Format) error {
+	packages, err := getAppPackageService(env, opsCenterURL)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, reader, err := packages.ReadPackage(locator)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer reader.Close()
+	stream, err := dockerarchive.DecompressStream(reader)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer stream.Close()
+	var doc *sbom.Document
+	err = archive.TarGlob(tar.NewReader(stream), defaults.ResourcesDir,
+		[]string{defaults.SBOMFilename}, func(_ string, r io.Reader) error {
+			doc, err = sbom.Decode(r)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			return archive.Abort
+		})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if doc == nil {
+		return trace.NotFound("application %v does not have a software bill of materials, "+
+			"it was probably built with an older version of tele", locator)
+	}
+	return trace.Wrap(sbom.Encode(os.Stdout, *doc, format))
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/scan"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+)
+
+type appScanConfig struct {
+	// Package is the application package to scan
+	Package loc.Locator
+	// OpsCenterURL is the optional Gravity Hub with the application package
+	OpsCenterURL string
+	// ScannerPath is the path to the vulnerability scanner binary
+	ScannerPath string
+	// Threshold is the lowest vulnerability severity that fails the scan
+	Threshold string
+	// IgnoreUnfixed ignores vulnerabilities without an available fix
+	IgnoreUnfixed bool
+	// Format is the output format
+	Format constants.Format
+}
+
+// scanApp scans the container images of the specified application package
+// for vulnerabilities
+func scanApp(env *localenv.LocalEnvironment, config appScanConfig) error {
+	var policy scan.Policy
+	if config.Threshold != "" {
+		threshold, err := scan.ParseSeverity(config.Threshold)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		policy = scan.Policy{Threshold: threshold, IgnoreUnfixed: config.IgnoreUnfixed}
+	}
+	packages, err := getAppPackageService(env, config.OpsCenterURL)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	dir, err := ioutil.TempDir("", "scan")
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer os.RemoveAll(dir)
+	if config.Format == constants.EncodingText {
+		env.PrintStep("Unpacking application %v", config.Package)
+	}
+	err = pack.Unpack(packages, config.Package, dir, nil)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	registryDir := filepath.Join(dir, defaults.RegistryDir)
+	if ok, _ := utils.IsDirectory(registryDir); !ok {
+		return trace.NotFound("application %v does not have container images", config.Package)
+	}
+	if config.Format == constants.EncodingText {
+		env.PrintStep("Scanning container images for vulnerabilities")
+	}
+	report, err := scan.ScanDir(context.TODO(), scan.Config{
+		Dir:     registryDir,
+		Scanner: scan.NewTrivy(config.ScannerPath),
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	switch config.Format {
+	case constants.EncodingJSON:
+		bytes, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		fmt.Println(string(bytes))
+	default:
+		if err := printScanReport(env, *report); err != nil {
+			return trace.Wrap(err)
+		}
+		reportPath := filepath.Join(dir, defaults.ResourcesDir, defaults.ScanReportFilename)
+		if buildReport, err := scan.ReadReport(reportPath); err == nil {
+			env.Printf("\nScan report embedded at build time (%v): %v.\n",
+				buildReport.Created.Format(constants.HumanDateFormatSeconds),
+				scan.FormatCounts(buildReport.Counts()))
+		}
+	}
+	return trace.Wrap(policy.Check(*report))
+}
+
+func printScanReport(env *localenv.LocalEnvironment, report scan.Report) error {
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 1, '\t', 0)
+	fmt.Fprintf(w, "Image\tVulnerabilities\n")
+	fmt.Fprintf(w, "-----\t---------------\n")
+	for _, image := range report.Images {
+		fmt.Fprintf(w, "%v\t%v\n", image.Image, scan.FormatCounts(image.Counts()))
+	}
+	if err := w.Flush(); err != nil {
+		return trace.Wrap(err)
+	}
+	env.Printf("\nTotal: %v.\n", scan.FormatCounts(report.Counts()))
+	return nil
+}
+
+// getAppPackageService returns the package service of the specified
+// Gravity Hub or the local cluster package service
+func getAppPackageService(env *localenv.LocalEnvironment, opsCenterURL string) (pack.PackageService, error) {
+	if opsCenterURL != "" {
+		return env.PackageService(opsCenterURL)
+	}
+	return env.ClusterPackages()
+}
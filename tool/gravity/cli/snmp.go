@@ -0,0 +1,90 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"time"
+
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/snmp"
+	statusapi "github.com/gravitational/gravity/lib/status"
+	"github.com/gravitational/gravity/lib/system/signals"
+
+	"github.com/gravitational/trace"
+)
+
+// snmpAgentConfig defines the configuration of the SNMP sub-agent
+type snmpAgentConfig struct {
+	// addr is the address of the AgentX master agent
+	addr string
+	// rootOID is the object identifier of the cluster health subtree
+	rootOID string
+	// interval is how often the cluster status is refreshed
+	interval time.Duration
+}
+
+// snmpAgent runs the SNMP sub-agent exposing the cluster health
+// until interrupted
+func snmpAgent(env *localenv.LocalEnvironment, config snmpAgentConfig) error {
+	root, err := snmp.ParseOID(config.rootOID)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	agent, err := snmp.NewSubAgent(snmp.Config{
+		Addr: config.addr,
+		Root: root,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupt := signals.WatchTerminationSignals(ctx, cancel, env)
+	defer interrupt.Close()
+
+	refresh := func() {
+		status, err := statusOnce(ctx, operator, "", env)
+		if err != nil {
+			log.WithError(err).Warn("Failed to collect cluster status.")
+		}
+		if status == nil {
+			status = &statusapi.Status{}
+		}
+		agent.SetVariables(snmp.ClusterVariables(root, *status))
+	}
+	refresh()
+	go func() {
+		ticker := time.NewTicker(config.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				refresh()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	env.Printf("Serving cluster health under %v via AgentX master agent at %v.\n", root, agent.Addr)
+	return trace.Wrap(agent.Serve(ctx))
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// stateBackendConfig specifies the backend to export the cluster state from
+// or import it into
+type stateBackendConfig struct {
+	// backendType is the type of the backend, the local cluster
+	// etcd backend is used if unspecified
+	backendType string
+	// params is the backend-specific configuration
+	params map[string]string
+}
+
+// exportState writes the cluster state from the specified backend to the
+// file at path or to stdout if path is "-"
+func exportState(env *localenv.LocalEnvironment, config stateBackendConfig, path string) error {
+	backend, err := newStateBackend(env, config)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer backend.Close()
+	var w io.Writer = os.Stdout
+	if path != "-" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, defaults.PrivateFileMask)
+		if err != nil {
+			return trace.ConvertSystemError(err)
+		}
+		defer f.Close()
+		w = f
+	}
+	count, err := storage.WriteStateArchive(context.TODO(), backend, w)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if path != "-" {
+		env.Printf("Exported %v records to %v.\n", count, path)
+	}
+	return nil
+}
+
+// importState restores the cluster state from the archive at path
+// in the specified backend
+func importState(env *localenv.LocalEnvironment, config stateBackendConfig, path string, confirmed bool) error {
+	if !confirmed {
+		env.Println("Records from the archive will overwrite the existing " +
+			"records in the cluster state. Are you sure?")
+		resp, err := confirm()
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if !resp {
+			env.Println("Action cancelled by user.")
+			return nil
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	backend, err := newStateBackend(env, config)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer backend.Close()
+	count, err := storage.ReadStateArchive(context.TODO(), backend, f)
+	if err != nil {
+		return trace.Wrap(err, "failed to import state after %v records", count)
+	}
+	env.Printf("Imported %v records from %v.\n", count, path)
+	return nil
+}
+
+func newStateBackend(env *localenv.LocalEnvironment, config stateBackendConfig) (storage.Backend, error) {
+	if config.backendType == "" {
+		clusterEnv, err := env.NewClusterEnvironment()
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return clusterEnv.Backend, nil
+	}
+	params := make(storage.BackendParams, len(config.params))
+	for name, value := range config.params {
+		params[name] = parseBackendParam(value)
+	}
+	backend, err := storage.NewBackend(config.backendType, params)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return backend, nil
+}
+
+// parseBackendParam converts the value specified on the command line
+// to the type expected by the backend configuration
+func parseBackendParam(value string) interface{} {
+	if i, err := strconv.Atoi(value); err == nil {
+		return i
+	}
+	if b, err := strconv.ParseBool(value); err == nil {
+		return b
+	}
+	return value
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// listStateChanges prints the changes to the cluster state
+// recorded after the specified point in time
+func listStateChanges(env *localenv.LocalEnvironment, config stateBackendConfig, since string) error {
+	sinceTime, err := parsePointInTime(since, time.Now())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	changelog, closer, err := newStateChangelog(env, config)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer closer.Close()
+	changes, err := changelog.GetStateChanges(sinceTime)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if len(changes) == 0 {
+		env.Println("No changes recorded.")
+		return nil
+	}
+	printStateChanges(changes, os.Stdout)
+	return nil
+}
+
+// restoreState reverts the changes to the cluster state recorded
+// after the specified point in time
+func restoreState(env *localenv.LocalEnvironment, config stateBackendConfig, to string, confirmed bool) error {
+	toTime, err := parsePointInTime(to, time.Now())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	changelog, closer, err := newStateChangelog(env, config)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer closer.Close()
+	changes, err := changelog.GetStateChanges(toTime)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if len(changes) == 0 {
+		env.Printf("No changes recorded after %v.\n", toTime.Format(time.RFC3339))
+		return nil
+	}
+	if !confirmed {
+		printStateChanges(changes, os.Stdout)
+		env.Printf("The %v changes above will be reverted to restore the cluster "+
+			"state as of %v. Are you sure?\n", len(changes), toTime.Format(time.RFC3339))
+		resp, err := confirm()
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if !resp {
+			env.Println("Action cancelled by user.")
+			return nil
+		}
+	}
+	count, err := changelog.RestoreState(context.TODO(), toTime)
+	if err != nil {
+		return trace.Wrap(err, "failed to restore state after reverting %v changes", count)
+	}
+	env.Printf("Reverted %v changes, the cluster state has been restored to %v.\n",
+		count, toTime.Format(time.RFC3339))
+	return nil
+}
+
+func newStateChangelog(env *localenv.LocalEnvironment, config stateBackendConfig) (storage.StateChangelog, io.Closer, error) {
+	backend, err := newStateBackend(env, config)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	changelog, ok := backend.(storage.StateChangelog)
+	if !ok {
+		backend.Close()
+		return nil, nil, trace.BadParameter("backend %T does not support state changelog", backend)
+	}
+	return changelog, backend, nil
+}
+
+func printStateChanges(changes []storage.StateChange, w io.Writer) {
+	t := new(tabwriter.Writer)
+	t.Init(w, 0, 8, 1, '\t', 0)
+	fmt.Fprintf(t, "Time\tChange\tKey\n")
+	fmt.Fprintf(t, "----\t------\t---\n")
+	for _, change := range changes {
+		fmt.Fprintf(t, "%v\t%v\t%v\n", change.Time.Format(time.RFC3339),
+			change.Op, change.Path())
+	}
+	t.Flush()
+}
+
+// parsePointInTime parses the specified value either as a timestamp
+// in RFC3339 format or as a duration relative to now
+func parsePointInTime(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return time.Time{}, trace.BadParameter("expected a timestamp in RFC3339 format "+
+			"(e.g. 2019-06-01T10:00:00Z) or a duration (e.g. 30m), got %q", value)
+	}
+	return now.Add(-d).UTC(), nil
+}
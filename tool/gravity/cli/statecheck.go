@@ -0,0 +1,77 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/storage"
+
+	"github.com/gravitational/trace"
+)
+
+// checkState validates the referential integrity of the cluster state
+// and optionally repairs the found inconsistencies
+func checkState(env *localenv.LocalEnvironment, config stateBackendConfig, fix bool) error {
+	backend, err := newStateBackend(env, config)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer backend.Close()
+	problems, err := ops.CheckState(context.TODO(), backend)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if len(problems) == 0 {
+		env.Println("No inconsistencies found.")
+		return nil
+	}
+	printStateProblems(problems, os.Stdout)
+	if !fix {
+		return trace.CompareFailed("found %v inconsistencies, "+
+			"run with --fix to repair the fixable ones", len(problems))
+	}
+	fixed, err := ops.FixState(context.TODO(), backend, problems)
+	if err != nil {
+		return trace.Wrap(err, "failed to repair state after fixing %v inconsistencies", fixed)
+	}
+	env.Printf("Fixed %v inconsistencies.\n", fixed)
+	if fixed < len(problems) {
+		return trace.CompareFailed("%v inconsistencies need to be fixed manually",
+			len(problems)-fixed)
+	}
+	return nil
+}
+
+func printStateProblems(problems []storage.StateProblem, w io.Writer) {
+	t := new(tabwriter.Writer)
+	t.Init(w, 0, 8, 1, '\t', 0)
+	fmt.Fprintf(t, "Problem\tKey\tFixable\tDescription\n")
+	fmt.Fprintf(t, "-------\t---\t-------\t-----------\n")
+	for _, problem := range problems {
+		fmt.Fprintf(t, "%v\t%v\t%v\t%v\n", problem.Kind,
+			strings.Join(problem.Key, "/"), problem.Fixable, problem.Message)
+	}
+	t.Flush()
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"text/tabwriter"
 	"time"
 
@@ -32,6 +33,8 @@ import (
 	"github.com/gravitational/gravity/lib/ops"
 	"github.com/gravitational/gravity/lib/schema"
 	statusapi "github.com/gravitational/gravity/lib/status"
+	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/clusterconfig"
 	"github.com/prometheus/alertmanager/api/v2/models"
 
 	"github.com/dustin/go-humanize"
@@ -166,9 +169,31 @@ func statusOnce(ctx context.Context, operator ops.Operator, operationID string,
 		return status, trace.Wrap(err)
 	}
 
+	addResourcePressure(ctx, operator, *cluster, status)
 	return status, nil
 }
 
+// addResourcePressure checks the local node for resource pressure and
+// records the results in the status of the node
+func addResourcePressure(ctx context.Context, operator ops.Operator, cluster ops.Site, status *statusapi.Status) {
+	if status.Agent == nil {
+		return
+	}
+	server, err := findLocalServer(cluster)
+	if err != nil {
+		log.WithError(err).Debug("Failed to find local node.")
+		return
+	}
+	thresholds := clusterconfig.DefaultResourcePressure()
+	config, err := operator.GetClusterConfiguration(cluster.Key())
+	if err != nil {
+		log.WithError(err).Warn("Failed to query cluster configuration, will use default resource pressure thresholds.")
+	} else {
+		thresholds = config.GetResourcePressure()
+	}
+	status.Agent.AddNodeProbes(server.AdvertiseIP, checks.RunResourcePressureChecks(ctx, thresholds))
+}
+
 // printStatus calls an appropriate "print" method based on the printing options
 func printStatus(operator ops.Operator, status clusterStatus, printOptions printOptions) error {
 	switch {
@@ -185,7 +210,7 @@ func printStatus(operator ops.Operator, status clusterStatus, printOptions print
 		return nil
 
 	case printOptions.token:
-		fmt.Print(status.Token.Token)
+		return trace.Wrap(printJoinToken(operator))
 
 	case printOptions.quiet:
 	default:
@@ -198,6 +223,8 @@ func printStatusWithOptions(status clusterStatus, printOptions printOptions) err
 	switch printOptions.format {
 	case constants.EncodingJSON:
 		return trace.Wrap(printStatusJSON(status))
+	case constants.EncodingNagios:
+		return trace.Wrap(printStatusNagios(status, os.Stdout))
 	default:
 		printStatusText(status)
 	}
@@ -294,12 +321,59 @@ func printClusterStatus(cluster statusapi.Cluster, w io.Writer) {
 		fmt.Fprintf(w, "Cluster image:\t%v, version %v\n", cluster.App.Name,
 			cluster.App.Version)
 	}
-	if cluster.Token.Token != "" {
-		fmt.Fprintf(w, "Join token:\t%v\n", cluster.Token.Token)
+	if len(cluster.Releases) != 0 {
+		fmt.Fprintf(w, "Applications:\n")
+		for _, release := range cluster.Releases {
+			printRelease(release, w)
+		}
+	}
+	if cluster.Demo {
+		fmt.Fprintf(w, "Cluster mode:\t%v\n", color.YellowString("demo (not for production use)"))
+	}
+	if cluster.FIPS {
+		fmt.Fprintf(w, "FIPS mode:\tenabled\n")
+	}
+	if cluster.Hardening != nil {
+		if len(cluster.Hardening.Problems) == 0 {
+			fmt.Fprintf(w, "Hardening:\t%v\n", cluster.Hardening.Profile)
+		} else {
+			fmt.Fprintf(w, "Hardening:\t%v, %v\n", cluster.Hardening.Profile,
+				color.YellowString("%v deviation(-s)", len(cluster.Hardening.Problems)))
+			for _, problem := range cluster.Hardening.Problems {
+				fmt.Fprintf(w, "    * %v\n", problem)
+			}
+		}
 	}
 	if cluster.Extension != nil {
 		cluster.Extension.WriteTo(w)
 	}
+	if len(cluster.NodePools) != 0 {
+		fmt.Fprintf(w, "Node pools:\n")
+		for _, pool := range cluster.NodePools {
+			printNodePool(pool, w)
+		}
+	}
+	if cluster.HealthChecks != nil && len(cluster.HealthChecks.Results) != 0 {
+		printHealthChecks(*cluster.HealthChecks, w)
+	}
+	if cluster.EtcdMaintenance != nil {
+		printEtcdMaintenance(*cluster.EtcdMaintenance, w)
+	}
+	if cluster.Backup != nil {
+		printBackupStatus(*cluster.Backup, w)
+	}
+	if cluster.Replication != nil {
+		printReplicationStatus(*cluster.Replication, w)
+	}
+	if cluster.EtcdHealth != nil {
+		printEtcdHealth(*cluster.EtcdHealth, w)
+	}
+	if cluster.Network != nil {
+		printNetwork(*cluster.Network, w)
+	}
+	if cluster.Certificates != nil {
+		printCertificates(*cluster.Certificates, w)
+	}
 	if len(cluster.ActiveOperations) != 0 {
 		fmt.Fprintf(w, "Active operations:\n")
 		for _, op := range cluster.ActiveOperations {
@@ -313,6 +387,230 @@ func printClusterStatus(cluster statusapi.Cluster, w io.Writer) {
 	cluster.Endpoints.Cluster.WriteTo(w)
 }
 
+func printRelease(release statusapi.Release, w io.Writer) {
+	fmt.Fprintf(w, "    * %v:\t%v, version %v, revision %v in namespace %v",
+		release.Name, release.Application.Name, release.Application.Version,
+		release.Revision, release.Namespace)
+	if !release.IsDeployed() {
+		fmt.Fprint(w, color.YellowString(", %v", strings.ToLower(release.Status)))
+	}
+	fmt.Fprintln(w)
+}
+
+func printNodePool(pool statusapi.NodePool, w io.Writer) {
+	fmt.Fprintf(w, "    * %v (profile %v):\t", pool.Name, pool.Profile)
+	switch capacity := pool.Capacity(); {
+	case capacity < 0:
+		fmt.Fprintf(w, "%v node(-s)", pool.Nodes)
+	case capacity == 0:
+		fmt.Fprintf(w, "%v of %v node(-s), full", pool.Nodes, pool.MaxCount)
+	default:
+		fmt.Fprintf(w, "%v of %v node(-s), %v can join", pool.Nodes, pool.MaxCount, capacity)
+	}
+	if pool.IsBelowMinimum() {
+		fmt.Fprint(w, color.YellowString(", below minimum of %v", pool.MinCount))
+	}
+	fmt.Fprintln(w)
+}
+
+func printHealthChecks(status storage.HealthCheckStatus, w io.Writer) {
+	fmt.Fprintf(w, "Health checks:\tlast run %v\n", humanize.RelTime(status.Updated, time.Now(), "ago", ""))
+	for _, result := range status.Results {
+		switch {
+		case result.Healthy:
+			fmt.Fprintf(w, "    * %v:\t%v\n", result.Name, color.GreenString("healthy"))
+		case result.Severity == storage.HealthCheckSeverityWarning:
+			fmt.Fprintf(w, "    * %v:\t%v\n", result.Name, color.YellowString("warning, %v", result.Message))
+		default:
+			fmt.Fprintf(w, "    * %v:\t%v\n", result.Name, color.RedString("failed, %v", result.Message))
+		}
+	}
+}
+
+func printNetwork(network statusapi.Network, w io.Writer) {
+	var pairs int
+	var maxRTT time.Duration
+	for _, report := range network.Reports {
+		for _, peer := range report.Peers {
+			pairs++
+			if peer.Host.RTT > maxRTT {
+				maxRTT = peer.Host.RTT
+			}
+		}
+	}
+	fmt.Fprintf(w, "Network:\t")
+	if len(network.Problems) == 0 {
+		fmt.Fprint(w, color.GreenString("ok"))
+	} else {
+		fmt.Fprint(w, color.YellowString("degraded"))
+	}
+	fmt.Fprintf(w, ", %v node pair(-s) probed, max latency %v\n", pairs, maxRTT.Round(time.Microsecond))
+	for _, problem := range network.Problems {
+		message := color.YellowString(problem.Message)
+		if problem.Critical {
+			message = color.RedString(problem.Message)
+		}
+		if problem.To == "" {
+			fmt.Fprintf(w, "    * %v:\t%v\n", problem.From, message)
+			continue
+		}
+		fmt.Fprintf(w, "    * %v -> %v:\t%v\n", problem.From, problem.To, message)
+	}
+}
+
+func printEtcdHealth(health statusapi.EtcdHealth, w io.Writer) {
+	status := health.Status
+	fmt.Fprintf(w, "Etcd:\t")
+	var critical bool
+	for _, problem := range health.Problems {
+		critical = critical || problem.Critical
+	}
+	switch {
+	case critical:
+		fmt.Fprint(w, color.RedString("critical"))
+	case len(health.Problems) != 0:
+		fmt.Fprint(w, color.YellowString("degraded"))
+	default:
+		fmt.Fprint(w, color.GreenString("ok"))
+	}
+	fmt.Fprintf(w, ", %v%% of %v quota used", status.QuotaUsage(), humanize.Bytes(uint64(status.QuotaBytes)))
+	if status.Leader != "" {
+		fmt.Fprintf(w, ", leader %v", status.Leader)
+	}
+	fmt.Fprintf(w, ", %v leader change(-s) in the last %v\n", len(status.LeaderChanges),
+		defaults.EtcdLeaderChangesWindow)
+	for _, member := range status.Members {
+		if member.Message != "" {
+			continue
+		}
+		fmt.Fprintf(w, "    * %v:\tdatabase size %v, %v raft entries behind the leader\n",
+			member.Name, humanize.Bytes(uint64(member.DBSize)), member.RaftIndexLag)
+	}
+	if len(status.Stores) != 0 {
+		var stores []string
+		for _, store := range status.Stores {
+			stores = append(stores, fmt.Sprintf("%v %v", store.Name, store.Keys))
+		}
+		fmt.Fprintf(w, "    * keys:\t%v\n", strings.Join(stores, ", "))
+	}
+	for _, problem := range health.Problems {
+		message := color.YellowString(problem.String())
+		if problem.Critical {
+			message = color.RedString(problem.String())
+		}
+		fmt.Fprintf(w, "    %v\n", message)
+	}
+}
+
+func printCertificates(certificates statusapi.Certificates, w io.Writer) {
+	status := certificates.Status
+	fmt.Fprintf(w, "Certificates:\t")
+	var critical bool
+	for _, problem := range certificates.Problems {
+		critical = critical || problem.Critical
+	}
+	switch {
+	case critical:
+		fmt.Fprint(w, color.RedString("critical"))
+	case len(certificates.Problems) != 0:
+		fmt.Fprint(w, color.YellowString("expiring"))
+	default:
+		fmt.Fprint(w, color.GreenString("ok"))
+	}
+	if next := status.Next(); next != nil {
+		fmt.Fprintf(w, ", next to expire %v in %v", next,
+			storage.FormatExpiresIn(next.ExpiresIn(time.Now())))
+	}
+	fmt.Fprintln(w)
+	for _, problem := range certificates.Problems {
+		message := color.YellowString(problem.String())
+		if problem.Critical {
+			message = color.RedString(problem.String())
+		}
+		fmt.Fprintf(w, "    %v\n", message)
+	}
+	for _, message := range status.Errors {
+		fmt.Fprintf(w, "    %v\n", color.YellowString(message))
+	}
+}
+
+func printBackupStatus(status storage.BackupStatus, w io.Writer) {
+	fmt.Fprintf(w, "Last backup:\t")
+	if status.LastBackup.IsZero() {
+		fmt.Fprint(w, "none")
+	} else {
+		fmt.Fprintf(w, "%v, %v on %v", humanize.RelTime(status.LastBackup, time.Now(), "ago", ""),
+			status.Path, status.Node)
+	}
+	fmt.Fprintln(w)
+	if status.Message != "" {
+		fmt.Fprintf(w, "    %v\n", color.YellowString("failed %v: %v",
+			humanize.RelTime(status.LastAttempt, time.Now(), "ago", ""), status.Message))
+	}
+	if status.Uploaded != "" {
+		fmt.Fprintf(w, "    * uploaded to %v\n", status.Uploaded)
+	}
+	if status.UploadMessage != "" {
+		fmt.Fprintf(w, "    %v\n", color.YellowString("upload failed: %v", status.UploadMessage))
+	}
+	for _, app := range status.Apps {
+		fmt.Fprintf(w, "    * %v:\t", app.App)
+		if app.Message == "" {
+			fmt.Fprintln(w, color.GreenString("ok"))
+		} else {
+			fmt.Fprintln(w, color.YellowString(app.Message))
+		}
+	}
+}
+
+func printReplicationStatus(status storage.ReplicationStatus, w io.Writer) {
+	fmt.Fprintf(w, "Standby replication:\t")
+	if status.Backup == "" {
+		fmt.Fprint(w, "none")
+	} else {
+		fmt.Fprintf(w, "%v behind primary, %v on %v",
+			humanize.RelTime(status.Taken, time.Now(), "", ""), status.Path, status.Node)
+	}
+	fmt.Fprintln(w)
+	if status.Message != "" {
+		fmt.Fprintf(w, "    %v\n", color.YellowString("failed %v: %v",
+			humanize.RelTime(status.LastAttempt, time.Now(), "ago", ""), status.Message))
+	}
+	if len(status.MissingImages) != 0 {
+		fmt.Fprintf(w, "    %v\n", color.YellowString("%v images missing from the registry: %v",
+			len(status.MissingImages), strings.Join(status.MissingImages, ", ")))
+	}
+}
+
+func printEtcdMaintenance(status storage.EtcdMaintenanceStatus, w io.Writer) {
+	fmt.Fprintf(w, "Etcd maintenance:\t")
+	if status.IsHealthy() {
+		fmt.Fprint(w, color.GreenString("ok"))
+	} else {
+		fmt.Fprint(w, color.YellowString("degraded"))
+	}
+	fmt.Fprintf(w, ", last run %v\n", humanize.RelTime(status.Updated, time.Now(), "ago", ""))
+	if status.Message != "" {
+		fmt.Fprintf(w, "    %v\n", color.YellowString(status.Message))
+	}
+	if !status.LastCompaction.IsZero() {
+		fmt.Fprintf(w, "    * compacted to revision %v:\t%v\n", status.CompactedRevision,
+			humanize.RelTime(status.LastCompaction, time.Now(), "ago", ""))
+	}
+	for _, member := range status.Members {
+		fmt.Fprintf(w, "    * %v:\tdatabase size %v", member.Name, humanize.Bytes(uint64(member.DBSize)))
+		if !member.LastDefragmentation.IsZero() {
+			fmt.Fprintf(w, ", defragmented %v, reclaimed %v",
+				humanize.RelTime(member.LastDefragmentation, time.Now(), "ago", ""),
+				humanize.Bytes(uint64(member.ReclaimedBytes)))
+		}
+		if member.Message != "" {
+			fmt.Fprint(w, color.YellowString(", %v", member.Message))
+		}
+		fmt.Fprintln(w)
+	}
+}
+
 func printOperation(operation *statusapi.ClusterOperation, w io.Writer) {
 	fmt.Fprintf(w, "    * %v (%v)\n", operation.Type, operation.ID)
 	fmt.Fprintf(w, "      started:\t%v (%v)\n",
@@ -421,7 +719,7 @@ type printOptions struct {
 	quiet bool
 	// operationID limits output to that of a particular operation
 	operationID string
-	// format specifies the output format (JSON or text)
+	// format specifies the output format (JSON, text or Nagios)
 	format constants.Format
 }
 
@@ -441,6 +739,23 @@ func (r statusOperator) GetApplicationEndpoints(clusterKey ops.SiteKey) ([]ops.E
 	return r.Operator.GetApplicationEndpoints(clusterKey)
 }
 
+// printJoinToken issues a new short-lived cluster join token and prints it
+func printJoinToken(operator ops.Operator) error {
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	token, err := operator.CreateJoinToken(context.TODO(), ops.CreateJoinTokenRequest{
+		SiteKey: cluster.Key(),
+		TTL:     defaults.JoinTokenTTL,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	fmt.Print(token.Token)
+	return nil
+}
+
 // statusOperator is a thin-wrapper around operator that uses
 // etcd directly but falls back the cluster controller if available for certain APIs
 type statusOperator struct {
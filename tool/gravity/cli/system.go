@@ -276,6 +276,8 @@ func reinstallOneshotService(env *localenv.LocalEnvironment, serviceName string,
 			Type:            constants.OneshotService,
 			StartCommand:    strings.Join(cmd, " "),
 			RemainAfterExit: true,
+			// Restart the service after the node reboots
+			WantedBy: defaults.SystemServiceWantedBy,
 		},
 	})
 	return trace.Wrap(err)
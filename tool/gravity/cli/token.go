@@ -0,0 +1,117 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/gravitational/trace"
+)
+
+func createJoinToken(env *localenv.LocalEnvironment, ttl time.Duration, roles []string, uses int) error {
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	token, err := operator.CreateJoinToken(context.TODO(), ops.CreateJoinTokenRequest{
+		SiteKey: cluster.Key(),
+		TTL:     ttl,
+		Roles:   utils.FlattenStringSlice(roles),
+		MaxUses: uses,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	fmt.Printf("Join token has been created and is valid until %v:\n%v\n",
+		token.Expires.Format(time.RFC3339), token.Token)
+	return nil
+}
+
+func listJoinTokens(env *localenv.LocalEnvironment) error {
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	tokens, err := operator.GetJoinTokens(context.TODO(), cluster.Key())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 1, '\t', 0)
+	fmt.Fprintf(w, "Token\tRoles\tUses\tExpires\n")
+	fmt.Fprintf(w, "-----\t-----\t----\t-------\n")
+	for _, token := range tokens {
+		roles := "*"
+		if len(token.Roles) != 0 {
+			roles = strings.Join(token.Roles, ",")
+		}
+		uses := fmt.Sprintf("%v", token.Uses)
+		if token.MaxUses != 0 {
+			uses = fmt.Sprintf("%v/%v", token.Uses, token.MaxUses)
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", token.Token, roles, uses,
+			token.Expires.Format(time.RFC3339))
+	}
+	w.Flush()
+	return nil
+}
+
+func removeJoinToken(env *localenv.LocalEnvironment, token string) error {
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	err = operator.DeleteJoinToken(context.TODO(), ops.DeleteJoinTokenRequest{
+		SiteKey: cluster.Key(),
+		Token:   token,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	fmt.Println("Join token has been removed.")
+	return nil
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/localenv"
+	clusterupdate "github.com/gravitational/gravity/lib/update/cluster"
+
+	"github.com/dustin/go-humanize"
+	"github.com/gravitational/trace"
+)
+
+// analyzeUpgrade reports the impact of upgrading the cluster to the specified
+// cluster image without creating the upgrade operation
+func analyzeUpgrade(env *localenv.LocalEnvironment, updatePackage string) error {
+	clusterEnv, err := env.NewClusterEnvironment()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := clusterEnv.Operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if updatePackage == "" {
+		updatePackage = cluster.App.Package.Name
+	}
+	updateLoc, err := loc.MakeLocator(updatePackage)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	installedApp, err := clusterEnv.Apps.GetApp(cluster.App.Package)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	updateApp, err := clusterEnv.Apps.GetApp(*updateLoc)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	path, err := clusterupdate.GetUpgradePath(clusterEnv.Apps, *installedApp, *updateApp)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	report, err := clusterupdate.Analyze(clusterupdate.AnalyzeConfig{
+		Packages:     clusterEnv.ClusterPackages,
+		Client:       clusterEnv.Client,
+		Servers:      cluster.ClusterState.Servers,
+		InstalledApp: *installedApp,
+		UpdateApp:    *updateApp,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	printUpgradeReport(env, *report, path)
+	return nil
+}
+
+func printUpgradeReport(env *localenv.LocalEnvironment, report clusterupdate.UpgradeReport, path []clusterupdate.Release) {
+	env.Printf("Upgrade from %v to %v\n", report.InstalledPackage, report.UpdatePackage)
+	if len(path) > 1 {
+		env.Printf("Upgrade path: %v -> %v\n", report.InstalledPackage.Version, formatUpgradePath(path))
+	}
+	if report.KubernetesVersion != "" {
+		env.Printf("Kubernetes version: %v\n", report.KubernetesVersion)
+	}
+
+	env.Println("\nDeprecated Kubernetes APIs:")
+	if len(report.DeprecatedAPIs) == 0 {
+		env.Println("  none")
+	} else {
+		w := new(tabwriter.Writer)
+		w.Init(os.Stdout, 0, 8, 1, '\t', 0)
+		fmt.Fprintf(w, "Kind\tNamespace\tName\tAPI Version\tReplacement\tRemoved In\n")
+		fmt.Fprintf(w, "----\t---------\t----\t-----------\t-----------\t----------\n")
+		for _, usage := range report.DeprecatedAPIs {
+			fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\n", usage.Kind, usage.Namespace,
+				usage.Name, usage.APIVersion, usage.Replacement, usage.RemovedIn)
+		}
+		w.Flush()
+	}
+
+	env.Println("\nRemoved features:")
+	if len(report.RemovedFeatures) == 0 {
+		env.Println("  none")
+	}
+	for _, feature := range report.RemovedFeatures {
+		env.Printf("  * %v\n", feature)
+	}
+
+	env.Println("\nNodes:")
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 1, '\t', 0)
+	fmt.Fprintf(w, "Hostname\tAddress\tRole\tStaging Disk Space\tEstimated Downtime\n")
+	fmt.Fprintf(w, "--------\t-------\t----\t------------------\t------------------\n")
+	for _, node := range report.Nodes {
+		downtime := "none"
+		if node.Downtime != 0 {
+			downtime = node.Downtime.String()
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", node.Hostname, node.AdvertiseIP,
+			node.Role, humanize.Bytes(node.StagingBytes), downtime)
+	}
+	w.Flush()
+}
@@ -0,0 +1,252 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"path/filepath"
+
+	appservice "github.com/gravitational/gravity/lib/app/service"
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
+	libfsm "github.com/gravitational/gravity/lib/fsm"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/ops"
+	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/rpc"
+	"github.com/gravitational/gravity/lib/state"
+	"github.com/gravitational/gravity/lib/systeminfo"
+	clusterupdate "github.com/gravitational/gravity/lib/update/cluster"
+	"github.com/gravitational/gravity/lib/utils"
+
+	"github.com/dustin/go-humanize"
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+)
+
+// stageUpgrade downloads the system packages of the specified cluster image
+// to all cluster nodes without creating the upgrade operation.
+// The upgrade operation started later skips the packages that have already been staged
+func stageUpgrade(env *localenv.LocalEnvironment, updatePackage string) error {
+	clusterEnv, err := env.NewClusterEnvironment()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	operator := clusterEnv.Operator
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if cluster.State != ops.SiteStateActive {
+		return trace.BadParameter("cluster is %v: packages can only be staged "+
+			"on an active cluster", cluster.State)
+	}
+	if updatePackage == "" {
+		updatePackage = cluster.App.Package.Name
+	}
+	updateLoc, err := loc.MakeLocator(updatePackage)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	updateApp, err := clusterEnv.Apps.GetApp(*updateLoc)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = pack.CheckUpdatePackage(cluster.App.Package, updateApp.Package)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = checkCanUpdate(*cluster, operator, updateApp.Manifest)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	leader, err := findLocalServer(*cluster)
+	if err != nil {
+		return trace.Wrap(err, "failed to find local node in cluster state.\n"+
+			"Make sure you stage the upgrade from one of the cluster master nodes.")
+	}
+	teleportClient, err := env.TeleportClient(constants.Localhost)
+	if err != nil {
+		return trace.Wrap(err, "failed to create a teleport client")
+	}
+	ctx := context.TODO()
+	proxy, err := teleportClient.ConnectToProxy(ctx)
+	if err != nil {
+		return trace.Wrap(err, "failed to connect to teleport proxy")
+	}
+	env.PrintStep("Staging upgrade of %v from %v to %v",
+		updateApp.Package.Name, cluster.App.Package.Version, updateApp.Package.Version)
+	deployCtx, cancel := context.WithTimeout(ctx, defaults.AgentDeployTimeout)
+	defer cancel()
+	env.PrintStep("Deploying agents on the nodes")
+	creds, err := deployAgents(deployCtx, deployAgentsRequest{
+		clusterState: cluster.ClusterState,
+		clusterName:  cluster.Domain,
+		clusterEnv:   clusterEnv,
+		proxy:        proxy,
+		leader:       leader,
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	runner := libfsm.NewAgentRunner(creds)
+	defer func() {
+		var addrs []string
+		for _, server := range cluster.ClusterState.Servers {
+			addrs = append(addrs, server.AdvertiseIP)
+		}
+		err := rpc.ShutdownAgents(ctx, addrs, logrus.StandardLogger(), runner)
+		if err != nil {
+			logrus.WithError(err).Warn("Failed to shut down agents.")
+		}
+	}()
+	for _, server := range cluster.ClusterState.Servers {
+		packages, err := clusterupdate.StagedPackages(cluster.App.Manifest,
+			updateApp.Manifest, server.Role)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if len(packages) == 0 {
+			env.PrintStep("Node %v does not need system package updates", server.Hostname)
+			continue
+		}
+		env.PrintStep("Staging system packages on node %v", server.Hostname)
+		args := []string{"system", "stage"}
+		for _, locator := range packages {
+			args = append(args, "--package", locator.String())
+		}
+		err = runner.Run(ctx, server, args...)
+		if err != nil {
+			return trace.Wrap(err, "failed to stage packages on node %v", server.Hostname)
+		}
+	}
+	env.PrintStep("Upgrade has been staged. Run 'gravity upgrade %v' to start it", updateApp.Package)
+	return nil
+}
+
+// systemStage downloads the specified packages from the cluster package service
+// into the local package store after making sure there is enough disk space for them
+func systemStage(env *localenv.LocalEnvironment, packages []string) error {
+	clusterPackages, err := env.ClusterPackages()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	var updates []loc.Locator
+	var requiredBytes uint64
+	for _, name := range packages {
+		locator, err := loc.ParseLocator(name)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		upToDate, err := pack.IsPackageUpToDate(clusterPackages, env.Packages, *locator)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if upToDate {
+			env.Printf("Package %v has already been staged.\n", locator)
+			continue
+		}
+		envelope, err := clusterPackages.ReadPackageEnvelope(*locator)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		// The package is stored both as an archive and unpacked
+		requiredBytes += 2 * uint64(envelope.SizeBytes)
+		updates = append(updates, *locator)
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	stateDir, err := state.GetStateDir()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := checkFreeSpace(stateDir, requiredBytes); err != nil {
+		return trace.Wrap(err)
+	}
+	for _, update := range updates {
+		env.Printf("Pulling package %v.\n", update)
+		existingLabels, err := queryLocalPackageLabels(env.Packages, update)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		_, err = appservice.PullPackage(appservice.PackagePullRequest{
+			SrcPack: clusterPackages,
+			DstPack: env.Packages,
+			Package: update,
+			Upsert:  true,
+			Labels:  existingLabels,
+		})
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		upToDate, err := pack.IsPackageUpToDate(clusterPackages, env.Packages, update)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if !upToDate {
+			return trace.CompareFailed("checksum of the staged package %v "+
+				"does not match the cluster package", update)
+		}
+	}
+	// Packages have been pulled as root so restore the ownership
+	// of the local state directory
+	operator, err := env.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := operator.GetLocalSite()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = utils.Chown(filepath.Join(stateDir, defaults.LocalDir),
+		cluster.ServiceUser.UID, cluster.ServiceUser.GID)
+	return trace.Wrap(err)
+}
+
+// checkFreeSpace verifies that the filesystem of the specified directory
+// has at least requiredBytes of free space
+func checkFreeSpace(dir string, requiredBytes uint64) error {
+	info, err := systeminfo.New()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	fs, err := systeminfo.FilesystemForDir(info, dir)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if fs.FreeBytes() < requiredBytes {
+		return trace.BadParameter("not enough disk space on %v to stage the upgrade: "+
+			"%v required, %v available", fs.Filesystem.DirName,
+			humanize.Bytes(requiredBytes), humanize.Bytes(fs.FreeBytes()))
+	}
+	return nil
+}
+
+// queryLocalPackageLabels returns the labels of the specified package
+// in the local package store or nil if the package does not exist
+func queryLocalPackageLabels(packages pack.PackageService, locator loc.Locator) (map[string]string, error) {
+	envelope, err := packages.ReadPackageEnvelope(locator)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, trace.Wrap(err)
+	}
+	return envelope.RuntimeLabels, nil
+}
@@ -34,6 +34,7 @@ import (
 	rpcserver "github.com/gravitational/gravity/lib/rpc/server"
 	"github.com/gravitational/gravity/lib/state"
 	"github.com/gravitational/gravity/lib/storage"
+	"github.com/gravitational/gravity/lib/storage/encryption"
 	"github.com/gravitational/gravity/lib/systeminfo"
 	"github.com/gravitational/gravity/lib/utils"
 	"github.com/gravitational/gravity/tool/common"
@@ -132,6 +133,12 @@ func (g *Application) getEnvWithArgs(args localenv.LocalEnvironmentArgs) (*local
 	if err == nil && cfg.Devmode {
 		args.Insecure = true
 	}
+	if args.EncryptionKey == nil {
+		args.EncryptionKey, err = encryption.LoadKey(context.TODO())
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
 	return localenv.NewLocalEnvironment(args)
 }
 
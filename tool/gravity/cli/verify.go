@@ -0,0 +1,174 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"archive/tar"
+	"context"
+	"crypto"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/gravitational/gravity/lib/archive"
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/pack"
+	"github.com/gravitational/gravity/lib/provenance"
+
+	dockerarchive "github.com/docker/docker/pkg/archive"
+	"github.com/gravitational/trace"
+)
+
+type appVerifyConfig struct {
+	// Package is the application package to verify
+	Package loc.Locator
+	// KeyPaths lists the paths to the trusted public keys
+	KeyPaths []string
+	// OpsCenterURL is the optional Gravity Hub with the application package
+	OpsCenterURL string
+	// InstallerDir is the optional unpacked installer directory
+	// with the application package
+	InstallerDir string
+}
+
+// verifyApp verifies the signed build provenance embedded into the specified
+// application package against the trusted keys and the package contents
+func verifyApp(env *localenv.LocalEnvironment, config appVerifyConfig) error {
+	if config.OpsCenterURL != "" && config.InstallerDir != "" {
+		return trace.BadParameter("--ops-url and --installer-dir can not be used together")
+	}
+	keys, err := readPublicKeys(config.KeyPaths)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	var packages pack.PackageService
+	if config.InstallerDir != "" {
+		installerEnv, err := localenv.NewLocalEnvironment(localenv.LocalEnvironmentArgs{
+			StateDir:        config.InstallerDir,
+			ReadonlyBackend: true,
+		})
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		defer installerEnv.Close()
+		packages = installerEnv.Packages
+	} else {
+		packages, err = getAppPackageService(env, config.OpsCenterURL)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	attestation, err := readAttestation(packages, config.Package)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	p, err := provenance.Verify(*attestation, keys)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if p.Image != config.Package.String() {
+		return trace.CompareFailed("provenance is recorded for image %v, not %v",
+			p.Image, config.Package)
+	}
+	env.PrintStep("Signature of the build provenance is valid")
+	dir, err := ioutil.TempDir("", "verify")
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer os.RemoveAll(dir)
+	env.PrintStep("Unpacking application %v", config.Package)
+	if err := pack.Unpack(packages, config.Package, dir, nil); err != nil {
+		return trace.Wrap(err)
+	}
+	env.PrintStep("Verifying image contents against the build provenance")
+	skipped, err := provenance.VerifyMaterials(context.TODO(), *p, packages,
+		filepath.Join(dir, defaults.RegistryDir))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	for _, material := range skipped {
+		env.Printf("Package %v is not available, skipping.\n", material.Name)
+	}
+	env.Printf("\nBuilder:\t%v (tele %v)\n", p.Builder.ID, p.Builder.Version)
+	if p.Source.Revision != "" {
+		env.Printf("Source:\t\t%v@%v\n", p.Source.URI, p.Source.Revision)
+	}
+	env.Printf("Finished:\t%v\n", p.Finished.Format(constants.HumanDateFormatSeconds))
+	verified := len(p.Materials) - len(skipped) - countMaterials(p.Materials, provenance.MaterialManifest)
+	env.Printf("Verified:\t%v packages and container images, %v skipped\n", verified, len(skipped))
+	return nil
+}
+
+// readAttestation reads the build provenance attestation embedded
+// into the specified application package
+func readAttestation(packages pack.PackageService, locator loc.Locator) (*provenance.Attestation, error) {
+	_, reader, err := packages.ReadPackage(locator)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer reader.Close()
+	stream, err := dockerarchive.DecompressStream(reader)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer stream.Close()
+	var attestation *provenance.Attestation
+	err = archive.TarGlob(tar.NewReader(stream), defaults.ResourcesDir,
+		[]string{defaults.AttestationFilename}, func(_ string, r io.Reader) error {
+			attestation, err = provenance.Decode(r)
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			return archive.Abort
+		})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if attestation == nil {
+		return nil, trace.NotFound("application %v does not have a signed build provenance, "+
+			"it was built without --sign-key or with an older version of tele", locator)
+	}
+	return attestation, nil
+}
+
+func readPublicKeys(paths []string) (keys []crypto.PublicKey, err error) {
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, trace.ConvertSystemError(err)
+		}
+		key, err := provenance.ParsePublicKeyPEM(data)
+		if err != nil {
+			return nil, trace.Wrap(err, "failed to parse public key %v", path)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func countMaterials(materials []provenance.Material, materialType provenance.MaterialType) (count int) {
+	for _, material := range materials {
+		if material.Type == materialType {
+			count++
+		}
+	}
+	return count
+}
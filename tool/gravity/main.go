@@ -41,7 +41,7 @@ func main() {
 	app := kingpin.New("gravity", "Gravity cluster management tool.")
 	if err := run(app); err != nil {
 		if errCode, ok := trace.Unwrap(err).(utils.ExitCodeError); ok {
-			if errCode != installer.ErrCompleted {
+			if errCode != installer.ErrCompleted && !utils.IsSilentExitCodeError(errCode) {
 				log.WithError(err).Warn("Command failed.")
 				common.PrintError(errCode.OrigError())
 			}
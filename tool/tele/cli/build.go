@@ -18,9 +18,22 @@ package cli
 
 import (
 	"context"
+	"crypto"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/gravitational/gravity/lib/app/docker"
 	"github.com/gravitational/gravity/lib/app/service"
+	"github.com/gravitational/gravity/lib/buildcache"
 	"github.com/gravitational/gravity/lib/builder"
+	"github.com/gravitational/gravity/lib/builder/plugin"
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/provenance"
+	"github.com/gravitational/gravity/lib/scan"
 	"github.com/gravitational/gravity/lib/utils"
 
 	"github.com/gravitational/trace"
@@ -42,10 +55,214 @@ type BuildParameters struct {
 	Silent bool
 	// Insecure turns on insecure verify mode
 	Insecure bool
+	// Delta indicates whether to build a delta cluster image
+	Delta bool
+	// BaseImagePath is the path to the cluster image to build the delta cluster image against
+	BaseImagePath string
+	// Scan enables vulnerability scan of the application images
+	Scan bool
+	// ScannerPath is the path to the vulnerability scanner binary
+	ScannerPath string
+	// ScanThreshold is the lowest vulnerability severity that fails the build
+	ScanThreshold string
+	// ScanIgnoreUnfixed ignores vulnerabilities without an available fix
+	ScanIgnoreUnfixed bool
+	// RegistryConfig is the path to the Docker client configuration file
+	// with the registry credentials
+	RegistryConfig string
+	// PushTo is the registry repository to publish the built image to
+	PushTo string
+	// Architectures is the comma-separated list of CPU architectures
+	// to build the image for
+	Architectures string
+	// Reproducible builds a byte-identical image from identical inputs
+	Reproducible bool
+	// CacheDir is the build cache directory, if was specified
+	CacheDir string
+	// NoCache disables the build cache
+	NoCache bool
+	// Plugins lists the paths to the build plugins
+	Plugins []string
+	// SigningKeyPath is the path to the private key to sign the build provenance with
+	SigningKeyPath string
+	// BuilderID identifies the builder in the build provenance
+	BuilderID string
+	// Arguments lists the build command line arguments
+	Arguments []string
+	// RuntimeOnly builds the runtime-only cluster image without an application
+	RuntimeOnly bool
+}
+
+// timestamp returns the time to record in the image of a reproducible build.
+// The time is taken from the SOURCE_DATE_EPOCH environment variable
+// and defaults to the Unix epoch
+func (p BuildParameters) timestamp() (time.Time, error) {
+	if !p.Reproducible {
+		return time.Time{}, nil
+	}
+	epoch := os.Getenv(constants.EnvSourceDateEpoch)
+	if epoch == "" {
+		return time.Unix(0, 0).UTC(), nil
+	}
+	seconds, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return time.Time{}, trace.BadParameter("%v should be the number of seconds "+
+			"since the Unix epoch, got %q", constants.EnvSourceDateEpoch, epoch)
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+// cache returns the build cache or nil if the cache is disabled
+func (p BuildParameters) cache() (*buildcache.Cache, error) {
+	if p.NoCache {
+		if p.CacheDir != "" {
+			return nil, trace.BadParameter("--cache-dir and --no-cache can not be used together")
+		}
+		return nil, nil
+	}
+	dir, err := utils.EnsureLocalPath(p.CacheDir, defaults.LocalCacheDir, defaults.BuildCacheDir)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	cache, err := buildcache.New(dir)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return cache, nil
+}
+
+// architectures returns the list of CPU architectures to build the image for
+func (p BuildParameters) architectures() (architectures []string) {
+	for _, arch := range strings.Split(p.Architectures, ",") {
+		if arch = strings.TrimSpace(arch); arch != "" {
+			architectures = append(architectures, arch)
+		}
+	}
+	return architectures
+}
+
+// registryAuth returns the registry credentials from the Docker client
+// configuration file. The default configuration file is optional
+func (p BuildParameters) registryAuth() (docker.RegistryAuth, error) {
+	if p.RegistryConfig != "" {
+		auth, err := docker.ReadRegistryAuth(p.RegistryConfig)
+		return auth, trace.Wrap(err)
+	}
+	auth, err := docker.ReadRegistryAuth(docker.DockerConfigPath())
+	if err != nil && !trace.IsNotFound(err) {
+		return nil, trace.Wrap(err)
+	}
+	return auth, nil
+}
+
+// plugins loads the build plugins
+func (p BuildParameters) plugins() (plugins []plugin.Plugin, err error) {
+	for _, path := range p.Plugins {
+		loaded, err := plugin.Load(path)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		plugins = append(plugins, loaded)
+	}
+	return plugins, nil
+}
+
+// signingKey returns the key to sign the build provenance with
+// or nil if the provenance should not be recorded
+func (p BuildParameters) signingKey() (crypto.Signer, error) {
+	if p.SigningKeyPath == "" {
+		if p.BuilderID != "" {
+			return nil, trace.BadParameter("--builder-id requires --sign-key")
+		}
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(p.SigningKeyPath)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	key, err := provenance.ParsePrivateKeyPEM(data)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return key, nil
+}
+
+// scanner returns the vulnerability scanner and the scan policy
+// specified with the build parameters
+func (p BuildParameters) scanner() (scanner scan.Scanner, policy scan.Policy, err error) {
+	if !p.Scan {
+		if p.ScanThreshold != "" {
+			return nil, policy, trace.BadParameter("--scan-threshold requires --scan")
+		}
+		return nil, policy, nil
+	}
+	if p.ScanThreshold != "" {
+		policy.Threshold, err = scan.ParseSeverity(p.ScanThreshold)
+		if err != nil {
+			return nil, policy, trace.Wrap(err)
+		}
+	}
+	policy.IgnoreUnfixed = p.ScanIgnoreUnfixed
+	return scan.NewTrivy(p.ScannerPath), policy, nil
 }
 
 // build builds an installer tarball according to the provided parameters
 func build(ctx context.Context, params BuildParameters, req service.VendorRequest) error {
+	if params.Delta != (params.BaseImagePath != "") {
+		return trace.BadParameter("--delta and --from should be specified together")
+	}
+	if params.RuntimeOnly {
+		if params.ManifestPath != "" {
+			return trace.BadParameter("--runtime-only builds the image without a manifest, " +
+				"use a manifest of kind Platform to customize the runtime-only image")
+		}
+		dir, err := ioutil.TempDir("", "platform")
+		if err != nil {
+			return trace.ConvertSystemError(err)
+		}
+		defer os.RemoveAll(dir)
+		params.ManifestPath, err = builder.WritePlatformManifest(dir, req.PackageName, req.PackageVersion)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	if params.ManifestPath == "" {
+		params.ManifestPath = defaults.ManifestFileName
+	}
+	scanner, scanPolicy, err := params.scanner()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	plugins, err := params.plugins()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	signingKey, err := params.signingKey()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	req.Architectures = params.architectures()
+	req.Cache, err = params.cache()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	timestamp, err := params.timestamp()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if req.PullFromRegistry || params.PushTo != "" {
+		req.RegistryAuth, err = params.registryAuth()
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	var baseImage *builder.BaseImage
+	if params.Delta {
+		baseImage, err = builder.ReadBaseImage(params.BaseImagePath)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
 	installerBuilder, err := builder.New(builder.Config{
 		Context:          ctx,
 		StateDir:         params.StateDir,
@@ -56,6 +273,15 @@ func build(ctx context.Context, params BuildParameters, req service.VendorReques
 		SkipVersionCheck: params.SkipVersionCheck,
 		VendorReq:        req,
 		Progress:         utils.NewProgress(ctx, "Build", 6, params.Silent),
+		BaseImage:        baseImage,
+		Scanner:          scanner,
+		ScanPolicy:       scanPolicy,
+		PushTo:           params.PushTo,
+		Timestamp:        timestamp,
+		Plugins:          plugins,
+		SigningKey:       signingKey,
+		BuilderID:        params.BuilderID,
+		Arguments:        params.Arguments,
 	})
 	if err != nil {
 		return trace.Wrap(err)
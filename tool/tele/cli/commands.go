@@ -17,6 +17,8 @@ limitations under the License.
 package cli
 
 import (
+	"time"
+
 	"gopkg.in/alecthomas/kingpin.v2"
 
 	"github.com/gravitational/gravity/lib/constants"
@@ -37,10 +39,25 @@ type Application struct {
 	VersionCmd VersionCmd
 	// BuildCmd builds app installer tarball
 	BuildCmd BuildCmd
+	// LintCmd validates the cluster image manifest
+	LintCmd LintCmd
 	// ListCmd lists available apps and runtimes
 	ListCmd ListCmd
 	// PullCmd downloads app installer from Ops Center
 	PullCmd PullCmd
+	// LoginCmd logs into Gravity Hub or cluster with single sign-on
+	LoginCmd LoginCmd
+}
+
+// LoginCmd logs into Gravity Hub or cluster with single sign-on
+type LoginCmd struct {
+	*kingpin.CmdClause
+	// Hub is the address of Gravity Hub or cluster to log into
+	Hub *string
+	// Connector is the name of the auth connector to log in with
+	Connector *string
+	// TTL is the validity period of the issued certificates
+	TTL *time.Duration
 }
 
 // VersionCmd outputs the binary version
@@ -77,6 +94,51 @@ type BuildCmd struct {
 	Parallel *int
 	// Quiet allows to suppress console output
 	Quiet *bool
+	// Delta enables building a delta cluster image
+	Delta *bool
+	// From is the cluster image to build the delta cluster image against
+	From *string
+	// Scan enables vulnerability scan of the application images
+	Scan *bool
+	// ScannerPath is the path to the vulnerability scanner binary
+	ScannerPath *string
+	// ScanThreshold is the lowest vulnerability severity that fails the build
+	ScanThreshold *string
+	// ScanIgnoreUnfixed ignores vulnerabilities without an available fix
+	ScanIgnoreUnfixed *bool
+	// PullFromRegistry pulls the application images directly from their registries
+	PullFromRegistry *bool
+	// RegistryConfig is the path to the Docker client configuration file with registry credentials
+	RegistryConfig *string
+	// Push is the registry repository to publish the built image to
+	Push *string
+	// Arch is the comma-separated list of CPU architectures to build the image for
+	Arch *string
+	// Reproducible builds a byte-identical image from identical inputs
+	Reproducible *bool
+	// CacheDir is the build cache directory
+	CacheDir *string
+	// NoCache disables the build cache
+	NoCache *bool
+	// Plugins lists the paths to the build plugins
+	Plugins *[]string
+	// SignKey is the path to the private key to sign the build provenance with
+	SignKey *string
+	// BuilderID identifies the builder in the build provenance
+	BuilderID *string
+	// RuntimeOnly builds the runtime-only cluster image without an application
+	RuntimeOnly *bool
+}
+
+// LintCmd validates the cluster image manifest
+type LintCmd struct {
+	*kingpin.CmdClause
+	// ManifestPath is the path to the manifest file
+	ManifestPath *string
+	// Format is the output format
+	Format *constants.Format
+	// Strict fails the command on warnings
+	Strict *bool
 }
 
 type ListCmd struct {
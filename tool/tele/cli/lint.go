@@ -0,0 +1,68 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gravitational/gravity/lib/constants"
+	"github.com/gravitational/gravity/lib/schema"
+
+	"github.com/ghodss/yaml"
+	"github.com/gravitational/trace"
+)
+
+// lint validates the manifest at the specified path and outputs the found issues.
+// Returns an error if the manifest has errors, or warnings in strict mode
+func lint(manifestPath string, format constants.Format, strict bool) error {
+	issues, err := schema.Lint(manifestPath)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if issues == nil {
+		issues = schema.LintIssues{}
+	}
+	switch format {
+	case constants.EncodingText:
+		for _, issue := range issues {
+			fmt.Println(issue)
+		}
+		if len(issues) == 0 {
+			fmt.Printf("No issues found in %v.\n", manifestPath)
+		}
+	case constants.EncodingJSON:
+		bytes, err := json.MarshalIndent(issues, "", "  ")
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		fmt.Println(string(bytes))
+	case constants.EncodingYAML:
+		bytes, err := yaml.Marshal(issues)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		fmt.Print(string(bytes))
+	default:
+		return trace.BadParameter("unsupported output format %q, supported are: %v",
+			format, []constants.Format{constants.EncodingText, constants.EncodingJSON, constants.EncodingYAML})
+	}
+	if issues.HasErrors() || (strict && len(issues) != 0) {
+		return trace.BadParameter("%v has %v issue(s)", manifestPath, len(issues))
+	}
+	return nil
+}
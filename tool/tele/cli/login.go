@@ -0,0 +1,57 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	"github.com/gravitational/gravity/lib/localenv"
+	"github.com/gravitational/gravity/lib/utils"
+	"github.com/gravitational/gravity/lib/webapi"
+
+	"github.com/gravitational/trace"
+)
+
+// login logs into the specified Gravity Hub or cluster with single sign-on.
+// The user authenticates in a browser, possibly on another machine, and
+// the short-lived certificates issued for the user are saved on local disk
+func login(env localenv.LocalEnvironment, hubURL, connectorID string, ttl time.Duration) error {
+	hubURL = utils.ParseOpsCenterAddress(hubURL, defaults.HTTPSPort)
+	result, err := webapi.DeviceLogin(context.TODO(), webapi.DeviceLoginConfig{
+		URL:         hubURL,
+		ConnectorID: connectorID,
+		CertTTL:     ttl,
+		Insecure:    env.Insecure,
+		Prompt: func(login webapi.DeviceLoginResponse) {
+			fmt.Printf("To log in, open the following URL in a browser and confirm the code %v:\n\n    %v\n\n",
+				login.UserCode, login.VerificationURL)
+			fmt.Println("Waiting for the login to complete...")
+		},
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	err = env.Credentials.UpsertKey(hubURL, result.Username, result.Key)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	fmt.Printf("Logged in to %v as %v.\n", hubURL, result.Username)
+	return nil
+}
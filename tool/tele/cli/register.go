@@ -22,6 +22,7 @@ import (
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/loc"
+	"github.com/gravitational/gravity/lib/scan"
 	"github.com/gravitational/gravity/tool/common"
 
 	"gopkg.in/alecthomas/kingpin.v2"
@@ -41,11 +42,11 @@ func RegisterCommands(app *kingpin.Application) Application {
 	tele.VersionCmd.Output = common.Format(tele.VersionCmd.Flag("output", "Output format: text or json.").Short('o').Default(string(constants.EncodingText)))
 
 	tele.BuildCmd.CmdClause = app.Command("build", "Cluster and application image build tool.")
-	tele.BuildCmd.ManifestPath = tele.BuildCmd.Arg("path", fmt.Sprintf("Path to the cluster image manifest file (must be named %q), or unpacked Helm chart to build an application image out of.", defaults.ManifestFileName)).Default(defaults.ManifestFileName).String()
+	tele.BuildCmd.ManifestPath = tele.BuildCmd.Arg("path", fmt.Sprintf("Path to the cluster image manifest file (must be named %q), or unpacked Helm chart to build an application image out of. Defaults to %q.", defaults.ManifestFileName, defaults.ManifestFileName)).String()
 	tele.BuildCmd.OutFile = tele.BuildCmd.Flag("output", "Cluster or application image file name. Defaults to <name>-<version>.tar.").Short('o').String()
 	tele.BuildCmd.Overwrite = tele.BuildCmd.Flag("overwrite", "Overwrite the existing image file.").Short('f').Bool()
-	tele.BuildCmd.Name = tele.BuildCmd.Flag("name", "Optional cluster image name, overrides the one specified in the manifest file.").Hidden().String()
-	tele.BuildCmd.Version = tele.BuildCmd.Flag("version", "Optional cluster image version, overrides the one specified in the manifest file.").Hidden().String()
+	tele.BuildCmd.Name = tele.BuildCmd.Flag("name", "Optional cluster image name, overrides the one specified in the manifest file.").String()
+	tele.BuildCmd.Version = tele.BuildCmd.Flag("version", "Optional cluster image version, overrides the one specified in the manifest file.").String()
 	tele.BuildCmd.VendorPatterns = tele.BuildCmd.Flag("glob", "File pattern to search for container image references.").Default(defaults.VendorPattern).Hidden().Strings()
 	tele.BuildCmd.VendorIgnorePatterns = tele.BuildCmd.Flag("ignore", "Ignore files matching this regular expression when searching for container references.").Hidden().Strings()
 	tele.BuildCmd.SetImages = loc.ImagesSlice(tele.BuildCmd.Flag("set-image", "Rewrite Docker image versions in the image resource files during vendoring, e.g. 'postgres:9.3.4' will rewrite all images with name 'postgres' to 'postgres:9.3.4'.").Hidden())
@@ -53,6 +54,28 @@ func RegisterCommands(app *kingpin.Application) Application {
 	tele.BuildCmd.SkipVersionCheck = tele.BuildCmd.Flag("skip-version-check", "Skip version compatibility check.").Hidden().Bool()
 	tele.BuildCmd.Parallel = tele.BuildCmd.Flag("parallel", "Specifies the number of concurrent tasks. If < 0, the number of tasks is not restricted, if unspecified, then tasks are capped at the number of logical CPU cores.").Int()
 	tele.BuildCmd.Quiet = tele.BuildCmd.Flag("quiet", "Suppress any output to stdout.").Short('q').Bool()
+	tele.BuildCmd.Delta = tele.BuildCmd.Flag("delta", "Build a delta cluster image with only the packages changed since the cluster image specified with --from.").Bool()
+	tele.BuildCmd.From = tele.BuildCmd.Flag("from", "Path to the previous cluster image tarball to build the delta cluster image against.").String()
+	tele.BuildCmd.Scan = tele.BuildCmd.Flag("scan", "Scan the application container images for vulnerabilities and embed the scan report into the image.").Bool()
+	tele.BuildCmd.ScannerPath = tele.BuildCmd.Flag("scanner-path", "Path to the trivy vulnerability scanner binary. Looked up in PATH if unspecified.").String()
+	tele.BuildCmd.ScanThreshold = tele.BuildCmd.Flag("scan-threshold", fmt.Sprintf("Fail the build if vulnerabilities of this or higher severity are found. One of %v.", scan.Severities)).String()
+	tele.BuildCmd.ScanIgnoreUnfixed = tele.BuildCmd.Flag("scan-ignore-unfixed", "Ignore vulnerabilities without an available fix when applying --scan-threshold.").Bool()
+	tele.BuildCmd.PullFromRegistry = tele.BuildCmd.Flag("pull-from-registry", "Pull the application container images directly from their registries instead of the local Docker daemon. Images can be pinned to a digest, e.g. 'nginx@sha256:...'.").Bool()
+	tele.BuildCmd.RegistryConfig = tele.BuildCmd.Flag("registry-config", "Path to the Docker client configuration file with the registry credentials. Defaults to ~/.docker/config.json.").String()
+	tele.BuildCmd.Arch = tele.BuildCmd.Flag("arch", "Comma-separated list of CPU architectures to build the image for, e.g. 'amd64,arm64'. Multiple architectures require --pull-from-registry. Defaults to amd64.").String()
+	tele.BuildCmd.Push = tele.BuildCmd.Flag("push", "Publish the built image to the specified registry repository as an artifact, e.g. 'registry.example.com/org/name'. Tagged with the image version unless a tag is specified.").String()
+	tele.BuildCmd.Reproducible = tele.BuildCmd.Flag("reproducible", fmt.Sprintf("Build a byte-identical image from identical inputs. The time recorded in the image is taken from %v or defaults to the Unix epoch.", constants.EnvSourceDateEpoch)).Bool()
+	tele.BuildCmd.CacheDir = tele.BuildCmd.Flag("cache-dir", "Directory of the build cache with the vendored image layers reused by subsequent builds. Defaults to ~/.gravity/cache/build.").String()
+	tele.BuildCmd.NoCache = tele.BuildCmd.Flag("no-cache", "Do not use the build cache.").Bool()
+	tele.BuildCmd.Plugins = tele.BuildCmd.Flag("plugin", "Path to the build plugin to invoke before the application is packaged and after the image is written: an executable, or a Go plugin with the .so extension. Can be repeated, the plugins are invoked in order.").Strings()
+	tele.BuildCmd.SignKey = tele.BuildCmd.Flag("sign-key", "Path to the PEM-encoded ECDSA or RSA private key to sign the build provenance with. The signed provenance is embedded into the image and can be verified with 'gravity app verify'.").String()
+	tele.BuildCmd.RuntimeOnly = tele.BuildCmd.Flag("runtime-only", "Build a Kubernetes-only cluster image without an application, with the applications installed later from the catalog. The image is named 'platform' and versioned after tele unless --name and --version are specified.").Bool()
+	tele.BuildCmd.BuilderID = tele.BuildCmd.Flag("builder-id", "Builder identity to record in the build provenance, e.g. the CI job URL. Defaults to user@host.").String()
+
+	tele.LintCmd.CmdClause = app.Command("lint", "Validate the cluster or application image manifest.")
+	tele.LintCmd.ManifestPath = tele.LintCmd.Arg("path", "Path to the image manifest file.").Default(defaults.ManifestFileName).String()
+	tele.LintCmd.Format = common.Format(tele.LintCmd.Flag("format", "Output format: text, json or yaml.").Default(string(constants.EncodingText)))
+	tele.LintCmd.Strict = tele.LintCmd.Flag("strict", "Fail on warnings as well as errors.").Bool()
 
 	tele.ListCmd.CmdClause = app.Command("ls", "List cluster and application images published to Gravity Hub.")
 	tele.ListCmd.Runtimes = tele.ListCmd.Flag("runtimes", "Show only runtimes.").Short('r').Hidden().Bool()
@@ -65,5 +88,10 @@ func RegisterCommands(app *kingpin.Application) Application {
 	tele.PullCmd.Force = tele.PullCmd.Flag("force", "Overwrite the existing image file.").Short('f').Bool()
 	tele.PullCmd.Quiet = tele.PullCmd.Flag("quiet", "Suppress any output to stdout.").Short('q').Bool()
 
+	tele.LoginCmd.CmdClause = app.Command("login", "Log into Gravity Hub or cluster with single sign-on.")
+	tele.LoginCmd.Hub = tele.LoginCmd.Flag("hub", "Address of Gravity Hub or cluster to log into.").Short('o').Required().String()
+	tele.LoginCmd.Connector = tele.LoginCmd.Flag("auth", "Name of the auth connector to log in with. Defaults to the connector of the cluster auth preference.").String()
+	tele.LoginCmd.TTL = tele.LoginCmd.Flag("ttl", "Validity period of the issued certificates.").Default(defaults.CertTTL.String()).Duration()
+
 	return tele
 }
@@ -51,13 +51,30 @@ func Run(tele Application) error {
 		return printVersion(*tele.VersionCmd.Output)
 	case tele.BuildCmd.FullCommand():
 		return build(context.Background(), BuildParameters{
-			StateDir:         *tele.StateDir,
-			ManifestPath:     *tele.BuildCmd.ManifestPath,
-			OutPath:          *tele.BuildCmd.OutFile,
-			Overwrite:        *tele.BuildCmd.Overwrite,
-			SkipVersionCheck: *tele.BuildCmd.SkipVersionCheck,
-			Silent:           *tele.BuildCmd.Quiet,
-			Insecure:         *tele.Insecure,
+			StateDir:          *tele.StateDir,
+			ManifestPath:      *tele.BuildCmd.ManifestPath,
+			OutPath:           *tele.BuildCmd.OutFile,
+			Overwrite:         *tele.BuildCmd.Overwrite,
+			SkipVersionCheck:  *tele.BuildCmd.SkipVersionCheck,
+			Silent:            *tele.BuildCmd.Quiet,
+			Insecure:          *tele.Insecure,
+			Delta:             *tele.BuildCmd.Delta,
+			BaseImagePath:     *tele.BuildCmd.From,
+			Scan:              *tele.BuildCmd.Scan,
+			ScannerPath:       *tele.BuildCmd.ScannerPath,
+			ScanThreshold:     *tele.BuildCmd.ScanThreshold,
+			ScanIgnoreUnfixed: *tele.BuildCmd.ScanIgnoreUnfixed,
+			RegistryConfig:    *tele.BuildCmd.RegistryConfig,
+			PushTo:            *tele.BuildCmd.Push,
+			Architectures:     *tele.BuildCmd.Arch,
+			Reproducible:      *tele.BuildCmd.Reproducible,
+			CacheDir:          *tele.BuildCmd.CacheDir,
+			NoCache:           *tele.BuildCmd.NoCache,
+			Plugins:           *tele.BuildCmd.Plugins,
+			SigningKeyPath:    *tele.BuildCmd.SignKey,
+			BuilderID:         *tele.BuildCmd.BuilderID,
+			Arguments:         os.Args[1:],
+			RuntimeOnly:       *tele.BuildCmd.RuntimeOnly,
 		}, service.VendorRequest{
 			PackageName:            *tele.BuildCmd.Name,
 			PackageVersion:         *tele.BuildCmd.Version,
@@ -67,7 +84,12 @@ func Run(tele Application) error {
 			SetDeps:                *tele.BuildCmd.SetDeps,
 			Parallel:               *tele.BuildCmd.Parallel,
 			VendorRuntime:          true,
+			PullFromRegistry:       *tele.BuildCmd.PullFromRegistry,
 		})
+	case tele.LintCmd.FullCommand():
+		return lint(*tele.LintCmd.ManifestPath,
+			*tele.LintCmd.Format,
+			*tele.LintCmd.Strict)
 	}
 
 	keystoreDir := *tele.StateDir
@@ -101,6 +123,11 @@ func Run(tele Application) error {
 		return list(*env,
 			*tele.ListCmd.All,
 			*tele.ListCmd.Format)
+	case tele.LoginCmd.FullCommand():
+		return login(*env,
+			*tele.LoginCmd.Hub,
+			*tele.LoginCmd.Connector,
+			*tele.LoginCmd.TTL)
 	}
 
 	return trace.NotFound("unknown command %v", cmd)